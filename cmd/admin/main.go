@@ -0,0 +1,124 @@
+// Command admin runs one-off support and maintenance operations against the
+// database the server uses.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/ninenine/babytrack/internal/anonymize"
+	"github.com/ninenine/babytrack/internal/apijson"
+	"github.com/ninenine/babytrack/internal/app"
+	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/journal"
+	"github.com/ninenine/babytrack/internal/media"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/transfer"
+	"github.com/ninenine/babytrack/internal/vaccination"
+)
+
+const usage = `usage: admin <command> [flags]
+
+commands:
+  anonymize -family <id> [-out fixture.json]
+        write a scrubbed copy of a family's data for reproducing bugs
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "anonymize":
+		anonymizeCmd(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func anonymizeCmd(args []string) {
+	fs := flag.NewFlagSet("anonymize", flag.ExitOnError)
+	configPath := fs.String("config", "./configs/config.yaml", "path to config file")
+	familyID := fs.String("family", "", "ID of the family to copy")
+	out := fs.String("out", "", "file to write the fixture to (default stdout)")
+	_ = fs.Parse(args) // ExitOnError exits on bad flags
+
+	if *familyID == "" {
+		log.Fatal("anonymize: -family is required")
+	}
+
+	cfg, err := app.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	database, err := db.New(cfg.Database.DSN)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer func() {
+		if closeErr := database.Close(); closeErr != nil {
+			log.Printf("error closing database: %v", closeErr)
+		}
+	}()
+
+	// Only the read paths used by exports are needed, so services are built
+	// without the notifiers, history and scanners the server adds
+	familyService := family.NewService(family.NewRepository(database.DB))
+	mediaService := media.NewService(media.NewRepository(database.DB), familyService, media.NoopScanner{}, 0)
+	transferService := transfer.NewService(
+		transfer.NewRepository(database.DB),
+		familyService,
+		feeding.NewService(feeding.NewRepository(database.DB)),
+		sleep.NewService(sleep.NewRepository(database.DB)),
+		medication.NewService(medication.NewRepository(database.DB)),
+		vaccination.NewService(vaccination.NewRepository(database.DB)),
+		appointment.NewService(appointment.NewRepository(database.DB)),
+		notes.NewService(notes.NewRepository(database.DB)),
+		journal.NewService(journal.NewRepository(database.DB), familyService, mediaService),
+	)
+	anonymizeService := anonymize.NewService(familyService, transferService)
+
+	fixture, err := anonymizeService.Family(context.Background(), *familyID)
+	if err != nil {
+		log.Fatalf("failed to anonymize family: %v", err) //nolint:gocritic // Acceptable in CLI - OS closes db on exit
+	}
+
+	if *out == "" {
+		if err := writeFixture(os.Stdout, fixture); err != nil {
+			log.Fatalf("failed to write fixture: %v", err)
+		}
+		return
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("failed to create %s: %v", *out, err)
+	}
+	if err := writeFixture(f, fixture); err != nil {
+		log.Fatalf("failed to write fixture: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("failed to write fixture: %v", err)
+	}
+	log.Printf("wrote fixture for %d children to %s", len(fixture.Children), *out)
+}
+
+func writeFixture(w io.Writer, fixture *anonymize.Fixture) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(apijson.UTC(fixture))
+}
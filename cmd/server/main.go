@@ -26,7 +26,10 @@ func main() {
 	}
 
 	// Initialise database
-	database, err := db.New(cfg.Database.DSN)
+	database, err := db.New(cfg.Database.DSN, db.WithSlowQueryLog(db.SlowQueryConfig{
+		Threshold:         cfg.Database.SlowQuery.Threshold,
+		ExplainSampleRate: cfg.Database.SlowQuery.ExplainSampleRate,
+	}))
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
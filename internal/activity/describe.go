@@ -0,0 +1,107 @@
+package activity
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+)
+
+// unknownActor stands in for writes with no recorded user: those made before
+// attribution began and those made by background jobs or devices
+const unknownActor = "Someone"
+
+// describe tells a record version as a sentence. Data that no longer decodes
+// still gets a plain description of the record type.
+func describe(rec *Record) string {
+	actor := rec.ActorName
+	if actor == "" {
+		actor = unknownActor
+	}
+
+	verb := "logged"
+	switch rec.Action {
+	case audit.ActionUpdate:
+		verb = "updated"
+	case audit.ActionDelete:
+		verb = "deleted"
+	}
+
+	var what string
+	switch rec.EntityType {
+	case audit.EntityFeeding:
+		var f feeding.Feeding
+		if json.Unmarshal(rec.Data, &f) == nil {
+			what = feedingPhrase(&f)
+		} else {
+			what = "a feeding"
+		}
+	case audit.EntitySleep:
+		var s sleep.Sleep
+		if json.Unmarshal(rec.Data, &s) == nil {
+			what = sleepPhrase(&s)
+			if _, ended := s.Duration(); !ended && rec.Action == audit.ActionCreate {
+				verb = "started"
+			}
+		} else {
+			what = "a sleep"
+		}
+	case audit.EntityVaccination:
+		var v vaccination.Vaccination
+		if json.Unmarshal(rec.Data, &v) == nil {
+			what = vaccinationPhrase(&v)
+			if rec.Action == audit.ActionCreate && v.Status == vaccination.StatusScheduled {
+				verb = "scheduled"
+			}
+		} else {
+			what = "a vaccination"
+		}
+	default:
+		what = "a " + string(rec.EntityType) + " record"
+	}
+
+	return fmt.Sprintf("%s %s %s for %s", actor, verb, what, rec.ChildName)
+}
+
+func feedingPhrase(f *feeding.Feeding) string {
+	switch f.Type {
+	case feeding.FeedingTypeBreast:
+		if d, ok := f.Duration(); ok {
+			return fmt.Sprintf("a %s breastfeed", d)
+		}
+		return "a breastfeed"
+	case feeding.FeedingTypeBottle, feeding.FeedingTypeFormula:
+		kind := "bottle"
+		if f.Type == feeding.FeedingTypeFormula {
+			kind = "formula feed"
+		}
+		if q, ok := f.Quantity(); ok {
+			return fmt.Sprintf("a %s %s", q.Round(1), kind)
+		}
+		return "a " + kind
+	case feeding.FeedingTypeSolid:
+		return "a solids meal"
+	}
+	return "a feeding"
+}
+
+func sleepPhrase(s *sleep.Sleep) string {
+	kind := "nap"
+	if s.Type == sleep.SleepTypeNight {
+		kind = "night's sleep"
+	}
+	if d, ok := s.Duration(); ok {
+		return fmt.Sprintf("a %s %s", d, kind)
+	}
+	return "a " + kind
+}
+
+func vaccinationPhrase(v *vaccination.Vaccination) string {
+	if v.Dose > 0 {
+		return fmt.Sprintf("the %s vaccination (dose %d)", v.Name, v.Dose)
+	}
+	return fmt.Sprintf("the %s vaccination", v.Name)
+}
@@ -0,0 +1,64 @@
+package activity
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ninenine/babytrack/internal/envelope"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterFamilyRoutes registers the activity feed on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/activity", h.list)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	filter := Filter{
+		ChildID: c.Query("child_id"),
+		ActorID: c.Query("actor_id"),
+		Before:  c.Query("before"),
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	feed, err := h.service.List(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), &filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	page := envelope.Pagination{HasMore: feed.HasMore}
+	if feed.HasMore && len(feed.Entries) > 0 {
+		page.Next = feed.Entries[len(feed.Entries)-1].ID
+	}
+	envelope.SetPagination(c, page)
+	c.JSON(http.StatusOK, feed)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
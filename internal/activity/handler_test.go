@@ -0,0 +1,126 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	listFn func(ctx context.Context, userID, familyID string, filter *Filter) (*Feed, error)
+}
+
+func (m *mockService) List(ctx context.Context, userID, familyID string, filter *Filter) (*Feed, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, familyID, filter)
+	}
+	return &Feed{FamilyID: familyID, Entries: []Entry{}}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterFamilyRoutes(protected.Group("/families"))
+	return router
+}
+
+func TestList_Success(t *testing.T) {
+	var gotUserID, gotFamilyID string
+	var gotFilter *Filter
+	svc := &mockService{
+		listFn: func(ctx context.Context, userID, familyID string, filter *Filter) (*Feed, error) {
+			gotUserID, gotFamilyID, gotFilter = userID, familyID, filter
+			return &Feed{
+				FamilyID: familyID,
+				Entries:  []Entry{{ID: "12", Summary: "Sam logged a 40m nap for Emma"}, {ID: "9"}},
+				HasMore:  true,
+			}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/families/family-1/activity?child_id=child-1&actor_id=user-2&before=40&limit=2", http.NoBody)
+	req.Header.Set("X-Envelope", "true")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if gotUserID != "test-user-123" || gotFamilyID != "family-1" {
+		t.Errorf("List() called with %s, %s", gotUserID, gotFamilyID)
+	}
+	want := Filter{ChildID: "child-1", ActorID: "user-2", Before: "40", Limit: 2}
+	if *gotFilter != want {
+		t.Errorf("filter = %+v, want %+v", *gotFilter, want)
+	}
+
+	var feed Feed
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(feed.Entries) != 2 || feed.Entries[0].Summary != "Sam logged a 40m nap for Emma" || !feed.HasMore {
+		t.Errorf("feed = %+v", feed)
+	}
+}
+
+func TestList_InvalidLimit(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/families/family-1/activity?limit=zero", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestList_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrNotMember, http.StatusForbidden},
+		{ErrInvalidCursor, http.StatusBadRequest},
+		{errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			svc := &mockService{
+				listFn: func(ctx context.Context, userID, familyID string, filter *Filter) (*Feed, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("GET", "/families/family-1/activity", http.NoBody)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
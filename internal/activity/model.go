@@ -0,0 +1,50 @@
+package activity
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+// Entry is one write to a family's records, told as a sentence such as
+// "Sam logged a 40m nap for Emma"
+type Entry struct {
+	ID         string           `json:"id"`
+	ChildID    string           `json:"child_id"`
+	ChildName  string           `json:"child_name"`
+	ActorID    string           `json:"actor_id,omitempty"`
+	ActorName  string           `json:"actor_name,omitempty"`
+	EntityType audit.EntityType `json:"entity_type"`
+	EntityID   string           `json:"entity_id"`
+	Action     audit.Action     `json:"action"`
+	Summary    string           `json:"summary"`
+	At         time.Time        `json:"at"`
+}
+
+type Feed struct {
+	FamilyID string  `json:"family_id"`
+	Entries  []Entry `json:"entries"`
+	// HasMore is set when older entries exist; pass the last entry's ID as
+	// before to fetch them
+	HasMore bool `json:"has_more"`
+}
+
+type Filter struct {
+	ChildID string
+	ActorID string
+	// Before is an entry ID; only older entries are returned
+	Before string
+	Limit  int
+}
+
+// Record is a record version with the names needed to describe it
+type Record struct {
+	audit.Version
+	ChildName string
+	ActorName string
+}
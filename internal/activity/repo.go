@@ -0,0 +1,112 @@
+package activity
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ninenine/babytrack/internal/health"
+
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	// List returns the newest record versions of the family's children,
+	// leaving out the entity types in hidden. before is a version ID, 0 for
+	// the newest. When viewerID is set, records another member has made
+	// private are left out too.
+	List(ctx context.Context, familyID string, filter *Filter, before int64, hidden []string, viewerID string, limit int) ([]Record, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck reads the version history activity feeds are built from
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "record_versions")
+}
+
+// privateFrom matches a version another member made private. Feedings
+// record their author and sleep who started it.
+const privateFrom = `COALESCE((p.data->>'private')::boolean, FALSE)
+	AND COALESCE(p.data->>'author_id', p.data->>'started_by', '') <> $%d`
+
+func (r *repository) List(ctx context.Context, familyID string, filter *Filter, before int64, hidden []string, viewerID string, limit int) ([]Record, error) {
+	query := `
+		SELECT v.id, v.entity_type, v.entity_id, v.child_id, COALESCE(v.actor_id, ''), v.action, v.data, v.recorded_at,
+		       c.name, COALESCE(u.name, '')
+		FROM record_versions v
+		JOIN children c ON c.id = v.child_id
+		LEFT JOIN users u ON u.id = v.actor_id
+		WHERE c.family_id = $1
+	`
+	args := []any{familyID}
+	argIndex := 2
+
+	if filter.ChildID != "" {
+		query += fmt.Sprintf(` AND v.child_id = $%d`, argIndex)
+		args = append(args, filter.ChildID)
+		argIndex++
+	}
+
+	if filter.ActorID != "" {
+		query += fmt.Sprintf(` AND v.actor_id = $%d`, argIndex)
+		args = append(args, filter.ActorID)
+		argIndex++
+	}
+
+	if before > 0 {
+		query += fmt.Sprintf(` AND v.id < $%d`, argIndex)
+		args = append(args, before)
+		argIndex++
+	}
+
+	if len(hidden) > 0 {
+		query += fmt.Sprintf(` AND v.entity_type <> ALL($%d)`, argIndex)
+		args = append(args, pq.Array(hidden))
+		argIndex++
+	}
+
+	// A version is left out when it was private, or when the record is
+	// private now
+	if viewerID != "" {
+		query += fmt.Sprintf(` AND NOT EXISTS (
+			SELECT 1 FROM record_versions p
+			WHERE p.entity_type = v.entity_type AND p.entity_id = v.entity_id
+			AND (p.id = v.id OR p.id = (
+				SELECT MAX(l.id) FROM record_versions l
+				WHERE l.entity_type = v.entity_type AND l.entity_id = v.entity_id))
+			AND `+privateFrom+`)`, argIndex)
+		args = append(args, viewerID)
+		argIndex++
+	}
+
+	query += fmt.Sprintf(` ORDER BY v.id DESC LIMIT $%d`, argIndex)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	records := []Record{}
+	for rows.Next() {
+		var rec Record
+		var data []byte
+		if err := rows.Scan(
+			&rec.ID, &rec.EntityType, &rec.EntityID, &rec.ChildID, &rec.ActorID, &rec.Action, &data, &rec.RecordedAt,
+			&rec.ChildName, &rec.ActorName,
+		); err != nil {
+			return nil, err
+		}
+		rec.Data = data
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
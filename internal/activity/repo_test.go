@@ -0,0 +1,89 @@
+package activity
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	"github.com/ninenine/babytrack/internal/audit"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var recordColumns = []string{
+	"id", "entity_type", "entity_id", "child_id", "actor_id", "action", "data", "recorded_at", "name", "name",
+}
+
+func TestRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("WHERE c.family_id = \\$1 AND v.child_id = \\$2 AND v.actor_id = \\$3 AND v.id < \\$4 AND v.entity_type <> ALL\\(\\$5\\) ORDER BY v.id DESC LIMIT \\$6").
+		WithArgs("family-1", "child-1", "user-1", int64(90), pq.Array([]string{"vaccination"}), 21).
+		WillReturnRows(sqlmock.NewRows(recordColumns).
+			AddRow(12, "sleep", "sleep-1", "child-1", "user-1", "create", []byte(`{"type":"nap"}`), now, "Emma", "Sam").
+			AddRow(11, "feeding", "feeding-1", "child-1", "", "delete", []byte(`{}`), now, "Emma", ""))
+
+	filter := &Filter{ChildID: "child-1", ActorID: "user-1"}
+	records, err := repo.List(context.Background(), "family-1", filter, 90, []string{"vaccination"}, "", 21)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("List() = %d records, want 2", len(records))
+	}
+	if r := records[0]; r.ID != 12 || r.EntityType != audit.EntitySleep || r.ChildName != "Emma" || r.ActorName != "Sam" {
+		t.Errorf("List()[0] = %+v", r)
+	}
+	if r := records[1]; r.ActorID != "" || r.Action != audit.ActionDelete {
+		t.Errorf("List()[1] = %+v", r)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_List_FamilyOnly(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("WHERE c.family_id = \\$1 ORDER BY v.id DESC LIMIT \\$2").
+		WithArgs("family-1", 51).
+		WillReturnRows(sqlmock.NewRows(recordColumns))
+
+	records, err := repo.List(context.Background(), "family-1", &Filter{}, 0, nil, "", 51)
+	if err != nil || len(records) != 0 {
+		t.Errorf("List() = %v, %v; want no records", records, err)
+	}
+}
+
+func TestRepository_List_Private(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("WHERE c.family_id = \\$1 AND NOT EXISTS \\(.+p.data->>'private'.+<> \\$2\\) ORDER BY v.id DESC LIMIT \\$3").
+		WithArgs("family-1", "user-1", 51).
+		WillReturnRows(sqlmock.NewRows(recordColumns))
+
+	if _, err := repo.List(context.Background(), "family-1", &Filter{}, 0, nil, "user-1", 51); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
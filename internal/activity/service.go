@@ -0,0 +1,127 @@
+// Package activity tells who did what across a family's records, as a feed of
+// sentences such as "Sam logged a 40m nap for Emma". It is read from the
+// record version history, so it covers the record types kept there.
+package activity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrNotMember     = errors.New("user is not a member of this family")
+	ErrInvalidCursor = errors.New("before must be an entry ID")
+)
+
+type Service interface {
+	List(ctx context.Context, userID, familyID string, filter *Filter) (*Feed, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	visibility    visibility.Service
+}
+
+type Option func(*service)
+
+// WithVisibility leaves record types hidden from a member out of their feed
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+func NewService(repo Repository, familyService family.Service, opts ...Option) Service {
+	s := &service{
+		repo:          repo,
+		familyService: familyService,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether the activity feed can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) List(ctx context.Context, userID, familyID string, filter *Filter) (*Feed, error) {
+	if _, err := s.familyService.GetMemberRole(ctx, familyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+
+	var before int64
+	if filter.Before != "" {
+		id, err := strconv.ParseInt(filter.Before, 10, 64)
+		if err != nil || id < 1 {
+			return nil, ErrInvalidCursor
+		}
+		before = id
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	limit = min(limit, maxLimit)
+
+	hidden, err := s.hiddenTypes(ctx, userID, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.repo.List(ctx, familyID, filter, before, hidden, userID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activity: %w", err)
+	}
+
+	feed := &Feed{FamilyID: familyID, Entries: []Entry{}, HasMore: len(records) > limit}
+	if feed.HasMore {
+		records = records[:limit]
+	}
+	for i := range records {
+		rec := &records[i]
+		feed.Entries = append(feed.Entries, Entry{
+			ID:         strconv.FormatInt(rec.ID, 10),
+			ChildID:    rec.ChildID,
+			ChildName:  rec.ChildName,
+			ActorID:    rec.ActorID,
+			ActorName:  rec.ActorName,
+			EntityType: rec.EntityType,
+			EntityID:   rec.EntityID,
+			Action:     rec.Action,
+			Summary:    describe(rec),
+			At:         rec.RecordedAt,
+		})
+	}
+	return feed, nil
+}
+
+// hiddenTypes returns the record types hidden from the user in the family.
+// Audit entity types share their names with visibility record types.
+func (s *service) hiddenTypes(ctx context.Context, userID, familyID string) ([]string, error) {
+	if s.visibility == nil {
+		return nil, nil
+	}
+	v, err := s.visibility.GetMemberVisibility(ctx, userID, familyID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check visibility: %w", err)
+	}
+	hidden := make([]string, 0, len(v.HiddenTypes))
+	for _, t := range v.HiddenTypes {
+		hidden = append(hidden, string(t))
+	}
+	return hidden, nil
+}
@@ -0,0 +1,217 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type mockRepository struct {
+	records    []Record // newest first
+	lastHidden []string
+	lastViewer string
+	lastLimit  int
+}
+
+func (m *mockRepository) List(ctx context.Context, familyID string, filter *Filter, before int64, hidden []string, viewerID string, limit int) ([]Record, error) {
+	m.lastHidden = hidden
+	m.lastViewer = viewerID
+	m.lastLimit = limit
+	out := []Record{}
+	for _, r := range m.records {
+		if before > 0 && r.ID >= before {
+			continue
+		}
+		if filter.ChildID != "" && r.ChildID != filter.ChildID {
+			continue
+		}
+		if filter.ActorID != "" && r.ActorID != filter.ActorID {
+			continue
+		}
+		if slices.Contains(hidden, string(r.EntityType)) {
+			continue
+		}
+		out = append(out, r)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	roles map[string]string
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if role, ok := m.roles[familyID+"/"+userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockVisibilityService struct {
+	visibility.Service
+	hidden []visibility.RecordType
+}
+
+func (m *mockVisibilityService) GetMemberVisibility(ctx context.Context, requesterID, familyID, userID string) (*visibility.MemberVisibility, error) {
+	return &visibility.MemberVisibility{FamilyID: familyID, UserID: userID, HiddenTypes: m.hidden}, nil
+}
+
+func record(id int64, entityType audit.EntityType, action audit.Action, actorID, actorName, data string) Record {
+	return Record{
+		Version: audit.Version{
+			ID: id, EntityType: entityType, EntityID: string(entityType) + "-1", ChildID: "child-1",
+			ActorID: actorID, Action: action, Data: json.RawMessage(data), RecordedAt: time.Now(),
+		},
+		ChildName: "Emma",
+		ActorName: actorName,
+	}
+}
+
+func newTestService(records ...Record) (Service, *mockRepository) {
+	repo := &mockRepository{records: records}
+	families := &mockFamilyService{roles: map[string]string{"family-1/user-1": "member"}}
+	return NewService(repo, families), repo
+}
+
+func TestDescribe(t *testing.T) {
+	start := time.Date(2025, 3, 1, 13, 0, 0, 0, time.UTC)
+	end := start.Add(40 * time.Minute)
+	stamp := func(v any) string {
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+
+	tests := []struct {
+		name string
+		rec  Record
+		want string
+	}{
+		{"ended nap", record(1, audit.EntitySleep, audit.ActionCreate, "user-1", "Sam",
+			stamp(map[string]any{"type": "nap", "start_time": start, "end_time": end})),
+			"Sam logged a 40m nap for Emma"},
+		{"running night sleep", record(1, audit.EntitySleep, audit.ActionCreate, "user-1", "Sam",
+			stamp(map[string]any{"type": "night", "start_time": start})),
+			"Sam started a night's sleep for Emma"},
+		{"bottle", record(1, audit.EntityFeeding, audit.ActionCreate, "user-1", "Sam",
+			`{"type":"bottle","amount":120,"unit":"ml"}`),
+			"Sam logged a 120 ml bottle for Emma"},
+		{"edited breastfeed", record(1, audit.EntityFeeding, audit.ActionUpdate, "user-2", "Alex",
+			stamp(map[string]any{"type": "breast", "start_time": start, "end_time": start.Add(15 * time.Minute)})),
+			"Alex updated a 15m breastfeed for Emma"},
+		{"deleted solids", record(1, audit.EntityFeeding, audit.ActionDelete, "user-1", "Sam",
+			`{"type":"solid"}`),
+			"Sam deleted a solids meal for Emma"},
+		{"scheduled vaccination", record(1, audit.EntityVaccination, audit.ActionCreate, "", "",
+			`{"name":"MMR","dose":1,"status":"scheduled"}`),
+			"Someone scheduled the MMR vaccination (dose 1) for Emma"},
+		{"undecodable data", record(1, audit.EntityFeeding, audit.ActionCreate, "user-1", "Sam",
+			`[]`),
+			"Sam logged a feeding for Emma"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describe(&tt.rec); got != tt.want {
+				t.Errorf("describe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_List(t *testing.T) {
+	svc, _ := newTestService(
+		record(3, audit.EntitySleep, audit.ActionCreate, "user-1", "Sam", `{"type":"nap"}`),
+		record(2, audit.EntityFeeding, audit.ActionCreate, "user-2", "Alex", `{"type":"solid"}`),
+		record(1, audit.EntityFeeding, audit.ActionCreate, "user-1", "Sam", `{"type":"solid"}`),
+	)
+	ctx := context.Background()
+
+	feed, err := svc.List(ctx, "user-1", "family-1", &Filter{Limit: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(feed.Entries) != 2 || !feed.HasMore || feed.Entries[0].ID != "3" || feed.Entries[1].ID != "2" {
+		t.Fatalf("List() = %+v, want entries 3 and 2 with more", feed)
+	}
+	if feed.Entries[0].Summary != "Sam started a nap for Emma" {
+		t.Errorf("List() summary = %q", feed.Entries[0].Summary)
+	}
+
+	feed, err = svc.List(ctx, "user-1", "family-1", &Filter{Before: "2", Limit: 2})
+	if err != nil || len(feed.Entries) != 1 || feed.HasMore || feed.Entries[0].ID != "1" {
+		t.Errorf("List(before 2) = %+v, %v; want only entry 1", feed, err)
+	}
+
+	feed, err = svc.List(ctx, "user-1", "family-1", &Filter{ActorID: "user-2"})
+	if err != nil || len(feed.Entries) != 1 || feed.Entries[0].ActorName != "Alex" {
+		t.Errorf("List(actor user-2) = %+v, %v", feed, err)
+	}
+}
+
+func TestService_List_Limits(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	if _, err := svc.List(ctx, "user-1", "family-1", &Filter{}); err != nil || repo.lastLimit != defaultLimit+1 {
+		t.Errorf("List() asked for %d, want %d", repo.lastLimit, defaultLimit+1)
+	}
+	if _, err := svc.List(ctx, "user-1", "family-1", &Filter{Limit: 5000}); err != nil || repo.lastLimit != maxLimit+1 {
+		t.Errorf("List() asked for %d, want %d", repo.lastLimit, maxLimit+1)
+	}
+}
+
+func TestService_List_Errors(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+
+	if _, err := svc.List(ctx, "stranger", "family-1", &Filter{}); !errors.Is(err, ErrNotMember) {
+		t.Errorf("List() by non-member error = %v, want ErrNotMember", err)
+	}
+	for _, before := range []string{"abc", "0", "-4"} {
+		if _, err := svc.List(ctx, "user-1", "family-1", &Filter{Before: before}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("List(before %q) error = %v, want ErrInvalidCursor", before, err)
+		}
+	}
+}
+
+func TestService_List_HidesRecordTypes(t *testing.T) {
+	repo := &mockRepository{records: []Record{
+		record(2, audit.EntitySleep, audit.ActionCreate, "user-2", "Alex", `{"type":"nap"}`),
+		record(1, audit.EntityVaccination, audit.ActionCreate, "user-2", "Alex", `{"name":"MMR"}`),
+	}}
+	families := &mockFamilyService{roles: map[string]string{"family-1/user-1": "member"}}
+	svc := NewService(repo, families, WithVisibility(&mockVisibilityService{hidden: []visibility.RecordType{visibility.RecordVaccination}}))
+
+	feed, err := svc.List(context.Background(), "user-1", "family-1", &Filter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(feed.Entries) != 1 || feed.Entries[0].EntityType != audit.EntitySleep {
+		t.Errorf("List() = %+v, want only the sleep", feed.Entries)
+	}
+	if !slices.Equal(repo.lastHidden, []string{"vaccination"}) {
+		t.Errorf("hidden = %v, want [vaccination]", repo.lastHidden)
+	}
+}
+
+func TestService_List_HidesPrivateRecords(t *testing.T) {
+	svc, repo := newTestService()
+
+	if _, err := svc.List(context.Background(), "user-1", "family-1", &Filter{}); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if repo.lastViewer != "user-1" {
+		t.Errorf("viewer = %q, want user-1 so other members' private records are left out", repo.lastViewer)
+	}
+}
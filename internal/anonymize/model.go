@@ -0,0 +1,21 @@
+package anonymize
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/transfer"
+)
+
+// FixtureVersion is bumped whenever the fixture layout changes incompatibly
+const FixtureVersion = 1
+
+// Fixture is a scrubbed copy of one family for reproducing bugs. Each child is
+// a transfer bundle, so it can be imported into a test family as it is.
+type Fixture struct {
+	Version   int                     `json:"version"`
+	CreatedAt time.Time               `json:"created_at"`
+	Family    family.Family           `json:"family"`
+	Members   []family.MemberWithUser `json:"members"`
+	Children  []transfer.Bundle       `json:"children"`
+}
@@ -0,0 +1,125 @@
+package anonymize
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/transfer"
+)
+
+// filler replaces free text. Scrubbed text keeps the original's length, so
+// bugs around long or empty text still reproduce.
+const filler = "Lorem ipsum dolor sit amet, consectetur adipiscing elit. "
+
+// scrubText replaces s with filler of the same number of characters
+func scrubText(s string) string {
+	n := len([]rune(s))
+	if n == 0 {
+		return ""
+	}
+	text := strings.Repeat(filler, n/len(filler)+1)
+	return text[:n]
+}
+
+// scrubber swaps people for numbered stand-ins, keeping the same stand-in for
+// a user wherever they appear
+type scrubber struct {
+	users map[string]string
+}
+
+func newScrubber() *scrubber {
+	return &scrubber{users: make(map[string]string)}
+}
+
+// user returns the stand-in ID for a real user ID
+func (s *scrubber) user(id string) string {
+	if id == "" {
+		return ""
+	}
+	if alias, ok := s.users[id]; ok {
+		return alias
+	}
+	alias := fmt.Sprintf("user-%d", len(s.users)+1)
+	s.users[id] = alias
+	return alias
+}
+
+func (s *scrubber) family(f *family.Family) {
+	f.Name = "Test Family"
+}
+
+func (s *scrubber) members(members []family.MemberWithUser) {
+	for i := range members {
+		m := &members[i]
+		m.UserID = s.user(m.UserID)
+		m.Name = fmt.Sprintf("Member %d", i+1)
+		m.Email = fmt.Sprintf("member%d@example.invalid", i+1)
+		m.AvatarURL = ""
+	}
+}
+
+// bundle scrubs names and free text from a child's records. Times, amounts
+// and vaccine names are kept, since schedules and stats depend on them.
+// Uploaded files are not copied, so references to them are dropped.
+func (s *scrubber) bundle(b *transfer.Bundle, n int) {
+	b.ExportedBy = s.user(b.ExportedBy)
+	b.Child.Name = fmt.Sprintf("Child %d", n)
+	b.Child.AvatarURL = ""
+
+	for i := range b.Feedings {
+		b.Feedings[i].Notes = scrubText(b.Feedings[i].Notes)
+		b.Feedings[i].AuthorID = s.user(b.Feedings[i].AuthorID)
+	}
+	for i := range b.Sleep {
+		sl := &b.Sleep[i]
+		sl.Notes = scrubText(sl.Notes)
+		sl.StartedBy = s.user(sl.StartedBy)
+		sl.EndedBy = s.user(sl.EndedBy)
+	}
+	for i := range b.Medications {
+		med := &b.Medications[i]
+		med.Name = fmt.Sprintf("Medication %d", i+1)
+		med.Instructions = scrubText(med.Instructions)
+		for j := range med.Logs {
+			med.Logs[j].GivenBy = s.user(med.Logs[j].GivenBy)
+			med.Logs[j].Notes = scrubText(med.Logs[j].Notes)
+		}
+	}
+	for i := range b.Vaccinations {
+		v := &b.Vaccinations[i]
+		v.Provider = scrubText(v.Provider)
+		v.Location = scrubText(v.Location)
+		v.LotNumber = scrubText(v.LotNumber)
+		v.Notes = scrubText(v.Notes)
+		v.StatusReason = scrubText(v.StatusReason)
+		v.DocumentIDs = []string{}
+	}
+	for i := range b.Appointments {
+		a := &b.Appointments[i]
+		a.Title = fmt.Sprintf("Appointment %d", i+1)
+		a.Provider = scrubText(a.Provider)
+		a.Location = scrubText(a.Location)
+		a.Notes = scrubText(a.Notes)
+		a.Reason = scrubText(a.Reason)
+		a.Outcome = scrubText(a.Outcome)
+		a.VaccinationIDs, a.MeasurementIDs = nil, nil
+	}
+	for i := range b.Notes {
+		note := &b.Notes[i]
+		note.AuthorID = s.user(note.AuthorID)
+		note.Title = scrubText(note.Title)
+		note.Content = scrubText(note.Content)
+		note.MediaIDs = nil
+		for j := range note.SeenBy {
+			note.SeenBy[j].UserID = s.user(note.SeenBy[j].UserID)
+			note.SeenBy[j].Name = ""
+		}
+	}
+	for i := range b.Journal {
+		e := &b.Journal[i]
+		e.CreatedBy = s.user(e.CreatedBy)
+		e.Caption = scrubText(e.Caption)
+		e.MediaID = ""
+	}
+}
@@ -0,0 +1,86 @@
+// Package anonymize copies a family's data into a scrubbed fixture, so support
+// can reproduce a bug without handling real names or medical details.
+package anonymize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/transfer"
+)
+
+var (
+	ErrFamilyNotFound = errors.New("family not found")
+	ErrNoMembers      = errors.New("family has no members")
+)
+
+type Service interface {
+	// Family builds a scrubbed fixture of the family, its members and every
+	// child's records
+	Family(ctx context.Context, familyID string) (*Fixture, error)
+}
+
+type service struct {
+	familyService   family.Service
+	transferService transfer.Service
+}
+
+func NewService(familyService family.Service, transferService transfer.Service) Service {
+	return &service{
+		familyService:   familyService,
+		transferService: transferService,
+	}
+}
+
+func (s *service) Family(ctx context.Context, familyID string) (*Fixture, error) {
+	f, err := s.familyService.GetFamily(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, ErrFamilyNotFound
+	}
+
+	members, err := s.familyService.GetFamilyMembers(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %w", err)
+	}
+	if len(members) == 0 {
+		return nil, ErrNoMembers
+	}
+
+	children, err := s.familyService.GetChildren(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get children: %w", err)
+	}
+
+	fixture := &Fixture{
+		Version:   FixtureVersion,
+		CreatedAt: time.Now(),
+		Family:    *f,
+		Members:   members,
+		Children:  make([]transfer.Bundle, 0, len(children)),
+	}
+
+	// Exports are checked against a member, so export as the first one
+	exporter := members[0].UserID
+	for _, child := range children {
+		b, err := s.transferService.Export(ctx, exporter, child.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export child %s: %w", child.ID, err)
+		}
+		fixture.Children = append(fixture.Children, *b)
+	}
+
+	sc := newScrubber()
+	sc.family(&fixture.Family)
+	sc.members(fixture.Members)
+	for i := range fixture.Children {
+		sc.bundle(&fixture.Children[i], i+1)
+	}
+
+	return fixture, nil
+}
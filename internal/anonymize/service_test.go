@@ -0,0 +1,143 @@
+package anonymize
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/journal"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/transfer"
+	"github.com/ninenine/babytrack/internal/vaccination"
+)
+
+// mockFamilyService is a test double for family.Service; unused methods panic via the nil embed
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetFamily(ctx context.Context, familyID string) (*family.Family, error) {
+	if familyID == "family-1" {
+		return &family.Family{ID: "family-1", Name: "The Garcias"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetFamilyMembers(ctx context.Context, familyID string) ([]family.MemberWithUser, error) {
+	return []family.MemberWithUser{
+		{UserID: "real-user-a", Name: "Ana Garcia", Email: "ana@example.com", Role: "admin"},
+		{UserID: "real-user-b", Name: "Luis Garcia", Email: "luis@example.com", Role: "member"},
+	}, nil
+}
+
+func (m *mockFamilyService) GetChildren(ctx context.Context, familyID string) ([]family.Child, error) {
+	return []family.Child{{ID: "child-1", FamilyID: familyID, Name: "Sofia"}}, nil
+}
+
+type mockTransferService struct {
+	transfer.Service
+	exportedBy string
+}
+
+func (m *mockTransferService) Export(ctx context.Context, userID, childID string) (*transfer.Bundle, error) {
+	m.exportedBy = userID
+	administered := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	return &transfer.Bundle{
+		Version:    transfer.BundleVersion,
+		ExportedBy: userID,
+		Child:      family.Child{ID: childID, Name: "Sofia", AvatarURL: "https://example.com/sofia.png"},
+		Sleep:      []sleep.Sleep{{ID: "sleep-1", StartedBy: "real-user-b", Notes: "Fussy after the clinic"}},
+		Medications: []transfer.BundleMedication{{
+			Medication: medication.Medication{ID: "med-1", Name: "Amoxicillin", Dosage: "5"},
+			Logs:       []medication.MedicationLog{{ID: "log-1", GivenBy: "real-user-a"}},
+		}},
+		Vaccinations: []vaccination.Vaccination{{
+			ID: "vax-1", Name: "DTaP", Provider: "Dr. Patel", LotNumber: "AB123",
+			AdministeredAt: &administered, DocumentIDs: []string{"media-9"},
+		}},
+		Notes: []notes.Note{{
+			ID: "note-1", AuthorID: "real-user-a", Title: "Rash", Content: "Rash on left arm", Tags: []string{"health"},
+			SeenBy: []notes.NoteSeen{{UserID: "real-user-b", Name: "Luis Garcia"}},
+		}},
+		Journal: []journal.Entry{{ID: "entry-1", CreatedBy: "real-user-a", MediaID: "media-1", Caption: "Sofia's first steps"}},
+	}, nil
+}
+
+func TestService_Family(t *testing.T) {
+	transferSvc := &mockTransferService{}
+	svc := NewService(&mockFamilyService{}, transferSvc)
+
+	fixture, err := svc.Family(context.Background(), "family-1")
+	if err != nil {
+		t.Fatalf("Family() error = %v", err)
+	}
+	if transferSvc.exportedBy != "real-user-a" {
+		t.Errorf("exported as %q, want the first member", transferSvc.exportedBy)
+	}
+
+	data, err := json.Marshal(fixture)
+	if err != nil {
+		t.Fatalf("failed to encode fixture: %v", err)
+	}
+	for _, secret := range []string{
+		"Garcia", "ana@example.com", "real-user", "Sofia", "sofia.png", "Amoxicillin",
+		"Dr. Patel", "AB123", "Rash", "Fussy", "media-1", "media-9",
+	} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("fixture still contains %q", secret)
+		}
+	}
+
+	b := fixture.Children[0]
+	// The same person gets the same stand-in everywhere
+	if fixture.Members[0].UserID != "user-1" || b.Notes[0].AuthorID != "user-1" || b.Medications[0].Logs[0].GivenBy != "user-1" {
+		t.Errorf("first member's records not attributed to user-1: %+v", b.Notes[0])
+	}
+	if fixture.Members[1].UserID != b.Sleep[0].StartedBy || b.Notes[0].SeenBy[0].UserID != b.Sleep[0].StartedBy {
+		t.Errorf("second member's records attributed inconsistently")
+	}
+
+	// What the app's behaviour depends on is kept
+	if b.Vaccinations[0].Name != "DTaP" || b.Vaccinations[0].AdministeredAt == nil || b.Medications[0].Dosage != "5" {
+		t.Errorf("structural data lost: %+v", b.Vaccinations[0])
+	}
+	if len(b.Notes[0].Content) != len("Rash on left arm") || b.Notes[0].Tags[0] != "health" {
+		t.Errorf("note = %+v, want scrubbed text of the same length and tags kept", b.Notes[0])
+	}
+	if b.Version != transfer.BundleVersion {
+		t.Errorf("bundle version = %d, want importable %d", b.Version, transfer.BundleVersion)
+	}
+}
+
+func TestService_Family_NotFound(t *testing.T) {
+	svc := NewService(&mockFamilyService{}, &mockTransferService{})
+
+	if _, err := svc.Family(context.Background(), "family-9"); !errors.Is(err, ErrFamilyNotFound) {
+		t.Errorf("Family() error = %v, want ErrFamilyNotFound", err)
+	}
+}
+
+func TestScrubText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"Fever", "Lorem"},
+		{"Très fatigué", "Lorem ipsum "},
+	}
+	for _, tt := range tests {
+		if got := scrubText(tt.in); got != tt.want {
+			t.Errorf("scrubText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if got := scrubText(strings.Repeat("x", 200)); len(got) != 200 {
+		t.Errorf("scrubText() of 200 characters returned %d", len(got))
+	}
+}
@@ -0,0 +1,170 @@
+// Package apijson encodes API responses. Every time.Time in a response is
+// written in UTC as RFC 3339, whatever zone the database driver or the server
+// clock left it in, so clients never see mixed offsets.
+package apijson
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	ginjson "github.com/gin-gonic/gin/codec/json"
+)
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+
+	// holdsTime caches whether values of a type can contain a time.Time, so
+	// slices of strings or numbers are passed through without copying
+	holdsTime sync.Map
+)
+
+// Codec is the JSON codec for gin. Set ginjson.API to it at startup.
+type Codec struct{}
+
+func (Codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(UTC(v))
+}
+
+func (Codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (Codec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(UTC(v), prefix, indent)
+}
+
+func (Codec) NewEncoder(w io.Writer) ginjson.Encoder {
+	return encoder{json.NewEncoder(w)}
+}
+
+func (Codec) NewDecoder(r io.Reader) ginjson.Decoder {
+	return json.NewDecoder(r)
+}
+
+type encoder struct {
+	*json.Encoder
+}
+
+func (e encoder) Encode(v any) error {
+	return e.Encoder.Encode(UTC(v))
+}
+
+// Marshal encodes v the way API responses are encoded, for JSON sent to
+// clients outside gin such as WebSocket events
+func Marshal(v any) ([]byte, error) {
+	return json.Marshal(UTC(v))
+}
+
+// UTC returns a copy of v with every exported time.Time in it, however deeply
+// nested, moved to UTC. v itself is left untouched, since responses are often
+// built from cached or shared records. Types with their own MarshalJSON are
+// left to encode themselves.
+func UTC(v any) any {
+	if v == nil {
+		return nil
+	}
+	return utc(reflect.ValueOf(v)).Interface()
+}
+
+func utc(v reflect.Value) reflect.Value {
+	t := v.Type()
+	if !mayHoldTime(t) {
+		return v
+	}
+	if t == timeType {
+		return reflect.ValueOf(v.Interface().(time.Time).UTC())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(utc(v.Elem()))
+		return p
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t).Elem()
+		out.Set(utc(v.Elem()))
+		return out
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		out.Set(v)
+		for i := range t.NumField() {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			field := out.Field(i)
+			field.Set(utc(field))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(utc(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := range v.Len() {
+			out.Index(i).Set(utc(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), utc(iter.Value()))
+		}
+		return out
+	}
+	return v
+}
+
+func mayHoldTime(t reflect.Type) bool {
+	if known, ok := holdsTime.Load(t); ok {
+		return known.(bool)
+	}
+	// Assume so while a recursive type is being worked out; copying a value
+	// that turns out not to need it is harmless
+	holdsTime.Store(t, true)
+
+	holds := false
+	switch {
+	case t == timeType:
+		holds = true
+	case t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface &&
+		(t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType)):
+		holds = false
+	default:
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			holds = mayHoldTime(t.Elem())
+		case reflect.Interface:
+			holds = true
+		case reflect.Struct:
+			for i := range t.NumField() {
+				if f := t.Field(i); f.IsExported() && mayHoldTime(f.Type) {
+					holds = true
+					break
+				}
+			}
+		}
+	}
+
+	holdsTime.Store(t, holds)
+	return holds
+}
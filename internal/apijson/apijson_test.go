@@ -0,0 +1,132 @@
+package apijson_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apijson"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notifications"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+
+	"github.com/gin-gonic/gin"
+	ginjson "github.com/gin-gonic/gin/codec/json"
+)
+
+// timestamp matches any JSON string that starts like a date-time
+var timestamp = regexp.MustCompile(`"(\d{4}-\d{2}-\d{2}T[^"]*)"`)
+
+// Times as lib/pq hands them back from a session in another zone, and as
+// time.Now gives them on a server with a local zone
+var (
+	nairobi = time.FixedZone("", 3*60*60)
+	pacific = time.FixedZone("PDT", -7*60*60)
+	start   = time.Date(2024, 6, 1, 21, 30, 0, 0, nairobi)
+	end     = time.Date(2024, 6, 1, 12, 15, 30, 500, pacific)
+)
+
+func assertUTC(t *testing.T, data []byte) {
+	t.Helper()
+	matches := timestamp.FindAllStringSubmatch(string(data), -1)
+	if len(matches) == 0 {
+		t.Fatalf("no timestamps in %s", data)
+	}
+	for _, m := range matches {
+		parsed, err := time.Parse(time.RFC3339Nano, m[1])
+		if err != nil {
+			t.Errorf("%q is not RFC 3339: %v", m[1], err)
+			continue
+		}
+		if !strings.HasSuffix(m[1], "Z") {
+			t.Errorf("%q is not in UTC", m[1])
+		}
+		if !parsed.Equal(start) && !parsed.Equal(end) {
+			t.Errorf("%q changed the instant", m[1])
+		}
+	}
+}
+
+func TestMarshal_Entities(t *testing.T) {
+	amount := 120.0
+	weight := 5.4
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"feeding", &feeding.Feeding{ID: "f1", StartTime: start, EndTime: &end, Amount: &amount, CreatedAt: start, UpdatedAt: end}},
+		{"sleep list", []sleep.Sleep{{ID: "s1", StartTime: start, EndTime: &end, CreatedAt: start, UpdatedAt: end}}},
+		{"medication log", medication.MedicationLog{ID: "l1", GivenAt: start, CreatedAt: end, SyncedAt: &end}},
+		{"growth", &growth.Measurement{ID: "g1", MeasuredAt: start, WeightKg: &weight, CreatedAt: start, UpdatedAt: end}},
+		{"vaccination", vaccination.Vaccination{ID: "v1", ScheduledAt: start, AdministeredAt: &end, CreatedAt: start, UpdatedAt: end}},
+		{"members", map[string]any{"members": []family.MemberWithUser{{ID: "m1", CreatedAt: start}}}},
+		{"event with data", notifications.Event{ID: "e1", Timestamp: start, Data: map[string]any{"due": &end}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := apijson.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			assertUTC(t, data)
+		})
+	}
+}
+
+func TestUTC_LeavesInputAlone(t *testing.T) {
+	f := &feeding.Feeding{ID: "f1", StartTime: start, EndTime: &end}
+
+	out := apijson.UTC(f).(*feeding.Feeding)
+
+	if out == f || out.EndTime == f.EndTime {
+		t.Error("UTC() should copy rather than share pointers")
+	}
+	if f.StartTime.Location() != nairobi || f.EndTime.Location() != pacific {
+		t.Error("UTC() should not modify its input")
+	}
+	if out.StartTime.Location() != time.UTC || !out.StartTime.Equal(start) {
+		t.Errorf("UTC() start = %v, want %v in UTC", out.StartTime, start)
+	}
+}
+
+func TestUTC_KeepsOtherValues(t *testing.T) {
+	raw := []byte(`{"a":1}`)
+	if got := apijson.UTC(raw).([]byte); !bytes.Equal(got, raw) {
+		t.Errorf("UTC() = %s, want %s", got, raw)
+	}
+	if got := apijson.UTC(nil); got != nil {
+		t.Errorf("UTC(nil) = %v, want nil", got)
+	}
+	if got := apijson.UTC(gin.H{"count": 3}).(gin.H); got["count"] != 3 {
+		t.Errorf("UTC() = %v", got)
+	}
+}
+
+func TestCodec_GinResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := ginjson.API
+	ginjson.API = apijson.Codec{}
+	t.Cleanup(func() { ginjson.API = previous })
+
+	router := gin.New()
+	router.GET("/feeding", func(c *gin.Context) {
+		c.JSON(http.StatusOK, feeding.Feeding{ID: "f1", StartTime: start, EndTime: &end, CreatedAt: start, UpdatedAt: end})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/feeding", http.NoBody))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	assertUTC(t, w.Body.Bytes())
+}
@@ -0,0 +1,75 @@
+package apijson
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// compactKeys lists the fields compact responses keep, with the short key
+// each is sent under. Wearables only show what is happening now and what
+// happened last, so everything else is left out. Add a field here when a
+// compact client needs it; short keys must stay unique.
+var compactKeys = map[string]string{
+	"id":         "i",
+	"child_id":   "c",
+	"name":       "n",
+	"type":       "t",
+	"kind":       "k",
+	"start_time": "s",
+	"end_time":   "e",
+	"started_by": "b",
+	"active":     "on",
+
+	// Active timers
+	"activities":      "a",
+	"paused":          "p",
+	"elapsed_seconds": "el",
+	"as_of":           "at",
+	"side":            "sd",
+	"side_started_at": "ss",
+	"left_seconds":    "l",
+	"right_seconds":   "r",
+
+	// Feeds, doses and sleep
+	"amount":   "am",
+	"unit":     "u",
+	"dosage":   "ds",
+	"given_at": "g",
+	"wakings":  "w",
+	"items":    "it",
+}
+
+// Compact rewrites an encoded response for wearables: fields not in the
+// compact set and null or empty string values are dropped, and the rest are
+// renamed to their short keys. Arrays and nested objects are compacted the
+// same way.
+func Compact(body []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(compact(v))
+}
+
+func compact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			short, ok := compactKeys[key]
+			if !ok || value == nil || value == "" {
+				continue
+			}
+			out[short] = compact(value)
+		}
+		return out
+	case []any:
+		for i := range v {
+			v[i] = compact(v[i])
+		}
+		return v
+	}
+	return v
+}
@@ -0,0 +1,42 @@
+package apijson
+
+import (
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	body := []byte(`{"child_id":"c1","activities":[{"kind":"nursing","id":"f1","paused":false,"elapsed_seconds":420,"detail":{"left_seconds":300}}],"as_of":"2024-03-01T12:00:00Z","notes":"","extra":null}`)
+
+	got, err := Compact(body)
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	want := `{"a":[{"el":420,"i":"f1","k":"nursing","p":false}],"at":"2024-03-01T12:00:00Z","c":"c1"}`
+	if string(got) != want {
+		t.Errorf("Compact() = %s, want %s", got, want)
+	}
+}
+
+func TestCompact_List(t *testing.T) {
+	got, err := Compact([]byte(`[{"id":"1","amount":120.5,"unit":"ml","created_at":"2024-03-01T12:00:00Z"}]`))
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if want := `[{"am":120.5,"i":"1","u":"ml"}]`; string(got) != want {
+		t.Errorf("Compact() = %s, want %s", got, want)
+	}
+
+	if _, err := Compact([]byte("not json")); err == nil {
+		t.Error("expected error for a body that is not JSON")
+	}
+}
+
+func TestCompactKeys_Unique(t *testing.T) {
+	seen := map[string]string{}
+	for key, short := range compactKeys {
+		if other, ok := seen[short]; ok {
+			t.Errorf("%s and %s both compact to %q", key, other, short)
+		}
+		seen[short] = key
+	}
+}
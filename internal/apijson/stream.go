@@ -0,0 +1,98 @@
+package apijson
+
+import (
+	"encoding/json"
+	"io"
+	"iter"
+)
+
+// ObjectWriter writes a JSON object to w one field at a time, so arrays too
+// large to hold in memory can be streamed into a response. Values are
+// encoded like Marshal. After the first error nothing more is written, and
+// Close returns it.
+type ObjectWriter struct {
+	w      io.Writer
+	fields int
+	err    error
+}
+
+func NewObjectWriter(w io.Writer) *ObjectWriter {
+	return &ObjectWriter{w: w}
+}
+
+// Field writes one field holding v
+func (o *ObjectWriter) Field(name string, v any) {
+	data, err := Marshal(v)
+	if err != nil {
+		o.fail(err)
+		return
+	}
+	o.key(name)
+	o.write(data)
+}
+
+// StreamField writes a field holding an array of the values in seq,
+// encoding each as it is yielded. An error from seq ends the array there
+// and is kept as the writer's error. Once the writer has failed, seq is not
+// read at all.
+func StreamField[T any](o *ObjectWriter, name string, seq iter.Seq2[T, error]) {
+	if o.err != nil {
+		return
+	}
+	o.key(name)
+	o.write([]byte{'['})
+	n := 0
+	for v, err := range seq {
+		if err != nil {
+			o.fail(err)
+		}
+		if o.err != nil {
+			return
+		}
+		data, err := Marshal(v)
+		if err != nil {
+			o.fail(err)
+			return
+		}
+		if n > 0 {
+			o.write([]byte{','})
+		}
+		o.write(data)
+		n++
+	}
+	o.write([]byte{']'})
+}
+
+// Close ends the object. An object with nothing written is sent as {}.
+func (o *ObjectWriter) Close() error {
+	if o.fields == 0 {
+		o.write([]byte{'{'})
+	}
+	o.write([]byte{'}'})
+	return o.err
+}
+
+func (o *ObjectWriter) key(name string) {
+	sep := byte(',')
+	if o.fields == 0 {
+		sep = '{'
+	}
+	o.fields++
+	key, _ := json.Marshal(name) //nolint:errcheck // Strings always encode
+	o.write(append(append([]byte{sep}, key...), ':'))
+}
+
+func (o *ObjectWriter) write(data []byte) {
+	if o.err != nil {
+		return
+	}
+	if _, err := o.w.Write(data); err != nil {
+		o.err = err
+	}
+}
+
+func (o *ObjectWriter) fail(err error) {
+	if o.err == nil {
+		o.err = err
+	}
+}
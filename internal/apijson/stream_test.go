@@ -0,0 +1,75 @@
+package apijson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"iter"
+	"testing"
+	"time"
+)
+
+func values[T any](vs ...T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, v := range vs {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestObjectWriter(t *testing.T) {
+	type point struct {
+		At time.Time `json:"at"`
+	}
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.FixedZone("EST", -5*3600))
+
+	var buf bytes.Buffer
+	o := NewObjectWriter(&buf)
+	o.Field("version", 1)
+	StreamField(o, "points", values(point{At: at}, point{At: at.Add(time.Hour)}))
+	StreamField(o, "empty", values[point]())
+	o.Field("note", "done")
+	if err := o.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := `{"version":1,"points":[{"at":"2024-03-01T14:00:00Z"},{"at":"2024-03-01T15:00:00Z"}],"empty":[],"note":"done"}`
+	if buf.String() != want {
+		t.Errorf("wrote %s, want %s", buf.String(), want)
+	}
+	if !json.Valid(buf.Bytes()) {
+		t.Error("output is not valid JSON")
+	}
+}
+
+func TestObjectWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewObjectWriter(&buf).Close(); err != nil || buf.String() != "{}" {
+		t.Errorf("Close() = %q, %v; want {}", buf.String(), err)
+	}
+}
+
+func TestObjectWriter_StreamError(t *testing.T) {
+	failed := errors.New("connection reset")
+	seq := func(yield func(int, error) bool) {
+		if yield(1, nil) {
+			yield(0, failed)
+		}
+	}
+
+	var buf bytes.Buffer
+	o := NewObjectWriter(&buf)
+	StreamField(o, "n", seq)
+	o.Field("after", true)
+	StreamField(o, "unread", func(yield func(int, error) bool) {
+		t.Error("a stream after the error should not be read")
+	})
+	if err := o.Close(); !errors.Is(err, failed) {
+		t.Errorf("Close() error = %v, want %v", err, failed)
+	}
+	if buf.String() != `{"n":[1` {
+		t.Errorf("wrote %q after the error", buf.String())
+	}
+}
@@ -0,0 +1,327 @@
+// Package apispec describes the HTTP API as an OpenAPI 3.1 document. Routes
+// are listed by hand with the Go types their handlers encode, and the schemas
+// are generated from those types. The contract tests replay the responses
+// recorded in handler tests against the document and fail on routes, status
+// codes or fields it does not describe, so the two cannot drift apart.
+package apispec
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ninenine/babytrack/internal/jsonschema"
+	"github.com/ninenine/babytrack/internal/maintenance"
+)
+
+// route documents one endpoint
+type route struct {
+	Method string
+	// Path is the route as registered with gin, e.g. /api/feeding/:id
+	Path    string
+	Summary string
+	// Description adds detail the summary line has no room for
+	Description string
+	// Query lists the query parameters the handler reads
+	Query []string
+	// Request is a value of the type the JSON body is decoded into
+	Request any
+	// RequestContentType is set for bodies that are not JSON
+	RequestContentType string
+	Responses          []response
+	// Errors lists the statuses answered with an Error body
+	Errors []int
+}
+
+// response documents one status a route can answer with
+type response struct {
+	Status int
+	// Bodies are values of the types encoded as JSON. With several the body
+	// is any one of them, and a nil entry allows null.
+	Bodies []any
+	// ContentType is set for bodies that are not JSON
+	ContentType string
+}
+
+func ok(bodies ...any) response {
+	return response{Status: http.StatusOK, Bodies: bodies}
+}
+
+func created(bodies ...any) response {
+	return response{Status: http.StatusCreated, Bodies: bodies}
+}
+
+func noContent() response {
+	return response{Status: http.StatusNoContent}
+}
+
+// respond documents any other status, without a body if none is given
+func respond(status int, bodies ...any) response {
+	return response{Status: status, Bodies: bodies}
+}
+
+func file(status int, contentType string) response {
+	return response{Status: status, ContentType: contentType}
+}
+
+// redirect documents a 307; gin sends a short HTML link with it
+func redirect() response {
+	return response{Status: http.StatusTemporaryRedirect, ContentType: "text/html"}
+}
+
+// group holds the routes served by one package, which become a tag
+type group struct {
+	tag    string
+	routes []route
+}
+
+var groups = []group{
+	{"app", appRoutes},
+	{"health", healthRoutes},
+	{"apispec", apispecRoutes},
+	{"status", statusRoutes},
+	{"eventschema", eventschemaRoutes},
+	{"deprecation", deprecationRoutes},
+	{"maintenance", maintenanceRoutes},
+	{"profiling", profilingRoutes},
+	{"synccapture", synccaptureRoutes},
+	{"auth", authRoutes},
+	{"authz", authzRoutes},
+	{"devices", devicesRoutes},
+	{"quicklog", quicklogRoutes},
+	{"sync", syncRoutes},
+	{"notifications", notificationsRoutes},
+	{"preferences", preferencesRoutes},
+
+	{"family", familyRoutes},
+	{"sandbox", sandboxRoutes},
+	{"inbound", inboundRoutes},
+	{"closures", closuresRoutes},
+	{"activity", activityRoutes},
+	{"kiosk", kioskRoutes},
+	{"visibility", visibilityRoutes},
+	{"healthinfo", healthinfoRoutes},
+	{"integrations", integrationsRoutes},
+	{"daycare", daycareRoutes},
+	{"dashboard", dashboardRoutes},
+	{"stats", statsRoutes},
+	{"export", exportRoutes},
+
+	{"memories", memoriesRoutes},
+	{"grants", grantsRoutes},
+	{"messaging", messagingRoutes},
+	{"careplan", careplanRoutes},
+	{"documents", documentsRoutes},
+	{"solids", solidsRoutes},
+	{"playtime", playtimeRoutes},
+	{"quality", qualityRoutes},
+	{"timers", timersRoutes},
+	{"logsheet", logsheetRoutes},
+	{"transfer", transferRoutes},
+	{"journal", journalRoutes},
+	{"attachments", attachmentsRoutes},
+	{"media", mediaRoutes},
+	{"favorites", favoritesRoutes},
+	{"templates", templatesRoutes},
+	{"presence", presenceRoutes},
+
+	{"feeding", feedingRoutes},
+	{"pumping", pumpingRoutes},
+	{"sleep", sleepRoutes},
+	{"medication", medicationRoutes},
+	{"vaccination", vaccinationRoutes},
+	{"appointment", appointmentRoutes},
+	{"notes", notesRoutes},
+	{"growth", growthRoutes},
+	{"temperature", temperatureRoutes},
+}
+
+// Document is an OpenAPI 3.1 document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Tags       []Tag                `json:"tags"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem holds a path's operations by lower-case method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string             `json:"name"`
+	In       string             `json:"in"`
+	Required bool               `json:"required,omitempty"`
+	Schema   *jsonschema.Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *jsonschema.Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*jsonschema.Schema `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme     `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+const description = `The babytrack server's HTTP API. Protected routes take the access token from
+sign-in as a bearer token; operator routes take the admin token instead.
+
+Timestamps are RFC 3339 in UTC. Clients that send X-Envelope: true get every
+JSON response wrapped as {"data", "meta"}, and ?compact=true trims responses
+to short keys for wearables; neither is described here.`
+
+// jsonType is the content type of JSON bodies
+const jsonType = "application/json"
+
+var pathParam = regexp.MustCompile(`[:*](\w+)`)
+
+// openAPIPath turns a gin route into an OpenAPI path, e.g. /api/feeding/:id
+// into /api/feeding/{id}
+func openAPIPath(path string) string {
+	return pathParam.ReplaceAllString(path, "{$1}")
+}
+
+// Build generates the document for the given server version
+func Build(version string) *Document {
+	g := jsonschema.NewGenerator("#/components/schemas/", jsonschema.WithNullable())
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info:    Info{Title: "babytrack", Version: version, Description: description},
+		Paths:   map[string]*PathItem{},
+		Components: Components{SecuritySchemes: map[string]SecurityScheme{
+			securityBearer: {Type: "http", Scheme: "bearer"},
+			securityAdmin:  {Type: "apiKey", In: "header", Name: maintenance.HeaderAdminToken},
+		}},
+	}
+	for _, grp := range groups {
+		doc.Tags = append(doc.Tags, Tag{Name: grp.tag})
+		for _, r := range grp.routes {
+			path := openAPIPath(r.Path)
+			item, ok := doc.Paths[path]
+			if !ok {
+				item = &PathItem{}
+				doc.Paths[path] = item
+			}
+			(*item)[strings.ToLower(r.Method)] = operation(g, grp.tag, r)
+		}
+	}
+	doc.Components.Schemas = g.Defs()
+	return doc
+}
+
+func operation(g *jsonschema.Generator, tag string, r route) *Operation {
+	op := &Operation{Tags: []string{tag}, Summary: r.Summary, Description: r.Description, Responses: map[string]*Response{}}
+
+	for _, m := range pathParam.FindAllStringSubmatch(r.Path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name: m[1], In: "path", Required: true, Schema: &jsonschema.Schema{Type: "string"},
+		})
+	}
+	for _, name := range r.Query {
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "query", Schema: &jsonschema.Schema{Type: "string"}})
+	}
+	switch {
+	case r.Request != nil:
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			jsonType: {Schema: g.For(reflect.TypeOf(r.Request))},
+		}}
+	case r.RequestContentType != "":
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{r.RequestContentType: {}}}
+	}
+
+	responses := slices.Clone(r.Responses)
+	for _, status := range r.Errors {
+		responses = append(responses, respond(status, Error{}))
+	}
+	for _, rule := range shared {
+		if rule.applies(r.Method, r.Path) {
+			responses = append(responses, rule.responses...)
+			if rule.security != "" {
+				op.Security = []map[string][]string{{rule.security: {}}}
+			}
+		}
+	}
+
+	// Responses with the same status are merged; the body is any of theirs
+	bodies := map[int][]reflect.Type{}
+	for _, resp := range responses {
+		key := strconv.Itoa(resp.Status)
+		doc, ok := op.Responses[key]
+		if !ok {
+			doc = &Response{Description: http.StatusText(resp.Status)}
+			op.Responses[key] = doc
+		}
+		if resp.ContentType != "" {
+			if doc.Content == nil {
+				doc.Content = map[string]MediaType{}
+			}
+			doc.Content[resp.ContentType] = MediaType{}
+		}
+		for _, body := range resp.Bodies {
+			if t := reflect.TypeOf(body); !slices.Contains(bodies[resp.Status], t) {
+				bodies[resp.Status] = append(bodies[resp.Status], t)
+			}
+		}
+	}
+	for status, types := range bodies {
+		schemas := make([]*jsonschema.Schema, len(types))
+		for i, t := range types {
+			if t == nil {
+				schemas[i] = jsonschema.Null
+			} else {
+				schemas[i] = g.For(t)
+			}
+		}
+		schema := schemas[0]
+		if len(schemas) > 1 {
+			schema = &jsonschema.Schema{AnyOf: schemas}
+		}
+		doc := op.Responses[strconv.Itoa(status)]
+		if doc.Content == nil {
+			doc.Content = map[string]MediaType{}
+		}
+		doc.Content[jsonType] = MediaType{Schema: schema}
+	}
+	return op
+}
@@ -0,0 +1,283 @@
+// Package apispectest records the responses handlers write in their tests,
+// so the OpenAPI contract tests can replay them against the spec. Handler
+// test routers add Record; it does nothing unless APISPEC_RECORD is set:
+//
+//	APISPEC_RECORD=1 go test ./internal/...
+//
+// It only imports gin, so any handler package's tests can use it.
+package apispectest
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnvRecord turns recording on when set
+const EnvRecord = "APISPEC_RECORD"
+
+// perStatus caps the differently shaped bodies kept for each route and
+// status
+const perStatus = 3
+
+// Recording is one response a handler wrote in a test
+type Recording struct {
+	Method string `json:"method"`
+	// Route is the route as registered on the server, e.g. /api/feeding/:id
+	Route       string          `json:"route"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+}
+
+var (
+	mu sync.Mutex
+	// recorded holds this test binary's recordings by package
+	recorded = map[string][]Recording{}
+)
+
+// Record returns middleware that records responses. prefix is what the test
+// router leaves off the server's routes, usually "/api". The recordings are
+// written to a file named after the package of the caller.
+func Record(prefix string) gin.HandlerFunc {
+	if os.Getenv(EnvRecord) == "" {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	_, file, _, _ := runtime.Caller(1)
+	pkg := filepath.Base(filepath.Dir(file))
+
+	return func(c *gin.Context) {
+		w := &teeWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		// Unmatched requests have no route to check
+		if c.FullPath() == "" {
+			return
+		}
+		r := Recording{
+			Method:      c.Request.Method,
+			Route:       prefix + c.FullPath(),
+			Status:      w.Status(),
+			ContentType: w.Header().Get("Content-Type"),
+		}
+		if strings.HasPrefix(r.ContentType, "application/json") {
+			r.Body = pin(w.body.Bytes())
+		}
+		if err := add(pkg, r); err != nil {
+			panic("apispectest: " + err.Error())
+		}
+	}
+}
+
+type teeWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *teeWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Dir is where the recordings are kept
+func Dir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "testdata", "recordings")
+}
+
+// add keeps r if its body has a new shape and rewrites the package's file.
+// The file is started afresh by the first recording of a run, so responses
+// tests no longer produce do not linger.
+func add(pkg string, r Recording) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list := recorded[pkg]
+	key, shapes := shape(r.Body), 0
+	for i, seen := range list {
+		if seen.Method != r.Method || seen.Route != r.Route || seen.Status != r.Status {
+			continue
+		}
+		if shape(seen.Body) == key {
+			if len(r.Body) <= len(seen.Body) {
+				return nil
+			}
+			// The fuller body of the same shape covers more fields
+			list[i] = r
+			return write(pkg, list)
+		}
+		shapes++
+	}
+	if shapes >= perStatus {
+		return nil
+	}
+	recorded[pkg] = append(list, r)
+	return write(pkg, recorded[pkg])
+}
+
+const (
+	// pinned stands in for every timestamp
+	pinned = "2000-01-01T00:00:00Z"
+	// pinnedID stands in for every UUID, which handlers generate
+	pinnedID = "00000000-0000-0000-0000-000000000000"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// pin compacts a JSON body and replaces its timestamps and UUIDs with fixed
+// ones, so recording again only changes the files when the responses do.
+// Only the format of those values is checked against the spec. Bodies that
+// are not JSON are dropped.
+func pin(body []byte) json.RawMessage {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil
+	}
+	var walk func(v any) any
+	walk = func(v any) any {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, x := range v {
+				v[k] = walk(x)
+			}
+		case []any:
+			for i, x := range v {
+				v[i] = walk(x)
+			}
+		case string:
+			if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return pinned
+			}
+			if uuidPattern.MatchString(v) {
+				return pinnedID
+			}
+		}
+		return v
+	}
+	out, err := json.Marshal(walk(v))
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// shape is a body with its values replaced by their JSON types, so bodies
+// that differ only in IDs or timestamps count as one
+func shape(body json.RawMessage) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	b, _ := json.Marshal(typesOf(v))
+	return string(b)
+}
+
+func typesOf(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, x := range v {
+			v[k] = typesOf(x)
+		}
+		return v
+	case []any:
+		var items []any
+		for _, x := range v {
+			if t := typesOf(x); !slices.ContainsFunc(items, func(y any) bool { return equal(y, t) }) {
+				items = append(items, t)
+			}
+		}
+		return items
+	case nil:
+		return nil
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return v
+}
+
+func equal(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return bytes.Equal(x, y)
+}
+
+// compare orders recordings by route, method and status, then longest body
+// first, so files come out the same from run to run
+func compare(a, b Recording) int {
+	return cmp.Or(
+		strings.Compare(a.Route, b.Route),
+		strings.Compare(a.Method, b.Method),
+		cmp.Compare(a.Status, b.Status),
+		cmp.Compare(len(b.Body), len(a.Body)),
+		bytes.Compare(a.Body, b.Body),
+	)
+}
+
+// write sorts the package's recordings and puts one per line, which keeps
+// diffs readable
+func write(pkg string, list []Recording) error {
+	slices.SortFunc(list, compare)
+	var buf bytes.Buffer
+	buf.WriteString("[\n")
+	for i, r := range list {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		if i < len(list)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("]\n")
+	if err := os.MkdirAll(Dir(), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(Dir(), pkg+".json"), buf.Bytes(), 0o644)
+}
+
+// Load reads every package's recordings, keyed by package
+func Load() (map[string][]Recording, error) {
+	files, err := filepath.Glob(filepath.Join(Dir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	all := make(map[string][]Recording, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var list []Recording
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		all[strings.TrimSuffix(filepath.Base(file), ".json")] = list
+	}
+	return all, nil
+}
@@ -0,0 +1,109 @@
+package apispec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/jsonschema"
+)
+
+// TestContract replays the responses recorded by the handler tests against
+// the document. Record them again after changing a handler:
+//
+//	APISPEC_RECORD=1 go test ./internal/...
+func TestContract(t *testing.T) {
+	recorded, err := apispectest.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) == 0 {
+		t.Fatal("no recordings; run the handler tests with " + apispectest.EnvRecord + "=1")
+	}
+	doc := Build("test")
+
+	for pkg, list := range recorded {
+		t.Run(pkg, func(t *testing.T) {
+			for _, r := range list {
+				if err := check(doc, r); err != nil {
+					t.Errorf("%s %s %d: %v", r.Method, r.Route, r.Status, err)
+				}
+			}
+		})
+	}
+}
+
+func check(doc *Document, r apispectest.Recording) error {
+	item, ok := doc.Paths[openAPIPath(r.Route)]
+	if !ok {
+		return fmt.Errorf("undocumented route")
+	}
+	op, ok := (*item)[strings.ToLower(r.Method)]
+	if !ok {
+		return fmt.Errorf("undocumented method")
+	}
+	resp, ok := op.Responses[strconv.Itoa(r.Status)]
+	if !ok {
+		return fmt.Errorf("undocumented status")
+	}
+	if r.ContentType == "" {
+		return nil
+	}
+
+	contentType, _, _ := mime.ParseMediaType(r.ContentType)
+	media, ok := resp.Content[contentType]
+	if !ok {
+		// Downloads served as stored are documented with a wildcard
+		if _, ok := resp.Content["*/*"]; ok {
+			return nil
+		}
+		return fmt.Errorf("undocumented content type %s", contentType)
+	}
+	if contentType != jsonType {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(r.Body))
+	var body any
+	if err := dec.Decode(&body); err != nil {
+		return err
+	}
+	return jsonschema.Validate(media.Schema, doc.Components.Schemas, body)
+}
+
+func TestCheck(t *testing.T) {
+	doc := Build("test")
+	const jsonUTF8 = "application/json; charset=utf-8"
+
+	tests := []struct {
+		name    string
+		r       apispectest.Recording
+		wantErr string
+	}{
+		{"documented", apispectest.Recording{Method: "GET", Route: "/api/version", Status: 200, ContentType: jsonUTF8, Body: []byte(`{"version":"1"}`)}, ""},
+		{"no body", apispectest.Recording{Method: "DELETE", Route: "/api/feeding/:id", Status: 204}, ""},
+		{"undocumented route", apispectest.Recording{Method: "GET", Route: "/api/nope", Status: 200}, "undocumented route"},
+		{"undocumented method", apispectest.Recording{Method: "PATCH", Route: "/api/version", Status: 200}, "undocumented method"},
+		{"undocumented status", apispectest.Recording{Method: "GET", Route: "/api/version", Status: 418}, "undocumented status"},
+		{"undocumented content type", apispectest.Recording{Method: "GET", Route: "/api/version", Status: 200, ContentType: "text/csv"}, "undocumented content type text/csv"},
+		{"undocumented field", apispectest.Recording{Method: "GET", Route: "/api/version", Status: 200, ContentType: jsonUTF8, Body: []byte(`{"version":"1","commit":"abc"}`)}, "$.commit: undocumented field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := check(doc, tt.r)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("check() = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("check() = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
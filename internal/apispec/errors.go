@@ -0,0 +1,42 @@
+package apispec
+
+import "github.com/ninenine/babytrack/internal/quota"
+
+// The error bodies shared by handlers and middleware. Handlers write them as
+// gin.H, so these only exist to describe them.
+
+// Error is the body of most error responses
+type Error struct {
+	Error string `json:"error"`
+}
+
+// InternalError is written when a handler panics
+type InternalError struct {
+	Error      string `json:"error"`
+	IncidentID string `json:"incident_id"`
+}
+
+// RetiredError is written for routes past their sunset
+type RetiredError struct {
+	Error       string `json:"error"`
+	Replacement string `json:"replacement"`
+}
+
+// MaintenanceError is written for writes refused during maintenance
+type MaintenanceError struct {
+	Error       string `json:"error"`
+	Maintenance bool   `json:"maintenance"`
+	RetryAfter  int    `json:"retry_after"`
+}
+
+// QuotaError is written once the caller's daily quota is used up
+type QuotaError struct {
+	Error string      `json:"error"`
+	Quota quota.Quota `json:"quota"`
+}
+
+// TimingError is written for vaccination doses given too early
+type TimingError struct {
+	Error    string   `json:"error"`
+	Problems []string `json:"problems"`
+}
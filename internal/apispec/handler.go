@@ -0,0 +1,26 @@
+package apispec
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	doc *Document
+}
+
+// NewHandler builds the document once; it only changes with the code
+func NewHandler(version string) *Handler {
+	return &Handler{doc: Build(version)}
+}
+
+// RegisterRoutes registers the public OpenAPI document
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/openapi.json", h.spec)
+}
+
+func (h *Handler) spec(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.doc)
+}
@@ -0,0 +1,99 @@
+package apispec
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/jsonschema"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestHandler_Spec(t *testing.T) {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	NewHandler("1.2.3").RegisterRoutes(router.Group(""))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var got Document
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to parse %s: %v", w.Body.String(), err)
+	}
+	if got.OpenAPI != "3.1.0" || got.Info.Version != "1.2.3" || got.Paths["/api/openapi.json"] == nil {
+		t.Errorf("document = %+v", got.Info)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	doc := Build("test")
+
+	get := (*doc.Paths["/api/feeding/{id}"])["get"]
+	if get == nil || len(get.Parameters) != 1 || get.Parameters[0].Name != "id" || get.Parameters[0].In != "path" {
+		t.Fatalf("GET /api/feeding/{id} = %+v", get)
+	}
+	// Middleware responses are documented on the routes they run on
+	for _, status := range []string{"401", "403", "410", "429", "500"} {
+		if get.Responses[status] == nil {
+			t.Errorf("GET /api/feeding/{id} has no %s response", status)
+		}
+	}
+	if get.Responses["503"] != nil {
+		t.Error("reads are not refused during maintenance")
+	}
+	if len(get.Security) != 1 || get.Security[0][securityBearer] == nil {
+		t.Errorf("security = %v, want bearer", get.Security)
+	}
+	if health := (*doc.Paths["/api/health"])["get"]; health.Security != nil || health.Responses["401"] != nil {
+		t.Errorf("health = %+v, want public", health)
+	}
+
+	// Every $ref resolves
+	const prefix = "#/components/schemas/"
+	var check func(s *jsonschema.Schema)
+	check = func(s *jsonschema.Schema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" && (!strings.HasPrefix(s.Ref, prefix) || doc.Components.Schemas[s.Ref[len(prefix):]] == nil) {
+			t.Errorf("unresolved reference %s", s.Ref)
+		}
+		for _, p := range s.Properties {
+			check(p)
+		}
+		for _, a := range s.AnyOf {
+			check(a)
+		}
+		check(s.Items)
+		check(s.AdditionalProperties)
+	}
+	for _, def := range doc.Components.Schemas {
+		check(def)
+	}
+	for _, item := range doc.Paths {
+		for _, op := range *item {
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					check(media.Schema)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, media := range resp.Content {
+					check(media.Schema)
+				}
+			}
+		}
+	}
+}
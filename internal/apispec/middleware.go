@@ -0,0 +1,112 @@
+package apispec
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	securityBearer = "bearer"
+	securityAdmin  = "adminToken"
+)
+
+// rule documents what a middleware adds to the routes it runs on, mirroring
+// setupRoutes in internal/app
+type rule struct {
+	applies   func(method, path string) bool
+	responses []response
+	// security names the scheme the middleware checks, if any
+	security string
+}
+
+// public are the /api routes served without a signed-in user
+var public = []string{
+	"/api/health", "/api/version", "/api/status", "/api/events", "/api/deprecations",
+	"/api/openapi.json", "/api/admin", "/api/auth", "/api/webhooks", "/api/display",
+}
+
+var visible = []string{"/api/feeding", "/api/sleep", "/api/medications", "/api/vaccinations", "/api/appointments", "/api/notes"}
+
+var rateLimited = []string{"/api/status", "/api/events", "/api/deprecations", "/api/display", "/api/openapi.json"}
+
+func under(path string, prefixes ...string) bool {
+	return slices.ContainsFunc(prefixes, func(p string) bool {
+		return path == p || strings.HasPrefix(path, p+"/")
+	})
+}
+
+func api(_, path string) bool {
+	return under(path, "/api")
+}
+
+func protected(method, path string) bool {
+	if !api(method, path) || under(path, public...) {
+		return false
+	}
+	// Pushed by devices and shortcuts with their own keys
+	return !(method == http.MethodPost && (path == "/api/devices/:id/measurements" || path == "/api/quicklog"))
+}
+
+var shared = []rule{
+	{
+		// Recovery covers the whole router
+		applies:   func(string, string) bool { return true },
+		responses: []response{respond(http.StatusInternalServerError, InternalError{})},
+	},
+	{
+		// Retired routes are configured, so any of them may be
+		applies:   api,
+		responses: []response{respond(http.StatusGone, RetiredError{})},
+	},
+	{
+		applies: func(method, path string) bool {
+			switch method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return false
+			}
+			return api(method, path) && !under(path, "/api/auth", "/api/admin")
+		},
+		responses: []response{respond(http.StatusServiceUnavailable, MaintenanceError{})},
+	},
+	{
+		applies: func(_, path string) bool {
+			return under(path, rateLimited...)
+		},
+		responses: []response{respond(http.StatusTooManyRequests, Error{})},
+	},
+	{
+		applies: func(_, path string) bool {
+			return under(path, "/api/admin")
+		},
+		responses: []response{respond(http.StatusNotFound, Error{}), respond(http.StatusUnauthorized, Error{})},
+		security:  securityAdmin,
+	},
+	{
+		// Webhooks are authenticated by request signature
+		applies: func(_, path string) bool {
+			return under(path, "/api/webhooks")
+		},
+		responses: []response{
+			respond(http.StatusUnauthorized, Error{}), respond(http.StatusNotFound, Error{}),
+			respond(http.StatusConflict, Error{}), respond(http.StatusRequestEntityTooLarge, Error{}),
+		},
+	},
+	{
+		applies:   protected,
+		responses: []response{respond(http.StatusUnauthorized, Error{})},
+		security:  securityBearer,
+	},
+	{
+		applies: func(method, path string) bool {
+			return protected(method, path) || (method == http.MethodPost && path == "/api/quicklog")
+		},
+		responses: []response{respond(http.StatusTooManyRequests, QuotaError{})},
+	},
+	{
+		applies: func(_, path string) bool {
+			return under(path, visible...)
+		},
+		responses: []response{respond(http.StatusForbidden, Error{}), respond(http.StatusInternalServerError, Error{})},
+	},
+}
@@ -0,0 +1,9 @@
+package apispec
+
+var apispecRoutes = []route{
+	{
+		Method: "GET", Path: "/api/openapi.json",
+		Summary:   "This document",
+		Responses: []response{ok(Document{})},
+	},
+}
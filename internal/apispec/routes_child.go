@@ -0,0 +1,534 @@
+package apispec
+
+import (
+	"github.com/ninenine/babytrack/internal/attachments"
+	"github.com/ninenine/babytrack/internal/careplan"
+	"github.com/ninenine/babytrack/internal/documents"
+	"github.com/ninenine/babytrack/internal/favorites"
+	"github.com/ninenine/babytrack/internal/grants"
+	"github.com/ninenine/babytrack/internal/journal"
+	"github.com/ninenine/babytrack/internal/media"
+	"github.com/ninenine/babytrack/internal/memories"
+	"github.com/ninenine/babytrack/internal/messaging"
+	"github.com/ninenine/babytrack/internal/playtime"
+	"github.com/ninenine/babytrack/internal/presence"
+	"github.com/ninenine/babytrack/internal/quality"
+	"github.com/ninenine/babytrack/internal/solids"
+	"github.com/ninenine/babytrack/internal/templates"
+	"github.com/ninenine/babytrack/internal/timers"
+	"github.com/ninenine/babytrack/internal/transfer"
+)
+
+var memoriesRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/on-this-day",
+		Summary:     "Memories from this day in earlier months",
+		Description: "Memories from the same day of the month in earlier months, newest first: `{\"child_id\",\"date\",\"memories\":[{\"kind\",\"id\",\"date\",\"months_ago\",\"title\",...}]}`; `date` is `YYYY-MM-DD` and defaults to today",
+		Query:       []string{"date"},
+		Responses:   []response{ok(memories.OnThisDay{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+}
+
+var grantsRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/access-grants",
+		Summary:     "Professionals with access to the child",
+		Description: "Professionals given access to the child, with each grant's `status` (`active`, `expired` or `revoked`)",
+		Responses:   []response{ok([]grants.Grant{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/children/:id/access-grants",
+		Summary:     "Grant a professional read access",
+		Description: "Grant a professional read access (admins only): `email` of their professional account, `days` (default 14, at most 90) and an optional `note`",
+		Request:     grants.CreateGrantRequest{},
+		Responses:   []response{created(grants.Grant{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/children/:id/access-grants/:grantId",
+		Summary:   "Revoke a grant early (admins only)",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/professional",
+		Summary:   "Your professional account; 403 if you have not registered one",
+		Responses: []response{ok(grants.Professional{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/professional",
+		Summary:     "Register or update your professional account",
+		Description: "Register or update it: `profession` (`pediatrician`, `lactation_consultant`, `midwife`, `nurse` or `other`) and optional `organisation`",
+		Request:     grants.RegisterRequest{},
+		Responses:   []response{ok(grants.Professional{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/professional/children",
+		Summary:   "Children you currently hold an active grant for",
+		Responses: []response{ok([]grants.Grant{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/professional/children/:childId/records",
+		Summary:   "The child's records, in the same layout as the child bundle",
+		Responses: []response{ok(transfer.Bundle{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+}
+
+var messagingRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/messages",
+		Summary:     "The child's message thread",
+		Description: "The child's thread, oldest first: `{\"child_id\",\"messages\",\"has_more\",\"last_read_at\",\"unread\"}`; `limit` defaults to 50 (at most 200) and `before` takes a message ID to page back",
+		Query:       []string{"before", "limit"},
+		Responses:   []response{ok(messaging.Thread{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/children/:id/messages",
+		Summary:   "Post a message (`body`, up to 4000 characters)",
+		Request:   messaging.SendRequest{},
+		Responses: []response{created(messaging.Message{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/children/:id/messages/participants",
+		Summary:     "Participants in the child's thread",
+		Description: "Who can read and post: family members with their role, and professionals with an active grant with their profession",
+		Responses:   []response{ok([]messaging.Participant{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/children/:id/messages/read",
+		Summary:     "Mark the thread read",
+		Description: "Move your read marker to `message_id`, or to the newest message when the body is empty; returns the marker with the remaining `unread` count",
+		Request:     messaging.MarkReadRequest{},
+		Responses:   []response{ok(messaging.ReadMarker{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+}
+
+var careplanRoutes = []route{
+	{
+		Method: "GET", Path: "/api/care-plans/:id",
+		Summary:   "Get a plan",
+		Responses: []response{ok(careplan.Plan{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/care-plans/:id",
+		Summary:   "Replace a plan; tasks that keep their `id` keep their history",
+		Request:   careplan.PlanRequest{},
+		Responses: []response{ok(careplan.Plan{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/care-plans/:id",
+		Summary:   "Delete a plan",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/care-plans/:id/compliance",
+		Summary:     "Care plan adherence",
+		Description: "Per task and overall: periods `done`, `missed` or `pending`, with `due`, `done` and `rate`; defaults to the last 7 days of the plan up to today",
+		Query:       []string{"from", "to"},
+		Responses:   []response{ok(careplan.Compliance{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/children/:id/care-plans",
+		Summary:   "The child's care plans",
+		Responses: []response{ok([]careplan.Plan{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/children/:id/care-plans",
+		Summary:     "Create a care plan",
+		Description: "Create a plan: `title`, optional `notes`, `timezone` (IANA, default `UTC`), `start_date` (default today), optional `end_date`, and `tasks`",
+		Request:     careplan.PlanRequest{},
+		Responses:   []response{created(careplan.Plan{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+}
+
+var documentsRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/documents",
+		Summary:   "A child's documents grouped by category, optionally in one category",
+		Query:     []string{"category"},
+		Responses: []response{ok([]documents.Document{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/children/:id/documents",
+		Summary:     "Add a document",
+		Description: "Add a document: `{\"media_id\":\"...\",\"category\":\"insurance\",\"title\":\"Insurance card\",\"notes\":\"\",\"expires_on\":\"2025-06-30\"}`",
+		Request:     documents.DocumentRequest{},
+		Responses:   []response{created(documents.Document{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/documents/:id",
+		Summary:   "Get a document",
+		Responses: []response{ok(documents.Document{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/documents/:id",
+		Summary:   "Replace a document's file, category, title, notes and expiry date",
+		Request:   documents.DocumentRequest{},
+		Responses: []response{ok(documents.Document{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/documents/:id",
+		Summary:   "Delete a document; the file stays in the media store",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 500},
+	},
+}
+
+var solidsRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/allergens",
+		Summary:     "Allergen introduction checklist",
+		Description: "Checklist of the nine major allergens: milk, egg, peanut, tree nuts, soy, wheat, fish, shellfish and sesame",
+		Responses:   []response{ok(solids.AllergenChecklist{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/children/:id/food-logs",
+		Summary:   "Every time a food was tried, oldest first",
+		Query:     []string{"food"},
+		Responses: []response{ok([]solids.FoodLog{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/children/:id/food-logs",
+		Summary:     "Log a food",
+		Description: "Log a food: `food`, `reaction` (`none`, `rash`, `hives`, `vomiting`, `diarrhea`, `swelling` or `other`), and optionally `severity` (`mild`, `moderate` or `severe`, only with a reaction), `allergen`, `tried_at` and `notes`",
+		Request:     solids.LogFoodRequest{},
+		Responses:   []response{created(solids.FoodLog{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/children/:id/food-logs/:logId",
+		Summary:   "Delete a log",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/children/:id/foods",
+		Summary:     "Foods tried",
+		Description: "Foods the child has tried, each with how often and whether it brought a reaction, and the common first foods not tried yet",
+		Responses:   []response{ok(solids.FoodList{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+}
+
+var playtimeRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/activities",
+		Summary:   "Latest 100 sessions, newest first",
+		Query:     []string{"type"},
+		Responses: []response{ok([]playtime.Session{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/children/:id/activities",
+		Summary:     "Log a play session",
+		Description: "Log a finished session: `type` (`tummy_time`, `outdoor`, `screen` or `play`), `start_time`, `end_time` and optional `notes`",
+		Request:     playtime.LogRequest{},
+		Responses:   []response{created(playtime.Session{})},
+		Errors:      []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/children/:id/activities/start",
+		Summary:   "Start a `type` of activity now",
+		Request:   playtime.StartRequest{},
+		Responses: []response{created(playtime.Session{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "GET", Path: "/api/children/:id/activities/totals",
+		Summary:     "Daily play minutes",
+		Description: "Minutes of each activity per day, for `days` days (default 7, at most 31) from `start` (default so the last day is today), counted in `tz` (default UTC)",
+		Query:       []string{"tz", "days", "start"},
+		Responses:   []response{ok(playtime.Totals{})},
+		Errors:      []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/children/:id/activities/:sessionId",
+		Summary:   "Delete a session",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/children/:id/activities/:sessionId/stop",
+		Summary:   "Stop a running session; stopping one that has already stopped returns it unchanged",
+		Responses: []response{ok(playtime.Session{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+}
+
+var qualityRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/data-quality",
+		Summary:     "The child's data quality report",
+		Description: "The child's latest report: `{\"child_id\",\"issues\":[{\"rule\",\"message\",\"records\":[{\"type\",\"id\",\"at\",\"link\"}],\"fix\"}],\"generated_at\"}`",
+		Responses:   []response{ok(quality.Report{})},
+		Errors:      []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/children/:id/data-quality/refresh",
+		Summary:   "Run the checks now, e.g. after correcting records",
+		Responses: []response{ok(quality.Report{})},
+		Errors:    []int{403, 404, 500},
+	},
+}
+
+var timersRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/active",
+		Summary:     "The child's running timers",
+		Description: "The child's in-progress timed activities: `{\"child_id\",\"activities\":[{\"kind\",\"id\",\"started_by\",\"paused\",\"elapsed_seconds\",\"detail\"}],\"as_of\"}`",
+		Responses:   []response{ok(timers.Active{})},
+		Errors:      []int{403, 404, 500},
+	},
+}
+
+var logsheetRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/sheets/daily.pdf",
+		Summary:   "One day's feeds, sleep and medication doses as a PDF table, a row per hour",
+		Query:     []string{"tz", "date"},
+		Responses: []response{file(200, "application/pdf"), file(200, "application/zip")},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/children/:id/sheets/weekly.pdf",
+		Summary:   "Seven days from `start`, a page per day",
+		Query:     []string{"tz", "start"},
+		Responses: []response{file(200, "application/pdf"), file(200, "application/zip")},
+		Errors:    []int{400, 403, 404, 500},
+	},
+}
+
+var transferRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/bundle",
+		Summary:     "Export the child as a bundle",
+		Description: "Export the child's complete record as a portable JSON bundle, feedings and sleep oldest first, other sections in the order their own lists use",
+		Responses:   []response{ok(transfer.Bundle{}), file(200, "application/zip")},
+		Errors:      []int{400, 403, 404, 422, 500},
+	},
+	{
+		Method: "GET", Path: "/api/children/:id/imports",
+		Summary:   "Provenance of any bundles imported into this child",
+		Responses: []response{ok([]transfer.ChildImport{})},
+		Errors:    []int{400, 403, 404, 422, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/child-imports",
+		Summary:     "Import a child bundle",
+		Description: "Import a child bundle into this family, recording where each record came from. With `source` set to `huckleberry`, `baby_tracker` or `glow_baby`, the body is that app's export file instead",
+		Query:       []string{"source", "name", "date_of_birth", "timezone", "gender"},
+		Request:     transfer.Bundle{},
+		Responses:   []response{created(transfer.ImportResult{})},
+		Errors:      []int{400, 403, 404, 413, 422, 500},
+	},
+}
+
+var journalRoutes = []route{
+	{
+		Method: "GET", Path: "/api/journal",
+		Summary:     "Journal entries by month",
+		Description: "Entries grouped by month, newest first: `[{\"month\":\"2024-03\",\"entries\":[...]}]`; `from` and `to` are `YYYY-MM-DD`",
+		Query:       []string{"child_id", "milestone", "from", "to"},
+		Responses:   []response{ok([]journal.Month{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/journal",
+		Summary:     "Add a journal entry",
+		Description: "Add an entry: `child_id`, `date` (`YYYY-MM-DD`), and a `media_id` photo, a `caption`, or both; set `milestone` for firsts",
+		Request:     journal.CreateEntryRequest{},
+		Responses:   []response{created(journal.Entry{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/journal/:id",
+		Summary:   "Get an entry",
+		Responses: []response{ok(journal.Entry{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/journal/:id",
+		Summary:   "Replace an entry's photo, caption, date and milestone flag",
+		Request:   journal.UpdateEntryRequest{},
+		Responses: []response{ok(journal.Entry{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/journal/:id",
+		Summary:   "Delete an entry; the photo stays in the media store",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 500},
+	},
+}
+
+var attachmentsRoutes = []route{
+	{
+		Method: "GET", Path: "/api/attachments/:entityType/:entityId",
+		Summary:   "Files attached to a record, oldest first",
+		Responses: []response{ok([]attachments.Attachment{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/attachments/:entityType/:entityId",
+		Summary:     "Attach an uploaded file",
+		Description: "Attach an uploaded file: `{\"media_id\":\"...\",\"caption\":\"Six month check\"}`; attaching it again updates the caption",
+		Request:     attachments.AttachRequest{},
+		Responses:   []response{created(attachments.Attachment{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/attachments/:entityType/:entityId/:mediaId",
+		Summary:   "Detach a file; it stays in the media store",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 500},
+	},
+}
+
+var mediaRoutes = []route{
+	{
+		Method: "POST", Path: "/api/media",
+		Summary:            "Upload an attachment",
+		Description:        "Upload an attachment (multipart `file` and `family_id`); type is sniffed from content and the file is scanned for malware",
+		RequestContentType: "multipart/form-data",
+		Responses:          []response{created(media.Media{})},
+		Errors:             []int{400, 403, 404, 413, 415, 423, 500},
+	},
+	{
+		Method: "GET", Path: "/api/media/:id",
+		Summary:   "Attachment metadata and scan status, with a `content_url` that includes the content hash",
+		Responses: []response{ok(media.Media{})},
+		Errors:    []int{400, 403, 404, 413, 415, 423, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/media/:id",
+		Summary:   "Delete an attachment",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 413, 415, 423, 500},
+	},
+	{
+		Method: "GET", Path: "/api/media/:id/content",
+		Summary:   "Download an attachment; returns 423 while pending or quarantined",
+		Query:     []string{"v"},
+		Responses: []response{respond(304), file(200, "*/*")},
+		Errors:    []int{400, 403, 404, 413, 415, 423, 500},
+	},
+	{
+		Method: "POST", Path: "/api/media/:id/release",
+		Summary:   "Rescan and release a quarantined attachment (admins only)",
+		Responses: []response{ok(media.Media{})},
+		Errors:    []int{400, 403, 404, 413, 415, 423, 500},
+	},
+	{
+		Method: "POST", Path: "/api/media/:id/report",
+		Summary:   "Report an attachment, quarantining it",
+		Request:   media.ReportRequest{},
+		Responses: []response{ok(media.Media{})},
+		Errors:    []int{400, 403, 404, 413, 415, 423, 500},
+	},
+}
+
+var favoritesRoutes = []route{
+	{
+		Method: "GET", Path: "/api/favorites",
+		Summary:   "List the current user's starred records",
+		Query:     []string{"entity_type"},
+		Responses: []response{ok([]favorites.Favorite{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "POST", Path: "/api/favorites",
+		Summary:   "Star a record",
+		Request:   favorites.StarRequest{},
+		Responses: []response{created(favorites.Favorite{})},
+		Errors:    []int{400, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/favorites/:entityType/:entityId",
+		Summary:   "Unstar a record",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 500},
+	},
+}
+
+var templatesRoutes = []route{
+	{
+		Method: "GET", Path: "/api/templates",
+		Summary:   "List note templates and quick-log presets",
+		Query:     []string{"family_id", "kind"},
+		Responses: []response{ok([]templates.Template{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "POST", Path: "/api/templates",
+		Summary:   "Create template",
+		Request:   templates.CreateTemplateRequest{},
+		Responses: []response{created(templates.Template{})},
+		Errors:    []int{400, 500},
+	},
+	{
+		Method: "GET", Path: "/api/templates/:id",
+		Summary:   "Get a template",
+		Responses: []response{ok(templates.Template{})},
+		Errors:    []int{404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/templates/:id",
+		Summary:   "Update template",
+		Request:   templates.CreateTemplateRequest{},
+		Responses: []response{ok(templates.Template{})},
+		Errors:    []int{400, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/templates/:id",
+		Summary:   "Delete template",
+		Responses: []response{noContent()},
+		Errors:    []int{500},
+	},
+}
+
+var presenceRoutes = []route{
+	{
+		Method: "GET", Path: "/api/presence",
+		Summary:   "Other family members currently active on the child",
+		Query:     []string{"child_id"},
+		Responses: []response{ok([]presence.Presence{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/presence",
+		Summary:     "Presence heartbeat",
+		Description: "Heartbeat while logging for a child (`child_id`, `activity`: `typing_note`, `sleep_timer` or `logging`); send every ~10 seconds",
+		Request:     presence.HeartbeatRequest{},
+		Responses:   []response{ok(presence.Presence{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/presence",
+		Summary:   "Stop showing an activity",
+		Query:     []string{"child_id", "activity"},
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 500},
+	},
+}
@@ -0,0 +1,449 @@
+package apispec
+
+import (
+	"github.com/ninenine/babytrack/internal/activity"
+	"github.com/ninenine/babytrack/internal/closures"
+	"github.com/ninenine/babytrack/internal/dashboard"
+	"github.com/ninenine/babytrack/internal/daycare"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/export"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/healthinfo"
+	"github.com/ninenine/babytrack/internal/inbound"
+	"github.com/ninenine/babytrack/internal/integrations"
+	"github.com/ninenine/babytrack/internal/kiosk"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sandbox"
+	"github.com/ninenine/babytrack/internal/stats"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var familyRoutes = []route{
+	{
+		Method: "GET", Path: "/api/families",
+		Summary:   "List user's families",
+		Responses: []response{ok([]family.FamilyWithChildren{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "POST", Path: "/api/families",
+		Summary:   "Create family",
+		Request:   family.CreateFamilyRequest{},
+		Responses: []response{created(family.Family{})},
+		Errors:    []int{400, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/join",
+		Summary:   "Join with a scanned link invite: `{\"token\"}`",
+		Request:   family.AcceptInviteRequest{},
+		Responses: []response{ok(family.Family{})},
+		Errors:    []int{400, 403, 404, 409, 410, 429, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId",
+		Summary:   "Get a family",
+		Responses: []response{ok(family.Family{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "PUT", Path: "/api/families/:familyId",
+		Summary:   "Rename a family",
+		Request:   family.CreateFamilyRequest{},
+		Responses: []response{ok(family.Family{})},
+		Errors:    []int{400, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/families/:familyId",
+		Summary:   "Delete a family (admins)",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/children",
+		Summary:   "List children, or only the changes since a time",
+		Query:     []string{"since"},
+		Responses: []response{ok(delta.Changes[family.Child]{}, []family.Child{})},
+		Errors:    []int{400, 410, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/children",
+		Summary:   "Add child",
+		Request:   family.AddChildRequest{},
+		Responses: []response{created(family.Child{})},
+		Errors:    []int{400, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/families/:familyId/children/:childId",
+		Summary:   "Update child",
+		Request:   family.AddChildRequest{},
+		Responses: []response{ok(family.Child{})},
+		Errors:    []int{400, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/families/:familyId/children/:childId",
+		Summary:   "Remove a child",
+		Responses: []response{noContent()},
+		Errors:    []int{500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/invite",
+		Summary:   "Invite someone by `email` (admins only); inviting an address with an open invite resends it",
+		Request:   family.InviteRequest{},
+		Responses: []response{ok(family.Invite{})},
+		Errors:    []int{400, 403, 404, 409, 410, 429, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/invites",
+		Summary:   "Invites with their `pending`, `expired` or `accepted` status (admins only)",
+		Responses: []response{ok([]family.Invite{})},
+		Errors:    []int{403, 404, 409, 410, 429, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/invites/qr",
+		Summary:     "Create a QR code invite",
+		Description: "Create a link invite and return it as a PNG QR code, with `X-Invite-Id` and `X-Invite-Expires-At` headers (admins only)",
+		Responses:   []response{file(201, "image/png")},
+		Errors:      []int{403, 404, 409, 410, 429, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/families/:familyId/invites/:inviteId",
+		Summary:   "Revoke an emailed or link invite that hasn't been accepted (admins only)",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 404, 409, 410, 429, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/invites/:inviteId/resend",
+		Summary:   "Send an invite again and restart its expiry (admins only)",
+		Responses: []response{ok(family.Invite{})},
+		Errors:    []int{403, 404, 409, 410, 429, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/join",
+		Summary:   "Join a family by ID",
+		Responses: []response{ok(family.Family{})},
+		Errors:    []int{404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/leave",
+		Summary:   "Leave a family",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/members",
+		Summary:   "List family members",
+		Responses: []response{ok([]family.MemberWithUser{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "DELETE", Path: "/api/families/:familyId/members/:userId",
+		Summary:   "Remove a member",
+		Responses: []response{noContent()},
+		Errors:    []int{500},
+	},
+}
+
+var sandboxRoutes = []route{
+	{
+		Method: "POST", Path: "/api/families/sandbox",
+		Summary:     "Create a sandbox family",
+		Description: "Create a sandbox family for testing an integration: `{\"name\"}`. Returns `{\"family\",\"sandbox\"}`, with the caller as admin",
+		Request:     sandbox.CreateRequest{},
+		Responses:   []response{created(sandbox.Created{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/sandbox",
+		Summary:   "`created_at`, `reset_at` and `next_reset_at`; 404 for ordinary families",
+		Responses: []response{ok(sandbox.Sandbox{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/sandbox/captures",
+		Summary:     "Held-back sandbox notifications",
+		Description: "Notifications held back from the family's members, newest first: `[{\"id\",\"user_id\",\"kind\",\"payload\",\"created_at\"}]`. `limit` defaults to 50, up to 200",
+		Query:       []string{"limit"},
+		Responses:   []response{ok([]sandbox.Capture{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/sandbox/reset",
+		Summary:   "Wipe the family's records now (admins only)",
+		Responses: []response{ok(sandbox.Sandbox{})},
+		Errors:    []int{403, 404, 500},
+	},
+}
+
+var inboundRoutes = []route{
+	{
+		Method: "GET", Path: "/api/families/:familyId/inbound-address",
+		Summary:   "The family's email-to-note address, created on first request",
+		Responses: []response{ok(inbound.Address{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/inbound-address/rotate",
+		Summary:   "Replace the address (admins only); mail to the old one is rejected",
+		Responses: []response{ok(inbound.Address{})},
+		Errors:    []int{403, 404, 500},
+	},
+}
+
+var closuresRoutes = []route{
+	{
+		Method: "GET", Path: "/api/families/:familyId/closures",
+		Summary:   "Days the clinic is closed, from today on unless `all` is set",
+		Query:     []string{"all"},
+		Responses: []response{ok([]closures.Closure{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/closures",
+		Summary:   "Add a closed day (admins only): `date` (YYYY-MM-DD) and optional `name`",
+		Request:   closures.CreateClosureRequest{},
+		Responses: []response{created(closures.Closure{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/families/:familyId/closures/:closureId",
+		Summary:   "Remove a closed day (admins only)",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/reminder-settings",
+		Summary:   "Whether reminders skip closed days",
+		Responses: []response{ok(closures.Settings{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/families/:familyId/reminder-settings",
+		Summary:   "Set `shift_to_business_day`, `weekends_closed` and `timezone` (admins only)",
+		Request:   closures.UpdateSettingsRequest{},
+		Responses: []response{ok(closures.Settings{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+}
+
+var activityRoutes = []route{
+	{
+		Method: "GET", Path: "/api/families/:familyId/activity",
+		Summary:     "Family activity feed",
+		Description: "Who did what across the family's children, newest first: `[{\"id\",\"child_id\",\"child_name\",\"actor_id\",\"actor_name\",\"entity_type\",\"entity_id\",\"action\",\"summary\",\"at\"}]`, with `summary` a sentence such as \"Sam logged a 40m nap for Emma\". Page back with `before=<id>` from the pagination `next`. `limit` defaults to 50, up to 200",
+		Query:       []string{"child_id", "actor_id", "before", "limit"},
+		Responses:   []response{ok(activity.Feed{})},
+		Errors:      []int{400, 403, 500},
+	},
+}
+
+var kioskRoutes = []route{
+	{
+		Method: "GET", Path: "/api/display",
+		Summary:     "Wall display live view",
+		Description: "The live view, authenticated with the `X-Display-Token` header or `Authorization: Bearer <token>`: `{\"family_id\",\"family_name\",\"children\":[{\"child_id\",\"child_name\",\"timers\",\"last_feeding\",\"last_sleep\",\"hidden\"}],\"as_of\",\"next_token\",\"token_expires_at\"}`",
+		Responses:   []response{ok(kiosk.View{})},
+		Errors:      []int{401, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/displays",
+		Summary:   "List the family's paired displays (admins only)",
+		Responses: []response{ok([]kiosk.Display{})},
+		Errors:    []int{401, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/displays",
+		Summary:   "Pair a display (`name`), admins only; the token is only returned here",
+		Request:   kiosk.PairRequest{},
+		Responses: []response{created(kiosk.Display{})},
+		Errors:    []int{400, 401, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/families/:familyId/displays/:displayId",
+		Summary:   "Revoke a display (admins only); it stops working on its next refresh",
+		Responses: []response{noContent()},
+		Errors:    []int{401, 403, 404, 500},
+	},
+}
+
+var visibilityRoutes = []route{
+	{
+		Method: "GET", Path: "/api/families/:familyId/members/:userId/visibility",
+		Summary:   "Record types hidden from a member",
+		Responses: []response{ok(visibility.MemberVisibility{})},
+		Errors:    []int{400, 403, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/families/:familyId/members/:userId/visibility",
+		Summary:     "Set a member's hidden record types",
+		Description: "Hide record types from a member (admins only); hidden types return 403 on child-scoped requests",
+		Request:     visibility.SetVisibilityRequest{},
+		Responses:   []response{ok(visibility.MemberVisibility{})},
+		Errors:      []int{400, 403, 500},
+	},
+}
+
+var healthinfoRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/health-info",
+		Summary:   "The same details through a child, for appointment booking and the emergency card",
+		Responses: []response{ok(healthinfo.HealthInfo{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/health-info",
+		Summary:   "The family's `insurance` and preferred `pharmacy`; either is `null` until set",
+		Responses: []response{ok(healthinfo.HealthInfo{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/families/:familyId/health-info",
+		Summary:     "Replace the family's health info",
+		Description: "Replace both (admins only): `{\"insurance\":{\"provider\":\"...\",\"policy_number\":\"...\",\"plan_name\":\"\",\"group_number\":\"\",\"policy_holder\":\"\",\"phone\":\"\"},\"pharmacy\":{\"name\":\"...\",\"phone\":\"\",\"address\":\"\"}}`; a `null` section clears it",
+		Request:     healthinfo.UpdateRequest{},
+		Responses:   []response{ok(healthinfo.HealthInfo{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+}
+
+var integrationsRoutes = []route{
+	{
+		Method: "GET", Path: "/api/families/:familyId/receiver-keys",
+		Summary:   "List integration receiver keys (admins only)",
+		Responses: []response{ok([]integrations.ReceiverKey{})},
+		Errors:    []int{400, 401, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/receiver-keys",
+		Summary:   "Create a receiver key; the signing secret is only returned here",
+		Request:   integrations.CreateKeyRequest{},
+		Responses: []response{created(integrations.ReceiverKey{})},
+		Errors:    []int{400, 401, 403, 404, 409, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/families/:familyId/receiver-keys/:keyId",
+		Summary:   "Revoke a receiver key",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 401, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/webhooks/daycare",
+		Summary:     "Receive a daycare report",
+		Description: "Daycare report (`child_id`, `title`, `content`, optional `occurred_at`), filed as a note on the child",
+		Responses:   []response{created(notes.Note{})},
+		Errors:      []int{400, 401, 403, 404, 409, 500},
+	},
+}
+
+var daycareRoutes = []route{
+	{
+		Method: "GET", Path: "/api/daycare/providers",
+		Summary:   "The connectors this server has, with whether each polls and whether it receives pushes",
+		Responses: []response{ok([]daycare.ProviderInfo{})},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/daycare-connections",
+		Summary:   "Daycare connections with when each last synced and its last error (admins only)",
+		Responses: []response{ok([]daycare.Connection{})},
+		Errors:    []int{400, 403, 404, 500, 502, 503},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/daycare-connections",
+		Summary:     "Connect a daycare",
+		Description: "Connect a daycare (admins only): `provider`, `name`, `children` mapping the provider's child IDs to the family's, and the `endpoint` and optional `token` for providers that are polled",
+		Request:     daycare.CreateConnectionRequest{},
+		Responses:   []response{created(daycare.Connection{})},
+		Errors:      []int{400, 403, 404, 500, 502, 503},
+	},
+	{
+		Method: "DELETE", Path: "/api/families/:familyId/daycare-connections/:connectionId",
+		Summary:   "Disconnect a daycare; records already imported stay (admins only)",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 500, 502, 503},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/daycare-connections/:connectionId/sync",
+		Summary:   "Poll the provider now (admins only)",
+		Responses: []response{ok(daycare.SyncResult{})},
+		Errors:    []int{400, 403, 404, 500, 502, 503},
+	},
+	{
+		Method: "POST", Path: "/api/webhooks/daycare/:connectionId",
+		Summary:            "Receive a daycare provider report",
+		Description:        "A daily report pushed for one of the key's family's daycare connections, in the provider's format",
+		RequestContentType: "application/json",
+		Responses:          []response{ok(daycare.SyncResult{})},
+		Errors:             []int{400, 403, 404, 500, 502, 503},
+	},
+}
+
+var dashboardRoutes = []route{
+	{
+		Method: "GET", Path: "/api/families/:familyId/due",
+		Summary:     "Overdue and upcoming items",
+		Description: "Prioritised list of overdue and upcoming items across all children: scheduled vaccinations, medication courses ending, appointments and pending screenings",
+		Query:       []string{"days"},
+		Responses:   []response{ok(dashboard.FamilyDue{})},
+		Errors:      []int{403, 500},
+	},
+	{
+		Method: "GET", Path: "/api/me/children",
+		Summary:     "All children across the user's families",
+		Description: "Every child in all of the user's families, each with its family, any running sleep, the next scheduled vaccination and active medications",
+		Responses:   []response{ok([]dashboard.ChildStatus{})},
+		Errors:      []int{500},
+	},
+}
+
+var statsRoutes = []route{
+	{
+		Method: "GET", Path: "/api/families/:familyId/stats-opt-in",
+		Summary:   "Whether the family shares anonymised stats",
+		Responses: []response{ok(stats.OptIn{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "PUT", Path: "/api/families/:familyId/stats-opt-in",
+		Summary:   "Opt in or out of anonymised stats (admins only)",
+		Request:   stats.SetOptInRequest{},
+		Responses: []response{ok(stats.OptIn{})},
+		Errors:    []int{400, 403, 500},
+	},
+	{
+		Method: "GET", Path: "/api/stats/children/:childId/daily",
+		Summary:     "Family daily summary",
+		Description: "A child's feedings, ml, nursing minutes, sleeps and sleep minutes per day for the last `days` days (default 7, up to 90), oldest first",
+		Query:       []string{"days"},
+		Responses:   []response{ok(stats.DailyStats{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/stats/population/:metric",
+		Summary:   "Population curve for a metric",
+		Responses: []response{ok(stats.Curve{})},
+		Errors:    []int{404, 500},
+	},
+}
+
+var exportRoutes = []route{
+	{
+		Method: "GET", Path: "/api/children/:id/dataset.csv",
+		Summary:     "Long-format CSV (timestamp, type, metric, value) for spreadsheet or R analysis",
+		Description: "`types` is a comma-separated list of `sleep` and `feeding`, by default every type not hidden from the caller. Any other type returns 400 and a hidden type 403.",
+		Query:       []string{"types", "from", "to", "as_of"},
+		Responses:   []response{file(200, "text/csv"), file(200, "application/zip")},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/export-settings",
+		Summary:   "Whether the family requires a passphrase on exports",
+		Responses: []response{ok(export.Settings{})},
+		Errors:    []int{400, 403, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/families/:familyId/export-settings",
+		Summary:   "Turn the requirement on or off (admins only): `require_passphrase`",
+		Request:   export.UpdateSettingsRequest{},
+		Responses: []response{ok(export.Settings{})},
+		Errors:    []int{400, 403, 500},
+	},
+}
@@ -0,0 +1,789 @@
+package apispec
+
+import (
+	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/pumping"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/temperature"
+	"github.com/ninenine/babytrack/internal/vaccination"
+)
+
+var feedingRoutes = []route{
+	{
+		Method: "GET", Path: "/api/feeding",
+		Summary:   "List feedings (?child_id=)",
+		Query:     []string{"child_id"},
+		Responses: []response{ok([]feeding.Feeding{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/feeding",
+		Summary:   "Log a feeding",
+		Request:   feeding.CreateFeedingRequest{},
+		Responses: []response{created(feeding.Feeding{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/feeding/formula/:childId",
+		Summary:     "Formula guide",
+		Description: "Formula guide from the child's latest recorded weight and age: daily volume range, feeds per day and per-feed amounts in ml and oz. `kcal_per_oz` sets the formula concentration (19-30, default 20) and `guideline` overrides the configured guideline",
+		Query:       []string{"guideline", "kcal_per_oz"},
+		Responses:   []response{ok(feeding.FormulaGuide{})},
+		Errors:      []int{400, 404, 422, 500, 503},
+	},
+	{
+		Method: "GET", Path: "/api/feeding/goals/:childId",
+		Summary:   "The child's daily intake goal",
+		Responses: []response{ok(feeding.Goal{})},
+		Errors:    []int{404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/feeding/goals/:childId",
+		Summary:     "Set the child's feeding goal",
+		Description: "Set it: any of `daily_ml`, `daily_kcal` and `daily_nursing_minutes`. Targets left out are not tracked",
+		Request:     feeding.SetGoalRequest{},
+		Responses:   []response{ok(feeding.Goal{})},
+		Errors:      []int{400, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/feeding/goals/:childId",
+		Summary:   "Stop tracking intake against a goal",
+		Responses: []response{noContent()},
+		Errors:    []int{500},
+	},
+	{
+		Method: "GET", Path: "/api/feeding/last/:childId",
+		Summary:   "Get the child's most recent feeding",
+		Responses: []response{ok(feeding.Feeding{}, nil)},
+		Errors:    []int{500},
+	},
+	{
+		Method: "POST", Path: "/api/feeding/nursing/start",
+		Summary:     "Start a nursing timer",
+		Description: "Start a nursing timer for `child_id` on `side` (`left` or `right`); this also starts a breastfeed",
+		Request:     feeding.StartNursingRequest{},
+		Responses:   []response{created(feeding.NursingTimer{})},
+		Errors:      []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "GET", Path: "/api/feeding/nursing/:childId",
+		Summary:   "The child's running nursing timer",
+		Responses: []response{ok(feeding.NursingTimer{}, nil)},
+		Errors:    []int{403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/feeding/nursing/:childId/pause",
+		Summary:   "Pause the timer",
+		Responses: []response{ok(feeding.NursingTimer{})},
+		Errors:    []int{403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/feeding/nursing/:childId/resume",
+		Summary:   "Resume it, optionally on a different `side`",
+		Request:   feeding.NursingSideRequest{},
+		Responses: []response{ok(feeding.NursingTimer{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/feeding/nursing/:childId/stop",
+		Summary:   "Stop the timer and end the feed",
+		Responses: []response{ok(feeding.NursingTimer{})},
+		Errors:    []int{403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/feeding/nursing/:childId/switch",
+		Summary:   "Switch sides; without a `side` it switches to the other one",
+		Request:   feeding.NursingSideRequest{},
+		Responses: []response{ok(feeding.NursingTimer{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "GET", Path: "/api/feeding/stats/:childId",
+		Summary:     "Daily intake against the goal",
+		Description: "Daily intake for the last `days` days (default 7, up to 90): feedings, ml, estimated kcal and nursing minutes, with progress against the child's goal",
+		Query:       []string{"days"},
+		Responses:   []response{ok(feeding.Stats{})},
+		Errors:      []int{400, 500},
+	},
+	{
+		Method: "GET", Path: "/api/feeding/:id",
+		Summary:   "Get a feeding",
+		Responses: []response{ok(feeding.Feeding{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/feeding/:id",
+		Summary:   "Update a feeding",
+		Request:   feeding.CreateFeedingRequest{},
+		Responses: []response{ok(feeding.Feeding{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/feeding/:id",
+		Summary:   "Delete a feeding",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/feeding/:id/private",
+		Summary:     "Mark a feeding private to its author, or shared again (author only): `{\"private\": true}`",
+		Description: "Other members' private feedings are left out of lists, the activity feed and exports, and read as not found",
+		Request:     feeding.SetPrivateRequest{},
+		Responses:   []response{respond(200)},
+		Errors:      []int{400, 403, 404, 500},
+	},
+}
+
+var pumpingRoutes = []route{
+	{
+		Method: "GET", Path: "/api/families/:familyId/milk-stash",
+		Summary:     "Stored milk",
+		Description: "Stored milk, soonest to expire first, with totals in ml; `all` includes empty and discarded containers",
+		Query:       []string{"all"},
+		Responses:   []response{ok(pumping.Inventory{})},
+		Errors:      []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/milk-stash",
+		Summary:     "Store a milk container",
+		Description: "Store a container: `amount`, `unit`, `storage`, and optionally `pumped_at`, `expires_at` and `notes`",
+		Request:     pumping.AddItemRequest{},
+		Responses:   []response{created(pumping.Item{})},
+		Errors:      []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/families/:familyId/milk-stash/:itemId",
+		Summary:   "Discard a container",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/milk-stash/:itemId/consume",
+		Summary:     "Use stored milk for a feed",
+		Description: "Use milk for a bottle feed: `feeding_id`, and `amount` with `unit` when less than the feed's own amount was taken from this container",
+		Request:     pumping.ConsumeRequest{},
+		Responses:   []response{ok(pumping.Item{})},
+		Errors:      []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/milk-stash/:itemId/thaw",
+		Summary:   "Move frozen milk to the fridge",
+		Responses: []response{ok(pumping.Item{})},
+		Errors:    []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/pumping",
+		Summary:   "Pumping sessions, newest first; `from` and `to` are RFC 3339",
+		Query:     []string{"from", "to"},
+		Responses: []response{ok([]pumping.Session{})},
+		Errors:    []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/pumping",
+		Summary:     "Log a pumping session",
+		Description: "Log a session: `start_time`, and optionally `duration_minutes`, `side` (`left`, `right` or `both`, the default), `amount` with `unit`, `notes`, and `store` (`fridge` or `freezer`) to put the amount in the stash",
+		Request:     pumping.CreateSessionRequest{},
+		Responses:   []response{created(pumping.Session{})},
+		Errors:      []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/pumping/start",
+		Summary:     "Start a pumping session",
+		Description: "Start timing a session now, with optional `side` and `notes`; 409 if you already have one running",
+		Request:     pumping.StartSessionRequest{},
+		Responses:   []response{created(pumping.Session{})},
+		Errors:      []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/families/:familyId/pumping/:sessionId",
+		Summary:   "Delete a session; milk stored from it stays in the stash",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "POST", Path: "/api/families/:familyId/pumping/:sessionId/stop",
+		Summary:     "Stop a pumping session",
+		Description: "Stop a running session, filling in `duration_minutes`, with optional `amount`, `unit` and `store` as above; stopping one that has already stopped returns it unchanged",
+		Request:     pumping.StopSessionRequest{},
+		Responses:   []response{ok(pumping.Session{})},
+		Errors:      []int{400, 403, 404, 409, 422, 500},
+	},
+}
+
+var sleepRoutes = []route{
+	{
+		Method: "GET", Path: "/api/sleep",
+		Summary:   "List sleep records",
+		Query:     []string{"child_id"},
+		Responses: []response{ok([]sleep.Sleep{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/sleep",
+		Summary:   "Start sleep session",
+		Request:   sleep.CreateSleepRequest{},
+		Responses: []response{created(sleep.Sleep{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/sleep/active/:childId",
+		Summary:   "The child's running timer, if any",
+		Responses: []response{ok(nil, sleep.Sleep{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "GET", Path: "/api/sleep/conflicts",
+		Summary:     "Overlapping sleep records",
+		Description: "Groups of overlapping records (e.g. monitor epochs and a manual log) and which one would be kept; defaults to the last 7 days",
+		Query:       []string{"child_id", "from", "to"},
+		Responses:   []response{ok([]sleep.Conflict{})},
+		Errors:      []int{400, 500},
+	},
+	{
+		Method: "POST", Path: "/api/sleep/merge",
+		Summary:   "Join two back-to-back sessions: `{\"ids\":[\"...\",\"...\"]}`",
+		Request:   sleep.MergeRequest{},
+		Responses: []response{ok(sleep.EditResult{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/sleep/reconcile",
+		Summary:     "Merge overlapping sleep records",
+		Description: "Merge conflicts for `child_id` in the window: the record whose source ranks highest in `sleep.source_priority` is kept and picks up missing quality and notes, the rest are deleted",
+		Request:     sleep.ReconcileRequest{},
+		Responses:   []response{ok(sleep.ReconcileResult{})},
+		Errors:      []int{400, 500},
+	},
+	{
+		Method: "POST", Path: "/api/sleep/start",
+		Summary: "Start a timer for `child_id`; the record notes who started it in `started_by`",
+		Request: struct {
+			ChildID string          `json:"child_id"`
+			Type    sleep.SleepType `json:"type"`
+		}{},
+		Responses: []response{created(sleep.Sleep{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "GET", Path: "/api/sleep/:id",
+		Summary:   "Get a sleep",
+		Responses: []response{ok(sleep.Sleep{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/sleep/:id",
+		Summary:   "Update/end sleep",
+		Request:   sleep.CreateSleepRequest{},
+		Responses: []response{ok(sleep.Sleep{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/sleep/:id",
+		Summary:   "Delete sleep record",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/sleep/:id/end",
+		Summary:     "End a sleep timer",
+		Description: "End a timer. Any member of the child's family may end it, so a nap started on one phone can be stopped from another; the member who started it receives a `sleep_ended` event. Ending a timer that has already ended returns it unchanged",
+		Responses:   []response{ok(sleep.Sleep{})},
+		Errors:      []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/sleep/:id/private",
+		Summary:     "Mark sleep private to the member who started it, or shared again (that member only): `{\"private\": true}`",
+		Description: "Other members' private sleep is left out of lists, the activity feed and exports, and reads as not found",
+		Request:     sleep.SetPrivateRequest{},
+		Responses:   []response{respond(200)},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/sleep/:id/split",
+		Summary:   "Cut a session in two at `at`, e.g. a night waking logged as part of the night",
+		Request:   sleep.SplitRequest{},
+		Responses: []response{ok(sleep.EditResult{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/sleep/:id/wakings",
+		Summary:     "Log a night waking",
+		Description: "Log a night waking on a running session: `{\"start_time\":\"...\",\"end_time\":\"...\",\"reason\":\"hungry\"}`; both times are optional, with the start defaulting to now and no end while the child is still awake",
+		Request:     sleep.WakingRequest{},
+		Responses:   []response{created(sleep.Sleep{})},
+		Errors:      []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/sleep/:id/wakings/:wakingId",
+		Summary:   "Remove a waking logged by mistake",
+		Responses: []response{ok(sleep.Sleep{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+	{
+		Method: "POST", Path: "/api/sleep/:id/wakings/:wakingId/end",
+		Summary:   "Mark the child as back asleep",
+		Responses: []response{ok(sleep.Sleep{})},
+		Errors:    []int{400, 403, 404, 409, 500},
+	},
+}
+
+var medicationRoutes = []route{
+	{
+		Method: "GET", Path: "/api/medications",
+		Summary:   "List medications, or only the changes since a time",
+		Query:     []string{"child_id", "active_only", "since"},
+		Responses: []response{ok(delta.Changes[medication.Medication]{}, []medication.Medication{})},
+		Errors:    []int{400, 403, 404, 410, 500},
+	},
+	{
+		Method: "POST", Path: "/api/medications",
+		Summary:   "Create medication",
+		Request:   medication.CreateMedicationRequest{},
+		Responses: []response{created(medication.Medication{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/medications/ingredients",
+		Summary: "The active ingredients a medication name normalizes to, with their RxNorm codes",
+		Query:   []string{"name"},
+		Responses: []response{ok(struct {
+			Name        string                  `json:"name"`
+			Ingredients []medication.Ingredient `json:"ingredients"`
+		}{})},
+		Errors: []int{400},
+	},
+	{
+		Method: "POST", Path: "/api/medications/log",
+		Summary:   "Log a dose",
+		Request:   medication.LogMedicationRequest{},
+		Responses: []response{created(medication.MedicationLog{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/medications/:id",
+		Summary:   "Get a medication",
+		Responses: []response{ok(medication.Medication{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/medications/:id",
+		Summary:   "Update medication",
+		Request:   medication.CreateMedicationRequest{},
+		Responses: []response{ok(medication.Medication{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/medications/:id",
+		Summary:   "Delete medication",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/medications/:id/deactivate",
+		Summary:   "Deactivate medication",
+		Responses: []response{respond(200)},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/medications/:id/logs",
+		Summary:   "Get dose history",
+		Responses: []response{ok([]medication.MedicationLog{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/medications/:id/logs/last",
+		Summary:   "Get the last dose given",
+		Responses: []response{ok(medication.MedicationLog{}, nil)},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/medications/:id/logs/:logId/corrections",
+		Summary:   "A dose's corrections, oldest first",
+		Responses: []response{ok([]corrections.Correction{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/medications/:id/logs/:logId/corrections",
+		Summary:   "Correct a logged dose: `{\"given_at\",\"dosage\",\"notes\",\"reason\"}`",
+		Request:   medication.CorrectLogRequest{},
+		Responses: []response{ok(medication.MedicationLog{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/medications/:id/logs/:logId/private",
+		Summary:     "Mark a dose private to the member who gave it, or shared again (that member only): `{\"private\": true}`",
+		Description: "Other members' private doses are left out of dose history, the last dose and exports",
+		Request:     medication.SetPrivateRequest{},
+		Responses:   []response{respond(200)},
+		Errors:      []int{400, 403, 404, 500},
+	},
+}
+
+var vaccinationRoutes = []route{
+	{
+		Method: "GET", Path: "/api/vaccinations",
+		Summary:   "List vaccinations, optionally by comma-separated status, or only the changes since a time",
+		Query:     []string{"status", "completed", "child_id", "upcoming_only", "since"},
+		Responses: []response{ok(delta.Changes[vaccination.Vaccination]{}, []vaccination.Vaccination{})},
+		Errors:    []int{400, 403, 404, 410, 500},
+	},
+	{
+		Method: "POST", Path: "/api/vaccinations",
+		Summary:   "Create vaccination (omit `dose` to use the next dose of that vaccine)",
+		Request:   vaccination.CreateVaccinationRequest{},
+		Responses: []response{created(vaccination.Vaccination{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/vaccinations/coverage/:childId",
+		Summary:   "Series completion, overdue doses and next eligible dates",
+		Query:     []string{"as_of"},
+		Responses: []response{ok(vaccination.CoverageReport{})},
+		Errors:    []int{400, 500},
+	},
+	{
+		Method: "POST", Path: "/api/vaccinations/generate/:childId",
+		Summary: "Create the scheduled vaccinations from a birth date",
+		Request: struct {
+			BirthDate string `json:"birth_date"`
+		}{},
+		Responses: []response{created([]vaccination.Vaccination{})},
+		Errors:    []int{400, 500},
+	},
+	{
+		Method: "GET", Path: "/api/vaccinations/prefill/:childId",
+		Summary:   "Suggested `provider`, `location` and `lot_prefix` for recording a dose",
+		Query:     []string{"name"},
+		Responses: []response{ok(vaccination.Prefill{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "GET", Path: "/api/vaccinations/refusals/:childId",
+		Summary:   "CSV of refused and contraindicated doses with reasons and exemptions",
+		Responses: []response{file(200, "text/csv"), file(200, "application/zip")},
+		Errors:    []int{400, 403, 500},
+	},
+	{
+		Method: "GET", Path: "/api/vaccinations/schedule",
+		Summary:   "The vaccination schedule",
+		Responses: []response{ok([]vaccination.VaccinationSchedule{})},
+	},
+	{
+		Method: "GET", Path: "/api/vaccinations/upcoming/:childId",
+		Summary:   "List the child's upcoming vaccinations",
+		Query:     []string{"days"},
+		Responses: []response{ok([]vaccination.Vaccination{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "GET", Path: "/api/vaccinations/:id",
+		Summary:   "Get a vaccination",
+		Responses: []response{ok(vaccination.Vaccination{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/vaccinations/:id",
+		Summary:   "Update vaccination",
+		Request:   vaccination.CreateVaccinationRequest{},
+		Responses: []response{ok(vaccination.Vaccination{}), respond(422, TimingError{})},
+		Errors:    []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/vaccinations/:id",
+		Summary:   "Delete vaccination",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/vaccinations/:id/corrections",
+		Summary:   "A dose's corrections, oldest first",
+		Responses: []response{ok([]corrections.Correction{}), respond(422, TimingError{})},
+		Errors:    []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "POST", Path: "/api/vaccinations/:id/corrections",
+		Summary:     "Correct an administered dose",
+		Description: "Correct an administered dose: the `record` fields plus optional `name` and `dose`, and a `reason`",
+		Request:     vaccination.CorrectVaccinationRequest{},
+		Responses:   []response{ok(vaccination.Vaccination{}), respond(422, TimingError{})},
+		Errors:      []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "POST", Path: "/api/vaccinations/:id/record",
+		Summary:     "Record administration",
+		Description: "Record administration (status becomes `completed`); doses given too early need `override_reason`",
+		Request:     vaccination.RecordVaccinationRequest{},
+		Responses:   []response{ok(vaccination.Vaccination{}), respond(422, TimingError{})},
+		Errors:      []int{400, 403, 404, 409, 422, 500},
+	},
+	{
+		Method: "POST", Path: "/api/vaccinations/:id/status",
+		Summary:   "Mark a dose skipped, refused or contraindicated, or reschedule it",
+		Request:   vaccination.SetStatusRequest{},
+		Responses: []response{ok(vaccination.Vaccination{}), respond(422, TimingError{})},
+		Errors:    []int{400, 403, 404, 409, 422, 500},
+	},
+}
+
+var appointmentRoutes = []route{
+	{
+		Method: "GET", Path: "/api/appointments",
+		Summary:   "List appointments",
+		Query:     []string{"child_id", "upcoming_only"},
+		Responses: []response{ok([]appointment.Appointment{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/appointments",
+		Summary:   "Create appointment",
+		Request:   appointment.CreateAppointmentRequest{},
+		Responses: []response{created(appointment.Appointment{})},
+		Errors:    []int{400, 403, 404, 409, 500, 503},
+	},
+	{
+		Method: "GET", Path: "/api/appointments/screenings/:childId",
+		Summary:     "The child's well-visit screenings",
+		Description: "Screenings at the child's checkups: each with its `well_visit`, `due_on`, `status` (`pending`, `done` or `missed`) and `appointment_id`",
+		Responses:   []response{ok([]appointment.ScreeningDue{})},
+		Errors:      []int{400, 403, 404, 409, 500, 503},
+	},
+	{
+		Method: "GET", Path: "/api/appointments/upcoming/:childId",
+		Summary:   "List the child's upcoming appointments",
+		Query:     []string{"days"},
+		Responses: []response{ok([]appointment.Appointment{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "GET", Path: "/api/appointments/well-visits/schedule",
+		Summary:   "Well-visit checkup schedule (2 weeks, 1, 2, 4, 6, 9, 12, 15, 18, 24 and 30 months, 3 years)",
+		Responses: []response{ok([]appointment.WellVisit{})},
+	},
+	{
+		Method: "GET", Path: "/api/appointments/well-visits/:childId",
+		Summary:     "The child's well-visit schedule",
+		Description: "The schedule for a child: each checkup's `due_on` date, `status` and the `appointment_id` that books it",
+		Responses:   []response{ok([]appointment.WellVisitSuggestion{})},
+		Errors:      []int{400, 403, 404, 409, 500, 503},
+	},
+	{
+		Method: "GET", Path: "/api/appointments/:id",
+		Summary:   "Get an appointment",
+		Responses: []response{ok(appointment.Appointment{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/appointments/:id",
+		Summary:   "Update appointment",
+		Request:   appointment.CreateAppointmentRequest{},
+		Responses: []response{ok(appointment.Appointment{})},
+		Errors:    []int{400, 403, 404, 409, 500, 503},
+	},
+	{
+		Method: "DELETE", Path: "/api/appointments/:id",
+		Summary:   "Delete appointment",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/appointments/:id/cancel",
+		Summary:   "Cancel an appointment",
+		Responses: []response{respond(200)},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/appointments/:id/complete",
+		Summary:   "Mark an appointment completed",
+		Responses: []response{respond(200)},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/appointments/:id/outcome",
+		Summary:     "Record a visit's outcome",
+		Description: "Record what came of a visit, with the `vaccination_ids` and `measurement_ids` taken at it; marks the appointment completed",
+		Request:     appointment.RecordOutcomeRequest{},
+		Responses:   []response{ok(appointment.Appointment{})},
+		Errors:      []int{400, 403, 404, 409, 500, 503},
+	},
+	{
+		Method: "GET", Path: "/api/families/:familyId/appointments/upcoming",
+		Summary:   "Upcoming appointments of every child in the family, soonest first, each with its `child_name`",
+		Query:     []string{"days"},
+		Responses: []response{ok([]appointment.FamilyAppointment{})},
+		Errors:    []int{400, 403, 404, 409, 500, 503},
+	},
+}
+
+var notesRoutes = []route{
+	{
+		Method: "GET", Path: "/api/notes",
+		Summary:   "List notes",
+		Query:     []string{"child_id", "pinned_only"},
+		Responses: []response{ok([]notes.Note{})},
+		Errors:    []int{403, 500},
+	},
+	{
+		Method: "POST", Path: "/api/notes",
+		Summary:   "Create note; pass `occurred_at` to backdate it (defaults to now)",
+		Request:   notes.CreateNoteRequest{},
+		Responses: []response{created(notes.Note{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/notes/search",
+		Summary:   "Search notes (?q=)",
+		Query:     []string{"child_id", "q"},
+		Responses: []response{ok([]notes.Note{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/notes/:id",
+		Summary:   "Get a note",
+		Responses: []response{ok(notes.Note{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/notes/:id",
+		Summary:   "Update note",
+		Request:   notes.UpdateNoteRequest{},
+		Responses: []response{ok(notes.Note{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/notes/:id",
+		Summary:   "Delete note",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/notes/:id/pin",
+		Summary: "Pin or unpin a note",
+		Request: struct {
+			Pinned bool `json:"pinned"`
+		}{},
+		Responses: []response{respond(200)},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/notes/:id/private",
+		Summary: "Mark a note private to its author, or shared again (author only): `{\"private\": true}`",
+		Request: struct {
+			Private bool `json:"private"`
+		}{},
+		Responses: []response{respond(200)},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/notes/:id/seen",
+		Summary:   "Mark a note as read; note responses include `seen_by`",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 404, 500},
+	},
+}
+
+var growthRoutes = []route{
+	{
+		Method: "GET", Path: "/api/admin/growth/percentiles",
+		Summary:   "The current reference version and how many measurements still need recomputing (admin token)",
+		Responses: []response{ok(growth.PercentileStatus{})},
+		Errors:    []int{500},
+	},
+	{
+		Method: "POST", Path: "/api/admin/growth/percentiles/recompute",
+		Summary:     "Recompute growth percentiles",
+		Description: "Recompute them now and return how many were updated; 409 while a recompute is already running (admin token)",
+		Responses: []response{ok(struct {
+			Reference  string `json:"reference"`
+			Recomputed int    `json:"recomputed"`
+		}{}), respond(500, struct {
+			Error      string `json:"error"`
+			Recomputed int    `json:"recomputed"`
+		}{})},
+		Errors: []int{409, 500, 503},
+	},
+	{
+		Method: "GET", Path: "/api/growth",
+		Summary:   "List growth measurements",
+		Query:     []string{"child_id"},
+		Responses: []response{ok([]growth.Measurement{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/growth",
+		Summary:   "Record weight, length and/or head circumference",
+		Request:   growth.CreateMeasurementRequest{},
+		Responses: []response{created(growth.Measurement{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/growth/:id/percentiles",
+		Summary:     "Growth chart series",
+		Description: "Growth chart series for weight, length and head circumference: the 3rd, 15th, 50th, 85th and 97th percentile lines by month, and each of the child's measurements with its age, z-score and percentile",
+		Responses:   []response{ok(growth.Chart{})},
+		Errors:      []int{403, 404, 422, 500, 503},
+	},
+	{
+		Method: "GET", Path: "/api/growth/projection/:childId",
+		Summary:     "Expected growth range on a day",
+		Description: "Expected weight and length range on a day, following the child's percentile track; pass today's clinic figures to see whether they fall inside",
+		Query:       []string{"date", "weight_kg", "length_cm"},
+		Responses:   []response{ok(growth.Projection{})},
+		Errors:      []int{400, 403, 404, 422, 500, 503},
+	},
+	{
+		Method: "GET", Path: "/api/growth/:id",
+		Summary:   "Get a measurement",
+		Responses: []response{ok(growth.Measurement{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/growth/:id",
+		Summary:   "Delete a measurement",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 404, 500},
+	},
+}
+
+var temperatureRoutes = []route{
+	{
+		Method: "GET", Path: "/api/temperature",
+		Summary:   "List temperature readings, newest first; `from` and `to` are RFC 3339",
+		Query:     []string{"child_id", "from", "to"},
+		Responses: []response{ok([]temperature.Reading{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/temperature",
+		Summary:     "Record a temperature",
+		Description: "Record a temperature: `taken_at`, `value`, `unit` (`C` or `F`), and optionally `site`, `symptoms`, `medication_id`, `medication_given` and `notes`",
+		Request:     temperature.CreateReadingRequest{},
+		Responses:   []response{created(temperature.Reading{})},
+		Errors:      []int{400, 422, 500},
+	},
+	{
+		Method: "GET", Path: "/api/temperature/episodes",
+		Summary:     "Fever episodes",
+		Description: "Fever episodes, newest first, each with its readings in time order for plotting the fever curve",
+		Query:       []string{"child_id", "from", "to"},
+		Responses:   []response{ok([]temperature.Episode{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/temperature/:id",
+		Summary:   "Get a reading",
+		Responses: []response{ok(temperature.Reading{})},
+		Errors:    []int{403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/temperature/:id",
+		Summary:   "Delete a reading",
+		Responses: []response{noContent()},
+		Errors:    []int{403, 404, 500},
+	},
+}
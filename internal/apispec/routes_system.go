@@ -0,0 +1,338 @@
+package apispec
+
+import (
+	"github.com/ninenine/babytrack/internal/auth"
+	"github.com/ninenine/babytrack/internal/authz"
+	"github.com/ninenine/babytrack/internal/deprecation"
+	"github.com/ninenine/babytrack/internal/devices"
+	"github.com/ninenine/babytrack/internal/eventschema"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/maintenance"
+	"github.com/ninenine/babytrack/internal/preferences"
+	"github.com/ninenine/babytrack/internal/quicklog"
+	"github.com/ninenine/babytrack/internal/quota"
+	"github.com/ninenine/babytrack/internal/status"
+	"github.com/ninenine/babytrack/internal/sync"
+	"github.com/ninenine/babytrack/internal/synccapture"
+)
+
+var appRoutes = []route{
+	{
+		Method: "GET", Path: "/api/health",
+		Summary: "Liveness check",
+		Responses: []response{ok(struct {
+			Status string `json:"status"`
+		}{})},
+	},
+	{
+		Method: "GET", Path: "/api/version",
+		Summary: "Server version",
+		Responses: []response{ok(struct {
+			Version string `json:"version"`
+		}{})},
+	},
+}
+
+var statusRoutes = []route{
+	{
+		Method: "GET", Path: "/api/status",
+		Summary:     "Service status",
+		Description: "Returns `status` (`ok`, `maintenance` or `outage`), the planned `maintenance` windows that have not ended yet (soonest first) and `checked_at`, so clients can show an outage banner. Results are cached for 10 seconds and each client IP is limited to 60 requests a minute (429 beyond that). Maintenance windows are set under `status.maintenance` in the config.",
+		Responses:   []response{ok(status.Status{})},
+	},
+}
+
+var eventschemaRoutes = []route{
+	{
+		Method: "GET", Path: "/api/events/schema",
+		Summary:     "Notification event schemas",
+		Description: "Every event type sent over the notification stream, with JSON schemas generated from the server's own types: `{\"event\",\"digest\",\"events\":[{\"type\",\"description\",\"routable\",\"data\"}],\"$defs\"}`",
+		Responses:   []response{ok(eventschema.Catalog{})},
+	},
+}
+
+var deprecationRoutes = []route{
+	{
+		Method: "GET", Path: "/api/deprecations",
+		Summary:     "Deprecated routes",
+		Description: "Deprecated routes in config order: `[{\"method\",\"path\",\"deprecated_at\",\"sunset\",\"replacement\",\"link\",\"message\",\"gone\"}]`",
+		Responses:   []response{ok([]deprecation.Notice{})},
+	},
+}
+
+var maintenanceRoutes = []route{
+	{
+		Method: "GET", Path: "/api/admin/maintenance",
+		Summary:   "Current maintenance mode",
+		Responses: []response{ok(maintenance.ModeResponse{})},
+	},
+	{
+		Method: "PUT", Path: "/api/admin/maintenance",
+		Summary:     "Turn maintenance mode on or off",
+		Description: "Turn it on or off: `{\"enabled\":true,\"message\":\"Upgrading the database\",\"retry_after\":300}` (`retry_after` in seconds, defaults to `maintenance.retry_after`)",
+		Request:     maintenance.SetModeRequest{},
+		Responses:   []response{ok(maintenance.ModeResponse{})},
+		Errors:      []int{400},
+	},
+}
+
+var profilingRoutes = []route{
+	{
+		Method: "GET", Path: "/api/admin/debug/pprof/",
+		Summary:   "Index of the runtime profiles",
+		Responses: []response{file(200, "text/html")},
+	},
+	{
+		Method: "GET", Path: "/api/admin/debug/pprof/cmdline",
+		Summary:   "The server's command line",
+		Responses: []response{file(200, "text/plain")},
+	},
+	{
+		Method: "GET", Path: "/api/admin/debug/pprof/profile",
+		Summary:   "CPU profile (?seconds=)",
+		Responses: []response{file(200, "application/octet-stream")},
+	},
+	{
+		Method: "GET", Path: "/api/admin/debug/pprof/symbol",
+		Summary:   "Look up program counters",
+		Responses: []response{file(200, "text/plain")},
+	},
+	{
+		Method: "POST", Path: "/api/admin/debug/pprof/symbol",
+		Summary:   "Look up program counters",
+		Responses: []response{file(200, "text/plain")},
+	},
+	{
+		Method: "GET", Path: "/api/admin/debug/pprof/trace",
+		Summary:   "Execution trace (?seconds=)",
+		Responses: []response{file(200, "application/octet-stream")},
+	},
+	{
+		Method: "GET", Path: "/api/admin/debug/pprof/:profile",
+		Summary:   "Named profile: heap, goroutine, allocs, block, mutex or threadcreate",
+		Responses: []response{file(200, "*/*"), file(404, "text/plain")},
+	},
+}
+
+var synccaptureRoutes = []route{
+	{
+		Method: "GET", Path: "/api/admin/sync-captures/:userId",
+		Summary:     "A user's sync captures",
+		Description: "Support's view: the user's capture status and their last 100 sync requests with responses, newest first (admin token)",
+		Responses:   []response{ok(synccapture.Captured{})},
+	},
+	{
+		Method: "DELETE", Path: "/api/admin/sync-captures/:userId",
+		Summary:   "Drop the user's captured requests (admin token)",
+		Responses: []response{noContent()},
+	},
+	{
+		Method: "GET", Path: "/api/sync/debug",
+		Summary:   "Whether sync debug capture is on for you, and `until` when",
+		Responses: []response{ok(synccapture.Status{})},
+	},
+	{
+		Method: "PUT", Path: "/api/sync/debug",
+		Summary:   "Turn it on or off: `{\"enabled\":true}`",
+		Request:   synccapture.SetRequest{},
+		Responses: []response{ok(synccapture.Status{})},
+		Errors:    []int{400},
+	},
+}
+
+var authRoutes = []route{
+	{
+		Method: "GET", Path: "/api/auth/google",
+		Summary:   "Start Google sign-in",
+		Query:     []string{"client"},
+		Responses: []response{redirect()},
+	},
+	{
+		Method: "GET", Path: "/api/auth/google/callback",
+		Summary:   "Finish Google sign-in and redirect to the app with tokens",
+		Query:     []string{"code", "state", "error"},
+		Responses: []response{redirect()},
+	},
+	{
+		Method: "GET", Path: "/api/auth/me",
+		Summary: "Get current user, with today's API `usage` when quotas are enabled",
+		Query:   []string{"token"},
+		Responses: []response{ok(struct {
+			*auth.User
+			Usage *quota.Usage `json:"usage,omitempty"`
+		}{})},
+		Errors: []int{401},
+	},
+	{
+		Method: "POST", Path: "/api/auth/refresh",
+		Summary:   "Exchange a refresh token for new tokens",
+		Query:     []string{"token"},
+		Responses: []response{ok(auth.AuthResponse{})},
+		Errors:    []int{401},
+	},
+}
+
+var authzRoutes = []route{
+	{
+		Method: "POST", Path: "/api/authz/check",
+		Summary:     "Check permissions in bulk",
+		Description: "What you may do: `{\"checks\":[{\"action\":\"delete\",\"resource\":\"family:<id>\"},...]}`, up to 100, answered in order with `allowed` and, when denied, a `reason`",
+		Request:     authz.CheckRequest{},
+		Responses:   []response{ok(authz.CheckResponse{})},
+		Errors:      []int{400, 500},
+	},
+}
+
+var devicesRoutes = []route{
+	{
+		Method: "GET", Path: "/api/devices",
+		Summary:   "List devices bound to a child",
+		Query:     []string{"child_id"},
+		Responses: []response{ok([]devices.Device{})},
+		Errors:    []int{400, 401, 403, 404, 413, 500},
+	},
+	{
+		Method: "POST", Path: "/api/devices",
+		Summary:   "Register a `smart_scale` or `sleep_monitor` for a child; the API key is only returned here",
+		Request:   devices.RegisterDeviceRequest{},
+		Responses: []response{created(devices.Device{})},
+		Errors:    []int{400, 401, 403, 404, 413, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/devices/:id",
+		Summary:   "Remove a device",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 401, 403, 404, 413, 500},
+	},
+	{
+		Method: "POST", Path: "/api/devices/:id/measurements",
+		Summary:     "Push a device measurement",
+		Description: "Device push, authenticated with the `X-Device-Key` header. Scales send `{\"kind\":\"weight\",\"weight_kg\":5.4}` readings, monitors send `{\"kind\":\"sleep_epoch\",\"start_time\":...,\"end_time\":...,\"night\":true}`",
+		Request:     devices.PushMeasurementsRequest{},
+		Responses:   []response{created(devices.PushMeasurementsResponse{})},
+		Errors:      []int{400, 401, 403, 404, 413, 500},
+	},
+}
+
+var quicklogRoutes = []route{
+	{
+		Method: "POST", Path: "/api/quicklog",
+		Summary:     "Quick-log an entry",
+		Description: "Log one entry as `{\"child\":\"Emma\",\"type\":\"bottle\",\"value\":120}`, authenticated with the `X-Api-Key` header or `Authorization: Bearer <key>`",
+		Request:     quicklog.Request{},
+		Responses:   []response{created(quicklog.Result{})},
+		Errors:      []int{400, 401, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/quicklog/defaults",
+		Summary:   "Suggested values for quick logging, from the last 14 days of records",
+		Query:     []string{"child_id"},
+		Responses: []response{ok(quicklog.Defaults{})},
+		Errors:    []int{400, 401, 403, 404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/quicklog/keys",
+		Summary:   "List your personal API keys",
+		Responses: []response{ok([]quicklog.APIKey{})},
+		Errors:    []int{400, 401, 403, 404, 500},
+	},
+	{
+		Method: "POST", Path: "/api/quicklog/keys",
+		Summary:   "Create a key (`name`); the key is only returned here",
+		Request:   quicklog.CreateKeyRequest{},
+		Responses: []response{created(quicklog.APIKey{})},
+		Errors:    []int{400, 401, 403, 404, 500},
+	},
+	{
+		Method: "DELETE", Path: "/api/quicklog/keys/:id",
+		Summary:   "Revoke a key",
+		Responses: []response{noContent()},
+		Errors:    []int{400, 401, 403, 404, 500},
+	},
+}
+
+var syncRoutes = []route{
+	{
+		Method: "GET", Path: "/api/sync/conflicts",
+		Summary:     "Your unresolved sync conflicts",
+		Description: "Your unresolved conflicts, newest first: the losing `client_data`, the winning `server_data`, and when each was saved",
+		Responses:   []response{ok([]sync.Conflict{})},
+		Errors:      []int{500},
+	},
+	{
+		Method: "POST", Path: "/api/sync/conflicts/:id/resolve",
+		Summary:     "Resolve a sync conflict",
+		Description: "Settle a conflict: `{\"keep\":\"client\"}` restores your edit over the current record, `{\"keep\":\"server\"}` keeps the record as it is",
+		Request:     sync.ResolveConflictRequest{},
+		Responses:   []response{ok(sync.Conflict{})},
+		Errors:      []int{400, 404, 409, 500},
+	},
+	{
+		Method: "GET", Path: "/api/sync/devices",
+		Summary:     "Your sync devices",
+		Description: "Your sync clients with their checkpoint: `device_id`, `last_token`, `last_seen_at` and whether a reset is pending",
+		Responses:   []response{ok([]sync.DeviceState{})},
+		Errors:      []int{500},
+	},
+	{
+		Method: "POST", Path: "/api/sync/devices/:deviceId/reset",
+		Summary:   "Reset one client's sync state",
+		Responses: []response{ok(sync.DeviceState{})},
+		Errors:    []int{404, 500},
+	},
+	{
+		Method: "GET", Path: "/api/sync/pull",
+		Summary:   "Records deleted since `last_sync` (RFC 3339), as `delete` events",
+		Query:     []string{"last_sync", "client_id"},
+		Responses: []response{ok(sync.PullResponse{})},
+		Errors:    []int{400, 500},
+	},
+	{
+		Method: "POST", Path: "/api/sync/push",
+		Summary:   "Push offline changes",
+		Request:   sync.PushRequest{},
+		Responses: []response{ok(sync.PushResponse{})},
+		Errors:    []int{400, 413, 500, 503},
+	},
+	{
+		Method: "GET", Path: "/api/sync/status",
+		Summary:   "Sync status of the current device",
+		Query:     []string{"client_id"},
+		Responses: []response{ok(sync.SyncStatus{})},
+		Errors:    []int{500},
+	},
+}
+
+var notificationsRoutes = []route{
+	{
+		Method: "GET", Path: "/api/notifications/stream",
+		Summary:   "Server-sent event stream of notifications",
+		Responses: []response{file(200, "text/event-stream")},
+		Errors:    []int{401},
+	},
+}
+
+var preferencesRoutes = []route{
+	{
+		Method: "GET", Path: "/api/notifications/preferences",
+		Summary:   "Your quiet hours, channels and muted children",
+		Responses: []response{ok(preferences.Preferences{})},
+		Errors:    []int{400, 403, 404, 500},
+	},
+	{
+		Method: "PUT", Path: "/api/notifications/preferences",
+		Summary:     "Replace your notification preferences",
+		Description: "Replace them: `quiet_hours` (`{\"start\":\"22:00\",\"end\":\"07:00\"}`, may wrap midnight), `timezone` (IANA, default `UTC`), `channels` (event type to `push`, `email` or `none`) and `muted_children` (child IDs)",
+		Request:     preferences.UpdatePreferencesRequest{},
+		Responses:   []response{ok(preferences.Preferences{})},
+		Errors:      []int{400, 403, 404, 500},
+	},
+}
+
+var healthRoutes = []route{
+	{
+		Method: "GET", Path: "/readyz",
+		Summary:   "Readiness of each module; 503 while any is not ready",
+		Responses: []response{ok(health.Report{}), respond(503, health.Report{})},
+	},
+}
@@ -2,24 +2,60 @@ package app
 
 import (
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Server        ServerConfig        `yaml:"server"`
-	Database      DatabaseConfig      `yaml:"database"`
-	Auth          AuthConfig          `yaml:"auth"`
-	Notifications NotificationsConfig `yaml:"notifications"`
+	Server         ServerConfig         `yaml:"server"`
+	Database       DatabaseConfig       `yaml:"database"`
+	Auth           AuthConfig           `yaml:"auth"`
+	Notifications  NotificationsConfig  `yaml:"notifications"`
+	Media          MediaConfig          `yaml:"media"`
+	Feeding        FeedingConfig        `yaml:"feeding"`
+	Sleep          SleepConfig          `yaml:"sleep"`
+	Mail           MailConfig           `yaml:"mail"`
+	Status         StatusConfig         `yaml:"status"`
+	Maintenance    MaintenanceConfig    `yaml:"maintenance"`
+	Admin          AdminConfig          `yaml:"admin"`
+	Quotas         QuotasConfig         `yaml:"quotas"`
+	Sandbox        SandboxConfig        `yaml:"sandbox"`
+	Sync           SyncConfig           `yaml:"sync"`
+	Deprecation    DeprecationConfig    `yaml:"deprecation"`
+	Logging        LoggingConfig        `yaml:"logging"`
+	ErrorReporting ErrorReportingConfig `yaml:"error_reporting"`
+	Limits         LimitsConfig         `yaml:"limits"`
 }
 
 type ServerConfig struct {
 	Port    int    `yaml:"port"`
 	BaseURL string `yaml:"base_url"`
+	// AssetBaseURL is a CDN origin that pulls from this server, e.g.
+	// https://cdn.example.com. Pages then load their content-hashed assets
+	// from it. Empty serves them directly.
+	AssetBaseURL string `yaml:"asset_base_url"`
 }
 
 type DatabaseConfig struct {
-	DSN string `yaml:"dsn"`
+	DSN       string             `yaml:"dsn"`
+	SlowQuery SlowQueryLogConfig `yaml:"slow_query"`
+	// EncryptionKey seals sensitive fields before they are stored, 32 bytes
+	// base64 encoded. Empty disables family insurance and pharmacy details.
+	EncryptionKey string `yaml:"encryption_key"`
+	// ArchiveAfter is the age, rounded down to whole months, at which
+	// feedings and sleep move to the archive tables. Empty uses the archive
+	// package default.
+	ArchiveAfter time.Duration `yaml:"archive_after"`
+}
+
+type SlowQueryLogConfig struct {
+	// Threshold logs queries that take longer, e.g. 500ms. Empty disables
+	// the slow query log.
+	Threshold time.Duration `yaml:"threshold"`
+	// ExplainSampleRate is the share of slow queries, 0 to 1, logged with
+	// their EXPLAIN plan
+	ExplainSampleRate float64 `yaml:"explain_sample_rate"`
 }
 
 type AuthConfig struct {
@@ -30,6 +66,148 @@ type AuthConfig struct {
 
 type NotificationsConfig struct {
 	Enabled bool `yaml:"enabled"`
+	// Digest holds events of a type for a window, e.g. sleep_insight: 10m,
+	// and sends a burst as one summary. Types not listed are sent at once.
+	Digest map[string]time.Duration `yaml:"digest"`
+}
+
+type MediaConfig struct {
+	MaxUploadMB int    `yaml:"max_upload_mb"`
+	ClamAVAddr  string `yaml:"clamav_addr"` // Empty disables malware scanning
+}
+
+type FeedingConfig struct {
+	// FormulaGuideline picks the published guideline behind the formula
+	// helper, "aap" or "nhs". Empty uses the feeding package default.
+	FormulaGuideline string `yaml:"formula_guideline"`
+}
+
+type SleepConfig struct {
+	// SourcePriority decides which of two overlapping records survives a
+	// reconcile, e.g. [manual, device]. Empty uses the sleep package default.
+	SourcePriority []string `yaml:"source_priority"`
+}
+
+type MailConfig struct {
+	// InboundDomain is the domain of the family inbound addresses. Empty
+	// disables email-to-note.
+	InboundDomain string `yaml:"inbound_domain"`
+	// Maildir is where the mail server delivers inbound messages
+	Maildir string `yaml:"maildir"`
+}
+
+type StatusConfig struct {
+	// Maintenance lists planned maintenance windows shown on the status
+	// endpoint until they end
+	Maintenance []MaintenanceWindowConfig `yaml:"maintenance"`
+}
+
+type MaintenanceWindowConfig struct {
+	Title    string    `yaml:"title"`
+	Message  string    `yaml:"message"`
+	StartsAt time.Time `yaml:"starts_at"`
+	EndsAt   time.Time `yaml:"ends_at"`
+}
+
+type MaintenanceConfig struct {
+	// Enabled starts the server in maintenance mode, refusing writes with 503
+	Enabled bool   `yaml:"enabled"`
+	Message string `yaml:"message"`
+	// RetryAfter is the backoff hint sent to clients, 5m when empty
+	RetryAfter time.Duration `yaml:"retry_after"`
+}
+
+type AdminConfig struct {
+	// Token authenticates operator endpoints. Empty disables them.
+	Token string `yaml:"token"`
+	// Pprof serves Go runtime profiles under /api/admin/debug/pprof
+	Pprof bool `yaml:"pprof"`
+}
+
+type QuotasConfig struct {
+	// Plans maps plan names to the daily request limit of each user and of
+	// each of their API keys, 0 for unlimited. Empty disables quotas.
+	Plans map[string]int `yaml:"plans"`
+	// DefaultPlan applies to users without a plan or with one not listed
+	DefaultPlan string `yaml:"default_plan"`
+}
+
+type SandboxConfig struct {
+	// ResetInterval is how often sandbox families' records are wiped. Empty
+	// uses the sandbox package default.
+	ResetInterval time.Duration `yaml:"reset_interval"`
+}
+
+type SyncConfig struct {
+	// TombstoneRetention is how long deletions are kept for sync pulls and
+	// ?since= lists. Empty uses the delta package default.
+	TombstoneRetention time.Duration `yaml:"tombstone_retention"`
+}
+
+type DeprecationConfig struct {
+	// MinNotice is the shortest time from a route's deprecation to its
+	// sunset. Empty uses the deprecation package default.
+	MinNotice time.Duration `yaml:"min_notice"`
+	// RetireAfterSunset answers calls to a route with 410 Gone once its
+	// sunset has passed
+	RetireAfterSunset bool `yaml:"retire_after_sunset"`
+	// Routes lists the deprecated endpoints, as the router names them
+	Routes []DeprecatedRouteConfig `yaml:"routes"`
+}
+
+type DeprecatedRouteConfig struct {
+	Method       string     `yaml:"method"`
+	Path         string     `yaml:"path"` // e.g. /api/feeding/:id
+	DeprecatedAt time.Time  `yaml:"deprecated_at"`
+	Sunset       *time.Time `yaml:"sunset"`
+	Replacement  string     `yaml:"replacement"` // e.g. GET /api/children/:id/active
+	Link         string     `yaml:"link"`
+	Message      string     `yaml:"message"`
+}
+
+type LoggingConfig struct {
+	// Verbose logs the JSON request and response bodies of API calls,
+	// redacted, for tracing problems in production
+	Verbose bool         `yaml:"verbose"`
+	Redact  RedactConfig `yaml:"redact"`
+}
+
+// RedactConfig extends what is masked in logs and error reports. Emails,
+// tokens, note content and medical fields are always masked.
+type RedactConfig struct {
+	// Keys are JSON fields and query parameters to mask, e.g. [name]
+	Keys []string `yaml:"keys"`
+	// Allow keeps fields that are masked by default, e.g. [dosage]
+	Allow []string `yaml:"allow"`
+	// Patterns are regular expressions to mask wherever they appear
+	Patterns []string `yaml:"patterns"`
+}
+
+type ErrorReportingConfig struct {
+	// DSN is a Sentry-compatible project DSN. Empty disables error reporting.
+	DSN         string `yaml:"dsn"`
+	Environment string `yaml:"environment"`
+	// SampleRate is the share of 5xx responses reported, 0 to 1. Empty
+	// reports them all.
+	SampleRate *float64 `yaml:"sample_rate"`
+	// PanicSampleRate is the share of panics reported, 0 to 1. Empty reports
+	// them all.
+	PanicSampleRate *float64 `yaml:"panic_sample_rate"`
+	// UserSalt keys the hash that stands in for user IDs in reports
+	UserSalt string `yaml:"user_salt"`
+}
+
+// LimitsConfig caps what one request may carry. Empty uses the limits
+// package defaults.
+type LimitsConfig struct {
+	TagsPerNote          int `yaml:"tags_per_note"`
+	BulkItems            int `yaml:"bulk_items"`
+	ChildrenPerRequest   int `yaml:"children_per_request"`
+	AttachmentsPerRecord int `yaml:"attachments_per_record"`
+	// NameLength, in characters, must stay within the 255 character title
+	// columns
+	NameLength int `yaml:"name_length"`
+	TextLength int `yaml:"text_length"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -1,25 +1,48 @@
 package app
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"io"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/ninenine/babytrack/internal/audit"
 	"github.com/ninenine/babytrack/internal/auth"
+	"github.com/ninenine/babytrack/internal/envelope"
+	"github.com/ninenine/babytrack/internal/export"
+	"github.com/ninenine/babytrack/internal/ids"
 
 	"github.com/gin-gonic/gin"
 )
 
 func (s *Server) setupMiddleware() {
-	s.router.Use(gin.Recovery())
+	s.router.Use(requestID())
+	s.router.Use(s.recovery())
+	if s.errorReporter != nil {
+		s.router.Use(s.errorReporter.Middleware())
+	}
 	s.router.Use(s.corsMiddleware())
 	s.router.Use(s.requestLogger())
+	if s.cfg.Logging.Verbose {
+		s.router.Use(s.bodyLogger())
+	}
 }
 
 func (s *Server) corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, "+envelope.HeaderEnvelope+", "+export.HeaderPassphrase+", "+headerRequestID)
+		c.Header("Access-Control-Expose-Headers", headerRequestID)
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -30,8 +53,212 @@ func (s *Server) corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// headerRequestID carries the ID that ties a response to its log lines and
+// error reports. Clients may send their own.
+const headerRequestID = "X-Request-ID"
+
+// requestID takes the client's request ID when it is a sensible one and
+// makes one up otherwise
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(headerRequestID)
+		if !validRequestID(id) {
+			id = ids.New()
+		}
+		c.Set("request_id", id)
+		c.Header(headerRequestID, id)
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// recovery answers a panicking request with 500 and an incident ID the user
+// can quote to support. The stack is logged under that ID and reported to
+// the error tracker. Requests whose client has gone are just dropped.
+func (s *Server) recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// The handler may swap in a buffering writer, e.g. the envelope's;
+		// the error goes around it
+		w := c.Writer
+
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if err, ok := p.(error); ok && (errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) ||
+				errors.Is(err, http.ErrAbortHandler)) {
+				c.Abort()
+				return
+			}
+
+			incident := newIncidentID()
+			log.Printf("[panic] incident %s, request %s, %s %s: %v\n%s",
+				incident, c.GetString("request_id"), c.Request.Method, c.Request.URL.Path, p, debug.Stack())
+			if s.errorReporter != nil {
+				s.errorReporter.CapturePanic(c, p, incident)
+			}
+
+			c.Writer = w
+			if w.Written() {
+				c.Abort()
+				return
+			}
+			body := gin.H{
+				"error":       "something went wrong; quote incident " + incident + " to support",
+				"incident_id": incident,
+			}
+			if envelope.Requested(c.Request) {
+				body["meta"] = envelope.MetaFor(c)
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, body)
+		}()
+
+		c.Next()
+	}
+}
+
+// newIncidentID is short enough to read out over the phone, e.g. INC-3F9A0C7E
+func newIncidentID() string {
+	b := make([]byte, 4)
+	rand.Read(b) //nolint:errcheck // crypto/rand.Read never fails
+	return "INC-" + strings.ToUpper(hex.EncodeToString(b))
+}
+
 func (s *Server) requestLogger() gin.HandlerFunc {
-	return gin.Logger()
+	return gin.LoggerWithWriter(s.redactor.Writer(gin.DefaultWriter))
+}
+
+// maxLoggedBody is the largest body the verbose log includes
+const maxLoggedBody = 4 << 10
+
+// bodyLogger logs the JSON request and response bodies of API calls, with
+// masked fields redacted. Other bodies, such as uploads, CSV and event
+// streams, are logged by size only.
+func (s *Server) bodyLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.HasPrefix(c.Request.URL.Path, "/api/") {
+			c.Next()
+			return
+		}
+
+		var request []byte
+		size := int(c.Request.ContentLength)
+		if c.Request.Body != nil && strings.HasPrefix(c.ContentType(), "application/json") {
+			request, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedBody+1)) //nolint:errcheck // The handler sees the same error
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(request), c.Request.Body), c.Request.Body}
+		}
+
+		w := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		log.Printf("[http] %s %s %d id=%s request=%s response=%s", c.Request.Method, s.redactor.String(c.Request.URL.RequestURI()), w.Status(), c.GetString("request_id"),
+			s.loggedBody(request, max(size, len(request))), s.loggedBody(w.body.Bytes(), w.Size()))
+	}
+}
+
+// loggedBody is a body as the verbose log shows it
+func (s *Server) loggedBody(body []byte, size int) string {
+	switch {
+	case size <= 0:
+		return "-"
+	case size > maxLoggedBody:
+		return "(" + strconv.Itoa(size) + " bytes)"
+	}
+	if redacted, ok := s.redactor.JSON(body); ok {
+		return string(redacted)
+	}
+	return "(" + strconv.Itoa(size) + " bytes)"
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyRecorder keeps the start of a JSON response for the verbose log, and
+// passes everything on as written
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) record(n int) bool {
+	return w.body.Len()+n <= maxLoggedBody+1 && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	if w.record(len(data)) {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	if w.record(len(s)) {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// rateLimit allows each client IP limit requests per window on the routes it
+// guards. It is meant for public endpoints; counts are kept in memory and are
+// per server instance.
+func rateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	type bucket struct {
+		start time.Time
+		count int
+	}
+
+	var mu sync.Mutex
+	buckets := make(map[string]*bucket)
+	lastSweep := time.Now()
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		if now.Sub(lastSweep) > window {
+			for k, b := range buckets {
+				if now.Sub(b.start) >= window {
+					delete(buckets, k)
+				}
+			}
+			lastSweep = now
+		}
+
+		b, ok := buckets[ip]
+		if !ok || now.Sub(b.start) >= window {
+			b = &bucket{start: now}
+			buckets[ip] = b
+		}
+		b.count++
+		allowed := b.count <= limit
+		retryAfter := b.start.Add(window).Sub(now)
+		mu.Unlock()
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.AbortWithStatusJSON(429, gin.H{"error": "too many requests"})
+			return
+		}
+
+		c.Next()
+	}
 }
 
 func (s *Server) authMiddleware() gin.HandlerFunc {
@@ -56,11 +283,22 @@ func (s *Server) authMiddleware() gin.HandlerFunc {
 		c.Set("user_id", user.ID)
 		c.Set("user_email", user.Email)
 		c.Set("user", user)
+		// Record versions written by this request are attributed to the user
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), user.ID))
 
 		c.Next()
 	}
 }
 
+// quotaMiddleware charges authenticated requests to the caller's daily quota.
+// It is empty when quotas are disabled.
+func (s *Server) quotaMiddleware() []gin.HandlerFunc {
+	if s.quotaHandler == nil {
+		return nil
+	}
+	return []gin.HandlerFunc{s.quotaHandler.Enforce()}
+}
+
 func extractToken(c *gin.Context) string {
 	// Try Authorization header first
 	authHeader := c.GetHeader("Authorization")
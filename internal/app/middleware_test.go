@@ -1,15 +1,23 @@
 package app
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/ninenine/babytrack/internal/auth"
+	"github.com/ninenine/babytrack/internal/envelope"
+	"github.com/ninenine/babytrack/internal/redact"
 )
 
 func init() {
@@ -254,6 +262,39 @@ func TestCORSMiddleware_AllowOrigin(t *testing.T) {
 	}
 }
 
+func TestRateLimit(t *testing.T) {
+	router := gin.New()
+	router.GET("/test", rateLimit(2, time.Minute), func(c *gin.Context) {
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", http.NoBody)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := send("10.0.0.1:1234"); w.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected status 200, got %d", i+1, w.Code)
+		}
+	}
+
+	w := send("10.0.0.1:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429 over the limit, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After to be set")
+	}
+
+	if w := send("10.0.0.2:1234"); w.Code != http.StatusOK {
+		t.Errorf("Expected another client to be unaffected, got %d", w.Code)
+	}
+}
+
 func TestExtractToken_BearerHeader(t *testing.T) {
 	router := gin.New()
 	var extractedToken string
@@ -366,3 +407,140 @@ func TestExtractToken_InvalidAuthHeaderFormat(t *testing.T) {
 		t.Errorf("Expected empty token for Basic auth, got %s", extractedToken)
 	}
 }
+
+func TestBodyLogger(t *testing.T) {
+	redactor, err := redact.New(redact.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &Server{cfg: &Config{}, router: gin.New(), redactor: redactor}
+	server.router.Use(server.bodyLogger())
+	server.router.POST("/api/notes", func(c *gin.Context) {
+		var req map[string]any
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": "n1", "title": req["title"], "content": req["content"]})
+	})
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/notes?token=secret", strings.NewReader(`{"title":"Clinic","content":"Fever 38.5"}`))
+	req.Header.Set("Content-Type", "application/json")
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), "Fever 38.5") {
+		t.Fatalf("handler did not see the request body: %d %s", w.Code, w.Body.String())
+	}
+	line := logged.String()
+	if strings.Contains(line, "Fever") || strings.Contains(line, "secret") {
+		t.Errorf("log line was not redacted: %s", line)
+	}
+	for _, want := range []string{"POST /api/notes", "201", `request={"content":"[redacted]","title":"Clinic"}`, `"id":"n1"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log line %q lacks %q", line, want)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	router := gin.New()
+	router.Use(requestID())
+	router.GET("/api/health", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("request_id"))
+	})
+
+	tests := []struct {
+		name string
+		sent string
+		kept bool
+	}{
+		{"client ID", "app-7f3a.42", true},
+		{"none", "", false},
+		{"unsafe characters", "id\nwith newline", false},
+		{"too long", strings.Repeat("a", 129), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/health", nil)
+			if tt.sent != "" {
+				req.Header.Set(headerRequestID, tt.sent)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get(headerRequestID)
+			if got == "" || got != w.Body.String() {
+				t.Fatalf("header %q, context %q", got, w.Body.String())
+			}
+			if (got == tt.sent) != tt.kept {
+				t.Errorf("request ID = %q, sent %q", got, tt.sent)
+			}
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	redactor, err := redact.New(redact.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &Server{cfg: &Config{}, router: gin.New(), redactor: redactor}
+	server.router.Use(requestID(), server.recovery())
+	api := server.router.Group("/api", envelope.Middleware())
+	api.GET("/boom", func(c *gin.Context) {
+		envelope.Warn(c, "clock adjusted")
+		panic("boom")
+	})
+	api.GET("/gone", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	for _, enveloped := range []bool{false, true} {
+		logged.Reset()
+		req := httptest.NewRequest("GET", "/api/boom", nil)
+		req.Header.Set(headerRequestID, "req-1")
+		if enveloped {
+			req.Header.Set(envelope.HeaderEnvelope, "true")
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("envelope=%v: status = %d", enveloped, w.Code)
+		}
+		var resp struct {
+			Error      string         `json:"error"`
+			IncidentID string         `json:"incident_id"`
+			Meta       *envelope.Meta `json:"meta"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("envelope=%v: body %q: %v", enveloped, w.Body.String(), err)
+		}
+		if len(resp.IncidentID) != len("INC-3F9A0C7E") || !strings.HasPrefix(resp.IncidentID, "INC-") || !strings.Contains(resp.Error, resp.IncidentID) {
+			t.Errorf("envelope=%v: body = %s", enveloped, w.Body.String())
+		}
+		if enveloped != (resp.Meta != nil) || (enveloped && resp.Meta.Warnings[0] != "clock adjusted") {
+			t.Errorf("envelope=%v: meta = %+v", enveloped, resp.Meta)
+		}
+		line := logged.String()
+		if !strings.Contains(line, "[panic] incident "+resp.IncidentID+", request req-1, GET /api/boom: boom") || !strings.Contains(line, "goroutine") {
+			t.Errorf("envelope=%v: logged %q", enveloped, line)
+		}
+	}
+
+	logged.Reset()
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/gone", nil))
+	if w.Body.Len() != 0 || logged.Len() != 0 {
+		t.Errorf("aborted request: body %q, logged %q", w.Body.String(), logged.String())
+	}
+}
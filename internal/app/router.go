@@ -1,9 +1,23 @@
 package app
 
-import "github.com/gin-gonic/gin"
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/envelope"
+	"github.com/ninenine/babytrack/internal/visibility"
+	"github.com/ninenine/babytrack/internal/webapp"
+
+	"github.com/gin-gonic/gin"
+)
 
 func (s *Server) setupRoutes() {
 	api := s.router.Group("/api")
+	// Wrap JSON responses as {data, meta} for clients that send X-Envelope
+	api.Use(envelope.Middleware())
+	// Announce deprecated routes, and refuse retired ones, before anything else
+	api.Use(s.deprecationPolicy.Middleware())
+	// Refuse writes during maintenance; sign-in and operator routes stay open
+	api.Use(s.maintenanceSwitch.Guard("/api/auth", "/api/admin"))
 	{
 		// Health check
 		api.GET("/health", func(c *gin.Context) {
@@ -15,52 +29,210 @@ func (s *Server) setupRoutes() {
 			c.JSON(200, gin.H{"version": GetVersion()})
 		})
 
+		// Status page data (public, rate limited per client)
+		statusGroup := api.Group("/status", rateLimit(60, time.Minute))
+		s.statusHandler.RegisterRoutes(statusGroup)
+
+		// Notification event schemas for integration builders (public, rate limited per client)
+		eventsGroup := api.Group("/events", rateLimit(60, time.Minute))
+		s.eventSchemaHandler.RegisterRoutes(eventsGroup)
+
+		// OpenAPI document for client teams (public, rate limited per client)
+		apiSpecGroup := api.Group("", rateLimit(60, time.Minute))
+		s.apiSpecHandler.RegisterRoutes(apiSpecGroup)
+
+		// Deprecated routes and their replacements for client teams (public, rate limited per client)
+		deprecationsGroup := api.Group("/deprecations", rateLimit(60, time.Minute))
+		s.deprecationHandler.RegisterRoutes(deprecationsGroup)
+
+		// Operator routes (authenticated by the admin token)
+		adminGroup := api.Group("/admin")
+		s.maintenanceHandler.RegisterAdminRoutes(adminGroup)
+		if s.profilingHandler != nil {
+			pprofGroup := adminGroup.Group("/debug/pprof", s.maintenanceHandler.RequireAdmin)
+			s.profilingHandler.RegisterAdminRoutes(pprofGroup)
+		}
+		syncCapturesGroup := adminGroup.Group("/sync-captures", s.maintenanceHandler.RequireAdmin)
+		s.syncCaptureHandler.RegisterAdminRoutes(syncCapturesGroup)
+		growthAdminGroup := adminGroup.Group("/growth", s.maintenanceHandler.RequireAdmin)
+		s.growthHandler.RegisterAdminRoutes(growthAdminGroup)
+
 		// Auth routes (public)
 		authGroup := api.Group("/auth")
 		s.authHandler.RegisterRoutes(authGroup)
 
+		// Integration webhooks (public, authenticated by request signature)
+		webhookGroup := api.Group("/webhooks")
+		s.integrationsHandler.RegisterWebhookRoutes(webhookGroup)
+		s.daycareHandler.RegisterWebhookRoutes(webhookGroup, s.integrationsHandler.Verify())
+
+		// Device measurement pushes (public, authenticated by device API key)
+		deviceIngestGroup := api.Group("/devices")
+		s.devicesHandler.RegisterIngestRoutes(deviceIngestGroup)
+
+		// Quick-log entries from shortcuts (public, authenticated by personal API key)
+		quicklogGroup := api.Group("/quicklog")
+		s.quicklogHandler.RegisterRoutes(quicklogGroup, s.quotaMiddleware()...)
+
+		// Live view for wall displays (public, authenticated by display token, rate limited per client)
+		displayGroup := api.Group("/display", rateLimit(60, time.Minute))
+		s.kioskHandler.RegisterRoutes(displayGroup)
+
 		// Protected routes
 		protected := api.Group("/")
 		protected.Use(s.authMiddleware())
+		protected.Use(s.quotaMiddleware()...)
 		{
 			// Family routes
 			familyGroup := protected.Group("/families")
 			s.familyHandler.RegisterRoutes(familyGroup)
+			s.dashboardHandler.RegisterFamilyRoutes(familyGroup)
+			s.statsHandler.RegisterFamilyRoutes(familyGroup)
+			s.transferHandler.RegisterFamilyRoutes(familyGroup)
+			s.exportHandler.RegisterFamilyRoutes(familyGroup)
+			s.visibilityHandler.RegisterFamilyRoutes(familyGroup)
+			s.integrationsHandler.RegisterFamilyRoutes(familyGroup)
+			s.daycareHandler.RegisterFamilyRoutes(familyGroup)
+			s.inboundHandler.RegisterFamilyRoutes(familyGroup)
+			s.closuresHandler.RegisterFamilyRoutes(familyGroup)
+			s.sandboxHandler.RegisterFamilyRoutes(familyGroup)
+			s.activityHandler.RegisterFamilyRoutes(familyGroup)
+			s.pumpingHandler.RegisterFamilyRoutes(familyGroup)
+			s.kioskHandler.RegisterFamilyRoutes(familyGroup)
+			s.appointmentHandler.RegisterFamilyRoutes(familyGroup)
+			if s.healthinfoHandler != nil {
+				s.healthinfoHandler.RegisterFamilyRoutes(familyGroup)
+			}
+
+			// Signed-in user's views across all their families
+			meGroup := protected.Group("/me")
+			s.dashboardHandler.RegisterMeRoutes(meGroup)
+
+			// Child-scoped routes
+			childGroup := protected.Group("/children")
+			s.exportHandler.RegisterChildRoutes(childGroup)
+			s.transferHandler.RegisterChildRoutes(childGroup, s.exportHandler.Encrypt("id"))
+			s.memoriesHandler.RegisterChildRoutes(childGroup)
+			s.grantsHandler.RegisterChildRoutes(childGroup)
+			s.messagingHandler.RegisterChildRoutes(childGroup)
+			s.careplanHandler.RegisterChildRoutes(childGroup)
+			s.documentsHandler.RegisterChildRoutes(childGroup)
+			s.solidsHandler.RegisterChildRoutes(childGroup)
+			s.playtimeHandler.RegisterChildRoutes(childGroup)
+			s.qualityHandler.RegisterChildRoutes(childGroup)
+			s.timersHandler.RegisterChildRoutes(childGroup)
+			s.logsheetHandler.RegisterChildRoutes(childGroup, s.exportHandler.Encrypt("id"))
+			if s.healthinfoHandler != nil {
+				s.healthinfoHandler.RegisterChildRoutes(childGroup)
+			}
+
+			// Care plan routes
+			careplanGroup := protected.Group("/care-plans")
+			s.careplanHandler.RegisterRoutes(careplanGroup)
+
+			// Professional account routes
+			professionalGroup := protected.Group("/professional")
+			s.grantsHandler.RegisterRoutes(professionalGroup)
+
+			// Permission check routes
+			authzGroup := protected.Group("/authz")
+			s.authzHandler.RegisterRoutes(authzGroup)
 
 			// Feeding routes
-			feedingGroup := protected.Group("/feeding")
+			feedingGroup := protected.Group("/feeding", s.visibilityHandler.Enforce(visibility.RecordFeeding))
 			s.feedingHandler.RegisterRoutes(feedingGroup)
 
 			// Sleep routes
-			sleepGroup := protected.Group("/sleep")
+			sleepGroup := protected.Group("/sleep", s.visibilityHandler.Enforce(visibility.RecordSleep))
 			s.sleepHandler.RegisterRoutes(sleepGroup)
 
 			// Medication routes
-			medicationGroup := protected.Group("/medications")
+			medicationGroup := protected.Group("/medications", s.visibilityHandler.Enforce(visibility.RecordMedication))
 			s.medicationHandler.RegisterRoutes(medicationGroup)
 
 			// Vaccination routes
-			vaccinationGroup := protected.Group("/vaccinations")
-			s.vaccinationHandler.RegisterRoutes(vaccinationGroup)
+			vaccinationGroup := protected.Group("/vaccinations", s.visibilityHandler.Enforce(visibility.RecordVaccination))
+			s.vaccinationHandler.RegisterRoutes(vaccinationGroup, s.exportHandler.Encrypt("childId"))
 
 			// Appointment routes
-			appointmentGroup := protected.Group("/appointments")
+			appointmentGroup := protected.Group("/appointments", s.visibilityHandler.Enforce(visibility.RecordAppointment))
 			s.appointmentHandler.RegisterRoutes(appointmentGroup)
 
 			// Notes routes
-			notesGroup := protected.Group("/notes")
+			notesGroup := protected.Group("/notes", s.visibilityHandler.Enforce(visibility.RecordNotes))
 			s.notesHandler.RegisterRoutes(notesGroup)
 
+			// Growth routes
+			growthGroup := protected.Group("/growth", s.visibilityHandler.Enforce(visibility.RecordGrowth))
+			s.growthHandler.RegisterRoutes(growthGroup)
+
+			// Temperature and fever routes
+			temperatureGroup := protected.Group("/temperature", s.visibilityHandler.Enforce(visibility.RecordTemperature))
+			s.temperatureHandler.RegisterRoutes(temperatureGroup)
+
+			// Device routes
+			devicesGroup := protected.Group("/devices")
+			s.devicesHandler.RegisterRoutes(devicesGroup)
+
+			// Quick-log API key routes
+			quicklogKeysGroup := protected.Group("/quicklog")
+			s.quicklogHandler.RegisterKeyRoutes(quicklogKeysGroup)
+
+			// Templates routes
+			templatesGroup := protected.Group("/templates")
+			s.templatesHandler.RegisterRoutes(templatesGroup)
+
+			// Favorites routes
+			favoritesGroup := protected.Group("/favorites")
+			s.favoritesHandler.RegisterRoutes(favoritesGroup)
+
+			// Media routes
+			mediaGroup := protected.Group("/media")
+			s.mediaHandler.RegisterRoutes(mediaGroup)
+
+			// Attachment routes
+			attachmentsGroup := protected.Group("/attachments")
+			s.attachmentsHandler.RegisterRoutes(attachmentsGroup)
+
+			// Child document routes
+			documentsGroup := protected.Group("/documents")
+			s.documentsHandler.RegisterRoutes(documentsGroup)
+
+			// Photo journal routes
+			journalGroup := protected.Group("/journal", s.visibilityHandler.Enforce(visibility.RecordJournal))
+			s.journalHandler.RegisterRoutes(journalGroup)
+
+			// Population stats routes
+			statsGroup := protected.Group("/stats")
+			s.statsHandler.RegisterRoutes(statsGroup)
+
 			// Sync routes
 			syncGroup := protected.Group("/sync")
-			s.syncHandler.RegisterRoutes(syncGroup)
+			s.syncHandler.RegisterRoutes(syncGroup.Group("", s.syncCaptureHandler.Capture))
+			s.syncCaptureHandler.RegisterRoutes(syncGroup)
 
 			// Notifications routes (SSE)
 			notificationsGroup := protected.Group("/notifications")
 			s.notificationsHandler.RegisterRoutes(notificationsGroup)
+			s.preferencesHandler.RegisterRoutes(notificationsGroup.Group("/preferences"))
+
+			// Daycare provider routes
+			daycareGroup := protected.Group("/daycare")
+			s.daycareHandler.RegisterRoutes(daycareGroup)
+
+			// Presence routes
+			presenceGroup := protected.Group("/presence")
+			s.presenceHandler.RegisterRoutes(presenceGroup)
 		}
 	}
 
+	// Readiness probe, per module, outside /api so maintenance mode and
+	// deprecations do not apply
+	s.healthHandler.RegisterRoutes(s.router.Group(""))
+
+	// Lightweight web dashboard for self-hosters without the mobile app
+	s.webappHandler.RegisterRoutes(s.router.Group(webapp.Prefix))
+
 	// Serve UI for all other routes
 	s.serveUI()
 }
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec"
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/webapp"
+)
+
+// TestRoutes_Documented keeps the OpenAPI document and the router in step:
+// every route is documented and every documented route is served
+func TestRoutes_Documented(t *testing.T) {
+	cfg := &Config{}
+	// Optional modules are switched on so their routes are registered too
+	cfg.Admin.Pprof = true
+	cfg.Database.EncryptionKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+	s, err := NewServer(cfg, &db.DB{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	param := regexp.MustCompile(`[:*](\w+)`)
+	served := map[string]bool{}
+	for _, r := range s.router.Routes() {
+		// The web dashboard serves HTML, not the API
+		if strings.HasPrefix(r.Path, webapp.Prefix) {
+			continue
+		}
+		served[strings.ToLower(r.Method)+" "+param.ReplaceAllString(r.Path, "{$1}")] = true
+	}
+
+	documented := map[string]bool{}
+	for path, item := range apispec.Build(GetVersion()).Paths {
+		for method := range *item {
+			documented[method+" "+path] = true
+		}
+	}
+
+	for route := range served {
+		if !documented[route] {
+			t.Errorf("%s is not in the OpenAPI document", route)
+		}
+	}
+	for route := range documented {
+		if !served[route] {
+			t.Errorf("%s is documented but not served", route)
+		}
+	}
+}
@@ -1,29 +1,89 @@
 package app
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"fmt"
 	"io/fs"
+	"log"
 	"net/http"
+	"os"
 	"path"
 	"strings"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/activity"
+	"github.com/ninenine/babytrack/internal/apijson"
+	"github.com/ninenine/babytrack/internal/apispec"
 	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/archive"
+	"github.com/ninenine/babytrack/internal/attachments"
+	"github.com/ninenine/babytrack/internal/audit"
 	"github.com/ninenine/babytrack/internal/auth"
+	"github.com/ninenine/babytrack/internal/authz"
+	"github.com/ninenine/babytrack/internal/careplan"
+	"github.com/ninenine/babytrack/internal/closures"
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/dashboard"
+	"github.com/ninenine/babytrack/internal/daycare"
 	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/deprecation"
+	"github.com/ninenine/babytrack/internal/devices"
+	"github.com/ninenine/babytrack/internal/documents"
+	"github.com/ninenine/babytrack/internal/errreport"
+	"github.com/ninenine/babytrack/internal/eventschema"
+	"github.com/ninenine/babytrack/internal/export"
 	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/favorites"
 	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/grants"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/healthinfo"
+	"github.com/ninenine/babytrack/internal/inbound"
+	"github.com/ninenine/babytrack/internal/integrations"
 	"github.com/ninenine/babytrack/internal/jobs"
+	"github.com/ninenine/babytrack/internal/journal"
+	"github.com/ninenine/babytrack/internal/kiosk"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/logsheet"
+	"github.com/ninenine/babytrack/internal/maintenance"
+	"github.com/ninenine/babytrack/internal/media"
 	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/memories"
+	"github.com/ninenine/babytrack/internal/messaging"
 	"github.com/ninenine/babytrack/internal/notes"
 	"github.com/ninenine/babytrack/internal/notifications"
+	"github.com/ninenine/babytrack/internal/playtime"
+	"github.com/ninenine/babytrack/internal/preferences"
+	"github.com/ninenine/babytrack/internal/presence"
+	"github.com/ninenine/babytrack/internal/profiling"
+	"github.com/ninenine/babytrack/internal/pumping"
+	"github.com/ninenine/babytrack/internal/quality"
+	"github.com/ninenine/babytrack/internal/quicklog"
+	"github.com/ninenine/babytrack/internal/quota"
+	"github.com/ninenine/babytrack/internal/redact"
+	"github.com/ninenine/babytrack/internal/replay"
+	"github.com/ninenine/babytrack/internal/sandbox"
+	"github.com/ninenine/babytrack/internal/sealed"
 	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/solids"
+	"github.com/ninenine/babytrack/internal/stats"
+	"github.com/ninenine/babytrack/internal/status"
 	"github.com/ninenine/babytrack/internal/sync"
+	"github.com/ninenine/babytrack/internal/synccapture"
+	"github.com/ninenine/babytrack/internal/temperature"
+	"github.com/ninenine/babytrack/internal/templates"
+	"github.com/ninenine/babytrack/internal/timers"
+	"github.com/ninenine/babytrack/internal/transfer"
 	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+	"github.com/ninenine/babytrack/internal/webapp"
 
 	"github.com/gin-gonic/gin"
+	ginjson "github.com/gin-gonic/gin/codec/json"
 )
 
 //go:embed all:web_dist
@@ -36,6 +96,9 @@ type Server struct {
 	httpServer           *http.Server
 	scheduler            *jobs.Scheduler
 	notificationHub      *notifications.Hub
+	maintenanceSwitch    *maintenance.Switch
+	redactor             *redact.Redactor
+	errorReporter        *errreport.Reporter // nil without error_reporting.dsn
 	authService          auth.Service
 	authHandler          *auth.Handler
 	familyHandler        *family.Handler
@@ -45,12 +108,106 @@ type Server struct {
 	notesHandler         *notes.Handler
 	vaccinationHandler   *vaccination.Handler
 	appointmentHandler   *appointment.Handler
+	growthHandler        *growth.Handler
+	temperatureHandler   *temperature.Handler
+	pumpingHandler       *pumping.Handler
+	devicesHandler       *devices.Handler
+	dashboardHandler     *dashboard.Handler
+	exportHandler        *export.Handler
+	statsHandler         *stats.Handler
+	transferHandler      *transfer.Handler
+	grantsHandler        *grants.Handler
+	authzHandler         *authz.Handler
+	messagingHandler     *messaging.Handler
+	careplanHandler      *careplan.Handler
+	closuresHandler      *closures.Handler
+	healthinfoHandler    *healthinfo.Handler // nil without database.encryption_key
+	visibilityHandler    *visibility.Handler
+	activityHandler      *activity.Handler
+	templatesHandler     *templates.Handler
+	favoritesHandler     *favorites.Handler
+	mediaHandler         *media.Handler
+	attachmentsHandler   *attachments.Handler
+	documentsHandler     *documents.Handler
+	solidsHandler        *solids.Handler
+	playtimeHandler      *playtime.Handler
+	journalHandler       *journal.Handler
+	memoriesHandler      *memories.Handler
+	integrationsHandler  *integrations.Handler
+	inboundHandler       *inbound.Handler
+	quicklogHandler      *quicklog.Handler
+	qualityHandler       *quality.Handler
+	timersHandler        *timers.Handler
+	kioskHandler         *kiosk.Handler
+	daycareHandler       *daycare.Handler
+	logsheetHandler      *logsheet.Handler
 	syncHandler          *sync.Handler
+	syncCaptureHandler   *synccapture.Handler
 	notificationsHandler *notifications.Handler
+	presenceHandler      *presence.Handler
+	quotaHandler         *quota.Handler // nil when quotas are disabled
+	preferencesHandler   *preferences.Handler
+	sandboxHandler       *sandbox.Handler
+	statusHandler        *status.Handler
+	healthHandler        *health.Handler
+	eventSchemaHandler   *eventschema.Handler
+	apiSpecHandler       *apispec.Handler
+	maintenanceHandler   *maintenance.Handler
+	deprecationPolicy    *deprecation.Policy
+	deprecationHandler   *deprecation.Handler
+	profilingHandler     *profiling.Handler // nil unless admin.pprof is on
+	webappHandler        *webapp.Handler
 }
 
 func NewServer(cfg *Config, database *db.DB) (*Server, error) {
 	gin.SetMode(gin.ReleaseMode)
+	// Responses carry timestamps in UTC whatever zone they were read in
+	ginjson.API = apijson.Codec{}
+
+	// Titles and names are stored in 255 character columns
+	if cfg.Limits.NameLength > 255 {
+		return nil, fmt.Errorf("limits.name_length: %d is more than the 255 characters stored", cfg.Limits.NameLength)
+	}
+	limits.Set(limits.Limits{
+		TagsPerNote:          cfg.Limits.TagsPerNote,
+		BulkItems:            cfg.Limits.BulkItems,
+		ChildrenPerRequest:   cfg.Limits.ChildrenPerRequest,
+		AttachmentsPerRecord: cfg.Limits.AttachmentsPerRecord,
+		NameLength:           cfg.Limits.NameLength,
+		TextLength:           cfg.Limits.TextLength,
+	})
+
+	// Mask personal data and secrets in everything logged from here on
+	redactor, err := redact.New(redact.Config{
+		Keys: cfg.Logging.Redact.Keys, Allow: cfg.Logging.Redact.Allow, Patterns: cfg.Logging.Redact.Patterns,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("logging.redact: %w", err)
+	}
+	log.SetOutput(redactor.Writer(os.Stderr))
+
+	var errorReporter *errreport.Reporter
+	if cfg.ErrorReporting.DSN != "" {
+		errorReporter, err = errreport.New(errreport.Config{
+			DSN:             cfg.ErrorReporting.DSN,
+			Environment:     cfg.ErrorReporting.Environment,
+			Release:         GetVersion(),
+			SampleRate:      sampleRate(cfg.ErrorReporting.SampleRate),
+			PanicSampleRate: sampleRate(cfg.ErrorReporting.PanicSampleRate),
+			UserSalt:        cfg.ErrorReporting.UserSalt,
+		}, errreport.WithRedactor(redactor))
+		if err != nil {
+			return nil, fmt.Errorf("error_reporting: %w", err)
+		}
+	}
+
+	// Initialise maintenance mode components
+	maintenanceSwitch := maintenance.NewSwitch(cfg.Maintenance.Enabled, cfg.Maintenance.Message, cfg.Maintenance.RetryAfter)
+	maintenanceHandler := maintenance.NewHandler(maintenanceSwitch, cfg.Admin.Token)
+	var profilingHandler *profiling.Handler
+	if cfg.Admin.Pprof {
+		profilingHandler = profiling.NewHandler()
+	}
 
 	// Initialise auth components
 	googleClient := auth.NewGoogleOAuthClient(&auth.GoogleOAuthConfig{
@@ -65,56 +222,470 @@ func NewServer(cfg *Config, database *db.DB) (*Server, error) {
 	authService := auth.NewService(authRepo, googleClient, jwtManager)
 	authHandler := auth.NewHandler(authService)
 
+	// Initialise API quota components
+	var quotaService quota.Service
+	var quotaHandler *quota.Handler
+	if len(cfg.Quotas.Plans) > 0 {
+		if _, ok := cfg.Quotas.Plans[cfg.Quotas.DefaultPlan]; !ok {
+			return nil, fmt.Errorf("quotas.default_plan %q is not one of quotas.plans", cfg.Quotas.DefaultPlan)
+		}
+		quotaRepo := quota.NewRepository(database.DB)
+		quotaService = quota.NewService(quotaRepo, quota.Plans(cfg.Quotas.Plans), cfg.Quotas.DefaultPlan)
+		quotaHandler = quota.NewHandler(quotaService)
+		authHandler.WithUsage(quotaService)
+	}
+
+	// Shared by services whose writes span several rows or repositories
+	txManager := db.NewTxManager(database.DB)
+
+	// Deleted record IDs for sync pulls and ?since= list fetches
+	tombstones := delta.NewTombstones(database.DB, cfg.Sync.TombstoneRetention)
+	archiver := archive.NewArchiver(database.DB, cfg.Database.ArchiveAfter)
+
 	// Initialise family components
 	familyRepo := family.NewRepository(database.DB)
-	familyService := family.NewService(familyRepo)
+	familyService := family.NewService(familyRepo, family.WithTxManager(txManager), family.WithTombstones(tombstones),
+		family.WithJoinURL(strings.TrimSuffix(cfg.Server.BaseURL, "/")+"/join"))
 	familyHandler := family.NewHandler(familyService)
 
+	// Initialise notification hub
+	notificationHub := notifications.NewHub()
+	if len(cfg.Notifications.Digest) > 0 {
+		rules := make(notifications.DigestRules, len(cfg.Notifications.Digest))
+		for eventType, window := range cfg.Notifications.Digest {
+			rules[notifications.EventType(eventType)] = window
+		}
+		notificationHub.SetDigest(rules)
+	}
+	go notificationHub.Run()
+	notificationsHandler := notifications.NewHandler(notificationHub)
+
+	// Initialise notification preference components
+	preferencesRepo := preferences.NewRepository(database.DB)
+	preferencesService := preferences.NewService(preferencesRepo, familyService)
+	preferencesHandler := preferences.NewHandler(preferencesService)
+
+	// Initialise sandbox components. Notifications about sandbox children are
+	// captured rather than delivered.
+	sandboxRepo := sandbox.NewRepository(database.DB)
+	sandboxService := sandbox.NewService(sandboxRepo, familyService,
+		sandbox.WithTxManager(txManager), sandbox.WithResetInterval(cfg.Sandbox.ResetInterval))
+	sandboxHandler := sandbox.NewHandler(sandboxService)
+	notificationHub.SetFilter(sandbox.HubFilter(sandboxService, preferences.HubFilter(preferencesService)))
+
+	// Record history is kept for as-of exports and coverage reports
+	historyStore := audit.NewStore(database.DB)
+	// Corrections to administered doses keep the values they replace
+	correctionStore := corrections.NewStore(database.DB)
+
+	// Initialise member visibility components. Record modules check it on
+	// every read and change.
+	visibilityRepo := visibility.NewRepository(database.DB)
+	visibilityService := visibility.NewService(visibilityRepo, familyService)
+	visibilityHandler := visibility.NewHandler(visibilityService)
+
+	// Initialise growth components
+	growthRepo := growth.NewRepository(database.DB)
+	growthService := growth.NewService(growthRepo, growth.WithChildren(familyService),
+		growth.WithVisibility(visibilityService))
+	growthHandler := growth.NewHandler(growthService)
+
 	// Initialise feeding components
+	if name := cfg.Feeding.FormulaGuideline; name != "" {
+		if _, ok := feeding.FormulaGuidelines[name]; !ok {
+			return nil, fmt.Errorf("unknown feeding.formula_guideline %q", name)
+		}
+	}
 	feedingRepo := feeding.NewRepository(database.DB)
-	feedingService := feeding.NewService(feedingRepo)
+	feedingService := feeding.NewService(feedingRepo,
+		feeding.WithHistory(historyStore),
+		feeding.WithFormulaGuide(familyService, growthService, cfg.Feeding.FormulaGuideline),
+		feeding.WithNursingTimer(familyService, notificationHub),
+		feeding.WithTombstones(tombstones, txManager),
+		feeding.WithVisibility(visibilityService),
+	)
 	feedingHandler := feeding.NewHandler(feedingService)
 
 	// Initialise sleep components
 	sleepRepo := sleep.NewRepository(database.DB)
-	sleepService := sleep.NewService(sleepRepo)
+	sleepService := sleep.NewService(sleepRepo,
+		sleep.WithSourcePriority(cfg.Sleep.SourcePriority),
+		sleep.WithHistory(historyStore),
+		sleep.WithFamily(familyService),
+		sleep.WithNotifier(notificationHub),
+		sleep.WithTombstones(tombstones, txManager),
+		sleep.WithVisibility(visibilityService),
+	)
 	sleepHandler := sleep.NewHandler(sleepService)
 
 	// Initialise medication components
 	medicationRepo := medication.NewRepository(database.DB)
-	medicationService := medication.NewService(medicationRepo)
+	medicationService := medication.NewService(medicationRepo,
+		medication.WithTxManager(txManager), medication.WithTombstones(tombstones),
+		medication.WithCorrections(correctionStore), medication.WithVisibility(visibilityService))
 	medicationHandler := medication.NewHandler(medicationService)
 
+	// Initialise temperature components
+	temperatureRepo := temperature.NewRepository(database.DB)
+	temperatureService := temperature.NewService(temperatureRepo, temperature.WithMedications(medicationService),
+		temperature.WithVisibility(visibilityService))
+	temperatureHandler := temperature.NewHandler(temperatureService)
+
+	// Initialise pumping and milk stash components
+	pumpingRepo := pumping.NewRepository(database.DB)
+	pumpingService := pumping.NewService(pumpingRepo, familyService, feedingService)
+	pumpingHandler := pumping.NewHandler(pumpingService)
+
 	// Initialise notes components
 	notesRepo := notes.NewRepository(database.DB)
-	notesService := notes.NewService(notesRepo)
+	notesService := notes.NewService(notesRepo, notes.WithTombstones(tombstones, txManager),
+		notes.WithVisibility(visibilityService))
 	notesHandler := notes.NewHandler(notesService)
 
 	// Initialise vaccination components
 	vaccinationRepo := vaccination.NewRepository(database.DB)
-	vaccinationService := vaccination.NewService(vaccinationRepo)
+	vaccinationService := vaccination.NewService(vaccinationRepo,
+		vaccination.WithHistory(historyStore),
+		vaccination.WithTxManager(txManager),
+		vaccination.WithFamily(familyService),
+		vaccination.WithTombstones(tombstones),
+		vaccination.WithCorrections(correctionStore),
+		vaccination.WithVisibility(visibilityService),
+	)
 	vaccinationHandler := vaccination.NewHandler(vaccinationService)
 
 	// Initialise appointment components
 	appointmentRepo := appointment.NewRepository(database.DB)
-	appointmentService := appointment.NewService(appointmentRepo)
+	appointmentService := appointment.NewService(appointmentRepo,
+		appointment.WithChildren(familyService),
+		appointment.WithRecords(vaccinationService, growthService),
+		appointment.WithVisibility(visibilityService),
+	)
 	appointmentHandler := appointment.NewHandler(appointmentService)
 
+	// Initialise device ingestion components
+	devicesRepo := devices.NewRepository(database.DB)
+	devicesService := devices.NewService(devicesRepo, familyService, growthService, sleepService)
+	devicesHandler := devices.NewHandler(devicesService)
+
+	// Initialise templates components
+	templatesRepo := templates.NewRepository(database.DB)
+	templatesService := templates.NewService(templatesRepo)
+	templatesHandler := templates.NewHandler(templatesService)
+
+	// Initialise favorites components
+	favoritesRepo := favorites.NewRepository(database.DB)
+	favoritesService := favorites.NewService(favoritesRepo, map[favorites.EntityType]favorites.Resolver{
+		favorites.EntityNote:        favorites.ResolveFor(notesService.GetVisible),
+		favorites.EntityFeeding:     favorites.ResolveWith(feedingService.Get),
+		favorites.EntitySleep:       favorites.ResolveWith(sleepService.Get),
+		favorites.EntityMedication:  favorites.ResolveWith(medicationService.Get),
+		favorites.EntityVaccination: favorites.ResolveWith(vaccinationService.Get),
+		favorites.EntityAppointment: favorites.ResolveWith(appointmentService.Get),
+	})
+	favoritesHandler := favorites.NewHandler(favoritesService)
+
+	// Initialise replay protection shared by integrations and sync
+	replayStore := replay.NewStore(database.DB)
+
+	// Initialise integration receiver components
+	integrationsRepo := integrations.NewRepository(database.DB)
+	integrationsService := integrations.NewService(integrationsRepo, replayStore, familyService, notesService)
+	integrationsHandler := integrations.NewHandler(integrationsService)
+
+	// Initialise media components
+	maxUploadBytes := int64(cfg.Media.MaxUploadMB) << 20 // Zero falls back to media.DefaultMaxUploadBytes
+	var scanner media.Scanner = media.NoopScanner{}
+	if cfg.Media.ClamAVAddr != "" {
+		scanner = media.NewClamAVScanner(cfg.Media.ClamAVAddr)
+	}
+	mediaRepo := media.NewRepository(database.DB)
+	mediaService := media.NewService(mediaRepo, familyService, scanner, maxUploadBytes)
+	mediaHandler := media.NewHandler(mediaService, maxUploadBytes)
+
+	// Initialise photo journal components
+	journalRepo := journal.NewRepository(database.DB)
+	journalService := journal.NewService(journalRepo, familyService, mediaService,
+		journal.WithVisibility(visibilityService))
+	journalHandler := journal.NewHandler(journalService)
+
+	// Initialise inbound email components
+	inboundRepo := inbound.NewRepository(database.DB)
+	inboundService := inbound.NewService(inboundRepo, familyService, notesService, mediaService, cfg.Mail.InboundDomain)
+	inboundHandler := inbound.NewHandler(inboundService)
+
+	// Initialise export components
+	exportRepo := export.NewRepository(database.DB)
+	exportService := export.NewService(exportRepo, familyService, sleepService, feedingService, historyStore,
+		export.WithVisibility(visibilityService))
+	exportHandler := export.NewHandler(exportService)
+
+	// Initialise child transfer components
+	transferRepo := transfer.NewRepository(database.DB)
+	transferService := transfer.NewService(
+		transferRepo, familyService, feedingService, sleepService,
+		medicationService, vaccinationService, appointmentService, notesService, journalService,
+		transfer.WithVisibility(visibilityService),
+	)
+	transferHandler := transfer.NewHandler(transferService)
+
+	// Initialise professional access components
+	grantsRepo := grants.NewRepository(database.DB)
+	grantsService := grants.NewService(grantsRepo, familyService, transferService)
+	grantsHandler := grants.NewHandler(grantsService)
+
+	// Initialise permission check components
+	authzService := authz.NewService(familyService, grantsService)
+	authzHandler := authz.NewHandler(authzService)
+
+	// Initialise care team messaging components
+	messagingRepo := messaging.NewRepository(database.DB)
+	messagingService := messaging.NewService(messagingRepo, familyService, grantsService, notificationHub)
+	messagingHandler := messaging.NewHandler(messagingService)
+
+	// Initialise care plan components
+	careplanRepo := careplan.NewRepository(database.DB)
+	careplanService := careplan.NewService(careplanRepo, familyService, grantsService,
+		medicationService, notesService, growthService)
+	careplanHandler := careplan.NewHandler(careplanService)
+
+	// Initialise closure calendar components
+	closuresRepo := closures.NewRepository(database.DB)
+	closuresService := closures.NewService(closuresRepo, familyService)
+	closuresHandler := closures.NewHandler(closuresService)
+
+	// Initialise insurance and pharmacy components, which store sealed fields
+	var box *sealed.Box
+	var healthinfoService healthinfo.Service
+	var healthinfoHandler *healthinfo.Handler
+	if cfg.Database.EncryptionKey != "" {
+		key, err := sealed.ParseKey(cfg.Database.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("database.encryption_key: %w", err)
+		}
+		if box, err = sealed.NewBox(key); err != nil {
+			return nil, err
+		}
+		healthinfoRepo := healthinfo.NewRepository(database.DB)
+		healthinfoService = healthinfo.NewService(healthinfoRepo, familyService, box)
+		healthinfoHandler = healthinfo.NewHandler(healthinfoService)
+	}
+
+	// Initialise family activity feed components
+	activityRepo := activity.NewRepository(database.DB)
+	activityService := activity.NewService(activityRepo, familyService, activity.WithVisibility(visibilityService))
+	activityHandler := activity.NewHandler(activityService)
+
+	// Initialise quick-log components
+	quicklogRepo := quicklog.NewRepository(database.DB)
+	quicklogService := quicklog.NewService(quicklogRepo, familyService, feedingService, sleepService,
+		medicationService, notesService, growthService, quicklog.WithVisibility(visibilityService))
+	quicklogHandler := quicklog.NewHandler(quicklogService)
+
+	// Initialise data quality components
+	qualityRepo := quality.NewRepository(database.DB)
+	qualityService := quality.NewService(qualityRepo, familyService, sleepService, feedingService,
+		vaccinationService, medicationService, growthService, quality.WithVisibility(visibilityService))
+	qualityHandler := quality.NewHandler(qualityService)
+
+	// Initialise timed activity components
+	playtimeRepo := playtime.NewRepository(database.DB)
+	playtimeService := playtime.NewService(playtimeRepo, familyService)
+	playtimeHandler := playtime.NewHandler(playtimeService)
+
+	// Initialise active timer components
+	timersService := timers.NewService(familyService,
+		[]timers.Source{
+			timers.SleepSource(sleepService),
+			timers.NursingSource(feedingService),
+			timers.PlaytimeSource(playtimeService),
+			timers.PumpingSource(pumpingService, familyService),
+		},
+		timers.WithVisibility(visibilityService))
+	timersHandler := timers.NewHandler(timersService)
+
+	// Initialise wall display components
+	kioskRepo := kiosk.NewRepository(database.DB)
+	kioskService := kiosk.NewService(kioskRepo, familyService, timersService, feedingService, sleepService,
+		kiosk.WithVisibility(visibilityService))
+	kioskHandler := kiosk.NewHandler(kioskService)
+
+	// Initialise daycare connectors, which poll or receive daily reports
+	daycareRegistry := daycare.NewRegistry(daycare.NewDailyReport(&http.Client{Timeout: 30 * time.Second}))
+	var daycareOpts []daycare.Option
+	if box != nil {
+		daycareOpts = append(daycareOpts, daycare.WithBox(box))
+	}
+	daycareRepo := daycare.NewRepository(database.DB)
+	daycareService := daycare.NewService(daycareRepo, daycareRegistry, familyService, feedingService, sleepService,
+		notesService, daycareOpts...)
+	daycareHandler := daycare.NewHandler(daycareService)
+
+	logsheetService := logsheet.NewService(familyService, feedingService, sleepService, medicationService,
+		logsheet.WithVisibility(visibilityService))
+	logsheetHandler := logsheet.NewHandler(logsheetService)
+
+	// Initialise attachment components
+	attachmentsRepo := attachments.NewRepository(database.DB)
+	attachmentsService := attachments.NewService(attachmentsRepo, familyService, mediaService, notesService,
+		growthService, vaccinationService, attachments.WithVisibility(visibilityService))
+	attachmentsHandler := attachments.NewHandler(attachmentsService)
+
+	// Initialise child document components
+	documentsRepo := documents.NewRepository(database.DB)
+	documentsService := documents.NewService(documentsRepo, familyService, mediaService)
+	documentsHandler := documents.NewHandler(documentsService)
+
+	// Initialise solids introduction components
+	solidsRepo := solids.NewRepository(database.DB)
+	solidsService := solids.NewService(solidsRepo, familyService)
+	solidsHandler := solids.NewHandler(solidsService)
+
+	// Initialise memories components
+	memoriesService := memories.NewService(familyService, journalService, notesService, growthService,
+		vaccinationService, memories.WithVisibility(visibilityService))
+	memoriesHandler := memories.NewHandler(memoriesService)
+
+	// Initialise status page components
+	windows := make(status.Schedule, 0, len(cfg.Status.Maintenance))
+	for _, m := range cfg.Status.Maintenance {
+		windows = append(windows, status.MaintenanceWindow{
+			Title: m.Title, Message: m.Message, StartsAt: m.StartsAt, EndsAt: m.EndsAt,
+		})
+	}
+	statusService := status.NewService(database.DB, windows, status.WithMaintenanceMode(maintenanceSwitch))
+	statusHandler := status.NewHandler(statusService)
+
+	// Initialise deprecation components
+	deprecated := make([]deprecation.Route, 0, len(cfg.Deprecation.Routes))
+	for _, r := range cfg.Deprecation.Routes {
+		deprecated = append(deprecated, deprecation.Route{
+			Method: r.Method, Path: r.Path, DeprecatedAt: r.DeprecatedAt, Sunset: r.Sunset,
+			Replacement: r.Replacement, Link: r.Link, Message: r.Message,
+		})
+	}
+	deprecationOpts := []deprecation.Option{deprecation.WithMinNotice(cfg.Deprecation.MinNotice)}
+	if cfg.Deprecation.RetireAfterSunset {
+		deprecationOpts = append(deprecationOpts, deprecation.WithRetirement())
+	}
+	deprecationPolicy, err := deprecation.NewPolicy(deprecated, deprecationOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("deprecation.routes: %w", err)
+	}
+	deprecationHandler := deprecation.NewHandler(deprecationPolicy)
+
+	// Initialise event schema components
+	eventSchemaHandler := eventschema.NewHandler()
+
+	// Initialise API spec components
+	apiSpecHandler := apispec.NewHandler(GetVersion())
+
+	// Initialise population stats components
+	statsRepo := stats.NewRepository(database.DB)
+	statsService := stats.NewService(statsRepo, familyService)
+	statsHandler := stats.NewHandler(statsService)
+
+	// Initialise dashboard components
+	dashboardService := dashboard.NewService(familyService, vaccinationService, medicationService, appointmentService,
+		sleepService, dashboard.WithVisibility(visibilityService))
+	dashboardHandler := dashboard.NewHandler(dashboardService)
+
 	// Initialise sync components
-	syncService := sync.NewService(feedingService, sleepService, medicationService, notesService)
+	syncConflictRepo := sync.NewConflictRepository(database.DB)
+	syncDeviceRepo := sync.NewDeviceRepository(database.DB)
+	syncService := sync.NewService(replayStore, feedingService, sleepService, medicationService, notesService,
+		sync.WithMaintenance(maintenanceSwitch), sync.WithConflicts(syncConflictRepo),
+		sync.WithDevices(syncDeviceRepo), sync.WithTombstones(tombstones, familyService),
+		sync.WithVisibility(visibilityService))
 	syncHandler := sync.NewHandler(syncService)
+	syncCaptureHandler := synccapture.NewHandler(synccapture.NewRecorder())
 
-	// Initialise notification hub
-	notificationHub := notifications.NewHub()
-	go notificationHub.Run()
-	notificationsHandler := notifications.NewHandler(notificationHub)
+	// Initialise presence components
+	presenceService := presence.NewService(familyService, notificationHub)
+	presenceHandler := presence.NewHandler(presenceService)
 
 	// Initialise scheduler and jobs
 	scheduler := jobs.NewScheduler()
 	scheduler.Register(jobs.NewMedicationReminderJob(medicationService, notificationHub))
-	scheduler.Register(jobs.NewVaccinationReminderJob(vaccinationService, notificationHub))
+	scheduler.Register(jobs.NewVaccinationReminderJob(vaccinationService, notificationHub).WithClosures(closuresService))
 	scheduler.Register(jobs.NewAppointmentReminderJob(appointmentService, notificationHub))
+	scheduler.Register(jobs.NewWellVisitGrowthJob(appointmentService, growthService, notificationHub))
+	scheduler.Register(jobs.NewDocumentExpiryJob(documentsService, notificationHub))
 	scheduler.Register(jobs.NewSleepAnalyticsJob(sleepService).WithNotificationHub(notificationHub))
+	scheduler.Register(jobs.NewFeedingGoalJob(feedingService, notificationHub))
+	scheduler.Register(jobs.NewOnThisDayJob(memoriesService, notificationHub))
+	scheduler.Register(jobs.NewPopulationStatsJob(statsService))
+	scheduler.Register(jobs.NewDailyStatsJob(statsService))
+	scheduler.Register(jobs.NewDataQualityJob(qualityService))
+	scheduler.Register(jobs.NewMediaRescanJob(mediaService))
+	scheduler.Register(jobs.NewGrowthPercentilesJob(growthService))
+	scheduler.Register(jobs.NewNoncePurgeJob(replayStore))
+	scheduler.Register(jobs.NewTombstonePurgeJob(tombstones))
+	scheduler.Register(jobs.NewLogArchiveJob(archiver))
+	scheduler.Register(jobs.NewInvitePurgeJob(familyService))
+	scheduler.Register(jobs.NewSandboxResetJob(sandboxService))
+	scheduler.Register(jobs.NewPresenceExpiryJob(presenceService))
+	scheduler.Register(jobs.NewDaycareSyncJob(daycareService))
+	if quotaService != nil {
+		scheduler.Register(jobs.NewQuotaPurgeJob(quotaService))
+	}
+	if cfg.Mail.InboundDomain != "" && cfg.Mail.Maildir != "" {
+		scheduler.Register(jobs.NewMailIngestJob(inboundService, cfg.Mail.Maildir))
+	}
+
+	// Initialise readiness checks, one per module, for /readyz
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register("auth", authService)
+	healthRegistry.Register("family", familyService)
+	healthRegistry.Register("feeding", feedingService)
+	healthRegistry.Register("sleep", sleepService)
+	healthRegistry.Register("archive", archiver)
+	healthRegistry.Register("medication", medicationService)
+	healthRegistry.Register("notes", notesService)
+	healthRegistry.Register("vaccination", vaccinationService)
+	healthRegistry.Register("appointment", appointmentService)
+	healthRegistry.Register("growth", growthService)
+	healthRegistry.Register("temperature", temperatureService)
+	healthRegistry.Register("pumping", pumpingService)
+	healthRegistry.Register("devices", devicesService)
+	healthRegistry.Register("history", historyStore)
+	healthRegistry.Register("corrections", correctionStore)
+	healthRegistry.Register("stats", statsService)
+	healthRegistry.Register("transfer", transferService)
+	healthRegistry.Register("export", exportService)
+	healthRegistry.Register("grants", grantsService)
+	healthRegistry.Register("messaging", messagingService)
+	healthRegistry.Register("careplan", careplanService)
+	healthRegistry.Register("closures", closuresService)
+	healthRegistry.Register("visibility", visibilityService)
+	healthRegistry.Register("activity", activityService)
+	healthRegistry.Register("templates", templatesService)
+	healthRegistry.Register("favorites", favoritesService)
+	healthRegistry.Register("media", mediaService)
+	healthRegistry.Register("attachments", attachmentsService)
+	healthRegistry.Register("documents", documentsService)
+	healthRegistry.Register("solids", solidsService)
+	healthRegistry.Register("playtime", playtimeService)
+	healthRegistry.Register("journal", journalService)
+	healthRegistry.Register("integrations", integrationsService)
+	healthRegistry.Register("inbound", inboundService)
+	healthRegistry.Register("quicklog", quicklogService)
+	healthRegistry.Register("quality", qualityService)
+	healthRegistry.Register("kiosk", kioskService)
+	healthRegistry.Register("daycare", daycareService)
+	healthRegistry.Register("sync", syncService)
+	healthRegistry.Register("notifications", notificationHub)
+	healthRegistry.Register("preferences", preferencesService)
+	healthRegistry.Register("sandbox", sandboxService)
+	if healthinfoService != nil {
+		healthRegistry.Register("healthinfo", healthinfoService)
+	}
+	if quotaService != nil {
+		healthRegistry.Register("quota", quotaService)
+	}
+	if errorReporter != nil {
+		healthRegistry.Register("error_reporting", errorReporter)
+	}
 
 	s := &Server{
 		cfg:                  cfg,
@@ -122,6 +693,9 @@ func NewServer(cfg *Config, database *db.DB) (*Server, error) {
 		router:               gin.New(),
 		scheduler:            scheduler,
 		notificationHub:      notificationHub,
+		maintenanceSwitch:    maintenanceSwitch,
+		redactor:             redactor,
+		errorReporter:        errorReporter,
 		authService:          authService,
 		authHandler:          authHandler,
 		familyHandler:        familyHandler,
@@ -131,12 +705,62 @@ func NewServer(cfg *Config, database *db.DB) (*Server, error) {
 		notesHandler:         notesHandler,
 		vaccinationHandler:   vaccinationHandler,
 		appointmentHandler:   appointmentHandler,
+		growthHandler:        growthHandler,
+		temperatureHandler:   temperatureHandler,
+		pumpingHandler:       pumpingHandler,
+		devicesHandler:       devicesHandler,
+		dashboardHandler:     dashboardHandler,
+		exportHandler:        exportHandler,
+		statsHandler:         statsHandler,
+		transferHandler:      transferHandler,
+		grantsHandler:        grantsHandler,
+		authzHandler:         authzHandler,
+		messagingHandler:     messagingHandler,
+		careplanHandler:      careplanHandler,
+		closuresHandler:      closuresHandler,
+		healthinfoHandler:    healthinfoHandler,
+		visibilityHandler:    visibilityHandler,
+		activityHandler:      activityHandler,
+		templatesHandler:     templatesHandler,
+		favoritesHandler:     favoritesHandler,
+		mediaHandler:         mediaHandler,
+		attachmentsHandler:   attachmentsHandler,
+		documentsHandler:     documentsHandler,
+		solidsHandler:        solidsHandler,
+		playtimeHandler:      playtimeHandler,
+		journalHandler:       journalHandler,
+		memoriesHandler:      memoriesHandler,
+		integrationsHandler:  integrationsHandler,
+		inboundHandler:       inboundHandler,
+		quicklogHandler:      quicklogHandler,
+		qualityHandler:       qualityHandler,
+		timersHandler:        timersHandler,
+		kioskHandler:         kioskHandler,
+		daycareHandler:       daycareHandler,
+		logsheetHandler:      logsheetHandler,
 		syncHandler:          syncHandler,
+		syncCaptureHandler:   syncCaptureHandler,
 		notificationsHandler: notificationsHandler,
+		presenceHandler:      presenceHandler,
+		quotaHandler:         quotaHandler,
+		preferencesHandler:   preferencesHandler,
+		sandboxHandler:       sandboxHandler,
+		statusHandler:        statusHandler,
+		healthHandler:        health.NewHandler(healthRegistry),
+		eventSchemaHandler:   eventSchemaHandler,
+		apiSpecHandler:       apiSpecHandler,
+		maintenanceHandler:   maintenanceHandler,
+		deprecationPolicy:    deprecationPolicy,
+		deprecationHandler:   deprecationHandler,
+		profilingHandler:     profilingHandler,
+		webappHandler:        webapp.NewHandler(cfg.Server.AssetBaseURL),
 	}
 
 	s.setupMiddleware()
 	s.setupRoutes()
+	if err := s.deprecationPolicy.Check(s.router.Routes()); err != nil {
+		return nil, fmt.Errorf("deprecation.routes: %w", err)
+	}
 
 	s.httpServer = &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -163,9 +787,32 @@ func (s *Server) Shutdown() error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	return s.httpServer.Shutdown(ctx)
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	// Send the errors reported while requests finished
+	if s.errorReporter != nil {
+		return s.errorReporter.Close(ctx)
+	}
+	return nil
 }
 
+// sampleRate reads a configured sample rate, where empty means everything
+func sampleRate(rate *float64) float64 {
+	if rate == nil {
+		return 1
+	}
+	return *rate
+}
+
+const (
+	// Vite names everything under assets/ by content hash, so those files
+	// can be cached for good by browsers and any CDN in front
+	cacheImmutable  = "public, max-age=31536000, immutable"
+	cacheRevalidate = "no-cache"
+)
+
 func (s *Server) serveUI() {
 	subFS, err := fs.Sub(uiFS, "web_dist")
 	if err != nil {
@@ -174,6 +821,13 @@ func (s *Server) serveUI() {
 
 	fileServer := http.FileServer(http.FS(subFS))
 
+	// The page itself is revalidated on every load so a new build takes
+	// effect at once. With a CDN configured it points its hashed assets there.
+	indexFile, indexErr := fs.ReadFile(subFS, "index.html")
+	if base := strings.TrimSuffix(s.cfg.Server.AssetBaseURL, "/"); base != "" && indexErr == nil {
+		indexFile = bytes.ReplaceAll(indexFile, []byte(`="/assets/`), []byte(`="`+base+`/assets/`))
+	}
+
 	s.router.NoRoute(func(c *gin.Context) {
 		reqPath := c.Request.URL.Path
 
@@ -183,14 +837,14 @@ func (s *Server) serveUI() {
 			return
 		}
 
-		// Try to serve the file directly
+		// Try to serve the file directly; the page itself is handled below
 		filePath := strings.TrimPrefix(reqPath, "/")
-		if filePath == "" {
-			filePath = "index.html"
+		if filePath == "index.html" {
+			filePath = ""
 		}
 
 		// Check if file exists
-		if f, err := subFS.Open(filePath); err == nil {
+		if f, err := subFS.Open(filePath); filePath != "" && err == nil {
 			_ = f.Close() //nolint:errcheck // Best-effort close, just checking file existence
 			// Set correct content type based on extension
 			ext := path.Ext(filePath)
@@ -210,16 +864,21 @@ func (s *Server) serveUI() {
 			case ".woff2":
 				c.Header("Content-Type", "font/woff2")
 			}
+			if strings.HasPrefix(filePath, "assets/") {
+				c.Header("Cache-Control", cacheImmutable)
+			} else {
+				c.Header("Cache-Control", cacheRevalidate)
+			}
 			fileServer.ServeHTTP(c.Writer, c.Request)
 			return
 		}
 
 		// File not found, serve index.html for SPA routing
-		indexFile, err := fs.ReadFile(subFS, "index.html")
-		if err != nil {
+		if indexErr != nil {
 			c.String(500, "Internal Server Error")
 			return
 		}
+		c.Header("Cache-Control", cacheRevalidate)
 		c.Data(200, "text/html; charset=utf-8", indexFile)
 	})
 }
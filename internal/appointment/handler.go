@@ -1,10 +1,15 @@
 package appointment
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
 type Handler struct {
@@ -19,21 +24,50 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.GET("", h.list)
 	rg.POST("", h.create)
 	rg.GET("/upcoming/:childId", h.getUpcoming)
+	rg.GET("/well-visits/schedule", h.getWellVisitSchedule)
+	rg.GET("/well-visits/:childId", h.suggestWellVisits)
+	rg.GET("/screenings/:childId", h.screenings)
 	rg.GET("/:id", h.get)
 	rg.PUT("/:id", h.update)
 	rg.DELETE("/:id", h.delete)
 	rg.POST("/:id/complete", h.complete)
 	rg.POST("/:id/cancel", h.cancel)
+	rg.POST("/:id/outcome", h.recordOutcome)
+}
+
+// RegisterFamilyRoutes mounts the family-wide appointment views under
+// /families
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/appointments/upcoming", h.familyUpcoming)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownWellVisit), errors.Is(err, ErrWellVisitType),
+		errors.Is(err, ErrRecordNotFound), errors.Is(err, ErrInvalidDays), errors.Is(err, limits.ErrExceeded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrChildNotFound), errors.Is(err, ErrAppointmentNotFound), errors.Is(err, visibility.ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, family.ErrNotMember), errors.Is(err, visibility.ErrHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrCancelled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSuggestionsUnavailable), errors.Is(err, ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
 
 func (h *Handler) list(c *gin.Context) {
 	filter := &AppointmentFilter{
 		ChildID:      c.Query("child_id"),
 		UpcomingOnly: c.Query("upcoming_only") == "true",
+		ViewerID:     c.GetString("user_id"),
 	}
 	apts, err := h.service.List(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, apts)
@@ -46,9 +80,10 @@ func (h *Handler) create(c *gin.Context) {
 		return
 	}
 
+	req.UserID = c.GetString("user_id")
 	apt, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, apt)
@@ -56,9 +91,9 @@ func (h *Handler) create(c *gin.Context) {
 
 func (h *Handler) get(c *gin.Context) {
 	id := c.Param("id")
-	apt, err := h.service.Get(c.Request.Context(), id)
+	apt, err := h.service.Get(c.Request.Context(), c.GetString("user_id"), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, apt)
@@ -72,9 +107,9 @@ func (h *Handler) update(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	apt, err := h.service.Update(c.Request.Context(), id, &req)
+	apt, err := h.service.Update(c.Request.Context(), c.GetString("user_id"), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, apt)
@@ -82,8 +117,8 @@ func (h *Handler) update(c *gin.Context) {
 
 func (h *Handler) delete(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), id); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -91,8 +126,8 @@ func (h *Handler) delete(c *gin.Context) {
 
 func (h *Handler) complete(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.service.Complete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Complete(c.Request.Context(), c.GetString("user_id"), id); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusOK)
@@ -100,8 +135,8 @@ func (h *Handler) complete(c *gin.Context) {
 
 func (h *Handler) cancel(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.service.Cancel(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Cancel(c.Request.Context(), c.GetString("user_id"), id); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusOK)
@@ -123,3 +158,59 @@ func (h *Handler) getUpcoming(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, apts)
 }
+
+func (h *Handler) getWellVisitSchedule(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.GetWellVisitSchedule())
+}
+
+func (h *Handler) suggestWellVisits(c *gin.Context) {
+	suggestions, err := h.service.SuggestWellVisits(c.Request.Context(), c.GetString("user_id"), c.Param("childId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func (h *Handler) screenings(c *gin.Context) {
+	screenings, err := h.service.Screenings(c.Request.Context(), c.GetString("user_id"), c.Param("childId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, screenings)
+}
+
+func (h *Handler) recordOutcome(c *gin.Context) {
+	var req RecordOutcomeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	apt, err := h.service.RecordOutcome(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apt)
+}
+
+func (h *Handler) familyUpcoming(c *gin.Context) {
+	days := 30
+	if d := c.Query("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a number"})
+			return
+		}
+		days = parsed
+	}
+
+	apts, err := h.service.UpcomingForFamily(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), days)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apts)
+}
@@ -5,12 +5,19 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"iter"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
 func init() {
@@ -27,6 +34,10 @@ type mockService struct {
 	completeFn    func(ctx context.Context, id string) error
 	cancelFn      func(ctx context.Context, id string) error
 	getUpcomingFn func(ctx context.Context, childID string, days int) ([]Appointment, error)
+	suggestFn     func(ctx context.Context, userID, childID string) ([]WellVisitSuggestion, error)
+	screeningsFn  func(ctx context.Context, userID, childID string) ([]ScreeningDue, error)
+	outcomeFn     func(ctx context.Context, userID, id string, req *RecordOutcomeRequest) (*Appointment, error)
+	familyFn      func(ctx context.Context, userID, familyID string, days int) ([]FamilyAppointment, error)
 }
 
 func (m *mockService) Create(ctx context.Context, req *CreateAppointmentRequest) (*Appointment, error) {
@@ -36,7 +47,7 @@ func (m *mockService) Create(ctx context.Context, req *CreateAppointmentRequest)
 	return nil, nil
 }
 
-func (m *mockService) Get(ctx context.Context, id string) (*Appointment, error) {
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Appointment, error) {
 	if m.getFn != nil {
 		return m.getFn(ctx, id)
 	}
@@ -50,28 +61,32 @@ func (m *mockService) List(ctx context.Context, filter *AppointmentFilter) ([]Ap
 	return nil, nil
 }
 
-func (m *mockService) Update(ctx context.Context, id string, req *CreateAppointmentRequest) (*Appointment, error) {
+func (m *mockService) Stream(ctx context.Context, filter *AppointmentFilter) iter.Seq2[Appointment, error] {
+	return nil
+}
+
+func (m *mockService) Update(ctx context.Context, userID, id string, req *CreateAppointmentRequest) (*Appointment, error) {
 	if m.updateFn != nil {
 		return m.updateFn(ctx, id, req)
 	}
 	return nil, nil
 }
 
-func (m *mockService) Delete(ctx context.Context, id string) error {
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
 	if m.deleteFn != nil {
 		return m.deleteFn(ctx, id)
 	}
 	return nil
 }
 
-func (m *mockService) Complete(ctx context.Context, id string) error {
+func (m *mockService) Complete(ctx context.Context, userID, id string) error {
 	if m.completeFn != nil {
 		return m.completeFn(ctx, id)
 	}
 	return nil
 }
 
-func (m *mockService) Cancel(ctx context.Context, id string) error {
+func (m *mockService) Cancel(ctx context.Context, userID, id string) error {
 	if m.cancelFn != nil {
 		return m.cancelFn(ctx, id)
 	}
@@ -85,9 +100,48 @@ func (m *mockService) GetUpcoming(ctx context.Context, childID string, days int)
 	return nil, nil
 }
 
+func (m *mockService) GetWellVisitSchedule() []WellVisit {
+	return wellVisitSchedule
+}
+
+func (m *mockService) SuggestWellVisits(ctx context.Context, userID, childID string) ([]WellVisitSuggestion, error) {
+	if m.suggestFn != nil {
+		return m.suggestFn(ctx, userID, childID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Screenings(ctx context.Context, userID, childID string) ([]ScreeningDue, error) {
+	if m.screeningsFn != nil {
+		return m.screeningsFn(ctx, userID, childID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) PendingScreenings(ctx context.Context, child *family.Child) ([]ScreeningDue, error) {
+	return nil, nil
+}
+
+func (m *mockService) DueGrowthReminders(ctx context.Context, now time.Time) ([]Appointment, error) {
+	return nil, nil
+}
+
+func (m *mockService) MarkGrowthReminded(ctx context.Context, id string, at time.Time) error {
+	return nil
+}
+
+func (m *mockService) RecordOutcome(ctx context.Context, userID, id string, req *RecordOutcomeRequest) (*Appointment, error) {
+	return m.outcomeFn(ctx, userID, id, req)
+}
+
+func (m *mockService) UpcomingForFamily(ctx context.Context, userID, familyID string, days int) ([]FamilyAppointment, error) {
+	return m.familyFn(ctx, userID, familyID, days)
+}
+
 // setupRouter creates a test router with the handler registered
 func setupRouter(svc Service) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 	handler := NewHandler(svc)
 
 	// Add middleware to set user_id in context (simulating auth)
@@ -98,6 +152,7 @@ func setupRouter(svc Service) *gin.Engine {
 
 	group := router.Group("/appointments")
 	handler.RegisterRoutes(group)
+	handler.RegisterFamilyRoutes(router.Group("/families"))
 	return router
 }
 
@@ -300,6 +355,23 @@ func TestGet_ServiceError(t *testing.T) {
 	}
 }
 
+func TestGet_Hidden(t *testing.T) {
+	svc := &mockService{
+		getFn: func(ctx context.Context, id string) (*Appointment, error) {
+			return nil, visibility.ErrHidden
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/appointments/apt-123", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
 func TestGet_VerifiesIDParam(t *testing.T) {
 	var capturedID string
 	svc := &mockService{
@@ -1005,3 +1077,231 @@ func TestNewHandler(t *testing.T) {
 		t.Error("Expected service to be set")
 	}
 }
+
+func TestCreate_UnknownWellVisit(t *testing.T) {
+	svc := &mockService{
+		createFn: func(ctx context.Context, req *CreateAppointmentRequest) (*Appointment, error) {
+			return nil, ErrUnknownWellVisit
+		},
+	}
+	router := setupRouter(svc)
+
+	body, _ := json.Marshal(map[string]any{
+		"child_id": "child-456", "type": "well_visit", "title": "Checkup",
+		"scheduled_at": time.Now().Add(24 * time.Hour), "well_visit": "5m",
+	})
+	req := httptest.NewRequest("POST", "/appointments", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestGetWellVisitSchedule(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/appointments/well-visits/schedule", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	var result []WellVisit
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(result) == 0 || result[0].ID != "2w" {
+		t.Errorf("Expected the schedule starting at 2 weeks, got %+v", result)
+	}
+}
+
+func TestSuggestWellVisits(t *testing.T) {
+	var gotUser, gotChild string
+	svc := &mockService{
+		suggestFn: func(ctx context.Context, userID, childID string) ([]WellVisitSuggestion, error) {
+			gotUser, gotChild = userID, childID
+			return []WellVisitSuggestion{{WellVisit: wellVisitSchedule[0], DueOn: "2024-01-24", Status: WellVisitDue}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/appointments/well-visits/child-456", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if gotUser != "test-user-123" || gotChild != "child-456" {
+		t.Errorf("Expected suggestions for test-user-123 and child-456, got %s and %s", gotUser, gotChild)
+	}
+}
+
+func TestSuggestWellVisits_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrChildNotFound, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+		{ErrSuggestionsUnavailable, http.StatusServiceUnavailable},
+		{errors.New("database error"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		svc := &mockService{
+			suggestFn: func(ctx context.Context, userID, childID string) ([]WellVisitSuggestion, error) {
+				return nil, tt.err
+			},
+		}
+		router := setupRouter(svc)
+
+		req := httptest.NewRequest("GET", "/appointments/well-visits/child-456", http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.want, w.Code)
+		}
+	}
+}
+
+func TestScreenings(t *testing.T) {
+	var gotUser, gotChild string
+	svc := &mockService{
+		screeningsFn: func(ctx context.Context, userID, childID string) ([]ScreeningDue, error) {
+			gotUser, gotChild = userID, childID
+			return []ScreeningDue{{Screening: screenings["lead"], WellVisit: "12m", DueOn: "2025-01-10", Status: ScreeningPending}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/appointments/screenings/child-456", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if gotUser != "test-user-123" || gotChild != "child-456" {
+		t.Errorf("Expected screenings for test-user-123 and child-456, got %s and %s", gotUser, gotChild)
+	}
+	if !strings.Contains(w.Body.String(), `"id":"lead"`) {
+		t.Errorf("Expected the lead screening, got %s", w.Body.String())
+	}
+
+	svc.screeningsFn = func(ctx context.Context, userID, childID string) ([]ScreeningDue, error) {
+		return nil, ErrNotMember
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/appointments/screenings/child-456", http.NoBody))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-member, got %d", w.Code)
+	}
+}
+
+func TestRecordOutcome(t *testing.T) {
+	var gotUser, gotID string
+	svc := &mockService{
+		outcomeFn: func(ctx context.Context, userID, id string, req *RecordOutcomeRequest) (*Appointment, error) {
+			gotUser, gotID = userID, id
+			apt := sampleAppointment()
+			apt.Outcome, apt.Completed, apt.VaccinationIDs = req.Outcome, true, req.VaccinationIDs
+			return apt, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"outcome":"All fine","vaccination_ids":["vax-1"]}`
+	req := httptest.NewRequest("POST", "/appointments/apt-123/outcome", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotUser != "test-user-123" || gotID != "apt-123" {
+		t.Errorf("RecordOutcome() called with user %q, id %q", gotUser, gotID)
+	}
+	var result Appointment
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if !result.Completed || result.Outcome != "All fine" || len(result.VaccinationIDs) != 1 {
+		t.Errorf("response = %+v", result)
+	}
+}
+
+func TestRecordOutcome_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrAppointmentNotFound, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+		{ErrCancelled, http.StatusConflict},
+		{ErrRecordNotFound, http.StatusBadRequest},
+		{&limits.Error{Field: "outcome", Max: 10, Got: 11, Unit: "characters"}, http.StatusBadRequest},
+		{ErrNotConfigured, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		svc := &mockService{
+			outcomeFn: func(ctx context.Context, userID, id string, req *RecordOutcomeRequest) (*Appointment, error) {
+				return nil, tt.err
+			},
+		}
+		router := setupRouter(svc)
+
+		req := httptest.NewRequest("POST", "/appointments/apt-123/outcome", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.want, w.Code)
+		}
+	}
+}
+
+func TestFamilyUpcoming(t *testing.T) {
+	var gotFamily string
+	var gotDays int
+	svc := &mockService{
+		familyFn: func(ctx context.Context, userID, familyID string, days int) ([]FamilyAppointment, error) {
+			if days < 1 || days > MaxUpcomingDays {
+				return nil, ErrInvalidDays
+			}
+			gotFamily, gotDays = familyID, days
+			return []FamilyAppointment{{Appointment: *sampleAppointment(), ChildName: "Ada"}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/families/family-1/appointments/upcoming", http.NoBody))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if gotFamily != "family-1" || gotDays != 30 {
+		t.Errorf("UpcomingForFamily() called with family %q, days %d", gotFamily, gotDays)
+	}
+	var result []FamilyAppointment
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(result) != 1 || result[0].ChildName != "Ada" || result[0].ID != "apt-123" {
+		t.Errorf("response = %+v", result)
+	}
+
+	for _, query := range []string{"days=soon", "days=0"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/families/family-1/appointments/upcoming?"+query, http.NoBody))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, w.Code)
+		}
+	}
+}
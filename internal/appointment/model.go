@@ -22,10 +22,20 @@ type Appointment struct {
 	ScheduledAt time.Time       `json:"scheduled_at"`
 	Duration    int             `json:"duration"` // in minutes
 	Notes       string          `json:"notes,omitempty"`
-	Completed   bool            `json:"completed"`
-	Cancelled   bool            `json:"cancelled"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
+	Reason      string          `json:"reason,omitempty"`
+	// Outcome is what the doctor found or advised, recorded after the visit
+	Outcome string `json:"outcome,omitempty"`
+	// WellVisit is the ID of the checkup in the well-visit schedule this
+	// appointment books, for well visits booked from a suggestion
+	WellVisit string `json:"well_visit,omitempty"`
+	// VaccinationIDs and MeasurementIDs are the records taken at the visit.
+	// They are filled in when a single appointment is fetched.
+	VaccinationIDs []string  `json:"vaccination_ids,omitempty"`
+	MeasurementIDs []string  `json:"measurement_ids,omitempty"`
+	Completed      bool      `json:"completed"`
+	Cancelled      bool      `json:"cancelled"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 type CreateAppointmentRequest struct {
@@ -37,6 +47,26 @@ type CreateAppointmentRequest struct {
 	ScheduledAt time.Time       `json:"scheduled_at" binding:"required"`
 	Duration    int             `json:"duration"`
 	Notes       string          `json:"notes,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+	WellVisit   string          `json:"well_visit,omitempty"`
+	// UserID is set by the server to the member booking the appointment, who
+	// must be able to see the child's appointments. It is ignored on update.
+	UserID string `json:"-"`
+}
+
+// RecordOutcomeRequest closes a visit with what came of it. The linked IDs
+// replace any linked before and must belong to the appointment's child.
+type RecordOutcomeRequest struct {
+	Outcome        string   `json:"outcome"`
+	VaccinationIDs []string `json:"vaccination_ids"`
+	MeasurementIDs []string `json:"measurement_ids"`
+}
+
+// FamilyAppointment is an upcoming appointment with the name of the child
+// it is for, so a family-wide list reads without another lookup
+type FamilyAppointment struct {
+	Appointment
+	ChildName string `json:"child_name"`
 }
 
 type AppointmentFilter struct {
@@ -45,4 +75,64 @@ type AppointmentFilter struct {
 	UpcomingOnly bool
 	StartDate    *time.Time
 	EndDate      *time.Time
+	// ViewerID leaves out appointments hidden from that member when set
+	ViewerID string
+}
+
+// WellVisit is a routine checkup in the well-visit schedule
+type WellVisit struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	AgeWeeks    int    `json:"age_weeks,omitempty"`
+	AgeMonths   int    `json:"age_months,omitempty"`
+	AgeLabel    string `json:"age_label"`
+	// Screenings are the IDs of the screenings done at the checkup
+	Screenings []string `json:"screenings,omitempty"`
+}
+
+type WellVisitStatus string
+
+const (
+	WellVisitDone     WellVisitStatus = "done"     // A completed appointment books it
+	WellVisitBooked   WellVisitStatus = "booked"   // An appointment is booked and not cancelled
+	WellVisitDue      WellVisitStatus = "due"      // Within its window and not booked yet
+	WellVisitUpcoming WellVisitStatus = "upcoming" // Its window has not opened yet
+	WellVisitOverdue  WellVisitStatus = "overdue"  // Past its window but still worth booking
+	WellVisitMissed   WellVisitStatus = "missed"   // The next checkup is due, so it is no longer suggested
+)
+
+// WellVisitSuggestion is one checkup of the schedule for a child, with when
+// it falls due and whether it has been booked
+type WellVisitSuggestion struct {
+	WellVisit
+	DueOn         string          `json:"due_on"`
+	Status        WellVisitStatus `json:"status"`
+	AppointmentID string          `json:"appointment_id,omitempty"`
+}
+
+// Screening is a check done at well visits, such as a lead test or an
+// autism questionnaire
+type Screening struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ScreeningStatus string
+
+const (
+	ScreeningPending ScreeningStatus = "pending" // Its checkup has not been completed yet
+	ScreeningDone    ScreeningStatus = "done"    // Its checkup's appointment was completed
+	ScreeningMissed  ScreeningStatus = "missed"  // Its checkup was missed
+)
+
+// ScreeningDue is one screening at one checkup of the schedule for a child.
+// A screening done at several checkups is listed once for each.
+type ScreeningDue struct {
+	Screening
+	WellVisit     string          `json:"well_visit"`
+	WellVisitName string          `json:"well_visit_name"`
+	DueOn         string          `json:"due_on"`
+	Status        ScreeningStatus `json:"status"`
+	AppointmentID string          `json:"appointment_id,omitempty"`
 }
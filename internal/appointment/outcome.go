@@ -0,0 +1,172 @@
+package appointment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// MaxUpcomingDays bounds how far ahead the family upcoming list looks
+const MaxUpcomingDays = 365
+
+var (
+	ErrAppointmentNotFound = errors.New("appointment not found")
+	ErrCancelled           = errors.New("appointment is cancelled")
+	ErrRecordNotFound      = errors.New("linked record not found for this child")
+	ErrInvalidDays         = fmt.Errorf("days must be between 1 and %d", MaxUpcomingDays)
+	ErrNotConfigured       = errors.New("family and record checks are not configured")
+)
+
+// WithRecords lets outcomes link the vaccinations and growth measurements
+// recorded at a visit
+func WithRecords(vaccinations vaccination.Service, measurements growth.Service) Option {
+	return func(s *service) {
+		s.vaccinations = vaccinations
+		s.measurements = measurements
+	}
+}
+
+// authorizeChild loads the child and checks the user belongs to its family
+// and may see its appointments
+func (s *service) authorizeChild(ctx context.Context, userID, childID string) (*family.Child, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+	if err := s.checkMember(ctx, child.FamilyID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+func (s *service) checkMember(ctx context.Context, familyID, userID string) error {
+	if _, err := s.familyService.GetMemberRole(ctx, familyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	return nil
+}
+
+// RecordOutcome notes what came of a visit and links the records taken at
+// it. Recording an outcome marks the appointment completed; recording it
+// again replaces the outcome and the links.
+func (s *service) RecordOutcome(ctx context.Context, userID, id string, req *RecordOutcomeRequest) (*Appointment, error) {
+	if s.familyService == nil || s.vaccinations == nil || s.measurements == nil {
+		return nil, ErrNotConfigured
+	}
+	apt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment: %w", err)
+	}
+	if apt == nil {
+		return nil, ErrAppointmentNotFound
+	}
+	if _, err := s.authorizeChild(ctx, userID, apt.ChildID); err != nil {
+		return nil, err
+	}
+	if apt.Cancelled {
+		return nil, ErrCancelled
+	}
+
+	outcome := strings.TrimSpace(req.Outcome)
+	vaccinationIDs := uniqueIDs(req.VaccinationIDs)
+	measurementIDs := uniqueIDs(req.MeasurementIDs)
+	if err := checkOutcomeLimits(outcome, vaccinationIDs, measurementIDs); err != nil {
+		return nil, err
+	}
+
+	for _, vid := range vaccinationIDs {
+		v, err := s.vaccinations.Get(ctx, userID, vid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get vaccination: %w", err)
+		}
+		if v == nil || v.ChildID != apt.ChildID {
+			return nil, fmt.Errorf("%w: vaccination %s", ErrRecordNotFound, vid)
+		}
+	}
+	for _, mid := range measurementIDs {
+		m, err := s.measurements.Get(ctx, userID, mid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get measurement: %w", err)
+		}
+		if m == nil || m.ChildID != apt.ChildID {
+			return nil, fmt.Errorf("%w: measurement %s", ErrRecordNotFound, mid)
+		}
+	}
+
+	apt.Outcome = outcome
+	apt.Completed = true
+	apt.UpdatedAt = s.now()
+	if err := s.repo.SaveOutcome(ctx, apt, vaccinationIDs, measurementIDs); err != nil {
+		return nil, fmt.Errorf("failed to save outcome: %w", err)
+	}
+	apt.VaccinationIDs, apt.MeasurementIDs = vaccinationIDs, measurementIDs
+	return apt, nil
+}
+
+func checkOutcomeLimits(outcome string, vaccinationIDs, measurementIDs []string) error {
+	l := limits.Get()
+	if err := limits.Length("outcome", outcome, l.TextLength); err != nil {
+		return err
+	}
+	if err := limits.Items("vaccination_ids", len(vaccinationIDs), l.AttachmentsPerRecord); err != nil {
+		return err
+	}
+	return limits.Items("measurement_ids", len(measurementIDs), l.AttachmentsPerRecord)
+}
+
+// uniqueIDs drops blanks and repeats, keeping the first of each
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
+
+// UpcomingForFamily lists the next days of appointments across all of a
+// family's children, soonest first
+func (s *service) UpcomingForFamily(ctx context.Context, userID, familyID string, days int) ([]FamilyAppointment, error) {
+	if s.familyService == nil {
+		return nil, ErrNotConfigured
+	}
+	if days < 1 || days > MaxUpcomingDays {
+		return nil, ErrInvalidDays
+	}
+	if err := s.checkMember(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	viewer, err := s.viewer(ctx, &AppointmentFilter{ViewerID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	apts, err := s.repo.UpcomingForFamily(ctx, familyID, now, now.AddDate(0, 0, days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upcoming appointments: %w", err)
+	}
+	return visibility.Filter(ctx, viewer, apts, func(a FamilyAppointment) string { return a.ChildID })
+}
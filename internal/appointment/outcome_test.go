@@ -0,0 +1,146 @@
+package appointment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/vaccination"
+)
+
+type mockVaccinationService struct {
+	vaccination.Service
+	records map[string]*vaccination.Vaccination
+}
+
+func (m *mockVaccinationService) Get(ctx context.Context, userID, id string) (*vaccination.Vaccination, error) {
+	return m.records[id], nil
+}
+
+type mockGrowthService struct {
+	growth.Service
+	records map[string]*growth.Measurement
+}
+
+func (m *mockGrowthService) Get(ctx context.Context, userID, id string) (*growth.Measurement, error) {
+	return m.records[id], nil
+}
+
+var outcomeNow = time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)
+
+func newOutcomeService(repo *mockRepository) Service {
+	vaccinations := &mockVaccinationService{records: map[string]*vaccination.Vaccination{
+		"vax-1":     {ID: "vax-1", ChildID: "child-1"},
+		"vax-other": {ID: "vax-other", ChildID: "child-2"},
+	}}
+	measurements := &mockGrowthService{records: map[string]*growth.Measurement{
+		"m-1": {ID: "m-1", ChildID: "child-1"},
+	}}
+	child := &family.Child{ID: "child-1", FamilyID: "family-1"}
+	svc := NewService(repo, WithChildren(&mockFamilyService{child: child}), WithRecords(vaccinations, measurements))
+	svc.(*service).now = func() time.Time { return outcomeNow }
+	return svc
+}
+
+func TestService_RecordOutcome(t *testing.T) {
+	repo := newMockRepository()
+	repo.appointments["apt-1"] = &Appointment{ID: "apt-1", ChildID: "child-1", Type: AppointmentTypeWellVisit}
+	svc := newOutcomeService(repo)
+	ctx := context.Background()
+
+	apt, err := svc.RecordOutcome(ctx, "user-1", "apt-1", &RecordOutcomeRequest{
+		Outcome:        "  Growing well, vitamin D advised  ",
+		VaccinationIDs: []string{"vax-1", "vax-1", ""},
+		MeasurementIDs: []string{"m-1"},
+	})
+	if err != nil {
+		t.Fatalf("RecordOutcome() error = %v", err)
+	}
+	if !apt.Completed || apt.Outcome != "Growing well, vitamin D advised" || !apt.UpdatedAt.Equal(outcomeNow) {
+		t.Errorf("RecordOutcome() = %+v", apt)
+	}
+	if len(apt.VaccinationIDs) != 1 || len(apt.MeasurementIDs) != 1 {
+		t.Errorf("linked = %v, %v; want one of each", apt.VaccinationIDs, apt.MeasurementIDs)
+	}
+
+	// Get returns the links with the appointment
+	got, err := svc.Get(ctx, "user-1", "apt-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(got.VaccinationIDs) != 1 || got.VaccinationIDs[0] != "vax-1" || got.MeasurementIDs[0] != "m-1" {
+		t.Errorf("Get() links = %v, %v", got.VaccinationIDs, got.MeasurementIDs)
+	}
+}
+
+func TestService_RecordOutcome_Errors(t *testing.T) {
+	repo := newMockRepository()
+	repo.appointments["apt-1"] = &Appointment{ID: "apt-1", ChildID: "child-1"}
+	repo.appointments["apt-cancelled"] = &Appointment{ID: "apt-cancelled", ChildID: "child-1", Cancelled: true}
+	svc := newOutcomeService(repo)
+
+	tests := []struct {
+		name   string
+		userID string
+		id     string
+		req    RecordOutcomeRequest
+		want   error
+	}{
+		{"unknown appointment", "user-1", "apt-9", RecordOutcomeRequest{}, ErrAppointmentNotFound},
+		{"not a member", "user-2", "apt-1", RecordOutcomeRequest{}, ErrNotMember},
+		{"cancelled", "user-1", "apt-cancelled", RecordOutcomeRequest{}, ErrCancelled},
+		{"another child's vaccination", "user-1", "apt-1", RecordOutcomeRequest{VaccinationIDs: []string{"vax-other"}}, ErrRecordNotFound},
+		{"unknown measurement", "user-1", "apt-1", RecordOutcomeRequest{MeasurementIDs: []string{"m-9"}}, ErrRecordNotFound},
+		{"outcome too long", "user-1", "apt-1", RecordOutcomeRequest{Outcome: strings.Repeat("a", limits.Get().TextLength+1)}, limits.ErrExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.RecordOutcome(context.Background(), tt.userID, tt.id, &tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("RecordOutcome() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if repo.appointments["apt-1"].Completed {
+		t.Error("a refused outcome should leave the appointment open")
+	}
+}
+
+func TestService_RecordOutcome_NotConfigured(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, WithChildren(&mockFamilyService{}))
+
+	if _, err := svc.RecordOutcome(context.Background(), "user-1", "apt-1", &RecordOutcomeRequest{}); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("RecordOutcome() error = %v, want %v", err, ErrNotConfigured)
+	}
+}
+
+func TestService_UpcomingForFamily(t *testing.T) {
+	repo := newMockRepository()
+	repo.appointments["apt-soon"] = &Appointment{ID: "apt-soon", ChildID: "child-1", ScheduledAt: outcomeNow.Add(48 * time.Hour)}
+	repo.appointments["apt-later"] = &Appointment{ID: "apt-later", ChildID: "child-1", ScheduledAt: outcomeNow.AddDate(0, 0, 20)}
+	repo.appointments["apt-past"] = &Appointment{ID: "apt-past", ChildID: "child-1", ScheduledAt: outcomeNow.Add(-time.Hour)}
+	svc := newOutcomeService(repo)
+	ctx := context.Background()
+
+	apts, err := svc.UpcomingForFamily(ctx, "user-1", "family-1", 7)
+	if err != nil {
+		t.Fatalf("UpcomingForFamily() error = %v", err)
+	}
+	if len(apts) != 1 || apts[0].ID != "apt-soon" {
+		t.Errorf("UpcomingForFamily() = %+v, want only apt-soon", apts)
+	}
+
+	if _, err := svc.UpcomingForFamily(ctx, "user-2", "family-1", 7); !errors.Is(err, ErrNotMember) {
+		t.Errorf("UpcomingForFamily() by a non-member error = %v, want %v", err, ErrNotMember)
+	}
+	for _, days := range []int{0, MaxUpcomingDays + 1} {
+		if _, err := svc.UpcomingForFamily(ctx, "user-1", "family-1", days); !errors.Is(err, ErrInvalidDays) {
+			t.Errorf("UpcomingForFamily(days = %d) error = %v, want %v", days, err, ErrInvalidDays)
+		}
+	}
+}
@@ -5,16 +5,35 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"iter"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
 )
 
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*Appointment, error)
 	List(ctx context.Context, filter *AppointmentFilter) ([]Appointment, error)
+	// Stream yields what List returns, reading rows as they are consumed
+	Stream(ctx context.Context, filter *AppointmentFilter) iter.Seq2[Appointment, error]
 	Create(ctx context.Context, apt *Appointment) error
 	Update(ctx context.Context, apt *Appointment) error
 	Delete(ctx context.Context, id string) error
 	GetUpcoming(ctx context.Context, childID string, days int) ([]Appointment, error)
+	// EndedWellVisits returns well visits, not cancelled, that ended between
+	// from and to and whose family has not been reminded to record growth
+	EndedWellVisits(ctx context.Context, from, to time.Time) ([]Appointment, error)
+	MarkGrowthReminded(ctx context.Context, id string, at time.Time) error
+	// Links returns the IDs of the vaccinations and measurements linked to
+	// the appointment
+	Links(ctx context.Context, id string) (vaccinationIDs, measurementIDs []string, err error)
+	// SaveOutcome stores the outcome, marks the appointment completed and
+	// replaces its links, all in one transaction
+	SaveOutcome(ctx context.Context, apt *Appointment, vaccinationIDs, measurementIDs []string) error
+	// UpcomingForFamily returns the appointments of every child in the
+	// family scheduled between from and to, not completed or cancelled
+	UpcomingForFamily(ctx context.Context, familyID string, from, to time.Time) ([]FamilyAppointment, error)
 }
 
 type repository struct {
@@ -25,10 +44,15 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
+// HealthCheck checks the appointments table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "appointments", "appointment_vaccinations", "appointment_measurements")
+}
+
 func (r *repository) GetByID(ctx context.Context, id string) (*Appointment, error) {
 	query := `
 		SELECT id, child_id, type, title, provider, location, scheduled_at,
-		       duration, notes, completed, cancelled, created_at, updated_at
+		       duration, notes, COALESCE(well_visit, ''), COALESCE(reason, ''), COALESCE(outcome, ''), completed, cancelled, created_at, updated_at
 		FROM appointments
 		WHERE id = $1
 	`
@@ -38,7 +62,7 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Appointment, erro
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&a.ID, &a.ChildID, &a.Type, &a.Title, &provider, &location, &a.ScheduledAt,
-		&a.Duration, &notes, &a.Completed, &a.Cancelled, &a.CreatedAt, &a.UpdatedAt,
+		&a.Duration, &notes, &a.WellVisit, &a.Reason, &a.Outcome, &a.Completed, &a.Cancelled, &a.CreatedAt, &a.UpdatedAt,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -61,10 +85,11 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Appointment, erro
 	return &a, nil
 }
 
-func (r *repository) List(ctx context.Context, filter *AppointmentFilter) ([]Appointment, error) {
+// filterQuery selects the appointments matching filter, in schedule order
+func filterQuery(filter *AppointmentFilter) (string, []any) {
 	query := `
 		SELECT id, child_id, type, title, provider, location, scheduled_at,
-		       duration, notes, completed, cancelled, created_at, updated_at
+		       duration, notes, COALESCE(well_visit, ''), COALESCE(reason, ''), COALESCE(outcome, ''), completed, cancelled, created_at, updated_at
 		FROM appointments
 		WHERE 1=1
 	`
@@ -100,94 +125,92 @@ func (r *repository) List(ctx context.Context, filter *AppointmentFilter) ([]App
 		args = append(args, *filter.EndDate)
 	}
 
-	query += ` ORDER BY scheduled_at ASC`
+	return query + ` ORDER BY scheduled_at ASC`, args
+}
 
+func scanAppointment(rows *sql.Rows) (Appointment, error) {
+	var a Appointment
+	var provider, location, notes sql.NullString
+
+	if err := rows.Scan(
+		&a.ID, &a.ChildID, &a.Type, &a.Title, &provider, &location, &a.ScheduledAt,
+		&a.Duration, &notes, &a.WellVisit, &a.Reason, &a.Outcome, &a.Completed, &a.Cancelled, &a.CreatedAt, &a.UpdatedAt,
+	); err != nil {
+		return Appointment{}, err
+	}
+
+	a.Provider, a.Location, a.Notes = provider.String, location.String, notes.String
+	return a, nil
+}
+
+func (r *repository) List(ctx context.Context, filter *AppointmentFilter) ([]Appointment, error) {
+	query, args := filterQuery(filter)
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close() //nolint:errcheck // Best-effort close
 
-	var appointments []Appointment
+	appointments := []Appointment{}
 	for rows.Next() {
-		var a Appointment
-		var provider, location, notes sql.NullString
-
-		if err := rows.Scan(
-			&a.ID, &a.ChildID, &a.Type, &a.Title, &provider, &location, &a.ScheduledAt,
-			&a.Duration, &notes, &a.Completed, &a.Cancelled, &a.CreatedAt, &a.UpdatedAt,
-		); err != nil {
+		a, err := scanAppointment(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		if provider.Valid {
-			a.Provider = provider.String
-		}
-		if location.Valid {
-			a.Location = location.String
-		}
-		if notes.Valid {
-			a.Notes = notes.String
-		}
-
 		appointments = append(appointments, a)
 	}
 
-	if appointments == nil {
-		return []Appointment{}, nil
-	}
-
 	return appointments, rows.Err()
 }
 
+func (r *repository) Stream(ctx context.Context, filter *AppointmentFilter) iter.Seq2[Appointment, error] {
+	query, args := filterQuery(filter)
+	return db.Stream(ctx, r.db, scanAppointment, query, args...)
+}
+
 func (r *repository) Create(ctx context.Context, apt *Appointment) error {
 	query := `
 		INSERT INTO appointments (id, child_id, type, title, provider, location, scheduled_at,
-		                          duration, notes, completed, cancelled, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		                          duration, notes, well_visit, reason, outcome, completed, cancelled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 
-	var provider, location, notes *string
-	if apt.Provider != "" {
-		provider = &apt.Provider
-	}
-	if apt.Location != "" {
-		location = &apt.Location
-	}
-	if apt.Notes != "" {
-		notes = &apt.Notes
-	}
+	provider, location, notes, wellVisit, reason, outcome := optionalFields(apt)
 
 	_, err := r.db.ExecContext(ctx, query,
 		apt.ID, apt.ChildID, apt.Type, apt.Title, provider, location, apt.ScheduledAt,
-		apt.Duration, notes, apt.Completed, apt.Cancelled, apt.CreatedAt, apt.UpdatedAt,
+		apt.Duration, notes, wellVisit, reason, outcome, apt.Completed, apt.Cancelled, apt.CreatedAt, apt.UpdatedAt,
 	)
 
 	return err
 }
 
+// optionalFields returns the nullable columns of apt, nil where empty
+func optionalFields(apt *Appointment) (provider, location, notes, wellVisit, reason, outcome *string) {
+	orNil := func(v *string) *string {
+		if *v == "" {
+			return nil
+		}
+		return v
+	}
+	return orNil(&apt.Provider), orNil(&apt.Location), orNil(&apt.Notes),
+		orNil(&apt.WellVisit), orNil(&apt.Reason), orNil(&apt.Outcome)
+}
+
 func (r *repository) Update(ctx context.Context, apt *Appointment) error {
 	query := `
 		UPDATE appointments
 		SET type = $2, title = $3, provider = $4, location = $5, scheduled_at = $6,
-		    duration = $7, notes = $8, completed = $9, cancelled = $10, updated_at = $11
+		    duration = $7, notes = $8, well_visit = $9, reason = $10, outcome = $11,
+		    completed = $12, cancelled = $13, updated_at = $14
 		WHERE id = $1
 	`
 
-	var provider, location, notes *string
-	if apt.Provider != "" {
-		provider = &apt.Provider
-	}
-	if apt.Location != "" {
-		location = &apt.Location
-	}
-	if apt.Notes != "" {
-		notes = &apt.Notes
-	}
+	provider, location, notes, wellVisit, reason, outcome := optionalFields(apt)
 
 	_, err := r.db.ExecContext(ctx, query,
 		apt.ID, apt.Type, apt.Title, provider, location, apt.ScheduledAt,
-		apt.Duration, notes, apt.Completed, apt.Cancelled, apt.UpdatedAt,
+		apt.Duration, notes, wellVisit, reason, outcome, apt.Completed, apt.Cancelled, apt.UpdatedAt,
 	)
 
 	return err
@@ -202,7 +225,7 @@ func (r *repository) Delete(ctx context.Context, id string) error {
 func (r *repository) GetUpcoming(ctx context.Context, childID string, days int) ([]Appointment, error) {
 	query := `
 		SELECT id, child_id, type, title, provider, location, scheduled_at,
-		       duration, notes, completed, cancelled, created_at, updated_at
+		       duration, notes, COALESCE(well_visit, ''), COALESCE(reason, ''), COALESCE(outcome, ''), completed, cancelled, created_at, updated_at
 		FROM appointments
 		WHERE child_id = $1
 		  AND completed = false
@@ -228,7 +251,7 @@ func (r *repository) GetUpcoming(ctx context.Context, childID string, days int)
 
 		if err := rows.Scan(
 			&a.ID, &a.ChildID, &a.Type, &a.Title, &provider, &location, &a.ScheduledAt,
-			&a.Duration, &notes, &a.Completed, &a.Cancelled, &a.CreatedAt, &a.UpdatedAt,
+			&a.Duration, &notes, &a.WellVisit, &a.Reason, &a.Outcome, &a.Completed, &a.Cancelled, &a.CreatedAt, &a.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -252,3 +275,162 @@ func (r *repository) GetUpcoming(ctx context.Context, childID string, days int)
 
 	return appointments, rows.Err()
 }
+
+func (r *repository) EndedWellVisits(ctx context.Context, from, to time.Time) ([]Appointment, error) {
+	query := `
+		SELECT id, child_id, type, title, provider, location, scheduled_at,
+		       duration, notes, COALESCE(well_visit, ''), COALESCE(reason, ''), COALESCE(outcome, ''), completed, cancelled, created_at, updated_at
+		FROM appointments
+		WHERE type = 'well_visit'
+		  AND cancelled = false
+		  AND growth_reminded_at IS NULL
+		  AND scheduled_at + duration * INTERVAL '1 minute' BETWEEN $1 AND $2
+		ORDER BY scheduled_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	appointments := []Appointment{}
+	for rows.Next() {
+		var a Appointment
+		var provider, location, notes sql.NullString
+
+		if err := rows.Scan(
+			&a.ID, &a.ChildID, &a.Type, &a.Title, &provider, &location, &a.ScheduledAt,
+			&a.Duration, &notes, &a.WellVisit, &a.Reason, &a.Outcome, &a.Completed, &a.Cancelled, &a.CreatedAt, &a.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		a.Provider, a.Location, a.Notes = provider.String, location.String, notes.String
+		appointments = append(appointments, a)
+	}
+
+	return appointments, rows.Err()
+}
+
+func (r *repository) MarkGrowthReminded(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE appointments SET growth_reminded_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+func (r *repository) Links(ctx context.Context, id string) ([]string, []string, error) {
+	vaccinationIDs, err := r.linkedIDs(ctx,
+		`SELECT vaccination_id FROM appointment_vaccinations WHERE appointment_id = $1 ORDER BY vaccination_id`, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	measurementIDs, err := r.linkedIDs(ctx,
+		`SELECT measurement_id FROM appointment_measurements WHERE appointment_id = $1 ORDER BY measurement_id`, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vaccinationIDs, measurementIDs, nil
+}
+
+func (r *repository) linkedIDs(ctx context.Context, query, id string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	ids := []string{}
+	for rows.Next() {
+		var linked string
+		if err := rows.Scan(&linked); err != nil {
+			return nil, err
+		}
+		ids = append(ids, linked)
+	}
+	return ids, rows.Err()
+}
+
+func (r *repository) SaveOutcome(ctx context.Context, apt *Appointment, vaccinationIDs, measurementIDs []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit
+
+	var outcome *string
+	if apt.Outcome != "" {
+		outcome = &apt.Outcome
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE appointments SET outcome = $2, completed = true, updated_at = $3 WHERE id = $1`,
+		apt.ID, outcome, apt.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM appointment_vaccinations WHERE appointment_id = $1`, apt.ID); err != nil {
+		return err
+	}
+	for _, id := range vaccinationIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO appointment_vaccinations (appointment_id, vaccination_id) VALUES ($1, $2)`, apt.ID, id,
+		); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM appointment_measurements WHERE appointment_id = $1`, apt.ID); err != nil {
+		return err
+	}
+	for _, id := range measurementIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO appointment_measurements (appointment_id, measurement_id) VALUES ($1, $2)`, apt.ID, id,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) UpcomingForFamily(ctx context.Context, familyID string, from, to time.Time) ([]FamilyAppointment, error) {
+	query := `
+		SELECT a.id, a.child_id, a.type, a.title, a.provider, a.location, a.scheduled_at,
+		       a.duration, a.notes, COALESCE(a.well_visit, ''), COALESCE(a.reason, ''), COALESCE(a.outcome, ''),
+		       a.completed, a.cancelled, a.created_at, a.updated_at, c.name
+		FROM appointments a
+		JOIN children c ON c.id = a.child_id
+		WHERE c.family_id = $1
+		  AND a.completed = false
+		  AND a.cancelled = false
+		  AND a.scheduled_at >= $2
+		  AND a.scheduled_at <= $3
+		ORDER BY a.scheduled_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, familyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	appointments := []FamilyAppointment{}
+	for rows.Next() {
+		var fa FamilyAppointment
+		a := &fa.Appointment
+		var provider, location, notes sql.NullString
+
+		if err := rows.Scan(
+			&a.ID, &a.ChildID, &a.Type, &a.Title, &provider, &location, &a.ScheduledAt,
+			&a.Duration, &notes, &a.WellVisit, &a.Reason, &a.Outcome, &a.Completed, &a.Cancelled,
+			&a.CreatedAt, &a.UpdatedAt, &fa.ChildName,
+		); err != nil {
+			return nil, err
+		}
+
+		a.Provider, a.Location, a.Notes = provider.String, location.String, notes.String
+		appointments = append(appointments, fa)
+	}
+
+	return appointments, rows.Err()
+}
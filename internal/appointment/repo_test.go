@@ -21,7 +21,7 @@ func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
 
 var appointmentColumns = []string{
 	"id", "child_id", "type", "title", "provider", "location", "scheduled_at",
-	"duration", "notes", "completed", "cancelled", "created_at", "updated_at",
+	"duration", "notes", "well_visit", "reason", "outcome", "completed", "cancelled", "created_at", "updated_at",
 }
 
 func TestRepository_GetByID(t *testing.T) {
@@ -31,7 +31,7 @@ func TestRepository_GetByID(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(appointmentColumns).
-		AddRow("apt-123", "child-456", "well_visit", "Checkup", "Dr. Smith", "Clinic", now, 30, "Notes", false, false, now, now)
+		AddRow("apt-123", "child-456", "well_visit", "Checkup", "Dr. Smith", "Clinic", now, 30, "Notes", "", "", "", false, false, now, now)
 
 	mock.ExpectQuery("SELECT id, child_id, type, title, provider, location, scheduled_at").
 		WithArgs("apt-123").
@@ -108,7 +108,7 @@ func TestRepository_GetByID_NullOptionalFields(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(appointmentColumns).
-		AddRow("apt-123", "child-456", "well_visit", "Checkup", nil, nil, now, 30, nil, false, false, now, now)
+		AddRow("apt-123", "child-456", "well_visit", "Checkup", nil, nil, now, 30, nil, "", "", "", false, false, now, now)
 
 	mock.ExpectQuery("SELECT id, child_id, type, title, provider, location, scheduled_at").
 		WithArgs("apt-123").
@@ -135,8 +135,8 @@ func TestRepository_List(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(appointmentColumns).
-		AddRow("apt-1", "child-456", "well_visit", "Checkup 1", "Dr. A", "Clinic A", now, 30, "Notes 1", false, false, now, now).
-		AddRow("apt-2", "child-456", "sick_visit", "Checkup 2", "Dr. B", "Clinic B", now, 45, "Notes 2", true, false, now, now)
+		AddRow("apt-1", "child-456", "well_visit", "Checkup 1", "Dr. A", "Clinic A", now, 30, "Notes 1", "", "", "", false, false, now, now).
+		AddRow("apt-2", "child-456", "sick_visit", "Checkup 2", "Dr. B", "Clinic B", now, 45, "Notes 2", "", "", "", true, false, now, now)
 
 	mock.ExpectQuery("SELECT id, child_id, type, title, provider, location, scheduled_at").
 		WithArgs("child-456").
@@ -229,7 +229,7 @@ func TestRepository_Create(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO appointments").
 		WithArgs(apt.ID, apt.ChildID, apt.Type, apt.Title, &apt.Provider, &apt.Location, apt.ScheduledAt,
-			apt.Duration, &apt.Notes, apt.Completed, apt.Cancelled, apt.CreatedAt, apt.UpdatedAt).
+			apt.Duration, &apt.Notes, nil, nil, nil, apt.Completed, apt.Cancelled, apt.CreatedAt, apt.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), apt)
@@ -261,7 +261,7 @@ func TestRepository_Create_NoOptionalFields(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO appointments").
 		WithArgs(apt.ID, apt.ChildID, apt.Type, apt.Title, nil, nil, apt.ScheduledAt,
-			apt.Duration, nil, apt.Completed, apt.Cancelled, apt.CreatedAt, apt.UpdatedAt).
+			apt.Duration, nil, nil, nil, nil, apt.Completed, apt.Cancelled, apt.CreatedAt, apt.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), apt)
@@ -292,7 +292,7 @@ func TestRepository_Create_Error(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO appointments").
 		WithArgs(apt.ID, apt.ChildID, apt.Type, apt.Title, nil, nil, apt.ScheduledAt,
-			apt.Duration, nil, apt.Completed, apt.Cancelled, apt.CreatedAt, apt.UpdatedAt).
+			apt.Duration, nil, nil, nil, nil, apt.Completed, apt.Cancelled, apt.CreatedAt, apt.UpdatedAt).
 		WillReturnError(errors.New("duplicate key"))
 
 	err := repo.Create(context.Background(), apt)
@@ -327,7 +327,7 @@ func TestRepository_Update(t *testing.T) {
 
 	mock.ExpectExec("UPDATE appointments SET type").
 		WithArgs(apt.ID, apt.Type, apt.Title, &apt.Provider, &apt.Location, apt.ScheduledAt,
-			apt.Duration, &apt.Notes, apt.Completed, apt.Cancelled, apt.UpdatedAt).
+			apt.Duration, &apt.Notes, nil, nil, nil, apt.Completed, apt.Cancelled, apt.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), apt)
@@ -356,7 +356,7 @@ func TestRepository_Update_Error(t *testing.T) {
 
 	mock.ExpectExec("UPDATE appointments SET type").
 		WithArgs(apt.ID, apt.Type, apt.Title, nil, nil, apt.ScheduledAt,
-			apt.Duration, nil, apt.Completed, apt.Cancelled, apt.UpdatedAt).
+			apt.Duration, nil, nil, nil, nil, apt.Completed, apt.Cancelled, apt.UpdatedAt).
 		WillReturnError(errors.New("database error"))
 
 	err := repo.Update(context.Background(), apt)
@@ -414,8 +414,8 @@ func TestRepository_GetUpcoming(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(appointmentColumns).
-		AddRow("apt-1", "child-123", "well_visit", "Upcoming 1", "Dr. A", "Clinic", now.Add(24*time.Hour), 30, "Notes", false, false, now, now).
-		AddRow("apt-2", "child-123", "dental", "Upcoming 2", "Dr. B", "Dental", now.Add(48*time.Hour), 45, nil, false, false, now, now)
+		AddRow("apt-1", "child-123", "well_visit", "Upcoming 1", "Dr. A", "Clinic", now.Add(24*time.Hour), 30, "Notes", "", "", "", false, false, now, now).
+		AddRow("apt-2", "child-123", "dental", "Upcoming 2", "Dr. B", "Dental", now.Add(48*time.Hour), 45, nil, "", "", "", false, false, now, now)
 
 	mock.ExpectQuery("SELECT id, child_id, type, title, provider, location, scheduled_at").
 		WithArgs("child-123", sqlmock.AnyArg(), sqlmock.AnyArg()).
@@ -482,3 +482,110 @@ func TestRepository_GetUpcoming_Error(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func TestRepository_SaveOutcome(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	apt := &Appointment{ID: "apt-1", Outcome: "Healthy, next visit in 3 months", UpdatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE appointments SET outcome = \\$2, completed = true, updated_at = \\$3 WHERE id = \\$1").
+		WithArgs("apt-1", &apt.Outcome, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM appointment_vaccinations WHERE appointment_id = \\$1").
+		WithArgs("apt-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO appointment_vaccinations").
+		WithArgs("apt-1", "vax-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM appointment_measurements WHERE appointment_id = \\$1").
+		WithArgs("apt-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO appointment_measurements").
+		WithArgs("apt-1", "m-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO appointment_measurements").
+		WithArgs("apt-1", "m-2").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.SaveOutcome(context.Background(), apt, []string{"vax-1"}, []string{"m-1", "m-2"}); err != nil {
+		t.Fatalf("SaveOutcome() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_SaveOutcome_RollsBack(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE appointments SET outcome").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM appointment_vaccinations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO appointment_vaccinations").
+		WillReturnError(errors.New("foreign key violation"))
+	mock.ExpectRollback()
+
+	apt := &Appointment{ID: "apt-1", UpdatedAt: time.Now()}
+	if err := repo.SaveOutcome(context.Background(), apt, []string{"vax-gone"}, nil); err == nil {
+		t.Error("SaveOutcome() should return error when a link fails")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Links(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT vaccination_id FROM appointment_vaccinations WHERE appointment_id = \\$1").
+		WithArgs("apt-1").
+		WillReturnRows(sqlmock.NewRows([]string{"vaccination_id"}).AddRow("vax-1").AddRow("vax-2"))
+	mock.ExpectQuery("SELECT measurement_id FROM appointment_measurements WHERE appointment_id = \\$1").
+		WithArgs("apt-1").
+		WillReturnRows(sqlmock.NewRows([]string{"measurement_id"}))
+
+	vaccinationIDs, measurementIDs, err := repo.Links(context.Background(), "apt-1")
+	if err != nil {
+		t.Fatalf("Links() error = %v", err)
+	}
+	if len(vaccinationIDs) != 2 || vaccinationIDs[1] != "vax-2" || measurementIDs == nil || len(measurementIDs) != 0 {
+		t.Errorf("Links() = %v, %v", vaccinationIDs, measurementIDs)
+	}
+}
+
+func TestRepository_UpcomingForFamily(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	to := now.AddDate(0, 0, 30)
+	columns := append(append([]string{}, appointmentColumns...), "name")
+	mock.ExpectQuery("FROM appointments a\\s+JOIN children c ON c.id = a.child_id\\s+WHERE c.family_id = \\$1").
+		WithArgs("family-1", now, to).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("apt-1", "child-1", "well_visit", "6 month checkup", "Dr. A", nil, now.Add(time.Hour), 30, nil, "6m", "Routine", "", false, false, now, now, "Ada").
+			AddRow("apt-2", "child-2", "dental", "First dentist visit", nil, nil, now.Add(48*time.Hour), 20, nil, "", "", "", false, false, now, now, "Ben"))
+
+	apts, err := repo.UpcomingForFamily(context.Background(), "family-1", now, to)
+	if err != nil {
+		t.Fatalf("UpcomingForFamily() error = %v", err)
+	}
+	if len(apts) != 2 || apts[0].ChildName != "Ada" || apts[0].Reason != "Routine" || apts[1].ChildID != "child-2" {
+		t.Errorf("UpcomingForFamily() = %+v", apts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -2,32 +2,83 @@ package appointment
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"fmt"
+	"iter"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
 type Service interface {
 	Create(ctx context.Context, req *CreateAppointmentRequest) (*Appointment, error)
-	Get(ctx context.Context, id string) (*Appointment, error)
+	Get(ctx context.Context, userID, id string) (*Appointment, error)
 	List(ctx context.Context, filter *AppointmentFilter) ([]Appointment, error)
-	Update(ctx context.Context, id string, req *CreateAppointmentRequest) (*Appointment, error)
-	Delete(ctx context.Context, id string) error
-	Complete(ctx context.Context, id string) error
-	Cancel(ctx context.Context, id string) error
+	// Stream yields what List returns without holding it all, for exports
+	Stream(ctx context.Context, filter *AppointmentFilter) iter.Seq2[Appointment, error]
+	Update(ctx context.Context, userID, id string, req *CreateAppointmentRequest) (*Appointment, error)
+	Delete(ctx context.Context, userID, id string) error
+	Complete(ctx context.Context, userID, id string) error
+	Cancel(ctx context.Context, userID, id string) error
 	GetUpcoming(ctx context.Context, childID string, days int) ([]Appointment, error)
+	GetWellVisitSchedule() []WellVisit
+	// SuggestWellVisits returns every checkup of the well-visit schedule for
+	// the child, when it falls due and whether it is booked
+	SuggestWellVisits(ctx context.Context, userID, childID string) ([]WellVisitSuggestion, error)
+	// Screenings returns the screenings done at the child's checkups, when
+	// each falls due and whether it is done
+	Screenings(ctx context.Context, userID, childID string) ([]ScreeningDue, error)
+	// PendingScreenings returns the child's screenings still to be done, for
+	// digests that have already checked the caller belongs to the family
+	PendingScreenings(ctx context.Context, child *family.Child) ([]ScreeningDue, error)
+	DueGrowthReminders(ctx context.Context, now time.Time) ([]Appointment, error)
+	MarkGrowthReminded(ctx context.Context, id string, at time.Time) error
+	RecordOutcome(ctx context.Context, userID, id string, req *RecordOutcomeRequest) (*Appointment, error)
+	UpcomingForFamily(ctx context.Context, userID, familyID string, days int) ([]FamilyAppointment, error)
 }
 
 type service struct {
-	repo Repository
+	repo          Repository
+	familyService family.Service
+	vaccinations  vaccination.Service
+	measurements  growth.Service
+	visibility    visibility.Service
+	now           func() time.Time
+}
+
+type Option func(*service)
+
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{repo: repo, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// HealthCheck reports whether appointments can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
 }
 
 func (s *service) Create(ctx context.Context, req *CreateAppointmentRequest) (*Appointment, error) {
+	// Internal callers create without a user and check access themselves
+	if req.UserID != "" {
+		if err := s.authorize(ctx, req.UserID, req.ChildID); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateWellVisit(req); err != nil {
+		return nil, err
+	}
+	if err := limits.Length("reason", req.Reason, limits.Get().TextLength); err != nil {
+		return nil, err
+	}
 	now := time.Now()
 
 	duration := req.Duration
@@ -36,7 +87,7 @@ func (s *service) Create(ctx context.Context, req *CreateAppointmentRequest) (*A
 	}
 
 	apt := &Appointment{
-		ID:          generateID(),
+		ID:          ids.New(),
 		ChildID:     req.ChildID,
 		Type:        req.Type,
 		Title:       req.Title,
@@ -45,6 +96,8 @@ func (s *service) Create(ctx context.Context, req *CreateAppointmentRequest) (*A
 		ScheduledAt: req.ScheduledAt,
 		Duration:    duration,
 		Notes:       req.Notes,
+		Reason:      req.Reason,
+		WellVisit:   req.WellVisit,
 		Completed:   false,
 		Cancelled:   false,
 		CreatedAt:   now,
@@ -58,21 +111,57 @@ func (s *service) Create(ctx context.Context, req *CreateAppointmentRequest) (*A
 	return apt, nil
 }
 
-func (s *service) Get(ctx context.Context, id string) (*Appointment, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *service) Get(ctx context.Context, userID, id string) (*Appointment, error) {
+	apt, err := s.repo.GetByID(ctx, id)
+	if err != nil || apt == nil {
+		return apt, err
+	}
+	if err := s.authorize(ctx, userID, apt.ChildID); err != nil {
+		return nil, err
+	}
+	if apt.VaccinationIDs, apt.MeasurementIDs, err = s.repo.Links(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get linked records: %w", err)
+	}
+	return apt, nil
 }
 
 func (s *service) List(ctx context.Context, filter *AppointmentFilter) ([]Appointment, error) {
-	return s.repo.List(ctx, filter)
+	viewer, err := s.viewer(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	apts, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return visibility.Filter(ctx, viewer, apts, childOf)
 }
 
-func (s *service) Update(ctx context.Context, id string, req *CreateAppointmentRequest) (*Appointment, error) {
-	apt, err := s.repo.GetByID(ctx, id)
-	if err != nil {
+func (s *service) Stream(ctx context.Context, filter *AppointmentFilter) iter.Seq2[Appointment, error] {
+	return func(yield func(Appointment, error) bool) {
+		viewer, err := s.viewer(ctx, filter)
+		if err != nil {
+			yield(Appointment{}, err)
+			return
+		}
+		for a, err := range visibility.FilterSeq(ctx, viewer, s.repo.Stream(ctx, filter), childOf) {
+			if !yield(a, err) {
+				return
+			}
+		}
+	}
+}
+
+func (s *service) Update(ctx context.Context, userID, id string, req *CreateAppointmentRequest) (*Appointment, error) {
+	if err := validateWellVisit(req); err != nil {
 		return nil, err
 	}
-	if apt == nil {
-		return nil, fmt.Errorf("appointment not found")
+	if err := limits.Length("reason", req.Reason, limits.Get().TextLength); err != nil {
+		return nil, err
+	}
+	apt, err := s.getForChange(ctx, userID, id)
+	if err != nil {
+		return nil, err
 	}
 
 	apt.Type = req.Type
@@ -82,6 +171,8 @@ func (s *service) Update(ctx context.Context, id string, req *CreateAppointmentR
 	apt.ScheduledAt = req.ScheduledAt
 	apt.Duration = req.Duration
 	apt.Notes = req.Notes
+	apt.Reason = req.Reason
+	apt.WellVisit = req.WellVisit
 	apt.UpdatedAt = time.Now()
 
 	if err := s.repo.Update(ctx, apt); err != nil {
@@ -91,18 +182,24 @@ func (s *service) Update(ctx context.Context, id string, req *CreateAppointmentR
 	return apt, nil
 }
 
-func (s *service) Delete(ctx context.Context, id string) error {
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	apt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if apt != nil {
+		if err := s.authorize(ctx, userID, apt.ChildID); err != nil {
+			return err
+		}
+	}
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *service) Complete(ctx context.Context, id string) error {
-	apt, err := s.repo.GetByID(ctx, id)
+func (s *service) Complete(ctx context.Context, userID, id string) error {
+	apt, err := s.getForChange(ctx, userID, id)
 	if err != nil {
 		return err
 	}
-	if apt == nil {
-		return fmt.Errorf("appointment not found")
-	}
 
 	apt.Completed = true
 	apt.UpdatedAt = time.Now()
@@ -114,14 +211,11 @@ func (s *service) Complete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *service) Cancel(ctx context.Context, id string) error {
-	apt, err := s.repo.GetByID(ctx, id)
+func (s *service) Cancel(ctx context.Context, userID, id string) error {
+	apt, err := s.getForChange(ctx, userID, id)
 	if err != nil {
 		return err
 	}
-	if apt == nil {
-		return fmt.Errorf("appointment not found")
-	}
 
 	apt.Cancelled = true
 	apt.UpdatedAt = time.Now()
@@ -136,9 +230,3 @@ func (s *service) Cancel(ctx context.Context, id string) error {
 func (s *service) GetUpcoming(ctx context.Context, childID string, days int) ([]Appointment, error) {
 	return s.repo.GetUpcoming(ctx, childID, days)
 }
-
-func generateID() string {
-	b := make([]byte, 16)
-	rand.Read(b) //nolint:errcheck // crypto/rand.Read rarely fails
-	return hex.EncodeToString(b)
-}
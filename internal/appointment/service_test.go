@@ -3,21 +3,28 @@ package appointment
 import (
 	"context"
 	"errors"
+	"iter"
 	"testing"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
 // mockRepository is a test double for Repository
 type mockRepository struct {
 	appointments map[string]*Appointment
-	createErr    error
-	updateErr    error
-	deleteErr    error
+	// links holds the linked vaccination and measurement IDs by appointment
+	links     map[string][2][]string
+	createErr error
+	updateErr error
+	deleteErr error
 }
 
 func newMockRepository() *mockRepository {
 	return &mockRepository{
 		appointments: make(map[string]*Appointment),
+		links:        make(map[string][2][]string),
 	}
 }
 
@@ -40,6 +47,17 @@ func (m *mockRepository) List(ctx context.Context, filter *AppointmentFilter) ([
 	return result, nil
 }
 
+func (m *mockRepository) Stream(ctx context.Context, filter *AppointmentFilter) iter.Seq2[Appointment, error] {
+	appointments, _ := m.List(ctx, filter)
+	return func(yield func(Appointment, error) bool) {
+		for _, a := range appointments {
+			if !yield(a, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (m *mockRepository) Create(ctx context.Context, apt *Appointment) error {
 	if m.createErr != nil {
 		return m.createErr
@@ -79,6 +97,45 @@ func (m *mockRepository) GetUpcoming(ctx context.Context, childID string, days i
 	return result, nil
 }
 
+func (m *mockRepository) EndedWellVisits(ctx context.Context, from, to time.Time) ([]Appointment, error) {
+	var result []Appointment
+	for _, apt := range m.appointments {
+		end := apt.ScheduledAt.Add(time.Duration(apt.Duration) * time.Minute)
+		if apt.Type == AppointmentTypeWellVisit && !apt.Cancelled && !end.Before(from) && !end.After(to) {
+			result = append(result, *apt)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) MarkGrowthReminded(ctx context.Context, id string, at time.Time) error {
+	return nil
+}
+
+func (m *mockRepository) Links(ctx context.Context, id string) ([]string, []string, error) {
+	l := m.links[id]
+	return l[0], l[1], nil
+}
+
+func (m *mockRepository) SaveOutcome(ctx context.Context, apt *Appointment, vaccinationIDs, measurementIDs []string) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.appointments[apt.ID] = apt
+	m.links[apt.ID] = [2][]string{vaccinationIDs, measurementIDs}
+	return nil
+}
+
+func (m *mockRepository) UpcomingForFamily(ctx context.Context, familyID string, from, to time.Time) ([]FamilyAppointment, error) {
+	result := []FamilyAppointment{}
+	for _, apt := range m.appointments {
+		if !apt.Completed && !apt.Cancelled && !apt.ScheduledAt.Before(from) && !apt.ScheduledAt.After(to) {
+			result = append(result, FamilyAppointment{Appointment: *apt})
+		}
+	}
+	return result, nil
+}
+
 func TestService_Create(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -171,7 +228,7 @@ func TestService_Get(t *testing.T) {
 	created, _ := svc.Create(context.Background(), req)
 
 	// Get it back
-	apt, err := svc.Get(context.Background(), created.ID)
+	apt, err := svc.Get(context.Background(), "user-1", created.ID)
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -189,7 +246,7 @@ func TestService_Get_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	apt, err := svc.Get(context.Background(), "non-existent-id")
+	apt, err := svc.Get(context.Background(), "user-1", "non-existent-id")
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -213,13 +270,13 @@ func TestService_Complete(t *testing.T) {
 	created, _ := svc.Create(context.Background(), req)
 
 	// Complete it
-	err := svc.Complete(context.Background(), created.ID)
+	err := svc.Complete(context.Background(), "user-1", created.ID)
 	if err != nil {
 		t.Fatalf("Complete() error = %v", err)
 	}
 
 	// Verify it's marked as completed
-	apt, _ := svc.Get(context.Background(), created.ID)
+	apt, _ := svc.Get(context.Background(), "user-1", created.ID)
 	if !apt.Completed {
 		t.Error("Complete() should set Completed = true")
 	}
@@ -229,7 +286,7 @@ func TestService_Complete_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	err := svc.Complete(context.Background(), "non-existent-id")
+	err := svc.Complete(context.Background(), "user-1", "non-existent-id")
 	if err == nil {
 		t.Error("Complete() should return error for non-existent appointment")
 	}
@@ -249,13 +306,13 @@ func TestService_Cancel(t *testing.T) {
 	created, _ := svc.Create(context.Background(), req)
 
 	// Cancel it
-	err := svc.Cancel(context.Background(), created.ID)
+	err := svc.Cancel(context.Background(), "user-1", created.ID)
 	if err != nil {
 		t.Fatalf("Cancel() error = %v", err)
 	}
 
 	// Verify it's marked as canceled
-	apt, _ := svc.Get(context.Background(), created.ID)
+	apt, _ := svc.Get(context.Background(), "user-1", created.ID)
 	if !apt.Cancelled {
 		t.Error("Cancel() should set Canceled = true")
 	}
@@ -265,7 +322,7 @@ func TestService_Cancel_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	err := svc.Cancel(context.Background(), "non-existent-id")
+	err := svc.Cancel(context.Background(), "user-1", "non-existent-id")
 	if err == nil {
 		t.Error("Cancel() should return error for non-existent appointment")
 	}
@@ -285,13 +342,13 @@ func TestService_Delete(t *testing.T) {
 	created, _ := svc.Create(context.Background(), req)
 
 	// Delete it
-	err := svc.Delete(context.Background(), created.ID)
+	err := svc.Delete(context.Background(), "user-1", created.ID)
 	if err != nil {
 		t.Fatalf("Delete() error = %v", err)
 	}
 
 	// Verify it's gone
-	apt, _ := svc.Get(context.Background(), created.ID)
+	apt, _ := svc.Get(context.Background(), "user-1", created.ID)
 	if apt != nil {
 		t.Error("Delete() should remove the appointment")
 	}
@@ -356,7 +413,7 @@ func TestService_Update(t *testing.T) {
 		Duration:    60,
 	}
 
-	updated, err := svc.Update(context.Background(), created.ID, updateReq)
+	updated, err := svc.Update(context.Background(), "user-1", created.ID, updateReq)
 	if err != nil {
 		t.Fatalf("Update() error = %v", err)
 	}
@@ -385,7 +442,7 @@ func TestService_Update_NotFound(t *testing.T) {
 		ScheduledAt: time.Now().Add(24 * time.Hour),
 	}
 
-	_, err := svc.Update(context.Background(), "non-existent", req)
+	_, err := svc.Update(context.Background(), "user-1", "non-existent", req)
 	if err == nil {
 		t.Error("Update() should return error for non-existent appointment")
 	}
@@ -407,7 +464,7 @@ func TestService_Update_RepoError(t *testing.T) {
 	// Set error on update
 	repo.updateErr = errors.New("database error")
 
-	_, err := svc.Update(context.Background(), created.ID, req)
+	_, err := svc.Update(context.Background(), "user-1", created.ID, req)
 	if err == nil {
 		t.Error("Update() should return error when repo fails")
 	}
@@ -454,3 +511,75 @@ func TestService_GetUpcoming(t *testing.T) {
 		t.Errorf("GetUpcoming() returned %d appointments, want 1", len(apts))
 	}
 }
+
+// mockVisibilityService hides child-hidden's appointments, and puts
+// child-other in another family
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) CheckChild(ctx context.Context, userID, childID string, recordType visibility.RecordType) error {
+	switch childID {
+	case "child-hidden":
+		return visibility.ErrHidden
+	case "child-other":
+		return family.ErrNotMember
+	}
+	return nil
+}
+
+func TestService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewService(repo, WithVisibility(&mockVisibilityService{}))
+	for _, childID := range []string{"child-123", "child-hidden", "child-other"} {
+		repo.appointments[childID] = &Appointment{ID: childID, ChildID: childID, Type: AppointmentTypeWellVisit, ScheduledAt: time.Now()}
+	}
+
+	if _, err := svc.Get(ctx, "user-1", "child-123"); err != nil {
+		t.Errorf("Get() visible error = %v", err)
+	}
+	if _, err := svc.Get(ctx, "user-1", "child-hidden"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Get() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Get(ctx, "user-1", "child-other"); !errors.Is(err, family.ErrNotMember) {
+		t.Errorf("Get() other family error = %v, want ErrNotMember", err)
+	}
+	req := &CreateAppointmentRequest{Type: AppointmentTypeWellVisit, Title: "Checkup", ScheduledAt: time.Now()}
+	if _, err := svc.Update(ctx, "user-1", "child-hidden", req); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Update() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Cancel(ctx, "user-1", "child-hidden"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Cancel() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Delete(ctx, "user-1", "child-hidden"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Delete() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Create(ctx, &CreateAppointmentRequest{ChildID: "child-hidden", Type: AppointmentTypeWellVisit, Title: "Checkup", ScheduledAt: time.Now(), UserID: "user-1"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Create() hidden error = %v, want ErrHidden", err)
+	}
+	if repo.appointments["child-hidden"] == nil {
+		t.Error("Delete() removed a hidden appointment")
+	}
+
+	list, err := svc.List(ctx, &AppointmentFilter{ViewerID: "user-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ChildID != "child-123" {
+		t.Errorf("List() = %+v, want only child-123's appointment", list)
+	}
+	if _, err := svc.List(ctx, &AppointmentFilter{ChildID: "child-hidden", ViewerID: "user-1"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("List() hidden child error = %v, want ErrHidden", err)
+	}
+	var streamed int
+	for _, err := range svc.Stream(ctx, &AppointmentFilter{ViewerID: "user-1"}) {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		streamed++
+	}
+	if streamed != 1 {
+		t.Errorf("Stream() yielded %d appointments, want 1", streamed)
+	}
+}
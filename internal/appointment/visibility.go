@@ -0,0 +1,47 @@
+package appointment
+
+import (
+	"context"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// WithVisibility applies family visibility settings, so members cannot see or
+// change appointments hidden from them
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+// authorize checks that userID may see the child's appointments
+func (s *service) authorize(ctx context.Context, userID, childID string) error {
+	if s.visibility == nil {
+		return nil
+	}
+	return s.visibility.CheckChild(ctx, userID, childID, visibility.RecordAppointment)
+}
+
+// viewer filters a list for the filter's viewer, if it has one
+func (s *service) viewer(ctx context.Context, filter *AppointmentFilter) (*visibility.Viewer, error) {
+	return visibility.ViewerFor(ctx, s.visibility, filter.ViewerID, filter.ChildID, visibility.RecordAppointment)
+}
+
+// getForChange loads an appointment userID may change
+func (s *service) getForChange(ctx context.Context, userID, id string) (*Appointment, error) {
+	apt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if apt == nil {
+		return nil, ErrAppointmentNotFound
+	}
+	if err := s.authorize(ctx, userID, apt.ChildID); err != nil {
+		return nil, err
+	}
+	return apt, nil
+}
+
+func childOf(a Appointment) string {
+	return a.ChildID
+}
@@ -0,0 +1,219 @@
+package appointment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+var (
+	ErrUnknownWellVisit       = errors.New("unknown well visit")
+	ErrWellVisitType          = errors.New("well_visit is only for appointments of type well_visit")
+	ErrSuggestionsUnavailable = errors.New("well-visit suggestions are not available")
+	ErrChildNotFound          = errors.New("child not found")
+	ErrNotMember              = errors.New("user is not a member of this family")
+)
+
+const (
+	// A checkup is suggested from two weeks before it falls due, and for a
+	// month after, before it counts as overdue
+	dueBefore = 14 * 24 * time.Hour
+	dueAfter  = 30 * 24 * time.Hour
+
+	// growthReminderWindow is how long after a well visit the family is
+	// still asked to record the measurements taken at it
+	growthReminderWindow = 7 * 24 * time.Hour
+)
+
+// wellVisitSchedule lists routine checkups by age, following the AAP Bright
+// Futures periodicity schedule for the first three years
+var wellVisitSchedule = []WellVisit{
+	{ID: "2w", Name: "2 week checkup", Description: "Weight regained since birth, feeding and jaundice check", AgeWeeks: 2, AgeLabel: "2 weeks"},
+	{ID: "1m", Name: "1 month checkup", Description: "Growth, feeding and sleep review", AgeMonths: 1, AgeLabel: "1 month",
+		Screenings: []string{"hearing"}},
+	{ID: "2m", Name: "2 month checkup", Description: "Growth and development; first vaccinations are usually due", AgeMonths: 2, AgeLabel: "2 months"},
+	{ID: "4m", Name: "4 month checkup", Description: "Growth, development and vaccinations", AgeMonths: 4, AgeLabel: "4 months"},
+	{ID: "6m", Name: "6 month checkup", Description: "Growth, starting solids and vaccinations", AgeMonths: 6, AgeLabel: "6 months"},
+	{ID: "9m", Name: "9 month checkup", Description: "Growth and developmental screening", AgeMonths: 9, AgeLabel: "9 months",
+		Screenings: []string{"developmental"}},
+	{ID: "12m", Name: "12 month checkup", Description: "Growth, anaemia and lead screening, vaccinations", AgeMonths: 12, AgeLabel: "12 months",
+		Screenings: []string{"anaemia", "lead"}},
+	{ID: "15m", Name: "15 month checkup", Description: "Growth, development and vaccinations", AgeMonths: 15, AgeLabel: "15 months"},
+	{ID: "18m", Name: "18 month checkup", Description: "Growth, developmental and autism screening", AgeMonths: 18, AgeLabel: "18 months",
+		Screenings: []string{"developmental", "autism"}},
+	{ID: "24m", Name: "2 year checkup", Description: "Growth, BMI, developmental and autism screening", AgeMonths: 24, AgeLabel: "2 years",
+		Screenings: []string{"developmental", "autism", "lead"}},
+	{ID: "30m", Name: "30 month checkup", Description: "Growth and developmental screening", AgeMonths: 30, AgeLabel: "30 months",
+		Screenings: []string{"developmental"}},
+	{ID: "3y", Name: "3 year checkup", Description: "Growth, BMI, blood pressure and vision", AgeMonths: 36, AgeLabel: "3 years",
+		Screenings: []string{"vision", "blood_pressure"}},
+}
+
+// screenings are the checks the well-visit schedule refers to
+var screenings = map[string]Screening{
+	"hearing":        {ID: "hearing", Name: "Hearing screen result confirmed"},
+	"developmental":  {ID: "developmental", Name: "Developmental screening"},
+	"autism":         {ID: "autism", Name: "Autism screening"},
+	"anaemia":        {ID: "anaemia", Name: "Anaemia screening"},
+	"lead":           {ID: "lead", Name: "Lead screening"},
+	"vision":         {ID: "vision", Name: "Vision screening"},
+	"blood_pressure": {ID: "blood_pressure", Name: "Blood pressure"},
+}
+
+// WithChildren supplies children's birth dates for well-visit suggestions,
+// and checks the requester belongs to the child's family
+func WithChildren(familyService family.Service) Option {
+	return func(s *service) {
+		s.familyService = familyService
+	}
+}
+
+func (s *service) GetWellVisitSchedule() []WellVisit {
+	return wellVisitSchedule
+}
+
+// dueOn returns the date a checkup falls due for a child born on birth
+func (v WellVisit) dueOn(birth time.Time) time.Time {
+	return birth.AddDate(0, v.AgeMonths, v.AgeWeeks*7)
+}
+
+func wellVisitByID(id string) (WellVisit, bool) {
+	i := slices.IndexFunc(wellVisitSchedule, func(v WellVisit) bool { return v.ID == id })
+	if i < 0 {
+		return WellVisit{}, false
+	}
+	return wellVisitSchedule[i], true
+}
+
+func validateWellVisit(req *CreateAppointmentRequest) error {
+	if req.WellVisit == "" {
+		return nil
+	}
+	if _, ok := wellVisitByID(req.WellVisit); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownWellVisit, req.WellVisit)
+	}
+	if req.Type != AppointmentTypeWellVisit {
+		return ErrWellVisitType
+	}
+	return nil
+}
+
+// SuggestWellVisits lays the well-visit schedule over the child's age and
+// their well-visit appointments, so parents can see which checkups to book
+func (s *service) SuggestWellVisits(ctx context.Context, userID, childID string) ([]WellVisitSuggestion, error) {
+	if s.familyService == nil {
+		return nil, ErrSuggestionsUnavailable
+	}
+	child, err := s.authorizeChild(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+	return s.suggestWellVisits(ctx, child)
+}
+
+func (s *service) suggestWellVisits(ctx context.Context, child *family.Child) ([]WellVisitSuggestion, error) {
+	childID := child.ID
+	wellVisit := AppointmentTypeWellVisit
+	apts, err := s.repo.List(ctx, &AppointmentFilter{ChildID: childID, Type: &wellVisit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list well visits: %w", err)
+	}
+	// A completed appointment wins over one that is only booked
+	booked := make(map[string]*Appointment)
+	for i := range apts {
+		a := &apts[i]
+		if a.WellVisit == "" || a.Cancelled {
+			continue
+		}
+		if prev, ok := booked[a.WellVisit]; !ok || (a.Completed && !prev.Completed) {
+			booked[a.WellVisit] = a
+		}
+	}
+
+	now := s.now()
+	suggestions := make([]WellVisitSuggestion, len(wellVisitSchedule))
+	for i, v := range wellVisitSchedule {
+		due := v.dueOn(child.DateOfBirth)
+		sg := WellVisitSuggestion{WellVisit: v, DueOn: due.Format(time.DateOnly)}
+		switch apt := booked[v.ID]; {
+		case apt != nil && apt.Completed:
+			sg.Status, sg.AppointmentID = WellVisitDone, apt.ID
+		case apt != nil:
+			sg.Status, sg.AppointmentID = WellVisitBooked, apt.ID
+		case now.Before(due.Add(-dueBefore)):
+			sg.Status = WellVisitUpcoming
+		case !now.After(due.Add(dueAfter)):
+			sg.Status = WellVisitDue
+		case i+1 < len(wellVisitSchedule) && !now.Before(wellVisitSchedule[i+1].dueOn(child.DateOfBirth)):
+			sg.Status = WellVisitMissed
+		default:
+			sg.Status = WellVisitOverdue
+		}
+		suggestions[i] = sg
+	}
+	return suggestions, nil
+}
+
+// Screenings lists the screenings of the well-visit schedule for the child,
+// each marked done once the checkup it belongs to has been completed
+func (s *service) Screenings(ctx context.Context, userID, childID string) ([]ScreeningDue, error) {
+	if s.familyService == nil {
+		return nil, ErrSuggestionsUnavailable
+	}
+	child, err := s.authorizeChild(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+	return s.screenings(ctx, child)
+}
+
+func (s *service) PendingScreenings(ctx context.Context, child *family.Child) ([]ScreeningDue, error) {
+	all, err := s.screenings(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(all, func(sc ScreeningDue) bool { return sc.Status != ScreeningPending }), nil
+}
+
+func (s *service) screenings(ctx context.Context, child *family.Child) ([]ScreeningDue, error) {
+	suggestions, err := s.suggestWellVisits(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+
+	due := []ScreeningDue{}
+	for _, sg := range suggestions {
+		status := ScreeningPending
+		switch sg.Status {
+		case WellVisitDone:
+			status = ScreeningDone
+		case WellVisitMissed:
+			status = ScreeningMissed
+		}
+		for _, id := range sg.Screenings {
+			due = append(due, ScreeningDue{
+				Screening:     screenings[id],
+				WellVisit:     sg.ID,
+				WellVisitName: sg.Name,
+				DueOn:         sg.DueOn,
+				Status:        status,
+				AppointmentID: sg.AppointmentID,
+			})
+		}
+	}
+	return due, nil
+}
+
+// DueGrowthReminders returns the well visits that ended within the last week
+// and whose family has not yet been asked to record the measurements taken
+func (s *service) DueGrowthReminders(ctx context.Context, now time.Time) ([]Appointment, error) {
+	return s.repo.EndedWellVisits(ctx, now.Add(-growthReminderWindow), now)
+}
+
+func (s *service) MarkGrowthReminded(ctx context.Context, id string, at time.Time) error {
+	return s.repo.MarkGrowthReminded(ctx, id, at)
+}
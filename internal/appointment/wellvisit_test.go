@@ -0,0 +1,219 @@
+package appointment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+// mockFamilyService is a test double for family.Service
+type mockFamilyService struct {
+	family.Service
+	child *family.Child
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if m.child == nil || m.child.ID != childID {
+		return nil, nil
+	}
+	return m.child, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID != "user-1" {
+		return "", family.ErrNotMember
+	}
+	return "admin", nil
+}
+
+func newWellVisitService(repo Repository, now time.Time) *service {
+	child := &family.Child{ID: "child-1", FamilyID: "family-1", DateOfBirth: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)}
+	s := NewService(repo, WithChildren(&mockFamilyService{child: child})).(*service)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestService_SuggestWellVisits(t *testing.T) {
+	repo := newMockRepository()
+	repo.appointments["apt-2w"] = &Appointment{ID: "apt-2w", ChildID: "child-1", Type: AppointmentTypeWellVisit, WellVisit: "2w", Completed: true}
+	repo.appointments["apt-2m"] = &Appointment{ID: "apt-2m", ChildID: "child-1", Type: AppointmentTypeWellVisit, WellVisit: "2m"}
+	repo.appointments["apt-2m-old"] = &Appointment{ID: "apt-2m-old", ChildID: "child-1", Type: AppointmentTypeWellVisit, WellVisit: "4m", Cancelled: true}
+
+	// 3 months and 18 days old: the 1 month checkup was never booked and
+	// the 4 month one is due in 12 days
+	svc := newWellVisitService(repo, time.Date(2024, 4, 28, 9, 0, 0, 0, time.UTC))
+
+	got, err := svc.SuggestWellVisits(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("SuggestWellVisits() error = %v", err)
+	}
+	if len(got) != len(wellVisitSchedule) {
+		t.Fatalf("SuggestWellVisits() returned %d, want the whole schedule", len(got))
+	}
+
+	want := map[string]struct {
+		status WellVisitStatus
+		dueOn  string
+		aptID  string
+	}{
+		"2w": {WellVisitDone, "2024-01-24", "apt-2w"},
+		"1m": {WellVisitMissed, "2024-02-10", ""},
+		"2m": {WellVisitBooked, "2024-03-10", "apt-2m"},
+		"4m": {WellVisitDue, "2024-05-10", ""},
+		"6m": {WellVisitUpcoming, "2024-07-10", ""},
+	}
+	for _, sg := range got {
+		w, ok := want[sg.ID]
+		if !ok {
+			continue
+		}
+		if sg.Status != w.status || sg.DueOn != w.dueOn || sg.AppointmentID != w.aptID {
+			t.Errorf("%s = %s due %s (%q), want %s due %s (%q)", sg.ID, sg.Status, sg.DueOn, sg.AppointmentID, w.status, w.dueOn, w.aptID)
+		}
+	}
+}
+
+func TestService_SuggestWellVisits_Overdue(t *testing.T) {
+	// Six weeks past the 2 month checkup, with the 4 month one not yet due
+	svc := newWellVisitService(newMockRepository(), time.Date(2024, 4, 21, 9, 0, 0, 0, time.UTC))
+
+	got, err := svc.SuggestWellVisits(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("SuggestWellVisits() error = %v", err)
+	}
+	if got[2].ID != "2m" || got[2].Status != WellVisitOverdue {
+		t.Errorf("2m = %+v, want overdue", got[2])
+	}
+}
+
+func TestService_SuggestWellVisits_Errors(t *testing.T) {
+	svc := newWellVisitService(newMockRepository(), time.Now())
+
+	if _, err := svc.SuggestWellVisits(context.Background(), "user-1", "child-unknown"); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("unknown child error = %v, want ErrChildNotFound", err)
+	}
+	if _, err := svc.SuggestWellVisits(context.Background(), "user-2", "child-1"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("outsider error = %v, want ErrNotMember", err)
+	}
+	if _, err := NewService(newMockRepository()).SuggestWellVisits(context.Background(), "user-1", "child-1"); !errors.Is(err, ErrSuggestionsUnavailable) {
+		t.Errorf("without children error = %v, want ErrSuggestionsUnavailable", err)
+	}
+}
+
+func TestService_Screenings(t *testing.T) {
+	repo := newMockRepository()
+	repo.appointments["apt-9m"] = &Appointment{ID: "apt-9m", ChildID: "child-1", Type: AppointmentTypeWellVisit, WellVisit: "9m", Completed: true}
+
+	// A year and three weeks old: the 1 month checkup was missed, the 9 month
+	// one done and the 12 month one is due
+	svc := newWellVisitService(repo, time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	got, err := svc.Screenings(ctx, "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Screenings() error = %v", err)
+	}
+
+	want := map[string]struct {
+		status ScreeningStatus
+		dueOn  string
+		aptID  string
+	}{
+		"1m/hearing":       {ScreeningMissed, "2024-02-10", ""},
+		"9m/developmental": {ScreeningDone, "2024-10-10", "apt-9m"},
+		"12m/lead":         {ScreeningPending, "2025-01-10", ""},
+		"24m/lead":         {ScreeningPending, "2026-01-10", ""},
+	}
+	seen := 0
+	for _, sc := range got {
+		if sc.Name == "" {
+			t.Errorf("%s at %s has no name", sc.ID, sc.WellVisit)
+		}
+		w, ok := want[sc.WellVisit+"/"+sc.ID]
+		if !ok {
+			continue
+		}
+		seen++
+		if sc.Status != w.status || sc.DueOn != w.dueOn || sc.AppointmentID != w.aptID {
+			t.Errorf("%s at %s = %s due %s (%q), want %s due %s (%q)", sc.ID, sc.WellVisit, sc.Status, sc.DueOn, sc.AppointmentID, w.status, w.dueOn, w.aptID)
+		}
+	}
+	if seen != len(want) {
+		t.Errorf("Screenings() matched %d of %d expected screenings: %+v", seen, len(want), got)
+	}
+
+	pending, err := svc.PendingScreenings(ctx, &family.Child{ID: "child-1", DateOfBirth: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("PendingScreenings() error = %v", err)
+	}
+	for _, sc := range pending {
+		if sc.Status != ScreeningPending {
+			t.Errorf("PendingScreenings() included %s at %s, which is %s", sc.ID, sc.WellVisit, sc.Status)
+		}
+	}
+	if len(pending) == 0 || pending[0].WellVisit != "12m" {
+		t.Errorf("PendingScreenings() = %+v, want the 12 month screenings first", pending)
+	}
+
+	if _, err := svc.Screenings(ctx, "user-2", "child-1"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("outsider error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestWellVisitSchedule_ScreeningsKnown(t *testing.T) {
+	for _, v := range wellVisitSchedule {
+		for _, id := range v.Screenings {
+			if _, ok := screenings[id]; !ok {
+				t.Errorf("%s lists unknown screening %q", v.ID, id)
+			}
+		}
+	}
+}
+
+func TestService_Create_WellVisit(t *testing.T) {
+	svc := NewService(newMockRepository())
+	req := &CreateAppointmentRequest{
+		ChildID:     "child-1",
+		Type:        AppointmentTypeWellVisit,
+		Title:       "4 month checkup",
+		ScheduledAt: time.Now().Add(24 * time.Hour),
+		WellVisit:   "4m",
+	}
+
+	apt, err := svc.Create(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if apt.WellVisit != "4m" {
+		t.Errorf("WellVisit = %q, want 4m", apt.WellVisit)
+	}
+
+	req.WellVisit = "5m"
+	if _, err := svc.Create(context.Background(), req); !errors.Is(err, ErrUnknownWellVisit) {
+		t.Errorf("Create() unknown visit error = %v, want ErrUnknownWellVisit", err)
+	}
+
+	req.WellVisit, req.Type = "4m", AppointmentTypeDental
+	if _, err := svc.Create(context.Background(), req); !errors.Is(err, ErrWellVisitType) {
+		t.Errorf("Create() dental error = %v, want ErrWellVisitType", err)
+	}
+}
+
+func TestService_DueGrowthReminders(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	repo := newMockRepository()
+	repo.appointments["recent"] = &Appointment{ID: "recent", Type: AppointmentTypeWellVisit, ScheduledAt: now.Add(-2 * time.Hour), Duration: 30}
+	repo.appointments["running"] = &Appointment{ID: "running", Type: AppointmentTypeWellVisit, ScheduledAt: now.Add(-10 * time.Minute), Duration: 30}
+	repo.appointments["old"] = &Appointment{ID: "old", Type: AppointmentTypeWellVisit, ScheduledAt: now.Add(-8 * 24 * time.Hour), Duration: 30}
+
+	got, err := NewService(repo).DueGrowthReminders(context.Background(), now)
+	if err != nil {
+		t.Fatalf("DueGrowthReminders() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "recent" {
+		t.Errorf("DueGrowthReminders() = %+v, want only the visit that ended within the week", got)
+	}
+}
@@ -0,0 +1,119 @@
+// Package archive moves cold log rows out of the live feeding and sleep
+// tables into archive twins with the same columns. Each live table has a view
+// joining the two for reads that reach back into archived history.
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+// DefaultAfter keeps a little over a year live, so this month and the same
+// month last year are both read from the live tables
+const DefaultAfter = 396 * 24 * time.Hour
+
+// Table is a live log table and where its cold rows go
+type Table struct {
+	Name    string
+	Archive string
+	// Movable is an SQL condition on the live row that must hold for it to
+	// be archived. Rows other tables point at by foreign key stay live.
+	Movable string
+}
+
+var (
+	Feedings = Table{
+		Name:    "feedings",
+		Archive: "feedings_archive",
+		Movable: `NOT EXISTS (SELECT 1 FROM nursing_timers t WHERE t.feeding_id = feedings.id)
+			AND NOT EXISTS (SELECT 1 FROM milk_consumptions m WHERE m.feeding_id = feedings.id)`,
+	}
+	Sleep = Table{
+		Name:    "sleep_records",
+		Archive: "sleep_records_archive",
+		Movable: `end_time IS NOT NULL
+			AND NOT EXISTS (SELECT 1 FROM sleep_wakings w WHERE w.sleep_id = sleep_records.id)`,
+	}
+
+	// Tables lists every archived table, in the order the job moves them
+	Tables = []Table{Feedings, Sleep}
+)
+
+type Archiver interface {
+	// Move archives up to limit rows of t that started before before, and
+	// returns how many it moved
+	Move(ctx context.Context, t Table, before time.Time, limit int) (int64, error)
+	// Cutoff is the start of the oldest month still kept live at now. Rows
+	// that started before it are cold.
+	Cutoff(now time.Time) time.Time
+}
+
+type archiver struct {
+	db    *sql.DB
+	after time.Duration
+}
+
+// NewArchiver archives rows once they are older than after, counted in whole
+// months. Zero uses DefaultAfter.
+func NewArchiver(db *sql.DB, after time.Duration) Archiver {
+	if after <= 0 {
+		after = DefaultAfter
+	}
+	return &archiver{db: db, after: after}
+}
+
+// HealthCheck checks the archive tables and the views reading through them
+func (a *archiver) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, a.db, "feedings_archive", "sleep_records_archive", "feedings_all", "sleep_records_all")
+}
+
+func (a *archiver) Cutoff(now time.Time) time.Time {
+	y, m, _ := now.Add(-a.after).Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, now.Location())
+}
+
+func (a *archiver) Move(ctx context.Context, t Table, before time.Time, limit int) (int64, error) {
+	// Deleting and inserting in one statement moves each row exactly once,
+	// even if the job overlaps itself. SKIP LOCKED leaves rows being edited
+	// for the next run.
+	query := fmt.Sprintf(`
+		WITH moved AS (
+			DELETE FROM %[1]s
+			WHERE id IN (
+				SELECT id FROM %[1]s
+				WHERE start_time < $1 AND %[3]s
+				ORDER BY start_time
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		INSERT INTO %[2]s SELECT * FROM moved
+	`, t.Name, t.Archive, t.Movable)
+
+	result, err := a.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive %s: %w", t.Name, err)
+	}
+	return result.RowsAffected()
+}
+
+// Restore moves a row back from the archive into the live table, so that it
+// can be edited or deleted there. It does nothing for rows that are live. It
+// joins the transaction carried by ctx, if any.
+func Restore(ctx context.Context, conn *sql.DB, t Table, id string) error {
+	query := fmt.Sprintf(`
+		WITH restored AS (
+			DELETE FROM %s WHERE id = $1 RETURNING *
+		)
+		INSERT INTO %s SELECT * FROM restored
+	`, t.Archive, t.Name)
+
+	_, err := db.Use(ctx, conn).ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,77 @@
+package archive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCutoff_StartsAMonth(t *testing.T) {
+	a := NewArchiver(nil, 0)
+	now := time.Date(2025, 3, 20, 15, 0, 0, 0, time.UTC)
+
+	got := a.Cutoff(now)
+	want := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("Cutoff() = %v, want %v", got, want)
+	}
+}
+
+func TestCutoff_Configured(t *testing.T) {
+	a := NewArchiver(nil, 90*24*time.Hour)
+	now := time.Date(2025, 3, 20, 15, 0, 0, 0, time.UTC)
+
+	got := a.Cutoff(now)
+	want := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("Cutoff() = %v, want %v", got, want)
+	}
+}
+
+func TestMove(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	a := NewArchiver(db, 0)
+
+	before := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec("DELETE FROM sleep_records WHERE id IN \\(.*end_time IS NOT NULL.*FOR UPDATE SKIP LOCKED.*INSERT INTO sleep_records_archive SELECT \\* FROM moved").
+		WithArgs(before, 100).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	moved, err := a.Move(context.Background(), Sleep, before, 100)
+	if err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if moved != 42 {
+		t.Errorf("Move() = %d, want 42", moved)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM feedings_archive WHERE id = \\$1 RETURNING \\* \\) INSERT INTO feedings SELECT \\* FROM restored").
+		WithArgs("feeding-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := Restore(context.Background(), db, Feedings, "feeding-1"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
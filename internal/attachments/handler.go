@@ -0,0 +1,75 @@
+package attachments
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:entityType/:entityId", h.list)
+	rg.POST("/:entityType/:entityId", h.attach)
+	rg.DELETE("/:entityType/:entityId/:mediaId", h.detach)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	attachments, err := h.service.List(c.Request.Context(), c.GetString("user_id"),
+		EntityType(c.Param("entityType")), c.Param("entityId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+func (h *Handler) attach(c *gin.Context) {
+	var req AttachRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	a, err := h.service.Attach(c.Request.Context(), c.GetString("user_id"),
+		EntityType(c.Param("entityType")), c.Param("entityId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, a)
+}
+
+func (h *Handler) detach(c *gin.Context) {
+	err := h.service.Detach(c.Request.Context(), c.GetString("user_id"),
+		EntityType(c.Param("entityType")), c.Param("entityId"), c.Param("mediaId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownEntityType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrEntityNotFound), errors.Is(err, ErrMediaNotFound), errors.Is(err, ErrAttachmentNotFound),
+		errors.Is(err, visibility.ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, ErrHidden), errors.Is(err, family.ErrNotMember), errors.Is(err, visibility.ErrHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,147 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	listFn   func(ctx context.Context, userID string, entityType EntityType, entityID string) ([]Attachment, error)
+	attachFn func(ctx context.Context, userID string, entityType EntityType, entityID string, req *AttachRequest) (*Attachment, error)
+	detachFn func(ctx context.Context, userID string, entityType EntityType, entityID, mediaID string) error
+}
+
+func (m *mockService) List(ctx context.Context, userID string, entityType EntityType, entityID string) ([]Attachment, error) {
+	return m.listFn(ctx, userID, entityType, entityID)
+}
+
+func (m *mockService) Attach(ctx context.Context, userID string, entityType EntityType, entityID string, req *AttachRequest) (*Attachment, error) {
+	return m.attachFn(ctx, userID, entityType, entityID, req)
+}
+
+func (m *mockService) Detach(ctx context.Context, userID string, entityType EntityType, entityID, mediaID string) error {
+	return m.detachFn(ctx, userID, entityType, entityID, mediaID)
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterRoutes(router.Group("/attachments"))
+	return router
+}
+
+func TestHandler_List(t *testing.T) {
+	svc := &mockService{listFn: func(ctx context.Context, userID string, entityType EntityType, entityID string) ([]Attachment, error) {
+		if userID != "test-user-123" || entityType != EntityGrowth || entityID != "growth-1" {
+			t.Errorf("List() called with %s, %s, %s", userID, entityType, entityID)
+		}
+		return []Attachment{{ID: "att-1", MediaID: "media-1"}}, nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("GET", "/attachments/growth/growth-1", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var got []Attachment
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || len(got) != 1 {
+		t.Errorf("Expected one attachment, got %s", w.Body.String())
+	}
+}
+
+func TestHandler_Attach(t *testing.T) {
+	svc := &mockService{attachFn: func(ctx context.Context, userID string, entityType EntityType, entityID string, req *AttachRequest) (*Attachment, error) {
+		return &Attachment{ID: "att-1", EntityType: entityType, EntityID: entityID, MediaID: req.MediaID, Caption: req.Caption}, nil
+	}}
+
+	body, _ := json.Marshal(AttachRequest{MediaID: "media-1", Caption: "Shot day"})
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("POST", "/attachments/vaccination/vax-1", bytes.NewReader(body)))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+}
+
+func TestHandler_Attach_MissingMedia(t *testing.T) {
+	w := httptest.NewRecorder()
+	setupRouter(&mockService{}).ServeHTTP(w, httptest.NewRequest("POST", "/attachments/note/note-1", bytes.NewReader([]byte(`{}`))))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrUnknownEntityType, http.StatusBadRequest},
+		{ErrEntityNotFound, http.StatusNotFound},
+		{ErrAttachmentNotFound, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+		{ErrHidden, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			svc := &mockService{detachFn: func(ctx context.Context, userID string, entityType EntityType, entityID, mediaID string) error {
+				return tt.err
+			}}
+
+			w := httptest.NewRecorder()
+			setupRouter(svc).ServeHTTP(w, httptest.NewRequest("DELETE", "/attachments/note/note-1/media-1", nil))
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_PrivateNoteOfAnotherMember(t *testing.T) {
+	svc, _ := newTestService()
+	if _, err := svc.Attach(context.Background(), "user-1", EntityNote, "note-private", &AttachRequest{MediaID: "media-1"}); err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-2")
+		c.Next()
+	})
+	NewHandler(svc).RegisterRoutes(router.Group("/attachments"))
+
+	requests := []*http.Request{
+		httptest.NewRequest("GET", "/attachments/note/note-private", nil),
+		httptest.NewRequest("POST", "/attachments/note/note-private", bytes.NewBufferString(`{"media_id":"media-1"}`)),
+		httptest.NewRequest("DELETE", "/attachments/note/note-private/media-1", nil),
+	}
+	for _, req := range requests {
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected status 404, got %d", req.Method, req.URL.Path, w.Code)
+		}
+	}
+}
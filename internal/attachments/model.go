@@ -0,0 +1,29 @@
+package attachments
+
+import "time"
+
+// EntityType is the kind of record a file can be attached to. Milestones are
+// notes tagged "milestone", so they take attachments as notes.
+type EntityType string
+
+const (
+	EntityNote        EntityType = "note"
+	EntityGrowth      EntityType = "growth"
+	EntityVaccination EntityType = "vaccination"
+)
+
+// Attachment links an uploaded file from the media store to a record
+type Attachment struct {
+	ID         string     `json:"id"`
+	EntityType EntityType `json:"entity_type"`
+	EntityID   string     `json:"entity_id"`
+	MediaID    string     `json:"media_id"`
+	Caption    string     `json:"caption,omitempty"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type AttachRequest struct {
+	MediaID string `json:"media_id" binding:"required"`
+	Caption string `json:"caption"`
+}
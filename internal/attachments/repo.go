@@ -0,0 +1,90 @@
+package attachments
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	List(ctx context.Context, entityType EntityType, entityID string) ([]Attachment, error)
+	// Create adds the link, or updates the caption if the file is already attached
+	Create(ctx context.Context, a *Attachment) error
+	Delete(ctx context.Context, entityType EntityType, entityID, mediaID string) (bool, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks that attachment records can be read
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "attachments")
+}
+
+func (r *repository) List(ctx context.Context, entityType EntityType, entityID string) ([]Attachment, error) {
+	query := `
+		SELECT id, entity_type, entity_id, media_id, caption, created_by, created_at
+		FROM attachments
+		WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	attachments := []Attachment{}
+	for rows.Next() {
+		var a Attachment
+		var caption, createdBy sql.NullString
+		if err := rows.Scan(&a.ID, &a.EntityType, &a.EntityID, &a.MediaID, &caption, &createdBy, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.Caption = caption.String
+		a.CreatedBy = createdBy.String
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (r *repository) Create(ctx context.Context, a *Attachment) error {
+	query := `
+		INSERT INTO attachments (id, entity_type, entity_id, media_id, caption, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (entity_type, entity_id, media_id) DO UPDATE SET caption = EXCLUDED.caption
+		RETURNING id, created_by, created_at
+	`
+
+	var createdBy sql.NullString
+	err := r.db.QueryRowContext(ctx, query,
+		a.ID, a.EntityType, a.EntityID, a.MediaID,
+		sql.NullString{String: a.Caption, Valid: a.Caption != ""},
+		sql.NullString{String: a.CreatedBy, Valid: a.CreatedBy != ""},
+		a.CreatedAt,
+	).Scan(&a.ID, &createdBy, &a.CreatedAt)
+	if err != nil {
+		return err
+	}
+	a.CreatedBy = createdBy.String
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, entityType EntityType, entityID, mediaID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM attachments WHERE entity_type = $1 AND entity_id = $2 AND media_id = $3`,
+		entityType, entityID, mediaID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
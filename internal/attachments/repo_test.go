@@ -0,0 +1,79 @@
+package attachments
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, entity_type, entity_id, media_id, caption").
+		WithArgs(EntityGrowth, "growth-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "entity_type", "entity_id", "media_id", "caption", "created_by", "created_at"}).
+			AddRow("att-1", "growth", "growth-1", "media-1", "Six month check", "user-1", now).
+			AddRow("att-2", "growth", "growth-1", "media-2", nil, nil, now))
+
+	attachments, err := repo.List(context.Background(), EntityGrowth, "growth-1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(attachments) != 2 || attachments[0].Caption != "Six month check" || attachments[1].CreatedBy != "" {
+		t.Errorf("List() = %+v", attachments)
+	}
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	created := time.Now().Add(-time.Hour)
+	a := &Attachment{ID: "att-new", EntityType: EntityNote, EntityID: "note-1", MediaID: "media-1",
+		Caption: "First steps", CreatedBy: "user-1", CreatedAt: time.Now()}
+
+	mock.ExpectQuery("INSERT INTO attachments").
+		WithArgs("att-new", EntityNote, "note-1", "media-1", "First steps", "user-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_by", "created_at"}).AddRow("att-1", "user-2", created))
+
+	if err := repo.Create(context.Background(), a); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if a.ID != "att-1" || a.CreatedBy != "user-2" || !a.CreatedAt.Equal(created) {
+		t.Errorf("Create() on an existing link = %+v, want the original kept", a)
+	}
+}
+
+func TestRepository_Delete(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectExec("DELETE FROM attachments").
+		WithArgs(EntityVaccination, "vax-1", "media-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	deleted, err := repo.Delete(context.Background(), EntityVaccination, "vax-1", "media-1")
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if deleted {
+		t.Error("Delete() = true, want false when nothing was attached")
+	}
+}
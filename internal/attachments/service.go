@@ -0,0 +1,220 @@
+// Package attachments links uploaded files to records other than notes, such
+// as growth measurements and vaccinations. The files themselves live in the
+// media store, which handles upload, scanning and serving.
+package attachments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/media"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrUnknownEntityType  = errors.New("unknown entity type")
+	ErrEntityNotFound     = errors.New("record not found")
+	ErrMediaNotFound      = errors.New("media not found")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrNotMember          = errors.New("user is not a member of this family")
+	ErrHidden             = errors.New("these records are hidden from you")
+)
+
+// hiddenAs maps each entity type onto the record type family visibility
+// settings hide
+var hiddenAs = map[EntityType]visibility.RecordType{
+	EntityNote:        visibility.RecordNotes,
+	EntityGrowth:      visibility.RecordGrowth,
+	EntityVaccination: visibility.RecordVaccination,
+}
+
+type Service interface {
+	List(ctx context.Context, userID string, entityType EntityType, entityID string) ([]Attachment, error)
+	Attach(ctx context.Context, userID string, entityType EntityType, entityID string, req *AttachRequest) (*Attachment, error)
+	Detach(ctx context.Context, userID string, entityType EntityType, entityID, mediaID string) error
+}
+
+type Option func(*service)
+
+// WithVisibility applies family visibility settings, so members cannot see or
+// change attachments on records hidden from them
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+type service struct {
+	repo               Repository
+	familyService      family.Service
+	mediaService       media.Service
+	notesService       notes.Service
+	growthService      growth.Service
+	vaccinationService vaccination.Service
+	visibility         visibility.Service
+}
+
+func NewService(
+	repo Repository,
+	familyService family.Service,
+	mediaService media.Service,
+	notesService notes.Service,
+	growthService growth.Service,
+	vaccinationService vaccination.Service,
+	opts ...Option,
+) Service {
+	s := &service{
+		repo:               repo,
+		familyService:      familyService,
+		mediaService:       mediaService,
+		notesService:       notesService,
+		growthService:      growthService,
+		vaccinationService: vaccinationService,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether attachments can be listed
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) List(ctx context.Context, userID string, entityType EntityType, entityID string) ([]Attachment, error) {
+	if _, err := s.resolve(ctx, userID, entityType, entityID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.List(ctx, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// Attach links a file the family uploaded to the record. Attaching the same
+// file again updates its caption.
+func (s *service) Attach(ctx context.Context, userID string, entityType EntityType, entityID string, req *AttachRequest) (*Attachment, error) {
+	familyID, err := s.resolve(ctx, userID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := s.mediaService.Get(ctx, userID, req.MediaID)
+	if err != nil {
+		if errors.Is(err, media.ErrMediaNotFound) || errors.Is(err, media.ErrNotMember) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+	// Files stay within their family, even for members of both
+	if m.FamilyID != familyID {
+		return nil, ErrMediaNotFound
+	}
+
+	a := &Attachment{
+		ID:         ids.New(),
+		EntityType: entityType,
+		EntityID:   entityID,
+		MediaID:    m.ID,
+		Caption:    req.Caption,
+		CreatedBy:  userID,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.Create(ctx, a); err != nil {
+		return nil, fmt.Errorf("failed to attach media: %w", err)
+	}
+	return a, nil
+}
+
+// Detach unlinks a file from the record; the file itself stays in the media store
+func (s *service) Detach(ctx context.Context, userID string, entityType EntityType, entityID, mediaID string) error {
+	if _, err := s.resolve(ctx, userID, entityType, entityID); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, entityType, entityID, mediaID)
+	if err != nil {
+		return fmt.Errorf("failed to detach media: %w", err)
+	}
+	if !deleted {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// resolve finds the record's child and checks the user may see its records,
+// returning the child's family
+func (s *service) resolve(ctx context.Context, userID string, entityType EntityType, entityID string) (string, error) {
+	childID, err := s.childOf(ctx, userID, entityType, entityID)
+	if err != nil {
+		return "", err
+	}
+
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return "", err
+	}
+	if child == nil {
+		return "", ErrEntityNotFound
+	}
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		return "", ErrNotMember
+	}
+
+	if recordType, ok := hiddenAs[entityType]; ok && s.visibility != nil {
+		hidden, err := s.visibility.IsHidden(ctx, userID, childID, recordType)
+		if err != nil {
+			return "", err
+		}
+		if hidden {
+			return "", ErrHidden
+		}
+	}
+
+	return child.FamilyID, nil
+}
+
+// childOf returns the child the record belongs to. Another member's private
+// note is reported as not found.
+func (s *service) childOf(ctx context.Context, userID string, entityType EntityType, entityID string) (string, error) {
+	switch entityType {
+	case EntityNote:
+		n, err := s.notesService.GetVisible(ctx, entityID, userID)
+		if err != nil || n == nil {
+			return "", notFound(err)
+		}
+		return n.ChildID, nil
+	case EntityGrowth:
+		m, err := s.growthService.Get(ctx, userID, entityID)
+		if err != nil || m == nil {
+			return "", notFound(err)
+		}
+		return m.ChildID, nil
+	case EntityVaccination:
+		v, err := s.vaccinationService.Get(ctx, userID, entityID)
+		if err != nil || v == nil {
+			return "", notFound(err)
+		}
+		return v.ChildID, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownEntityType, entityType)
+	}
+}
+
+func notFound(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrEntityNotFound
+}
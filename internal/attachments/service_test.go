@@ -0,0 +1,243 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/media"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type mockRepository struct {
+	attachments []Attachment
+}
+
+func (m *mockRepository) List(ctx context.Context, entityType EntityType, entityID string) ([]Attachment, error) {
+	var out []Attachment
+	for _, a := range m.attachments {
+		if a.EntityType == entityType && a.EntityID == entityID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepository) Create(ctx context.Context, a *Attachment) error {
+	m.attachments = append(m.attachments, *a)
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, entityType EntityType, entityID, mediaID string) (bool, error) {
+	for i, a := range m.attachments {
+		if a.EntityType == entityType && a.EntityID == entityID && a.MediaID == mediaID {
+			m.attachments = append(m.attachments[:i], m.attachments[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	switch childID {
+	case "child-1":
+		return &family.Child{ID: "child-1", FamilyID: "family-1"}, nil
+	case "child-2":
+		return &family.Child{ID: "child-2", FamilyID: "family-2"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	// user-2 shares family-1 with user-1
+	if userID == "user-1" || (userID == "user-2" && familyID == "family-1") {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockMediaService struct {
+	media.Service
+}
+
+func (m *mockMediaService) Get(ctx context.Context, userID, id string) (*media.Media, error) {
+	switch id {
+	case "media-1":
+		return &media.Media{ID: "media-1", FamilyID: "family-1"}, nil
+	case "media-other":
+		return &media.Media{ID: "media-other", FamilyID: "family-2"}, nil
+	}
+	return nil, media.ErrMediaNotFound
+}
+
+type mockNotesService struct {
+	notes.Service
+}
+
+func (m *mockNotesService) GetVisible(ctx context.Context, id, userID string) (*notes.Note, error) {
+	var n *notes.Note
+	switch id {
+	case "note-1":
+		n = &notes.Note{ID: "note-1", ChildID: "child-1", AuthorID: "user-1"}
+	case "note-private":
+		n = &notes.Note{ID: "note-private", ChildID: "child-1", AuthorID: "user-1", Private: true}
+	}
+	if n == nil || !n.VisibleTo(userID) {
+		return nil, nil
+	}
+	return n, nil
+}
+
+type mockGrowthService struct {
+	growth.Service
+}
+
+func (m *mockGrowthService) Get(ctx context.Context, userID, id string) (*growth.Measurement, error) {
+	if id == "growth-1" {
+		return &growth.Measurement{ID: "growth-1", ChildID: "child-1"}, nil
+	}
+	return nil, nil
+}
+
+type mockVaccinationService struct {
+	vaccination.Service
+}
+
+func (m *mockVaccinationService) Get(ctx context.Context, userID, id string) (*vaccination.Vaccination, error) {
+	if id == "vax-1" {
+		return &vaccination.Vaccination{ID: "vax-1", ChildID: "child-1"}, nil
+	}
+	return nil, nil
+}
+
+type mockVisibilityService struct {
+	visibility.Service
+	hidden visibility.RecordType
+}
+
+func (m *mockVisibilityService) IsHidden(ctx context.Context, userID, childID string, recordType visibility.RecordType) (bool, error) {
+	return recordType == m.hidden, nil
+}
+
+func newTestService(opts ...Option) (Service, *mockRepository) {
+	repo := &mockRepository{}
+	svc := NewService(repo, &mockFamilyService{}, &mockMediaService{}, &mockNotesService{},
+		&mockGrowthService{}, &mockVaccinationService{}, opts...)
+	return svc, repo
+}
+
+func TestService_AttachAndList(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+
+	for _, entity := range []struct {
+		entityType EntityType
+		id         string
+	}{{EntityNote, "note-1"}, {EntityGrowth, "growth-1"}, {EntityVaccination, "vax-1"}} {
+		a, err := svc.Attach(ctx, "user-1", entity.entityType, entity.id, &AttachRequest{MediaID: "media-1", Caption: "Photo"})
+		if err != nil {
+			t.Fatalf("Attach(%s) error = %v", entity.entityType, err)
+		}
+		if a.ID == "" || a.CreatedBy != "user-1" || a.Caption != "Photo" {
+			t.Errorf("Attach(%s) = %+v", entity.entityType, a)
+		}
+	}
+
+	list, err := svc.List(ctx, "user-1", EntityGrowth, "growth-1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].MediaID != "media-1" {
+		t.Errorf("List() = %+v, want the growth entry's attachment only", list)
+	}
+}
+
+func TestService_Attach_Errors(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		userID     string
+		entityType EntityType
+		entityID   string
+		mediaID    string
+		want       error
+	}{
+		{"unknown type", "user-1", "feeding", "feed-1", "media-1", ErrUnknownEntityType},
+		{"missing record", "user-1", EntityNote, "note-missing", "media-1", ErrEntityNotFound},
+		{"not a member", "user-9", EntityNote, "note-1", "media-1", ErrNotMember},
+		{"missing media", "user-1", EntityNote, "note-1", "media-missing", ErrMediaNotFound},
+		{"media from another family", "user-1", EntityNote, "note-1", "media-other", ErrMediaNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Attach(ctx, tt.userID, tt.entityType, tt.entityID, &AttachRequest{MediaID: tt.mediaID})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Attach() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Hidden(t *testing.T) {
+	svc, _ := newTestService(WithVisibility(&mockVisibilityService{hidden: visibility.RecordVaccination}))
+	ctx := context.Background()
+
+	if _, err := svc.List(ctx, "user-1", EntityVaccination, "vax-1"); !errors.Is(err, ErrHidden) {
+		t.Errorf("List() error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.List(ctx, "user-1", EntityGrowth, "growth-1"); err != nil {
+		t.Errorf("List() growth error = %v, want growth visible", err)
+	}
+}
+
+func TestService_Detach(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	if _, err := svc.Attach(ctx, "user-1", EntityNote, "note-1", &AttachRequest{MediaID: "media-1"}); err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+	if err := svc.Detach(ctx, "user-1", EntityNote, "note-1", "media-1"); err != nil {
+		t.Fatalf("Detach() error = %v", err)
+	}
+	if len(repo.attachments) != 0 {
+		t.Errorf("Detach() left %+v", repo.attachments)
+	}
+	if err := svc.Detach(ctx, "user-1", EntityNote, "note-1", "media-1"); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Errorf("Detach() again error = %v, want ErrAttachmentNotFound", err)
+	}
+}
+
+func TestService_PrivateNote(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	if _, err := svc.Attach(ctx, "user-1", EntityNote, "note-private", &AttachRequest{MediaID: "media-1"}); err != nil {
+		t.Fatalf("Attach() by the author error = %v", err)
+	}
+
+	// To another member the note does not exist
+	if _, err := svc.List(ctx, "user-2", EntityNote, "note-private"); !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("List() error = %v, want %v", err, ErrEntityNotFound)
+	}
+	if _, err := svc.Attach(ctx, "user-2", EntityNote, "note-private", &AttachRequest{MediaID: "media-1"}); !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("Attach() error = %v, want %v", err, ErrEntityNotFound)
+	}
+	if err := svc.Detach(ctx, "user-2", EntityNote, "note-private", "media-1"); !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("Detach() error = %v, want %v", err, ErrEntityNotFound)
+	}
+	if len(repo.attachments) != 1 {
+		t.Errorf("attachments = %d, want the author's one left", len(repo.attachments))
+	}
+}
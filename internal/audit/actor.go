@@ -0,0 +1,17 @@
+package audit
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor returns a context whose writes are attributed to userID
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFrom returns the user writes made with ctx are attributed to, or ""
+// for writes made outside a request, such as by background jobs
+func ActorFrom(ctx context.Context) string {
+	userID, _ := ctx.Value(actorKey{}).(string)
+	return userID
+}
@@ -0,0 +1,146 @@
+// Package audit keeps a copy of a record every time it is written, so that
+// exports and reports can be reproduced as they stood on a past date.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type EntityType string
+
+const (
+	EntityFeeding     EntityType = "feeding"
+	EntitySleep       EntityType = "sleep"
+	EntityVaccination EntityType = "vaccination"
+)
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+var ErrNoHistory = errors.New("record history is not available")
+
+// Version is a record as it stood after one write. Deletes keep the last state.
+type Version struct {
+	ID         int64           `json:"id"`
+	EntityType EntityType      `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	ChildID    string          `json:"child_id"`
+	ActorID    string          `json:"actor_id,omitempty"` // who made the write, see WithActor
+	Action     Action          `json:"action"`
+	Data       json.RawMessage `json:"data"`
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+type Store interface {
+	// Record stores data as the latest version of the entity, attributed to
+	// the actor in ctx
+	Record(ctx context.Context, entityType EntityType, entityID, childID string, action Action, data any) error
+	// AsOf returns the latest version of each of the child's records of one
+	// type as they stood at asOf, leaving out records deleted by then.
+	AsOf(ctx context.Context, childID string, entityType EntityType, asOf time.Time) ([]Version, error)
+}
+
+type store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) Store {
+	return &store{db: db}
+}
+
+// HealthCheck checks the version history table
+func (s *store) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, s.db, "record_versions")
+}
+
+func (s *store) Record(ctx context.Context, entityType EntityType, entityID, childID string, action Action, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s %s: %w", entityType, entityID, err)
+	}
+
+	query := `
+		INSERT INTO record_versions (entity_type, entity_id, child_id, actor_id, action, data, recorded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	actor := ActorFrom(ctx)
+	actorID := sql.NullString{String: actor, Valid: actor != ""}
+	_, err = s.db.ExecContext(ctx, query, entityType, entityID, childID, actorID, action, payload, time.Now())
+	return err
+}
+
+func (s *store) AsOf(ctx context.Context, childID string, entityType EntityType, asOf time.Time) ([]Version, error) {
+	query := `
+		SELECT id, entity_type, entity_id, child_id, action, data, recorded_at
+		FROM (
+			SELECT DISTINCT ON (entity_id) id, entity_type, entity_id, child_id, action, data, recorded_at
+			FROM record_versions
+			WHERE child_id = $1 AND entity_type = $2 AND recorded_at <= $3
+			ORDER BY entity_id, recorded_at DESC, id DESC
+		) latest
+		WHERE action <> $4
+		ORDER BY recorded_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, childID, entityType, asOf, ActionDelete)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	versions := []Version{}
+	for rows.Next() {
+		var v Version
+		var data []byte
+		if err := rows.Scan(&v.ID, &v.EntityType, &v.EntityID, &v.ChildID, &v.Action, &data, &v.RecordedAt); err != nil {
+			return nil, err
+		}
+		v.Data = data
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// Decode unmarshals the data of each version into a T
+func Decode[T any](versions []Version) ([]T, error) {
+	out := make([]T, 0, len(versions))
+	for _, v := range versions {
+		var record T
+		if err := json.Unmarshal(v.Data, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode %s %s: %w", v.EntityType, v.EntityID, err)
+		}
+		out = append(out, record)
+	}
+	return out, nil
+}
+
+// ParseAsOf reads an as_of query value. RFC 3339 timestamps are taken as
+// given; a plain YYYY-MM-DD date means the end of that day (UTC).
+func ParseAsOf(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return nil, err
+	}
+	t = t.Add(24*time.Hour - time.Nanosecond)
+	return &t, nil
+}
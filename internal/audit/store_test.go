@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type record struct {
+	ID    string `json:"id"`
+	Notes string `json:"notes"`
+}
+
+func TestStore_Record(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	s := NewStore(db)
+
+	mock.ExpectExec("INSERT INTO record_versions").
+		WithArgs(EntitySleep, "sleep-1", "child-1", sql.NullString{String: "user-1", Valid: true}, ActionUpdate, []byte(`{"id":"sleep-1","notes":"woke once"}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = s.Record(WithActor(context.Background(), "user-1"), EntitySleep, "sleep-1", "child-1", ActionUpdate, record{ID: "sleep-1", Notes: "woke once"})
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestStore_Record_WithoutActor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	s := NewStore(db)
+
+	mock.ExpectExec("INSERT INTO record_versions").
+		WithArgs(EntityFeeding, "feeding-1", "child-1", sql.NullString{}, ActionCreate, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := s.Record(context.Background(), EntityFeeding, "feeding-1", "child-1", ActionCreate, record{ID: "feeding-1"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestStore_AsOf(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	s := NewStore(db)
+
+	asOf := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "entity_type", "entity_id", "child_id", "action", "data", "recorded_at"}).
+		AddRow(1, "sleep", "sleep-1", "child-1", "create", []byte(`{"id":"sleep-1"}`), asOf.Add(-48*time.Hour)).
+		AddRow(7, "sleep", "sleep-2", "child-1", "update", []byte(`{"id":"sleep-2","notes":"edited"}`), asOf.Add(-time.Hour))
+
+	mock.ExpectQuery("SELECT DISTINCT ON \\(entity_id\\)").
+		WithArgs("child-1", EntitySleep, asOf, ActionDelete).
+		WillReturnRows(rows)
+
+	versions, err := s.AsOf(context.Background(), "child-1", EntitySleep, asOf)
+	if err != nil {
+		t.Fatalf("AsOf() error = %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("AsOf() returned %d versions, want 2", len(versions))
+	}
+
+	records, err := Decode[record](versions)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if records[1].ID != "sleep-2" || records[1].Notes != "edited" {
+		t.Errorf("Decode() = %+v, want sleep-2 as edited", records[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestParseAsOf(t *testing.T) {
+	got, err := ParseAsOf("2024-03-01")
+	if err != nil {
+		t.Fatalf("ParseAsOf() error = %v", err)
+	}
+	want := time.Date(2024, 3, 1, 23, 59, 59, 999999999, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("ParseAsOf(date) = %v, want end of day %v", got, want)
+	}
+
+	got, err = ParseAsOf("2024-03-01T09:30:00Z")
+	if err != nil || !got.Equal(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("ParseAsOf(timestamp) = %v, %v", got, err)
+	}
+
+	if got, err := ParseAsOf(""); got != nil || err != nil {
+		t.Errorf("ParseAsOf(\"\") = %v, %v; want nil, nil", got, err)
+	}
+
+	if _, err := ParseAsOf("yesterday"); err == nil {
+		t.Error("ParseAsOf() should reject unparseable values")
+	}
+}
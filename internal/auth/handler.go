@@ -1,20 +1,30 @@
 package auth
 
 import (
+	"log"
 	"net/http"
 	"strings"
 
+	"github.com/ninenine/babytrack/internal/quota"
+
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
 	service Service
+	quotas  quota.Service
 }
 
 func NewHandler(service Service) *Handler {
 	return &Handler{service: service}
 }
 
+// WithUsage reports the user's API quota usage on GET /me
+func (h *Handler) WithUsage(quotas quota.Service) *Handler {
+	h.quotas = quotas
+	return h
+}
+
 func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.GET("/google", h.googleAuth)
 	rg.GET("/google/callback", h.googleCallback)
@@ -31,33 +41,52 @@ func (h *Handler) googleAuth(c *gin.Context) {
 	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
 	c.SetCookie("oauth_state", state, 600, "/", "", secure, true)
 
+	// Remember which web client started sign-in so the token goes back to it
+	if _, ok := loginPages[c.Query("client")]; ok {
+		c.SetCookie("oauth_client", c.Query("client"), 600, "/", "", secure, true)
+	}
+
 	c.Redirect(http.StatusTemporaryRedirect, url)
 }
 
+// loginPages maps the web clients that can start sign-in to the page that
+// receives the token. Anything else goes to the main app's /login.
+var loginPages = map[string]string{
+	"dashboard": "/app/login",
+}
+
 // GET /api/auth/google/callback - Handle OAuth callback
 func (h *Handler) googleCallback(c *gin.Context) {
 	code := c.Query("code")
 	state := c.Query("state")
 	errorParam := c.Query("error")
 
+	loginPage := "/login"
+	if client, err := c.Cookie("oauth_client"); err == nil {
+		if page, ok := loginPages[client]; ok {
+			loginPage = page
+		}
+		c.SetCookie("oauth_client", "", -1, "/", "", false, true)
+	}
+
 	if errorParam != "" {
-		c.Redirect(http.StatusTemporaryRedirect, "/login?error="+errorParam)
+		c.Redirect(http.StatusTemporaryRedirect, loginPage+"?error="+errorParam)
 		return
 	}
 
 	if code == "" || state == "" {
-		c.Redirect(http.StatusTemporaryRedirect, "/login?error=missing_params")
+		c.Redirect(http.StatusTemporaryRedirect, loginPage+"?error=missing_params")
 		return
 	}
 
 	resp, err := h.service.HandleGoogleCallback(c.Request.Context(), code, state)
 	if err != nil {
-		c.Redirect(http.StatusTemporaryRedirect, "/login?error=auth_failed")
+		c.Redirect(http.StatusTemporaryRedirect, loginPage+"?error=auth_failed")
 		return
 	}
 
 	// Redirect to frontend with token
-	c.Redirect(http.StatusTemporaryRedirect, "/login?token="+resp.Token)
+	c.Redirect(http.StatusTemporaryRedirect, loginPage+"?token="+resp.Token)
 }
 
 // POST /api/auth/refresh - Refresh JWT token
@@ -91,7 +120,20 @@ func (h *Handler) getCurrentUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	if h.quotas == nil {
+		c.JSON(http.StatusOK, user)
+		return
+	}
+
+	// Usage is informational; the profile is still returned without it
+	usage, err := h.quotas.Usage(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Printf("[auth] Failed to get usage for %s: %v", user.ID, err)
+	}
+	c.JSON(http.StatusOK, struct {
+		*User
+		Usage *quota.Usage `json:"usage,omitempty"`
+	}{user, usage})
 }
 
 func extractToken(c *gin.Context) string {
@@ -9,6 +9,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/quota"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -58,6 +61,7 @@ func (m *mockService) GetUserByID(ctx context.Context, id string) (*User, error)
 func setupTestRouter(h *Handler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(apispectest.Record(""))
 	rg := router.Group("/api/auth")
 	h.RegisterRoutes(rg)
 	return router
@@ -162,6 +166,29 @@ func TestHandler_GoogleCallback_Success(t *testing.T) {
 	}
 }
 
+func TestHandler_GoogleCallback_DashboardClient(t *testing.T) {
+	mockSvc := &mockService{
+		callbackResp: &AuthResponse{
+			User:  &User{ID: "user-123"},
+			Token: "jwt-token-here",
+		},
+	}
+	handler := NewHandler(mockSvc)
+	router := setupTestRouter(handler)
+
+	req, _ := http.NewRequest("GET", "/api/auth/google/callback?code=auth-code&state=valid-state", http.NoBody)
+	req.AddCookie(&http.Cookie{Name: "oauth_client", Value: "dashboard"})
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	location := resp.Header().Get("Location")
+	expectedLocation := "/app/login?token=jwt-token-here"
+	if location != expectedLocation {
+		t.Errorf("expected redirect to %s, got %s", expectedLocation, location)
+	}
+}
+
 func TestHandler_GoogleCallback_ErrorParam(t *testing.T) {
 	mockSvc := &mockService{}
 	handler := NewHandler(mockSvc)
@@ -459,6 +486,50 @@ func TestHandler_GetCurrentUser_Success(t *testing.T) {
 	}
 }
 
+// mockQuotaService is a test double for quota.Service
+type mockQuotaService struct {
+	quota.Service
+	usage *quota.Usage
+}
+
+func (m *mockQuotaService) Usage(ctx context.Context, userID string) (*quota.Usage, error) {
+	return m.usage, nil
+}
+
+func TestHandler_GetCurrentUser_WithUsage(t *testing.T) {
+	mockSvc := &mockService{
+		validateUser: &User{ID: "user-123", Email: "test@example.com", Name: "Test User"},
+	}
+	usage := &quota.Usage{Plan: "free", User: quota.Quota{Limit: 100, Used: 40, Remaining: 60}}
+	handler := NewHandler(mockSvc).WithUsage(&mockQuotaService{usage: usage})
+	router := setupTestRouter(handler)
+
+	req, _ := http.NewRequest("GET", "/api/auth/me", http.NoBody)
+	req.Header.Set("Authorization", "Bearer valid-jwt-token")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var result struct {
+		User
+		Usage *quota.Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if result.ID != "user-123" {
+		t.Errorf("expected user ID user-123, got %s", result.ID)
+	}
+	if result.Usage == nil || result.Usage.Plan != "free" || result.Usage.User.Remaining != 60 {
+		t.Errorf("expected usage in the profile, got %+v", result.Usage)
+	}
+}
+
 func TestHandler_GetCurrentUser_WithQueryParam(t *testing.T) {
 	testUser := &User{
 		ID:        "user-123",
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+
+	"github.com/ninenine/babytrack/internal/health"
 )
 
 type Repository interface {
@@ -21,6 +23,11 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
+// HealthCheck checks the users table that every sign-in reads
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "users")
+}
+
 func (r *repository) GetUserByID(ctx context.Context, id string) (*User, error) {
 	query := `
 		SELECT id, email, name, avatar_url, created_at, updated_at
@@ -6,6 +6,9 @@ import (
 	"encoding/hex"
 	"fmt"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
 )
 
 type Service interface {
@@ -32,6 +35,12 @@ func NewService(repo Repository, googleClient *GoogleOAuthClient, jwtManager *JW
 	}
 }
 
+// HealthCheck reports whether users can be looked up; without them nobody
+// can sign in
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
 func (s *service) GetGoogleAuthURL() (string, string) {
 	state := generateState()
 	s.states[state] = time.Now().Add(10 * time.Minute)
@@ -68,7 +77,7 @@ func (s *service) HandleGoogleCallback(ctx context.Context, code, state string)
 	if user == nil {
 		// Create new user
 		user = &User{
-			ID:        generateID(),
+			ID:        ids.New(),
 			Email:     userInfo.Email,
 			Name:      userInfo.Name,
 			AvatarURL: userInfo.Picture,
@@ -149,9 +158,3 @@ func generateState() string {
 	rand.Read(b) //nolint:errcheck // crypto/rand.Read rarely fails
 	return hex.EncodeToString(b)
 }
-
-func generateID() string {
-	b := make([]byte, 16)
-	rand.Read(b) //nolint:errcheck // crypto/rand.Read rarely fails
-	return hex.EncodeToString(b)
-}
@@ -0,0 +1,118 @@
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	checkFn func(ctx context.Context, userID string, checks []Check) ([]Result, error)
+}
+
+func (m *mockService) Check(ctx context.Context, userID string, checks []Check) ([]Result, error) {
+	if m.checkFn != nil {
+		return m.checkFn(ctx, userID, checks)
+	}
+	return []Result{}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterRoutes(protected.Group("/authz"))
+	return router
+}
+
+func TestCheck_Success(t *testing.T) {
+	var gotUserID string
+	svc := &mockService{
+		checkFn: func(ctx context.Context, userID string, checks []Check) ([]Result, error) {
+			gotUserID = userID
+			results := make([]Result, 0, len(checks))
+			for _, c := range checks {
+				results = append(results, Result{Action: c.Action, Resource: c.Resource, Allowed: c.Action == "view"})
+			}
+			return results, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"checks":[{"action":"view","resource":"family:family-1"},{"action":"delete","resource":"family:family-1"}]}`
+	req := httptest.NewRequest("POST", "/authz/check", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if gotUserID != "test-user-123" {
+		t.Errorf("userID = %q, want test-user-123", gotUserID)
+	}
+
+	var resp CheckResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(resp.Results) != 2 || !resp.Results[0].Allowed || resp.Results[1].Allowed {
+		t.Errorf("Results = %+v", resp.Results)
+	}
+}
+
+func TestCheck_InvalidBody(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	for _, body := range []string{
+		`{"checks":[]}`,
+		`{"checks":[{"action":"view"}]}`,
+		`not json`,
+	} {
+		req := httptest.NewRequest("POST", "/authz/check", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: expected status 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestCheck_ServiceError(t *testing.T) {
+	svc := &mockService{
+		checkFn: func(ctx context.Context, userID string, checks []Check) ([]Result, error) {
+			return nil, errors.New("database error")
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/authz/check", bytes.NewBufferString(`{"checks":[{"action":"view","resource":"child:child-1"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,35 @@
+package authz
+
+// MaxChecks caps how many checks one request may ask for
+const MaxChecks = 100
+
+// Why a check was denied
+const (
+	ReasonUnknownAction   = "unknown_action"
+	ReasonUnknownResource = "unknown_resource"
+	ReasonNotMember       = "not_member"
+	ReasonAdminOnly       = "admin_only"
+)
+
+// Check asks whether the current user may take action on resource. Resource
+// is "family:<id>" or "child:<id>".
+type Check struct {
+	Action   string `json:"action" binding:"required"`
+	Resource string `json:"resource" binding:"required"`
+}
+
+type CheckRequest struct {
+	Checks []Check `json:"checks" binding:"required,min=1,max=100,dive"`
+}
+
+// Result answers one Check, in the order they were asked
+type Result struct {
+	Action   string `json:"action"`
+	Resource string `json:"resource"`
+	Allowed  bool   `json:"allowed"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type CheckResponse struct {
+	Results []Result `json:"results"`
+}
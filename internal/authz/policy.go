@@ -0,0 +1,36 @@
+package authz
+
+// Family roles, from least to most privileged
+const (
+	roleMember = "member"
+	roleAdmin  = "admin"
+)
+
+// familyActions maps what can be done to a family to the role it needs.
+// They mirror the checks the services make when the action is attempted.
+var familyActions = map[string]string{
+	"view":                   roleMember,
+	"leave":                  roleMember,
+	"delete":                 roleAdmin,
+	"invite":                 roleAdmin, // invite, list and resend invites
+	"manage_visibility":      roleAdmin,
+	"manage_health_info":     roleAdmin,
+	"manage_stats_sharing":   roleAdmin,
+	"manage_closures":        roleAdmin,
+	"manage_integrations":    roleAdmin,
+	"rotate_inbound_address": roleAdmin,
+	"reset_sandbox":          roleAdmin,
+}
+
+// childActions is the same for a child, judged by the role in the child's
+// family. A professional with an active grant may also view the child.
+var childActions = map[string]string{
+	"view":          roleMember,
+	"log":           roleMember,
+	"manage_grants": roleAdmin,
+}
+
+// satisfies reports whether role is at least required
+func satisfies(role, required string) bool {
+	return role == roleAdmin || role == required
+}
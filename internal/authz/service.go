@@ -0,0 +1,127 @@
+// Package authz answers in one call what the current user may do to a list
+// of families and children, so clients can show only the actions that will
+// succeed. It decides from the same family roles and access grants the
+// services enforce.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/grants"
+)
+
+var ErrTooManyChecks = fmt.Errorf("at most %d checks per request", MaxChecks)
+
+type Service interface {
+	Check(ctx context.Context, userID string, checks []Check) ([]Result, error)
+}
+
+type service struct {
+	familyService family.Service
+	grantsService grants.Service
+}
+
+func NewService(familyService family.Service, grantsService grants.Service) Service {
+	return &service{
+		familyService: familyService,
+		grantsService: grantsService,
+	}
+}
+
+func (s *service) Check(ctx context.Context, userID string, checks []Check) ([]Result, error) {
+	if len(checks) > MaxChecks {
+		return nil, ErrTooManyChecks
+	}
+
+	// Roles are looked up once per family however many checks name it
+	roles := make(map[string]string)
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		reason, err := s.decide(ctx, userID, c, roles)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, Result{Action: c.Action, Resource: c.Resource, Allowed: reason == "", Reason: reason})
+	}
+	return results, nil
+}
+
+// decide returns why the check is denied, or "" when it is allowed
+func (s *service) decide(ctx context.Context, userID string, c Check, roles map[string]string) (string, error) {
+	kind, id, ok := strings.Cut(c.Resource, ":")
+	if !ok || id == "" {
+		return ReasonUnknownResource, nil
+	}
+
+	switch kind {
+	case "family":
+		required, ok := familyActions[c.Action]
+		if !ok {
+			return ReasonUnknownAction, nil
+		}
+		role, err := s.role(ctx, id, userID, roles)
+		if err != nil {
+			return "", err
+		}
+		return judge(role, required), nil
+
+	case "child":
+		required, ok := childActions[c.Action]
+		if !ok {
+			return ReasonUnknownAction, nil
+		}
+		child, err := s.familyService.GetChild(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		// A missing child reads the same as someone else's
+		if child == nil {
+			return ReasonNotMember, nil
+		}
+		role, err := s.role(ctx, child.FamilyID, userID, roles)
+		if err != nil {
+			return "", err
+		}
+		if role == "" && c.Action == "view" && s.grantsService != nil {
+			granted, err := s.grantsService.HasAccess(ctx, userID, id)
+			if err != nil {
+				return "", err
+			}
+			if granted {
+				return "", nil
+			}
+		}
+		return judge(role, required), nil
+	}
+	return ReasonUnknownResource, nil
+}
+
+// role returns the user's role in the family, "" when they are not a member
+func (s *service) role(ctx context.Context, familyID, userID string, roles map[string]string) (string, error) {
+	if role, ok := roles[familyID]; ok {
+		return role, nil
+	}
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if errors.Is(err, family.ErrNotMember) {
+		role, err = "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get member role: %w", err)
+	}
+	roles[familyID] = role
+	return role, nil
+}
+
+func judge(role, required string) string {
+	switch {
+	case role == "":
+		return ReasonNotMember
+	case !satisfies(role, required):
+		return ReasonAdminOnly
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/grants"
+)
+
+type mockFamilyService struct {
+	family.Service
+	roles       map[string]string
+	children    map[string]*family.Child
+	roleLookups int
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	m.roleLookups++
+	if familyID == "family-broken" {
+		return "", errors.New("database error")
+	}
+	if role, ok := m.roles[familyID+"/"+userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+type mockGrantsService struct {
+	grants.Service
+	access map[string]bool // userID/childID -> active grant
+}
+
+func (m *mockGrantsService) HasAccess(ctx context.Context, userID, childID string) (bool, error) {
+	return m.access[userID+"/"+childID], nil
+}
+
+func newTestService() (Service, *mockFamilyService) {
+	families := &mockFamilyService{
+		roles: map[string]string{
+			"family-1/admin-1":  "admin",
+			"family-1/member-1": "member",
+		},
+		children: map[string]*family.Child{
+			"child-1": {ID: "child-1", FamilyID: "family-1"},
+		},
+	}
+	grantsSvc := &mockGrantsService{access: map[string]bool{"doctor-1/child-1": true}}
+	return NewService(families, grantsSvc), families
+}
+
+func TestService_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		check      Check
+		wantReason string
+	}{
+		{"member views family", "member-1", Check{"view", "family:family-1"}, ""},
+		{"member deletes family", "member-1", Check{"delete", "family:family-1"}, ReasonAdminOnly},
+		{"admin deletes family", "admin-1", Check{"delete", "family:family-1"}, ""},
+		{"stranger views family", "stranger-1", Check{"view", "family:family-1"}, ReasonNotMember},
+		{"member logs for child", "member-1", Check{"log", "child:child-1"}, ""},
+		{"member manages grants", "member-1", Check{"manage_grants", "child:child-1"}, ReasonAdminOnly},
+		{"professional views granted child", "doctor-1", Check{"view", "child:child-1"}, ""},
+		{"professional logs for granted child", "doctor-1", Check{"log", "child:child-1"}, ReasonNotMember},
+		{"missing child", "admin-1", Check{"view", "child:child-404"}, ReasonNotMember},
+		{"unknown action", "admin-1", Check{"fly", "family:family-1"}, ReasonUnknownAction},
+		{"unknown resource type", "admin-1", Check{"view", "feeding:feeding-1"}, ReasonUnknownResource},
+		{"resource without ID", "admin-1", Check{"view", "family"}, ReasonUnknownResource},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService()
+			results, err := svc.Check(context.Background(), tt.userID, []Check{tt.check})
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("Check() = %d results, want 1", len(results))
+			}
+			got := results[0]
+			if got.Reason != tt.wantReason || got.Allowed != (tt.wantReason == "") {
+				t.Errorf("Check() = %+v, want reason %q", got, tt.wantReason)
+			}
+			if got.Action != tt.check.Action || got.Resource != tt.check.Resource {
+				t.Errorf("Check() echoed %s %s, want %s %s", got.Action, got.Resource, tt.check.Action, tt.check.Resource)
+			}
+		})
+	}
+}
+
+func TestService_Check_LooksUpEachFamilyOnce(t *testing.T) {
+	svc, families := newTestService()
+
+	checks := []Check{
+		{"view", "family:family-1"},
+		{"delete", "family:family-1"},
+		{"log", "child:child-1"},
+	}
+	if _, err := svc.Check(context.Background(), "admin-1", checks); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if families.roleLookups != 1 {
+		t.Errorf("role lookups = %d, want 1", families.roleLookups)
+	}
+}
+
+func TestService_Check_Errors(t *testing.T) {
+	svc, _ := newTestService()
+
+	if _, err := svc.Check(context.Background(), "admin-1", []Check{{"view", "family:family-broken"}}); err == nil {
+		t.Error("Check() should return error when the role lookup fails")
+	}
+
+	checks := make([]Check, MaxChecks+1)
+	if _, err := svc.Check(context.Background(), "admin-1", checks); !errors.Is(err, ErrTooManyChecks) {
+		t.Errorf("Check() error = %v, want ErrTooManyChecks", err)
+	}
+}
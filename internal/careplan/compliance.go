@@ -0,0 +1,105 @@
+package careplan
+
+import "time"
+
+// window is the span of days a compliance summary covers, as midnights in the
+// plan's timezone, with to inclusive
+type window struct {
+	from, to time.Time
+	// today is the current day; periods after it are left out and the one
+	// containing it may still be pending
+	today time.Time
+	// planStart anchors weekly periods
+	planStart time.Time
+}
+
+type span struct {
+	start, end time.Time // end is the last day, inclusive
+}
+
+// periods lists the task's periods that overlap the window and have begun.
+// Weekly periods are kept whole so a week cut by the window is not judged on
+// part of its days.
+func (w window) periods(per Period) []span {
+	var spans []span
+	last := w.to
+	if w.today.Before(last) {
+		last = w.today
+	}
+
+	if per == PerWeek {
+		for start := w.weekStart(w.from); !start.After(last); start = start.AddDate(0, 0, 7) {
+			spans = append(spans, span{start: start, end: start.AddDate(0, 0, 6)})
+		}
+		return spans
+	}
+
+	for day := w.from; !day.After(last); day = day.AddDate(0, 0, 1) {
+		spans = append(spans, span{start: day, end: day})
+	}
+	return spans
+}
+
+// bounds returns the earliest and latest instants any period may cover, so
+// records are fetched once for all of them
+func (w window) bounds() (time.Time, time.Time) {
+	return w.weekStart(w.from), w.to.AddDate(0, 0, 7)
+}
+
+// weekStart returns the first day of the weekly period containing day
+func (w window) weekStart(day time.Time) time.Time {
+	return w.planStart.AddDate(0, 0, daysBetween(w.planStart, day)/7*7)
+}
+
+// evaluate judges each of the task's periods against the times its records
+// were made
+func (w window) evaluate(task Task, events []time.Time) TaskCompliance {
+	tc := TaskCompliance{TaskID: task.ID, Title: task.Title, Kind: task.Kind, Periods: []PeriodCompliance{}}
+
+	for _, p := range w.periods(task.Schedule.Per) {
+		next := p.end.AddDate(0, 0, 1)
+		logged := 0
+		for _, t := range events {
+			if !t.Before(p.start) && t.Before(next) {
+				logged++
+			}
+		}
+
+		pc := PeriodCompliance{
+			Start:    p.start.Format(time.DateOnly),
+			End:      p.end.Format(time.DateOnly),
+			Required: task.Schedule.Times,
+			Logged:   logged,
+		}
+		switch {
+		case logged >= task.Schedule.Times:
+			pc.Status = PeriodDone
+			tc.Due++
+			tc.Done++
+		case !p.end.Before(w.today):
+			pc.Status = PeriodPending
+		default:
+			pc.Status = PeriodMissed
+			tc.Due++
+		}
+		tc.Periods = append(tc.Periods, pc)
+	}
+
+	tc.Rate = rate(tc.Done, tc.Due)
+	return tc
+}
+
+func rate(done, due int) *float64 {
+	if due == 0 {
+		return nil
+	}
+	r := float64(done) / float64(due)
+	return &r
+}
+
+// daysBetween counts calendar days from a to b, both midnights in one zone
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return int(time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC).Sub(time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
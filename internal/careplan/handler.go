@@ -0,0 +1,122 @@
+package careplan
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/limits"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterChildRoutes registers plan listing and creation on the children group
+func (h *Handler) RegisterChildRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id/care-plans", h.listByChild)
+	rg.POST("/:id/care-plans", h.create)
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id", h.get)
+	rg.PUT("/:id", h.update)
+	rg.DELETE("/:id", h.delete)
+	rg.GET("/:id/compliance", h.compliance)
+}
+
+func (h *Handler) listByChild(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	plans, err := h.service.ListByChild(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, plans)
+}
+
+func (h *Handler) create(c *gin.Context) {
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	p, err := h.service.Create(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+func (h *Handler) get(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	p, err := h.service.Get(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *Handler) update(c *gin.Context) {
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	p, err := h.service.Update(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.service.Delete(c.Request.Context(), userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) compliance(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	summary, err := h.service.Compliance(c.Request.Context(), userID, c.Param("id"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrPlanNotFound), errors.Is(err, ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNoAccess), errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTask), errors.Is(err, ErrInvalidDate), errors.Is(err, ErrInvalidTimezone),
+		errors.Is(err, limits.ErrExceeded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
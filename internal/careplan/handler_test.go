@@ -0,0 +1,163 @@
+package careplan
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	createFn     func(ctx context.Context, userID, childID string, req *PlanRequest) (*Plan, error)
+	getFn        func(ctx context.Context, userID, id string) (*Plan, error)
+	complianceFn func(ctx context.Context, userID, id, from, to string) (*Compliance, error)
+}
+
+func (m *mockService) Create(ctx context.Context, userID, childID string, req *PlanRequest) (*Plan, error) {
+	if m.createFn != nil {
+		return m.createFn(ctx, userID, childID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Plan, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID, id)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ListByChild(ctx context.Context, userID, childID string) ([]Plan, error) {
+	return []Plan{}, nil
+}
+
+func (m *mockService) Update(ctx context.Context, userID, id string, req *PlanRequest) (*Plan, error) {
+	return nil, nil
+}
+
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
+	return nil
+}
+
+func (m *mockService) Compliance(ctx context.Context, userID, id, from, to string) (*Compliance, error) {
+	if m.complianceFn != nil {
+		return m.complianceFn(ctx, userID, id, from, to)
+	}
+	return nil, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterChildRoutes(protected.Group("/children"))
+	handler.RegisterRoutes(protected.Group("/care-plans"))
+	return router
+}
+
+func TestCreatePlan_Success(t *testing.T) {
+	svc := &mockService{
+		createFn: func(ctx context.Context, userID, childID string, req *PlanRequest) (*Plan, error) {
+			if childID != "child-1" || len(req.Tasks) != 1 || req.Tasks[0].Schedule.Times != 3 {
+				t.Errorf("Unexpected childID %q req %+v", childID, req)
+			}
+			return &Plan{ID: "plan-1", ChildID: childID}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"title":"Reflux","tasks":[{"kind":"exercise","title":"Tummy time","tag":"tummy_time","schedule":{"times":3,"per":"day"}}]}`
+	req := httptest.NewRequest("POST", "/children/child-1/care-plans", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCreatePlan_NoTasks(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("POST", "/children/child-1/care-plans", bytes.NewBufferString(`{"title":"Empty","tasks":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreatePlan_InvalidTask(t *testing.T) {
+	svc := &mockService{
+		createFn: func(ctx context.Context, userID, childID string, req *PlanRequest) (*Plan, error) {
+			return nil, ErrInvalidTask
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"title":"x","tasks":[{"kind":"yoga","title":"x"}]}`
+	req := httptest.NewRequest("POST", "/children/child-1/care-plans", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestGetPlan_NoAccess(t *testing.T) {
+	svc := &mockService{
+		getFn: func(ctx context.Context, userID, id string) (*Plan, error) {
+			return nil, ErrNoAccess
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/care-plans/plan-1", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestCompliance_Query(t *testing.T) {
+	svc := &mockService{
+		complianceFn: func(ctx context.Context, userID, id, from, to string) (*Compliance, error) {
+			if id != "plan-1" || from != "2024-06-01" || to != "2024-06-07" {
+				t.Errorf("Unexpected id %q from %q to %q", id, from, to)
+			}
+			return &Compliance{PlanID: id, Tasks: []TaskCompliance{}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/care-plans/plan-1/compliance?from=2024-06-01&to=2024-06-07", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,116 @@
+package careplan
+
+import "time"
+
+// TaskKind says which records count towards a task
+type TaskKind string
+
+const (
+	// TaskMedication is met by doses logged against MedicationID
+	TaskMedication TaskKind = "medication"
+	// TaskExercise is met by notes tagged with Tag, e.g. "tummy_time"
+	TaskExercise TaskKind = "exercise"
+	// TaskMeasurement is met by growth measurements that include Metric
+	TaskMeasurement TaskKind = "measurement"
+)
+
+type Metric string
+
+const (
+	MetricWeight            Metric = "weight"
+	MetricLength            Metric = "length"
+	MetricHeadCircumference Metric = "head_circumference"
+)
+
+type Period string
+
+const (
+	PerDay  Period = "day"
+	PerWeek Period = "week"
+)
+
+// Schedule is how often a task is due, e.g. 3 times per day. Weeks run in
+// seven-day blocks from the plan's start date.
+type Schedule struct {
+	Times int    `json:"times"`
+	Per   Period `json:"per"`
+}
+
+type Task struct {
+	ID       string   `json:"id"`
+	Kind     TaskKind `json:"kind"`
+	Title    string   `json:"title"`
+	Schedule Schedule `json:"schedule"`
+
+	MedicationID string `json:"medication_id,omitempty"`
+	Tag          string `json:"tag,omitempty"`
+	Metric       Metric `json:"metric,omitempty"`
+}
+
+// Plan is a set of tasks a parent or professional has set for a child.
+// Nobody ticks tasks off: completion is read from the child's logs.
+type Plan struct {
+	ID      string `json:"id"`
+	ChildID string `json:"child_id"`
+	Title   string `json:"title"`
+	Notes   string `json:"notes,omitempty"`
+	// Timezone decides where days begin and end, IANA, default UTC
+	Timezone  string    `json:"timezone"`
+	StartDate string    `json:"start_date"`         // YYYY-MM-DD
+	EndDate   string    `json:"end_date,omitempty"` // YYYY-MM-DD, inclusive
+	Tasks     []Task    `json:"tasks"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PlanRequest creates or replaces a plan. Tasks that keep their ID keep
+// their history; tasks without one are new.
+type PlanRequest struct {
+	Title     string `json:"title" binding:"required"`
+	Notes     string `json:"notes,omitempty"`
+	Timezone  string `json:"timezone,omitempty"`
+	StartDate string `json:"start_date,omitempty"` // defaults to today
+	EndDate   string `json:"end_date,omitempty"`
+	Tasks     []Task `json:"tasks" binding:"required,min=1"`
+}
+
+type PeriodStatus string
+
+const (
+	PeriodDone    PeriodStatus = "done"
+	PeriodMissed  PeriodStatus = "missed"
+	PeriodPending PeriodStatus = "pending" // still under way and not yet done
+)
+
+type PeriodCompliance struct {
+	Start    string       `json:"start"` // YYYY-MM-DD
+	End      string       `json:"end"`   // YYYY-MM-DD, inclusive
+	Required int          `json:"required"`
+	Logged   int          `json:"logged"`
+	Status   PeriodStatus `json:"status"`
+}
+
+type TaskCompliance struct {
+	TaskID string   `json:"task_id"`
+	Title  string   `json:"title"`
+	Kind   TaskKind `json:"kind"`
+	// Due counts finished periods plus pending ones already done
+	Due  int `json:"due"`
+	Done int `json:"done"`
+	// Rate is Done over Due, absent while nothing is due
+	Rate    *float64           `json:"rate,omitempty"`
+	Periods []PeriodCompliance `json:"periods"`
+}
+
+// Compliance summarises how well a plan was kept between two dates
+type Compliance struct {
+	PlanID  string           `json:"plan_id"`
+	ChildID string           `json:"child_id"`
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Due     int              `json:"due"`
+	Done    int              `json:"done"`
+	Rate    *float64         `json:"rate,omitempty"`
+	Tasks   []TaskCompliance `json:"tasks"`
+}
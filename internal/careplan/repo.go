@@ -0,0 +1,134 @@
+package careplan
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, p *Plan) error
+	GetByID(ctx context.Context, id string) (*Plan, error)
+	ListByChild(ctx context.Context, childID string) ([]Plan, error)
+	Update(ctx context.Context, p *Plan) error
+	Delete(ctx context.Context, id string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the care plans table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "care_plans")
+}
+
+const planColumns = `id, child_id, title, notes, timezone, start_date, end_date, tasks, COALESCE(created_by, ''), created_at, updated_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPlan(row scanner) (*Plan, error) {
+	var p Plan
+	var startDate time.Time
+	var endDate sql.NullTime
+	var tasks []byte
+
+	if err := row.Scan(
+		&p.ID, &p.ChildID, &p.Title, &p.Notes, &p.Timezone, &startDate, &endDate, &tasks, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	p.StartDate = startDate.Format(time.DateOnly)
+	if endDate.Valid {
+		p.EndDate = endDate.Time.Format(time.DateOnly)
+	}
+	if err := json.Unmarshal(tasks, &p.Tasks); err != nil {
+		return nil, fmt.Errorf("failed to decode tasks: %w", err)
+	}
+	return &p, nil
+}
+
+func (r *repository) Create(ctx context.Context, p *Plan) error {
+	query := `
+		INSERT INTO care_plans (id, child_id, title, notes, timezone, start_date, end_date, tasks, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	tasks, err := json.Marshal(p.Tasks)
+	if err != nil {
+		return fmt.Errorf("failed to encode tasks: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
+		p.ID, p.ChildID, p.Title, p.Notes, p.Timezone, p.StartDate, nullString(p.EndDate), tasks, nullString(p.CreatedBy), p.CreatedAt, p.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Plan, error) {
+	p, err := scanPlan(r.db.QueryRowContext(ctx, `SELECT `+planColumns+` FROM care_plans WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (r *repository) ListByChild(ctx context.Context, childID string) ([]Plan, error) {
+	query := `SELECT ` + planColumns + ` FROM care_plans WHERE child_id = $1 ORDER BY start_date DESC, created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, childID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	plans := []Plan{}
+	for rows.Next() {
+		p, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, *p)
+	}
+
+	return plans, rows.Err()
+}
+
+func (r *repository) Update(ctx context.Context, p *Plan) error {
+	query := `
+		UPDATE care_plans
+		SET title = $2, notes = $3, timezone = $4, start_date = $5, end_date = $6, tasks = $7, updated_at = $8
+		WHERE id = $1
+	`
+
+	tasks, err := json.Marshal(p.Tasks)
+	if err != nil {
+		return fmt.Errorf("failed to encode tasks: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
+		p.ID, p.Title, p.Notes, p.Timezone, p.StartDate, nullString(p.EndDate), tasks, p.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM care_plans WHERE id = $1`, id)
+	return err
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
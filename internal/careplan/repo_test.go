@@ -0,0 +1,109 @@
+package careplan
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var planRowColumns = []string{
+	"id", "child_id", "title", "notes", "timezone", "start_date", "end_date", "tasks", "created_by", "created_at", "updated_at",
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	p := &Plan{
+		ID: "plan-1", ChildID: "child-1", Title: "Reflux", Timezone: "UTC", StartDate: "2024-06-01",
+		Tasks:     []Task{{ID: "task-1", Kind: TaskExercise, Title: "Tummy time", Tag: "tummy_time", Schedule: Schedule{Times: 3, Per: PerDay}}},
+		CreatedBy: "user-1", CreatedAt: now, UpdatedAt: now,
+	}
+
+	mock.ExpectExec("INSERT INTO care_plans").
+		WithArgs("plan-1", "child-1", "Reflux", "", "UTC", "2024-06-01", sql.NullString{},
+			sqlmock.AnyArg(), sql.NullString{String: "user-1", Valid: true}, now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	tasks := `[{"id":"task-1","kind":"measurement","title":"Weigh-in","schedule":{"times":1,"per":"week"},"metric":"weight"}]`
+	rows := sqlmock.NewRows(planRowColumns).
+		AddRow("plan-1", "child-1", "Weight gain", "", "Europe/London", start, end, []byte(tasks), "user-1", now, now)
+
+	mock.ExpectQuery("SELECT .+ FROM care_plans WHERE id = \\$1").
+		WithArgs("plan-1").
+		WillReturnRows(rows)
+
+	p, err := repo.GetByID(context.Background(), "plan-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if p.StartDate != "2024-06-01" || p.EndDate != "2024-06-30" {
+		t.Errorf("dates = %s to %s", p.StartDate, p.EndDate)
+	}
+	if len(p.Tasks) != 1 || p.Tasks[0].Metric != MetricWeight || p.Tasks[0].Schedule.Per != PerWeek {
+		t.Errorf("Tasks = %+v", p.Tasks)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT .+ FROM care_plans").
+		WithArgs("plan-1").
+		WillReturnError(sql.ErrNoRows)
+
+	p, err := repo.GetByID(context.Background(), "plan-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if p != nil {
+		t.Errorf("GetByID() = %+v, want nil", p)
+	}
+}
+
+func TestRepository_Delete(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectExec("DELETE FROM care_plans").
+		WithArgs("plan-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.Delete(context.Background(), "plan-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+}
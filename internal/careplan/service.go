@@ -0,0 +1,431 @@
+// Package careplan lets parents and professionals set a child a plan of
+// recurring tasks, such as doses, exercises and weigh-ins, and reports how well
+// it was kept from the records the family logs anyway.
+package careplan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/grants"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+)
+
+var (
+	ErrPlanNotFound    = errors.New("care plan not found")
+	ErrChildNotFound   = errors.New("child not found")
+	ErrNotMember       = errors.New("user is not a member of this family")
+	ErrNoAccess        = errors.New("user has no access to this child")
+	ErrInvalidTask     = errors.New("invalid task")
+	ErrInvalidDate     = errors.New("dates must be YYYY-MM-DD with the end not before the start")
+	ErrInvalidTimezone = errors.New("invalid timezone")
+)
+
+// defaultWindow is how many days a compliance summary covers by default
+const defaultWindow = 7
+
+type Service interface {
+	Create(ctx context.Context, userID, childID string, req *PlanRequest) (*Plan, error)
+	Get(ctx context.Context, userID, id string) (*Plan, error)
+	ListByChild(ctx context.Context, userID, childID string) ([]Plan, error)
+	Update(ctx context.Context, userID, id string, req *PlanRequest) (*Plan, error)
+	Delete(ctx context.Context, userID, id string) error
+	// Compliance summarises the plan between two YYYY-MM-DD dates; empty
+	// dates cover the last week of the plan up to today
+	Compliance(ctx context.Context, userID, id, from, to string) (*Compliance, error)
+}
+
+type service struct {
+	repo              Repository
+	familyService     family.Service
+	grantsService     grants.Service
+	medicationService medication.Service
+	notesService      notes.Service
+	growthService     growth.Service
+	now               func() time.Time
+}
+
+func NewService(
+	repo Repository,
+	familyService family.Service,
+	grantsService grants.Service,
+	medicationService medication.Service,
+	notesService notes.Service,
+	growthService growth.Service,
+) Service {
+	return &service{
+		repo:              repo,
+		familyService:     familyService,
+		grantsService:     grantsService,
+		medicationService: medicationService,
+		notesService:      notesService,
+		growthService:     growthService,
+		now:               time.Now,
+	}
+}
+
+// HealthCheck reports whether care plans can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Create(ctx context.Context, userID, childID string, req *PlanRequest) (*Plan, error) {
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	p := &Plan{
+		ID:        ids.New(),
+		ChildID:   childID,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.apply(ctx, p, req, nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, fmt.Errorf("failed to create care plan: %w", err)
+	}
+	return p, nil
+}
+
+func (s *service) Get(ctx context.Context, userID, id string) (*Plan, error) {
+	return s.getPlan(ctx, userID, id)
+}
+
+func (s *service) ListByChild(ctx context.Context, userID, childID string) ([]Plan, error) {
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	plans, err := s.repo.ListByChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list care plans: %w", err)
+	}
+	return plans, nil
+}
+
+func (s *service) Update(ctx context.Context, userID, id string, req *PlanRequest) (*Plan, error) {
+	p, err := s.getPlan(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, p, req, p.Tasks); err != nil {
+		return nil, err
+	}
+	p.UpdatedAt = s.now()
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, fmt.Errorf("failed to update care plan: %w", err)
+	}
+	return p, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	if _, err := s.getPlan(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete care plan: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Compliance(ctx context.Context, userID, id, from, to string) (*Compliance, error) {
+	p, err := s.getPlan(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := s.window(p, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Compliance{
+		PlanID:  p.ID,
+		ChildID: p.ChildID,
+		From:    w.from.Format(time.DateOnly),
+		To:      w.to.Format(time.DateOnly),
+		Tasks:   []TaskCompliance{},
+	}
+
+	records := &records{service: s, childID: p.ChildID}
+	records.from, records.to = w.bounds()
+	for _, task := range p.Tasks {
+		events, err := records.times(ctx, task)
+		if err != nil {
+			return nil, err
+		}
+		tc := w.evaluate(task, events)
+		c.Due += tc.Due
+		c.Done += tc.Done
+		c.Tasks = append(c.Tasks, tc)
+	}
+	c.Rate = rate(c.Done, c.Due)
+	return c, nil
+}
+
+// window resolves the requested dates in the plan's timezone and clips them
+// to the plan's dates
+func (s *service) window(p *Plan, from, to string) (window, error) {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return window{}, fmt.Errorf("%w: %s", ErrInvalidTimezone, p.Timezone)
+	}
+
+	y, m, d := s.now().In(loc).Date()
+	w := window{today: time.Date(y, m, d, 0, 0, 0, 0, loc)}
+	if w.planStart, err = time.ParseInLocation(time.DateOnly, p.StartDate, loc); err != nil {
+		return window{}, ErrInvalidDate
+	}
+
+	w.to = w.today
+	if to != "" {
+		if w.to, err = time.ParseInLocation(time.DateOnly, to, loc); err != nil {
+			return window{}, ErrInvalidDate
+		}
+	}
+	if p.EndDate != "" {
+		end, err := time.ParseInLocation(time.DateOnly, p.EndDate, loc)
+		if err != nil {
+			return window{}, ErrInvalidDate
+		}
+		if end.Before(w.to) {
+			w.to = end
+		}
+	}
+
+	w.from = w.to.AddDate(0, 0, 1-defaultWindow)
+	if from != "" {
+		if w.from, err = time.ParseInLocation(time.DateOnly, from, loc); err != nil {
+			return window{}, ErrInvalidDate
+		}
+	}
+	if w.from.Before(w.planStart) {
+		w.from = w.planStart
+	}
+	if from != "" && to != "" && w.to.Before(w.from) {
+		return window{}, ErrInvalidDate
+	}
+	return w, nil
+}
+
+// apply validates the request onto the plan. Tasks whose ID matches one in
+// existing keep it; others get a new one.
+func (s *service) apply(ctx context.Context, p *Plan, req *PlanRequest, existing []Task) error {
+	p.Title = strings.TrimSpace(req.Title)
+	p.Notes = strings.TrimSpace(req.Notes)
+
+	l := limits.Get()
+	if err := limits.Length("title", p.Title, l.NameLength); err != nil {
+		return err
+	}
+	if err := limits.Length("notes", p.Notes, l.TextLength); err != nil {
+		return err
+	}
+	if err := limits.Items("tasks", len(req.Tasks), l.BulkItems); err != nil {
+		return err
+	}
+
+	p.Timezone = req.Timezone
+	if p.Timezone == "" {
+		p.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidTimezone, p.Timezone)
+	}
+
+	p.StartDate = req.StartDate
+	if p.StartDate == "" {
+		p.StartDate = s.now().In(loc).Format(time.DateOnly)
+	}
+	p.EndDate = req.EndDate
+	start, err := time.Parse(time.DateOnly, p.StartDate)
+	if err != nil {
+		return ErrInvalidDate
+	}
+	if p.EndDate != "" {
+		end, err := time.Parse(time.DateOnly, p.EndDate)
+		if err != nil || end.Before(start) {
+			return ErrInvalidDate
+		}
+	}
+
+	tasks := make([]Task, 0, len(req.Tasks))
+	for _, t := range req.Tasks {
+		task, err := s.validateTask(ctx, p.ChildID, t)
+		if err != nil {
+			return err
+		}
+		if task.ID == "" || !slices.ContainsFunc(existing, func(e Task) bool { return e.ID == task.ID }) {
+			task.ID = ids.New()
+		}
+		tasks = append(tasks, task)
+	}
+	p.Tasks = tasks
+	return nil
+}
+
+func (s *service) validateTask(ctx context.Context, childID string, t Task) (Task, error) {
+	t.Title = strings.TrimSpace(t.Title)
+	if t.Title == "" {
+		return t, fmt.Errorf("%w: title is required", ErrInvalidTask)
+	}
+
+	switch t.Kind {
+	case TaskMedication:
+		med, err := s.medicationService.Get(ctx, "", t.MedicationID)
+		if err != nil {
+			return t, fmt.Errorf("failed to get medication: %w", err)
+		}
+		if med == nil || med.ChildID != childID {
+			return t, fmt.Errorf("%w: medication not found for this child", ErrInvalidTask)
+		}
+		// Doses follow the medication's own frequency unless told otherwise
+		if t.Schedule.Times == 0 {
+			interval, ok := medication.DoseInterval(med.Frequency)
+			if !ok {
+				return t, fmt.Errorf("%w: as-needed medication needs a schedule", ErrInvalidTask)
+			}
+			t.Schedule = Schedule{Times: max(1, int(24*time.Hour/interval)), Per: PerDay}
+		}
+		t.Tag, t.Metric = "", ""
+	case TaskExercise:
+		t.Tag = strings.TrimSpace(t.Tag)
+		if t.Tag == "" {
+			return t, fmt.Errorf("%w: exercise tasks need the note tag that records them", ErrInvalidTask)
+		}
+		t.MedicationID, t.Metric = "", ""
+	case TaskMeasurement:
+		if !slices.Contains([]Metric{MetricWeight, MetricLength, MetricHeadCircumference}, t.Metric) {
+			return t, fmt.Errorf("%w: metric must be weight, length or head_circumference", ErrInvalidTask)
+		}
+		t.MedicationID, t.Tag = "", ""
+	default:
+		return t, fmt.Errorf("%w: kind must be medication, exercise or measurement", ErrInvalidTask)
+	}
+
+	if t.Schedule.Times < 1 || (t.Schedule.Per != PerDay && t.Schedule.Per != PerWeek) {
+		return t, fmt.Errorf("%w: schedule needs times of at least 1 per day or week", ErrInvalidTask)
+	}
+	return t, nil
+}
+
+func (s *service) getPlan(ctx context.Context, userID, id string) (*Plan, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care plan: %w", err)
+	}
+	if p == nil {
+		return nil, ErrPlanNotFound
+	}
+	if err := s.authorize(ctx, userID, p.ChildID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// authorize lets in members of the child's family and professionals with an
+// active grant for the child
+func (s *service) authorize(ctx context.Context, userID, childID string) error {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return ErrChildNotFound
+	}
+
+	_, err = s.familyService.GetMemberRole(ctx, child.FamilyID, userID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, family.ErrNotMember) {
+		return err
+	}
+
+	ok, err := s.grantsService.HasAccess(ctx, userID, childID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNoAccess
+	}
+	return nil
+}
+
+// records fetches the times of the records that count towards tasks, reading
+// growth measurements once for all measurement tasks
+type records struct {
+	*service
+	childID      string
+	from, to     time.Time
+	measurements []growth.Measurement
+	loaded       bool
+}
+
+func (r *records) times(ctx context.Context, task Task) ([]time.Time, error) {
+	var times []time.Time
+
+	switch task.Kind {
+	case TaskMedication:
+		logs, err := r.medicationService.GetLogs(ctx, task.MedicationID, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get medication logs: %w", err)
+		}
+		for _, l := range logs {
+			times = append(times, l.GivenAt)
+		}
+	case TaskExercise:
+		list, err := r.notesService.List(ctx, &notes.NoteFilter{ChildID: r.childID, Tags: []string{task.Tag}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list notes: %w", err)
+		}
+		for _, n := range list {
+			times = append(times, n.OccurredAt)
+		}
+	case TaskMeasurement:
+		if !r.loaded {
+			list, err := r.growthService.List(ctx, &growth.MeasurementFilter{ChildID: r.childID, StartDate: &r.from, EndDate: &r.to})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list measurements: %w", err)
+			}
+			r.measurements, r.loaded = list, true
+		}
+		for _, m := range r.measurements {
+			if hasMetric(m, task.Metric) {
+				times = append(times, m.MeasuredAt)
+			}
+		}
+	}
+	return times, nil
+}
+
+func hasMetric(m growth.Measurement, metric Metric) bool {
+	switch metric {
+	case MetricWeight:
+		return m.WeightKg != nil
+	case MetricLength:
+		return m.LengthCm != nil
+	case MetricHeadCircumference:
+		return m.HeadCircumferenceCm != nil
+	}
+	return false
+}
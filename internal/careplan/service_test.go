@@ -0,0 +1,348 @@
+package careplan
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/grants"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+)
+
+type mockRepository struct {
+	plans map[string]*Plan
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{plans: make(map[string]*Plan)}
+}
+
+func (m *mockRepository) Create(ctx context.Context, p *Plan) error {
+	copied := *p
+	m.plans[p.ID] = &copied
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Plan, error) {
+	if p, ok := m.plans[id]; ok {
+		copied := *p
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListByChild(ctx context.Context, childID string) ([]Plan, error) {
+	plans := []Plan{}
+	for _, p := range m.plans {
+		if p.ChildID == childID {
+			plans = append(plans, *p)
+		}
+	}
+	return plans, nil
+}
+
+func (m *mockRepository) Update(ctx context.Context, p *Plan) error {
+	copied := *p
+	m.plans[p.ID] = &copied
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	delete(m.plans, id)
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" {
+		return &family.Child{ID: "child-1", FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if familyID == "family-1" && userID == "parent-1" {
+		return "admin", nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockGrantsService struct {
+	grants.Service
+}
+
+func (m *mockGrantsService) HasAccess(ctx context.Context, userID, childID string) (bool, error) {
+	return userID == "pro-1" && childID == "child-1", nil
+}
+
+type mockMedicationService struct {
+	medication.Service
+	meds map[string]*medication.Medication
+	logs []medication.MedicationLog
+}
+
+func (m *mockMedicationService) Get(ctx context.Context, userID, id string) (*medication.Medication, error) {
+	return m.meds[id], nil
+}
+
+func (m *mockMedicationService) GetLogs(ctx context.Context, medicationID, viewerID string) ([]medication.MedicationLog, error) {
+	logs := []medication.MedicationLog{}
+	for _, l := range m.logs {
+		if l.MedicationID == medicationID {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+type mockNotesService struct {
+	notes.Service
+	notes []notes.Note
+}
+
+func (m *mockNotesService) List(ctx context.Context, filter *notes.NoteFilter) ([]notes.Note, error) {
+	list := []notes.Note{}
+	for _, n := range m.notes {
+		for _, tag := range n.Tags {
+			if n.ChildID == filter.ChildID && tag == filter.Tags[0] {
+				list = append(list, n)
+			}
+		}
+	}
+	return list, nil
+}
+
+type mockGrowthService struct {
+	growth.Service
+	measurements []growth.Measurement
+	calls        int
+}
+
+func (m *mockGrowthService) List(ctx context.Context, filter *growth.MeasurementFilter) ([]growth.Measurement, error) {
+	m.calls++
+	return m.measurements, nil
+}
+
+// testNow is mid-afternoon on Monday 10 June 2024, UTC
+var testNow = time.Date(2024, 6, 10, 15, 0, 0, 0, time.UTC)
+
+type testDeps struct {
+	repo        *mockRepository
+	medications *mockMedicationService
+	notes       *mockNotesService
+	growth      *mockGrowthService
+}
+
+func newTestService() (*service, *testDeps) {
+	deps := &testDeps{
+		repo: newMockRepository(),
+		medications: &mockMedicationService{meds: map[string]*medication.Medication{
+			"med-1": {ID: "med-1", ChildID: "child-1", Frequency: "twice_daily"},
+			"med-2": {ID: "med-2", ChildID: "child-2", Frequency: "once_daily"},
+			"med-3": {ID: "med-3", ChildID: "child-1", Frequency: "as_needed"},
+		}},
+		notes:  &mockNotesService{},
+		growth: &mockGrowthService{},
+	}
+	svc := &service{
+		repo:              deps.repo,
+		familyService:     &mockFamilyService{},
+		grantsService:     &mockGrantsService{},
+		medicationService: deps.medications,
+		notesService:      deps.notes,
+		growthService:     deps.growth,
+		now:               func() time.Time { return testNow },
+	}
+	return svc, deps
+}
+
+func at(date string, hour int) time.Time {
+	d, _ := time.Parse(time.DateOnly, date)
+	return d.Add(time.Duration(hour) * time.Hour)
+}
+
+func TestService_Create(t *testing.T) {
+	svc, _ := newTestService()
+
+	p, err := svc.Create(context.Background(), "pro-1", "child-1", &PlanRequest{
+		Title: " Reflux plan ",
+		Tasks: []Task{
+			{Kind: TaskMedication, Title: "Omeprazole", MedicationID: "med-1"},
+			{Kind: TaskExercise, Title: "Tummy time", Tag: " tummy_time ", Schedule: Schedule{Times: 3, Per: PerDay}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if p.Title != "Reflux plan" || p.Timezone != "UTC" || p.StartDate != "2024-06-10" || p.CreatedBy != "pro-1" {
+		t.Errorf("Create() = %+v", p)
+	}
+	if p.Tasks[0].Schedule != (Schedule{Times: 2, Per: PerDay}) {
+		t.Errorf("medication schedule = %+v, want twice a day from its frequency", p.Tasks[0].Schedule)
+	}
+	if p.Tasks[1].Tag != "tummy_time" || p.Tasks[0].ID == "" || p.Tasks[0].ID == p.Tasks[1].ID {
+		t.Errorf("Tasks = %+v", p.Tasks)
+	}
+}
+
+func TestService_Create_Invalid(t *testing.T) {
+	daily := Schedule{Times: 1, Per: PerDay}
+	tests := []struct {
+		name string
+		req  PlanRequest
+		want error
+	}{
+		{"other child's medication", PlanRequest{Title: "x", Tasks: []Task{{Kind: TaskMedication, Title: "x", MedicationID: "med-2"}}}, ErrInvalidTask},
+		{"as needed without schedule", PlanRequest{Title: "x", Tasks: []Task{{Kind: TaskMedication, Title: "x", MedicationID: "med-3"}}}, ErrInvalidTask},
+		{"exercise without tag", PlanRequest{Title: "x", Tasks: []Task{{Kind: TaskExercise, Title: "x", Schedule: daily}}}, ErrInvalidTask},
+		{"unknown metric", PlanRequest{Title: "x", Tasks: []Task{{Kind: TaskMeasurement, Title: "x", Metric: "bmi", Schedule: daily}}}, ErrInvalidTask},
+		{"monthly", PlanRequest{Title: "x", Tasks: []Task{{Kind: TaskExercise, Title: "x", Tag: "t", Schedule: Schedule{Times: 1, Per: "month"}}}}, ErrInvalidTask},
+		{"end before start", PlanRequest{Title: "x", StartDate: "2024-06-10", EndDate: "2024-06-01", Tasks: []Task{{Kind: TaskExercise, Title: "x", Tag: "t", Schedule: daily}}}, ErrInvalidDate},
+		{"bad timezone", PlanRequest{Title: "x", Timezone: "Mars/Olympus", Tasks: []Task{{Kind: TaskExercise, Title: "x", Tag: "t", Schedule: daily}}}, ErrInvalidTimezone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService()
+			if _, err := svc.Create(context.Background(), "parent-1", "child-1", &tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("Create() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Access(t *testing.T) {
+	svc, _ := newTestService()
+	req := &PlanRequest{Title: "x", Tasks: []Task{{Kind: TaskExercise, Title: "x", Tag: "t", Schedule: Schedule{Times: 1, Per: PerDay}}}}
+
+	if _, err := svc.Create(context.Background(), "stranger", "child-1", req); !errors.Is(err, ErrNoAccess) {
+		t.Errorf("Create() error = %v, want ErrNoAccess", err)
+	}
+	if _, err := svc.ListByChild(context.Background(), "parent-1", "child-9"); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("ListByChild() error = %v, want ErrChildNotFound", err)
+	}
+}
+
+func TestService_Update_KeepsTaskIDs(t *testing.T) {
+	svc, _ := newTestService()
+	daily := Schedule{Times: 1, Per: PerDay}
+	p, _ := svc.Create(context.Background(), "parent-1", "child-1", &PlanRequest{
+		Title: "x", Tasks: []Task{{Kind: TaskExercise, Title: "Stretches", Tag: "stretch", Schedule: daily}},
+	})
+	kept := p.Tasks[0].ID
+
+	updated, err := svc.Update(context.Background(), "pro-1", p.ID, &PlanRequest{
+		Title: "y",
+		Tasks: []Task{
+			{ID: kept, Kind: TaskExercise, Title: "Stretches", Tag: "stretch", Schedule: Schedule{Times: 2, Per: PerDay}},
+			{ID: "made-up", Kind: TaskMeasurement, Title: "Weigh-in", Metric: MetricWeight, Schedule: Schedule{Times: 1, Per: PerWeek}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.Tasks[0].ID != kept || updated.Tasks[1].ID == "made-up" {
+		t.Errorf("Task IDs = %s, %s; want the first kept and the second new", updated.Tasks[0].ID, updated.Tasks[1].ID)
+	}
+}
+
+func TestService_Compliance(t *testing.T) {
+	svc, deps := newTestService()
+	deps.repo.plans["plan-1"] = &Plan{
+		ID: "plan-1", ChildID: "child-1", Timezone: "UTC", StartDate: "2024-06-01",
+		Tasks: []Task{
+			{ID: "exercise", Kind: TaskExercise, Tag: "tummy_time", Schedule: Schedule{Times: 2, Per: PerDay}},
+			{ID: "weigh", Kind: TaskMeasurement, Metric: MetricWeight, Schedule: Schedule{Times: 1, Per: PerWeek}},
+			{ID: "meds", Kind: TaskMedication, MedicationID: "med-1", Schedule: Schedule{Times: 1, Per: PerDay}},
+		},
+	}
+	for _, occurred := range []time.Time{at("2024-06-04", 9), at("2024-06-04", 17), at("2024-06-05", 9), at("2024-06-10", 9)} {
+		deps.notes.notes = append(deps.notes.notes, notes.Note{ChildID: "child-1", Tags: []string{"tummy_time"}, OccurredAt: occurred})
+	}
+	weight := 5.2
+	deps.growth.measurements = []growth.Measurement{
+		{MeasuredAt: at("2024-06-02", 10), WeightKg: &weight},
+		{MeasuredAt: at("2024-06-09", 10)}, // length only
+	}
+	for _, date := range []string{"2024-06-04", "2024-06-05", "2024-06-06", "2024-06-07", "2024-06-08", "2024-06-09", "2024-06-10"} {
+		deps.medications.logs = append(deps.medications.logs, medication.MedicationLog{MedicationID: "med-1", GivenAt: at(date, 8)})
+	}
+
+	c, err := svc.Compliance(context.Background(), "parent-1", "plan-1", "", "")
+	if err != nil {
+		t.Fatalf("Compliance() error = %v", err)
+	}
+	if c.From != "2024-06-04" || c.To != "2024-06-10" {
+		t.Errorf("window = %s to %s, want the last 7 days", c.From, c.To)
+	}
+
+	exercise := c.Tasks[0]
+	if len(exercise.Periods) != 7 || exercise.Due != 6 || exercise.Done != 1 {
+		t.Errorf("exercise = %d periods, %d/%d done", len(exercise.Periods), exercise.Done, exercise.Due)
+	}
+	if exercise.Periods[1].Status != PeriodMissed || exercise.Periods[1].Logged != 1 || exercise.Periods[6].Status != PeriodPending {
+		t.Errorf("exercise periods = %+v", exercise.Periods)
+	}
+
+	weigh := c.Tasks[1]
+	if len(weigh.Periods) != 2 || weigh.Periods[0].Start != "2024-06-01" || weigh.Periods[0].Status != PeriodDone ||
+		weigh.Periods[1].Status != PeriodPending {
+		t.Errorf("weigh-in periods = %+v, want a done first week and a pending second", weigh.Periods)
+	}
+
+	if meds := c.Tasks[2]; meds.Due != 7 || meds.Done != 7 || *meds.Rate != 1 {
+		t.Errorf("medication = %d/%d", meds.Done, meds.Due)
+	}
+	if c.Due != 14 || c.Done != 9 {
+		t.Errorf("overall = %d/%d, want 9/14", c.Done, c.Due)
+	}
+	if deps.growth.calls != 1 {
+		t.Errorf("growth listed %d times, want once", deps.growth.calls)
+	}
+}
+
+func TestService_Compliance_Timezone(t *testing.T) {
+	svc, deps := newTestService()
+	deps.repo.plans["plan-1"] = &Plan{
+		ID: "plan-1", ChildID: "child-1", Timezone: "America/New_York", StartDate: "2024-06-01",
+		Tasks: []Task{{ID: "exercise", Kind: TaskExercise, Tag: "tummy_time", Schedule: Schedule{Times: 1, Per: PerDay}}},
+	}
+	// 23:30 UTC is still the evening of the 5th in New York
+	deps.notes.notes = []notes.Note{{ChildID: "child-1", Tags: []string{"tummy_time"}, OccurredAt: time.Date(2024, 6, 5, 23, 30, 0, 0, time.UTC)}}
+
+	c, err := svc.Compliance(context.Background(), "parent-1", "plan-1", "2024-06-05", "2024-06-06")
+	if err != nil {
+		t.Fatalf("Compliance() error = %v", err)
+	}
+	periods := c.Tasks[0].Periods
+	if periods[0].Status != PeriodDone || periods[1].Status != PeriodMissed {
+		t.Errorf("periods = %+v, want the 5th done", periods)
+	}
+}
+
+func TestService_Compliance_ClipsToPlan(t *testing.T) {
+	svc, deps := newTestService()
+	deps.repo.plans["plan-1"] = &Plan{
+		ID: "plan-1", ChildID: "child-1", Timezone: "UTC", StartDate: "2024-06-08", EndDate: "2024-06-09",
+		Tasks: []Task{{ID: "exercise", Kind: TaskExercise, Tag: "tummy_time", Schedule: Schedule{Times: 1, Per: PerDay}}},
+	}
+
+	c, err := svc.Compliance(context.Background(), "parent-1", "plan-1", "", "")
+	if err != nil {
+		t.Fatalf("Compliance() error = %v", err)
+	}
+	if c.From != "2024-06-08" || c.To != "2024-06-09" || len(c.Tasks[0].Periods) != 2 || c.Rate == nil || *c.Rate != 0 {
+		t.Errorf("Compliance() = %+v", c)
+	}
+
+	if _, err := svc.Compliance(context.Background(), "parent-1", "plan-1", "2024-06-09", "2024-06-01"); !errors.Is(err, ErrInvalidDate) {
+		t.Errorf("Compliance(reversed) error = %v, want ErrInvalidDate", err)
+	}
+}
@@ -0,0 +1,22 @@
+package closures
+
+import "time"
+
+// maxShiftDays bounds how far a due date can move, so a calendar with every
+// day closed cannot push a reminder out indefinitely
+const maxShiftDays = 31
+
+// NextBusinessDay returns due if its day is open, and otherwise the same time
+// of day on the next open day. Days are taken in loc; closed holds closed
+// dates as YYYY-MM-DD.
+func NextBusinessDay(due time.Time, loc *time.Location, weekendsClosed bool, closed map[string]bool) time.Time {
+	local := due.In(loc)
+	for range maxShiftDays {
+		weekend := local.Weekday() == time.Saturday || local.Weekday() == time.Sunday
+		if !(weekendsClosed && weekend) && !closed[local.Format(time.DateOnly)] {
+			return local
+		}
+		local = local.AddDate(0, 0, 1)
+	}
+	return due.In(loc)
+}
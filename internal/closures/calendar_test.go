@@ -0,0 +1,57 @@
+package closures
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextBusinessDay(t *testing.T) {
+	// 2024-06-01 is a Saturday
+	saturday := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		due            time.Time
+		weekendsClosed bool
+		closed         map[string]bool
+		want           string
+	}{
+		{"open weekday", saturday.AddDate(0, 0, 2), true, nil, "2024-06-03T09:30:00Z"},
+		{"weekend moves to monday", saturday, true, nil, "2024-06-03T09:30:00Z"},
+		{"weekend open", saturday, false, nil, "2024-06-01T09:30:00Z"},
+		{"holiday monday", saturday, true, map[string]bool{"2024-06-03": true}, "2024-06-04T09:30:00Z"},
+		{"holiday on weekday", saturday.AddDate(0, 0, 4), true, map[string]bool{"2024-06-05": true}, "2024-06-06T09:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextBusinessDay(tt.due, time.UTC, tt.weekendsClosed, tt.closed)
+			if got.Format(time.RFC3339) != tt.want {
+				t.Errorf("NextBusinessDay() = %s, want %s", got.Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+func TestNextBusinessDay_UsesLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+10", 10*60*60)
+	// Friday 20:00 UTC is already Saturday morning at UTC+10
+	due := time.Date(2024, 5, 31, 20, 0, 0, 0, time.UTC)
+
+	got := NextBusinessDay(due, loc, true, nil)
+	if got.Format(time.DateOnly) != "2024-06-03" || got.Hour() != 6 {
+		t.Errorf("NextBusinessDay() = %s, want Monday 06:00 local", got)
+	}
+}
+
+func TestNextBusinessDay_AllClosed(t *testing.T) {
+	due := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	closed := map[string]bool{}
+	for d := range 60 {
+		closed[due.AddDate(0, 0, d).Format(time.DateOnly)] = true
+	}
+
+	if got := NextBusinessDay(due, time.UTC, false, closed); !got.Equal(due) {
+		t.Errorf("NextBusinessDay() = %s, want the original due time", got)
+	}
+}
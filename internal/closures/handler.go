@@ -0,0 +1,106 @@
+package closures
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterFamilyRoutes registers the closure calendar on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/closures", h.list)
+	rg.POST("/:familyId/closures", h.add)
+	rg.DELETE("/:familyId/closures/:closureId", h.delete)
+	rg.GET("/:familyId/reminder-settings", h.getSettings)
+	rg.PUT("/:familyId/reminder-settings", h.updateSettings)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	closures, err := h.service.List(c.Request.Context(), userID, c.Param("familyId"), c.Query("all") == "true")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, closures)
+}
+
+func (h *Handler) add(c *gin.Context) {
+	var req CreateClosureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	closure, err := h.service.Add(c.Request.Context(), userID, c.Param("familyId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, closure)
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.service.Delete(c.Request.Context(), userID, c.Param("familyId"), c.Param("closureId")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) getSettings(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	settings, err := h.service.GetSettings(c.Request.Context(), userID, c.Param("familyId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *Handler) updateSettings(c *gin.Context) {
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	settings, err := h.service.UpdateSettings(c.Request.Context(), userID, c.Param("familyId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrClosureNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, ErrNotAdmin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrDuplicateDate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidDate), errors.Is(err, ErrInvalidTimezone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,228 @@
+package closures
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	getSettingsFn    func(ctx context.Context, userID, familyID string) (*Settings, error)
+	updateSettingsFn func(ctx context.Context, userID, familyID string, req *UpdateSettingsRequest) (*Settings, error)
+	listFn           func(ctx context.Context, userID, familyID string, all bool) ([]Closure, error)
+	addFn            func(ctx context.Context, userID, familyID string, req *CreateClosureRequest) (*Closure, error)
+	deleteFn         func(ctx context.Context, userID, familyID, closureID string) error
+}
+
+func (m *mockService) GetSettings(ctx context.Context, userID, familyID string) (*Settings, error) {
+	if m.getSettingsFn != nil {
+		return m.getSettingsFn(ctx, userID, familyID)
+	}
+	return &Settings{FamilyID: familyID}, nil
+}
+
+func (m *mockService) UpdateSettings(ctx context.Context, userID, familyID string, req *UpdateSettingsRequest) (*Settings, error) {
+	if m.updateSettingsFn != nil {
+		return m.updateSettingsFn(ctx, userID, familyID, req)
+	}
+	return &Settings{FamilyID: familyID}, nil
+}
+
+func (m *mockService) List(ctx context.Context, userID, familyID string, all bool) ([]Closure, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, familyID, all)
+	}
+	return []Closure{}, nil
+}
+
+func (m *mockService) Add(ctx context.Context, userID, familyID string, req *CreateClosureRequest) (*Closure, error) {
+	if m.addFn != nil {
+		return m.addFn(ctx, userID, familyID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Delete(ctx context.Context, userID, familyID, closureID string) error {
+	if m.deleteFn != nil {
+		return m.deleteFn(ctx, userID, familyID, closureID)
+	}
+	return nil
+}
+
+func (m *mockService) ShiftForChild(ctx context.Context, childID string, due time.Time) (time.Time, error) {
+	return due, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterFamilyRoutes(protected.Group("/families"))
+	return router
+}
+
+func TestList_All(t *testing.T) {
+	var gotAll bool
+	svc := &mockService{
+		listFn: func(ctx context.Context, userID, familyID string, all bool) ([]Closure, error) {
+			gotAll = all
+			return []Closure{{ID: "closure-1", FamilyID: familyID, Date: "2024-12-25"}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/families/family-1/closures?all=true", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !gotAll {
+		t.Error("Expected all=true to be passed to the service")
+	}
+}
+
+func TestAdd_Success(t *testing.T) {
+	svc := &mockService{
+		addFn: func(ctx context.Context, userID, familyID string, req *CreateClosureRequest) (*Closure, error) {
+			return &Closure{ID: "closure-1", FamilyID: familyID, Date: req.Date, Name: req.Name, CreatedBy: userID}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"date":"2024-12-25","name":"Christmas"}`
+	req := httptest.NewRequest("POST", "/families/family-1/closures", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var c Closure
+	json.Unmarshal(w.Body.Bytes(), &c)
+	if c.Date != "2024-12-25" || c.CreatedBy != "test-user-123" {
+		t.Errorf("Unexpected closure %+v", c)
+	}
+}
+
+func TestAdd_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"duplicate", ErrDuplicateDate, http.StatusConflict},
+		{"invalid date", ErrInvalidDate, http.StatusBadRequest},
+		{"not admin", ErrNotAdmin, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				addFn: func(ctx context.Context, userID, familyID string, req *CreateClosureRequest) (*Closure, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/families/family-1/closures", bytes.NewBufferString(`{"date":"2024-12-25"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestAdd_MissingDate(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("POST", "/families/family-1/closures", bytes.NewBufferString(`{"name":"Christmas"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestDelete_NotFound(t *testing.T) {
+	svc := &mockService{
+		deleteFn: func(ctx context.Context, userID, familyID, closureID string) error {
+			return ErrClosureNotFound
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("DELETE", "/families/family-1/closures/missing", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestUpdateSettings_Success(t *testing.T) {
+	svc := &mockService{
+		updateSettingsFn: func(ctx context.Context, userID, familyID string, req *UpdateSettingsRequest) (*Settings, error) {
+			return &Settings{FamilyID: familyID, ShiftToBusinessDay: *req.ShiftToBusinessDay, WeekendsClosed: *req.WeekendsClosed, Timezone: req.Timezone}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"shift_to_business_day":true,"weekends_closed":false,"timezone":"Europe/London"}`
+	req := httptest.NewRequest("PUT", "/families/family-1/reminder-settings", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var s Settings
+	json.Unmarshal(w.Body.Bytes(), &s)
+	if !s.ShiftToBusinessDay || s.WeekendsClosed || s.Timezone != "Europe/London" {
+		t.Errorf("Unexpected settings %+v", s)
+	}
+}
+
+func TestUpdateSettings_MissingFields(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("PUT", "/families/family-1/reminder-settings", bytes.NewBufferString(`{"timezone":"UTC"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,37 @@
+package closures
+
+import "time"
+
+// Closure is a day the family's clinic is closed, such as a public holiday
+type Closure struct {
+	ID        string    `json:"id"`
+	FamilyID  string    `json:"family_id"`
+	Date      string    `json:"date"` // YYYY-MM-DD
+	Name      string    `json:"name,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateClosureRequest struct {
+	Date string `json:"date" binding:"required"`
+	Name string `json:"name,omitempty"`
+}
+
+// Settings control whether reminders skip closed days. They are off until a
+// family admin turns them on.
+type Settings struct {
+	FamilyID string `json:"family_id"`
+	// ShiftToBusinessDay moves reminders due on a closed day to the next open one
+	ShiftToBusinessDay bool `json:"shift_to_business_day"`
+	// WeekendsClosed treats every Saturday and Sunday as closed
+	WeekendsClosed bool `json:"weekends_closed"`
+	// Timezone decides which day a due time falls on, IANA
+	Timezone  string     `json:"timezone"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type UpdateSettingsRequest struct {
+	ShiftToBusinessDay *bool  `json:"shift_to_business_day" binding:"required"`
+	WeekendsClosed     *bool  `json:"weekends_closed" binding:"required"`
+	Timezone           string `json:"timezone,omitempty"`
+}
@@ -0,0 +1,142 @@
+package closures
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	// Create reports false when the family already has a closure on that date
+	Create(ctx context.Context, c *Closure) (bool, error)
+	GetByID(ctx context.Context, id string) (*Closure, error)
+	// ListByFamily returns closures from the given date on, all of them when from is empty
+	ListByFamily(ctx context.Context, familyID, from string) ([]Closure, error)
+	Delete(ctx context.Context, id string) error
+
+	GetSettings(ctx context.Context, familyID string) (*Settings, error)
+	UpsertSettings(ctx context.Context, s *Settings) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the closure and reminder settings tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "family_closures", "family_reminder_settings")
+}
+
+func (r *repository) Create(ctx context.Context, c *Closure) (bool, error) {
+	query := `
+		INSERT INTO family_closures (id, family_id, closed_on, name, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (family_id, closed_on) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, c.ID, c.FamilyID, c.Date, c.Name, c.CreatedBy, c.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+const closureColumns = `id, family_id, closed_on, name, COALESCE(created_by, ''), created_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanClosure(row scanner) (*Closure, error) {
+	var c Closure
+	var date time.Time
+	if err := row.Scan(&c.ID, &c.FamilyID, &date, &c.Name, &c.CreatedBy, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	c.Date = date.Format(time.DateOnly)
+	return &c, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Closure, error) {
+	c, err := scanClosure(r.db.QueryRowContext(ctx, `SELECT `+closureColumns+` FROM family_closures WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *repository) ListByFamily(ctx context.Context, familyID, from string) ([]Closure, error) {
+	query := `
+		SELECT ` + closureColumns + `
+		FROM family_closures
+		WHERE family_id = $1 AND ($2 = '' OR closed_on >= $2::date)
+		ORDER BY closed_on
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, familyID, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	closures := []Closure{}
+	for rows.Next() {
+		c, err := scanClosure(rows)
+		if err != nil {
+			return nil, err
+		}
+		closures = append(closures, *c)
+	}
+
+	return closures, rows.Err()
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM family_closures WHERE id = $1`, id)
+	return err
+}
+
+func (r *repository) GetSettings(ctx context.Context, familyID string) (*Settings, error) {
+	query := `
+		SELECT family_id, shift_to_business_day, weekends_closed, timezone, updated_at
+		FROM family_reminder_settings
+		WHERE family_id = $1
+	`
+
+	var s Settings
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(ctx, query, familyID).Scan(
+		&s.FamilyID, &s.ShiftToBusinessDay, &s.WeekendsClosed, &s.Timezone, &updatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.UpdatedAt = &updatedAt
+	return &s, nil
+}
+
+func (r *repository) UpsertSettings(ctx context.Context, s *Settings) error {
+	query := `
+		INSERT INTO family_reminder_settings (family_id, shift_to_business_day, weekends_closed, timezone, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (family_id) DO UPDATE
+		SET shift_to_business_day = $2, weekends_closed = $3, timezone = $4, updated_at = $5
+	`
+
+	_, err := r.db.ExecContext(ctx, query, s.FamilyID, s.ShiftToBusinessDay, s.WeekendsClosed, s.Timezone, s.UpdatedAt)
+	return err
+}
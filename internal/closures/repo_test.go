@@ -0,0 +1,144 @@
+package closures
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	c := &Closure{ID: "closure-1", FamilyID: "family-1", Date: "2024-12-25", Name: "Christmas", CreatedBy: "user-1", CreatedAt: now}
+
+	mock.ExpectExec("INSERT INTO family_closures .+ ON CONFLICT").
+		WithArgs("closure-1", "family-1", "2024-12-25", "Christmas", "user-1", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	created, err := repo.Create(context.Background(), c)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !created {
+		t.Error("Create() should report the closure as created")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Create_Duplicate(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectExec("INSERT INTO family_closures").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	created, err := repo.Create(context.Background(), &Closure{ID: "closure-2", FamilyID: "family-1", Date: "2024-12-25"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created {
+		t.Error("Create() should report a duplicate date as not created")
+	}
+}
+
+func TestRepository_ListByFamily(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "family_id", "closed_on", "name", "created_by", "created_at"}).
+		AddRow("closure-1", "family-1", time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), "Christmas", "user-1", now).
+		AddRow("closure-2", "family-1", time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC), "Boxing Day", "", now)
+
+	mock.ExpectQuery("SELECT .+ FROM family_closures").
+		WithArgs("family-1", "2024-12-01").
+		WillReturnRows(rows)
+
+	closures, err := repo.ListByFamily(context.Background(), "family-1", "2024-12-01")
+	if err != nil {
+		t.Fatalf("ListByFamily() error = %v", err)
+	}
+	if len(closures) != 2 {
+		t.Fatalf("Expected 2 closures, got %d", len(closures))
+	}
+	if closures[0].Date != "2024-12-25" || closures[1].Name != "Boxing Day" {
+		t.Errorf("Unexpected closures %+v", closures)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT .+ FROM family_closures WHERE id").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	c, err := repo.GetByID(context.Background(), "missing")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if c != nil {
+		t.Error("GetByID() should return nil for a missing closure")
+	}
+}
+
+func TestRepository_GetSettings_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT .+ FROM family_reminder_settings").
+		WithArgs("family-1").
+		WillReturnError(sql.ErrNoRows)
+
+	s, err := repo.GetSettings(context.Background(), "family-1")
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if s != nil {
+		t.Error("GetSettings() should return nil when nothing is saved")
+	}
+}
+
+func TestRepository_UpsertSettings(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	s := &Settings{FamilyID: "family-1", ShiftToBusinessDay: true, WeekendsClosed: true, Timezone: "Europe/London", UpdatedAt: &now}
+
+	mock.ExpectExec("INSERT INTO family_reminder_settings .+ ON CONFLICT").
+		WithArgs("family-1", true, true, "Europe/London", &now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.UpsertSettings(context.Background(), s); err != nil {
+		t.Fatalf("UpsertSettings() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
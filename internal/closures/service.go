@@ -0,0 +1,227 @@
+// Package closures keeps each family's calendar of days the clinic is closed
+// and moves reminders that fall on them to the next open day.
+package closures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+)
+
+var (
+	ErrClosureNotFound = errors.New("closure not found")
+	ErrDuplicateDate   = errors.New("the family already has a closure on that date")
+	ErrInvalidDate     = errors.New("date must be YYYY-MM-DD")
+	ErrInvalidTimezone = errors.New("invalid timezone")
+	ErrNotMember       = errors.New("user is not a member of this family")
+	ErrNotAdmin        = errors.New("only admins can change the closure calendar")
+)
+
+const defaultTimezone = "UTC"
+
+type Service interface {
+	GetSettings(ctx context.Context, userID, familyID string) (*Settings, error)
+	UpdateSettings(ctx context.Context, userID, familyID string, req *UpdateSettingsRequest) (*Settings, error)
+
+	// List returns the family's closures from today on, or every closure when
+	// all is set
+	List(ctx context.Context, userID, familyID string, all bool) ([]Closure, error)
+	Add(ctx context.Context, userID, familyID string, req *CreateClosureRequest) (*Closure, error)
+	Delete(ctx context.Context, userID, familyID, closureID string) error
+
+	// ShiftForChild returns when a reminder due for the child should go out:
+	// due itself, or the next open day when the child's family shifts
+	// reminders and due falls on a closed day
+	ShiftForChild(ctx context.Context, childID string, due time.Time) (time.Time, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	now           func() time.Time
+}
+
+func NewService(repo Repository, familyService family.Service) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+		now:           time.Now,
+	}
+}
+
+// HealthCheck reports whether closures and reminder settings can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) GetSettings(ctx context.Context, userID, familyID string) (*Settings, error) {
+	if _, err := s.memberRole(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+	return s.settings(ctx, familyID)
+}
+
+func (s *service) UpdateSettings(ctx context.Context, userID, familyID string, req *UpdateSettingsRequest) (*Settings, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	tz := strings.TrimSpace(req.Timezone)
+	if tz == "" {
+		tz = defaultTimezone
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return nil, ErrInvalidTimezone
+	}
+
+	now := s.now()
+	settings := &Settings{
+		FamilyID:           familyID,
+		ShiftToBusinessDay: *req.ShiftToBusinessDay,
+		WeekendsClosed:     *req.WeekendsClosed,
+		Timezone:           tz,
+		UpdatedAt:          &now,
+	}
+	if err := s.repo.UpsertSettings(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save reminder settings: %w", err)
+	}
+	return settings, nil
+}
+
+func (s *service) List(ctx context.Context, userID, familyID string, all bool) ([]Closure, error) {
+	if _, err := s.memberRole(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	from := ""
+	if !all {
+		from = s.now().Format(time.DateOnly)
+	}
+	closures, err := s.repo.ListByFamily(ctx, familyID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list closures: %w", err)
+	}
+	return closures, nil
+}
+
+func (s *service) Add(ctx context.Context, userID, familyID string, req *CreateClosureRequest) (*Closure, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	date, err := time.Parse(time.DateOnly, strings.TrimSpace(req.Date))
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+
+	c := &Closure{
+		ID:        ids.New(),
+		FamilyID:  familyID,
+		Date:      date.Format(time.DateOnly),
+		Name:      strings.TrimSpace(req.Name),
+		CreatedBy: userID,
+		CreatedAt: s.now(),
+	}
+	created, err := s.repo.Create(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create closure: %w", err)
+	}
+	if !created {
+		return nil, ErrDuplicateDate
+	}
+	return c, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, familyID, closureID string) error {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return err
+	}
+
+	c, err := s.repo.GetByID(ctx, closureID)
+	if err != nil {
+		return fmt.Errorf("failed to get closure: %w", err)
+	}
+	if c == nil || c.FamilyID != familyID {
+		return ErrClosureNotFound
+	}
+	return s.repo.Delete(ctx, closureID)
+}
+
+func (s *service) ShiftForChild(ctx context.Context, childID string, due time.Time) (time.Time, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return due, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return due, nil
+	}
+
+	settings, err := s.settings(ctx, child.FamilyID)
+	if err != nil {
+		return due, err
+	}
+	if !settings.ShiftToBusinessDay {
+		return due, nil
+	}
+
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	closures, err := s.repo.ListByFamily(ctx, child.FamilyID, due.In(loc).Format(time.DateOnly))
+	if err != nil {
+		return due, fmt.Errorf("failed to list closures: %w", err)
+	}
+	closed := make(map[string]bool, len(closures))
+	for _, c := range closures {
+		closed[c.Date] = true
+	}
+
+	return NextBusinessDay(due, loc, settings.WeekendsClosed, closed), nil
+}
+
+// settings returns the family's stored settings, or the defaults when an
+// admin has never saved any
+func (s *service) settings(ctx context.Context, familyID string) (*Settings, error) {
+	settings, err := s.repo.GetSettings(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder settings: %w", err)
+	}
+	if settings == nil {
+		settings = &Settings{
+			FamilyID:       familyID,
+			WeekendsClosed: true,
+			Timezone:       defaultTimezone,
+		}
+	}
+	return settings, nil
+}
+
+func (s *service) memberRole(ctx context.Context, familyID, userID string) (string, error) {
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return "", ErrNotMember
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+func (s *service) requireAdmin(ctx context.Context, familyID, userID string) error {
+	role, err := s.memberRole(ctx, familyID, userID)
+	if err != nil {
+		return err
+	}
+	if role != "admin" {
+		return ErrNotAdmin
+	}
+	return nil
+}
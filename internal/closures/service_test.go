@@ -0,0 +1,268 @@
+package closures
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+type mockRepository struct {
+	closures map[string]*Closure
+	settings map[string]*Settings
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		closures: make(map[string]*Closure),
+		settings: make(map[string]*Settings),
+	}
+}
+
+func (m *mockRepository) Create(ctx context.Context, c *Closure) (bool, error) {
+	for _, existing := range m.closures {
+		if existing.FamilyID == c.FamilyID && existing.Date == c.Date {
+			return false, nil
+		}
+	}
+	copied := *c
+	m.closures[c.ID] = &copied
+	return true, nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Closure, error) {
+	if c, ok := m.closures[id]; ok {
+		copied := *c
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListByFamily(ctx context.Context, familyID, from string) ([]Closure, error) {
+	closures := []Closure{}
+	for _, c := range m.closures {
+		if c.FamilyID == familyID && c.Date >= from {
+			closures = append(closures, *c)
+		}
+	}
+	sort.Slice(closures, func(i, j int) bool { return closures[i].Date < closures[j].Date })
+	return closures, nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	delete(m.closures, id)
+	return nil
+}
+
+func (m *mockRepository) GetSettings(ctx context.Context, familyID string) (*Settings, error) {
+	if s, ok := m.settings[familyID]; ok {
+		copied := *s
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) UpsertSettings(ctx context.Context, s *Settings) error {
+	copied := *s
+	m.settings[s.FamilyID] = &copied
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	roles    map[string]string
+	children map[string]*family.Child
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if role, ok := m.roles[familyID+"/"+userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+// testNow is a Wednesday
+var testNow = time.Date(2024, 5, 29, 12, 0, 0, 0, time.UTC)
+
+func newTestService() (*service, *mockRepository) {
+	repo := newMockRepository()
+	svc := &service{
+		repo: repo,
+		familyService: &mockFamilyService{
+			roles: map[string]string{
+				"family-1/admin-1":  "admin",
+				"family-1/member-1": "member",
+			},
+			children: map[string]*family.Child{
+				"child-1": {ID: "child-1", FamilyID: "family-1"},
+			},
+		},
+		now: func() time.Time { return testNow },
+	}
+	return svc, repo
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestService_GetSettings_Defaults(t *testing.T) {
+	svc, _ := newTestService()
+
+	s, err := svc.GetSettings(context.Background(), "member-1", "family-1")
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if s.ShiftToBusinessDay || !s.WeekendsClosed || s.Timezone != "UTC" {
+		t.Errorf("Unexpected default settings %+v", s)
+	}
+}
+
+func TestService_GetSettings_NotMember(t *testing.T) {
+	svc, _ := newTestService()
+
+	if _, err := svc.GetSettings(context.Background(), "stranger", "family-1"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Expected ErrNotMember, got %v", err)
+	}
+}
+
+func TestService_UpdateSettings(t *testing.T) {
+	svc, repo := newTestService()
+
+	req := &UpdateSettingsRequest{ShiftToBusinessDay: boolPtr(true), WeekendsClosed: boolPtr(false), Timezone: "Europe/London"}
+	s, err := svc.UpdateSettings(context.Background(), "admin-1", "family-1", req)
+	if err != nil {
+		t.Fatalf("UpdateSettings() error = %v", err)
+	}
+	if !s.ShiftToBusinessDay || s.WeekendsClosed || s.Timezone != "Europe/London" {
+		t.Errorf("Unexpected settings %+v", s)
+	}
+	if repo.settings["family-1"] == nil {
+		t.Error("Settings should be saved")
+	}
+}
+
+func TestService_UpdateSettings_Errors(t *testing.T) {
+	svc, _ := newTestService()
+
+	req := &UpdateSettingsRequest{ShiftToBusinessDay: boolPtr(true), WeekendsClosed: boolPtr(true)}
+	if _, err := svc.UpdateSettings(context.Background(), "member-1", "family-1", req); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("Expected ErrNotAdmin, got %v", err)
+	}
+
+	req.Timezone = "Mars/Olympus"
+	if _, err := svc.UpdateSettings(context.Background(), "admin-1", "family-1", req); !errors.Is(err, ErrInvalidTimezone) {
+		t.Errorf("Expected ErrInvalidTimezone, got %v", err)
+	}
+}
+
+func TestService_Add(t *testing.T) {
+	svc, _ := newTestService()
+
+	c, err := svc.Add(context.Background(), "admin-1", "family-1", &CreateClosureRequest{Date: "2024-06-03", Name: " Clinic training day "})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if c.Date != "2024-06-03" || c.Name != "Clinic training day" || c.CreatedBy != "admin-1" {
+		t.Errorf("Unexpected closure %+v", c)
+	}
+
+	_, err = svc.Add(context.Background(), "admin-1", "family-1", &CreateClosureRequest{Date: "2024-06-03"})
+	if !errors.Is(err, ErrDuplicateDate) {
+		t.Errorf("Expected ErrDuplicateDate, got %v", err)
+	}
+}
+
+func TestService_Add_Errors(t *testing.T) {
+	svc, _ := newTestService()
+
+	if _, err := svc.Add(context.Background(), "member-1", "family-1", &CreateClosureRequest{Date: "2024-06-03"}); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("Expected ErrNotAdmin, got %v", err)
+	}
+	if _, err := svc.Add(context.Background(), "admin-1", "family-1", &CreateClosureRequest{Date: "03/06/2024"}); !errors.Is(err, ErrInvalidDate) {
+		t.Errorf("Expected ErrInvalidDate, got %v", err)
+	}
+}
+
+func TestService_List(t *testing.T) {
+	svc, repo := newTestService()
+	repo.closures["past"] = &Closure{ID: "past", FamilyID: "family-1", Date: "2024-01-01"}
+	repo.closures["future"] = &Closure{ID: "future", FamilyID: "family-1", Date: "2024-12-25"}
+	repo.closures["other"] = &Closure{ID: "other", FamilyID: "family-2", Date: "2024-12-25"}
+
+	upcoming, err := svc.List(context.Background(), "member-1", "family-1", false)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(upcoming) != 1 || upcoming[0].ID != "future" {
+		t.Errorf("Expected only the upcoming closure, got %+v", upcoming)
+	}
+
+	all, _ := svc.List(context.Background(), "member-1", "family-1", true)
+	if len(all) != 2 {
+		t.Errorf("Expected 2 closures, got %d", len(all))
+	}
+}
+
+func TestService_Delete(t *testing.T) {
+	svc, repo := newTestService()
+	repo.closures["closure-1"] = &Closure{ID: "closure-1", FamilyID: "family-1", Date: "2024-12-25"}
+	repo.closures["closure-2"] = &Closure{ID: "closure-2", FamilyID: "family-2", Date: "2024-12-25"}
+
+	if err := svc.Delete(context.Background(), "admin-1", "family-1", "closure-2"); !errors.Is(err, ErrClosureNotFound) {
+		t.Errorf("Expected ErrClosureNotFound for another family's closure, got %v", err)
+	}
+	if err := svc.Delete(context.Background(), "admin-1", "family-1", "closure-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := repo.closures["closure-1"]; ok {
+		t.Error("Closure should be deleted")
+	}
+}
+
+func TestService_ShiftForChild(t *testing.T) {
+	// 2024-06-01 is a Saturday
+	due := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("off by default", func(t *testing.T) {
+		svc, _ := newTestService()
+
+		got, err := svc.ShiftForChild(context.Background(), "child-1", due)
+		if err != nil {
+			t.Fatalf("ShiftForChild() error = %v", err)
+		}
+		if !got.Equal(due) {
+			t.Errorf("ShiftForChild() = %s, want unchanged", got)
+		}
+	})
+
+	t.Run("skips weekend and holiday", func(t *testing.T) {
+		svc, repo := newTestService()
+		repo.settings["family-1"] = &Settings{FamilyID: "family-1", ShiftToBusinessDay: true, WeekendsClosed: true, Timezone: "UTC"}
+		repo.closures["closure-1"] = &Closure{ID: "closure-1", FamilyID: "family-1", Date: "2024-06-03"}
+
+		got, err := svc.ShiftForChild(context.Background(), "child-1", due)
+		if err != nil {
+			t.Fatalf("ShiftForChild() error = %v", err)
+		}
+		if got.Format(time.DateOnly) != "2024-06-04" || got.Hour() != 9 {
+			t.Errorf("ShiftForChild() = %s, want Tuesday 09:00", got)
+		}
+	})
+
+	t.Run("unknown child", func(t *testing.T) {
+		svc, _ := newTestService()
+
+		got, err := svc.ShiftForChild(context.Background(), "missing", due)
+		if err != nil || !got.Equal(due) {
+			t.Errorf("ShiftForChild() = %s, %v, want unchanged", got, err)
+		}
+	})
+}
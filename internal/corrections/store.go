@@ -0,0 +1,132 @@
+// Package corrections keeps the original of a clinically relevant record,
+// such as an administered vaccine or a medication dose, each time it is
+// corrected, together with the corrected values and why they changed.
+package corrections
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+)
+
+type EntityType string
+
+const (
+	EntityVaccination   EntityType = "vaccination"
+	EntityMedicationLog EntityType = "medication_log"
+)
+
+var (
+	ErrReasonRequired = errors.New("a reason is required to correct a record")
+	// ErrUnavailable is returned by services built without a Store
+	ErrUnavailable = errors.New("record corrections are not available")
+)
+
+// Correction is one change to a record: its values before and after, who
+// made it and why
+type Correction struct {
+	ID          string          `json:"id"`
+	EntityType  EntityType      `json:"entity_type"`
+	EntityID    string          `json:"entity_id"`
+	ChildID     string          `json:"child_id"`
+	Original    json.RawMessage `json:"original"`
+	Corrected   json.RawMessage `json:"corrected"`
+	Reason      string          `json:"reason"`
+	CorrectedBy string          `json:"corrected_by,omitempty"`
+	CorrectedAt time.Time       `json:"corrected_at"`
+}
+
+// Reason trims reason and checks that one was given
+func Reason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", ErrReasonRequired
+	}
+	return reason, nil
+}
+
+type Store interface {
+	// Record stores c with original and corrected as the record's values
+	// before and after, filling in its ID. It joins the caller's
+	// transaction, so the correction is only kept if the record's update
+	// commits.
+	Record(ctx context.Context, c *Correction, original, corrected any) error
+	// List returns the corrections of one record, oldest first
+	List(ctx context.Context, entityType EntityType, entityID string) ([]Correction, error)
+}
+
+type store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) Store {
+	return &store{db: db}
+}
+
+// HealthCheck checks the corrections table, which corrected records are
+// written to in the same transaction
+func (s *store) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, s.db, "record_corrections")
+}
+
+func (s *store) Record(ctx context.Context, c *Correction, original, corrected any) error {
+	var err error
+	if c.Original, err = json.Marshal(original); err != nil {
+		return fmt.Errorf("failed to encode original %s %s: %w", c.EntityType, c.EntityID, err)
+	}
+	if c.Corrected, err = json.Marshal(corrected); err != nil {
+		return fmt.Errorf("failed to encode corrected %s %s: %w", c.EntityType, c.EntityID, err)
+	}
+	if c.ID == "" {
+		c.ID = ids.New()
+	}
+
+	query := `
+		INSERT INTO record_corrections (id, entity_type, entity_id, child_id, original, corrected, reason, corrected_by, corrected_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	correctedBy := sql.NullString{String: c.CorrectedBy, Valid: c.CorrectedBy != ""}
+	_, err = db.Use(ctx, s.db).ExecContext(ctx, query,
+		c.ID, c.EntityType, c.EntityID, c.ChildID, []byte(c.Original), []byte(c.Corrected),
+		c.Reason, correctedBy, c.CorrectedAt,
+	)
+	return err
+}
+
+func (s *store) List(ctx context.Context, entityType EntityType, entityID string) ([]Correction, error) {
+	query := `
+		SELECT id, entity_type, entity_id, child_id, original, corrected, reason, COALESCE(corrected_by, ''), corrected_at
+		FROM record_corrections
+		WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY corrected_at, id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	list := []Correction{}
+	for rows.Next() {
+		var c Correction
+		var original, corrected []byte
+		if err := rows.Scan(&c.ID, &c.EntityType, &c.EntityID, &c.ChildID, &original, &corrected,
+			&c.Reason, &c.CorrectedBy, &c.CorrectedAt); err != nil {
+			return nil, err
+		}
+		c.Original = original
+		c.Corrected = corrected
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,83 @@
+package corrections
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type dose struct {
+	Dosage string `json:"dosage"`
+}
+
+func TestStore_Record(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	s := NewStore(db)
+
+	at := time.Now()
+	mock.ExpectExec("INSERT INTO record_corrections").
+		WithArgs(sqlmock.AnyArg(), EntityMedicationLog, "log-1", "child-1", []byte(`{"dosage":"5 ml"}`), []byte(`{"dosage":"2.5 ml"}`),
+			"wrong syringe", sql.NullString{String: "user-1", Valid: true}, at).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	c := &Correction{
+		EntityType:  EntityMedicationLog,
+		EntityID:    "log-1",
+		ChildID:     "child-1",
+		Reason:      "wrong syringe",
+		CorrectedBy: "user-1",
+		CorrectedAt: at,
+	}
+	if err := s.Record(context.Background(), c, dose{"5 ml"}, dose{"2.5 ml"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if c.ID == "" || string(c.Original) != `{"dosage":"5 ml"}` || string(c.Corrected) != `{"dosage":"2.5 ml"}` {
+		t.Errorf("Record() left correction as %+v", c)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestStore_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	s := NewStore(db)
+
+	at := time.Now()
+	mock.ExpectQuery("FROM record_corrections").
+		WithArgs(EntityVaccination, "vax-1").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "entity_type", "entity_id", "child_id", "original", "corrected", "reason", "corrected_by", "corrected_at",
+		}).AddRow("corr-1", "vaccination", "vax-1", "child-1", []byte(`{"lot_number":"A1"}`), []byte(`{"lot_number":"A7"}`),
+			"lot misread", "", at))
+
+	list, err := s.List(context.Background(), EntityVaccination, "vax-1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].Reason != "lot misread" || string(list[0].Corrected) != `{"lot_number":"A7"}` || list[0].CorrectedBy != "" {
+		t.Errorf("List() = %+v", list)
+	}
+}
+
+func TestReason(t *testing.T) {
+	if got, err := Reason("  typo in lot number "); err != nil || got != "typo in lot number" {
+		t.Errorf("Reason() = %q, %v", got, err)
+	}
+	if _, err := Reason(" \n"); !errors.Is(err, ErrReasonRequired) {
+		t.Errorf("Reason(blank) error = %v, want ErrReasonRequired", err)
+	}
+}
@@ -0,0 +1,61 @@
+package dashboard
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ninenine/babytrack/internal/family"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterFamilyRoutes registers the family-scoped dashboard routes on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/due", h.getFamilyDue)
+}
+
+// RegisterMeRoutes registers the signed-in user's cross-family views
+func (h *Handler) RegisterMeRoutes(rg *gin.RouterGroup) {
+	rg.GET("/children", h.getMyChildren)
+}
+
+func (h *Handler) getMyChildren(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	children, err := h.service.MyChildren(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, children)
+}
+
+func (h *Handler) getFamilyDue(c *gin.Context) {
+	familyID := c.Param("familyId")
+	days := 14 // default
+	if d := c.Query("days"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 {
+			days = parsed
+		}
+	}
+
+	due, err := h.service.GetFamilyDue(c.Request.Context(), c.GetString("user_id"), familyID, days)
+	if err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, due)
+}
@@ -0,0 +1,197 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/family"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	getFamilyDueFn func(ctx context.Context, userID, familyID string, days int) (*FamilyDue, error)
+	myChildrenFn   func(ctx context.Context, userID string) ([]ChildStatus, error)
+}
+
+func (m *mockService) GetFamilyDue(ctx context.Context, userID, familyID string, days int) (*FamilyDue, error) {
+	if m.getFamilyDueFn != nil {
+		return m.getFamilyDueFn(ctx, userID, familyID, days)
+	}
+	return nil, nil
+}
+
+func (m *mockService) MyChildren(ctx context.Context, userID string) ([]ChildStatus, error) {
+	if m.myChildrenFn != nil {
+		return m.myChildrenFn(ctx, userID)
+	}
+	return []ChildStatus{}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+
+	handler.RegisterFamilyRoutes(router.Group("/families"))
+	handler.RegisterMeRoutes(router.Group("/me"))
+	return router
+}
+
+func TestGetFamilyDue_Success(t *testing.T) {
+	var capturedUserID, capturedFamilyID string
+	var capturedDays int
+	svc := &mockService{
+		getFamilyDueFn: func(ctx context.Context, userID, familyID string, days int) (*FamilyDue, error) {
+			capturedUserID = userID
+			capturedFamilyID = familyID
+			capturedDays = days
+			return &FamilyDue{
+				FamilyID: familyID,
+				Days:     days,
+				Items:    []DueItem{{Type: DueItemVaccination, EntityID: "vax-1", Priority: PriorityOverdue}},
+			}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/families/family-123/due", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if capturedUserID != "test-user-123" {
+		t.Errorf("Expected user test-user-123, got %s", capturedUserID)
+	}
+	if capturedFamilyID != "family-123" {
+		t.Errorf("Expected familyID family-123, got %s", capturedFamilyID)
+	}
+	if capturedDays != 14 {
+		t.Errorf("Expected default days 14, got %d", capturedDays)
+	}
+
+	var result FamilyDue
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Errorf("Expected 1 item, got %d", len(result.Items))
+	}
+}
+
+func TestGetFamilyDue_CustomDays(t *testing.T) {
+	var capturedDays int
+	svc := &mockService{
+		getFamilyDueFn: func(ctx context.Context, userID, familyID string, days int) (*FamilyDue, error) {
+			capturedDays = days
+			return &FamilyDue{}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/families/family-123/due?days=30", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if capturedDays != 30 {
+		t.Errorf("Expected days 30, got %d", capturedDays)
+	}
+}
+
+func TestGetFamilyDue_ServiceError(t *testing.T) {
+	svc := &mockService{
+		getFamilyDueFn: func(ctx context.Context, userID, familyID string, days int) (*FamilyDue, error) {
+			return nil, errors.New("database error")
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/families/family-123/due", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func TestGetFamilyDue_NotMember(t *testing.T) {
+	svc := &mockService{
+		getFamilyDueFn: func(ctx context.Context, userID, familyID string, days int) (*FamilyDue, error) {
+			return nil, family.ErrNotMember
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/families/family-123/due", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestGetMyChildren_Success(t *testing.T) {
+	var capturedUserID string
+	svc := &mockService{
+		myChildrenFn: func(ctx context.Context, userID string) ([]ChildStatus, error) {
+			capturedUserID = userID
+			return []ChildStatus{{ChildID: "child-1", FamilyID: "family-1"}, {ChildID: "child-2", FamilyID: "family-2"}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/me/children", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if capturedUserID != "test-user-123" {
+		t.Errorf("Expected user test-user-123, got %s", capturedUserID)
+	}
+
+	var result []ChildStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(result) != 2 {
+		t.Errorf("Expected 2 children, got %d", len(result))
+	}
+}
+
+func TestGetMyChildren_ServiceError(t *testing.T) {
+	svc := &mockService{
+		myChildrenFn: func(ctx context.Context, userID string) ([]ChildStatus, error) {
+			return nil, errors.New("database error")
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/me/children", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
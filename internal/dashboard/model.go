@@ -0,0 +1,61 @@
+package dashboard
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type DueItemType string
+
+const (
+	DueItemVaccination DueItemType = "vaccination"
+	DueItemMedication  DueItemType = "medication"
+	DueItemAppointment DueItemType = "appointment"
+	DueItemScreening   DueItemType = "screening"
+)
+
+// Priority levels for due items (lower is more urgent)
+const (
+	PriorityOverdue  = 1
+	PriorityDueSoon  = 2
+	PriorityUpcoming = 3
+)
+
+// DueItem is a single upcoming or overdue task for one child
+type DueItem struct {
+	Type      DueItemType `json:"type"`
+	EntityID  string      `json:"entity_id"`
+	ChildID   string      `json:"child_id"`
+	ChildName string      `json:"child_name"`
+	Title     string      `json:"title"`
+	DueAt     time.Time   `json:"due_at"`
+	Overdue   bool        `json:"overdue"`
+	Priority  int         `json:"priority"`
+}
+
+// FamilyDue is the prioritised digest of everything due across a family's children
+type FamilyDue struct {
+	FamilyID    string    `json:"family_id"`
+	Days        int       `json:"days"`
+	Items       []DueItem `json:"items"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// ChildStatus is one child's current state, for a user's combined view of
+// the children in all their families. Record types hidden from the user are
+// listed in Hidden and left empty.
+type ChildStatus struct {
+	ChildID           string                   `json:"child_id"`
+	ChildName         string                   `json:"child_name"`
+	DateOfBirth       time.Time                `json:"date_of_birth"`
+	FamilyID          string                   `json:"family_id"`
+	FamilyName        string                   `json:"family_name"`
+	ActiveSleep       *sleep.Sleep             `json:"active_sleep,omitempty"`
+	NextVaccination   *vaccination.Vaccination `json:"next_vaccination,omitempty"`
+	ActiveMedications []medication.Medication  `json:"active_medications"`
+	Hidden            []visibility.RecordType  `json:"hidden,omitempty"`
+}
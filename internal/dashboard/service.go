@@ -0,0 +1,286 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// dueSoonWindow is how close a due date must be to be flagged as due soon
+const dueSoonWindow = 3 * 24 * time.Hour
+
+type Service interface {
+	// GetFamilyDue lists what is due across the family's children, leaving
+	// out record types hidden from the user
+	GetFamilyDue(ctx context.Context, userID, familyID string, days int) (*FamilyDue, error)
+	// MyChildren returns the status of every child in the user's families
+	MyChildren(ctx context.Context, userID string) ([]ChildStatus, error)
+}
+
+type service struct {
+	familyService      family.Service
+	vaccinationService vaccination.Service
+	medicationService  medication.Service
+	appointmentService appointment.Service
+	sleepService       sleep.Service
+	visibility         visibility.Service
+}
+
+type Option func(*service)
+
+// WithVisibility leaves record types hidden from a member out of their
+// combined child view
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+func NewService(
+	familyService family.Service,
+	vaccinationService vaccination.Service,
+	medicationService medication.Service,
+	appointmentService appointment.Service,
+	sleepService sleep.Service,
+	opts ...Option,
+) Service {
+	s := &service{
+		familyService:      familyService,
+		vaccinationService: vaccinationService,
+		medicationService:  medicationService,
+		appointmentService: appointmentService,
+		sleepService:       sleepService,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *service) GetFamilyDue(ctx context.Context, userID, familyID string, days int) (*FamilyDue, error) {
+	if _, err := s.familyService.GetMemberRole(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	children, err := s.familyService.GetChildren(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get children: %w", err)
+	}
+
+	now := time.Now()
+	horizon := now.AddDate(0, 0, days)
+	items := []DueItem{}
+
+	for _, child := range children {
+		hidden, err := s.hiddenTypes(ctx, userID, child.ID, dueTypes)
+		if err != nil {
+			return nil, err
+		}
+
+		// Vaccinations: anything still scheduled that is overdue or falls inside the window
+		if !slices.Contains(hidden, visibility.RecordVaccination) {
+			vaxes, err := s.vaccinationService.List(ctx, &vaccination.VaccinationFilter{
+				ChildID:  child.ID,
+				Statuses: []vaccination.Status{vaccination.StatusScheduled},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get vaccinations for child %s: %w", child.ID, err)
+			}
+			for _, vax := range vaxes {
+				if vax.ScheduledAt.After(horizon) {
+					continue
+				}
+				items = append(items, newDueItem(DueItemVaccination, vax.ID, &child,
+					fmt.Sprintf("%s (Dose %d)", vax.Name, vax.Dose), vax.ScheduledAt, now))
+			}
+		}
+
+		// Medications: active courses whose end date falls inside the window
+		if !slices.Contains(hidden, visibility.RecordMedication) {
+			meds, err := s.medicationService.List(ctx, &medication.MedicationFilter{
+				ChildID:    child.ID,
+				ActiveOnly: true,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get medications for child %s: %w", child.ID, err)
+			}
+			for _, med := range meds {
+				if med.EndDate == nil || med.EndDate.After(horizon) {
+					continue
+				}
+				items = append(items, newDueItem(DueItemMedication, med.ID, &child,
+					fmt.Sprintf("%s course ends", med.Name), *med.EndDate, now))
+			}
+		}
+
+		// Screenings are part of well visits, so they are hidden with appointments
+		if slices.Contains(hidden, visibility.RecordAppointment) {
+			continue
+		}
+
+		// Appointments: upcoming visits inside the window
+		apts, err := s.appointmentService.GetUpcoming(ctx, child.ID, days)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get appointments for child %s: %w", child.ID, err)
+		}
+		for _, apt := range apts {
+			items = append(items, newDueItem(DueItemAppointment, apt.ID, &child,
+				apt.Title, apt.ScheduledAt, now))
+		}
+
+		// Screenings: checks at well visits that haven't been done, due inside the window
+		screenings, err := s.appointmentService.PendingScreenings(ctx, &child)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get screenings for child %s: %w", child.ID, err)
+		}
+		for _, sc := range screenings {
+			dueOn, err := time.Parse(time.DateOnly, sc.DueOn)
+			if err != nil || dueOn.After(horizon) {
+				continue
+			}
+			items = append(items, newDueItem(DueItemScreening, sc.WellVisit+":"+sc.ID, &child,
+				fmt.Sprintf("%s (%s)", sc.Name, sc.WellVisitName), dueOn, now))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Priority != items[j].Priority {
+			return items[i].Priority < items[j].Priority
+		}
+		return items[i].DueAt.Before(items[j].DueAt)
+	})
+
+	return &FamilyDue{
+		FamilyID:    familyID,
+		Days:        days,
+		Items:       items,
+		GeneratedAt: now,
+	}, nil
+}
+
+func newDueItem(itemType DueItemType, entityID string, child *family.Child, title string, dueAt, now time.Time) DueItem {
+	item := DueItem{
+		Type:      itemType,
+		EntityID:  entityID,
+		ChildID:   child.ID,
+		ChildName: child.Name,
+		Title:     title,
+		DueAt:     dueAt,
+		Priority:  PriorityUpcoming,
+	}
+
+	switch {
+	case dueAt.Before(now):
+		item.Overdue = true
+		item.Priority = PriorityOverdue
+	case dueAt.Sub(now) <= dueSoonWindow:
+		item.Priority = PriorityDueSoon
+	}
+
+	return item
+}
+
+func (s *service) MyChildren(ctx context.Context, userID string) ([]ChildStatus, error) {
+	families, err := s.familyService.GetUserFamilies(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get families: %w", err)
+	}
+
+	statuses := []ChildStatus{}
+	for _, f := range families {
+		for _, child := range f.Children {
+			status, err := s.childStatus(ctx, userID, &child)
+			if err != nil {
+				return nil, err
+			}
+			status.FamilyID, status.FamilyName = f.ID, f.Name
+			statuses = append(statuses, *status)
+		}
+	}
+	return statuses, nil
+}
+
+func (s *service) childStatus(ctx context.Context, userID string, child *family.Child) (*ChildStatus, error) {
+	status := &ChildStatus{
+		ChildID:           child.ID,
+		ChildName:         child.Name,
+		DateOfBirth:       child.DateOfBirth,
+		ActiveMedications: []medication.Medication{},
+	}
+
+	hidden, err := s.hiddenTypes(ctx, userID, child.ID, statusTypes)
+	if err != nil {
+		return nil, err
+	}
+	status.Hidden = hidden
+
+	if !slices.Contains(hidden, visibility.RecordSleep) {
+		active, err := s.sleepService.GetActiveSleep(ctx, child.ID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get active sleep for child %s: %w", child.ID, err)
+		}
+		status.ActiveSleep = active
+	}
+
+	if !slices.Contains(hidden, visibility.RecordVaccination) {
+		vaxes, err := s.vaccinationService.List(ctx, &vaccination.VaccinationFilter{
+			ChildID:  child.ID,
+			Statuses: []vaccination.Status{vaccination.StatusScheduled},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get vaccinations for child %s: %w", child.ID, err)
+		}
+		for i := range vaxes {
+			if status.NextVaccination == nil || vaxes[i].ScheduledAt.Before(status.NextVaccination.ScheduledAt) {
+				status.NextVaccination = &vaxes[i]
+			}
+		}
+	}
+
+	if !slices.Contains(hidden, visibility.RecordMedication) {
+		meds, err := s.medicationService.List(ctx, &medication.MedicationFilter{
+			ChildID:    child.ID,
+			ActiveOnly: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get medications for child %s: %w", child.ID, err)
+		}
+		status.ActiveMedications = append(status.ActiveMedications, meds...)
+	}
+
+	return status, nil
+}
+
+// Record types summarised in a child's status and in the family due list
+var (
+	statusTypes = []visibility.RecordType{visibility.RecordSleep, visibility.RecordVaccination, visibility.RecordMedication}
+	dueTypes    = []visibility.RecordType{visibility.RecordVaccination, visibility.RecordMedication, visibility.RecordAppointment}
+)
+
+// hiddenTypes returns which of the given record types are hidden from the
+// user for the child
+func (s *service) hiddenTypes(ctx context.Context, userID, childID string, types []visibility.RecordType) ([]visibility.RecordType, error) {
+	if s.visibility == nil {
+		return nil, nil
+	}
+	var hidden []visibility.RecordType
+	for _, rt := range types {
+		isHidden, err := s.visibility.IsHidden(ctx, userID, childID, rt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check visibility: %w", err)
+		}
+		if isHidden {
+			hidden = append(hidden, rt)
+		}
+	}
+	return hidden, nil
+}
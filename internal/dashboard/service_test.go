@@ -0,0 +1,334 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/testutil/factory"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// mockFamilyService is a test double for family.Service; unused methods panic via the nil embed
+type mockFamilyService struct {
+	family.Service
+	children    []family.Child
+	childrenErr error
+	families    []family.FamilyWithChildren
+}
+
+func (m *mockFamilyService) GetUserFamilies(ctx context.Context, userID string) ([]family.FamilyWithChildren, error) {
+	return m.families, nil
+}
+
+// GetMemberRole treats user-1 as the only member of every family
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID != "user-1" {
+		return "", family.ErrNotMember
+	}
+	return "member", nil
+}
+
+func (m *mockFamilyService) GetChildren(ctx context.Context, familyID string) ([]family.Child, error) {
+	return m.children, m.childrenErr
+}
+
+// mockVaccinationService is a test double for vaccination.Service
+type mockVaccinationService struct {
+	vaccination.Service
+	vaccinations []vaccination.Vaccination
+	listErr      error
+}
+
+func (m *mockVaccinationService) List(ctx context.Context, filter *vaccination.VaccinationFilter) ([]vaccination.Vaccination, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	var result []vaccination.Vaccination
+	for _, v := range m.vaccinations {
+		if v.ChildID == filter.ChildID {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// mockMedicationService is a test double for medication.Service
+type mockMedicationService struct {
+	medication.Service
+	medications []medication.Medication
+}
+
+func (m *mockMedicationService) ListSince(ctx context.Context, filter *medication.MedicationFilter, since time.Time) (*delta.Changes[medication.Medication], error) {
+	return nil, nil
+}
+
+func (m *mockMedicationService) List(ctx context.Context, filter *medication.MedicationFilter) ([]medication.Medication, error) {
+	var result []medication.Medication
+	for _, med := range m.medications {
+		if med.ChildID == filter.ChildID {
+			result = append(result, med)
+		}
+	}
+	return result, nil
+}
+
+// mockAppointmentService is a test double for appointment.Service
+type mockAppointmentService struct {
+	appointment.Service
+	appointments []appointment.Appointment
+	screenings   map[string][]appointment.ScreeningDue
+}
+
+func (m *mockAppointmentService) PendingScreenings(ctx context.Context, child *family.Child) ([]appointment.ScreeningDue, error) {
+	return m.screenings[child.ID], nil
+}
+
+func (m *mockAppointmentService) GetUpcoming(ctx context.Context, childID string, days int) ([]appointment.Appointment, error) {
+	var result []appointment.Appointment
+	for _, apt := range m.appointments {
+		if apt.ChildID == childID {
+			result = append(result, apt)
+		}
+	}
+	return result, nil
+}
+
+// mockSleepService is a test double for sleep.Service
+type mockSleepService struct {
+	sleep.Service
+	active map[string]*sleep.Sleep
+}
+
+func (m *mockSleepService) GetActiveSleep(ctx context.Context, childID, viewerID string) (*sleep.Sleep, error) {
+	return m.active[childID], nil
+}
+
+// mockVisibilityService hides record types per child
+type mockVisibilityService struct {
+	visibility.Service
+	hidden map[string][]visibility.RecordType
+}
+
+func (m *mockVisibilityService) IsHidden(ctx context.Context, userID, childID string, recordType visibility.RecordType) (bool, error) {
+	return slices.Contains(m.hidden[childID], recordType), nil
+}
+
+func TestService_GetFamilyDue(t *testing.T) {
+	now := time.Now()
+	medEnd := now.AddDate(0, 0, 2)
+	farEnd := now.AddDate(0, 2, 0)
+
+	familySvc := &mockFamilyService{children: []family.Child{
+		{ID: "child-1", Name: "Emma"},
+		{ID: "child-2", Name: "Noah"},
+	}}
+	vaxSvc := &mockVaccinationService{vaccinations: []vaccination.Vaccination{
+		{ID: "vax-overdue", ChildID: "child-1", Name: "PCV", Dose: 2, ScheduledAt: now.AddDate(0, 0, -5)},
+		{ID: "vax-upcoming", ChildID: "child-2", Name: "OPV", Dose: 1, ScheduledAt: now.AddDate(0, 0, 10)},
+		{ID: "vax-far", ChildID: "child-2", Name: "MR", Dose: 1, ScheduledAt: now.AddDate(0, 3, 0)},
+	}}
+	medSvc := &mockMedicationService{medications: []medication.Medication{
+		{ID: "med-ending", ChildID: "child-1", Name: "Amoxicillin", EndDate: &medEnd, Active: true},
+		{ID: "med-long", ChildID: "child-1", Name: "Vitamin D", EndDate: &farEnd, Active: true},
+		{ID: "med-open", ChildID: "child-2", Name: "Iron", Active: true},
+	}}
+	aptSvc := &mockAppointmentService{appointments: []appointment.Appointment{
+		{ID: "apt-1", ChildID: "child-2", Title: "Well visit", ScheduledAt: now.AddDate(0, 0, 7)},
+	}}
+
+	svc := NewService(familySvc, vaxSvc, medSvc, aptSvc, &mockSleepService{})
+
+	due, err := svc.GetFamilyDue(context.Background(), "user-1", "family-1", 14)
+	if err != nil {
+		t.Fatalf("GetFamilyDue() error = %v", err)
+	}
+
+	if len(due.Items) != 4 {
+		t.Fatalf("GetFamilyDue() returned %d items, want 4: %+v", len(due.Items), due.Items)
+	}
+
+	wantOrder := []string{"vax-overdue", "med-ending", "apt-1", "vax-upcoming"}
+	for i, id := range wantOrder {
+		if due.Items[i].EntityID != id {
+			t.Errorf("GetFamilyDue() item %d = %s, want %s", i, due.Items[i].EntityID, id)
+		}
+	}
+
+	if !due.Items[0].Overdue || due.Items[0].Priority != PriorityOverdue {
+		t.Errorf("GetFamilyDue() first item should be overdue, got %+v", due.Items[0])
+	}
+	if due.Items[1].Priority != PriorityDueSoon {
+		t.Errorf("GetFamilyDue() medication ending in 2 days should be due soon, got %d", due.Items[1].Priority)
+	}
+	if due.Items[0].ChildName != "Emma" {
+		t.Errorf("GetFamilyDue() ChildName = %s, want Emma", due.Items[0].ChildName)
+	}
+}
+
+func TestService_GetFamilyDue_Screenings(t *testing.T) {
+	now := time.Now()
+	familySvc := &mockFamilyService{children: []family.Child{factory.Child(func(c *family.Child) { c.Name = "Emma" })}}
+	lead := appointment.Screening{ID: "lead", Name: "Lead screening"}
+	aptSvc := &mockAppointmentService{screenings: map[string][]appointment.ScreeningDue{
+		"child-1": {
+			{Screening: lead, WellVisit: "12m", WellVisitName: "12 month checkup", DueOn: now.AddDate(0, 0, -10).Format(time.DateOnly)},
+			{Screening: lead, WellVisit: "24m", WellVisitName: "2 year checkup", DueOn: now.AddDate(1, 0, 0).Format(time.DateOnly)},
+		},
+	}}
+	svc := NewService(familySvc, &mockVaccinationService{}, &mockMedicationService{}, aptSvc, &mockSleepService{})
+
+	due, err := svc.GetFamilyDue(context.Background(), "user-1", "family-1", 14)
+	if err != nil {
+		t.Fatalf("GetFamilyDue() error = %v", err)
+	}
+	if len(due.Items) != 1 {
+		t.Fatalf("GetFamilyDue() returned %d items, want only the screening inside the window: %+v", len(due.Items), due.Items)
+	}
+	got := due.Items[0]
+	if got.Type != DueItemScreening || got.EntityID != "12m:lead" || got.Title != "Lead screening (12 month checkup)" || !got.Overdue {
+		t.Errorf("GetFamilyDue() screening = %+v", got)
+	}
+}
+
+func TestService_GetFamilyDue_NotMember(t *testing.T) {
+	familySvc := &mockFamilyService{children: []family.Child{{ID: "child-1", Name: "Emma"}}}
+	svc := NewService(familySvc, &mockVaccinationService{}, &mockMedicationService{}, &mockAppointmentService{}, &mockSleepService{})
+
+	if _, err := svc.GetFamilyDue(context.Background(), "stranger", "family-1", 14); !errors.Is(err, family.ErrNotMember) {
+		t.Errorf("GetFamilyDue() by a non-member error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestService_GetFamilyDue_Hidden(t *testing.T) {
+	now := time.Now()
+	end := now.AddDate(0, 0, 2)
+	familySvc := &mockFamilyService{children: []family.Child{{ID: "child-1", Name: "Emma"}, {ID: "child-2", Name: "Noah"}}}
+	vaxSvc := &mockVaccinationService{vaccinations: []vaccination.Vaccination{
+		{ID: "vax-1", ChildID: "child-1", Name: "PCV", Dose: 2, ScheduledAt: now.AddDate(0, 0, 1)},
+		{ID: "vax-2", ChildID: "child-2", Name: "OPV", Dose: 1, ScheduledAt: now.AddDate(0, 0, 1)},
+	}}
+	medSvc := &mockMedicationService{medications: []medication.Medication{
+		{ID: "med-1", ChildID: "child-1", Name: "Amoxicillin", EndDate: &end, Active: true},
+	}}
+	lead := appointment.Screening{ID: "lead", Name: "Lead screening"}
+	aptSvc := &mockAppointmentService{
+		appointments: []appointment.Appointment{{ID: "apt-1", ChildID: "child-1", Title: "Well visit", ScheduledAt: now.AddDate(0, 0, 3)}},
+		screenings: map[string][]appointment.ScreeningDue{
+			"child-1": {{Screening: lead, WellVisit: "12m", WellVisitName: "12 month checkup", DueOn: now.Format(time.DateOnly)}},
+		},
+	}
+	vis := &mockVisibilityService{hidden: map[string][]visibility.RecordType{
+		"child-1": {visibility.RecordMedication, visibility.RecordAppointment},
+		"child-2": {visibility.RecordVaccination},
+	}}
+	svc := NewService(familySvc, vaxSvc, medSvc, aptSvc, &mockSleepService{}, WithVisibility(vis))
+
+	due, err := svc.GetFamilyDue(context.Background(), "user-1", "family-1", 14)
+	if err != nil {
+		t.Fatalf("GetFamilyDue() error = %v", err)
+	}
+	if len(due.Items) != 1 || due.Items[0].EntityID != "vax-1" {
+		t.Errorf("GetFamilyDue() = %+v, want only Emma's vaccination", due.Items)
+	}
+}
+
+func TestService_GetFamilyDue_NoChildren(t *testing.T) {
+	svc := NewService(&mockFamilyService{}, &mockVaccinationService{}, &mockMedicationService{}, &mockAppointmentService{}, &mockSleepService{})
+
+	due, err := svc.GetFamilyDue(context.Background(), "user-1", "family-1", 14)
+	if err != nil {
+		t.Fatalf("GetFamilyDue() error = %v", err)
+	}
+	if due.Items == nil || len(due.Items) != 0 {
+		t.Errorf("GetFamilyDue() Items = %v, want empty slice", due.Items)
+	}
+}
+
+func TestService_GetFamilyDue_ChildrenError(t *testing.T) {
+	familySvc := &mockFamilyService{childrenErr: errors.New("database error")}
+	svc := NewService(familySvc, &mockVaccinationService{}, &mockMedicationService{}, &mockAppointmentService{}, &mockSleepService{})
+
+	if _, err := svc.GetFamilyDue(context.Background(), "user-1", "family-1", 14); err == nil {
+		t.Error("GetFamilyDue() should return error when children lookup fails")
+	}
+}
+
+func TestService_GetFamilyDue_VaccinationError(t *testing.T) {
+	familySvc := &mockFamilyService{children: []family.Child{{ID: "child-1"}}}
+	vaxSvc := &mockVaccinationService{listErr: errors.New("database error")}
+	svc := NewService(familySvc, vaxSvc, &mockMedicationService{}, &mockAppointmentService{}, &mockSleepService{})
+
+	if _, err := svc.GetFamilyDue(context.Background(), "user-1", "family-1", 14); err == nil {
+		t.Error("GetFamilyDue() should return error when vaccination lookup fails")
+	}
+}
+
+func TestService_MyChildren(t *testing.T) {
+	now := time.Now()
+	familySvc := &mockFamilyService{families: []family.FamilyWithChildren{
+		{ID: "family-1", Name: "Mum's", Children: []family.Child{{ID: "child-1", FamilyID: "family-1", Name: "Emma"}}},
+		{ID: "family-2", Name: "Dad's", Children: []family.Child{{ID: "child-2", FamilyID: "family-2", Name: "Noah"}}},
+	}}
+	vaxSvc := &mockVaccinationService{vaccinations: []vaccination.Vaccination{
+		{ID: "vax-later", ChildID: "child-1", Name: "MR", ScheduledAt: now.AddDate(0, 2, 0)},
+		{ID: "vax-next", ChildID: "child-1", Name: "PCV", ScheduledAt: now.AddDate(0, 0, 3)},
+		{ID: "vax-2", ChildID: "child-2", Name: "OPV", ScheduledAt: now.AddDate(0, 0, 1)},
+	}}
+	medSvc := &mockMedicationService{medications: []medication.Medication{
+		{ID: "med-1", ChildID: "child-2", Name: "Iron", Active: true},
+	}}
+	sleepSvc := &mockSleepService{active: map[string]*sleep.Sleep{
+		"child-1": {ID: "sleep-1", ChildID: "child-1", Type: sleep.SleepTypeNap, StartTime: now.Add(-time.Hour)},
+	}}
+	vis := &mockVisibilityService{hidden: map[string][]visibility.RecordType{
+		"child-2": {visibility.RecordVaccination},
+	}}
+
+	svc := NewService(familySvc, vaxSvc, medSvc, &mockAppointmentService{}, sleepSvc, WithVisibility(vis))
+
+	children, err := svc.MyChildren(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("MyChildren() error = %v", err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("MyChildren() returned %d children, want 2", len(children))
+	}
+
+	emma, noah := children[0], children[1]
+	if emma.FamilyName != "Mum's" || noah.FamilyID != "family-2" {
+		t.Errorf("Children should carry their family, got %+v and %+v", emma, noah)
+	}
+	if emma.ActiveSleep == nil || emma.ActiveSleep.ID != "sleep-1" {
+		t.Errorf("Emma's active sleep = %+v, want sleep-1", emma.ActiveSleep)
+	}
+	if emma.NextVaccination == nil || emma.NextVaccination.ID != "vax-next" {
+		t.Errorf("Emma's next vaccination = %+v, want vax-next", emma.NextVaccination)
+	}
+	if noah.NextVaccination != nil || !slices.Contains(noah.Hidden, visibility.RecordVaccination) {
+		t.Errorf("Noah's vaccinations should be hidden, got %+v", noah)
+	}
+	if len(noah.ActiveMedications) != 1 || len(emma.ActiveMedications) != 0 {
+		t.Errorf("Unexpected active medications: Emma %v, Noah %v", emma.ActiveMedications, noah.ActiveMedications)
+	}
+}
+
+func TestService_MyChildren_NoFamilies(t *testing.T) {
+	svc := NewService(&mockFamilyService{}, &mockVaccinationService{}, &mockMedicationService{}, &mockAppointmentService{}, &mockSleepService{})
+
+	children, err := svc.MyChildren(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("MyChildren() error = %v", err)
+	}
+	if children == nil || len(children) != 0 {
+		t.Errorf("MyChildren() = %v, want an empty list", children)
+	}
+}
@@ -0,0 +1,74 @@
+package daycare
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Connector reads daily reports from one provider's daycare software. Each
+// is registered under its provider name and reads reports by polling the
+// provider, by receiving its pushes, or both.
+type Connector interface {
+	Provider() string
+}
+
+// Poller is a connector that fetches reports from the provider's API
+type Poller interface {
+	Connector
+	// Fetch returns the entries after cursor, or from wherever the provider
+	// starts when cursor is empty. token is the connection's opened token.
+	Fetch(ctx context.Context, conn *Connection, token, cursor string) (*Batch, error)
+}
+
+// Receiver is a connector that accepts reports the provider pushes to us
+type Receiver interface {
+	Connector
+	Parse(body []byte) (*Batch, error)
+}
+
+// ProviderInfo describes a registered connector
+type ProviderInfo struct {
+	Provider string `json:"provider"`
+	Polls    bool   `json:"polls"`
+	Receives bool   `json:"receives"`
+}
+
+// Registry holds the connectors a server was built with
+type Registry struct {
+	connectors map[string]Connector
+}
+
+func NewRegistry(connectors ...Connector) *Registry {
+	r := &Registry{connectors: make(map[string]Connector)}
+	for _, c := range connectors {
+		r.Register(c)
+	}
+	return r
+}
+
+// Register adds a connector. Two connectors for one provider is a wiring
+// mistake and panics at startup.
+func (r *Registry) Register(c Connector) {
+	if _, ok := r.connectors[c.Provider()]; ok {
+		panic(fmt.Sprintf("daycare: connector %q registered twice", c.Provider()))
+	}
+	r.connectors[c.Provider()] = c
+}
+
+func (r *Registry) Get(provider string) (Connector, bool) {
+	c, ok := r.connectors[provider]
+	return c, ok
+}
+
+// Providers lists the registered connectors by name
+func (r *Registry) Providers() []ProviderInfo {
+	providers := make([]ProviderInfo, 0, len(r.connectors))
+	for name, c := range r.connectors {
+		_, polls := c.(Poller)
+		_, receives := c.(Receiver)
+		providers = append(providers, ProviderInfo{Provider: name, Polls: polls, Receives: receives})
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Provider < providers[j].Provider })
+	return providers
+}
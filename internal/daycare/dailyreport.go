@@ -0,0 +1,80 @@
+package daycare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ProviderDailyReport is the provider-neutral daily report format: a JSON
+// Batch, served from the connection's endpoint or pushed to the webhook.
+// Daycare software that can produce it needs no connector of its own.
+const ProviderDailyReport = "daily_report"
+
+// maxReportBody caps the size of a fetched report
+const maxReportBody = 4 << 20
+
+// DailyReport is the connector for the daily report format
+type DailyReport struct {
+	client *http.Client
+}
+
+func NewDailyReport(client *http.Client) *DailyReport {
+	return &DailyReport{client: client}
+}
+
+func (d *DailyReport) Provider() string {
+	return ProviderDailyReport
+}
+
+// Fetch GETs the endpoint with the cursor as a query parameter and the token
+// as a bearer token
+func (d *DailyReport) Fetch(ctx context.Context, conn *Connection, token, cursor string) (*Batch, error) {
+	u, err := url.Parse(conn.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if cursor != "" {
+		q := u.Query()
+		q.Set("cursor", cursor)
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider answered %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReportBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxReportBody {
+		return nil, fmt.Errorf("report larger than %d bytes", maxReportBody)
+	}
+	return d.Parse(body)
+}
+
+func (d *DailyReport) Parse(body []byte) (*Batch, error) {
+	var batch Batch
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, fmt.Errorf("invalid daily report: %w", err)
+	}
+	return &batch, nil
+}
@@ -0,0 +1,49 @@
+package daycare
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDailyReport_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer provider-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("room") != "blue" || r.URL.Query().Get("cursor") != "page-1" {
+			t.Errorf("Fetch() requested %s", r.URL)
+		}
+		w.Write([]byte(`{"cursor":"page-2","entries":[{"id":"e1","child_id":"kid-42","kind":"note","start_time":"2024-06-03T10:00:00Z","text":"Sang songs"}]}`))
+	}))
+	defer server.Close()
+
+	connector := NewDailyReport(server.Client())
+	conn := &Connection{Endpoint: server.URL + "/reports?room=blue"}
+
+	batch, err := connector.Fetch(context.Background(), conn, "provider-secret", "page-1")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if batch.Cursor != "page-2" || len(batch.Entries) != 1 || batch.Entries[0].Kind != EntryNote {
+		t.Errorf("Fetch() = %+v", batch)
+	}
+
+	if _, err := connector.Fetch(context.Background(), conn, "wrong", ""); err == nil {
+		t.Error("Fetch() should fail when the provider refuses")
+	}
+}
+
+func TestDailyReport_Parse(t *testing.T) {
+	connector := NewDailyReport(nil)
+
+	if _, err := connector.Parse([]byte(`{"entries":`)); err == nil {
+		t.Error("Parse() should reject truncated JSON")
+	}
+	batch, err := connector.Parse([]byte(`{"entries":[{"id":"e1","kind":"sleep"}]}`))
+	if err != nil || len(batch.Entries) != 1 {
+		t.Errorf("Parse() = %+v, %v", batch, err)
+	}
+}
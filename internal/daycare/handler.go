@@ -0,0 +1,131 @@
+package daycare
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ninenine/babytrack/internal/integrations"
+	"github.com/ninenine/babytrack/internal/limits"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterFamilyRoutes registers connection management on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/daycare-connections", h.list)
+	rg.POST("/:familyId/daycare-connections", h.connect)
+	rg.DELETE("/:familyId/daycare-connections/:connectionId", h.disconnect)
+	rg.POST("/:familyId/daycare-connections/:connectionId/sync", h.sync)
+}
+
+// RegisterRoutes registers the list of available providers
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/providers", h.providers)
+}
+
+// RegisterWebhookRoutes registers the push endpoint behind verify, which
+// authenticates the request with one of the family's receiver keys
+func (h *Handler) RegisterWebhookRoutes(rg *gin.RouterGroup, verify gin.HandlerFunc) {
+	rg.POST("/daycare/:connectionId", verify, h.receive)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	connections, err := h.service.List(c.Request.Context(), userID, c.Param("familyId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, connections)
+}
+
+func (h *Handler) connect(c *gin.Context) {
+	var req CreateConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	conn, err := h.service.Connect(c.Request.Context(), userID, c.Param("familyId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, conn)
+}
+
+func (h *Handler) disconnect(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.service.Disconnect(c.Request.Context(), userID, c.Param("familyId"), c.Param("connectionId")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) sync(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	result, err := h.service.Sync(c.Request.Context(), userID, c.Param("familyId"), c.Param("connectionId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) providers(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Providers())
+}
+
+func (h *Handler) receive(c *gin.Context) {
+	key, ok := integrations.KeyFrom(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "missing receiver key"})
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.Receive(c.Request.Context(), key.FamilyID, c.Param("connectionId"), body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrConnectionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotAdmin), errors.Is(err, ErrNotMember), errors.Is(err, ErrChildNotInFamily):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUnknownProvider), errors.Is(err, ErrInvalidEndpoint), errors.Is(err, ErrPollUnsupported),
+		errors.Is(err, ErrPushUnsupported), errors.Is(err, ErrInvalidReport), errors.Is(err, ErrTooManyEntries),
+		errors.Is(err, limits.ErrExceeded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrProviderUnavailable):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTokenUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,205 @@
+package daycare
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/integrations"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	connectFn    func(ctx context.Context, userID, familyID string, req *CreateConnectionRequest) (*Connection, error)
+	listFn       func(ctx context.Context, userID, familyID string) ([]Connection, error)
+	disconnectFn func(ctx context.Context, userID, familyID, connectionID string) error
+	syncFn       func(ctx context.Context, userID, familyID, connectionID string) (*SyncResult, error)
+	receiveFn    func(ctx context.Context, familyID, connectionID string, body []byte) (*SyncResult, error)
+}
+
+func (m *mockService) Connect(ctx context.Context, userID, familyID string, req *CreateConnectionRequest) (*Connection, error) {
+	if m.connectFn != nil {
+		return m.connectFn(ctx, userID, familyID, req)
+	}
+	return &Connection{}, nil
+}
+
+func (m *mockService) List(ctx context.Context, userID, familyID string) ([]Connection, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, familyID)
+	}
+	return []Connection{}, nil
+}
+
+func (m *mockService) Disconnect(ctx context.Context, userID, familyID, connectionID string) error {
+	if m.disconnectFn != nil {
+		return m.disconnectFn(ctx, userID, familyID, connectionID)
+	}
+	return nil
+}
+
+func (m *mockService) Providers() []ProviderInfo {
+	return []ProviderInfo{{Provider: ProviderDailyReport, Polls: true, Receives: true}}
+}
+
+func (m *mockService) Sync(ctx context.Context, userID, familyID, connectionID string) (*SyncResult, error) {
+	if m.syncFn != nil {
+		return m.syncFn(ctx, userID, familyID, connectionID)
+	}
+	return &SyncResult{}, nil
+}
+
+func (m *mockService) SyncAll(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (m *mockService) Receive(ctx context.Context, familyID, connectionID string, body []byte) (*SyncResult, error) {
+	if m.receiveFn != nil {
+		return m.receiveFn(ctx, familyID, connectionID, body)
+	}
+	return &SyncResult{}, nil
+}
+
+// mockIntegrationsService accepts every signed request as family-1's key
+type mockIntegrationsService struct {
+	integrations.Service
+}
+
+func (m *mockIntegrationsService) Verify(ctx context.Context, req *integrations.SignedRequest) (*integrations.ReceiverKey, error) {
+	if req.Signature == "" {
+		return nil, integrations.ErrInvalidSignature
+	}
+	return &integrations.ReceiverKey{ID: "key-1", FamilyID: "family-1"}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	verify := integrations.NewHandler(&mockIntegrationsService{}).Verify()
+	handler.RegisterWebhookRoutes(router.Group("/webhooks"), verify)
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterFamilyRoutes(protected.Group("/families"))
+	handler.RegisterRoutes(protected.Group("/daycare"))
+	return router
+}
+
+func TestConnect_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{ErrNotAdmin, http.StatusForbidden},
+		{ErrUnknownProvider, http.StatusBadRequest},
+		{ErrInvalidEndpoint, http.StatusBadRequest},
+		{ErrTokenUnavailable, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		svc := &mockService{
+			connectFn: func(ctx context.Context, userID, familyID string, req *CreateConnectionRequest) (*Connection, error) {
+				return nil, tt.err
+			},
+		}
+		router := setupRouter(svc)
+
+		body := `{"provider":"daily_report","name":"Sunny Days","children":{"kid-42":"child-1"}}`
+		req := httptest.NewRequest("POST", "/families/family-1/daycare-connections", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.status, w.Code)
+		}
+	}
+}
+
+func TestConnect_RequiresChildren(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("POST", "/families/family-1/daycare-connections", bytes.NewBufferString(`{"provider":"daily_report","name":"Sunny Days"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestSync_ProviderUnavailable(t *testing.T) {
+	svc := &mockService{
+		syncFn: func(ctx context.Context, userID, familyID, connectionID string) (*SyncResult, error) {
+			return nil, ErrProviderUnavailable
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/families/family-1/daycare-connections/conn-1/sync", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Expected status 502, got %d", w.Code)
+	}
+}
+
+func TestReceive(t *testing.T) {
+	var gotFamily, gotConnection, gotBody string
+	svc := &mockService{
+		receiveFn: func(ctx context.Context, familyID, connectionID string, body []byte) (*SyncResult, error) {
+			gotFamily, gotConnection, gotBody = familyID, connectionID, string(body)
+			return &SyncResult{Imported: []ImportedEntry{}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/webhooks/daycare/conn-1", bytes.NewBufferString(`{"entries":[]}`))
+	req.Header.Set(integrations.HeaderSignature, "sha256=abc")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotFamily != "family-1" || gotConnection != "conn-1" || gotBody != `{"entries":[]}` {
+		t.Errorf("Receive() called with %q %q %q", gotFamily, gotConnection, gotBody)
+	}
+
+	// Unsigned pushes never reach the service
+	req = httptest.NewRequest("POST", "/webhooks/daycare/conn-1", bytes.NewBufferString(`{"entries":[]}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a signature, got %d", w.Code)
+	}
+}
+
+func TestProviders(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/daycare/providers", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(ProviderDailyReport)) {
+		t.Errorf("Expected the providers, got %d: %s", w.Code, w.Body.String())
+	}
+}
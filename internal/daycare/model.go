@@ -0,0 +1,82 @@
+package daycare
+
+import "time"
+
+type EntryKind string
+
+const (
+	EntryFeeding EntryKind = "feeding"
+	EntrySleep   EntryKind = "sleep"
+	EntryNote    EntryKind = "note"
+)
+
+// Connection links a family to its daycare's software through one of the
+// registered connectors. Children maps the provider's child IDs to the
+// family's children; entries for children not in it are not imported. The
+// token the connector polls with is never returned.
+type Connection struct {
+	ID           string            `json:"id"`
+	FamilyID     string            `json:"family_id"`
+	Provider     string            `json:"provider"`
+	Name         string            `json:"name"`
+	Endpoint     string            `json:"endpoint,omitempty"`
+	HasToken     bool              `json:"has_token"`
+	Children     map[string]string `json:"children"`
+	CreatedBy    string            `json:"created_by"`
+	CreatedAt    time.Time         `json:"created_at"`
+	LastSyncedAt *time.Time        `json:"last_synced_at,omitempty"`
+	LastError    string            `json:"last_error,omitempty"`
+
+	cursor string
+	token  []byte // sealed
+}
+
+type CreateConnectionRequest struct {
+	Provider string            `json:"provider" binding:"required"`
+	Name     string            `json:"name" binding:"required"`
+	Endpoint string            `json:"endpoint,omitempty"`
+	Token    string            `json:"token,omitempty"`
+	Children map[string]string `json:"children" binding:"required,min=1"`
+}
+
+// Entry is one item of a daycare's daily report. ID and ChildID are the
+// provider's own; ID must not change between polls.
+type Entry struct {
+	ID        string     `json:"id"`
+	ChildID   string     `json:"child_id"`
+	Kind      EntryKind  `json:"kind"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+	// FeedingType, Amount and Unit describe feedings, as in the feeding API
+	FeedingType string   `json:"feeding_type,omitempty"`
+	Amount      *float64 `json:"amount,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+	Text        string   `json:"text,omitempty"`
+}
+
+// Batch is what a connector read from its provider. Cursor is where the next
+// poll picks up; empty keeps the previous one.
+type Batch struct {
+	Entries []Entry `json:"entries"`
+	Cursor  string  `json:"cursor,omitempty"`
+}
+
+// SyncResult reports what became of a batch. Entries already imported are
+// skipped; failed ones can come again in a later batch.
+type SyncResult struct {
+	Imported []ImportedEntry `json:"imported"`
+	Skipped  int             `json:"skipped"`
+	Failed   []FailedEntry   `json:"failed,omitempty"`
+}
+
+// ImportedEntry points at the record an entry became
+type ImportedEntry struct {
+	EntryID  string    `json:"entry_id"`
+	Kind     EntryKind `json:"kind"`
+	RecordID string    `json:"record_id"`
+}
+
+type FailedEntry struct {
+	EntryID string `json:"entry_id"`
+	Error   string `json:"error"`
+}
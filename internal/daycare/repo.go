@@ -0,0 +1,173 @@
+package daycare
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, c *Connection) error
+	GetByID(ctx context.Context, id string) (*Connection, error)
+	ListByFamily(ctx context.Context, familyID string) ([]Connection, error)
+	// ListAll returns every family's connections, for the poll job
+	ListAll(ctx context.Context) ([]Connection, error)
+	Delete(ctx context.Context, familyID, id string) (bool, error)
+	UpdateSyncState(ctx context.Context, id, cursor string, syncedAt time.Time, lastError string) error
+	// ClaimEntry marks an entry as being imported, reporting false if it
+	// already was
+	ClaimEntry(ctx context.Context, connectionID, entryID string, at time.Time) (bool, error)
+	SetEntryRecord(ctx context.Context, connectionID, entryID string, kind EntryKind, recordID string) error
+	// ReleaseEntry gives up a claim whose record could not be created
+	ReleaseEntry(ctx context.Context, connectionID, entryID string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the connection and import tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "daycare_connections", "daycare_imports")
+}
+
+const connectionColumns = `id, family_id, provider, name, endpoint, token, children, sync_cursor,
+	created_by, created_at, last_synced_at, last_error`
+
+func (r *repository) Create(ctx context.Context, c *Connection) error {
+	children, err := json.Marshal(c.Children)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO daycare_connections (id, family_id, provider, name, endpoint, token, children, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		c.ID, c.FamilyID, c.Provider, c.Name, c.Endpoint, c.token, children, c.CreatedBy, c.CreatedAt,
+	)
+	return err
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Connection, error) {
+	query := `SELECT ` + connectionColumns + ` FROM daycare_connections WHERE id = $1`
+
+	c, err := scanConnection(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *repository) ListByFamily(ctx context.Context, familyID string) ([]Connection, error) {
+	query := `SELECT ` + connectionColumns + ` FROM daycare_connections WHERE family_id = $1 ORDER BY created_at`
+	return r.list(ctx, query, familyID)
+}
+
+func (r *repository) ListAll(ctx context.Context) ([]Connection, error) {
+	query := `SELECT ` + connectionColumns + ` FROM daycare_connections ORDER BY created_at`
+	return r.list(ctx, query)
+}
+
+func (r *repository) list(ctx context.Context, query string, args ...any) ([]Connection, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	connections := []Connection{}
+	for rows.Next() {
+		c, err := scanConnection(rows)
+		if err != nil {
+			return nil, err
+		}
+		connections = append(connections, *c)
+	}
+	return connections, rows.Err()
+}
+
+func (r *repository) Delete(ctx context.Context, familyID, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM daycare_connections WHERE id = $1 AND family_id = $2`, id, familyID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *repository) UpdateSyncState(ctx context.Context, id, cursor string, syncedAt time.Time, lastError string) error {
+	query := `
+		UPDATE daycare_connections
+		SET sync_cursor = $2, last_synced_at = $3, last_error = $4
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, cursor, syncedAt, lastError)
+	return err
+}
+
+func (r *repository) ClaimEntry(ctx context.Context, connectionID, entryID string, at time.Time) (bool, error) {
+	query := `
+		INSERT INTO daycare_imports (connection_id, entry_id, imported_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (connection_id, entry_id) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, connectionID, entryID, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *repository) SetEntryRecord(ctx context.Context, connectionID, entryID string, kind EntryKind, recordID string) error {
+	query := `UPDATE daycare_imports SET kind = $3, record_id = $4 WHERE connection_id = $1 AND entry_id = $2`
+
+	_, err := r.db.ExecContext(ctx, query, connectionID, entryID, kind, recordID)
+	return err
+}
+
+func (r *repository) ReleaseEntry(ctx context.Context, connectionID, entryID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM daycare_imports WHERE connection_id = $1 AND entry_id = $2`, connectionID, entryID)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanConnection(row scanner) (*Connection, error) {
+	var c Connection
+	var children []byte
+	var lastSyncedAt sql.NullTime
+
+	err := row.Scan(
+		&c.ID, &c.FamilyID, &c.Provider, &c.Name, &c.Endpoint, &c.token, &children, &c.cursor,
+		&c.CreatedBy, &c.CreatedAt, &lastSyncedAt, &c.LastError,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(children, &c.Children); err != nil {
+		return nil, err
+	}
+	if lastSyncedAt.Valid {
+		c.LastSyncedAt = &lastSyncedAt.Time
+	}
+	c.HasToken = len(c.token) > 0
+
+	return &c, nil
+}
@@ -0,0 +1,98 @@
+package daycare
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var connectionRowColumns = []string{"id", "family_id", "provider", "name", "endpoint", "token", "children", "sync_cursor",
+	"created_by", "created_at", "last_synced_at", "last_error"}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	c := &Connection{ID: "conn-1", FamilyID: "family-1", Provider: ProviderDailyReport, Name: "Sunny Days",
+		Children: map[string]string{"kid-42": "child-1"}, CreatedBy: "user-1", CreatedAt: now, token: []byte("sealed")}
+
+	mock.ExpectExec("INSERT INTO daycare_connections").
+		WithArgs("conn-1", "family-1", ProviderDailyReport, "Sunny Days", "", []byte("sealed"), []byte(`{"kid-42":"child-1"}`), "user-1", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), c); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, family_id, provider").
+		WithArgs("conn-1").
+		WillReturnRows(sqlmock.NewRows(connectionRowColumns).
+			AddRow("conn-1", "family-1", ProviderDailyReport, "Sunny Days", "https://daycare.example", []byte("sealed"),
+				[]byte(`{"kid-42":"child-1"}`), "page-2", "user-1", now, now, ""))
+
+	c, err := repo.GetByID(context.Background(), "conn-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if c.Children["kid-42"] != "child-1" || c.cursor != "page-2" || !c.HasToken || c.LastSyncedAt == nil {
+		t.Errorf("GetByID() = %+v", c)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT id, family_id, provider").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	c, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || c != nil {
+		t.Errorf("GetByID() = %v, %v; want nil, nil", c, err)
+	}
+}
+
+func TestRepository_ClaimEntry(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO daycare_imports").
+		WithArgs("conn-1", "e1", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err := repo.ClaimEntry(context.Background(), "conn-1", "e1", now)
+	if err != nil {
+		t.Fatalf("ClaimEntry() error = %v", err)
+	}
+	if claimed {
+		t.Error("ClaimEntry() should report false for an entry already imported")
+	}
+}
@@ -0,0 +1,468 @@
+package daycare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sealed"
+	"github.com/ninenine/babytrack/internal/sleep"
+)
+
+var (
+	ErrConnectionNotFound  = errors.New("daycare connection not found")
+	ErrNotAdmin            = errors.New("only admins can manage daycare connections")
+	ErrNotMember           = errors.New("user is not a member of this family")
+	ErrChildNotInFamily    = errors.New("child does not belong to this family")
+	ErrUnknownProvider     = errors.New("unknown daycare provider")
+	ErrInvalidEndpoint     = errors.New("endpoint must be an https URL")
+	ErrPollUnsupported     = errors.New("this provider does not support polling")
+	ErrPushUnsupported     = errors.New("this provider does not push reports")
+	ErrInvalidReport       = errors.New("invalid daycare report")
+	ErrTooManyEntries      = errors.New("too many entries in one report")
+	ErrTokenUnavailable    = errors.New("storing provider tokens needs database.encryption_key")
+	ErrProviderUnavailable = errors.New("the daycare provider could not be reached")
+)
+
+type Service interface {
+	Connect(ctx context.Context, userID, familyID string, req *CreateConnectionRequest) (*Connection, error)
+	List(ctx context.Context, userID, familyID string) ([]Connection, error)
+	Disconnect(ctx context.Context, userID, familyID, connectionID string) error
+	Providers() []ProviderInfo
+	// Sync polls one connection now instead of waiting for the job
+	Sync(ctx context.Context, userID, familyID, connectionID string) (*SyncResult, error)
+	// SyncAll polls every connection whose provider supports it and returns
+	// how many entries were imported
+	SyncAll(ctx context.Context) (int, error)
+	// Receive imports a report pushed for one of the family's connections
+	Receive(ctx context.Context, familyID, connectionID string, body []byte) (*SyncResult, error)
+}
+
+type service struct {
+	repo           Repository
+	registry       *Registry
+	familyService  family.Service
+	feedingService feeding.Service
+	sleepService   sleep.Service
+	notesService   notes.Service
+	box            *sealed.Box
+	now            func() time.Time
+}
+
+type Option func(*service)
+
+// WithBox seals provider tokens. Without it, connections can't store one.
+func WithBox(box *sealed.Box) Option {
+	return func(s *service) {
+		s.box = box
+	}
+}
+
+func NewService(
+	repo Repository,
+	registry *Registry,
+	familyService family.Service,
+	feedingService feeding.Service,
+	sleepService sleep.Service,
+	notesService notes.Service,
+	opts ...Option,
+) Service {
+	s := &service{
+		repo:           repo,
+		registry:       registry,
+		familyService:  familyService,
+		feedingService: feedingService,
+		sleepService:   sleepService,
+		notesService:   notesService,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether daycare connections can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Connect(ctx context.Context, userID, familyID string, req *CreateConnectionRequest) (*Connection, error) {
+	l := limits.Get()
+	if err := limits.Length("name", req.Name, l.NameLength); err != nil {
+		return nil, err
+	}
+	if err := limits.Items("children", len(req.Children), l.ChildrenPerRequest); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	connector, ok := s.registry.Get(req.Provider)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, req.Provider)
+	}
+	conn := &Connection{
+		ID:        ids.New(),
+		FamilyID:  familyID,
+		Provider:  req.Provider,
+		Name:      strings.TrimSpace(req.Name),
+		Children:  req.Children,
+		CreatedBy: userID,
+		CreatedAt: s.now(),
+	}
+
+	if _, ok := connector.(Poller); ok {
+		u, err := url.Parse(req.Endpoint)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, ErrInvalidEndpoint
+		}
+		conn.Endpoint = req.Endpoint
+	}
+	if req.Token != "" {
+		if s.box == nil {
+			return nil, ErrTokenUnavailable
+		}
+		conn.token = s.box.Seal([]byte(req.Token), tokenLabel(conn))
+		conn.HasToken = true
+	}
+
+	for _, childID := range req.Children {
+		child, err := s.familyService.GetChild(ctx, childID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get child: %w", err)
+		}
+		if child == nil || child.FamilyID != familyID {
+			return nil, ErrChildNotInFamily
+		}
+	}
+
+	if err := s.repo.Create(ctx, conn); err != nil {
+		return nil, fmt.Errorf("failed to create daycare connection: %w", err)
+	}
+	return conn, nil
+}
+
+func (s *service) List(ctx context.Context, userID, familyID string) ([]Connection, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	connections, err := s.repo.ListByFamily(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daycare connections: %w", err)
+	}
+	return connections, nil
+}
+
+func (s *service) Disconnect(ctx context.Context, userID, familyID, connectionID string) error {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, familyID, connectionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete daycare connection: %w", err)
+	}
+	if !deleted {
+		return ErrConnectionNotFound
+	}
+	return nil
+}
+
+func (s *service) Providers() []ProviderInfo {
+	return s.registry.Providers()
+}
+
+func (s *service) Sync(ctx context.Context, userID, familyID, connectionID string) (*SyncResult, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	conn, err := s.connection(ctx, familyID, connectionID)
+	if err != nil {
+		return nil, err
+	}
+	poller, ok := s.connector(conn).(Poller)
+	if !ok {
+		return nil, ErrPollUnsupported
+	}
+	return s.poll(ctx, poller, conn)
+}
+
+func (s *service) SyncAll(ctx context.Context) (int, error) {
+	connections, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list daycare connections: %w", err)
+	}
+
+	imported := 0
+	for i := range connections {
+		if ctx.Err() != nil {
+			return imported, ctx.Err()
+		}
+		conn := &connections[i]
+		poller, ok := s.connector(conn).(Poller)
+		if !ok {
+			continue
+		}
+		result, err := s.poll(ctx, poller, conn)
+		if err != nil {
+			log.Printf("[daycare] Failed to sync connection %s: %v", conn.ID, err)
+			continue
+		}
+		imported += len(result.Imported)
+	}
+	return imported, nil
+}
+
+func (s *service) Receive(ctx context.Context, familyID, connectionID string, body []byte) (*SyncResult, error) {
+	conn, err := s.connection(ctx, familyID, connectionID)
+	if err != nil {
+		return nil, err
+	}
+	receiver, ok := s.connector(conn).(Receiver)
+	if !ok {
+		return nil, ErrPushUnsupported
+	}
+
+	batch, err := receiver.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidReport, err)
+	}
+	if batch == nil {
+		batch = &Batch{}
+	}
+	result, err := s.ingest(ctx, conn, batch)
+	if err != nil {
+		return nil, err
+	}
+	s.recordSync(ctx, conn, conn.cursor, result)
+	return result, nil
+}
+
+// poll fetches what the provider has after the connection's cursor and
+// moves the cursor on. Entries that fail are reported and not retried unless
+// the provider sends them again.
+func (s *service) poll(ctx context.Context, poller Poller, conn *Connection) (*SyncResult, error) {
+	var token string
+	if len(conn.token) > 0 {
+		if s.box == nil {
+			return nil, ErrTokenUnavailable
+		}
+		plaintext, err := s.box.Open(conn.token, tokenLabel(conn))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open provider token: %w", err)
+		}
+		token = string(plaintext)
+	}
+
+	batch, err := poller.Fetch(ctx, conn, token, conn.cursor)
+	if err != nil {
+		if uerr := s.repo.UpdateSyncState(ctx, conn.ID, conn.cursor, s.now(), err.Error()); uerr != nil {
+			log.Printf("[daycare] Failed to record sync of %s: %v", conn.ID, uerr)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+
+	if batch == nil {
+		batch = &Batch{}
+	}
+	result, err := s.ingest(ctx, conn, batch)
+	if err != nil {
+		return nil, err
+	}
+	cursor := conn.cursor
+	if batch.Cursor != "" {
+		cursor = batch.Cursor
+	}
+	s.recordSync(ctx, conn, cursor, result)
+	return result, nil
+}
+
+// ingest files each entry as a record on the mapped child, authored by the
+// admin who made the connection. Sleep records carry the connection as
+// their source; feedings and notes name it in their text.
+func (s *service) ingest(ctx context.Context, conn *Connection, batch *Batch) (*SyncResult, error) {
+	// One poll or push may carry as many entries as any other bulk request
+	if limit := limits.Get().BulkItems; len(batch.Entries) > limit {
+		return nil, fmt.Errorf("%w: at most %d", ErrTooManyEntries, limit)
+	}
+
+	result := &SyncResult{Imported: []ImportedEntry{}}
+	for i := range batch.Entries {
+		e := &batch.Entries[i]
+		childID, ok := conn.Children[e.ChildID]
+		if !ok {
+			result.Failed = append(result.Failed, FailedEntry{EntryID: e.ID, Error: "child " + e.ChildID + " is not mapped"})
+			continue
+		}
+		if err := validateEntry(e); err != nil {
+			result.Failed = append(result.Failed, FailedEntry{EntryID: e.ID, Error: err.Error()})
+			continue
+		}
+
+		claimed, err := s.repo.ClaimEntry(ctx, conn.ID, e.ID, s.now())
+		if err != nil {
+			return result, fmt.Errorf("failed to claim entry: %w", err)
+		}
+		if !claimed {
+			result.Skipped++
+			continue
+		}
+
+		recordID, err := s.file(ctx, conn, childID, e)
+		if err != nil {
+			if rerr := s.repo.ReleaseEntry(ctx, conn.ID, e.ID); rerr != nil {
+				return result, fmt.Errorf("failed to release entry: %w", rerr)
+			}
+			result.Failed = append(result.Failed, FailedEntry{EntryID: e.ID, Error: err.Error()})
+			continue
+		}
+		if err := s.repo.SetEntryRecord(ctx, conn.ID, e.ID, e.Kind, recordID); err != nil {
+			return result, fmt.Errorf("failed to record import: %w", err)
+		}
+		result.Imported = append(result.Imported, ImportedEntry{EntryID: e.ID, Kind: e.Kind, RecordID: recordID})
+	}
+	return result, nil
+}
+
+func (s *service) file(ctx context.Context, conn *Connection, childID string, e *Entry) (string, error) {
+	switch e.Kind {
+	case EntryFeeding:
+		notesText := "From " + conn.Name
+		if e.Text != "" {
+			notesText += ": " + e.Text
+		}
+		f, err := s.feedingService.Create(ctx, &feeding.CreateFeedingRequest{
+			ChildID:   childID,
+			Type:      feeding.FeedingType(e.FeedingType),
+			StartTime: e.StartTime,
+			EndTime:   e.EndTime,
+			Amount:    e.Amount,
+			Unit:      e.Unit,
+			Notes:     notesText,
+		})
+		if err != nil {
+			return "", err
+		}
+		return f.ID, nil
+
+	case EntrySleep:
+		sl, err := s.sleepService.Create(ctx, &sleep.CreateSleepRequest{
+			ChildID:   childID,
+			Type:      sleep.SleepTypeNap,
+			StartTime: e.StartTime,
+			EndTime:   e.EndTime,
+			Notes:     e.Text,
+			Source:    "daycare:" + conn.ID,
+		})
+		if err != nil {
+			return "", err
+		}
+		return sl.ID, nil
+
+	default:
+		occurredAt := e.StartTime
+		n, err := s.notesService.Create(ctx, conn.CreatedBy, &notes.CreateNoteRequest{
+			ChildID:    childID,
+			Title:      conn.Name,
+			Content:    limits.Truncate(e.Text, limits.Get().TextLength),
+			Tags:       []string{"daycare", conn.Provider},
+			OccurredAt: &occurredAt,
+		})
+		if err != nil {
+			return "", err
+		}
+		return n.ID, nil
+	}
+}
+
+func validateEntry(e *Entry) error {
+	if e.ID == "" {
+		return errors.New("entry has no id")
+	}
+	if e.StartTime.IsZero() {
+		return errors.New("start_time is required")
+	}
+	switch e.Kind {
+	case EntryFeeding:
+		switch feeding.FeedingType(e.FeedingType) {
+		case feeding.FeedingTypeBreast, feeding.FeedingTypeBottle, feeding.FeedingTypeFormula, feeding.FeedingTypeSolid:
+		default:
+			return fmt.Errorf("unknown feeding_type %q", e.FeedingType)
+		}
+	case EntrySleep:
+		if e.EndTime == nil {
+			return errors.New("sleep needs an end_time")
+		}
+	case EntryNote:
+		if strings.TrimSpace(e.Text) == "" {
+			return errors.New("note has no text")
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", e.Kind)
+	}
+	return nil
+}
+
+// recordSync stores where the connection got to. It is best effort: the
+// imports themselves are already recorded.
+func (s *service) recordSync(ctx context.Context, conn *Connection, cursor string, result *SyncResult) {
+	lastError := ""
+	if n := len(result.Failed); n > 0 {
+		lastError = fmt.Sprintf("%d entries could not be imported; first: %s", n, result.Failed[0].Error)
+	}
+	if err := s.repo.UpdateSyncState(ctx, conn.ID, cursor, s.now(), lastError); err != nil {
+		log.Printf("[daycare] Failed to record sync of %s: %v", conn.ID, err)
+	}
+}
+
+func (s *service) connection(ctx context.Context, familyID, connectionID string) (*Connection, error) {
+	conn, err := s.repo.GetByID(ctx, connectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daycare connection: %w", err)
+	}
+	if conn == nil || conn.FamilyID != familyID {
+		return nil, ErrConnectionNotFound
+	}
+	return conn, nil
+}
+
+// connector returns the connection's connector, or nil when the provider is
+// no longer registered
+func (s *service) connector(conn *Connection) Connector {
+	c, _ := s.registry.Get(conn.Provider)
+	return c
+}
+
+func (s *service) requireAdmin(ctx context.Context, familyID, userID string) error {
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	if role != "admin" {
+		return ErrNotAdmin
+	}
+	return nil
+}
+
+// tokenLabel binds a sealed token to its connection
+func tokenLabel(conn *Connection) string {
+	return conn.ID + "/token"
+}
@@ -0,0 +1,435 @@
+package daycare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sealed"
+	"github.com/ninenine/babytrack/internal/sleep"
+)
+
+type mockRepository struct {
+	connections map[string]*Connection
+	imports     map[string]string
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{connections: make(map[string]*Connection), imports: make(map[string]string)}
+}
+
+func (m *mockRepository) Create(ctx context.Context, c *Connection) error {
+	stored := *c
+	m.connections[c.ID] = &stored
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Connection, error) {
+	if c, ok := m.connections[id]; ok {
+		found := *c
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListByFamily(ctx context.Context, familyID string) ([]Connection, error) {
+	var result []Connection
+	for _, c := range m.connections {
+		if c.FamilyID == familyID {
+			result = append(result, *c)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) ListAll(ctx context.Context) ([]Connection, error) {
+	var result []Connection
+	for _, c := range m.connections {
+		result = append(result, *c)
+	}
+	return result, nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, familyID, id string) (bool, error) {
+	c, ok := m.connections[id]
+	if !ok || c.FamilyID != familyID {
+		return false, nil
+	}
+	delete(m.connections, id)
+	return true, nil
+}
+
+func (m *mockRepository) UpdateSyncState(ctx context.Context, id, cursor string, syncedAt time.Time, lastError string) error {
+	c := m.connections[id]
+	c.cursor, c.LastSyncedAt, c.LastError = cursor, &syncedAt, lastError
+	return nil
+}
+
+func (m *mockRepository) ClaimEntry(ctx context.Context, connectionID, entryID string, at time.Time) (bool, error) {
+	if _, ok := m.imports[connectionID+"/"+entryID]; ok {
+		return false, nil
+	}
+	m.imports[connectionID+"/"+entryID] = ""
+	return true, nil
+}
+
+func (m *mockRepository) SetEntryRecord(ctx context.Context, connectionID, entryID string, kind EntryKind, recordID string) error {
+	m.imports[connectionID+"/"+entryID] = recordID
+	return nil
+}
+
+func (m *mockRepository) ReleaseEntry(ctx context.Context, connectionID, entryID string) error {
+	delete(m.imports, connectionID+"/"+entryID)
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	switch {
+	case familyID == "family-1" && userID == "admin-1":
+		return "admin", nil
+	case familyID == "family-1" && userID == "member-1":
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	switch childID {
+	case "child-1":
+		return &family.Child{ID: childID, FamilyID: "family-1"}, nil
+	case "child-2":
+		return &family.Child{ID: childID, FamilyID: "family-2"}, nil
+	}
+	return nil, nil
+}
+
+type mockFeedingService struct {
+	feeding.Service
+	created []*feeding.CreateFeedingRequest
+}
+
+func (m *mockFeedingService) Create(ctx context.Context, req *feeding.CreateFeedingRequest) (*feeding.Feeding, error) {
+	m.created = append(m.created, req)
+	return &feeding.Feeding{ID: fmt.Sprintf("feeding-%d", len(m.created))}, nil
+}
+
+type mockSleepService struct {
+	sleep.Service
+	created []*sleep.CreateSleepRequest
+	err     error
+}
+
+func (m *mockSleepService) Create(ctx context.Context, req *sleep.CreateSleepRequest) (*sleep.Sleep, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.created = append(m.created, req)
+	return &sleep.Sleep{ID: fmt.Sprintf("sleep-%d", len(m.created))}, nil
+}
+
+type mockNotesService struct {
+	notes.Service
+	authors []string
+	created []*notes.CreateNoteRequest
+}
+
+func (m *mockNotesService) Create(ctx context.Context, userID string, req *notes.CreateNoteRequest) (*notes.Note, error) {
+	m.authors = append(m.authors, userID)
+	m.created = append(m.created, req)
+	return &notes.Note{ID: fmt.Sprintf("note-%d", len(m.created))}, nil
+}
+
+// fakeConnector polls and receives a fixed batch, recording what it was
+// asked for
+type fakeConnector struct {
+	provider string
+	batch    *Batch
+	err      error
+
+	token, cursor string
+}
+
+func (f *fakeConnector) Provider() string { return f.provider }
+
+func (f *fakeConnector) Fetch(ctx context.Context, conn *Connection, token, cursor string) (*Batch, error) {
+	f.token, f.cursor = token, cursor
+	return f.batch, f.err
+}
+
+func (f *fakeConnector) Parse(body []byte) (*Batch, error) {
+	if string(body) == "garbage" {
+		return nil, errors.New("not json")
+	}
+	return f.batch, nil
+}
+
+// pushOnly is a connector that can only receive
+type pushOnly struct{}
+
+func (p *pushOnly) Provider() string { return "push_only" }
+
+func (p *pushOnly) Parse(body []byte) (*Batch, error) { return &Batch{}, nil }
+
+type testDeps struct {
+	svc       Service
+	repo      *mockRepository
+	connector *fakeConnector
+	feedings  *mockFeedingService
+	sleeps    *mockSleepService
+	notes     *mockNotesService
+}
+
+func newTestService(t *testing.T, opts ...Option) *testDeps {
+	t.Helper()
+	d := &testDeps{
+		repo:      newMockRepository(),
+		connector: &fakeConnector{provider: "fake", batch: &Batch{}},
+		feedings:  &mockFeedingService{},
+		sleeps:    &mockSleepService{},
+		notes:     &mockNotesService{},
+	}
+	registry := NewRegistry(d.connector, &pushOnly{})
+	d.svc = NewService(d.repo, registry, &mockFamilyService{}, d.feedings, d.sleeps, d.notes, opts...)
+	return d
+}
+
+func testBox(t *testing.T) *sealed.Box {
+	t.Helper()
+	box, err := sealed.NewBox(make([]byte, sealed.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return box
+}
+
+func connectRequest() *CreateConnectionRequest {
+	return &CreateConnectionRequest{
+		Provider: "fake",
+		Name:     " Sunny Days ",
+		Endpoint: "https://daycare.example/reports",
+		Children: map[string]string{"kid-42": "child-1"},
+	}
+}
+
+func TestService_Connect(t *testing.T) {
+	d := newTestService(t, WithBox(testBox(t)))
+	req := connectRequest()
+	req.Token = "provider-secret"
+
+	conn, err := d.svc.Connect(context.Background(), "admin-1", "family-1", req)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if conn.Name != "Sunny Days" || !conn.HasToken || conn.Endpoint != req.Endpoint {
+		t.Errorf("Connect() = %+v", conn)
+	}
+	stored := d.repo.connections[conn.ID]
+	if len(stored.token) == 0 || string(stored.token) == "provider-secret" {
+		t.Error("Connect() should store the token sealed")
+	}
+}
+
+func TestService_Connect_Errors(t *testing.T) {
+	ctx := context.Background()
+	d := newTestService(t)
+
+	tests := []struct {
+		name   string
+		userID string
+		modify func(*CreateConnectionRequest)
+		want   error
+	}{
+		{"not admin", "member-1", func(*CreateConnectionRequest) {}, ErrNotAdmin},
+		{"not member", "stranger", func(*CreateConnectionRequest) {}, ErrNotMember},
+		{"unknown provider", "admin-1", func(r *CreateConnectionRequest) { r.Provider = "nope" }, ErrUnknownProvider},
+		{"plain http", "admin-1", func(r *CreateConnectionRequest) { r.Endpoint = "http://daycare.example" }, ErrInvalidEndpoint},
+		{"no endpoint", "admin-1", func(r *CreateConnectionRequest) { r.Endpoint = "" }, ErrInvalidEndpoint},
+		{"token without key", "admin-1", func(r *CreateConnectionRequest) { r.Token = "secret" }, ErrTokenUnavailable},
+		{"other family's child", "admin-1", func(r *CreateConnectionRequest) { r.Children["kid-7"] = "child-2" }, ErrChildNotInFamily},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := connectRequest()
+			tt.modify(req)
+			if _, err := d.svc.Connect(ctx, tt.userID, "family-1", req); !errors.Is(err, tt.want) {
+				t.Errorf("Connect() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// A connector that only receives needs no endpoint
+	req := connectRequest()
+	req.Provider, req.Endpoint = "push_only", ""
+	if _, err := d.svc.Connect(ctx, "admin-1", "family-1", req); err != nil {
+		t.Errorf("Connect() push-only error = %v", err)
+	}
+}
+
+func TestService_Receive(t *testing.T) {
+	ctx := context.Background()
+	d := newTestService(t)
+	conn, err := d.svc.Connect(ctx, "admin-1", "family-1", connectRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nap := time.Date(2024, 6, 3, 12, 30, 0, 0, time.UTC)
+	napEnd := nap.Add(90 * time.Minute)
+	d.connector.batch = &Batch{Entries: []Entry{
+		{ID: "e1", ChildID: "kid-42", Kind: EntryFeeding, StartTime: nap.Add(-2 * time.Hour), FeedingType: "bottle", Amount: ptr(120), Unit: "ml", Text: "Finished it"},
+		{ID: "e2", ChildID: "kid-42", Kind: EntrySleep, StartTime: nap, EndTime: &napEnd},
+		{ID: "e3", ChildID: "kid-42", Kind: EntryNote, StartTime: nap, Text: "Painted with fingers"},
+		{ID: "e4", ChildID: "kid-99", Kind: EntryNote, StartTime: nap, Text: "Someone else's child"},
+		{ID: "e5", ChildID: "kid-42", Kind: "diaper", StartTime: nap},
+	}}
+
+	result, err := d.svc.Receive(ctx, "family-1", conn.ID, []byte("{}"))
+	if err != nil {
+		t.Fatalf("Receive() error = %v", err)
+	}
+	if len(result.Imported) != 3 || len(result.Failed) != 2 {
+		t.Fatalf("Receive() = %+v, want three imported and two failed", result)
+	}
+
+	if f := d.feedings.created[0]; f.ChildID != "child-1" || f.Type != feeding.FeedingTypeBottle || f.Notes != "From Sunny Days: Finished it" {
+		t.Errorf("feeding = %+v", f)
+	}
+	if sl := d.sleeps.created[0]; sl.Type != sleep.SleepTypeNap || sl.Source != "daycare:"+conn.ID {
+		t.Errorf("sleep = %+v", sl)
+	}
+	if d.notes.authors[0] != "admin-1" || d.notes.created[0].Tags[0] != "daycare" {
+		t.Errorf("note by %s = %+v", d.notes.authors[0], d.notes.created[0])
+	}
+	if d.repo.imports[conn.ID+"/e2"] != "sleep-1" {
+		t.Error("Receive() should record which record an entry became")
+	}
+	if d.repo.connections[conn.ID].LastError == "" {
+		t.Error("Receive() should record that entries failed")
+	}
+
+	// The same report again imports nothing twice
+	result, err = d.svc.Receive(ctx, "family-1", conn.ID, []byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Imported) != 0 || result.Skipped != 3 {
+		t.Errorf("Receive() again = %+v, want all skipped", result)
+	}
+}
+
+func TestService_Receive_Errors(t *testing.T) {
+	ctx := context.Background()
+	d := newTestService(t)
+	conn, _ := d.svc.Connect(ctx, "admin-1", "family-1", connectRequest())
+
+	if _, err := d.svc.Receive(ctx, "family-2", conn.ID, []byte("{}")); !errors.Is(err, ErrConnectionNotFound) {
+		t.Errorf("other family error = %v, want ErrConnectionNotFound", err)
+	}
+	if _, err := d.svc.Receive(ctx, "family-1", conn.ID, []byte("garbage")); !errors.Is(err, ErrInvalidReport) {
+		t.Errorf("garbage error = %v, want ErrInvalidReport", err)
+	}
+
+	d.connector.batch = &Batch{Entries: make([]Entry, limits.Get().BulkItems+1)}
+	if _, err := d.svc.Receive(ctx, "family-1", conn.ID, []byte("{}")); !errors.Is(err, ErrTooManyEntries) {
+		t.Errorf("oversized error = %v, want ErrTooManyEntries", err)
+	}
+
+	// A record that can't be stored gives its entry up to be sent again
+	end := time.Now()
+	d.connector.batch = &Batch{Entries: []Entry{{ID: "e1", ChildID: "kid-42", Kind: EntrySleep, StartTime: end.Add(-time.Hour), EndTime: &end}}}
+	d.sleeps.err = errors.New("database error")
+	if result, err := d.svc.Receive(ctx, "family-1", conn.ID, []byte("{}")); err != nil || len(result.Failed) != 1 {
+		t.Fatalf("Receive() = %+v, %v", result, err)
+	}
+	if _, claimed := d.repo.imports[conn.ID+"/e1"]; claimed {
+		t.Error("Receive() should release an entry whose record failed")
+	}
+}
+
+func TestService_Sync(t *testing.T) {
+	ctx := context.Background()
+	d := newTestService(t, WithBox(testBox(t)))
+	req := connectRequest()
+	req.Token = "provider-secret"
+	conn, _ := d.svc.Connect(ctx, "admin-1", "family-1", req)
+
+	d.connector.batch = &Batch{Cursor: "page-2", Entries: []Entry{
+		{ID: "e1", ChildID: "kid-42", Kind: EntryNote, StartTime: time.Now().Add(-time.Hour), Text: "Good day"},
+	}}
+	result, err := d.svc.Sync(ctx, "admin-1", "family-1", conn.ID)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(result.Imported) != 1 || d.connector.token != "provider-secret" || d.connector.cursor != "" {
+		t.Errorf("Sync() = %+v with token %q cursor %q", result, d.connector.token, d.connector.cursor)
+	}
+
+	// The next poll resumes from the cursor, and an empty one keeps it
+	d.connector.batch = &Batch{}
+	if _, err := d.svc.SyncAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d.connector.cursor != "page-2" || d.repo.connections[conn.ID].cursor != "page-2" {
+		t.Errorf("SyncAll() polled from %q and kept %q, want page-2", d.connector.cursor, d.repo.connections[conn.ID].cursor)
+	}
+
+	d.connector.err = errors.New("503 Service Unavailable")
+	if _, err := d.svc.Sync(ctx, "admin-1", "family-1", conn.ID); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("unreachable error = %v, want ErrProviderUnavailable", err)
+	}
+	if d.repo.connections[conn.ID].LastError == "" {
+		t.Error("Sync() should record why the provider could not be reached")
+	}
+	if _, err := d.svc.Sync(ctx, "member-1", "family-1", conn.ID); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("member error = %v, want ErrNotAdmin", err)
+	}
+}
+
+func TestService_Sync_PushOnly(t *testing.T) {
+	ctx := context.Background()
+	d := newTestService(t)
+	req := connectRequest()
+	req.Provider = "push_only"
+	conn, _ := d.svc.Connect(ctx, "admin-1", "family-1", req)
+
+	if _, err := d.svc.Sync(ctx, "admin-1", "family-1", conn.ID); !errors.Is(err, ErrPollUnsupported) {
+		t.Errorf("Sync() error = %v, want ErrPollUnsupported", err)
+	}
+	if n, err := d.svc.SyncAll(ctx); err != nil || n != 0 {
+		t.Errorf("SyncAll() = %d, %v; want push-only connections left alone", n, err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(&pushOnly{}, NewDailyReport(nil))
+
+	providers := registry.Providers()
+	if len(providers) != 2 || providers[0].Provider != ProviderDailyReport || !providers[0].Polls || !providers[0].Receives {
+		t.Fatalf("Providers() = %+v", providers)
+	}
+	if providers[1].Polls || !providers[1].Receives {
+		t.Errorf("push-only provider = %+v", providers[1])
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() should panic on a second connector for a provider")
+		}
+	}()
+	registry.Register(&pushOnly{})
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
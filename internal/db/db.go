@@ -11,7 +11,7 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq"
 )
 
 //go:embed migrations/*.sql
@@ -21,12 +21,40 @@ type DB struct {
 	*sql.DB
 }
 
-func New(dsn string) (*DB, error) {
-	db, err := sql.Open("postgres", dsn)
+type Option func(*options)
+
+type options struct {
+	slowQuery SlowQueryConfig
+}
+
+// WithSlowQueryLog logs queries slower than cfg.Threshold, with a sample of
+// their EXPLAIN plans. A zero threshold leaves it off.
+func WithSlowQueryLog(cfg SlowQueryConfig) Option {
+	return func(o *options) {
+		o.slowQuery = cfg
+	}
+}
+
+func New(dsn string, opts ...Option) (*DB, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	connector, err := pq.NewConnector(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	var db *sql.DB
+	if o.slowQuery.Threshold > 0 {
+		slow := newSlowLog(o.slowQuery)
+		db = sql.OpenDB(slow.connector(connector))
+		slow.db = db
+	} else {
+		db = sql.OpenDB(connector)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := db.PingContext(ctx); err != nil {
@@ -0,0 +1,13 @@
+CREATE TABLE templates (
+    id VARCHAR(64) PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    created_by VARCHAR(64) NOT NULL REFERENCES users(id),
+    kind VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_templates_family_id ON templates(family_id);
+CREATE INDEX idx_templates_family_kind ON templates(family_id, kind);
@@ -0,0 +1,11 @@
+CREATE TABLE favorites (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, entity_type, entity_id)
+);
+
+CREATE INDEX idx_favorites_user_id ON favorites(user_id, created_at DESC);
+CREATE INDEX idx_favorites_entity ON favorites(entity_type, entity_id);
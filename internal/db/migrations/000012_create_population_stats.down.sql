@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS population_stats;
+DROP TABLE IF EXISTS stats_opt_ins;
@@ -0,0 +1,13 @@
+CREATE TABLE stats_opt_ins (
+    family_id VARCHAR(64) PRIMARY KEY REFERENCES families(id) ON DELETE CASCADE,
+    opted_in_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE population_stats (
+    metric VARCHAR(50) NOT NULL,
+    age_weeks INTEGER NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    sample_size INTEGER NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (metric, age_weeks)
+);
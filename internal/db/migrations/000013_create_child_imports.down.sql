@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS imported_records;
+DROP TABLE IF EXISTS child_imports;
@@ -0,0 +1,22 @@
+CREATE TABLE child_imports (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    source_child_id VARCHAR(64) NOT NULL,
+    source_family_id VARCHAR(64) NOT NULL,
+    exported_at TIMESTAMPTZ NOT NULL,
+    imported_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    imported_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_child_imports_child_id ON child_imports(child_id);
+
+CREATE TABLE imported_records (
+    import_id VARCHAR(64) NOT NULL REFERENCES child_imports(id) ON DELETE CASCADE,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL,
+    source_id VARCHAR(64) NOT NULL,
+    source_created_at TIMESTAMPTZ,
+    PRIMARY KEY (entity_type, entity_id)
+);
+
+CREATE INDEX idx_imported_records_import_id ON imported_records(import_id);
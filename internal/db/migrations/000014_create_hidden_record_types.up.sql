@@ -0,0 +1,7 @@
+CREATE TABLE hidden_record_types (
+    family_id VARCHAR(64) NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    record_type VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (family_id, user_id, record_type)
+);
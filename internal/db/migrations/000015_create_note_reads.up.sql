@@ -0,0 +1,6 @@
+CREATE TABLE note_reads (
+    note_id VARCHAR(64) NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (note_id, user_id)
+);
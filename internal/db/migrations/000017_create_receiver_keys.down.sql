@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS request_nonces;
+DROP TABLE IF EXISTS receiver_keys;
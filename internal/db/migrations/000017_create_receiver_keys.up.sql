@@ -0,0 +1,21 @@
+CREATE TABLE receiver_keys (
+    id VARCHAR(64) PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    created_by VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_receiver_keys_family_id ON receiver_keys(family_id);
+
+CREATE TABLE request_nonces (
+    scope VARCHAR(128) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scope, nonce)
+);
+
+CREATE INDEX idx_request_nonces_expires_at ON request_nonces(expires_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS devices;
+ALTER TABLE sleep_records DROP COLUMN IF EXISTS source;
+DROP TABLE IF EXISTS growth_measurements;
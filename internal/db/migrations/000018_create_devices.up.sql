@@ -0,0 +1,30 @@
+CREATE TABLE growth_measurements (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    measured_at TIMESTAMPTZ NOT NULL,
+    weight_kg DOUBLE PRECISION CHECK (weight_kg > 0),
+    length_cm DOUBLE PRECISION CHECK (length_cm > 0),
+    head_circumference_cm DOUBLE PRECISION CHECK (head_circumference_cm > 0),
+    source VARCHAR(100) NOT NULL DEFAULT 'manual',
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    synced_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_growth_child_measured ON growth_measurements(child_id, measured_at DESC);
+
+ALTER TABLE sleep_records ADD COLUMN source VARCHAR(100) NOT NULL DEFAULT 'manual';
+
+CREATE TABLE devices (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    api_key_hash VARCHAR(64) NOT NULL,
+    created_by VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_devices_child_id ON devices(child_id);
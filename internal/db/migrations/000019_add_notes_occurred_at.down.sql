@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_notes_occurred;
+ALTER TABLE notes DROP COLUMN IF EXISTS occurred_at;
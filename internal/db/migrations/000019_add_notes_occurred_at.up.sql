@@ -0,0 +1,5 @@
+ALTER TABLE notes ADD COLUMN occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE notes SET occurred_at = created_at;
+
+CREATE INDEX idx_notes_occurred ON notes(child_id, occurred_at DESC);
@@ -0,0 +1,26 @@
+CREATE TABLE record_versions (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    data JSONB NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_record_versions_child ON record_versions(child_id, entity_type, recorded_at);
+CREATE INDEX idx_record_versions_entity ON record_versions(entity_id, recorded_at DESC);
+
+-- History starts here: existing records are seeded with their current state
+-- as of when they were created.
+INSERT INTO record_versions (entity_type, entity_id, child_id, action, data, recorded_at)
+SELECT 'feeding', f.id, f.child_id, 'create', to_jsonb(f), f.created_at FROM feedings f;
+
+INSERT INTO record_versions (entity_type, entity_id, child_id, action, data, recorded_at)
+SELECT 'sleep', s.id, s.child_id, 'create', to_jsonb(s), s.created_at FROM sleep_records s;
+
+INSERT INTO record_versions (entity_type, entity_id, child_id, action, data, recorded_at)
+SELECT 'vaccination', v.id, v.child_id, 'create',
+       to_jsonb(v) || jsonb_build_object('scheduled_at', v.scheduled_at::timestamptz),
+       v.created_at
+FROM vaccinations v;
@@ -0,0 +1,2 @@
+ALTER TABLE sleep_records DROP COLUMN IF EXISTS ended_by;
+ALTER TABLE sleep_records DROP COLUMN IF EXISTS started_by;
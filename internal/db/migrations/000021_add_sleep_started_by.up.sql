@@ -0,0 +1,2 @@
+ALTER TABLE sleep_records ADD COLUMN started_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE sleep_records ADD COLUMN ended_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+CREATE TABLE notification_preferences (
+    user_id VARCHAR(64) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    quiet_start VARCHAR(5),
+    quiet_end VARCHAR(5),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    channels JSONB NOT NULL DEFAULT '{}',
+    muted_children TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+CREATE TABLE family_inbound_addresses (
+    family_id VARCHAR(64) PRIMARY KEY REFERENCES families(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
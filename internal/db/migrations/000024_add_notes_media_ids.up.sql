@@ -0,0 +1 @@
+ALTER TABLE notes ADD COLUMN media_ids TEXT[] NOT NULL DEFAULT '{}';
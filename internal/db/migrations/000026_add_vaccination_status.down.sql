@@ -0,0 +1,11 @@
+ALTER TABLE vaccinations ADD COLUMN completed BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE vaccinations SET completed = true WHERE status = 'completed';
+
+DROP INDEX idx_vaccinations_status;
+ALTER TABLE vaccinations
+    DROP COLUMN status,
+    DROP COLUMN status_reason,
+    DROP COLUMN status_changed_at;
+
+CREATE INDEX idx_vaccinations_completed ON vaccinations(child_id, completed);
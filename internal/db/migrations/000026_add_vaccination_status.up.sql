@@ -0,0 +1,12 @@
+ALTER TABLE vaccinations
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'completed', 'skipped', 'refused', 'contraindicated')),
+    ADD COLUMN status_reason TEXT,
+    ADD COLUMN status_changed_at TIMESTAMPTZ;
+
+UPDATE vaccinations SET status = 'completed', status_changed_at = administered_at WHERE completed;
+
+DROP INDEX idx_vaccinations_completed;
+ALTER TABLE vaccinations DROP COLUMN completed;
+
+CREATE INDEX idx_vaccinations_status ON vaccinations(child_id, status);
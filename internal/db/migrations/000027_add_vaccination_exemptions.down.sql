@@ -0,0 +1,4 @@
+ALTER TABLE vaccinations
+    DROP COLUMN reason_code,
+    DROP COLUMN exemption,
+    DROP COLUMN document_ids;
@@ -0,0 +1,4 @@
+ALTER TABLE vaccinations
+    ADD COLUMN reason_code VARCHAR(30),
+    ADD COLUMN exemption BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN document_ids TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,8 @@
+CREATE TABLE feeding_goals (
+    child_id VARCHAR(64) PRIMARY KEY REFERENCES children(id) ON DELETE CASCADE,
+    daily_ml DOUBLE PRECISION CHECK (daily_ml > 0),
+    daily_kcal DOUBLE PRECISION CHECK (daily_kcal > 0),
+    daily_nursing_minutes DOUBLE PRECISION CHECK (daily_nursing_minutes > 0),
+    set_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS api_usage;
+
+ALTER TABLE users DROP COLUMN plan;
@@ -0,0 +1,10 @@
+ALTER TABLE users ADD COLUMN plan VARCHAR(30);
+
+CREATE TABLE api_usage (
+    subject VARCHAR(100) NOT NULL,
+    day DATE NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (subject, day)
+);
+
+CREATE INDEX idx_api_usage_day ON api_usage(day);
@@ -0,0 +1,12 @@
+CREATE TABLE attachments (
+    id VARCHAR(64) PRIMARY KEY,
+    entity_type VARCHAR(30) NOT NULL CHECK (entity_type IN ('note', 'growth', 'vaccination')),
+    entity_id VARCHAR(64) NOT NULL,
+    media_id VARCHAR(64) NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    caption TEXT,
+    created_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (entity_type, entity_id, media_id)
+);
+
+CREATE INDEX idx_attachments_media_id ON attachments(media_id);
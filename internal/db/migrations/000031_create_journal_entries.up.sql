@@ -0,0 +1,13 @@
+CREATE TABLE journal_entries (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    media_id VARCHAR(64) REFERENCES media(id) ON DELETE SET NULL,
+    caption TEXT NOT NULL DEFAULT '',
+    entry_date DATE NOT NULL,
+    milestone BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_journal_entries_child_date ON journal_entries(child_id, entry_date);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS child_access_grants;
+DROP TABLE IF EXISTS professionals;
@@ -0,0 +1,21 @@
+CREATE TABLE professionals (
+    user_id VARCHAR(64) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    profession VARCHAR(50) NOT NULL,
+    organisation VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE child_access_grants (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    professional_id VARCHAR(64) NOT NULL REFERENCES professionals(user_id) ON DELETE CASCADE,
+    granted_by VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    note TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_child_access_grants_child_id ON child_access_grants(child_id);
+CREATE INDEX idx_child_access_grants_professional_id ON child_access_grants(professional_id, expires_at);
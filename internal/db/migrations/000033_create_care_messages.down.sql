@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS care_message_reads;
+DROP TABLE IF EXISTS care_messages;
@@ -0,0 +1,17 @@
+CREATE TABLE care_messages (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    author_id VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    author_role VARCHAR(50) NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_care_messages_child_created ON care_messages(child_id, created_at);
+
+CREATE TABLE care_message_reads (
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_read_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (child_id, user_id)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE care_plans (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    start_date DATE NOT NULL,
+    end_date DATE,
+    tasks JSONB NOT NULL DEFAULT '[]',
+    created_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_care_plans_child_id ON care_plans(child_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS family_reminder_settings;
+DROP TABLE IF EXISTS family_closures;
@@ -0,0 +1,17 @@
+CREATE TABLE family_closures (
+    id VARCHAR(64) PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    closed_on DATE NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    created_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (family_id, closed_on)
+);
+
+CREATE TABLE family_reminder_settings (
+    family_id VARCHAR(64) PRIMARY KEY REFERENCES families(id) ON DELETE CASCADE,
+    shift_to_business_day BOOLEAN NOT NULL DEFAULT FALSE,
+    weekends_closed BOOLEAN NOT NULL DEFAULT TRUE,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
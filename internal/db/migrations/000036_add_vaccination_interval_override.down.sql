@@ -0,0 +1 @@
+ALTER TABLE vaccinations DROP COLUMN IF EXISTS interval_override;
@@ -0,0 +1 @@
+ALTER TABLE vaccinations ADD COLUMN interval_override TEXT;
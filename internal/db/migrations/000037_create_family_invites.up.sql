@@ -0,0 +1,15 @@
+CREATE TABLE family_invites (
+    id VARCHAR(64) PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    invited_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    send_count INTEGER NOT NULL DEFAULT 1,
+    last_sent_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    accepted_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_family_invites_family ON family_invites(family_id, created_at DESC);
+CREATE INDEX idx_family_invites_email ON family_invites(family_id, LOWER(email)) WHERE accepted_at IS NULL;
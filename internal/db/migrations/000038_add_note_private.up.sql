@@ -0,0 +1 @@
+ALTER TABLE notes ADD COLUMN private BOOLEAN NOT NULL DEFAULT FALSE;
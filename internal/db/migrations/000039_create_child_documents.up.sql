@@ -0,0 +1,16 @@
+CREATE TABLE child_documents (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    media_id VARCHAR(64) NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    category VARCHAR(32) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    expires_on DATE,
+    reminded_for DATE,
+    created_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_child_documents_child_id ON child_documents(child_id);
+CREATE INDEX idx_child_documents_expires_on ON child_documents(expires_on) WHERE expires_on IS NOT NULL;
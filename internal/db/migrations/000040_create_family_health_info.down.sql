@@ -0,0 +1 @@
+DROP TABLE IF EXISTS family_health_info;
@@ -0,0 +1,8 @@
+-- insurance and pharmacy hold JSON sealed with the server's encryption key
+CREATE TABLE family_health_info (
+    family_id VARCHAR(64) PRIMARY KEY REFERENCES families(id) ON DELETE CASCADE,
+    insurance BYTEA,
+    pharmacy BYTEA,
+    updated_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
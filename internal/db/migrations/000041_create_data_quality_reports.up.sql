@@ -0,0 +1,5 @@
+CREATE TABLE data_quality_reports (
+    child_id VARCHAR(64) PRIMARY KEY REFERENCES children(id) ON DELETE CASCADE,
+    issues JSONB NOT NULL DEFAULT '[]',
+    generated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
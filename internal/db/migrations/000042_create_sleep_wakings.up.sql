@@ -0,0 +1,11 @@
+CREATE TABLE sleep_wakings (
+    id VARCHAR(64) PRIMARY KEY,
+    sleep_id VARCHAR(64) NOT NULL REFERENCES sleep_records(id) ON DELETE CASCADE,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ,
+    reason VARCHAR(50),
+    created_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sleep_wakings_sleep ON sleep_wakings(sleep_id, start_time);
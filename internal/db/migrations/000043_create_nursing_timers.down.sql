@@ -0,0 +1 @@
+DROP TABLE IF EXISTS nursing_timers;
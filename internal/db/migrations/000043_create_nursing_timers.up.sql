@@ -0,0 +1,14 @@
+CREATE TABLE nursing_timers (
+    feeding_id VARCHAR(64) PRIMARY KEY REFERENCES feedings(id) ON DELETE CASCADE,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    side VARCHAR(10) NOT NULL,
+    side_started_at TIMESTAMPTZ,
+    left_seconds INTEGER NOT NULL DEFAULT 0,
+    right_seconds INTEGER NOT NULL DEFAULT 0,
+    started_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMPTZ
+);
+
+-- One running timer per child
+CREATE UNIQUE INDEX idx_nursing_timers_running ON nursing_timers(child_id) WHERE ended_at IS NULL;
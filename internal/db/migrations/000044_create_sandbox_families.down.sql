@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sandbox_captures;
+DROP TABLE IF EXISTS sandbox_families;
@@ -0,0 +1,19 @@
+-- Families for integration testing: their records are wiped on a schedule
+-- and their notifications are captured instead of delivered
+CREATE TABLE sandbox_families (
+    family_id VARCHAR(64) PRIMARY KEY REFERENCES families(id) ON DELETE CASCADE,
+    created_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reset_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE sandbox_captures (
+    id VARCHAR(64) PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL REFERENCES sandbox_families(family_id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sandbox_captures_family ON sandbox_captures(family_id, created_at DESC);
@@ -0,0 +1,18 @@
+-- Offline edits that lost to a newer server version during sync, kept so
+-- the user can bring them back
+CREATE TABLE sync_conflicts (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id VARCHAR(64) NOT NULL,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL,
+    client_data JSONB NOT NULL,
+    server_data JSONB NOT NULL,
+    client_time TIMESTAMPTZ NOT NULL,
+    server_time TIMESTAMPTZ NOT NULL,
+    resolution VARCHAR(16),
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sync_conflicts_user ON sync_conflicts(user_id, created_at DESC) WHERE resolved_at IS NULL;
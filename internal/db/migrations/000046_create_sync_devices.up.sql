@@ -0,0 +1,11 @@
+-- Sync checkpoint of each client a user syncs from, so one client's state
+-- can be reset without touching the others
+CREATE TABLE sync_devices (
+    user_id VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id VARCHAR(64) NOT NULL,
+    last_token VARCHAR(64),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reset_pending BOOLEAN NOT NULL DEFAULT FALSE,
+    reset_at TIMESTAMPTZ,
+    PRIMARY KEY (user_id, device_id)
+);
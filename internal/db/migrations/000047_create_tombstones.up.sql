@@ -0,0 +1,13 @@
+-- IDs of deleted records, so incremental list fetches can report deletions.
+-- scope_id is what the list is fetched by: the family for children, the
+-- child for vaccinations and medications.
+CREATE TABLE tombstones (
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL,
+    scope_id VARCHAR(64) NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (entity_type, entity_id)
+);
+
+CREATE INDEX idx_tombstones_scope ON tombstones(entity_type, scope_id, deleted_at);
+CREATE INDEX idx_tombstones_deleted_at ON tombstones(deleted_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_record_versions_feed;
+ALTER TABLE record_versions DROP COLUMN IF EXISTS actor_id;
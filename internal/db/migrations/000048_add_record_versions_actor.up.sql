@@ -0,0 +1,5 @@
+-- Who made each write, for the family activity feed. Earlier versions and
+-- writes made by background jobs have none.
+ALTER TABLE record_versions ADD COLUMN actor_id VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_record_versions_feed ON record_versions(child_id, id DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE medication_logs DROP COLUMN IF EXISTS corrected_at;
+ALTER TABLE vaccinations DROP COLUMN IF EXISTS corrected_at;
+DROP TABLE IF EXISTS record_corrections;
@@ -0,0 +1,18 @@
+-- Corrections to clinically relevant records. The record itself holds the
+-- corrected values; the original is kept here along with why it changed.
+CREATE TABLE record_corrections (
+    id VARCHAR(64) PRIMARY KEY,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    original JSONB NOT NULL,
+    corrected JSONB NOT NULL,
+    reason TEXT NOT NULL,
+    corrected_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    corrected_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_record_corrections_entity ON record_corrections(entity_type, entity_id, corrected_at);
+
+ALTER TABLE vaccinations ADD COLUMN corrected_at TIMESTAMPTZ;
+ALTER TABLE medication_logs ADD COLUMN corrected_at TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_appointments_growth_reminders;
+
+ALTER TABLE appointments DROP COLUMN IF EXISTS growth_reminded_at;
+ALTER TABLE appointments DROP COLUMN IF EXISTS well_visit;
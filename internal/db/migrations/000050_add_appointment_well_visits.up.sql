@@ -0,0 +1,7 @@
+-- Which checkup in the well-visit schedule an appointment books, and when the
+-- family was reminded to record the weight and length taken at it
+ALTER TABLE appointments ADD COLUMN well_visit VARCHAR(16);
+ALTER TABLE appointments ADD COLUMN growth_reminded_at TIMESTAMPTZ;
+
+CREATE INDEX idx_appointments_growth_reminders ON appointments(scheduled_at)
+    WHERE type = 'well_visit' AND cancelled = false AND growth_reminded_at IS NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_growth_percentile_reference;
+
+ALTER TABLE growth_measurements DROP COLUMN IF EXISTS percentile_reference;
+ALTER TABLE growth_measurements DROP COLUMN IF EXISTS length_percentile;
+ALTER TABLE growth_measurements DROP COLUMN IF EXISTS weight_percentile;
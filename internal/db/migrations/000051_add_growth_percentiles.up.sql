@@ -0,0 +1,8 @@
+-- Percentiles computed when a measurement is recorded, and which version of
+-- the WHO reference tables they came from, so that they can be recomputed
+-- when the tables are updated
+ALTER TABLE growth_measurements ADD COLUMN weight_percentile DOUBLE PRECISION;
+ALTER TABLE growth_measurements ADD COLUMN length_percentile DOUBLE PRECISION;
+ALTER TABLE growth_measurements ADD COLUMN percentile_reference VARCHAR(32);
+
+CREATE INDEX idx_growth_percentile_reference ON growth_measurements(percentile_reference);
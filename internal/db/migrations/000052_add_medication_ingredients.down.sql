@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_medications_active_ingredients;
+
+ALTER TABLE medications DROP COLUMN IF EXISTS active_ingredients;
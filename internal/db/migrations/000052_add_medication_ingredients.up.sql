@@ -0,0 +1,5 @@
+-- Active ingredients normalized from the medication's name. NULL for
+-- medications saved before this, which are normalized when read.
+ALTER TABLE medications ADD COLUMN active_ingredients TEXT[];
+
+CREATE INDEX idx_medications_active_ingredients ON medications USING GIN (active_ingredients);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_family_invites_token;
+
+DELETE FROM family_invites WHERE email IS NULL;
+ALTER TABLE family_invites DROP COLUMN IF EXISTS token_hash;
+ALTER TABLE family_invites ALTER COLUMN email SET NOT NULL;
@@ -0,0 +1,6 @@
+-- Link invites are shown as a QR code rather than emailed, so they have no
+-- address; whoever scans one first joins with its token
+ALTER TABLE family_invites ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE family_invites ADD COLUMN token_hash VARCHAR(64);
+
+CREATE UNIQUE INDEX idx_family_invites_token ON family_invites(token_hash) WHERE token_hash IS NOT NULL;
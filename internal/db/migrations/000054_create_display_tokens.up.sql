@@ -0,0 +1,20 @@
+-- Wall displays, such as a kitchen tablet, read a family's live view with a
+-- token that rotates as it is used. The token it replaced stays valid until
+-- previous_valid_until so a display that misses the new one can still recover.
+CREATE TABLE display_tokens (
+    id VARCHAR(64) PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    previous_hash VARCHAR(64),
+    previous_valid_until TIMESTAMPTZ,
+    created_by VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_display_tokens_family_id ON display_tokens(family_id);
+CREATE INDEX idx_display_tokens_previous_hash ON display_tokens(previous_hash) WHERE previous_hash IS NOT NULL;
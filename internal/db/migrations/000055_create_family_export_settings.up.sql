@@ -0,0 +1,8 @@
+-- Families can insist that exports of their children's records are only
+-- given out as passphrase-encrypted archives. The passphrases themselves
+-- are never stored.
+CREATE TABLE family_export_settings (
+    family_id VARCHAR(64) PRIMARY KEY REFERENCES families(id) ON DELETE CASCADE,
+    require_passphrase BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS daycare_imports;
+DROP TABLE IF EXISTS daycare_connections;
@@ -0,0 +1,30 @@
+-- A family's link to its daycare's software. children maps the provider's
+-- child IDs to babytrack children; token is sealed with the server's
+-- encryption key.
+CREATE TABLE daycare_connections (
+    id VARCHAR(64) PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    provider VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    endpoint TEXT NOT NULL DEFAULT '',
+    token BYTEA,
+    children JSONB NOT NULL DEFAULT '{}',
+    sync_cursor TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(64) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_synced_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_daycare_connections_family_id ON daycare_connections(family_id);
+
+-- Each provider entry is imported once. record_id is empty while the record
+-- is being created.
+CREATE TABLE daycare_imports (
+    connection_id VARCHAR(64) NOT NULL REFERENCES daycare_connections(id) ON DELETE CASCADE,
+    entry_id VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL DEFAULT '',
+    record_id VARCHAR(64) NOT NULL DEFAULT '',
+    imported_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (connection_id, entry_id)
+);
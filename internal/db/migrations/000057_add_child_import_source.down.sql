@@ -0,0 +1 @@
+ALTER TABLE child_imports DROP COLUMN IF EXISTS source;
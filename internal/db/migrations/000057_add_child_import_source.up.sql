@@ -0,0 +1,2 @@
+-- Where an import came from: a babytrack bundle, or another app's export
+ALTER TABLE child_imports ADD COLUMN source VARCHAR(32) NOT NULL DEFAULT 'bundle';
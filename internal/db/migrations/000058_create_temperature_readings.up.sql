@@ -0,0 +1,19 @@
+-- Temperatures as entered, in their own unit, with celsius kept alongside so
+-- readings in either unit can be compared and charted together
+CREATE TABLE temperature_readings (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    taken_at TIMESTAMPTZ NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    unit VARCHAR(1) NOT NULL CHECK (unit IN ('C', 'F')),
+    site VARCHAR(16) NOT NULL DEFAULT '',
+    celsius DOUBLE PRECISION NOT NULL,
+    symptoms TEXT[] NOT NULL DEFAULT '{}',
+    medication_id VARCHAR(64) REFERENCES medications(id) ON DELETE SET NULL,
+    medication_given TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_temperature_child_taken ON temperature_readings(child_id, taken_at DESC);
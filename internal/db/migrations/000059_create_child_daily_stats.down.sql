@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS child_daily_stats_refreshes;
+DROP TABLE IF EXISTS child_daily_stats;
@@ -0,0 +1,21 @@
+-- Per child and calendar day totals of feedings and ended sleep, kept by the
+-- daily-stats job so stats read one row a day instead of aggregating the raw
+-- logs. Days are those of start_time in the database's time zone.
+CREATE TABLE child_daily_stats (
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    feedings INTEGER NOT NULL DEFAULT 0,
+    feeding_ml DOUBLE PRECISION NOT NULL DEFAULT 0,
+    nursing_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    sleeps INTEGER NOT NULL DEFAULT 0,
+    sleep_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (child_id, day)
+);
+
+-- When the job last refreshed the table. Days before that date are
+-- materialized; changes made after it are picked up on the next run.
+CREATE TABLE child_daily_stats_refreshes (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    refreshed_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS milk_consumptions;
+DROP TABLE IF EXISTS milk_stash;
+DROP TABLE IF EXISTS pumping_sessions;
@@ -0,0 +1,53 @@
+-- Expressed milk belongs to the family rather than one child, since any of
+-- its children may be fed from the stash
+CREATE TABLE pumping_sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    pumped_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    duration_minutes INT NOT NULL DEFAULT 0,
+    side VARCHAR(8) NOT NULL DEFAULT 'both' CHECK (side IN ('left', 'right', 'both')),
+    amount DECIMAL(10, 2),
+    unit VARCHAR(8) NOT NULL DEFAULT '',
+    amount_ml DECIMAL(10, 2),
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pumping_sessions_family_start ON pumping_sessions(family_id, start_time DESC);
+
+-- Each row is one stored container. remaining_ml goes down as bottle feeds
+-- draw from it; discarded_at is set when it is thrown away.
+CREATE TABLE milk_stash (
+    id VARCHAR(64) PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    session_id VARCHAR(64) REFERENCES pumping_sessions(id) ON DELETE SET NULL,
+    storage VARCHAR(8) NOT NULL CHECK (storage IN ('fridge', 'freezer')),
+    amount_ml DECIMAL(10, 2) NOT NULL CHECK (amount_ml > 0),
+    remaining_ml DECIMAL(10, 2) NOT NULL CHECK (remaining_ml >= 0),
+    pumped_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    thawed_at TIMESTAMPTZ,
+    discarded_at TIMESTAMPTZ,
+    notes TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_milk_stash_family_expires ON milk_stash(family_id, expires_at)
+    WHERE discarded_at IS NULL AND remaining_ml > 0;
+
+-- A bottle feed may draw from several containers, but from each only once
+CREATE TABLE milk_consumptions (
+    id VARCHAR(64) PRIMARY KEY,
+    item_id VARCHAR(64) NOT NULL REFERENCES milk_stash(id) ON DELETE CASCADE,
+    feeding_id VARCHAR(64) NOT NULL REFERENCES feedings(id) ON DELETE CASCADE,
+    amount_ml DECIMAL(10, 2) NOT NULL CHECK (amount_ml > 0),
+    consumed_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    consumed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (item_id, feeding_id)
+);
+
+CREATE INDEX idx_milk_consumptions_feeding ON milk_consumptions(feeding_id);
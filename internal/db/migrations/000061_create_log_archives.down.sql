@@ -0,0 +1,8 @@
+-- Bring archived rows back before dropping their tables
+INSERT INTO feedings SELECT * FROM feedings_archive;
+INSERT INTO sleep_records SELECT * FROM sleep_records_archive;
+
+DROP VIEW IF EXISTS sleep_records_all;
+DROP VIEW IF EXISTS feedings_all;
+DROP TABLE IF EXISTS sleep_records_archive;
+DROP TABLE IF EXISTS feedings_archive;
@@ -0,0 +1,24 @@
+-- Cold feedings and sleep records are moved into archive tables so the live
+-- tables, and their indexes, stay the size of recent history. Rows are moved
+-- with SELECT *, so a column added to a live table must be added to its
+-- archive in the same migration, in the same position.
+CREATE TABLE feedings_archive (LIKE feedings INCLUDING ALL);
+ALTER TABLE feedings_archive
+    ADD FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE;
+
+CREATE TABLE sleep_records_archive (LIKE sleep_records INCLUDING ALL);
+ALTER TABLE sleep_records_archive
+    ADD FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (started_by) REFERENCES users(id) ON DELETE SET NULL,
+    ADD FOREIGN KEY (ended_by) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Reads that may reach back past the archive cutoff go through these
+CREATE VIEW feedings_all AS
+    SELECT * FROM feedings
+    UNION ALL
+    SELECT * FROM feedings_archive;
+
+CREATE VIEW sleep_records_all AS
+    SELECT * FROM sleep_records
+    UNION ALL
+    SELECT * FROM sleep_records_archive;
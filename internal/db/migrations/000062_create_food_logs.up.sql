@@ -0,0 +1,20 @@
+-- One row each time a child tries a food. The allergen checklist and the
+-- tried / not tried list are worked out from these rows.
+CREATE TABLE food_logs (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    food VARCHAR(100) NOT NULL,
+    allergen VARCHAR(16) NOT NULL DEFAULT '' CHECK (allergen IN (
+        '', 'milk', 'egg', 'peanut', 'tree_nuts', 'soy', 'wheat', 'fish', 'shellfish', 'sesame'
+    )),
+    tried_at TIMESTAMPTZ NOT NULL,
+    reaction VARCHAR(16) NOT NULL CHECK (reaction IN (
+        'none', 'rash', 'hives', 'vomiting', 'diarrhea', 'swelling', 'other'
+    )),
+    severity VARCHAR(16) NOT NULL DEFAULT '' CHECK (severity IN ('', 'mild', 'moderate', 'severe')),
+    notes TEXT NOT NULL DEFAULT '',
+    logged_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_food_logs_child_food ON food_logs(child_id, food, tried_at);
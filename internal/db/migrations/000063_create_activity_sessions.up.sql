@@ -0,0 +1,18 @@
+-- Timed activities such as tummy time and screen time. end_time stays NULL
+-- while a session is running; the partial index allows one running session
+-- of each type per child.
+CREATE TABLE activity_sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    child_id VARCHAR(64) NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('tummy_time', 'outdoor', 'screen', 'play')),
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ CHECK (end_time IS NULL OR end_time >= start_time),
+    notes TEXT NOT NULL DEFAULT '',
+    started_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    ended_by VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_activity_sessions_child_start ON activity_sessions(child_id, start_time);
+CREATE UNIQUE INDEX idx_activity_sessions_running ON activity_sessions(child_id, type) WHERE end_time IS NULL;
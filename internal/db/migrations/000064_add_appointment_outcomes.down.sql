@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS appointment_measurements;
+DROP TABLE IF EXISTS appointment_vaccinations;
+
+ALTER TABLE appointments DROP COLUMN IF EXISTS outcome;
+ALTER TABLE appointments DROP COLUMN IF EXISTS reason;
@@ -0,0 +1,16 @@
+-- Why a visit was booked, what came of it, and the vaccinations and growth
+-- measurements recorded at it
+ALTER TABLE appointments ADD COLUMN reason TEXT;
+ALTER TABLE appointments ADD COLUMN outcome TEXT;
+
+CREATE TABLE appointment_vaccinations (
+    appointment_id VARCHAR(64) NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+    vaccination_id VARCHAR(64) NOT NULL REFERENCES vaccinations(id) ON DELETE CASCADE,
+    PRIMARY KEY (appointment_id, vaccination_id)
+);
+
+CREATE TABLE appointment_measurements (
+    appointment_id VARCHAR(64) NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+    measurement_id VARCHAR(64) NOT NULL REFERENCES growth_measurements(id) ON DELETE CASCADE,
+    PRIMARY KEY (appointment_id, measurement_id)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pumping_sessions_running;
+ALTER TABLE pumping_sessions DROP COLUMN IF EXISTS running;
@@ -0,0 +1,5 @@
+-- A session started from a timer stays running until it is stopped, when its
+-- duration is filled in. Each member can only pump one session at a time.
+ALTER TABLE pumping_sessions ADD COLUMN running BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX idx_pumping_sessions_running ON pumping_sessions(family_id, pumped_by) WHERE running;
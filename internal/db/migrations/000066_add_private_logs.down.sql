@@ -0,0 +1,20 @@
+DROP VIEW IF EXISTS sleep_records_all;
+DROP VIEW IF EXISTS feedings_all;
+
+ALTER TABLE medication_logs DROP COLUMN IF EXISTS private;
+
+ALTER TABLE sleep_records_archive DROP COLUMN IF EXISTS private;
+ALTER TABLE sleep_records DROP COLUMN IF EXISTS private;
+
+ALTER TABLE feedings_archive DROP COLUMN IF EXISTS private, DROP COLUMN IF EXISTS author_id;
+ALTER TABLE feedings DROP COLUMN IF EXISTS private, DROP COLUMN IF EXISTS author_id;
+
+CREATE VIEW feedings_all AS
+    SELECT * FROM feedings
+    UNION ALL
+    SELECT * FROM feedings_archive;
+
+CREATE VIEW sleep_records_all AS
+    SELECT * FROM sleep_records
+    UNION ALL
+    SELECT * FROM sleep_records_archive;
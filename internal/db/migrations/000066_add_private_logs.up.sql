@@ -0,0 +1,25 @@
+-- A private feeding, sleep or dose is only shown to the member who logged
+-- it. Feedings did not record who logged them until now, so older feedings
+-- have no author and cannot be made private.
+ALTER TABLE feedings
+    ADD COLUMN author_id VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN private BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE feedings_archive
+    ADD COLUMN author_id VARCHAR(64) REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN private BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE sleep_records ADD COLUMN private BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE sleep_records_archive ADD COLUMN private BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE medication_logs ADD COLUMN private BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The views were expanded when they were created, so pick up the new columns
+CREATE OR REPLACE VIEW feedings_all AS
+    SELECT * FROM feedings
+    UNION ALL
+    SELECT * FROM feedings_archive;
+
+CREATE OR REPLACE VIEW sleep_records_all AS
+    SELECT * FROM sleep_records
+    UNION ALL
+    SELECT * FROM sleep_records_archive;
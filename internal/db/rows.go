@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"iter"
+)
+
+// Stream runs query and yields each row as scan reads it, so results of any
+// size can be walked without holding them in memory. The rows are closed
+// when the caller stops early. A failed query, scan or iteration is yielded
+// as the last element, with the zero T.
+func Stream[T any](ctx context.Context, conn Conn, scan func(*sql.Rows) (T, error), query string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		rows, err := conn.QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close() //nolint:errcheck // Best-effort close
+
+		for rows.Next() {
+			v, err := scan(rows)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func scanName(rows *sql.Rows) (string, error) {
+	var name string
+	err := rows.Scan(&name)
+	return name, err
+}
+
+func TestStream(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT name FROM children WHERE family_id = \\$1").
+		WithArgs("family-1").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Emma").AddRow("Noah"))
+
+	var names []string
+	for name, err := range Stream(context.Background(), db, scanName, "SELECT name FROM children WHERE family_id = $1", "family-1") {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		names = append(names, name)
+	}
+	if len(names) != 2 || names[0] != "Emma" || names[1] != "Noah" {
+		t.Errorf("Stream() = %v", names)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestStream_StopsEarly(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT name FROM children").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Emma").AddRow("Noah")).
+		RowsWillBeClosed()
+
+	for name := range Stream(context.Background(), db, scanName, "SELECT name FROM children") {
+		if name != "Emma" {
+			t.Errorf("first row = %q", name)
+		}
+		break
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("rows not closed: %v", err)
+	}
+}
+
+func TestStream_QueryError(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+
+	failed := errors.New("connection reset")
+	mock.ExpectQuery("SELECT name FROM children").WillReturnError(failed)
+
+	var errs []error
+	for _, err := range Stream(context.Background(), db, scanName, "SELECT name FROM children") {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], failed) {
+		t.Errorf("Stream() errors = %v, want one %v", errs, failed)
+	}
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SlowQueryConfig turns on logging of queries that take longer than
+// Threshold. A share of them, ExplainSampleRate between 0 and 1, are also
+// run through EXPLAIN so the plan is logged next to the query.
+type SlowQueryConfig struct {
+	Threshold         time.Duration
+	ExplainSampleRate float64
+}
+
+// explainTimeout bounds the EXPLAIN run after a slow query. EXPLAIN only
+// plans the query, so it is quick unless the database is struggling.
+const explainTimeout = 5 * time.Second
+
+type explainKey struct{}
+
+// slowLog times every query and exec made through the connections it wraps,
+// whichever repository issued them. Timing stops when the database starts
+// returning rows, not when the caller has read them all.
+type slowLog struct {
+	cfg    SlowQueryConfig
+	db     *sql.DB // runs EXPLAIN; set once the pool is open
+	logf   func(format string, args ...any)
+	sample func() float64
+	wg     sync.WaitGroup // tracks EXPLAIN runs in flight
+}
+
+func newSlowLog(cfg SlowQueryConfig) *slowLog {
+	return &slowLog{cfg: cfg, logf: log.Printf, sample: rand.Float64}
+}
+
+func (l *slowLog) connector(c driver.Connector) driver.Connector {
+	return &slowConnector{Connector: c, log: l}
+}
+
+func (l *slowLog) observe(ctx context.Context, query string, args []driver.NamedValue, took time.Duration) {
+	if took < l.cfg.Threshold || ctx.Value(explainKey{}) != nil {
+		return
+	}
+
+	query = strings.Join(strings.Fields(query), " ")
+	// Arguments can hold personal data, so only their count is logged
+	l.logf("[db] Slow query took %s (%d args): %s", took.Round(time.Millisecond), len(args), query)
+
+	if l.db == nil || !explainable(query) || l.sample() >= l.cfg.ExplainSampleRate {
+		return
+	}
+
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		plan, err := l.explain(query, values)
+		if err != nil {
+			l.logf("[db] EXPLAIN failed for slow query: %v", err)
+			return
+		}
+		l.logf("[db] EXPLAIN for slow query: %s\n%s", query, plan)
+	}()
+}
+
+func (l *slowLog) explain(query string, args []any) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, explainKey{}, true)
+
+	rows, err := l.db.QueryContext(ctx, "EXPLAIN "+query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// explainable reports whether EXPLAIN accepts the statement. Without ANALYZE
+// it only plans, so even writes are safe to explain.
+func explainable(query string) bool {
+	verb, _, _ := strings.Cut(query, " ")
+	switch strings.ToUpper(verb) {
+	case "SELECT", "WITH", "INSERT", "UPDATE", "DELETE":
+		return true
+	}
+	return false
+}
+
+type slowConnector struct {
+	driver.Connector
+	log *slowLog
+}
+
+func (c *slowConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &slowConn{Conn: conn, log: c.log}, nil
+}
+
+// slowConn times queries and passes everything else through, keeping the
+// optional interfaces database/sql looks for on the driver's connection
+type slowConn struct {
+	driver.Conn
+	log *slowLog
+}
+
+func (c *slowConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		c.log.observe(ctx, query, args, time.Since(start))
+	}
+	return rows, err
+}
+
+func (c *slowConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := e.ExecContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		c.log.observe(ctx, query, args, time.Since(start))
+	}
+	return result, err
+}
+
+func (c *slowConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Prepare(query)
+}
+
+func (c *slowConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts.ReadOnly || opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("driver does not support transaction options")
+	}
+	return c.Begin() //nolint:staticcheck // Fallback for drivers without BeginTx
+}
+
+func (c *slowConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *slowConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *slowConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *slowConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
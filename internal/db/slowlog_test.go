@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// dsnConnector opens connections from a registered driver, as sql.Open would
+type dsnConnector struct {
+	dsn string
+	drv driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.drv.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.drv }
+
+// newSlowMockDB returns a pool whose connections go through the slow query
+// log, backed by sqlmock, and the lines it logged
+func newSlowMockDB(t *testing.T, cfg SlowQueryConfig) (*sql.DB, sqlmock.Sqlmock, *slowLog, func() []string) {
+	t.Helper()
+	dsn := "slowlog-" + t.Name()
+	_, mock, err := sqlmock.NewWithDSN(dsn, sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	base, err := sql.Open("sqlmock", dsn)
+	if err != nil {
+		t.Fatalf("Failed to open mock driver: %v", err)
+	}
+
+	var mu sync.Mutex
+	var lines []string
+	slow := newSlowLog(cfg)
+	slow.logf = func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	slow.sample = func() float64 { return 0.5 }
+
+	db := sql.OpenDB(slow.connector(dsnConnector{dsn: dsn, drv: base.Driver()}))
+	db.SetMaxOpenConns(1)
+	slow.db = db
+	t.Cleanup(func() { db.Close() })
+
+	logged := func() []string {
+		slow.wg.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return lines
+	}
+	return db, mock, slow, logged
+}
+
+func TestSlowLog_FastQueryNotLogged(t *testing.T) {
+	db, mock, _, logged := newSlowMockDB(t, SlowQueryConfig{Threshold: time.Second, ExplainSampleRate: 1})
+
+	mock.ExpectQuery("SELECT id FROM feedings").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	rows, err := db.QueryContext(context.Background(), "SELECT id FROM feedings WHERE child_id = $1", "child-1")
+	if err != nil {
+		t.Fatalf("QueryContext() error = %v", err)
+	}
+	rows.Close()
+
+	if lines := logged(); len(lines) != 0 {
+		t.Errorf("Expected nothing logged, got %v", lines)
+	}
+}
+
+func TestSlowLog_SlowQueryExplained(t *testing.T) {
+	db, mock, _, logged := newSlowMockDB(t, SlowQueryConfig{Threshold: time.Millisecond, ExplainSampleRate: 1})
+
+	mock.ExpectQuery("SELECT id FROM feedings").
+		WithArgs("child-1").
+		WillDelayFor(5 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("EXPLAIN SELECT id FROM feedings").
+		WithArgs("child-1").
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).
+			AddRow("Seq Scan on feedings").
+			AddRow("  Filter: (child_id = $1)"))
+
+	rows, err := db.QueryContext(context.Background(), "SELECT id\n\t\tFROM feedings\n\t\tWHERE child_id = $1", "child-1")
+	if err != nil {
+		t.Fatalf("QueryContext() error = %v", err)
+	}
+	rows.Close()
+
+	lines := logged()
+	if len(lines) != 2 {
+		t.Fatalf("Expected the query and its plan logged, got %v", lines)
+	}
+	if !strings.Contains(lines[0], "(1 args): SELECT id FROM feedings WHERE child_id = $1") {
+		t.Errorf("Expected the compacted query without argument values, got %q", lines[0])
+	}
+	if strings.Contains(lines[0], "child-1") {
+		t.Error("Expected argument values to be left out of the log")
+	}
+	if !strings.Contains(lines[1], "Seq Scan on feedings") {
+		t.Errorf("Expected the plan logged, got %q", lines[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSlowLog_ExplainSampled(t *testing.T) {
+	db, mock, _, logged := newSlowMockDB(t, SlowQueryConfig{Threshold: time.Millisecond, ExplainSampleRate: 0.1})
+
+	mock.ExpectExec("UPDATE sleep").
+		WillDelayFor(5 * time.Millisecond).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := db.ExecContext(context.Background(), "UPDATE sleep SET end_time = NOW()"); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	if lines := logged(); len(lines) != 1 {
+		t.Errorf("Expected the slow exec logged without a plan, got %v", lines)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestExplainable(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT 1":                             true,
+		"with recent AS (SELECT 1) SELECT 1":   true,
+		"DELETE FROM api_usage WHERE day < $1": true,
+		"BEGIN":                                false,
+		"LOCK TABLE feedings":                  false,
+	}
+	for query, want := range tests {
+		if got := explainable(query); got != want {
+			t.Errorf("explainable(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
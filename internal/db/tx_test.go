@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestWithinTx_Commit(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO a").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO b").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := NewTxManager(db).WithinTx(context.Background(), func(ctx context.Context) error {
+		if _, err := Use(ctx, db).ExecContext(ctx, "INSERT INTO a"); err != nil {
+			return err
+		}
+		_, err := Use(ctx, db).ExecContext(ctx, "INSERT INTO b")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithinTx() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWithinTx_RollbackOnError(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO a").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectRollback()
+
+	boom := errors.New("boom")
+	err := NewTxManager(db).WithinTx(context.Background(), func(ctx context.Context) error {
+		if _, err := Use(ctx, db).ExecContext(ctx, "INSERT INTO a"); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("Expected fn error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWithinTx_RollbackOnPanic(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic to propagate")
+			}
+		}()
+		_ = NewTxManager(db).WithinTx(context.Background(), func(ctx context.Context) error {
+			panic("boom")
+		})
+	}()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWithinTx_NestedJoinsOuter(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO a").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	tx := NewTxManager(db)
+	err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		return tx.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := Use(ctx, db).ExecContext(ctx, "INSERT INTO a")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("WithinTx() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUse_WithoutTx(t *testing.T) {
+	db, _ := newMockDB(t)
+	defer db.Close()
+
+	if Use(context.Background(), db) != Conn(db) {
+		t.Error("Expected the database outside a transaction")
+	}
+}
@@ -0,0 +1,86 @@
+// Package delta answers list requests made with ?since= with only what
+// changed: records created or updated after the cursor and the IDs of
+// records deleted since.
+package delta
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type EntityType string
+
+const (
+	EntityChild       EntityType = "child"
+	EntityVaccination EntityType = "vaccination"
+	EntityMedication  EntityType = "medication"
+	EntityFeeding     EntityType = "feeding"
+	EntitySleep       EntityType = "sleep"
+	EntityNote        EntityType = "note"
+)
+
+// DefaultRetention is how long deletions are remembered unless configured
+// otherwise. A cursor older than the retention can no longer be answered
+// and the client has to fetch the full list.
+const DefaultRetention = 90 * 24 * time.Hour
+
+var (
+	ErrInvalidSince = errors.New("since must be an RFC 3339 timestamp")
+	ErrSinceExpired = errors.New("since is too old, fetch the full list")
+)
+
+// Changes is a delta against a since cursor. ServerTime is the cursor to
+// send next time.
+type Changes[T any] struct {
+	Created    []T       `json:"created"`
+	Updated    []T       `json:"updated"`
+	Deleted    []string  `json:"deleted"`
+	ServerTime time.Time `json:"server_time"`
+}
+
+// ParseSince reads a since query value, returning nil when it is absent
+func ParseSince(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSince, s)
+	}
+	return &t, nil
+}
+
+// Check rejects a cursor older than the deletions still on record
+func Check(since, now time.Time, retention time.Duration) error {
+	if since.Before(now.Add(-retention)) {
+		return ErrSinceExpired
+	}
+	return nil
+}
+
+// Build keeps the items written after since, split by whether they were
+// created or only updated after it. stamps returns an item's created and
+// updated times. serverTime should be taken before the items were read so
+// that nothing written meanwhile is skipped by the next cursor.
+func Build[T any](items []T, since time.Time, deleted []string, serverTime time.Time, stamps func(*T) (created, updated time.Time)) *Changes[T] {
+	changes := &Changes[T]{
+		Created:    []T{},
+		Updated:    []T{},
+		Deleted:    deleted,
+		ServerTime: serverTime.UTC(),
+	}
+	if changes.Deleted == nil {
+		changes.Deleted = []string{}
+	}
+	for i := range items {
+		created, updated := stamps(&items[i])
+		switch {
+		case created.After(since):
+			changes.Created = append(changes.Created, items[i])
+		case updated.After(since):
+			changes.Updated = append(changes.Updated, items[i])
+		}
+	}
+	return changes
+}
@@ -0,0 +1,68 @@
+package delta
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type record struct {
+	ID        string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func TestParseSince(t *testing.T) {
+	since, err := ParseSince("")
+	if err != nil || since != nil {
+		t.Errorf("ParseSince(\"\") = %v, %v; want nil, nil", since, err)
+	}
+
+	since, err = ParseSince("2025-03-01T10:00:00+02:00")
+	if err != nil || !since.Equal(time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseSince() = %v, %v", since, err)
+	}
+
+	if _, err := ParseSince("2025-03-01"); !errors.Is(err, ErrInvalidSince) {
+		t.Errorf("ParseSince(date) error = %v, want ErrInvalidSince", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	week := 7 * 24 * time.Hour
+	if err := Check(now.Add(-week+time.Hour), now, week); err != nil {
+		t.Errorf("Check() within retention error = %v", err)
+	}
+	if err := Check(now.Add(-week-time.Hour), now, week); !errors.Is(err, ErrSinceExpired) {
+		t.Errorf("Check() past retention error = %v, want ErrSinceExpired", err)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	now := since.Add(48 * time.Hour)
+	items := []record{
+		{ID: "unchanged", CreatedAt: since.Add(-time.Hour), UpdatedAt: since.Add(-time.Hour)},
+		{ID: "updated", CreatedAt: since.Add(-time.Hour), UpdatedAt: since.Add(time.Hour)},
+		{ID: "created", CreatedAt: since.Add(time.Hour), UpdatedAt: since.Add(2 * time.Hour)},
+		{ID: "at-cursor", CreatedAt: since, UpdatedAt: since},
+	}
+
+	changes := Build(items, since, nil, now.In(time.FixedZone("x", 3600)), func(r *record) (time.Time, time.Time) {
+		return r.CreatedAt, r.UpdatedAt
+	})
+
+	if len(changes.Created) != 1 || changes.Created[0].ID != "created" {
+		t.Errorf("Created = %+v, want created", changes.Created)
+	}
+	if len(changes.Updated) != 1 || changes.Updated[0].ID != "updated" {
+		t.Errorf("Updated = %+v, want updated", changes.Updated)
+	}
+	if changes.Deleted == nil || len(changes.Deleted) != 0 {
+		t.Errorf("Deleted = %#v, want an empty list", changes.Deleted)
+	}
+	if !changes.ServerTime.Equal(now) || changes.ServerTime.Location() != time.UTC {
+		t.Errorf("ServerTime = %v, want %v in UTC", changes.ServerTime, now)
+	}
+}
@@ -0,0 +1,139 @@
+package delta
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+// Tombstone is a remembered deletion. ScopeID is what the record belonged
+// to: the family for a child, the child for everything else.
+type Tombstone struct {
+	EntityType EntityType `json:"entity_type"`
+	EntityID   string     `json:"entity_id"`
+	ScopeID    string     `json:"scope_id"`
+	DeletedAt  time.Time  `json:"deleted_at"`
+}
+
+type Tombstones interface {
+	// Record remembers that a record was deleted. It joins the caller's
+	// transaction, so the tombstone is only kept if the delete commits.
+	Record(ctx context.Context, entityType EntityType, entityID, scopeID string, at time.Time) error
+	// DeletedSince returns the IDs deleted within scopeID after since
+	DeletedSince(ctx context.Context, entityType EntityType, scopeID string, since time.Time) ([]string, error)
+	// Since returns every tombstone in scopeIDs after since, oldest first
+	Since(ctx context.Context, scopeIDs []string, since time.Time) ([]Tombstone, error)
+	// Purge drops tombstones older than before and returns how many were removed
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	// Retention is how long tombstones are kept
+	Retention() time.Duration
+}
+
+type tombstones struct {
+	db        *sql.DB
+	retention time.Duration
+}
+
+// NewTombstones keeps deletions for retention. Zero uses DefaultRetention.
+func NewTombstones(db *sql.DB, retention time.Duration) Tombstones {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &tombstones{db: db, retention: retention}
+}
+
+// HealthCheck checks the tombstones table that delta syncs read deletions from
+func (t *tombstones) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, t.db, "tombstones")
+}
+
+// Deleted checks since against the store's retention and returns the IDs of
+// entityType deleted within scopeID after it. Without a store nothing is
+// remembered, so only the cursor is checked against DefaultRetention.
+func Deleted(ctx context.Context, t Tombstones, entityType EntityType, scopeID string, since, now time.Time) ([]string, error) {
+	if t == nil {
+		return nil, Check(since, now, DefaultRetention)
+	}
+	if err := Check(since, now, t.Retention()); err != nil {
+		return nil, err
+	}
+	return t.DeletedSince(ctx, entityType, scopeID, since)
+}
+
+func (t *tombstones) Retention() time.Duration {
+	return t.retention
+}
+
+func (t *tombstones) Record(ctx context.Context, entityType EntityType, entityID, scopeID string, at time.Time) error {
+	query := `
+		INSERT INTO tombstones (entity_type, entity_id, scope_id, deleted_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (entity_type, entity_id) DO UPDATE SET scope_id = EXCLUDED.scope_id, deleted_at = EXCLUDED.deleted_at
+	`
+
+	_, err := db.Use(ctx, t.db).ExecContext(ctx, query, entityType, entityID, scopeID, at)
+	return err
+}
+
+func (t *tombstones) DeletedSince(ctx context.Context, entityType EntityType, scopeID string, since time.Time) ([]string, error) {
+	query := `
+		SELECT entity_id
+		FROM tombstones
+		WHERE entity_type = $1 AND scope_id = $2 AND deleted_at > $3
+		ORDER BY deleted_at
+	`
+
+	rows, err := t.db.QueryContext(ctx, query, entityType, scopeID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (t *tombstones) Since(ctx context.Context, scopeIDs []string, since time.Time) ([]Tombstone, error) {
+	query := `
+		SELECT entity_type, entity_id, scope_id, deleted_at
+		FROM tombstones
+		WHERE scope_id = ANY($1) AND deleted_at > $2
+		ORDER BY deleted_at
+	`
+
+	rows, err := t.db.QueryContext(ctx, query, pq.Array(scopeIDs), since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	stones := []Tombstone{}
+	for rows.Next() {
+		var ts Tombstone
+		if err := rows.Scan(&ts.EntityType, &ts.EntityID, &ts.ScopeID, &ts.DeletedAt); err != nil {
+			return nil, err
+		}
+		stones = append(stones, ts)
+	}
+	return stones, rows.Err()
+}
+
+func (t *tombstones) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result, err := t.db.ExecContext(ctx, `DELETE FROM tombstones WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
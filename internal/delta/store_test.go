@@ -0,0 +1,128 @@
+package delta
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestTombstones_Record(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	store := NewTombstones(db, 0)
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO tombstones").
+		WithArgs(EntityChild, "child-1", "family-1", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := store.Record(context.Background(), EntityChild, "child-1", "family-1", now); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestTombstones_DeletedSince(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	store := NewTombstones(db, 0)
+
+	since := time.Now().Add(-time.Hour)
+	mock.ExpectQuery("SELECT entity_id FROM tombstones").
+		WithArgs(EntityVaccination, "child-1", since).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow("vax-1").AddRow("vax-2"))
+
+	ids, err := store.DeletedSince(context.Background(), EntityVaccination, "child-1", since)
+	if err != nil {
+		t.Fatalf("DeletedSince() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "vax-1" {
+		t.Errorf("DeletedSince() = %v", ids)
+	}
+}
+
+func TestTombstones_Since(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	store := NewTombstones(db, 0)
+
+	since := time.Now().Add(-time.Hour)
+	mock.ExpectQuery("WHERE scope_id = ANY\\(\\$1\\) AND deleted_at > \\$2").
+		WithArgs(pq.Array([]string{"family-1", "child-1"}), since).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_type", "entity_id", "scope_id", "deleted_at"}).
+			AddRow("child", "child-2", "family-1", since.Add(time.Minute)).
+			AddRow("feeding", "feeding-1", "child-1", since.Add(2*time.Minute)))
+
+	stones, err := store.Since(context.Background(), []string{"family-1", "child-1"}, since)
+	if err != nil {
+		t.Fatalf("Since() error = %v", err)
+	}
+	if len(stones) != 2 || stones[0].EntityType != EntityChild || stones[1].EntityID != "feeding-1" {
+		t.Errorf("Since() = %+v", stones)
+	}
+}
+
+func TestNewTombstones_Retention(t *testing.T) {
+	if got := NewTombstones(nil, 0).Retention(); got != DefaultRetention {
+		t.Errorf("Retention() = %v, want %v", got, DefaultRetention)
+	}
+	if got := NewTombstones(nil, time.Hour).Retention(); got != time.Hour {
+		t.Errorf("Retention() = %v, want 1h", got)
+	}
+}
+
+func TestDeleted(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	store := NewTombstones(db, 24*time.Hour)
+
+	now := time.Now()
+	if _, err := Deleted(context.Background(), store, EntityNote, "child-1", now.Add(-48*time.Hour), now); !errors.Is(err, ErrSinceExpired) {
+		t.Errorf("Deleted() past retention error = %v, want ErrSinceExpired", err)
+	}
+
+	since := now.Add(-time.Hour)
+	mock.ExpectQuery("SELECT entity_id FROM tombstones").
+		WithArgs(EntityNote, "child-1", since).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow("note-1"))
+	ids, err := Deleted(context.Background(), store, EntityNote, "child-1", since, now)
+	if err != nil || len(ids) != 1 {
+		t.Errorf("Deleted() = %v, %v", ids, err)
+	}
+
+	if ids, err := Deleted(context.Background(), nil, EntityNote, "child-1", since, now); err != nil || ids != nil {
+		t.Errorf("Deleted() without store = %v, %v; want nil, nil", ids, err)
+	}
+}
+
+func TestTombstones_Purge(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	store := NewTombstones(db, 0)
+
+	before := time.Now()
+	mock.ExpectExec("DELETE FROM tombstones WHERE deleted_at < \\$1").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	purged, err := store.Purge(context.Background(), before)
+	if err != nil || purged != 3 {
+		t.Errorf("Purge() = %d, %v; want 3", purged, err)
+	}
+}
@@ -0,0 +1,23 @@
+package deprecation
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	policy *Policy
+}
+
+func NewHandler(policy *Policy) *Handler {
+	return &Handler{policy: policy}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.list)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	c.JSON(http.StatusOK, h.policy.List())
+}
@@ -0,0 +1,39 @@
+package deprecation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandler_List(t *testing.T) {
+	p, err := NewPolicy(testRoutes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	NewHandler(p).RegisterRoutes(router.Group("/deprecations"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/deprecations", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0]["path"] != "/items/:id" || got[0]["sunset"] != "2026-07-01T00:00:00Z" || got[0]["gone"] != false {
+		t.Errorf("body = %s", w.Body.String())
+	}
+	if _, ok := got[1]["sunset"]; ok {
+		t.Errorf("route without a sunset lists one: %v", got[1])
+	}
+}
@@ -0,0 +1,24 @@
+package deprecation
+
+import "time"
+
+// Route is a deprecated endpoint, named the way the router names it, e.g.
+// GET /api/feeding/:id
+type Route struct {
+	Method       string     `json:"method"`
+	Path         string     `json:"path"`
+	DeprecatedAt time.Time  `json:"deprecated_at"`
+	Sunset       *time.Time `json:"sunset,omitempty"`
+	// Replacement names what to call instead, e.g. GET /api/children/:id/active
+	Replacement string `json:"replacement,omitempty"`
+	// Link points at migration notes
+	Link    string `json:"link,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Notice is a deprecated route as listed for client teams
+type Notice struct {
+	Route
+	// Gone is true once the route answers 410 Gone instead of serving
+	Gone bool `json:"gone"`
+}
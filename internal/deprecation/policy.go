@@ -0,0 +1,163 @@
+// Package deprecation announces endpoints that are going away. Deprecated
+// routes are listed centrally in config; responses from them carry the
+// Deprecation, Sunset and Link headers, and the full list is served for
+// client teams to plan their migrations against. Once a route's sunset has
+// passed it can be retired, answering 410 Gone.
+package deprecation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/envelope"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMinNotice is the shortest time from deprecation to sunset
+const DefaultMinNotice = 90 * 24 * time.Hour
+
+var (
+	ErrInvalidRoute   = errors.New("invalid deprecated route")
+	ErrShortNotice    = errors.New("sunset gives less notice than the policy requires")
+	ErrUnknownRoute   = errors.New("deprecated route is not registered")
+	ErrDuplicateRoute = errors.New("route is listed more than once")
+)
+
+// Policy holds the deprecated routes and the rules for their sunset
+type Policy struct {
+	routes    []Route
+	byKey     map[string]*Route
+	minNotice time.Duration
+	retire    bool
+	now       func() time.Time
+}
+
+type Option func(*Policy)
+
+// WithMinNotice sets the shortest time from deprecation to sunset
+func WithMinNotice(d time.Duration) Option {
+	return func(p *Policy) {
+		if d > 0 {
+			p.minNotice = d
+		}
+	}
+}
+
+// WithRetirement answers calls to a route with 410 Gone once its sunset has
+// passed. Without it, sunset routes keep serving with their headers.
+func WithRetirement() Option {
+	return func(p *Policy) {
+		p.retire = true
+	}
+}
+
+// NewPolicy checks each route's dates against the policy. Routes are kept in
+// the order given.
+func NewPolicy(routes []Route, opts ...Option) (*Policy, error) {
+	p := &Policy{
+		byKey:     make(map[string]*Route, len(routes)),
+		minNotice: DefaultMinNotice,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.routes = make([]Route, len(routes))
+	for i, r := range routes {
+		r.Method = strings.ToUpper(strings.TrimSpace(r.Method))
+		if r.Method == "" || !strings.HasPrefix(r.Path, "/") {
+			return nil, fmt.Errorf("%w: %q %q needs a method and a path", ErrInvalidRoute, r.Method, r.Path)
+		}
+		if r.DeprecatedAt.IsZero() {
+			return nil, fmt.Errorf("%w: %s %s needs deprecated_at", ErrInvalidRoute, r.Method, r.Path)
+		}
+		if r.Sunset != nil && r.Sunset.Sub(r.DeprecatedAt) < p.minNotice {
+			return nil, fmt.Errorf("%w: %s %s sunsets %s after deprecation, under %s",
+				ErrShortNotice, r.Method, r.Path, r.Sunset.Sub(r.DeprecatedAt), p.minNotice)
+		}
+		if _, ok := p.byKey[key(r.Method, r.Path)]; ok {
+			return nil, fmt.Errorf("%w: %s %s", ErrDuplicateRoute, r.Method, r.Path)
+		}
+		p.routes[i] = r
+		p.byKey[key(r.Method, r.Path)] = &p.routes[i]
+	}
+	return p, nil
+}
+
+func key(method, path string) string {
+	return method + " " + path
+}
+
+// Check makes sure each deprecated route is one the router serves, so that a
+// typo in config doesn't silently announce nothing
+func (p *Policy) Check(registered gin.RoutesInfo) error {
+	served := make(map[string]bool, len(registered))
+	for _, r := range registered {
+		served[key(r.Method, r.Path)] = true
+	}
+	for _, r := range p.routes {
+		if !served[key(r.Method, r.Path)] {
+			return fmt.Errorf("%w: %s %s", ErrUnknownRoute, r.Method, r.Path)
+		}
+	}
+	return nil
+}
+
+// List returns the deprecated routes as of now
+func (p *Policy) List() []Notice {
+	now := p.now()
+	notices := make([]Notice, len(p.routes))
+	for i, r := range p.routes {
+		r.Message = r.message()
+		notices[i] = Notice{Route: r, Gone: p.gone(&p.routes[i], now)}
+	}
+	return notices
+}
+
+func (p *Policy) gone(r *Route, now time.Time) bool {
+	return p.retire && r.Sunset != nil && !now.Before(*r.Sunset)
+}
+
+// Middleware marks responses from deprecated routes, and refuses retired ones
+// with 410 Gone. It goes after routing has matched a path, on the group the
+// routes belong to.
+func (p *Policy) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r, ok := p.byKey[key(c.Request.Method, c.FullPath())]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		envelope.Deprecate(c, envelope.Deprecation{
+			Message: r.message(),
+			Since:   &r.DeprecatedAt,
+			Sunset:  r.Sunset,
+			Link:    r.Link,
+		})
+		if p.gone(r, p.now()) {
+			c.AbortWithStatusJSON(http.StatusGone, gin.H{
+				"error":       "this endpoint was retired on " + r.Sunset.UTC().Format(time.DateOnly),
+				"replacement": r.Replacement,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (r *Route) message() string {
+	switch {
+	case r.Message != "":
+		return r.Message
+	case r.Replacement != "":
+		return "deprecated; use " + r.Replacement + " instead"
+	default:
+		return "deprecated"
+	}
+}
@@ -0,0 +1,161 @@
+package deprecation
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+var (
+	deprecatedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset       = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func testRoutes() []Route {
+	return []Route{
+		{Method: "get", Path: "/items/:id", DeprecatedAt: deprecatedAt, Sunset: &sunset, Replacement: "GET /things/:id", Link: "https://example.com/migrate"},
+		{Method: "POST", Path: "/items", DeprecatedAt: deprecatedAt, Message: "post to /things"},
+	}
+}
+
+func setupRouter(p *Policy) *gin.Engine {
+	router := gin.New()
+	router.Use(p.Middleware())
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) }
+	router.GET("/items/:id", ok)
+	router.POST("/items", ok)
+	router.GET("/things/:id", ok)
+	return router
+}
+
+func TestNewPolicy_Errors(t *testing.T) {
+	tooSoon := deprecatedAt.Add(30 * 24 * time.Hour)
+	tests := []struct {
+		name   string
+		routes []Route
+		opts   []Option
+		want   error
+	}{
+		{"no method", []Route{{Path: "/items", DeprecatedAt: deprecatedAt}}, nil, ErrInvalidRoute},
+		{"relative path", []Route{{Method: "GET", Path: "items", DeprecatedAt: deprecatedAt}}, nil, ErrInvalidRoute},
+		{"no date", []Route{{Method: "GET", Path: "/items"}}, nil, ErrInvalidRoute},
+		{"short notice", []Route{{Method: "GET", Path: "/items", DeprecatedAt: deprecatedAt, Sunset: &tooSoon}}, nil, ErrShortNotice},
+		{"short custom notice", testRoutes(), []Option{WithMinNotice(365 * 24 * time.Hour)}, ErrShortNotice},
+		{"duplicate", append(testRoutes(), Route{Method: "GET", Path: "/items/:id", DeprecatedAt: deprecatedAt}), nil, ErrDuplicateRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPolicy(tt.routes, tt.opts...); !errors.Is(err, tt.want) {
+				t.Errorf("NewPolicy() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	p, err := NewPolicy(testRoutes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check(setupRouter(p).Routes()); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/items/:id", func(c *gin.Context) {})
+	if err := p.Check(router.Routes()); !errors.Is(err, ErrUnknownRoute) {
+		t.Errorf("Check() error = %v, want ErrUnknownRoute", err)
+	}
+}
+
+func TestPolicy_Middleware(t *testing.T) {
+	p, err := NewPolicy(testRoutes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.now = func() time.Time { return sunset.Add(time.Hour) }
+	router := setupRouter(p)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/items/1", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 without retirement", w.Code)
+	}
+	if got := w.Header().Get("Deprecation"); got != "@1767225600" {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if got := w.Header().Get("Sunset"); got != "Wed, 01 Jul 2026 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+	if got := w.Header().Get("Link"); got != `<https://example.com/migrate>; rel="deprecation"` {
+		t.Errorf("Link = %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/items", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Deprecation") == "" || w.Header().Get("Sunset") != "" {
+		t.Errorf("POST /items: status = %d, headers = %v", w.Code, w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/things/1", nil))
+	if w.Header().Get("Deprecation") != "" {
+		t.Errorf("GET /things/1 is not deprecated, Deprecation = %q", w.Header().Get("Deprecation"))
+	}
+}
+
+func TestPolicy_Retirement(t *testing.T) {
+	p, err := NewPolicy(testRoutes(), WithRetirement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := setupRouter(p)
+
+	p.now = func() time.Time { return sunset.Add(-time.Second) }
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/items/1", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("before sunset: status = %d, want 200", w.Code)
+	}
+
+	p.now = func() time.Time { return sunset }
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/items/1", nil))
+	if w.Code != http.StatusGone || w.Header().Get("Sunset") == "" {
+		t.Errorf("at sunset: status = %d, headers = %v", w.Code, w.Header())
+	}
+
+	// Without a sunset, a route is never retired
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/items", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("POST /items: status = %d, want 200", w.Code)
+	}
+}
+
+func TestPolicy_List(t *testing.T) {
+	p, err := NewPolicy(testRoutes(), WithRetirement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.now = func() time.Time { return sunset }
+
+	got := p.List()
+	if len(got) != 2 {
+		t.Fatalf("List() = %+v", got)
+	}
+	if got[0].Method != "GET" || got[0].Path != "/items/:id" || !got[0].Gone || got[0].Replacement != "GET /things/:id" {
+		t.Errorf("List()[0] = %+v", got[0])
+	}
+	if got[1].Gone || got[1].message() != "post to /things" {
+		t.Errorf("List()[1] = %+v", got[1])
+	}
+}
@@ -0,0 +1,107 @@
+package devices
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ninenine/babytrack/internal/occurrence"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers device management for signed-in family members
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.list)
+	rg.POST("", h.register)
+	rg.DELETE("/:id", h.delete)
+}
+
+// RegisterIngestRoutes registers the endpoints devices call with their own API key
+func (h *Handler) RegisterIngestRoutes(rg *gin.RouterGroup) {
+	rg.POST("/:id/measurements", h.pushMeasurements)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	childID := c.Query("child_id")
+	if childID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "child_id is required"})
+		return
+	}
+
+	devices, err := h.service.List(c.Request.Context(), c.GetString("user_id"), childID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, devices)
+}
+
+func (h *Handler) register(c *gin.Context) {
+	var req RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	device, err := h.service.Register(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, device)
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) pushMeasurements(c *gin.Context) {
+	device, err := h.service.Authenticate(c.Request.Context(), c.Param("id"), c.GetHeader(HeaderAPIKey))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var req PushMeasurementsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.Ingest(c.Request.Context(), device, req.Measurements)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrDeviceNotFound), errors.Is(err, ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidAPIKey):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidDeviceType), errors.Is(err, ErrUnsupportedReading),
+		errors.Is(err, ErrInvalidMeasurement), errors.Is(err, occurrence.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTooManyMeasurements):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,167 @@
+package devices
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	registerFn     func(ctx context.Context, userID string, req *RegisterDeviceRequest) (*Device, error)
+	listFn         func(ctx context.Context, userID, childID string) ([]Device, error)
+	deleteFn       func(ctx context.Context, userID, id string) error
+	authenticateFn func(ctx context.Context, id, apiKey string) (*Device, error)
+	ingestFn       func(ctx context.Context, device *Device, measurements []Measurement) (*PushMeasurementsResponse, error)
+}
+
+func (m *mockService) Register(ctx context.Context, userID string, req *RegisterDeviceRequest) (*Device, error) {
+	if m.registerFn != nil {
+		return m.registerFn(ctx, userID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) List(ctx context.Context, userID, childID string) ([]Device, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, childID)
+	}
+	return []Device{}, nil
+}
+
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
+	if m.deleteFn != nil {
+		return m.deleteFn(ctx, userID, id)
+	}
+	return nil
+}
+
+func (m *mockService) Authenticate(ctx context.Context, id, apiKey string) (*Device, error) {
+	if m.authenticateFn != nil {
+		return m.authenticateFn(ctx, id, apiKey)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Ingest(ctx context.Context, device *Device, measurements []Measurement) (*PushMeasurementsResponse, error) {
+	if m.ingestFn != nil {
+		return m.ingestFn(ctx, device, measurements)
+	}
+	return &PushMeasurementsResponse{}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	handler.RegisterIngestRoutes(router.Group("/devices"))
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterRoutes(protected.Group("/devices"))
+	return router
+}
+
+func TestRegister_Success(t *testing.T) {
+	svc := &mockService{
+		registerFn: func(ctx context.Context, userID string, req *RegisterDeviceRequest) (*Device, error) {
+			return &Device{ID: "dev-1", ChildID: req.ChildID, Type: req.Type, APIKey: "key"}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"child_id":"child-1","type":"smart_scale","name":"Nursery scale"}`
+	req := httptest.NewRequest("POST", "/devices", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", w.Code)
+	}
+}
+
+func TestPushMeasurements_Success(t *testing.T) {
+	var gotKey string
+	svc := &mockService{
+		authenticateFn: func(ctx context.Context, id, apiKey string) (*Device, error) {
+			gotKey = apiKey
+			return &Device{ID: id, ChildID: "child-1", Type: DeviceSmartScale}, nil
+		},
+		ingestFn: func(ctx context.Context, device *Device, measurements []Measurement) (*PushMeasurementsResponse, error) {
+			if len(measurements) != 1 || *measurements[0].WeightKg != 5.4 {
+				t.Errorf("Unexpected measurements %+v", measurements)
+			}
+			return &PushMeasurementsResponse{Records: []IngestedRecord{{Kind: MeasurementWeight, RecordID: "growth-1"}}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"measurements":[{"kind":"weight","weight_kg":5.4}]}`
+	req := httptest.NewRequest("POST", "/devices/dev-1/measurements", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderAPIKey, "secret-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotKey != "secret-key" {
+		t.Errorf("Expected api key from header, got %q", gotKey)
+	}
+}
+
+func TestPushMeasurements_BadKey(t *testing.T) {
+	svc := &mockService{
+		authenticateFn: func(ctx context.Context, id, apiKey string) (*Device, error) {
+			return nil, ErrInvalidAPIKey
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/devices/dev-1/measurements", bytes.NewBufferString(`{"measurements":[{"kind":"weight"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+func TestPushMeasurements_UnsupportedReading(t *testing.T) {
+	svc := &mockService{
+		authenticateFn: func(ctx context.Context, id, apiKey string) (*Device, error) {
+			return &Device{ID: id, Type: DeviceSmartScale}, nil
+		},
+		ingestFn: func(ctx context.Context, device *Device, measurements []Measurement) (*PushMeasurementsResponse, error) {
+			return nil, ErrUnsupportedReading
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/devices/dev-1/measurements", bytes.NewBufferString(`{"measurements":[{"kind":"sleep_epoch"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderAPIKey, "k")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
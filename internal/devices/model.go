@@ -0,0 +1,64 @@
+package devices
+
+import "time"
+
+type DeviceType string
+
+const (
+	DeviceSmartScale   DeviceType = "smart_scale"
+	DeviceSleepMonitor DeviceType = "sleep_monitor"
+)
+
+type MeasurementKind string
+
+const (
+	MeasurementWeight     MeasurementKind = "weight"
+	MeasurementSleepEpoch MeasurementKind = "sleep_epoch"
+)
+
+// HeaderAPIKey carries the device API key on measurement pushes
+const HeaderAPIKey = "X-Device-Key"
+
+// Device is a piece of hardware bound to one child. The API key is only
+// returned when the device is registered.
+type Device struct {
+	ID         string     `json:"id"`
+	ChildID    string     `json:"child_id"`
+	Type       DeviceType `json:"type"`
+	Name       string     `json:"name"`
+	APIKey     string     `json:"api_key,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+type RegisterDeviceRequest struct {
+	ChildID string     `json:"child_id" binding:"required"`
+	Type    DeviceType `json:"type" binding:"required"`
+	Name    string     `json:"name" binding:"required"`
+}
+
+// Measurement is one reading pushed by a device. Weight readings set
+// WeightKg; sleep epochs set StartTime and EndTime.
+type Measurement struct {
+	Kind       MeasurementKind `json:"kind" binding:"required"`
+	MeasuredAt *time.Time      `json:"measured_at,omitempty"`
+	WeightKg   *float64        `json:"weight_kg,omitempty"`
+	StartTime  *time.Time      `json:"start_time,omitempty"`
+	EndTime    *time.Time      `json:"end_time,omitempty"`
+	Night      bool            `json:"night,omitempty"`
+}
+
+type PushMeasurementsRequest struct {
+	Measurements []Measurement `json:"measurements" binding:"required,min=1,dive"`
+}
+
+// IngestedRecord points at the growth or sleep record a measurement became
+type IngestedRecord struct {
+	Kind     MeasurementKind `json:"kind"`
+	RecordID string          `json:"record_id"`
+}
+
+type PushMeasurementsResponse struct {
+	Records []IngestedRecord `json:"records"`
+}
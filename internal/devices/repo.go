@@ -0,0 +1,126 @@
+package devices
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, device *Device, apiKeyHash string) error
+	GetByID(ctx context.Context, id string) (*Device, error)
+	GetKeyHash(ctx context.Context, id string) (string, error)
+	ListByChild(ctx context.Context, childID string) ([]Device, error)
+	TouchLastSeen(ctx context.Context, id string, seenAt time.Time) error
+	Delete(ctx context.Context, id string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the devices table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "devices")
+}
+
+func (r *repository) Create(ctx context.Context, device *Device, apiKeyHash string) error {
+	query := `
+		INSERT INTO devices (id, child_id, type, name, api_key_hash, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		device.ID, device.ChildID, device.Type, device.Name, apiKeyHash, device.CreatedBy, device.CreatedAt,
+	)
+	return err
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Device, error) {
+	query := `
+		SELECT id, child_id, type, name, created_by, created_at, last_seen_at
+		FROM devices
+		WHERE id = $1
+	`
+
+	var d Device
+	var lastSeenAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&d.ID, &d.ChildID, &d.Type, &d.Name, &d.CreatedBy, &d.CreatedAt, &lastSeenAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lastSeenAt.Valid {
+		d.LastSeenAt = &lastSeenAt.Time
+	}
+
+	return &d, nil
+}
+
+// GetKeyHash returns an empty string when the device does not exist
+func (r *repository) GetKeyHash(ctx context.Context, id string) (string, error) {
+	var hash string
+	err := r.db.QueryRowContext(ctx, `SELECT api_key_hash FROM devices WHERE id = $1`, id).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return hash, err
+}
+
+func (r *repository) ListByChild(ctx context.Context, childID string) ([]Device, error) {
+	query := `
+		SELECT id, child_id, type, name, created_by, created_at, last_seen_at
+		FROM devices
+		WHERE child_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, childID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	devices := []Device{}
+	for rows.Next() {
+		var d Device
+		var lastSeenAt sql.NullTime
+
+		if err := rows.Scan(
+			&d.ID, &d.ChildID, &d.Type, &d.Name, &d.CreatedBy, &d.CreatedAt, &lastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if lastSeenAt.Valid {
+			d.LastSeenAt = &lastSeenAt.Time
+		}
+
+		devices = append(devices, d)
+	}
+
+	return devices, rows.Err()
+}
+
+func (r *repository) TouchLastSeen(ctx context.Context, id string, seenAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE devices SET last_seen_at = $2 WHERE id = $1`, id, seenAt)
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM devices WHERE id = $1`, id)
+	return err
+}
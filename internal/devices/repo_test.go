@@ -0,0 +1,78 @@
+package devices
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	d := &Device{ID: "dev-1", ChildID: "child-1", Type: DeviceSmartScale, Name: "Nursery scale", CreatedBy: "user-1", CreatedAt: now}
+
+	mock.ExpectExec("INSERT INTO devices").
+		WithArgs("dev-1", "child-1", DeviceSmartScale, "Nursery scale", "hash", "user-1", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), d, "hash"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetKeyHash_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT api_key_hash FROM devices").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	hash, err := repo.GetKeyHash(context.Background(), "missing")
+	if err != nil || hash != "" {
+		t.Errorf("GetKeyHash() = %q, %v; want empty", hash, err)
+	}
+}
+
+func TestRepository_ListByChild(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "name", "created_by", "created_at", "last_seen_at"}).
+		AddRow("dev-1", "child-1", "smart_scale", "Nursery scale", "user-1", now, now).
+		AddRow("dev-2", "child-1", "sleep_monitor", "Cot monitor", "user-1", now, nil)
+
+	mock.ExpectQuery("SELECT id, child_id, type, name").
+		WithArgs("child-1").
+		WillReturnRows(rows)
+
+	devices, err := repo.ListByChild(context.Background(), "child-1")
+	if err != nil {
+		t.Fatalf("ListByChild() error = %v", err)
+	}
+	if len(devices) != 2 || devices[0].LastSeenAt == nil || devices[1].LastSeenAt != nil {
+		t.Errorf("ListByChild() = %+v", devices)
+	}
+}
@@ -0,0 +1,267 @@
+package devices
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/sleep"
+)
+
+var (
+	ErrDeviceNotFound      = errors.New("device not found")
+	ErrChildNotFound       = errors.New("child not found")
+	ErrNotMember           = errors.New("user is not a member of this family")
+	ErrInvalidDeviceType   = errors.New("invalid device type")
+	ErrInvalidAPIKey       = errors.New("invalid device api key")
+	ErrUnsupportedReading  = errors.New("device does not report this kind of measurement")
+	ErrInvalidMeasurement  = errors.New("invalid measurement")
+	ErrTooManyMeasurements = errors.New("too many measurements in one push")
+)
+
+// supportedReadings lists what each device type is allowed to push
+var supportedReadings = map[DeviceType]MeasurementKind{
+	DeviceSmartScale:   MeasurementWeight,
+	DeviceSleepMonitor: MeasurementSleepEpoch,
+}
+
+type Service interface {
+	Register(ctx context.Context, userID string, req *RegisterDeviceRequest) (*Device, error)
+	List(ctx context.Context, userID, childID string) ([]Device, error)
+	Delete(ctx context.Context, userID, id string) error
+	Authenticate(ctx context.Context, id, apiKey string) (*Device, error)
+	Ingest(ctx context.Context, device *Device, measurements []Measurement) (*PushMeasurementsResponse, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	growthService growth.Service
+	sleepService  sleep.Service
+}
+
+func NewService(repo Repository, familyService family.Service, growthService growth.Service, sleepService sleep.Service) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+		growthService: growthService,
+		sleepService:  sleepService,
+	}
+}
+
+// HealthCheck reports whether registered devices can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Register(ctx context.Context, userID string, req *RegisterDeviceRequest) (*Device, error) {
+	if _, ok := supportedReadings[req.Type]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDeviceType, req.Type)
+	}
+
+	if err := s.requireChildAccess(ctx, userID, req.ChildID); err != nil {
+		return nil, err
+	}
+
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	device := &Device{
+		ID:        ids.New(),
+		ChildID:   req.ChildID,
+		Type:      req.Type,
+		Name:      strings.TrimSpace(req.Name),
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repo.Create(ctx, device, hashKey(apiKey)); err != nil {
+		return nil, fmt.Errorf("failed to register device: %w", err)
+	}
+
+	device.APIKey = apiKey
+	return device, nil
+}
+
+func (s *service) List(ctx context.Context, userID, childID string) ([]Device, error) {
+	if err := s.requireChildAccess(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	devices, err := s.repo.ListByChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	return devices, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	device, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get device: %w", err)
+	}
+	if device == nil {
+		return ErrDeviceNotFound
+	}
+
+	if err := s.requireChildAccess(ctx, userID, device.ChildID); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) Authenticate(ctx context.Context, id, apiKey string) (*Device, error) {
+	if apiKey == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	hash, err := s.repo.GetKeyHash(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+	if hash == "" || subtle.ConstantTimeCompare([]byte(hash), []byte(hashKey(apiKey))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	device, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+	if device == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.repo.TouchLastSeen(ctx, id, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to update device: %w", err)
+	}
+
+	return device, nil
+}
+
+// Ingest maps device readings onto growth and sleep records tagged with the
+// device as their source. Every reading is validated before any is stored so
+// a malformed push does not leave half its readings behind.
+func (s *service) Ingest(ctx context.Context, device *Device, measurements []Measurement) (*PushMeasurementsResponse, error) {
+	if limit := limits.Get().BulkItems; len(measurements) > limit {
+		return nil, fmt.Errorf("%w: at most %d", ErrTooManyMeasurements, limit)
+	}
+
+	for i := range measurements {
+		if err := validateMeasurement(device, &measurements[i]); err != nil {
+			return nil, fmt.Errorf("measurement %d: %w", i, err)
+		}
+	}
+
+	source := "device:" + device.ID
+	resp := &PushMeasurementsResponse{Records: make([]IngestedRecord, 0, len(measurements))}
+
+	for _, m := range measurements {
+		var recordID string
+
+		switch m.Kind {
+		case MeasurementWeight:
+			created, err := s.growthService.Create(ctx, &growth.CreateMeasurementRequest{
+				ChildID:    device.ChildID,
+				MeasuredAt: *m.MeasuredAt,
+				WeightKg:   m.WeightKg,
+				Source:     source,
+			})
+			if err != nil {
+				return resp, fmt.Errorf("failed to store weight: %w", err)
+			}
+			recordID = created.ID
+
+		case MeasurementSleepEpoch:
+			sleepType := sleep.SleepTypeNap
+			if m.Night {
+				sleepType = sleep.SleepTypeNight
+			}
+			created, err := s.sleepService.Create(ctx, &sleep.CreateSleepRequest{
+				ChildID:   device.ChildID,
+				Type:      sleepType,
+				StartTime: *m.StartTime,
+				EndTime:   m.EndTime,
+				Source:    source,
+			})
+			if err != nil {
+				return resp, fmt.Errorf("failed to store sleep: %w", err)
+			}
+			recordID = created.ID
+		}
+
+		resp.Records = append(resp.Records, IngestedRecord{Kind: m.Kind, RecordID: recordID})
+	}
+
+	return resp, nil
+}
+
+func validateMeasurement(device *Device, m *Measurement) error {
+	if supportedReadings[device.Type] != m.Kind {
+		return fmt.Errorf("%w: %s sent %s", ErrUnsupportedReading, device.Type, m.Kind)
+	}
+
+	switch m.Kind {
+	case MeasurementWeight:
+		if m.WeightKg == nil || *m.WeightKg <= 0 {
+			return fmt.Errorf("%w: weight_kg must be positive", ErrInvalidMeasurement)
+		}
+		if m.MeasuredAt == nil {
+			now := time.Now()
+			m.MeasuredAt = &now
+		}
+		return occurrence.Validate(*m.MeasuredAt, time.Now())
+	case MeasurementSleepEpoch:
+		if m.StartTime == nil || m.EndTime == nil || !m.EndTime.After(*m.StartTime) {
+			return fmt.Errorf("%w: sleep epochs need start_time before end_time", ErrInvalidMeasurement)
+		}
+		return occurrence.ValidateRange(*m.StartTime, m.EndTime, time.Now())
+	}
+
+	return nil
+}
+
+func (s *service) requireChildAccess(ctx context.Context, userID, childID string) error {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return ErrChildNotFound
+	}
+
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	return nil
+}
+
+func hashKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,220 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/sleep"
+)
+
+type mockRepository struct {
+	devices map[string]*Device
+	hashes  map[string]string
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		devices: make(map[string]*Device),
+		hashes:  make(map[string]string),
+	}
+}
+
+func (m *mockRepository) Create(ctx context.Context, device *Device, apiKeyHash string) error {
+	copied := *device
+	m.devices[device.ID] = &copied
+	m.hashes[device.ID] = apiKeyHash
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Device, error) {
+	if d, ok := m.devices[id]; ok {
+		copied := *d
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) GetKeyHash(ctx context.Context, id string) (string, error) {
+	return m.hashes[id], nil
+}
+
+func (m *mockRepository) ListByChild(ctx context.Context, childID string) ([]Device, error) {
+	result := []Device{}
+	for _, d := range m.devices {
+		if d.ChildID == childID {
+			result = append(result, *d)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) TouchLastSeen(ctx context.Context, id string, seenAt time.Time) error {
+	m.devices[id].LastSeenAt = &seenAt
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	delete(m.devices, id)
+	delete(m.hashes, id)
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" {
+		return &family.Child{ID: "child-1", FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if familyID == "family-1" && userID == "user-1" {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockGrowthService struct {
+	growth.Service
+	created []*growth.CreateMeasurementRequest
+}
+
+func (m *mockGrowthService) Create(ctx context.Context, req *growth.CreateMeasurementRequest) (*growth.Measurement, error) {
+	m.created = append(m.created, req)
+	return &growth.Measurement{ID: fmt.Sprintf("growth-%d", len(m.created))}, nil
+}
+
+type mockSleepService struct {
+	sleep.Service
+	created []*sleep.CreateSleepRequest
+}
+
+func (m *mockSleepService) Create(ctx context.Context, req *sleep.CreateSleepRequest) (*sleep.Sleep, error) {
+	m.created = append(m.created, req)
+	return &sleep.Sleep{ID: fmt.Sprintf("sleep-%d", len(m.created))}, nil
+}
+
+func newTestService() (Service, *mockRepository, *mockGrowthService, *mockSleepService) {
+	repo := newMockRepository()
+	growthSvc := &mockGrowthService{}
+	sleepSvc := &mockSleepService{}
+	return NewService(repo, &mockFamilyService{}, growthSvc, sleepSvc), repo, growthSvc, sleepSvc
+}
+
+func TestService_RegisterAndAuthenticate(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+
+	device, err := svc.Register(context.Background(), "user-1", &RegisterDeviceRequest{
+		ChildID: "child-1", Type: DeviceSmartScale, Name: "Nursery scale",
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if device.APIKey == "" {
+		t.Fatal("Register() should return the api key")
+	}
+	if repo.hashes[device.ID] == device.APIKey {
+		t.Error("Register() should store only a hash of the api key")
+	}
+
+	authed, err := svc.Authenticate(context.Background(), device.ID, device.APIKey)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if authed.APIKey != "" || repo.devices[device.ID].LastSeenAt == nil {
+		t.Errorf("Authenticate() = %+v, want key hidden and last seen recorded", authed)
+	}
+
+	if _, err := svc.Authenticate(context.Background(), device.ID, "wrong"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() with wrong key error = %v, want ErrInvalidAPIKey", err)
+	}
+	if _, err := svc.Authenticate(context.Background(), "missing", device.APIKey); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() unknown device error = %v, want ErrInvalidAPIKey", err)
+	}
+}
+
+func TestService_Register_Validation(t *testing.T) {
+	svc, _, _, _ := newTestService()
+
+	tests := []struct {
+		name    string
+		userID  string
+		req     *RegisterDeviceRequest
+		wantErr error
+	}{
+		{"bad type", "user-1", &RegisterDeviceRequest{ChildID: "child-1", Type: "toaster", Name: "x"}, ErrInvalidDeviceType},
+		{"unknown child", "user-1", &RegisterDeviceRequest{ChildID: "child-9", Type: DeviceSmartScale, Name: "x"}, ErrChildNotFound},
+		{"not a member", "stranger", &RegisterDeviceRequest{ChildID: "child-1", Type: DeviceSmartScale, Name: "x"}, ErrNotMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Register(context.Background(), tt.userID, tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Register() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_Ingest_Scale(t *testing.T) {
+	svc, _, growthSvc, _ := newTestService()
+	device := &Device{ID: "dev-1", ChildID: "child-1", Type: DeviceSmartScale}
+	weight := 5.42
+
+	resp, err := svc.Ingest(context.Background(), device, []Measurement{{Kind: MeasurementWeight, WeightKg: &weight}})
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	if len(resp.Records) != 1 || resp.Records[0].RecordID != "growth-1" {
+		t.Errorf("Ingest() = %+v", resp)
+	}
+	got := growthSvc.created[0]
+	if got.ChildID != "child-1" || got.Source != "device:dev-1" || got.MeasuredAt.IsZero() {
+		t.Errorf("Ingest() growth request = %+v", got)
+	}
+}
+
+func TestService_Ingest_SleepMonitor(t *testing.T) {
+	svc, _, _, sleepSvc := newTestService()
+	device := &Device{ID: "dev-2", ChildID: "child-1", Type: DeviceSleepMonitor}
+	start := time.Date(2024, 6, 1, 19, 30, 0, 0, time.UTC)
+	end := start.Add(10 * time.Hour)
+
+	if _, err := svc.Ingest(context.Background(), device, []Measurement{
+		{Kind: MeasurementSleepEpoch, StartTime: &start, EndTime: &end, Night: true},
+	}); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	got := sleepSvc.created[0]
+	if got.Type != sleep.SleepTypeNight || got.Source != "device:dev-2" || !got.EndTime.Equal(end) {
+		t.Errorf("Ingest() sleep request = %+v", got)
+	}
+}
+
+func TestService_Ingest_RejectsWholePush(t *testing.T) {
+	svc, _, growthSvc, _ := newTestService()
+	device := &Device{ID: "dev-1", ChildID: "child-1", Type: DeviceSmartScale}
+	weight := 5.4
+	start := time.Now()
+
+	_, err := svc.Ingest(context.Background(), device, []Measurement{
+		{Kind: MeasurementWeight, WeightKg: &weight},
+		{Kind: MeasurementSleepEpoch, StartTime: &start, EndTime: &start},
+	})
+	if !errors.Is(err, ErrUnsupportedReading) {
+		t.Errorf("Ingest() error = %v, want ErrUnsupportedReading", err)
+	}
+	if len(growthSvc.created) != 0 {
+		t.Error("Ingest() should not store anything when a reading is invalid")
+	}
+}
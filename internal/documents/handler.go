@@ -0,0 +1,107 @@
+package documents
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterChildRoutes registers document listing and creation on the children group
+func (h *Handler) RegisterChildRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id/documents", h.listByChild)
+	rg.POST("/:id/documents", h.create)
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id", h.get)
+	rg.PUT("/:id", h.update)
+	rg.DELETE("/:id", h.delete)
+}
+
+func (h *Handler) listByChild(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	docs, err := h.service.List(c.Request.Context(), userID, c.Param("id"), Category(c.Query("category")))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, docs)
+}
+
+func (h *Handler) create(c *gin.Context) {
+	var req DocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	d, err := h.service.Create(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, d)
+}
+
+func (h *Handler) get(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	d, err := h.service.Get(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+func (h *Handler) update(c *gin.Context) {
+	var req DocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	d, err := h.service.Update(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.service.Delete(c.Request.Context(), userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrDocumentNotFound), errors.Is(err, ErrChildNotFound), errors.Is(err, ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidCategory), errors.Is(err, ErrInvalidDate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
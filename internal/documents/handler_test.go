@@ -0,0 +1,157 @@
+package documents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	createFn func(ctx context.Context, userID, childID string, req *DocumentRequest) (*Document, error)
+	getFn    func(ctx context.Context, userID, id string) (*Document, error)
+	listFn   func(ctx context.Context, userID, childID string, category Category) ([]Document, error)
+	updateFn func(ctx context.Context, userID, id string, req *DocumentRequest) (*Document, error)
+	deleteFn func(ctx context.Context, userID, id string) error
+}
+
+func (m *mockService) Create(ctx context.Context, userID, childID string, req *DocumentRequest) (*Document, error) {
+	return m.createFn(ctx, userID, childID, req)
+}
+
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Document, error) {
+	return m.getFn(ctx, userID, id)
+}
+
+func (m *mockService) List(ctx context.Context, userID, childID string, category Category) ([]Document, error) {
+	return m.listFn(ctx, userID, childID, category)
+}
+
+func (m *mockService) Update(ctx context.Context, userID, id string, req *DocumentRequest) (*Document, error) {
+	return m.updateFn(ctx, userID, id, req)
+}
+
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
+	return m.deleteFn(ctx, userID, id)
+}
+
+func (m *mockService) DueReminders(ctx context.Context, now time.Time) ([]Document, error) {
+	return nil, nil
+}
+
+func (m *mockService) MarkReminded(ctx context.Context, d *Document) error {
+	return nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	h := NewHandler(svc)
+	h.RegisterChildRoutes(router.Group("/children"))
+	h.RegisterRoutes(router.Group("/documents"))
+	return router
+}
+
+func TestHandler_ListByChild(t *testing.T) {
+	svc := &mockService{listFn: func(ctx context.Context, userID, childID string, category Category) ([]Document, error) {
+		if userID != "test-user-123" || childID != "child-1" || category != CategoryCustody {
+			t.Errorf("List() called with %s, %s, %s", userID, childID, category)
+		}
+		return []Document{{ID: "doc-1", Category: CategoryCustody, Status: StatusValid}}, nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("GET", "/children/child-1/documents?category=custody", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var got []Document
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || len(got) != 1 {
+		t.Errorf("Expected one document, got %s", w.Body.String())
+	}
+}
+
+func TestHandler_Create(t *testing.T) {
+	svc := &mockService{createFn: func(ctx context.Context, userID, childID string, req *DocumentRequest) (*Document, error) {
+		return &Document{ID: "doc-1", ChildID: childID, MediaID: req.MediaID, Category: req.Category, Title: req.Title}, nil
+	}}
+
+	body, _ := json.Marshal(DocumentRequest{MediaID: "media-1", Category: CategoryConsent, Title: "School trip consent"})
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("POST", "/children/child-1/documents", bytes.NewReader(body)))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+}
+
+func TestHandler_Create_MissingFields(t *testing.T) {
+	w := httptest.NewRecorder()
+	setupRouter(&mockService{}).ServeHTTP(w, httptest.NewRequest("POST", "/children/child-1/documents", bytes.NewReader([]byte(`{"title":"x"}`))))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"not found", ErrDocumentNotFound, http.StatusNotFound},
+		{"not member", ErrNotMember, http.StatusForbidden},
+		{"media not found", ErrMediaNotFound, http.StatusNotFound},
+		{"invalid date", ErrInvalidDate, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{updateFn: func(ctx context.Context, userID, id string, req *DocumentRequest) (*Document, error) {
+				return nil, tt.err
+			}}
+
+			body, _ := json.Marshal(DocumentRequest{MediaID: "media-1", Category: CategoryMedical, Title: "Allergy plan"})
+			w := httptest.NewRecorder()
+			setupRouter(svc).ServeHTTP(w, httptest.NewRequest("PUT", "/documents/doc-1", bytes.NewReader(body)))
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_Delete(t *testing.T) {
+	svc := &mockService{deleteFn: func(ctx context.Context, userID, id string) error {
+		if id != "doc-1" {
+			t.Errorf("Delete() called with %s", id)
+		}
+		return nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("DELETE", "/documents/doc-1", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+}
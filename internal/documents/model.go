@@ -0,0 +1,63 @@
+package documents
+
+import "time"
+
+// Category groups a child's paperwork
+type Category string
+
+const (
+	CategoryInsurance Category = "insurance"
+	CategoryCustody   Category = "custody"
+	CategoryConsent   Category = "consent"
+	CategoryIdentity  Category = "identity"
+	CategoryMedical   Category = "medical"
+	CategoryOther     Category = "other"
+)
+
+var validCategories = map[Category]bool{
+	CategoryInsurance: true,
+	CategoryCustody:   true,
+	CategoryConsent:   true,
+	CategoryIdentity:  true,
+	CategoryMedical:   true,
+	CategoryOther:     true,
+}
+
+type Status string
+
+const (
+	StatusValid    Status = "valid"
+	StatusExpiring Status = "expiring" // Expires within ReminderLeadDays
+	StatusExpired  Status = "expired"
+)
+
+// ReminderLeadDays is how many days before its expiry a document is flagged
+// as expiring and the family is reminded
+const ReminderLeadDays = 30
+
+// Document is a file from the media store kept with a child's paperwork, such
+// as an insurance card or a signed consent form.
+type Document struct {
+	ID        string   `json:"id"`
+	ChildID   string   `json:"child_id"`
+	MediaID   string   `json:"media_id"`
+	Category  Category `json:"category"`
+	Title     string   `json:"title"`
+	Notes     string   `json:"notes,omitempty"`
+	ExpiresOn string   `json:"expires_on,omitempty"` // YYYY-MM-DD
+	Status    Status   `json:"status"`
+	// RemindedFor is the expiry date the last reminder was sent for, so a new
+	// expiry date gets a reminder of its own
+	RemindedFor string    `json:"-"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type DocumentRequest struct {
+	MediaID   string   `json:"media_id" binding:"required"`
+	Category  Category `json:"category" binding:"required"`
+	Title     string   `json:"title" binding:"required"`
+	Notes     string   `json:"notes"`
+	ExpiresOn string   `json:"expires_on"`
+}
@@ -0,0 +1,153 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, d *Document) error
+	GetByID(ctx context.Context, id string) (*Document, error)
+	// ListByChild returns the child's documents, in one category unless it is empty
+	ListByChild(ctx context.Context, childID string, category Category) ([]Document, error)
+	Update(ctx context.Context, d *Document) error
+	Delete(ctx context.Context, id string) error
+	// ListUnreminded returns documents expiring between from and to that have
+	// not been reminded about for their current expiry date
+	ListUnreminded(ctx context.Context, from, to string) ([]Document, error)
+	MarkReminded(ctx context.Context, id, expiresOn string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the documents table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "child_documents")
+}
+
+const documentColumns = `id, child_id, media_id, category, title, notes, expires_on, reminded_for, COALESCE(created_by, ''), created_at, updated_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDocument(row scanner) (*Document, error) {
+	var d Document
+	var expiresOn, remindedFor sql.NullTime
+
+	if err := row.Scan(
+		&d.ID, &d.ChildID, &d.MediaID, &d.Category, &d.Title, &d.Notes, &expiresOn, &remindedFor, &d.CreatedBy, &d.CreatedAt, &d.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if expiresOn.Valid {
+		d.ExpiresOn = expiresOn.Time.Format(time.DateOnly)
+	}
+	if remindedFor.Valid {
+		d.RemindedFor = remindedFor.Time.Format(time.DateOnly)
+	}
+	return &d, nil
+}
+
+func (r *repository) Create(ctx context.Context, d *Document) error {
+	query := `
+		INSERT INTO child_documents (id, child_id, media_id, category, title, notes, expires_on, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		d.ID, d.ChildID, d.MediaID, d.Category, d.Title, d.Notes, nullString(d.ExpiresOn), nullString(d.CreatedBy), d.CreatedAt, d.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Document, error) {
+	d, err := scanDocument(r.db.QueryRowContext(ctx, `SELECT `+documentColumns+` FROM child_documents WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return d, err
+}
+
+func (r *repository) ListByChild(ctx context.Context, childID string, category Category) ([]Document, error) {
+	query := `SELECT ` + documentColumns + ` FROM child_documents WHERE child_id = $1`
+	args := []any{childID}
+
+	if category != "" {
+		query += fmt.Sprintf(` AND category = $%d`, len(args)+1)
+		args = append(args, category)
+	}
+	query += ` ORDER BY category, title, created_at DESC`
+
+	return r.list(ctx, query, args...)
+}
+
+func (r *repository) Update(ctx context.Context, d *Document) error {
+	query := `
+		UPDATE child_documents
+		SET media_id = $2, category = $3, title = $4, notes = $5, expires_on = $6, updated_at = $7
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		d.ID, d.MediaID, d.Category, d.Title, d.Notes, nullString(d.ExpiresOn), d.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM child_documents WHERE id = $1`, id)
+	return err
+}
+
+func (r *repository) ListUnreminded(ctx context.Context, from, to string) ([]Document, error) {
+	query := `
+		SELECT ` + documentColumns + `
+		FROM child_documents
+		WHERE expires_on BETWEEN $1 AND $2
+		  AND (reminded_for IS NULL OR reminded_for <> expires_on)
+		ORDER BY expires_on
+	`
+
+	return r.list(ctx, query, from, to)
+}
+
+func (r *repository) MarkReminded(ctx context.Context, id, expiresOn string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE child_documents SET reminded_for = $2 WHERE id = $1`, id, expiresOn)
+	return err
+}
+
+func (r *repository) list(ctx context.Context, query string, args ...any) ([]Document, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	docs := []Document{}
+	for rows.Next() {
+		d, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, *d)
+	}
+
+	return docs, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
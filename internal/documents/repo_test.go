@@ -0,0 +1,97 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var documentRowColumns = []string{"id", "child_id", "media_id", "category", "title", "notes", "expires_on", "reminded_for", "created_by", "created_at", "updated_at"}
+
+func TestRepository_ListByChild(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	expires := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, child_id, media_id, category, title, notes, expires_on, reminded_for, .* FROM child_documents WHERE child_id = \\$1 AND category = \\$2").
+		WithArgs("child-1", CategoryInsurance).
+		WillReturnRows(sqlmock.NewRows(documentRowColumns).
+			AddRow("doc-1", "child-1", "media-1", "insurance", "Insurance card", "", expires, nil, "user-1", now, now).
+			AddRow("doc-2", "child-1", "media-2", "insurance", "Dental plan", "Family plan", nil, nil, "", now, now))
+
+	docs, err := repo.ListByChild(context.Background(), "child-1", CategoryInsurance)
+	if err != nil {
+		t.Fatalf("ListByChild() error = %v", err)
+	}
+	if len(docs) != 2 || docs[0].ExpiresOn != "2024-09-01" || docs[1].ExpiresOn != "" || docs[1].Notes != "Family plan" {
+		t.Errorf("ListByChild() = %+v", docs)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT .* FROM child_documents WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(documentRowColumns))
+
+	d, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || d != nil {
+		t.Errorf("GetByID() = %v, %v; want nil, nil", d, err)
+	}
+}
+
+func TestRepository_ListUnreminded(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	expires := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	reminded := time.Date(2023, 5, 20, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("WHERE expires_on BETWEEN \\$1 AND \\$2 AND \\(reminded_for IS NULL OR reminded_for <> expires_on\\)").
+		WithArgs("2024-05-01", "2024-05-31").
+		WillReturnRows(sqlmock.NewRows(documentRowColumns).
+			AddRow("doc-1", "child-1", "media-1", "identity", "Passport", "", expires, reminded, "user-1", now, now))
+
+	docs, err := repo.ListUnreminded(context.Background(), "2024-05-01", "2024-05-31")
+	if err != nil {
+		t.Fatalf("ListUnreminded() error = %v", err)
+	}
+	if len(docs) != 1 || docs[0].RemindedFor != "2023-05-20" {
+		t.Errorf("ListUnreminded() = %+v", docs)
+	}
+}
+
+func TestRepository_MarkReminded(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectExec("UPDATE child_documents SET reminded_for = \\$2 WHERE id = \\$1").
+		WithArgs("doc-1", "2024-05-20").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.MarkReminded(context.Background(), "doc-1", "2024-05-20"); err != nil {
+		t.Fatalf("MarkReminded() error = %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+// Package documents keeps a child's paperwork, such as insurance cards,
+// custody orders and consent forms, with the file itself in the media store.
+// Documents can carry an expiry date, and the family is reminded ahead of it.
+package documents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/media"
+)
+
+var (
+	ErrDocumentNotFound = errors.New("document not found")
+	ErrChildNotFound    = errors.New("child not found")
+	ErrMediaNotFound    = errors.New("media not found")
+	ErrNotMember        = errors.New("user is not a member of this family")
+	ErrInvalidCategory  = errors.New("category must be one of insurance, custody, consent, identity, medical or other")
+	ErrInvalidDate      = errors.New("expires_on must be YYYY-MM-DD")
+)
+
+type Service interface {
+	Create(ctx context.Context, userID, childID string, req *DocumentRequest) (*Document, error)
+	Get(ctx context.Context, userID, id string) (*Document, error)
+	// List returns the child's documents, in one category unless it is empty
+	List(ctx context.Context, userID, childID string, category Category) ([]Document, error)
+	Update(ctx context.Context, userID, id string, req *DocumentRequest) (*Document, error)
+	Delete(ctx context.Context, userID, id string) error
+	// DueReminders returns documents expiring within ReminderLeadDays of now
+	// that have not been reminded about for their current expiry date
+	DueReminders(ctx context.Context, now time.Time) ([]Document, error)
+	MarkReminded(ctx context.Context, d *Document) error
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	mediaService  media.Service
+	now           func() time.Time
+}
+
+func NewService(repo Repository, familyService family.Service, mediaService media.Service) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+		mediaService:  mediaService,
+		now:           time.Now,
+	}
+}
+
+// HealthCheck reports whether child documents can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Create(ctx context.Context, userID, childID string, req *DocumentRequest) (*Document, error) {
+	familyID, err := s.authorize(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	d := &Document{
+		ID:        ids.New(),
+		ChildID:   childID,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.apply(ctx, userID, familyID, d, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, d); err != nil {
+		return nil, fmt.Errorf("failed to create document: %w", err)
+	}
+	d.Status = d.StatusOn(now)
+	return d, nil
+}
+
+func (s *service) Get(ctx context.Context, userID, id string) (*Document, error) {
+	d, _, err := s.getDocument(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	d.Status = d.StatusOn(s.now())
+	return d, nil
+}
+
+func (s *service) List(ctx context.Context, userID, childID string, category Category) ([]Document, error) {
+	if category != "" && !validCategories[category] {
+		return nil, ErrInvalidCategory
+	}
+	if _, err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	docs, err := s.repo.ListByChild(ctx, childID, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	now := s.now()
+	for i := range docs {
+		docs[i].Status = docs[i].StatusOn(now)
+	}
+	return docs, nil
+}
+
+func (s *service) Update(ctx context.Context, userID, id string, req *DocumentRequest) (*Document, error) {
+	d, familyID, err := s.getDocument(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, userID, familyID, d, req); err != nil {
+		return nil, err
+	}
+	d.UpdatedAt = s.now()
+
+	if err := s.repo.Update(ctx, d); err != nil {
+		return nil, fmt.Errorf("failed to update document: %w", err)
+	}
+	d.Status = d.StatusOn(d.UpdatedAt)
+	return d, nil
+}
+
+// Delete removes the document; its file stays in the media store
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	if _, _, err := s.getDocument(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+func (s *service) DueReminders(ctx context.Context, now time.Time) ([]Document, error) {
+	from := now.Format(time.DateOnly)
+	to := now.AddDate(0, 0, ReminderLeadDays).Format(time.DateOnly)
+
+	docs, err := s.repo.ListUnreminded(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring documents: %w", err)
+	}
+	for i := range docs {
+		docs[i].Status = docs[i].StatusOn(now)
+	}
+	return docs, nil
+}
+
+func (s *service) MarkReminded(ctx context.Context, d *Document) error {
+	if err := s.repo.MarkReminded(ctx, d.ID, d.ExpiresOn); err != nil {
+		return fmt.Errorf("failed to mark document reminded: %w", err)
+	}
+	return nil
+}
+
+// StatusOn reports whether the document has expired, or expires within
+// ReminderLeadDays, as of now. Documents without an expiry date stay valid.
+func (d *Document) StatusOn(now time.Time) Status {
+	if d.ExpiresOn == "" {
+		return StatusValid
+	}
+	// YYYY-MM-DD dates compare correctly as strings
+	switch {
+	case d.ExpiresOn < now.Format(time.DateOnly):
+		return StatusExpired
+	case d.ExpiresOn <= now.AddDate(0, 0, ReminderLeadDays).Format(time.DateOnly):
+		return StatusExpiring
+	default:
+		return StatusValid
+	}
+}
+
+// apply validates the request and copies it onto the document. The file must
+// have been uploaded to the child's own family.
+func (s *service) apply(ctx context.Context, userID, familyID string, d *Document, req *DocumentRequest) error {
+	if !validCategories[req.Category] {
+		return ErrInvalidCategory
+	}
+	if req.ExpiresOn != "" {
+		if _, err := time.Parse(time.DateOnly, req.ExpiresOn); err != nil {
+			return ErrInvalidDate
+		}
+	}
+
+	m, err := s.mediaService.Get(ctx, userID, req.MediaID)
+	if err != nil {
+		if errors.Is(err, media.ErrMediaNotFound) || errors.Is(err, media.ErrNotMember) {
+			return ErrMediaNotFound
+		}
+		return err
+	}
+	if m.FamilyID != familyID {
+		return ErrMediaNotFound
+	}
+
+	d.MediaID = m.ID
+	d.Category = req.Category
+	d.Title = req.Title
+	d.Notes = req.Notes
+	d.ExpiresOn = req.ExpiresOn
+	return nil
+}
+
+func (s *service) getDocument(ctx context.Context, userID, id string) (*Document, string, error) {
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get document: %w", err)
+	}
+	if d == nil {
+		return nil, "", ErrDocumentNotFound
+	}
+	familyID, err := s.authorize(ctx, userID, d.ChildID)
+	if err != nil {
+		return nil, "", err
+	}
+	return d, familyID, nil
+}
+
+// authorize lets in members of the child's family and returns the family
+func (s *service) authorize(ctx context.Context, userID, childID string) (string, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return "", ErrChildNotFound
+	}
+
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return "", ErrNotMember
+		}
+		return "", err
+	}
+	return child.FamilyID, nil
+}
@@ -0,0 +1,267 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/media"
+)
+
+var testNow = time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+type mockRepository struct {
+	docs     map[string]*Document
+	reminded map[string]string
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{docs: map[string]*Document{}, reminded: map[string]string{}}
+}
+
+func (m *mockRepository) Create(ctx context.Context, d *Document) error {
+	stored := *d
+	m.docs[d.ID] = &stored
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Document, error) {
+	d, ok := m.docs[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *d
+	return &copied, nil
+}
+
+func (m *mockRepository) ListByChild(ctx context.Context, childID string, category Category) ([]Document, error) {
+	docs := []Document{}
+	for _, d := range m.docs {
+		if d.ChildID == childID && (category == "" || d.Category == category) {
+			docs = append(docs, *d)
+		}
+	}
+	return docs, nil
+}
+
+func (m *mockRepository) Update(ctx context.Context, d *Document) error {
+	stored := *d
+	m.docs[d.ID] = &stored
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	delete(m.docs, id)
+	return nil
+}
+
+func (m *mockRepository) ListUnreminded(ctx context.Context, from, to string) ([]Document, error) {
+	docs := []Document{}
+	for _, d := range m.docs {
+		if d.ExpiresOn >= from && d.ExpiresOn <= to && d.RemindedFor != d.ExpiresOn {
+			docs = append(docs, *d)
+		}
+	}
+	return docs, nil
+}
+
+func (m *mockRepository) MarkReminded(ctx context.Context, id, expiresOn string) error {
+	m.reminded[id] = expiresOn
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" {
+		return &family.Child{ID: "child-1", FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID == "user-1" {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockMediaService struct {
+	media.Service
+}
+
+func (m *mockMediaService) Get(ctx context.Context, userID, id string) (*media.Media, error) {
+	switch id {
+	case "media-1", "media-2":
+		return &media.Media{ID: id, FamilyID: "family-1"}, nil
+	case "media-other":
+		return &media.Media{ID: id, FamilyID: "family-2"}, nil
+	}
+	return nil, media.ErrMediaNotFound
+}
+
+func newTestService() (*service, *mockRepository) {
+	repo := newMockRepository()
+	svc := NewService(repo, &mockFamilyService{}, &mockMediaService{}).(*service)
+	svc.now = func() time.Time { return testNow }
+	return svc, repo
+}
+
+func insuranceCard(expiresOn string) *DocumentRequest {
+	return &DocumentRequest{MediaID: "media-1", Category: CategoryInsurance, Title: "Insurance card", ExpiresOn: expiresOn}
+}
+
+func TestService_Create(t *testing.T) {
+	svc, repo := newTestService()
+
+	d, err := svc.Create(context.Background(), "user-1", "child-1", insuranceCard("2024-05-20"))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if d.CreatedBy != "user-1" || d.Category != CategoryInsurance || d.MediaID != "media-1" {
+		t.Errorf("Create() = %+v", d)
+	}
+	if d.Status != StatusExpiring {
+		t.Errorf("Create() Status = %v, want expiring", d.Status)
+	}
+	if _, ok := repo.docs[d.ID]; !ok {
+		t.Error("Create() should store the document")
+	}
+}
+
+func TestService_Create_Errors(t *testing.T) {
+	svc, _ := newTestService()
+
+	tests := []struct {
+		name    string
+		userID  string
+		childID string
+		req     *DocumentRequest
+		want    error
+	}{
+		{"not a member", "user-2", "child-1", insuranceCard(""), ErrNotMember},
+		{"missing child", "user-1", "child-9", insuranceCard(""), ErrChildNotFound},
+		{"bad category", "user-1", "child-1", &DocumentRequest{MediaID: "media-1", Category: "receipts", Title: "x"}, ErrInvalidCategory},
+		{"bad date", "user-1", "child-1", insuranceCard("next year"), ErrInvalidDate},
+		{"missing media", "user-1", "child-1", &DocumentRequest{MediaID: "media-9", Category: CategoryConsent, Title: "x"}, ErrMediaNotFound},
+		{"other family's media", "user-1", "child-1", &DocumentRequest{MediaID: "media-other", Category: CategoryConsent, Title: "x"}, ErrMediaNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Create(context.Background(), tt.userID, tt.childID, tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("Create() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_List_ByCategory(t *testing.T) {
+	svc, repo := newTestService()
+	repo.docs["doc-1"] = &Document{ID: "doc-1", ChildID: "child-1", Category: CategoryInsurance, ExpiresOn: "2024-04-30"}
+	repo.docs["doc-2"] = &Document{ID: "doc-2", ChildID: "child-1", Category: CategoryCustody}
+
+	docs, err := svc.List(context.Background(), "user-1", "child-1", CategoryInsurance)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(docs) != 1 || docs[0].ID != "doc-1" {
+		t.Fatalf("List() = %+v, want only doc-1", docs)
+	}
+	if docs[0].Status != StatusExpired {
+		t.Errorf("List() Status = %v, want expired", docs[0].Status)
+	}
+
+	if _, err := svc.List(context.Background(), "user-1", "child-1", "receipts"); !errors.Is(err, ErrInvalidCategory) {
+		t.Errorf("List() error = %v, want ErrInvalidCategory", err)
+	}
+}
+
+func TestService_Update(t *testing.T) {
+	svc, repo := newTestService()
+	repo.docs["doc-1"] = &Document{ID: "doc-1", ChildID: "child-1", MediaID: "media-1", Category: CategoryInsurance, ExpiresOn: "2024-05-10"}
+
+	req := insuranceCard("2025-05-10")
+	req.MediaID = "media-2"
+	d, err := svc.Update(context.Background(), "user-1", "doc-1", req)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if d.MediaID != "media-2" || d.ExpiresOn != "2025-05-10" || d.Status != StatusValid {
+		t.Errorf("Update() = %+v", d)
+	}
+}
+
+func TestService_Delete(t *testing.T) {
+	svc, repo := newTestService()
+	repo.docs["doc-1"] = &Document{ID: "doc-1", ChildID: "child-1"}
+
+	if err := svc.Delete(context.Background(), "user-2", "doc-1"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Delete() by non-member error = %v, want ErrNotMember", err)
+	}
+	if err := svc.Delete(context.Background(), "user-1", "doc-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := repo.docs["doc-1"]; ok {
+		t.Error("Delete() should remove the document")
+	}
+	if err := svc.Delete(context.Background(), "user-1", "doc-1"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("Delete() again error = %v, want ErrDocumentNotFound", err)
+	}
+}
+
+func TestService_DueReminders(t *testing.T) {
+	svc, repo := newTestService()
+	repo.docs["soon"] = &Document{ID: "soon", ChildID: "child-1", ExpiresOn: "2024-05-20"}
+	repo.docs["reminded"] = &Document{ID: "reminded", ChildID: "child-1", ExpiresOn: "2024-05-21", RemindedFor: "2024-05-21"}
+	repo.docs["renewed"] = &Document{ID: "renewed", ChildID: "child-1", ExpiresOn: "2024-05-22", RemindedFor: "2023-05-22"}
+	repo.docs["later"] = &Document{ID: "later", ChildID: "child-1", ExpiresOn: "2024-07-01"}
+	repo.docs["past"] = &Document{ID: "past", ChildID: "child-1", ExpiresOn: "2024-04-01"}
+	repo.docs["none"] = &Document{ID: "none", ChildID: "child-1"}
+
+	docs, err := svc.DueReminders(context.Background(), testNow)
+	if err != nil {
+		t.Fatalf("DueReminders() error = %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, d := range docs {
+		got[d.ID] = true
+	}
+	if len(got) != 2 || !got["soon"] || !got["renewed"] {
+		t.Errorf("DueReminders() = %v, want soon and renewed", got)
+	}
+
+	if err := svc.MarkReminded(context.Background(), repo.docs["soon"]); err != nil {
+		t.Fatalf("MarkReminded() error = %v", err)
+	}
+	if repo.reminded["soon"] != "2024-05-20" {
+		t.Errorf("MarkReminded() stored %q, want the expiry date", repo.reminded["soon"])
+	}
+}
+
+func TestDocument_StatusOn(t *testing.T) {
+	tests := []struct {
+		expiresOn string
+		want      Status
+	}{
+		{"", StatusValid},
+		{"2024-04-30", StatusExpired},
+		{"2024-05-01", StatusExpiring},
+		{"2024-05-31", StatusExpiring},
+		{"2024-06-01", StatusValid},
+	}
+
+	for _, tt := range tests {
+		d := &Document{ExpiresOn: tt.expiresOn}
+		if got := d.StatusOn(testNow); got != tt.want {
+			t.Errorf("StatusOn() with expiry %q = %v, want %v", tt.expiresOn, got, tt.want)
+		}
+	}
+}
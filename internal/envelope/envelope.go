@@ -0,0 +1,251 @@
+// Package envelope wraps JSON responses as {"data":...,"meta":...} for
+// clients that ask for it with the X-Envelope header. Handlers keep writing
+// their plain responses and attach metadata through the context; the
+// middleware does the wrapping, so no handler builds its own wrapper. Warnings
+// reach clients without envelopes too, as a "warnings" field. Wearables can
+// ask for compact responses with ?compact=true.
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apijson"
+	"github.com/ninenine/babytrack/internal/warnings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderEnvelope opts a request into enveloped responses ("1" or "true")
+const HeaderEnvelope = "X-Envelope"
+
+// QueryCompact opts a request into compact responses ("1" or "true")
+const QueryCompact = "compact"
+
+const metaKey = "envelope_meta"
+
+// Meta is sent next to the response data
+type Meta struct {
+	Warnings    []string     `json:"warnings,omitempty"`
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	Pagination  *Pagination  `json:"pagination,omitempty"`
+}
+
+// Deprecation announces that an endpoint or field is going away
+type Deprecation struct {
+	Message string     `json:"message"`
+	Since   *time.Time `json:"since,omitempty"`
+	Sunset  *time.Time `json:"sunset,omitempty"`
+	// Link points at the replacement or migration notes
+	Link string `json:"link,omitempty"`
+}
+
+// Pagination tells the client how to fetch the next page
+type Pagination struct {
+	HasMore bool `json:"has_more"`
+	// Next is the cursor to pass for the next page, empty on the last one
+	Next  string `json:"next,omitempty"`
+	Total *int   `json:"total,omitempty"`
+}
+
+// Warn attaches a warning to the response. Services add theirs through
+// warnings.Add on the request context.
+func Warn(c *gin.Context, message string) {
+	warnings.Add(c.Request.Context(), message)
+}
+
+// Deprecate marks the response as coming from something deprecated. Clients
+// without envelopes see the Deprecation and Sunset headers; Deprecation
+// carries the date as @<unix time> when Since is set.
+func Deprecate(c *gin.Context, d Deprecation) {
+	meta(c).Deprecation = &d
+	if d.Since != nil {
+		c.Header("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	} else {
+		c.Header("Deprecation", "true")
+	}
+	if d.Sunset != nil {
+		c.Header("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		c.Header("Link", "<"+d.Link+`>; rel="deprecation"`)
+	}
+}
+
+// SetPagination records where the next page starts
+func SetPagination(c *gin.Context, p Pagination) {
+	meta(c).Pagination = &p
+}
+
+// MetaFor returns the metadata gathered for the request so far, warnings
+// included, for error responses written around the middleware
+func MetaFor(c *gin.Context) *Meta {
+	m := meta(c)
+	m.Warnings = warnings.From(c.Request.Context())
+	return m
+}
+
+func meta(c *gin.Context) *Meta {
+	if m, ok := c.Get(metaKey); ok {
+		return m.(*Meta)
+	}
+	m := &Meta{}
+	c.Set(metaKey, m)
+	return m
+}
+
+// Requested reports whether the request asked for enveloped responses
+func Requested(r *http.Request) bool {
+	v, err := strconv.ParseBool(strings.TrimSpace(r.Header.Get(HeaderEnvelope)))
+	return err == nil && v
+}
+
+// CompactRequested reports whether the request asked for compact responses,
+// with short keys and only the fields wearables use
+func CompactRequested(r *http.Request) bool {
+	v, err := strconv.ParseBool(strings.TrimSpace(r.URL.Query().Get(QueryCompact)))
+	return err == nil && v
+}
+
+// Middleware collects warnings for every request, and wraps or compacts the
+// JSON responses of requests that opt in. Other responses, such as CSV
+// downloads, media and event streams, pass through.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := warnings.NewContext(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		w := &writer{
+			ResponseWriter: c.Writer,
+			ctx:            ctx,
+			enveloped:      Requested(c.Request),
+			compact:        CompactRequested(c.Request),
+		}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.buffering {
+			m := meta(c)
+			m.Warnings = warnings.From(ctx)
+			w.flush(m)
+		}
+	}
+}
+
+// writer holds back JSON bodies that need wrapping, or a warnings field,
+// until the handler is done, and lets anything else straight through
+type writer struct {
+	gin.ResponseWriter
+	ctx       context.Context
+	enveloped bool
+	compact   bool
+	decided   bool
+	buffering bool
+	body      bytes.Buffer
+}
+
+// decide runs on the first write, by which point the handler has called its
+// services and any warnings are in
+func (w *writer) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	isJSON := strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	w.buffering = isJSON && (w.enveloped || w.compact || len(warnings.From(w.ctx)) > 0)
+}
+
+func (w *writer) Write(data []byte) (int, error) {
+	w.decide()
+	if w.buffering {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *writer) WriteString(s string) (int, error) {
+	w.decide()
+	if w.buffering {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Written and Size report the buffered body as written, so gin does not
+// write the status and an empty body over it
+func (w *writer) Written() bool {
+	return w.buffering || w.ResponseWriter.Written()
+}
+
+func (w *writer) Size() int {
+	if w.buffering {
+		return w.body.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+// flush writes the buffered body as {"data":...,"meta":...}. Error
+// responses keep their fields at the top level, with meta added alongside.
+// Without an envelope, objects gain a "warnings" field and anything else is
+// sent as it was. Compact responses have their data compacted first; errors
+// are left whole so clients can still show them.
+func (w *writer) flush(m *Meta) {
+	body := bytes.TrimSpace(w.body.Bytes())
+	if w.compact && w.Status() < http.StatusBadRequest {
+		if compacted, err := apijson.Compact(body); err == nil {
+			body = compacted
+		}
+	}
+	if !w.enveloped {
+		w.ResponseWriter.Write(withWarnings(body, m.Warnings)) //nolint:errcheck // The client has gone if this fails
+		return
+	}
+
+	var out any
+	if w.Status() >= http.StatusBadRequest {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err == nil {
+			metaJSON, err := apijson.Marshal(m)
+			if err == nil {
+				fields["meta"] = metaJSON
+				out = fields
+			}
+		}
+	}
+	if out == nil {
+		out = struct {
+			Data json.RawMessage `json:"data"`
+			Meta *Meta           `json:"meta"`
+		}{Data: json.RawMessage(body), Meta: m}
+	}
+
+	wrapped, err := apijson.Marshal(out)
+	if err != nil {
+		// The body was not valid JSON after all; send it as it was
+		wrapped = w.body.Bytes()
+	}
+	w.ResponseWriter.Write(wrapped) //nolint:errcheck // The client has gone if this fails
+}
+
+func withWarnings(body []byte, list []string) []byte {
+	var fields map[string]json.RawMessage
+	if len(list) == 0 || json.Unmarshal(body, &fields) != nil {
+		return body
+	}
+	encoded, err := json.Marshal(list)
+	if err != nil {
+		return body
+	}
+	fields["warnings"] = encoded
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return out
+}
@@ -0,0 +1,192 @@
+package envelope
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/warnings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func setupRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/items", func(c *gin.Context) {
+		SetPagination(c, Pagination{HasMore: true, Next: "abc"})
+		Warn(c, "showing cached items")
+		c.JSON(http.StatusOK, []gin.H{{"id": "1"}})
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	})
+	router.GET("/old", func(c *gin.Context) {
+		sunset := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		Deprecate(c, Deprecation{Message: "use /items", Sunset: &sunset, Link: "/items"})
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/export.csv", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/csv", []byte("a,b\n"))
+	})
+	router.POST("/items", func(c *gin.Context) {
+		warnings.Add(c.Request.Context(), "start_time was adjusted")
+		c.JSON(http.StatusCreated, gin.H{"id": "2"})
+	})
+	router.DELETE("/items/1", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func get(router *gin.Engine, method, path string, envelope bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, http.NoBody)
+	if envelope {
+		req.Header.Set(HeaderEnvelope, "true")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_Wraps(t *testing.T) {
+	w := get(setupRouter(), "GET", "/items", true)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var resp struct {
+		Data []map[string]string `json:"data"`
+		Meta Meta                `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse %s: %v", w.Body.String(), err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0]["id"] != "1" {
+		t.Errorf("data = %v, want the handler's list", resp.Data)
+	}
+	if resp.Meta.Pagination == nil || !resp.Meta.Pagination.HasMore || resp.Meta.Pagination.Next != "abc" {
+		t.Errorf("meta.pagination = %+v", resp.Meta.Pagination)
+	}
+	if len(resp.Meta.Warnings) != 1 {
+		t.Errorf("meta.warnings = %v, want one warning", resp.Meta.Warnings)
+	}
+}
+
+func TestMiddleware_PlainByDefault(t *testing.T) {
+	w := get(setupRouter(), "GET", "/items", false)
+
+	if w.Body.String() != `[{"id":"1"}]` {
+		t.Errorf("body = %s, want the handler's response unchanged", w.Body.String())
+	}
+}
+
+func TestMiddleware_WarningsWithoutEnvelope(t *testing.T) {
+	w := get(setupRouter(), "POST", "/items", false)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+	if w.Body.String() != `{"id":"2","warnings":["start_time was adjusted"]}` {
+		t.Errorf("body = %s, want a warnings field added", w.Body.String())
+	}
+
+	// Lists stay lists; their warnings only travel in the envelope
+	w = get(setupRouter(), "GET", "/items", false)
+	if w.Body.String() != `[{"id":"1"}]` {
+		t.Errorf("body = %s, want the list unchanged", w.Body.String())
+	}
+}
+
+func TestMiddleware_Errors(t *testing.T) {
+	w := get(setupRouter(), "GET", "/missing", true)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404, got %d", w.Code)
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse %s: %v", w.Body.String(), err)
+	}
+	if resp["error"] != "not found" {
+		t.Errorf("error = %v, want it kept at the top level", resp["error"])
+	}
+	if _, ok := resp["meta"]; !ok {
+		t.Error("Expected meta next to the error")
+	}
+}
+
+func TestMiddleware_Deprecation(t *testing.T) {
+	for _, enveloped := range []bool{true, false} {
+		w := get(setupRouter(), "GET", "/old", enveloped)
+
+		if w.Header().Get("Deprecation") != "true" {
+			t.Errorf("envelope=%v: Deprecation header = %q", enveloped, w.Header().Get("Deprecation"))
+		}
+		if w.Header().Get("Sunset") != "Wed, 01 Jan 2025 00:00:00 GMT" {
+			t.Errorf("envelope=%v: Sunset header = %q", enveloped, w.Header().Get("Sunset"))
+		}
+	}
+
+	var resp struct {
+		Meta Meta `json:"meta"`
+	}
+	w := get(setupRouter(), "GET", "/old", true)
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse %s: %v", w.Body.String(), err)
+	}
+	if resp.Meta.Deprecation == nil || resp.Meta.Deprecation.Message != "use /items" {
+		t.Errorf("meta.deprecation = %+v", resp.Meta.Deprecation)
+	}
+}
+
+func TestMiddleware_PassesThroughOtherContent(t *testing.T) {
+	router := setupRouter()
+
+	w := get(router, "GET", "/export.csv", true)
+	if w.Body.String() != "a,b\n" {
+		t.Errorf("CSV body = %q, want it unchanged", w.Body.String())
+	}
+
+	w = get(router, "DELETE", "/items/1", true)
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("DELETE = %d %q, want an empty 204", w.Code, w.Body.String())
+	}
+}
+
+func TestMiddleware_Compact(t *testing.T) {
+	router := setupRouter()
+	router.GET("/feedings", func(c *gin.Context) {
+		c.JSON(http.StatusOK, []gin.H{{"id": "1", "amount": 90, "notes": "fussy"}})
+	})
+
+	w := get(router, "GET", "/feedings?compact=true", false)
+	if w.Body.String() != `[{"am":90,"i":"1"}]` {
+		t.Errorf("body = %s, want short keys without notes", w.Body.String())
+	}
+
+	// Enveloped, only the data is compacted
+	w = get(router, "GET", "/feedings?compact=1", true)
+	var resp struct {
+		Data []map[string]any `json:"data"`
+		Meta *Meta            `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse %s: %v", w.Body.String(), err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0]["i"] != "1" || resp.Meta == nil {
+		t.Errorf("body = %s", w.Body.String())
+	}
+
+	// Errors are left whole
+	w = get(router, "GET", "/missing?compact=true", false)
+	if w.Body.String() != `{"error":"not found"}` {
+		t.Errorf("error body = %s, want it unchanged", w.Body.String())
+	}
+}
@@ -0,0 +1,49 @@
+package errreport
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var ErrInvalidDSN = errors.New("invalid DSN")
+
+// DSN says where to send events, parsed from a Sentry-style DSN such as
+// https://<public key>@o1.ingest.sentry.io/<project id>
+type DSN struct {
+	raw       string
+	publicKey string
+	endpoint  string
+}
+
+func ParseDSN(s string) (*DSN, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDSN, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return nil, fmt.Errorf("%w: scheme must be http or https", ErrInvalidDSN)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("%w: missing public key", ErrInvalidDSN)
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	project := path[i+1:]
+	if project == "" {
+		return nil, fmt.Errorf("%w: missing project ID", ErrInvalidDSN)
+	}
+
+	return &DSN{
+		raw:       s,
+		publicKey: u.User.Username(),
+		endpoint:  fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, path[:i], project),
+	}, nil
+}
+
+// Endpoint is the URL events are posted to
+func (d *DSN) Endpoint() string {
+	return d.endpoint
+}
@@ -0,0 +1,140 @@
+package errreport
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// modulePath marks the frames that are this app's own code
+const modulePath = "github.com/ninenine/babytrack/"
+
+type Level string
+
+const (
+	LevelError Level = "error"
+	LevelFatal Level = "fatal"
+)
+
+// Event is the part of the Sentry event payload this app fills in
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       Level             `json:"level"`
+	Logger      string            `json:"logger,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Message     *Message          `json:"message,omitempty"`
+	Exception   *Exceptions       `json:"exception,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	User        *User             `json:"user,omitempty"`
+	Request     *Request          `json:"request,omitempty"`
+}
+
+type Message struct {
+	Formatted string `json:"formatted"`
+}
+
+type Exceptions struct {
+	Values []Exception `json:"values"`
+}
+
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// User identifies the signed-in user by a hash of their ID, never the ID
+type User struct {
+	ID string `json:"id"`
+}
+
+type Request struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	QueryString string `json:"query_string,omitempty"`
+}
+
+// stacktrace returns the stack of a panicking goroutine, oldest call first
+// as Sentry lists it. The frames above the panic, the runtime's and this
+// package's, are left out.
+func stacktrace() *Stacktrace {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []Frame
+	inRuntime, trimmed := false, false
+	for {
+		f, more := frames.Next()
+		pkg, fn := splitFunction(f.Function)
+		out = append(out, Frame{
+			Function: fn,
+			Module:   pkg,
+			Filename: shortFile(f.File),
+			AbsPath:  f.File,
+			Lineno:   f.Line,
+			InApp:    strings.HasPrefix(pkg, modulePath),
+		})
+		switch {
+		case trimmed:
+		case pkg == "runtime":
+			inRuntime = true
+		case inRuntime:
+			// The first frame past the panic machinery panicked; start there
+			out, trimmed = out[len(out)-1:], true
+		}
+		if !more {
+			break
+		}
+	}
+	slices.Reverse(out)
+	return &Stacktrace{Frames: out}
+}
+
+// splitFunction splits a qualified function name, e.g.
+// github.com/ninenine/babytrack/internal/feeding.(*Handler).create, into
+// its package path and the rest
+func splitFunction(name string) (pkg, fn string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:slash+1+dot], name[slash+2+dot:]
+}
+
+// module names the app package a function belongs to, e.g. feeding
+func module(function string) string {
+	pkg, _ := splitFunction(function)
+	return pkg[strings.LastIndex(pkg, "/")+1:]
+}
+
+func shortFile(path string) string {
+	if i := strings.LastIndex(path, "/internal/"); i >= 0 {
+		return path[i+1:]
+	}
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
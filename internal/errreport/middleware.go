@@ -0,0 +1,119 @@
+package errreport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRecordedBody is how much of a 5xx response is kept to find its error
+const maxRecordedBody = 4 << 10
+
+// Middleware reports 5xx responses. It goes inside the recovery, which
+// reports panics itself through CapturePanic.
+func (r *Reporter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := &recorder{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.Status() >= http.StatusInternalServerError && r.sampled(r.cfg.SampleRate) {
+			ev := r.event(c, LevelError, w.Status())
+			ev.Message = &Message{Formatted: r.redact(errorMessage(c, w))}
+			r.Capture(ev)
+		}
+	}
+}
+
+// CapturePanic reports a panic with its stack and the incident ID the user
+// was given. Call it from the deferred function that recovered, while the
+// stack is still there to read.
+func (r *Reporter) CapturePanic(c *gin.Context, p any, incidentID string) bool {
+	if !r.sampled(r.cfg.PanicSampleRate) {
+		return false
+	}
+	ev := r.event(c, LevelFatal, http.StatusInternalServerError)
+	ev.Tags["incident_id"] = incidentID
+	ev.Exception = &Exceptions{Values: []Exception{{
+		Type:       "panic",
+		Value:      r.redact(fmt.Sprint(p)),
+		Module:     ev.Tags["module"],
+		Stacktrace: stacktrace(),
+	}}}
+	return r.Capture(ev)
+}
+
+// event fills in what is known about the request
+func (r *Reporter) event(c *gin.Context, level Level, status int) *Event {
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	ev := &Event{
+		Level:       level,
+		Logger:      "http",
+		Transaction: c.Request.Method + " " + route,
+		Tags: map[string]string{
+			"route":       route,
+			"module":      module(c.HandlerName()),
+			"status_code": strconv.Itoa(status),
+		},
+		Request: &Request{
+			Method:      c.Request.Method,
+			URL:         c.Request.URL.Path,
+			QueryString: r.redact(c.Request.URL.RawQuery),
+		},
+	}
+	if id := c.GetString("request_id"); id != "" {
+		ev.Tags["request_id"] = id
+	}
+	if userID := c.GetString("user_id"); userID != "" {
+		ev.User = &User{ID: r.hashUser(userID)}
+	}
+	return ev
+}
+
+// errorMessage finds what went wrong: an error attached to the context, or
+// the "error" field handlers answer with
+func errorMessage(c *gin.Context, w *recorder) string {
+	if err := c.Errors.Last(); err != nil {
+		return err.Error()
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(w.body.Bytes(), &body) == nil && body.Error != "" {
+		return body.Error
+	}
+	return fmt.Sprintf("%d %s", w.Status(), http.StatusText(w.Status()))
+}
+
+// recorder keeps the start of 5xx response bodies
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recorder) record(n int) bool {
+	return w.Status() >= http.StatusInternalServerError && w.body.Len()+n <= maxRecordedBody
+}
+
+func (w *recorder) Write(data []byte) (int, error) {
+	if w.record(len(data)) {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	if w.record(len(s)) {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,163 @@
+package errreport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/redact"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type notesHandler struct{}
+
+func (notesHandler) get(c *gin.Context) {
+	switch c.Query("fail") {
+	case "panic":
+		var m map[string]int
+		m["jo@example.com"]++
+	case "error":
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load note for jo@example.com"})
+	case "attached":
+		_ = c.Error(errors.New("database is down")) //nolint:errcheck // Returns its argument
+		c.Status(http.StatusServiceUnavailable)
+	case "missing":
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	}
+}
+
+func setupRouter(r *Reporter) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("request_id", "req-1")
+		c.Set("user_id", "user-1")
+		defer func() {
+			if p := recover(); p != nil {
+				r.CapturePanic(c, p, "INC-1")
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+	})
+	router.Use(r.Middleware())
+	router.GET("/api/notes/:id", notesHandler{}.get)
+	return router
+}
+
+// serve makes the request and returns the events it reported
+func serve(t *testing.T, r *Reporter, col *collector, url string) (int, []Event) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	setupRouter(r).ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, col.events
+}
+
+func allSampled() Config {
+	return Config{SampleRate: 1, PanicSampleRate: 1, UserSalt: "salt"}
+}
+
+func TestMiddleware_Panic(t *testing.T) {
+	redactor, err := redact.New(redact.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, col := newTestReporter(t, allSampled(), WithRedactor(redactor))
+
+	code, events := serve(t, r, col, "/api/notes/n1?fail=panic&token=secret")
+	if code != http.StatusInternalServerError || len(events) != 1 {
+		t.Fatalf("status = %d, events = %+v", code, events)
+	}
+
+	ev := events[0]
+	if ev.Level != LevelFatal || ev.Transaction != "GET /api/notes/:id" {
+		t.Errorf("event = %+v", ev)
+	}
+	want := map[string]string{"route": "/api/notes/:id", "module": "errreport", "request_id": "req-1", "incident_id": "INC-1", "status_code": "500"}
+	for k, v := range want {
+		if ev.Tags[k] != v {
+			t.Errorf("tag %s = %q, want %q", k, ev.Tags[k], v)
+		}
+	}
+	if ev.User == nil || ev.User.ID != r.hashUser("user-1") {
+		t.Errorf("user = %+v, want the hashed ID", ev.User)
+	}
+	if ev.Request.URL != "/api/notes/n1" || strings.Contains(ev.Request.QueryString, "secret") {
+		t.Errorf("request = %+v", ev.Request)
+	}
+
+	exc := ev.Exception.Values[0]
+	if exc.Type != "panic" || !strings.Contains(exc.Value, "nil map") {
+		t.Errorf("exception = %+v", exc)
+	}
+	frames := exc.Stacktrace.Frames
+	top := frames[len(frames)-1]
+	if top.Function != "notesHandler.get" || !top.InApp || top.Filename != "internal/errreport/middleware_test.go" {
+		t.Errorf("top frame = %+v", top)
+	}
+}
+
+func TestMiddleware_ServerError(t *testing.T) {
+	redactor, err := redact.New(redact.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url     string
+		code    int
+		message string
+	}{
+		{"/api/notes/n1?fail=error", http.StatusInternalServerError, "failed to load note for [redacted]"},
+		{"/api/notes/n1?fail=attached", http.StatusServiceUnavailable, "database is down"},
+	}
+	for _, tt := range tests {
+		r, col := newTestReporter(t, allSampled(), WithRedactor(redactor))
+		code, events := serve(t, r, col, tt.url)
+		if code != tt.code || len(events) != 1 {
+			t.Fatalf("%s: status = %d, events = %+v", tt.url, code, events)
+		}
+		if ev := events[0]; ev.Level != LevelError || ev.Message.Formatted != tt.message {
+			t.Errorf("%s: event = %+v", tt.url, ev)
+		}
+	}
+}
+
+func TestMiddleware_NotReported(t *testing.T) {
+	for _, url := range []string{"/api/notes/n1", "/api/notes/n1?fail=missing"} {
+		r, col := newTestReporter(t, allSampled())
+		if _, events := serve(t, r, col, url); len(events) != 0 {
+			t.Errorf("%s: reported %+v", url, events)
+		}
+	}
+}
+
+func TestMiddleware_Sampling(t *testing.T) {
+	r, col := newTestReporter(t, Config{SampleRate: 0.25, PanicSampleRate: 0})
+	r.random = func() float64 { return 0.5 }
+
+	if _, events := serve(t, r, col, "/api/notes/n1?fail=error"); len(events) != 0 {
+		t.Errorf("sampled-out error reported: %+v", events)
+	}
+
+	r, col = newTestReporter(t, Config{SampleRate: 0.25})
+	r.random = func() float64 { return 0.1 }
+	if _, events := serve(t, r, col, "/api/notes/n1?fail=panic"); len(events) != 0 {
+		t.Errorf("panic reported at a 0 panic sample rate: %+v", events)
+	}
+}
@@ -0,0 +1,232 @@
+// Package errreport sends panics and 5xx responses to a Sentry-compatible
+// error tracker, tagged with the request ID, route, module and a hash of the
+// user ID. Events are redacted like logs, sampled, and sent in the
+// background so reporting never slows or fails a request.
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/redact"
+)
+
+// queueSize is how many events may wait to be sent; beyond it events are
+// dropped rather than held
+const queueSize = 100
+
+var ErrInvalidSampleRate = errors.New("sample rate must be between 0 and 1")
+
+type Config struct {
+	DSN         string
+	Environment string
+	Release     string
+	// SampleRate is the share of 5xx responses reported, 0 to 1
+	SampleRate float64
+	// PanicSampleRate is the share of panics reported, 0 to 1
+	PanicSampleRate float64
+	// UserSalt keys the hash that stands in for user IDs
+	UserSalt string
+}
+
+type Reporter struct {
+	dsn        *DSN
+	cfg        Config
+	serverName string
+	client     *http.Client
+	redactor   *redact.Redactor
+	random     func() float64
+	now        func() time.Time
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan *Event
+	done   chan struct{}
+}
+
+type Option func(*Reporter)
+
+// WithRedactor masks messages, URLs and query strings before they are sent
+func WithRedactor(r *redact.Redactor) Option {
+	return func(rep *Reporter) {
+		rep.redactor = r
+	}
+}
+
+func WithHTTPClient(c *http.Client) Option {
+	return func(rep *Reporter) {
+		rep.client = c
+	}
+}
+
+// New starts a reporter sending to cfg.DSN. Close it to send what is queued.
+func New(cfg Config, opts ...Option) (*Reporter, error) {
+	dsn, err := ParseDSN(cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	for _, rate := range []float64{cfg.SampleRate, cfg.PanicSampleRate} {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSampleRate, rate)
+		}
+	}
+
+	hostname, _ := os.Hostname() //nolint:errcheck // The server name is optional
+	r := &Reporter{
+		dsn:        dsn,
+		cfg:        cfg,
+		serverName: hostname,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		random:     rand.Float64,
+		now:        time.Now,
+		queue:      make(chan *Event, queueSize),
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	go r.run()
+	return r, nil
+}
+
+// Capture queues an event to send, filling in what every event carries. It
+// reports false when the queue is full or the reporter is closed.
+func (r *Reporter) Capture(ev *Event) bool {
+	if ev.EventID == "" {
+		ev.EventID = newEventID()
+	}
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = r.now().UTC()
+	}
+	ev.Platform = "go"
+	ev.ServerName = r.serverName
+	ev.Release = r.cfg.Release
+	ev.Environment = r.cfg.Environment
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return false
+	}
+	select {
+	case r.queue <- ev:
+		return true
+	default:
+		log.Printf("[errreport] queue full, dropping event %s", ev.EventID)
+		return false
+	}
+}
+
+// Close stops taking events and waits for the queued ones to be sent, until
+// ctx is done
+func (r *Reporter) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HealthCheck reports the reporter as degraded while its queue is nearly
+// full, which means the tracker is slow or unreachable and events are about
+// to be dropped
+func (r *Reporter) HealthCheck(ctx context.Context) error {
+	return health.Queue(len(r.queue), cap(r.queue))
+}
+
+func (r *Reporter) run() {
+	defer close(r.done)
+	for ev := range r.queue {
+		if err := r.send(ev); err != nil {
+			log.Printf("[errreport] failed to send event %s: %v", ev.EventID, err)
+		}
+	}
+}
+
+// send posts the event as a Sentry envelope: a header line, an item header
+// line and the event
+func (r *Reporter) send(ev *Event) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	header, _ := json.Marshal(map[string]string{ //nolint:errcheck // Strings always marshal
+		"event_id": ev.EventID,
+		"dsn":      r.dsn.raw,
+		"sent_at":  r.now().UTC().Format(time.RFC3339),
+	})
+	item, _ := json.Marshal(map[string]any{"type": "event", "length": len(payload)}) //nolint:errcheck // Plain values always marshal
+	for _, line := range [][]byte{header, item, payload} {
+		body.Write(line)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.dsn.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=babytrack/%s, sentry_key=%s",
+		r.cfg.Release, r.dsn.publicKey))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Best-effort close
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sampled decides whether to report something sampled at rate
+func (r *Reporter) sampled(rate float64) bool {
+	return rate > 0 && r.random() < rate
+}
+
+// hashUser stands in for a user ID, so reports can be grouped by user
+// without saying who they are
+func (r *Reporter) hashUser(userID string) string {
+	mac := hmac.New(sha256.New, []byte(r.cfg.UserSalt))
+	mac.Write([]byte(userID))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+func (r *Reporter) redact(s string) string {
+	if r.redactor == nil {
+		return s
+	}
+	return r.redactor.String(s)
+}
+
+// newEventID is a UUID without dashes, as Sentry expects
+func newEventID() string {
+	return strings.ReplaceAll(ids.New(), "-", "")
+}
@@ -0,0 +1,124 @@
+package errreport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is a fake error tracker that keeps the events posted to it
+type collector struct {
+	mu     sync.Mutex
+	events []Event
+	auth   string
+	path   string
+}
+
+func (col *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	col.auth, col.path = r.Header.Get("X-Sentry-Auth"), r.URL.Path
+
+	lines := bufio.NewScanner(r.Body)
+	var n int
+	for lines.Scan() {
+		if n++; n == 3 {
+			var ev Event
+			if err := json.Unmarshal(lines.Bytes(), &ev); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			col.events = append(col.events, ev)
+		}
+	}
+	if n != 3 {
+		http.Error(w, "want 3 envelope lines", http.StatusBadRequest)
+	}
+}
+
+func newTestReporter(t *testing.T, cfg Config, opts ...Option) (*Reporter, *collector) {
+	t.Helper()
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	t.Cleanup(srv.Close)
+
+	cfg.DSN = strings.Replace(srv.URL, "http://", "http://pubkey@", 1) + "/42"
+	r, err := New(cfg, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return r, col
+}
+
+func TestParseDSN(t *testing.T) {
+	dsn, err := ParseDSN("https://abc123@o1.ingest.example.com/sentry/42")
+	if err != nil {
+		t.Fatalf("ParseDSN() error = %v", err)
+	}
+	if dsn.publicKey != "abc123" || dsn.Endpoint() != "https://o1.ingest.example.com/sentry/api/42/envelope/" {
+		t.Errorf("ParseDSN() = %+v", dsn)
+	}
+
+	for _, bad := range []string{"", "ftp://key@host/1", "https://host/1", "https://key@host/"} {
+		if _, err := ParseDSN(bad); !errors.Is(err, ErrInvalidDSN) {
+			t.Errorf("ParseDSN(%q) error = %v, want ErrInvalidDSN", bad, err)
+		}
+	}
+}
+
+func TestNew_SampleRate(t *testing.T) {
+	_, err := New(Config{DSN: "https://key@host/1", SampleRate: 1.5})
+	if !errors.Is(err, ErrInvalidSampleRate) {
+		t.Errorf("New() error = %v, want ErrInvalidSampleRate", err)
+	}
+}
+
+func TestReporter_Send(t *testing.T) {
+	r, col := newTestReporter(t, Config{Environment: "production", Release: "1.2.3"})
+
+	if !r.Capture(&Event{Level: LevelError, Message: &Message{Formatted: "boom"}}) {
+		t.Fatal("Capture() dropped the event")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(col.events) != 1 {
+		t.Fatalf("collector got %d events", len(col.events))
+	}
+	ev := col.events[0]
+	if len(ev.EventID) != 32 || ev.Platform != "go" || ev.Environment != "production" || ev.Release != "1.2.3" || ev.Message.Formatted != "boom" {
+		t.Errorf("event = %+v", ev)
+	}
+	if col.path != "/api/42/envelope/" || !strings.Contains(col.auth, "sentry_key=pubkey") {
+		t.Errorf("posted to %s with auth %q", col.path, col.auth)
+	}
+
+	if r.Capture(&Event{}) {
+		t.Error("Capture() after Close() queued an event")
+	}
+}
+
+func TestReporter_HashUser(t *testing.T) {
+	a, _ := newTestReporter(t, Config{UserSalt: "a"})
+	b, _ := newTestReporter(t, Config{UserSalt: "b"})
+
+	if a.hashUser("user-1") != a.hashUser("user-1") || len(a.hashUser("user-1")) != 32 {
+		t.Errorf("hashUser() is not a stable 32-character hash: %q", a.hashUser("user-1"))
+	}
+	if a.hashUser("user-1") == a.hashUser("user-2") || a.hashUser("user-1") == b.hashUser("user-1") {
+		t.Error("hashUser() does not depend on both the user and the salt")
+	}
+	if strings.Contains(a.hashUser("user-1"), "user") {
+		t.Error("hashUser() leaks the ID")
+	}
+}
@@ -0,0 +1,95 @@
+// Package eventschema documents the notification events the server sends,
+// with JSON schemas for their payloads generated from the Go types that are
+// encoded, so integrations can be written against them without guessing.
+package eventschema
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/jsonschema"
+	"github.com/ninenine/babytrack/internal/messaging"
+	"github.com/ninenine/babytrack/internal/notifications"
+	"github.com/ninenine/babytrack/internal/preferences"
+	"github.com/ninenine/babytrack/internal/presence"
+	"github.com/ninenine/babytrack/internal/sleep"
+)
+
+// Catalog describes every event type
+type Catalog struct {
+	// Event is the envelope every event is sent in; its data field holds the
+	// event's payload
+	Event *jsonschema.Schema `json:"event"`
+	// Digest is the data of an alert that stands in for several batched by
+	// the user's digest rules
+	Digest *jsonschema.Schema            `json:"digest"`
+	Events []EventSchema                 `json:"events"`
+	Defs   map[string]*jsonschema.Schema `json:"$defs"`
+}
+
+// EventSchema describes one event type
+type EventSchema struct {
+	Type        notifications.EventType `json:"type"`
+	Description string                  `json:"description"`
+	// Routable events are alerts users can route or silence in their
+	// notification preferences; the rest are always delivered
+	Routable bool `json:"routable"`
+	// Data is the schema of the event's data, absent when it carries none
+	Data *jsonschema.Schema `json:"data,omitempty"`
+}
+
+// events lists each event type with the value sent as its data, if any.
+// Add new event types here when they are introduced.
+var events = []struct {
+	eventType   notifications.EventType
+	description string
+	data        any
+}{
+	{notifications.EventMedicationDue, "A dose of an active medication is due", nil},
+	{notifications.EventVaccinationDue, "A scheduled vaccination is coming up or overdue", nil},
+	{notifications.EventAppointmentSoon, "An appointment is today, tomorrow or starting shortly", nil},
+	{notifications.EventSleepInsight, "A sleep alert or the daily sleep summary", nil},
+	{notifications.EventFeedingInsight, "Intake has been below the child's feeding goal for several days", nil},
+	{notifications.EventOnThisDay, "The morning look-back at the same day in earlier months", nil},
+	{notifications.EventDocumentExpiring, "A child's document is close to its expiry date", nil},
+	{notifications.EventGrowthReminder, "A well visit has ended; record the weight and length taken at it", nil},
+	{notifications.EventSleepEnded, "A sleep timer the user started was ended on another device", sleep.Sleep{}},
+	{notifications.EventCareMessage, "A new message in a child's care team thread", messaging.Message{}},
+	{notifications.EventPresence, "Another family member started or stopped logging for a child", presence.Presence{}},
+	{notifications.EventNursingTimer, "A child's nursing timer changed on any of the family's devices", feeding.NursingTimer{}},
+}
+
+// Build generates the catalog
+func Build() *Catalog {
+	g := jsonschema.NewGenerator("#/$defs/")
+	types := make([]notifications.EventType, len(events))
+	for i, e := range events {
+		types[i] = e.eventType
+	}
+	jsonschema.Enum(g, types...)
+	jsonschema.Enum(g, sleep.SleepTypeNap, sleep.SleepTypeNight)
+	jsonschema.Enum(g, sleep.WakingHungry, sleep.WakingDiaper, sleep.WakingComfort, sleep.WakingTeething,
+		sleep.WakingUnwell, sleep.WakingOther)
+	jsonschema.Enum(g, feeding.SideLeft, feeding.SideRight)
+	jsonschema.Enum(g, presence.ActivityTypingNote, presence.ActivitySleepTimer, presence.ActivityLogging)
+
+	c := &Catalog{
+		Event:  g.For(reflect.TypeFor[notifications.Event]()),
+		Digest: g.For(reflect.TypeFor[notifications.DigestData]()),
+		Events: make([]EventSchema, 0, len(events)),
+	}
+	for _, e := range events {
+		es := EventSchema{
+			Type:        e.eventType,
+			Description: e.description,
+			Routable:    slices.Contains(preferences.RoutableEvents, e.eventType),
+		}
+		if e.data != nil {
+			es.Data = g.For(reflect.TypeOf(e.data))
+		}
+		c.Events = append(c.Events, es)
+	}
+	c.Defs = g.Defs()
+	return c
+}
@@ -0,0 +1,26 @@
+package eventschema
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	catalog *Catalog
+}
+
+// NewHandler builds the catalog once; it only changes with the code
+func NewHandler() *Handler {
+	return &Handler{catalog: Build()}
+}
+
+// RegisterRoutes registers the public event schema endpoint
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/schema", h.schema)
+}
+
+func (h *Handler) schema(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.catalog)
+}
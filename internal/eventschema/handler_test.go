@@ -0,0 +1,87 @@
+package eventschema
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/jsonschema"
+	"github.com/ninenine/babytrack/internal/notifications"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestHandler_Schema(t *testing.T) {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	NewHandler().RegisterRoutes(router.Group("/events"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/events/schema", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var got Catalog
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to parse %s: %v", w.Body.String(), err)
+	}
+	if got.Event == nil || got.Event.Ref != "#/$defs/notifications.Event" || got.Defs["notifications.Event"] == nil {
+		t.Errorf("event = %+v, want the envelope schema", got.Event)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	c := Build()
+
+	byType := map[notifications.EventType]EventSchema{}
+	for _, e := range c.Events {
+		byType[e.Type] = e
+	}
+
+	ended, ok := byType[notifications.EventSleepEnded]
+	if !ok || !ended.Routable || ended.Data == nil || ended.Data.Ref != "#/$defs/sleep.Sleep" {
+		t.Errorf("sleep_ended = %+v", ended)
+	}
+	if timer := byType[notifications.EventNursingTimer]; timer.Routable || timer.Data == nil {
+		t.Errorf("nursing_timer = %+v, want data and not routable", timer)
+	}
+	if due := byType[notifications.EventMedicationDue]; due.Data != nil {
+		t.Errorf("medication_due data = %+v, want none", due.Data)
+	}
+
+	// Every $ref resolves
+	var check func(s *jsonschema.Schema)
+	check = func(s *jsonschema.Schema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			if c.Defs[s.Ref[len("#/$defs/"):]] == nil {
+				t.Errorf("unresolved reference %s", s.Ref)
+			}
+		}
+		for _, p := range s.Properties {
+			check(p)
+		}
+		check(s.Items)
+		check(s.AdditionalProperties)
+	}
+	for _, def := range c.Defs {
+		check(def)
+	}
+	for _, e := range c.Events {
+		check(e.Data)
+	}
+
+	sleepDef := c.Defs["sleep.Sleep"]
+	if sleepDef == nil || len(sleepDef.Properties["type"].Enum) != 2 {
+		t.Errorf("sleep.Sleep = %+v, want the sleep type enum", sleepDef)
+	}
+}
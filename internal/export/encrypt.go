@@ -0,0 +1,163 @@
+package export
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ninenine/babytrack/internal/zipcrypt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Encrypt wraps an export route. With a passphrase in X-Export-Passphrase
+// the download is handed out as an encrypted zip archive holding the file
+// the route would have sent; without one it passes through, unless the
+// child's family requires encryption. childParam names the route parameter
+// holding the child's ID.
+func (h *Handler) Encrypt(childParam string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		passphrase := c.GetHeader(HeaderPassphrase)
+		if passphrase == "" {
+			required, err := h.service.PassphraseRequired(c.Request.Context(), c.Param(childParam))
+			if err == nil && required {
+				err = ErrPassphraseRequired
+			}
+			if err != nil {
+				respondError(c, err)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		if utf8.RuneCountInString(passphrase) < MinPassphraseLength {
+			respondError(c, ErrWeakPassphrase)
+			c.Abort()
+			return
+		}
+
+		w := &archiveWriter{ResponseWriter: c.Writer, passphrase: passphrase}
+		defer w.discard()
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		// A route that fails after it started its download records the
+		// error, and what it wrote so far is thrown away
+		if err := w.finish(); err != nil {
+			c.Error(err) //nolint:errcheck // Recorded for the checks below
+		}
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			c.Header("Content-Disposition", "")
+			c.Header("Content-Type", "")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": c.Errors.Last().Error()})
+			return
+		}
+		// Errors went out as they were
+		if w.archive == nil {
+			return
+		}
+
+		info, err := w.archive.Stat()
+		if err != nil {
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": w.name + ".zip"}))
+		c.Header("Cache-Control", "no-store")
+		c.DataFromReader(http.StatusOK, info.Size(), "application/zip", w.archive, nil)
+	}
+}
+
+// exportFilename is the name the route gave its download, which becomes the
+// file inside the archive
+func exportFilename(disposition string) string {
+	if _, params, err := mime.ParseMediaType(disposition); err == nil && params["filename"] != "" {
+		return path.Base(params["filename"])
+	}
+	return "export"
+}
+
+// archiveWriter compresses and encrypts a successful export into a
+// temporary file as it is written, so the archive is complete before any of
+// it is sent and the export is never held in memory. Anything other than a
+// 200 passes through untouched.
+type archiveWriter struct {
+	gin.ResponseWriter
+	passphrase string
+
+	name    string
+	archive *os.File
+	zw      *zipcrypt.Writer
+	entry   io.Writer
+}
+
+func (w *archiveWriter) Write(data []byte) (int, error) {
+	if w.Status() != http.StatusOK {
+		return w.ResponseWriter.Write(data)
+	}
+	if w.zw == nil {
+		if err := w.start(); err != nil {
+			return 0, err
+		}
+	}
+	return w.entry.Write(data)
+}
+
+func (w *archiveWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// start opens the archive on the first write, by when the route has named
+// its download
+func (w *archiveWriter) start() error {
+	archive, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return err
+	}
+	w.archive = archive
+	w.name = exportFilename(w.Header().Get("Content-Disposition"))
+	zw := zipcrypt.NewWriter(archive, w.passphrase)
+	if w.entry, err = zw.Create(w.name, time.Now()); err != nil {
+		return err
+	}
+	w.zw = zw
+	return nil
+}
+
+// finish seals the archive and rewinds it for sending
+func (w *archiveWriter) finish() error {
+	if w.zw == nil {
+		return nil
+	}
+	if err := w.zw.Close(); err != nil {
+		return err
+	}
+	_, err := w.archive.Seek(0, io.SeekStart)
+	return err
+}
+
+func (w *archiveWriter) discard() {
+	if w.archive != nil {
+		w.archive.Close()           //nolint:errcheck // Only read from
+		os.Remove(w.archive.Name()) //nolint:errcheck // Left to the OS if this fails
+	}
+}
+
+func (w *archiveWriter) Written() bool {
+	return w.archive != nil || w.ResponseWriter.Written()
+}
+
+// Flush holds back an archive, which is only sent once it is complete
+func (w *archiveWriter) Flush() {
+	if w.archive == nil {
+		w.ResponseWriter.Flush()
+	}
+}
@@ -0,0 +1,170 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/visibility"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterChildRoutes registers the child-scoped export routes on the children group
+func (h *Handler) RegisterChildRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id/dataset.csv", h.Encrypt("id"), h.dataset)
+}
+
+// RegisterFamilyRoutes registers the family's export settings on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/export-settings", h.getSettings)
+	rg.PUT("/:familyId/export-settings", h.updateSettings)
+}
+
+func (h *Handler) getSettings(c *gin.Context) {
+	settings, err := h.service.GetSettings(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *Handler) updateSettings(c *gin.Context) {
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *Handler) dataset(c *gin.Context) {
+	filter := &DatasetFilter{
+		ChildID: c.Param("id"),
+	}
+
+	if types := c.Query("types"); types != "" {
+		for t := range strings.SplitSeq(types, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, DatasetType(t))
+			}
+		}
+	}
+
+	var err error
+	if filter.From, err = parseDate(c.Query("from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date"})
+		return
+	}
+	if filter.To, err = parseDate(c.Query("to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date"})
+		return
+	}
+	if filter.AsOf, err = audit.ParseAsOf(c.Query("as_of")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid as_of date"})
+		return
+	}
+
+	points, err := h.service.Dataset(c.Request.Context(), c.GetString("user_id"), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="dataset.csv"`)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+
+	// Rows go out as they are read. The csv writer holds back the first few
+	// kilobytes, so a query that fails at the start is still answered with
+	// an error rather than a partial file.
+	out := &countingWriter{w: c.Writer}
+	w := csv.NewWriter(out)
+	w.Write([]string{"timestamp", "type", "metric", "value"}) //nolint:errcheck // Reported by w.Error
+	for p, err := range points {
+		if err != nil {
+			if out.n == 0 {
+				c.Header("Content-Disposition", "")
+				c.Header("Content-Type", "")
+				respondError(c, err)
+				return
+			}
+			log.Printf("[export] dataset for child %s ended early: %v", filter.ChildID, err)
+			c.Error(err) //nolint:errcheck // Lets Encrypt drop the partial file
+			c.Abort()
+			return
+		}
+		w.Write([]string{ //nolint:errcheck // Reported by w.Error
+			p.Timestamp.UTC().Format(time.RFC3339),
+			string(p.Type),
+			p.Metric,
+			strconv.FormatFloat(p.Value, 'f', -1, 64),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("[export] failed to write dataset for child %s: %v", filter.ChildID, err)
+		c.Error(err) //nolint:errcheck // Lets Encrypt drop the partial file
+		c.Abort()
+	}
+}
+
+// countingWriter tells whether any of the response has been written yet
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(data []byte) (int, error) {
+	n, err := c.w.Write(data)
+	c.n += n
+	return n, err
+}
+
+// parseDate accepts either RFC 3339 timestamps or plain YYYY-MM-DD dates
+func parseDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUnsupportedType), errors.Is(err, ErrPassphraseRequired), errors.Is(err, ErrWeakPassphrase):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, ErrNotAdmin), errors.Is(err, visibility.ErrHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
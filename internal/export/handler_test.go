@@ -0,0 +1,382 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/visibility"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	datasetFn            func(ctx context.Context, filter *DatasetFilter) ([]DataPoint, error)
+	streamErr            error // Yielded after the points from datasetFn
+	getSettingsFn        func(ctx context.Context, userID, familyID string) (*Settings, error)
+	updateSettingsFn     func(ctx context.Context, userID, familyID string, req *UpdateSettingsRequest) (*Settings, error)
+	passphraseRequiredFn func(ctx context.Context, childID string) (bool, error)
+}
+
+func (m *mockService) Dataset(ctx context.Context, userID string, filter *DatasetFilter) (iter.Seq2[DataPoint, error], error) {
+	var points []DataPoint
+	if m.datasetFn != nil {
+		var err error
+		if points, err = m.datasetFn(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+	return func(yield func(DataPoint, error) bool) {
+		for _, p := range points {
+			if !yield(p, nil) {
+				return
+			}
+		}
+		if m.streamErr != nil {
+			yield(DataPoint{}, m.streamErr)
+		}
+	}, nil
+}
+
+func (m *mockService) GetSettings(ctx context.Context, userID, familyID string) (*Settings, error) {
+	if m.getSettingsFn != nil {
+		return m.getSettingsFn(ctx, userID, familyID)
+	}
+	return &Settings{FamilyID: familyID}, nil
+}
+
+func (m *mockService) UpdateSettings(ctx context.Context, userID, familyID string, req *UpdateSettingsRequest) (*Settings, error) {
+	if m.updateSettingsFn != nil {
+		return m.updateSettingsFn(ctx, userID, familyID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) PassphraseRequired(ctx context.Context, childID string) (bool, error) {
+	if m.passphraseRequiredFn != nil {
+		return m.passphraseRequiredFn(ctx, childID)
+	}
+	return false, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler := NewHandler(svc)
+	handler.RegisterChildRoutes(router.Group("/children"))
+	handler.RegisterFamilyRoutes(router.Group("/families"))
+	return router
+}
+
+func TestDataset_WritesCSV(t *testing.T) {
+	var captured *DatasetFilter
+	svc := &mockService{
+		datasetFn: func(ctx context.Context, filter *DatasetFilter) ([]DataPoint, error) {
+			captured = filter
+			return []DataPoint{
+				{Timestamp: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), Type: DatasetSleep, Metric: MetricDurationMinutes, Value: 90.5},
+			}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/children/child-1/dataset.csv?types=sleep,feeding&from=2024-03-01&to=2024-03-31T23:59:59Z", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Expected text/csv content type, got %s", ct)
+	}
+
+	want := "timestamp,type,metric,value\n2024-03-01T08:00:00Z,sleep,duration_minutes,90.5\n"
+	if w.Body.String() != want {
+		t.Errorf("Body = %q, want %q", w.Body.String(), want)
+	}
+
+	if captured.ChildID != "child-1" || len(captured.Types) != 2 {
+		t.Errorf("Unexpected filter %+v", captured)
+	}
+	if captured.From == nil || captured.To == nil {
+		t.Error("Expected from and to to be parsed")
+	}
+}
+
+func TestDataset_StreamErrors(t *testing.T) {
+	at := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	many := make([]DataPoint, 1000)
+	for i := range many {
+		many[i] = DataPoint{Timestamp: at.Add(time.Duration(i) * time.Minute), Type: DatasetFeeding, Metric: MetricAmountML, Value: 120}
+	}
+
+	tests := []struct {
+		name       string
+		points     []DataPoint
+		wantStatus int
+		wantRows   bool
+	}{
+		// Nothing has been sent yet, so the error can still be reported
+		{"before any rows", many[:1], http.StatusInternalServerError, false},
+		// The first rows have gone out; the download just stops
+		{"after rows were sent", many, http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				datasetFn: func(ctx context.Context, filter *DatasetFilter) ([]DataPoint, error) {
+					return tt.points, nil
+				},
+				streamErr: errors.New("connection reset"),
+			}
+
+			req := httptest.NewRequest("GET", "/children/child-1/dataset.csv", http.NoBody)
+			w := httptest.NewRecorder()
+			setupRouter(svc).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if hasRows := strings.HasPrefix(w.Body.String(), "timestamp,type"); hasRows != tt.wantRows {
+				t.Errorf("Body = %.80q", w.Body.String())
+			}
+			if !tt.wantRows && w.Header().Get("Content-Disposition") != "" {
+				t.Error("Error response should not be served as an attachment")
+			}
+			if ct := w.Header().Get("Content-Type"); !tt.wantRows && !strings.HasPrefix(ct, "application/json") {
+				t.Errorf("Error response served as %s", ct)
+			}
+		})
+	}
+}
+
+func TestDataset_InvalidDate(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/children/child-1/dataset.csv?from=yesterday", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestDataset_InvalidAsOf(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/children/child-1/dataset.csv?as_of=last-week", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestDataset_UnsupportedType(t *testing.T) {
+	svc := &mockService{
+		datasetFn: func(ctx context.Context, filter *DatasetFilter) ([]DataPoint, error) {
+			return nil, fmt.Errorf("%w: diaper", ErrUnsupportedType)
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/children/child-1/dataset.csv?types=diaper", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestDataset_Access(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"unknown child", ErrChildNotFound, http.StatusNotFound},
+		{"not a member", ErrNotMember, http.StatusForbidden},
+		{"hidden type", fmt.Errorf("%w: feeding", visibility.ErrHidden), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter(&mockService{
+				datasetFn: func(ctx context.Context, filter *DatasetFilter) ([]DataPoint, error) {
+					return nil, tt.err
+				},
+			})
+
+			req := httptest.NewRequest("GET", "/children/child-1/dataset.csv", http.NoBody)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestDataset_Encrypted(t *testing.T) {
+	svc := &mockService{
+		datasetFn: func(ctx context.Context, filter *DatasetFilter) ([]DataPoint, error) {
+			return []DataPoint{}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/children/child-1/dataset.csv", http.NoBody)
+	req.Header.Set(HeaderPassphrase, "correct horse battery")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Expected application/zip, got %s", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename=dataset.csv.zip` {
+		t.Errorf("Unexpected Content-Disposition %q", cd)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("Expected a zip archive: %v", err)
+	}
+	if len(r.File) != 1 || r.File[0].Name != "dataset.csv" || r.File[0].Flags&0x1 == 0 {
+		t.Errorf("Expected one encrypted dataset.csv, got %+v", r.File)
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("timestamp,type")) {
+		t.Error("Archive should not contain the CSV in the clear")
+	}
+}
+
+func TestDataset_EncryptionRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		required   bool
+		passphrase string
+		wantStatus int
+	}{
+		{"optional", false, "", http.StatusOK},
+		{"required without passphrase", true, "", http.StatusBadRequest},
+		{"short passphrase", false, "hunter2", http.StatusBadRequest},
+		{"required with passphrase", true, "correct horse battery", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				passphraseRequiredFn: func(ctx context.Context, childID string) (bool, error) {
+					if childID != "child-1" {
+						t.Errorf("Unexpected childID %q", childID)
+					}
+					return tt.required, nil
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("GET", "/children/child-1/dataset.csv", http.NoBody)
+			if tt.passphrase != "" {
+				req.Header.Set(HeaderPassphrase, tt.passphrase)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDataset_EncryptedError(t *testing.T) {
+	svc := &mockService{
+		datasetFn: func(ctx context.Context, filter *DatasetFilter) ([]DataPoint, error) {
+			return nil, fmt.Errorf("%w: diaper", ErrUnsupportedType)
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/children/child-1/dataset.csv?types=diaper", http.NoBody)
+	req.Header.Set(HeaderPassphrase, "correct horse battery")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unsupported dataset type") {
+		t.Errorf("Expected the error as it was, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDataset_EncryptedStreamError(t *testing.T) {
+	// Far more than the csv writer holds back, so the failure comes after
+	// rows were written into the archive
+	at := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	many := make([]DataPoint, 1000)
+	for i := range many {
+		many[i] = DataPoint{Timestamp: at.Add(time.Duration(i) * time.Minute), Type: DatasetFeeding, Metric: MetricAmountML, Value: 120}
+	}
+	svc := &mockService{
+		datasetFn: func(ctx context.Context, filter *DatasetFilter) ([]DataPoint, error) {
+			return many, nil
+		},
+		streamErr: errors.New("connection reset"),
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/children/child-1/dataset.csv", http.NoBody)
+	req.Header.Set(HeaderPassphrase, "correct horse battery")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "connection reset") {
+		t.Fatalf("Expected a 500 rather than a truncated archive, got %d: %.80q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Error response served as %s", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != "" {
+		t.Errorf("Error response should not be served as an attachment, got %q", cd)
+	}
+}
+
+func TestUpdateSettings_NotAdmin(t *testing.T) {
+	svc := &mockService{
+		updateSettingsFn: func(ctx context.Context, userID, familyID string, req *UpdateSettingsRequest) (*Settings, error) {
+			return nil, ErrNotAdmin
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("PUT", "/families/family-1/export-settings", strings.NewReader(`{"require_passphrase":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,56 @@
+package export
+
+import "time"
+
+type DatasetType string
+
+const (
+	DatasetSleep   DatasetType = "sleep"
+	DatasetFeeding DatasetType = "feeding"
+)
+
+// Metric names emitted in the long-format dataset
+const (
+	MetricDurationMinutes = "duration_minutes"
+	MetricQuality         = "quality"
+	MetricAmountML        = "amount_ml"
+	MetricAmountOz        = "amount_oz"
+)
+
+// DataPoint is a single row of the long-format dataset: one measurement of
+// one metric at one point in time
+type DataPoint struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Type      DatasetType `json:"type"`
+	Metric    string      `json:"metric"`
+	Value     float64     `json:"value"`
+}
+
+type DatasetFilter struct {
+	ChildID string
+	Types   []DatasetType
+	From    *time.Time
+	To      *time.Time
+	// AsOf rebuilds the dataset from the records as they stood at that time
+	AsOf *time.Time
+}
+
+// HeaderPassphrase asks for an export as an encrypted zip archive. The
+// passphrase is only used to derive the key and is never stored or logged.
+const HeaderPassphrase = "X-Export-Passphrase"
+
+// MinPassphraseLength keeps passphrases long enough to resist guessing
+// against a copy of the archive
+const MinPassphraseLength = 10
+
+// Settings are a family's export rules. With RequirePassphrase on, exports
+// of the family's children are only given out as encrypted archives.
+type Settings struct {
+	FamilyID          string     `json:"family_id"`
+	RequirePassphrase bool       `json:"require_passphrase"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"`
+}
+
+type UpdateSettingsRequest struct {
+	RequirePassphrase *bool `json:"require_passphrase" binding:"required"`
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	GetSettings(ctx context.Context, familyID string) (*Settings, error)
+	UpsertSettings(ctx context.Context, s *Settings) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the export settings table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "family_export_settings")
+}
+
+// GetSettings returns nil when the family has never saved any
+func (r *repository) GetSettings(ctx context.Context, familyID string) (*Settings, error) {
+	query := `
+		SELECT family_id, require_passphrase, updated_at
+		FROM family_export_settings
+		WHERE family_id = $1
+	`
+
+	var s Settings
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(ctx, query, familyID).Scan(&s.FamilyID, &s.RequirePassphrase, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.UpdatedAt = &updatedAt
+	return &s, nil
+}
+
+func (r *repository) UpsertSettings(ctx context.Context, s *Settings) error {
+	query := `
+		INSERT INTO family_export_settings (family_id, require_passphrase, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (family_id) DO UPDATE
+		SET require_passphrase = $2, updated_at = $3
+	`
+
+	_, err := r.db.ExecContext(ctx, query, s.FamilyID, s.RequirePassphrase, s.UpdatedAt)
+	return err
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_GetSettings(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT family_id, require_passphrase, updated_at").
+		WithArgs("family-1").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id", "require_passphrase", "updated_at"}).AddRow("family-1", true, now))
+
+	settings, err := repo.GetSettings(context.Background(), "family-1")
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if !settings.RequirePassphrase || settings.UpdatedAt == nil {
+		t.Errorf("GetSettings() = %+v", settings)
+	}
+}
+
+func TestRepository_GetSettings_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT family_id, require_passphrase, updated_at").
+		WithArgs("family-1").
+		WillReturnError(sql.ErrNoRows)
+
+	settings, err := repo.GetSettings(context.Background(), "family-1")
+	if err != nil || settings != nil {
+		t.Errorf("GetSettings() = %v, %v; want nil, nil", settings, err)
+	}
+}
+
+func TestRepository_UpsertSettings(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO family_export_settings").
+		WithArgs("family-1", true, &now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.UpsertSettings(context.Background(), &Settings{FamilyID: "family-1", RequirePassphrase: true, UpdatedAt: &now}); err != nil {
+		t.Fatalf("UpsertSettings() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
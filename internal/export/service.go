@@ -0,0 +1,403 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/measure"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrUnsupportedType    = errors.New("unsupported dataset type; supported types are sleep and feeding")
+	ErrChildNotFound      = errors.New("child not found")
+	ErrNotMember          = errors.New("user is not a member of this family")
+	ErrNotAdmin           = errors.New("only admins can change export settings")
+	ErrPassphraseRequired = errors.New("this family only allows encrypted exports; send a passphrase in " + HeaderPassphrase)
+	ErrWeakPassphrase     = fmt.Errorf("export passphrase must be at least %d characters", MinPassphraseLength)
+)
+
+// SupportedTypes lists the record types that can be exported, in default order
+var SupportedTypes = []DatasetType{DatasetSleep, DatasetFeeding}
+
+type Service interface {
+	// Dataset returns ErrChildNotFound, ErrNotMember or visibility.ErrHidden
+	// before any rows when userID may not export the requested types
+	Dataset(ctx context.Context, userID string, filter *DatasetFilter) (iter.Seq2[DataPoint, error], error)
+	GetSettings(ctx context.Context, userID, familyID string) (*Settings, error)
+	UpdateSettings(ctx context.Context, userID, familyID string, req *UpdateSettingsRequest) (*Settings, error)
+	// PassphraseRequired reports whether the child's family only allows
+	// encrypted exports
+	PassphraseRequired(ctx context.Context, childID string) (bool, error)
+}
+
+type service struct {
+	repo           Repository
+	familyService  family.Service
+	sleepService   sleep.Service
+	feedingService feeding.Service
+	history        audit.Store
+	visibility     visibility.Service
+	now            func() time.Time
+}
+
+func NewService(
+	repo Repository,
+	familyService family.Service,
+	sleepService sleep.Service,
+	feedingService feeding.Service,
+	history audit.Store,
+	opts ...Option,
+) Service {
+	s := &service{
+		repo:           repo,
+		familyService:  familyService,
+		sleepService:   sleepService,
+		feedingService: feedingService,
+		history:        history,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether export settings can be read, which every
+// export checks first
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) GetSettings(ctx context.Context, userID, familyID string) (*Settings, error) {
+	if _, err := s.memberRole(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+	return s.settings(ctx, familyID)
+}
+
+func (s *service) UpdateSettings(ctx context.Context, userID, familyID string, req *UpdateSettingsRequest) (*Settings, error) {
+	role, err := s.memberRole(ctx, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "admin" {
+		return nil, ErrNotAdmin
+	}
+
+	now := s.now()
+	settings := &Settings{
+		FamilyID:          familyID,
+		RequirePassphrase: *req.RequirePassphrase,
+		UpdatedAt:         &now,
+	}
+	if err := s.repo.UpsertSettings(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save export settings: %w", err)
+	}
+	return settings, nil
+}
+
+// PassphraseRequired leaves unknown children to the export itself, which
+// answers for them
+func (s *service) PassphraseRequired(ctx context.Context, childID string) (bool, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return false, nil
+	}
+
+	settings, err := s.settings(ctx, child.FamilyID)
+	if err != nil {
+		return false, err
+	}
+	return settings.RequirePassphrase, nil
+}
+
+// settings returns the family's stored settings, or the defaults when an
+// admin has never saved any
+func (s *service) settings(ctx context.Context, familyID string) (*Settings, error) {
+	settings, err := s.repo.GetSettings(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export settings: %w", err)
+	}
+	if settings == nil {
+		settings = &Settings{FamilyID: familyID}
+	}
+	return settings, nil
+}
+
+func (s *service) memberRole(ctx context.Context, familyID, userID string) (string, error) {
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return "", ErrNotMember
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// Dataset flattens the child's records into timestamp/type/metric/value rows,
+// ordered by timestamp. No types in the filter means all supported types not
+// hidden from the user; asking for a hidden type fails. Rows are read from
+// each type's records as they are consumed, so the dataset is never held in
+// memory; an unsupported type fails up front.
+func (s *service) Dataset(ctx context.Context, userID string, filter *DatasetFilter) (iter.Seq2[DataPoint, error], error) {
+	child, err := s.familyService.GetChild(ctx, filter.ChildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+	if _, err := s.memberRole(ctx, child.FamilyID, userID); err != nil {
+		return nil, err
+	}
+	hidden, err := s.hiddenTypes(ctx, userID, child.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	types := filter.Types
+	if len(types) == 0 {
+		types = slices.DeleteFunc(slices.Clone(SupportedTypes), func(t DatasetType) bool {
+			return slices.Contains(hidden, recordTypes[t])
+		})
+	}
+
+	streams := make([]iter.Seq2[DataPoint, error], 0, len(types))
+	for _, t := range types {
+		recordType, ok := recordTypes[t]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+		}
+		if slices.Contains(hidden, recordType) {
+			return nil, fmt.Errorf("%w: %s", visibility.ErrHidden, t)
+		}
+		switch t {
+		case DatasetSleep:
+			streams = append(streams, s.sleepPoints(ctx, userID, filter))
+		case DatasetFeeding:
+			streams = append(streams, s.feedingPoints(ctx, userID, filter))
+		}
+	}
+	return mergePoints(streams), nil
+}
+
+// mergePoints interleaves streams that are each in timestamp order. Points
+// with equal timestamps keep the order of their streams.
+func mergePoints(streams []iter.Seq2[DataPoint, error]) iter.Seq2[DataPoint, error] {
+	if len(streams) == 1 {
+		return streams[0]
+	}
+	return func(yield func(DataPoint, error) bool) {
+		type head struct {
+			next  func() (DataPoint, error, bool)
+			point DataPoint
+		}
+
+		heads := make([]*head, 0, len(streams))
+		var stops []func()
+		defer func() {
+			for _, stop := range stops {
+				stop()
+			}
+		}()
+
+		// advance moves h to its stream's next point, reporting false when
+		// the stream is done. Errors end the merge.
+		advance := func(h *head) (bool, error) {
+			p, err, ok := h.next()
+			if !ok {
+				return false, nil
+			}
+			h.point = p
+			return true, err
+		}
+
+		for _, stream := range streams {
+			next, stop := iter.Pull2(stream)
+			stops = append(stops, stop)
+			h := &head{next: next}
+			ok, err := advance(h)
+			if err != nil {
+				yield(DataPoint{}, err)
+				return
+			}
+			if ok {
+				heads = append(heads, h)
+			}
+		}
+
+		for len(heads) > 0 {
+			first := 0
+			for i := 1; i < len(heads); i++ {
+				if heads[i].point.Timestamp.Before(heads[first].point.Timestamp) {
+					first = i
+				}
+			}
+			if !yield(heads[first].point, nil) {
+				return
+			}
+			ok, err := advance(heads[first])
+			if err != nil {
+				yield(DataPoint{}, err)
+				return
+			}
+			if !ok {
+				heads = slices.Delete(heads, first, first+1)
+			}
+		}
+	}
+}
+
+func (s *service) sleepPoints(ctx context.Context, userID string, filter *DatasetFilter) iter.Seq2[DataPoint, error] {
+	var sleeps iter.Seq2[sleep.Sleep, error]
+	if filter.AsOf != nil {
+		sleeps = historyAsOf(ctx, s.history, filter, audit.EntitySleep, func(sl *sleep.Sleep) time.Time {
+			return sl.StartTime
+		})
+	} else {
+		sleeps = s.sleepService.Stream(ctx, &sleep.SleepFilter{
+			ChildID:   filter.ChildID,
+			StartDate: filter.From,
+			EndDate:   filter.To,
+			ViewerID:  userID,
+		})
+	}
+
+	return func(yield func(DataPoint, error) bool) {
+		for sl, err := range sleeps {
+			if err != nil {
+				yield(DataPoint{}, fmt.Errorf("failed to list sleep: %w", err))
+				return
+			}
+			// The stream leaves out other members' private sleep, but
+			// history does not
+			if !sl.VisibleTo(userID) {
+				continue
+			}
+			if d, ok := sl.Duration(); ok {
+				if !yield(DataPoint{
+					Timestamp: sl.StartTime,
+					Type:      DatasetSleep,
+					Metric:    MetricDurationMinutes,
+					Value:     d.Minutes(),
+				}, nil) {
+					return
+				}
+			}
+			if sl.Quality != nil {
+				if !yield(DataPoint{
+					Timestamp: sl.StartTime,
+					Type:      DatasetSleep,
+					Metric:    MetricQuality,
+					Value:     float64(*sl.Quality),
+				}, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *service) feedingPoints(ctx context.Context, userID string, filter *DatasetFilter) iter.Seq2[DataPoint, error] {
+	var feedings iter.Seq2[feeding.Feeding, error]
+	if filter.AsOf != nil {
+		feedings = historyAsOf(ctx, s.history, filter, audit.EntityFeeding, func(f *feeding.Feeding) time.Time {
+			return f.StartTime
+		})
+	} else {
+		feedings = s.feedingService.Stream(ctx, &feeding.FeedingFilter{
+			ChildID:   filter.ChildID,
+			StartDate: filter.From,
+			EndDate:   filter.To,
+			ViewerID:  userID,
+		})
+	}
+
+	return func(yield func(DataPoint, error) bool) {
+		for f, err := range feedings {
+			if err != nil {
+				yield(DataPoint{}, fmt.Errorf("failed to list feedings: %w", err))
+				return
+			}
+			if !f.VisibleTo(userID) {
+				continue
+			}
+			if d, ok := f.Duration(); ok {
+				if !yield(DataPoint{
+					Timestamp: f.StartTime,
+					Type:      DatasetFeeding,
+					Metric:    MetricDurationMinutes,
+					Value:     d.Minutes(),
+				}, nil) {
+					return
+				}
+			}
+			if q, ok := f.Quantity(); ok {
+				metric := MetricAmountML
+				if q.Unit == measure.FluidOunce {
+					metric = MetricAmountOz
+				}
+				if !yield(DataPoint{
+					Timestamp: f.StartTime,
+					Type:      DatasetFeeding,
+					Metric:    metric,
+					Value:     q.Value,
+				}, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// historyAsOf rebuilds the child's records of one type as they stood at
+// filter.AsOf, yielding those that started within the from/to window in
+// start order. History is decoded in memory, so only live exports stream
+// from the database.
+func historyAsOf[T any](ctx context.Context, history audit.Store, filter *DatasetFilter, entityType audit.EntityType, start func(*T) time.Time) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if history == nil {
+			yield(zero, audit.ErrNoHistory)
+			return
+		}
+
+		versions, err := history.AsOf(ctx, filter.ChildID, entityType, *filter.AsOf)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		records, err := audit.Decode[T](versions)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		records = slices.DeleteFunc(records, func(r T) bool {
+			t := start(&r)
+			return (filter.From != nil && t.Before(*filter.From)) || (filter.To != nil && t.After(*filter.To))
+		})
+		slices.SortStableFunc(records, func(a, b T) int {
+			return start(&a).Compare(start(&b))
+		})
+		for _, r := range records {
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+}
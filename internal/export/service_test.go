@@ -0,0 +1,374 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/testutil/factory"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type mockSleepService struct {
+	sleep.Service
+	sleeps  []sleep.Sleep
+	listErr error
+}
+
+func (m *mockSleepService) Stream(ctx context.Context, filter *sleep.SleepFilter) iter.Seq2[sleep.Sleep, error] {
+	return func(yield func(sleep.Sleep, error) bool) {
+		if m.listErr != nil {
+			yield(sleep.Sleep{}, m.listErr)
+			return
+		}
+		for _, sl := range m.sleeps {
+			if !yield(sl, nil) {
+				return
+			}
+		}
+	}
+}
+
+type mockFeedingService struct {
+	feeding.Service
+	feedings []feeding.Feeding
+}
+
+func (m *mockFeedingService) Stream(ctx context.Context, filter *feeding.FeedingFilter) iter.Seq2[feeding.Feeding, error] {
+	return func(yield func(feeding.Feeding, error) bool) {
+		for _, f := range m.feedings {
+			if !yield(f, nil) {
+				return
+			}
+		}
+	}
+}
+
+// collect reads a whole dataset, stopping at the first error
+func collect(points iter.Seq2[DataPoint, error], err error) ([]DataPoint, error) {
+	if err != nil {
+		return nil, err
+	}
+	var all []DataPoint
+	for p, err := range points {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, p)
+	}
+	return all, nil
+}
+
+// fakeHistory returns fixed versions for any as-of query
+type fakeHistory struct {
+	audit.Store
+	versions map[audit.EntityType][]audit.Version
+}
+
+func (f *fakeHistory) AsOf(ctx context.Context, childID string, entityType audit.EntityType, asOf time.Time) ([]audit.Version, error) {
+	return f.versions[entityType], nil
+}
+
+func TestService_Dataset(t *testing.T) {
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	sleepEnd := base.Add(90 * time.Minute)
+	feedEnd := base.Add(-40 * time.Minute)
+	quality := 4
+	amount := 4.0
+
+	sleepSvc := &mockSleepService{sleeps: []sleep.Sleep{
+		{ID: "s1", StartTime: base, EndTime: &sleepEnd, Quality: &quality},
+		{ID: "s2", StartTime: base.Add(time.Hour)}, // still in progress
+	}}
+	feedingSvc := &mockFeedingService{feedings: []feeding.Feeding{
+		{ID: "f1", StartTime: base.Add(-time.Hour), EndTime: &feedEnd, Amount: &amount, Unit: "oz"},
+	}}
+	svc := NewService(nil, newFamily(), sleepSvc, feedingSvc, nil)
+
+	points, err := collect(svc.Dataset(context.Background(), "user-1", &DatasetFilter{ChildID: "child-1"}))
+	if err != nil {
+		t.Fatalf("Dataset() error = %v", err)
+	}
+
+	want := []DataPoint{
+		{Timestamp: base.Add(-time.Hour), Type: DatasetFeeding, Metric: MetricDurationMinutes, Value: 20},
+		{Timestamp: base.Add(-time.Hour), Type: DatasetFeeding, Metric: MetricAmountOz, Value: 4},
+		{Timestamp: base, Type: DatasetSleep, Metric: MetricDurationMinutes, Value: 90},
+		{Timestamp: base, Type: DatasetSleep, Metric: MetricQuality, Value: 4},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("Dataset() returned %d points, want %d: %+v", len(points), len(want), points)
+	}
+	for i := range want {
+		if points[i] != want[i] {
+			t.Errorf("point[%d] = %+v, want %+v", i, points[i], want[i])
+		}
+	}
+}
+
+func TestService_Dataset_Interleaves(t *testing.T) {
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	end := func(h int) *time.Time { e := at(h).Add(30 * time.Minute); return &e }
+
+	sleepSvc := &mockSleepService{sleeps: []sleep.Sleep{
+		{StartTime: at(1), EndTime: end(1)},
+		{StartTime: at(3), EndTime: end(3)},
+	}}
+	feedingSvc := &mockFeedingService{feedings: []feeding.Feeding{
+		{StartTime: at(0), EndTime: end(0)},
+		{StartTime: at(3), EndTime: end(3)},
+		{StartTime: at(4), EndTime: end(4)},
+	}}
+	svc := NewService(nil, newFamily(), sleepSvc, feedingSvc, nil)
+
+	points, err := collect(svc.Dataset(context.Background(), "user-1", &DatasetFilter{ChildID: "child-1"}))
+	if err != nil {
+		t.Fatalf("Dataset() error = %v", err)
+	}
+
+	// Ties go to the type listed first, sleep
+	want := []struct {
+		hour int
+		typ  DatasetType
+	}{{0, DatasetFeeding}, {1, DatasetSleep}, {3, DatasetSleep}, {3, DatasetFeeding}, {4, DatasetFeeding}}
+	if len(points) != len(want) {
+		t.Fatalf("Dataset() = %+v", points)
+	}
+	for i, w := range want {
+		if !points[i].Timestamp.Equal(at(w.hour)) || points[i].Type != w.typ {
+			t.Errorf("point[%d] = %v %s, want hour %d %s", i, points[i].Timestamp, points[i].Type, w.hour, w.typ)
+		}
+	}
+}
+
+func TestService_Dataset_FiltersTypes(t *testing.T) {
+	end := time.Now()
+	sleepSvc := &mockSleepService{sleeps: []sleep.Sleep{factory.Sleep()}}
+	feedingSvc := &mockFeedingService{feedings: []feeding.Feeding{{StartTime: end.Add(-time.Hour), EndTime: &end}}}
+	svc := NewService(nil, newFamily(), sleepSvc, feedingSvc, nil)
+
+	points, err := collect(svc.Dataset(context.Background(), "user-1", &DatasetFilter{ChildID: "child-1", Types: []DatasetType{DatasetFeeding}}))
+	if err != nil {
+		t.Fatalf("Dataset() error = %v", err)
+	}
+	if len(points) != 1 || points[0].Type != DatasetFeeding {
+		t.Errorf("Dataset() = %+v, want only feeding points", points)
+	}
+}
+
+func TestService_Dataset_UnsupportedType(t *testing.T) {
+	svc := NewService(nil, newFamily(), &mockSleepService{}, &mockFeedingService{}, nil)
+
+	_, err := collect(svc.Dataset(context.Background(), "user-1", &DatasetFilter{ChildID: "child-1", Types: []DatasetType{"diaper"}}))
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Dataset() error = %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestService_Dataset_ServiceError(t *testing.T) {
+	svc := NewService(nil, newFamily(), &mockSleepService{listErr: errors.New("database error")}, &mockFeedingService{}, nil)
+
+	if _, err := collect(svc.Dataset(context.Background(), "user-1", &DatasetFilter{ChildID: "child-1"})); err == nil {
+		t.Error("Dataset() should return error when a module fails")
+	}
+}
+
+func TestService_Dataset_AsOf(t *testing.T) {
+	asOf := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	history := &fakeHistory{versions: map[audit.EntityType][]audit.Version{
+		audit.EntitySleep: {
+			{EntityType: audit.EntitySleep, EntityID: "s1", Data: []byte(`{"id":"s1","start_time":"2024-03-02T08:00:00Z","end_time":"2024-03-02T09:00:00Z"}`)},
+			{EntityType: audit.EntitySleep, EntityID: "s0", Data: []byte(`{"id":"s0","start_time":"2024-02-20T08:00:00Z","end_time":"2024-02-20T09:00:00Z"}`)},
+		},
+	}}
+	// The live service has since been edited; as_of must not read from it
+	sleepSvc := &mockSleepService{listErr: errors.New("should not be called")}
+	svc := NewService(nil, newFamily(), sleepSvc, &mockFeedingService{}, history)
+
+	points, err := collect(svc.Dataset(context.Background(), "user-1", &DatasetFilter{
+		ChildID: "child-1",
+		Types:   []DatasetType{DatasetSleep},
+		From:    &from,
+		AsOf:    &asOf,
+	}))
+	if err != nil {
+		t.Fatalf("Dataset() error = %v", err)
+	}
+
+	want := DataPoint{Timestamp: time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC), Type: DatasetSleep, Metric: MetricDurationMinutes, Value: 60}
+	if len(points) != 1 || points[0] != want {
+		t.Errorf("Dataset() = %+v, want [%+v]", points, want)
+	}
+}
+
+func TestService_Dataset_AsOfWithoutHistory(t *testing.T) {
+	svc := NewService(nil, newFamily(), &mockSleepService{}, &mockFeedingService{}, nil)
+
+	asOf := time.Now()
+	_, err := collect(svc.Dataset(context.Background(), "user-1", &DatasetFilter{ChildID: "child-1", AsOf: &asOf}))
+	if !errors.Is(err, audit.ErrNoHistory) {
+		t.Errorf("Dataset() error = %v, want %v", err, audit.ErrNoHistory)
+	}
+}
+
+type mockRepository struct {
+	settings map[string]*Settings
+}
+
+func (m *mockRepository) GetSettings(ctx context.Context, familyID string) (*Settings, error) {
+	return m.settings[familyID], nil
+}
+
+func (m *mockRepository) UpsertSettings(ctx context.Context, s *Settings) error {
+	m.settings[s.FamilyID] = s
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	roles    map[string]string
+	children map[string]*family.Child
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if role, ok := m.roles[familyID+"/"+userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+// newFamily has user-1 as a member of child-1's family
+func newFamily() *mockFamilyService {
+	child := factory.Child()
+	return &mockFamilyService{
+		roles:    map[string]string{factory.FamilyID + "/" + factory.UserID: "member"},
+		children: map[string]*family.Child{child.ID: &child},
+	}
+}
+
+// mockVisibilityService hides feeding from every member
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) GetMemberVisibility(ctx context.Context, requesterID, familyID, userID string) (*visibility.MemberVisibility, error) {
+	return &visibility.MemberVisibility{FamilyID: familyID, UserID: userID, HiddenTypes: []visibility.RecordType{visibility.RecordFeeding}}, nil
+}
+
+func TestService_Dataset_Access(t *testing.T) {
+	svc := NewService(nil, newFamily(), &mockSleepService{}, &mockFeedingService{}, nil)
+	ctx := context.Background()
+
+	if _, err := svc.Dataset(ctx, "user-2", &DatasetFilter{ChildID: "child-1"}); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Dataset() by non-member error = %v, want ErrNotMember", err)
+	}
+	if _, err := svc.Dataset(ctx, "user-1", &DatasetFilter{ChildID: "child-9"}); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("Dataset() of unknown child error = %v, want ErrChildNotFound", err)
+	}
+}
+
+func TestService_Dataset_Hidden(t *testing.T) {
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	end := base.Add(time.Hour)
+	svc := NewService(nil, newFamily(),
+		&mockSleepService{sleeps: []sleep.Sleep{factory.Sleep(func(s *sleep.Sleep) {
+			s.StartTime, s.EndTime = base, &end
+		})}},
+		&mockFeedingService{feedings: []feeding.Feeding{{StartTime: base, EndTime: &end}}},
+		nil, WithVisibility(&mockVisibilityService{}))
+	ctx := context.Background()
+
+	points, err := collect(svc.Dataset(ctx, "user-1", &DatasetFilter{ChildID: "child-1"}))
+	if err != nil {
+		t.Fatalf("Dataset() error = %v", err)
+	}
+	if len(points) != 1 || points[0].Type != DatasetSleep {
+		t.Errorf("Dataset() = %+v, want only sleep points", points)
+	}
+
+	_, err = svc.Dataset(ctx, "user-1", &DatasetFilter{ChildID: "child-1", Types: []DatasetType{DatasetFeeding}})
+	if !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Dataset() of hidden type error = %v, want ErrHidden", err)
+	}
+}
+
+func TestService_Settings(t *testing.T) {
+	repo := &mockRepository{settings: map[string]*Settings{}}
+	svc := NewService(repo, &mockFamilyService{
+		roles: map[string]string{"family-1/admin-1": "admin", "family-1/member-1": "member"},
+		children: map[string]*family.Child{
+			"child-1": {ID: "child-1", FamilyID: "family-1"},
+			"child-2": {ID: "child-2", FamilyID: "family-2"},
+		},
+	}, &mockSleepService{}, &mockFeedingService{}, nil)
+	ctx := context.Background()
+
+	settings, err := svc.GetSettings(ctx, "member-1", "family-1")
+	if err != nil || settings.RequirePassphrase {
+		t.Fatalf("GetSettings() = %+v, %v; want encryption optional by default", settings, err)
+	}
+
+	on := true
+	if _, err := svc.UpdateSettings(ctx, "member-1", "family-1", &UpdateSettingsRequest{RequirePassphrase: &on}); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("UpdateSettings() by member error = %v, want ErrNotAdmin", err)
+	}
+	if _, err := svc.GetSettings(ctx, "stranger", "family-1"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("GetSettings() by stranger error = %v, want ErrNotMember", err)
+	}
+	if _, err := svc.UpdateSettings(ctx, "admin-1", "family-1", &UpdateSettingsRequest{RequirePassphrase: &on}); err != nil {
+		t.Fatalf("UpdateSettings() error = %v", err)
+	}
+
+	tests := []struct {
+		childID string
+		want    bool
+	}{
+		{"child-1", true},
+		{"child-2", false}, // another family
+		{"missing", false}, // left to the export to answer
+	}
+	for _, tt := range tests {
+		if got, err := svc.PassphraseRequired(ctx, tt.childID); err != nil || got != tt.want {
+			t.Errorf("PassphraseRequired(%s) = %v, %v; want %v", tt.childID, got, err, tt.want)
+		}
+	}
+}
+
+func BenchmarkService_Dataset(b *testing.B) {
+	// Three months of interleaved sleep and feeds
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	quality := 3
+	amount := 120.0
+	var sleeps []sleep.Sleep
+	var feedings []feeding.Feeding
+	for i := range 90 * 10 {
+		start := base.Add(time.Duration(i) * 144 * time.Minute)
+		end := start.Add(40 * time.Minute)
+		if i%10 < 8 {
+			sleeps = append(sleeps, sleep.Sleep{ID: fmt.Sprintf("s%d", i), StartTime: start.Add(time.Hour), EndTime: &end, Quality: &quality})
+		}
+		feedings = append(feedings, feeding.Feeding{ID: fmt.Sprintf("f%d", i), StartTime: start, EndTime: &end, Amount: &amount, Unit: "ml"})
+	}
+	svc := NewService(nil, newFamily(), &mockSleepService{sleeps: sleeps}, &mockFeedingService{feedings: feedings}, nil)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := collect(svc.Dataset(ctx, "user-1", &DatasetFilter{ChildID: "child-1"})); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
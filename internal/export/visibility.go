@@ -0,0 +1,35 @@
+package export
+
+import (
+	"context"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type Option func(*service)
+
+// WithVisibility leaves the record types hidden from the exporting member out
+// of their datasets
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+// recordTypes maps each dataset type to the record type that hides it
+var recordTypes = map[DatasetType]visibility.RecordType{
+	DatasetSleep:   visibility.RecordSleep,
+	DatasetFeeding: visibility.RecordFeeding,
+}
+
+// hiddenTypes returns the record types hidden from the user in the family
+func (s *service) hiddenTypes(ctx context.Context, userID, familyID string) ([]visibility.RecordType, error) {
+	if s.visibility == nil {
+		return nil, nil
+	}
+	v, err := s.visibility.GetMemberVisibility(ctx, userID, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return v.HiddenTypes, nil
+}
@@ -1,7 +1,13 @@
 package family
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/qr"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,6 +30,11 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 
 	rg.GET("/:familyId/members", h.listMembers)
 	rg.POST("/:familyId/invite", h.inviteMember)
+	rg.GET("/:familyId/invites", h.listInvites)
+	rg.POST("/:familyId/invites/:inviteId/resend", h.resendInvite)
+	rg.DELETE("/:familyId/invites/:inviteId", h.revokeInvite)
+	rg.POST("/:familyId/invites/qr", h.createQRInvite)
+	rg.POST("/join", h.acceptLinkInvite)
 	rg.POST("/:familyId/join", h.joinFamily)
 	rg.DELETE("/:familyId/members/:userId", h.removeMember)
 
@@ -133,11 +144,118 @@ func (h *Handler) inviteMember(c *gin.Context) {
 	}
 
 	familyID := c.Param("familyId")
-	if err := h.service.InviteMember(c.Request.Context(), familyID, &req); err != nil {
+	userID := c.GetString("user_id")
+
+	invite, err := h.service.InviteMember(c.Request.Context(), familyID, userID, &req)
+	if err != nil {
+		respondInviteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invite)
+}
+
+func (h *Handler) listInvites(c *gin.Context) {
+	familyID := c.Param("familyId")
+	userID := c.GetString("user_id")
+
+	invites, err := h.service.ListInvites(c.Request.Context(), familyID, userID)
+	if err != nil {
+		respondInviteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invites)
+}
+
+func (h *Handler) resendInvite(c *gin.Context) {
+	familyID := c.Param("familyId")
+	inviteID := c.Param("inviteId")
+	userID := c.GetString("user_id")
+
+	invite, err := h.service.ResendInvite(c.Request.Context(), familyID, inviteID, userID)
+	if err != nil {
+		respondInviteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invite)
+}
+
+// qrScale is the pixels per module of invite QR codes, large enough to
+// scan from another phone's screen
+const qrScale = 8
+
+// createQRInvite returns a link invite as a PNG QR code. The invite's ID
+// and expiry come in headers, for revoking it or showing a countdown.
+func (h *Handler) createQRInvite(c *gin.Context) {
+	familyID := c.Param("familyId")
+	userID := c.GetString("user_id")
+
+	link, err := h.service.CreateLinkInvite(c.Request.Context(), familyID, userID)
+	if err != nil {
+		respondInviteError(c, err)
+		return
+	}
+
+	code, err := qr.Encode([]byte(link.URL))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	image, err := code.PNG(qrScale)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "invite sent"})
+
+	c.Header("X-Invite-Id", link.ID)
+	c.Header("X-Invite-Expires-At", link.ExpiresAt.UTC().Format(time.RFC3339))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusCreated, "image/png", image)
+}
+
+func (h *Handler) acceptLinkInvite(c *gin.Context) {
+	var req AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	family, err := h.service.AcceptLinkInvite(c.Request.Context(), req.Token, c.GetString("user_id"))
+	if err != nil {
+		respondInviteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, family)
+}
+
+func (h *Handler) revokeInvite(c *gin.Context) {
+	familyID := c.Param("familyId")
+	inviteID := c.Param("inviteId")
+	userID := c.GetString("user_id")
+
+	if err := h.service.RevokeInvite(c.Request.Context(), familyID, inviteID, userID); err != nil {
+		respondInviteError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondInviteError(c *gin.Context, err error) {
+	var throttled *InviteThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		c.Header("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, ErrNotAdmin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInviteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAlreadyMember), errors.Is(err, ErrInviteAccepted), errors.Is(err, ErrLinkInviteResend):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInviteExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
 
 func (h *Handler) joinFamily(c *gin.Context) {
@@ -168,6 +286,25 @@ func (h *Handler) removeMember(c *gin.Context) {
 
 func (h *Handler) listChildren(c *gin.Context) {
 	familyID := c.Param("familyId")
+	since, err := delta.ParseSince(c.Query("since"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if since != nil {
+		changes, err := h.service.ChildrenSince(c.Request.Context(), familyID, *since)
+		if errors.Is(err, delta.ErrSinceExpired) {
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, changes)
+		return
+	}
+
 	children, err := h.service.GetChildren(c.Request.Context(), familyID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -5,11 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"image/png"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/delta"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -27,11 +31,17 @@ type mockService struct {
 	leaveFamilyFn      func(ctx context.Context, familyID, userID string) error
 	getMemberRoleFn    func(ctx context.Context, familyID, userID string) (string, error)
 	getFamilyMembersFn func(ctx context.Context, familyID string) ([]MemberWithUser, error)
-	inviteMemberFn     func(ctx context.Context, familyID string, req *InviteRequest) error
+	inviteMemberFn     func(ctx context.Context, familyID, userID string, req *InviteRequest) (*Invite, error)
+	listInvitesFn      func(ctx context.Context, familyID, userID string) ([]Invite, error)
+	resendInviteFn     func(ctx context.Context, familyID, inviteID, userID string) (*Invite, error)
+	createLinkFn       func(ctx context.Context, familyID, userID string) (*LinkInvite, error)
+	acceptLinkFn       func(ctx context.Context, token, userID string) (*Family, error)
+	revokeInviteFn     func(ctx context.Context, familyID, inviteID, userID string) error
 	joinFamilyFn       func(ctx context.Context, familyID, userID string) (*Family, error)
 	removeMemberFn     func(ctx context.Context, familyID, userID string) error
 	addChildFn         func(ctx context.Context, familyID string, req *AddChildRequest) (*Child, error)
 	getChildrenFn      func(ctx context.Context, familyID string) ([]Child, error)
+	childrenSinceFn    func(ctx context.Context, familyID string, since time.Time) (*delta.Changes[Child], error)
 	getChildFn         func(ctx context.Context, childID string) (*Child, error)
 	updateChildFn      func(ctx context.Context, childID string, req *AddChildRequest) (*Child, error)
 	deleteChildFn      func(ctx context.Context, childID string) error
@@ -93,13 +103,52 @@ func (m *mockService) GetFamilyMembers(ctx context.Context, familyID string) ([]
 	return nil, nil
 }
 
-func (m *mockService) InviteMember(ctx context.Context, familyID string, req *InviteRequest) error {
+func (m *mockService) InviteMember(ctx context.Context, familyID, userID string, req *InviteRequest) (*Invite, error) {
 	if m.inviteMemberFn != nil {
-		return m.inviteMemberFn(ctx, familyID, req)
+		return m.inviteMemberFn(ctx, familyID, userID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ListInvites(ctx context.Context, familyID, userID string) ([]Invite, error) {
+	if m.listInvitesFn != nil {
+		return m.listInvitesFn(ctx, familyID, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ResendInvite(ctx context.Context, familyID, inviteID, userID string) (*Invite, error) {
+	if m.resendInviteFn != nil {
+		return m.resendInviteFn(ctx, familyID, inviteID, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) CreateLinkInvite(ctx context.Context, familyID, userID string) (*LinkInvite, error) {
+	if m.createLinkFn != nil {
+		return m.createLinkFn(ctx, familyID, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) AcceptLinkInvite(ctx context.Context, token, userID string) (*Family, error) {
+	if m.acceptLinkFn != nil {
+		return m.acceptLinkFn(ctx, token, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) RevokeInvite(ctx context.Context, familyID, inviteID, userID string) error {
+	if m.revokeInviteFn != nil {
+		return m.revokeInviteFn(ctx, familyID, inviteID, userID)
 	}
 	return nil
 }
 
+func (m *mockService) PurgeInvites(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
 func (m *mockService) JoinFamily(ctx context.Context, familyID, userID string) (*Family, error) {
 	if m.joinFamilyFn != nil {
 		return m.joinFamilyFn(ctx, familyID, userID)
@@ -128,6 +177,13 @@ func (m *mockService) GetChildren(ctx context.Context, familyID string) ([]Child
 	return nil, nil
 }
 
+func (m *mockService) ChildrenSince(ctx context.Context, familyID string, since time.Time) (*delta.Changes[Child], error) {
+	if m.childrenSinceFn != nil {
+		return m.childrenSinceFn(ctx, familyID, since)
+	}
+	return nil, nil
+}
+
 func (m *mockService) GetChild(ctx context.Context, childID string) (*Child, error) {
 	if m.getChildFn != nil {
 		return m.getChildFn(ctx, childID)
@@ -152,6 +208,7 @@ func (m *mockService) DeleteChild(ctx context.Context, childID string) error {
 // setupRouter creates a test router with auth context middleware
 func setupRouter(h *Handler) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 	// Middleware to set user_id in context (simulating auth middleware)
 	router.Use(func(c *gin.Context) {
 		c.Set("user_id", "test-user")
@@ -165,6 +222,7 @@ func setupRouter(h *Handler) *gin.Engine {
 // setupRouterWithUserID creates a test router with a specific user_id
 func setupRouterWithUserID(h *Handler, userID string) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 	router.Use(func(c *gin.Context) {
 		c.Set("user_id", userID)
 		c.Next()
@@ -823,14 +881,17 @@ func TestListMembers_ServiceError(t *testing.T) {
 
 func TestInviteMember_Success(t *testing.T) {
 	mock := &mockService{
-		inviteMemberFn: func(ctx context.Context, familyID string, req *InviteRequest) error {
+		inviteMemberFn: func(ctx context.Context, familyID, userID string, req *InviteRequest) (*Invite, error) {
 			if familyID != "family-123" {
 				t.Errorf("Expected familyID family-123, got %s", familyID)
 			}
+			if userID != "test-user" {
+				t.Errorf("Expected userID test-user, got %s", userID)
+			}
 			if req.Email != "invite@example.com" {
 				t.Errorf("Expected email invite@example.com, got %s", req.Email)
 			}
-			return nil
+			return &Invite{ID: "invite-1", FamilyID: familyID, Email: req.Email, Status: InviteStatusPending}, nil
 		},
 	}
 
@@ -847,13 +908,13 @@ func TestInviteMember_Success(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
 
-	var response map[string]string
+	var response Invite
 	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
 		t.Fatalf("Failed to parse response: %v", err)
 	}
 
-	if response["message"] != "invite sent" {
-		t.Errorf("Expected message 'invite sent', got %s", response["message"])
+	if response.ID != "invite-1" || response.Status != InviteStatusPending {
+		t.Errorf("Expected pending invite-1, got %+v", response)
 	}
 }
 
@@ -907,8 +968,8 @@ func TestInviteMember_ValidationError_InvalidJSON(t *testing.T) {
 
 func TestInviteMember_ServiceError(t *testing.T) {
 	mock := &mockService{
-		inviteMemberFn: func(ctx context.Context, familyID string, req *InviteRequest) error {
-			return errors.New("failed to send invite")
+		inviteMemberFn: func(ctx context.Context, familyID, userID string, req *InviteRequest) (*Invite, error) {
+			return nil, errors.New("failed to send invite")
 		},
 	}
 
@@ -926,6 +987,186 @@ func TestInviteMember_ServiceError(t *testing.T) {
 	}
 }
 
+func TestInviteMember_NotAdmin(t *testing.T) {
+	mock := &mockService{
+		inviteMemberFn: func(ctx context.Context, familyID, userID string, req *InviteRequest) (*Invite, error) {
+			return nil, ErrNotAdmin
+		},
+	}
+
+	handler := NewHandler(mock)
+	router := setupRouter(handler)
+
+	body := `{"email": "invite@example.com"}`
+	req := httptest.NewRequest("POST", "/families/family-123/invite", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestInviteMember_AlreadyMember(t *testing.T) {
+	mock := &mockService{
+		inviteMemberFn: func(ctx context.Context, familyID, userID string, req *InviteRequest) (*Invite, error) {
+			return nil, ErrAlreadyMember
+		},
+	}
+
+	handler := NewHandler(mock)
+	router := setupRouter(handler)
+
+	body := `{"email": "invite@example.com"}`
+	req := httptest.NewRequest("POST", "/families/family-123/invite", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", w.Code)
+	}
+}
+
+// ============================================================================
+// List Invites Tests
+// ============================================================================
+
+func TestListInvites_Success(t *testing.T) {
+	mock := &mockService{
+		listInvitesFn: func(ctx context.Context, familyID, userID string) ([]Invite, error) {
+			if familyID != "family-123" || userID != "test-user" {
+				t.Errorf("Unexpected familyID %s / userID %s", familyID, userID)
+			}
+			return []Invite{
+				{ID: "invite-1", Email: "a@example.com", Status: InviteStatusPending},
+				{ID: "invite-2", Email: "b@example.com", Status: InviteStatusExpired},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mock)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest("GET", "/families/family-123/invites", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response []Invite
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(response) != 2 || response[1].Status != InviteStatusExpired {
+		t.Errorf("Unexpected invites: %+v", response)
+	}
+}
+
+func TestListInvites_NotMember(t *testing.T) {
+	mock := &mockService{
+		listInvitesFn: func(ctx context.Context, familyID, userID string) ([]Invite, error) {
+			return nil, ErrNotMember
+		},
+	}
+
+	handler := NewHandler(mock)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest("GET", "/families/family-123/invites", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+// ============================================================================
+// Resend Invite Tests
+// ============================================================================
+
+func TestResendInvite_Success(t *testing.T) {
+	mock := &mockService{
+		resendInviteFn: func(ctx context.Context, familyID, inviteID, userID string) (*Invite, error) {
+			if inviteID != "invite-1" {
+				t.Errorf("Expected inviteID invite-1, got %s", inviteID)
+			}
+			return &Invite{ID: inviteID, SendCount: 2, Status: InviteStatusPending}, nil
+		},
+	}
+
+	handler := NewHandler(mock)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest("POST", "/families/family-123/invites/invite-1/resend", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestResendInvite_Throttled(t *testing.T) {
+	mock := &mockService{
+		resendInviteFn: func(ctx context.Context, familyID, inviteID, userID string) (*Invite, error) {
+			return nil, &InviteThrottledError{RetryAfter: 90 * time.Second}
+		},
+	}
+
+	handler := NewHandler(mock)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest("POST", "/families/family-123/invites/invite-1/resend", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "91" {
+		t.Errorf("Expected Retry-After 91, got %q", got)
+	}
+}
+
+func TestResendInvite_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"not found", ErrInviteNotFound, http.StatusNotFound},
+		{"accepted", ErrInviteAccepted, http.StatusConflict},
+		{"not admin", ErrNotAdmin, http.StatusForbidden},
+		{"other", errors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockService{
+				resendInviteFn: func(ctx context.Context, familyID, inviteID, userID string) (*Invite, error) {
+					return nil, tt.err
+				},
+			}
+
+			handler := NewHandler(mock)
+			router := setupRouter(handler)
+
+			req := httptest.NewRequest("POST", "/families/family-123/invites/invite-1/resend", http.NoBody)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
 // ============================================================================
 // Join Family Tests
 // ============================================================================
@@ -1163,6 +1404,67 @@ func TestListChildren_ServiceError(t *testing.T) {
 	}
 }
 
+func TestListChildren_Since(t *testing.T) {
+	mock := &mockService{
+		childrenSinceFn: func(ctx context.Context, familyID string, since time.Time) (*delta.Changes[Child], error) {
+			if !since.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("Expected since 2025-01-01, got %s", since)
+			}
+			return &delta.Changes[Child]{
+				Created: []Child{{ID: "child-1", Name: "Alice"}},
+				Updated: []Child{},
+				Deleted: []string{"child-2"},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mock)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest("GET", "/families/family-123/children?since=2025-01-01T00:00:00Z", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var response delta.Changes[Child]
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(response.Created) != 1 || len(response.Deleted) != 1 || response.Deleted[0] != "child-2" {
+		t.Errorf("Unexpected delta %+v", response)
+	}
+}
+
+func TestListChildren_SinceErrors(t *testing.T) {
+	mock := &mockService{
+		childrenSinceFn: func(ctx context.Context, familyID string, since time.Time) (*delta.Changes[Child], error) {
+			return nil, delta.ErrSinceExpired
+		},
+	}
+
+	handler := NewHandler(mock)
+	router := setupRouter(handler)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+	}{
+		{"since=yesterday", http.StatusBadRequest},
+		{"since=2020-01-01T00:00:00Z", http.StatusGone},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/families/family-123/children?"+tt.query, http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.query, tt.wantStatus, w.Code)
+		}
+	}
+}
+
 // ============================================================================
 // Add Child Tests
 // ============================================================================
@@ -1568,3 +1870,132 @@ func TestJoinFamily_UsesCorrectUserID(t *testing.T) {
 		t.Errorf("Expected userID joining-user-789, got %s", capturedUserID)
 	}
 }
+
+func TestCreateQRInvite_Success(t *testing.T) {
+	expires := time.Date(2024, 6, 8, 9, 0, 0, 0, time.UTC)
+	mock := &mockService{
+		createLinkFn: func(ctx context.Context, familyID, userID string) (*LinkInvite, error) {
+			return &LinkInvite{
+				Invite: Invite{ID: "invite-1", FamilyID: familyID, ExpiresAt: expires},
+				Token:  "token",
+				URL:    "https://babytrack.example.com/join?invite=token",
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mock)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest("POST", "/families/family-123/invites/qr", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Expected image/png, got %q", ct)
+	}
+	if _, err := png.Decode(w.Body); err != nil {
+		t.Errorf("Body is not a PNG: %v", err)
+	}
+	if got := w.Header().Get("X-Invite-Id"); got != "invite-1" {
+		t.Errorf("Expected X-Invite-Id invite-1, got %q", got)
+	}
+	if got := w.Header().Get("X-Invite-Expires-At"); got != "2024-06-08T09:00:00Z" {
+		t.Errorf("Expected X-Invite-Expires-At, got %q", got)
+	}
+}
+
+func TestCreateQRInvite_NotAdmin(t *testing.T) {
+	mock := &mockService{
+		createLinkFn: func(ctx context.Context, familyID, userID string) (*LinkInvite, error) {
+			return nil, ErrNotAdmin
+		},
+	}
+
+	handler := NewHandler(mock)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest("POST", "/families/family-123/invites/qr", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestAcceptLinkInvite(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+		want int
+	}{
+		{"joined", `{"token":"abc"}`, nil, http.StatusOK},
+		{"missing token", `{}`, nil, http.StatusBadRequest},
+		{"unknown", `{"token":"abc"}`, ErrInviteNotFound, http.StatusNotFound},
+		{"used", `{"token":"abc"}`, ErrInviteAccepted, http.StatusConflict},
+		{"expired", `{"token":"abc"}`, ErrInviteExpired, http.StatusGone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockService{
+				acceptLinkFn: func(ctx context.Context, token, userID string) (*Family, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &Family{ID: "family-123"}, nil
+				},
+			}
+
+			handler := NewHandler(mock)
+			router := setupRouter(handler)
+
+			req := httptest.NewRequest("POST", "/families/join", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRevokeInvite(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"revoked", nil, http.StatusNoContent},
+		{"not found", ErrInviteNotFound, http.StatusNotFound},
+		{"accepted", ErrInviteAccepted, http.StatusConflict},
+		{"not admin", ErrNotAdmin, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockService{
+				revokeInviteFn: func(ctx context.Context, familyID, inviteID, userID string) error {
+					return tt.err
+				},
+			}
+
+			handler := NewHandler(mock)
+			router := setupRouter(handler)
+
+			req := httptest.NewRequest("DELETE", "/families/family-123/invites/invite-1", http.NoBody)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package family
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/warnings"
+)
+
+const (
+	// InviteTTL is how long an invite can be accepted after it was last sent.
+	InviteTTL = 7 * 24 * time.Hour
+	// InviteResendCooldown is the minimum gap between two sends of one invite.
+	InviteResendCooldown = 15 * time.Minute
+	// InviteRetention is how long expired and accepted invites stay listed
+	// before the purge job removes them.
+	InviteRetention = 30 * 24 * time.Hour
+)
+
+var (
+	ErrNotMember       = errors.New("user is not a member of this family")
+	ErrNotAdmin        = errors.New("only admins can manage invites")
+	ErrInviteNotFound  = errors.New("invite not found")
+	ErrInviteAccepted  = errors.New("invite has already been accepted")
+	ErrAlreadyMember   = errors.New("that email already belongs to a member of this family")
+	ErrInviteThrottled = errors.New("invite was sent too recently")
+)
+
+// InviteThrottledError reports how long until an invite can be sent again.
+type InviteThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *InviteThrottledError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrInviteThrottled, e.RetryAfter.Round(time.Second))
+}
+
+func (e *InviteThrottledError) Unwrap() error {
+	return ErrInviteThrottled
+}
+
+// InviteSender delivers an invite to its recipient.
+type InviteSender interface {
+	SendInvite(ctx context.Context, family *Family, invite *Invite) error
+}
+
+// WithInviteSender delivers invites as they are created and resent. Without
+// one, invites are only recorded and the family link has to be shared by hand.
+func WithInviteSender(sender InviteSender) Option {
+	return func(s *service) {
+		s.sender = sender
+	}
+}
+
+func (s *service) InviteMember(ctx context.Context, familyID, userID string, req *InviteRequest) (*Invite, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(req.Email)
+	members, err := s.repo.GetFamilyMembersWithUsers(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family members: %w", err)
+	}
+	for _, m := range members {
+		if strings.EqualFold(m.Email, email) {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	// Inviting the same address again is a resend, so it stays throttled
+	existing, err := s.repo.GetOpenInvite(ctx, familyID, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+	if existing != nil {
+		return s.resend(ctx, existing)
+	}
+
+	now := s.now()
+	invite := &Invite{
+		ID:         ids.New(),
+		FamilyID:   familyID,
+		Email:      email,
+		InvitedBy:  userID,
+		SendCount:  1,
+		LastSentAt: now,
+		ExpiresAt:  now.Add(InviteTTL),
+		CreatedAt:  now,
+	}
+	if err := s.repo.CreateInvite(ctx, invite); err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	s.deliver(ctx, invite)
+	invite.Status = invite.StatusAt(now)
+	return invite, nil
+}
+
+func (s *service) ListInvites(ctx context.Context, familyID, userID string) ([]Invite, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	invites, err := s.repo.ListInvites(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invites: %w", err)
+	}
+	if invites == nil {
+		return []Invite{}, nil
+	}
+
+	now := s.now()
+	for i := range invites {
+		invites[i].Status = invites[i].StatusAt(now)
+	}
+	return invites, nil
+}
+
+func (s *service) ResendInvite(ctx context.Context, familyID, inviteID, userID string) (*Invite, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	invite, err := s.repo.GetInvite(ctx, inviteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+	if invite == nil || invite.FamilyID != familyID {
+		return nil, ErrInviteNotFound
+	}
+
+	return s.resend(ctx, invite)
+}
+
+func (s *service) PurgeInvites(ctx context.Context, now time.Time) (int64, error) {
+	return s.repo.PurgeInvites(ctx, now.Add(-InviteRetention))
+}
+
+// resend sends the invite again and restarts its expiry, so an expired invite
+// can be revived rather than created afresh.
+func (s *service) resend(ctx context.Context, invite *Invite) (*Invite, error) {
+	if invite.AcceptedAt != nil {
+		return nil, ErrInviteAccepted
+	}
+	if invite.Email == "" {
+		return nil, ErrLinkInviteResend
+	}
+
+	now := s.now()
+	if wait := invite.LastSentAt.Add(InviteResendCooldown).Sub(now); wait > 0 {
+		return nil, &InviteThrottledError{RetryAfter: wait}
+	}
+
+	expiresAt := now.Add(InviteTTL)
+	ok, err := s.repo.RecordInviteSend(ctx, invite.ID, now, expiresAt, now.Add(-InviteResendCooldown))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record invite send: %w", err)
+	}
+	if !ok {
+		// Lost a race with another resend or the invite was just accepted
+		return nil, &InviteThrottledError{RetryAfter: InviteResendCooldown}
+	}
+
+	invite.SendCount++
+	invite.LastSentAt = now
+	invite.ExpiresAt = expiresAt
+
+	s.deliver(ctx, invite)
+	invite.Status = invite.StatusAt(now)
+	return invite, nil
+}
+
+// deliver hands the invite to the sender. The invite is already recorded, so
+// a failed delivery is reported as a warning rather than failing the request.
+func (s *service) deliver(ctx context.Context, invite *Invite) {
+	if s.sender == nil {
+		return
+	}
+
+	family, err := s.repo.GetFamilyByID(ctx, invite.FamilyID)
+	if err == nil && family != nil {
+		err = s.sender.SendInvite(ctx, family, invite)
+	}
+	if err != nil {
+		warnings.Addf(ctx, "invite to %s was saved but could not be delivered; resend it later", invite.Email)
+	}
+}
+
+func (s *service) requireAdmin(ctx context.Context, familyID, userID string) error {
+	role, err := s.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		return err
+	}
+	if role != "admin" {
+		return ErrNotAdmin
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package family
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ninenine/babytrack/internal/ids"
+)
+
+var (
+	ErrInviteExpired    = errors.New("invite has expired")
+	ErrLinkInviteResend = errors.New("link invites can't be resent; create a new one")
+)
+
+// LinkInvite is a new link invite with its token, which is only returned
+// here. URL is what the QR code encodes.
+type LinkInvite struct {
+	Invite
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// AcceptInviteRequest carries the token from a scanned link invite
+type AcceptInviteRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// WithJoinURL sets the page that link invites open, such as
+// https://babytrack.example.com/join, which gets the token as ?invite=.
+// Without it the QR code holds the bare token for the app to read.
+func WithJoinURL(url string) Option {
+	return func(s *service) {
+		s.joinURL = url
+	}
+}
+
+// CreateLinkInvite makes an invite that whoever holds its link can accept
+// once. It expires after InviteTTL like an emailed invite.
+func (s *service) CreateLinkInvite(ctx context.Context, familyID, userID string) (*LinkInvite, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	token, err := generateInviteToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite token: %w", err)
+	}
+
+	now := s.now()
+	invite := &Invite{
+		ID:         ids.New(),
+		FamilyID:   familyID,
+		InvitedBy:  userID,
+		SendCount:  1,
+		LastSentAt: now,
+		ExpiresAt:  now.Add(InviteTTL),
+		CreatedAt:  now,
+		tokenHash:  hashInviteToken(token),
+	}
+	if err := s.repo.CreateInvite(ctx, invite); err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+	invite.Status = invite.StatusAt(now)
+
+	url := token
+	if s.joinURL != "" {
+		url = s.joinURL + "?invite=" + token
+	}
+	return &LinkInvite{Invite: *invite, Token: token, URL: url}, nil
+}
+
+// AcceptLinkInvite adds the user to the invite's family. Someone already in
+// the family leaves the invite open for the person it was meant for.
+func (s *service) AcceptLinkInvite(ctx context.Context, token, userID string) (*Family, error) {
+	invite, err := s.repo.GetInviteByToken(ctx, hashInviteToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+	if invite == nil {
+		return nil, ErrInviteNotFound
+	}
+
+	now := s.now()
+	switch invite.StatusAt(now) {
+	case InviteStatusAccepted:
+		return nil, ErrInviteAccepted
+	case InviteStatusExpired:
+		return nil, ErrInviteExpired
+	}
+
+	family, err := s.repo.GetFamilyByID(ctx, invite.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family: %w", err)
+	}
+	if family == nil {
+		return nil, ErrInviteNotFound
+	}
+
+	isMember, err := s.repo.IsMember(ctx, invite.FamilyID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if isMember {
+		return family, nil
+	}
+
+	member := &FamilyMember{
+		ID:        ids.New(),
+		FamilyID:  invite.FamilyID,
+		UserID:    userID,
+		Role:      "member",
+		CreatedAt: now,
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		ok, err := s.repo.AcceptInvite(ctx, invite.ID, userID, now)
+		if err != nil {
+			return fmt.Errorf("failed to accept invite: %w", err)
+		}
+		if !ok {
+			// Scanned by someone else a moment ago, or revoked
+			return ErrInviteAccepted
+		}
+		if err := s.repo.AddFamilyMember(ctx, member); err != nil {
+			return fmt.Errorf("failed to join family: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return family, nil
+}
+
+// RevokeInvite withdraws an emailed or link invite that hasn't been
+// accepted, so it can no longer be used
+func (s *service) RevokeInvite(ctx context.Context, familyID, inviteID, userID string) error {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return err
+	}
+
+	invite, err := s.repo.GetInvite(ctx, inviteID)
+	if err != nil {
+		return fmt.Errorf("failed to get invite: %w", err)
+	}
+	if invite == nil || invite.FamilyID != familyID {
+		return ErrInviteNotFound
+	}
+	if invite.AcceptedAt != nil {
+		return ErrInviteAccepted
+	}
+
+	ok, err := s.repo.DeleteInvite(ctx, inviteID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+	if !ok {
+		return ErrInviteAccepted
+	}
+	return nil
+}
+
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateInviteToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -60,3 +60,42 @@ type MemberWithUser struct {
 	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"joined_at"`
 }
+
+type InviteStatus string
+
+const (
+	InviteStatusPending  InviteStatus = "pending"
+	InviteStatusExpired  InviteStatus = "expired"
+	InviteStatusAccepted InviteStatus = "accepted"
+)
+
+// Invite is an invitation to join a family, emailed or shown as a QR code.
+// Status is derived when the invite is read rather than stored.
+type Invite struct {
+	ID         string       `json:"id"`
+	FamilyID   string       `json:"family_id"`
+	Email      string       `json:"email,omitempty"` // empty for link invites
+	InvitedBy  string       `json:"invited_by,omitempty"`
+	Status     InviteStatus `json:"status"`
+	SendCount  int          `json:"send_count"`
+	LastSentAt time.Time    `json:"last_sent_at"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	AcceptedAt *time.Time   `json:"accepted_at,omitempty"`
+	AcceptedBy string       `json:"accepted_by,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+
+	// tokenHash identifies a link invite; the token itself is never stored
+	tokenHash string
+}
+
+// StatusAt reports whether the invite is still usable at now.
+func (i *Invite) StatusAt(now time.Time) InviteStatus {
+	switch {
+	case i.AcceptedAt != nil:
+		return InviteStatusAccepted
+	case !now.Before(i.ExpiresAt):
+		return InviteStatusExpired
+	default:
+		return InviteStatusPending
+	}
+}
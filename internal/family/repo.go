@@ -4,6 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
 )
 
 type Repository interface {
@@ -27,6 +31,21 @@ type Repository interface {
 	CreateChild(ctx context.Context, child *Child) error
 	UpdateChild(ctx context.Context, child *Child) error
 	DeleteChild(ctx context.Context, id string) error
+
+	// Invites
+	CreateInvite(ctx context.Context, invite *Invite) error
+	GetInvite(ctx context.Context, id string) (*Invite, error)
+	GetOpenInvite(ctx context.Context, familyID, email string) (*Invite, error)
+	ListInvites(ctx context.Context, familyID string) ([]Invite, error)
+	RecordInviteSend(ctx context.Context, id string, sentAt, expiresAt, notSentSince time.Time) (bool, error)
+	AcceptInvites(ctx context.Context, familyID, userID string, at time.Time) (int64, error)
+	PurgeInvites(ctx context.Context, before time.Time) (int64, error)
+	GetInviteByToken(ctx context.Context, tokenHash string) (*Invite, error)
+	// AcceptInvite marks one invite accepted, reporting false if it was
+	// already accepted, has expired or is gone
+	AcceptInvite(ctx context.Context, id, userID string, at time.Time) (bool, error)
+	// DeleteInvite removes an invite that hasn't been accepted
+	DeleteInvite(ctx context.Context, id string) (bool, error)
 }
 
 type repository struct {
@@ -37,13 +56,18 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
+// HealthCheck checks the family, membership, children and invite tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "families", "family_members", "children", "family_invites")
+}
+
 // Family methods
 
 func (r *repository) GetFamilyByID(ctx context.Context, id string) (*Family, error) {
 	query := `SELECT id, name, created_at, updated_at FROM families WHERE id = $1`
 
 	var family Family
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := db.Use(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&family.ID,
 		&family.Name,
 		&family.CreatedAt,
@@ -63,7 +87,7 @@ func (r *repository) GetFamilyByID(ctx context.Context, id string) (*Family, err
 func (r *repository) CreateFamily(ctx context.Context, family *Family) error {
 	query := `INSERT INTO families (id, name, created_at, updated_at) VALUES ($1, $2, $3, $4)`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query,
 		family.ID,
 		family.Name,
 		family.CreatedAt,
@@ -76,7 +100,7 @@ func (r *repository) CreateFamily(ctx context.Context, family *Family) error {
 func (r *repository) UpdateFamily(ctx context.Context, family *Family) error {
 	query := `UPDATE families SET name = $2, updated_at = $3 WHERE id = $1`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query,
 		family.ID,
 		family.Name,
 		family.UpdatedAt,
@@ -87,7 +111,7 @@ func (r *repository) UpdateFamily(ctx context.Context, family *Family) error {
 
 func (r *repository) DeleteFamily(ctx context.Context, id string) error {
 	query := `DELETE FROM families WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, id)
 	return err
 }
 
@@ -96,7 +120,7 @@ func (r *repository) DeleteFamily(ctx context.Context, id string) error {
 func (r *repository) GetFamilyMembers(ctx context.Context, familyID string) ([]FamilyMember, error) {
 	query := `SELECT id, family_id, user_id, role, created_at FROM family_members WHERE family_id = $1`
 
-	rows, err := r.db.QueryContext(ctx, query, familyID)
+	rows, err := db.Use(ctx, r.db).QueryContext(ctx, query, familyID)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +147,7 @@ func (r *repository) GetFamilyMembersWithUsers(ctx context.Context, familyID str
 		ORDER BY fm.created_at ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, familyID)
+	rows, err := db.Use(ctx, r.db).QueryContext(ctx, query, familyID)
 	if err != nil {
 		return nil, err
 	}
@@ -151,7 +175,7 @@ func (r *repository) GetFamilyMembersWithUsers(ctx context.Context, familyID str
 func (r *repository) AddFamilyMember(ctx context.Context, member *FamilyMember) error {
 	query := `INSERT INTO family_members (id, family_id, user_id, role, created_at) VALUES ($1, $2, $3, $4, $5)`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query,
 		member.ID,
 		member.FamilyID,
 		member.UserID,
@@ -165,7 +189,7 @@ func (r *repository) AddFamilyMember(ctx context.Context, member *FamilyMember)
 func (r *repository) RemoveFamilyMember(ctx context.Context, familyID, userID string) error {
 	query := `DELETE FROM family_members WHERE family_id = $1 AND user_id = $2`
 
-	_, err := r.db.ExecContext(ctx, query, familyID, userID)
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, familyID, userID)
 	return err
 }
 
@@ -173,7 +197,7 @@ func (r *repository) IsMember(ctx context.Context, familyID, userID string) (boo
 	query := `SELECT EXISTS(SELECT 1 FROM family_members WHERE family_id = $1 AND user_id = $2)`
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, familyID, userID).Scan(&exists)
+	err := db.Use(ctx, r.db).QueryRowContext(ctx, query, familyID, userID).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -189,7 +213,7 @@ func (r *repository) GetUserFamilies(ctx context.Context, userID string) ([]Fami
 		ORDER BY f.created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := db.Use(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -217,7 +241,7 @@ func (r *repository) GetChildren(ctx context.Context, familyID string) ([]Child,
 		ORDER BY date_of_birth DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, familyID)
+	rows, err := db.Use(ctx, r.db).QueryContext(ctx, query, familyID)
 	if err != nil {
 		return nil, err
 	}
@@ -258,7 +282,7 @@ func (r *repository) GetChildByID(ctx context.Context, id string) (*Child, error
 	var c Child
 	var gender, avatarURL sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := db.Use(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&c.ID, &c.FamilyID, &c.Name, &c.DateOfBirth,
 		&gender, &avatarURL, &c.CreatedAt, &c.UpdatedAt,
 	)
@@ -294,7 +318,7 @@ func (r *repository) CreateChild(ctx context.Context, child *Child) error {
 		avatarURL = &child.AvatarURL
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query,
 		child.ID,
 		child.FamilyID,
 		child.Name,
@@ -323,7 +347,7 @@ func (r *repository) UpdateChild(ctx context.Context, child *Child) error {
 		avatarURL = &child.AvatarURL
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query,
 		child.ID,
 		child.Name,
 		child.DateOfBirth,
@@ -337,6 +361,209 @@ func (r *repository) UpdateChild(ctx context.Context, child *Child) error {
 
 func (r *repository) DeleteChild(ctx context.Context, id string) error {
 	query := `DELETE FROM children WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, id)
 	return err
 }
+
+// Invite methods
+
+const inviteColumns = `id, family_id, email, invited_by, send_count, last_sent_at, expires_at, accepted_at, accepted_by, created_at`
+
+func (r *repository) CreateInvite(ctx context.Context, invite *Invite) error {
+	query := `INSERT INTO family_invites (` + inviteColumns + `, token_hash) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query,
+		invite.ID,
+		invite.FamilyID,
+		nullString(invite.Email),
+		nullString(invite.InvitedBy),
+		invite.SendCount,
+		invite.LastSentAt,
+		invite.ExpiresAt,
+		invite.AcceptedAt,
+		nullString(invite.AcceptedBy),
+		invite.CreatedAt,
+		nullString(invite.tokenHash),
+	)
+
+	return err
+}
+
+func (r *repository) GetInvite(ctx context.Context, id string) (*Invite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM family_invites WHERE id = $1`
+
+	invite, err := scanInvite(db.Use(ctx, r.db).QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return invite, err
+}
+
+func (r *repository) GetOpenInvite(ctx context.Context, familyID, email string) (*Invite, error) {
+	query := `
+		SELECT ` + inviteColumns + `
+		FROM family_invites
+		WHERE family_id = $1 AND LOWER(email) = LOWER($2) AND accepted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	invite, err := scanInvite(db.Use(ctx, r.db).QueryRowContext(ctx, query, familyID, email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return invite, err
+}
+
+func (r *repository) ListInvites(ctx context.Context, familyID string) ([]Invite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM family_invites WHERE family_id = $1 ORDER BY created_at DESC`
+
+	rows, err := db.Use(ctx, r.db).QueryContext(ctx, query, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	invites := []Invite{}
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, *invite)
+	}
+
+	return invites, rows.Err()
+}
+
+// RecordInviteSend bumps the send count unless the invite was last sent after
+// notSentSince, so two concurrent resends cannot both get through.
+func (r *repository) RecordInviteSend(ctx context.Context, id string, sentAt, expiresAt, notSentSince time.Time) (bool, error) {
+	query := `
+		UPDATE family_invites
+		SET send_count = send_count + 1, last_sent_at = $2, expires_at = $3
+		WHERE id = $1 AND accepted_at IS NULL AND last_sent_at <= $4
+	`
+
+	result, err := db.Use(ctx, r.db).ExecContext(ctx, query, id, sentAt, expiresAt, notSentSince)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// AcceptInvites marks the family's unexpired invites addressed to the user's
+// email as accepted by them.
+func (r *repository) AcceptInvites(ctx context.Context, familyID, userID string, at time.Time) (int64, error) {
+	query := `
+		UPDATE family_invites
+		SET accepted_at = $3, accepted_by = $2
+		WHERE family_id = $1
+			AND accepted_at IS NULL
+			AND expires_at > $3
+			AND LOWER(email) = (SELECT LOWER(email) FROM users WHERE id = $2)
+	`
+
+	result, err := db.Use(ctx, r.db).ExecContext(ctx, query, familyID, userID, at)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *repository) PurgeInvites(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM family_invites
+		WHERE (accepted_at IS NULL AND expires_at < $1) OR accepted_at < $1
+	`
+
+	result, err := db.Use(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *repository) GetInviteByToken(ctx context.Context, tokenHash string) (*Invite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM family_invites WHERE token_hash = $1`
+
+	invite, err := scanInvite(db.Use(ctx, r.db).QueryRowContext(ctx, query, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return invite, err
+}
+
+func (r *repository) AcceptInvite(ctx context.Context, id, userID string, at time.Time) (bool, error) {
+	query := `
+		UPDATE family_invites
+		SET accepted_at = $3, accepted_by = $2
+		WHERE id = $1 AND accepted_at IS NULL AND expires_at > $3
+	`
+
+	result, err := db.Use(ctx, r.db).ExecContext(ctx, query, id, userID, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *repository) DeleteInvite(ctx context.Context, id string) (bool, error) {
+	query := `DELETE FROM family_invites WHERE id = $1 AND accepted_at IS NULL`
+
+	result, err := db.Use(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanInvite(row scanner) (*Invite, error) {
+	var invite Invite
+	var email, invitedBy, acceptedBy sql.NullString
+	var acceptedAt sql.NullTime
+
+	err := row.Scan(
+		&invite.ID,
+		&invite.FamilyID,
+		&email,
+		&invitedBy,
+		&invite.SendCount,
+		&invite.LastSentAt,
+		&invite.ExpiresAt,
+		&acceptedAt,
+		&acceptedBy,
+		&invite.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	invite.Email = email.String
+	invite.InvitedBy = invitedBy.String
+	invite.AcceptedBy = acceptedBy.String
+	if acceptedAt.Valid {
+		invite.AcceptedAt = &acceptedAt.Time
+	}
+	return &invite, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	dbpkg "github.com/ninenine/babytrack/internal/db"
+
 	"github.com/DATA-DOG/go-sqlmock"
 )
 
@@ -1319,3 +1321,211 @@ func TestRepository_DeleteChild_Error(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+// Invite tests
+
+var inviteRowColumns = []string{"id", "family_id", "email", "invited_by", "send_count", "last_sent_at", "expires_at", "accepted_at", "accepted_by", "created_at"}
+
+func TestRepository_ListInvites(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(inviteRowColumns).
+		AddRow("invite-1", "family-123", "a@example.com", "user-1", 2, now, now.Add(time.Hour), nil, nil, now).
+		AddRow("invite-2", "family-123", "b@example.com", nil, 1, now, now, now, "user-2", now)
+
+	mock.ExpectQuery("SELECT (.+) FROM family_invites WHERE family_id = \\$1").
+		WithArgs("family-123").
+		WillReturnRows(rows)
+
+	invites, err := repo.ListInvites(context.Background(), "family-123")
+	if err != nil {
+		t.Fatalf("ListInvites() error = %v", err)
+	}
+
+	if len(invites) != 2 {
+		t.Fatalf("ListInvites() returned %d invites, want 2", len(invites))
+	}
+	if invites[0].InvitedBy != "user-1" || invites[0].SendCount != 2 || invites[0].AcceptedAt != nil {
+		t.Errorf("ListInvites()[0] = %+v", invites[0])
+	}
+	if invites[1].AcceptedAt == nil || invites[1].AcceptedBy != "user-2" {
+		t.Errorf("ListInvites()[1] = %+v, want accepted by user-2", invites[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetInvite_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM family_invites WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(inviteRowColumns))
+
+	invite, err := repo.GetInvite(context.Background(), "missing")
+	if err != nil {
+		t.Fatalf("GetInvite() error = %v", err)
+	}
+	if invite != nil {
+		t.Errorf("GetInvite() = %+v, want nil", invite)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_RecordInviteSend_Throttled(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE family_invites SET send_count = send_count \\+ 1").
+		WithArgs("invite-1", now, now.Add(InviteTTL), now.Add(-InviteResendCooldown)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := repo.RecordInviteSend(context.Background(), "invite-1", now, now.Add(InviteTTL), now.Add(-InviteResendCooldown))
+	if err != nil {
+		t.Fatalf("RecordInviteSend() error = %v", err)
+	}
+	if ok {
+		t.Error("RecordInviteSend() = true, want false when no row matched")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_AcceptInvites(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE family_invites SET accepted_at = \\$3, accepted_by = \\$2").
+		WithArgs("family-123", "user-456", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := repo.AcceptInvites(context.Background(), "family-123", "user-456", now)
+	if err != nil {
+		t.Fatalf("AcceptInvites() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("AcceptInvites() = %d, want 1", n)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_PurgeInvites(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	before := time.Now()
+	mock.ExpectExec("DELETE FROM family_invites").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := repo.PurgeInvites(context.Background(), before)
+	if err != nil {
+		t.Fatalf("PurgeInvites() error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("PurgeInvites() = %d, want 3", n)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestService_CreateFamily_RollsBackWithoutAdmin(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	svc := NewService(NewRepository(db), WithTxManager(dbpkg.NewTxManager(db)))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO families").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO family_members").WillReturnError(errors.New("foreign key violation"))
+	mock.ExpectRollback()
+
+	if _, err := svc.CreateFamily(context.Background(), "user-123", &CreateFamilyRequest{Name: "Smith Family"}); err == nil {
+		t.Error("CreateFamily() should return error when adding member fails")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Family insert was not rolled back: %v", err)
+	}
+}
+
+func TestRepository_CreateInvite_Link(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	invite := &Invite{ID: "invite-1", FamilyID: "family-123", InvitedBy: "user-1", SendCount: 1,
+		LastSentAt: now, ExpiresAt: now.Add(InviteTTL), CreatedAt: now, tokenHash: "abc123"}
+
+	mock.ExpectExec("INSERT INTO family_invites \\(.+, token_hash\\)").
+		WithArgs("invite-1", "family-123", nil, "user-1", 1, now, now.Add(InviteTTL), nil, nil, now, "abc123").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.CreateInvite(context.Background(), invite); err != nil {
+		t.Fatalf("CreateInvite() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetInviteByToken(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM family_invites WHERE token_hash = \\$1").
+		WithArgs("abc123").
+		WillReturnRows(sqlmock.NewRows(inviteRowColumns).
+			AddRow("invite-1", "family-123", nil, "user-1", 1, now, now.Add(InviteTTL), nil, nil, now))
+
+	invite, err := repo.GetInviteByToken(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("GetInviteByToken() error = %v", err)
+	}
+	if invite == nil || invite.ID != "invite-1" || invite.Email != "" {
+		t.Errorf("GetInviteByToken() = %+v, want invite-1 with no email", invite)
+	}
+}
+
+func TestRepository_AcceptInvite_AlreadyTaken(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE family_invites SET accepted_at = \\$3, accepted_by = \\$2 WHERE id = \\$1 AND accepted_at IS NULL").
+		WithArgs("invite-1", "user-456", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := repo.AcceptInvite(context.Background(), "invite-1", "user-456", now)
+	if err != nil {
+		t.Fatalf("AcceptInvite() error = %v", err)
+	}
+	if ok {
+		t.Error("AcceptInvite() = true, want false when no row matched")
+	}
+}
@@ -2,10 +2,13 @@ package family
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"fmt"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
 )
 
 type Service interface {
@@ -20,51 +23,99 @@ type Service interface {
 
 	// Members
 	GetFamilyMembers(ctx context.Context, familyID string) ([]MemberWithUser, error)
-	InviteMember(ctx context.Context, familyID string, req *InviteRequest) error
+	InviteMember(ctx context.Context, familyID, userID string, req *InviteRequest) (*Invite, error)
+	ListInvites(ctx context.Context, familyID, userID string) ([]Invite, error)
+	ResendInvite(ctx context.Context, familyID, inviteID, userID string) (*Invite, error)
+	PurgeInvites(ctx context.Context, now time.Time) (int64, error)
+	// CreateLinkInvite makes an invite to show as a QR code; the token is
+	// only returned here
+	CreateLinkInvite(ctx context.Context, familyID, userID string) (*LinkInvite, error)
+	AcceptLinkInvite(ctx context.Context, token, userID string) (*Family, error)
+	RevokeInvite(ctx context.Context, familyID, inviteID, userID string) error
 	JoinFamily(ctx context.Context, familyID, userID string) (*Family, error)
 	RemoveMember(ctx context.Context, familyID, userID string) error
 
 	// Children
 	AddChild(ctx context.Context, familyID string, req *AddChildRequest) (*Child, error)
 	GetChildren(ctx context.Context, familyID string) ([]Child, error)
+	ChildrenSince(ctx context.Context, familyID string, since time.Time) (*delta.Changes[Child], error)
 	GetChild(ctx context.Context, childID string) (*Child, error)
 	UpdateChild(ctx context.Context, childID string, req *AddChildRequest) (*Child, error)
 	DeleteChild(ctx context.Context, childID string) error
 }
 
 type service struct {
-	repo Repository
+	repo       Repository
+	tx         db.TxManager
+	sender     InviteSender
+	tombstones delta.Tombstones
+	joinURL    string
+	now        func() time.Time
+}
+
+type Option func(*service)
+
+// WithTxManager creates a family and its admin membership atomically. The
+// repository must be built on the same database.
+func WithTxManager(tx db.TxManager) Option {
+	return func(s *service) {
+		s.tx = tx
+	}
+}
+
+// WithTombstones remembers deleted children so that ChildrenSince can
+// report them
+func WithTombstones(tombstones delta.Tombstones) Option {
+	return func(s *service) {
+		s.tombstones = tombstones
+	}
+}
+
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{repo: repo, tx: db.NoTx, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// HealthCheck reports whether families can be read. Nearly every other
+// module depends on them.
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
 }
 
 func (s *service) CreateFamily(ctx context.Context, userID string, req *CreateFamilyRequest) (*Family, error) {
 	now := time.Now()
 
 	family := &Family{
-		ID:        generateID(),
+		ID:        ids.New(),
 		Name:      req.Name,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	if err := s.repo.CreateFamily(ctx, family); err != nil {
-		return nil, fmt.Errorf("failed to create family: %w", err)
-	}
-
 	// Add the creator as admin
 	member := &FamilyMember{
-		ID:        generateID(),
+		ID:        ids.New(),
 		FamilyID:  family.ID,
 		UserID:    userID,
 		Role:      "admin",
 		CreatedAt: now,
 	}
 
-	if err := s.repo.AddFamilyMember(ctx, member); err != nil {
-		return nil, fmt.Errorf("failed to add family member: %w", err)
+	// A family without its admin cannot be reached or deleted by anyone
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateFamily(ctx, family); err != nil {
+			return fmt.Errorf("failed to create family: %w", err)
+		}
+		if err := s.repo.AddFamilyMember(ctx, member); err != nil {
+			return fmt.Errorf("failed to add family member: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return family, nil
@@ -180,7 +231,7 @@ func (s *service) GetMemberRole(ctx context.Context, familyID, userID string) (s
 			return m.Role, nil
 		}
 	}
-	return "", fmt.Errorf("user is not a member of this family")
+	return "", ErrNotMember
 }
 
 func (s *service) GetFamilyMembers(ctx context.Context, familyID string) ([]MemberWithUser, error) {
@@ -194,11 +245,6 @@ func (s *service) GetFamilyMembers(ctx context.Context, familyID string) ([]Memb
 	return members, nil
 }
 
-func (s *service) InviteMember(ctx context.Context, familyID string, req *InviteRequest) error {
-	// TODO: implement email invite
-	return fmt.Errorf("not implemented")
-}
-
 func (s *service) JoinFamily(ctx context.Context, familyID, userID string) (*Family, error) {
 	// Check if family exists
 	family, err := s.repo.GetFamilyByID(ctx, familyID)
@@ -220,15 +266,25 @@ func (s *service) JoinFamily(ctx context.Context, familyID, userID string) (*Fam
 
 	// Add user as member
 	member := &FamilyMember{
-		ID:        generateID(),
+		ID:        ids.New(),
 		FamilyID:  familyID,
 		UserID:    userID,
 		Role:      "member",
-		CreatedAt: time.Now(),
+		CreatedAt: s.now(),
 	}
 
-	if err := s.repo.AddFamilyMember(ctx, member); err != nil {
-		return nil, fmt.Errorf("failed to join family: %w", err)
+	// Joining settles any pending invite sent to the user's email
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.AddFamilyMember(ctx, member); err != nil {
+			return fmt.Errorf("failed to join family: %w", err)
+		}
+		if _, err := s.repo.AcceptInvites(ctx, familyID, userID, member.CreatedAt); err != nil {
+			return fmt.Errorf("failed to accept invite: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return family, nil
@@ -242,7 +298,7 @@ func (s *service) AddChild(ctx context.Context, familyID string, req *AddChildRe
 	now := time.Now()
 
 	child := &Child{
-		ID:          generateID(),
+		ID:          ids.New(),
 		FamilyID:    familyID,
 		Name:        req.Name,
 		DateOfBirth: req.DateOfBirth,
@@ -269,6 +325,25 @@ func (s *service) GetChildren(ctx context.Context, familyID string) ([]Child, er
 	return children, nil
 }
 
+// ChildrenSince returns the family's children written after since and the
+// IDs of those deleted since
+func (s *service) ChildrenSince(ctx context.Context, familyID string, since time.Time) (*delta.Changes[Child], error) {
+	now := s.now()
+	deleted, err := delta.Deleted(ctx, s.tombstones, delta.EntityChild, familyID, since, now)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := s.GetChildren(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return delta.Build(children, since, deleted, now, func(c *Child) (time.Time, time.Time) {
+		return c.CreatedAt, c.UpdatedAt
+	}), nil
+}
+
 func (s *service) GetChild(ctx context.Context, childID string) (*Child, error) {
 	return s.repo.GetChildByID(ctx, childID)
 }
@@ -295,11 +370,18 @@ func (s *service) UpdateChild(ctx context.Context, childID string, req *AddChild
 }
 
 func (s *service) DeleteChild(ctx context.Context, childID string) error {
-	return s.repo.DeleteChild(ctx, childID)
-}
+	if s.tombstones == nil {
+		return s.repo.DeleteChild(ctx, childID)
+	}
 
-func generateID() string {
-	b := make([]byte, 16)
-	rand.Read(b) //nolint:errcheck // crypto/rand.Read rarely fails
-	return hex.EncodeToString(b)
+	child, err := s.repo.GetChildByID(ctx, childID)
+	if err != nil || child == nil {
+		return err
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteChild(ctx, childID); err != nil {
+			return err
+		}
+		return s.tombstones.Record(ctx, delta.EntityChild, childID, child.FamilyID, s.now())
+	})
 }
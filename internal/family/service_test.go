@@ -3,8 +3,12 @@ package family
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/warnings"
 )
 
 // mockRepository is a test double for Repository
@@ -13,6 +17,8 @@ type mockRepository struct {
 	members         map[string][]FamilyMember
 	children        map[string]*Child
 	userFamilies    map[string][]Family
+	invites         map[string]*Invite
+	emails          map[string]string
 	createFamilyErr error
 	addMemberErr    error
 	createChildErr  error
@@ -27,6 +33,8 @@ func newMockRepository() *mockRepository {
 		members:      make(map[string][]FamilyMember),
 		children:     make(map[string]*Child),
 		userFamilies: make(map[string][]Family),
+		invites:      make(map[string]*Invite),
+		emails:       make(map[string]string),
 	}
 }
 
@@ -71,6 +79,7 @@ func (m *mockRepository) GetFamilyMembersWithUsers(ctx context.Context, familyID
 		result[i] = MemberWithUser{
 			ID:     member.ID,
 			UserID: member.UserID,
+			Email:  m.emails[member.UserID],
 			Role:   member.Role,
 		}
 	}
@@ -155,6 +164,106 @@ func (m *mockRepository) DeleteChild(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *mockRepository) CreateInvite(ctx context.Context, invite *Invite) error {
+	stored := *invite
+	m.invites[invite.ID] = &stored
+	return nil
+}
+
+func (m *mockRepository) GetInvite(ctx context.Context, id string) (*Invite, error) {
+	invite, ok := m.invites[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *invite
+	return &copied, nil
+}
+
+func (m *mockRepository) GetOpenInvite(ctx context.Context, familyID, email string) (*Invite, error) {
+	for _, invite := range m.invites {
+		if invite.FamilyID == familyID && strings.EqualFold(invite.Email, email) && invite.AcceptedAt == nil {
+			copied := *invite
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListInvites(ctx context.Context, familyID string) ([]Invite, error) {
+	var result []Invite
+	for _, invite := range m.invites {
+		if invite.FamilyID == familyID {
+			result = append(result, *invite)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) RecordInviteSend(ctx context.Context, id string, sentAt, expiresAt, notSentSince time.Time) (bool, error) {
+	invite, ok := m.invites[id]
+	if !ok || invite.AcceptedAt != nil || invite.LastSentAt.After(notSentSince) {
+		return false, nil
+	}
+	invite.SendCount++
+	invite.LastSentAt = sentAt
+	invite.ExpiresAt = expiresAt
+	return true, nil
+}
+
+func (m *mockRepository) AcceptInvites(ctx context.Context, familyID, userID string, at time.Time) (int64, error) {
+	var n int64
+	for _, invite := range m.invites {
+		if invite.FamilyID == familyID && invite.AcceptedAt == nil && invite.ExpiresAt.After(at) &&
+			invite.Email != "" && strings.EqualFold(invite.Email, m.emails[userID]) {
+			invite.AcceptedAt = &at
+			invite.AcceptedBy = userID
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockRepository) GetInviteByToken(ctx context.Context, tokenHash string) (*Invite, error) {
+	for _, invite := range m.invites {
+		if invite.tokenHash != "" && invite.tokenHash == tokenHash {
+			copied := *invite
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) AcceptInvite(ctx context.Context, id, userID string, at time.Time) (bool, error) {
+	invite, ok := m.invites[id]
+	if !ok || invite.AcceptedAt != nil || !invite.ExpiresAt.After(at) {
+		return false, nil
+	}
+	invite.AcceptedAt = &at
+	invite.AcceptedBy = userID
+	return true, nil
+}
+
+func (m *mockRepository) DeleteInvite(ctx context.Context, id string) (bool, error) {
+	invite, ok := m.invites[id]
+	if !ok || invite.AcceptedAt != nil {
+		return false, nil
+	}
+	delete(m.invites, id)
+	return true, nil
+}
+
+func (m *mockRepository) PurgeInvites(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	for id, invite := range m.invites {
+		if (invite.AcceptedAt == nil && invite.ExpiresAt.Before(before)) ||
+			(invite.AcceptedAt != nil && invite.AcceptedAt.Before(before)) {
+			delete(m.invites, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
 func TestService_CreateFamily(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -586,6 +695,71 @@ func TestService_GetChildren(t *testing.T) {
 	}
 }
 
+type mockTombstones struct {
+	deleted map[string]string // entity ID -> scope ID
+}
+
+func (m *mockTombstones) Record(ctx context.Context, entityType delta.EntityType, entityID, scopeID string, at time.Time) error {
+	m.deleted[entityID] = scopeID
+	return nil
+}
+
+func (m *mockTombstones) DeletedSince(ctx context.Context, entityType delta.EntityType, scopeID string, since time.Time) ([]string, error) {
+	var ids []string
+	for id, scope := range m.deleted {
+		if scope == scopeID {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockTombstones) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockTombstones) Since(ctx context.Context, scopeIDs []string, since time.Time) ([]delta.Tombstone, error) {
+	return nil, nil
+}
+
+func (m *mockTombstones) Retention() time.Duration {
+	return delta.DefaultRetention
+}
+
+func TestService_ChildrenSince(t *testing.T) {
+	repo := newMockRepository()
+	tombstones := &mockTombstones{deleted: make(map[string]string)}
+	svc := NewService(repo, WithTombstones(tombstones))
+
+	since := time.Now().Add(-time.Hour)
+	repo.children["child-1"] = &Child{ID: "child-1", FamilyID: "family-123", CreatedAt: since.Add(-time.Hour), UpdatedAt: since.Add(-time.Hour)}
+	repo.children["child-2"] = &Child{ID: "child-2", FamilyID: "family-123", CreatedAt: since.Add(-time.Hour), UpdatedAt: since.Add(time.Minute)}
+	repo.children["child-3"] = &Child{ID: "child-3", FamilyID: "family-123", CreatedAt: since.Add(time.Minute), UpdatedAt: since.Add(time.Minute)}
+	repo.children["child-4"] = &Child{ID: "child-4", FamilyID: "family-123"}
+
+	if err := svc.DeleteChild(context.Background(), "child-4"); err != nil {
+		t.Fatalf("DeleteChild() error = %v", err)
+	}
+
+	changes, err := svc.ChildrenSince(context.Background(), "family-123", since)
+	if err != nil {
+		t.Fatalf("ChildrenSince() error = %v", err)
+	}
+	if len(changes.Created) != 1 || changes.Created[0].ID != "child-3" {
+		t.Errorf("Created = %+v, want child-3", changes.Created)
+	}
+	if len(changes.Updated) != 1 || changes.Updated[0].ID != "child-2" {
+		t.Errorf("Updated = %+v, want child-2", changes.Updated)
+	}
+	if len(changes.Deleted) != 1 || changes.Deleted[0] != "child-4" {
+		t.Errorf("Deleted = %v, want child-4", changes.Deleted)
+	}
+
+	if _, err := svc.ChildrenSince(context.Background(), "family-123", time.Now().Add(-delta.DefaultRetention-time.Hour)); !errors.Is(err, delta.ErrSinceExpired) {
+		t.Errorf("ChildrenSince() with an old cursor error = %v, want ErrSinceExpired", err)
+	}
+}
+
 func TestService_GetChildren_Empty(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -993,3 +1167,325 @@ func TestService_GetMemberRole_NotMember(t *testing.T) {
 		t.Error("GetMemberRole() should return error for non-member user")
 	}
 }
+
+// Invite tests
+
+var inviteNow = time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+type mockInviteSender struct {
+	sent []string
+	err  error
+}
+
+func (m *mockInviteSender) SendInvite(ctx context.Context, family *Family, invite *Invite) error {
+	m.sent = append(m.sent, invite.Email)
+	return m.err
+}
+
+func newInviteService(repo *mockRepository, opts ...Option) *service {
+	repo.families["family-123"] = &Family{ID: "family-123", Name: "Test Family"}
+	repo.members["family-123"] = []FamilyMember{
+		{ID: "member-1", FamilyID: "family-123", UserID: "admin-1", Role: "admin"},
+		{ID: "member-2", FamilyID: "family-123", UserID: "member-1", Role: "member"},
+	}
+	repo.emails["admin-1"] = "admin@example.com"
+	svc := NewService(repo, opts...).(*service)
+	svc.now = func() time.Time { return inviteNow }
+	return svc
+}
+
+func TestService_InviteMember(t *testing.T) {
+	repo := newMockRepository()
+	sender := &mockInviteSender{}
+	svc := newInviteService(repo, WithInviteSender(sender))
+
+	invite, err := svc.InviteMember(context.Background(), "family-123", "admin-1", &InviteRequest{Email: " new@example.com "})
+	if err != nil {
+		t.Fatalf("InviteMember() error = %v", err)
+	}
+
+	if invite.Email != "new@example.com" || invite.Status != InviteStatusPending {
+		t.Errorf("InviteMember() = %+v, want pending invite to new@example.com", invite)
+	}
+	if !invite.ExpiresAt.Equal(inviteNow.Add(InviteTTL)) {
+		t.Errorf("InviteMember() ExpiresAt = %v, want %v", invite.ExpiresAt, inviteNow.Add(InviteTTL))
+	}
+	if len(sender.sent) != 1 {
+		t.Errorf("InviteMember() sent %d invites, want 1", len(sender.sent))
+	}
+	if _, ok := repo.invites[invite.ID]; !ok {
+		t.Error("InviteMember() should store the invite")
+	}
+}
+
+func TestService_InviteMember_NotAdmin(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo)
+
+	_, err := svc.InviteMember(context.Background(), "family-123", "member-1", &InviteRequest{Email: "new@example.com"})
+	if !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("InviteMember() error = %v, want ErrNotAdmin", err)
+	}
+
+	_, err = svc.InviteMember(context.Background(), "family-123", "stranger", &InviteRequest{Email: "new@example.com"})
+	if !errors.Is(err, ErrNotMember) {
+		t.Errorf("InviteMember() error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestService_InviteMember_AlreadyMember(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo)
+
+	_, err := svc.InviteMember(context.Background(), "family-123", "admin-1", &InviteRequest{Email: "ADMIN@example.com"})
+	if !errors.Is(err, ErrAlreadyMember) {
+		t.Errorf("InviteMember() error = %v, want ErrAlreadyMember", err)
+	}
+}
+
+func TestService_InviteMember_ReinviteIsThrottled(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo)
+
+	first, err := svc.InviteMember(context.Background(), "family-123", "admin-1", &InviteRequest{Email: "new@example.com"})
+	if err != nil {
+		t.Fatalf("InviteMember() error = %v", err)
+	}
+
+	_, err = svc.InviteMember(context.Background(), "family-123", "admin-1", &InviteRequest{Email: "new@example.com"})
+	var throttled *InviteThrottledError
+	if !errors.As(err, &throttled) {
+		t.Fatalf("InviteMember() error = %v, want InviteThrottledError", err)
+	}
+	if throttled.RetryAfter != InviteResendCooldown {
+		t.Errorf("RetryAfter = %v, want %v", throttled.RetryAfter, InviteResendCooldown)
+	}
+
+	svc.now = func() time.Time { return inviteNow.Add(InviteResendCooldown) }
+	again, err := svc.InviteMember(context.Background(), "family-123", "admin-1", &InviteRequest{Email: "new@example.com"})
+	if err != nil {
+		t.Fatalf("InviteMember() after cooldown error = %v", err)
+	}
+	if again.ID != first.ID || again.SendCount != 2 {
+		t.Errorf("InviteMember() = %+v, want invite %s resent", again, first.ID)
+	}
+	if len(repo.invites) != 1 {
+		t.Errorf("InviteMember() stored %d invites, want 1", len(repo.invites))
+	}
+}
+
+func TestService_InviteMember_DeliveryFailureIsNotFatal(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo, WithInviteSender(&mockInviteSender{err: errors.New("smtp down")}))
+
+	ctx := warnings.NewContext(context.Background())
+	if _, err := svc.InviteMember(ctx, "family-123", "admin-1", &InviteRequest{Email: "new@example.com"}); err != nil {
+		t.Fatalf("InviteMember() error = %v", err)
+	}
+	if got := warnings.From(ctx); len(got) != 1 {
+		t.Errorf("warnings = %v, want one delivery warning", got)
+	}
+}
+
+func TestService_ResendInvite_RevivesExpired(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo)
+	repo.invites["invite-1"] = &Invite{
+		ID:         "invite-1",
+		FamilyID:   "family-123",
+		Email:      "new@example.com",
+		SendCount:  1,
+		LastSentAt: inviteNow.Add(-10 * 24 * time.Hour),
+		ExpiresAt:  inviteNow.Add(-3 * 24 * time.Hour),
+	}
+
+	invite, err := svc.ResendInvite(context.Background(), "family-123", "invite-1", "admin-1")
+	if err != nil {
+		t.Fatalf("ResendInvite() error = %v", err)
+	}
+	if invite.Status != InviteStatusPending || invite.SendCount != 2 {
+		t.Errorf("ResendInvite() = %+v, want pending invite sent twice", invite)
+	}
+}
+
+func TestService_ResendInvite_Errors(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo)
+	accepted := inviteNow.Add(-time.Hour)
+	repo.invites["accepted"] = &Invite{ID: "accepted", FamilyID: "family-123", AcceptedAt: &accepted}
+	repo.invites["elsewhere"] = &Invite{ID: "elsewhere", FamilyID: "family-999"}
+
+	if _, err := svc.ResendInvite(context.Background(), "family-123", "accepted", "admin-1"); !errors.Is(err, ErrInviteAccepted) {
+		t.Errorf("ResendInvite() error = %v, want ErrInviteAccepted", err)
+	}
+	if _, err := svc.ResendInvite(context.Background(), "family-123", "elsewhere", "admin-1"); !errors.Is(err, ErrInviteNotFound) {
+		t.Errorf("ResendInvite() error = %v, want ErrInviteNotFound", err)
+	}
+	if _, err := svc.ResendInvite(context.Background(), "family-123", "missing", "admin-1"); !errors.Is(err, ErrInviteNotFound) {
+		t.Errorf("ResendInvite() error = %v, want ErrInviteNotFound", err)
+	}
+}
+
+func TestService_ListInvites_Statuses(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo)
+	accepted := inviteNow.Add(-time.Hour)
+	repo.invites["pending"] = &Invite{ID: "pending", FamilyID: "family-123", ExpiresAt: inviteNow.Add(time.Hour)}
+	repo.invites["expired"] = &Invite{ID: "expired", FamilyID: "family-123", ExpiresAt: inviteNow}
+	repo.invites["accepted"] = &Invite{ID: "accepted", FamilyID: "family-123", ExpiresAt: inviteNow.Add(time.Hour), AcceptedAt: &accepted}
+
+	invites, err := svc.ListInvites(context.Background(), "family-123", "admin-1")
+	if err != nil {
+		t.Fatalf("ListInvites() error = %v", err)
+	}
+
+	got := map[string]InviteStatus{}
+	for _, invite := range invites {
+		got[invite.ID] = invite.Status
+	}
+	want := map[string]InviteStatus{
+		"pending":  InviteStatusPending,
+		"expired":  InviteStatusExpired,
+		"accepted": InviteStatusAccepted,
+	}
+	for id, status := range want {
+		if got[id] != status {
+			t.Errorf("ListInvites() %s status = %v, want %v", id, got[id], status)
+		}
+	}
+}
+
+func TestService_JoinFamily_AcceptsInvite(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo)
+	repo.emails["user-456"] = "New@example.com"
+	repo.invites["invite-1"] = &Invite{ID: "invite-1", FamilyID: "family-123", Email: "new@example.com", ExpiresAt: inviteNow.Add(time.Hour)}
+
+	if _, err := svc.JoinFamily(context.Background(), "family-123", "user-456"); err != nil {
+		t.Fatalf("JoinFamily() error = %v", err)
+	}
+
+	invite := repo.invites["invite-1"]
+	if invite.AcceptedAt == nil || invite.AcceptedBy != "user-456" {
+		t.Errorf("JoinFamily() should accept the invite, got %+v", invite)
+	}
+}
+
+func TestService_PurgeInvites(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo)
+	repo.invites["stale"] = &Invite{ID: "stale", FamilyID: "family-123", ExpiresAt: inviteNow.Add(-InviteRetention - time.Hour)}
+	repo.invites["recent"] = &Invite{ID: "recent", FamilyID: "family-123", ExpiresAt: inviteNow.Add(-time.Hour)}
+
+	purged, err := svc.PurgeInvites(context.Background(), inviteNow)
+	if err != nil {
+		t.Fatalf("PurgeInvites() error = %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("PurgeInvites() = %d, want 1", purged)
+	}
+	if _, ok := repo.invites["recent"]; !ok {
+		t.Error("PurgeInvites() should keep recently expired invites")
+	}
+}
+
+func TestService_LinkInvite(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo, WithJoinURL("https://babytrack.example.com/join"))
+	ctx := context.Background()
+
+	link, err := svc.CreateLinkInvite(ctx, "family-123", "admin-1")
+	if err != nil {
+		t.Fatalf("CreateLinkInvite() error = %v", err)
+	}
+	if link.Token == "" || link.URL != "https://babytrack.example.com/join?invite="+link.Token {
+		t.Errorf("CreateLinkInvite() URL = %q for token %q", link.URL, link.Token)
+	}
+	if link.Email != "" || link.Status != InviteStatusPending || !link.ExpiresAt.Equal(inviteNow.Add(InviteTTL)) {
+		t.Errorf("CreateLinkInvite() = %+v, want a pending invite with no email", link.Invite)
+	}
+	if stored := repo.invites[link.ID]; stored.tokenHash == "" || stored.tokenHash == link.Token {
+		t.Error("CreateLinkInvite() should store the token's hash, not the token")
+	}
+
+	family, err := svc.AcceptLinkInvite(ctx, link.Token, "user-456")
+	if err != nil || family.ID != "family-123" {
+		t.Fatalf("AcceptLinkInvite() = %v, %v", family, err)
+	}
+	if role, _ := svc.GetMemberRole(ctx, "family-123", "user-456"); role != "member" {
+		t.Errorf("AcceptLinkInvite() role = %q, want member", role)
+	}
+
+	// One scan only
+	if _, err := svc.AcceptLinkInvite(ctx, link.Token, "user-789"); !errors.Is(err, ErrInviteAccepted) {
+		t.Errorf("second AcceptLinkInvite() error = %v, want ErrInviteAccepted", err)
+	}
+	if _, err := svc.ResendInvite(ctx, "family-123", link.ID, "admin-1"); !errors.Is(err, ErrInviteAccepted) {
+		t.Errorf("ResendInvite() error = %v, want ErrInviteAccepted", err)
+	}
+}
+
+func TestService_AcceptLinkInvite_Errors(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.CreateLinkInvite(ctx, "family-123", "member-1"); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("CreateLinkInvite() by a member error = %v, want ErrNotAdmin", err)
+	}
+
+	link, err := svc.CreateLinkInvite(ctx, "family-123", "admin-1")
+	if err != nil {
+		t.Fatalf("CreateLinkInvite() error = %v", err)
+	}
+	if link.URL != link.Token {
+		t.Errorf("CreateLinkInvite() URL = %q, want the bare token without a join URL", link.URL)
+	}
+	if _, err := svc.ResendInvite(ctx, "family-123", link.ID, "admin-1"); !errors.Is(err, ErrLinkInviteResend) {
+		t.Errorf("ResendInvite() error = %v, want ErrLinkInviteResend", err)
+	}
+	if _, err := svc.AcceptLinkInvite(ctx, "not-a-token", "user-456"); !errors.Is(err, ErrInviteNotFound) {
+		t.Errorf("AcceptLinkInvite(unknown) error = %v, want ErrInviteNotFound", err)
+	}
+
+	// Members scanning it leave it for the co-parent
+	if _, err := svc.AcceptLinkInvite(ctx, link.Token, "member-1"); err != nil {
+		t.Errorf("AcceptLinkInvite() by a member error = %v", err)
+	}
+	if repo.invites[link.ID].AcceptedAt != nil {
+		t.Error("AcceptLinkInvite() by a member should leave the invite open")
+	}
+
+	svc.now = func() time.Time { return inviteNow.Add(InviteTTL) }
+	if _, err := svc.AcceptLinkInvite(ctx, link.Token, "user-456"); !errors.Is(err, ErrInviteExpired) {
+		t.Errorf("AcceptLinkInvite() after expiry error = %v, want ErrInviteExpired", err)
+	}
+}
+
+func TestService_RevokeInvite(t *testing.T) {
+	repo := newMockRepository()
+	svc := newInviteService(repo)
+	ctx := context.Background()
+
+	link, err := svc.CreateLinkInvite(ctx, "family-123", "admin-1")
+	if err != nil {
+		t.Fatalf("CreateLinkInvite() error = %v", err)
+	}
+	if err := svc.RevokeInvite(ctx, "family-123", link.ID, "member-1"); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("RevokeInvite() by a member error = %v, want ErrNotAdmin", err)
+	}
+	if err := svc.RevokeInvite(ctx, "family-123", link.ID, "admin-1"); err != nil {
+		t.Fatalf("RevokeInvite() error = %v", err)
+	}
+	if _, err := svc.AcceptLinkInvite(ctx, link.Token, "user-456"); !errors.Is(err, ErrInviteNotFound) {
+		t.Errorf("AcceptLinkInvite() after revoking error = %v, want ErrInviteNotFound", err)
+	}
+
+	repo.invites["accepted"] = &Invite{ID: "accepted", FamilyID: "family-123", Email: "a@example.com", AcceptedAt: &inviteNow}
+	if err := svc.RevokeInvite(ctx, "family-123", "accepted", "admin-1"); !errors.Is(err, ErrInviteAccepted) {
+		t.Errorf("RevokeInvite(accepted) error = %v, want ErrInviteAccepted", err)
+	}
+	if err := svc.RevokeInvite(ctx, "other-family", "accepted", "admin-1"); err == nil {
+		t.Error("RevokeInvite() of another family's invite should fail")
+	}
+}
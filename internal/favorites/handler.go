@@ -0,0 +1,79 @@
+package favorites
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.list)
+	rg.POST("", h.star)
+	rg.DELETE("/:entityType/:entityId", h.unstar)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	filter := &FavoriteFilter{
+		UserID: c.GetString("user_id"),
+	}
+	if entityType := c.Query("entity_type"); entityType != "" {
+		et := EntityType(entityType)
+		filter.EntityType = &et
+	}
+
+	favorites, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, favorites)
+}
+
+func (h *Handler) star(c *gin.Context) {
+	var req StarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	fav, err := h.service.Star(c.Request.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, ErrUnknownEntityType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, fav)
+}
+
+func (h *Handler) unstar(c *gin.Context) {
+	userID := c.GetString("user_id")
+	entityType := EntityType(c.Param("entityType"))
+	entityID := c.Param("entityId")
+
+	if err := h.service.Unstar(c.Request.Context(), userID, entityType, entityID); err != nil {
+		if errors.Is(err, ErrUnknownEntityType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
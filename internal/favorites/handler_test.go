@@ -0,0 +1,200 @@
+package favorites
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	starFn   func(ctx context.Context, userID string, req *StarRequest) (*Favorite, error)
+	unstarFn func(ctx context.Context, userID string, entityType EntityType, entityID string) error
+	listFn   func(ctx context.Context, filter *FavoriteFilter) ([]Favorite, error)
+}
+
+func (m *mockService) Star(ctx context.Context, userID string, req *StarRequest) (*Favorite, error) {
+	if m.starFn != nil {
+		return m.starFn(ctx, userID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Unstar(ctx context.Context, userID string, entityType EntityType, entityID string) error {
+	if m.unstarFn != nil {
+		return m.unstarFn(ctx, userID, entityType, entityID)
+	}
+	return nil
+}
+
+func (m *mockService) List(ctx context.Context, filter *FavoriteFilter) ([]Favorite, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, filter)
+	}
+	return nil, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+
+	handler.RegisterRoutes(router.Group("/favorites"))
+	return router
+}
+
+func TestList_UsesAuthenticatedUser(t *testing.T) {
+	var captured *FavoriteFilter
+	svc := &mockService{
+		listFn: func(ctx context.Context, filter *FavoriteFilter) ([]Favorite, error) {
+			captured = filter
+			return []Favorite{}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/favorites?entity_type=note", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if captured.UserID != "test-user-123" {
+		t.Errorf("Expected UserID test-user-123, got %s", captured.UserID)
+	}
+	if captured.EntityType == nil || *captured.EntityType != EntityNote {
+		t.Errorf("Expected EntityType note, got %v", captured.EntityType)
+	}
+}
+
+func TestStar_Success(t *testing.T) {
+	svc := &mockService{
+		starFn: func(ctx context.Context, userID string, req *StarRequest) (*Favorite, error) {
+			return &Favorite{ID: "fav-1", UserID: userID, EntityType: req.EntityType, EntityID: req.EntityID}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/favorites", bytes.NewBufferString(`{"entity_type":"note","entity_id":"note-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", w.Code)
+	}
+}
+
+func TestStar_UnknownEntityType(t *testing.T) {
+	svc := &mockService{
+		starFn: func(ctx context.Context, userID string, req *StarRequest) (*Favorite, error) {
+			return nil, ErrUnknownEntityType
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/favorites", bytes.NewBufferString(`{"entity_type":"photo","entity_id":"p-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestStar_MissingFields(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("POST", "/favorites", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestUnstar_Success(t *testing.T) {
+	var capturedType EntityType
+	var capturedID string
+	svc := &mockService{
+		unstarFn: func(ctx context.Context, userID string, entityType EntityType, entityID string) error {
+			capturedType = entityType
+			capturedID = entityID
+			return nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("DELETE", "/favorites/sleep/sleep-1", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if capturedType != EntitySleep || capturedID != "sleep-1" {
+		t.Errorf("Expected sleep/sleep-1, got %s/%s", capturedType, capturedID)
+	}
+}
+
+func TestUnstar_ServiceError(t *testing.T) {
+	svc := &mockService{
+		unstarFn: func(ctx context.Context, userID string, entityType EntityType, entityID string) error {
+			return errors.New("database error")
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("DELETE", "/favorites/note/note-1", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func TestHandler_PrivateNoteOfAnotherMember(t *testing.T) {
+	repo := &mockRepository{favorites: []Favorite{
+		{ID: "fav-1", UserID: "test-user-123", EntityType: EntityNote, EntityID: "note-private"},
+	}}
+	router := setupRouter(NewService(repo, privateNotes()))
+
+	req := httptest.NewRequest("POST", "/favorites", bytes.NewBufferString(`{"entity_type":"note","entity_id":"note-private"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("star: expected status 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/favorites", http.NoBody))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: expected status 200, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "Just for me") || strings.Contains(w.Body.String(), "note-private") {
+		t.Errorf("list leaked the private note: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,34 @@
+package favorites
+
+import "time"
+
+type EntityType string
+
+const (
+	EntityNote        EntityType = "note"
+	EntityFeeding     EntityType = "feeding"
+	EntitySleep       EntityType = "sleep"
+	EntityMedication  EntityType = "medication"
+	EntityVaccination EntityType = "vaccination"
+	EntityAppointment EntityType = "appointment"
+)
+
+// Favorite is a per-user star on a record from any module
+type Favorite struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	EntityType EntityType `json:"entity_type"`
+	EntityID   string     `json:"entity_id"`
+	Record     any        `json:"record,omitempty"` // resolved entity, populated on list
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type StarRequest struct {
+	EntityType EntityType `json:"entity_type" binding:"required"`
+	EntityID   string     `json:"entity_id" binding:"required"`
+}
+
+type FavoriteFilter struct {
+	UserID     string
+	EntityType *EntityType
+}
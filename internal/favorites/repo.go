@@ -0,0 +1,85 @@
+package favorites
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	List(ctx context.Context, filter *FavoriteFilter) ([]Favorite, error)
+	Create(ctx context.Context, fav *Favorite) error
+	Delete(ctx context.Context, userID string, entityType EntityType, entityID string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the favorites table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "favorites")
+}
+
+func (r *repository) List(ctx context.Context, filter *FavoriteFilter) ([]Favorite, error) {
+	query := `
+		SELECT id, user_id, entity_type, entity_id, created_at
+		FROM favorites
+		WHERE user_id = $1
+	`
+	args := []any{filter.UserID}
+
+	if filter.EntityType != nil {
+		query += ` AND entity_type = $2`
+		args = append(args, *filter.EntityType)
+	}
+
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	var favorites []Favorite
+	for rows.Next() {
+		var f Favorite
+		if err := rows.Scan(&f.ID, &f.UserID, &f.EntityType, &f.EntityID, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, f)
+	}
+
+	if favorites == nil {
+		return []Favorite{}, nil
+	}
+
+	return favorites, rows.Err()
+}
+
+func (r *repository) Create(ctx context.Context, fav *Favorite) error {
+	query := `
+		INSERT INTO favorites (id, user_id, entity_type, entity_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, entity_type, entity_id) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query, fav.ID, fav.UserID, fav.EntityType, fav.EntityID, fav.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert favorite: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, userID string, entityType EntityType, entityID string) error {
+	query := `DELETE FROM favorites WHERE user_id = $1 AND entity_type = $2 AND entity_id = $3`
+	_, err := r.db.ExecContext(ctx, query, userID, entityType, entityID)
+	return err
+}
@@ -0,0 +1,109 @@
+package favorites
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var favoriteColumns = []string{"id", "user_id", "entity_type", "entity_id", "created_at"}
+
+func TestRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(favoriteColumns).
+		AddRow("fav-1", "user-1", "note", "note-1", now).
+		AddRow("fav-2", "user-1", "sleep", "sleep-1", now)
+
+	mock.ExpectQuery("SELECT id, user_id, entity_type, entity_id, created_at").
+		WithArgs("user-1").
+		WillReturnRows(rows)
+
+	favorites, err := repo.List(context.Background(), &FavoriteFilter{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(favorites) != 2 {
+		t.Errorf("List() returned %d favorites, want 2", len(favorites))
+	}
+	if favorites[1].EntityType != EntitySleep {
+		t.Errorf("List() EntityType = %v, want sleep", favorites[1].EntityType)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_List_WithEntityType(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	entityType := EntityNote
+	mock.ExpectQuery("SELECT id, user_id, entity_type, entity_id, created_at").
+		WithArgs("user-1", entityType).
+		WillReturnRows(sqlmock.NewRows(favoriteColumns))
+
+	favorites, err := repo.List(context.Background(), &FavoriteFilter{UserID: "user-1", EntityType: &entityType})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if favorites == nil || len(favorites) != 0 {
+		t.Errorf("List() = %v, want empty slice", favorites)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	fav := &Favorite{ID: "fav-1", UserID: "user-1", EntityType: EntityNote, EntityID: "note-1", CreatedAt: time.Now()}
+
+	mock.ExpectExec("INSERT INTO favorites").
+		WithArgs(fav.ID, fav.UserID, fav.EntityType, fav.EntityID, fav.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), fav); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Delete(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectExec("DELETE FROM favorites").
+		WithArgs("user-1", EntityNote, "note-1").
+		WillReturnError(errors.New("database error"))
+
+	if err := repo.Delete(context.Background(), "user-1", EntityNote, "note-1"); err == nil {
+		t.Error("Delete() should return error")
+	}
+}
@@ -0,0 +1,149 @@
+package favorites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrUnknownEntityType = errors.New("unknown entity type")
+	ErrRecordNotFound    = errors.New("record not found")
+)
+
+// Resolver loads the record behind a favorite as viewerID sees it. It returns
+// nil when the record no longer exists, or is hidden from the viewer, so
+// stale stars can be skipped.
+type Resolver func(ctx context.Context, viewerID, id string) (any, error)
+
+// ResolveWith adapts a module's Get method into a Resolver, mapping a nil
+// pointer to an untyped nil so deleted records are detected. Records the
+// module's visibility checks refuse the viewer resolve to nil as well.
+func ResolveWith[T any](get func(ctx context.Context, viewerID, id string) (*T, error)) Resolver {
+	return func(ctx context.Context, viewerID, id string) (any, error) {
+		record, err := get(ctx, viewerID, id)
+		if errors.Is(err, visibility.ErrHidden) || errors.Is(err, visibility.ErrChildNotFound) || errors.Is(err, family.ErrNotMember) {
+			return nil, nil
+		}
+		if err != nil || record == nil {
+			return nil, err
+		}
+		return record, nil
+	}
+}
+
+// ResolveFor adapts a Get method that takes the viewer after the ID, for
+// records such as private notes that only some members may see
+func ResolveFor[T any](get func(ctx context.Context, id, viewerID string) (*T, error)) Resolver {
+	return ResolveWith(func(ctx context.Context, viewerID, id string) (*T, error) {
+		return get(ctx, id, viewerID)
+	})
+}
+
+type Service interface {
+	Star(ctx context.Context, userID string, req *StarRequest) (*Favorite, error)
+	Unstar(ctx context.Context, userID string, entityType EntityType, entityID string) error
+	List(ctx context.Context, filter *FavoriteFilter) ([]Favorite, error)
+}
+
+type service struct {
+	repo      Repository
+	resolvers map[EntityType]Resolver
+}
+
+// NewService creates the favorites service. Resolvers map each entity type to
+// the module that owns it; types without a resolver are listed unhydrated.
+func NewService(repo Repository, resolvers map[EntityType]Resolver) Service {
+	if resolvers == nil {
+		resolvers = make(map[EntityType]Resolver)
+	}
+	return &service{
+		repo:      repo,
+		resolvers: resolvers,
+	}
+}
+
+// HealthCheck reports whether favorites can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Star(ctx context.Context, userID string, req *StarRequest) (*Favorite, error) {
+	if !validEntityType(req.EntityType) {
+		return nil, ErrUnknownEntityType
+	}
+	// Only records the user can see may be starred
+	if resolve, ok := s.resolvers[req.EntityType]; ok {
+		record, err := resolve(ctx, userID, req.EntityID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s %s: %w", req.EntityType, req.EntityID, err)
+		}
+		if record == nil {
+			return nil, ErrRecordNotFound
+		}
+	}
+
+	fav := &Favorite{
+		ID:         ids.New(),
+		UserID:     userID,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.repo.Create(ctx, fav); err != nil {
+		return nil, fmt.Errorf("failed to star record: %w", err)
+	}
+
+	return fav, nil
+}
+
+func (s *service) Unstar(ctx context.Context, userID string, entityType EntityType, entityID string) error {
+	if !validEntityType(entityType) {
+		return ErrUnknownEntityType
+	}
+	return s.repo.Delete(ctx, userID, entityType, entityID)
+}
+
+func (s *service) List(ctx context.Context, filter *FavoriteFilter) ([]Favorite, error) {
+	favorites, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Favorite, 0, len(favorites))
+	for _, fav := range favorites {
+		resolve, ok := s.resolvers[fav.EntityType]
+		if !ok {
+			result = append(result, fav)
+			continue
+		}
+
+		record, err := resolve(ctx, filter.UserID, fav.EntityID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s %s: %w", fav.EntityType, fav.EntityID, err)
+		}
+		if record == nil {
+			continue // Record was deleted, or made private, after being starred
+		}
+
+		fav.Record = record
+		result = append(result, fav)
+	}
+
+	return result, nil
+}
+
+func validEntityType(entityType EntityType) bool {
+	switch entityType {
+	case EntityNote, EntityFeeding, EntitySleep, EntityMedication, EntityVaccination, EntityAppointment:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package favorites
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// mockRepository is a test double for Repository
+type mockRepository struct {
+	favorites []Favorite
+	deleted   []string
+	listErr   error
+}
+
+func (m *mockRepository) List(ctx context.Context, filter *FavoriteFilter) ([]Favorite, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	var result []Favorite
+	for _, f := range m.favorites {
+		if f.UserID != filter.UserID {
+			continue
+		}
+		if filter.EntityType != nil && f.EntityType != *filter.EntityType {
+			continue
+		}
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+func (m *mockRepository) Create(ctx context.Context, fav *Favorite) error {
+	m.favorites = append(m.favorites, *fav)
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, userID string, entityType EntityType, entityID string) error {
+	m.deleted = append(m.deleted, string(entityType)+"/"+entityID)
+	return nil
+}
+
+func TestService_Star(t *testing.T) {
+	repo := &mockRepository{}
+	svc := NewService(repo, nil)
+
+	fav, err := svc.Star(context.Background(), "user-1", &StarRequest{EntityType: EntityNote, EntityID: "note-1"})
+	if err != nil {
+		t.Fatalf("Star() error = %v", err)
+	}
+	if fav.ID == "" || fav.UserID != "user-1" {
+		t.Errorf("Star() returned unexpected favorite %+v", fav)
+	}
+	if len(repo.favorites) != 1 {
+		t.Errorf("Star() should persist the favorite")
+	}
+}
+
+func TestService_Star_UnknownEntityType(t *testing.T) {
+	svc := NewService(&mockRepository{}, nil)
+
+	_, err := svc.Star(context.Background(), "user-1", &StarRequest{EntityType: "photo", EntityID: "p-1"})
+	if !errors.Is(err, ErrUnknownEntityType) {
+		t.Errorf("Star() error = %v, want ErrUnknownEntityType", err)
+	}
+}
+
+func TestService_Unstar(t *testing.T) {
+	repo := &mockRepository{}
+	svc := NewService(repo, nil)
+
+	if err := svc.Unstar(context.Background(), "user-1", EntitySleep, "sleep-1"); err != nil {
+		t.Fatalf("Unstar() error = %v", err)
+	}
+	if len(repo.deleted) != 1 || repo.deleted[0] != "sleep/sleep-1" {
+		t.Errorf("Unstar() deleted = %v", repo.deleted)
+	}
+}
+
+func TestService_List_ResolvesRecords(t *testing.T) {
+	repo := &mockRepository{favorites: []Favorite{
+		{ID: "fav-1", UserID: "user-1", EntityType: EntityNote, EntityID: "note-1"},
+		{ID: "fav-2", UserID: "user-1", EntityType: EntityNote, EntityID: "deleted-note"},
+		{ID: "fav-3", UserID: "user-1", EntityType: EntitySleep, EntityID: "sleep-1"},
+	}}
+	svc := NewService(repo, map[EntityType]Resolver{
+		EntityNote: func(ctx context.Context, viewerID, id string) (any, error) {
+			if id == "deleted-note" {
+				return nil, nil
+			}
+			return map[string]string{"id": id}, nil
+		},
+	})
+
+	favorites, err := svc.List(context.Background(), &FavoriteFilter{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(favorites) != 2 {
+		t.Fatalf("List() returned %d favorites, want 2 (deleted record skipped)", len(favorites))
+	}
+	if favorites[0].Record == nil {
+		t.Error("List() should hydrate records with a resolver")
+	}
+	if favorites[1].Record != nil {
+		t.Error("List() should leave records without a resolver unhydrated")
+	}
+}
+
+func TestService_List_ResolverError(t *testing.T) {
+	repo := &mockRepository{favorites: []Favorite{
+		{ID: "fav-1", UserID: "user-1", EntityType: EntityNote, EntityID: "note-1"},
+	}}
+	svc := NewService(repo, map[EntityType]Resolver{
+		EntityNote: func(ctx context.Context, viewerID, id string) (any, error) {
+			return nil, errors.New("database error")
+		},
+	})
+
+	if _, err := svc.List(context.Background(), &FavoriteFilter{UserID: "user-1"}); err == nil {
+		t.Error("List() should return error when a resolver fails")
+	}
+}
+
+func TestResolveWith_NilPointerIsUntyped(t *testing.T) {
+	type record struct{ ID string }
+	resolve := ResolveWith(func(ctx context.Context, viewerID, id string) (*record, error) {
+		return nil, nil
+	})
+
+	got, err := resolve(context.Background(), "user-1", "missing")
+	if err != nil {
+		t.Fatalf("resolve() error = %v", err)
+	}
+	if got != nil {
+		t.Errorf("resolve() = %#v, want untyped nil", got)
+	}
+}
+
+func TestResolveWith_HiddenIsNil(t *testing.T) {
+	type record struct{ ID string }
+	resolve := ResolveWith(func(ctx context.Context, viewerID, id string) (*record, error) {
+		return nil, visibility.ErrHidden
+	})
+
+	got, err := resolve(context.Background(), "user-1", "rec-1")
+	if err != nil || got != nil {
+		t.Errorf("resolve() = %#v, %v; want a hidden record skipped", got, err)
+	}
+}
+
+// privateNotes resolves notes the way the notes module does: note-private
+// belongs to user-1 and is hidden from everyone else
+func privateNotes() map[EntityType]Resolver {
+	stored := map[string]*notes.Note{
+		"note-shared":  {ID: "note-shared", AuthorID: "user-1"},
+		"note-private": {ID: "note-private", AuthorID: "user-1", Private: true, Content: "Just for me"},
+	}
+	return map[EntityType]Resolver{
+		EntityNote: ResolveFor(func(ctx context.Context, id, viewerID string) (*notes.Note, error) {
+			if n := stored[id]; n != nil && n.VisibleTo(viewerID) {
+				return n, nil
+			}
+			return nil, nil
+		}),
+	}
+}
+
+func TestService_Star_PrivateNote(t *testing.T) {
+	repo := &mockRepository{}
+	svc := NewService(repo, privateNotes())
+
+	if _, err := svc.Star(context.Background(), "user-2", &StarRequest{EntityType: EntityNote, EntityID: "note-private"}); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("Star() of another member's private note error = %v, want %v", err, ErrRecordNotFound)
+	}
+	if _, err := svc.Star(context.Background(), "user-1", &StarRequest{EntityType: EntityNote, EntityID: "note-private"}); err != nil {
+		t.Errorf("Star() by the author error = %v", err)
+	}
+	if len(repo.favorites) != 1 {
+		t.Errorf("favorites = %d, want only the author's", len(repo.favorites))
+	}
+}
+
+func TestService_List_SkipsPrivateNotes(t *testing.T) {
+	// Starred before the author made the note private
+	repo := &mockRepository{favorites: []Favorite{
+		{ID: "fav-1", UserID: "user-2", EntityType: EntityNote, EntityID: "note-shared"},
+		{ID: "fav-2", UserID: "user-2", EntityType: EntityNote, EntityID: "note-private"},
+	}}
+	svc := NewService(repo, privateNotes())
+
+	favorites, err := svc.List(context.Background(), &FavoriteFilter{UserID: "user-2"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(favorites) != 1 || favorites[0].EntityID != "note-shared" {
+		t.Errorf("List() = %+v, want only the shared note", favorites)
+	}
+}
@@ -0,0 +1,267 @@
+package feeding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/measure"
+)
+
+// FormulaDisclaimer is returned with every formula guide. The figures are
+// population averages and are no substitute for the child's clinician.
+const FormulaDisclaimer = "These amounts are general guidance based on weight and age, not medical advice. " +
+	"Babies' needs vary from day to day; follow your baby's hunger cues and check with your " +
+	"pediatrician or health visitor, especially for premature babies or any feeding concerns."
+
+const (
+	// StandardKcalPerOz is the energy density of formula made up as directed
+	StandardKcalPerOz = 20.0
+	minKcalPerOz      = 19.0
+	maxKcalPerOz      = 30.0
+
+	// formulaMaxAgeMonths is where the guidelines stop; after the first
+	// year milk is part of a mixed diet
+	formulaMaxAgeMonths = 12
+	// staleWeightDays flags a guide worked out from an old weight
+	staleWeightDays = 14
+)
+
+var (
+	ErrFormulaUnavailable   = errors.New("formula guidance is not configured")
+	ErrChildNotFound        = errors.New("child not found")
+	ErrNoWeight             = errors.New("no weight recorded for this child")
+	ErrUnknownGuideline     = errors.New("unknown formula guideline")
+	ErrInvalidConcentration = errors.New("formula concentration must be between 19 and 30 kcal/oz")
+	ErrOutsideGuideline     = errors.New("formula guidance covers the first 12 months only")
+)
+
+// FeedBand is the usual number of feeds a day up to an age
+type FeedBand struct {
+	UpToMonths int
+	Min        int
+	Max        int
+}
+
+// Guideline is a published rule of thumb for daily formula volume by weight,
+// at standard concentration
+type Guideline struct {
+	Name       string     `json:"name"`
+	Source     string     `json:"source"`
+	MinMlPerKg float64    `json:"min_ml_per_kg"`
+	MaxMlPerKg float64    `json:"max_ml_per_kg"`
+	MaxDailyMl float64    `json:"max_daily_ml,omitempty"` // 0 when the guideline sets no cap
+	Feeds      []FeedBand `json:"-"`
+}
+
+// DefaultFormulaGuideline is used when none is configured
+const DefaultFormulaGuideline = "aap"
+
+// FormulaGuidelines are the guidelines that can be configured or requested
+var FormulaGuidelines = map[string]Guideline{
+	"aap": {
+		Name:       "aap",
+		Source:     "American Academy of Pediatrics, HealthyChildren.org: Amount and Schedule of Baby's Formula Feedings",
+		MinMlPerKg: 130, // 2 oz per lb
+		MaxMlPerKg: 165, // 2.5 oz per lb
+		MaxDailyMl: 960, // 32 oz
+		Feeds: []FeedBand{
+			{UpToMonths: 1, Min: 8, Max: 12},
+			{UpToMonths: 2, Min: 6, Max: 8},
+			{UpToMonths: 6, Min: 5, Max: 6},
+			{UpToMonths: 12, Min: 4, Max: 5},
+		},
+	},
+	"nhs": {
+		Name:       "nhs",
+		Source:     "NHS (UK) infant formula guidance: 150-200 ml per kg a day until 6 months",
+		MinMlPerKg: 150,
+		MaxMlPerKg: 200,
+		Feeds: []FeedBand{
+			{UpToMonths: 1, Min: 8, Max: 12},
+			{UpToMonths: 3, Min: 6, Max: 8},
+			{UpToMonths: 6, Min: 5, Max: 6},
+			{UpToMonths: 12, Min: 3, Max: 4},
+		},
+	},
+}
+
+// Range is an inclusive recommendation
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+type FormulaGuide struct {
+	ChildID     string    `json:"child_id"`
+	WeightKg    float64   `json:"weight_kg"`
+	WeighedAt   time.Time `json:"weighed_at"`
+	AgeMonths   int       `json:"age_months"`
+	KcalPerOz   float64   `json:"kcal_per_oz"`
+	Guideline   Guideline `json:"guideline"`
+	DailyMl     Range     `json:"daily_ml"`
+	DailyOz     Range     `json:"daily_oz"`
+	FeedsPerDay Range     `json:"feeds_per_day"`
+	PerFeedMl   Range     `json:"per_feed_ml"`
+	PerFeedOz   Range     `json:"per_feed_oz"`
+	Notes       []string  `json:"notes,omitempty"`
+	Disclaimer  string    `json:"disclaimer"`
+}
+
+type FormulaGuideRequest struct {
+	KcalPerOz float64 // 0 means standard concentration
+	Guideline string  // empty uses the configured guideline
+}
+
+// WithFormulaGuide supplies the child's age and latest weight for the
+// formula helper, and the guideline it uses by default
+func WithFormulaGuide(familyService family.Service, growthService growth.Service, guideline string) Option {
+	return func(s *service) {
+		s.familyService = familyService
+		s.growthService = growthService
+		if guideline != "" {
+			s.formulaGuideline = guideline
+		}
+	}
+}
+
+func (s *service) GetFormulaGuide(ctx context.Context, childID string, req *FormulaGuideRequest) (*FormulaGuide, error) {
+	if s.familyService == nil || s.growthService == nil {
+		return nil, ErrFormulaUnavailable
+	}
+
+	name := req.Guideline
+	if name == "" {
+		name = s.formulaGuideline
+	}
+	guideline, ok := FormulaGuidelines[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGuideline, name)
+	}
+
+	kcalPerOz := req.KcalPerOz
+	if kcalPerOz == 0 {
+		kcalPerOz = StandardKcalPerOz
+	}
+	if kcalPerOz < minKcalPerOz || kcalPerOz > maxKcalPerOz {
+		return nil, ErrInvalidConcentration
+	}
+
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+
+	now := time.Now()
+	age := ageInMonths(child.DateOfBirth, now)
+	if age >= formulaMaxAgeMonths {
+		return nil, ErrOutsideGuideline
+	}
+
+	weight, err := s.latestWeight(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	guide := &FormulaGuide{
+		ChildID:    childID,
+		WeightKg:   *weight.WeightKg,
+		WeighedAt:  weight.MeasuredAt,
+		AgeMonths:  age,
+		KcalPerOz:  kcalPerOz,
+		Guideline:  guideline,
+		Disclaimer: FormulaDisclaimer,
+	}
+
+	// Guidelines assume standard formula. A denser feed meets the same
+	// energy needs in proportionally less volume.
+	scale := StandardKcalPerOz / kcalPerOz
+	guide.DailyMl = Range{
+		Min: guide.WeightKg * guideline.MinMlPerKg * scale,
+		Max: guide.WeightKg * guideline.MaxMlPerKg * scale,
+	}
+	if kcalPerOz != StandardKcalPerOz {
+		guide.Notes = append(guide.Notes, fmt.Sprintf(
+			"Volumes are adjusted for %.0f kcal/oz formula; only use concentrated formula as directed by your pediatrician", kcalPerOz))
+	}
+	if guideline.MaxDailyMl > 0 && guide.DailyMl.Max > guideline.MaxDailyMl {
+		guide.DailyMl.Max = guideline.MaxDailyMl
+		guide.DailyMl.Min = math.Min(guide.DailyMl.Min, guideline.MaxDailyMl)
+		guide.Notes = append(guide.Notes, fmt.Sprintf("Capped at the guideline maximum of %.0f ml a day", guideline.MaxDailyMl))
+	}
+	if days := int(now.Sub(weight.MeasuredAt).Hours() / 24); days > staleWeightDays {
+		guide.Notes = append(guide.Notes, fmt.Sprintf("Based on a weight recorded %d days ago; weigh again for an up-to-date guide", days))
+	}
+
+	band := feedBand(guideline, age)
+	guide.FeedsPerDay = Range{Min: float64(band.Min), Max: float64(band.Max)}
+	// Fewer, larger feeds or more, smaller ones cover the same daily total
+	guide.PerFeedMl = Range{Min: guide.DailyMl.Min / float64(band.Max), Max: guide.DailyMl.Max / float64(band.Min)}
+
+	guide.DailyMl = roundRange(guide.DailyMl, 0)
+	guide.PerFeedMl = roundRange(guide.PerFeedMl, 0)
+	guide.DailyOz = roundRange(Range{Min: ozOf(guide.DailyMl.Min), Max: ozOf(guide.DailyMl.Max)}, 1)
+	guide.PerFeedOz = roundRange(Range{Min: ozOf(guide.PerFeedMl.Min), Max: ozOf(guide.PerFeedMl.Max)}, 1)
+
+	return guide, nil
+}
+
+// latestWeight returns the most recent measurement that includes a weight
+func (s *service) latestWeight(ctx context.Context, childID string) (*growth.Measurement, error) {
+	measurements, err := s.growthService.List(ctx, &growth.MeasurementFilter{ChildID: childID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list measurements: %w", err)
+	}
+
+	var latest *growth.Measurement
+	for i := range measurements {
+		m := &measurements[i]
+		if m.WeightKg == nil {
+			continue
+		}
+		if latest == nil || m.MeasuredAt.After(latest.MeasuredAt) {
+			latest = m
+		}
+	}
+	if latest == nil {
+		return nil, ErrNoWeight
+	}
+	return latest, nil
+}
+
+func feedBand(g Guideline, ageMonths int) FeedBand {
+	for _, band := range g.Feeds {
+		if ageMonths < band.UpToMonths {
+			return band
+		}
+	}
+	return g.Feeds[len(g.Feeds)-1]
+}
+
+// ageInMonths counts whole months since birth
+func ageInMonths(dob, now time.Time) int {
+	months := (now.Year()-dob.Year())*12 + int(now.Month()-dob.Month())
+	if now.Day() < dob.Day() {
+		months--
+	}
+	return max(months, 0)
+}
+
+// roundRange rounds both ends to the given number of decimal places
+func roundRange(r Range, places int) Range {
+	p := math.Pow10(places)
+	return Range{Min: math.Round(r.Min*p) / p, Max: math.Round(r.Max*p) / p}
+}
+
+// ozOf converts a volume in ml to US fluid ounces
+func ozOf(ml float64) float64 {
+	oz, _ := measure.Quantity{Value: ml, Unit: measure.Milliliter}.In(measure.FluidOunce)
+	return oz.Value
+}
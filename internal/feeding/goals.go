@@ -0,0 +1,148 @@
+package feeding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/measure"
+)
+
+const (
+	// kcalPerMl is the usual energy density of breast milk and standard
+	// formula. Calories are estimated from volume; solids are not counted.
+	kcalPerMl = 0.67
+	// belowTargetPercent is how far under a goal a day has to fall before it
+	// counts as below target rather than ordinary day-to-day variation
+	belowTargetPercent = 80
+
+	defaultStatsDays = 7
+	maxStatsDays     = 90
+)
+
+// LowIntakeDays is how many complete days in a row have to run below target
+// before intake is considered persistently low
+const LowIntakeDays = 3
+
+var ErrEmptyGoal = errors.New("at least one daily goal is required")
+
+func (s *service) GetGoal(ctx context.Context, childID string) (*Goal, error) {
+	return s.repo.GetGoal(ctx, childID)
+}
+
+func (s *service) ListGoals(ctx context.Context) ([]Goal, error) {
+	return s.repo.ListGoals(ctx)
+}
+
+// SetGoal replaces the child's goal. Targets left out of the request stop
+// being tracked.
+func (s *service) SetGoal(ctx context.Context, userID, childID string, req *SetGoalRequest) (*Goal, error) {
+	if req.DailyMl == nil && req.DailyKcal == nil && req.DailyNursingMinutes == nil {
+		return nil, ErrEmptyGoal
+	}
+
+	goal := &Goal{
+		ChildID:             childID,
+		DailyMl:             req.DailyMl,
+		DailyKcal:           req.DailyKcal,
+		DailyNursingMinutes: req.DailyNursingMinutes,
+		SetBy:               userID,
+		UpdatedAt:           time.Now(),
+	}
+	if err := s.repo.UpsertGoal(ctx, goal); err != nil {
+		return nil, fmt.Errorf("failed to save feeding goal: %w", err)
+	}
+	return goal, nil
+}
+
+func (s *service) DeleteGoal(ctx context.Context, childID string) error {
+	return s.repo.DeleteGoal(ctx, childID)
+}
+
+// GetStats totals intake per day over the last days days, today included,
+// and measures each day against the child's goal when one is set
+func (s *service) GetStats(ctx context.Context, childID string, days int) (*Stats, error) {
+	if days <= 0 {
+		days = defaultStatsDays
+	}
+	if days > maxStatsDays {
+		days = maxStatsDays
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := today.AddDate(0, 0, -(days - 1))
+	end := today.AddDate(0, 0, 1)
+
+	goal, err := s.repo.GetGoal(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feeding goal: %w", err)
+	}
+	feedings, err := s.repo.List(ctx, &FeedingFilter{ChildID: childID, StartDate: &start, EndDate: &end})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedings: %w", err)
+	}
+
+	stats := &Stats{ChildID: childID, Goal: goal, Days: make([]DailyIntake, days)}
+	index := make(map[string]int, days)
+	for i := range stats.Days {
+		date := start.AddDate(0, 0, i).Format(time.DateOnly)
+		stats.Days[i].Date = date
+		index[date] = i
+	}
+
+	for _, f := range feedings {
+		i, ok := index[f.StartTime.In(now.Location()).Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+		day := &stats.Days[i]
+		day.Feedings++
+
+		if q, ok := f.Quantity(); ok && f.Type != FeedingTypeSolid {
+			if ml, err := q.In(measure.Milliliter); err == nil {
+				day.Ml += ml.Value
+				day.Kcal += ml.Value * kcalPerMl
+			}
+		}
+		if d, ok := f.Duration(); ok && f.Type == FeedingTypeBreast {
+			day.NursingMinutes += d.Minutes()
+		}
+	}
+
+	if goal == nil {
+		return stats, nil
+	}
+
+	for i := range stats.Days {
+		stats.Days[i].Progress = progress(&stats.Days[i], goal)
+	}
+	// Today is still in progress, so the streak starts from yesterday
+	for i := len(stats.Days) - 2; i >= 0 && stats.Days[i].Progress.BelowTarget; i-- {
+		stats.DaysBelowTarget++
+	}
+
+	return stats, nil
+}
+
+// progress reports a day against each goal that is set. The day is below
+// target when any of them falls under belowTargetPercent.
+func progress(day *DailyIntake, goal *Goal) *Progress {
+	p := &Progress{}
+	measure := func(actual float64, target *float64) *float64 {
+		if target == nil {
+			return nil
+		}
+		pct := actual / *target * 100
+		if pct < belowTargetPercent {
+			p.BelowTarget = true
+		}
+		return &pct
+	}
+
+	p.Ml = measure(day.Ml, goal.DailyMl)
+	p.Kcal = measure(day.Kcal, goal.DailyKcal)
+	p.NursingMinutes = measure(day.NursingMinutes, goal.DailyNursingMinutes)
+	return p
+}
@@ -1,7 +1,13 @@
 package feeding
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,16 +26,29 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.GET("/:id", h.get)
 	rg.PUT("/:id", h.update)
 	rg.DELETE("/:id", h.delete)
+	rg.POST("/:id/private", h.setPrivate)
 	rg.GET("/last/:childId", h.getLast)
+	rg.GET("/stats/:childId", h.getStats)
+	rg.GET("/goals/:childId", h.getGoal)
+	rg.PUT("/goals/:childId", h.setGoal)
+	rg.DELETE("/goals/:childId", h.deleteGoal)
+	rg.GET("/formula/:childId", h.getFormulaGuide)
+	rg.POST("/nursing/start", h.startNursing)
+	rg.GET("/nursing/:childId", h.getNursing)
+	rg.POST("/nursing/:childId/pause", h.pauseNursing)
+	rg.POST("/nursing/:childId/resume", h.resumeNursing)
+	rg.POST("/nursing/:childId/switch", h.switchNursingSide)
+	rg.POST("/nursing/:childId/stop", h.stopNursing)
 }
 
 func (h *Handler) list(c *gin.Context) {
 	filter := &FeedingFilter{
-		ChildID: c.Query("child_id"),
+		ChildID:  c.Query("child_id"),
+		ViewerID: c.GetString("user_id"),
 	}
 	feedings, err := h.service.List(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, feedings)
@@ -42,9 +61,14 @@ func (h *Handler) create(c *gin.Context) {
 		return
 	}
 
+	req.AuthorID = c.GetString("user_id")
 	feeding, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, occurrence.ErrInvalid) || errors.Is(err, ErrInvalidAmount) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, feeding)
@@ -52,9 +76,9 @@ func (h *Handler) create(c *gin.Context) {
 
 func (h *Handler) get(c *gin.Context) {
 	id := c.Param("id")
-	feeding, err := h.service.Get(c.Request.Context(), id)
+	feeding, err := h.service.Get(c.Request.Context(), c.GetString("user_id"), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, feeding)
@@ -68,9 +92,13 @@ func (h *Handler) update(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	feeding, err := h.service.Update(c.Request.Context(), id, &req)
+	feeding, err := h.service.Update(c.Request.Context(), c.GetString("user_id"), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, occurrence.ErrInvalid) || errors.Is(err, ErrInvalidAmount) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, feeding)
@@ -78,19 +106,229 @@ func (h *Handler) update(c *gin.Context) {
 
 func (h *Handler) delete(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), id); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
 
+func (h *Handler) setPrivate(c *gin.Context) {
+	var req SetPrivateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.SetPrivate(c.Request.Context(), c.GetString("user_id"), c.Param("id"), req.Private); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
 func (h *Handler) getLast(c *gin.Context) {
 	childID := c.Param("childId")
-	feeding, err := h.service.GetLastFeeding(c.Request.Context(), childID)
+	feeding, err := h.service.GetLastFeeding(c.Request.Context(), childID, c.GetString("user_id"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, feeding)
 }
+
+func (h *Handler) getStats(c *gin.Context) {
+	days := 0
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive number"})
+			return
+		}
+		days = n
+	}
+
+	stats, err := h.service.GetStats(c.Request.Context(), c.Param("childId"), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *Handler) getGoal(c *gin.Context) {
+	goal, err := h.service.GetGoal(c.Request.Context(), c.Param("childId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if goal == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no feeding goal set"})
+		return
+	}
+	c.JSON(http.StatusOK, goal)
+}
+
+func (h *Handler) setGoal(c *gin.Context) {
+	var req SetGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, err := h.service.SetGoal(c.Request.Context(), c.GetString("user_id"), c.Param("childId"), &req)
+	if err != nil {
+		if errors.Is(err, ErrEmptyGoal) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, goal)
+}
+
+func (h *Handler) deleteGoal(c *gin.Context) {
+	if err := h.service.DeleteGoal(c.Request.Context(), c.Param("childId")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) getFormulaGuide(c *gin.Context) {
+	req := FormulaGuideRequest{Guideline: c.Query("guideline")}
+	if v := c.Query("kcal_per_oz"); v != "" {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": ErrInvalidConcentration.Error()})
+			return
+		}
+		req.KcalPerOz = n
+	}
+
+	guide, err := h.service.GetFormulaGuide(c.Request.Context(), c.Param("childId"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrChildNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrUnknownGuideline), errors.Is(err, ErrInvalidConcentration):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrNoWeight), errors.Is(err, ErrOutsideGuideline):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrFormulaUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, guide)
+}
+
+func (h *Handler) startNursing(c *gin.Context) {
+	var req StartNursingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	timer, err := h.service.StartNursing(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		respondNursingError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, timer)
+}
+
+func (h *Handler) getNursing(c *gin.Context) {
+	timer, err := h.service.GetNursing(c.Request.Context(), c.GetString("user_id"), c.Param("childId"))
+	if err != nil {
+		respondNursingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, timer)
+}
+
+func (h *Handler) pauseNursing(c *gin.Context) {
+	timer, err := h.service.PauseNursing(c.Request.Context(), c.GetString("user_id"), c.Param("childId"))
+	if err != nil {
+		respondNursingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, timer)
+}
+
+func (h *Handler) resumeNursing(c *gin.Context) {
+	req, ok := bindNursingSide(c)
+	if !ok {
+		return
+	}
+
+	timer, err := h.service.ResumeNursing(c.Request.Context(), c.GetString("user_id"), c.Param("childId"), req.Side)
+	if err != nil {
+		respondNursingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, timer)
+}
+
+func (h *Handler) switchNursingSide(c *gin.Context) {
+	req, ok := bindNursingSide(c)
+	if !ok {
+		return
+	}
+
+	timer, err := h.service.SwitchNursingSide(c.Request.Context(), c.GetString("user_id"), c.Param("childId"), req.Side)
+	if err != nil {
+		respondNursingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, timer)
+}
+
+func (h *Handler) stopNursing(c *gin.Context) {
+	timer, err := h.service.StopNursing(c.Request.Context(), c.GetString("user_id"), c.Param("childId"))
+	if err != nil {
+		respondNursingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, timer)
+}
+
+// bindNursingSide reads the optional side from the body
+func bindNursingSide(c *gin.Context) (NursingSideRequest, bool) {
+	var req NursingSideRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return req, false
+		}
+	}
+	return req, true
+}
+
+func respondNursingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrChildNotFound), errors.Is(err, ErrNoTimerRunning):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTimerRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// respondError maps errors from reading and changing feedings
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, visibility.ErrChildNotFound), errors.Is(err, ErrFeedingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, family.ErrNotMember), errors.Is(err, visibility.ErrHidden), errors.Is(err, ErrNotAuthor):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
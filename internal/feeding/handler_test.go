@@ -5,11 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"iter"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/visibility"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -24,7 +29,13 @@ type mockService struct {
 	listFn           func(ctx context.Context, filter *FeedingFilter) ([]Feeding, error)
 	updateFn         func(ctx context.Context, id string, req *CreateFeedingRequest) (*Feeding, error)
 	deleteFn         func(ctx context.Context, id string) error
-	getLastFeedingFn func(ctx context.Context, childID string) (*Feeding, error)
+	getLastFeedingFn func(ctx context.Context, childID, viewerID string) (*Feeding, error)
+	setGoalFn        func(ctx context.Context, userID, childID string, req *SetGoalRequest) (*Goal, error)
+	getStatsFn       func(ctx context.Context, childID string, days int) (*Stats, error)
+	formulaGuideFn   func(ctx context.Context, childID string, req *FormulaGuideRequest) (*FormulaGuide, error)
+	startNursingFn   func(ctx context.Context, userID string, req *StartNursingRequest) (*NursingTimer, error)
+	nursingFn        func(ctx context.Context, action, childID string, side Side) (*NursingTimer, error)
+	setPrivateFn     func(ctx context.Context, userID, id string, private bool) error
 }
 
 func (m *mockService) Create(ctx context.Context, req *CreateFeedingRequest) (*Feeding, error) {
@@ -34,7 +45,7 @@ func (m *mockService) Create(ctx context.Context, req *CreateFeedingRequest) (*F
 	return nil, nil
 }
 
-func (m *mockService) Get(ctx context.Context, id string) (*Feeding, error) {
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Feeding, error) {
 	if m.getFn != nil {
 		return m.getFn(ctx, id)
 	}
@@ -48,30 +59,99 @@ func (m *mockService) List(ctx context.Context, filter *FeedingFilter) ([]Feedin
 	return nil, nil
 }
 
-func (m *mockService) Update(ctx context.Context, id string, req *CreateFeedingRequest) (*Feeding, error) {
+func (m *mockService) Stream(ctx context.Context, filter *FeedingFilter) iter.Seq2[Feeding, error] {
+	return nil
+}
+
+func (m *mockService) Update(ctx context.Context, userID, id string, req *CreateFeedingRequest) (*Feeding, error) {
 	if m.updateFn != nil {
 		return m.updateFn(ctx, id, req)
 	}
 	return nil, nil
 }
 
-func (m *mockService) Delete(ctx context.Context, id string) error {
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
 	if m.deleteFn != nil {
 		return m.deleteFn(ctx, id)
 	}
 	return nil
 }
 
-func (m *mockService) GetLastFeeding(ctx context.Context, childID string) (*Feeding, error) {
+func (m *mockService) GetLastFeeding(ctx context.Context, childID, viewerID string) (*Feeding, error) {
 	if m.getLastFeedingFn != nil {
-		return m.getLastFeedingFn(ctx, childID)
+		return m.getLastFeedingFn(ctx, childID, viewerID)
 	}
 	return nil, nil
 }
 
+func (m *mockService) GetGoal(ctx context.Context, childID string) (*Goal, error) {
+	return nil, nil
+}
+
+func (m *mockService) ListGoals(ctx context.Context) ([]Goal, error) {
+	return nil, nil
+}
+
+func (m *mockService) SetPrivate(ctx context.Context, userID, id string, private bool) error {
+	if m.setPrivateFn != nil {
+		return m.setPrivateFn(ctx, userID, id, private)
+	}
+	return nil
+}
+
+func (m *mockService) SetGoal(ctx context.Context, userID, childID string, req *SetGoalRequest) (*Goal, error) {
+	if m.setGoalFn != nil {
+		return m.setGoalFn(ctx, userID, childID, req)
+	}
+	return &Goal{ChildID: childID}, nil
+}
+
+func (m *mockService) DeleteGoal(ctx context.Context, childID string) error {
+	return nil
+}
+
+func (m *mockService) GetStats(ctx context.Context, childID string, days int) (*Stats, error) {
+	if m.getStatsFn != nil {
+		return m.getStatsFn(ctx, childID, days)
+	}
+	return &Stats{ChildID: childID}, nil
+}
+
+func (m *mockService) GetFormulaGuide(ctx context.Context, childID string, req *FormulaGuideRequest) (*FormulaGuide, error) {
+	if m.formulaGuideFn != nil {
+		return m.formulaGuideFn(ctx, childID, req)
+	}
+	return &FormulaGuide{ChildID: childID}, nil
+}
+
+func (m *mockService) StartNursing(ctx context.Context, userID string, req *StartNursingRequest) (*NursingTimer, error) {
+	return m.startNursingFn(ctx, userID, req)
+}
+
+func (m *mockService) GetNursing(ctx context.Context, userID, childID string) (*NursingTimer, error) {
+	return m.nursingFn(ctx, "get", childID, "")
+}
+
+func (m *mockService) PauseNursing(ctx context.Context, userID, childID string) (*NursingTimer, error) {
+	return m.nursingFn(ctx, "pause", childID, "")
+}
+
+func (m *mockService) ResumeNursing(ctx context.Context, userID, childID string, side Side) (*NursingTimer, error) {
+	return m.nursingFn(ctx, "resume", childID, side)
+}
+
+func (m *mockService) SwitchNursingSide(ctx context.Context, userID, childID string, side Side) (*NursingTimer, error) {
+	return m.nursingFn(ctx, "switch", childID, side)
+}
+
+func (m *mockService) StopNursing(ctx context.Context, userID, childID string) (*NursingTimer, error) {
+	return m.nursingFn(ctx, "stop", childID, "")
+}
+
 // setupRouter creates a test router with the handler registered
 func setupRouter(svc Service) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 	handler := NewHandler(svc)
 
 	// Add middleware to set user_id in context (simulating auth)
@@ -80,7 +160,7 @@ func setupRouter(svc Service) *gin.Engine {
 		c.Next()
 	})
 
-	group := router.Group("/feedings")
+	group := router.Group("/feeding")
 	handler.RegisterRoutes(group)
 	return router
 }
@@ -127,7 +207,7 @@ func TestList_Success(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -154,7 +234,7 @@ func TestList_WithFilter(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings?child_id=child-456", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding?child_id=child-456", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -177,7 +257,7 @@ func TestList_ServiceError(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -202,7 +282,7 @@ func TestList_EmptyResult(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -235,7 +315,7 @@ func TestGet_Success(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings/feeding-123", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding/feeding-123", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -260,7 +340,7 @@ func TestGet_ServiceError(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings/nonexistent", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding/nonexistent", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -277,6 +357,23 @@ func TestGet_ServiceError(t *testing.T) {
 	}
 }
 
+func TestGet_Hidden(t *testing.T) {
+	svc := &mockService{
+		getFn: func(ctx context.Context, id string) (*Feeding, error) {
+			return nil, visibility.ErrHidden
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/feeding/feeding-123", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
 func TestGet_VerifiesIDParam(t *testing.T) {
 	var capturedID string
 	svc := &mockService{
@@ -287,7 +384,7 @@ func TestGet_VerifiesIDParam(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings/my-specific-id", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding/my-specific-id", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -310,7 +407,7 @@ func TestCreate_Success(t *testing.T) {
 	router := setupRouter(svc)
 
 	body, _ := json.Marshal(validRequestBody())
-	req := httptest.NewRequest("POST", "/feedings", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/feeding", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -332,7 +429,7 @@ func TestCreate_InvalidJSON(t *testing.T) {
 	svc := &mockService{}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("POST", "/feedings", bytes.NewReader([]byte("invalid json")))
+	req := httptest.NewRequest("POST", "/feeding", bytes.NewReader([]byte("invalid json")))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -358,7 +455,7 @@ func TestCreate_MissingRequiredFields(t *testing.T) {
 	body, _ := json.Marshal(map[string]any{
 		"notes": "Some notes",
 	})
-	req := httptest.NewRequest("POST", "/feedings", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/feeding", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -377,7 +474,7 @@ func TestCreate_ServiceError(t *testing.T) {
 	router := setupRouter(svc)
 
 	body, _ := json.Marshal(validRequestBody())
-	req := httptest.NewRequest("POST", "/feedings", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/feeding", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -395,6 +492,25 @@ func TestCreate_ServiceError(t *testing.T) {
 	}
 }
 
+func TestCreate_Hidden(t *testing.T) {
+	svc := &mockService{
+		createFn: func(ctx context.Context, req *CreateFeedingRequest) (*Feeding, error) {
+			return nil, visibility.ErrHidden
+		},
+	}
+	router := setupRouter(svc)
+
+	body, _ := json.Marshal(validRequestBody())
+	req := httptest.NewRequest("POST", "/feeding", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
 func TestCreate_VerifiesRequestData(t *testing.T) {
 	var capturedReq *CreateFeedingRequest
 	svc := &mockService{
@@ -407,7 +523,7 @@ func TestCreate_VerifiesRequestData(t *testing.T) {
 
 	reqBody := validRequestBody()
 	body, _ := json.Marshal(reqBody)
-	req := httptest.NewRequest("POST", "/feedings", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/feeding", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -424,6 +540,9 @@ func TestCreate_VerifiesRequestData(t *testing.T) {
 	if capturedReq.Unit != reqBody.Unit {
 		t.Errorf("Expected Unit %s, got %s", reqBody.Unit, capturedReq.Unit)
 	}
+	if capturedReq.AuthorID != "test-user-123" {
+		t.Errorf("Expected AuthorID test-user-123, got %s", capturedReq.AuthorID)
+	}
 }
 
 func TestCreate_AllFeedingTypes(t *testing.T) {
@@ -448,7 +567,7 @@ func TestCreate_AllFeedingTypes(t *testing.T) {
 			reqBody := validRequestBody()
 			reqBody.Type = feedingType
 			body, _ := json.Marshal(reqBody)
-			req := httptest.NewRequest("POST", "/feedings", bytes.NewReader(body))
+			req := httptest.NewRequest("POST", "/feeding", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
@@ -486,7 +605,7 @@ func TestCreate_WithOptionalFields(t *testing.T) {
 		Notes:     "Good feeding session",
 	}
 	body, _ := json.Marshal(reqBody)
-	req := httptest.NewRequest("POST", "/feedings", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/feeding", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -520,7 +639,7 @@ func TestUpdate_Success(t *testing.T) {
 	router := setupRouter(svc)
 
 	body, _ := json.Marshal(validRequestBody())
-	req := httptest.NewRequest("PUT", "/feedings/feeding-123", bytes.NewReader(body))
+	req := httptest.NewRequest("PUT", "/feeding/feeding-123", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -542,7 +661,7 @@ func TestUpdate_InvalidJSON(t *testing.T) {
 	svc := &mockService{}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("PUT", "/feedings/feeding-123", bytes.NewReader([]byte("invalid json")))
+	req := httptest.NewRequest("PUT", "/feeding/feeding-123", bytes.NewReader([]byte("invalid json")))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -559,7 +678,7 @@ func TestUpdate_MissingRequiredFields(t *testing.T) {
 	body, _ := json.Marshal(map[string]any{
 		"notes": "Some notes",
 	})
-	req := httptest.NewRequest("PUT", "/feedings/feeding-123", bytes.NewReader(body))
+	req := httptest.NewRequest("PUT", "/feeding/feeding-123", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -578,7 +697,7 @@ func TestUpdate_ServiceError(t *testing.T) {
 	router := setupRouter(svc)
 
 	body, _ := json.Marshal(validRequestBody())
-	req := httptest.NewRequest("PUT", "/feedings/nonexistent", bytes.NewReader(body))
+	req := httptest.NewRequest("PUT", "/feeding/nonexistent", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -610,7 +729,7 @@ func TestUpdate_VerifiesIDAndRequest(t *testing.T) {
 
 	reqBody := validRequestBody()
 	body, _ := json.Marshal(reqBody)
-	req := httptest.NewRequest("PUT", "/feedings/update-id-456", bytes.NewReader(body))
+	req := httptest.NewRequest("PUT", "/feeding/update-id-456", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -638,7 +757,7 @@ func TestDelete_Success(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("DELETE", "/feedings/feeding-123", http.NoBody)
+	req := httptest.NewRequest("DELETE", "/feeding/feeding-123", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -655,7 +774,7 @@ func TestDelete_ServiceError(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("DELETE", "/feedings/nonexistent", http.NoBody)
+	req := httptest.NewRequest("DELETE", "/feeding/nonexistent", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -682,7 +801,7 @@ func TestDelete_VerifiesIDParam(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("DELETE", "/feedings/delete-me-789", http.NoBody)
+	req := httptest.NewRequest("DELETE", "/feeding/delete-me-789", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -697,20 +816,25 @@ func TestDelete_VerifiesIDParam(t *testing.T) {
 
 func TestGetLast_Success(t *testing.T) {
 	feeding := sampleFeeding()
+	var gotViewer string
 	svc := &mockService{
-		getLastFeedingFn: func(ctx context.Context, childID string) (*Feeding, error) {
+		getLastFeedingFn: func(ctx context.Context, childID, viewerID string) (*Feeding, error) {
+			gotViewer = viewerID
 			return feeding, nil
 		},
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings/last/child-456", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding/last/child-456", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
+	if gotViewer != "test-user-123" {
+		t.Errorf("Expected the caller as viewer, got %q", gotViewer)
+	}
 
 	var result Feeding
 	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
@@ -723,13 +847,13 @@ func TestGetLast_Success(t *testing.T) {
 
 func TestGetLast_ServiceError(t *testing.T) {
 	svc := &mockService{
-		getLastFeedingFn: func(ctx context.Context, childID string) (*Feeding, error) {
+		getLastFeedingFn: func(ctx context.Context, childID, viewerID string) (*Feeding, error) {
 			return nil, errors.New("no feedings found")
 		},
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings/last/child-456", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding/last/child-456", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -749,14 +873,14 @@ func TestGetLast_ServiceError(t *testing.T) {
 func TestGetLast_VerifiesChildIDParam(t *testing.T) {
 	var capturedChildID string
 	svc := &mockService{
-		getLastFeedingFn: func(ctx context.Context, childID string) (*Feeding, error) {
+		getLastFeedingFn: func(ctx context.Context, childID, viewerID string) (*Feeding, error) {
 			capturedChildID = childID
 			return sampleFeeding(), nil
 		},
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings/last/specific-child-id", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding/last/specific-child-id", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -767,13 +891,13 @@ func TestGetLast_VerifiesChildIDParam(t *testing.T) {
 
 func TestGetLast_NilResult(t *testing.T) {
 	svc := &mockService{
-		getLastFeedingFn: func(ctx context.Context, childID string) (*Feeding, error) {
+		getLastFeedingFn: func(ctx context.Context, childID, viewerID string) (*Feeding, error) {
 			return nil, nil
 		},
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings/last/child-456", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding/last/child-456", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -803,7 +927,7 @@ func TestRegisterRoutes(t *testing.T) {
 		deleteFn: func(ctx context.Context, id string) error {
 			return nil
 		},
-		getLastFeedingFn: func(ctx context.Context, childID string) (*Feeding, error) {
+		getLastFeedingFn: func(ctx context.Context, childID, viewerID string) (*Feeding, error) {
 			return sampleFeeding(), nil
 		},
 	}
@@ -815,12 +939,12 @@ func TestRegisterRoutes(t *testing.T) {
 		body         string
 		expectedCode int
 	}{
-		{"GET", "/feedings", "", http.StatusOK},
-		{"GET", "/feedings/feeding-123", "", http.StatusOK},
-		{"POST", "/feedings", `{"child_id":"c1","type":"bottle","start_time":"2025-01-15T10:00:00Z"}`, http.StatusCreated},
-		{"PUT", "/feedings/feeding-123", `{"child_id":"c1","type":"bottle","start_time":"2025-01-15T10:00:00Z"}`, http.StatusOK},
-		{"DELETE", "/feedings/feeding-123", "", http.StatusNoContent},
-		{"GET", "/feedings/last/child-456", "", http.StatusOK},
+		{"GET", "/feeding", "", http.StatusOK},
+		{"GET", "/feeding/feeding-123", "", http.StatusOK},
+		{"POST", "/feeding", `{"child_id":"c1","type":"bottle","start_time":"2025-01-15T10:00:00Z"}`, http.StatusCreated},
+		{"PUT", "/feeding/feeding-123", `{"child_id":"c1","type":"bottle","start_time":"2025-01-15T10:00:00Z"}`, http.StatusOK},
+		{"DELETE", "/feeding/feeding-123", "", http.StatusNoContent},
+		{"GET", "/feeding/last/child-456", "", http.StatusOK},
 	}
 
 	for _, tc := range testCases {
@@ -866,7 +990,7 @@ func TestCreate_EmptyBody(t *testing.T) {
 	svc := &mockService{}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("POST", "/feedings", bytes.NewReader([]byte("")))
+	req := httptest.NewRequest("POST", "/feeding", bytes.NewReader([]byte("")))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -880,7 +1004,7 @@ func TestUpdate_EmptyBody(t *testing.T) {
 	svc := &mockService{}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("PUT", "/feedings/feeding-123", bytes.NewReader([]byte("")))
+	req := httptest.NewRequest("PUT", "/feeding/feeding-123", bytes.NewReader([]byte("")))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -895,7 +1019,7 @@ func TestCreate_MalformedJSON(t *testing.T) {
 	router := setupRouter(svc)
 
 	// JSON with trailing comma
-	req := httptest.NewRequest("POST", "/feedings", bytes.NewReader([]byte(`{"child_id":"c1",}`)))
+	req := httptest.NewRequest("POST", "/feeding", bytes.NewReader([]byte(`{"child_id":"c1",}`)))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -910,7 +1034,7 @@ func TestUpdate_MalformedJSON(t *testing.T) {
 	router := setupRouter(svc)
 
 	// JSON with missing closing brace
-	req := httptest.NewRequest("PUT", "/feedings/feeding-123", bytes.NewReader([]byte(`{"child_id":"c1"`)))
+	req := httptest.NewRequest("PUT", "/feeding/feeding-123", bytes.NewReader([]byte(`{"child_id":"c1"`)))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -934,7 +1058,7 @@ func TestList_MultipleFeedings(t *testing.T) {
 	}
 	router := setupRouter(svc)
 
-	req := httptest.NewRequest("GET", "/feedings", http.NoBody)
+	req := httptest.NewRequest("GET", "/feeding", http.NoBody)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -968,7 +1092,7 @@ func TestCreate_WithNilOptionalFields(t *testing.T) {
 		StartTime: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
 	}
 	body, _ := json.Marshal(reqBody)
-	req := httptest.NewRequest("POST", "/feedings", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/feeding", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -986,3 +1110,248 @@ func TestCreate_WithNilOptionalFields(t *testing.T) {
 		t.Errorf("Expected Side to be empty, got %s", capturedReq.Side)
 	}
 }
+
+func TestHandler_GetStats(t *testing.T) {
+	var gotDays int
+	svc := &mockService{
+		getStatsFn: func(ctx context.Context, childID string, days int) (*Stats, error) {
+			gotDays = days
+			return &Stats{ChildID: childID}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/feeding/stats/child-123?days=14", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if gotDays != 14 {
+		t.Errorf("Expected 14 days, got %d", gotDays)
+	}
+
+	req = httptest.NewRequest("GET", "/feeding/stats/child-123?days=soon", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for bad days, got %d", w.Code)
+	}
+}
+
+func TestHandler_SetGoal(t *testing.T) {
+	var gotUser string
+	svc := &mockService{
+		setGoalFn: func(ctx context.Context, userID, childID string, req *SetGoalRequest) (*Goal, error) {
+			gotUser = userID
+			return &Goal{ChildID: childID, DailyMl: req.DailyMl}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("PUT", "/feeding/goals/child-123", bytes.NewBufferString(`{"daily_ml":700}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotUser != "test-user-123" {
+		t.Errorf("Expected goal set by session user, got %q", gotUser)
+	}
+
+	req = httptest.NewRequest("PUT", "/feeding/goals/child-123", bytes.NewBufferString(`{"daily_ml":-5}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for negative goal, got %d", w.Code)
+	}
+}
+
+func TestHandler_GetFormulaGuide(t *testing.T) {
+	var gotReq *FormulaGuideRequest
+	svc := &mockService{
+		formulaGuideFn: func(ctx context.Context, childID string, req *FormulaGuideRequest) (*FormulaGuide, error) {
+			gotReq = req
+			return &FormulaGuide{ChildID: childID, Disclaimer: FormulaDisclaimer}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/feeding/formula/child-123?kcal_per_oz=24&guideline=nhs", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if gotReq.KcalPerOz != 24 || gotReq.Guideline != "nhs" {
+		t.Errorf("Expected concentration and guideline from the query, got %+v", gotReq)
+	}
+}
+
+func TestHandler_GetFormulaGuide_ErrorStatuses(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"unknown child", ErrChildNotFound, http.StatusNotFound},
+		{"no weight", ErrNoWeight, http.StatusUnprocessableEntity},
+		{"too old", ErrOutsideGuideline, http.StatusUnprocessableEntity},
+		{"bad concentration", ErrInvalidConcentration, http.StatusBadRequest},
+		{"bad guideline", ErrUnknownGuideline, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				formulaGuideFn: func(ctx context.Context, childID string, req *FormulaGuideRequest) (*FormulaGuide, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("GET", "/feeding/formula/child-123", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_StartNursing(t *testing.T) {
+	svc := &mockService{
+		startNursingFn: func(ctx context.Context, userID string, req *StartNursingRequest) (*NursingTimer, error) {
+			if userID != "test-user-123" || req.ChildID != "child-123" || req.Side != SideLeft {
+				t.Errorf("StartNursing() called with %s, %+v", userID, req)
+			}
+			return &NursingTimer{FeedingID: "feeding-1", ChildID: req.ChildID, Side: req.Side}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/feeding/nursing/start", bytes.NewBufferString(`{"child_id":"child-123","side":"left"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/feeding/nursing/start", bytes.NewBufferString(`{"child_id":"child-123","side":"middle"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown side, got %d", w.Code)
+	}
+}
+
+func TestHandler_NursingActions(t *testing.T) {
+	var calls []string
+	svc := &mockService{
+		nursingFn: func(ctx context.Context, action, childID string, side Side) (*NursingTimer, error) {
+			calls = append(calls, action+":"+string(side))
+			return &NursingTimer{ChildID: childID}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	requests := []*http.Request{
+		httptest.NewRequest("GET", "/feeding/nursing/child-123", http.NoBody),
+		httptest.NewRequest("POST", "/feeding/nursing/child-123/pause", http.NoBody),
+		httptest.NewRequest("POST", "/feeding/nursing/child-123/resume", http.NoBody),
+		httptest.NewRequest("POST", "/feeding/nursing/child-123/switch", bytes.NewBufferString(`{"side":"right"}`)),
+		httptest.NewRequest("POST", "/feeding/nursing/child-123/stop", http.NoBody),
+	}
+	for _, req := range requests {
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s %s: expected status 200, got %d", req.Method, req.URL.Path, w.Code)
+		}
+	}
+
+	want := []string{"get:", "pause:", "resume:", "switch:right", "stop:"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestHandler_Nursing_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"no timer", ErrNoTimerRunning, http.StatusNotFound},
+		{"child not found", ErrChildNotFound, http.StatusNotFound},
+		{"not member", ErrNotMember, http.StatusForbidden},
+		{"already running", ErrTimerRunning, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				nursingFn: func(ctx context.Context, action, childID string, side Side) (*NursingTimer, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/feeding/nursing/child-123/pause", http.NoBody))
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetPrivate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, http.StatusOK},
+		{"not author", ErrNotAuthor, http.StatusForbidden},
+		{"not found", ErrFeedingNotFound, http.StatusNotFound},
+		{"other", errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				setPrivateFn: func(ctx context.Context, userID, id string, private bool) error {
+					if userID != "test-user-123" || id != "feeding-123" || !private {
+						t.Errorf("SetPrivate(%s, %s, %v)", userID, id, private)
+					}
+					return tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/feeding/feeding-123/private", bytes.NewBufferString(`{"private":true}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
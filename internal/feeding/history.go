@@ -0,0 +1,29 @@
+package feeding
+
+import (
+	"context"
+	"log"
+
+	"github.com/ninenine/babytrack/internal/audit"
+)
+
+// Option configures the feeding service
+type Option func(*service)
+
+// WithHistory keeps a version of every feeding record written, for as-of exports
+func WithHistory(store audit.Store) Option {
+	return func(s *service) {
+		s.history = store
+	}
+}
+
+// recordVersion is best effort: the write has already happened, so a
+// failure to keep its history is only logged.
+func (s *service) recordVersion(ctx context.Context, f *Feeding, action audit.Action) {
+	if s.history == nil {
+		return
+	}
+	if err := s.history.Record(ctx, audit.EntityFeeding, f.ID, f.ChildID, action, f); err != nil {
+		log.Printf("[feeding] failed to record %s of %s: %v", action, f.ID, err)
+	}
+}
@@ -1,6 +1,10 @@
 package feeding
 
-import "time"
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/measure"
+)
 
 type FeedingType string
 
@@ -24,6 +28,36 @@ type Feeding struct {
 	CreatedAt time.Time   `json:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at"`
 	SyncedAt  *time.Time  `json:"synced_at,omitempty"`
+	// AuthorID is who logged the feeding, when it was logged by a member
+	AuthorID string `json:"author_id,omitempty"`
+	// Private feedings are only shown to their author, including in exports
+	Private bool `json:"private"`
+}
+
+// VisibleTo reports whether userID may see the feeding
+func (f *Feeding) VisibleTo(userID string) bool {
+	return !f.Private || f.AuthorID == userID
+}
+
+// Quantity returns the logged amount, taken as ml when no unit was recorded
+func (f *Feeding) Quantity() (measure.Quantity, bool) {
+	if f.Amount == nil {
+		return measure.Quantity{}, false
+	}
+	return amountOf(*f.Amount, f.Unit), true
+}
+
+// Duration returns how long the feed lasted, and false while it is ongoing
+func (f *Feeding) Duration() (measure.Duration, bool) {
+	return measure.Between(f.StartTime, f.EndTime)
+}
+
+func amountOf(amount float64, unit string) measure.Quantity {
+	u := measure.ParseUnit(unit)
+	if u == "" {
+		u = measure.Milliliter
+	}
+	return measure.Quantity{Value: amount, Unit: u}
 }
 
 type CreateFeedingRequest struct {
@@ -35,6 +69,15 @@ type CreateFeedingRequest struct {
 	Unit      string      `json:"unit,omitempty"`
 	Side      string      `json:"side,omitempty"`
 	Notes     string      `json:"notes,omitempty"`
+	// Private is only read on create; SetPrivate changes it afterwards
+	Private bool `json:"private"`
+	// AuthorID is set by the server to the member logging the feeding. A
+	// feeding without one cannot be private.
+	AuthorID string `json:"-"`
+}
+
+type SetPrivateRequest struct {
+	Private bool `json:"private"`
 }
 
 type FeedingFilter struct {
@@ -42,4 +85,87 @@ type FeedingFilter struct {
 	StartDate *time.Time
 	EndDate   *time.Time
 	Type      *FeedingType
+	// ViewerID leaves out feedings hidden from that member, and other
+	// members' private feedings, when set
+	ViewerID string
+}
+
+// Goal is a child's daily intake target. Any of the three may be set; a nil
+// target is not tracked.
+type Goal struct {
+	ChildID             string    `json:"child_id"`
+	DailyMl             *float64  `json:"daily_ml,omitempty"`
+	DailyKcal           *float64  `json:"daily_kcal,omitempty"`
+	DailyNursingMinutes *float64  `json:"daily_nursing_minutes,omitempty"`
+	SetBy               string    `json:"set_by,omitempty"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+type SetGoalRequest struct {
+	DailyMl             *float64 `json:"daily_ml,omitempty" binding:"omitempty,gt=0"`
+	DailyKcal           *float64 `json:"daily_kcal,omitempty" binding:"omitempty,gt=0"`
+	DailyNursingMinutes *float64 `json:"daily_nursing_minutes,omitempty" binding:"omitempty,gt=0"`
+}
+
+// Progress is a day's intake as a percentage of each goal that is set
+type Progress struct {
+	Ml             *float64 `json:"ml,omitempty"`
+	Kcal           *float64 `json:"kcal,omitempty"`
+	NursingMinutes *float64 `json:"nursing_minutes,omitempty"`
+	BelowTarget    bool     `json:"below_target"`
+}
+
+type DailyIntake struct {
+	Date           string    `json:"date"` // YYYY-MM-DD, server local time
+	Feedings       int       `json:"feedings"`
+	Ml             float64   `json:"ml"`
+	Kcal           float64   `json:"kcal"`
+	NursingMinutes float64   `json:"nursing_minutes"`
+	Progress       *Progress `json:"progress,omitempty"`
+}
+
+type Stats struct {
+	ChildID string        `json:"child_id"`
+	Goal    *Goal         `json:"goal,omitempty"`
+	Days    []DailyIntake `json:"days"` // oldest first, ending with today
+	// DaysBelowTarget counts the complete days, back from yesterday, that
+	// ran below target without a break
+	DaysBelowTarget int `json:"days_below_target"`
+}
+
+type Side string
+
+const (
+	SideLeft  Side = "left"
+	SideRight Side = "right"
+)
+
+// NursingTimer is a breastfeed being timed on the server, so any of the
+// family's devices can pause, switch sides or stop it. Elapsed seconds run
+// up to the time the timer was read.
+type NursingTimer struct {
+	FeedingID string `json:"feeding_id"`
+	ChildID   string `json:"child_id"`
+	// Side is the side in use, or the last one used while paused
+	Side   Side `json:"side"`
+	Paused bool `json:"paused"`
+	// SideStartedAt is when the current stretch on Side began counting;
+	// unset while paused
+	SideStartedAt *time.Time `json:"side_started_at,omitempty"`
+	LeftSeconds   int        `json:"left_seconds"`
+	RightSeconds  int        `json:"right_seconds"`
+	StartedBy     string     `json:"started_by,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+}
+
+type StartNursingRequest struct {
+	ChildID string `json:"child_id" binding:"required"`
+	Side    Side   `json:"side" binding:"required,oneof=left right"`
+}
+
+// NursingSideRequest picks the side to resume or switch to; it defaults to
+// the last side on resume and the other side on switch
+type NursingSideRequest struct {
+	Side Side `json:"side,omitempty" binding:"omitempty,oneof=left right"`
 }
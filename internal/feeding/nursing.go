@@ -0,0 +1,288 @@
+package feeding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+var (
+	ErrNotMember      = errors.New("user is not a member of this family")
+	ErrTimerRunning   = errors.New("a nursing timer is already running for this child")
+	ErrNoTimerRunning = errors.New("no nursing timer is running for this child")
+)
+
+// Notifier delivers timer changes to the family's devices
+type Notifier interface {
+	SendToUsers(userIDs []string, event notifications.Event)
+}
+
+// WithNursingTimer restricts the nursing timer to members of the child's
+// family and sends every change to all of their devices, including the one
+// that made it, so a phone picked up mid-feed shows the same timer.
+func WithNursingTimer(familyService family.Service, notifier Notifier) Option {
+	return func(s *service) {
+		s.familyService = familyService
+		s.notifier = notifier
+	}
+}
+
+// StartNursing starts a breastfeed on one side. The feed is logged straight
+// away and ends when the timer is stopped.
+func (s *service) StartNursing(ctx context.Context, userID string, req *StartNursingRequest) (*NursingTimer, error) {
+	members, err := s.timerAudience(ctx, userID, req.ChildID)
+	if err != nil {
+		return nil, err
+	}
+	running, err := s.repo.GetNursingTimer(ctx, req.ChildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nursing timer: %w", err)
+	}
+	if running != nil {
+		return nil, ErrTimerRunning
+	}
+
+	now := time.Now()
+	feeding := &Feeding{
+		ID:        ids.New(),
+		ChildID:   req.ChildID,
+		Type:      FeedingTypeBreast,
+		StartTime: now,
+		Side:      string(req.Side),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Create(ctx, feeding); err != nil {
+		return nil, fmt.Errorf("failed to create feeding: %w", err)
+	}
+	s.recordVersion(ctx, feeding, audit.ActionCreate)
+
+	timer := &NursingTimer{
+		FeedingID:     feeding.ID,
+		ChildID:       req.ChildID,
+		Side:          req.Side,
+		SideStartedAt: &now,
+		StartedBy:     userID,
+		UpdatedAt:     now,
+	}
+	if err := s.repo.CreateNursingTimer(ctx, timer); err != nil {
+		return nil, fmt.Errorf("failed to create nursing timer: %w", err)
+	}
+
+	return s.timerChanged(members, timer, "Nursing started"), nil
+}
+
+// GetNursing returns the child's running timer, or nil when there is none
+func (s *service) GetNursing(ctx context.Context, userID, childID string) (*NursingTimer, error) {
+	if _, err := s.timerAudience(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+	timer, err := s.repo.GetNursingTimer(ctx, childID)
+	if err != nil || timer == nil {
+		return nil, err
+	}
+	view := timer.asOf(time.Now())
+	return &view, nil
+}
+
+func (s *service) PauseNursing(ctx context.Context, userID, childID string) (*NursingTimer, error) {
+	return s.changeTimer(ctx, userID, childID, "Nursing paused", func(t *NursingTimer, now time.Time) {
+		t.settle(now)
+		t.SideStartedAt = nil
+	})
+}
+
+// ResumeNursing restarts a paused timer. Resuming one that is running leaves
+// it as it is.
+func (s *service) ResumeNursing(ctx context.Context, userID, childID string, side Side) (*NursingTimer, error) {
+	return s.changeTimer(ctx, userID, childID, "Nursing resumed", func(t *NursingTimer, now time.Time) {
+		if t.SideStartedAt != nil {
+			return
+		}
+		if side != "" {
+			t.Side = side
+		}
+		t.SideStartedAt = &now
+	})
+}
+
+// SwitchNursingSide moves the timer to the other side, or the one given.
+// A paused timer resumes on the new side.
+func (s *service) SwitchNursingSide(ctx context.Context, userID, childID string, side Side) (*NursingTimer, error) {
+	return s.changeTimer(ctx, userID, childID, "Nursing switched sides", func(t *NursingTimer, now time.Time) {
+		t.settle(now)
+		if side == "" {
+			side = t.Side.other()
+		}
+		t.Side = side
+		t.SideStartedAt = &now
+	})
+}
+
+// StopNursing ends the timer and its feed. The feed's side becomes "both"
+// when time was spent on each.
+func (s *service) StopNursing(ctx context.Context, userID, childID string) (*NursingTimer, error) {
+	members, err := s.timerAudience(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+	timer, err := s.runningTimer(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	timer.settle(now)
+	timer.SideStartedAt = nil
+	timer.EndedAt = &now
+	timer.UpdatedAt = now
+	if err := s.repo.UpdateNursingTimer(ctx, timer); err != nil {
+		return nil, fmt.Errorf("failed to update nursing timer: %w", err)
+	}
+
+	feeding, err := s.repo.GetByID(ctx, timer.FeedingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feeding: %w", err)
+	}
+	if feeding != nil {
+		feeding.EndTime = &now
+		feeding.Side = timer.sidesUsed()
+		feeding.UpdatedAt = now
+		if err := s.repo.Update(ctx, feeding); err != nil {
+			return nil, fmt.Errorf("failed to update feeding: %w", err)
+		}
+		s.recordVersion(ctx, feeding, audit.ActionUpdate)
+	}
+
+	return s.timerChanged(members, timer, "Nursing stopped"), nil
+}
+
+// changeTimer applies a change to the child's running timer and shares it
+func (s *service) changeTimer(ctx context.Context, userID, childID, title string, change func(*NursingTimer, time.Time)) (*NursingTimer, error) {
+	members, err := s.timerAudience(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+	timer, err := s.runningTimer(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	change(timer, now)
+	timer.UpdatedAt = now
+	if err := s.repo.UpdateNursingTimer(ctx, timer); err != nil {
+		return nil, fmt.Errorf("failed to update nursing timer: %w", err)
+	}
+
+	return s.timerChanged(members, timer, title), nil
+}
+
+func (s *service) runningTimer(ctx context.Context, childID string) (*NursingTimer, error) {
+	timer, err := s.repo.GetNursingTimer(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nursing timer: %w", err)
+	}
+	if timer == nil {
+		return nil, ErrNoTimerRunning
+	}
+	return timer, nil
+}
+
+// timerAudience checks the user belongs to the child's family and returns
+// the members to tell about the change. Without a family service every
+// caller is allowed and nobody is told.
+func (s *service) timerAudience(ctx context.Context, userID, childID string) ([]family.MemberWithUser, error) {
+	if s.familyService == nil {
+		return nil, nil
+	}
+
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+
+	members, err := s.familyService.GetFamilyMembers(ctx, child.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family members: %w", err)
+	}
+	if !slices.ContainsFunc(members, func(m family.MemberWithUser) bool { return m.UserID == userID }) {
+		return nil, ErrNotMember
+	}
+	return members, nil
+}
+
+// timerChanged sends the timer to every member's devices and returns it as
+// of now
+func (s *service) timerChanged(members []family.MemberWithUser, timer *NursingTimer, title string) *NursingTimer {
+	view := timer.asOf(timer.UpdatedAt)
+	if s.notifier == nil || len(members) == 0 {
+		return &view
+	}
+
+	userIDs := make([]string, 0, len(members))
+	for _, m := range members {
+		userIDs = append(userIDs, m.UserID)
+	}
+	s.notifier.SendToUsers(userIDs, notifications.Event{
+		ID:        ids.New(),
+		Type:      notifications.EventNursingTimer,
+		Title:     title,
+		ChildID:   timer.ChildID,
+		Timestamp: timer.UpdatedAt,
+		Data:      view,
+	})
+	return &view
+}
+
+// settle adds the running stretch to its side's total and starts a new
+// stretch at now
+func (t *NursingTimer) settle(now time.Time) {
+	if t.SideStartedAt == nil {
+		return
+	}
+	elapsed := int(now.Sub(*t.SideStartedAt).Round(time.Second) / time.Second)
+	if t.Side == SideLeft {
+		t.LeftSeconds += max(elapsed, 0)
+	} else {
+		t.RightSeconds += max(elapsed, 0)
+	}
+	t.SideStartedAt = &now
+}
+
+// asOf returns a copy with elapsed time counted up to now
+func (t *NursingTimer) asOf(now time.Time) NursingTimer {
+	view := *t
+	view.settle(now)
+	view.Paused = view.SideStartedAt == nil
+	return view
+}
+
+func (t *NursingTimer) sidesUsed() string {
+	switch {
+	case t.LeftSeconds > 0 && t.RightSeconds > 0:
+		return "both"
+	case t.LeftSeconds > 0:
+		return string(SideLeft)
+	case t.RightSeconds > 0:
+		return string(SideRight)
+	}
+	return string(t.Side)
+}
+
+func (side Side) other() Side {
+	if side == SideLeft {
+		return SideRight
+	}
+	return SideLeft
+}
@@ -0,0 +1,47 @@
+package feeding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+)
+
+var (
+	ErrFeedingNotFound = errors.New("feeding not found")
+	ErrNotAuthor       = errors.New("only the author can change who sees a feeding")
+)
+
+// hiddenFrom reports whether the feeding is another member's private feeding.
+// Reads without a user, by jobs and other services, see every feeding.
+func hiddenFrom(f *Feeding, userID string) bool {
+	return userID != "" && !f.VisibleTo(userID)
+}
+
+// SetPrivate lets the feeding's author hide it from the other members, or
+// show it again. Other members' private feedings are not found.
+func (s *service) SetPrivate(ctx context.Context, userID, id string, private bool) error {
+	feeding, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if feeding == nil || hiddenFrom(feeding, userID) {
+		return ErrFeedingNotFound
+	}
+	if err := s.authorize(ctx, userID, feeding.ChildID); err != nil {
+		return err
+	}
+	if feeding.AuthorID != userID {
+		return ErrNotAuthor
+	}
+
+	feeding.Private = private
+	feeding.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, feeding); err != nil {
+		return fmt.Errorf("failed to update feeding: %w", err)
+	}
+	s.recordVersion(ctx, feeding, audit.ActionUpdate)
+	return nil
+}
@@ -5,15 +5,32 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"iter"
+
+	"github.com/ninenine/babytrack/internal/archive"
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
 )
 
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*Feeding, error)
+	// List returns the latest 100 feedings matching filter, newest first
 	List(ctx context.Context, filter *FeedingFilter) ([]Feeding, error)
+	// Stream yields every feeding matching filter, oldest first, reading
+	// rows as they are consumed
+	Stream(ctx context.Context, filter *FeedingFilter) iter.Seq2[Feeding, error]
 	Create(ctx context.Context, feeding *Feeding) error
 	Update(ctx context.Context, feeding *Feeding) error
 	Delete(ctx context.Context, id string) error
-	GetLastFeeding(ctx context.Context, childID string) (*Feeding, error)
+	GetLastFeeding(ctx context.Context, childID, viewerID string) (*Feeding, error)
+	GetGoal(ctx context.Context, childID string) (*Goal, error)
+	ListGoals(ctx context.Context) ([]Goal, error)
+	UpsertGoal(ctx context.Context, goal *Goal) error
+	DeleteGoal(ctx context.Context, childID string) error
+	// GetNursingTimer returns the child's running nursing timer, if any
+	GetNursingTimer(ctx context.Context, childID string) (*NursingTimer, error)
+	CreateNursingTimer(ctx context.Context, t *NursingTimer) error
+	UpdateNursingTimer(ctx context.Context, t *NursingTimer) error
 }
 
 type repository struct {
@@ -24,29 +41,31 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) GetByID(ctx context.Context, id string) (*Feeding, error) {
-	query := `
-		SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at
-		FROM feedings
-		WHERE id = $1
-	`
+// HealthCheck checks the feeding, nursing timer and goal tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "feedings", "nursing_timers", "feeding_goals")
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
 
+const feedingColumns = `id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private`
+
+func scanFeeding(row scanner) (*Feeding, error) {
 	var f Feeding
 	var endTime, syncedAt sql.NullTime
 	var amount sql.NullFloat64
-	var unit, side, notes sql.NullString
+	var unit, side, notes, authorID sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	if err := row.Scan(
 		&f.ID, &f.ChildID, &f.Type, &f.StartTime, &endTime,
 		&amount, &unit, &side, &notes, &f.CreatedAt, &f.UpdatedAt, &syncedAt,
-	)
-
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, nil
-	}
-	if err != nil {
+		&authorID, &f.Private,
+	); err != nil {
 		return nil, err
 	}
+	f.AuthorID = authorID.String
 
 	if endTime.Valid {
 		f.EndTime = &endTime.Time
@@ -70,12 +89,19 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Feeding, error) {
 	return &f, nil
 }
 
-func (r *repository) List(ctx context.Context, filter *FeedingFilter) ([]Feeding, error) {
-	query := `
-		SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at
-		FROM feedings
-		WHERE 1=1
-	`
+func (r *repository) GetByID(ctx context.Context, id string) (*Feeding, error) {
+	query := `SELECT ` + feedingColumns + ` FROM feedings_all WHERE id = $1`
+
+	f, err := scanFeeding(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return f, err
+}
+
+// filterQuery selects the feedings matching filter, leaving the order to the caller
+func filterQuery(filter *FeedingFilter) (string, []any) {
+	query := `SELECT ` + feedingColumns + ` FROM feedings_all WHERE 1=1`
 	args := []any{}
 	argIndex := 1
 
@@ -100,8 +126,19 @@ func (r *repository) List(ctx context.Context, filter *FeedingFilter) ([]Feeding
 	if filter.Type != nil {
 		query += fmt.Sprintf(` AND type = $%d`, argIndex)
 		args = append(args, *filter.Type)
+		argIndex++
+	}
+
+	if filter.ViewerID != "" {
+		query += fmt.Sprintf(` AND (private = FALSE OR author_id = $%d)`, argIndex)
+		args = append(args, filter.ViewerID)
 	}
 
+	return query, args
+}
+
+func (r *repository) List(ctx context.Context, filter *FeedingFilter) ([]Feeding, error) {
+	query, args := filterQuery(filter)
 	query += ` ORDER BY start_time DESC LIMIT 100`
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -110,56 +147,38 @@ func (r *repository) List(ctx context.Context, filter *FeedingFilter) ([]Feeding
 	}
 	defer rows.Close() //nolint:errcheck // Best-effort close
 
-	var feedings []Feeding
+	feedings := []Feeding{}
 	for rows.Next() {
-		var f Feeding
-		var endTime, syncedAt sql.NullTime
-		var amount sql.NullFloat64
-		var unit, side, notes sql.NullString
-
-		if err := rows.Scan(
-			&f.ID, &f.ChildID, &f.Type, &f.StartTime, &endTime,
-			&amount, &unit, &side, &notes, &f.CreatedAt, &f.UpdatedAt, &syncedAt,
-		); err != nil {
+		f, err := scanFeeding(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		if endTime.Valid {
-			f.EndTime = &endTime.Time
-		}
-		if amount.Valid {
-			f.Amount = &amount.Float64
-		}
-		if unit.Valid {
-			f.Unit = unit.String
-		}
-		if side.Valid {
-			f.Side = side.String
-		}
-		if notes.Valid {
-			f.Notes = notes.String
-		}
-		if syncedAt.Valid {
-			f.SyncedAt = &syncedAt.Time
-		}
-
-		feedings = append(feedings, f)
-	}
-
-	if feedings == nil {
-		return []Feeding{}, nil
+		feedings = append(feedings, *f)
 	}
 
 	return feedings, rows.Err()
 }
 
+func (r *repository) Stream(ctx context.Context, filter *FeedingFilter) iter.Seq2[Feeding, error] {
+	query, args := filterQuery(filter)
+	query += ` ORDER BY start_time ASC, id ASC`
+
+	return db.Stream(ctx, r.db, func(rows *sql.Rows) (Feeding, error) {
+		f, err := scanFeeding(rows)
+		if err != nil {
+			return Feeding{}, err
+		}
+		return *f, nil
+	}, query, args...)
+}
+
 func (r *repository) Create(ctx context.Context, feeding *Feeding) error {
 	query := `
-		INSERT INTO feedings (id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO feedings (id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, author_id, private)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 
-	var unit, side, notes *string
+	var unit, side, notes, authorID *string
 	if feeding.Unit != "" {
 		unit = &feeding.Unit
 	}
@@ -169,6 +188,9 @@ func (r *repository) Create(ctx context.Context, feeding *Feeding) error {
 	if feeding.Notes != "" {
 		notes = &feeding.Notes
 	}
+	if feeding.AuthorID != "" {
+		authorID = &feeding.AuthorID
+	}
 
 	_, err := r.db.ExecContext(ctx, query,
 		feeding.ID,
@@ -182,15 +204,22 @@ func (r *repository) Create(ctx context.Context, feeding *Feeding) error {
 		notes,
 		feeding.CreatedAt,
 		feeding.UpdatedAt,
+		authorID,
+		feeding.Private,
 	)
 
 	return err
 }
 
 func (r *repository) Update(ctx context.Context, feeding *Feeding) error {
+	// Edits to archived history bring the record back to the live table
+	if err := archive.Restore(ctx, r.db, archive.Feedings, feeding.ID); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE feedings
-		SET type = $2, start_time = $3, end_time = $4, amount = $5, unit = $6, side = $7, notes = $8, updated_at = $9
+		SET type = $2, start_time = $3, end_time = $4, amount = $5, unit = $6, side = $7, notes = $8, updated_at = $9, private = $10
 		WHERE id = $1
 	`
 
@@ -215,61 +244,178 @@ func (r *repository) Update(ctx context.Context, feeding *Feeding) error {
 		side,
 		notes,
 		feeding.UpdatedAt,
+		feeding.Private,
 	)
 
 	return err
 }
 
 func (r *repository) Delete(ctx context.Context, id string) error {
+	if err := archive.Restore(ctx, r.db, archive.Feedings, id); err != nil {
+		return err
+	}
+
 	query := `DELETE FROM feedings WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *repository) GetLastFeeding(ctx context.Context, childID string) (*Feeding, error) {
+// GetLastFeeding returns the child's latest feeding that viewerID may see,
+// or the latest of all when viewerID is empty
+func (r *repository) GetLastFeeding(ctx context.Context, childID, viewerID string) (*Feeding, error) {
 	query := `
-		SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at
+		SELECT ` + feedingColumns + `
 		FROM feedings
-		WHERE child_id = $1
+		WHERE child_id = $1 AND ($2 = '' OR private = FALSE OR author_id = $2)
 		ORDER BY start_time DESC
 		LIMIT 1
 	`
 
-	var f Feeding
-	var endTime, syncedAt sql.NullTime
-	var amount sql.NullFloat64
-	var unit, side, notes sql.NullString
+	f, err := scanFeeding(db.Use(ctx, r.db).QueryRowContext(ctx, query, childID, viewerID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return f, err
+}
 
-	err := r.db.QueryRowContext(ctx, query, childID).Scan(
-		&f.ID, &f.ChildID, &f.Type, &f.StartTime, &endTime,
-		&amount, &unit, &side, &notes, &f.CreatedAt, &f.UpdatedAt, &syncedAt,
-	)
+const goalColumns = `child_id, daily_ml, daily_kcal, daily_nursing_minutes, set_by, updated_at`
+
+func scanGoal(row scanner) (*Goal, error) {
+	var g Goal
+	var ml, kcal, minutes sql.NullFloat64
+	var setBy sql.NullString
+
+	if err := row.Scan(&g.ChildID, &ml, &kcal, &minutes, &setBy, &g.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if ml.Valid {
+		g.DailyMl = &ml.Float64
+	}
+	if kcal.Valid {
+		g.DailyKcal = &kcal.Float64
+	}
+	if minutes.Valid {
+		g.DailyNursingMinutes = &minutes.Float64
+	}
+	if setBy.Valid {
+		g.SetBy = setBy.String
+	}
+	return &g, nil
+}
 
+func (r *repository) GetGoal(ctx context.Context, childID string) (*Goal, error) {
+	query := `SELECT ` + goalColumns + ` FROM feeding_goals WHERE child_id = $1`
+
+	g, err := scanGoal(r.db.QueryRowContext(ctx, query, childID))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	return g, nil
+}
 
-	if endTime.Valid {
-		f.EndTime = &endTime.Time
+func (r *repository) ListGoals(ctx context.Context) ([]Goal, error) {
+	query := `SELECT ` + goalColumns + ` FROM feeding_goals ORDER BY child_id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
 	}
-	if amount.Valid {
-		f.Amount = &amount.Float64
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	var goals []Goal
+	for rows.Next() {
+		g, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, *g)
 	}
-	if unit.Valid {
-		f.Unit = unit.String
+	return goals, rows.Err()
+}
+
+func (r *repository) UpsertGoal(ctx context.Context, goal *Goal) error {
+	query := `
+		INSERT INTO feeding_goals (` + goalColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (child_id) DO UPDATE SET
+			daily_ml = EXCLUDED.daily_ml,
+			daily_kcal = EXCLUDED.daily_kcal,
+			daily_nursing_minutes = EXCLUDED.daily_nursing_minutes,
+			set_by = EXCLUDED.set_by,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	var setBy sql.NullString
+	if goal.SetBy != "" {
+		setBy = sql.NullString{String: goal.SetBy, Valid: true}
 	}
-	if side.Valid {
-		f.Side = side.String
+
+	_, err := r.db.ExecContext(ctx, query,
+		goal.ChildID, goal.DailyMl, goal.DailyKcal, goal.DailyNursingMinutes, setBy, goal.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) DeleteGoal(ctx context.Context, childID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM feeding_goals WHERE child_id = $1`, childID)
+	return err
+}
+
+func (r *repository) GetNursingTimer(ctx context.Context, childID string) (*NursingTimer, error) {
+	query := `
+		SELECT feeding_id, child_id, side, side_started_at, left_seconds, right_seconds, COALESCE(started_by, ''), updated_at
+		FROM nursing_timers
+		WHERE child_id = $1 AND ended_at IS NULL
+	`
+
+	var t NursingTimer
+	var sideStartedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, childID).Scan(
+		&t.FeedingID, &t.ChildID, &t.Side, &sideStartedAt, &t.LeftSeconds, &t.RightSeconds, &t.StartedBy, &t.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
 	}
-	if notes.Valid {
-		f.Notes = notes.String
+	if err != nil {
+		return nil, err
 	}
-	if syncedAt.Valid {
-		f.SyncedAt = &syncedAt.Time
+
+	if sideStartedAt.Valid {
+		t.SideStartedAt = &sideStartedAt.Time
+	}
+	return &t, nil
+}
+
+func (r *repository) CreateNursingTimer(ctx context.Context, t *NursingTimer) error {
+	query := `
+		INSERT INTO nursing_timers (feeding_id, child_id, side, side_started_at, left_seconds, right_seconds, started_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	var startedBy *string
+	if t.StartedBy != "" {
+		startedBy = &t.StartedBy
 	}
 
-	return &f, nil
+	_, err := r.db.ExecContext(ctx, query,
+		t.FeedingID, t.ChildID, t.Side, t.SideStartedAt, t.LeftSeconds, t.RightSeconds, startedBy, t.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) UpdateNursingTimer(ctx context.Context, t *NursingTimer) error {
+	query := `
+		UPDATE nursing_timers
+		SET side = $2, side_started_at = $3, left_seconds = $4, right_seconds = $5, updated_at = $6, ended_at = $7
+		WHERE feeding_id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		t.FeedingID, t.Side, t.SideStartedAt, t.LeftSeconds, t.RightSeconds, t.UpdatedAt, t.EndedAt,
+	)
+	return err
 }
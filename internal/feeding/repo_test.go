@@ -29,10 +29,10 @@ func TestRepository_GetByID(t *testing.T) {
 	now := time.Now()
 	endTime := now.Add(30 * time.Minute)
 	amount := 120.0
-	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at"}).
-		AddRow("feeding-123", "child-456", "breast", now, endTime, amount, "ml", "left", "Good feeding", now, now, now)
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at", "author_id", "private"}).
+		AddRow("feeding-123", "child-456", "breast", now, endTime, amount, "ml", "left", "Good feeding", now, now, now, nil, false)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings_all WHERE id = \\$1").
 		WithArgs("feeding-123").
 		WillReturnRows(rows)
 
@@ -87,7 +87,7 @@ func TestRepository_GetByID_NotFound(t *testing.T) {
 	defer db.Close()
 	repo := NewRepository(db)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings_all WHERE id = \\$1").
 		WithArgs("non-existent").
 		WillReturnError(sql.ErrNoRows)
 
@@ -110,7 +110,7 @@ func TestRepository_GetByID_Error(t *testing.T) {
 	defer db.Close()
 	repo := NewRepository(db)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings_all WHERE id = \\$1").
 		WithArgs("feeding-123").
 		WillReturnError(errors.New("database error"))
 
@@ -130,10 +130,10 @@ func TestRepository_GetByID_NullFields(t *testing.T) {
 	repo := NewRepository(db)
 
 	now := time.Now()
-	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at"}).
-		AddRow("feeding-123", "child-456", "bottle", now, nil, nil, nil, nil, nil, now, now, nil)
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at", "author_id", "private"}).
+		AddRow("feeding-123", "child-456", "bottle", now, nil, nil, nil, nil, nil, now, now, nil, nil, false)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings_all WHERE id = \\$1").
 		WithArgs("feeding-123").
 		WillReturnRows(rows)
 
@@ -181,11 +181,11 @@ func TestRepository_List(t *testing.T) {
 	now := time.Now()
 	endTime := now.Add(30 * time.Minute)
 	amount := 150.0
-	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at"}).
-		AddRow("feeding-1", "child-456", "breast", now, endTime, amount, "ml", "left", "Note 1", now, now, now).
-		AddRow("feeding-2", "child-456", "bottle", now, nil, nil, nil, nil, nil, now, now, nil)
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at", "author_id", "private"}).
+		AddRow("feeding-1", "child-456", "breast", now, endTime, amount, "ml", "left", "Note 1", now, now, now, nil, false).
+		AddRow("feeding-2", "child-456", "bottle", now, nil, nil, nil, nil, nil, now, now, nil, nil, false)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE 1=1 AND child_id = \\$1 ORDER BY start_time DESC LIMIT 100").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings_all WHERE 1=1 AND child_id = \\$1 ORDER BY start_time DESC LIMIT 100").
 		WithArgs("child-456").
 		WillReturnRows(rows)
 
@@ -230,10 +230,10 @@ func TestRepository_List_WithAllFilters(t *testing.T) {
 	startDate := now.Add(-24 * time.Hour)
 	endDate := now
 	feedingType := FeedingTypeBreast
-	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at"}).
-		AddRow("feeding-1", "child-456", "breast", now, nil, nil, nil, "left", nil, now, now, nil)
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at", "author_id", "private"}).
+		AddRow("feeding-1", "child-456", "breast", now, nil, nil, nil, "left", nil, now, now, nil, nil, false)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE 1=1 AND child_id = \\$1 AND start_time >= \\$2 AND start_time <= \\$3 AND type = \\$4 ORDER BY start_time DESC LIMIT 100").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings_all WHERE 1=1 AND child_id = \\$1 AND start_time >= \\$2 AND start_time <= \\$3 AND type = \\$4 ORDER BY start_time DESC LIMIT 100").
 		WithArgs("child-456", startDate, endDate, feedingType).
 		WillReturnRows(rows)
 
@@ -262,9 +262,9 @@ func TestRepository_List_Empty(t *testing.T) {
 	defer db.Close()
 	repo := NewRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at"})
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at", "author_id", "private"})
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE 1=1 AND child_id = \\$1 ORDER BY start_time DESC LIMIT 100").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings_all WHERE 1=1 AND child_id = \\$1 ORDER BY start_time DESC LIMIT 100").
 		WithArgs("child-456").
 		WillReturnRows(rows)
 
@@ -287,12 +287,38 @@ func TestRepository_List_Empty(t *testing.T) {
 	}
 }
 
+func TestRepository_List_Private(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at", "author_id", "private"}).
+		AddRow("feeding-1", "child-456", "bottle", now, nil, nil, nil, nil, nil, now, now, nil, "user-1", true)
+
+	mock.ExpectQuery("SELECT .* FROM feedings_all WHERE 1=1 AND child_id = \\$1 AND \\(private = FALSE OR author_id = \\$2\\) ORDER BY start_time DESC LIMIT 100").
+		WithArgs("child-456", "user-1").
+		WillReturnRows(rows)
+
+	feedings, err := repo.List(context.Background(), &FeedingFilter{ChildID: "child-456", ViewerID: "user-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(feedings) != 1 || feedings[0].AuthorID != "user-1" || !feedings[0].Private {
+		t.Errorf("List() = %+v, want the author's private feeding", feedings)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestRepository_List_Error(t *testing.T) {
 	db, mock := newMockDB(t)
 	defer db.Close()
 	repo := NewRepository(db)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE 1=1 AND child_id = \\$1 ORDER BY start_time DESC LIMIT 100").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings_all WHERE 1=1 AND child_id = \\$1 ORDER BY start_time DESC LIMIT 100").
 		WithArgs("child-456").
 		WillReturnError(errors.New("database error"))
 
@@ -312,10 +338,10 @@ func TestRepository_List_ScanError(t *testing.T) {
 	defer db.Close()
 	repo := NewRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at"}).
-		AddRow("feeding-1", "child-456", "breast", "invalid-time", nil, nil, nil, nil, nil, nil, nil, nil)
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at", "author_id", "private"}).
+		AddRow("feeding-1", "child-456", "breast", "invalid-time", nil, nil, nil, nil, nil, nil, nil, nil, nil, false)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE 1=1 AND child_id = \\$1 ORDER BY start_time DESC LIMIT 100").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings_all WHERE 1=1 AND child_id = \\$1 ORDER BY start_time DESC LIMIT 100").
 		WithArgs("child-456").
 		WillReturnRows(rows)
 
@@ -355,7 +381,7 @@ func TestRepository_Create(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO feedings").
-		WithArgs(feeding.ID, feeding.ChildID, feeding.Type, feeding.StartTime, feeding.EndTime, feeding.Amount, &feeding.Unit, &feeding.Side, &feeding.Notes, feeding.CreatedAt, feeding.UpdatedAt).
+		WithArgs(feeding.ID, feeding.ChildID, feeding.Type, feeding.StartTime, feeding.EndTime, feeding.Amount, &feeding.Unit, &feeding.Side, &feeding.Notes, feeding.CreatedAt, feeding.UpdatedAt, nil, false).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), feeding)
@@ -389,7 +415,7 @@ func TestRepository_Create_MinimalFields(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO feedings").
-		WithArgs(feeding.ID, feeding.ChildID, feeding.Type, feeding.StartTime, nil, nil, nil, nil, nil, feeding.CreatedAt, feeding.UpdatedAt).
+		WithArgs(feeding.ID, feeding.ChildID, feeding.Type, feeding.StartTime, nil, nil, nil, nil, nil, feeding.CreatedAt, feeding.UpdatedAt, nil, false).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), feeding)
@@ -418,7 +444,7 @@ func TestRepository_Create_Error(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO feedings").
-		WithArgs(feeding.ID, feeding.ChildID, feeding.Type, feeding.StartTime, nil, nil, nil, nil, nil, feeding.CreatedAt, feeding.UpdatedAt).
+		WithArgs(feeding.ID, feeding.ChildID, feeding.Type, feeding.StartTime, nil, nil, nil, nil, nil, feeding.CreatedAt, feeding.UpdatedAt, nil, false).
 		WillReturnError(errors.New("duplicate key"))
 
 	err := repo.Create(context.Background(), feeding)
@@ -453,8 +479,10 @@ func TestRepository_Update(t *testing.T) {
 		UpdatedAt: now,
 	}
 
-	mock.ExpectExec("UPDATE feedings SET type = \\$2, start_time = \\$3, end_time = \\$4, amount = \\$5, unit = \\$6, side = \\$7, notes = \\$8, updated_at = \\$9 WHERE id = \\$1").
-		WithArgs(feeding.ID, feeding.Type, feeding.StartTime, feeding.EndTime, feeding.Amount, &feeding.Unit, &feeding.Side, &feeding.Notes, feeding.UpdatedAt).
+	mock.ExpectExec("INSERT INTO feedings SELECT \\* FROM restored").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE feedings SET type = \\$2, start_time = \\$3, end_time = \\$4, amount = \\$5, unit = \\$6, side = \\$7, notes = \\$8, updated_at = \\$9, private = \\$10 WHERE id = \\$1").
+		WithArgs(feeding.ID, feeding.Type, feeding.StartTime, feeding.EndTime, feeding.Amount, &feeding.Unit, &feeding.Side, &feeding.Notes, feeding.UpdatedAt, false).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), feeding)
@@ -485,8 +513,10 @@ func TestRepository_Update_NullOptionalFields(t *testing.T) {
 		UpdatedAt: now,
 	}
 
-	mock.ExpectExec("UPDATE feedings SET type = \\$2, start_time = \\$3, end_time = \\$4, amount = \\$5, unit = \\$6, side = \\$7, notes = \\$8, updated_at = \\$9 WHERE id = \\$1").
-		WithArgs(feeding.ID, feeding.Type, feeding.StartTime, nil, nil, nil, nil, nil, feeding.UpdatedAt).
+	mock.ExpectExec("INSERT INTO feedings SELECT \\* FROM restored").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE feedings SET type = \\$2, start_time = \\$3, end_time = \\$4, amount = \\$5, unit = \\$6, side = \\$7, notes = \\$8, updated_at = \\$9, private = \\$10 WHERE id = \\$1").
+		WithArgs(feeding.ID, feeding.Type, feeding.StartTime, nil, nil, nil, nil, nil, feeding.UpdatedAt, false).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), feeding)
@@ -512,8 +542,10 @@ func TestRepository_Update_Error(t *testing.T) {
 		UpdatedAt: now,
 	}
 
-	mock.ExpectExec("UPDATE feedings SET type = \\$2, start_time = \\$3, end_time = \\$4, amount = \\$5, unit = \\$6, side = \\$7, notes = \\$8, updated_at = \\$9 WHERE id = \\$1").
-		WithArgs(feeding.ID, feeding.Type, feeding.StartTime, nil, nil, nil, nil, nil, feeding.UpdatedAt).
+	mock.ExpectExec("INSERT INTO feedings SELECT \\* FROM restored").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE feedings SET type = \\$2, start_time = \\$3, end_time = \\$4, amount = \\$5, unit = \\$6, side = \\$7, notes = \\$8, updated_at = \\$9, private = \\$10 WHERE id = \\$1").
+		WithArgs(feeding.ID, feeding.Type, feeding.StartTime, nil, nil, nil, nil, nil, feeding.UpdatedAt, false).
 		WillReturnError(errors.New("database error"))
 
 	err := repo.Update(context.Background(), feeding)
@@ -533,6 +565,8 @@ func TestRepository_Delete(t *testing.T) {
 	defer db.Close()
 	repo := NewRepository(db)
 
+	mock.ExpectExec("INSERT INTO feedings SELECT \\* FROM restored").
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("DELETE FROM feedings WHERE id = \\$1").
 		WithArgs("delete-feeding-123").
 		WillReturnResult(sqlmock.NewResult(0, 1))
@@ -552,6 +586,8 @@ func TestRepository_Delete_Error(t *testing.T) {
 	defer db.Close()
 	repo := NewRepository(db)
 
+	mock.ExpectExec("INSERT INTO feedings SELECT \\* FROM restored").
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("DELETE FROM feedings WHERE id = \\$1").
 		WithArgs("error-delete-feeding").
 		WillReturnError(errors.New("database error"))
@@ -576,14 +612,14 @@ func TestRepository_GetLastFeeding(t *testing.T) {
 	now := time.Now()
 	endTime := now.Add(30 * time.Minute)
 	amount := 100.0
-	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at"}).
-		AddRow("last-feeding-123", "child-456", "breast", now, endTime, amount, "ml", "right", "Last feeding notes", now, now, now)
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at", "author_id", "private"}).
+		AddRow("last-feeding-123", "child-456", "breast", now, endTime, amount, "ml", "right", "Last feeding notes", now, now, now, "user-1", false)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE child_id = \\$1 ORDER BY start_time DESC LIMIT 1").
-		WithArgs("child-456").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings WHERE child_id = \\$1 AND \\(\\$2 = '' OR private = FALSE OR author_id = \\$2\\) ORDER BY start_time DESC LIMIT 1").
+		WithArgs("child-456", "").
 		WillReturnRows(rows)
 
-	feeding, err := repo.GetLastFeeding(context.Background(), "child-456")
+	feeding, err := repo.GetLastFeeding(context.Background(), "child-456", "")
 	if err != nil {
 		t.Fatalf("GetLastFeeding() error = %v", err)
 	}
@@ -614,11 +650,11 @@ func TestRepository_GetLastFeeding_NotFound(t *testing.T) {
 	defer db.Close()
 	repo := NewRepository(db)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE child_id = \\$1 ORDER BY start_time DESC LIMIT 1").
-		WithArgs("child-no-feedings").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings WHERE child_id = \\$1 AND \\(\\$2 = '' OR private = FALSE OR author_id = \\$2\\) ORDER BY start_time DESC LIMIT 1").
+		WithArgs("child-no-feedings", "").
 		WillReturnError(sql.ErrNoRows)
 
-	feeding, err := repo.GetLastFeeding(context.Background(), "child-no-feedings")
+	feeding, err := repo.GetLastFeeding(context.Background(), "child-no-feedings", "")
 	if err != nil {
 		t.Fatalf("GetLastFeeding() error = %v", err)
 	}
@@ -637,11 +673,11 @@ func TestRepository_GetLastFeeding_Error(t *testing.T) {
 	defer db.Close()
 	repo := NewRepository(db)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE child_id = \\$1 ORDER BY start_time DESC LIMIT 1").
-		WithArgs("child-456").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings WHERE child_id = \\$1 AND \\(\\$2 = '' OR private = FALSE OR author_id = \\$2\\) ORDER BY start_time DESC LIMIT 1").
+		WithArgs("child-456", "").
 		WillReturnError(errors.New("database error"))
 
-	_, err := repo.GetLastFeeding(context.Background(), "child-456")
+	_, err := repo.GetLastFeeding(context.Background(), "child-456", "")
 	if err == nil {
 		t.Error("GetLastFeeding() should return error on database failure")
 	}
@@ -657,14 +693,14 @@ func TestRepository_GetLastFeeding_NullFields(t *testing.T) {
 	repo := NewRepository(db)
 
 	now := time.Now()
-	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at"}).
-		AddRow("last-feeding-789", "child-456", "formula", now, nil, nil, nil, nil, nil, now, now, nil)
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at", "author_id", "private"}).
+		AddRow("last-feeding-789", "child-456", "formula", now, nil, nil, nil, nil, nil, now, now, nil, nil, false)
 
-	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at FROM feedings WHERE child_id = \\$1 ORDER BY start_time DESC LIMIT 1").
-		WithArgs("child-456").
+	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, amount, unit, side, notes, created_at, updated_at, synced_at, author_id, private FROM feedings WHERE child_id = \\$1 AND \\(\\$2 = '' OR private = FALSE OR author_id = \\$2\\) ORDER BY start_time DESC LIMIT 1").
+		WithArgs("child-456", "").
 		WillReturnRows(rows)
 
-	feeding, err := repo.GetLastFeeding(context.Background(), "child-456")
+	feeding, err := repo.GetLastFeeding(context.Background(), "child-456", "")
 	if err != nil {
 		t.Fatalf("GetLastFeeding() error = %v", err)
 	}
@@ -701,3 +737,177 @@ func TestRepository_GetLastFeeding_NullFields(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func TestRepository_GetLastFeeding_Viewer(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	// Another member's private feeding is newer, so the query must skip it
+	mock.ExpectQuery("FROM feedings WHERE child_id = \\$1 AND \\(\\$2 = '' OR private = FALSE OR author_id = \\$2\\)").
+		WithArgs("child-456", "user-1").
+		WillReturnError(sql.ErrNoRows)
+
+	feeding, err := repo.GetLastFeeding(context.Background(), "child-456", "user-1")
+	if err != nil || feeding != nil {
+		t.Errorf("GetLastFeeding() = %+v, %v; want nothing", feeding, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// Goal tests
+
+func TestRepository_GetGoal(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"child_id", "daily_ml", "daily_kcal", "daily_nursing_minutes", "set_by", "updated_at"}).
+		AddRow("child-456", 700.0, nil, 90.0, nil, now)
+
+	mock.ExpectQuery("SELECT child_id, daily_ml, daily_kcal, daily_nursing_minutes, set_by, updated_at FROM feeding_goals WHERE child_id = \\$1").
+		WithArgs("child-456").
+		WillReturnRows(rows)
+
+	goal, err := repo.GetGoal(context.Background(), "child-456")
+	if err != nil {
+		t.Fatalf("GetGoal() error = %v", err)
+	}
+	if goal.DailyMl == nil || *goal.DailyMl != 700 {
+		t.Errorf("GetGoal() DailyMl = %v, want 700", goal.DailyMl)
+	}
+	if goal.DailyKcal != nil {
+		t.Error("GetGoal() DailyKcal should be nil for NULL")
+	}
+	if goal.DailyNursingMinutes == nil || *goal.DailyNursingMinutes != 90 {
+		t.Errorf("GetGoal() DailyNursingMinutes = %v, want 90", goal.DailyNursingMinutes)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetGoal_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM feeding_goals WHERE child_id = \\$1").
+		WithArgs("child-456").
+		WillReturnError(sql.ErrNoRows)
+
+	goal, err := repo.GetGoal(context.Background(), "child-456")
+	if err != nil {
+		t.Fatalf("GetGoal() error = %v", err)
+	}
+	if goal != nil {
+		t.Errorf("GetGoal() = %+v, want nil", goal)
+	}
+}
+
+func TestRepository_UpsertGoal(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	ml := 700.0
+	goal := &Goal{ChildID: "child-456", DailyMl: &ml, SetBy: "user-1", UpdatedAt: time.Now()}
+
+	mock.ExpectExec("INSERT INTO feeding_goals (.+) ON CONFLICT \\(child_id\\) DO UPDATE").
+		WithArgs(goal.ChildID, goal.DailyMl, goal.DailyKcal, goal.DailyNursingMinutes, sqlmock.AnyArg(), goal.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpsertGoal(context.Background(), goal); err != nil {
+		t.Fatalf("UpsertGoal() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetNursingTimer(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT feeding_id, child_id, side, side_started_at, .* FROM nursing_timers WHERE child_id = \\$1 AND ended_at IS NULL").
+		WithArgs("child-123").
+		WillReturnRows(sqlmock.NewRows([]string{"feeding_id", "child_id", "side", "side_started_at", "left_seconds", "right_seconds", "started_by", "updated_at"}).
+			AddRow("feeding-1", "child-123", "left", nil, 600, 0, "user-1", now))
+
+	timer, err := repo.GetNursingTimer(context.Background(), "child-123")
+	if err != nil {
+		t.Fatalf("GetNursingTimer() error = %v", err)
+	}
+	if timer.FeedingID != "feeding-1" || timer.Side != SideLeft || timer.SideStartedAt != nil || timer.LeftSeconds != 600 {
+		t.Errorf("GetNursingTimer() = %+v", timer)
+	}
+}
+
+func TestRepository_GetNursingTimer_None(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT .* FROM nursing_timers").
+		WithArgs("child-123").
+		WillReturnError(sql.ErrNoRows)
+
+	timer, err := repo.GetNursingTimer(context.Background(), "child-123")
+	if err != nil || timer != nil {
+		t.Errorf("GetNursingTimer() = %v, %v; want nil, nil", timer, err)
+	}
+}
+
+func TestRepository_UpdateNursingTimer(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	timer := &NursingTimer{FeedingID: "feeding-1", Side: SideRight, RightSeconds: 300, UpdatedAt: now, EndedAt: &now}
+	mock.ExpectExec("UPDATE nursing_timers SET side = \\$2, .* WHERE feeding_id = \\$1").
+		WithArgs("feeding-1", SideRight, nil, 0, 300, now, &now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdateNursingTimer(context.Background(), timer); err != nil {
+		t.Fatalf("UpdateNursingTimer() error = %v", err)
+	}
+}
+
+func TestRepository_Stream(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	from := now.AddDate(-2, 0, 0)
+	rows := sqlmock.NewRows([]string{"id", "child_id", "type", "start_time", "end_time", "amount", "unit", "side", "notes", "created_at", "updated_at", "synced_at", "author_id", "private"}).
+		AddRow("feeding-1", "child-456", "bottle", from, nil, 120.0, "ml", nil, nil, now, now, nil, nil, false).
+		AddRow("feeding-2", "child-456", "breast", now, nil, nil, nil, "left", nil, now, now, nil, nil, false)
+
+	mock.ExpectQuery("SELECT .* FROM feedings_all WHERE 1=1 AND child_id = \\$1 AND start_time >= \\$2 ORDER BY start_time ASC, id ASC$").
+		WithArgs("child-456", from).
+		WillReturnRows(rows)
+
+	var ids []string
+	for f, err := range repo.Stream(context.Background(), &FeedingFilter{ChildID: "child-456", StartDate: &from}) {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		ids = append(ids, f.ID)
+	}
+	if len(ids) != 2 || ids[0] != "feeding-1" || ids[1] != "feeding-2" {
+		t.Errorf("Stream() = %v", ids)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
@@ -2,34 +2,99 @@ package feeding
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"iter"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/measure"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
+// ErrInvalidAmount rejects amounts that are not positive, and milk feeds
+// measured in anything other than a volume
+var ErrInvalidAmount = errors.New("amount must be positive, in ml or oz for milk feeds")
+
 type Service interface {
 	Create(ctx context.Context, req *CreateFeedingRequest) (*Feeding, error)
-	Get(ctx context.Context, id string) (*Feeding, error)
+	Get(ctx context.Context, userID, id string) (*Feeding, error)
 	List(ctx context.Context, filter *FeedingFilter) ([]Feeding, error)
-	Update(ctx context.Context, id string, req *CreateFeedingRequest) (*Feeding, error)
-	Delete(ctx context.Context, id string) error
-	GetLastFeeding(ctx context.Context, childID string) (*Feeding, error)
+	// Stream yields every matching feeding, oldest first, for exports too
+	// large to list
+	Stream(ctx context.Context, filter *FeedingFilter) iter.Seq2[Feeding, error]
+	Update(ctx context.Context, userID, id string, req *CreateFeedingRequest) (*Feeding, error)
+	Delete(ctx context.Context, userID, id string) error
+	SetPrivate(ctx context.Context, userID, id string, private bool) error
+	// GetLastFeeding leaves out other members' private feedings when viewerID is set
+	GetLastFeeding(ctx context.Context, childID, viewerID string) (*Feeding, error)
+	GetGoal(ctx context.Context, childID string) (*Goal, error)
+	ListGoals(ctx context.Context) ([]Goal, error)
+	SetGoal(ctx context.Context, userID, childID string, req *SetGoalRequest) (*Goal, error)
+	DeleteGoal(ctx context.Context, childID string) error
+	GetStats(ctx context.Context, childID string, days int) (*Stats, error)
+	GetFormulaGuide(ctx context.Context, childID string, req *FormulaGuideRequest) (*FormulaGuide, error)
+	StartNursing(ctx context.Context, userID string, req *StartNursingRequest) (*NursingTimer, error)
+	GetNursing(ctx context.Context, userID, childID string) (*NursingTimer, error)
+	PauseNursing(ctx context.Context, userID, childID string) (*NursingTimer, error)
+	ResumeNursing(ctx context.Context, userID, childID string, side Side) (*NursingTimer, error)
+	SwitchNursingSide(ctx context.Context, userID, childID string, side Side) (*NursingTimer, error)
+	StopNursing(ctx context.Context, userID, childID string) (*NursingTimer, error)
 }
 
 type service struct {
-	repo Repository
+	repo       Repository
+	history    audit.Store
+	tx         db.TxManager
+	tombstones delta.Tombstones
+	visibility visibility.Service
+
+	familyService    family.Service
+	growthService    growth.Service
+	formulaGuideline string
+	notifier         Notifier
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{repo: repo, tx: db.NoTx, formulaGuideline: DefaultFormulaGuideline}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether feedings can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
 }
 
 func (s *service) Create(ctx context.Context, req *CreateFeedingRequest) (*Feeding, error) {
+	// Internal callers log without an author and check access themselves
+	if req.AuthorID != "" {
+		if err := s.authorize(ctx, req.AuthorID, req.ChildID); err != nil {
+			return nil, err
+		}
+	}
 	now := time.Now()
 
+	occurrence.Adjust(ctx, "start_time", &req.StartTime, now)
+	occurrence.Adjust(ctx, "end_time", req.EndTime, now)
+	if err := occurrence.ValidateRange(req.StartTime, req.EndTime, now); err != nil {
+		return nil, err
+	}
+	if err := validateAmount(req); err != nil {
+		return nil, err
+	}
+
 	feeding := &Feeding{
-		ID:        generateID(),
+		ID:        ids.New(),
 		ChildID:   req.ChildID,
 		Type:      req.Type,
 		StartTime: req.StartTime,
@@ -40,30 +105,93 @@ func (s *service) Create(ctx context.Context, req *CreateFeedingRequest) (*Feedi
 		Notes:     req.Notes,
 		CreatedAt: now,
 		UpdatedAt: now,
+		AuthorID:  req.AuthorID,
+		Private:   req.Private && req.AuthorID != "",
 	}
 
 	if err := s.repo.Create(ctx, feeding); err != nil {
 		return nil, fmt.Errorf("failed to create feeding: %w", err)
 	}
+	s.recordVersion(ctx, feeding, audit.ActionCreate)
 
 	return feeding, nil
 }
 
-func (s *service) Get(ctx context.Context, id string) (*Feeding, error) {
-	return s.repo.GetByID(ctx, id)
+// validateAmount checks the amount against its unit and stores the unit in its
+// canonical spelling
+func validateAmount(req *CreateFeedingRequest) error {
+	if req.Amount == nil {
+		return nil
+	}
+	q := amountOf(*req.Amount, req.Unit)
+	if err := q.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+	if dim, _ := q.Unit.Dimension(); req.Type != FeedingTypeSolid && dim != measure.DimensionVolume {
+		return fmt.Errorf("%w: %q is not a volume", ErrInvalidAmount, req.Unit)
+	}
+	if req.Unit != "" {
+		req.Unit = string(q.Unit)
+	}
+	return nil
+}
+
+func (s *service) Get(ctx context.Context, userID, id string) (*Feeding, error) {
+	feeding, err := s.repo.GetByID(ctx, id)
+	if err != nil || feeding == nil {
+		return feeding, err
+	}
+	if err := s.authorize(ctx, userID, feeding.ChildID); err != nil {
+		return nil, err
+	}
+	if hiddenFrom(feeding, userID) {
+		return nil, nil
+	}
+	return feeding, nil
 }
 
 func (s *service) List(ctx context.Context, filter *FeedingFilter) ([]Feeding, error) {
-	return s.repo.List(ctx, filter)
+	viewer, err := s.viewer(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	feedings, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return visibility.Filter(ctx, viewer, feedings, childOf)
+}
+
+func (s *service) Stream(ctx context.Context, filter *FeedingFilter) iter.Seq2[Feeding, error] {
+	viewer, err := s.viewer(ctx, filter)
+	if err != nil {
+		return func(yield func(Feeding, error) bool) {
+			yield(Feeding{}, err)
+		}
+	}
+	return visibility.FilterSeq(ctx, viewer, s.repo.Stream(ctx, filter), childOf)
 }
 
-func (s *service) Update(ctx context.Context, id string, req *CreateFeedingRequest) (*Feeding, error) {
+func (s *service) Update(ctx context.Context, userID, id string, req *CreateFeedingRequest) (*Feeding, error) {
 	feeding, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if feeding == nil {
-		return nil, fmt.Errorf("feeding not found")
+	if feeding == nil || hiddenFrom(feeding, userID) {
+		return nil, ErrFeedingNotFound
+	}
+	if err := s.authorize(ctx, userID, feeding.ChildID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	occurrence.Adjust(ctx, "start_time", &req.StartTime, now)
+	occurrence.Adjust(ctx, "end_time", req.EndTime, now)
+	if err := occurrence.ValidateRange(req.StartTime, req.EndTime, now); err != nil {
+		return nil, err
+	}
+	if err := validateAmount(req); err != nil {
+		return nil, err
 	}
 
 	feeding.Type = req.Type
@@ -78,20 +206,32 @@ func (s *service) Update(ctx context.Context, id string, req *CreateFeedingReque
 	if err := s.repo.Update(ctx, feeding); err != nil {
 		return nil, fmt.Errorf("failed to update feeding: %w", err)
 	}
+	s.recordVersion(ctx, feeding, audit.ActionUpdate)
 
 	return feeding, nil
 }
 
-func (s *service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
-}
-
-func (s *service) GetLastFeeding(ctx context.Context, childID string) (*Feeding, error) {
-	return s.repo.GetLastFeeding(ctx, childID)
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	feeding, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if feeding == nil {
+		return s.repo.Delete(ctx, id)
+	}
+	if hiddenFrom(feeding, userID) {
+		return ErrFeedingNotFound
+	}
+	if err := s.authorize(ctx, userID, feeding.ChildID); err != nil {
+		return err
+	}
+	if err := s.deleteRecord(ctx, feeding); err != nil {
+		return err
+	}
+	s.recordVersion(ctx, feeding, audit.ActionDelete)
+	return nil
 }
 
-func generateID() string {
-	b := make([]byte, 16)
-	rand.Read(b) //nolint:errcheck // crypto/rand.Read rarely fails
-	return hex.EncodeToString(b)
+func (s *service) GetLastFeeding(ctx context.Context, childID, viewerID string) (*Feeding, error) {
+	return s.repo.GetLastFeeding(ctx, childID, viewerID)
 }
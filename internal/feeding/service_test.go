@@ -3,13 +3,27 @@ package feeding
 import (
 	"context"
 	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"sort"
 	"testing"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/notifications"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
 // mockRepository is a test double for Repository
 type mockRepository struct {
 	feedings  map[string]*Feeding
+	goals     map[string]*Goal
+	timers    map[string]*NursingTimer
 	createErr error
 	updateErr error
 	deleteErr error
@@ -18,6 +32,8 @@ type mockRepository struct {
 func newMockRepository() *mockRepository {
 	return &mockRepository{
 		feedings: make(map[string]*Feeding),
+		goals:    make(map[string]*Goal),
+		timers:   make(map[string]*NursingTimer),
 	}
 }
 
@@ -44,11 +60,26 @@ func (m *mockRepository) List(ctx context.Context, filter *FeedingFilter) ([]Fee
 		if filter.EndDate != nil && f.StartTime.After(*filter.EndDate) {
 			continue
 		}
+		if filter.ViewerID != "" && !f.VisibleTo(filter.ViewerID) {
+			continue
+		}
 		result = append(result, *f)
 	}
 	return result, nil
 }
 
+func (m *mockRepository) Stream(ctx context.Context, filter *FeedingFilter) iter.Seq2[Feeding, error] {
+	feedings, _ := m.List(ctx, filter)
+	sort.Slice(feedings, func(i, j int) bool { return feedings[i].StartTime.Before(feedings[j].StartTime) })
+	return func(yield func(Feeding, error) bool) {
+		for _, f := range feedings {
+			if !yield(f, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (m *mockRepository) Create(ctx context.Context, feeding *Feeding) error {
 	if m.createErr != nil {
 		return m.createErr
@@ -73,7 +104,7 @@ func (m *mockRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *mockRepository) GetLastFeeding(ctx context.Context, childID string) (*Feeding, error) {
+func (m *mockRepository) GetLastFeeding(ctx context.Context, childID, viewerID string) (*Feeding, error) {
 	var latest *Feeding
 	for _, f := range m.feedings {
 		if f.ChildID == childID {
@@ -85,11 +116,55 @@ func (m *mockRepository) GetLastFeeding(ctx context.Context, childID string) (*F
 	return latest, nil
 }
 
+func (m *mockRepository) GetGoal(ctx context.Context, childID string) (*Goal, error) {
+	return m.goals[childID], nil
+}
+
+func (m *mockRepository) ListGoals(ctx context.Context) ([]Goal, error) {
+	var goals []Goal
+	for _, g := range m.goals {
+		goals = append(goals, *g)
+	}
+	return goals, nil
+}
+
+func (m *mockRepository) UpsertGoal(ctx context.Context, goal *Goal) error {
+	m.goals[goal.ChildID] = goal
+	return nil
+}
+
+func (m *mockRepository) DeleteGoal(ctx context.Context, childID string) error {
+	delete(m.goals, childID)
+	return nil
+}
+
+func (m *mockRepository) GetNursingTimer(ctx context.Context, childID string) (*NursingTimer, error) {
+	for _, t := range m.timers {
+		if t.ChildID == childID && t.EndedAt == nil {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) CreateNursingTimer(ctx context.Context, t *NursingTimer) error {
+	stored := *t
+	m.timers[t.FeedingID] = &stored
+	return nil
+}
+
+func (m *mockRepository) UpdateNursingTimer(ctx context.Context, t *NursingTimer) error {
+	stored := *t
+	m.timers[t.FeedingID] = &stored
+	return nil
+}
+
 func TestService_Create(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	startTime := time.Now()
+	startTime := time.Now().Add(-time.Hour)
 	endTime := startTime.Add(30 * time.Minute)
 	amount := 120.0
 
@@ -171,6 +246,95 @@ func TestService_Create_RepoError(t *testing.T) {
 	}
 }
 
+func TestService_Create_InvalidTimes(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name      string
+		startTime time.Time
+		endTime   *time.Time
+	}{
+		{"start in the future", now.Add(time.Hour), nil},
+		{"end before start", now.Add(-time.Hour), &before},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			svc := NewService(repo)
+
+			_, err := svc.Create(context.Background(), &CreateFeedingRequest{
+				ChildID:   "child-123",
+				Type:      FeedingTypeBottle,
+				StartTime: tt.startTime,
+				EndTime:   tt.endTime,
+			})
+			if !errors.Is(err, occurrence.ErrInvalid) {
+				t.Errorf("Create() error = %v, want %v", err, occurrence.ErrInvalid)
+			}
+		})
+	}
+}
+
+func TestService_Create_Amount(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      FeedingType
+		amount   float64
+		unit     string
+		wantUnit string
+		wantErr  error
+	}{
+		{"ml", FeedingTypeBottle, 120, "ml", "ml", nil},
+		{"unit spelling normalized", FeedingTypeBottle, 4, "OZ", "oz", nil},
+		{"no unit", FeedingTypeFormula, 120, "", "", nil},
+		{"solids by weight", FeedingTypeSolid, 40, "g", "g", nil},
+		{"zero", FeedingTypeBottle, 0, "ml", "", ErrInvalidAmount},
+		{"milk by weight", FeedingTypeBottle, 40, "g", "", ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService(newMockRepository())
+
+			f, err := svc.Create(context.Background(), &CreateFeedingRequest{
+				ChildID:   "child-123",
+				Type:      tt.typ,
+				StartTime: time.Now().Add(-time.Hour),
+				Amount:    &tt.amount,
+				Unit:      tt.unit,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && f.Unit != tt.wantUnit {
+				t.Errorf("Create() unit = %q, want %q", f.Unit, tt.wantUnit)
+			}
+		})
+	}
+}
+
+func TestService_Create_ClampsClockSkew(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	// A phone running two minutes fast logs a feed "now"
+	ahead := time.Now().Add(2 * time.Minute)
+	f, err := svc.Create(context.Background(), &CreateFeedingRequest{
+		ChildID:   "child-123",
+		Type:      FeedingTypeBottle,
+		StartTime: ahead,
+		EndTime:   &ahead,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if f.StartTime.After(time.Now()) || f.EndTime.After(time.Now()) {
+		t.Errorf("Create() = %v - %v, want times clamped to now", f.StartTime, *f.EndTime)
+	}
+}
+
 func TestService_Get(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -184,7 +348,7 @@ func TestService_Get(t *testing.T) {
 	created, _ := svc.Create(context.Background(), req)
 
 	// Get it back
-	feeding, err := svc.Get(context.Background(), created.ID)
+	feeding, err := svc.Get(context.Background(), "user-1", created.ID)
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -202,7 +366,7 @@ func TestService_Get_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	feeding, err := svc.Get(context.Background(), "non-existent")
+	feeding, err := svc.Get(context.Background(), "user-1", "non-existent")
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -221,7 +385,7 @@ func TestService_List(t *testing.T) {
 		req := &CreateFeedingRequest{
 			ChildID:   "child-123",
 			Type:      FeedingTypeBottle,
-			StartTime: time.Now().Add(time.Duration(i) * time.Hour),
+			StartTime: time.Now().Add(time.Duration(-i) * time.Hour),
 		}
 		svc.Create(context.Background(), req)
 	}
@@ -299,7 +463,7 @@ func TestService_Update(t *testing.T) {
 
 	// Update it
 	newAmount := 200.0
-	newEndTime := time.Now().Add(45 * time.Minute)
+	newEndTime := time.Now()
 	updateReq := &CreateFeedingRequest{
 		ChildID:   "child-123",
 		Type:      FeedingTypeFormula,
@@ -310,7 +474,7 @@ func TestService_Update(t *testing.T) {
 		Notes:     "Updated notes",
 	}
 
-	updated, err := svc.Update(context.Background(), created.ID, updateReq)
+	updated, err := svc.Update(context.Background(), "user-1", created.ID, updateReq)
 	if err != nil {
 		t.Fatalf("Update() error = %v", err)
 	}
@@ -338,7 +502,7 @@ func TestService_Update_NotFound(t *testing.T) {
 		StartTime: time.Now(),
 	}
 
-	_, err := svc.Update(context.Background(), "non-existent", req)
+	_, err := svc.Update(context.Background(), "user-1", "non-existent", req)
 	if err == nil {
 		t.Error("Update() should return error for non-existent feeding")
 	}
@@ -359,7 +523,7 @@ func TestService_Update_RepoError(t *testing.T) {
 	// Set error and try update
 	repo.updateErr = errors.New("database error")
 
-	_, err := svc.Update(context.Background(), created.ID, req)
+	_, err := svc.Update(context.Background(), "user-1", created.ID, req)
 	if err == nil {
 		t.Error("Update() should return error when repo fails")
 	}
@@ -378,13 +542,13 @@ func TestService_Delete(t *testing.T) {
 	created, _ := svc.Create(context.Background(), req)
 
 	// Delete it
-	err := svc.Delete(context.Background(), created.ID)
+	err := svc.Delete(context.Background(), "user-1", created.ID)
 	if err != nil {
 		t.Fatalf("Delete() error = %v", err)
 	}
 
 	// Verify it's gone
-	feeding, _ := svc.Get(context.Background(), created.ID)
+	feeding, _ := svc.Get(context.Background(), "user-1", created.ID)
 	if feeding != nil {
 		t.Error("Delete() should remove the feeding")
 	}
@@ -395,12 +559,184 @@ func TestService_Delete_RepoError(t *testing.T) {
 	repo.deleteErr = errors.New("database error")
 	svc := NewService(repo)
 
-	err := svc.Delete(context.Background(), "some-id")
+	err := svc.Delete(context.Background(), "user-1", "some-id")
 	if err == nil {
 		t.Error("Delete() should return error when repo fails")
 	}
 }
 
+// mockTombstones is a test double for delta.Tombstones
+type mockTombstones struct {
+	delta.Tombstones
+	deleted map[string]string // entity ID -> scope ID
+}
+
+func (m *mockTombstones) Record(ctx context.Context, entityType delta.EntityType, entityID, scopeID string, at time.Time) error {
+	if entityType != delta.EntityFeeding {
+		return errors.New("unexpected entity type")
+	}
+	m.deleted[entityID] = scopeID
+	return nil
+}
+
+func TestService_Delete_RecordsTombstone(t *testing.T) {
+	repo := newMockRepository()
+	tombstones := &mockTombstones{deleted: make(map[string]string)}
+	svc := NewService(repo, WithTombstones(tombstones, db.NoTx))
+
+	created, _ := svc.Create(context.Background(), &CreateFeedingRequest{
+		ChildID:   "child-123",
+		Type:      FeedingTypeBottle,
+		StartTime: time.Now(),
+	})
+	if err := svc.Delete(context.Background(), "user-1", created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if tombstones.deleted[created.ID] != "child-123" {
+		t.Errorf("tombstones = %v, want %s deleted from child-123", tombstones.deleted, created.ID)
+	}
+}
+
+// mockVisibilityService hides child-hidden's feedings, and puts child-other in
+// another family
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) CheckChild(ctx context.Context, userID, childID string, recordType visibility.RecordType) error {
+	switch childID {
+	case "child-hidden":
+		return visibility.ErrHidden
+	case "child-other":
+		return family.ErrNotMember
+	}
+	return nil
+}
+
+func TestService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewService(repo, WithVisibility(&mockVisibilityService{}))
+
+	ids := map[string]string{}
+	for _, childID := range []string{"child-123", "child-hidden", "child-other"} {
+		f, err := svc.Create(ctx, &CreateFeedingRequest{ChildID: childID, Type: FeedingTypeBottle, StartTime: time.Now()})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids[childID] = f.ID
+	}
+
+	if _, err := svc.Get(ctx, "user-1", ids["child-123"]); err != nil {
+		t.Errorf("Get() visible error = %v", err)
+	}
+	if _, err := svc.Get(ctx, "user-1", ids["child-hidden"]); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Get() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Get(ctx, "user-1", ids["child-other"]); !errors.Is(err, family.ErrNotMember) {
+		t.Errorf("Get() other family error = %v, want ErrNotMember", err)
+	}
+	req := &CreateFeedingRequest{Type: FeedingTypeBottle, StartTime: time.Now()}
+	if _, err := svc.Update(ctx, "user-1", ids["child-hidden"], req); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Update() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Delete(ctx, "user-1", ids["child-hidden"]); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Delete() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Create(ctx, &CreateFeedingRequest{ChildID: "child-hidden", Type: FeedingTypeBottle, StartTime: time.Now(), AuthorID: "user-1"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Create() hidden error = %v, want ErrHidden", err)
+	}
+	if repo.feedings[ids["child-hidden"]] == nil {
+		t.Error("Delete() removed a hidden feeding")
+	}
+
+	list, err := svc.List(ctx, &FeedingFilter{ViewerID: "user-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ChildID != "child-123" {
+		t.Errorf("List() = %+v, want only child-123's feeding", list)
+	}
+	if _, err := svc.List(ctx, &FeedingFilter{ChildID: "child-hidden", ViewerID: "user-1"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("List() hidden child error = %v, want ErrHidden", err)
+	}
+	var streamed int
+	for _, err := range svc.Stream(ctx, &FeedingFilter{ViewerID: "user-1"}) {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		streamed++
+	}
+	if streamed != 1 {
+		t.Errorf("Stream() yielded %d feedings, want 1", streamed)
+	}
+	if all, _ := svc.List(ctx, &FeedingFilter{}); len(all) != 3 {
+		t.Errorf("List() without a viewer = %d feedings, want 3", len(all))
+	}
+}
+
+func TestService_Private(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	private, err := svc.Create(ctx, &CreateFeedingRequest{
+		ChildID: "child-123", Type: FeedingTypeBottle, StartTime: time.Now(), Private: true, AuthorID: "user-1",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	shared, err := svc.Create(ctx, &CreateFeedingRequest{
+		ChildID: "child-123", Type: FeedingTypeBottle, StartTime: time.Now(), AuthorID: "user-1",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !private.Private || shared.Private {
+		t.Fatalf("Create() private = %v, %v; want true, false", private.Private, shared.Private)
+	}
+
+	if f, err := svc.Get(ctx, "user-1", private.ID); err != nil || f == nil {
+		t.Errorf("Get() by author = %v, %v; want the feeding", f, err)
+	}
+	if f, err := svc.Get(ctx, "user-2", private.ID); err != nil || f != nil {
+		t.Errorf("Get() by another member = %v, %v; want not found", f, err)
+	}
+	if list, _ := svc.List(ctx, &FeedingFilter{ChildID: "child-123", ViewerID: "user-2"}); len(list) != 1 || list[0].ID != shared.ID {
+		t.Errorf("List() for another member = %+v, want only the shared feeding", list)
+	}
+	if list, _ := svc.List(ctx, &FeedingFilter{ChildID: "child-123", ViewerID: "user-1"}); len(list) != 2 {
+		t.Errorf("List() for the author = %d feedings, want 2", len(list))
+	}
+
+	req := &CreateFeedingRequest{Type: FeedingTypeBottle, StartTime: time.Now()}
+	if _, err := svc.Update(ctx, "user-2", private.ID, req); !errors.Is(err, ErrFeedingNotFound) {
+		t.Errorf("Update() by another member error = %v, want ErrFeedingNotFound", err)
+	}
+	if err := svc.Delete(ctx, "user-2", private.ID); !errors.Is(err, ErrFeedingNotFound) {
+		t.Errorf("Delete() by another member error = %v, want ErrFeedingNotFound", err)
+	}
+	if err := svc.SetPrivate(ctx, "user-2", private.ID, false); !errors.Is(err, ErrFeedingNotFound) {
+		t.Errorf("SetPrivate() by another member error = %v, want ErrFeedingNotFound", err)
+	}
+	if err := svc.SetPrivate(ctx, "user-2", shared.ID, true); !errors.Is(err, ErrNotAuthor) {
+		t.Errorf("SetPrivate() of a shared feeding by another member error = %v, want ErrNotAuthor", err)
+	}
+
+	if err := svc.SetPrivate(ctx, "user-1", private.ID, false); err != nil {
+		t.Fatalf("SetPrivate() by author error = %v", err)
+	}
+	if f, err := svc.Get(ctx, "user-2", private.ID); err != nil || f == nil {
+		t.Errorf("Get() by another member after sharing = %v, %v; want the feeding", f, err)
+	}
+
+	// Feedings logged without a member, e.g. by a device, cannot be private
+	anon, err := svc.Create(ctx, &CreateFeedingRequest{ChildID: "child-123", Type: FeedingTypeBottle, StartTime: time.Now(), Private: true})
+	if err != nil || anon.Private {
+		t.Errorf("Create() without an author = %+v, %v; want shared", anon, err)
+	}
+}
+
 func TestService_GetLastFeeding(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -411,7 +747,7 @@ func TestService_GetLastFeeding(t *testing.T) {
 		req := &CreateFeedingRequest{
 			ChildID:   "child-123",
 			Type:      FeedingTypeBottle,
-			StartTime: now.Add(time.Duration(-i) * time.Hour), // Earlier times
+			StartTime: now.Add(time.Duration(-i-1) * time.Hour), // Earlier times
 		}
 		svc.Create(context.Background(), req)
 	}
@@ -420,12 +756,12 @@ func TestService_GetLastFeeding(t *testing.T) {
 	latestReq := &CreateFeedingRequest{
 		ChildID:   "child-123",
 		Type:      FeedingTypeBreast,
-		StartTime: now.Add(1 * time.Hour), // Most recent
+		StartTime: now, // Most recent
 	}
 	latest, _ := svc.Create(context.Background(), latestReq)
 
 	// Get last feeding
-	lastFeeding, err := svc.GetLastFeeding(context.Background(), "child-123")
+	lastFeeding, err := svc.GetLastFeeding(context.Background(), "child-123", "")
 	if err != nil {
 		t.Fatalf("GetLastFeeding() error = %v", err)
 	}
@@ -443,7 +779,7 @@ func TestService_GetLastFeeding_NoFeedings(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	lastFeeding, err := svc.GetLastFeeding(context.Background(), "child-no-feedings")
+	lastFeeding, err := svc.GetLastFeeding(context.Background(), "child-no-feedings", "")
 	if err != nil {
 		t.Fatalf("GetLastFeeding() error = %v", err)
 	}
@@ -483,3 +819,395 @@ func TestFeedingTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestService_SetGoal(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	ml := 700.0
+	goal, err := svc.SetGoal(context.Background(), "user-1", "child-123", &SetGoalRequest{DailyMl: &ml})
+	if err != nil {
+		t.Fatalf("SetGoal() error = %v", err)
+	}
+	if goal.SetBy != "user-1" || *goal.DailyMl != 700 {
+		t.Errorf("SetGoal() = %+v", goal)
+	}
+	if repo.goals["child-123"] == nil {
+		t.Error("SetGoal() should store the goal")
+	}
+
+	if _, err := svc.SetGoal(context.Background(), "user-1", "child-123", &SetGoalRequest{}); !errors.Is(err, ErrEmptyGoal) {
+		t.Errorf("SetGoal() with no targets error = %v, want ErrEmptyGoal", err)
+	}
+}
+
+func TestService_GetStats(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	add := func(id string, day int, typ FeedingType, amount float64, unit string, minutes int) {
+		start := today.AddDate(0, 0, -day).Add(9 * time.Hour)
+		f := &Feeding{ID: id, ChildID: "child-123", Type: typ, StartTime: start, Unit: unit}
+		if amount > 0 {
+			f.Amount = &amount
+		}
+		if minutes > 0 {
+			end := start.Add(time.Duration(minutes) * time.Minute)
+			f.EndTime = &end
+		}
+		repo.feedings[id] = f
+	}
+
+	add("f1", 1, FeedingTypeBottle, 300, "ml", 0)
+	add("f2", 1, FeedingTypeFormula, 10, "oz", 0)
+	add("f3", 1, FeedingTypeBreast, 0, "", 25)
+	add("f4", 1, FeedingTypeSolid, 50, "ml", 0)
+	add("f5", 2, FeedingTypeBottle, 200, "ml", 0)
+	add("f6", 3, FeedingTypeBottle, 200, "ml", 0)
+
+	ml := 600.0
+	repo.goals["child-123"] = &Goal{ChildID: "child-123", DailyMl: &ml}
+
+	stats, err := svc.GetStats(context.Background(), "child-123", 5)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if len(stats.Days) != 5 {
+		t.Fatalf("GetStats() returned %d days, want 5", len(stats.Days))
+	}
+
+	yesterday := stats.Days[3]
+	if yesterday.Feedings != 4 {
+		t.Errorf("yesterday feedings = %d, want 4", yesterday.Feedings)
+	}
+	if want := 300 + 10*29.5735; math.Abs(yesterday.Ml-want) > 1e-9 {
+		t.Errorf("yesterday ml = %v, want %v (solids excluded, oz converted)", yesterday.Ml, want)
+	}
+	if yesterday.NursingMinutes != 25 {
+		t.Errorf("yesterday nursing minutes = %v, want 25", yesterday.NursingMinutes)
+	}
+	if yesterday.Progress == nil || yesterday.Progress.BelowTarget {
+		t.Errorf("yesterday should meet the goal, got %+v", yesterday.Progress)
+	}
+	if stats.Days[2].Progress == nil || !stats.Days[2].Progress.BelowTarget {
+		t.Errorf("two days ago should be below target, got %+v", stats.Days[2].Progress)
+	}
+
+	// The streak is broken by yesterday meeting the goal
+	if stats.DaysBelowTarget != 0 {
+		t.Errorf("DaysBelowTarget = %d, want 0", stats.DaysBelowTarget)
+	}
+
+	delete(repo.feedings, "f1")
+	delete(repo.feedings, "f2")
+	stats, err = svc.GetStats(context.Background(), "child-123", 5)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.DaysBelowTarget != 4 {
+		t.Errorf("DaysBelowTarget = %d, want 4", stats.DaysBelowTarget)
+	}
+}
+
+func TestService_GetStats_NoGoal(t *testing.T) {
+	svc := NewService(newMockRepository())
+
+	stats, err := svc.GetStats(context.Background(), "child-123", 0)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if len(stats.Days) != defaultStatsDays {
+		t.Errorf("GetStats() returned %d days, want %d", len(stats.Days), defaultStatsDays)
+	}
+	if stats.Goal != nil || stats.Days[0].Progress != nil {
+		t.Error("GetStats() without a goal should not report progress")
+	}
+}
+
+// mockFamilyService is a test double for the child and member lookups of family.Service
+type mockFamilyService struct {
+	family.Service
+	children map[string]*family.Child
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+func (m *mockFamilyService) GetFamilyMembers(ctx context.Context, familyID string) ([]family.MemberWithUser, error) {
+	return []family.MemberWithUser{{UserID: "user-1"}, {UserID: "user-2"}}, nil
+}
+
+// mockGrowthService is a test double for growth.Service
+type mockGrowthService struct {
+	growth.Service
+	measurements []growth.Measurement
+}
+
+func (m *mockGrowthService) List(ctx context.Context, filter *growth.MeasurementFilter) ([]growth.Measurement, error) {
+	return m.measurements, nil
+}
+
+func newFormulaService(ageMonths int, measurements ...growth.Measurement) Service {
+	families := &mockFamilyService{children: map[string]*family.Child{
+		"child-123": {ID: "child-123", DateOfBirth: time.Now().AddDate(0, -ageMonths, -1)},
+	}}
+	return NewService(newMockRepository(),
+		WithFormulaGuide(families, &mockGrowthService{measurements: measurements}, ""))
+}
+
+func TestService_GetFormulaGuide(t *testing.T) {
+	now := time.Now()
+	older, latest := 4.0, 5.0
+	svc := newFormulaService(3,
+		growth.Measurement{MeasuredAt: now.AddDate(0, 0, -30), WeightKg: &older},
+		growth.Measurement{MeasuredAt: now.AddDate(0, 0, -1)},
+		growth.Measurement{MeasuredAt: now.AddDate(0, 0, -2), WeightKg: &latest},
+	)
+
+	guide, err := svc.GetFormulaGuide(context.Background(), "child-123", &FormulaGuideRequest{})
+	if err != nil {
+		t.Fatalf("GetFormulaGuide() error = %v", err)
+	}
+
+	if guide.WeightKg != 5 {
+		t.Errorf("WeightKg = %v, want the latest recorded weight 5", guide.WeightKg)
+	}
+	if guide.Guideline.Name != DefaultFormulaGuideline || guide.KcalPerOz != StandardKcalPerOz {
+		t.Errorf("Expected the default guideline at standard concentration, got %s at %v", guide.Guideline.Name, guide.KcalPerOz)
+	}
+	if guide.DailyMl.Min != 650 || guide.DailyMl.Max != 825 {
+		t.Errorf("DailyMl = %+v, want 650-825", guide.DailyMl)
+	}
+	if guide.FeedsPerDay.Min != 5 || guide.FeedsPerDay.Max != 6 {
+		t.Errorf("FeedsPerDay = %+v, want 5-6 at 3 months", guide.FeedsPerDay)
+	}
+	if guide.PerFeedMl.Min != 108 || guide.PerFeedMl.Max != 165 {
+		t.Errorf("PerFeedMl = %+v, want 108-165", guide.PerFeedMl)
+	}
+	if guide.Disclaimer == "" {
+		t.Error("Expected the non-medical-advice disclaimer")
+	}
+}
+
+func TestService_GetFormulaGuide_ConcentrationAndCap(t *testing.T) {
+	weight := 7.0
+	svc := newFormulaService(5, growth.Measurement{MeasuredAt: time.Now(), WeightKg: &weight})
+
+	guide, err := svc.GetFormulaGuide(context.Background(), "child-123", &FormulaGuideRequest{KcalPerOz: 24})
+	if err != nil {
+		t.Fatalf("GetFormulaGuide() error = %v", err)
+	}
+	// 7 kg at 130-165 ml/kg is 910-1155 ml of standard formula, 758-963 ml at 24 kcal/oz
+	if guide.DailyMl.Min != 758 || guide.DailyMl.Max != 960 {
+		t.Errorf("DailyMl = %+v, want 758-960 after adjusting and capping", guide.DailyMl)
+	}
+	if len(guide.Notes) != 2 {
+		t.Errorf("Expected notes on concentration and the cap, got %v", guide.Notes)
+	}
+
+	guide, err = svc.GetFormulaGuide(context.Background(), "child-123", &FormulaGuideRequest{Guideline: "nhs"})
+	if err != nil {
+		t.Fatalf("GetFormulaGuide() error = %v", err)
+	}
+	if guide.DailyMl.Min != 1050 || guide.DailyMl.Max != 1400 {
+		t.Errorf("DailyMl = %+v, want 1050-1400 under nhs with no cap", guide.DailyMl)
+	}
+}
+
+func TestService_GetFormulaGuide_Errors(t *testing.T) {
+	weight := 5.0
+	withWeight := growth.Measurement{MeasuredAt: time.Now(), WeightKg: &weight}
+
+	tests := []struct {
+		name string
+		svc  Service
+		req  FormulaGuideRequest
+		want error
+	}{
+		{"not configured", NewService(newMockRepository()), FormulaGuideRequest{}, ErrFormulaUnavailable},
+		{"unknown guideline", newFormulaService(3, withWeight), FormulaGuideRequest{Guideline: "who"}, ErrUnknownGuideline},
+		{"too dilute", newFormulaService(3, withWeight), FormulaGuideRequest{KcalPerOz: 10}, ErrInvalidConcentration},
+		{"no weight", newFormulaService(3), FormulaGuideRequest{}, ErrNoWeight},
+		{"over a year", newFormulaService(13, withWeight), FormulaGuideRequest{}, ErrOutsideGuideline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.svc.GetFormulaGuide(context.Background(), "child-123", &tt.req)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("GetFormulaGuide() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	_, err := newFormulaService(3, withWeight).GetFormulaGuide(context.Background(), "child-999", &FormulaGuideRequest{})
+	if !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("GetFormulaGuide() error = %v, want ErrChildNotFound", err)
+	}
+}
+
+func BenchmarkService_GetStats(b *testing.B) {
+	repo := newMockRepository()
+	dailyMl := 750.0
+	repo.goals["child-1"] = &Goal{ChildID: "child-1", DailyMl: &dailyMl}
+	// A month of bottle feeds every 2.5 hours
+	now := time.Now()
+	for i := range 30 * 10 {
+		amount := 90.0
+		start := now.Add(-time.Duration(i) * 150 * time.Minute)
+		repo.feedings[fmt.Sprintf("feeding-%d", i)] = &Feeding{
+			ID: fmt.Sprintf("feeding-%d", i), ChildID: "child-1", Type: FeedingTypeBottle,
+			StartTime: start, Amount: &amount, Unit: "ml",
+		}
+	}
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := svc.GetStats(ctx, "child-1", 30); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type mockNotifier struct {
+	userIDs []string
+	events  []notifications.Event
+}
+
+func (m *mockNotifier) SendToUsers(userIDs []string, event notifications.Event) {
+	m.userIDs = userIDs
+	m.events = append(m.events, event)
+}
+
+func newNursingService() (Service, *mockRepository, *mockNotifier) {
+	repo := newMockRepository()
+	notifier := &mockNotifier{}
+	families := &mockFamilyService{children: map[string]*family.Child{
+		"child-123": {ID: "child-123", FamilyID: "family-1"},
+	}}
+	return NewService(repo, WithNursingTimer(families, notifier)), repo, notifier
+}
+
+// rewind moves the running stretch of the child's timer into the past
+func rewind(repo *mockRepository, childID string, d time.Duration) {
+	for _, t := range repo.timers {
+		if t.ChildID == childID && t.SideStartedAt != nil {
+			started := t.SideStartedAt.Add(-d)
+			t.SideStartedAt = &started
+		}
+	}
+}
+
+func TestService_Nursing(t *testing.T) {
+	svc, repo, notifier := newNursingService()
+	ctx := context.Background()
+
+	timer, err := svc.StartNursing(ctx, "user-1", &StartNursingRequest{ChildID: "child-123", Side: SideLeft})
+	if err != nil {
+		t.Fatalf("StartNursing() error = %v", err)
+	}
+	feeding := repo.feedings[timer.FeedingID]
+	if feeding == nil || feeding.Type != FeedingTypeBreast || feeding.EndTime != nil {
+		t.Fatalf("StartNursing() should log a running breastfeed, got %+v", feeding)
+	}
+
+	// Ten minutes on the left, paused for a while, then five on the right
+	rewind(repo, "child-123", 10*time.Minute)
+	paused, err := svc.PauseNursing(ctx, "user-2", "child-123")
+	if err != nil {
+		t.Fatalf("PauseNursing() error = %v", err)
+	}
+	if !paused.Paused || paused.LeftSeconds != 600 || paused.SideStartedAt != nil {
+		t.Errorf("PauseNursing() = %+v", paused)
+	}
+
+	switched, err := svc.SwitchNursingSide(ctx, "user-2", "child-123", "")
+	if err != nil {
+		t.Fatalf("SwitchNursingSide() error = %v", err)
+	}
+	if switched.Paused || switched.Side != SideRight || switched.LeftSeconds != 600 {
+		t.Errorf("SwitchNursingSide() = %+v", switched)
+	}
+
+	rewind(repo, "child-123", 5*time.Minute)
+	current, err := svc.GetNursing(ctx, "user-1", "child-123")
+	if err != nil || current.RightSeconds != 300 {
+		t.Errorf("GetNursing() = %+v, %v; want 300 seconds on the right", current, err)
+	}
+
+	stopped, err := svc.StopNursing(ctx, "user-1", "child-123")
+	if err != nil {
+		t.Fatalf("StopNursing() error = %v", err)
+	}
+	if stopped.EndedAt == nil || stopped.RightSeconds != 300 {
+		t.Errorf("StopNursing() = %+v", stopped)
+	}
+	if feeding := repo.feedings[timer.FeedingID]; feeding.EndTime == nil || feeding.Side != "both" {
+		t.Errorf("StopNursing() should end the feed on both sides, got %+v", feeding)
+	}
+
+	if len(notifier.events) != 4 || notifier.events[0].Type != notifications.EventNursingTimer {
+		t.Errorf("expected an event per change, got %d", len(notifier.events))
+	}
+	if len(notifier.userIDs) != 2 {
+		t.Errorf("events should go to every member, including the actor, got %v", notifier.userIDs)
+	}
+	if _, err := svc.PauseNursing(ctx, "user-1", "child-123"); !errors.Is(err, ErrNoTimerRunning) {
+		t.Errorf("PauseNursing() after stop error = %v, want ErrNoTimerRunning", err)
+	}
+}
+
+func TestService_ResumeNursing(t *testing.T) {
+	svc, repo, _ := newNursingService()
+	ctx := context.Background()
+
+	if _, err := svc.StartNursing(ctx, "user-1", &StartNursingRequest{ChildID: "child-123", Side: SideRight}); err != nil {
+		t.Fatalf("StartNursing() error = %v", err)
+	}
+	rewind(repo, "child-123", time.Minute)
+	if _, err := svc.PauseNursing(ctx, "user-1", "child-123"); err != nil {
+		t.Fatalf("PauseNursing() error = %v", err)
+	}
+
+	resumed, err := svc.ResumeNursing(ctx, "user-2", "child-123", "")
+	if err != nil {
+		t.Fatalf("ResumeNursing() error = %v", err)
+	}
+	if resumed.Paused || resumed.Side != SideRight || resumed.RightSeconds != 60 {
+		t.Errorf("ResumeNursing() = %+v", resumed)
+	}
+}
+
+func TestService_Nursing_Errors(t *testing.T) {
+	svc, _, _ := newNursingService()
+	ctx := context.Background()
+
+	if _, err := svc.StartNursing(ctx, "user-1", &StartNursingRequest{ChildID: "child-123", Side: SideLeft}); err != nil {
+		t.Fatalf("StartNursing() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		childID string
+		want    error
+	}{
+		{"already running", "user-1", "child-123", ErrTimerRunning},
+		{"not member", "stranger", "child-123", ErrNotMember},
+		{"child not found", "user-1", "missing", ErrChildNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.StartNursing(ctx, tt.userID, &StartNursingRequest{ChildID: tt.childID, Side: SideLeft})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("StartNursing() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package feeding
+
+import (
+	"context"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+)
+
+// WithTombstones remembers deleted feedings so that sync pulls can report
+// them. tx deletes a feeding and records its tombstone atomically; the
+// repository must be built on the same database.
+func WithTombstones(tombstones delta.Tombstones, tx db.TxManager) Option {
+	return func(s *service) {
+		s.tombstones = tombstones
+		s.tx = tx
+	}
+}
+
+// deleteRecord deletes the feeding and leaves a tombstone for it in the same
+// transaction
+func (s *service) deleteRecord(ctx context.Context, f *Feeding) error {
+	if s.tombstones == nil {
+		return s.repo.Delete(ctx, f.ID)
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, f.ID); err != nil {
+			return err
+		}
+		return s.tombstones.Record(ctx, delta.EntityFeeding, f.ID, f.ChildID, time.Now())
+	})
+}
@@ -0,0 +1,32 @@
+package feeding
+
+import (
+	"context"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// WithVisibility applies family visibility settings, so members cannot see or
+// change feedings hidden from them
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+// authorize checks that userID may see the child's feedings
+func (s *service) authorize(ctx context.Context, userID, childID string) error {
+	if s.visibility == nil {
+		return nil
+	}
+	return s.visibility.CheckChild(ctx, userID, childID, visibility.RecordFeeding)
+}
+
+// viewer filters a list for the filter's viewer, if it has one
+func (s *service) viewer(ctx context.Context, filter *FeedingFilter) (*visibility.Viewer, error) {
+	return visibility.ViewerFor(ctx, s.visibility, filter.ViewerID, filter.ChildID, visibility.RecordFeeding)
+}
+
+func childOf(f Feeding) string {
+	return f.ChildID
+}
@@ -0,0 +1,132 @@
+package grants
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterChildRoutes registers grant management on the children group
+func (h *Handler) RegisterChildRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id/access-grants", h.listForChild)
+	rg.POST("/:id/access-grants", h.grant)
+	rg.DELETE("/:id/access-grants/:grantId", h.revoke)
+}
+
+// RegisterRoutes registers the professional account endpoints
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.getProfessional)
+	rg.PUT("", h.register)
+	rg.GET("/children", h.listGranted)
+	rg.GET("/children/:childId/records", h.childRecords)
+}
+
+func (h *Handler) getProfessional(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	p, err := h.service.GetProfessional(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *Handler) register(c *gin.Context) {
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	p, err := h.service.Register(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *Handler) listGranted(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	grants, err := h.service.ListGranted(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, grants)
+}
+
+func (h *Handler) childRecords(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	bundle, err := h.service.ChildRecords(c.Request.Context(), userID, c.Param("childId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, bundle)
+}
+
+func (h *Handler) listForChild(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	grants, err := h.service.ListForChild(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, grants)
+}
+
+func (h *Handler) grant(c *gin.Context) {
+	var req CreateGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	g, err := h.service.Grant(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, g)
+}
+
+func (h *Handler) revoke(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.service.Revoke(c.Request.Context(), userID, c.Param("id"), c.Param("grantId")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrChildNotFound), errors.Is(err, ErrGrantNotFound), errors.Is(err, ErrProfessionalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, ErrNotAdmin), errors.Is(err, ErrNotProfessional), errors.Is(err, ErrNoAccess):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidProfession), errors.Is(err, ErrInvalidDuration):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
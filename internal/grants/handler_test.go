@@ -0,0 +1,235 @@
+package grants
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/transfer"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	registerFn        func(ctx context.Context, userID string, req *RegisterRequest) (*Professional, error)
+	getProfessionalFn func(ctx context.Context, userID string) (*Professional, error)
+	grantFn           func(ctx context.Context, userID, childID string, req *CreateGrantRequest) (*Grant, error)
+	listForChildFn    func(ctx context.Context, userID, childID string) ([]Grant, error)
+	revokeFn          func(ctx context.Context, userID, childID, grantID string) error
+	listGrantedFn     func(ctx context.Context, userID string) ([]Grant, error)
+	childRecordsFn    func(ctx context.Context, userID, childID string) (*transfer.Bundle, error)
+}
+
+func (m *mockService) Register(ctx context.Context, userID string, req *RegisterRequest) (*Professional, error) {
+	if m.registerFn != nil {
+		return m.registerFn(ctx, userID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) GetProfessional(ctx context.Context, userID string) (*Professional, error) {
+	if m.getProfessionalFn != nil {
+		return m.getProfessionalFn(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Grant(ctx context.Context, userID, childID string, req *CreateGrantRequest) (*Grant, error) {
+	if m.grantFn != nil {
+		return m.grantFn(ctx, userID, childID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ListForChild(ctx context.Context, userID, childID string) ([]Grant, error) {
+	if m.listForChildFn != nil {
+		return m.listForChildFn(ctx, userID, childID)
+	}
+	return []Grant{}, nil
+}
+
+func (m *mockService) Revoke(ctx context.Context, userID, childID, grantID string) error {
+	if m.revokeFn != nil {
+		return m.revokeFn(ctx, userID, childID, grantID)
+	}
+	return nil
+}
+
+func (m *mockService) ListGranted(ctx context.Context, userID string) ([]Grant, error) {
+	if m.listGrantedFn != nil {
+		return m.listGrantedFn(ctx, userID)
+	}
+	return []Grant{}, nil
+}
+
+func (m *mockService) ChildRecords(ctx context.Context, userID, childID string) (*transfer.Bundle, error) {
+	if m.childRecordsFn != nil {
+		return m.childRecordsFn(ctx, userID, childID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) HasAccess(ctx context.Context, userID, childID string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockService) ActiveForChild(ctx context.Context, childID string) ([]Grant, error) {
+	return []Grant{}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterChildRoutes(protected.Group("/children"))
+	handler.RegisterRoutes(protected.Group("/professional"))
+	return router
+}
+
+func TestRegister_Success(t *testing.T) {
+	svc := &mockService{
+		registerFn: func(ctx context.Context, userID string, req *RegisterRequest) (*Professional, error) {
+			return &Professional{UserID: userID, Profession: req.Profession}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("PUT", "/professional", bytes.NewBufferString(`{"profession":"midwife"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var p Professional
+	json.Unmarshal(w.Body.Bytes(), &p)
+	if p.UserID != "test-user-123" || p.Profession != ProfessionMidwife {
+		t.Errorf("Unexpected professional %+v", p)
+	}
+}
+
+func TestGetProfessional_NotRegistered(t *testing.T) {
+	svc := &mockService{
+		getProfessionalFn: func(ctx context.Context, userID string) (*Professional, error) {
+			return nil, ErrNotProfessional
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/professional", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestGrant_Success(t *testing.T) {
+	svc := &mockService{
+		grantFn: func(ctx context.Context, userID, childID string, req *CreateGrantRequest) (*Grant, error) {
+			if userID != "test-user-123" || childID != "child-1" || req.Days != 30 {
+				t.Errorf("Unexpected userID %q childID %q req %+v", userID, childID, req)
+			}
+			return &Grant{ID: "grant-1", ChildID: childID, Status: GrantActive}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/children/child-1/access-grants", bytes.NewBufferString(`{"email":"patel@example.com","days":30}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGrant_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"missing email", `{"days":30}`, nil, http.StatusBadRequest},
+		{"too long", `{"email":"patel@example.com","days":365}`, ErrInvalidDuration, http.StatusBadRequest},
+		{"unknown professional", `{"email":"patel@example.com"}`, ErrProfessionalNotFound, http.StatusNotFound},
+		{"not admin", `{"email":"patel@example.com"}`, ErrNotAdmin, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				grantFn: func(ctx context.Context, userID, childID string, req *CreateGrantRequest) (*Grant, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/children/child-1/access-grants", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestRevoke_Success(t *testing.T) {
+	svc := &mockService{
+		revokeFn: func(ctx context.Context, userID, childID, grantID string) error {
+			if childID != "child-1" || grantID != "grant-1" {
+				t.Errorf("Unexpected childID %q grantID %q", childID, grantID)
+			}
+			return nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("DELETE", "/children/child-1/access-grants/grant-1", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+}
+
+func TestChildRecords_NoAccess(t *testing.T) {
+	svc := &mockService{
+		childRecordsFn: func(ctx context.Context, userID, childID string) (*transfer.Bundle, error) {
+			return nil, ErrNoAccess
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/professional/children/child-1/records", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,93 @@
+package grants
+
+import "time"
+
+// Profession is what a professional account does for the families it serves
+type Profession string
+
+const (
+	ProfessionPediatrician        Profession = "pediatrician"
+	ProfessionLactationConsultant Profession = "lactation_consultant"
+	ProfessionMidwife             Profession = "midwife"
+	ProfessionNurse               Profession = "nurse"
+	ProfessionOther               Profession = "other"
+)
+
+func (p Profession) Valid() bool {
+	switch p {
+	case ProfessionPediatrician, ProfessionLactationConsultant, ProfessionMidwife, ProfessionNurse, ProfessionOther:
+		return true
+	}
+	return false
+}
+
+const (
+	// DefaultGrantDays is how long a grant lasts when no length is given
+	DefaultGrantDays = 14
+	// MaxGrantDays caps a single grant; families renew for longer care
+	MaxGrantDays = 90
+)
+
+// Professional is a user account registered as a pediatrician, lactation
+// consultant or similar. It is separate from family membership: a
+// professional sees only the children they hold a grant for.
+type Professional struct {
+	UserID       string     `json:"user_id"`
+	Name         string     `json:"name"`
+	Email        string     `json:"email"`
+	Profession   Profession `json:"profession"`
+	Organisation string     `json:"organisation,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type RegisterRequest struct {
+	Profession   Profession `json:"profession" binding:"required"`
+	Organisation string     `json:"organisation,omitempty"`
+}
+
+type GrantStatus string
+
+const (
+	GrantActive  GrantStatus = "active"
+	GrantExpired GrantStatus = "expired"
+	GrantRevoked GrantStatus = "revoked"
+)
+
+// Grant gives a professional read access to one child's records until it
+// expires or a family admin revokes it
+type Grant struct {
+	ID                string      `json:"id"`
+	ChildID           string      `json:"child_id"`
+	ChildName         string      `json:"child_name"`
+	ProfessionalID    string      `json:"professional_id"`
+	ProfessionalName  string      `json:"professional_name"`
+	ProfessionalEmail string      `json:"professional_email"`
+	Profession        Profession  `json:"profession"`
+	GrantedBy         string      `json:"granted_by"`
+	Note              string      `json:"note,omitempty"`
+	ExpiresAt         time.Time   `json:"expires_at"`
+	RevokedAt         *time.Time  `json:"revoked_at,omitempty"`
+	CreatedAt         time.Time   `json:"created_at"`
+	Status            GrantStatus `json:"status"`
+}
+
+// StatusAt derives the grant's status at a point in time
+func (g *Grant) StatusAt(now time.Time) GrantStatus {
+	switch {
+	case g.RevokedAt != nil:
+		return GrantRevoked
+	case !now.Before(g.ExpiresAt):
+		return GrantExpired
+	default:
+		return GrantActive
+	}
+}
+
+type CreateGrantRequest struct {
+	// Email identifies the professional account to grant access to
+	Email string `json:"email" binding:"required,email"`
+	// Days the grant lasts, DefaultGrantDays when left out
+	Days int    `json:"days,omitempty" binding:"omitempty,min=1"`
+	Note string `json:"note,omitempty"`
+}
@@ -0,0 +1,196 @@
+package grants
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	UpsertProfessional(ctx context.Context, p *Professional) error
+	GetProfessional(ctx context.Context, userID string) (*Professional, error)
+	FindProfessionalByEmail(ctx context.Context, email string) (*Professional, error)
+
+	CreateGrant(ctx context.Context, g *Grant) error
+	GetGrant(ctx context.Context, id string) (*Grant, error)
+	ListGrantsByChild(ctx context.Context, childID string) ([]Grant, error)
+	ListActiveGrantsByProfessional(ctx context.Context, professionalID string, now time.Time) ([]Grant, error)
+	ListActiveGrantsByChild(ctx context.Context, childID string, now time.Time) ([]Grant, error)
+	ActiveGrant(ctx context.Context, childID, professionalID string, now time.Time) (*Grant, error)
+	RevokeGrant(ctx context.Context, id string, revokedAt time.Time) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the professional and grant tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "professionals", "child_access_grants")
+}
+
+const professionalColumns = `p.user_id, u.name, u.email, p.profession, p.organisation, p.created_at, p.updated_at`
+
+func (r *repository) UpsertProfessional(ctx context.Context, p *Professional) error {
+	query := `
+		INSERT INTO professionals (user_id, profession, organisation, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET profession = EXCLUDED.profession, organisation = EXCLUDED.organisation, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		p.UserID, p.Profession, p.Organisation, p.CreatedAt, p.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) GetProfessional(ctx context.Context, userID string) (*Professional, error) {
+	query := `
+		SELECT ` + professionalColumns + `
+		FROM professionals p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.user_id = $1
+	`
+	return scanProfessional(r.db.QueryRowContext(ctx, query, userID))
+}
+
+func (r *repository) FindProfessionalByEmail(ctx context.Context, email string) (*Professional, error) {
+	query := `
+		SELECT ` + professionalColumns + `
+		FROM professionals p
+		JOIN users u ON u.id = p.user_id
+		WHERE LOWER(u.email) = LOWER($1)
+	`
+	return scanProfessional(r.db.QueryRowContext(ctx, query, email))
+}
+
+func scanProfessional(row *sql.Row) (*Professional, error) {
+	var p Professional
+	err := row.Scan(&p.UserID, &p.Name, &p.Email, &p.Profession, &p.Organisation, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *repository) CreateGrant(ctx context.Context, g *Grant) error {
+	query := `
+		INSERT INTO child_access_grants (id, child_id, professional_id, granted_by, note, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		g.ID, g.ChildID, g.ProfessionalID, g.GrantedBy, g.Note, g.ExpiresAt, g.CreatedAt,
+	)
+	return err
+}
+
+// grantSelect joins in the child and professional so listings can show who
+// has access to what without further lookups
+const grantSelect = `
+	SELECT g.id, g.child_id, c.name, g.professional_id, u.name, u.email, p.profession,
+		g.granted_by, g.note, g.expires_at, g.revoked_at, g.created_at
+	FROM child_access_grants g
+	JOIN children c ON c.id = g.child_id
+	JOIN professionals p ON p.user_id = g.professional_id
+	JOIN users u ON u.id = g.professional_id
+`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanGrant(row scanner) (*Grant, error) {
+	var g Grant
+	var revokedAt sql.NullTime
+
+	if err := row.Scan(
+		&g.ID, &g.ChildID, &g.ChildName, &g.ProfessionalID, &g.ProfessionalName, &g.ProfessionalEmail, &g.Profession,
+		&g.GrantedBy, &g.Note, &g.ExpiresAt, &revokedAt, &g.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		g.RevokedAt = &revokedAt.Time
+	}
+	return &g, nil
+}
+
+func (r *repository) GetGrant(ctx context.Context, id string) (*Grant, error) {
+	g, err := scanGrant(r.db.QueryRowContext(ctx, grantSelect+`WHERE g.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return g, err
+}
+
+func (r *repository) ListGrantsByChild(ctx context.Context, childID string) ([]Grant, error) {
+	return r.listGrants(ctx, grantSelect+`WHERE g.child_id = $1 ORDER BY g.created_at DESC`, childID)
+}
+
+func (r *repository) ListActiveGrantsByProfessional(ctx context.Context, professionalID string, now time.Time) ([]Grant, error) {
+	query := grantSelect + `
+		WHERE g.professional_id = $1 AND g.revoked_at IS NULL AND g.expires_at > $2
+		ORDER BY c.name
+	`
+	return r.listGrants(ctx, query, professionalID, now)
+}
+
+func (r *repository) ListActiveGrantsByChild(ctx context.Context, childID string, now time.Time) ([]Grant, error) {
+	query := grantSelect + `
+		WHERE g.child_id = $1 AND g.revoked_at IS NULL AND g.expires_at > $2
+		ORDER BY u.name
+	`
+	return r.listGrants(ctx, query, childID, now)
+}
+
+func (r *repository) listGrants(ctx context.Context, query string, args ...any) ([]Grant, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	grants := []Grant{}
+	for rows.Next() {
+		g, err := scanGrant(rows)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, *g)
+	}
+
+	return grants, rows.Err()
+}
+
+// ActiveGrant returns the latest unrevoked, unexpired grant a professional
+// holds for the child, or nil
+func (r *repository) ActiveGrant(ctx context.Context, childID, professionalID string, now time.Time) (*Grant, error) {
+	query := grantSelect + `
+		WHERE g.child_id = $1 AND g.professional_id = $2 AND g.revoked_at IS NULL AND g.expires_at > $3
+		ORDER BY g.expires_at DESC
+		LIMIT 1
+	`
+	g, err := scanGrant(r.db.QueryRowContext(ctx, query, childID, professionalID, now))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return g, err
+}
+
+func (r *repository) RevokeGrant(ctx context.Context, id string, revokedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE child_access_grants SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, revokedAt)
+	return err
+}
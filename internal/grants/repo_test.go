@@ -0,0 +1,177 @@
+package grants
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var grantColumns = []string{
+	"id", "child_id", "name", "professional_id", "name", "email", "profession",
+	"granted_by", "note", "expires_at", "revoked_at", "created_at",
+}
+
+func TestRepository_UpsertProfessional(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	p := &Professional{UserID: "user-1", Profession: ProfessionPediatrician, Organisation: "Northside Clinic", CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectExec("INSERT INTO professionals").
+		WithArgs("user-1", ProfessionPediatrician, "Northside Clinic", now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.UpsertProfessional(context.Background(), p); err != nil {
+		t.Fatalf("UpsertProfessional() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_FindProfessionalByEmail(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"user_id", "name", "email", "profession", "organisation", "created_at", "updated_at"}).
+		AddRow("user-1", "Dr. Patel", "patel@example.com", "pediatrician", "", now, now)
+
+	mock.ExpectQuery("SELECT .+ FROM professionals p").
+		WithArgs("Patel@Example.com").
+		WillReturnRows(rows)
+
+	p, err := repo.FindProfessionalByEmail(context.Background(), "Patel@Example.com")
+	if err != nil {
+		t.Fatalf("FindProfessionalByEmail() error = %v", err)
+	}
+	if p == nil || p.UserID != "user-1" || p.Profession != ProfessionPediatrician {
+		t.Errorf("FindProfessionalByEmail() = %+v", p)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetProfessional_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT .+ FROM professionals p").
+		WithArgs("user-1").
+		WillReturnError(sql.ErrNoRows)
+
+	p, err := repo.GetProfessional(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("GetProfessional() error = %v", err)
+	}
+	if p != nil {
+		t.Errorf("GetProfessional() = %+v, want nil", p)
+	}
+}
+
+func TestRepository_CreateGrant(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	g := &Grant{ID: "grant-1", ChildID: "child-1", ProfessionalID: "pro-1", GrantedBy: "user-1", Note: "6 month checkup", ExpiresAt: now.AddDate(0, 0, 14), CreatedAt: now}
+
+	mock.ExpectExec("INSERT INTO child_access_grants").
+		WithArgs("grant-1", "child-1", "pro-1", "user-1", "6 month checkup", g.ExpiresAt, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.CreateGrant(context.Background(), g); err != nil {
+		t.Fatalf("CreateGrant() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListGrantsByChild(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(grantColumns).
+		AddRow("grant-2", "child-1", "Emma", "pro-1", "Dr. Patel", "patel@example.com", "pediatrician", "user-1", "", now.Add(time.Hour), nil, now).
+		AddRow("grant-1", "child-1", "Emma", "pro-2", "Sam Lee", "sam@example.com", "lactation_consultant", "user-1", "", now, now, now.Add(-time.Hour))
+
+	mock.ExpectQuery("SELECT .+ FROM child_access_grants g").
+		WithArgs("child-1").
+		WillReturnRows(rows)
+
+	grants, err := repo.ListGrantsByChild(context.Background(), "child-1")
+	if err != nil {
+		t.Fatalf("ListGrantsByChild() error = %v", err)
+	}
+	if len(grants) != 2 {
+		t.Fatalf("ListGrantsByChild() returned %d grants, want 2", len(grants))
+	}
+	if grants[0].RevokedAt != nil || grants[1].RevokedAt == nil {
+		t.Errorf("RevokedAt not scanned: %+v", grants)
+	}
+	if grants[1].Profession != ProfessionLactationConsultant || grants[1].ChildName != "Emma" {
+		t.Errorf("grants[1] = %+v", grants[1])
+	}
+}
+
+func TestRepository_ActiveGrant_None(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT .+ FROM child_access_grants g .+ g.revoked_at IS NULL AND g.expires_at > \\$3").
+		WithArgs("child-1", "pro-1", now).
+		WillReturnRows(sqlmock.NewRows(grantColumns))
+
+	g, err := repo.ActiveGrant(context.Background(), "child-1", "pro-1", now)
+	if err != nil {
+		t.Fatalf("ActiveGrant() error = %v", err)
+	}
+	if g != nil {
+		t.Errorf("ActiveGrant() = %+v, want nil", g)
+	}
+}
+
+func TestRepository_RevokeGrant(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE child_access_grants SET revoked_at").
+		WithArgs("grant-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RevokeGrant(context.Background(), "grant-1", now); err != nil {
+		t.Fatalf("RevokeGrant() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+package grants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/transfer"
+)
+
+var (
+	ErrInvalidProfession    = errors.New("invalid profession")
+	ErrInvalidDuration      = errors.New("grant length must be between 1 and 90 days")
+	ErrNotProfessional      = errors.New("user is not registered as a professional")
+	ErrProfessionalNotFound = errors.New("no professional account with that email")
+	ErrChildNotFound        = errors.New("child not found")
+	ErrGrantNotFound        = errors.New("access grant not found")
+	ErrNotMember            = errors.New("user is not a member of this family")
+	ErrNotAdmin             = errors.New("only admins can manage access grants")
+	ErrNoAccess             = errors.New("no active access grant for this child")
+)
+
+type Service interface {
+	Register(ctx context.Context, userID string, req *RegisterRequest) (*Professional, error)
+	GetProfessional(ctx context.Context, userID string) (*Professional, error)
+
+	Grant(ctx context.Context, userID, childID string, req *CreateGrantRequest) (*Grant, error)
+	ListForChild(ctx context.Context, userID, childID string) ([]Grant, error)
+	Revoke(ctx context.Context, userID, childID, grantID string) error
+
+	ListGranted(ctx context.Context, userID string) ([]Grant, error)
+	ChildRecords(ctx context.Context, userID, childID string) (*transfer.Bundle, error)
+
+	// HasAccess reports whether the user holds an active grant for the child
+	HasAccess(ctx context.Context, userID, childID string) (bool, error)
+	// ActiveForChild returns the child's active grants, for services that
+	// share child-scoped features with professionals
+	ActiveForChild(ctx context.Context, childID string) ([]Grant, error)
+}
+
+type service struct {
+	repo            Repository
+	familyService   family.Service
+	transferService transfer.Service
+	now             func() time.Time
+}
+
+func NewService(repo Repository, familyService family.Service, transferService transfer.Service) Service {
+	return &service{
+		repo:            repo,
+		familyService:   familyService,
+		transferService: transferService,
+		now:             time.Now,
+	}
+}
+
+// HealthCheck reports whether professional access grants can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+// Register turns the user's account into a professional account, or updates
+// its details. It gives no access by itself; families grant that per child.
+func (s *service) Register(ctx context.Context, userID string, req *RegisterRequest) (*Professional, error) {
+	if !req.Profession.Valid() {
+		return nil, ErrInvalidProfession
+	}
+
+	now := s.now()
+	p := &Professional{
+		UserID:       userID,
+		Profession:   req.Profession,
+		Organisation: strings.TrimSpace(req.Organisation),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.UpsertProfessional(ctx, p); err != nil {
+		return nil, fmt.Errorf("failed to register professional: %w", err)
+	}
+
+	return s.GetProfessional(ctx, userID)
+}
+
+func (s *service) GetProfessional(ctx context.Context, userID string) (*Professional, error) {
+	p, err := s.repo.GetProfessional(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get professional: %w", err)
+	}
+	if p == nil {
+		return nil, ErrNotProfessional
+	}
+	return p, nil
+}
+
+func (s *service) Grant(ctx context.Context, userID, childID string, req *CreateGrantRequest) (*Grant, error) {
+	child, err := s.getChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(ctx, child.FamilyID, userID); err != nil {
+		return nil, err
+	}
+
+	days := req.Days
+	if days == 0 {
+		days = DefaultGrantDays
+	}
+	if days < 1 || days > MaxGrantDays {
+		return nil, ErrInvalidDuration
+	}
+
+	p, err := s.repo.FindProfessionalByEmail(ctx, strings.TrimSpace(req.Email))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find professional: %w", err)
+	}
+	if p == nil {
+		return nil, ErrProfessionalNotFound
+	}
+
+	now := s.now()
+	g := &Grant{
+		ID:                ids.New(),
+		ChildID:           childID,
+		ChildName:         child.Name,
+		ProfessionalID:    p.UserID,
+		ProfessionalName:  p.Name,
+		ProfessionalEmail: p.Email,
+		Profession:        p.Profession,
+		GrantedBy:         userID,
+		Note:              strings.TrimSpace(req.Note),
+		ExpiresAt:         now.AddDate(0, 0, days),
+		CreatedAt:         now,
+	}
+	if err := s.repo.CreateGrant(ctx, g); err != nil {
+		return nil, fmt.Errorf("failed to create access grant: %w", err)
+	}
+
+	g.Status = g.StatusAt(now)
+	return g, nil
+}
+
+// ListForChild shows family members which professionals have had access to
+// the child, including through expired and revoked grants
+func (s *service) ListForChild(ctx context.Context, userID, childID string) ([]Grant, error) {
+	child, err := s.getChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.memberRole(ctx, child.FamilyID, userID); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.repo.ListGrantsByChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access grants: %w", err)
+	}
+	return s.withStatus(grants), nil
+}
+
+func (s *service) Revoke(ctx context.Context, userID, childID, grantID string) error {
+	child, err := s.getChild(ctx, childID)
+	if err != nil {
+		return err
+	}
+	if err := s.requireAdmin(ctx, child.FamilyID, userID); err != nil {
+		return err
+	}
+
+	g, err := s.repo.GetGrant(ctx, grantID)
+	if err != nil {
+		return fmt.Errorf("failed to get access grant: %w", err)
+	}
+	if g == nil || g.ChildID != childID {
+		return ErrGrantNotFound
+	}
+
+	if err := s.repo.RevokeGrant(ctx, grantID, s.now()); err != nil {
+		return fmt.Errorf("failed to revoke access grant: %w", err)
+	}
+	return nil
+}
+
+// ListGranted returns the children a professional can currently see
+func (s *service) ListGranted(ctx context.Context, userID string) ([]Grant, error) {
+	if _, err := s.GetProfessional(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.repo.ListActiveGrantsByProfessional(ctx, userID, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access grants: %w", err)
+	}
+	return s.withStatus(grants), nil
+}
+
+// ChildRecords returns the child's records to a professional holding an active
+// grant. The records are read on behalf of the admin who gave the grant, so a
+// grant lapses if that admin leaves the family. Private records are left out,
+// the admin's own included.
+func (s *service) ChildRecords(ctx context.Context, userID, childID string) (*transfer.Bundle, error) {
+	if _, err := s.GetProfessional(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	g, err := s.repo.ActiveGrant(ctx, childID, userID, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check access grant: %w", err)
+	}
+	if g == nil {
+		return nil, ErrNoAccess
+	}
+
+	bundle, err := s.transferService.ExportShared(ctx, g.GrantedBy, childID)
+	if errors.Is(err, transfer.ErrNotMember) || errors.Is(err, transfer.ErrChildNotFound) {
+		return nil, ErrNoAccess
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bundle.ExportedBy = userID
+	return bundle, nil
+}
+
+func (s *service) HasAccess(ctx context.Context, userID, childID string) (bool, error) {
+	g, err := s.repo.ActiveGrant(ctx, childID, userID, s.now())
+	if err != nil {
+		return false, fmt.Errorf("failed to check access grant: %w", err)
+	}
+	return g != nil, nil
+}
+
+func (s *service) ActiveForChild(ctx context.Context, childID string) ([]Grant, error) {
+	grants, err := s.repo.ListActiveGrantsByChild(ctx, childID, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access grants: %w", err)
+	}
+	return s.withStatus(grants), nil
+}
+
+func (s *service) withStatus(grants []Grant) []Grant {
+	now := s.now()
+	for i := range grants {
+		grants[i].Status = grants[i].StatusAt(now)
+	}
+	return grants
+}
+
+func (s *service) getChild(ctx context.Context, childID string) (*family.Child, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+	return child, nil
+}
+
+func (s *service) memberRole(ctx context.Context, familyID, userID string) (string, error) {
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return "", ErrNotMember
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+func (s *service) requireAdmin(ctx context.Context, familyID, userID string) error {
+	role, err := s.memberRole(ctx, familyID, userID)
+	if err != nil {
+		return err
+	}
+	if role != "admin" {
+		return ErrNotAdmin
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+package grants
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/transfer"
+)
+
+type mockRepository struct {
+	professionals map[string]*Professional
+	grants        map[string]*Grant
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		professionals: make(map[string]*Professional),
+		grants:        make(map[string]*Grant),
+	}
+}
+
+func (m *mockRepository) UpsertProfessional(ctx context.Context, p *Professional) error {
+	copied := *p
+	if existing, ok := m.professionals[p.UserID]; ok {
+		copied.Name, copied.Email, copied.CreatedAt = existing.Name, existing.Email, existing.CreatedAt
+	}
+	m.professionals[p.UserID] = &copied
+	return nil
+}
+
+func (m *mockRepository) GetProfessional(ctx context.Context, userID string) (*Professional, error) {
+	if p, ok := m.professionals[userID]; ok {
+		copied := *p
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) FindProfessionalByEmail(ctx context.Context, email string) (*Professional, error) {
+	for _, p := range m.professionals {
+		if strings.EqualFold(p.Email, email) {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) CreateGrant(ctx context.Context, g *Grant) error {
+	copied := *g
+	m.grants[g.ID] = &copied
+	return nil
+}
+
+func (m *mockRepository) GetGrant(ctx context.Context, id string) (*Grant, error) {
+	if g, ok := m.grants[id]; ok {
+		copied := *g
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListGrantsByChild(ctx context.Context, childID string) ([]Grant, error) {
+	grants := []Grant{}
+	for _, g := range m.grants {
+		if g.ChildID == childID {
+			grants = append(grants, *g)
+		}
+	}
+	return grants, nil
+}
+
+func (m *mockRepository) ListActiveGrantsByProfessional(ctx context.Context, professionalID string, now time.Time) ([]Grant, error) {
+	grants := []Grant{}
+	for _, g := range m.grants {
+		if g.ProfessionalID == professionalID && g.StatusAt(now) == GrantActive {
+			grants = append(grants, *g)
+		}
+	}
+	return grants, nil
+}
+
+func (m *mockRepository) ListActiveGrantsByChild(ctx context.Context, childID string, now time.Time) ([]Grant, error) {
+	grants := []Grant{}
+	for _, g := range m.grants {
+		if g.ChildID == childID && g.StatusAt(now) == GrantActive {
+			grants = append(grants, *g)
+		}
+	}
+	return grants, nil
+}
+
+func (m *mockRepository) ActiveGrant(ctx context.Context, childID, professionalID string, now time.Time) (*Grant, error) {
+	for _, g := range m.grants {
+		if g.ChildID == childID && g.ProfessionalID == professionalID && g.StatusAt(now) == GrantActive {
+			copied := *g
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) RevokeGrant(ctx context.Context, id string, revokedAt time.Time) error {
+	m.grants[id].RevokedAt = &revokedAt
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	roles    map[string]string
+	children map[string]*family.Child
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if role, ok := m.roles[familyID+"/"+userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+type mockTransferService struct {
+	transfer.Service
+	members  map[string]bool
+	exporter string
+}
+
+// ExportShared is the only export grants may use; Export would include the
+// granting admin's private records
+func (m *mockTransferService) ExportShared(ctx context.Context, userID, childID string) (*transfer.Bundle, error) {
+	if !m.members[userID] {
+		return nil, transfer.ErrNotMember
+	}
+	m.exporter = userID
+	return &transfer.Bundle{ExportedBy: userID, Child: family.Child{ID: childID}}, nil
+}
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService() (*service, *mockRepository, *mockTransferService) {
+	repo := newMockRepository()
+	repo.professionals["pro-1"] = &Professional{UserID: "pro-1", Name: "Dr. Patel", Email: "patel@example.com", Profession: ProfessionPediatrician}
+	transferSvc := &mockTransferService{members: map[string]bool{"admin-1": true, "member-1": true}}
+	svc := &service{
+		repo: repo,
+		familyService: &mockFamilyService{
+			roles: map[string]string{
+				"family-1/admin-1":  "admin",
+				"family-1/member-1": "member",
+			},
+			children: map[string]*family.Child{
+				"child-1": {ID: "child-1", FamilyID: "family-1", Name: "Emma"},
+			},
+		},
+		transferService: transferSvc,
+		now:             func() time.Time { return testNow },
+	}
+	return svc, repo, transferSvc
+}
+
+func TestService_Register(t *testing.T) {
+	svc, repo, _ := newTestService()
+	repo.professionals["user-2"] = &Professional{UserID: "user-2", Name: "Sam Lee", Email: "sam@example.com", Profession: ProfessionOther}
+
+	p, err := svc.Register(context.Background(), "user-2", &RegisterRequest{Profession: ProfessionLactationConsultant, Organisation: "  Milk Matters "})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if p.Profession != ProfessionLactationConsultant || p.Organisation != "Milk Matters" || p.Name != "Sam Lee" {
+		t.Errorf("Register() = %+v", p)
+	}
+}
+
+func TestService_Register_InvalidProfession(t *testing.T) {
+	svc, _, _ := newTestService()
+
+	_, err := svc.Register(context.Background(), "user-2", &RegisterRequest{Profession: "astrologer"})
+	if !errors.Is(err, ErrInvalidProfession) {
+		t.Errorf("Register() error = %v, want ErrInvalidProfession", err)
+	}
+}
+
+func TestService_Grant(t *testing.T) {
+	svc, _, _ := newTestService()
+
+	g, err := svc.Grant(context.Background(), "admin-1", "child-1", &CreateGrantRequest{Email: "Patel@Example.com"})
+	if err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+	if g.ProfessionalID != "pro-1" || g.ChildName != "Emma" || g.GrantedBy != "admin-1" {
+		t.Errorf("Grant() = %+v", g)
+	}
+	if want := testNow.AddDate(0, 0, DefaultGrantDays); !g.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, want %v", g.ExpiresAt, want)
+	}
+	if g.Status != GrantActive {
+		t.Errorf("Status = %q, want active", g.Status)
+	}
+}
+
+func TestService_Grant_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		childID string
+		req     CreateGrantRequest
+		want    error
+	}{
+		{"member", "member-1", "child-1", CreateGrantRequest{Email: "patel@example.com"}, ErrNotAdmin},
+		{"outsider", "user-9", "child-1", CreateGrantRequest{Email: "patel@example.com"}, ErrNotMember},
+		{"missing child", "admin-1", "child-9", CreateGrantRequest{Email: "patel@example.com"}, ErrChildNotFound},
+		{"too long", "admin-1", "child-1", CreateGrantRequest{Email: "patel@example.com", Days: MaxGrantDays + 1}, ErrInvalidDuration},
+		{"not a professional", "admin-1", "child-1", CreateGrantRequest{Email: "someone@example.com"}, ErrProfessionalNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _ := newTestService()
+			_, err := svc.Grant(context.Background(), tt.userID, tt.childID, &tt.req)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Grant() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ListForChild_Status(t *testing.T) {
+	svc, repo, _ := newTestService()
+	revokedAt := testNow.Add(-time.Hour)
+	repo.grants["g-active"] = &Grant{ID: "g-active", ChildID: "child-1", ExpiresAt: testNow.Add(time.Hour)}
+	repo.grants["g-expired"] = &Grant{ID: "g-expired", ChildID: "child-1", ExpiresAt: testNow}
+	repo.grants["g-revoked"] = &Grant{ID: "g-revoked", ChildID: "child-1", ExpiresAt: testNow.Add(time.Hour), RevokedAt: &revokedAt}
+
+	grants, err := svc.ListForChild(context.Background(), "member-1", "child-1")
+	if err != nil {
+		t.Fatalf("ListForChild() error = %v", err)
+	}
+
+	want := map[string]GrantStatus{"g-active": GrantActive, "g-expired": GrantExpired, "g-revoked": GrantRevoked}
+	if len(grants) != len(want) {
+		t.Fatalf("ListForChild() returned %d grants, want %d", len(grants), len(want))
+	}
+	for _, g := range grants {
+		if g.Status != want[g.ID] {
+			t.Errorf("%s status = %q, want %q", g.ID, g.Status, want[g.ID])
+		}
+	}
+}
+
+func TestService_Revoke(t *testing.T) {
+	svc, repo, _ := newTestService()
+	repo.grants["grant-1"] = &Grant{ID: "grant-1", ChildID: "child-1", ProfessionalID: "pro-1", ExpiresAt: testNow.Add(time.Hour)}
+
+	if err := svc.Revoke(context.Background(), "member-1", "child-1", "grant-1"); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("Revoke() by member error = %v, want ErrNotAdmin", err)
+	}
+	if err := svc.Revoke(context.Background(), "admin-1", "child-1", "grant-9"); !errors.Is(err, ErrGrantNotFound) {
+		t.Errorf("Revoke() unknown grant error = %v, want ErrGrantNotFound", err)
+	}
+	if err := svc.Revoke(context.Background(), "admin-1", "child-1", "grant-1"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if repo.grants["grant-1"].RevokedAt == nil {
+		t.Error("grant was not revoked")
+	}
+
+	if _, err := svc.ChildRecords(context.Background(), "pro-1", "child-1"); !errors.Is(err, ErrNoAccess) {
+		t.Errorf("ChildRecords() after revoke error = %v, want ErrNoAccess", err)
+	}
+}
+
+func TestService_ChildRecords(t *testing.T) {
+	svc, repo, transferSvc := newTestService()
+	repo.grants["grant-1"] = &Grant{ID: "grant-1", ChildID: "child-1", ProfessionalID: "pro-1", GrantedBy: "admin-1", ExpiresAt: testNow.Add(time.Hour)}
+
+	bundle, err := svc.ChildRecords(context.Background(), "pro-1", "child-1")
+	if err != nil {
+		t.Fatalf("ChildRecords() error = %v", err)
+	}
+	if transferSvc.exporter != "admin-1" {
+		t.Errorf("exported as %q, want the granting admin", transferSvc.exporter)
+	}
+	if bundle.ExportedBy != "pro-1" {
+		t.Errorf("ExportedBy = %q, want pro-1", bundle.ExportedBy)
+	}
+}
+
+func TestService_ChildRecords_Expired(t *testing.T) {
+	svc, repo, _ := newTestService()
+	repo.grants["grant-1"] = &Grant{ID: "grant-1", ChildID: "child-1", ProfessionalID: "pro-1", GrantedBy: "admin-1", ExpiresAt: testNow}
+
+	if _, err := svc.ChildRecords(context.Background(), "pro-1", "child-1"); !errors.Is(err, ErrNoAccess) {
+		t.Errorf("ChildRecords() error = %v, want ErrNoAccess", err)
+	}
+}
+
+func TestService_ChildRecords_GranterLeft(t *testing.T) {
+	svc, repo, _ := newTestService()
+	repo.grants["grant-1"] = &Grant{ID: "grant-1", ChildID: "child-1", ProfessionalID: "pro-1", GrantedBy: "former-admin", ExpiresAt: testNow.Add(time.Hour)}
+
+	if _, err := svc.ChildRecords(context.Background(), "pro-1", "child-1"); !errors.Is(err, ErrNoAccess) {
+		t.Errorf("ChildRecords() error = %v, want ErrNoAccess", err)
+	}
+}
+
+func TestService_ChildRecords_NotProfessional(t *testing.T) {
+	svc, _, _ := newTestService()
+
+	if _, err := svc.ChildRecords(context.Background(), "admin-1", "child-1"); !errors.Is(err, ErrNotProfessional) {
+		t.Errorf("ChildRecords() error = %v, want ErrNotProfessional", err)
+	}
+}
+
+func TestService_HasAccess(t *testing.T) {
+	svc, repo, _ := newTestService()
+	repo.grants["grant-1"] = &Grant{ID: "grant-1", ChildID: "child-1", ProfessionalID: "pro-1", ExpiresAt: testNow.Add(time.Hour)}
+	repo.grants["grant-2"] = &Grant{ID: "grant-2", ChildID: "child-1", ProfessionalID: "pro-2", ExpiresAt: testNow.Add(-time.Hour)}
+
+	for userID, want := range map[string]bool{"pro-1": true, "pro-2": false, "admin-1": false} {
+		got, err := svc.HasAccess(context.Background(), userID, "child-1")
+		if err != nil {
+			t.Fatalf("HasAccess() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("HasAccess(%q) = %v, want %v", userID, got, want)
+		}
+	}
+
+	active, err := svc.ActiveForChild(context.Background(), "child-1")
+	if err != nil {
+		t.Fatalf("ActiveForChild() error = %v", err)
+	}
+	if len(active) != 1 || active[0].ProfessionalID != "pro-1" {
+		t.Errorf("ActiveForChild() = %+v, want only pro-1", active)
+	}
+}
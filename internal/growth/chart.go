@@ -0,0 +1,136 @@
+package growth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// chartPercentiles are the reference lines drawn on growth charts, the ones
+// the WHO charts print
+var chartPercentiles = []float64{3, 15, 50, 85, 97}
+
+// Chart is a child's measurements placed on the WHO standards for their sex,
+// with the reference lines to draw them against
+type Chart struct {
+	ChildID           string      `json:"child_id"`
+	Gender            string      `json:"gender"`
+	DateOfBirth       time.Time   `json:"date_of_birth"`
+	Reference         string      `json:"reference"`
+	Weight            ChartSeries `json:"weight"`             // kg
+	Length            ChartSeries `json:"length"`             // cm
+	HeadCircumference ChartSeries `json:"head_circumference"` // cm
+}
+
+// ChartSeries is one measurement's percentile lines and the child's points,
+// oldest first
+type ChartSeries struct {
+	Unit   string           `json:"unit"`
+	Lines  []PercentileLine `json:"lines"`
+	Points []ChartPoint     `json:"points"`
+}
+
+// PercentileLine is a reference percentile by month of age
+type PercentileLine struct {
+	Percentile float64       `json:"percentile"`
+	Values     []ReferenceAt `json:"values"`
+}
+
+type ReferenceAt struct {
+	AgeMonths int     `json:"age_months"`
+	Value     float64 `json:"value"`
+}
+
+// ChartPoint is one measurement. ZScore and Percentile are empty past the
+// ages the standards cover.
+type ChartPoint struct {
+	MeasurementID string    `json:"measurement_id"`
+	MeasuredAt    time.Time `json:"measured_at"`
+	AgeDays       int       `json:"age_days"`
+	AgeMonths     float64   `json:"age_months"`
+	Value         float64   `json:"value"`
+	ZScore        *float64  `json:"z_score,omitempty"`
+	Percentile    *float64  `json:"percentile,omitempty"`
+}
+
+func (s *service) Chart(ctx context.Context, childID string) (*Chart, error) {
+	if s.familyService == nil {
+		return nil, ErrPercentilesUnavailable
+	}
+
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+
+	weightTable, lengthTable, ok := tablesFor(child.Gender)
+	if !ok {
+		return nil, ErrUnknownSex
+	}
+	headTable, _ := headCircumferenceTableFor(child.Gender)
+
+	measurements, err := s.repo.List(ctx, &MeasurementFilter{ChildID: childID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list measurements: %w", err)
+	}
+	sort.Slice(measurements, func(i, j int) bool {
+		return measurements[i].MeasuredAt.Before(measurements[j].MeasuredAt)
+	})
+
+	return &Chart{
+		ChildID:           childID,
+		Gender:            child.Gender,
+		DateOfBirth:       child.DateOfBirth,
+		Reference:         ReferenceVersion,
+		Weight:            series("kg", weightTable, measurements, func(m *Measurement) *float64 { return m.WeightKg }, child.DateOfBirth, 2),
+		Length:            series("cm", lengthTable, measurements, func(m *Measurement) *float64 { return m.LengthCm }, child.DateOfBirth, 1),
+		HeadCircumference: series("cm", headTable, measurements, func(m *Measurement) *float64 { return m.HeadCircumferenceCm }, child.DateOfBirth, 1),
+	}, nil
+}
+
+func series(
+	unit string,
+	table []lms,
+	measurements []Measurement,
+	valueOf func(*Measurement) *float64,
+	dob time.Time,
+	places int,
+) ChartSeries {
+	cs := ChartSeries{Unit: unit, Lines: make([]PercentileLine, 0, len(chartPercentiles)), Points: []ChartPoint{}}
+	for _, p := range chartPercentiles {
+		z := math.Sqrt2 * math.Erfinv(p/50-1)
+		line := PercentileLine{Percentile: p, Values: make([]ReferenceAt, len(table))}
+		for month, at := range table {
+			line.Values[month] = ReferenceAt{AgeMonths: month, Value: round(at.value(z), places)}
+		}
+		cs.Lines = append(cs.Lines, line)
+	}
+
+	for i := range measurements {
+		m := &measurements[i]
+		v := valueOf(m)
+		if v == nil {
+			continue
+		}
+		ageDays := m.MeasuredAt.Sub(dob).Hours() / 24
+		point := ChartPoint{
+			MeasurementID: m.ID,
+			MeasuredAt:    m.MeasuredAt,
+			AgeDays:       int(ageDays),
+			AgeMonths:     round(ageDays/daysPerMonth, 2),
+			Value:         *v,
+		}
+		if at, ok := lmsAt(table, ageDays); ok {
+			z := at.zScore(*v)
+			zScore, pct := round(z, 2), round(percentile(z), 1)
+			point.ZScore, point.Percentile = &zScore, &pct
+		}
+		cs.Points = append(cs.Points, point)
+	}
+	return cs
+}
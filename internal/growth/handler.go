@@ -0,0 +1,204 @@
+package growth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.list)
+	rg.POST("", h.create)
+	rg.GET("/:id", h.get)
+	rg.DELETE("/:id", h.delete)
+	// gin needs one wildcard name per segment, so the child ID here shares
+	// :id with the measurement routes.
+	rg.GET("/:id/percentiles", h.getChart)
+	rg.GET("/projection/:childId", h.getProjection)
+}
+
+// RegisterAdminRoutes registers the percentile recompute endpoints. The
+// group must already require the admin token.
+func (h *Handler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/percentiles", h.percentileStatus)
+	rg.POST("/percentiles/recompute", h.recomputePercentiles)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	childID := c.Query("child_id")
+	if childID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "child_id is required"})
+		return
+	}
+
+	measurements, err := h.service.List(c.Request.Context(), &MeasurementFilter{ChildID: childID, ViewerID: c.GetString("user_id")})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, measurements)
+}
+
+func (h *Handler) create(c *gin.Context) {
+	var req CreateMeasurementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.UserID = c.GetString("user_id")
+	m, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, ErrNoValues) || errors.Is(err, ErrInvalidValue) || errors.Is(err, ErrInvalidUnit) || errors.Is(err, occurrence.ErrInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, m)
+}
+
+func (h *Handler) get(c *gin.Context) {
+	m, err := h.service.Get(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if m == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "measurement not found"})
+		return
+	}
+	c.JSON(http.StatusOK, m)
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondError maps errors from reading and deleting measurements
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, visibility.ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, family.ErrNotMember), errors.Is(err, visibility.ErrHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) getProjection(c *gin.Context) {
+	req := ProjectionRequest{Date: time.Now().UTC().Truncate(24 * time.Hour)}
+	if d := c.Query("date"); d != "" {
+		date, err := time.Parse(time.DateOnly, d)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+			return
+		}
+		req.Date = date
+	}
+	var err error
+	if req.WeightKg, err = parseMeasurement(c.Query("weight_kg")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid weight_kg"})
+		return
+	}
+	if req.LengthCm, err = parseMeasurement(c.Query("length_cm")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid length_cm"})
+		return
+	}
+
+	p, err := h.service.Project(c.Request.Context(), c.Param("childId"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrChildNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrUnknownSex), errors.Is(err, ErrOutsideStandards), errors.Is(err, ErrNoTrack):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrProjectionUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *Handler) getChart(c *gin.Context) {
+	chart, err := h.service.Chart(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrChildNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrUnknownSex):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrPercentilesUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, chart)
+}
+
+func parseMeasurement(s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	if v <= 0 {
+		return nil, ErrInvalidValue
+	}
+	return &v, nil
+}
+
+func (h *Handler) percentileStatus(c *gin.Context) {
+	status, err := h.service.PercentileStatus(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// recomputePercentiles runs the recompute now rather than waiting for the
+// job, and answers once every stale measurement is done
+func (h *Handler) recomputePercentiles(c *gin.Context) {
+	n, err := h.service.RecomputePercentiles(c.Request.Context())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRecomputeRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrPercentilesUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "recomputed": n})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reference": ReferenceVersion, "recomputed": n})
+}
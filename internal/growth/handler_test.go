@@ -0,0 +1,342 @@
+package growth
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/visibility"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	createFn    func(ctx context.Context, req *CreateMeasurementRequest) (*Measurement, error)
+	getFn       func(ctx context.Context, id string) (*Measurement, error)
+	listFn      func(ctx context.Context, filter *MeasurementFilter) ([]Measurement, error)
+	deleteFn    func(ctx context.Context, id string) error
+	projectFn   func(ctx context.Context, childID string, req *ProjectionRequest) (*Projection, error)
+	recomputeFn func(ctx context.Context) (int, error)
+	statusFn    func(ctx context.Context) (*PercentileStatus, error)
+	chartFn     func(ctx context.Context, childID string) (*Chart, error)
+}
+
+func (m *mockService) Create(ctx context.Context, req *CreateMeasurementRequest) (*Measurement, error) {
+	if m.createFn != nil {
+		return m.createFn(ctx, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Measurement, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *mockService) List(ctx context.Context, filter *MeasurementFilter) ([]Measurement, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, filter)
+	}
+	return []Measurement{}, nil
+}
+
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
+	if m.deleteFn != nil {
+		return m.deleteFn(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockService) Project(ctx context.Context, childID string, req *ProjectionRequest) (*Projection, error) {
+	if m.projectFn != nil {
+		return m.projectFn(ctx, childID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) RecomputePercentiles(ctx context.Context) (int, error) {
+	if m.recomputeFn != nil {
+		return m.recomputeFn(ctx)
+	}
+	return 0, nil
+}
+
+func (m *mockService) PercentileStatus(ctx context.Context) (*PercentileStatus, error) {
+	if m.statusFn != nil {
+		return m.statusFn(ctx)
+	}
+	return &PercentileStatus{Reference: ReferenceVersion}, nil
+}
+
+func (m *mockService) Chart(ctx context.Context, childID string) (*Chart, error) {
+	if m.chartFn != nil {
+		return m.chartFn(ctx, childID)
+	}
+	return &Chart{ChildID: childID}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+	handler.RegisterRoutes(router.Group("/growth"))
+	handler.RegisterAdminRoutes(router.Group("/admin/growth"))
+	return router
+}
+
+func TestList_RequiresChild(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/growth", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreate_Success(t *testing.T) {
+	svc := &mockService{
+		createFn: func(ctx context.Context, req *CreateMeasurementRequest) (*Measurement, error) {
+			return &Measurement{ID: "m-1", ChildID: req.ChildID, WeightKg: req.WeightKg}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"child_id":"child-1","measured_at":"2024-06-01T09:00:00Z","weight_kg":5.4}`
+	req := httptest.NewRequest("POST", "/growth", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", w.Code)
+	}
+}
+
+func TestCreate_NoValues(t *testing.T) {
+	svc := &mockService{
+		createFn: func(ctx context.Context, req *CreateMeasurementRequest) (*Measurement, error) {
+			return nil, ErrNoValues
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"child_id":"child-1","measured_at":"2024-06-01T09:00:00Z"}`
+	req := httptest.NewRequest("POST", "/growth", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestGet_NotFound(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/growth/missing", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestGet_Hidden(t *testing.T) {
+	router := setupRouter(&mockService{
+		getFn: func(ctx context.Context, id string) (*Measurement, error) {
+			return nil, visibility.ErrHidden
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/growth/m-1", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestGetProjection(t *testing.T) {
+	var captured *ProjectionRequest
+	svc := &mockService{
+		projectFn: func(ctx context.Context, childID string, req *ProjectionRequest) (*Projection, error) {
+			captured = req
+			return &Projection{ChildID: childID, Date: req.Date.Format(time.DateOnly)}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/growth/projection/child-1?date=2024-05-01&weight_kg=6.8", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if captured.Date.Format(time.DateOnly) != "2024-05-01" || captured.WeightKg == nil || *captured.WeightKg != 6.8 || captured.LengthCm != nil {
+		t.Errorf("Project() called with %+v", captured)
+	}
+}
+
+func TestGetProjection_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		err    error
+		status int
+	}{
+		{"invalid date", "?date=May", nil, http.StatusBadRequest},
+		{"invalid weight", "?weight_kg=heavy", nil, http.StatusBadRequest},
+		{"child not found", "", ErrChildNotFound, http.StatusNotFound},
+		{"no gender", "", ErrUnknownSex, http.StatusUnprocessableEntity},
+		{"too old", "", ErrOutsideStandards, http.StatusUnprocessableEntity},
+		{"unconfigured", "", ErrProjectionUnavailable, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				projectFn: func(ctx context.Context, childID string, req *ProjectionRequest) (*Projection, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/growth/projection/child-1"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestGetChart(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"ok", nil, http.StatusOK},
+		{"child not found", ErrChildNotFound, http.StatusNotFound},
+		{"no gender", ErrUnknownSex, http.StatusUnprocessableEntity},
+		{"unconfigured", ErrPercentilesUnavailable, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				chartFn: func(ctx context.Context, childID string) (*Chart, error) {
+					if childID != "child-1" {
+						t.Errorf("Chart() called for %q", childID)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &Chart{ChildID: childID}, nil
+				},
+			}
+			router := setupRouter(svc)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/growth/child-1/percentiles", nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestGetChart_BesideMeasurement(t *testing.T) {
+	var gotID, gotChild string
+	svc := &mockService{
+		getFn: func(ctx context.Context, id string) (*Measurement, error) {
+			gotID = id
+			return &Measurement{ID: id}, nil
+		},
+		chartFn: func(ctx context.Context, childID string) (*Chart, error) {
+			gotChild = childID
+			return &Chart{ChildID: childID}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/growth/child-1/percentiles", nil))
+	if w.Code != http.StatusOK || gotChild != "child-1" {
+		t.Errorf("GET /growth/child-1/percentiles = %d for %q, want the chart", w.Code, gotChild)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/growth/m-1", nil))
+	if w.Code != http.StatusOK || gotID != "m-1" {
+		t.Errorf("GET /growth/m-1 = %d for %q, want the measurement", w.Code, gotID)
+	}
+}
+
+func TestPercentileStatus(t *testing.T) {
+	svc := &mockService{
+		statusFn: func(ctx context.Context) (*PercentileStatus, error) {
+			return &PercentileStatus{Reference: ReferenceVersion, Stale: 3}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/growth/percentiles", http.NoBody))
+
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"stale":3`)) {
+		t.Errorf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRecomputePercentiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"recomputed", nil, http.StatusOK},
+		{"already running", ErrRecomputeRunning, http.StatusConflict},
+		{"unconfigured", ErrPercentilesUnavailable, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				recomputeFn: func(ctx context.Context) (int, error) {
+					return 4, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/growth/percentiles/recompute", http.NoBody))
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package growth
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/measure"
+)
+
+// SourceManual marks measurements entered by hand
+const SourceManual = "manual"
+
+type Measurement struct {
+	ID                  string    `json:"id"`
+	ChildID             string    `json:"child_id"`
+	MeasuredAt          time.Time `json:"measured_at"`
+	WeightKg            *float64  `json:"weight_kg,omitempty"`
+	LengthCm            *float64  `json:"length_cm,omitempty"`
+	HeadCircumferenceCm *float64  `json:"head_circumference_cm,omitempty"`
+	Source              string    `json:"source"` // "manual" or "device:<id>"
+	Notes               string    `json:"notes,omitempty"`
+	// WeightPercentile and LengthPercentile place the measurement on the WHO
+	// standards for the child's sex and age, computed with the reference
+	// tables named by PercentileReference. They are empty where the
+	// standards don't apply, e.g. past 24 months.
+	WeightPercentile    *float64   `json:"weight_percentile,omitempty"`
+	LengthPercentile    *float64   `json:"length_percentile,omitempty"`
+	PercentileReference string     `json:"percentile_reference,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	SyncedAt            *time.Time `json:"synced_at,omitempty"`
+}
+
+type CreateMeasurementRequest struct {
+	ChildID             string    `json:"child_id" binding:"required"`
+	MeasuredAt          time.Time `json:"measured_at" binding:"required"`
+	WeightKg            *float64  `json:"weight_kg,omitempty"`
+	LengthCm            *float64  `json:"length_cm,omitempty"`
+	HeadCircumferenceCm *float64  `json:"head_circumference_cm,omitempty"`
+	// Weight, Length and HeadCircumference take a value in any unit, e.g.
+	// {"value":17.6,"unit":"lb"} or "17.6 lb", in place of the kg/cm fields
+	Weight            *measure.Quantity `json:"weight,omitempty"`
+	Length            *measure.Quantity `json:"length,omitempty"`
+	HeadCircumference *measure.Quantity `json:"head_circumference,omitempty"`
+	Source            string            `json:"source,omitempty"`
+	Notes             string            `json:"notes,omitempty"`
+	// UserID is set by the server to the member recording the measurement,
+	// who must be able to see the child's measurements
+	UserID string `json:"-"`
+}
+
+type MeasurementFilter struct {
+	ChildID   string
+	StartDate *time.Time
+	EndDate   *time.Time
+	// ViewerID leaves out measurements hidden from that member when set
+	ViewerID string
+}
@@ -0,0 +1,156 @@
+package growth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+// ReferenceVersion names the WHO tables in lms.go. Change it whenever the
+// tables change: measurements whose percentiles came from another version
+// are then recomputed by the percentile job, or on demand by an operator.
+const ReferenceVersion = "who-2006"
+
+// recomputeBatch is how many measurements are read at a time while
+// recomputing percentiles
+const recomputeBatch = 500
+
+var (
+	ErrPercentilesUnavailable = errors.New("growth percentiles are not configured")
+	ErrRecomputeRunning       = errors.New("percentiles are already being recomputed")
+)
+
+// PercentileStatus is how many measurements still need their percentiles
+// computed against the current reference tables
+type PercentileStatus struct {
+	Reference string `json:"reference"`
+	Stale     int    `json:"stale"`
+	Running   bool   `json:"running"`
+}
+
+// tablesFor returns the weight and length tables for a child's sex
+func tablesFor(gender string) (weight, length []lms, ok bool) {
+	switch strings.ToLower(gender) {
+	case "male":
+		return weightForAgeBoys, lengthForAgeBoys, true
+	case "female":
+		return weightForAgeGirls, lengthForAgeGirls, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// headCircumferenceTableFor returns the head circumference table for a
+// child's sex
+func headCircumferenceTableFor(gender string) ([]lms, bool) {
+	switch strings.ToLower(gender) {
+	case "male":
+		return headCircumferenceForAgeBoys, true
+	case "female":
+		return headCircumferenceForAgeGirls, true
+	default:
+		return nil, false
+	}
+}
+
+// computePercentiles places m on the current reference tables for child.
+// Percentiles are left empty when the child is unknown, has no recorded sex
+// or is outside the ages the tables cover; the measurement is still marked
+// as done against this version.
+func computePercentiles(m *Measurement, child *family.Child) {
+	m.WeightPercentile, m.LengthPercentile = nil, nil
+	m.PercentileReference = ReferenceVersion
+	if child == nil {
+		return
+	}
+	weightTable, lengthTable, ok := tablesFor(child.Gender)
+	if !ok {
+		return
+	}
+	ageDays := m.MeasuredAt.Sub(child.DateOfBirth).Hours() / 24
+	m.WeightPercentile = percentileOn(weightTable, ageDays, m.WeightKg)
+	m.LengthPercentile = percentileOn(lengthTable, ageDays, m.LengthCm)
+}
+
+func percentileOn(table []lms, ageDays float64, v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	at, ok := lmsAt(table, ageDays)
+	if !ok {
+		return nil
+	}
+	p := round(percentile(at.zScore(*v)), 1)
+	return &p
+}
+
+// stampPercentiles computes a new measurement's percentiles. It is best
+// effort: if the child can't be read, the measurement is stored without a
+// reference and picked up by the next recompute.
+func (s *service) stampPercentiles(ctx context.Context, m *Measurement) {
+	if s.familyService == nil {
+		return
+	}
+	child, err := s.familyService.GetChild(ctx, m.ChildID)
+	if err != nil {
+		log.Printf("[growth] Failed to read child %s for percentiles: %v", m.ChildID, err)
+		return
+	}
+	computePercentiles(m, child)
+}
+
+// RecomputePercentiles brings every measurement computed against older
+// reference tables up to date, a batch at a time, and returns how many it
+// updated. Batches already written stay written if it fails part way.
+func (s *service) RecomputePercentiles(ctx context.Context) (int, error) {
+	if s.familyService == nil {
+		return 0, ErrPercentilesUnavailable
+	}
+	if !s.recomputing.CompareAndSwap(false, true) {
+		return 0, ErrRecomputeRunning
+	}
+	defer s.recomputing.Store(false)
+
+	total := 0
+	for {
+		batch, err := s.repo.ListStale(ctx, ReferenceVersion, recomputeBatch)
+		if err != nil {
+			return total, fmt.Errorf("failed to list stale percentiles: %w", err)
+		}
+
+		// Batches are ordered by child, so most children are read once
+		children := make(map[string]*family.Child)
+		for i := range batch {
+			m := &batch[i]
+			child, ok := children[m.ChildID]
+			if !ok {
+				if child, err = s.familyService.GetChild(ctx, m.ChildID); err != nil {
+					return total, fmt.Errorf("failed to get child: %w", err)
+				}
+				children[m.ChildID] = child
+			}
+
+			computePercentiles(m, child)
+			if err := s.repo.UpdatePercentiles(ctx, m); err != nil {
+				return total, fmt.Errorf("failed to update percentiles: %w", err)
+			}
+			total++
+		}
+
+		if len(batch) < recomputeBatch {
+			return total, nil
+		}
+	}
+}
+
+func (s *service) PercentileStatus(ctx context.Context) (*PercentileStatus, error) {
+	n, err := s.repo.CountStale(ctx, ReferenceVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count stale percentiles: %w", err)
+	}
+	return &PercentileStatus{Reference: ReferenceVersion, Stale: n, Running: s.recomputing.Load()}, nil
+}
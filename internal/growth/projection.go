@@ -0,0 +1,167 @@
+package growth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+// projectionBand is how far either side of the child's track, in standard
+// deviations, a new measurement is still expected to fall. Near the median the
+// WHO major percentile lines are about one standard deviation apart.
+const projectionBand = 1.0
+
+var (
+	ErrProjectionUnavailable = errors.New("growth projections are not configured")
+	ErrChildNotFound         = errors.New("child not found")
+	ErrUnknownSex            = errors.New("the child's gender must be male or female to compare with WHO growth standards")
+	ErrOutsideStandards      = errors.New("projections cover birth to 24 months")
+	ErrNoTrack               = errors.New("no weight or length recorded for this child")
+)
+
+// Projection is the expected range for a child's next weight and length on a
+// day, following the percentile track of their last measurements before it
+type Projection struct {
+	ChildID string            `json:"child_id"`
+	Date    string            `json:"date"`
+	AgeDays int               `json:"age_days"`
+	Weight  *MetricProjection `json:"weight,omitempty"` // kg
+	Length  *MetricProjection `json:"length,omitempty"` // cm
+}
+
+// MetricProjection projects one measurement along the percentile it was on
+type MetricProjection struct {
+	BasedOn    string    `json:"based_on"` // the measurement the track comes from
+	MeasuredAt time.Time `json:"measured_at"`
+	ZScore     float64   `json:"z_score"`
+	Percentile float64   `json:"percentile"`
+	Expected   float64   `json:"expected"`
+	Low        float64   `json:"low"`
+	High       float64   `json:"high"`
+	// Value is a measurement to check against the range, with its own
+	// percentile and whether it falls inside
+	Value           *float64 `json:"value,omitempty"`
+	ValuePercentile *float64 `json:"value_percentile,omitempty"`
+	Within          *bool    `json:"within,omitempty"`
+}
+
+// ProjectionRequest asks for a projection on the day starting at Date,
+// optionally checking measurements taken that day
+type ProjectionRequest struct {
+	Date     time.Time
+	WeightKg *float64
+	LengthCm *float64
+}
+
+type Option func(*service)
+
+// WithChildren supplies the child's birth date and gender for projections
+func WithChildren(familyService family.Service) Option {
+	return func(s *service) {
+		s.familyService = familyService
+	}
+}
+
+func (s *service) Project(ctx context.Context, childID string, req *ProjectionRequest) (*Projection, error) {
+	if s.familyService == nil {
+		return nil, ErrProjectionUnavailable
+	}
+
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+
+	weightTable, lengthTable, ok := tablesFor(child.Gender)
+	if !ok {
+		return nil, ErrUnknownSex
+	}
+
+	ageDays := req.Date.Sub(child.DateOfBirth).Hours() / 24
+	if _, ok := lmsAt(weightTable, ageDays); !ok {
+		return nil, ErrOutsideStandards
+	}
+
+	measurements, err := s.repo.List(ctx, &MeasurementFilter{ChildID: childID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list measurements: %w", err)
+	}
+
+	p := &Projection{
+		ChildID: childID,
+		Date:    req.Date.Format(time.DateOnly),
+		AgeDays: int(ageDays),
+	}
+	weightOf := func(m *Measurement) *float64 { return m.WeightKg }
+	lengthOf := func(m *Measurement) *float64 { return m.LengthCm }
+	p.Weight = project(measurements, weightOf, weightTable, child.DateOfBirth, req.Date, req.WeightKg, 2)
+	p.Length = project(measurements, lengthOf, lengthTable, child.DateOfBirth, req.Date, req.LengthCm, 1)
+	if p.Weight == nil && p.Length == nil {
+		return nil, ErrNoTrack
+	}
+	return p, nil
+}
+
+// project follows the z-score of the last measurement before date forward to
+// date. Measurements from the day itself are what is being checked, so they
+// are not part of the track. It returns nil when there is no usable
+// measurement.
+func project(
+	measurements []Measurement,
+	valueOf func(*Measurement) *float64,
+	table []lms,
+	dob, date time.Time,
+	check *float64,
+	places int,
+) *MetricProjection {
+	var latest *Measurement
+	for i := range measurements {
+		m := &measurements[i]
+		if valueOf(m) == nil || !m.MeasuredAt.Before(date) {
+			continue
+		}
+		if latest == nil || m.MeasuredAt.After(latest.MeasuredAt) {
+			latest = m
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+
+	then, ok := lmsAt(table, latest.MeasuredAt.Sub(dob).Hours()/24)
+	if !ok {
+		return nil
+	}
+	at, _ := lmsAt(table, date.Sub(dob).Hours()/24)
+	z := then.zScore(*valueOf(latest))
+
+	mp := &MetricProjection{
+		BasedOn:    latest.ID,
+		MeasuredAt: latest.MeasuredAt,
+		ZScore:     round(z, 2),
+		Percentile: round(percentile(z), 1),
+		Expected:   round(at.value(z), places),
+		Low:        round(at.value(z-projectionBand), places),
+		High:       round(at.value(z+projectionBand), places),
+	}
+	if check != nil {
+		within := *check >= mp.Low && *check <= mp.High
+		pct := round(percentile(at.zScore(*check)), 1)
+		mp.Value = check
+		mp.ValuePercentile = &pct
+		mp.Within = &within
+	}
+	return mp
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow10(places)
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,194 @@
+package growth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	GetByID(ctx context.Context, id string) (*Measurement, error)
+	List(ctx context.Context, filter *MeasurementFilter) ([]Measurement, error)
+	Create(ctx context.Context, m *Measurement) error
+	Delete(ctx context.Context, id string) error
+	// ListStale returns measurements whose percentiles were not computed
+	// with the reference tables named, oldest child first
+	ListStale(ctx context.Context, reference string, limit int) ([]Measurement, error)
+	CountStale(ctx context.Context, reference string) (int, error)
+	UpdatePercentiles(ctx context.Context, m *Measurement) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the measurements table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "growth_measurements")
+}
+
+const measurementColumns = `id, child_id, measured_at, weight_kg, length_cm, head_circumference_cm, source, notes,
+	weight_percentile, length_percentile, COALESCE(percentile_reference, ''), created_at, updated_at, synced_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMeasurement(row rowScanner) (*Measurement, error) {
+	var m Measurement
+	var weight, length, head, weightPct, lengthPct sql.NullFloat64
+	var notes sql.NullString
+	var syncedAt sql.NullTime
+
+	if err := row.Scan(
+		&m.ID, &m.ChildID, &m.MeasuredAt, &weight, &length, &head, &m.Source, &notes,
+		&weightPct, &lengthPct, &m.PercentileReference, &m.CreatedAt, &m.UpdatedAt, &syncedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if weight.Valid {
+		m.WeightKg = &weight.Float64
+	}
+	if length.Valid {
+		m.LengthCm = &length.Float64
+	}
+	if head.Valid {
+		m.HeadCircumferenceCm = &head.Float64
+	}
+	if notes.Valid {
+		m.Notes = notes.String
+	}
+	if weightPct.Valid {
+		m.WeightPercentile = &weightPct.Float64
+	}
+	if lengthPct.Valid {
+		m.LengthPercentile = &lengthPct.Float64
+	}
+	if syncedAt.Valid {
+		m.SyncedAt = &syncedAt.Time
+	}
+
+	return &m, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Measurement, error) {
+	query := `SELECT ` + measurementColumns + ` FROM growth_measurements WHERE id = $1`
+
+	m, err := scanMeasurement(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return m, err
+}
+
+func (r *repository) List(ctx context.Context, filter *MeasurementFilter) ([]Measurement, error) {
+	query := `SELECT ` + measurementColumns + ` FROM growth_measurements WHERE child_id = $1`
+	args := []any{filter.ChildID}
+	argIndex := 2
+
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(` AND measured_at >= $%d`, argIndex)
+		args = append(args, *filter.StartDate)
+		argIndex++
+	}
+
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(` AND measured_at <= $%d`, argIndex)
+		args = append(args, *filter.EndDate)
+	}
+
+	query += ` ORDER BY measured_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	measurements := []Measurement{}
+	for rows.Next() {
+		m, err := scanMeasurement(rows)
+		if err != nil {
+			return nil, err
+		}
+		measurements = append(measurements, *m)
+	}
+
+	return measurements, rows.Err()
+}
+
+func (r *repository) Create(ctx context.Context, m *Measurement) error {
+	query := `
+		INSERT INTO growth_measurements (id, child_id, measured_at, weight_kg, length_cm, head_circumference_cm, source, notes,
+		                                 weight_percentile, length_percentile, percentile_reference, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	var notes, reference *string
+	if m.Notes != "" {
+		notes = &m.Notes
+	}
+	if m.PercentileReference != "" {
+		reference = &m.PercentileReference
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		m.ID, m.ChildID, m.MeasuredAt, m.WeightKg, m.LengthCm, m.HeadCircumferenceCm, m.Source, notes,
+		m.WeightPercentile, m.LengthPercentile, reference, m.CreatedAt, m.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM growth_measurements WHERE id = $1`, id)
+	return err
+}
+
+// stale matches measurements with a weight or length whose percentiles came
+// from other reference tables, or were never computed
+const stale = `percentile_reference IS DISTINCT FROM $1 AND (weight_kg IS NOT NULL OR length_cm IS NOT NULL)`
+
+func (r *repository) ListStale(ctx context.Context, reference string, limit int) ([]Measurement, error) {
+	query := `SELECT ` + measurementColumns + ` FROM growth_measurements WHERE ` + stale + ` ORDER BY child_id, measured_at LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, reference, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	measurements := []Measurement{}
+	for rows.Next() {
+		m, err := scanMeasurement(rows)
+		if err != nil {
+			return nil, err
+		}
+		measurements = append(measurements, *m)
+	}
+
+	return measurements, rows.Err()
+}
+
+func (r *repository) CountStale(ctx context.Context, reference string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM growth_measurements WHERE `+stale, reference).Scan(&n)
+	return n, err
+}
+
+func (r *repository) UpdatePercentiles(ctx context.Context, m *Measurement) error {
+	query := `
+		UPDATE growth_measurements
+		SET weight_percentile = $2, length_percentile = $3, percentile_reference = $4
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, m.ID, m.WeightPercentile, m.LengthPercentile, m.PercentileReference)
+	return err
+}
@@ -0,0 +1,151 @@
+package growth
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var measurementRowColumns = []string{
+	"id", "child_id", "measured_at", "weight_kg", "length_cm", "head_circumference_cm",
+	"source", "notes", "weight_percentile", "length_percentile", "percentile_reference",
+	"created_at", "updated_at", "synced_at",
+}
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(measurementRowColumns).
+		AddRow("m-1", "child-1", now, 5.4, nil, nil, "device:scale-1", nil, 48.2, nil, "who-2006", now, now, nil)
+
+	mock.ExpectQuery("SELECT id, child_id, measured_at").
+		WithArgs("m-1").
+		WillReturnRows(rows)
+
+	m, err := repo.GetByID(context.Background(), "m-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if m.WeightKg == nil || *m.WeightKg != 5.4 || m.LengthCm != nil || m.Source != "device:scale-1" ||
+		m.WeightPercentile == nil || *m.WeightPercentile != 48.2 || m.PercentileReference != "who-2006" {
+		t.Errorf("GetByID() = %+v", m)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT id, child_id, measured_at").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	m, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || m != nil {
+		t.Errorf("GetByID() = %v, %v; want nil, nil", m, err)
+	}
+}
+
+func TestRepository_List_WithDates(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	start := now.AddDate(0, -1, 0)
+	rows := sqlmock.NewRows(measurementRowColumns).
+		AddRow("m-2", "child-1", now, 5.6, 58.0, 39.5, "manual", "Clinic visit", nil, nil, "", now, now, nil).
+		AddRow("m-1", "child-1", start, 5.1, nil, nil, "manual", nil, nil, nil, "", now, now, nil)
+
+	mock.ExpectQuery("SELECT .* FROM growth_measurements WHERE child_id = \\$1 AND measured_at >= \\$2 AND measured_at <= \\$3").
+		WithArgs("child-1", start, now).
+		WillReturnRows(rows)
+
+	measurements, err := repo.List(context.Background(), &MeasurementFilter{ChildID: "child-1", StartDate: &start, EndDate: &now})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(measurements) != 2 || measurements[0].Notes != "Clinic visit" {
+		t.Errorf("List() = %+v", measurements)
+	}
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	weight := 5.4
+	m := &Measurement{ID: "m-1", ChildID: "child-1", MeasuredAt: now, WeightKg: &weight, Source: "manual", CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectExec("INSERT INTO growth_measurements").
+		WithArgs("m-1", "child-1", now, &weight, nil, nil, "manual", nil, nil, nil, nil, now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), m); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListStale(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(measurementRowColumns).
+		AddRow("m-1", "child-1", now, 5.4, nil, nil, "manual", nil, 12.5, nil, "who-test", now, now, nil)
+
+	mock.ExpectQuery("SELECT .* FROM growth_measurements WHERE percentile_reference IS DISTINCT FROM \\$1 .* LIMIT \\$2").
+		WithArgs("who-2006", 500).
+		WillReturnRows(rows)
+
+	measurements, err := repo.ListStale(context.Background(), "who-2006", 500)
+	if err != nil {
+		t.Fatalf("ListStale() error = %v", err)
+	}
+	if len(measurements) != 1 || measurements[0].PercentileReference != "who-test" {
+		t.Errorf("ListStale() = %+v", measurements)
+	}
+}
+
+func TestRepository_UpdatePercentiles(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	p := 50.1
+	mock.ExpectExec("UPDATE growth_measurements").
+		WithArgs("m-1", &p, nil, "who-2006").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	m := &Measurement{ID: "m-1", WeightPercentile: &p, PercentileReference: "who-2006"}
+	if err := repo.UpdatePercentiles(context.Background(), m); err != nil {
+		t.Fatalf("UpdatePercentiles() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
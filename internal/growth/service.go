@@ -0,0 +1,173 @@
+package growth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/measure"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrNoValues     = errors.New("at least one of weight, length or head circumference is required")
+	ErrInvalidValue = errors.New("measurements must be positive")
+	ErrInvalidUnit  = errors.New("invalid measurement unit")
+)
+
+type Service interface {
+	Create(ctx context.Context, req *CreateMeasurementRequest) (*Measurement, error)
+	Get(ctx context.Context, userID, id string) (*Measurement, error)
+	List(ctx context.Context, filter *MeasurementFilter) ([]Measurement, error)
+	Delete(ctx context.Context, userID, id string) error
+	// Project gives the expected weight and length range on a date from the
+	// child's WHO percentile track
+	Project(ctx context.Context, childID string, req *ProjectionRequest) (*Projection, error)
+	// Chart places the child's weight, length and head circumference on the
+	// WHO standards, with the reference percentile lines to plot them on
+	Chart(ctx context.Context, childID string) (*Chart, error)
+	// RecomputePercentiles recomputes the stored percentiles that came from
+	// older reference tables, returning how many measurements it updated
+	RecomputePercentiles(ctx context.Context) (int, error)
+	PercentileStatus(ctx context.Context) (*PercentileStatus, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	visibility    visibility.Service
+	recomputing   atomic.Bool
+}
+
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{repo: repo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether growth measurements can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Create(ctx context.Context, req *CreateMeasurementRequest) (*Measurement, error) {
+	// Internal callers create without a user and check access themselves
+	if req.UserID != "" {
+		if err := s.authorize(ctx, req.UserID, req.ChildID); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if req.WeightKg, err = inUnit(req.WeightKg, req.Weight, measure.Kilogram); err != nil {
+		return nil, err
+	}
+	if req.LengthCm, err = inUnit(req.LengthCm, req.Length, measure.Centimeter); err != nil {
+		return nil, err
+	}
+	if req.HeadCircumferenceCm, err = inUnit(req.HeadCircumferenceCm, req.HeadCircumference, measure.Centimeter); err != nil {
+		return nil, err
+	}
+	if req.WeightKg == nil && req.LengthCm == nil && req.HeadCircumferenceCm == nil {
+		return nil, ErrNoValues
+	}
+	for _, v := range []*float64{req.WeightKg, req.LengthCm, req.HeadCircumferenceCm} {
+		if v != nil && *v <= 0 {
+			return nil, ErrInvalidValue
+		}
+	}
+
+	now := time.Now()
+	occurrence.Adjust(ctx, "measured_at", &req.MeasuredAt, now)
+	if err := occurrence.Validate(req.MeasuredAt, now); err != nil {
+		return nil, err
+	}
+
+	source := req.Source
+	if source == "" {
+		source = SourceManual
+	}
+
+	m := &Measurement{
+		ID:                  ids.New(),
+		ChildID:             req.ChildID,
+		MeasuredAt:          req.MeasuredAt,
+		WeightKg:            req.WeightKg,
+		LengthCm:            req.LengthCm,
+		HeadCircumferenceCm: req.HeadCircumferenceCm,
+		Source:              source,
+		Notes:               req.Notes,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	s.stampPercentiles(ctx, m)
+
+	if err := s.repo.Create(ctx, m); err != nil {
+		return nil, fmt.Errorf("failed to create measurement: %w", err)
+	}
+
+	return m, nil
+}
+
+// inUnit returns a measurement given with its own unit in the unit it is
+// stored in, or the plain value when no unit was given
+func inUnit(plain *float64, q *measure.Quantity, unit measure.Unit) (*float64, error) {
+	if q == nil {
+		return plain, nil
+	}
+	if plain != nil {
+		return nil, fmt.Errorf("%w: give each measurement once, in %s or with a unit", ErrInvalidUnit, unit)
+	}
+	if err := q.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidValue, err)
+	}
+	converted, err := q.In(unit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidUnit, err)
+	}
+	v := converted.Round(3).Value
+	return &v, nil
+}
+
+func (s *service) Get(ctx context.Context, userID, id string) (*Measurement, error) {
+	m, err := s.repo.GetByID(ctx, id)
+	if err != nil || m == nil {
+		return m, err
+	}
+	if err := s.authorize(ctx, userID, m.ChildID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *service) List(ctx context.Context, filter *MeasurementFilter) ([]Measurement, error) {
+	viewer, err := s.viewer(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	measurements, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return visibility.Filter(ctx, viewer, measurements, childOf)
+}
+
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	m, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if m != nil {
+		if err := s.authorize(ctx, userID, m.ChildID); err != nil {
+			return err
+		}
+	}
+	return s.repo.Delete(ctx, id)
+}
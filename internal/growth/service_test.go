@@ -0,0 +1,398 @@
+package growth
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/measure"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type mockRepository struct {
+	measurements map[string]*Measurement
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{measurements: make(map[string]*Measurement)}
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Measurement, error) {
+	return m.measurements[id], nil
+}
+
+func (m *mockRepository) List(ctx context.Context, filter *MeasurementFilter) ([]Measurement, error) {
+	result := []Measurement{}
+	for _, item := range m.measurements {
+		if item.ChildID == filter.ChildID {
+			result = append(result, *item)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) Create(ctx context.Context, item *Measurement) error {
+	m.measurements[item.ID] = item
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	delete(m.measurements, id)
+	return nil
+}
+
+func (m *mockRepository) ListStale(ctx context.Context, reference string, limit int) ([]Measurement, error) {
+	result := []Measurement{}
+	for _, item := range m.measurements {
+		if len(result) < limit && item.PercentileReference != reference && (item.WeightKg != nil || item.LengthCm != nil) {
+			result = append(result, *item)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) CountStale(ctx context.Context, reference string) (int, error) {
+	stale, _ := m.ListStale(ctx, reference, len(m.measurements))
+	return len(stale), nil
+}
+
+func (m *mockRepository) UpdatePercentiles(ctx context.Context, item *Measurement) error {
+	stored := m.measurements[item.ID]
+	stored.WeightPercentile, stored.LengthPercentile = item.WeightPercentile, item.LengthPercentile
+	stored.PercentileReference = item.PercentileReference
+	return nil
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestService_Create(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	m, err := svc.Create(context.Background(), &CreateMeasurementRequest{
+		ChildID: "child-1", MeasuredAt: time.Now(), WeightKg: ptr(5.4),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if m.Source != SourceManual {
+		t.Errorf("Create() source = %q, want manual default", m.Source)
+	}
+	if _, ok := repo.measurements[m.ID]; !ok {
+		t.Error("Create() should store the measurement")
+	}
+}
+
+func TestService_Create_Units(t *testing.T) {
+	svc := NewService(newMockRepository())
+
+	m, err := svc.Create(context.Background(), &CreateMeasurementRequest{
+		ChildID:    "child-1",
+		MeasuredAt: time.Now(),
+		Weight:     &measure.Quantity{Value: 17.6, Unit: measure.Pound},
+		Length:     &measure.Quantity{Value: 27, Unit: measure.Inch},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if m.WeightKg == nil || *m.WeightKg != 7.983 {
+		t.Errorf("Create() weight = %v, want 7.983 kg", m.WeightKg)
+	}
+	if m.LengthCm == nil || *m.LengthCm != 68.58 {
+		t.Errorf("Create() length = %v, want 68.58 cm", m.LengthCm)
+	}
+}
+
+func TestService_Create_Validation(t *testing.T) {
+	svc := NewService(newMockRepository())
+
+	tests := []struct {
+		name    string
+		req     *CreateMeasurementRequest
+		wantErr error
+	}{
+		{"no values", &CreateMeasurementRequest{ChildID: "child-1"}, ErrNoValues},
+		{"negative weight", &CreateMeasurementRequest{ChildID: "child-1", WeightKg: ptr(-1)}, ErrInvalidValue},
+		{"zero length", &CreateMeasurementRequest{ChildID: "child-1", LengthCm: ptr(0)}, ErrInvalidValue},
+		{"weight in cm", &CreateMeasurementRequest{ChildID: "child-1", Weight: &measure.Quantity{Value: 8, Unit: measure.Centimeter}}, ErrInvalidUnit},
+		{"weight given twice", &CreateMeasurementRequest{ChildID: "child-1", WeightKg: ptr(8), Weight: &measure.Quantity{Value: 8, Unit: measure.Kilogram}}, ErrInvalidUnit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Create(context.Background(), tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type mockFamilyService struct {
+	family.Service
+	child *family.Child
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if m.child != nil && m.child.ID == childID {
+		return m.child, nil
+	}
+	return nil, nil
+}
+
+// mockVisibilityService hides child-hidden's measurements
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) CheckChild(ctx context.Context, userID, childID string, recordType visibility.RecordType) error {
+	if childID == "child-hidden" {
+		return visibility.ErrHidden
+	}
+	return nil
+}
+
+func TestService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewService(repo, WithVisibility(&mockVisibilityService{}))
+	repo.measurements["m-1"] = &Measurement{ID: "m-1", ChildID: "child-1", WeightKg: ptr(5.4)}
+	repo.measurements["m-2"] = &Measurement{ID: "m-2", ChildID: "child-hidden", WeightKg: ptr(5.4)}
+
+	if m, err := svc.Get(ctx, "user-1", "m-1"); err != nil || m == nil {
+		t.Errorf("Get() visible = %v, %v", m, err)
+	}
+	if _, err := svc.Get(ctx, "user-1", "m-2"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Get() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.List(ctx, &MeasurementFilter{ChildID: "child-hidden", ViewerID: "user-1"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("List() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Delete(ctx, "user-1", "m-2"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Delete() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Create(ctx, &CreateMeasurementRequest{ChildID: "child-hidden", MeasuredAt: time.Now(), WeightKg: ptr(5.4), UserID: "user-1"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Create() hidden error = %v, want ErrHidden", err)
+	}
+	if repo.measurements["m-2"] == nil {
+		t.Error("Delete() removed a hidden measurement")
+	}
+}
+
+func TestLMS_MatchesWHOTables(t *testing.T) {
+	// WHO weight-for-age, boys at birth: median 3.3 kg, -2 SD 2.5 kg, +2 SD 4.4 kg
+	birth, _ := lmsAt(weightForAgeBoys, 0)
+	for _, tt := range []struct {
+		z, want float64
+	}{{0, 3.3}, {-2, 2.5}, {2, 4.4}} {
+		if got := round(birth.value(tt.z), 1); got != tt.want {
+			t.Errorf("value(%v) = %v, want %v", tt.z, got, tt.want)
+		}
+	}
+	if z := birth.zScore(3.3464); math.Abs(z) > 1e-9 {
+		t.Errorf("zScore(median) = %v, want 0", z)
+	}
+	if p := percentile(0); p != 50 {
+		t.Errorf("percentile(0) = %v, want 50", p)
+	}
+
+	if _, ok := lmsAt(weightForAgeBoys, 25*daysPerMonth); ok {
+		t.Error("lmsAt() should not extrapolate past 24 months")
+	}
+}
+
+func newProjectionService(gender string) (Service, *mockRepository) {
+	repo := newMockRepository()
+	child := &family.Child{ID: "child-1", DateOfBirth: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Gender: gender}
+	return NewService(repo, WithChildren(&mockFamilyService{child: child})), repo
+}
+
+func TestService_Project(t *testing.T) {
+	svc, repo := newProjectionService("male")
+	// On the median at two months, then a weight on the day being checked
+	repo.measurements["m1"] = &Measurement{ID: "m1", ChildID: "child-1",
+		MeasuredAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), WeightKg: ptr(5.56), LengthCm: ptr(58.4)}
+	repo.measurements["m2"] = &Measurement{ID: "m2", ChildID: "child-1",
+		MeasuredAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), WeightKg: ptr(9.9)}
+
+	p, err := svc.Project(context.Background(), "child-1", &ProjectionRequest{
+		Date:     time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		WeightKg: ptr(6.8),
+	})
+	if err != nil {
+		t.Fatalf("Project() error = %v", err)
+	}
+
+	w := p.Weight
+	if w == nil || w.BasedOn != "m1" {
+		t.Fatalf("Project() weight = %+v, want a track from m1", w)
+	}
+	// Four months' median is about 7.0 kg
+	if math.Abs(w.Expected-7.0) > 0.1 || w.Low >= w.Expected || w.High <= w.Expected {
+		t.Errorf("Project() weight range = %v < %v < %v", w.Low, w.Expected, w.High)
+	}
+	if math.Abs(w.Percentile-50) > 5 {
+		t.Errorf("Project() percentile = %v, want about 50", w.Percentile)
+	}
+	if w.Within == nil || !*w.Within || w.ValuePercentile == nil || *w.ValuePercentile >= 50 {
+		t.Errorf("Project() check of 6.8 kg = within %v at %v", w.Within, w.ValuePercentile)
+	}
+	if p.Length == nil || math.Abs(p.Length.Expected-63.8) > 0.3 {
+		t.Errorf("Project() length = %+v", p.Length)
+	}
+}
+
+func TestService_Project_Errors(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := NewService(newMockRepository()).Project(ctx, "child-1", &ProjectionRequest{Date: date}); !errors.Is(err, ErrProjectionUnavailable) {
+		t.Errorf("unconfigured error = %v, want ErrProjectionUnavailable", err)
+	}
+
+	svc, _ := newProjectionService("")
+	if _, err := svc.Project(ctx, "child-1", &ProjectionRequest{Date: date}); !errors.Is(err, ErrUnknownSex) {
+		t.Errorf("no gender error = %v, want ErrUnknownSex", err)
+	}
+
+	svc, _ = newProjectionService("female")
+	if _, err := svc.Project(ctx, "child-9", &ProjectionRequest{Date: date}); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("unknown child error = %v, want ErrChildNotFound", err)
+	}
+	if _, err := svc.Project(ctx, "child-1", &ProjectionRequest{Date: date}); !errors.Is(err, ErrNoTrack) {
+		t.Errorf("no measurements error = %v, want ErrNoTrack", err)
+	}
+	if _, err := svc.Project(ctx, "child-1", &ProjectionRequest{Date: date.AddDate(3, 0, 0)}); !errors.Is(err, ErrOutsideStandards) {
+		t.Errorf("three years old error = %v, want ErrOutsideStandards", err)
+	}
+}
+
+func TestService_Chart(t *testing.T) {
+	svc, repo := newProjectionService("male")
+	repo.measurements["m2"] = &Measurement{ID: "m2", ChildID: "child-1",
+		MeasuredAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), WeightKg: ptr(5.56), HeadCircumferenceCm: ptr(39.1)}
+	repo.measurements["m1"] = &Measurement{ID: "m1", ChildID: "child-1",
+		MeasuredAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), LengthCm: ptr(49.9)}
+	repo.measurements["m3"] = &Measurement{ID: "m3", ChildID: "child-1",
+		MeasuredAt: time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC), WeightKg: ptr(13.5)}
+
+	chart, err := svc.Chart(context.Background(), "child-1")
+	if err != nil {
+		t.Fatalf("Chart() error = %v", err)
+	}
+
+	// WHO head circumference-for-age, boys at birth: median 34.5 cm, 3rd
+	// percentile 32.1 cm, 97th 36.9 cm
+	lines := chart.HeadCircumference.Lines
+	if len(lines) != 5 || len(lines[0].Values) != 25 {
+		t.Fatalf("Chart() head circumference lines = %+v", lines)
+	}
+	for _, tt := range []struct {
+		line int
+		want float64
+	}{{0, 32.1}, {2, 34.5}, {4, 36.9}} {
+		if got := lines[tt.line].Values[0].Value; got != tt.want {
+			t.Errorf("P%v at birth = %v, want %v", lines[tt.line].Percentile, got, tt.want)
+		}
+	}
+
+	weight := chart.Weight.Points
+	if len(weight) != 2 || weight[0].MeasurementID != "m2" || weight[1].MeasurementID != "m3" {
+		t.Fatalf("Chart() weight points = %+v, want m2 then m3", weight)
+	}
+	if weight[0].Percentile == nil || math.Abs(*weight[0].Percentile-50) > 5 || weight[0].AgeDays != 60 {
+		t.Errorf("Chart() two month weight = %+v", weight[0])
+	}
+	if weight[1].ZScore != nil || weight[1].Percentile != nil {
+		t.Error("Chart() should leave points past 24 months unplaced")
+	}
+	if head := chart.HeadCircumference.Points; len(head) != 1 || head[0].Percentile == nil {
+		t.Errorf("Chart() head circumference points = %+v", head)
+	}
+	if length := chart.Length.Points; len(length) != 1 || math.Abs(*length[0].ZScore) > 0.1 {
+		t.Errorf("Chart() length points = %+v, want one on the median", length)
+	}
+}
+
+func TestService_Chart_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := NewService(newMockRepository()).Chart(ctx, "child-1"); !errors.Is(err, ErrPercentilesUnavailable) {
+		t.Errorf("unconfigured error = %v, want ErrPercentilesUnavailable", err)
+	}
+	svc, _ := newProjectionService("")
+	if _, err := svc.Chart(ctx, "child-1"); !errors.Is(err, ErrUnknownSex) {
+		t.Errorf("no gender error = %v, want ErrUnknownSex", err)
+	}
+	if _, err := svc.Chart(ctx, "child-9"); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("unknown child error = %v, want ErrChildNotFound", err)
+	}
+}
+
+func TestService_Create_StampsPercentiles(t *testing.T) {
+	svc, _ := newProjectionService("male")
+
+	m, err := svc.Create(context.Background(), &CreateMeasurementRequest{
+		ChildID: "child-1", MeasuredAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), WeightKg: ptr(3.35),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if m.PercentileReference != ReferenceVersion || m.WeightPercentile == nil || math.Abs(*m.WeightPercentile-50) > 1 {
+		t.Errorf("Create() percentiles = %v at %q, want about 50 at %q", m.WeightPercentile, m.PercentileReference, ReferenceVersion)
+	}
+	if m.LengthPercentile != nil {
+		t.Errorf("Create() length percentile = %v, want none without a length", *m.LengthPercentile)
+	}
+}
+
+func TestService_RecomputePercentiles(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newProjectionService("female")
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	repo.measurements["old"] = &Measurement{ID: "old", ChildID: "child-1", MeasuredAt: at,
+		WeightKg: ptr(5.1), PercentileReference: "who-test", WeightPercentile: ptr(1)}
+	repo.measurements["new"] = &Measurement{ID: "new", ChildID: "child-1", MeasuredAt: at,
+		LengthCm: ptr(57.1), PercentileReference: ReferenceVersion}
+	repo.measurements["orphan"] = &Measurement{ID: "orphan", ChildID: "child-2", MeasuredAt: at, WeightKg: ptr(5)}
+	repo.measurements["head"] = &Measurement{ID: "head", ChildID: "child-1", MeasuredAt: at, HeadCircumferenceCm: ptr(39)}
+
+	status, err := svc.PercentileStatus(ctx)
+	if err != nil || status.Stale != 2 || status.Reference != ReferenceVersion {
+		t.Fatalf("PercentileStatus() = %+v, %v; want 2 stale", status, err)
+	}
+
+	n, err := svc.RecomputePercentiles(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("RecomputePercentiles() = %d, %v; want 2", n, err)
+	}
+	// Two months' median for girls is about 5.1 kg
+	old := repo.measurements["old"]
+	if old.PercentileReference != ReferenceVersion || old.WeightPercentile == nil || math.Abs(*old.WeightPercentile-50) > 5 {
+		t.Errorf("recomputed = %v at %q", old.WeightPercentile, old.PercentileReference)
+	}
+	// An unknown child is stamped too, so it is not retried every run
+	if orphan := repo.measurements["orphan"]; orphan.PercentileReference != ReferenceVersion || orphan.WeightPercentile != nil {
+		t.Errorf("orphan = %v at %q", orphan.WeightPercentile, orphan.PercentileReference)
+	}
+	if status, _ := svc.PercentileStatus(ctx); status.Stale != 0 {
+		t.Errorf("PercentileStatus() stale = %d after recompute, want 0", status.Stale)
+	}
+}
+
+func TestService_RecomputePercentiles_Errors(t *testing.T) {
+	ctx := context.Background()
+	if _, err := NewService(newMockRepository()).RecomputePercentiles(ctx); !errors.Is(err, ErrPercentilesUnavailable) {
+		t.Errorf("unconfigured error = %v, want ErrPercentilesUnavailable", err)
+	}
+
+	svc, _ := newProjectionService("male")
+	svc.(*service).recomputing.Store(true)
+	if _, err := svc.RecomputePercentiles(ctx); !errors.Is(err, ErrRecomputeRunning) {
+		t.Errorf("concurrent error = %v, want ErrRecomputeRunning", err)
+	}
+}
@@ -0,0 +1,33 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	registry *Registry
+}
+
+func NewHandler(registry *Registry) *Handler {
+	return &Handler{registry: registry}
+}
+
+// RegisterRoutes registers the readiness probe
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/readyz", h.ready)
+}
+
+// ready answers 503 when any module is down, so load balancers stop sending
+// traffic. A degraded module still serves requests, so it answers 200.
+func (h *Handler) ready(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+
+	code := http.StatusOK
+	if report.Status == StatusDown {
+		code = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(code, report)
+}
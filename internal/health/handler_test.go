@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func serveReadyz(r *Registry) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(apispectest.Record(""))
+	NewHandler(r).RegisterRoutes(router.Group(""))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	return w
+}
+
+func TestHandler_Ready(t *testing.T) {
+	r := NewRegistry()
+	r.Register("family", checkFunc(func(context.Context) error { return nil }))
+	r.Register("media", checkFunc(func(context.Context) error { return Degraded("backlog") }))
+
+	w := serveReadyz(r)
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 while only degraded", w.Code)
+	}
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.Status != StatusDegraded || len(report.Modules) != 2 || report.Modules[1].Status != StatusDegraded {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestHandler_Ready_Down(t *testing.T) {
+	r := NewRegistry()
+	r.Register("auth", checkFunc(func(context.Context) error {
+		return errors.New("pq: password authentication failed for user babytrack")
+	}))
+
+	w := serveReadyz(r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+	if body := w.Body.String(); strings.Contains(body, "password") {
+		t.Errorf("body = %s, want the error kept out of the response", body)
+	}
+}
@@ -0,0 +1,87 @@
+// Package health checks that each module can reach what it depends on, so
+// /readyz can name the subsystem in trouble rather than answer with a single
+// yes or no. A module takes part by giving its service a HealthCheck method:
+// most read from their tables, and async ones also report how deep their
+// queue is.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Checker is implemented by services, repositories and queues that can tell
+// whether they are working
+type Checker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Of runs v's health check, if it has one. Anything without a check, such as
+// a test double, counts as healthy.
+func Of(ctx context.Context, v any) error {
+	if c, ok := v.(Checker); ok {
+		return c.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// degradedError marks a module that works but is falling behind
+type degradedError struct {
+	reason string
+}
+
+func (e *degradedError) Error() string {
+	return e.reason
+}
+
+// Degraded returns an error for a module that still works but needs
+// attention, such as a queue filling up. Any other error means it is down.
+func Degraded(format string, args ...any) error {
+	return &degradedError{reason: fmt.Sprintf(format, args...)}
+}
+
+// IsDegraded reports whether err came from Degraded. Errors joined with
+// errors.Join are degraded only if all of them are, since a module with one
+// failed dependency is down.
+func IsDegraded(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		for _, e := range errs {
+			if !IsDegraded(e) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	var d *degradedError
+	return errors.As(err, &d)
+}
+
+// Querier runs single-row queries. *sql.DB satisfies it.
+type Querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Tables checks that one row can be read from each table. An empty table is
+// fine; a missing table, a lost connection or a revoked grant is not.
+func Tables(ctx context.Context, q Querier, tables ...string) error {
+	for _, table := range tables {
+		var one int
+		err := q.QueryRowContext(ctx, `SELECT 1 FROM `+table+` LIMIT 1`).Scan(&one)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// Queue reports a queue as degraded once it is three quarters full, while
+// there is still room to catch up before work is dropped
+func Queue(depth, capacity int) error {
+	if capacity > 0 && depth*4 >= capacity*3 {
+		return Degraded("queue at %d of %d", depth, capacity)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestTables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT 1 FROM feedings LIMIT 1`).
+		WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+	mock.ExpectQuery(`SELECT 1 FROM feeding_goals LIMIT 1`).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`SELECT 1 FROM nursing_timers LIMIT 1`).
+		WillReturnError(errors.New(`relation "nursing_timers" does not exist`))
+
+	err = Tables(context.Background(), db, "feedings", "feeding_goals", "nursing_timers")
+	if err == nil || err.Error() != `nursing_timers: relation "nursing_timers" does not exist` {
+		t.Errorf("Tables() error = %v, want the missing table named", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestIsDegraded(t *testing.T) {
+	down := errors.New("connection refused")
+	slow := Degraded("queue at %d of %d", 80, 100)
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"down", down, false},
+		{"degraded", slow, true},
+		{"wrapped", fmt.Errorf("media: %w", slow), true},
+		{"joined degraded", errors.Join(nil, slow, Degraded("backlog")), true},
+		{"joined with down", errors.Join(slow, down), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDegraded(tt.err); got != tt.want {
+				t.Errorf("IsDegraded(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueue(t *testing.T) {
+	if err := Queue(74, 100); err != nil {
+		t.Errorf("Queue(74, 100) = %v, want nil", err)
+	}
+	if err := Queue(75, 100); !IsDegraded(err) {
+		t.Errorf("Queue(75, 100) = %v, want degraded", err)
+	}
+	if err := Queue(0, 0); err != nil {
+		t.Errorf("Queue(0, 0) = %v, want nil for an unbuffered queue", err)
+	}
+}
+
+func TestOf(t *testing.T) {
+	if err := Of(context.Background(), struct{}{}); err != nil {
+		t.Errorf("Of() = %v, want nil for something without a check", err)
+	}
+	want := errors.New("down")
+	if err := Of(context.Background(), checkFunc(func(context.Context) error { return want })); err != want {
+		t.Errorf("Of() = %v, want %v", err, want)
+	}
+}
+
+type checkFunc func(ctx context.Context) error
+
+func (f checkFunc) HealthCheck(ctx context.Context) error {
+	return f(ctx)
+}
@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// checkTimeout bounds each module's check, so one hung dependency
+	// cannot hold up the whole report
+	checkTimeout = 2 * time.Second
+	// cacheFor bounds how often probes reach the database; /readyz is not
+	// authenticated
+	cacheFor = 5 * time.Second
+)
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Module is the result of one module's check. Errors are logged rather than
+// returned, since they can name hosts and database users.
+type Module struct {
+	Name      string `json:"name"`
+	Status    Status `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// Report is the state of every registered module. Its status is the worst of
+// theirs.
+type Report struct {
+	Status    Status    `json:"status"`
+	Modules   []Module  `json:"modules"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type check struct {
+	name    string
+	checker Checker
+}
+
+// Registry runs the health checks of the modules registered with it
+type Registry struct {
+	checks  []check
+	timeout time.Duration
+	now     func() time.Time
+
+	mu     sync.Mutex
+	cached *Report
+}
+
+func NewRegistry() *Registry {
+	return &Registry{timeout: checkTimeout, now: time.Now}
+}
+
+// Register adds a module to the report, in the order registered. Services
+// are usually held as interfaces without HealthCheck, so v is checked here;
+// one without a health check is a wiring mistake and panics at startup.
+func (r *Registry) Register(name string, v any) {
+	c, ok := v.(Checker)
+	if !ok {
+		panic(fmt.Sprintf("health: %s (%T) has no HealthCheck method", name, v))
+	}
+	r.checks = append(r.checks, check{name: name, checker: c})
+}
+
+// Check runs every module's check at once and gathers the results, reusing a
+// recent report so frequent probes do not each reach the database
+func (r *Registry) Check(ctx context.Context) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if r.cached != nil && now.Sub(r.cached.CheckedAt) < cacheFor {
+		return r.cached
+	}
+
+	report := &Report{Status: StatusOK, Modules: make([]Module, len(r.checks)), CheckedAt: now}
+	var wg sync.WaitGroup
+	for i, c := range r.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Modules[i] = r.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for _, m := range report.Modules {
+		switch {
+		case m.Status == StatusDown:
+			report.Status = StatusDown
+		case m.Status == StatusDegraded && report.Status == StatusOK:
+			report.Status = StatusDegraded
+		}
+	}
+	r.cached = report
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c check) Module {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := r.now()
+	err := c.checker.HealthCheck(ctx)
+	m := Module{Name: c.name, Status: StatusOK, LatencyMS: r.now().Sub(start).Milliseconds()}
+	switch {
+	case err == nil:
+	case IsDegraded(err):
+		m.Status = StatusDegraded
+		log.Printf("[readyz] %s degraded: %v", c.name, err)
+	default:
+		m.Status = StatusDown
+		log.Printf("[readyz] %s down: %v", c.name, err)
+	}
+	return m
+}
@@ -0,0 +1,92 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestRegistry(now *time.Time) *Registry {
+	r := NewRegistry()
+	r.now = func() time.Time { return *now }
+	return r
+}
+
+func TestRegistry_Check(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := newTestRegistry(&now)
+	r.Register("family", checkFunc(func(context.Context) error { return nil }))
+	r.Register("media", checkFunc(func(context.Context) error { return Degraded("120 uploads waiting for a scan") }))
+
+	report := r.Check(context.Background())
+	if report.Status != StatusDegraded {
+		t.Errorf("Status = %s, want degraded", report.Status)
+	}
+	want := []Module{{Name: "family", Status: StatusOK}, {Name: "media", Status: StatusDegraded}}
+	if len(report.Modules) != len(want) {
+		t.Fatalf("Modules = %+v, want %+v", report.Modules, want)
+	}
+	for i, m := range report.Modules {
+		if m != want[i] {
+			t.Errorf("Modules[%d] = %+v, want %+v", i, m, want[i])
+		}
+	}
+}
+
+func TestRegistry_Check_Down(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := newTestRegistry(&now)
+	r.Register("media", checkFunc(func(context.Context) error { return Degraded("backlog") }))
+	r.Register("sleep", checkFunc(func(context.Context) error { return errors.New("connection refused") }))
+
+	if report := r.Check(context.Background()); report.Status != StatusDown || report.Modules[1].Status != StatusDown {
+		t.Errorf("Check() = %+v, want sleep down", report)
+	}
+}
+
+func TestRegistry_Check_Timeout(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := newTestRegistry(&now)
+	r.timeout = 10 * time.Millisecond
+	r.Register("stuck", checkFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	if report := r.Check(context.Background()); report.Status != StatusDown {
+		t.Errorf("Status = %s, want down once the check times out", report.Status)
+	}
+}
+
+func TestRegistry_Check_Cached(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := newTestRegistry(&now)
+	calls := 0
+	r.Register("family", checkFunc(func(context.Context) error {
+		calls++
+		return nil
+	}))
+
+	r.Check(context.Background())
+	now = now.Add(cacheFor - time.Second)
+	r.Check(context.Background())
+	if calls != 1 {
+		t.Errorf("checks run = %d, want 1 within the cache window", calls)
+	}
+
+	now = now.Add(time.Second)
+	r.Check(context.Background())
+	if calls != 2 {
+		t.Errorf("checks run = %d, want 2 once the cache expires", calls)
+	}
+}
+
+func TestRegistry_Register_WithoutCheck(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() did not panic for a module without a health check")
+		}
+	}()
+	NewRegistry().Register("family", struct{}{})
+}
@@ -0,0 +1,78 @@
+package healthinfo
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterFamilyRoutes registers the health info on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/health-info", h.get)
+	rg.PUT("/:familyId/health-info", h.update)
+}
+
+// RegisterChildRoutes registers read access through a child, for views that
+// start from one child such as appointment booking
+func (h *Handler) RegisterChildRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id/health-info", h.getForChild)
+}
+
+func (h *Handler) get(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	info, err := h.service.Get(c.Request.Context(), userID, c.Param("familyId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+func (h *Handler) getForChild(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	info, err := h.service.GetForChild(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+func (h *Handler) update(c *gin.Context) {
+	var req UpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	info, err := h.service.Update(c.Request.Context(), userID, c.Param("familyId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, ErrNotAdmin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
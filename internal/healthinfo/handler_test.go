@@ -0,0 +1,108 @@
+package healthinfo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	getFn         func(ctx context.Context, userID, familyID string) (*HealthInfo, error)
+	getForChildFn func(ctx context.Context, userID, childID string) (*HealthInfo, error)
+	updateFn      func(ctx context.Context, userID, familyID string, req *UpdateRequest) (*HealthInfo, error)
+}
+
+func (m *mockService) Get(ctx context.Context, userID, familyID string) (*HealthInfo, error) {
+	return m.getFn(ctx, userID, familyID)
+}
+
+func (m *mockService) GetForChild(ctx context.Context, userID, childID string) (*HealthInfo, error) {
+	return m.getForChildFn(ctx, userID, childID)
+}
+
+func (m *mockService) Update(ctx context.Context, userID, familyID string, req *UpdateRequest) (*HealthInfo, error) {
+	return m.updateFn(ctx, userID, familyID, req)
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	h := NewHandler(svc)
+	h.RegisterFamilyRoutes(router.Group("/families"))
+	h.RegisterChildRoutes(router.Group("/children"))
+	return router
+}
+
+func TestHandler_GetForChild(t *testing.T) {
+	svc := &mockService{getForChildFn: func(ctx context.Context, userID, childID string) (*HealthInfo, error) {
+		if userID != "test-user-123" || childID != "child-1" {
+			t.Errorf("GetForChild() called with %s, %s", userID, childID)
+		}
+		return &HealthInfo{FamilyID: "family-1", Pharmacy: &Pharmacy{Name: "Corner Pharmacy"}}, nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("GET", "/children/child-1/health-info", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte("Corner Pharmacy")) {
+		t.Errorf("Expected the pharmacy in the body, got %s", w.Body.String())
+	}
+}
+
+func TestHandler_Update(t *testing.T) {
+	svc := &mockService{updateFn: func(ctx context.Context, userID, familyID string, req *UpdateRequest) (*HealthInfo, error) {
+		if req.Insurance == nil || req.Insurance.PolicyNumber != "POL-1" || req.Pharmacy != nil {
+			t.Errorf("Update() called with %+v", req)
+		}
+		return &HealthInfo{FamilyID: familyID, Insurance: req.Insurance}, nil
+	}}
+
+	body := `{"insurance":{"provider":"Acme Health","policy_number":"POL-1"},"pharmacy":null}`
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("PUT", "/families/family-1/health-info", bytes.NewReader([]byte(body))))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandler_Update_MissingPolicyNumber(t *testing.T) {
+	body := `{"insurance":{"provider":"Acme Health"}}`
+	w := httptest.NewRecorder()
+	setupRouter(&mockService{}).ServeHTTP(w, httptest.NewRequest("PUT", "/families/family-1/health-info", bytes.NewReader([]byte(body))))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandler_Update_NotAdmin(t *testing.T) {
+	svc := &mockService{updateFn: func(ctx context.Context, userID, familyID string, req *UpdateRequest) (*HealthInfo, error) {
+		return nil, ErrNotAdmin
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("PUT", "/families/family-1/health-info", bytes.NewReader([]byte(`{}`))))
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,43 @@
+package healthinfo
+
+import "time"
+
+type Insurance struct {
+	Provider     string `json:"provider" binding:"required"`
+	PlanName     string `json:"plan_name,omitempty"`
+	PolicyNumber string `json:"policy_number" binding:"required"`
+	GroupNumber  string `json:"group_number,omitempty"`
+	PolicyHolder string `json:"policy_holder,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+}
+
+type Pharmacy struct {
+	Name    string `json:"name" binding:"required"`
+	Phone   string `json:"phone,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// HealthInfo is the family's insurance policy and preferred pharmacy, either
+// of which may be unset
+type HealthInfo struct {
+	FamilyID  string     `json:"family_id"`
+	Insurance *Insurance `json:"insurance"`
+	Pharmacy  *Pharmacy  `json:"pharmacy"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// UpdateRequest replaces both sections; a null section clears it
+type UpdateRequest struct {
+	Insurance *Insurance `json:"insurance"`
+	Pharmacy  *Pharmacy  `json:"pharmacy"`
+}
+
+// Record is HealthInfo as stored, with each section sealed
+type Record struct {
+	FamilyID  string
+	Insurance []byte
+	Pharmacy  []byte
+	UpdatedBy string
+	UpdatedAt time.Time
+}
@@ -0,0 +1,74 @@
+package healthinfo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Get(ctx context.Context, familyID string) (*Record, error)
+	Upsert(ctx context.Context, r *Record) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the health info table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "family_health_info")
+}
+
+func (r *repository) Get(ctx context.Context, familyID string) (*Record, error) {
+	query := `
+		SELECT family_id, insurance, pharmacy, COALESCE(updated_by, ''), updated_at
+		FROM family_health_info
+		WHERE family_id = $1
+	`
+
+	var rec Record
+	err := r.db.QueryRowContext(ctx, query, familyID).Scan(
+		&rec.FamilyID, &rec.Insurance, &rec.Pharmacy, &rec.UpdatedBy, &rec.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *repository) Upsert(ctx context.Context, rec *Record) error {
+	query := `
+		INSERT INTO family_health_info (family_id, insurance, pharmacy, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (family_id) DO UPDATE
+		SET insurance = EXCLUDED.insurance, pharmacy = EXCLUDED.pharmacy,
+			updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		rec.FamilyID, nullBytes(rec.Insurance), nullBytes(rec.Pharmacy), nullString(rec.UpdatedBy), rec.UpdatedAt,
+	)
+	return err
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullBytes stores an unset section as NULL rather than an empty bytea
+func nullBytes(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	return b
+}
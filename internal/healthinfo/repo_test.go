@@ -0,0 +1,72 @@
+package healthinfo
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_Get(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT family_id, insurance, pharmacy").
+		WithArgs("family-1").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id", "insurance", "pharmacy", "updated_by", "updated_at"}).
+			AddRow("family-1", []byte("sealed"), nil, "user-1", now))
+
+	rec, err := repo.Get(context.Background(), "family-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(rec.Insurance) != "sealed" || rec.Pharmacy != nil || rec.UpdatedBy != "user-1" {
+		t.Errorf("Get() = %+v", rec)
+	}
+}
+
+func TestRepository_Get_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT family_id, insurance, pharmacy").
+		WithArgs("family-1").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id", "insurance", "pharmacy", "updated_by", "updated_at"}))
+
+	rec, err := repo.Get(context.Background(), "family-1")
+	if err != nil || rec != nil {
+		t.Errorf("Get() = %v, %v; want nil, nil", rec, err)
+	}
+}
+
+func TestRepository_Upsert(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	rec := &Record{FamilyID: "family-1", Pharmacy: []byte("sealed"), UpdatedBy: "user-1", UpdatedAt: time.Now()}
+	mock.ExpectExec("INSERT INTO family_health_info .* ON CONFLICT \\(family_id\\) DO UPDATE").
+		WithArgs("family-1", nil, []byte("sealed"), "user-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.Upsert(context.Background(), rec); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
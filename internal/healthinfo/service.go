@@ -0,0 +1,161 @@
+// Package healthinfo keeps each family's insurance policy and preferred
+// pharmacy. Both are sealed before they are stored.
+package healthinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/sealed"
+)
+
+var (
+	ErrChildNotFound = errors.New("child not found")
+	ErrNotMember     = errors.New("user is not a member of this family")
+	ErrNotAdmin      = errors.New("only admins can change insurance and pharmacy details")
+)
+
+type Service interface {
+	Get(ctx context.Context, userID, familyID string) (*HealthInfo, error)
+	// GetForChild returns the health info of the child's family, for
+	// appointment booking and the emergency card
+	GetForChild(ctx context.Context, userID, childID string) (*HealthInfo, error)
+	Update(ctx context.Context, userID, familyID string, req *UpdateRequest) (*HealthInfo, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	box           *sealed.Box
+	now           func() time.Time
+}
+
+func NewService(repo Repository, familyService family.Service, box *sealed.Box) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+		box:           box,
+		now:           time.Now,
+	}
+}
+
+// HealthCheck reports whether insurance and pharmacy details can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Get(ctx context.Context, userID, familyID string) (*HealthInfo, error) {
+	if _, err := s.memberRole(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+	return s.load(ctx, familyID)
+}
+
+func (s *service) GetForChild(ctx context.Context, userID, childID string) (*HealthInfo, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+	return s.Get(ctx, userID, child.FamilyID)
+}
+
+func (s *service) Update(ctx context.Context, userID, familyID string, req *UpdateRequest) (*HealthInfo, error) {
+	role, err := s.memberRole(ctx, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "admin" {
+		return nil, ErrNotAdmin
+	}
+
+	rec := &Record{FamilyID: familyID, UpdatedBy: userID, UpdatedAt: s.now()}
+	if req.Insurance != nil {
+		if rec.Insurance, err = s.seal(req.Insurance, familyID, "insurance"); err != nil {
+			return nil, err
+		}
+	}
+	if req.Pharmacy != nil {
+		if rec.Pharmacy, err = s.seal(req.Pharmacy, familyID, "pharmacy"); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Upsert(ctx, rec); err != nil {
+		return nil, fmt.Errorf("failed to save health info: %w", err)
+	}
+
+	return &HealthInfo{
+		FamilyID:  familyID,
+		Insurance: req.Insurance,
+		Pharmacy:  req.Pharmacy,
+		UpdatedBy: userID,
+		UpdatedAt: &rec.UpdatedAt,
+	}, nil
+}
+
+// load returns the family's health info, empty when nothing has been saved
+func (s *service) load(ctx context.Context, familyID string) (*HealthInfo, error) {
+	rec, err := s.repo.Get(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get health info: %w", err)
+	}
+
+	info := &HealthInfo{FamilyID: familyID}
+	if rec == nil {
+		return info, nil
+	}
+
+	info.UpdatedBy = rec.UpdatedBy
+	info.UpdatedAt = &rec.UpdatedAt
+	if err := s.open(rec.Insurance, familyID, "insurance", &info.Insurance); err != nil {
+		return nil, err
+	}
+	if err := s.open(rec.Pharmacy, familyID, "pharmacy", &info.Pharmacy); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// seal encrypts one section bound to its family and name, so sections cannot
+// be moved between families or swapped with each other
+func (s *service) seal(section any, familyID, name string) ([]byte, error) {
+	plaintext, err := json.Marshal(section)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return s.box.Seal(plaintext, familyID+"/"+name), nil
+}
+
+// open decrypts a section stored by seal into dest, leaving dest nil when the
+// section is unset
+func (s *service) open(stored []byte, familyID, name string, dest any) error {
+	if stored == nil {
+		return nil
+	}
+	plaintext, err := s.box.Open(stored, familyID+"/"+name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	if err := json.Unmarshal(plaintext, dest); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *service) memberRole(ctx context.Context, familyID, userID string) (string, error) {
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return "", ErrNotMember
+		}
+		return "", err
+	}
+	return role, nil
+}
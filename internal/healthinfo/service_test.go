@@ -0,0 +1,150 @@
+package healthinfo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/sealed"
+)
+
+type mockRepository struct {
+	records map[string]*Record
+}
+
+func (m *mockRepository) Get(ctx context.Context, familyID string) (*Record, error) {
+	return m.records[familyID], nil
+}
+
+func (m *mockRepository) Upsert(ctx context.Context, r *Record) error {
+	stored := *r
+	m.records[r.FamilyID] = &stored
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" {
+		return &family.Child{ID: "child-1", FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	switch {
+	case userID == "admin-1":
+		return "admin", nil
+	case userID == "user-1" && familyID == "family-1":
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+func newTestService(t *testing.T) (*service, *mockRepository) {
+	t.Helper()
+	box, err := sealed.NewBox(bytes.Repeat([]byte{1}, sealed.KeySize))
+	if err != nil {
+		t.Fatalf("NewBox() error = %v", err)
+	}
+	repo := &mockRepository{records: map[string]*Record{}}
+	svc := NewService(repo, &mockFamilyService{}, box).(*service)
+	svc.now = func() time.Time { return time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC) }
+	return svc, repo
+}
+
+func TestService_Update_SealsSections(t *testing.T) {
+	svc, repo := newTestService(t)
+
+	req := &UpdateRequest{
+		Insurance: &Insurance{Provider: "Acme Health", PolicyNumber: "POL-778899", GroupNumber: "G-12"},
+		Pharmacy:  &Pharmacy{Name: "Corner Pharmacy", Phone: "555-0100"},
+	}
+	if _, err := svc.Update(context.Background(), "admin-1", "family-1", req); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	rec := repo.records["family-1"]
+	if rec == nil || rec.UpdatedBy != "admin-1" {
+		t.Fatalf("Update() stored %+v", rec)
+	}
+	if bytes.Contains(rec.Insurance, []byte("POL-778899")) || bytes.Contains(rec.Pharmacy, []byte("Corner")) {
+		t.Error("Update() stored a section unsealed")
+	}
+
+	info, err := svc.Get(context.Background(), "user-1", "family-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if info.Insurance == nil || info.Insurance.PolicyNumber != "POL-778899" || info.Pharmacy == nil || info.Pharmacy.Phone != "555-0100" {
+		t.Errorf("Get() = %+v", info)
+	}
+}
+
+func TestService_Update_ClearsSection(t *testing.T) {
+	svc, repo := newTestService(t)
+
+	req := &UpdateRequest{Pharmacy: &Pharmacy{Name: "Corner Pharmacy"}}
+	if _, err := svc.Update(context.Background(), "admin-1", "family-1", req); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if repo.records["family-1"].Insurance != nil {
+		t.Error("Update() should store a null section as NULL")
+	}
+
+	info, err := svc.GetForChild(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("GetForChild() error = %v", err)
+	}
+	if info.Insurance != nil || info.Pharmacy == nil {
+		t.Errorf("GetForChild() = %+v", info)
+	}
+}
+
+func TestService_Get_Empty(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	info, err := svc.Get(context.Background(), "user-1", "family-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if info.FamilyID != "family-1" || info.Insurance != nil || info.Pharmacy != nil || info.UpdatedAt != nil {
+		t.Errorf("Get() before any update = %+v", info)
+	}
+}
+
+func TestService_Get_RejectsMovedSection(t *testing.T) {
+	svc, repo := newTestService(t)
+
+	req := &UpdateRequest{Insurance: &Insurance{Provider: "Acme Health", PolicyNumber: "POL-1"}}
+	if _, err := svc.Update(context.Background(), "admin-1", "family-2", req); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	moved := *repo.records["family-2"]
+	moved.FamilyID = "family-1"
+	repo.records["family-1"] = &moved
+
+	if _, err := svc.Get(context.Background(), "user-1", "family-1"); !errors.Is(err, sealed.ErrTampered) {
+		t.Errorf("Get() error = %v, want ErrTampered", err)
+	}
+}
+
+func TestService_Errors(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.Update(ctx, "user-1", "family-1", &UpdateRequest{}); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("Update() by member error = %v, want ErrNotAdmin", err)
+	}
+	if _, err := svc.Get(ctx, "user-1", "family-2"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Get() by non-member error = %v, want ErrNotMember", err)
+	}
+	if _, err := svc.GetForChild(ctx, "user-1", "child-9"); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("GetForChild() error = %v, want ErrChildNotFound", err)
+	}
+}
@@ -0,0 +1,34 @@
+// Package ids generates the identifiers for every record the server creates.
+//
+// IDs are UUIDv7: the leading 48 bits are the creation time in milliseconds,
+// so IDs sort in creation order as plain strings and records created on
+// different servers or offline clients merge without coordination. Secrets
+// such as API keys and invite codes are not IDs and must keep using
+// crypto/rand directly.
+package ids
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// New returns a new UUIDv7 in its canonical 36-character form
+func New() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// CreatedAt returns the creation time embedded in a UUIDv7. IDs issued before
+// the switch to UUIDv7 carry no time and report false; they are 32 hex
+// characters, which uuid.Parse would otherwise accept.
+func CreatedAt(id string) (time.Time, bool) {
+	if len(id) != 36 {
+		return time.Time{}, false
+	}
+	u, err := uuid.Parse(id)
+	if err != nil || u.Version() != 7 {
+		return time.Time{}, false
+	}
+	sec, nsec := u.Time().UnixTime()
+	return time.Unix(sec, nsec), true
+}
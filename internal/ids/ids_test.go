@@ -0,0 +1,43 @@
+package ids
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNew_SortsByCreation(t *testing.T) {
+	generated := make([]string, 100)
+	for i := range generated {
+		generated[i] = New()
+		if i%10 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	sorted := append([]string(nil), generated...)
+	sort.Strings(sorted)
+	for i := range generated {
+		if sorted[i] != generated[i] {
+			t.Fatalf("IDs out of creation order at %d: %s vs %s", i, sorted[i], generated[i])
+		}
+	}
+}
+
+func TestCreatedAt(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	id := New()
+	after := time.Now()
+
+	got, ok := CreatedAt(id)
+	if !ok {
+		t.Fatalf("CreatedAt(%s) reported no time", id)
+	}
+	if got.Before(before) || got.After(after) {
+		t.Errorf("CreatedAt() = %v, want between %v and %v", got, before, after)
+	}
+
+	if _, ok := CreatedAt("3f2a9c4e8b7d7a5f4e3d2c1b0a998877"); ok {
+		t.Error("CreatedAt() should report false for a legacy hex ID")
+	}
+}
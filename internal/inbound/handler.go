@@ -0,0 +1,51 @@
+package inbound
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterFamilyRoutes registers the inbound address routes on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/inbound-address", h.getAddress)
+	rg.POST("/:familyId/inbound-address/rotate", h.rotateAddress)
+}
+
+func (h *Handler) getAddress(c *gin.Context) {
+	addr, err := h.service.GetAddress(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, addr)
+}
+
+func (h *Handler) rotateAddress(c *gin.Context) {
+	addr, err := h.service.RotateAddress(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, addr)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrDisabled):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, ErrNotAdmin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
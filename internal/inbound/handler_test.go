@@ -0,0 +1,133 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/notes"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	getAddressFn    func(ctx context.Context, userID, familyID string) (*Address, error)
+	rotateAddressFn func(ctx context.Context, userID, familyID string) (*Address, error)
+}
+
+func (m *mockService) GetAddress(ctx context.Context, userID, familyID string) (*Address, error) {
+	if m.getAddressFn != nil {
+		return m.getAddressFn(ctx, userID, familyID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) RotateAddress(ctx context.Context, userID, familyID string) (*Address, error) {
+	if m.rotateAddressFn != nil {
+		return m.rotateAddressFn(ctx, userID, familyID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Ingest(ctx context.Context, r io.Reader) (*notes.Note, error) {
+	return nil, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterFamilyRoutes(router.Group("/families"))
+	return router
+}
+
+func TestGetAddress_Success(t *testing.T) {
+	var capturedUser, capturedFamily string
+	svc := &mockService{
+		getAddressFn: func(ctx context.Context, userID, familyID string) (*Address, error) {
+			capturedUser, capturedFamily = userID, familyID
+			return &Address{FamilyID: familyID, Email: "tok@inbox.test", Token: "tok"}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/families/family-1/inbound-address", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if capturedUser != "test-user-123" || capturedFamily != "family-1" {
+		t.Errorf("Expected test-user-123/family-1, got %s/%s", capturedUser, capturedFamily)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if result["email"] != "tok@inbox.test" {
+		t.Errorf("Expected email tok@inbox.test, got %v", result["email"])
+	}
+	if _, ok := result["token"]; ok {
+		t.Error("Token should not be serialised separately")
+	}
+}
+
+func TestGetAddress_ErrorStatuses(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrDisabled, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			svc := &mockService{
+				getAddressFn: func(ctx context.Context, userID, familyID string) (*Address, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("GET", "/families/family-1/inbound-address", http.NoBody)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRotateAddress_NotAdmin(t *testing.T) {
+	svc := &mockService{
+		rotateAddressFn: func(ctx context.Context, userID, familyID string) (*Address, error) {
+			return nil, ErrNotAdmin
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/families/family-1/inbound-address/rotate", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,27 @@
+package inbound
+
+import "time"
+
+// Address is a family's inbound email address. Mail sent to it by a family
+// member becomes a note; the local part is the secret that routes it.
+type Address struct {
+	FamilyID  string    `json:"family_id"`
+	Email     string    `json:"email"`
+	Token     string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Message is the part of an email that is filed as a note
+type Message struct {
+	From        string
+	To          []string
+	Subject     string
+	Body        string
+	Date        *time.Time
+	Attachments []Attachment
+}
+
+type Attachment struct {
+	Filename string
+	Data     []byte
+}
@@ -0,0 +1,162 @@
+package inbound
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+)
+
+// MaxMessageBytes bounds how much of one email is read
+const MaxMessageBytes = 25 << 20
+
+var ErrMalformed = errors.New("malformed email")
+
+var (
+	htmlBlocks = regexp.MustCompile(`(?is)<(style|script)[^>]*>.*?</(style|script)>`)
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6])>`)
+	htmlTags   = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines = regexp.MustCompile(`\n\s*\n\s*\n+`)
+)
+
+var wordDecoder = new(mime.WordDecoder)
+
+// ParseMessage reads an RFC 5322 message. The first text/plain part is the
+// body, falling back to the HTML part with its markup stripped; parts with a
+// filename, and any other non-text parts, are attachments.
+func ParseMessage(r io.Reader) (*Message, error) {
+	m, err := mail.ReadMessage(io.LimitReader(r, MaxMessageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	from, err := mail.ParseAddress(m.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid From: %v", ErrMalformed, err)
+	}
+
+	msg := &Message{From: strings.ToLower(from.Address)}
+
+	for _, field := range []string{"To", "Cc", "Delivered-To", "X-Original-To"} {
+		list, err := mail.ParseAddressList(m.Header.Get(field))
+		if err != nil {
+			continue
+		}
+		for _, a := range list {
+			msg.To = append(msg.To, strings.ToLower(a.Address))
+		}
+	}
+
+	subject := m.Header.Get("Subject")
+	if decoded, err := wordDecoder.DecodeHeader(subject); err == nil {
+		subject = decoded
+	}
+	msg.Subject = strings.TrimSpace(subject)
+
+	if date, err := m.Header.Date(); err == nil {
+		msg.Date = &date
+	}
+
+	var htmlBody string
+	if err := readPart(msg, &htmlBody, textproto.MIMEHeader(m.Header), m.Body); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(msg.Body) == "" && htmlBody != "" {
+		msg.Body = stripHTML(htmlBody)
+	}
+	msg.Body = strings.TrimSpace(strings.ReplaceAll(msg.Body, "\r\n", "\n"))
+
+	return msg, nil
+}
+
+func readPart(msg *Message, htmlBody *string, header textproto.MIMEHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrMalformed, err)
+			}
+			if err := readPart(msg, htmlBody, part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransfer(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	filename := attachmentName(header, params)
+	if filename != "" || !strings.HasPrefix(mediaType, "text/") {
+		if filename == "" {
+			filename = "attachment"
+		}
+		msg.Attachments = append(msg.Attachments, Attachment{Filename: filename, Data: data})
+		return nil
+	}
+
+	switch mediaType {
+	case "text/plain":
+		if msg.Body == "" {
+			msg.Body = string(data)
+		}
+	case "text/html":
+		if *htmlBody == "" {
+			*htmlBody = string(data)
+		}
+	}
+	return nil
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+func attachmentName(header textproto.MIMEHeader, typeParams map[string]string) string {
+	name := typeParams["name"]
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		name = params["filename"]
+	}
+	if decoded, err := wordDecoder.DecodeHeader(name); err == nil {
+		name = decoded
+	}
+	return strings.TrimSpace(name)
+}
+
+func stripHTML(s string) string {
+	s = htmlBlocks.ReplaceAllString(s, "")
+	s = htmlBreaks.ReplaceAllString(s, "\n")
+	s = htmlTags.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
@@ -0,0 +1,106 @@
+package inbound
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseMessage_Plain(t *testing.T) {
+	raw := "From: Sam Parent <Sam@Example.com>\r\n" +
+		"To: abc123+emma@inbox.test\r\n" +
+		"Cc: other@example.com\r\n" +
+		"Subject: =?UTF-8?Q?Daycare_=E2=80=93_lunch?=\r\n" +
+		"Date: Mon, 2 Mar 2026 12:30:00 +0000\r\n" +
+		"\r\n" +
+		"Ate all her lunch.\r\n"
+
+	msg, err := ParseMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	if msg.From != "sam@example.com" {
+		t.Errorf("From = %q, want sam@example.com", msg.From)
+	}
+	if len(msg.To) != 2 || msg.To[0] != "abc123+emma@inbox.test" {
+		t.Errorf("To = %v, want the inbound address first", msg.To)
+	}
+	if msg.Subject != "Daycare – lunch" {
+		t.Errorf("Subject = %q, want decoded subject", msg.Subject)
+	}
+	if msg.Body != "Ate all her lunch." {
+		t.Errorf("Body = %q", msg.Body)
+	}
+	if msg.Date == nil || msg.Date.Hour() != 12 {
+		t.Errorf("Date = %v, want 12:30", msg.Date)
+	}
+}
+
+func TestParseMessage_MultipartWithAttachment(t *testing.T) {
+	raw := "From: sam@example.com\r\n" +
+		"To: abc123@inbox.test\r\n" +
+		"Subject: Rash photo\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=outer\r\n" +
+		"\r\n" +
+		"--outer\r\n" +
+		"Content-Type: multipart/alternative; boundary=inner\r\n" +
+		"\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"Looks =\r\nbetter today.\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/html\r\n" +
+		"\r\n" +
+		"<p>Looks better today.</p>\r\n" +
+		"--inner--\r\n" +
+		"--outer\r\n" +
+		"Content-Type: image/png\r\n" +
+		"Content-Disposition: attachment; filename=\"rash.png\"\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"iVBORw0KGgo=\r\n" +
+		"--outer--\r\n"
+
+	msg, err := ParseMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	if msg.Body != "Looks better today." {
+		t.Errorf("Body = %q, want the text part", msg.Body)
+	}
+	if len(msg.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(msg.Attachments))
+	}
+	if msg.Attachments[0].Filename != "rash.png" {
+		t.Errorf("Filename = %q, want rash.png", msg.Attachments[0].Filename)
+	}
+	if string(msg.Attachments[0].Data[1:4]) != "PNG" {
+		t.Errorf("attachment was not base64-decoded: %q", msg.Attachments[0].Data)
+	}
+}
+
+func TestParseMessage_HTMLOnly(t *testing.T) {
+	raw := "From: sam@example.com\r\n" +
+		"To: abc123@inbox.test\r\n" +
+		"Content-Type: text/html\r\n" +
+		"\r\n" +
+		"<html><style>p{}</style><body><p>Slept &amp; ate well</p><p>Happy</p></body></html>\r\n"
+
+	msg, err := ParseMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	if msg.Body != "Slept & ate well\nHappy" {
+		t.Errorf("Body = %q, want stripped HTML", msg.Body)
+	}
+}
+
+func TestParseMessage_Malformed(t *testing.T) {
+	_, err := ParseMessage(strings.NewReader("From: not an address\r\n\r\nhello"))
+	if !errors.Is(err, ErrMalformed) {
+		t.Errorf("ParseMessage() error = %v, want %v", err, ErrMalformed)
+	}
+}
@@ -0,0 +1,59 @@
+package inbound
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	GetByFamily(ctx context.Context, familyID string) (*Address, error)
+	GetByToken(ctx context.Context, token string) (*Address, error)
+	// Save creates the family's address or replaces its token
+	Save(ctx context.Context, addr *Address) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the inbound address table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "family_inbound_addresses")
+}
+
+func (r *repository) GetByFamily(ctx context.Context, familyID string) (*Address, error) {
+	return r.get(ctx, `SELECT family_id, token, created_at FROM family_inbound_addresses WHERE family_id = $1`, familyID)
+}
+
+func (r *repository) GetByToken(ctx context.Context, token string) (*Address, error) {
+	return r.get(ctx, `SELECT family_id, token, created_at FROM family_inbound_addresses WHERE token = $1`, token)
+}
+
+func (r *repository) get(ctx context.Context, query, arg string) (*Address, error) {
+	var a Address
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(&a.FamilyID, &a.Token, &a.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *repository) Save(ctx context.Context, addr *Address) error {
+	query := `
+		INSERT INTO family_inbound_addresses (family_id, token, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (family_id) DO UPDATE SET token = $2, created_at = $3
+	`
+	_, err := r.db.ExecContext(ctx, query, addr.FamilyID, addr.Token, addr.CreatedAt)
+	return err
+}
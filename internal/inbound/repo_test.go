@@ -0,0 +1,79 @@
+package inbound
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_GetByToken(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT family_id, token, created_at FROM family_inbound_addresses WHERE token").
+		WithArgs("tok123").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id", "token", "created_at"}).AddRow("family-1", "tok123", now))
+
+	addr, err := repo.GetByToken(context.Background(), "tok123")
+	if err != nil {
+		t.Fatalf("GetByToken() error = %v", err)
+	}
+	if addr == nil || addr.FamilyID != "family-1" {
+		t.Errorf("GetByToken() = %+v, want family-1", addr)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetByFamily_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT family_id, token, created_at FROM family_inbound_addresses WHERE family_id").
+		WithArgs("family-1").
+		WillReturnError(sql.ErrNoRows)
+
+	addr, err := repo.GetByFamily(context.Background(), "family-1")
+	if err != nil {
+		t.Fatalf("GetByFamily() error = %v", err)
+	}
+	if addr != nil {
+		t.Errorf("GetByFamily() = %+v, want nil", addr)
+	}
+}
+
+func TestRepository_Save(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	addr := &Address{FamilyID: "family-1", Token: "tok456", CreatedAt: time.Now()}
+	mock.ExpectExec("INSERT INTO family_inbound_addresses .* ON CONFLICT \\(family_id\\) DO UPDATE").
+		WithArgs(addr.FamilyID, addr.Token, addr.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.Save(context.Background(), addr); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
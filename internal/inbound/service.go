@@ -0,0 +1,294 @@
+// Package inbound turns email sent to a family's inbound address into notes.
+package inbound
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/media"
+	"github.com/ninenine/babytrack/internal/notes"
+)
+
+var (
+	ErrDisabled       = errors.New("inbound email is not configured")
+	ErrNotMember      = errors.New("user is not a member of this family")
+	ErrNotAdmin       = errors.New("only admins can rotate the inbound address")
+	ErrUnknownAddress = errors.New("no family has this inbound address")
+	ErrUnknownSender  = errors.New("sender is not a member of the family")
+	ErrNoChild        = errors.New("no child matches the message")
+	ErrEmptyMessage   = errors.New("message has no subject, body or attachments")
+)
+
+// NoteTag is added to every note filed from email
+const NoteTag = "email"
+
+// hashtag picks a child out of the subject, e.g. "Daycare photos #emma"
+var hashtag = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_-]+)`)
+
+type Service interface {
+	// GetAddress returns the family's address, creating it on first use
+	GetAddress(ctx context.Context, userID, familyID string) (*Address, error)
+	// RotateAddress replaces the address; mail to the old one is rejected
+	RotateAddress(ctx context.Context, userID, familyID string) (*Address, error)
+	Ingest(ctx context.Context, r io.Reader) (*notes.Note, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	notesService  notes.Service
+	mediaService  media.Service
+	domain        string
+}
+
+// NewService creates the inbound service for addresses at domain. An empty
+// domain disables inbound email.
+func NewService(repo Repository, familyService family.Service, notesService notes.Service, mediaService media.Service, domain string) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+		notesService:  notesService,
+		mediaService:  mediaService,
+		domain:        strings.ToLower(domain),
+	}
+}
+
+// HealthCheck reports whether inbound mail addresses can be resolved
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) GetAddress(ctx context.Context, userID, familyID string) (*Address, error) {
+	if s.domain == "" {
+		return nil, ErrDisabled
+	}
+	if _, err := s.memberRole(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	addr, err := s.repo.GetByFamily(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbound address: %w", err)
+	}
+	if addr == nil {
+		return s.newAddress(ctx, familyID)
+	}
+
+	addr.Email = s.email(addr.Token)
+	return addr, nil
+}
+
+func (s *service) RotateAddress(ctx context.Context, userID, familyID string) (*Address, error) {
+	if s.domain == "" {
+		return nil, ErrDisabled
+	}
+	role, err := s.memberRole(ctx, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "admin" {
+		return nil, ErrNotAdmin
+	}
+
+	return s.newAddress(ctx, familyID)
+}
+
+// Ingest files one email as a note. The family comes from the recipient
+// address and the author from the sender, who must be a family member. The
+// child is named by a plus tag on the address (token+emma@...) or a hashtag
+// in the subject, and may be left out when the family has one child.
+func (s *service) Ingest(ctx context.Context, r io.Reader) (*notes.Note, error) {
+	if s.domain == "" {
+		return nil, ErrDisabled
+	}
+
+	msg, err := ParseMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, tag, err := s.resolveRecipient(ctx, msg.To)
+	if err != nil {
+		return nil, err
+	}
+
+	authorID, err := s.resolveSender(ctx, addr.FamilyID, msg.From)
+	if err != nil {
+		return nil, err
+	}
+
+	title := msg.Subject
+	if tag == "" {
+		if m := hashtag.FindStringSubmatch(title); m != nil {
+			tag = m[1]
+			title = strings.TrimSpace(strings.Replace(title, "#"+m[1], "", 1))
+		}
+	}
+
+	child, err := s.resolveChild(ctx, addr.FamilyID, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	content := msg.Body
+	if content == "" {
+		content = title
+	}
+	if content == "" && len(msg.Attachments) == 0 {
+		return nil, ErrEmptyMessage
+	}
+
+	// Mail is filed whatever its size, so long text is cut short and extra
+	// attachments are left behind rather than refusing the message
+	l := limits.Get()
+	title = limits.Truncate(title, l.NameLength)
+	content = limits.Truncate(content, l.TextLength)
+
+	var mediaIDs []string
+	for _, a := range msg.Attachments {
+		if len(mediaIDs) == l.AttachmentsPerRecord {
+			log.Printf("[inbound] skipped attachment %q: at most %d per note", a.Filename, l.AttachmentsPerRecord)
+			continue
+		}
+		m, err := s.mediaService.Upload(ctx, authorID, &media.UploadRequest{
+			FamilyID: addr.FamilyID,
+			Filename: a.Filename,
+			Data:     a.Data,
+		})
+		if errors.Is(err, media.ErrUnsupportedType) || errors.Is(err, media.ErrFileTooLarge) || errors.Is(err, media.ErrEmptyFile) {
+			// Signatures and calendar invites shouldn't cost the whole message
+			log.Printf("[inbound] skipped attachment %q: %v", a.Filename, err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to store attachment %q: %w", a.Filename, err)
+		}
+		mediaIDs = append(mediaIDs, m.ID)
+	}
+
+	req := &notes.CreateNoteRequest{
+		ChildID:  child.ID,
+		Title:    title,
+		Content:  content,
+		Tags:     []string{NoteTag},
+		MediaIDs: mediaIDs,
+	}
+	if msg.Date != nil && msg.Date.Before(time.Now()) {
+		req.OccurredAt = msg.Date
+	}
+
+	return s.notesService.Create(ctx, authorID, req)
+}
+
+// resolveRecipient finds the family address among the recipients and returns
+// it with any plus tag
+func (s *service) resolveRecipient(ctx context.Context, recipients []string) (*Address, string, error) {
+	for _, rcpt := range recipients {
+		local, domain, ok := strings.Cut(rcpt, "@")
+		if !ok || domain != s.domain {
+			continue
+		}
+		token, tag, _ := strings.Cut(local, "+")
+
+		addr, err := s.repo.GetByToken(ctx, token)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get inbound address: %w", err)
+		}
+		if addr != nil {
+			return addr, tag, nil
+		}
+	}
+	return nil, "", ErrUnknownAddress
+}
+
+func (s *service) resolveSender(ctx context.Context, familyID, from string) (string, error) {
+	members, err := s.familyService.GetFamilyMembers(ctx, familyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get family members: %w", err)
+	}
+	for _, m := range members {
+		if strings.EqualFold(m.Email, from) {
+			return m.UserID, nil
+		}
+	}
+	return "", ErrUnknownSender
+}
+
+func (s *service) resolveChild(ctx context.Context, familyID, tag string) (*family.Child, error) {
+	children, err := s.familyService.GetChildren(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get children: %w", err)
+	}
+
+	if tag == "" {
+		if len(children) == 1 {
+			return &children[0], nil
+		}
+		return nil, ErrNoChild
+	}
+
+	for i := range children {
+		if normalise(children[i].Name) == normalise(tag) {
+			return &children[i], nil
+		}
+	}
+	return nil, ErrNoChild
+}
+
+func (s *service) newAddress(ctx context.Context, familyID string) (*Address, error) {
+	addr := &Address{
+		FamilyID:  familyID,
+		Token:     generateToken(),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Save(ctx, addr); err != nil {
+		return nil, fmt.Errorf("failed to save inbound address: %w", err)
+	}
+
+	addr.Email = s.email(addr.Token)
+	return addr, nil
+}
+
+func (s *service) email(token string) string {
+	return token + "@" + s.domain
+}
+
+func (s *service) memberRole(ctx context.Context, familyID, userID string) (string, error) {
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return "", ErrNotMember
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// normalise lets "#mary-jane" match a child named "Mary Jane"
+func normalise(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func generateToken() string {
+	b := make([]byte, 10)
+	rand.Read(b) //nolint:errcheck // crypto/rand.Read rarely fails
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,298 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/media"
+	"github.com/ninenine/babytrack/internal/notes"
+)
+
+// mockRepository is a test double for Repository
+type mockRepository struct {
+	byFamily map[string]*Address
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{byFamily: make(map[string]*Address)}
+}
+
+func (m *mockRepository) GetByFamily(ctx context.Context, familyID string) (*Address, error) {
+	return m.byFamily[familyID], nil
+}
+
+func (m *mockRepository) GetByToken(ctx context.Context, token string) (*Address, error) {
+	for _, a := range m.byFamily {
+		if a.Token == token {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) Save(ctx context.Context, addr *Address) error {
+	m.byFamily[addr.FamilyID] = addr
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	children []family.Child
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	switch userID {
+	case "admin-1":
+		return "admin", nil
+	case "member-1":
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) GetFamilyMembers(ctx context.Context, familyID string) ([]family.MemberWithUser, error) {
+	return []family.MemberWithUser{
+		{UserID: "admin-1", Email: "sam@example.com", Role: "admin"},
+		{UserID: "member-1", Email: "alex@example.com", Role: "member"},
+	}, nil
+}
+
+func (m *mockFamilyService) GetChildren(ctx context.Context, familyID string) ([]family.Child, error) {
+	return m.children, nil
+}
+
+type mockNotesService struct {
+	notes.Service
+	authorID string
+	req      *notes.CreateNoteRequest
+}
+
+func (m *mockNotesService) Create(ctx context.Context, userID string, req *notes.CreateNoteRequest) (*notes.Note, error) {
+	m.authorID = userID
+	m.req = req
+	return &notes.Note{ID: "note-1", ChildID: req.ChildID, AuthorID: userID, Title: req.Title, Content: req.Content, MediaIDs: req.MediaIDs}, nil
+}
+
+type mockMediaService struct {
+	media.Service
+	uploads []*media.UploadRequest
+}
+
+func (m *mockMediaService) Upload(ctx context.Context, userID string, req *media.UploadRequest) (*media.Media, error) {
+	if strings.HasSuffix(req.Filename, ".vcf") {
+		return nil, media.ErrUnsupportedType
+	}
+	m.uploads = append(m.uploads, req)
+	return &media.Media{ID: "media-" + req.Filename, FamilyID: req.FamilyID}, nil
+}
+
+type fixture struct {
+	svc   Service
+	repo  *mockRepository
+	notes *mockNotesService
+	media *mockMediaService
+}
+
+func newFixture(children ...family.Child) *fixture {
+	f := &fixture{
+		repo:  newMockRepository(),
+		notes: &mockNotesService{},
+		media: &mockMediaService{},
+	}
+	f.repo.byFamily["family-1"] = &Address{FamilyID: "family-1", Token: "tok123"}
+	f.svc = NewService(f.repo, &mockFamilyService{children: children}, f.notes, f.media, "inbox.test")
+	return f
+}
+
+var (
+	emma     = family.Child{ID: "child-1", FamilyID: "family-1", Name: "Emma"}
+	maryJane = family.Child{ID: "child-2", FamilyID: "family-1", Name: "Mary Jane"}
+)
+
+func email(from, to, subject, body string) *strings.Reader {
+	return strings.NewReader("From: " + from + "\r\nTo: " + to + "\r\nSubject: " + subject + "\r\n\r\n" + body + "\r\n")
+}
+
+func TestService_GetAddress_CreatesOnFirstUse(t *testing.T) {
+	f := newFixture()
+
+	addr, err := f.svc.GetAddress(context.Background(), "member-1", "family-2")
+	if err != nil {
+		t.Fatalf("GetAddress() error = %v", err)
+	}
+	if !strings.HasSuffix(addr.Email, "@inbox.test") || addr.Token == "" {
+		t.Errorf("GetAddress() = %+v, want a new address at inbox.test", addr)
+	}
+
+	again, _ := f.svc.GetAddress(context.Background(), "member-1", "family-2")
+	if again.Email != addr.Email {
+		t.Errorf("GetAddress() changed from %s to %s", addr.Email, again.Email)
+	}
+}
+
+func TestService_GetAddress_NotMember(t *testing.T) {
+	f := newFixture()
+
+	_, err := f.svc.GetAddress(context.Background(), "stranger", "family-1")
+	if !errors.Is(err, ErrNotMember) {
+		t.Errorf("GetAddress() error = %v, want %v", err, ErrNotMember)
+	}
+}
+
+func TestService_GetAddress_Disabled(t *testing.T) {
+	svc := NewService(newMockRepository(), &mockFamilyService{}, &mockNotesService{}, &mockMediaService{}, "")
+
+	_, err := svc.GetAddress(context.Background(), "member-1", "family-1")
+	if !errors.Is(err, ErrDisabled) {
+		t.Errorf("GetAddress() error = %v, want %v", err, ErrDisabled)
+	}
+}
+
+func TestService_RotateAddress(t *testing.T) {
+	f := newFixture()
+
+	if _, err := f.svc.RotateAddress(context.Background(), "member-1", "family-1"); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("RotateAddress() by member error = %v, want %v", err, ErrNotAdmin)
+	}
+
+	addr, err := f.svc.RotateAddress(context.Background(), "admin-1", "family-1")
+	if err != nil {
+		t.Fatalf("RotateAddress() error = %v", err)
+	}
+	if addr.Token == "tok123" {
+		t.Error("RotateAddress() should issue a new token")
+	}
+
+	_, err = f.svc.Ingest(context.Background(), email("sam@example.com", "tok123@inbox.test", "Hi", "Hello"))
+	if !errors.Is(err, ErrUnknownAddress) {
+		t.Errorf("Ingest() to old address error = %v, want %v", err, ErrUnknownAddress)
+	}
+}
+
+func TestService_Ingest_SingleChild(t *testing.T) {
+	f := newFixture(emma)
+
+	note, err := f.svc.Ingest(context.Background(), email("Alex <ALEX@example.com>", "tok123@inbox.test", "Daycare report", "Napped twice."))
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if note.ChildID != "child-1" {
+		t.Errorf("ChildID = %s, want child-1", note.ChildID)
+	}
+	if f.notes.authorID != "member-1" {
+		t.Errorf("author = %s, want the sending member", f.notes.authorID)
+	}
+	if f.notes.req.Title != "Daycare report" || f.notes.req.Content != "Napped twice." {
+		t.Errorf("note = %q / %q", f.notes.req.Title, f.notes.req.Content)
+	}
+	if len(f.notes.req.Tags) != 1 || f.notes.req.Tags[0] != NoteTag {
+		t.Errorf("Tags = %v, want [%s]", f.notes.req.Tags, NoteTag)
+	}
+}
+
+func TestService_Ingest_ChildTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		to      string
+		subject string
+		want    string
+		title   string
+	}{
+		{"plus address", "tok123+maryjane@inbox.test", "Photos", "child-2", "Photos"},
+		{"subject hashtag", "tok123@inbox.test", "Photos #mary-jane", "child-2", "Photos"},
+		{"hashtag case", "tok123@inbox.test", "#EMMA first steps", "child-1", "first steps"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(emma, maryJane)
+
+			note, err := f.svc.Ingest(context.Background(), email("sam@example.com", tt.to, tt.subject, "body"))
+			if err != nil {
+				t.Fatalf("Ingest() error = %v", err)
+			}
+			if note.ChildID != tt.want {
+				t.Errorf("ChildID = %s, want %s", note.ChildID, tt.want)
+			}
+			if note.Title != tt.title {
+				t.Errorf("Title = %q, want %q", note.Title, tt.title)
+			}
+		})
+	}
+}
+
+func TestService_Ingest_Rejected(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *strings.Reader
+		want error
+	}{
+		{"unknown address", email("sam@example.com", "nope@inbox.test", "Hi", "x"), ErrUnknownAddress},
+		{"other domain", email("sam@example.com", "tok123@elsewhere.test", "Hi", "x"), ErrUnknownAddress},
+		{"stranger", email("spam@example.net", "tok123@inbox.test", "Hi", "x"), ErrUnknownSender},
+		{"ambiguous child", email("sam@example.com", "tok123@inbox.test", "Hi", "x"), ErrNoChild},
+		{"unknown child", email("sam@example.com", "tok123+bob@inbox.test", "Hi", "x"), ErrNoChild},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(emma, maryJane)
+
+			_, err := f.svc.Ingest(context.Background(), tt.msg)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Ingest() error = %v, want %v", err, tt.want)
+			}
+			if f.notes.req != nil {
+				t.Error("Ingest() should not create a note")
+			}
+		})
+	}
+}
+
+func TestService_Ingest_Attachments(t *testing.T) {
+	f := newFixture(emma)
+
+	raw := "From: sam@example.com\r\n" +
+		"To: tok123@inbox.test\r\n" +
+		"Date: " + time.Now().Add(-time.Hour).Format(time.RFC1123Z) + "\r\n" +
+		"Content-Type: multipart/mixed; boundary=b\r\n" +
+		"\r\n" +
+		"--b\r\n" +
+		"Content-Type: image/png\r\n" +
+		"Content-Disposition: attachment; filename=photo.png\r\n" +
+		"\r\n" +
+		"png-bytes\r\n" +
+		"--b\r\n" +
+		"Content-Type: text/vcard\r\n" +
+		"Content-Disposition: attachment; filename=sam.vcf\r\n" +
+		"\r\n" +
+		"BEGIN:VCARD\r\n" +
+		"--b--\r\n"
+
+	note, err := f.svc.Ingest(context.Background(), strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if len(f.media.uploads) != 1 || f.media.uploads[0].FamilyID != "family-1" {
+		t.Fatalf("uploads = %+v, want photo.png for family-1", f.media.uploads)
+	}
+	if len(note.MediaIDs) != 1 || note.MediaIDs[0] != "media-photo.png" {
+		t.Errorf("MediaIDs = %v, want [media-photo.png]", note.MediaIDs)
+	}
+	if f.notes.req.OccurredAt == nil {
+		t.Error("OccurredAt should come from the Date header")
+	}
+}
+
+func TestService_Ingest_Empty(t *testing.T) {
+	f := newFixture(emma)
+
+	_, err := f.svc.Ingest(context.Background(), email("sam@example.com", "tok123@inbox.test", "", ""))
+	if !errors.Is(err, ErrEmptyMessage) {
+		t.Errorf("Ingest() error = %v, want %v", err, ErrEmptyMessage)
+	}
+}
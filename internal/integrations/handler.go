@@ -0,0 +1,156 @@
+package integrations
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ninenine/babytrack/internal/occurrence"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	// maxWebhookBody caps the size of an incoming integration payload
+	maxWebhookBody = 1 << 20
+
+	receiverKeyContext = "receiver_key"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterFamilyRoutes registers receiver key management on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/receiver-keys", h.listKeys)
+	rg.POST("/:familyId/receiver-keys", h.createKey)
+	rg.DELETE("/:familyId/receiver-keys/:keyId", h.revokeKey)
+}
+
+// RegisterWebhookRoutes registers the signed, unauthenticated receiver endpoints
+func (h *Handler) RegisterWebhookRoutes(rg *gin.RouterGroup) {
+	rg.POST("/daycare", h.Verify(), h.receiveDaycareReport)
+}
+
+// Verify authenticates a signed integration request and stores the receiver
+// key in the context. The body is buffered so later handlers can bind it.
+func (h *Handler) Verify() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBody))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key, err := h.service.Verify(c.Request.Context(), &SignedRequest{
+			KeyID:     c.GetHeader(HeaderKeyID),
+			Timestamp: c.GetHeader(HeaderTimestamp),
+			Nonce:     c.GetHeader(HeaderNonce),
+			Signature: c.GetHeader(HeaderSignature),
+			Body:      body,
+		})
+		if err != nil {
+			respondError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set(receiverKeyContext, key)
+		c.Set(gin.BodyBytesKey, body)
+		c.Next()
+	}
+}
+
+// KeyFrom returns the receiver key Verify authenticated the request with
+func KeyFrom(c *gin.Context) (*ReceiverKey, bool) {
+	key, ok := c.Get(receiverKeyContext)
+	if !ok {
+		return nil, false
+	}
+	k, ok := key.(*ReceiverKey)
+	return k, ok
+}
+
+func (h *Handler) receiveDaycareReport(c *gin.Context) {
+	var report DaycareReport
+	if err := c.ShouldBindBodyWith(&report, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, ok := KeyFrom(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "missing receiver key"})
+		return
+	}
+
+	note, err := h.service.ReceiveDaycareReport(c.Request.Context(), key, &report)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, note)
+}
+
+func (h *Handler) listKeys(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	keys, err := h.service.ListKeys(c.Request.Context(), userID, c.Param("familyId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *Handler) createKey(c *gin.Context) {
+	var req CreateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	key, err := h.service.CreateKey(c.Request.Context(), userID, c.Param("familyId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *Handler) revokeKey(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.service.RevokeKey(c.Request.Context(), userID, c.Param("familyId"), c.Param("keyId")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotAdmin), errors.Is(err, ErrNotMember), errors.Is(err, ErrChildNotInFamily):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidSignature), errors.Is(err, ErrStaleRequest):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, occurrence.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrReplayedRequest):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,198 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/notes"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	createKeyFn func(ctx context.Context, userID, familyID string, req *CreateKeyRequest) (*ReceiverKey, error)
+	listKeysFn  func(ctx context.Context, userID, familyID string) ([]ReceiverKey, error)
+	revokeKeyFn func(ctx context.Context, userID, familyID, keyID string) error
+	verifyFn    func(ctx context.Context, req *SignedRequest) (*ReceiverKey, error)
+	receiveFn   func(ctx context.Context, key *ReceiverKey, report *DaycareReport) (*notes.Note, error)
+}
+
+func (m *mockService) CreateKey(ctx context.Context, userID, familyID string, req *CreateKeyRequest) (*ReceiverKey, error) {
+	if m.createKeyFn != nil {
+		return m.createKeyFn(ctx, userID, familyID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ListKeys(ctx context.Context, userID, familyID string) ([]ReceiverKey, error) {
+	if m.listKeysFn != nil {
+		return m.listKeysFn(ctx, userID, familyID)
+	}
+	return []ReceiverKey{}, nil
+}
+
+func (m *mockService) RevokeKey(ctx context.Context, userID, familyID, keyID string) error {
+	if m.revokeKeyFn != nil {
+		return m.revokeKeyFn(ctx, userID, familyID, keyID)
+	}
+	return nil
+}
+
+func (m *mockService) Verify(ctx context.Context, req *SignedRequest) (*ReceiverKey, error) {
+	if m.verifyFn != nil {
+		return m.verifyFn(ctx, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ReceiveDaycareReport(ctx context.Context, key *ReceiverKey, report *DaycareReport) (*notes.Note, error) {
+	if m.receiveFn != nil {
+		return m.receiveFn(ctx, key, report)
+	}
+	return nil, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	handler.RegisterWebhookRoutes(router.Group("/webhooks"))
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterFamilyRoutes(protected.Group("/families"))
+	return router
+}
+
+func TestCreateKey_Success(t *testing.T) {
+	svc := &mockService{
+		createKeyFn: func(ctx context.Context, userID, familyID string, req *CreateKeyRequest) (*ReceiverKey, error) {
+			if userID != "test-user-123" || familyID != "family-1" {
+				t.Errorf("Unexpected userID %q familyID %q", userID, familyID)
+			}
+			return &ReceiverKey{ID: "key-1", Name: req.Name, Secret: "s3cret"}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/families/family-1/receiver-keys", bytes.NewBufferString(`{"name":"Daycare"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+
+	var key ReceiverKey
+	json.Unmarshal(w.Body.Bytes(), &key)
+	if key.Secret != "s3cret" {
+		t.Error("Expected secret to be returned on creation")
+	}
+}
+
+func TestRevokeKey_NotAdmin(t *testing.T) {
+	svc := &mockService{
+		revokeKeyFn: func(ctx context.Context, userID, familyID, keyID string) error {
+			return ErrNotAdmin
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("DELETE", "/families/family-1/receiver-keys/key-1", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestDaycareWebhook_Success(t *testing.T) {
+	body := `{"child_id":"child-1","content":"Ate well"}`
+	var gotReport *DaycareReport
+	svc := &mockService{
+		verifyFn: func(ctx context.Context, req *SignedRequest) (*ReceiverKey, error) {
+			if req.KeyID != "key-1" || req.Nonce != "n-1" || req.Timestamp != "1717243200" ||
+				req.Signature != "sha256=abc" || string(req.Body) != body {
+				t.Errorf("Unexpected signed request %+v", req)
+			}
+			return &ReceiverKey{ID: "key-1", FamilyID: "family-1"}, nil
+		},
+		receiveFn: func(ctx context.Context, key *ReceiverKey, report *DaycareReport) (*notes.Note, error) {
+			gotReport = report
+			return &notes.Note{ID: "note-1"}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/webhooks/daycare", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderKeyID, "key-1")
+	req.Header.Set(HeaderTimestamp, "1717243200")
+	req.Header.Set(HeaderNonce, "n-1")
+	req.Header.Set(HeaderSignature, "sha256=abc")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotReport == nil || gotReport.ChildID != "child-1" {
+		t.Errorf("Expected report to be bound after verification, got %+v", gotReport)
+	}
+}
+
+func TestDaycareWebhook_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"bad signature", ErrInvalidSignature, http.StatusUnauthorized},
+		{"stale", ErrStaleRequest, http.StatusUnauthorized},
+		{"replay", ErrReplayedRequest, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiveCalled := false
+			svc := &mockService{
+				verifyFn: func(ctx context.Context, req *SignedRequest) (*ReceiverKey, error) {
+					return nil, tt.err
+				},
+				receiveFn: func(ctx context.Context, key *ReceiverKey, report *DaycareReport) (*notes.Note, error) {
+					receiveCalled = true
+					return nil, nil
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/webhooks/daycare", bytes.NewBufferString(`{"child_id":"c","content":"x"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if receiveCalled {
+				t.Error("Report should not be processed when verification fails")
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package integrations
+
+import "time"
+
+// Headers an integration sends with every signed request
+const (
+	HeaderKeyID     = "X-Babytrack-Key"
+	HeaderTimestamp = "X-Babytrack-Timestamp"
+	HeaderNonce     = "X-Babytrack-Nonce"
+	HeaderSignature = "X-Babytrack-Signature"
+)
+
+// MaxClockSkew is how far a request timestamp may drift from server time.
+// Nonces are remembered for twice this window so a replay can never fall
+// outside both checks.
+const MaxClockSkew = 5 * time.Minute
+
+// ReceiverKey authenticates an external system posting into one family.
+// The secret is only returned when the key is created.
+type ReceiverKey struct {
+	ID         string     `json:"id"`
+	FamilyID   string     `json:"family_id"`
+	Name       string     `json:"name"`
+	Secret     string     `json:"secret,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type CreateKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// SignedRequest carries the authentication headers and raw body of an incoming call
+type SignedRequest struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Body      []byte
+}
+
+// DaycareReport is posted by a daycare system and stored as a note on the child
+type DaycareReport struct {
+	ChildID string `json:"child_id" binding:"required"`
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content" binding:"required"`
+	// OccurredAt is the day the report covers; it defaults to when it was received
+	OccurredAt *time.Time `json:"occurred_at,omitempty"`
+}
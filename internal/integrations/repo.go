@@ -0,0 +1,123 @@
+package integrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, key *ReceiverKey) error
+	GetByID(ctx context.Context, id string) (*ReceiverKey, error)
+	ListByFamily(ctx context.Context, familyID string) ([]ReceiverKey, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the receiver key table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "receiver_keys")
+}
+
+func (r *repository) Create(ctx context.Context, key *ReceiverKey) error {
+	query := `
+		INSERT INTO receiver_keys (id, family_id, name, secret, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		key.ID, key.FamilyID, key.Name, key.Secret, key.CreatedBy, key.CreatedAt,
+	)
+	return err
+}
+
+// GetByID includes the secret so the caller can verify signatures
+func (r *repository) GetByID(ctx context.Context, id string) (*ReceiverKey, error) {
+	query := `
+		SELECT id, family_id, name, secret, created_by, created_at, last_used_at, revoked_at
+		FROM receiver_keys
+		WHERE id = $1
+	`
+
+	var key ReceiverKey
+	var lastUsedAt, revokedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&key.ID, &key.FamilyID, &key.Name, &key.Secret, &key.CreatedBy, &key.CreatedAt, &lastUsedAt, &revokedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return &key, nil
+}
+
+// ListByFamily never returns secrets
+func (r *repository) ListByFamily(ctx context.Context, familyID string) ([]ReceiverKey, error) {
+	query := `
+		SELECT id, family_id, name, created_by, created_at, last_used_at, revoked_at
+		FROM receiver_keys
+		WHERE family_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	keys := []ReceiverKey{}
+	for rows.Next() {
+		var key ReceiverKey
+		var lastUsedAt, revokedAt sql.NullTime
+
+		if err := rows.Scan(
+			&key.ID, &key.FamilyID, &key.Name, &key.CreatedBy, &key.CreatedAt, &lastUsedAt, &revokedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if lastUsedAt.Valid {
+			key.LastUsedAt = &lastUsedAt.Time
+		}
+		if revokedAt.Valid {
+			key.RevokedAt = &revokedAt.Time
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *repository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE receiver_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, revokedAt)
+	return err
+}
+
+func (r *repository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE receiver_keys SET last_used_at = $2 WHERE id = $1`, id, usedAt)
+	return err
+}
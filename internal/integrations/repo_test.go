@@ -0,0 +1,100 @@
+package integrations
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	key := &ReceiverKey{ID: "key-1", FamilyID: "family-1", Name: "Sunshine Daycare", Secret: "s3cret", CreatedBy: "user-1", CreatedAt: now}
+
+	mock.ExpectExec("INSERT INTO receiver_keys").
+		WithArgs("key-1", "family-1", "Sunshine Daycare", "s3cret", "user-1", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), key); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "family_id", "name", "secret", "created_by", "created_at", "last_used_at", "revoked_at"}).
+		AddRow("key-1", "family-1", "Sunshine Daycare", "s3cret", "user-1", now, nil, now)
+
+	mock.ExpectQuery("SELECT id, family_id, name, secret").
+		WithArgs("key-1").
+		WillReturnRows(rows)
+
+	key, err := repo.GetByID(context.Background(), "key-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if key.Secret != "s3cret" || key.LastUsedAt != nil || key.RevokedAt == nil {
+		t.Errorf("GetByID() = %+v", key)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT id, family_id, name, secret").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	key, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || key != nil {
+		t.Errorf("GetByID() = %v, %v; want nil, nil", key, err)
+	}
+}
+
+func TestRepository_ListByFamily(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "family_id", "name", "created_by", "created_at", "last_used_at", "revoked_at"}).
+		AddRow("key-2", "family-1", "Nanny app", "user-1", now, now, nil).
+		AddRow("key-1", "family-1", "Sunshine Daycare", "user-1", now, nil, nil)
+
+	mock.ExpectQuery("SELECT id, family_id, name, created_by").
+		WithArgs("family-1").
+		WillReturnRows(rows)
+
+	keys, err := repo.ListByFamily(context.Background(), "family-1")
+	if err != nil {
+		t.Fatalf("ListByFamily() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0].LastUsedAt == nil || keys[1].Secret != "" {
+		t.Errorf("ListByFamily() = %+v", keys)
+	}
+}
@@ -0,0 +1,225 @@
+package integrations
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/replay"
+)
+
+var (
+	ErrKeyNotFound      = errors.New("receiver key not found")
+	ErrNotAdmin         = errors.New("only admins can manage receiver keys")
+	ErrNotMember        = errors.New("user is not a member of this family")
+	ErrInvalidSignature = errors.New("invalid request signature")
+	ErrStaleRequest     = errors.New("request timestamp outside allowed window")
+	ErrReplayedRequest  = errors.New("request nonce already used")
+	ErrChildNotInFamily = errors.New("child does not belong to this family")
+)
+
+type Service interface {
+	CreateKey(ctx context.Context, userID, familyID string, req *CreateKeyRequest) (*ReceiverKey, error)
+	ListKeys(ctx context.Context, userID, familyID string) ([]ReceiverKey, error)
+	RevokeKey(ctx context.Context, userID, familyID, keyID string) error
+	Verify(ctx context.Context, req *SignedRequest) (*ReceiverKey, error)
+	ReceiveDaycareReport(ctx context.Context, key *ReceiverKey, report *DaycareReport) (*notes.Note, error)
+}
+
+type service struct {
+	repo          Repository
+	replay        replay.Store
+	familyService family.Service
+	notesService  notes.Service
+	now           func() time.Time
+}
+
+func NewService(repo Repository, replayStore replay.Store, familyService family.Service, notesService notes.Service) Service {
+	return &service{
+		repo:          repo,
+		replay:        replayStore,
+		familyService: familyService,
+		notesService:  notesService,
+		now:           time.Now,
+	}
+}
+
+// HealthCheck reports whether receiver keys can be read, which every
+// webhook needs
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) CreateKey(ctx context.Context, userID, familyID string, req *CreateKeyRequest) (*ReceiverKey, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	key := &ReceiverKey{
+		ID:        ids.New(),
+		FamilyID:  familyID,
+		Name:      strings.TrimSpace(req.Name),
+		Secret:    secret,
+		CreatedBy: userID,
+		CreatedAt: s.now(),
+	}
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create receiver key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (s *service) ListKeys(ctx context.Context, userID, familyID string) ([]ReceiverKey, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.ListByFamily(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list receiver keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *service) RevokeKey(ctx context.Context, userID, familyID, keyID string) error {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return err
+	}
+
+	key, err := s.repo.GetByID(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to get receiver key: %w", err)
+	}
+	if key == nil || key.FamilyID != familyID {
+		return ErrKeyNotFound
+	}
+
+	if err := s.repo.Revoke(ctx, keyID, s.now()); err != nil {
+		return fmt.Errorf("failed to revoke receiver key: %w", err)
+	}
+	return nil
+}
+
+// Verify authenticates a signed request. The signature is the hex HMAC-SHA256,
+// keyed by the receiver secret, of "<timestamp>.<nonce>.<body>", optionally
+// prefixed with "sha256=". The timestamp is in Unix seconds and must be within
+// MaxClockSkew of server time, and each nonce is accepted once per key.
+func (s *service) Verify(ctx context.Context, req *SignedRequest) (*ReceiverKey, error) {
+	if req.KeyID == "" || req.Nonce == "" || req.Signature == "" {
+		return nil, ErrInvalidSignature
+	}
+
+	key, err := s.repo.GetByID(ctx, req.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receiver key: %w", err)
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	expected := Sign(key.Secret, req.Timestamp, req.Nonce, req.Body)
+	given := strings.TrimPrefix(req.Signature, "sha256=")
+	if !hmac.Equal([]byte(expected), []byte(given)) {
+		return nil, ErrInvalidSignature
+	}
+
+	// Check freshness only after the signature so unauthenticated callers
+	// cannot probe the server clock or fill the nonce table
+	unix, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrStaleRequest
+	}
+	now := s.now()
+	sent := time.Unix(unix, 0)
+	if sent.Before(now.Add(-MaxClockSkew)) || sent.After(now.Add(MaxClockSkew)) {
+		return nil, ErrStaleRequest
+	}
+
+	fresh, err := s.replay.Claim(ctx, "receiver:"+key.ID, req.Nonce, now.Add(2*MaxClockSkew))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record nonce: %w", err)
+	}
+	if !fresh {
+		return nil, ErrReplayedRequest
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to update receiver key: %w", err)
+	}
+
+	key.Secret = ""
+	return key, nil
+}
+
+// ReceiveDaycareReport files the report as a note on the child, authored by
+// whoever created the receiver key.
+func (s *service) ReceiveDaycareReport(ctx context.Context, key *ReceiverKey, report *DaycareReport) (*notes.Note, error) {
+	child, err := s.familyService.GetChild(ctx, report.ChildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil || child.FamilyID != key.FamilyID {
+		return nil, ErrChildNotInFamily
+	}
+
+	title := report.Title
+	if title == "" {
+		title = "Daycare report"
+	}
+
+	return s.notesService.Create(ctx, key.CreatedBy, &notes.CreateNoteRequest{
+		ChildID:    report.ChildID,
+		Title:      title,
+		Content:    report.Content,
+		Tags:       []string{"daycare", key.Name},
+		OccurredAt: report.OccurredAt,
+	})
+}
+
+func (s *service) requireAdmin(ctx context.Context, familyID, userID string) error {
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	if role != "admin" {
+		return ErrNotAdmin
+	}
+	return nil
+}
+
+// Sign computes the signature an integration must send for a request
+func Sign(secret, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + nonce + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
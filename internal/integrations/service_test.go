@@ -0,0 +1,248 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/notes"
+)
+
+type mockRepository struct {
+	keys map[string]*ReceiverKey
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{keys: make(map[string]*ReceiverKey)}
+}
+
+func (m *mockRepository) Create(ctx context.Context, key *ReceiverKey) error {
+	copied := *key
+	m.keys[key.ID] = &copied
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*ReceiverKey, error) {
+	if key, ok := m.keys[id]; ok {
+		copied := *key
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListByFamily(ctx context.Context, familyID string) ([]ReceiverKey, error) {
+	keys := []ReceiverKey{}
+	for _, key := range m.keys {
+		if key.FamilyID == familyID {
+			copied := *key
+			copied.Secret = ""
+			keys = append(keys, copied)
+		}
+	}
+	return keys, nil
+}
+
+func (m *mockRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	m.keys[id].RevokedAt = &revokedAt
+	return nil
+}
+
+func (m *mockRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	m.keys[id].LastUsedAt = &usedAt
+	return nil
+}
+
+type mockReplayStore struct {
+	claimed map[string]bool
+}
+
+func (m *mockReplayStore) Claim(ctx context.Context, scope, nonce string, expiresAt time.Time) (bool, error) {
+	if m.claimed[scope+"/"+nonce] {
+		return false, nil
+	}
+	m.claimed[scope+"/"+nonce] = true
+	return true, nil
+}
+
+func (m *mockReplayStore) Release(ctx context.Context, scope, nonce string) error {
+	delete(m.claimed, scope+"/"+nonce)
+	return nil
+}
+
+func (m *mockReplayStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	roles    map[string]string
+	children map[string]*family.Child
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if role, ok := m.roles[familyID+"/"+userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+type mockNotesService struct {
+	notes.Service
+	created []*notes.CreateNoteRequest
+	authors []string
+}
+
+func (m *mockNotesService) Create(ctx context.Context, userID string, req *notes.CreateNoteRequest) (*notes.Note, error) {
+	m.created = append(m.created, req)
+	m.authors = append(m.authors, userID)
+	return &notes.Note{ID: "note-1", ChildID: req.ChildID, AuthorID: userID, Title: req.Title, Content: req.Content}, nil
+}
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService() (*service, *mockRepository, *mockNotesService) {
+	repo := newMockRepository()
+	notesSvc := &mockNotesService{}
+	svc := &service{
+		repo:   repo,
+		replay: &mockReplayStore{claimed: make(map[string]bool)},
+		familyService: &mockFamilyService{
+			roles: map[string]string{
+				"family-1/admin-1":  "admin",
+				"family-1/member-1": "member",
+			},
+			children: map[string]*family.Child{
+				"child-1": {ID: "child-1", FamilyID: "family-1"},
+				"child-2": {ID: "child-2", FamilyID: "family-2"},
+			},
+		},
+		notesService: notesSvc,
+		now:          func() time.Time { return testNow },
+	}
+	return svc, repo, notesSvc
+}
+
+func signedRequest(key *ReceiverKey, sentAt time.Time, nonce string, body []byte) *SignedRequest {
+	ts := strconv.FormatInt(sentAt.Unix(), 10)
+	return &SignedRequest{
+		KeyID:     key.ID,
+		Timestamp: ts,
+		Nonce:     nonce,
+		Signature: "sha256=" + Sign(key.Secret, ts, nonce, body),
+		Body:      body,
+	}
+}
+
+func TestService_CreateKey(t *testing.T) {
+	svc, repo, _ := newTestService()
+
+	key, err := svc.CreateKey(context.Background(), "admin-1", "family-1", &CreateKeyRequest{Name: " Sunshine Daycare "})
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+	if key.Secret == "" || key.Name != "Sunshine Daycare" {
+		t.Errorf("CreateKey() = %+v", key)
+	}
+	if _, ok := repo.keys[key.ID]; !ok {
+		t.Error("CreateKey() should store the key")
+	}
+
+	if _, err := svc.CreateKey(context.Background(), "member-1", "family-1", &CreateKeyRequest{Name: "x"}); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("CreateKey() by member error = %v, want ErrNotAdmin", err)
+	}
+	if _, err := svc.CreateKey(context.Background(), "stranger", "family-1", &CreateKeyRequest{Name: "x"}); !errors.Is(err, ErrNotMember) {
+		t.Errorf("CreateKey() by stranger error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestService_Verify(t *testing.T) {
+	svc, repo, _ := newTestService()
+	key, _ := svc.CreateKey(context.Background(), "admin-1", "family-1", &CreateKeyRequest{Name: "Daycare"})
+	body := []byte(`{"child_id":"child-1","content":"Ate well"}`)
+
+	verified, err := svc.Verify(context.Background(), signedRequest(key, testNow, "nonce-1", body))
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if verified.ID != key.ID || verified.Secret != "" {
+		t.Errorf("Verify() = %+v, want key without secret", verified)
+	}
+	if repo.keys[key.ID].LastUsedAt == nil {
+		t.Error("Verify() should record last use")
+	}
+
+	tests := []struct {
+		name    string
+		req     *SignedRequest
+		wantErr error
+	}{
+		{"replayed nonce", signedRequest(key, testNow, "nonce-1", body), ErrReplayedRequest},
+		{"too old", signedRequest(key, testNow.Add(-MaxClockSkew-time.Second), "nonce-2", body), ErrStaleRequest},
+		{"from the future", signedRequest(key, testNow.Add(MaxClockSkew+time.Second), "nonce-3", body), ErrStaleRequest},
+		{"unknown key", signedRequest(&ReceiverKey{ID: "nope", Secret: key.Secret}, testNow, "nonce-4", body), ErrInvalidSignature},
+		{"wrong secret", signedRequest(&ReceiverKey{ID: key.ID, Secret: "guess"}, testNow, "nonce-5", body), ErrInvalidSignature},
+		{"missing nonce", signedRequest(key, testNow, "", body), ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Verify(context.Background(), tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("tampered body", func(t *testing.T) {
+		req := signedRequest(key, testNow, "nonce-6", body)
+		req.Body = []byte(`{"child_id":"child-2","content":"Ate well"}`)
+		if _, err := svc.Verify(context.Background(), req); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Verify() error = %v, want ErrInvalidSignature", err)
+		}
+	})
+}
+
+func TestService_Verify_RevokedKey(t *testing.T) {
+	svc, _, _ := newTestService()
+	key, _ := svc.CreateKey(context.Background(), "admin-1", "family-1", &CreateKeyRequest{Name: "Daycare"})
+
+	if err := svc.RevokeKey(context.Background(), "admin-1", "family-1", key.ID); err != nil {
+		t.Fatalf("RevokeKey() error = %v", err)
+	}
+
+	if _, err := svc.Verify(context.Background(), signedRequest(key, testNow, "nonce-1", nil)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() with revoked key error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestService_RevokeKey_OtherFamily(t *testing.T) {
+	svc, repo, _ := newTestService()
+	repo.keys["key-x"] = &ReceiverKey{ID: "key-x", FamilyID: "family-2"}
+
+	if err := svc.RevokeKey(context.Background(), "admin-1", "family-1", "key-x"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("RevokeKey() error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestService_ReceiveDaycareReport(t *testing.T) {
+	svc, _, notesSvc := newTestService()
+	key := &ReceiverKey{ID: "key-1", FamilyID: "family-1", Name: "Sunshine Daycare", CreatedBy: "admin-1"}
+
+	note, err := svc.ReceiveDaycareReport(context.Background(), key, &DaycareReport{ChildID: "child-1", Content: "Napped 2h"})
+	if err != nil {
+		t.Fatalf("ReceiveDaycareReport() error = %v", err)
+	}
+	if note.Title != "Daycare report" || notesSvc.authors[0] != "admin-1" {
+		t.Errorf("ReceiveDaycareReport() = %+v by %v", note, notesSvc.authors)
+	}
+
+	if _, err := svc.ReceiveDaycareReport(context.Background(), key, &DaycareReport{ChildID: "child-2", Content: "x"}); !errors.Is(err, ErrChildNotInFamily) {
+		t.Errorf("ReceiveDaycareReport() for other family's child error = %v, want ErrChildNotInFamily", err)
+	}
+}
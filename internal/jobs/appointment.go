@@ -7,9 +7,8 @@ import (
 	"time"
 
 	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/ids"
 	"github.com/ninenine/babytrack/internal/notifications"
-
-	"github.com/google/uuid"
 )
 
 // AppointmentReminderJob checks for upcoming appointments and sends notifications.
@@ -84,7 +83,7 @@ func (j *AppointmentReminderJob) Run(ctx context.Context) error {
 		// Broadcast notification to connected clients
 		if j.notificationHub != nil && j.notificationHub.ClientCount() > 0 {
 			j.notificationHub.Broadcast(notifications.Event{
-				ID:        uuid.New().String(),
+				ID:        ids.New(),
 				Type:      notifications.EventAppointmentSoon,
 				Title:     "Appointment Reminder",
 				Message:   message,
@@ -2,10 +2,12 @@ package jobs
 
 import (
 	"context"
+	"iter"
 	"testing"
 	"time"
 
 	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/family"
 	"github.com/ninenine/babytrack/internal/notifications"
 )
 
@@ -13,6 +15,8 @@ import (
 type mockAppointmentService struct {
 	upcoming    []appointment.Appointment
 	upcomingErr error
+	wellVisits  []appointment.Appointment
+	reminded    []string
 }
 
 func newMockAppointmentService() *mockAppointmentService {
@@ -23,7 +27,7 @@ func (m *mockAppointmentService) Create(ctx context.Context, req *appointment.Cr
 	return nil, nil
 }
 
-func (m *mockAppointmentService) Get(ctx context.Context, id string) (*appointment.Appointment, error) {
+func (m *mockAppointmentService) Get(ctx context.Context, userID, id string) (*appointment.Appointment, error) {
 	return nil, nil
 }
 
@@ -31,19 +35,23 @@ func (m *mockAppointmentService) List(ctx context.Context, filter *appointment.A
 	return nil, nil
 }
 
-func (m *mockAppointmentService) Update(ctx context.Context, id string, req *appointment.CreateAppointmentRequest) (*appointment.Appointment, error) {
+func (m *mockAppointmentService) Stream(ctx context.Context, filter *appointment.AppointmentFilter) iter.Seq2[appointment.Appointment, error] {
+	return nil
+}
+
+func (m *mockAppointmentService) Update(ctx context.Context, userID, id string, req *appointment.CreateAppointmentRequest) (*appointment.Appointment, error) {
 	return nil, nil
 }
 
-func (m *mockAppointmentService) Delete(ctx context.Context, id string) error {
+func (m *mockAppointmentService) Delete(ctx context.Context, userID, id string) error {
 	return nil
 }
 
-func (m *mockAppointmentService) Complete(ctx context.Context, id string) error {
+func (m *mockAppointmentService) Complete(ctx context.Context, userID, id string) error {
 	return nil
 }
 
-func (m *mockAppointmentService) Cancel(ctx context.Context, id string) error {
+func (m *mockAppointmentService) Cancel(ctx context.Context, userID, id string) error {
 	return nil
 }
 
@@ -54,6 +62,39 @@ func (m *mockAppointmentService) GetUpcoming(ctx context.Context, childID string
 	return m.upcoming, nil
 }
 
+func (m *mockAppointmentService) GetWellVisitSchedule() []appointment.WellVisit {
+	return nil
+}
+
+func (m *mockAppointmentService) SuggestWellVisits(ctx context.Context, userID, childID string) ([]appointment.WellVisitSuggestion, error) {
+	return nil, nil
+}
+
+func (m *mockAppointmentService) Screenings(ctx context.Context, userID, childID string) ([]appointment.ScreeningDue, error) {
+	return nil, nil
+}
+
+func (m *mockAppointmentService) PendingScreenings(ctx context.Context, child *family.Child) ([]appointment.ScreeningDue, error) {
+	return nil, nil
+}
+
+func (m *mockAppointmentService) DueGrowthReminders(ctx context.Context, now time.Time) ([]appointment.Appointment, error) {
+	return m.wellVisits, nil
+}
+
+func (m *mockAppointmentService) MarkGrowthReminded(ctx context.Context, id string, at time.Time) error {
+	m.reminded = append(m.reminded, id)
+	return nil
+}
+
+func (m *mockAppointmentService) RecordOutcome(ctx context.Context, userID, id string, req *appointment.RecordOutcomeRequest) (*appointment.Appointment, error) {
+	return nil, nil
+}
+
+func (m *mockAppointmentService) UpcomingForFamily(ctx context.Context, userID, familyID string, days int) ([]appointment.FamilyAppointment, error) {
+	return nil, nil
+}
+
 func TestNewAppointmentReminderJob(t *testing.T) {
 	aptSvc := newMockAppointmentService()
 	hub := notifications.NewHub()
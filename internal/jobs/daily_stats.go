@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/stats"
+)
+
+// DailyStatsJob keeps the per-child daily summaries current. Most runs only
+// pick up new days and recently edited children; once a night it rebuilds
+// the lot to catch anything the incremental runs missed.
+type DailyStatsJob struct {
+	statsService stats.Service
+	lastFull     string // date of the last nightly rebuild
+}
+
+func NewDailyStatsJob(statsService stats.Service) *DailyStatsJob {
+	return &DailyStatsJob{
+		statsService: statsService,
+	}
+}
+
+func (j *DailyStatsJob) Name() string {
+	return "daily-stats"
+}
+
+func (j *DailyStatsJob) Interval() time.Duration {
+	return 15 * time.Minute // Keeps the live part of dashboards short
+}
+
+func (j *DailyStatsJob) Run(ctx context.Context) error {
+	return j.refresh(ctx, time.Now())
+}
+
+func (j *DailyStatsJob) refresh(ctx context.Context, now time.Time) error {
+	today := now.Format(time.DateOnly)
+	// Rebuild once between 2 and 3 AM, when nobody is logging
+	full := now.Hour() == 2 && j.lastFull != today
+
+	written, err := j.statsService.RefreshDaily(ctx, full)
+	if err != nil {
+		return fmt.Errorf("failed to refresh daily stats: %w", err)
+	}
+	if full {
+		j.lastFull = today
+		log.Printf("[DailyStatsJob] Nightly rebuild wrote %d days", written)
+	} else if written > 0 {
+		log.Printf("[DailyStatsJob] Refreshed %d days", written)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDailyStatsJob_Name(t *testing.T) {
+	job := NewDailyStatsJob(nil)
+
+	if job.Name() != "daily-stats" {
+		t.Errorf("Name() = %v, want daily-stats", job.Name())
+	}
+}
+
+func TestDailyStatsJob_Interval(t *testing.T) {
+	job := NewDailyStatsJob(nil)
+
+	if job.Interval() != 15*time.Minute {
+		t.Errorf("Interval() = %v, want 15m", job.Interval())
+	}
+}
+
+func TestDailyStatsJob_NightlyRebuildOnce(t *testing.T) {
+	statsSvc := &mockStatsService{}
+	job := NewDailyStatsJob(statsSvc)
+
+	night := time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{
+		night.Add(-time.Hour),
+		night,
+		night.Add(15 * time.Minute),
+		night.Add(24 * time.Hour),
+	} {
+		if err := job.refresh(context.Background(), at); err != nil {
+			t.Fatalf("refresh() error = %v", err)
+		}
+	}
+
+	want := []bool{false, true, false, true}
+	for i := range want {
+		if statsSvc.dailyFull[i] != want[i] {
+			t.Errorf("run %d full = %v, want %v", i, statsSvc.dailyFull[i], want[i])
+		}
+	}
+}
+
+func TestDailyStatsJob_FailedRebuildRetries(t *testing.T) {
+	statsSvc := &mockStatsService{refreshErr: errors.New("database error")}
+	job := NewDailyStatsJob(statsSvc)
+
+	night := time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC)
+	if err := job.refresh(context.Background(), night); err == nil {
+		t.Fatal("refresh() should return error when refresh fails")
+	}
+
+	statsSvc.refreshErr = nil
+	if err := job.refresh(context.Background(), night.Add(15*time.Minute)); err != nil {
+		t.Fatalf("refresh() error = %v", err)
+	}
+	if !statsSvc.dailyFull[1] {
+		t.Error("refresh() should retry the nightly rebuild after a failure")
+	}
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/quality"
+)
+
+// DataQualityJob re-runs the data quality checks for every child.
+type DataQualityJob struct {
+	qualityService quality.Service
+}
+
+func NewDataQualityJob(qualityService quality.Service) *DataQualityJob {
+	return &DataQualityJob{
+		qualityService: qualityService,
+	}
+}
+
+func (j *DataQualityJob) Name() string {
+	return "data-quality"
+}
+
+func (j *DataQualityJob) Interval() time.Duration {
+	return 24 * time.Hour // Members can refresh a child's report on demand
+}
+
+func (j *DataQualityJob) Run(ctx context.Context) error {
+	log.Println("[DataQualityJob] Checking records...")
+
+	checked, err := j.qualityService.AnalyzeAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check data quality (%d children checked): %w", checked, err)
+	}
+
+	log.Printf("[DataQualityJob] Checked %d children", checked)
+	return nil
+}
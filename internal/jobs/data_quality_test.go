@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/quality"
+)
+
+// mockQualityService is a test double for quality.Service
+type mockQualityService struct {
+	quality.Service
+	analyzeCalls int
+	checked      int
+	analyzeErr   error
+}
+
+func (m *mockQualityService) AnalyzeAll(ctx context.Context) (int, error) {
+	m.analyzeCalls++
+	return m.checked, m.analyzeErr
+}
+
+func TestDataQualityJob_Name(t *testing.T) {
+	job := NewDataQualityJob(nil)
+
+	if job.Name() != "data-quality" {
+		t.Errorf("Name() = %v, want data-quality", job.Name())
+	}
+}
+
+func TestDataQualityJob_Interval(t *testing.T) {
+	job := NewDataQualityJob(nil)
+
+	if job.Interval() != 24*time.Hour {
+		t.Errorf("Interval() = %v, want 24h", job.Interval())
+	}
+}
+
+func TestDataQualityJob_Run(t *testing.T) {
+	qualitySvc := &mockQualityService{checked: 3}
+	job := NewDataQualityJob(qualitySvc)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if qualitySvc.analyzeCalls != 1 {
+		t.Errorf("Run() should analyze once, got %d calls", qualitySvc.analyzeCalls)
+	}
+}
+
+func TestDataQualityJob_Run_Error(t *testing.T) {
+	qualitySvc := &mockQualityService{checked: 2, analyzeErr: errors.New("database error")}
+	job := NewDataQualityJob(qualitySvc)
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when analysis fails")
+	}
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/daycare"
+)
+
+// DaycareSyncJob polls the daycare connections whose providers have an API
+// to poll. Pushed reports arrive through the webhook instead.
+type DaycareSyncJob struct {
+	daycareService daycare.Service
+}
+
+func NewDaycareSyncJob(daycareService daycare.Service) *DaycareSyncJob {
+	return &DaycareSyncJob{daycareService: daycareService}
+}
+
+func (j *DaycareSyncJob) Name() string {
+	return "daycare-sync"
+}
+
+func (j *DaycareSyncJob) Interval() time.Duration {
+	return 15 * time.Minute
+}
+
+func (j *DaycareSyncJob) Run(ctx context.Context) error {
+	imported, err := j.daycareService.SyncAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sync daycare connections: %w", err)
+	}
+	if imported > 0 {
+		log.Printf("[DaycareSyncJob] Imported %d daycare entries", imported)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/daycare"
+)
+
+// mockDaycareSyncService is a test double for daycare.Service
+type mockDaycareSyncService struct {
+	daycare.Service
+	calls int
+	err   error
+}
+
+func (m *mockDaycareSyncService) SyncAll(ctx context.Context) (int, error) {
+	m.calls++
+	return 3, m.err
+}
+
+func TestDaycareSyncJob_Name(t *testing.T) {
+	job := NewDaycareSyncJob(nil)
+
+	if job.Name() != "daycare-sync" {
+		t.Errorf("Name() = %v, want daycare-sync", job.Name())
+	}
+	if job.Interval() != 15*time.Minute {
+		t.Errorf("Interval() = %v, want 15m", job.Interval())
+	}
+}
+
+func TestDaycareSyncJob_Run(t *testing.T) {
+	daycareSvc := &mockDaycareSyncService{}
+	if err := NewDaycareSyncJob(daycareSvc).Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if daycareSvc.calls != 1 {
+		t.Errorf("Run() should sync once, got %d calls", daycareSvc.calls)
+	}
+
+	job := NewDaycareSyncJob(&mockDaycareSyncService{err: errors.New("database error")})
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when connections can't be listed")
+	}
+}
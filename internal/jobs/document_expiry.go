@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/documents"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+// DocumentExpiryJob reminds families once about each child document that
+// expires within the next month, such as an insurance card due for renewal.
+type DocumentExpiryJob struct {
+	documentsService documents.Service
+	notificationHub  *notifications.Hub
+}
+
+func NewDocumentExpiryJob(documentsService documents.Service, hub *notifications.Hub) *DocumentExpiryJob {
+	return &DocumentExpiryJob{
+		documentsService: documentsService,
+		notificationHub:  hub,
+	}
+}
+
+func (j *DocumentExpiryJob) Name() string {
+	return "document-expiry"
+}
+
+func (j *DocumentExpiryJob) Interval() time.Duration {
+	return 1 * time.Hour // Check every hour
+}
+
+func (j *DocumentExpiryJob) Run(ctx context.Context) error {
+	// Each document is only reminded about once, so wait until someone is
+	// connected to receive it
+	if j.notificationHub == nil || j.notificationHub.ClientCount() == 0 {
+		return nil
+	}
+
+	return j.sendReminders(ctx, time.Now())
+}
+
+func (j *DocumentExpiryJob) sendReminders(ctx context.Context, now time.Time) error {
+	due, err := j.documentsService.DueReminders(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for i := range due {
+		d := &due[i]
+		message := expiryMessage(d, now)
+		log.Printf("[DocumentExpiryJob] %s (Child: %s)", message, d.ChildID)
+
+		j.notificationHub.Broadcast(notifications.Event{
+			ID:        ids.New(),
+			Type:      notifications.EventDocumentExpiring,
+			Title:     "Document Expiring",
+			Message:   message,
+			ChildID:   d.ChildID,
+			Timestamp: now,
+		})
+
+		// Marked after sending, so a failure here repeats the reminder
+		// rather than losing it
+		if err := j.documentsService.MarkReminded(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	if len(due) > 0 {
+		log.Printf("[DocumentExpiryJob] Check complete. %d document reminders sent", len(due))
+	}
+	return nil
+}
+
+func expiryMessage(d *documents.Document, now time.Time) string {
+	expires, err := time.Parse(time.DateOnly, d.ExpiresOn)
+	if err != nil {
+		return fmt.Sprintf("%s is expiring soon", d.Title)
+	}
+
+	today, _ := time.Parse(time.DateOnly, now.Format(time.DateOnly))
+	switch days := int(expires.Sub(today).Hours() / 24); days {
+	case 0:
+		return fmt.Sprintf("%s expires today", d.Title)
+	case 1:
+		return fmt.Sprintf("%s expires tomorrow", d.Title)
+	default:
+		return fmt.Sprintf("%s expires on %s (in %d days)", d.Title, expires.Format("Mon Jan 2"), days)
+	}
+}
@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/documents"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+// mockDocumentsService is a test double for documents.Service
+type mockDocumentsService struct {
+	documents.Service
+	due      []documents.Document
+	dueErr   error
+	reminded []string
+}
+
+func (m *mockDocumentsService) DueReminders(ctx context.Context, now time.Time) ([]documents.Document, error) {
+	return m.due, m.dueErr
+}
+
+func (m *mockDocumentsService) MarkReminded(ctx context.Context, d *documents.Document) error {
+	m.reminded = append(m.reminded, d.ID)
+	return nil
+}
+
+func TestDocumentExpiryJob_Name(t *testing.T) {
+	job := NewDocumentExpiryJob(&mockDocumentsService{}, nil)
+	if job.Name() != "document-expiry" {
+		t.Errorf("Name() = %v, want document-expiry", job.Name())
+	}
+}
+
+func TestDocumentExpiryJob_Run_NoClients(t *testing.T) {
+	svc := &mockDocumentsService{due: []documents.Document{{ID: "doc-1", ExpiresOn: "2024-05-10"}}}
+	job := NewDocumentExpiryJob(svc, notifications.NewHub())
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(svc.reminded) != 0 {
+		t.Errorf("Run() marked %v reminded with nobody connected", svc.reminded)
+	}
+}
+
+func TestDocumentExpiryJob_SendReminders(t *testing.T) {
+	hub := notifications.NewHub()
+	go hub.Run()
+	time.Sleep(10 * time.Millisecond)
+
+	client := &notifications.Client{
+		UserID: "user-1",
+		Send:   make(chan []byte, 256),
+	}
+	hub.Register(client)
+	time.Sleep(10 * time.Millisecond)
+
+	svc := &mockDocumentsService{due: []documents.Document{
+		{ID: "doc-1", ChildID: "child-1", Title: "Insurance card", ExpiresOn: "2024-05-10"},
+	}}
+	job := NewDocumentExpiryJob(svc, hub)
+
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	if err := job.sendReminders(context.Background(), now); err != nil {
+		t.Fatalf("sendReminders() error = %v", err)
+	}
+
+	select {
+	case data := <-client.Send:
+		if !strings.Contains(string(data), "document_expiring") || !strings.Contains(string(data), "Insurance card expires on Fri May 10 (in 9 days)") {
+			t.Errorf("Expected an expiry notification for the insurance card, got %s", data)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected to receive an expiry notification")
+	}
+
+	if len(svc.reminded) != 1 || svc.reminded[0] != "doc-1" {
+		t.Errorf("sendReminders() marked %v reminded, want [doc-1]", svc.reminded)
+	}
+}
+
+func TestDocumentExpiryJob_SendReminders_Error(t *testing.T) {
+	job := NewDocumentExpiryJob(&mockDocumentsService{dueErr: errors.New("db down")}, notifications.NewHub())
+
+	if err := job.sendReminders(context.Background(), time.Now()); err == nil {
+		t.Error("sendReminders() should return the error from listing documents")
+	}
+}
+
+func TestExpiryMessage(t *testing.T) {
+	now := time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expiresOn string
+		want      string
+	}{
+		{"2024-05-01", "Consent form expires today"},
+		{"2024-05-02", "Consent form expires tomorrow"},
+		{"2024-05-31", "Consent form expires on Fri May 31 (in 30 days)"},
+		{"bad", "Consent form is expiring soon"},
+	}
+
+	for _, tt := range tests {
+		d := &documents.Document{Title: "Consent form", ExpiresOn: tt.expiresOn}
+		if got := expiryMessage(d, now); got != tt.want {
+			t.Errorf("expiryMessage(%s) = %q, want %q", tt.expiresOn, got, tt.want)
+		}
+	}
+}
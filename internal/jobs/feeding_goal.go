@@ -0,0 +1,108 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+// FeedingGoalJob sends an insight when a child's intake has run below their
+// feeding goal for several days in a row.
+type FeedingGoalJob struct {
+	feedingService  feeding.Service
+	notificationHub *notifications.Hub
+}
+
+func NewFeedingGoalJob(feedingService feeding.Service, hub *notifications.Hub) *FeedingGoalJob {
+	return &FeedingGoalJob{
+		feedingService:  feedingService,
+		notificationHub: hub,
+	}
+}
+
+func (j *FeedingGoalJob) Name() string {
+	return "feeding-goal"
+}
+
+func (j *FeedingGoalJob) Interval() time.Duration {
+	return 1 * time.Hour // Check every hour
+}
+
+func (j *FeedingGoalJob) Run(ctx context.Context) error {
+	now := time.Now()
+
+	// Only check once per day (between 8-9 AM), when yesterday is complete
+	if now.Hour() != 8 {
+		return nil
+	}
+
+	return j.checkGoals(ctx, now)
+}
+
+// checkGoals looks at every child with a goal and alerts for those whose
+// intake is persistently low
+func (j *FeedingGoalJob) checkGoals(ctx context.Context, now time.Time) error {
+	log.Println("[FeedingGoalJob] Checking intake against feeding goals...")
+
+	goals, err := j.feedingService.ListGoals(ctx)
+	if err != nil {
+		return err
+	}
+
+	alerted := 0
+	for _, goal := range goals {
+		// Today plus enough complete days to see the streak
+		stats, err := j.feedingService.GetStats(ctx, goal.ChildID, feeding.LowIntakeDays+1)
+		if err != nil {
+			log.Printf("[FeedingGoalJob] Error fetching stats for child %s: %v", goal.ChildID, err)
+			continue
+		}
+		if stats.DaysBelowTarget < feeding.LowIntakeDays {
+			continue
+		}
+
+		message := lowIntakeMessage(stats)
+		log.Printf("[FeedingGoalJob] %s (Child: %s)", message, goal.ChildID)
+		alerted++
+
+		if j.notificationHub != nil && j.notificationHub.ClientCount() > 0 {
+			j.notificationHub.Broadcast(notifications.Event{
+				ID:        ids.New(),
+				Type:      notifications.EventFeedingInsight,
+				Title:     "Feeding Insight",
+				Message:   message,
+				ChildID:   goal.ChildID,
+				Timestamp: now,
+			})
+		}
+	}
+
+	log.Printf("[FeedingGoalJob] Check complete. %d of %d children below target", alerted, len(goals))
+	return nil
+}
+
+// lowIntakeMessage summarises yesterday's intake against each goal
+func lowIntakeMessage(stats *feeding.Stats) string {
+	yesterday := stats.Days[len(stats.Days)-2]
+	goal := stats.Goal
+
+	var parts []string
+	if goal.DailyMl != nil {
+		parts = append(parts, fmt.Sprintf("%.0f of %.0f ml", yesterday.Ml, *goal.DailyMl))
+	}
+	if goal.DailyKcal != nil {
+		parts = append(parts, fmt.Sprintf("%.0f of %.0f kcal", yesterday.Kcal, *goal.DailyKcal))
+	}
+	if goal.DailyNursingMinutes != nil {
+		parts = append(parts, fmt.Sprintf("%.0f of %.0f nursing minutes", yesterday.NursingMinutes, *goal.DailyNursingMinutes))
+	}
+
+	return fmt.Sprintf("Intake has been below the daily goal for %d days. Yesterday: %s",
+		stats.DaysBelowTarget, strings.Join(parts, ", "))
+}
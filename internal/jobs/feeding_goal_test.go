@@ -0,0 +1,124 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+// mockFeedingService is a test double for feeding.Service
+type mockFeedingService struct {
+	feeding.Service
+	goals    []feeding.Goal
+	stats    map[string]*feeding.Stats
+	goalsErr error
+}
+
+func (m *mockFeedingService) ListGoals(ctx context.Context) ([]feeding.Goal, error) {
+	if m.goalsErr != nil {
+		return nil, m.goalsErr
+	}
+	return m.goals, nil
+}
+
+func (m *mockFeedingService) GetStats(ctx context.Context, childID string, days int) (*feeding.Stats, error) {
+	stats, ok := m.stats[childID]
+	if !ok {
+		return nil, errors.New("no stats")
+	}
+	return stats, nil
+}
+
+func lowIntakeStats(childID string, below int) *feeding.Stats {
+	ml := 700.0
+	return &feeding.Stats{
+		ChildID: childID,
+		Goal:    &feeding.Goal{ChildID: childID, DailyMl: &ml},
+		Days: []feeding.DailyIntake{
+			{Date: "2026-10-13", Ml: 400},
+			{Date: "2026-10-14", Ml: 420},
+			{Date: "2026-10-15", Ml: 410},
+			{Date: "2026-10-16", Ml: 150},
+		},
+		DaysBelowTarget: below,
+	}
+}
+
+func TestFeedingGoalJob_Name(t *testing.T) {
+	job := NewFeedingGoalJob(&mockFeedingService{}, nil)
+	if job.Name() != "feeding-goal" {
+		t.Errorf("Name() = %v, want feeding-goal", job.Name())
+	}
+}
+
+func TestFeedingGoalJob_Interval(t *testing.T) {
+	job := NewFeedingGoalJob(&mockFeedingService{}, nil)
+	if job.Interval() != time.Hour {
+		t.Errorf("Interval() = %v, want 1h", job.Interval())
+	}
+}
+
+func TestFeedingGoalJob_CheckGoals_Error(t *testing.T) {
+	job := NewFeedingGoalJob(&mockFeedingService{goalsErr: errors.New("db down")}, nil)
+
+	if err := job.checkGoals(context.Background(), time.Now()); err == nil {
+		t.Error("checkGoals() should return the error from listing goals")
+	}
+}
+
+func TestFeedingGoalJob_CheckGoals_PersistentlyLow(t *testing.T) {
+	svc := &mockFeedingService{
+		goals: []feeding.Goal{{ChildID: "child-1"}, {ChildID: "child-2"}, {ChildID: "child-3"}},
+		stats: map[string]*feeding.Stats{
+			"child-1": lowIntakeStats("child-1", feeding.LowIntakeDays),
+			"child-2": lowIntakeStats("child-2", feeding.LowIntakeDays-1),
+		},
+	}
+
+	hub := notifications.NewHub()
+	go hub.Run()
+	time.Sleep(10 * time.Millisecond)
+
+	client := &notifications.Client{
+		UserID: "user-1",
+		Send:   make(chan []byte, 256),
+	}
+	hub.Register(client)
+	time.Sleep(10 * time.Millisecond)
+
+	job := NewFeedingGoalJob(svc, hub)
+
+	if err := job.checkGoals(context.Background(), time.Now()); err != nil {
+		t.Fatalf("checkGoals() error = %v", err)
+	}
+
+	select {
+	case data := <-client.Send:
+		if !strings.Contains(string(data), "child-1") || !strings.Contains(string(data), "feeding_insight") {
+			t.Errorf("Expected a feeding insight for child-1, got %s", data)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected to receive a feeding insight")
+	}
+
+	// child-2 has not been low for long enough and child-3 has no stats
+	select {
+	case data := <-client.Send:
+		t.Errorf("Expected a single insight, also got %s", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestLowIntakeMessage(t *testing.T) {
+	msg := lowIntakeMessage(lowIntakeStats("child-1", 3))
+
+	want := "Intake has been below the daily goal for 3 days. Yesterday: 410 of 700 ml"
+	if msg != want {
+		t.Errorf("lowIntakeMessage() = %q, want %q", msg, want)
+	}
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/growth"
+)
+
+// GrowthPercentilesJob recomputes stored growth percentiles after the WHO
+// reference tables are updated. It runs at startup, so a release with new
+// tables catches up without anyone asking.
+type GrowthPercentilesJob struct {
+	growthService growth.Service
+}
+
+func NewGrowthPercentilesJob(growthService growth.Service) *GrowthPercentilesJob {
+	return &GrowthPercentilesJob{
+		growthService: growthService,
+	}
+}
+
+func (j *GrowthPercentilesJob) Name() string {
+	return "growth-percentiles"
+}
+
+func (j *GrowthPercentilesJob) Interval() time.Duration {
+	return 6 * time.Hour
+}
+
+func (j *GrowthPercentilesJob) Run(ctx context.Context) error {
+	n, err := j.growthService.RecomputePercentiles(ctx)
+	if errors.Is(err, growth.ErrRecomputeRunning) {
+		// An operator started one; it finishes the work
+		return nil
+	}
+	if n > 0 {
+		log.Printf("[GrowthPercentilesJob] Recomputed %d measurements against %s", n, growth.ReferenceVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to recompute growth percentiles: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/growth"
+)
+
+// mockGrowthRecomputeService is a test double for growth.Service
+type mockGrowthRecomputeService struct {
+	growth.Service
+	calls int
+	err   error
+}
+
+func (m *mockGrowthRecomputeService) RecomputePercentiles(ctx context.Context) (int, error) {
+	m.calls++
+	return 2, m.err
+}
+
+func TestGrowthPercentilesJob_Name(t *testing.T) {
+	job := NewGrowthPercentilesJob(nil)
+
+	if job.Name() != "growth-percentiles" {
+		t.Errorf("Name() = %v, want growth-percentiles", job.Name())
+	}
+}
+
+func TestGrowthPercentilesJob_Interval(t *testing.T) {
+	job := NewGrowthPercentilesJob(nil)
+
+	if job.Interval() != 6*time.Hour {
+		t.Errorf("Interval() = %v, want 6h", job.Interval())
+	}
+}
+
+func TestGrowthPercentilesJob_Run(t *testing.T) {
+	growthSvc := &mockGrowthRecomputeService{}
+	job := NewGrowthPercentilesJob(growthSvc)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if growthSvc.calls != 1 {
+		t.Errorf("Run() should recompute once, got %d calls", growthSvc.calls)
+	}
+}
+
+func TestGrowthPercentilesJob_Run_Errors(t *testing.T) {
+	job := NewGrowthPercentilesJob(&mockGrowthRecomputeService{err: growth.ErrRecomputeRunning})
+	if err := job.Run(context.Background()); err != nil {
+		t.Errorf("Run() error = %v, want nil while an operator's recompute runs", err)
+	}
+
+	job = NewGrowthPercentilesJob(&mockGrowthRecomputeService{err: errors.New("database error")})
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when the recompute fails")
+	}
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+// InvitePurgeJob drops family invites that expired or were accepted longer
+// ago than the invite retention period.
+type InvitePurgeJob struct {
+	familyService family.Service
+}
+
+func NewInvitePurgeJob(familyService family.Service) *InvitePurgeJob {
+	return &InvitePurgeJob{
+		familyService: familyService,
+	}
+}
+
+func (j *InvitePurgeJob) Name() string {
+	return "invite-purge"
+}
+
+func (j *InvitePurgeJob) Interval() time.Duration {
+	return 24 * time.Hour
+}
+
+func (j *InvitePurgeJob) Run(ctx context.Context) error {
+	purged, err := j.familyService.PurgeInvites(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to purge invites: %w", err)
+	}
+
+	if purged > 0 {
+		log.Printf("[InvitePurgeJob] Purged %d stale invites", purged)
+	}
+	return nil
+}
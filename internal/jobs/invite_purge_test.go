@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+// mockInviteFamilyService is a test double for family.Service
+type mockInviteFamilyService struct {
+	family.Service
+	purgedAt time.Time
+	purgeErr error
+}
+
+func (m *mockInviteFamilyService) PurgeInvites(ctx context.Context, now time.Time) (int64, error) {
+	m.purgedAt = now
+	return 4, m.purgeErr
+}
+
+func TestInvitePurgeJob_Name(t *testing.T) {
+	job := NewInvitePurgeJob(nil)
+
+	if job.Name() != "invite-purge" {
+		t.Errorf("Name() = %v, want invite-purge", job.Name())
+	}
+}
+
+func TestInvitePurgeJob_Run(t *testing.T) {
+	svc := &mockInviteFamilyService{}
+	job := NewInvitePurgeJob(svc)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if time.Since(svc.purgedAt) > time.Minute {
+		t.Errorf("Run() purged at %v, want now", svc.purgedAt)
+	}
+}
+
+func TestInvitePurgeJob_Run_Error(t *testing.T) {
+	job := NewInvitePurgeJob(&mockInviteFamilyService{purgeErr: errors.New("database error")})
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when purge fails")
+	}
+}
@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/archive"
+)
+
+// archiveBatch is how many rows move per statement, keeping each delete
+// short enough not to hold up writes to the live table
+const archiveBatch = 5000
+
+// LogArchiveJob moves feedings and sleep from months that have gone cold
+// into the archive tables.
+type LogArchiveJob struct {
+	archiver archive.Archiver
+	tables   []archive.Table
+}
+
+func NewLogArchiveJob(archiver archive.Archiver) *LogArchiveJob {
+	return &LogArchiveJob{
+		archiver: archiver,
+		tables:   archive.Tables,
+	}
+}
+
+func (j *LogArchiveJob) Name() string {
+	return "log-archive"
+}
+
+func (j *LogArchiveJob) Interval() time.Duration {
+	return 24 * time.Hour // A month goes cold once a month; daily catches it early
+}
+
+func (j *LogArchiveJob) Run(ctx context.Context) error {
+	cutoff := j.archiver.Cutoff(time.Now())
+
+	for _, t := range j.tables {
+		var total int64
+		for {
+			moved, err := j.archiver.Move(ctx, t, cutoff, archiveBatch)
+			if err != nil {
+				return err
+			}
+			total += moved
+			if moved < archiveBatch {
+				break
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		if total > 0 {
+			log.Printf("[LogArchiveJob] Archived %d %s from before %s", total, t.Name, cutoff.Format(time.DateOnly))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/archive"
+)
+
+// mockArchiver is a test double for archive.Archiver
+type mockArchiver struct {
+	cutoff  time.Time
+	pending map[string]int64
+	calls   map[string]int
+	moveErr error
+}
+
+func (m *mockArchiver) Cutoff(now time.Time) time.Time {
+	return m.cutoff
+}
+
+func (m *mockArchiver) Move(ctx context.Context, t archive.Table, before time.Time, limit int) (int64, error) {
+	if m.moveErr != nil {
+		return 0, m.moveErr
+	}
+	m.calls[t.Name]++
+	n := min(m.pending[t.Name], int64(limit))
+	m.pending[t.Name] -= n
+	return n, nil
+}
+
+func TestLogArchiveJob_Name(t *testing.T) {
+	job := NewLogArchiveJob(nil)
+
+	if job.Name() != "log-archive" {
+		t.Errorf("Name() = %v, want log-archive", job.Name())
+	}
+}
+
+func TestLogArchiveJob_Interval(t *testing.T) {
+	job := NewLogArchiveJob(nil)
+
+	if job.Interval() != 24*time.Hour {
+		t.Errorf("Interval() = %v, want 24h", job.Interval())
+	}
+}
+
+func TestLogArchiveJob_Run_MovesInBatches(t *testing.T) {
+	archiver := &mockArchiver{
+		pending: map[string]int64{"feedings": 2*archiveBatch + 10, "sleep_records": 0},
+		calls:   make(map[string]int),
+	}
+	job := NewLogArchiveJob(archiver)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if archiver.pending["feedings"] != 0 {
+		t.Errorf("Run() left %d feedings unarchived", archiver.pending["feedings"])
+	}
+	if archiver.calls["feedings"] != 3 {
+		t.Errorf("Run() moved feedings in %d batches, want 3", archiver.calls["feedings"])
+	}
+	if archiver.calls["sleep_records"] != 1 {
+		t.Errorf("Run() should still check sleep_records once, got %d", archiver.calls["sleep_records"])
+	}
+}
+
+func TestLogArchiveJob_Run_Error(t *testing.T) {
+	archiver := &mockArchiver{moveErr: errors.New("database error"), calls: make(map[string]int)}
+	job := NewLogArchiveJob(archiver)
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when a move fails")
+	}
+}
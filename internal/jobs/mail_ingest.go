@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/inbound"
+)
+
+// MailIngestJob files messages delivered to a Maildir as notes. The mail
+// server drops each message in new/; it is moved to cur/ once filed, or to
+// failed/ if it can't be, so nothing is processed twice.
+type MailIngestJob struct {
+	inboundService inbound.Service
+	maildir        string
+}
+
+func NewMailIngestJob(inboundService inbound.Service, maildir string) *MailIngestJob {
+	return &MailIngestJob{
+		inboundService: inboundService,
+		maildir:        maildir,
+	}
+}
+
+func (j *MailIngestJob) Name() string {
+	return "mail-ingest"
+}
+
+func (j *MailIngestJob) Interval() time.Duration {
+	return time.Minute
+}
+
+func (j *MailIngestJob) Run(ctx context.Context) error {
+	entries, err := os.ReadDir(filepath.Join(j.maildir, "new"))
+	if err != nil {
+		return fmt.Errorf("failed to read maildir: %w", err)
+	}
+
+	for _, dir := range []string{"cur", "failed"} {
+		if err := os.MkdirAll(filepath.Join(j.maildir, dir), 0o750); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	filed := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		dest := "cur"
+		if err := j.ingest(ctx, filepath.Join(j.maildir, "new", entry.Name())); err != nil {
+			log.Printf("[MailIngestJob] Could not file %s: %v", entry.Name(), err)
+			dest = "failed"
+		} else {
+			filed++
+		}
+
+		if err := os.Rename(filepath.Join(j.maildir, "new", entry.Name()), filepath.Join(j.maildir, dest, entry.Name())); err != nil {
+			return fmt.Errorf("failed to move %s: %w", entry.Name(), err)
+		}
+	}
+
+	if filed > 0 {
+		log.Printf("[MailIngestJob] Filed %d emails as notes", filed)
+	}
+	return nil
+}
+
+func (j *MailIngestJob) ingest(ctx context.Context, path string) error {
+	f, err := os.Open(path) //nolint:gosec // Path is within the configured maildir
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // Read-only file
+
+	_, err = j.inboundService.Ingest(ctx, f)
+	return err
+}
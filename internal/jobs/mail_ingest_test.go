@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/inbound"
+	"github.com/ninenine/babytrack/internal/notes"
+)
+
+// mockInboundService is a test double for inbound.Service
+type mockInboundService struct {
+	inbound.Service
+	ingested []string
+}
+
+func (m *mockInboundService) Ingest(ctx context.Context, r io.Reader) (*notes.Note, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m.ingested = append(m.ingested, string(data))
+	if strings.Contains(string(data), "bad") {
+		return nil, errors.New("sender is not a member of the family")
+	}
+	return &notes.Note{ID: "note-1"}, nil
+}
+
+func TestMailIngestJob_Name(t *testing.T) {
+	job := NewMailIngestJob(nil, "")
+
+	if job.Name() != "mail-ingest" {
+		t.Errorf("Name() = %v, want mail-ingest", job.Name())
+	}
+}
+
+func TestMailIngestJob_Interval(t *testing.T) {
+	job := NewMailIngestJob(nil, "")
+
+	if job.Interval() != time.Minute {
+		t.Errorf("Interval() = %v, want 1m", job.Interval())
+	}
+}
+
+func TestMailIngestJob_Run(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "new"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"1.eml": "good", "2.eml": "bad"} {
+		if err := os.WriteFile(filepath.Join(dir, "new", name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	svc := &mockInboundService{}
+	job := NewMailIngestJob(svc, dir)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(svc.ingested) != 2 {
+		t.Errorf("Run() ingested %d messages, want 2", len(svc.ingested))
+	}
+
+	for path, want := range map[string]bool{
+		"new/1.eml":    false,
+		"new/2.eml":    false,
+		"cur/1.eml":    true,
+		"failed/2.eml": true,
+	} {
+		_, err := os.Stat(filepath.Join(dir, path))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", path, exists, want)
+		}
+	}
+
+	// A second run finds nothing new
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if len(svc.ingested) != 2 {
+		t.Errorf("second Run() should not re-ingest, got %d messages", len(svc.ingested))
+	}
+}
+
+func TestMailIngestJob_Run_MissingMaildir(t *testing.T) {
+	job := NewMailIngestJob(&mockInboundService{}, filepath.Join(t.TempDir(), "missing"))
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when the maildir is missing")
+	}
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/media"
+)
+
+// MediaRescanJob retries the malware scan for uploads that were accepted while the scanner was unavailable.
+type MediaRescanJob struct {
+	mediaService media.Service
+}
+
+func NewMediaRescanJob(mediaService media.Service) *MediaRescanJob {
+	return &MediaRescanJob{
+		mediaService: mediaService,
+	}
+}
+
+func (j *MediaRescanJob) Name() string {
+	return "media-rescan"
+}
+
+func (j *MediaRescanJob) Interval() time.Duration {
+	return 15 * time.Minute
+}
+
+func (j *MediaRescanJob) Run(ctx context.Context) error {
+	log.Println("[MediaRescanJob] Rescanning pending uploads...")
+
+	if err := j.mediaService.RescanPending(ctx); err != nil {
+		return fmt.Errorf("failed to rescan pending media: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/media"
+)
+
+// mockMediaService is a test double for media.Service
+type mockMediaService struct {
+	media.Service
+	rescanCalls int
+	rescanErr   error
+}
+
+func (m *mockMediaService) RescanPending(ctx context.Context) error {
+	m.rescanCalls++
+	return m.rescanErr
+}
+
+func TestMediaRescanJob_Name(t *testing.T) {
+	job := NewMediaRescanJob(nil)
+
+	if job.Name() != "media-rescan" {
+		t.Errorf("Name() = %v, want media-rescan", job.Name())
+	}
+}
+
+func TestMediaRescanJob_Interval(t *testing.T) {
+	job := NewMediaRescanJob(nil)
+
+	if job.Interval() != 15*time.Minute {
+		t.Errorf("Interval() = %v, want 15m", job.Interval())
+	}
+}
+
+func TestMediaRescanJob_Run(t *testing.T) {
+	mediaSvc := &mockMediaService{}
+	job := NewMediaRescanJob(mediaSvc)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if mediaSvc.rescanCalls != 1 {
+		t.Errorf("Run() should rescan once, got %d calls", mediaSvc.rescanCalls)
+	}
+}
+
+func TestMediaRescanJob_Run_Error(t *testing.T) {
+	mediaSvc := &mockMediaService{rescanErr: errors.New("database error")}
+	job := NewMediaRescanJob(mediaSvc)
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when rescan fails")
+	}
+}
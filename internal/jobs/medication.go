@@ -6,10 +6,9 @@ import (
 	"log"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/ids"
 	"github.com/ninenine/babytrack/internal/medication"
 	"github.com/ninenine/babytrack/internal/notifications"
-
-	"github.com/google/uuid"
 )
 
 // MedicationReminderJob checks for medications that are due and sends notifications.
@@ -47,7 +46,7 @@ func (j *MedicationReminderJob) Run(ctx context.Context) error {
 
 	for _, med := range meds {
 		// Get the last log for this medication
-		lastLog, err := j.medicationService.GetLastLog(ctx, med.ID)
+		lastLog, err := j.medicationService.GetLastLog(ctx, med.ID, "")
 		if err != nil {
 			log.Printf("[MedicationReminderJob] Error getting last log for %s: %v", med.Name, err)
 			continue
@@ -63,7 +62,7 @@ func (j *MedicationReminderJob) Run(ctx context.Context) error {
 			// Broadcast notification to connected clients
 			if j.notificationHub != nil && j.notificationHub.ClientCount() > 0 {
 				j.notificationHub.Broadcast(notifications.Event{
-					ID:        uuid.New().String(),
+					ID:        ids.New(),
 					Type:      notifications.EventMedicationDue,
 					Title:     "Medication Due",
 					Message:   fmt.Sprintf("%s is due", med.Name),
@@ -85,30 +84,12 @@ func (j *MedicationReminderJob) isMedicationDue(med medication.Medication, lastL
 		return med.Frequency != "as_needed"
 	}
 
-	// Calculate the expected interval based on frequency
-	var expectedInterval time.Duration
-	switch med.Frequency {
-	case "once_daily":
-		expectedInterval = 24 * time.Hour
-	case "twice_daily":
-		expectedInterval = 12 * time.Hour
-	case "three_times_daily":
-		expectedInterval = 8 * time.Hour
-	case "four_times_daily":
-		expectedInterval = 6 * time.Hour
-	case "every_4_hours":
-		expectedInterval = 4 * time.Hour
-	case "every_6_hours":
-		expectedInterval = 6 * time.Hour
-	case "every_8_hours":
-		expectedInterval = 8 * time.Hour
-	case "as_needed":
+	expectedInterval, scheduled := medication.DoseInterval(med.Frequency)
+	if !scheduled {
 		return false // Never automatically due
-	default:
-		expectedInterval = 24 * time.Hour // Default to daily
 	}
 
-	// Add a 30-minute grace period before considering it due
+	// Allow a grace period before considering it due
 	timeSinceLastDose := now.Sub(lastLog.GivenAt)
-	return timeSinceLastDose >= expectedInterval-30*time.Minute
+	return timeSinceLastDose >= expectedInterval-medication.DoseGrace
 }
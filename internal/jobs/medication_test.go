@@ -2,9 +2,12 @@ package jobs
 
 import (
 	"context"
+	"iter"
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/delta"
 	"github.com/ninenine/babytrack/internal/medication"
 	"github.com/ninenine/babytrack/internal/notifications"
 )
@@ -27,7 +30,11 @@ func (m *mockMedicationService) Create(ctx context.Context, req *medication.Crea
 	return nil, nil
 }
 
-func (m *mockMedicationService) Get(ctx context.Context, id string) (*medication.Medication, error) {
+func (m *mockMedicationService) Get(ctx context.Context, userID, id string) (*medication.Medication, error) {
+	return nil, nil
+}
+
+func (m *mockMedicationService) ListSince(ctx context.Context, filter *medication.MedicationFilter, since time.Time) (*delta.Changes[medication.Medication], error) {
 	return nil, nil
 }
 
@@ -47,15 +54,19 @@ func (m *mockMedicationService) List(ctx context.Context, filter *medication.Med
 	return m.medications, nil
 }
 
-func (m *mockMedicationService) Update(ctx context.Context, id string, req *medication.CreateMedicationRequest) (*medication.Medication, error) {
+func (m *mockMedicationService) Stream(ctx context.Context, filter *medication.MedicationFilter) iter.Seq2[medication.Medication, error] {
+	return nil
+}
+
+func (m *mockMedicationService) Update(ctx context.Context, userID, id string, req *medication.CreateMedicationRequest) (*medication.Medication, error) {
 	return nil, nil
 }
 
-func (m *mockMedicationService) Delete(ctx context.Context, id string) error {
+func (m *mockMedicationService) Delete(ctx context.Context, userID, id string) error {
 	return nil
 }
 
-func (m *mockMedicationService) Deactivate(ctx context.Context, id string) error {
+func (m *mockMedicationService) Deactivate(ctx context.Context, userID, id string) error {
 	return nil
 }
 
@@ -63,11 +74,23 @@ func (m *mockMedicationService) LogMedication(ctx context.Context, userID string
 	return nil, nil
 }
 
-func (m *mockMedicationService) GetLogs(ctx context.Context, medicationID string) ([]medication.MedicationLog, error) {
+func (m *mockMedicationService) GetLogs(ctx context.Context, medicationID, viewerID string) ([]medication.MedicationLog, error) {
 	return nil, nil
 }
 
-func (m *mockMedicationService) GetLastLog(ctx context.Context, medicationID string) (*medication.MedicationLog, error) {
+func (m *mockMedicationService) CorrectLog(ctx context.Context, userID, medicationID, logID string, req *medication.CorrectLogRequest) (*medication.MedicationLog, error) {
+	return nil, nil
+}
+
+func (m *mockMedicationService) ListLogCorrections(ctx context.Context, userID, medicationID, logID string) ([]corrections.Correction, error) {
+	return nil, nil
+}
+
+func (m *mockMedicationService) SetLogPrivate(ctx context.Context, userID, medicationID, logID string, private bool) error {
+	return nil
+}
+
+func (m *mockMedicationService) GetLastLog(ctx context.Context, medicationID, viewerID string) (*medication.MedicationLog, error) {
 	if m.logErr != nil {
 		return nil, m.logErr
 	}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/replay"
+)
+
+// NoncePurgeJob drops expired replay-protection nonces so the table stays small.
+type NoncePurgeJob struct {
+	store replay.Store
+}
+
+func NewNoncePurgeJob(store replay.Store) *NoncePurgeJob {
+	return &NoncePurgeJob{
+		store: store,
+	}
+}
+
+func (j *NoncePurgeJob) Name() string {
+	return "nonce-purge"
+}
+
+func (j *NoncePurgeJob) Interval() time.Duration {
+	return 1 * time.Hour
+}
+
+func (j *NoncePurgeJob) Run(ctx context.Context) error {
+	purged, err := j.store.Purge(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to purge nonces: %w", err)
+	}
+
+	if purged > 0 {
+		log.Printf("[NoncePurgeJob] Purged %d expired nonces", purged)
+	}
+	return nil
+}
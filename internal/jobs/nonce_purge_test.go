@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/replay"
+)
+
+// mockReplayStore is a test double for replay.Store
+type mockReplayStore struct {
+	replay.Store
+	purgedBefore time.Time
+	purgeErr     error
+}
+
+func (m *mockReplayStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	m.purgedBefore = before
+	return 2, m.purgeErr
+}
+
+func TestNoncePurgeJob_Name(t *testing.T) {
+	job := NewNoncePurgeJob(nil)
+
+	if job.Name() != "nonce-purge" {
+		t.Errorf("Name() = %v, want nonce-purge", job.Name())
+	}
+}
+
+func TestNoncePurgeJob_Run(t *testing.T) {
+	store := &mockReplayStore{}
+	job := NewNoncePurgeJob(store)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if time.Since(store.purgedBefore) > time.Minute {
+		t.Errorf("Run() purged before %v, want now", store.purgedBefore)
+	}
+}
+
+func TestNoncePurgeJob_Run_Error(t *testing.T) {
+	job := NewNoncePurgeJob(&mockReplayStore{purgeErr: errors.New("database error")})
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when purge fails")
+	}
+}
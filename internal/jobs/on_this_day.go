@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/memories"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+// OnThisDayJob nudges families each morning when a child's journal has
+// entries from the same day in earlier months.
+type OnThisDayJob struct {
+	memoriesService memories.Service
+	notificationHub *notifications.Hub
+}
+
+func NewOnThisDayJob(memoriesService memories.Service, hub *notifications.Hub) *OnThisDayJob {
+	return &OnThisDayJob{
+		memoriesService: memoriesService,
+		notificationHub: hub,
+	}
+}
+
+func (j *OnThisDayJob) Name() string {
+	return "on-this-day"
+}
+
+func (j *OnThisDayJob) Interval() time.Duration {
+	return 1 * time.Hour // Check every hour
+}
+
+func (j *OnThisDayJob) Run(ctx context.Context) error {
+	now := time.Now()
+
+	// Only check once per day (between 9-10 AM)
+	if now.Hour() != 9 {
+		return nil
+	}
+
+	return j.sendMemories(ctx, now)
+}
+
+func (j *OnThisDayJob) sendMemories(ctx context.Context, now time.Time) error {
+	log.Println("[OnThisDayJob] Looking for memories from this day...")
+
+	counts, err := j.memoriesService.CountDue(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for childID, count := range counts {
+		message := memoriesMessage(count)
+		log.Printf("[OnThisDayJob] %s (Child: %s)", message, childID)
+
+		if j.notificationHub != nil && j.notificationHub.ClientCount() > 0 {
+			j.notificationHub.Broadcast(notifications.Event{
+				ID:        ids.New(),
+				Type:      notifications.EventOnThisDay,
+				Title:     "On This Day",
+				Message:   message,
+				ChildID:   childID,
+				Timestamp: now,
+			})
+		}
+	}
+
+	log.Printf("[OnThisDayJob] Check complete. %d children with memories", len(counts))
+	return nil
+}
+
+func memoriesMessage(count int) string {
+	if count == 1 {
+		return "You have a memory from this day. Take a look back in the journal."
+	}
+	return fmt.Sprintf("You have %d memories from this day. Take a look back in the journal.", count)
+}
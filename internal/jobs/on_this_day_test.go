@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/memories"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+// mockMemoriesService is a test double for memories.Service
+type mockMemoriesService struct {
+	memories.Service
+	counts   map[string]int
+	countErr error
+}
+
+func (m *mockMemoriesService) CountDue(ctx context.Context, date time.Time) (map[string]int, error) {
+	return m.counts, m.countErr
+}
+
+func TestOnThisDayJob_Name(t *testing.T) {
+	job := NewOnThisDayJob(&mockMemoriesService{}, nil)
+	if job.Name() != "on-this-day" {
+		t.Errorf("Name() = %v, want on-this-day", job.Name())
+	}
+}
+
+func TestOnThisDayJob_Interval(t *testing.T) {
+	job := NewOnThisDayJob(&mockMemoriesService{}, nil)
+	if job.Interval() != time.Hour {
+		t.Errorf("Interval() = %v, want 1h", job.Interval())
+	}
+}
+
+func TestOnThisDayJob_SendMemories_Error(t *testing.T) {
+	job := NewOnThisDayJob(&mockMemoriesService{countErr: errors.New("db down")}, nil)
+
+	if err := job.sendMemories(context.Background(), time.Now()); err == nil {
+		t.Error("sendMemories() should return the error from counting memories")
+	}
+}
+
+func TestOnThisDayJob_SendMemories(t *testing.T) {
+	hub := notifications.NewHub()
+	go hub.Run()
+	time.Sleep(10 * time.Millisecond)
+
+	client := &notifications.Client{
+		UserID: "user-1",
+		Send:   make(chan []byte, 256),
+	}
+	hub.Register(client)
+	time.Sleep(10 * time.Millisecond)
+
+	job := NewOnThisDayJob(&mockMemoriesService{counts: map[string]int{"child-1": 2}}, hub)
+
+	if err := job.sendMemories(context.Background(), time.Now()); err != nil {
+		t.Fatalf("sendMemories() error = %v", err)
+	}
+
+	select {
+	case data := <-client.Send:
+		if !strings.Contains(string(data), "child-1") || !strings.Contains(string(data), "on_this_day") {
+			t.Errorf("Expected an on-this-day notification for child-1, got %s", data)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected to receive an on-this-day notification")
+	}
+}
+
+func TestMemoriesMessage(t *testing.T) {
+	if got := memoriesMessage(1); got != "You have a memory from this day. Take a look back in the journal." {
+		t.Errorf("memoriesMessage(1) = %q", got)
+	}
+	if got := memoriesMessage(3); !strings.HasPrefix(got, "You have 3 memories") {
+		t.Errorf("memoriesMessage(3) = %q", got)
+	}
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/stats"
+)
+
+// PopulationStatsJob rebuilds the anonymised population curves from opted-in families.
+type PopulationStatsJob struct {
+	statsService stats.Service
+}
+
+func NewPopulationStatsJob(statsService stats.Service) *PopulationStatsJob {
+	return &PopulationStatsJob{
+		statsService: statsService,
+	}
+}
+
+func (j *PopulationStatsJob) Name() string {
+	return "population-stats"
+}
+
+func (j *PopulationStatsJob) Interval() time.Duration {
+	return 24 * time.Hour // Curves move slowly; refresh daily
+}
+
+func (j *PopulationStatsJob) Run(ctx context.Context) error {
+	log.Println("[PopulationStatsJob] Refreshing population stats...")
+
+	if err := j.statsService.Refresh(ctx); err != nil {
+		return fmt.Errorf("failed to refresh population stats: %w", err)
+	}
+
+	log.Println("[PopulationStatsJob] Population stats refreshed")
+	return nil
+}
@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/stats"
+)
+
+// mockStatsService is a test double for stats.Service
+type mockStatsService struct {
+	stats.Service
+	refreshCalls int
+	refreshErr   error
+	dailyFull    []bool
+}
+
+func (m *mockStatsService) Refresh(ctx context.Context) error {
+	m.refreshCalls++
+	return m.refreshErr
+}
+
+func (m *mockStatsService) RefreshDaily(ctx context.Context, full bool) (int, error) {
+	m.dailyFull = append(m.dailyFull, full)
+	return 0, m.refreshErr
+}
+
+func TestPopulationStatsJob_Name(t *testing.T) {
+	job := NewPopulationStatsJob(nil)
+
+	if job.Name() != "population-stats" {
+		t.Errorf("Name() = %v, want population-stats", job.Name())
+	}
+}
+
+func TestPopulationStatsJob_Interval(t *testing.T) {
+	job := NewPopulationStatsJob(nil)
+
+	if job.Interval() != 24*time.Hour {
+		t.Errorf("Interval() = %v, want 24h", job.Interval())
+	}
+}
+
+func TestPopulationStatsJob_Run(t *testing.T) {
+	statsSvc := &mockStatsService{}
+	job := NewPopulationStatsJob(statsSvc)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if statsSvc.refreshCalls != 1 {
+		t.Errorf("Run() should refresh once, got %d calls", statsSvc.refreshCalls)
+	}
+}
+
+func TestPopulationStatsJob_Run_Error(t *testing.T) {
+	statsSvc := &mockStatsService{refreshErr: errors.New("database error")}
+	job := NewPopulationStatsJob(statsSvc)
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when refresh fails")
+	}
+}
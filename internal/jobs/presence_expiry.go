@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/presence"
+)
+
+// PresenceExpiryJob ends presences whose heartbeats have stopped, telling the
+// rest of the family that nobody is logging any more.
+type PresenceExpiryJob struct {
+	presenceService presence.Service
+}
+
+func NewPresenceExpiryJob(presenceService presence.Service) *PresenceExpiryJob {
+	return &PresenceExpiryJob{
+		presenceService: presenceService,
+	}
+}
+
+func (j *PresenceExpiryJob) Name() string {
+	return "presence-expiry"
+}
+
+func (j *PresenceExpiryJob) Interval() time.Duration {
+	return 10 * time.Second
+}
+
+func (j *PresenceExpiryJob) Run(ctx context.Context) error {
+	ended, err := j.presenceService.Expire(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to expire presences: %w", err)
+	}
+
+	if ended > 0 {
+		log.Printf("[PresenceExpiryJob] Ended %d lapsed presences", ended)
+	}
+	return nil
+}
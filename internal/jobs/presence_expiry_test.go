@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/presence"
+)
+
+// mockPresenceService is a test double for presence.Service
+type mockPresenceService struct {
+	presence.Service
+	expiredAt time.Time
+	expireErr error
+}
+
+func (m *mockPresenceService) Expire(ctx context.Context, now time.Time) (int, error) {
+	m.expiredAt = now
+	return 1, m.expireErr
+}
+
+func TestPresenceExpiryJob_Name(t *testing.T) {
+	job := NewPresenceExpiryJob(nil)
+
+	if job.Name() != "presence-expiry" {
+		t.Errorf("Name() = %v, want presence-expiry", job.Name())
+	}
+}
+
+func TestPresenceExpiryJob_Run(t *testing.T) {
+	svc := &mockPresenceService{}
+	job := NewPresenceExpiryJob(svc)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if time.Since(svc.expiredAt) > time.Minute {
+		t.Errorf("Run() expired at %v, want now", svc.expiredAt)
+	}
+}
+
+func TestPresenceExpiryJob_Run_Error(t *testing.T) {
+	job := NewPresenceExpiryJob(&mockPresenceService{expireErr: errors.New("family lookup failed")})
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when expiry fails")
+	}
+}
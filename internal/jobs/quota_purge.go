@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/quota"
+)
+
+// QuotaPurgeJob drops daily API usage counts past their retention period.
+type QuotaPurgeJob struct {
+	quotaService quota.Service
+}
+
+func NewQuotaPurgeJob(quotaService quota.Service) *QuotaPurgeJob {
+	return &QuotaPurgeJob{
+		quotaService: quotaService,
+	}
+}
+
+func (j *QuotaPurgeJob) Name() string {
+	return "quota-purge"
+}
+
+func (j *QuotaPurgeJob) Interval() time.Duration {
+	return 24 * time.Hour
+}
+
+func (j *QuotaPurgeJob) Run(ctx context.Context) error {
+	purged, err := j.quotaService.Purge(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to purge usage counts: %w", err)
+	}
+
+	if purged > 0 {
+		log.Printf("[QuotaPurgeJob] Purged %d usage counts", purged)
+	}
+	return nil
+}
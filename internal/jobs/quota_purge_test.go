@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/quota"
+)
+
+// mockQuotaService is a test double for quota.Service
+type mockQuotaService struct {
+	quota.Service
+	purgedAt time.Time
+	purgeErr error
+}
+
+func (m *mockQuotaService) Purge(ctx context.Context, now time.Time) (int64, error) {
+	m.purgedAt = now
+	return 3, m.purgeErr
+}
+
+func TestQuotaPurgeJob_Name(t *testing.T) {
+	job := NewQuotaPurgeJob(nil)
+
+	if job.Name() != "quota-purge" {
+		t.Errorf("Name() = %v, want quota-purge", job.Name())
+	}
+}
+
+func TestQuotaPurgeJob_Run(t *testing.T) {
+	svc := &mockQuotaService{}
+	job := NewQuotaPurgeJob(svc)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if time.Since(svc.purgedAt) > time.Minute {
+		t.Errorf("Run() purged at %v, want now", svc.purgedAt)
+	}
+}
+
+func TestQuotaPurgeJob_Run_Error(t *testing.T) {
+	job := NewQuotaPurgeJob(&mockQuotaService{purgeErr: errors.New("database error")})
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when purge fails")
+	}
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/sandbox"
+)
+
+// SandboxResetJob wipes sandbox families whose reset interval has passed.
+type SandboxResetJob struct {
+	sandboxService sandbox.Service
+}
+
+func NewSandboxResetJob(sandboxService sandbox.Service) *SandboxResetJob {
+	return &SandboxResetJob{
+		sandboxService: sandboxService,
+	}
+}
+
+func (j *SandboxResetJob) Name() string {
+	return "sandbox-reset"
+}
+
+func (j *SandboxResetJob) Interval() time.Duration {
+	return time.Hour // Each sandbox keeps its own reset interval; this only picks up the due ones
+}
+
+func (j *SandboxResetJob) Run(ctx context.Context) error {
+	reset, err := j.sandboxService.ResetDue(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reset sandboxes (%d reset): %w", reset, err)
+	}
+
+	if reset > 0 {
+		log.Printf("[SandboxResetJob] Reset %d sandbox families", reset)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/sandbox"
+)
+
+// mockSandboxService is a test double for sandbox.Service
+type mockSandboxService struct {
+	sandbox.Service
+	resetCalls int
+	reset      int
+	resetErr   error
+}
+
+func (m *mockSandboxService) ResetDue(ctx context.Context) (int, error) {
+	m.resetCalls++
+	return m.reset, m.resetErr
+}
+
+func TestSandboxResetJob_Name(t *testing.T) {
+	job := NewSandboxResetJob(nil)
+
+	if job.Name() != "sandbox-reset" {
+		t.Errorf("Name() = %v, want sandbox-reset", job.Name())
+	}
+}
+
+func TestSandboxResetJob_Interval(t *testing.T) {
+	job := NewSandboxResetJob(nil)
+
+	if job.Interval() != time.Hour {
+		t.Errorf("Interval() = %v, want 1h", job.Interval())
+	}
+}
+
+func TestSandboxResetJob_Run(t *testing.T) {
+	sandboxSvc := &mockSandboxService{reset: 2}
+	job := NewSandboxResetJob(sandboxSvc)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if sandboxSvc.resetCalls != 1 {
+		t.Errorf("Run() should reset once, got %d calls", sandboxSvc.resetCalls)
+	}
+}
+
+func TestSandboxResetJob_Run_Error(t *testing.T) {
+	sandboxSvc := &mockSandboxService{reset: 1, resetErr: errors.New("database error")}
+	job := NewSandboxResetJob(sandboxSvc)
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when a reset fails")
+	}
+}
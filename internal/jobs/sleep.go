@@ -6,10 +6,9 @@ import (
 	"log"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/ids"
 	"github.com/ninenine/babytrack/internal/notifications"
 	"github.com/ninenine/babytrack/internal/sleep"
-
-	"github.com/google/uuid"
 )
 
 // Age-appropriate sleep recommendations (in hours)
@@ -96,7 +95,7 @@ func (j *SleepAnalyticsJob) checkLongSleeps(ctx context.Context, now time.Time)
 
 			if j.notificationHub != nil && j.notificationHub.ClientCount() > 0 {
 				j.notificationHub.Broadcast(notifications.Event{
-					ID:        uuid.New().String(),
+					ID:        ids.New(),
 					Type:      notifications.EventSleepInsight,
 					Title:     "Sleep Alert",
 					Message:   alertMessage,
@@ -129,15 +128,18 @@ func (j *SleepAnalyticsJob) runDailySummary(ctx context.Context, now time.Time)
 
 	// Group by child and calculate totals
 	type childStats struct {
-		totalSleep time.Duration
-		nightSleep time.Duration
-		napCount   int
-		napTime    time.Duration
+		totalSleep   time.Duration
+		nightSleep   time.Duration
+		napCount     int
+		napTime      time.Duration
+		nightWakings int
 	}
 	childData := make(map[string]*childStats)
 
 	for _, session := range sessions {
-		if session.EndTime == nil {
+		// Time awake during the night doesn't count as sleep
+		d, ok := session.AsleepDuration()
+		if !ok {
 			continue // Skip incomplete sessions
 		}
 
@@ -145,7 +147,7 @@ func (j *SleepAnalyticsJob) runDailySummary(ctx context.Context, now time.Time)
 			childData[session.ChildID] = &childStats{}
 		}
 
-		duration := session.EndTime.Sub(session.StartTime)
+		duration := time.Duration(d)
 		childData[session.ChildID].totalSleep += duration
 
 		if session.Type == sleep.SleepTypeNap {
@@ -153,6 +155,7 @@ func (j *SleepAnalyticsJob) runDailySummary(ctx context.Context, now time.Time)
 			childData[session.ChildID].napTime += duration
 		} else {
 			childData[session.ChildID].nightSleep += duration
+			childData[session.ChildID].nightWakings += len(session.Wakings)
 		}
 	}
 
@@ -162,8 +165,8 @@ func (j *SleepAnalyticsJob) runDailySummary(ctx context.Context, now time.Time)
 	for childID, stats := range childData {
 		totalHours := stats.totalSleep.Hours()
 
-		log.Printf("[SleepAnalyticsJob] Child %s: Total=%.1fh, Night=%.1fh, Naps=%d (%.1fh)",
-			childID, totalHours, stats.nightSleep.Hours(), stats.napCount, stats.napTime.Hours())
+		log.Printf("[SleepAnalyticsJob] Child %s: Total=%.1fh, Night=%.1fh (%d wakings), Naps=%d (%.1fh)",
+			childID, totalHours, stats.nightSleep.Hours(), stats.nightWakings, stats.napCount, stats.napTime.Hours())
 
 		// Generate insight message
 		var message string
@@ -185,10 +188,13 @@ func (j *SleepAnalyticsJob) runDailySummary(ctx context.Context, now time.Time)
 		if stats.napCount > 0 {
 			message += fmt.Sprintf(" Had %d nap(s).", stats.napCount)
 		}
+		if stats.nightWakings > 0 {
+			message += fmt.Sprintf(" Woke %d time(s) overnight.", stats.nightWakings)
+		}
 
 		if j.notificationHub != nil && j.notificationHub.ClientCount() > 0 {
 			j.notificationHub.Broadcast(notifications.Event{
-				ID:        uuid.New().String(),
+				ID:        ids.New(),
 				Type:      notifications.EventSleepInsight,
 				Title:     title,
 				Message:   message,
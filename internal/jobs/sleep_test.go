@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"context"
+	"iter"
 	"testing"
 	"time"
 
@@ -23,7 +24,7 @@ func (m *mockSleepService) Create(ctx context.Context, req *sleep.CreateSleepReq
 	return nil, nil
 }
 
-func (m *mockSleepService) Get(ctx context.Context, id string) (*sleep.Sleep, error) {
+func (m *mockSleepService) Get(ctx context.Context, userID, id string) (*sleep.Sleep, error) {
 	return nil, nil
 }
 
@@ -34,26 +35,62 @@ func (m *mockSleepService) List(ctx context.Context, filter *sleep.SleepFilter)
 	return m.sleeps, nil
 }
 
-func (m *mockSleepService) Update(ctx context.Context, id string, req *sleep.CreateSleepRequest) (*sleep.Sleep, error) {
+func (m *mockSleepService) Update(ctx context.Context, userID, id string, req *sleep.CreateSleepRequest) (*sleep.Sleep, error) {
 	return nil, nil
 }
 
-func (m *mockSleepService) Delete(ctx context.Context, id string) error {
+func (m *mockSleepService) Delete(ctx context.Context, userID, id string) error {
 	return nil
 }
 
-func (m *mockSleepService) StartSleep(ctx context.Context, childID string, sleepType sleep.SleepType) (*sleep.Sleep, error) {
+func (m *mockSleepService) StartSleep(ctx context.Context, userID, childID string, sleepType sleep.SleepType) (*sleep.Sleep, error) {
 	return nil, nil
 }
 
-func (m *mockSleepService) EndSleep(ctx context.Context, id string) (*sleep.Sleep, error) {
+func (m *mockSleepService) EndSleep(ctx context.Context, userID, id string) (*sleep.Sleep, error) {
 	return nil, nil
 }
 
-func (m *mockSleepService) GetActiveSleep(ctx context.Context, childID string) (*sleep.Sleep, error) {
+func (m *mockSleepService) GetActiveSleep(ctx context.Context, childID, viewerID string) (*sleep.Sleep, error) {
 	return nil, nil
 }
 
+func (m *mockSleepService) Stream(ctx context.Context, filter *sleep.SleepFilter) iter.Seq2[sleep.Sleep, error] {
+	return nil
+}
+
+func (m *mockSleepService) ListConflicts(ctx context.Context, childID string, from, to time.Time) ([]sleep.Conflict, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) Reconcile(ctx context.Context, childID string, from, to time.Time) (*sleep.ReconcileResult, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) Merge(ctx context.Context, userID, firstID, secondID string) (*sleep.EditResult, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) Split(ctx context.Context, userID, id string, at time.Time) (*sleep.EditResult, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) AddWaking(ctx context.Context, userID, sleepID string, req *sleep.WakingRequest) (*sleep.Sleep, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) EndWaking(ctx context.Context, userID, sleepID, wakingID string) (*sleep.Sleep, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) RemoveWaking(ctx context.Context, userID, sleepID, wakingID string) (*sleep.Sleep, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) SetPrivate(ctx context.Context, userID, id string, private bool) error {
+	return nil
+}
+
 func TestNewSleepAnalyticsJob(t *testing.T) {
 	sleepSvc := newMockSleepService()
 
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/delta"
+)
+
+// TombstonePurgeJob forgets deletions older than the store's retention;
+// cursors that old are refused, so nothing reads them any more.
+type TombstonePurgeJob struct {
+	tombstones delta.Tombstones
+}
+
+func NewTombstonePurgeJob(tombstones delta.Tombstones) *TombstonePurgeJob {
+	return &TombstonePurgeJob{
+		tombstones: tombstones,
+	}
+}
+
+func (j *TombstonePurgeJob) Name() string {
+	return "tombstone-purge"
+}
+
+func (j *TombstonePurgeJob) Interval() time.Duration {
+	return 24 * time.Hour
+}
+
+func (j *TombstonePurgeJob) Run(ctx context.Context) error {
+	purged, err := j.tombstones.Purge(ctx, time.Now().Add(-j.tombstones.Retention()))
+	if err != nil {
+		return fmt.Errorf("failed to purge tombstones: %w", err)
+	}
+
+	if purged > 0 {
+		log.Printf("[TombstonePurgeJob] Purged %d tombstones", purged)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/delta"
+)
+
+// mockTombstones is a test double for delta.Tombstones
+type mockTombstones struct {
+	delta.Tombstones
+	purgedBefore time.Time
+	purgeErr     error
+}
+
+func (m *mockTombstones) Purge(ctx context.Context, before time.Time) (int64, error) {
+	m.purgedBefore = before
+	return 4, m.purgeErr
+}
+
+func (m *mockTombstones) Retention() time.Duration {
+	return 30 * 24 * time.Hour
+}
+
+func TestTombstonePurgeJob_Name(t *testing.T) {
+	job := NewTombstonePurgeJob(nil)
+
+	if job.Name() != "tombstone-purge" {
+		t.Errorf("Name() = %v, want tombstone-purge", job.Name())
+	}
+}
+
+func TestTombstonePurgeJob_Run(t *testing.T) {
+	tombstones := &mockTombstones{}
+	job := NewTombstonePurgeJob(tombstones)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if age := time.Since(tombstones.purgedBefore); age < tombstones.Retention() || age > tombstones.Retention()+time.Minute {
+		t.Errorf("Run() purged before %v, want %v ago", tombstones.purgedBefore, tombstones.Retention())
+	}
+}
+
+func TestTombstonePurgeJob_Run_Error(t *testing.T) {
+	job := NewTombstonePurgeJob(&mockTombstones{purgeErr: errors.New("database error")})
+
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Run() should return error when purge fails")
+	}
+}
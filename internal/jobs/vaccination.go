@@ -6,16 +6,17 @@ import (
 	"log"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/closures"
+	"github.com/ninenine/babytrack/internal/ids"
 	"github.com/ninenine/babytrack/internal/notifications"
 	"github.com/ninenine/babytrack/internal/vaccination"
-
-	"github.com/google/uuid"
 )
 
 // VaccinationReminderJob checks for upcoming vaccinations and sends notifications.
 type VaccinationReminderJob struct {
 	vaccinationService vaccination.Service
 	notificationHub    *notifications.Hub
+	closuresService    closures.Service
 }
 
 func NewVaccinationReminderJob(vaccinationService vaccination.Service, hub *notifications.Hub) *VaccinationReminderJob {
@@ -25,6 +26,13 @@ func NewVaccinationReminderJob(vaccinationService vaccination.Service, hub *noti
 	}
 }
 
+// WithClosures moves reminders that fall on a family's closed days to the
+// next open day, for families that turn shifting on
+func (j *VaccinationReminderJob) WithClosures(closuresService closures.Service) *VaccinationReminderJob {
+	j.closuresService = closuresService
+	return j
+}
+
 func (j *VaccinationReminderJob) Name() string {
 	return "vaccination-reminder"
 }
@@ -46,11 +54,12 @@ func (j *VaccinationReminderJob) Run(ctx context.Context) error {
 	notifiedCount := 0
 
 	for _, vax := range upcoming {
-		if vax.Completed {
+		if vax.Status != vaccination.StatusScheduled {
 			continue
 		}
 
-		daysUntil := int(vax.ScheduledAt.Sub(now).Hours() / 24)
+		due := j.dueAt(ctx, &vax)
+		daysUntil := int(due.Sub(now).Hours() / 24)
 
 		// Only notify for vaccinations due today or in next 3 days
 		if daysUntil > 3 {
@@ -65,6 +74,9 @@ func (j *VaccinationReminderJob) Run(ctx context.Context) error {
 		} else {
 			message = fmt.Sprintf("%s (Dose %d) is due in %d days", vax.Name, vax.Dose, daysUntil)
 		}
+		if !due.Equal(vax.ScheduledAt) {
+			message += fmt.Sprintf(" (moved from %s, when the clinic is closed)", vax.ScheduledAt.In(due.Location()).Format("Mon Jan 2"))
+		}
 
 		log.Printf("[VaccinationReminderJob] %s (Child: %s)", message, vax.ChildID)
 		notifiedCount++
@@ -72,7 +84,7 @@ func (j *VaccinationReminderJob) Run(ctx context.Context) error {
 		// Broadcast notification to connected clients
 		if j.notificationHub != nil && j.notificationHub.ClientCount() > 0 {
 			j.notificationHub.Broadcast(notifications.Event{
-				ID:        uuid.New().String(),
+				ID:        ids.New(),
 				Type:      notifications.EventVaccinationDue,
 				Title:     "Vaccination Reminder",
 				Message:   message,
@@ -85,3 +97,17 @@ func (j *VaccinationReminderJob) Run(ctx context.Context) error {
 	log.Printf("[VaccinationReminderJob] Check complete. %d vaccination reminders sent", notifiedCount)
 	return nil
 }
+
+// dueAt returns when the vaccination's reminder is due, after any shift off
+// the family's closed days. The scheduled time stands if the lookup fails.
+func (j *VaccinationReminderJob) dueAt(ctx context.Context, vax *vaccination.Vaccination) time.Time {
+	if j.closuresService == nil {
+		return vax.ScheduledAt
+	}
+	due, err := j.closuresService.ShiftForChild(ctx, vax.ChildID, vax.ScheduledAt)
+	if err != nil {
+		log.Printf("[VaccinationReminderJob] Failed to check closures for %s: %v", vax.ID, err)
+		return vax.ScheduledAt
+	}
+	return due
+}
@@ -2,10 +2,16 @@ package jobs
 
 import (
 	"context"
+	"iter"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/closures"
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/delta"
 	"github.com/ninenine/babytrack/internal/notifications"
+	"github.com/ninenine/babytrack/internal/testutil/factory"
 	"github.com/ninenine/babytrack/internal/vaccination"
 )
 
@@ -23,7 +29,7 @@ func (m *mockVaccinationService) Create(ctx context.Context, req *vaccination.Cr
 	return nil, nil
 }
 
-func (m *mockVaccinationService) Get(ctx context.Context, id string) (*vaccination.Vaccination, error) {
+func (m *mockVaccinationService) Get(ctx context.Context, userID, id string) (*vaccination.Vaccination, error) {
 	return nil, nil
 }
 
@@ -31,15 +37,35 @@ func (m *mockVaccinationService) List(ctx context.Context, filter *vaccination.V
 	return nil, nil
 }
 
-func (m *mockVaccinationService) Update(ctx context.Context, id string, req *vaccination.CreateVaccinationRequest) (*vaccination.Vaccination, error) {
+func (m *mockVaccinationService) Stream(ctx context.Context, filter *vaccination.VaccinationFilter) iter.Seq2[vaccination.Vaccination, error] {
+	return nil
+}
+
+func (m *mockVaccinationService) ListSince(ctx context.Context, filter *vaccination.VaccinationFilter, since time.Time) (*delta.Changes[vaccination.Vaccination], error) {
+	return nil, nil
+}
+
+func (m *mockVaccinationService) Update(ctx context.Context, userID, id string, req *vaccination.CreateVaccinationRequest) (*vaccination.Vaccination, error) {
 	return nil, nil
 }
 
-func (m *mockVaccinationService) Delete(ctx context.Context, id string) error {
+func (m *mockVaccinationService) Delete(ctx context.Context, userID, id string) error {
 	return nil
 }
 
-func (m *mockVaccinationService) RecordAdministration(ctx context.Context, id string, req *vaccination.RecordVaccinationRequest) (*vaccination.Vaccination, error) {
+func (m *mockVaccinationService) RecordAdministration(ctx context.Context, userID, id string, req *vaccination.RecordVaccinationRequest) (*vaccination.Vaccination, error) {
+	return nil, nil
+}
+
+func (m *mockVaccinationService) Correct(ctx context.Context, userID, id string, req *vaccination.CorrectVaccinationRequest) (*vaccination.Vaccination, error) {
+	return nil, nil
+}
+
+func (m *mockVaccinationService) ListCorrections(ctx context.Context, userID, id string) ([]corrections.Correction, error) {
+	return nil, nil
+}
+
+func (m *mockVaccinationService) SetStatus(ctx context.Context, userID, id string, req *vaccination.SetStatusRequest) (*vaccination.Vaccination, error) {
 	return nil, nil
 }
 
@@ -58,6 +84,14 @@ func (m *mockVaccinationService) GenerateScheduleForChild(ctx context.Context, c
 	return nil, nil
 }
 
+func (m *mockVaccinationService) GetCoverage(ctx context.Context, childID string, asOf *time.Time) (*vaccination.CoverageReport, error) {
+	return nil, nil
+}
+
+func (m *mockVaccinationService) SuggestPrefill(ctx context.Context, childID, name string) (*vaccination.Prefill, error) {
+	return nil, nil
+}
+
 func TestNewVaccinationReminderJob(t *testing.T) {
 	vaxSvc := newMockVaccinationService()
 	hub := notifications.NewHub()
@@ -108,9 +142,9 @@ func TestVaccinationReminderJob_Run_WithUpcoming(t *testing.T) {
 	now := time.Now()
 	vaxSvc := newMockVaccinationService()
 	vaxSvc.upcoming = []vaccination.Vaccination{
-		{ID: "vax-1", Name: "DTaP", Dose: 1, ChildID: "child-1", ScheduledAt: now.AddDate(0, 0, 1), Completed: false},
-		{ID: "vax-2", Name: "Polio", Dose: 1, ChildID: "child-1", ScheduledAt: now.AddDate(0, 0, 2), Completed: false},
-		{ID: "vax-3", Name: "Completed", Dose: 1, ChildID: "child-1", ScheduledAt: now, Completed: true},
+		{ID: "vax-1", Name: "DTaP", Dose: 1, ChildID: "child-1", ScheduledAt: now.AddDate(0, 0, 1), Status: vaccination.StatusScheduled},
+		{ID: "vax-2", Name: "Polio", Dose: 1, ChildID: "child-1", ScheduledAt: now.AddDate(0, 0, 2), Status: vaccination.StatusScheduled},
+		{ID: "vax-3", Name: "Completed", Dose: 1, ChildID: "child-1", ScheduledAt: now, Status: vaccination.StatusCompleted},
 	}
 
 	job := NewVaccinationReminderJob(vaxSvc, nil)
@@ -125,7 +159,7 @@ func TestVaccinationReminderJob_Run_DueToday(t *testing.T) {
 	now := time.Now()
 	vaxSvc := newMockVaccinationService()
 	vaxSvc.upcoming = []vaccination.Vaccination{
-		{ID: "vax-1", Name: "DTaP", Dose: 1, ChildID: "child-1", ScheduledAt: now, Completed: false},
+		factory.Vaccination(func(v *vaccination.Vaccination) { v.ScheduledAt = now }),
 	}
 
 	hub := notifications.NewHub()
@@ -161,7 +195,7 @@ func TestVaccinationReminderJob_Run_DueTomorrow(t *testing.T) {
 	now := time.Now()
 	vaxSvc := newMockVaccinationService()
 	vaxSvc.upcoming = []vaccination.Vaccination{
-		{ID: "vax-1", Name: "DTaP", Dose: 2, ChildID: "child-1", ScheduledAt: now.AddDate(0, 0, 1), Completed: false},
+		{ID: "vax-1", Name: "DTaP", Dose: 2, ChildID: "child-1", ScheduledAt: now.AddDate(0, 0, 1), Status: vaccination.StatusScheduled},
 	}
 
 	hub := notifications.NewHub()
@@ -196,7 +230,7 @@ func TestVaccinationReminderJob_Run_DueInFewDays(t *testing.T) {
 	now := time.Now()
 	vaxSvc := newMockVaccinationService()
 	vaxSvc.upcoming = []vaccination.Vaccination{
-		{ID: "vax-1", Name: "DTaP", Dose: 3, ChildID: "child-1", ScheduledAt: now.AddDate(0, 0, 3), Completed: false},
+		{ID: "vax-1", Name: "DTaP", Dose: 3, ChildID: "child-1", ScheduledAt: now.AddDate(0, 0, 3), Status: vaccination.StatusScheduled},
 	}
 
 	hub := notifications.NewHub()
@@ -231,7 +265,7 @@ func TestVaccinationReminderJob_Run_TooFarAway(t *testing.T) {
 	now := time.Now()
 	vaxSvc := newMockVaccinationService()
 	vaxSvc.upcoming = []vaccination.Vaccination{
-		{ID: "vax-1", Name: "DTaP", Dose: 1, ChildID: "child-1", ScheduledAt: now.AddDate(0, 0, 5), Completed: false},
+		{ID: "vax-1", Name: "DTaP", Dose: 1, ChildID: "child-1", ScheduledAt: now.AddDate(0, 0, 5), Status: vaccination.StatusScheduled},
 	}
 
 	hub := notifications.NewHub()
@@ -265,7 +299,7 @@ func TestVaccinationReminderJob_Run_SkipsCompleted(t *testing.T) {
 	now := time.Now()
 	vaxSvc := newMockVaccinationService()
 	vaxSvc.upcoming = []vaccination.Vaccination{
-		{ID: "vax-1", Name: "DTaP", Dose: 1, ChildID: "child-1", ScheduledAt: now, Completed: true},
+		{ID: "vax-1", Name: "DTaP", Dose: 1, ChildID: "child-1", ScheduledAt: now, Status: vaccination.StatusCompleted},
 	}
 
 	hub := notifications.NewHub()
@@ -294,3 +328,80 @@ func TestVaccinationReminderJob_Run_SkipsCompleted(t *testing.T) {
 		// Expected - no notification
 	}
 }
+
+// mockClosuresService moves every due time by a fixed shift
+type mockClosuresService struct {
+	closures.Service
+	shift time.Duration
+}
+
+func (m *mockClosuresService) ShiftForChild(ctx context.Context, childID string, due time.Time) (time.Time, error) {
+	return due.Add(m.shift), nil
+}
+
+func TestVaccinationReminderJob_Run_ShiftedByClosures(t *testing.T) {
+	now := time.Now()
+	vaxSvc := newMockVaccinationService()
+	vaxSvc.upcoming = []vaccination.Vaccination{
+		factory.Vaccination(func(v *vaccination.Vaccination) { v.ScheduledAt = now.AddDate(0, 0, 2) }),
+	}
+
+	hub := notifications.NewHub()
+	go hub.Run()
+	time.Sleep(10 * time.Millisecond)
+
+	client := &notifications.Client{
+		UserID: "user-1",
+		Send:   make(chan []byte, 256),
+	}
+	hub.Register(client)
+	time.Sleep(10 * time.Millisecond)
+
+	// A long weekend of closures pushes the reminder past the 3 day window
+	job := NewVaccinationReminderJob(vaxSvc, hub).WithClosures(&mockClosuresService{shift: 72 * time.Hour})
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	select {
+	case <-client.Send:
+		t.Error("Should not receive notification once the due date moves past the window")
+	case <-time.After(50 * time.Millisecond):
+		// Expected - no notification
+	}
+}
+
+func TestVaccinationReminderJob_Run_ShiftNoted(t *testing.T) {
+	now := time.Now()
+	vaxSvc := newMockVaccinationService()
+	vaxSvc.upcoming = []vaccination.Vaccination{
+		factory.Vaccination(func(v *vaccination.Vaccination) { v.ScheduledAt = now }),
+	}
+
+	hub := notifications.NewHub()
+	go hub.Run()
+	time.Sleep(10 * time.Millisecond)
+
+	client := &notifications.Client{
+		UserID: "user-1",
+		Send:   make(chan []byte, 256),
+	}
+	hub.Register(client)
+	time.Sleep(10 * time.Millisecond)
+
+	job := NewVaccinationReminderJob(vaxSvc, hub).WithClosures(&mockClosuresService{shift: 24 * time.Hour})
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	select {
+	case data := <-client.Send:
+		if !strings.Contains(string(data), "(moved from") {
+			t.Errorf("Expected the message to note the move, got %s", data)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Expected to receive notification for the shifted vaccination")
+	}
+}
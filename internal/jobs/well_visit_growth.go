@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+// WellVisitGrowthJob asks families, once per well visit, to record the
+// weight and length taken at it. Visits that already have a measurement
+// from that day onwards are marked without a reminder.
+type WellVisitGrowthJob struct {
+	appointmentService appointment.Service
+	growthService      growth.Service
+	notificationHub    *notifications.Hub
+}
+
+func NewWellVisitGrowthJob(appointmentService appointment.Service, growthService growth.Service, hub *notifications.Hub) *WellVisitGrowthJob {
+	return &WellVisitGrowthJob{
+		appointmentService: appointmentService,
+		growthService:      growthService,
+		notificationHub:    hub,
+	}
+}
+
+func (j *WellVisitGrowthJob) Name() string {
+	return "well-visit-growth"
+}
+
+func (j *WellVisitGrowthJob) Interval() time.Duration {
+	return 1 * time.Hour
+}
+
+func (j *WellVisitGrowthJob) Run(ctx context.Context) error {
+	// Each visit is only reminded about once, so wait until someone is
+	// connected to receive it
+	if j.notificationHub == nil || j.notificationHub.ClientCount() == 0 {
+		return nil
+	}
+
+	return j.sendReminders(ctx, time.Now())
+}
+
+func (j *WellVisitGrowthJob) sendReminders(ctx context.Context, now time.Time) error {
+	visits, err := j.appointmentService.DueGrowthReminders(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for i := range visits {
+		apt := &visits[i]
+
+		// Measurements are often logged as of the start of the visit day
+		since := apt.ScheduledAt.Add(-24 * time.Hour)
+		recorded, err := j.growthService.List(ctx, &growth.MeasurementFilter{ChildID: apt.ChildID, StartDate: &since})
+		if err != nil {
+			log.Printf("[WellVisitGrowthJob] Failed to check measurements for %s: %v", apt.ID, err)
+			continue
+		}
+
+		if len(recorded) == 0 {
+			message := fmt.Sprintf("Record the weight and length from %s on %s", apt.Title, apt.ScheduledAt.Format("Mon Jan 2"))
+			log.Printf("[WellVisitGrowthJob] %s (Child: %s)", message, apt.ChildID)
+
+			j.notificationHub.Broadcast(notifications.Event{
+				ID:        ids.New(),
+				Type:      notifications.EventGrowthReminder,
+				Title:     "Record Growth",
+				Message:   message,
+				ChildID:   apt.ChildID,
+				Timestamp: now,
+			})
+			sent++
+		}
+
+		// Marked after sending, so a failure here repeats the reminder
+		// rather than losing it
+		if err := j.appointmentService.MarkGrowthReminded(ctx, apt.ID, now); err != nil {
+			return err
+		}
+	}
+
+	if sent > 0 {
+		log.Printf("[WellVisitGrowthJob] Check complete. %d growth reminders sent", sent)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+// mockGrowthListService is a test double for growth.Service that returns
+// measurements by child
+type mockGrowthListService struct {
+	growth.Service
+	measurements map[string][]growth.Measurement
+}
+
+func (m *mockGrowthListService) List(ctx context.Context, filter *growth.MeasurementFilter) ([]growth.Measurement, error) {
+	return m.measurements[filter.ChildID], nil
+}
+
+func TestWellVisitGrowthJob_Name(t *testing.T) {
+	job := NewWellVisitGrowthJob(newMockAppointmentService(), &mockGrowthListService{}, nil)
+	if job.Name() != "well-visit-growth" {
+		t.Errorf("Name() = %v, want well-visit-growth", job.Name())
+	}
+}
+
+func TestWellVisitGrowthJob_SendReminders(t *testing.T) {
+	hub := notifications.NewHub()
+	go hub.Run()
+	time.Sleep(10 * time.Millisecond)
+
+	client := &notifications.Client{
+		UserID: "user-1",
+		Send:   make(chan []byte, 256),
+	}
+	hub.Register(client)
+	time.Sleep(10 * time.Millisecond)
+
+	visit := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	aptSvc := newMockAppointmentService()
+	aptSvc.wellVisits = []appointment.Appointment{
+		{ID: "apt-1", ChildID: "child-1", Type: appointment.AppointmentTypeWellVisit, Title: "2 month checkup", ScheduledAt: visit, Duration: 30},
+		{ID: "apt-2", ChildID: "child-2", Type: appointment.AppointmentTypeWellVisit, Title: "4 month checkup", ScheduledAt: visit, Duration: 30},
+	}
+	growthSvc := &mockGrowthListService{measurements: map[string][]growth.Measurement{
+		"child-2": {{ID: "m-1", ChildID: "child-2", MeasuredAt: visit}},
+	}}
+	job := NewWellVisitGrowthJob(aptSvc, growthSvc, hub)
+
+	if err := job.sendReminders(context.Background(), visit.Add(2*time.Hour)); err != nil {
+		t.Fatalf("sendReminders() error = %v", err)
+	}
+
+	select {
+	case data := <-client.Send:
+		if !strings.Contains(string(data), "growth_reminder") || !strings.Contains(string(data), "Record the weight and length from 2 month checkup on Thu May 2") {
+			t.Errorf("Expected a growth reminder for the 2 month checkup, got %s", data)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected to receive a growth reminder")
+	}
+	select {
+	case data := <-client.Send:
+		t.Errorf("Expected no reminder for a visit with measurements, got %s", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if len(aptSvc.reminded) != 2 {
+		t.Errorf("reminded = %v, want both visits marked", aptSvc.reminded)
+	}
+}
@@ -0,0 +1,127 @@
+package journal
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.list)
+	rg.POST("", h.create)
+	rg.GET("/:id", h.get)
+	rg.PUT("/:id", h.update)
+	rg.DELETE("/:id", h.delete)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	filter := &EntryFilter{
+		ChildID:       c.Query("child_id"),
+		MilestoneOnly: c.Query("milestone") == "true",
+	}
+	if filter.ChildID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "child_id is required"})
+		return
+	}
+
+	var err error
+	if filter.From, err = parseDate(c.Query("from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date"})
+		return
+	}
+	if filter.To, err = parseDate(c.Query("to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date"})
+		return
+	}
+
+	months, err := h.service.ListByMonth(c.Request.Context(), c.GetString("user_id"), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, months)
+}
+
+func (h *Handler) create(c *gin.Context) {
+	var req CreateEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	e, err := h.service.Create(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, e)
+}
+
+func (h *Handler) get(c *gin.Context) {
+	e, err := h.service.Get(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+func (h *Handler) update(c *gin.Context) {
+	var req UpdateEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	e, err := h.service.Update(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// parseDate reads an optional YYYY-MM-DD query value
+func parseDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrEmptyEntry), errors.Is(err, ErrInvalidDate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrEntryNotFound), errors.Is(err, ErrChildNotFound), errors.Is(err, ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, visibility.ErrHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,131 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	Service
+	createFn      func(ctx context.Context, userID string, req *CreateEntryRequest) (*Entry, error)
+	listByMonthFn func(ctx context.Context, userID string, filter *EntryFilter) ([]Month, error)
+	getFn         func(ctx context.Context, userID, id string) (*Entry, error)
+}
+
+func (m *mockService) Create(ctx context.Context, userID string, req *CreateEntryRequest) (*Entry, error) {
+	return m.createFn(ctx, userID, req)
+}
+
+func (m *mockService) ListByMonth(ctx context.Context, userID string, filter *EntryFilter) ([]Month, error) {
+	return m.listByMonthFn(ctx, userID, filter)
+}
+
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Entry, error) {
+	return m.getFn(ctx, userID, id)
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterRoutes(router.Group("/journal"))
+	return router
+}
+
+func TestHandler_List(t *testing.T) {
+	svc := &mockService{listByMonthFn: func(ctx context.Context, userID string, filter *EntryFilter) ([]Month, error) {
+		if filter.ChildID != "child-1" || !filter.MilestoneOnly || filter.From == nil || filter.To != nil {
+			t.Errorf("ListByMonth() filter = %+v", filter)
+		}
+		return []Month{{Month: "2024-03", Entries: []Entry{{ID: "entry-1"}}}}, nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("GET", "/journal?child_id=child-1&milestone=true&from=2024-01-01", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var months []Month
+	if err := json.Unmarshal(w.Body.Bytes(), &months); err != nil || len(months) != 1 || months[0].Month != "2024-03" {
+		t.Errorf("Expected one month, got %s", w.Body.String())
+	}
+}
+
+func TestHandler_List_Validation(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	for _, target := range []string{"/journal", "/journal?child_id=child-1&to=March"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: expected status 400, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestHandler_Create(t *testing.T) {
+	svc := &mockService{createFn: func(ctx context.Context, userID string, req *CreateEntryRequest) (*Entry, error) {
+		return &Entry{ID: "entry-1", ChildID: req.ChildID, Caption: req.Caption, Date: req.Date}, nil
+	}}
+
+	body, _ := json.Marshal(CreateEntryRequest{ChildID: "child-1", Caption: "First tooth", Date: "2024-03-10"})
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("POST", "/journal", bytes.NewReader(body)))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+}
+
+func TestHandler_Create_Invalid(t *testing.T) {
+	svc := &mockService{createFn: func(ctx context.Context, userID string, req *CreateEntryRequest) (*Entry, error) {
+		return nil, ErrEmptyEntry
+	}}
+
+	body, _ := json.Marshal(CreateEntryRequest{ChildID: "child-1", Date: "2024-03-10"})
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("POST", "/journal", bytes.NewReader(body)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandler_Get_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrEntryNotFound, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		svc := &mockService{getFn: func(ctx context.Context, userID, id string) (*Entry, error) {
+			return nil, tt.err
+		}}
+
+		w := httptest.NewRecorder()
+		setupRouter(svc).ServeHTTP(w, httptest.NewRequest("GET", "/journal/entry-1", nil))
+		if w.Code != tt.want {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.want, w.Code)
+		}
+	}
+}
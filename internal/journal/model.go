@@ -0,0 +1,47 @@
+package journal
+
+import "time"
+
+// Entry is a moment worth keeping: a photo, a caption, or both, on the day it
+// happened. Milestone entries mark firsts such as first steps.
+type Entry struct {
+	ID        string    `json:"id"`
+	ChildID   string    `json:"child_id"`
+	MediaID   string    `json:"media_id,omitempty"`
+	Caption   string    `json:"caption"`
+	Date      string    `json:"date"` // YYYY-MM-DD
+	Milestone bool      `json:"milestone"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateEntryRequest struct {
+	ChildID   string `json:"child_id" binding:"required"`
+	MediaID   string `json:"media_id"`
+	Caption   string `json:"caption"`
+	Date      string `json:"date" binding:"required"`
+	Milestone bool   `json:"milestone"`
+}
+
+type UpdateEntryRequest struct {
+	MediaID   string `json:"media_id"`
+	Caption   string `json:"caption"`
+	Date      string `json:"date" binding:"required"`
+	Milestone bool   `json:"milestone"`
+}
+
+// Month groups a child's entries for one calendar month, newest first
+type Month struct {
+	Month   string  `json:"month"` // YYYY-MM
+	Entries []Entry `json:"entries"`
+}
+
+type EntryFilter struct {
+	ChildID       string
+	From          *time.Time
+	To            *time.Time
+	MilestoneOnly bool
+	// Day matches entries on this day of the month; zero matches every day
+	Day int
+}
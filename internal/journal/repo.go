@@ -0,0 +1,187 @@
+package journal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, e *Entry) error
+	GetByID(ctx context.Context, id string) (*Entry, error)
+	List(ctx context.Context, filter *EntryFilter) ([]Entry, error)
+	// Stream yields what List returns, reading rows as they are consumed
+	Stream(ctx context.Context, filter *EntryFilter) iter.Seq2[Entry, error]
+	// CountOnDay counts each child's entries from earlier months that fall on
+	// the same day of the month as date
+	CountOnDay(ctx context.Context, date time.Time) (map[string]int, error)
+	Update(ctx context.Context, e *Entry) error
+	Delete(ctx context.Context, id string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the journal table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "journal_entries")
+}
+
+const entryColumns = `id, child_id, media_id, caption, entry_date, milestone, created_by, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row rowScanner) (*Entry, error) {
+	var e Entry
+	var mediaID, createdBy sql.NullString
+	var date time.Time
+	if err := row.Scan(&e.ID, &e.ChildID, &mediaID, &e.Caption, &date, &e.Milestone, &createdBy, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	e.MediaID = mediaID.String
+	e.CreatedBy = createdBy.String
+	e.Date = date.Format(time.DateOnly)
+	return &e, nil
+}
+
+func (r *repository) Create(ctx context.Context, e *Entry) error {
+	query := `
+		INSERT INTO journal_entries (` + entryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		e.ID, e.ChildID, nullString(e.MediaID), e.Caption, e.Date, e.Milestone,
+		nullString(e.CreatedBy), e.CreatedAt, e.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM journal_entries WHERE id = $1`
+
+	e, err := scanEntry(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return e, err
+}
+
+// filterQuery selects the entries matching filter, newest first
+func filterQuery(filter *EntryFilter) (string, []any) {
+	query := `SELECT ` + entryColumns + ` FROM journal_entries WHERE child_id = $1`
+	args := []any{filter.ChildID}
+	argIndex := 2
+
+	if filter.From != nil {
+		query += fmt.Sprintf(` AND entry_date >= $%d`, argIndex)
+		args = append(args, filter.From.Format(time.DateOnly))
+		argIndex++
+	}
+
+	if filter.To != nil {
+		query += fmt.Sprintf(` AND entry_date <= $%d`, argIndex)
+		args = append(args, filter.To.Format(time.DateOnly))
+		argIndex++
+	}
+
+	if filter.Day > 0 {
+		query += fmt.Sprintf(` AND EXTRACT(DAY FROM entry_date) = $%d`, argIndex)
+		args = append(args, filter.Day)
+	}
+
+	if filter.MilestoneOnly {
+		query += ` AND milestone`
+	}
+
+	return query + ` ORDER BY entry_date DESC, created_at DESC`, args
+}
+
+func (r *repository) List(ctx context.Context, filter *EntryFilter) ([]Entry, error) {
+	query, args := filterQuery(filter)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	entries := []Entry{}
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *repository) Stream(ctx context.Context, filter *EntryFilter) iter.Seq2[Entry, error] {
+	query, args := filterQuery(filter)
+	return db.Stream(ctx, r.db, func(rows *sql.Rows) (Entry, error) {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return Entry{}, err
+		}
+		return *e, nil
+	}, query, args...)
+}
+
+func (r *repository) CountOnDay(ctx context.Context, date time.Time) (map[string]int, error) {
+	query := `
+		SELECT child_id, COUNT(*)
+		FROM journal_entries
+		WHERE EXTRACT(DAY FROM entry_date) = $1 AND entry_date < $2
+		GROUP BY child_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, date.Day(), date.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var childID string
+		var count int
+		if err := rows.Scan(&childID, &count); err != nil {
+			return nil, err
+		}
+		counts[childID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *repository) Update(ctx context.Context, e *Entry) error {
+	query := `
+		UPDATE journal_entries
+		SET media_id = $2, caption = $3, entry_date = $4, milestone = $5, updated_at = $6
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, e.ID, nullString(e.MediaID), e.Caption, e.Date, e.Milestone, e.UpdatedAt)
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM journal_entries WHERE id = $1`, id)
+	return err
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,157 @@
+package journal
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var entryRowColumns = []string{"id", "child_id", "media_id", "caption", "entry_date", "milestone", "created_by", "created_at", "updated_at"}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	e := &Entry{ID: "entry-1", ChildID: "child-1", Caption: "First smile", Date: "2024-03-10",
+		Milestone: true, CreatedBy: "user-1", CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectExec("INSERT INTO journal_entries").
+		WithArgs("entry-1", "child-1", sql.NullString{}, "First smile", "2024-03-10", true,
+			sql.NullString{String: "user-1", Valid: true}, now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), e); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM journal_entries WHERE id").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(entryRowColumns))
+
+	e, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || e != nil {
+		t.Errorf("GetByID() = %v, %v, want nil, nil", e, err)
+	}
+}
+
+func TestRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM journal_entries WHERE child_id = \\$1 AND entry_date >= \\$2 AND milestone ORDER BY entry_date DESC").
+		WithArgs("child-1", "2024-01-01").
+		WillReturnRows(sqlmock.NewRows(entryRowColumns).
+			AddRow("entry-1", "child-1", "media-1", "First steps", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), true, nil, now, now))
+
+	entries, err := repo.List(context.Background(), &EntryFilter{ChildID: "child-1", From: &from, MilestoneOnly: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Date != "2024-03-10" || entries[0].MediaID != "media-1" {
+		t.Errorf("List() = %+v", entries)
+	}
+}
+
+func TestRepository_List_Day(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	to := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT (.+) FROM journal_entries WHERE child_id = \\$1 AND entry_date <= \\$2 AND EXTRACT\\(DAY FROM entry_date\\) = \\$3 ORDER BY").
+		WithArgs("child-1", "2024-03-09", 10).
+		WillReturnRows(sqlmock.NewRows(entryRowColumns))
+
+	if _, err := repo.List(context.Background(), &EntryFilter{ChildID: "child-1", To: &to, Day: 10}); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Stream(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM journal_entries WHERE child_id = \\$1 ORDER BY entry_date DESC").
+		WithArgs("child-1").
+		WillReturnRows(sqlmock.NewRows(entryRowColumns).
+			AddRow("entry-2", "child-1", nil, "Crawling", time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), true, "user-1", now, now).
+			AddRow("entry-1", "child-1", "media-1", "First smile", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), false, nil, now, now))
+
+	var got []string
+	for e, err := range repo.Stream(context.Background(), &EntryFilter{ChildID: "child-1"}) {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		got = append(got, e.ID+" "+e.Date)
+	}
+	if len(got) != 2 || got[0] != "entry-2 2024-04-02" || got[1] != "entry-1 2024-03-10" {
+		t.Errorf("Stream() = %v", got)
+	}
+}
+
+func TestRepository_CountOnDay(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT child_id, COUNT\\(\\*\\)").
+		WithArgs(10, "2024-03-10").
+		WillReturnRows(sqlmock.NewRows([]string{"child_id", "count"}).
+			AddRow("child-1", 2).
+			AddRow("child-2", 1))
+
+	counts, err := repo.CountOnDay(context.Background(), time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("CountOnDay() error = %v", err)
+	}
+	if counts["child-1"] != 2 || counts["child-2"] != 1 {
+		t.Errorf("CountOnDay() = %v", counts)
+	}
+}
+
+func TestRepository_Update(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE journal_entries").
+		WithArgs("entry-1", sql.NullString{String: "media-2", Valid: true}, "Beach day", "2024-06-01", false, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	e := &Entry{ID: "entry-1", MediaID: "media-2", Caption: "Beach day", Date: "2024-06-01", UpdatedAt: now}
+	if err := repo.Update(context.Background(), e); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+}
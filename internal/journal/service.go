@@ -0,0 +1,238 @@
+// Package journal keeps a family's photo journal: milestone photos and
+// everyday moments, each with a caption and the day it happened.
+package journal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/media"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrEntryNotFound = errors.New("journal entry not found")
+	ErrChildNotFound = errors.New("child not found")
+	ErrMediaNotFound = errors.New("media not found")
+	ErrNotMember     = errors.New("user is not a member of this family")
+	ErrEmptyEntry    = errors.New("an entry needs a photo or a caption")
+	ErrInvalidDate   = errors.New("date must be YYYY-MM-DD and not in the future")
+)
+
+type Service interface {
+	Create(ctx context.Context, userID string, req *CreateEntryRequest) (*Entry, error)
+	Get(ctx context.Context, userID, id string) (*Entry, error)
+	Update(ctx context.Context, userID, id string, req *UpdateEntryRequest) (*Entry, error)
+	Delete(ctx context.Context, userID, id string) error
+	// ListByMonth returns the child's entries grouped by calendar month
+	ListByMonth(ctx context.Context, userID string, filter *EntryFilter) ([]Month, error)
+	// Stream yields all of a child's entries, newest first, for exports
+	Stream(ctx context.Context, childID string) iter.Seq2[Entry, error]
+	// OnThisDay returns a child's entries from earlier months on the same day
+	// of the month as date, newest first
+	OnThisDay(ctx context.Context, childID string, date time.Time) ([]Entry, error)
+	// CountOnThisDay counts each child's OnThisDay entries, keyed by child
+	CountOnThisDay(ctx context.Context, date time.Time) (map[string]int, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	mediaService  media.Service
+	visibility    visibility.Service
+}
+
+type Option func(*service)
+
+// WithVisibility applies family visibility settings, so members cannot see or
+// change journal entries hidden from them
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+func NewService(repo Repository, familyService family.Service, mediaService media.Service, opts ...Option) Service {
+	s := &service{
+		repo:          repo,
+		familyService: familyService,
+		mediaService:  mediaService,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether journal entries can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Create(ctx context.Context, userID string, req *CreateEntryRequest) (*Entry, error) {
+	familyID, err := s.authorizeChild(ctx, userID, req.ChildID)
+	if err != nil {
+		return nil, err
+	}
+	date, err := s.validate(ctx, userID, familyID, req.MediaID, req.Caption, req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	e := &Entry{
+		ID:        ids.New(),
+		ChildID:   req.ChildID,
+		MediaID:   req.MediaID,
+		Caption:   req.Caption,
+		Date:      date,
+		Milestone: req.Milestone,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Create(ctx, e); err != nil {
+		return nil, fmt.Errorf("failed to create journal entry: %w", err)
+	}
+	return e, nil
+}
+
+func (s *service) Get(ctx context.Context, userID, id string) (*Entry, error) {
+	e, _, err := s.authorizeEntry(ctx, userID, id)
+	return e, err
+}
+
+func (s *service) Update(ctx context.Context, userID, id string, req *UpdateEntryRequest) (*Entry, error) {
+	e, familyID, err := s.authorizeEntry(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	date, err := s.validate(ctx, userID, familyID, req.MediaID, req.Caption, req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	e.MediaID = req.MediaID
+	e.Caption = req.Caption
+	e.Date = date
+	e.Milestone = req.Milestone
+	e.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, e); err != nil {
+		return nil, fmt.Errorf("failed to update journal entry: %w", err)
+	}
+	return e, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	if _, _, err := s.authorizeEntry(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) ListByMonth(ctx context.Context, userID string, filter *EntryFilter) ([]Month, error) {
+	if _, err := s.authorizeChild(ctx, userID, filter.ChildID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journal entries: %w", err)
+	}
+
+	// Entries come newest first, so each month's run is contiguous
+	months := []Month{}
+	for _, e := range entries {
+		month := e.Date[:len("2006-01")]
+		if len(months) == 0 || months[len(months)-1].Month != month {
+			months = append(months, Month{Month: month})
+		}
+		last := &months[len(months)-1]
+		last.Entries = append(last.Entries, e)
+	}
+	return months, nil
+}
+
+func (s *service) Stream(ctx context.Context, childID string) iter.Seq2[Entry, error] {
+	return s.repo.Stream(ctx, &EntryFilter{ChildID: childID})
+}
+
+func (s *service) OnThisDay(ctx context.Context, childID string, date time.Time) ([]Entry, error) {
+	before := date.AddDate(0, 0, -1)
+	return s.repo.List(ctx, &EntryFilter{ChildID: childID, To: &before, Day: date.Day()})
+}
+
+func (s *service) CountOnThisDay(ctx context.Context, date time.Time) (map[string]int, error) {
+	return s.repo.CountOnDay(ctx, date)
+}
+
+// validate checks an entry's content and returns its normalised date. A photo
+// must have been uploaded to the child's family.
+func (s *service) validate(ctx context.Context, userID, familyID, mediaID, caption, date string) (string, error) {
+	if mediaID == "" && caption == "" {
+		return "", ErrEmptyEntry
+	}
+
+	d, err := time.Parse(time.DateOnly, date)
+	// A day of slack lets members ahead of UTC record today's moments
+	if err != nil || d.After(time.Now().AddDate(0, 0, 1)) {
+		return "", ErrInvalidDate
+	}
+
+	if mediaID != "" {
+		m, err := s.mediaService.Get(ctx, userID, mediaID)
+		if err != nil {
+			if errors.Is(err, media.ErrMediaNotFound) || errors.Is(err, media.ErrNotMember) {
+				return "", ErrMediaNotFound
+			}
+			return "", err
+		}
+		if m.FamilyID != familyID {
+			return "", ErrMediaNotFound
+		}
+	}
+
+	return d.Format(time.DateOnly), nil
+}
+
+func (s *service) authorizeEntry(ctx context.Context, userID, id string) (*Entry, string, error) {
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if e == nil {
+		return nil, "", ErrEntryNotFound
+	}
+	familyID, err := s.authorizeChild(ctx, userID, e.ChildID)
+	if err != nil {
+		return nil, "", err
+	}
+	return e, familyID, nil
+}
+
+// authorizeChild checks the user belongs to the child's family and may see
+// its journal, and returns the family
+func (s *service) authorizeChild(ctx context.Context, userID, childID string) (string, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return "", err
+	}
+	if child == nil {
+		return "", ErrChildNotFound
+	}
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		return "", ErrNotMember
+	}
+	if s.visibility != nil {
+		if err := s.visibility.CheckChild(ctx, userID, childID, visibility.RecordJournal); err != nil {
+			return "", err
+		}
+	}
+	return child.FamilyID, nil
+}
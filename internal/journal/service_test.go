@@ -0,0 +1,249 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/media"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type mockRepository struct {
+	entries map[string]*Entry
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{entries: make(map[string]*Entry)}
+}
+
+func (m *mockRepository) Create(ctx context.Context, e *Entry) error {
+	m.entries[e.ID] = e
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Entry, error) {
+	if e, ok := m.entries[id]; ok {
+		copied := *e
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) List(ctx context.Context, filter *EntryFilter) ([]Entry, error) {
+	var out []Entry
+	for _, e := range m.entries {
+		if e.ChildID == filter.ChildID {
+			out = append(out, *e)
+		}
+	}
+	// Newest first, as the database returns them
+	sort.Slice(out, func(i, j int) bool { return out[i].Date > out[j].Date })
+	return out, nil
+}
+
+func (m *mockRepository) Stream(ctx context.Context, filter *EntryFilter) iter.Seq2[Entry, error] {
+	entries, _ := m.List(ctx, filter)
+	return func(yield func(Entry, error) bool) {
+		for _, e := range entries {
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (m *mockRepository) CountOnDay(ctx context.Context, date time.Time) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (m *mockRepository) Update(ctx context.Context, e *Entry) error {
+	m.entries[e.ID] = e
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	delete(m.entries, id)
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	// members belong to family-1 besides user-1
+	members []string
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" {
+		return &family.Child{ID: "child-1", FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID == "user-1" || slices.Contains(m.members, userID) {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockMediaService struct {
+	media.Service
+}
+
+func (m *mockMediaService) Get(ctx context.Context, userID, id string) (*media.Media, error) {
+	switch id {
+	case "media-1":
+		return &media.Media{ID: "media-1", FamilyID: "family-1"}, nil
+	case "media-other":
+		return &media.Media{ID: "media-other", FamilyID: "family-2"}, nil
+	}
+	return nil, media.ErrMediaNotFound
+}
+
+func newTestService() (Service, *mockRepository) {
+	repo := newMockRepository()
+	return NewService(repo, &mockFamilyService{}, &mockMediaService{}), repo
+}
+
+func TestService_Create(t *testing.T) {
+	svc, repo := newTestService()
+
+	e, err := svc.Create(context.Background(), "user-1", &CreateEntryRequest{
+		ChildID: "child-1", MediaID: "media-1", Caption: "First steps", Date: "2024-03-10", Milestone: true,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if e.ID == "" || e.CreatedBy != "user-1" || !e.Milestone || repo.entries[e.ID] == nil {
+		t.Errorf("Create() = %+v", e)
+	}
+}
+
+func TestService_Create_Errors(t *testing.T) {
+	svc, _ := newTestService()
+	tomorrow := time.Now().AddDate(0, 0, 3).Format(time.DateOnly)
+
+	tests := []struct {
+		name   string
+		userID string
+		req    CreateEntryRequest
+		want   error
+	}{
+		{"empty", "user-1", CreateEntryRequest{ChildID: "child-1", Date: "2024-03-10"}, ErrEmptyEntry},
+		{"bad date", "user-1", CreateEntryRequest{ChildID: "child-1", Caption: "Hi", Date: "10/03/2024"}, ErrInvalidDate},
+		{"future date", "user-1", CreateEntryRequest{ChildID: "child-1", Caption: "Hi", Date: tomorrow}, ErrInvalidDate},
+		{"unknown child", "user-1", CreateEntryRequest{ChildID: "child-9", Caption: "Hi", Date: "2024-03-10"}, ErrChildNotFound},
+		{"not a member", "user-9", CreateEntryRequest{ChildID: "child-1", Caption: "Hi", Date: "2024-03-10"}, ErrNotMember},
+		{"other family's photo", "user-1", CreateEntryRequest{ChildID: "child-1", MediaID: "media-other", Date: "2024-03-10"}, ErrMediaNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Create(context.Background(), tt.userID, &tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("Create() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ListByMonth(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+
+	for _, date := range []string{"2024-02-03", "2024-03-10", "2024-03-01", "2024-02-28"} {
+		if _, err := svc.Create(ctx, "user-1", &CreateEntryRequest{ChildID: "child-1", Caption: date, Date: date}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	months, err := svc.ListByMonth(ctx, "user-1", &EntryFilter{ChildID: "child-1"})
+	if err != nil {
+		t.Fatalf("ListByMonth() error = %v", err)
+	}
+	if len(months) != 2 || months[0].Month != "2024-03" || months[1].Month != "2024-02" {
+		t.Fatalf("ListByMonth() = %+v, want March then February", months)
+	}
+	if len(months[0].Entries) != 2 || months[0].Entries[0].Date != "2024-03-10" {
+		t.Errorf("ListByMonth() March = %+v, want newest first", months[0].Entries)
+	}
+
+	if _, err := svc.ListByMonth(ctx, "user-9", &EntryFilter{ChildID: "child-1"}); !errors.Is(err, ErrNotMember) {
+		t.Errorf("ListByMonth() outsider error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestService_UpdateAndDelete(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	e, err := svc.Create(ctx, "user-1", &CreateEntryRequest{ChildID: "child-1", Caption: "Park", Date: "2024-03-10"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	updated, err := svc.Update(ctx, "user-1", e.ID, &UpdateEntryRequest{MediaID: "media-1", Caption: "Park, with photo", Date: "2024-03-09"})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.MediaID != "media-1" || updated.Date != "2024-03-09" || updated.CreatedBy != "user-1" {
+		t.Errorf("Update() = %+v", updated)
+	}
+
+	if err := svc.Delete(ctx, "user-9", e.ID); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Delete() outsider error = %v, want ErrNotMember", err)
+	}
+	if err := svc.Delete(ctx, "user-1", e.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(repo.entries) != 0 {
+		t.Error("Delete() left the entry")
+	}
+	if _, err := svc.Get(ctx, "user-1", e.ID); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Get() after delete error = %v, want ErrEntryNotFound", err)
+	}
+}
+
+// mockVisibilityService hides the journal from user-2
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) CheckChild(ctx context.Context, userID, childID string, recordType visibility.RecordType) error {
+	if userID == "user-2" {
+		return visibility.ErrHidden
+	}
+	return nil
+}
+
+func TestService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	families := &mockFamilyService{}
+	svc := NewService(repo, families, &mockMediaService{}, WithVisibility(&mockVisibilityService{}))
+
+	e, err := svc.Create(ctx, "user-1", &CreateEntryRequest{ChildID: "child-1", Caption: "Park", Date: "2024-03-10"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// user-2 belongs to the family in this test, so only visibility stops them
+	families.members = []string{"user-2"}
+	if _, err := svc.Get(ctx, "user-2", e.ID); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Get() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.ListByMonth(ctx, "user-2", &EntryFilter{ChildID: "child-1"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("ListByMonth() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Delete(ctx, "user-2", e.ID); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Delete() hidden error = %v, want ErrHidden", err)
+	}
+	if repo.entries[e.ID] == nil {
+		t.Error("Delete() removed a hidden entry")
+	}
+}
@@ -0,0 +1,200 @@
+// Package jsonschema generates JSON schemas from Go types the way
+// encoding/json encodes them, and checks decoded JSON against them. The event
+// catalog and the OpenAPI spec are both built with it.
+package jsonschema
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+// Schema is the subset of JSON Schema needed to describe API payloads
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// Null is the schema of a JSON null
+var Null = &Schema{Type: "null"}
+
+// Generator builds schemas from Go types. Named structs go into the
+// definitions once and are referenced from there, which also covers types
+// that contain themselves.
+type Generator struct {
+	refPrefix string
+	nullable  bool
+	defs      map[string]*Schema
+	enums     map[reflect.Type][]string
+}
+
+type Option func(*Generator)
+
+// WithNullable allows null wherever encoding/json can write one: nil
+// pointers, slices and maps that are not omitted when empty
+func WithNullable() Option {
+	return func(g *Generator) {
+		g.nullable = true
+	}
+}
+
+// NewGenerator returns a generator whose references point at refPrefix
+// followed by the definition's name, e.g. "#/$defs/"
+func NewGenerator(refPrefix string, opts ...Option) *Generator {
+	g := &Generator{refPrefix: refPrefix, defs: map[string]*Schema{}, enums: map[reflect.Type][]string{}}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Enum lists the values a named string type takes
+func Enum[T ~string](g *Generator, values ...T) {
+	list := make([]string, len(values))
+	for i, v := range values {
+		list[i] = string(v)
+	}
+	g.enums[reflect.TypeFor[T]()] = list
+}
+
+// Defs returns the definitions referenced by the schemas generated so far,
+// keyed by package and type name, e.g. "sleep.Sleep"
+func (g *Generator) Defs() map[string]*Schema {
+	return g.defs
+}
+
+// For returns the schema of values of type t
+func (g *Generator) For(t reflect.Type) *Schema {
+	return g.schemaFor(t, true)
+}
+
+func (g *Generator) schemaFor(t reflect.Type, nullable bool) *Schema {
+	s := g.schemaOf(t)
+	if nullable && g.nullable {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			return &Schema{AnyOf: []*Schema{s, Null}}
+		}
+	}
+	return s
+}
+
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if values, ok := g.enums[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+	if t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface &&
+		(t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType)) {
+		// Encodes itself; nothing can be said about its shape from the type
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaOf(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem(), true)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem(), true)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := path.Base(t.PkgPath()) + "." + defName(t.Name())
+		if _, ok := g.defs[name]; !ok {
+			def := &Schema{}
+			g.defs[name] = def
+			*def = *g.object(t)
+		}
+		return &Schema{Ref: g.refPrefix + name}
+	}
+	// Interfaces hold anything
+	return &Schema{}
+}
+
+// defName keeps instantiated generic types usable as definition names,
+// e.g. Changes[github.com/x/family.Child] becomes Changes_family.Child
+func defName(name string) string {
+	base, args, ok := strings.Cut(name, "[")
+	if !ok {
+		return name
+	}
+	args = strings.TrimSuffix(args, "]")
+	parts := strings.Split(args, ",")
+	for i, p := range parts {
+		parts[i] = path.Base(p)
+	}
+	return base + "_" + strings.Join(parts, "_")
+}
+
+func (g *Generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds the struct's encoded fields to s. Fields of embedded structs
+// are promoted, as encoding/json does.
+func (g *Generator) addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(s, embedded)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		// Empty values of omitted fields never reach the output as null
+		omitted := strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero")
+		s.Properties[name] = g.schemaFor(f.Type, !omitted)
+		if !omitted {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
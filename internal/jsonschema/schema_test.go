@@ -0,0 +1,68 @@
+package jsonschema
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type testKind string
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testNode struct {
+	testBase
+	Kind     testKind          `json:"kind"`
+	At       time.Time         `json:"at"`
+	Until    *time.Time        `json:"until,omitempty"`
+	Count    int               `json:"count"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Children []testNode        `json:"children"`
+	Extra    any               `json:"extra,omitempty"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestGenerator_Struct(t *testing.T) {
+	g := NewGenerator("#/$defs/")
+	Enum(g, testKind("a"), testKind("b"))
+
+	ref := g.For(reflect.TypeFor[*testNode]())
+	if ref.Ref != "#/$defs/jsonschema.testNode" {
+		t.Fatalf("schemaFor() = %+v, want a reference", ref)
+	}
+	s := g.Defs()["jsonschema.testNode"]
+
+	want := []string{"id", "kind", "at", "until", "count", "tags", "children", "extra"}
+	for _, name := range want {
+		if s.Properties[name] == nil {
+			t.Errorf("missing property %s", name)
+		}
+	}
+	if len(s.Properties) != len(want) {
+		t.Errorf("properties = %d, want %d", len(s.Properties), len(want))
+	}
+	if !slices.Equal(s.Required, []string{"id", "kind", "at", "count", "children"}) {
+		t.Errorf("required = %v", s.Required)
+	}
+
+	if p := s.Properties["kind"]; p.Type != "string" || !slices.Equal(p.Enum, []string{"a", "b"}) {
+		t.Errorf("kind = %+v, want an enum", p)
+	}
+	if p := s.Properties["until"]; p.Type != "string" || p.Format != "date-time" {
+		t.Errorf("until = %+v, want a date-time", p)
+	}
+	if p := s.Properties["tags"]; p.Type != "object" || p.AdditionalProperties.Type != "string" {
+		t.Errorf("tags = %+v", p)
+	}
+	// Recursive types refer back to their own definition
+	if p := s.Properties["children"]; p.Type != "array" || p.Items.Ref != ref.Ref {
+		t.Errorf("children = %+v", p)
+	}
+	if p := s.Properties["extra"]; p.Type != "" {
+		t.Errorf("extra = %+v, want any value", p)
+	}
+}
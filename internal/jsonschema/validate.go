@@ -0,0 +1,170 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Validate checks a value decoded from JSON with encoding/json against s,
+// resolving references in defs. Fields the schema does not list are
+// reported unless it allows additional properties, so a type that grows a
+// field without its schema being regenerated is caught.
+func Validate(s *Schema, defs map[string]*Schema, value any) error {
+	v := validator{defs: defs}
+	v.check("$", s, value)
+	return errors.Join(v.problems...)
+}
+
+type validator struct {
+	defs     map[string]*Schema
+	problems []error
+}
+
+func (v *validator) fail(at, format string, args ...any) {
+	v.problems = append(v.problems, fmt.Errorf("%s: %s", at, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) check(at string, s *Schema, value any) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		name := s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+		def, ok := v.defs[name]
+		if !ok {
+			v.fail(at, "unknown reference %s", s.Ref)
+			return
+		}
+		s = def
+	}
+	if len(s.AnyOf) > 0 {
+		v.checkAnyOf(at, s.AnyOf, value)
+		return
+	}
+
+	switch s.Type {
+	case "":
+		// Any value
+	case "null":
+		if value != nil {
+			v.fail(at, "got %s, want null", kind(value))
+		}
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.fail(at, "got %s, want object", kind(value))
+			return
+		}
+		v.checkObject(at, s, obj)
+	case "array":
+		list, ok := value.([]any)
+		if !ok {
+			v.fail(at, "got %s, want array", kind(value))
+			return
+		}
+		for i, item := range list {
+			v.check(fmt.Sprintf("%s[%d]", at, i), s.Items, item)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			v.fail(at, "got %s, want string", kind(value))
+			return
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			v.fail(at, "%q is not one of %v", str, s.Enum)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				v.fail(at, "%q is not a date-time", str)
+			}
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			v.fail(at, "got %s, want %s", kind(value), s.Type)
+			return
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			v.fail(at, "%v is not an integer", n)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(at, "got %s, want boolean", kind(value))
+		}
+	default:
+		v.fail(at, "unknown schema type %q", s.Type)
+	}
+}
+
+func (v *validator) checkObject(at string, s *Schema, obj map[string]any) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			v.fail(at, "missing required field %q", name)
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := at + "." + name
+		if prop, ok := s.Properties[name]; ok {
+			v.check(field, prop, obj[name])
+		} else if s.AdditionalProperties != nil {
+			v.check(field, s.AdditionalProperties, obj[name])
+		} else {
+			v.fail(field, "undocumented field")
+		}
+	}
+}
+
+// checkAnyOf accepts the value if one of the schemas does. Otherwise it
+// reports the problems of the closest match, which reads better than every
+// branch's. A null branch is only the closest match for null.
+func (v *validator) checkAnyOf(at string, schemas []*Schema, value any) {
+	var closest []error
+	for _, s := range schemas {
+		branch := validator{defs: v.defs}
+		branch.check(at, s, value)
+		if len(branch.problems) == 0 {
+			return
+		}
+		if s.Type == "null" && value != nil {
+			continue
+		}
+		if closest == nil || len(branch.problems) < len(closest) {
+			closest = branch.problems
+		}
+	}
+	if closest == nil {
+		v.fail(at, "got %s, want null", kind(value))
+		return
+	}
+	v.problems = append(v.problems, closest...)
+}
+
+func kind(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
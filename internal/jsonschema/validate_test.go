@@ -0,0 +1,94 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	Name  string     `json:"name"`
+	Count int        `json:"count"`
+	Note  *string    `json:"note"`
+	Tags  []string   `json:"tags,omitempty"`
+	Seen  *time.Time `json:"seen,omitempty"`
+}
+
+func TestGenerator_Nullable(t *testing.T) {
+	g := NewGenerator("#/components/schemas/", WithNullable())
+	list := g.For(reflect.TypeFor[[]testItem]())
+	if len(list.AnyOf) != 2 || list.AnyOf[1] != Null {
+		t.Fatalf("For([]testItem) = %+v, want array or null", list)
+	}
+
+	s := g.Defs()["jsonschema.testItem"]
+	if note := s.Properties["note"]; len(note.AnyOf) != 2 {
+		t.Errorf("note = %+v, want string or null", note)
+	}
+	// Omitted when empty, so never null
+	if tags := s.Properties["tags"]; tags.Type != "array" {
+		t.Errorf("tags = %+v, want a plain array", tags)
+	}
+}
+
+func TestDefName(t *testing.T) {
+	if got := defName("Changes[github.com/ninenine/babytrack/internal/family.Child]"); got != "Changes_family.Child" {
+		t.Errorf("defName() = %s", got)
+	}
+	if got := defName("Child"); got != "Child" {
+		t.Errorf("defName() = %s", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	g := NewGenerator("#/components/schemas/", WithNullable())
+	s := g.For(reflect.TypeFor[[]testItem]())
+
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"valid", `[{"name":"a","count":1,"note":null,"seen":"2024-01-02T03:04:05Z"}]`, nil},
+		{"null list", `null`, nil},
+		{"undocumented field", `[{"name":"a","count":1,"note":"x","colour":"red"}]`, []string{"$[0].colour: undocumented field"}},
+		{"missing field", `[{"name":"a","note":null}]`, []string{`$[0]: missing required field "count"`}},
+		{"wrong type", `[{"name":"a","count":1.5,"note":null,"tags":[1]}]`, []string{
+			"$[0].count: 1.5 is not an integer",
+			"$[0].tags[0]: got number, want string",
+		}},
+		{"bad date-time", `[{"name":"a","count":1,"note":null,"seen":"yesterday"}]`, []string{`$[0].seen: "yesterday" is not a date-time`}},
+		{"not an array", `{"name":"a"}`, []string{"$: got object, want array"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.body), &value); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			if err := Validate(s, g.Defs(), value); err != nil {
+				got = strings.Split(err.Error(), "\n")
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_Enum(t *testing.T) {
+	g := NewGenerator("#/$defs/")
+	Enum(g, testKind("a"), testKind("b"))
+	s := g.For(reflect.TypeFor[testKind]())
+
+	if err := Validate(s, nil, "a"); err != nil {
+		t.Errorf("Validate(a) = %v", err)
+	}
+	if err := Validate(s, nil, "c"); err == nil {
+		t.Error("Validate(c) should fail")
+	}
+}
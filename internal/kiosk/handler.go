@@ -0,0 +1,117 @@
+package kiosk
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const displayContext = "display"
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterFamilyRoutes registers display pairing and revocation on the
+// families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/displays", h.list)
+	rg.POST("/:familyId/displays", h.pair)
+	rg.DELETE("/:familyId/displays/:displayId", h.revoke)
+}
+
+// RegisterRoutes registers the live view, which authenticates with a display
+// token instead of a session
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.authenticate, h.view)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	displays, err := h.service.List(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, displays)
+}
+
+func (h *Handler) pair(c *gin.Context) {
+	var req PairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	d, err := h.service.Pair(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, d)
+}
+
+func (h *Handler) revoke(c *gin.Context) {
+	err := h.service.Revoke(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), c.Param("displayId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) authenticate(c *gin.Context) {
+	d, err := h.service.Authenticate(c.Request.Context(), tokenFromRequest(c))
+	if err != nil {
+		respondError(c, err)
+		c.Abort()
+		return
+	}
+
+	c.Set(displayContext, d)
+	c.Next()
+}
+
+func (h *Handler) view(c *gin.Context) {
+	d, ok := c.MustGet(displayContext).(*Display)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "missing display"})
+		return
+	}
+
+	view, err := h.service.View(c.Request.Context(), d)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// The body may carry a freshly rotated token
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, view)
+}
+
+// tokenFromRequest reads the token from X-Display-Token, falling back to a
+// bearer token
+func tokenFromRequest(c *gin.Context) string {
+	if token := c.GetHeader(HeaderDisplayToken); token != "" {
+		return token
+	}
+	return strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrTokenExpired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrDisplayNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotAdmin), errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
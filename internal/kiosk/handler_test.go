@@ -0,0 +1,184 @@
+package kiosk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	pairFn         func(ctx context.Context, userID, familyID string, req *PairRequest) (*Display, error)
+	listFn         func(ctx context.Context, userID, familyID string) ([]Display, error)
+	revokeFn       func(ctx context.Context, userID, familyID, displayID string) error
+	authenticateFn func(ctx context.Context, token string) (*Display, error)
+	viewFn         func(ctx context.Context, d *Display) (*View, error)
+}
+
+func (m *mockService) Pair(ctx context.Context, userID, familyID string, req *PairRequest) (*Display, error) {
+	if m.pairFn != nil {
+		return m.pairFn(ctx, userID, familyID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) List(ctx context.Context, userID, familyID string) ([]Display, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, familyID)
+	}
+	return []Display{}, nil
+}
+
+func (m *mockService) Revoke(ctx context.Context, userID, familyID, displayID string) error {
+	if m.revokeFn != nil {
+		return m.revokeFn(ctx, userID, familyID, displayID)
+	}
+	return nil
+}
+
+func (m *mockService) Authenticate(ctx context.Context, token string) (*Display, error) {
+	if m.authenticateFn != nil {
+		return m.authenticateFn(ctx, token)
+	}
+	return nil, ErrInvalidToken
+}
+
+func (m *mockService) View(ctx context.Context, d *Display) (*View, error) {
+	if m.viewFn != nil {
+		return m.viewFn(ctx, d)
+	}
+	return &View{}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	handler.RegisterRoutes(router.Group("/display"))
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterFamilyRoutes(protected.Group("/families"))
+	return router
+}
+
+func TestPair_Success(t *testing.T) {
+	svc := &mockService{
+		pairFn: func(ctx context.Context, userID, familyID string, req *PairRequest) (*Display, error) {
+			if userID != "test-user-123" || familyID != "family-1" {
+				t.Errorf("Unexpected userID %q familyID %q", userID, familyID)
+			}
+			return &Display{ID: "display-1", Name: req.Name, Token: "btd_abc"}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/families/family-1/displays", bytes.NewBufferString(`{"name":"Kitchen"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+
+	var d Display
+	json.Unmarshal(w.Body.Bytes(), &d)
+	if d.Token != "btd_abc" {
+		t.Error("Expected token to be returned on pairing")
+	}
+}
+
+func TestRevoke_NotAdmin(t *testing.T) {
+	svc := &mockService{
+		revokeFn: func(ctx context.Context, userID, familyID, displayID string) error {
+			return ErrNotAdmin
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("DELETE", "/families/family-1/displays/display-1", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestView_Success(t *testing.T) {
+	var gotToken string
+	svc := &mockService{
+		authenticateFn: func(ctx context.Context, token string) (*Display, error) {
+			gotToken = token
+			return &Display{ID: "display-1", FamilyID: "family-1", Token: "btd_next"}, nil
+		},
+		viewFn: func(ctx context.Context, d *Display) (*View, error) {
+			return &View{FamilyID: d.FamilyID, NextToken: d.Token, Children: []ChildView{}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/display", http.NoBody)
+	req.Header.Set(HeaderDisplayToken, "btd_current")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotToken != "btd_current" {
+		t.Errorf("Expected token from header, got %q", gotToken)
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Error("Expected the view not to be cached")
+	}
+
+	var view View
+	json.Unmarshal(w.Body.Bytes(), &view)
+	if view.NextToken != "btd_next" {
+		t.Errorf("Expected rotated token in the view, got %q", view.NextToken)
+	}
+}
+
+func TestView_Unauthorized(t *testing.T) {
+	for _, err := range []error{ErrInvalidToken, ErrTokenExpired} {
+		svc := &mockService{
+			authenticateFn: func(ctx context.Context, token string) (*Display, error) {
+				if token != "btd_old" {
+					t.Errorf("Expected bearer token, got %q", token)
+				}
+				return nil, err
+			},
+			viewFn: func(ctx context.Context, d *Display) (*View, error) {
+				t.Error("View should not run without a valid token")
+				return nil, nil
+			},
+		}
+		router := setupRouter(svc)
+
+		req := httptest.NewRequest("GET", "/display", http.NoBody)
+		req.Header.Set("Authorization", "Bearer btd_old")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%v: expected status 401, got %d", err, w.Code)
+		}
+	}
+}
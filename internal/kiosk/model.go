@@ -0,0 +1,70 @@
+package kiosk
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/timers"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// HeaderDisplayToken carries a display token on kiosk requests
+const HeaderDisplayToken = "X-Display-Token"
+
+const (
+	// RotateAfter is how long a display token is used before the next
+	// request swaps it for a new one
+	RotateAfter = 24 * time.Hour
+	// MaxAge is how long a token lasts without being rotated. A display
+	// switched off for longer has to be paired again.
+	MaxAge = 7 * 24 * time.Hour
+	// RotationGrace is how long the token a rotation replaced still works,
+	// for requests already in flight and displays that missed the response
+	RotationGrace = 10 * time.Minute
+)
+
+// Display is a wall display, such as a kitchen tablet, paired to a family
+// with a read-only token. The token is only returned when the display is
+// paired and, on the live view, each time it rotates.
+type Display struct {
+	ID         string     `json:"id"`
+	FamilyID   string     `json:"family_id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RotatedAt  time.Time  `json:"rotated_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	tokenHash string
+}
+
+type PairRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// View is the live dashboard a display shows: every child's running timers
+// and their latest feed and sleep
+type View struct {
+	FamilyID   string      `json:"family_id"`
+	FamilyName string      `json:"family_name"`
+	Children   []ChildView `json:"children"`
+	AsOf       time.Time   `json:"as_of"`
+	// NextToken is set when the display's token has just rotated; the
+	// display must use it from now on
+	NextToken      string    `json:"next_token,omitempty"`
+	TokenExpiresAt time.Time `json:"token_expires_at"`
+}
+
+// ChildView is one child's tile. Record types hidden from whoever paired the
+// display are listed in Hidden and left empty.
+type ChildView struct {
+	ChildID     string                  `json:"child_id"`
+	ChildName   string                  `json:"child_name"`
+	Timers      []timers.Activity       `json:"timers"`
+	LastFeeding *feeding.Feeding        `json:"last_feeding,omitempty"`
+	LastSleep   *sleep.Sleep            `json:"last_sleep,omitempty"`
+	Hidden      []visibility.RecordType `json:"hidden,omitempty"`
+}
@@ -0,0 +1,150 @@
+package kiosk
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, d *Display, tokenHash string) error
+	// GetByHash finds the live display whose current token, or whose
+	// previous token still inside its grace period, has the given hash
+	GetByHash(ctx context.Context, tokenHash string, now time.Time) (*Display, error)
+	ListByFamily(ctx context.Context, familyID string) ([]Display, error)
+	// Rotate swaps the token for a new one, keeping the old one valid until
+	// graceUntil. It reports false if the token had already changed.
+	Rotate(ctx context.Context, id, fromHash, toHash string, at, expiresAt, graceUntil time.Time) (bool, error)
+	Revoke(ctx context.Context, familyID, id string, revokedAt time.Time) (bool, error)
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the display token table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "display_tokens")
+}
+
+func (r *repository) Create(ctx context.Context, d *Display, tokenHash string) error {
+	query := `
+		INSERT INTO display_tokens (id, family_id, name, token_hash, created_by, created_at, rotated_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		d.ID, d.FamilyID, d.Name, tokenHash, d.CreatedBy, d.CreatedAt, d.RotatedAt, d.ExpiresAt,
+	)
+	return err
+}
+
+func (r *repository) GetByHash(ctx context.Context, tokenHash string, now time.Time) (*Display, error) {
+	query := `
+		SELECT id, family_id, name, token_hash, created_by, created_at, rotated_at, expires_at, last_used_at
+		FROM display_tokens
+		WHERE revoked_at IS NULL
+			AND (token_hash = $1 OR (previous_hash = $1 AND previous_valid_until > $2))
+	`
+
+	var d Display
+	var lastUsedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, tokenHash, now).Scan(
+		&d.ID, &d.FamilyID, &d.Name, &d.tokenHash, &d.CreatedBy, &d.CreatedAt, &d.RotatedAt, &d.ExpiresAt, &lastUsedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lastUsedAt.Valid {
+		d.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &d, nil
+}
+
+func (r *repository) ListByFamily(ctx context.Context, familyID string) ([]Display, error) {
+	query := `
+		SELECT id, family_id, name, created_by, created_at, rotated_at, expires_at, last_used_at
+		FROM display_tokens
+		WHERE family_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	displays := []Display{}
+	for rows.Next() {
+		var d Display
+		var lastUsedAt sql.NullTime
+		if err := rows.Scan(
+			&d.ID, &d.FamilyID, &d.Name, &d.CreatedBy, &d.CreatedAt, &d.RotatedAt, &d.ExpiresAt, &lastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		if lastUsedAt.Valid {
+			d.LastUsedAt = &lastUsedAt.Time
+		}
+		displays = append(displays, d)
+	}
+
+	return displays, rows.Err()
+}
+
+func (r *repository) Rotate(ctx context.Context, id, fromHash, toHash string, at, expiresAt, graceUntil time.Time) (bool, error) {
+	query := `
+		UPDATE display_tokens
+		SET token_hash = $3, previous_hash = token_hash, previous_valid_until = $6,
+			rotated_at = $4, expires_at = $5
+		WHERE id = $1 AND token_hash = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, fromHash, toHash, at, expiresAt, graceUntil)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Revoke reports whether a live display in the family was revoked
+func (r *repository) Revoke(ctx context.Context, familyID, id string, revokedAt time.Time) (bool, error) {
+	query := `
+		UPDATE display_tokens SET revoked_at = $3
+		WHERE id = $1 AND family_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, familyID, revokedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *repository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE display_tokens SET last_used_at = $2 WHERE id = $1`, id, usedAt)
+	return err
+}
@@ -0,0 +1,98 @@
+package kiosk
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	d := &Display{ID: "display-1", FamilyID: "family-1", Name: "Kitchen", CreatedBy: "user-1",
+		CreatedAt: now, RotatedAt: now, ExpiresAt: now.Add(MaxAge)}
+
+	mock.ExpectExec("INSERT INTO display_tokens").
+		WithArgs("display-1", "family-1", "Kitchen", "hash-1", "user-1", now, now, now.Add(MaxAge)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), d, "hash-1"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetByHash(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "family_id", "name", "token_hash", "created_by", "created_at", "rotated_at", "expires_at", "last_used_at"}).
+		AddRow("display-1", "family-1", "Kitchen", "hash-2", "user-1", now, now, now.Add(MaxAge), now)
+
+	mock.ExpectQuery("SELECT id, family_id, name, token_hash").
+		WithArgs("hash-1", now).
+		WillReturnRows(rows)
+
+	d, err := repo.GetByHash(context.Background(), "hash-1", now)
+	if err != nil {
+		t.Fatalf("GetByHash() error = %v", err)
+	}
+	if d.tokenHash != "hash-2" || d.LastUsedAt == nil {
+		t.Errorf("GetByHash() = %+v, want the current hash and last use", d)
+	}
+}
+
+func TestRepository_GetByHash_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, family_id, name, token_hash").
+		WithArgs("missing", now).
+		WillReturnError(sql.ErrNoRows)
+
+	d, err := repo.GetByHash(context.Background(), "missing", now)
+	if err != nil || d != nil {
+		t.Errorf("GetByHash() = %v, %v; want nil, nil", d, err)
+	}
+}
+
+func TestRepository_Rotate(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE display_tokens").
+		WithArgs("display-1", "hash-1", "hash-2", now, now.Add(MaxAge), now.Add(RotationGrace)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rotated, err := repo.Rotate(context.Background(), "display-1", "hash-1", "hash-2", now, now.Add(MaxAge), now.Add(RotationGrace))
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if rotated {
+		t.Error("Rotate() should report false when the token had already changed")
+	}
+}
@@ -0,0 +1,331 @@
+// Package kiosk serves a family's live dashboard to wall displays, such as a
+// kitchen tablet, which read it with a revocable token instead of a session.
+package kiosk
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/timers"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrInvalidToken    = errors.New("invalid display token")
+	ErrTokenExpired    = errors.New("display token expired; pair the display again")
+	ErrDisplayNotFound = errors.New("display not found")
+	ErrNotAdmin        = errors.New("only admins can manage displays")
+	ErrNotMember       = errors.New("user is not a member of this family")
+)
+
+// tokenPrefix tells display tokens apart from quick-log keys
+const tokenPrefix = "btd_"
+
+type Service interface {
+	// Pair creates a display for the family; the token is only returned here
+	// and when it rotates
+	Pair(ctx context.Context, userID, familyID string, req *PairRequest) (*Display, error)
+	List(ctx context.Context, userID, familyID string) ([]Display, error)
+	Revoke(ctx context.Context, userID, familyID, displayID string) error
+	// Authenticate checks a display token and rotates it once it is due. A
+	// rotated display carries its new token in Token.
+	Authenticate(ctx context.Context, token string) (*Display, error)
+	View(ctx context.Context, d *Display) (*View, error)
+}
+
+type service struct {
+	repo           Repository
+	familyService  family.Service
+	timersService  timers.Service
+	feedingService feeding.Service
+	sleepService   sleep.Service
+	visibility     visibility.Service
+	now            func() time.Time
+}
+
+type Option func(*service)
+
+// WithVisibility leaves out of the view the record types hidden from the
+// member who paired the display
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+func NewService(
+	repo Repository,
+	familyService family.Service,
+	timersService timers.Service,
+	feedingService feeding.Service,
+	sleepService sleep.Service,
+	opts ...Option,
+) Service {
+	s := &service{
+		repo:           repo,
+		familyService:  familyService,
+		timersService:  timersService,
+		feedingService: feedingService,
+		sleepService:   sleepService,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether display tokens can be checked, which every
+// wall display refresh needs
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Pair(ctx context.Context, userID, familyID string, req *PairRequest) (*Display, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate display token: %w", err)
+	}
+
+	now := s.now()
+	d := &Display{
+		ID:        ids.New(),
+		FamilyID:  familyID,
+		Name:      strings.TrimSpace(req.Name),
+		CreatedBy: userID,
+		CreatedAt: now,
+		RotatedAt: now,
+		ExpiresAt: now.Add(MaxAge),
+	}
+
+	if err := s.repo.Create(ctx, d, hashToken(token)); err != nil {
+		return nil, fmt.Errorf("failed to create display: %w", err)
+	}
+
+	d.Token = token
+	return d, nil
+}
+
+func (s *service) List(ctx context.Context, userID, familyID string) ([]Display, error) {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	displays, err := s.repo.ListByFamily(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list displays: %w", err)
+	}
+	return displays, nil
+}
+
+func (s *service) Revoke(ctx context.Context, userID, familyID, displayID string) error {
+	if err := s.requireAdmin(ctx, familyID, userID); err != nil {
+		return err
+	}
+
+	revoked, err := s.repo.Revoke(ctx, familyID, displayID, s.now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke display: %w", err)
+	}
+	if !revoked {
+		return ErrDisplayNotFound
+	}
+	return nil
+}
+
+// Authenticate accepts the display's current token, or the one it replaced
+// for RotationGrace after a rotation. A display that answers with the old
+// token missed the new one, so it is rotated again rather than left to
+// expire. Displays stop working when whoever paired them leaves the family.
+func (s *service) Authenticate(ctx context.Context, token string) (*Display, error) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	now := s.now()
+	hash := hashToken(token)
+	d, err := s.repo.GetByHash(ctx, hash, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get display: %w", err)
+	}
+	if d == nil {
+		return nil, ErrInvalidToken
+	}
+	if !now.Before(d.ExpiresAt) {
+		return nil, ErrTokenExpired
+	}
+
+	if _, err := s.familyService.GetMemberRole(ctx, d.FamilyID, d.CreatedBy); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, d.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to update display: %w", err)
+	}
+	d.LastUsedAt = &now
+
+	if hash != d.tokenHash || now.Sub(d.RotatedAt) >= RotateAfter {
+		if err := s.rotate(ctx, d, now); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// rotate gives the display a new token. When another request rotated it
+// first, the display keeps using the token it has, which is still valid.
+func (s *service) rotate(ctx context.Context, d *Display, now time.Time) error {
+	token, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate display token: %w", err)
+	}
+
+	hash := hashToken(token)
+	expiresAt := now.Add(MaxAge)
+	rotated, err := s.repo.Rotate(ctx, d.ID, d.tokenHash, hash, now, expiresAt, now.Add(RotationGrace))
+	if err != nil {
+		return fmt.Errorf("failed to rotate display token: %w", err)
+	}
+	if rotated {
+		d.Token, d.tokenHash = token, hash
+		d.RotatedAt, d.ExpiresAt = now, expiresAt
+	}
+	return nil
+}
+
+// View gathers the display's family as the member who paired it sees it
+func (s *service) View(ctx context.Context, d *Display) (*View, error) {
+	f, err := s.familyService.GetFamily(ctx, d.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family: %w", err)
+	}
+	if f == nil {
+		return nil, ErrDisplayNotFound
+	}
+
+	children, err := s.familyService.GetChildren(ctx, d.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get children: %w", err)
+	}
+
+	view := &View{
+		FamilyID:       f.ID,
+		FamilyName:     f.Name,
+		Children:       []ChildView{},
+		AsOf:           s.now(),
+		NextToken:      d.Token,
+		TokenExpiresAt: d.ExpiresAt,
+	}
+	for i := range children {
+		child, err := s.childView(ctx, d.CreatedBy, &children[i])
+		if err != nil {
+			return nil, err
+		}
+		view.Children = append(view.Children, *child)
+	}
+	return view, nil
+}
+
+func (s *service) childView(ctx context.Context, viewerID string, child *family.Child) (*ChildView, error) {
+	view := &ChildView{ChildID: child.ID, ChildName: child.Name, Timers: []timers.Activity{}}
+
+	hidden, err := s.hiddenTypes(ctx, viewerID, child.ID)
+	if err != nil {
+		return nil, err
+	}
+	view.Hidden = hidden
+
+	// The timers service leaves out hidden activities itself
+	active, err := s.timersService.Active(ctx, viewerID, child.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timers for child %s: %w", child.ID, err)
+	}
+	view.Timers = append(view.Timers, active.Activities...)
+
+	if !slices.Contains(hidden, visibility.RecordFeeding) {
+		last, err := s.feedingService.GetLastFeeding(ctx, child.ID, viewerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last feeding for child %s: %w", child.ID, err)
+		}
+		view.LastFeeding = last
+	}
+
+	if !slices.Contains(hidden, visibility.RecordSleep) {
+		// Newest first; a running sleep is already on the timers
+		sleeps, err := s.sleepService.List(ctx, &sleep.SleepFilter{ChildID: child.ID, ViewerID: viewerID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sleeps for child %s: %w", child.ID, err)
+		}
+		for i := range sleeps {
+			if sleeps[i].EndTime != nil {
+				view.LastSleep = &sleeps[i]
+				break
+			}
+		}
+	}
+
+	return view, nil
+}
+
+func (s *service) hiddenTypes(ctx context.Context, userID, childID string) ([]visibility.RecordType, error) {
+	if s.visibility == nil {
+		return nil, nil
+	}
+	var hidden []visibility.RecordType
+	for _, rt := range []visibility.RecordType{visibility.RecordFeeding, visibility.RecordSleep} {
+		isHidden, err := s.visibility.IsHidden(ctx, userID, childID, rt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check visibility: %w", err)
+		}
+		if isHidden {
+			hidden = append(hidden, rt)
+		}
+	}
+	return hidden, nil
+}
+
+func (s *service) requireAdmin(ctx context.Context, familyID, userID string) error {
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	if role != "admin" {
+		return ErrNotAdmin
+	}
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return tokenPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,318 @@
+package kiosk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/timers"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type storedDisplay struct {
+	Display
+	previousHash       string
+	previousValidUntil time.Time
+	revoked            bool
+}
+
+type mockRepository struct {
+	displays map[string]*storedDisplay
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{displays: make(map[string]*storedDisplay)}
+}
+
+func (m *mockRepository) Create(ctx context.Context, d *Display, tokenHash string) error {
+	stored := &storedDisplay{Display: *d}
+	stored.tokenHash = tokenHash
+	m.displays[d.ID] = stored
+	return nil
+}
+
+func (m *mockRepository) GetByHash(ctx context.Context, tokenHash string, now time.Time) (*Display, error) {
+	for _, d := range m.displays {
+		if d.revoked {
+			continue
+		}
+		if d.tokenHash == tokenHash || (d.previousHash == tokenHash && d.previousValidUntil.After(now)) {
+			copied := d.Display
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListByFamily(ctx context.Context, familyID string) ([]Display, error) {
+	displays := []Display{}
+	for _, d := range m.displays {
+		if d.FamilyID == familyID && !d.revoked {
+			displays = append(displays, d.Display)
+		}
+	}
+	return displays, nil
+}
+
+func (m *mockRepository) Rotate(ctx context.Context, id, fromHash, toHash string, at, expiresAt, graceUntil time.Time) (bool, error) {
+	d := m.displays[id]
+	if d == nil || d.revoked || d.tokenHash != fromHash {
+		return false, nil
+	}
+	d.previousHash, d.previousValidUntil = d.tokenHash, graceUntil
+	d.tokenHash, d.RotatedAt, d.ExpiresAt = toHash, at, expiresAt
+	return true, nil
+}
+
+func (m *mockRepository) Revoke(ctx context.Context, familyID, id string, revokedAt time.Time) (bool, error) {
+	d := m.displays[id]
+	if d == nil || d.FamilyID != familyID || d.revoked {
+		return false, nil
+	}
+	d.revoked = true
+	return true, nil
+}
+
+func (m *mockRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	m.displays[id].LastUsedAt = &usedAt
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	roles    map[string]string
+	children []family.Child
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if role, ok := m.roles[familyID+"/"+userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) GetFamily(ctx context.Context, familyID string) (*family.Family, error) {
+	return &family.Family{ID: familyID, Name: "The Smiths"}, nil
+}
+
+func (m *mockFamilyService) GetChildren(ctx context.Context, familyID string) ([]family.Child, error) {
+	return m.children, nil
+}
+
+type mockTimersService struct {
+	viewers []string
+}
+
+func (m *mockTimersService) Active(ctx context.Context, userID, childID string) (*timers.Active, error) {
+	m.viewers = append(m.viewers, userID)
+	return &timers.Active{
+		ChildID:    childID,
+		Activities: []timers.Activity{{Kind: timers.KindSleep, ID: "sleep-running", ElapsedSeconds: 600}},
+	}, nil
+}
+
+type mockFeedingService struct {
+	feeding.Service
+	viewerID string
+}
+
+func (m *mockFeedingService) GetLastFeeding(ctx context.Context, childID, viewerID string) (*feeding.Feeding, error) {
+	m.viewerID = viewerID
+	return &feeding.Feeding{ID: "feed-1", ChildID: childID}, nil
+}
+
+type mockSleepService struct {
+	sleep.Service
+	viewerID string
+}
+
+func (m *mockSleepService) List(ctx context.Context, filter *sleep.SleepFilter) ([]sleep.Sleep, error) {
+	m.viewerID = filter.ViewerID
+	ended := testNow.Add(-2 * time.Hour)
+	return []sleep.Sleep{
+		{ID: "sleep-running", ChildID: filter.ChildID},
+		{ID: "sleep-1", ChildID: filter.ChildID, EndTime: &ended},
+	}, nil
+}
+
+type mockVisibility struct {
+	visibility.Service
+	hidden map[visibility.RecordType]bool
+}
+
+func (m *mockVisibility) IsHidden(ctx context.Context, userID, childID string, recordType visibility.RecordType) (bool, error) {
+	return m.hidden[recordType], nil
+}
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService() (*service, *mockRepository, *mockFamilyService) {
+	repo := newMockRepository()
+	familySvc := &mockFamilyService{
+		roles: map[string]string{
+			"family-1/admin-1":  "admin",
+			"family-1/member-1": "member",
+		},
+		children: []family.Child{{ID: "child-1", FamilyID: "family-1", Name: "Ada"}},
+	}
+	svc := &service{
+		repo:           repo,
+		familyService:  familySvc,
+		timersService:  &mockTimersService{},
+		feedingService: &mockFeedingService{},
+		sleepService:   &mockSleepService{},
+		now:            func() time.Time { return testNow },
+	}
+	return svc, repo, familySvc
+}
+
+func TestService_Pair(t *testing.T) {
+	svc, repo, _ := newTestService()
+
+	d, err := svc.Pair(context.Background(), "admin-1", "family-1", &PairRequest{Name: " Kitchen "})
+	if err != nil {
+		t.Fatalf("Pair() error = %v", err)
+	}
+	if d.Name != "Kitchen" || d.Token == "" || !d.ExpiresAt.Equal(testNow.Add(MaxAge)) {
+		t.Errorf("Pair() = %+v", d)
+	}
+	if repo.displays[d.ID].tokenHash != hashToken(d.Token) {
+		t.Error("Pair() should store only the token's hash")
+	}
+
+	if _, err := svc.Pair(context.Background(), "member-1", "family-1", &PairRequest{Name: "x"}); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("Pair() by member error = %v, want ErrNotAdmin", err)
+	}
+	if _, err := svc.Pair(context.Background(), "stranger", "family-1", &PairRequest{Name: "x"}); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Pair() by stranger error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestService_Authenticate_Rotation(t *testing.T) {
+	svc, repo, _ := newTestService()
+	paired, _ := svc.Pair(context.Background(), "admin-1", "family-1", &PairRequest{Name: "Kitchen"})
+
+	// Fresh tokens are used as they are
+	d, err := svc.Authenticate(context.Background(), paired.Token)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if d.Token != "" || repo.displays[d.ID].LastUsedAt == nil {
+		t.Errorf("Authenticate() = %+v, want no rotation and last use recorded", d)
+	}
+
+	// A day later the next request rotates it
+	svc.now = func() time.Time { return testNow.Add(RotateAfter) }
+	d, err = svc.Authenticate(context.Background(), paired.Token)
+	if err != nil {
+		t.Fatalf("Authenticate() after a day error = %v", err)
+	}
+	if d.Token == "" || d.Token == paired.Token {
+		t.Fatalf("Authenticate() after a day should rotate, got token %q", d.Token)
+	}
+	if !d.ExpiresAt.Equal(testNow.Add(RotateAfter + MaxAge)) {
+		t.Errorf("Authenticate() expires at %v", d.ExpiresAt)
+	}
+	next := d.Token
+
+	// The old token still works for a moment, and rotates again for a
+	// display that missed the new one
+	svc.now = func() time.Time { return testNow.Add(RotateAfter + time.Minute) }
+	d, err = svc.Authenticate(context.Background(), paired.Token)
+	if err != nil {
+		t.Fatalf("Authenticate() with replaced token in grace error = %v", err)
+	}
+	if d.Token == "" || d.Token == next {
+		t.Errorf("Authenticate() with replaced token should issue a new one, got %q", d.Token)
+	}
+	latest := d.Token
+
+	svc.now = func() time.Time { return testNow.Add(RotateAfter + RotationGrace + 2*time.Minute) }
+	if _, err := svc.Authenticate(context.Background(), paired.Token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authenticate() with token past grace error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := svc.Authenticate(context.Background(), latest); err != nil {
+		t.Errorf("Authenticate() with latest token error = %v", err)
+	}
+}
+
+func TestService_Authenticate_Rejected(t *testing.T) {
+	svc, _, familySvc := newTestService()
+	paired, _ := svc.Pair(context.Background(), "admin-1", "family-1", &PairRequest{Name: "Kitchen"})
+
+	for _, token := range []string{"", "bt_quicklogkey", tokenPrefix + "guess"} {
+		if _, err := svc.Authenticate(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Authenticate(%q) error = %v, want ErrInvalidToken", token, err)
+		}
+	}
+
+	svc.now = func() time.Time { return testNow.Add(MaxAge) }
+	if _, err := svc.Authenticate(context.Background(), paired.Token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Authenticate() after MaxAge error = %v, want ErrTokenExpired", err)
+	}
+
+	svc.now = func() time.Time { return testNow }
+	delete(familySvc.roles, "family-1/admin-1")
+	if _, err := svc.Authenticate(context.Background(), paired.Token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authenticate() after the admin left error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestService_Revoke(t *testing.T) {
+	svc, repo, _ := newTestService()
+	paired, _ := svc.Pair(context.Background(), "admin-1", "family-1", &PairRequest{Name: "Kitchen"})
+	repo.displays["display-x"] = &storedDisplay{Display: Display{ID: "display-x", FamilyID: "family-2"}}
+
+	if err := svc.Revoke(context.Background(), "admin-1", "family-1", "display-x"); !errors.Is(err, ErrDisplayNotFound) {
+		t.Errorf("Revoke() of other family's display error = %v, want ErrDisplayNotFound", err)
+	}
+	if err := svc.Revoke(context.Background(), "admin-1", "family-1", paired.ID); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := svc.Authenticate(context.Background(), paired.Token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authenticate() after revoke error = %v, want ErrInvalidToken", err)
+	}
+	if displays, _ := svc.List(context.Background(), "admin-1", "family-1"); len(displays) != 0 {
+		t.Errorf("List() after revoke = %+v, want none", displays)
+	}
+}
+
+func TestService_View(t *testing.T) {
+	svc, _, _ := newTestService()
+	timersSvc := svc.timersService.(*mockTimersService)
+	d := &Display{ID: "display-1", FamilyID: "family-1", CreatedBy: "admin-1", Token: "btd_next", ExpiresAt: testNow.Add(MaxAge)}
+
+	view, err := svc.View(context.Background(), d)
+	if err != nil {
+		t.Fatalf("View() error = %v", err)
+	}
+	if view.FamilyName != "The Smiths" || view.NextToken != "btd_next" || len(view.Children) != 1 {
+		t.Fatalf("View() = %+v", view)
+	}
+	child := view.Children[0]
+	if len(child.Timers) != 1 || child.LastFeeding == nil || child.LastSleep == nil || child.LastSleep.ID != "sleep-1" {
+		t.Errorf("View() child = %+v, want the timer, last feed and last finished sleep", child)
+	}
+	if timersSvc.viewers[0] != "admin-1" {
+		t.Errorf("View() read timers as %q, want the member who paired the display", timersSvc.viewers[0])
+	}
+	// Other members' private feedings and sleep stay off the display
+	feedingSvc, sleepSvc := svc.feedingService.(*mockFeedingService), svc.sleepService.(*mockSleepService)
+	if feedingSvc.viewerID != "admin-1" || sleepSvc.viewerID != "admin-1" {
+		t.Errorf("View() read the last feeding as %q and sleep as %q, want the member who paired the display", feedingSvc.viewerID, sleepSvc.viewerID)
+	}
+
+	svc.visibility = &mockVisibility{hidden: map[visibility.RecordType]bool{visibility.RecordFeeding: true}}
+	view, err = svc.View(context.Background(), d)
+	if err != nil {
+		t.Fatalf("View() error = %v", err)
+	}
+	child = view.Children[0]
+	if child.LastFeeding != nil || child.LastSleep == nil || len(child.Hidden) != 1 {
+		t.Errorf("View() with feeding hidden = %+v", child)
+	}
+}
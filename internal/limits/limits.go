@@ -0,0 +1,118 @@
+// Package limits caps how much a single request may carry: list fields such
+// as tags and bulk uploads, and the length of strings. The caps are set once
+// at startup from config and read by the services that enforce them.
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// ErrExceeded is matched by every error from Items and Length
+var ErrExceeded = errors.New("limit exceeded")
+
+type Limits struct {
+	TagsPerNote int
+	// BulkItems bounds one bulk request: device pushes, sync pushes, daycare
+	// reports and care plan tasks
+	BulkItems int
+	// ChildrenPerRequest bounds requests that name several children, such as
+	// daycare connections and muted children
+	ChildrenPerRequest int
+	// AttachmentsPerRecord bounds the media or documents linked to one record
+	AttachmentsPerRecord int
+	// NameLength bounds titles, names and tags, in characters
+	NameLength int
+	// TextLength bounds free text such as note content, in characters
+	TextLength int
+}
+
+// Default applies to any limit left at zero. NameLength stays below the
+// 255 character title columns.
+var Default = Limits{
+	TagsPerNote:          20,
+	BulkItems:            500,
+	ChildrenPerRequest:   50,
+	AttachmentsPerRecord: 20,
+	NameLength:           200,
+	TextLength:           20000,
+}
+
+var current atomic.Pointer[Limits]
+
+// Set replaces the limits in force. Zero or negative fields keep their
+// default.
+func Set(l Limits) {
+	fill := func(v *int, def int) {
+		if *v <= 0 {
+			*v = def
+		}
+	}
+	fill(&l.TagsPerNote, Default.TagsPerNote)
+	fill(&l.BulkItems, Default.BulkItems)
+	fill(&l.ChildrenPerRequest, Default.ChildrenPerRequest)
+	fill(&l.AttachmentsPerRecord, Default.AttachmentsPerRecord)
+	fill(&l.NameLength, Default.NameLength)
+	fill(&l.TextLength, Default.TextLength)
+	current.Store(&l)
+}
+
+// Get returns the limits in force, the defaults until Set is called
+func Get() Limits {
+	if l := current.Load(); l != nil {
+		return *l
+	}
+	return Default
+}
+
+// Error names the field that went over and by how much
+type Error struct {
+	Field string
+	Max   int
+	Got   int
+	// Unit is "items" for lists and "characters" for strings
+	Unit string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s may have at most %d %s, got %d", e.Field, e.Max, e.Unit, e.Got)
+}
+
+func (e *Error) Is(target error) bool {
+	return target == ErrExceeded
+}
+
+// Items checks the length of a list or map field
+func Items(field string, n, limit int) error {
+	if n > limit {
+		return &Error{Field: field, Max: limit, Got: n, Unit: "items"}
+	}
+	return nil
+}
+
+// Length checks a string field. It counts characters, not bytes, so the
+// limit reads the same in every script.
+func Length(field, s string, limit int) error {
+	if n := utf8.RuneCountInString(s); n > limit {
+		return &Error{Field: field, Max: limit, Got: n, Unit: "characters"}
+	}
+	return nil
+}
+
+// Truncate shortens s to at most limit characters, for text that arrives
+// from outside a request and is better kept in part than refused
+func Truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	i := 0
+	for n := range s {
+		if i == limit {
+			return s[:n]
+		}
+		i++
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package limits
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSet_KeepsDefaults(t *testing.T) {
+	t.Cleanup(func() { Set(Default) })
+
+	Set(Limits{TagsPerNote: 5, BulkItems: -1})
+	got := Get()
+	if got.TagsPerNote != 5 {
+		t.Errorf("TagsPerNote = %d, want 5", got.TagsPerNote)
+	}
+	if got.BulkItems != Default.BulkItems || got.TextLength != Default.TextLength {
+		t.Errorf("Get() = %+v, want defaults for unset fields", got)
+	}
+}
+
+func TestItems(t *testing.T) {
+	if err := Items("tags", 3, 3); err != nil {
+		t.Errorf("Items() at the limit = %v", err)
+	}
+
+	err := Items("tags", 4, 3)
+	if !errors.Is(err, ErrExceeded) {
+		t.Fatalf("Items() over the limit = %v, want ErrExceeded", err)
+	}
+	if want := "tags may have at most 3 items, got 4"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestLength_CountsCharacters(t *testing.T) {
+	// Five characters, ten bytes
+	if err := Length("title", "ééééé", 5); err != nil {
+		t.Errorf("Length() = %v, want nil", err)
+	}
+	if err := Length("title", "ééééé", 4); !errors.Is(err, ErrExceeded) {
+		t.Errorf("Length() = %v, want ErrExceeded", err)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in    string
+		limit int
+		want  string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "hé"},
+		{strings.Repeat("a", 4), 4, "aaaa"},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.in, tt.limit); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.in, tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package logsheet
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const dateLayout = "2006-01-02"
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterChildRoutes registers the printable sheets on the children group,
+// each behind the middleware in export
+func (h *Handler) RegisterChildRoutes(rg *gin.RouterGroup, export ...gin.HandlerFunc) {
+	sheets := rg.Group("", export...)
+	sheets.GET("/:id/sheets/daily.pdf", h.daily)
+	sheets.GET("/:id/sheets/weekly.pdf", h.weekly)
+}
+
+// daily prints ?date= (default today) in ?tz= (default UTC)
+func (h *Handler) daily(c *gin.Context) {
+	h.sheet(c, "date", 1)
+}
+
+// weekly prints seven days from ?start= (default six days ago, so the
+// sheet ends today) in ?tz= (default UTC)
+func (h *Handler) weekly(c *gin.Context) {
+	h.sheet(c, "start", MaxDays)
+}
+
+func (h *Handler) sheet(c *gin.Context, param string, days int) {
+	userID := c.GetString("user_id")
+
+	loc := time.UTC
+	if tz := c.Query("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tz: " + tz})
+			return
+		}
+	}
+
+	start := time.Now().In(loc).AddDate(0, 0, 1-days)
+	if v := c.Query(param); v != "" {
+		var err error
+		if start, err = time.ParseInLocation(dateLayout, v, loc); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + " format, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	sheet, err := h.service.Build(c.Request.Context(), userID, c.Param("id"), start, days)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("log-sheet-%s.pdf", sheet.Days[0].Date.Format(dateLayout))
+	if days > 1 {
+		filename = fmt.Sprintf("log-sheet-%s-to-%s.pdf",
+			sheet.Days[0].Date.Format(dateLayout), sheet.Days[len(sheet.Days)-1].Date.Format(dateLayout))
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", Render(sheet))
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidDays):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,134 @@
+package logsheet
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	buildFn func(ctx context.Context, userID, childID string, start time.Time, days int) (*Sheet, error)
+}
+
+func (m *mockService) Build(ctx context.Context, userID, childID string, start time.Time, days int) (*Sheet, error) {
+	return m.buildFn(ctx, userID, childID, start, days)
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterChildRoutes(router.Group("/children"))
+	return router
+}
+
+// echoSheet builds an empty sheet for the days asked for
+func echoSheet(got *time.Time, gotDays *int) *mockService {
+	return &mockService{buildFn: func(ctx context.Context, userID, childID string, start time.Time, days int) (*Sheet, error) {
+		*got, *gotDays = start, days
+		sheet := &Sheet{ChildName: "Ada", Location: start.Location(), GeneratedAt: start}
+		for i := range days {
+			sheet.Days = append(sheet.Days, Day{Date: start.AddDate(0, 0, i)})
+		}
+		return sheet, nil
+	}}
+}
+
+func TestHandler_Daily(t *testing.T) {
+	var start time.Time
+	var days int
+	router := setupRouter(echoSheet(&start, &days))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/children/child-1/sheets/daily.pdf?date=2024-03-01&tz=Europe/Paris", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if days != 1 || start.Format(time.RFC3339) != "2024-03-01T00:00:00+01:00" {
+		t.Errorf("Build(start = %v, days = %d)", start, days)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `inline; filename="log-sheet-2024-03-01.pdf"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Errorf("body is not a PDF")
+	}
+}
+
+func TestHandler_Weekly(t *testing.T) {
+	var start time.Time
+	var days int
+	router := setupRouter(echoSheet(&start, &days))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/children/child-1/sheets/weekly.pdf?start=2024-03-01", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if days != MaxDays || start.Location() != time.UTC {
+		t.Errorf("Build(start = %v, days = %d)", start, days)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `inline; filename="log-sheet-2024-03-01-to-2024-03-07.pdf"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+}
+
+func TestHandler_BadRequest(t *testing.T) {
+	var start time.Time
+	var days int
+	router := setupRouter(echoSheet(&start, &days))
+
+	for _, url := range []string{
+		"/children/child-1/sheets/daily.pdf?date=01/03/2024",
+		"/children/child-1/sheets/daily.pdf?tz=Mars/Olympus",
+		"/children/child-1/sheets/weekly.pdf?start=yesterday",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", url, w.Code)
+		}
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrChildNotFound, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+		{context.DeadlineExceeded, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		svc := &mockService{buildFn: func(ctx context.Context, userID, childID string, start time.Time, days int) (*Sheet, error) {
+			return nil, tt.err
+		}}
+
+		w := httptest.NewRecorder()
+		setupRouter(svc).ServeHTTP(w, httptest.NewRequest("GET", "/children/child-1/sheets/daily.pdf", nil))
+		if w.Code != tt.want {
+			t.Errorf("%v: status = %d, want %d", tt.err, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package logsheet
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/measure"
+)
+
+// MaxDays is the longest sheet that can be printed at once, a week
+const MaxDays = 7
+
+// Column is a column of the sheet, after the time column
+type Column string
+
+const (
+	ColumnFeed       Column = "feed"
+	ColumnSleep      Column = "sleep"
+	ColumnDiaper     Column = "diaper" // not tracked; left blank to fill in by hand
+	ColumnMedication Column = "medication"
+)
+
+// Columns is the order the columns are printed in
+var Columns = []Column{ColumnFeed, ColumnSleep, ColumnDiaper, ColumnMedication}
+
+// Entry is one thing that happened, printed in the row of its hour
+type Entry struct {
+	At     time.Time
+	Column Column
+	Text   string
+}
+
+// Totals sum up a day for the foot of its page
+type Totals struct {
+	Feeds  int
+	Intake measure.Quantity // bottle and formula feeds, in ml
+	Sleep  measure.Duration // asleep within the day, less wakings
+	Doses  int
+}
+
+// Day is one page of the sheet
+type Day struct {
+	Date    time.Time // midnight, in the sheet's location
+	Entries []Entry   // in time order
+	Totals  Totals
+}
+
+// Sheet is a child's logs laid out for printing, one day per page
+type Sheet struct {
+	ChildID     string
+	ChildName   string
+	DateOfBirth time.Time
+	Location    *time.Location
+	Days        []Day
+	// Hidden lists the columns whose records are hidden from the member
+	// printing the sheet; they print empty and say so
+	Hidden      []Column
+	GeneratedAt time.Time
+}
@@ -0,0 +1,184 @@
+package logsheet
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/pdf"
+)
+
+// Page layout, in points on portrait A4
+const (
+	margin     = 36.0
+	timeWidth  = 44.0
+	minRow     = 26.0 // leaves room to write in; 24 fit on a page
+	fontSize   = 8.0
+	lineHeight = 10.0
+	cellPad    = 4.0
+)
+
+var columnWidths = map[Column]float64{
+	ColumnFeed:       150,
+	ColumnSleep:      140,
+	ColumnDiaper:     54,
+	ColumnMedication: pdf.A4Width - 2*margin - timeWidth - 150 - 140 - 54,
+}
+
+var columnTitles = map[Column]string{
+	ColumnFeed:       "Feed",
+	ColumnSleep:      "Sleep",
+	ColumnDiaper:     "Diaper",
+	ColumnMedication: "Medication",
+}
+
+// Render lays the sheet out one day to a page, with a row per hour. A day
+// busy enough to run off the bottom carries on over the page.
+func Render(sheet *Sheet) []byte {
+	r := &renderer{doc: pdf.New(pdf.A4Width, pdf.A4Height), sheet: sheet}
+	for i := range sheet.Days {
+		r.day(&sheet.Days[i])
+	}
+	r.footers()
+	return r.doc.Bytes()
+}
+
+type renderer struct {
+	doc   *pdf.Document
+	sheet *Sheet
+	y     float64
+}
+
+func (r *renderer) day(d *Day) {
+	r.page(d, false)
+
+	rows := make([][]Entry, 24)
+	for _, e := range d.Entries {
+		rows[e.At.Hour()] = append(rows[e.At.Hour()], e)
+	}
+	for hour, entries := range rows {
+		r.row(d, hour, entries)
+	}
+	r.totals(d)
+}
+
+// page starts a page for d with the heading and column titles
+func (r *renderer) page(d *Day, continued bool) {
+	r.doc.AddPage()
+	y := margin + 14
+
+	r.doc.Text(margin, y, pdf.Bold, 14, r.sheet.ChildName)
+	title := d.Date.Format("Monday 2 January 2006")
+	if continued {
+		title += " (continued)"
+	}
+	r.doc.Text(pdf.A4Width-margin-pdf.Width(pdf.Bold, 12, title), y, pdf.Bold, 12, title)
+
+	y += 14
+	info := "Times in " + zone(r.sheet.Location, d.Date)
+	if !r.sheet.DateOfBirth.IsZero() {
+		info = fmt.Sprintf("Born %s, %s", r.sheet.DateOfBirth.Format("2 Jan 2006"), info)
+	}
+	r.doc.Text(margin, y, pdf.Regular, 9, info)
+	y += 12
+
+	x := margin
+	r.doc.FillRect(x, y, pdf.A4Width-2*margin, 16, 0.85)
+	r.doc.Text(x+cellPad, y+11, pdf.Bold, 9, "Time")
+	x += timeWidth
+	for _, col := range Columns {
+		title := columnTitles[col]
+		if slices.Contains(r.sheet.Hidden, col) {
+			title += " (hidden)"
+		}
+		r.doc.Text(x+cellPad, y+11, pdf.Bold, 9, title)
+		x += columnWidths[col]
+	}
+	r.doc.Rect(margin, y, pdf.A4Width-2*margin, 16, 0.5)
+	r.y = y + 16
+}
+
+// bottom is as far down the page rows may go, above the totals and footer
+const bottom = pdf.A4Height - margin - 36
+
+func (r *renderer) row(d *Day, hour int, entries []Entry) {
+	cells := make(map[Column][]string, len(Columns))
+	lines := 1
+	for _, e := range entries {
+		text := e.At.Format("15:04") + " " + e.Text
+		cells[e.Column] = append(cells[e.Column], pdf.Wrap(pdf.Regular, fontSize, text, columnWidths[e.Column]-2*cellPad)...)
+		lines = max(lines, len(cells[e.Column]))
+	}
+	height := max(minRow, float64(lines)*lineHeight+2*cellPad)
+	if r.y+height > bottom {
+		r.page(d, true)
+	}
+
+	if hour%2 == 1 {
+		r.doc.FillRect(margin, r.y, pdf.A4Width-2*margin, height, 0.96)
+	}
+	r.doc.Text(margin+cellPad, r.y+cellPad+fontSize, pdf.Bold, 9, fmt.Sprintf("%02d:00", hour))
+
+	x := margin + timeWidth
+	for _, col := range Columns {
+		r.doc.Line(x, r.y, x, r.y+height, 0.5)
+		for i, line := range cells[col] {
+			r.doc.Text(x+cellPad, r.y+cellPad+fontSize+float64(i)*lineHeight, pdf.Regular, fontSize, line)
+		}
+		x += columnWidths[col]
+	}
+	r.doc.Rect(margin, r.y, pdf.A4Width-2*margin, height, 0.5)
+	r.y += height
+}
+
+func (r *renderer) totals(d *Day) {
+	var parts []string
+	if !slices.Contains(r.sheet.Hidden, ColumnFeed) {
+		feeds := fmt.Sprintf("%d feeds", d.Totals.Feeds)
+		if d.Totals.Intake.Value > 0 {
+			feeds += ", " + d.Totals.Intake.Round(0).String() + " by bottle"
+		}
+		parts = append(parts, feeds)
+	}
+	if !slices.Contains(r.sheet.Hidden, ColumnSleep) {
+		parts = append(parts, d.Totals.Sleep.String()+" asleep")
+	}
+	if !slices.Contains(r.sheet.Hidden, ColumnMedication) {
+		parts = append(parts, fmt.Sprintf("%d doses", d.Totals.Doses))
+	}
+	if len(parts) == 0 {
+		return
+	}
+	r.doc.Text(margin, r.y+14, pdf.Bold, 9, "Day total: "+strings.Join(parts, " · "))
+}
+
+// footers stamps each page once they are all laid out, so that they can say
+// how many there are
+func (r *renderer) footers() {
+	printed := "Printed " + r.sheet.GeneratedAt.In(r.sheet.Location).Format("2 Jan 2006 15:04")
+	total := r.doc.Pages()
+	for i := range total {
+		r.doc.Select(i)
+		r.doc.Text(margin, pdf.A4Height-margin+10, pdf.Regular, 7, printed)
+		page := fmt.Sprintf("Page %d of %d", i+1, total)
+		r.doc.Text(pdf.A4Width-margin-pdf.Width(pdf.Regular, 7, page), pdf.A4Height-margin+10, pdf.Regular, 7, page)
+	}
+}
+
+// zone names the location as printed, with the offset on the day
+func zone(loc *time.Location, day time.Time) string {
+	abbr, offset := day.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	utc := fmt.Sprintf("UTC%s%02d:%02d", sign, offset/3600, offset%3600/60)
+	if loc.String() == "UTC" {
+		return "UTC"
+	}
+	if abbr != "" && abbr[0] != '+' && abbr[0] != '-' {
+		return fmt.Sprintf("%s (%s, %s)", loc, abbr, utc)
+	}
+	return fmt.Sprintf("%s (%s)", loc, utc)
+}
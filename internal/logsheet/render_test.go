@@ -0,0 +1,66 @@
+package logsheet
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	sheet := &Sheet{
+		ChildName:   "Ada",
+		Location:    london,
+		GeneratedAt: testNow,
+		Hidden:      []Column{ColumnMedication},
+		Days: []Day{
+			{Date: testDay, Entries: []Entry{{at(6, 30), ColumnFeed, "Bottle 120 ml"}}},
+			{Date: testDay.AddDate(0, 0, 1)},
+		},
+	}
+
+	out := Render(sheet)
+	if !bytes.HasPrefix(out, []byte("%PDF-")) {
+		t.Fatalf("Render() is not a PDF")
+	}
+	for _, want := range []string{"(Ada)", "(Friday 1 March 2024)", "(Saturday 2 March 2024)",
+		"(06:30 Bottle 120 ml)", "(Medication \\(hidden\\))", "(Page 2 of 2)", "/Count 2"} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("Render() lacks %s", want)
+		}
+	}
+}
+
+// TestRender_Overflow checks a day too busy for one page carries on over
+// the next, headed as a continuation
+func TestRender_Overflow(t *testing.T) {
+	day := Day{Date: testDay}
+	for hour := range 24 {
+		for i := range 4 {
+			day.Entries = append(day.Entries, Entry{at(hour, i*15), ColumnFeed, fmt.Sprintf("Bottle %d ml", 30+i)})
+		}
+	}
+	sheet := &Sheet{ChildName: "Ada", Location: london, GeneratedAt: testNow, Days: []Day{day}}
+
+	out := Render(sheet)
+	if !bytes.Contains(out, []byte("(Friday 1 March 2024 \\(continued\\))")) || !bytes.Contains(out, []byte("(Page 2 of 2)")) {
+		t.Errorf("Render() did not carry the day over a second page")
+	}
+}
+
+func TestZone(t *testing.T) {
+	tests := []struct {
+		loc  *time.Location
+		day  time.Time
+		want string
+	}{
+		{time.UTC, testDay, "UTC"},
+		{london, time.Date(2024, 7, 1, 0, 0, 0, 0, london), "Europe/London (BST, UTC+01:00)"},
+		{mustLoad("America/St_Johns"), time.Date(2024, 1, 1, 0, 0, 0, 0, mustLoad("America/St_Johns")), "America/St_Johns (NST, UTC-03:30)"},
+	}
+	for _, tt := range tests {
+		if got := zone(tt.loc, tt.day); got != tt.want {
+			t.Errorf("zone(%s) = %q, want %q", tt.loc, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+// Package logsheet lays out a child's feeds, sleep and medication doses as
+// printable day sheets, with the hours of the day down the side, for
+// hospital stays and other places where staff want paper.
+package logsheet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/measure"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrChildNotFound = errors.New("child not found")
+	ErrNotMember     = errors.New("user is not a member of this family")
+	ErrInvalidDays   = fmt.Errorf("a sheet covers 1 to %d days", MaxDays)
+)
+
+type Service interface {
+	// Build gathers the child's logs for days days from start, which is
+	// taken as midnight in its location
+	Build(ctx context.Context, userID, childID string, start time.Time, days int) (*Sheet, error)
+}
+
+type service struct {
+	familyService     family.Service
+	feedingService    feeding.Service
+	sleepService      sleep.Service
+	medicationService medication.Service
+	visibility        visibility.Service
+	now               func() time.Time
+}
+
+type Option func(*service)
+
+// WithVisibility leaves out the records whose type is hidden from the member
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+func NewService(familyService family.Service, feedingService feeding.Service, sleepService sleep.Service,
+	medicationService medication.Service, opts ...Option) Service {
+	s := &service{
+		familyService:     familyService,
+		feedingService:    feedingService,
+		sleepService:      sleepService,
+		medicationService: medicationService,
+		now:               time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// hiddenAs is the record type that hides each column
+var hiddenAs = map[Column]visibility.RecordType{
+	ColumnFeed:       visibility.RecordFeeding,
+	ColumnSleep:      visibility.RecordSleep,
+	ColumnMedication: visibility.RecordMedication,
+}
+
+func (s *service) Build(ctx context.Context, userID, childID string, start time.Time, days int) (*Sheet, error) {
+	if days < 1 || days > MaxDays {
+		return nil, ErrInvalidDays
+	}
+	child, err := s.authorize(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := start.Location()
+	y, m, d := start.Date()
+	from := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, days)
+
+	sheet := &Sheet{
+		ChildID:     childID,
+		ChildName:   child.Name,
+		DateOfBirth: child.DateOfBirth,
+		Location:    loc,
+		Days:        make([]Day, days),
+		GeneratedAt: s.now(),
+	}
+	for i := range sheet.Days {
+		sheet.Days[i] = Day{Date: from.AddDate(0, 0, i), Entries: []Entry{}, Totals: Totals{Intake: measure.Quantity{Unit: measure.Milliliter}}}
+	}
+
+	for _, col := range Columns {
+		as, ok := hiddenAs[col]
+		if !ok {
+			continue
+		}
+		if s.visibility != nil {
+			hidden, err := s.visibility.IsHidden(ctx, userID, childID, as)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check visibility: %w", err)
+			}
+			if hidden {
+				sheet.Hidden = append(sheet.Hidden, col)
+				continue
+			}
+		}
+
+		switch col {
+		case ColumnFeed:
+			err = s.addFeeds(ctx, sheet, from, to)
+		case ColumnSleep:
+			err = s.addSleep(ctx, sheet, from, to)
+		case ColumnMedication:
+			err = s.addDoses(ctx, sheet, from, to)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range sheet.Days {
+		slices.SortStableFunc(sheet.Days[i].Entries, func(a, b Entry) int {
+			return a.At.Compare(b.At)
+		})
+	}
+	return sheet, nil
+}
+
+func (s *service) authorize(ctx context.Context, userID, childID string) (*family.Child, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+	return child, nil
+}
+
+// day returns the page at falls on, or nil outside the sheet
+func (sheet *Sheet) day(at time.Time) *Day {
+	at = at.In(sheet.Location)
+	for i := range sheet.Days {
+		d := &sheet.Days[i]
+		if !at.Before(d.Date) && at.Before(d.Date.AddDate(0, 0, 1)) {
+			return d
+		}
+	}
+	return nil
+}
+
+func (sheet *Sheet) add(at time.Time, col Column, text string) *Day {
+	d := sheet.day(at)
+	if d != nil {
+		d.Entries = append(d.Entries, Entry{At: at.In(sheet.Location), Column: col, Text: text})
+	}
+	return d
+}
+
+func (s *service) addFeeds(ctx context.Context, sheet *Sheet, from, to time.Time) error {
+	end := to.Add(-time.Nanosecond)
+	feeds, err := s.feedingService.List(ctx, &feeding.FeedingFilter{ChildID: sheet.ChildID, StartDate: &from, EndDate: &end})
+	if err != nil {
+		return fmt.Errorf("failed to list feedings: %w", err)
+	}
+
+	for i := range feeds {
+		f := &feeds[i]
+		d := sheet.add(f.StartTime, ColumnFeed, describeFeed(f))
+		if d == nil {
+			continue
+		}
+		d.Totals.Feeds++
+		if q, ok := f.Quantity(); ok && f.Type != feeding.FeedingTypeSolid {
+			if ml, err := q.In(measure.Milliliter); err == nil {
+				d.Totals.Intake.Value += ml.Value
+			}
+		}
+	}
+	return nil
+}
+
+func describeFeed(f *feeding.Feeding) string {
+	var parts []string
+	switch f.Type {
+	case feeding.FeedingTypeBreast:
+		parts = append(parts, "Breast")
+		if f.Side != "" {
+			parts = append(parts, f.Side)
+		}
+	case feeding.FeedingTypeBottle:
+		parts = append(parts, "Bottle")
+	case feeding.FeedingTypeFormula:
+		parts = append(parts, "Formula")
+	case feeding.FeedingTypeSolid:
+		parts = append(parts, "Solids")
+	default:
+		parts = append(parts, string(f.Type))
+	}
+	if q, ok := f.Quantity(); ok {
+		parts = append(parts, q.Round(1).String())
+	}
+	if d, ok := f.Duration(); ok {
+		parts = append(parts, d.String())
+	} else if f.EndTime == nil && f.Type == feeding.FeedingTypeBreast {
+		parts = append(parts, "(ongoing)")
+	}
+	return withNotes(strings.Join(parts, " "), f.Notes)
+}
+
+// addSleep lists sleeps from a day before the sheet, so that a night that
+// started the evening before still shows its waking and counts towards the
+// first day's total
+func (s *service) addSleep(ctx context.Context, sheet *Sheet, from, to time.Time) error {
+	since := from.AddDate(0, 0, -1)
+	end := to.Add(-time.Nanosecond)
+	sleeps, err := s.sleepService.List(ctx, &sleep.SleepFilter{ChildID: sheet.ChildID, StartDate: &since, EndDate: &end})
+	if err != nil {
+		return fmt.Errorf("failed to list sleep: %w", err)
+	}
+
+	for i := range sleeps {
+		sl := &sleeps[i]
+		kind := "nap"
+		if sl.Type == sleep.SleepTypeNight {
+			kind = "night"
+		}
+		sheet.add(sl.StartTime, ColumnSleep, withNotes(fmt.Sprintf("Asleep (%s)", kind), sl.Notes))
+		for _, w := range sl.Wakings {
+			text := "Woke"
+			if w.Reason != "" {
+				text += ", " + string(w.Reason)
+			}
+			if awake, ok := w.Duration(); ok {
+				text += fmt.Sprintf(", back down after %s", awake)
+			}
+			sheet.add(w.StartTime, ColumnSleep, text)
+		}
+		if sl.EndTime != nil {
+			text := "Woke up"
+			if asleep, ok := sl.AsleepDuration(); ok {
+				text += fmt.Sprintf(" (%s %s)", asleep, kind)
+			}
+			sheet.add(*sl.EndTime, ColumnSleep, text)
+		}
+
+		for i := range sheet.Days {
+			day := &sheet.Days[i]
+			day.Totals.Sleep += asleepWithin(sl, day.Date, day.Date.AddDate(0, 0, 1), s.now())
+		}
+	}
+	return nil
+}
+
+// asleepWithin is how much of the sleep fell between from and to, less the
+// time spent awake in wakings. A sleep still going counts up to now.
+func asleepWithin(sl *sleep.Sleep, from, to, now time.Time) measure.Duration {
+	overlap := func(start time.Time, end *time.Time) time.Duration {
+		e := now
+		if end != nil {
+			e = *end
+		}
+		if e.After(to) {
+			e = to
+		}
+		if start.Before(from) {
+			start = from
+		}
+		return max(e.Sub(start), 0)
+	}
+
+	d := overlap(sl.StartTime, sl.EndTime)
+	for _, w := range sl.Wakings {
+		if w.EndTime != nil {
+			d -= overlap(w.StartTime, w.EndTime)
+		}
+	}
+	return measure.Duration(max(d, 0))
+}
+
+// addDoses goes through each of the child's medications' recent doses,
+// which reach back further than a week for all but the most frequent
+func (s *service) addDoses(ctx context.Context, sheet *Sheet, from, to time.Time) error {
+	meds, err := s.medicationService.List(ctx, &medication.MedicationFilter{ChildID: sheet.ChildID})
+	if err != nil {
+		return fmt.Errorf("failed to list medications: %w", err)
+	}
+
+	for i := range meds {
+		med := &meds[i]
+		logs, err := s.medicationService.GetLogs(ctx, med.ID, "")
+		if err != nil {
+			return fmt.Errorf("failed to list doses of %s: %w", med.Name, err)
+		}
+		for _, l := range logs {
+			if l.GivenAt.Before(from) || !l.GivenAt.Before(to) {
+				continue
+			}
+			text := med.Name + " " + l.Dosage
+			if q, err := med.DoseOf(l.Dosage); err == nil {
+				text = med.Name + " " + q.String()
+			}
+			if l.CorrectedAt != nil {
+				text += " (corrected)"
+			}
+			if d := sheet.add(l.GivenAt, ColumnMedication, withNotes(text, l.Notes)); d != nil {
+				d.Totals.Doses++
+			}
+		}
+	}
+	return nil
+}
+
+func withNotes(text, notes string) string {
+	if notes = strings.TrimSpace(notes); notes != "" {
+		return text + " – " + notes
+	}
+	return text
+}
@@ -0,0 +1,232 @@
+package logsheet
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	london  = mustLoad("Europe/London")
+	testDay = time.Date(2024, 3, 1, 0, 0, 0, 0, london)
+	testNow = time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+)
+
+func mustLoad(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+func at(hour, minute int) time.Time {
+	return testDay.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" {
+		return &family.Child{ID: childID, FamilyID: "family-1", Name: "Ada"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID == "user-1" || userID == "sitter" {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockFeedingService struct {
+	feeding.Service
+	feeds  []feeding.Feeding
+	filter *feeding.FeedingFilter
+}
+
+func (m *mockFeedingService) List(ctx context.Context, filter *feeding.FeedingFilter) ([]feeding.Feeding, error) {
+	m.filter = filter
+	return m.feeds, nil
+}
+
+type mockSleepService struct {
+	sleep.Service
+	sleeps []sleep.Sleep
+	filter *sleep.SleepFilter
+}
+
+func (m *mockSleepService) List(ctx context.Context, filter *sleep.SleepFilter) ([]sleep.Sleep, error) {
+	m.filter = filter
+	return m.sleeps, nil
+}
+
+type mockMedicationService struct {
+	medication.Service
+	meds []medication.Medication
+	logs map[string][]medication.MedicationLog
+	err  error
+}
+
+func (m *mockMedicationService) List(ctx context.Context, filter *medication.MedicationFilter) ([]medication.Medication, error) {
+	return m.meds, m.err
+}
+
+func (m *mockMedicationService) GetLogs(ctx context.Context, medicationID, viewerID string) ([]medication.MedicationLog, error) {
+	return m.logs[medicationID], nil
+}
+
+// mockVisibilityService hides medication from the sitter
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) IsHidden(ctx context.Context, userID, childID string, recordType visibility.RecordType) (bool, error) {
+	return userID == "sitter" && recordType == visibility.RecordMedication, nil
+}
+
+func newTestService(feeds *mockFeedingService, sleeps *mockSleepService, meds *mockMedicationService, opts ...Option) *service {
+	svc := NewService(&mockFamilyService{}, feeds, sleeps, meds, opts...).(*service)
+	svc.now = func() time.Time { return testNow }
+	return svc
+}
+
+func TestService_Build(t *testing.T) {
+	feeds := &mockFeedingService{feeds: []feeding.Feeding{
+		{Type: feeding.FeedingTypeBottle, StartTime: at(6, 30), Amount: ptr(120.0), Unit: "ml"},
+		{Type: feeding.FeedingTypeBreast, StartTime: at(2, 10), EndTime: ptr(at(2, 35)), Side: "left"},
+		{Type: feeding.FeedingTypeFormula, StartTime: at(10, 0), Amount: ptr(2.0), Unit: "oz", Notes: "fussy"},
+	}}
+	sleeps := &mockSleepService{sleeps: []sleep.Sleep{{
+		Type:      sleep.SleepTypeNight,
+		StartTime: at(-4, 0), // 20:00 the evening before
+		EndTime:   ptr(at(6, 0)),
+		Wakings: []sleep.Waking{
+			{StartTime: at(2, 0), EndTime: ptr(at(2, 40)), Reason: sleep.WakingHungry},
+		},
+	}}}
+	meds := &mockMedicationService{
+		meds: []medication.Medication{{ID: "med-1", Name: "Paracetamol", Unit: "ml"}},
+		logs: map[string][]medication.MedicationLog{"med-1": {
+			{GivenAt: at(12, 0), Dosage: "2.5", CorrectedAt: ptr(testNow)},
+			{GivenAt: at(-2, 0), Dosage: "2.5"}, // the day before
+		}},
+	}
+
+	sheet, err := newTestService(feeds, sleeps, meds).Build(context.Background(), "user-1", "child-1", at(15, 0), 1)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if sheet.ChildName != "Ada" || len(sheet.Days) != 1 || !sheet.Days[0].Date.Equal(testDay) || !sheet.GeneratedAt.Equal(testNow) {
+		t.Fatalf("Build() = %+v", sheet)
+	}
+	if !feeds.filter.StartDate.Equal(testDay) || !feeds.filter.EndDate.Before(testDay.AddDate(0, 0, 1)) {
+		t.Errorf("feeding filter = %v to %v", feeds.filter.StartDate, feeds.filter.EndDate)
+	}
+	if !sleeps.filter.StartDate.Equal(testDay.AddDate(0, 0, -1)) {
+		t.Errorf("sleep filter starts %v, want the day before", sleeps.filter.StartDate)
+	}
+
+	want := []Entry{
+		{at(2, 0), ColumnSleep, "Woke, hungry, back down after 40m"},
+		{at(2, 10), ColumnFeed, "Breast left 25m"},
+		{at(6, 0), ColumnSleep, "Woke up (9h 20m night)"},
+		{at(6, 30), ColumnFeed, "Bottle 120 ml"},
+		{at(10, 0), ColumnFeed, "Formula 2 oz – fussy"},
+		{at(12, 0), ColumnMedication, "Paracetamol 2.5 ml (corrected)"},
+	}
+	got := sheet.Days[0].Entries
+	if len(got) != len(want) {
+		t.Fatalf("Entries = %+v", got)
+	}
+	for i := range want {
+		if !got[i].At.Equal(want[i].At) || got[i].Column != want[i].Column || got[i].Text != want[i].Text {
+			t.Errorf("Entries[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	totals := sheet.Days[0].Totals
+	if totals.Feeds != 3 || totals.Doses != 1 {
+		t.Errorf("Totals = %+v", totals)
+	}
+	if ml := totals.Intake.Round(0).Value; ml != 179 {
+		t.Errorf("Intake = %v ml, want 179", ml)
+	}
+	if totals.Sleep.String() != "5h 20m" {
+		t.Errorf("Sleep = %s, want 5h 20m", totals.Sleep)
+	}
+}
+
+func TestService_Build_Week(t *testing.T) {
+	feeds := &mockFeedingService{feeds: []feeding.Feeding{
+		{Type: feeding.FeedingTypeBottle, StartTime: at(0, 30).AddDate(0, 0, 3)},
+	}}
+	svc := newTestService(feeds, &mockSleepService{}, &mockMedicationService{})
+
+	sheet, err := svc.Build(context.Background(), "user-1", "child-1", testDay, MaxDays)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(sheet.Days) != MaxDays {
+		t.Fatalf("Days = %d, want %d", len(sheet.Days), MaxDays)
+	}
+	for i, d := range sheet.Days {
+		want := 0
+		if i == 3 {
+			want = 1
+		}
+		if len(d.Entries) != want {
+			t.Errorf("day %d has %d entries, want %d", i, len(d.Entries), want)
+		}
+	}
+}
+
+func TestService_Build_Hidden(t *testing.T) {
+	meds := &mockMedicationService{err: errors.New("should not be listed")}
+	svc := newTestService(&mockFeedingService{}, &mockSleepService{}, meds, WithVisibility(&mockVisibilityService{}))
+
+	sheet, err := svc.Build(context.Background(), "sitter", "child-1", testDay, 1)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(sheet.Hidden) != 1 || sheet.Hidden[0] != ColumnMedication {
+		t.Errorf("Hidden = %v, want [medication]", sheet.Hidden)
+	}
+}
+
+func TestService_Build_Errors(t *testing.T) {
+	svc := newTestService(&mockFeedingService{}, &mockSleepService{}, &mockMedicationService{})
+	tests := []struct {
+		name    string
+		userID  string
+		childID string
+		days    int
+		want    error
+	}{
+		{"unknown child", "user-1", "child-2", 1, ErrChildNotFound},
+		{"not a member", "user-2", "child-1", 1, ErrNotMember},
+		{"no days", "user-1", "child-1", 0, ErrInvalidDays},
+		{"over a week", "user-1", "child-1", MaxDays + 1, ErrInvalidDays},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Build(context.Background(), tt.userID, tt.childID, testDay, tt.days); !errors.Is(err, tt.want) {
+				t.Errorf("Build() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
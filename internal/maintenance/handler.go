@@ -0,0 +1,64 @@
+package maintenance
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	sw         *Switch
+	adminToken string
+}
+
+// NewHandler serves the admin endpoint. An empty adminToken disables it.
+func NewHandler(sw *Switch, adminToken string) *Handler {
+	return &Handler{sw: sw, adminToken: adminToken}
+}
+
+// RegisterAdminRoutes registers the operator endpoints, authenticated with
+// the admin token from config rather than a user session
+func (h *Handler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/maintenance", h.RequireAdmin, h.get)
+	rg.PUT("/maintenance", h.RequireAdmin, h.set)
+}
+
+// RequireAdmin aborts requests without the admin token, so other operator
+// routes can share the check
+func (h *Handler) RequireAdmin(c *gin.Context) {
+	if h.adminToken == "" {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "admin endpoints are disabled"})
+		return
+	}
+	token := c.GetHeader(HeaderAdminToken)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+		return
+	}
+	c.Next()
+}
+
+func (h *Handler) get(c *gin.Context) {
+	c.JSON(http.StatusOK, toResponse(h.sw.Get()))
+}
+
+func (h *Handler) set(c *gin.Context) {
+	var req SetModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.RetryAfter < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "retry_after must not be negative"})
+		return
+	}
+
+	mode := h.sw.Set(*req.Enabled, req.Message, time.Duration(req.RetryAfter)*time.Second)
+	c.JSON(http.StatusOK, toResponse(mode))
+}
+
+func toResponse(m Mode) ModeResponse {
+	return ModeResponse{Mode: m, RetryAfter: m.RetryAfterSeconds()}
+}
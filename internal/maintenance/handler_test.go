@@ -0,0 +1,68 @@
+package maintenance
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupAdminRouter(sw *Switch, token string) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	NewHandler(sw, token).RegisterAdminRoutes(router.Group("/admin"))
+	return router
+}
+
+func TestSetMode_Success(t *testing.T) {
+	sw := NewSwitch(false, "", 0)
+	router := setupAdminRouter(sw, "operator-secret")
+
+	req := httptest.NewRequest("PUT", "/admin/maintenance", bytes.NewBufferString(`{"enabled":true,"retry_after":60}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderAdminToken, "operator-secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if mode := sw.Get(); !mode.Enabled || mode.RetryAfterSeconds() != 60 {
+		t.Errorf("Expected maintenance on with 60s retry, got %+v", mode)
+	}
+}
+
+func TestSetMode_BadToken(t *testing.T) {
+	sw := NewSwitch(false, "", 0)
+	router := setupAdminRouter(sw, "operator-secret")
+
+	req := httptest.NewRequest("PUT", "/admin/maintenance", bytes.NewBufferString(`{"enabled":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderAdminToken, "guess")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+	if sw.Get().Enabled {
+		t.Error("Mode should not change without a valid token")
+	}
+}
+
+func TestGetMode_Disabled(t *testing.T) {
+	router := setupAdminRouter(NewSwitch(false, "", 0), "")
+
+	req := httptest.NewRequest("GET", "/admin/maintenance", http.NoBody)
+	req.Header.Set(HeaderAdminToken, "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 with no admin token configured, got %d", w.Code)
+	}
+}
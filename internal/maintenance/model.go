@@ -0,0 +1,37 @@
+package maintenance
+
+import "time"
+
+// HeaderAdminToken carries the operator token on admin requests
+const HeaderAdminToken = "X-Admin-Token"
+
+// Mode is the current maintenance state. While enabled, write requests are
+// refused with 503 and clients are told to retry after RetryAfter.
+type Mode struct {
+	Enabled    bool          `json:"enabled"`
+	Message    string        `json:"message,omitempty"`
+	RetryAfter time.Duration `json:"-"`
+	Since      *time.Time    `json:"since,omitempty"`
+}
+
+// RetryAfterSeconds is the backoff hint sent to clients, never less than one
+func (m Mode) RetryAfterSeconds() int {
+	secs := int(m.RetryAfter.Seconds())
+	if secs < 1 {
+		return 1
+	}
+	return secs
+}
+
+type SetModeRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Message string `json:"message"`
+	// RetryAfter is in seconds; zero keeps the configured default
+	RetryAfter int `json:"retry_after"`
+}
+
+// ModeResponse is Mode as returned by the admin endpoint
+type ModeResponse struct {
+	Mode
+	RetryAfter int `json:"retry_after"`
+}
@@ -0,0 +1,96 @@
+package maintenance
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultRetryAfter is used when neither the config nor the operator sets one
+const DefaultRetryAfter = 5 * time.Minute
+
+// Switch holds the maintenance mode for this server instance. It starts from
+// config and is flipped by operators through the admin endpoint; the state is
+// not persisted, so a restart returns to the configured mode.
+type Switch struct {
+	mu                sync.RWMutex
+	mode              Mode
+	defaultRetryAfter time.Duration
+}
+
+func NewSwitch(enabled bool, message string, retryAfter time.Duration) *Switch {
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+	s := &Switch{defaultRetryAfter: retryAfter}
+	s.Set(enabled, message, 0)
+	return s
+}
+
+func (s *Switch) Get() Mode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mode
+}
+
+// Set changes the mode. A zero retryAfter uses the configured default.
+func (s *Switch) Set(enabled bool, message string, retryAfter time.Duration) Mode {
+	if retryAfter <= 0 {
+		retryAfter = s.defaultRetryAfter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mode := Mode{Enabled: enabled, Message: message, RetryAfter: retryAfter}
+	if enabled {
+		since := time.Now()
+		if s.mode.Enabled && s.mode.Since != nil {
+			since = *s.mode.Since
+		}
+		mode.Since = &since
+	}
+	s.mode = mode
+	return mode
+}
+
+// Guard refuses requests that can change data while maintenance mode is on.
+// GET, HEAD and OPTIONS always pass, as do paths under the exempt prefixes.
+func (s *Switch) Guard(exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		mode := s.Get()
+		if !mode.Enabled {
+			c.Next()
+			return
+		}
+
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		message := mode.Message
+		if message == "" {
+			message = "down for maintenance"
+		}
+
+		c.Header("Retry-After", strconv.Itoa(mode.RetryAfterSeconds()))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":       message,
+			"maintenance": true,
+			"retry_after": mode.RetryAfterSeconds(),
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package maintenance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func setupGuardedRouter(sw *Switch) *gin.Engine {
+	router := gin.New()
+	router.Use(sw.Guard("/api/auth"))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) }
+	router.GET("/api/feeding", ok)
+	router.POST("/api/feeding", ok)
+	router.POST("/api/auth/refresh", ok)
+	return router
+}
+
+func TestGuard_Disabled(t *testing.T) {
+	router := setupGuardedRouter(NewSwitch(false, "", 0))
+
+	req := httptest.NewRequest("POST", "/api/feeding", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestGuard_Enabled(t *testing.T) {
+	sw := NewSwitch(true, "Upgrading the database", 2*time.Minute)
+	router := setupGuardedRouter(sw)
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{"GET", "/api/feeding", http.StatusOK},
+		{"POST", "/api/feeding", http.StatusServiceUnavailable},
+		{"POST", "/api/auth/refresh", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+		if w.Code == http.StatusServiceUnavailable && w.Header().Get("Retry-After") != "120" {
+			t.Errorf("Expected Retry-After 120, got %q", w.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestSwitch_Set(t *testing.T) {
+	sw := NewSwitch(false, "", 0)
+	if sw.Get().Enabled {
+		t.Fatal("Switch should start disabled")
+	}
+
+	first := sw.Set(true, "", 0)
+	if first.RetryAfter != DefaultRetryAfter || first.Since == nil {
+		t.Errorf("Set() = %+v, want default retry and a start time", first)
+	}
+
+	second := sw.Set(true, "Still going", 30*time.Second)
+	if !second.Since.Equal(*first.Since) || second.RetryAfterSeconds() != 30 {
+		t.Errorf("Set() while enabled = %+v, want the original start time kept", second)
+	}
+
+	if off := sw.Set(false, "", 0); off.Enabled || off.Since != nil {
+		t.Errorf("Set(false) = %+v", off)
+	}
+}
@@ -0,0 +1,47 @@
+package measure
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Duration is a length of time that encodes as a number of minutes in JSON,
+// which is how the app reports sleep and feeding lengths
+type Duration time.Duration
+
+// Between returns the time from start to end. It reports false for sessions
+// that are still open or end before they start.
+func Between(start time.Time, end *time.Time) (Duration, bool) {
+	if end == nil || end.Before(start) {
+		return 0, false
+	}
+	return Duration(end.Sub(start)), true
+}
+
+func (d Duration) Minutes() float64 {
+	return time.Duration(d).Minutes()
+}
+
+// String formats the duration to the minute, e.g. "45m" or "1h 20m"
+func (d Duration) String() string {
+	rounded := time.Duration(d).Round(time.Minute)
+	if rounded < time.Hour {
+		return fmt.Sprintf("%dm", int(rounded.Minutes()))
+	}
+	return fmt.Sprintf("%dh %dm", int(rounded.Hours()), int(rounded.Minutes())%60)
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(math.Round(d.Minutes()*10) / 10)
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var minutes float64
+	if err := json.Unmarshal(data, &minutes); err != nil {
+		return fmt.Errorf("duration must be a number of minutes: %w", err)
+	}
+	*d = Duration(minutes * float64(time.Minute))
+	return nil
+}
@@ -0,0 +1,57 @@
+package measure
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBetween(t *testing.T) {
+	start := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	before := start.Add(-time.Minute)
+
+	if d, ok := Between(start, &end); !ok || d.Minutes() != 90 {
+		t.Errorf("Between() = %v, %v, want 90 minutes", d.Minutes(), ok)
+	}
+	if _, ok := Between(start, nil); ok {
+		t.Error("Between() should report an open session")
+	}
+	if _, ok := Between(start, &before); ok {
+		t.Error("Between() should reject an end before the start")
+	}
+}
+
+func TestDuration_String(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{45 * time.Minute, "45m"},
+		{80*time.Minute + 40*time.Second, "1h 21m"},
+		{10 * time.Hour, "10h 0m"},
+	}
+	for _, tt := range tests {
+		if got := Duration(tt.d).String(); got != tt.want {
+			t.Errorf("Duration(%v).String() = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestDuration_JSON(t *testing.T) {
+	data, err := json.Marshal(Duration(95*time.Minute + 30*time.Second))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != "95.5" {
+		t.Errorf("Marshal() = %s, want 95.5", data)
+	}
+
+	var d Duration
+	if err := json.Unmarshal([]byte("45"), &d); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if time.Duration(d) != 45*time.Minute {
+		t.Errorf("Unmarshal() = %v, want 45m", time.Duration(d))
+	}
+}
@@ -0,0 +1,178 @@
+// Package measure holds value types for amounts with units and lengths of
+// time, so conversions and validation live in one place rather than in each
+// record type.
+package measure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalid      = errors.New("invalid quantity")
+	ErrIncompatible = errors.New("incompatible units")
+)
+
+// Unit is a unit of measure as written in records, e.g. "ml" or "mg". Units
+// this package does not know (IU, drops, tablets) are kept as written and
+// only match themselves.
+type Unit string
+
+const (
+	Milliliter Unit = "ml"
+	FluidOunce Unit = "oz" // US fluid ounce
+
+	Microgram Unit = "mcg"
+	Milligram Unit = "mg"
+	Gram      Unit = "g"
+	Kilogram  Unit = "kg"
+	Pound     Unit = "lb"
+
+	Centimeter Unit = "cm"
+	Inch       Unit = "in"
+)
+
+// Dimension groups units that convert into one another
+type Dimension string
+
+const (
+	DimensionVolume Dimension = "volume"
+	DimensionMass   Dimension = "mass"
+	DimensionLength Dimension = "length"
+)
+
+type unitInfo struct {
+	dim Dimension
+	// factor converts one of the unit into the dimension's base unit: ml, mg
+	// or cm
+	factor float64
+}
+
+var units = map[Unit]unitInfo{
+	Milliliter: {DimensionVolume, 1},
+	FluidOunce: {DimensionVolume, 29.5735},
+	Microgram:  {DimensionMass, 0.001},
+	Milligram:  {DimensionMass, 1},
+	Gram:       {DimensionMass, 1000},
+	Kilogram:   {DimensionMass, 1e6},
+	Pound:      {DimensionMass, 453592.37},
+	Centimeter: {DimensionLength, 1},
+	Inch:       {DimensionLength, 2.54},
+}
+
+// aliases maps common spellings onto the canonical unit
+var aliases = map[string]Unit{
+	"ml": Milliliter, "mls": Milliliter, "millilitre": Milliliter, "milliliter": Milliliter,
+	"oz": FluidOunce, "floz": FluidOunce, "fl oz": FluidOunce,
+	"mcg": Microgram, "µg": Microgram, "ug": Microgram,
+	"mg": Milligram, "g": Gram, "kg": Kilogram,
+	"lb": Pound, "lbs": Pound,
+	"cm": Centimeter, "in": Inch, "inch": Inch, "inches": Inch,
+}
+
+// ParseUnit returns the canonical form of a unit. Unknown units are returned
+// trimmed but otherwise as written.
+func ParseUnit(s string) Unit {
+	s = strings.TrimSpace(s)
+	if u, ok := aliases[strings.ToLower(s)]; ok {
+		return u
+	}
+	return Unit(s)
+}
+
+// Dimension reports what the unit measures, and false for units this package
+// cannot convert
+func (u Unit) Dimension() (Dimension, bool) {
+	info, ok := units[u]
+	return info.dim, ok
+}
+
+// Quantity is an amount in a unit, e.g. 250 mg. It encodes as
+// {"value":250,"unit":"mg"} and also decodes from the string "250 mg".
+type Quantity struct {
+	Value float64 `json:"value"`
+	Unit  Unit    `json:"unit"`
+}
+
+// ParseQuantity reads an amount such as "250", "250mg" or "2.5 ml". A bare
+// number takes defaultUnit.
+func ParseQuantity(s string, defaultUnit Unit) (Quantity, error) {
+	s = strings.TrimSpace(s)
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.') {
+		end++
+	}
+	value, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return Quantity{}, fmt.Errorf("%w: %q is not a number", ErrInvalid, s)
+	}
+	unit := defaultUnit
+	if rest := strings.TrimSpace(s[end:]); rest != "" {
+		unit = ParseUnit(rest)
+	}
+	q := Quantity{Value: value, Unit: ParseUnit(string(unit))}
+	if err := q.Validate(); err != nil {
+		return Quantity{}, err
+	}
+	return q, nil
+}
+
+// Validate requires a positive, finite amount with a unit
+func (q Quantity) Validate() error {
+	if math.IsNaN(q.Value) || math.IsInf(q.Value, 0) || q.Value <= 0 {
+		return fmt.Errorf("%w: amount must be positive", ErrInvalid)
+	}
+	if q.Unit == "" {
+		return fmt.Errorf("%w: unit is required", ErrInvalid)
+	}
+	return nil
+}
+
+// In converts the quantity to another unit of the same dimension. Units this
+// package does not know convert only to themselves, ignoring case.
+func (q Quantity) In(u Unit) (Quantity, error) {
+	if strings.EqualFold(string(q.Unit), string(u)) {
+		return Quantity{Value: q.Value, Unit: u}, nil
+	}
+	from, okFrom := units[q.Unit]
+	to, okTo := units[u]
+	if !okFrom || !okTo || from.dim != to.dim {
+		return Quantity{}, fmt.Errorf("%w: %s to %s", ErrIncompatible, q.Unit, u)
+	}
+	return Quantity{Value: q.Value * from.factor / to.factor, Unit: u}, nil
+}
+
+// Round returns the quantity rounded to the given number of decimals
+func (q Quantity) Round(decimals int) Quantity {
+	p := math.Pow(10, float64(decimals))
+	q.Value = math.Round(q.Value*p) / p
+	return q
+}
+
+func (q Quantity) String() string {
+	return strconv.FormatFloat(q.Value, 'f', -1, 64) + " " + string(q.Unit)
+}
+
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := ParseQuantity(s, "")
+		if err != nil {
+			return err
+		}
+		*q = parsed
+		return nil
+	}
+	// Alias drops the method set so the object form decodes normally
+	type plain Quantity
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*q = Quantity{Value: p.Value, Unit: ParseUnit(string(p.Unit))}
+	return nil
+}
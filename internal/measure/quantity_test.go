@@ -0,0 +1,94 @@
+package measure
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in      string
+		def     Unit
+		want    Quantity
+		wantErr error
+	}{
+		{"250", Milligram, Quantity{250, Milligram}, nil},
+		{"250mg", "", Quantity{250, Milligram}, nil},
+		{" 2.5 ML ", "", Quantity{2.5, Milliliter}, nil},
+		{"400 IU", Milligram, Quantity{400, "IU"}, nil},
+		{"4 fl oz", "", Quantity{4, FluidOunce}, nil},
+		{"250", "", Quantity{}, ErrInvalid},
+		{"0 mg", "", Quantity{}, ErrInvalid},
+		{"some", Milligram, Quantity{}, ErrInvalid},
+		{"", Milligram, Quantity{}, ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseQuantity(tt.in, tt.def)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseQuantity(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseQuantity(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantity_In(t *testing.T) {
+	tests := []struct {
+		name    string
+		q       Quantity
+		to      Unit
+		want    float64
+		wantErr error
+	}{
+		{"oz to ml", Quantity{4, FluidOunce}, Milliliter, 118.294, nil},
+		{"lb to kg", Quantity{10, Pound}, Kilogram, 4.5359237, nil},
+		{"g to mg", Quantity{0.25, Gram}, Milligram, 250, nil},
+		{"in to cm", Quantity{27, Inch}, Centimeter, 68.58, nil},
+		{"same unknown unit", Quantity{400, "IU"}, "iu", 400, nil},
+		{"across dimensions", Quantity{5, Milliliter}, Milligram, 0, ErrIncompatible},
+		{"unknown to known", Quantity{2, "drops"}, Milliliter, 0, ErrIncompatible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.q.In(tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("In() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (math.Abs(got.Value-tt.want) > 1e-9 || got.Unit != tt.to) {
+				t.Errorf("In() = %+v, want %v %s", got, tt.want, tt.to)
+			}
+		})
+	}
+}
+
+func TestQuantity_JSON(t *testing.T) {
+	data, err := json.Marshal(Quantity{250, Milligram})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"value":250,"unit":"mg"}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	for _, in := range []string{`{"value":250,"unit":"MG"}`, `"250 mg"`} {
+		var q Quantity
+		if err := json.Unmarshal([]byte(in), &q); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", in, err)
+		}
+		if q != (Quantity{250, Milligram}) {
+			t.Errorf("Unmarshal(%s) = %+v", in, q)
+		}
+	}
+
+	var q Quantity
+	if err := json.Unmarshal([]byte(`"lots"`), &q); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Unmarshal(lots) error = %v, want %v", err, ErrInvalid)
+	}
+}
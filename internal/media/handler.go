@@ -0,0 +1,224 @@
+package media
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// multipartOverhead allows for form fields and boundaries on top of the file
+	multipartOverhead = 1 << 20
+	// versionLength is how much of the content hash goes in content URLs
+	versionLength = 16
+
+	cacheImmutable  = "private, max-age=31536000, immutable"
+	cacheRevalidate = "private, no-cache"
+)
+
+type Handler struct {
+	service        Service
+	maxUploadBytes int64
+}
+
+func NewHandler(service Service, maxUploadBytes int64) *Handler {
+	if maxUploadBytes <= 0 {
+		maxUploadBytes = DefaultMaxUploadBytes
+	}
+	return &Handler{service: service, maxUploadBytes: maxUploadBytes}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.POST("", h.upload)
+	rg.GET("/:id", h.get)
+	rg.GET("/:id/content", h.content)
+	rg.POST("/:id/report", h.report)
+	rg.POST("/:id/release", h.release)
+	rg.DELETE("/:id", h.delete)
+}
+
+func (h *Handler) upload(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadBytes+multipartOverhead)
+
+	familyID := c.PostForm("family_id")
+	if familyID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "family_id is required"})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": ErrFileTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if header.Size > h.maxUploadBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": ErrFileTooLarge.Error()})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close() //nolint:errcheck // Best-effort close
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxUploadBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	m, err := h.service.Upload(c.Request.Context(), userID, &UploadRequest{
+		FamilyID: familyID,
+		Filename: header.Filename,
+		Data:     data,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, withContentURL(m))
+}
+
+func (h *Handler) get(c *gin.Context) {
+	m, err := h.service.Get(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, withContentURL(m))
+}
+
+func (h *Handler) content(c *gin.Context) {
+	userID, id := c.GetString("user_id"), c.Param("id")
+
+	// A client that already holds this version skips the download, which
+	// matters for avatars fetched on every screen of the mobile apps
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		m, err := h.service.Get(c.Request.Context(), userID, id)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if m.Status == StatusClean && etagMatches(match, etag(m)) {
+			setCacheHeaders(c, m)
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	m, data, err := h.service.Content(c.Request.Context(), userID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setCacheHeaders(c, m)
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": m.Filename}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, m.ContentType, data)
+}
+
+func (h *Handler) report(c *gin.Context) {
+	var req ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	m, err := h.service.Report(c.Request.Context(), c.GetString("user_id"), c.Param("id"), req.Reason)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, withContentURL(m))
+}
+
+func (h *Handler) release(c *gin.Context) {
+	m, err := h.service.Release(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, withContentURL(m))
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// setCacheHeaders lets clients keep content fetched through its versioned
+// URL indefinitely. Other requests revalidate against the ETag. Media is only
+// ever private: it is family data behind authentication, so shared caches
+// and CDNs must not store it.
+func setCacheHeaders(c *gin.Context, m *Media) {
+	c.Header("ETag", etag(m))
+	if v := c.Query("v"); v != "" && v == version(m) {
+		c.Header("Cache-Control", cacheImmutable)
+	} else {
+		c.Header("Cache-Control", cacheRevalidate)
+	}
+}
+
+func version(m *Media) string {
+	if len(m.SHA256) < versionLength {
+		return m.SHA256
+	}
+	return m.SHA256[:versionLength]
+}
+
+func etag(m *Media) string {
+	return `"` + m.SHA256 + `"`
+}
+
+// etagMatches reports whether an If-None-Match header names the ETag
+func etagMatches(header, tag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == tag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func withContentURL(m *Media) *Media {
+	if m != nil && m.SHA256 != "" {
+		m.ContentURL = "/api/media/" + m.ID + "/content?v=" + version(m)
+	}
+	return m
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, ErrNotAdmin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUnsupportedType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrEmptyFile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotServable), errors.Is(err, ErrInfected):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,292 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	uploadFn  func(ctx context.Context, userID string, req *UploadRequest) (*Media, error)
+	getFn     func(ctx context.Context, userID, id string) (*Media, error)
+	contentFn func(ctx context.Context, userID, id string) (*Media, []byte, error)
+	reportFn  func(ctx context.Context, userID, id, reason string) (*Media, error)
+	releaseFn func(ctx context.Context, userID, id string) (*Media, error)
+	deleteFn  func(ctx context.Context, userID, id string) error
+}
+
+func (m *mockService) Upload(ctx context.Context, userID string, req *UploadRequest) (*Media, error) {
+	if m.uploadFn != nil {
+		return m.uploadFn(ctx, userID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Media, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID, id)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Content(ctx context.Context, userID, id string) (*Media, []byte, error) {
+	if m.contentFn != nil {
+		return m.contentFn(ctx, userID, id)
+	}
+	return nil, nil, nil
+}
+
+func (m *mockService) Report(ctx context.Context, userID, id, reason string) (*Media, error) {
+	if m.reportFn != nil {
+		return m.reportFn(ctx, userID, id, reason)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Release(ctx context.Context, userID, id string) (*Media, error) {
+	if m.releaseFn != nil {
+		return m.releaseFn(ctx, userID, id)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
+	if m.deleteFn != nil {
+		return m.deleteFn(ctx, userID, id)
+	}
+	return nil
+}
+
+func (m *mockService) RescanPending(ctx context.Context) error {
+	return nil
+}
+
+func setupRouter(svc Service, maxUploadBytes int64) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc, maxUploadBytes)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+
+	handler.RegisterRoutes(router.Group("/media"))
+	return router
+}
+
+func multipartBody(t *testing.T, familyID string, content []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	if familyID != "" {
+		w.WriteField("family_id", familyID)
+	}
+	part, err := w.CreateFormFile("file", "photo.png")
+	if err != nil {
+		t.Fatalf("CreateFormFile() error = %v", err)
+	}
+	part.Write(content)
+	w.Close()
+	return body, w.FormDataContentType()
+}
+
+func TestUpload_Success(t *testing.T) {
+	var captured *UploadRequest
+	svc := &mockService{
+		uploadFn: func(ctx context.Context, userID string, req *UploadRequest) (*Media, error) {
+			captured = req
+			return &Media{ID: "media-1", Status: StatusClean}, nil
+		},
+	}
+	router := setupRouter(svc, 1024)
+
+	body, contentType := multipartBody(t, "family-1", pngHeader)
+	req := httptest.NewRequest("POST", "/media", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if captured.FamilyID != "family-1" || captured.Filename != "photo.png" || !bytes.Equal(captured.Data, pngHeader) {
+		t.Errorf("Unexpected upload request %+v", captured)
+	}
+}
+
+func TestUpload_TooLarge(t *testing.T) {
+	router := setupRouter(&mockService{}, 16)
+
+	body, contentType := multipartBody(t, "family-1", make([]byte, 64))
+	req := httptest.NewRequest("POST", "/media", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+}
+
+func TestUpload_MissingFamily(t *testing.T) {
+	router := setupRouter(&mockService{}, 1024)
+
+	body, contentType := multipartBody(t, "", pngHeader)
+	req := httptest.NewRequest("POST", "/media", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestContent_Served(t *testing.T) {
+	svc := &mockService{
+		contentFn: func(ctx context.Context, userID, id string) (*Media, []byte, error) {
+			return &Media{ID: id, Filename: "photo.png", ContentType: "image/png"}, pngHeader, nil
+		},
+	}
+	router := setupRouter(svc, 1024)
+
+	req := httptest.NewRequest("GET", "/media/media-1/content", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Type") != "image/png" || w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Unexpected headers %v", w.Header())
+	}
+}
+
+func TestContent_CacheHeaders(t *testing.T) {
+	sum := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	svc := &mockService{
+		contentFn: func(ctx context.Context, userID, id string) (*Media, []byte, error) {
+			return &Media{ID: id, ContentType: "image/png", SHA256: sum, Status: StatusClean}, pngHeader, nil
+		},
+	}
+	router := setupRouter(svc, 1024)
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/media/media-1/content?v=" + sum[:16], "private, max-age=31536000, immutable"},
+		{"/media/media-1/content?v=stale", "private, no-cache"},
+		{"/media/media-1/content", "private, no-cache"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.target, http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Cache-Control"); got != tt.want {
+			t.Errorf("%s: Cache-Control = %q, want %q", tt.target, got, tt.want)
+		}
+		if got := w.Header().Get("ETag"); got != `"`+sum+`"` {
+			t.Errorf("%s: ETag = %q", tt.target, got)
+		}
+	}
+}
+
+func TestContent_NotModified(t *testing.T) {
+	sum := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	loaded := false
+	svc := &mockService{
+		getFn: func(ctx context.Context, userID, id string) (*Media, error) {
+			return &Media{ID: id, SHA256: sum, Status: StatusClean}, nil
+		},
+		contentFn: func(ctx context.Context, userID, id string) (*Media, []byte, error) {
+			loaded = true
+			return &Media{ID: id, ContentType: "image/png", SHA256: sum, Status: StatusClean}, pngHeader, nil
+		},
+	}
+	router := setupRouter(svc, 1024)
+
+	req := httptest.NewRequest("GET", "/media/media-1/content", http.NoBody)
+	req.Header.Set("If-None-Match", `"`+sum+`"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("Expected status 304, got %d", w.Code)
+	}
+	if loaded {
+		t.Error("Expected the file not to be loaded for a matching ETag")
+	}
+
+	req = httptest.NewRequest("GET", "/media/media-1/content", http.NoBody)
+	req.Header.Set("If-None-Match", `"other"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !loaded {
+		t.Errorf("Expected the file for a stale ETag, got %d", w.Code)
+	}
+}
+
+func TestGet_ContentURL(t *testing.T) {
+	svc := &mockService{
+		getFn: func(ctx context.Context, userID, id string) (*Media, error) {
+			return &Media{ID: id, SHA256: "9f86d081884c7d659a2feaa0c55ad015"}, nil
+		},
+	}
+	router := setupRouter(svc, 1024)
+
+	req := httptest.NewRequest("GET", "/media/media-1", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !strings.Contains(w.Body.String(), `"content_url":"/api/media/media-1/content?v=9f86d081884c7d65"`) {
+		t.Errorf("Expected a versioned content URL, got %s", w.Body.String())
+	}
+}
+
+func TestContent_Quarantined(t *testing.T) {
+	svc := &mockService{
+		contentFn: func(ctx context.Context, userID, id string) (*Media, []byte, error) {
+			return nil, nil, ErrNotServable
+		},
+	}
+	router := setupRouter(svc, 1024)
+
+	req := httptest.NewRequest("GET", "/media/media-1/content", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusLocked {
+		t.Errorf("Expected status 423, got %d", w.Code)
+	}
+}
+
+func TestReport_RequiresReason(t *testing.T) {
+	router := setupRouter(&mockService{}, 1024)
+
+	req := httptest.NewRequest("POST", "/media/media-1/report", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,50 @@
+package media
+
+import "time"
+
+type Status string
+
+const (
+	StatusPending     Status = "pending"     // Awaiting a successful scan; not served
+	StatusClean       Status = "clean"       // Scanned and safe to serve
+	StatusQuarantined Status = "quarantined" // Infected or reported; not served
+)
+
+// DefaultMaxUploadBytes is used when no upload limit is configured
+const DefaultMaxUploadBytes int64 = 10 << 20
+
+// AllowedContentTypes are the sniffed MIME types accepted for upload
+var AllowedContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+type Media struct {
+	ID           string     `json:"id"`
+	FamilyID     string     `json:"family_id"`
+	UploadedBy   string     `json:"uploaded_by,omitempty"`
+	Filename     string     `json:"filename"`
+	ContentType  string     `json:"content_type"`
+	Size         int64      `json:"size"`
+	SHA256       string     `json:"sha256"`
+	Status       Status     `json:"status"`
+	StatusReason string     `json:"status_reason,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ScannedAt    *time.Time `json:"scanned_at,omitempty"`
+	// ContentURL carries the content hash, so clients can cache the file
+	// for good and pick up a replacement as a new URL
+	ContentURL string `json:"content_url,omitempty"`
+}
+
+type UploadRequest struct {
+	FamilyID string
+	Filename string
+	Data     []byte
+}
+
+type ReportRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
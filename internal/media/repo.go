@@ -0,0 +1,153 @@
+package media
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	GetByID(ctx context.Context, id string) (*Media, error)
+	GetContent(ctx context.Context, id string) ([]byte, error)
+	ListByStatus(ctx context.Context, status Status, limit int) ([]Media, error)
+	Create(ctx context.Context, m *Media, data []byte) error
+	UpdateStatus(ctx context.Context, id string, status Status, reason string, scannedAt *time.Time) error
+	Delete(ctx context.Context, id string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// pendingBacklog is how many uploads may wait for a scan before media is
+// reported as degraded. A backlog usually means the scanner is unreachable.
+const pendingBacklog = 100
+
+// HealthCheck checks the media table and how many uploads are waiting for a
+// virus scan
+func (r *repository) HealthCheck(ctx context.Context) error {
+	if err := health.Tables(ctx, r.db, "media"); err != nil {
+		return err
+	}
+	var pending int
+	query := `SELECT COUNT(*) FROM media WHERE status = $1`
+	if err := r.db.QueryRowContext(ctx, query, StatusPending).Scan(&pending); err != nil {
+		return err
+	}
+	if pending >= pendingBacklog {
+		return health.Degraded("%d uploads waiting for a scan", pending)
+	}
+	return nil
+}
+
+const mediaColumns = `id, family_id, uploaded_by, filename, content_type, size, sha256, status, status_reason, created_at, scanned_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMedia(row rowScanner) (*Media, error) {
+	var m Media
+	var uploadedBy, reason sql.NullString
+	var scannedAt sql.NullTime
+
+	if err := row.Scan(
+		&m.ID, &m.FamilyID, &uploadedBy, &m.Filename, &m.ContentType, &m.Size, &m.SHA256,
+		&m.Status, &reason, &m.CreatedAt, &scannedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	m.UploadedBy = uploadedBy.String
+	m.StatusReason = reason.String
+	if scannedAt.Valid {
+		m.ScannedAt = &scannedAt.Time
+	}
+	return &m, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Media, error) {
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE id = $1`
+
+	m, err := scanMedia(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return m, err
+}
+
+func (r *repository) GetContent(ctx context.Context, id string) ([]byte, error) {
+	query := `SELECT data FROM media WHERE id = $1`
+
+	var data []byte
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (r *repository) ListByStatus(ctx context.Context, status Status, limit int) ([]Media, error) {
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE status = $1 ORDER BY created_at ASC LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	items := []Media{}
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *m)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *repository) Create(ctx context.Context, m *Media, data []byte) error {
+	query := `
+		INSERT INTO media (id, family_id, uploaded_by, filename, content_type, size, sha256, data,
+		                   status, status_reason, created_at, scanned_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	var reason *string
+	if m.StatusReason != "" {
+		reason = &m.StatusReason
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		m.ID, m.FamilyID, m.UploadedBy, m.Filename, m.ContentType, m.Size, m.SHA256, data,
+		m.Status, reason, m.CreatedAt, m.ScannedAt,
+	)
+	return err
+}
+
+func (r *repository) UpdateStatus(ctx context.Context, id string, status Status, reason string, scannedAt *time.Time) error {
+	query := `UPDATE media SET status = $2, status_reason = $3, scanned_at = COALESCE($4, scanned_at) WHERE id = $1`
+
+	var reasonArg *string
+	if reason != "" {
+		reasonArg = &reason
+	}
+
+	_, err := r.db.ExecContext(ctx, query, id, status, reasonArg, scannedAt)
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM media WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,146 @@
+package media
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var mediaRowColumns = []string{
+	"id", "family_id", "uploaded_by", "filename", "content_type", "size", "sha256",
+	"status", "status_reason", "created_at", "scanned_at",
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(mediaRowColumns).
+		AddRow("media-1", "family-1", nil, "scan.png", "image/png", 1024, "abc", "quarantined", "reported: wrong child", now, nil)
+
+	mock.ExpectQuery("SELECT id, family_id, uploaded_by").
+		WithArgs("media-1").
+		WillReturnRows(rows)
+
+	m, err := repo.GetByID(context.Background(), "media-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if m.Status != StatusQuarantined || m.StatusReason != "reported: wrong child" || m.ScannedAt != nil {
+		t.Errorf("GetByID() = %+v", m)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT id, family_id, uploaded_by").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	m, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || m != nil {
+		t.Errorf("GetByID() = %v, %v; want nil, nil", m, err)
+	}
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	m := &Media{
+		ID: "media-1", FamilyID: "family-1", UploadedBy: "user-1", Filename: "scan.png",
+		ContentType: "image/png", Size: 3, SHA256: "abc", Status: StatusClean, CreatedAt: now, ScannedAt: &now,
+	}
+	data := []byte{1, 2, 3}
+
+	mock.ExpectExec("INSERT INTO media").
+		WithArgs("media-1", "family-1", "user-1", "scan.png", "image/png", int64(3), "abc", data,
+			StatusClean, nil, now, &now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), m, data); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListByStatus(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(mediaRowColumns).
+		AddRow("media-1", "family-1", "user-1", "a.png", "image/png", 10, "abc", "pending", nil, now, nil)
+
+	mock.ExpectQuery("SELECT id, family_id, uploaded_by").
+		WithArgs(StatusPending, 50).
+		WillReturnRows(rows)
+
+	items, err := repo.ListByStatus(context.Background(), StatusPending, 50)
+	if err != nil {
+		t.Fatalf("ListByStatus() error = %v", err)
+	}
+	if len(items) != 1 || items[0].UploadedBy != "user-1" {
+		t.Errorf("ListByStatus() = %+v", items)
+	}
+}
+
+func TestRepository_UpdateStatus(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectExec("UPDATE media SET status").
+		WithArgs("media-1", StatusClean, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdateStatus(context.Background(), "media-1", StatusClean, "", nil); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_HealthCheck_Backlog(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db).(*repository)
+
+	mock.ExpectQuery(`SELECT 1 FROM media LIMIT 1`).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM media WHERE status`).
+		WithArgs(StatusPending).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(pendingBacklog))
+
+	err := repo.HealthCheck(context.Background())
+	if !health.IsDegraded(err) {
+		t.Errorf("HealthCheck() = %v, want degraded with %d uploads pending", err, pendingBacklog)
+	}
+}
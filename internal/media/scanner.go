@@ -0,0 +1,97 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ScanResult is the verdict of a malware scan
+type ScanResult struct {
+	Clean     bool
+	Signature string // Name of the detected threat when not clean
+}
+
+// Scanner checks uploaded content for malware before it is served
+type Scanner interface {
+	Scan(ctx context.Context, data []byte) (*ScanResult, error)
+}
+
+// NoopScanner accepts everything. It is used when no scanner is configured,
+// leaving size and MIME validation as the only checks.
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(ctx context.Context, data []byte) (*ScanResult, error) {
+	return &ScanResult{Clean: true}, nil
+}
+
+// ClamAVScanner streams content to a clamd daemon using the INSTREAM command
+type ClamAVScanner struct {
+	addr    string
+	timeout time.Duration
+}
+
+func NewClamAVScanner(addr string) *ClamAVScanner {
+	return &ClamAVScanner{addr: addr, timeout: 30 * time.Second}
+}
+
+const clamChunkSize = 64 << 10
+
+func (s *ClamAVScanner) Scan(ctx context.Context, data []byte) (*ScanResult, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck // Best-effort close
+
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("failed to start clamd stream: %w", err)
+	}
+
+	size := make([]byte, 4)
+	for chunk := range slices.Chunk(data, clamChunkSize) {
+		binary.BigEndian.PutUint32(size, uint32(len(chunk))) //nolint:gosec // Chunks are at most 64KiB
+		if _, err := conn.Write(size); err != nil {
+			return nil, err
+		}
+		if _, err := conn.Write(chunk); err != nil {
+			return nil, err
+		}
+	}
+	// A zero-length chunk terminates the stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, err
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+
+	return parseClamReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseClamReply interprets replies such as "stream: OK" and
+// "stream: Eicar-Test-Signature FOUND"
+func parseClamReply(reply string) (*ScanResult, error) {
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case verdict == "OK":
+		return &ScanResult{Clean: true}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &ScanResult{Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return nil, fmt.Errorf("unexpected clamd reply: %q", reply)
+	}
+}
@@ -0,0 +1,118 @@
+package media
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestParseClamReply(t *testing.T) {
+	tests := []struct {
+		reply     string
+		wantClean bool
+		wantSig   string
+		wantErr   bool
+	}{
+		{"stream: OK", true, "", false},
+		{"stream: Eicar-Test-Signature FOUND", false, "Eicar-Test-Signature", false},
+		{"INSTREAM size limit exceeded. ERROR", false, "", true},
+	}
+
+	for _, tt := range tests {
+		result, err := parseClamReply(tt.reply)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseClamReply(%q) error = %v, wantErr %v", tt.reply, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if result.Clean != tt.wantClean || result.Signature != tt.wantSig {
+			t.Errorf("parseClamReply(%q) = %+v", tt.reply, result)
+		}
+	}
+}
+
+// fakeClamd accepts one INSTREAM session and flags content containing "EICAR"
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		if _, err := r.ReadString('\x00'); err != nil {
+			return
+		}
+
+		var body strings.Builder
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(r, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			chunk := make([]byte, n)
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return
+			}
+			body.Write(chunk)
+		}
+
+		if strings.Contains(body.String(), "EICAR") {
+			conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+			return
+		}
+		conn.Write([]byte("stream: OK\x00"))
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestClamAVScanner_Clean(t *testing.T) {
+	scanner := NewClamAVScanner(fakeClamd(t))
+
+	result, err := scanner.Scan(context.Background(), []byte("holiday photo"))
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !result.Clean {
+		t.Errorf("Scan() = %+v, want clean", result)
+	}
+}
+
+func TestClamAVScanner_Infected(t *testing.T) {
+	scanner := NewClamAVScanner(fakeClamd(t))
+
+	result, err := scanner.Scan(context.Background(), []byte("X5O!P%@AP EICAR test"))
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.Clean || result.Signature != "Eicar-Test-Signature" {
+		t.Errorf("Scan() = %+v, want infected", result)
+	}
+}
+
+func TestClamAVScanner_Unreachable(t *testing.T) {
+	scanner := NewClamAVScanner("127.0.0.1:1")
+
+	if _, err := scanner.Scan(context.Background(), []byte("data")); err == nil {
+		t.Error("Scan() should fail when clamd is unreachable")
+	}
+}
@@ -0,0 +1,272 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+)
+
+var (
+	ErrMediaNotFound   = errors.New("media not found")
+	ErrEmptyFile       = errors.New("file is empty")
+	ErrFileTooLarge    = errors.New("file exceeds the upload limit")
+	ErrUnsupportedType = errors.New("unsupported file type")
+	ErrNotServable     = errors.New("media is not available until it passes scanning")
+	ErrNotMember       = errors.New("user is not a member of this family")
+	ErrNotAdmin        = errors.New("only admins can release quarantined media")
+	ErrInfected        = errors.New("media failed malware scanning")
+)
+
+// rescanBatchSize bounds how many pending uploads one rescan pass handles
+const rescanBatchSize = 50
+
+type Service interface {
+	Upload(ctx context.Context, userID string, req *UploadRequest) (*Media, error)
+	Get(ctx context.Context, userID, id string) (*Media, error)
+	Content(ctx context.Context, userID, id string) (*Media, []byte, error)
+	Report(ctx context.Context, userID, id, reason string) (*Media, error)
+	Release(ctx context.Context, userID, id string) (*Media, error)
+	Delete(ctx context.Context, userID, id string) error
+	RescanPending(ctx context.Context) error
+}
+
+type service struct {
+	repo           Repository
+	familyService  family.Service
+	scanner        Scanner
+	maxUploadBytes int64
+}
+
+func NewService(repo Repository, familyService family.Service, scanner Scanner, maxUploadBytes int64) Service {
+	if maxUploadBytes <= 0 {
+		maxUploadBytes = DefaultMaxUploadBytes
+	}
+	return &service{
+		repo:           repo,
+		familyService:  familyService,
+		scanner:        scanner,
+		maxUploadBytes: maxUploadBytes,
+	}
+}
+
+// HealthCheck reports whether uploads can be read and are being scanned
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+// Upload validates and stores a file. The MIME type is sniffed from the
+// content rather than trusted from the client. Files that cannot be scanned
+// right now are kept as pending and picked up by RescanPending.
+func (s *service) Upload(ctx context.Context, userID string, req *UploadRequest) (*Media, error) {
+	if err := s.requireMember(ctx, req.FamilyID, userID); err != nil {
+		return nil, err
+	}
+
+	size := int64(len(req.Data))
+	if size == 0 {
+		return nil, ErrEmptyFile
+	}
+	if size > s.maxUploadBytes {
+		return nil, ErrFileTooLarge
+	}
+
+	contentType := sniffContentType(req.Data)
+	if !AllowedContentTypes[contentType] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	sum := sha256.Sum256(req.Data)
+	m := &Media{
+		ID:          ids.New(),
+		FamilyID:    req.FamilyID,
+		UploadedBy:  userID,
+		Filename:    sanitiseFilename(req.Filename),
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      hex.EncodeToString(sum[:]),
+		Status:      StatusPending,
+		CreatedAt:   time.Now(),
+	}
+
+	s.applyScan(ctx, m, req.Data)
+
+	if err := s.repo.Create(ctx, m, req.Data); err != nil {
+		return nil, fmt.Errorf("failed to store media: %w", err)
+	}
+
+	return m, nil
+}
+
+func (s *service) Get(ctx context.Context, userID, id string) (*Media, error) {
+	m, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrMediaNotFound
+	}
+	if err := s.requireMember(ctx, m.FamilyID, userID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Content returns the file bytes, but only for media that passed scanning
+func (s *service) Content(ctx context.Context, userID, id string) (*Media, []byte, error) {
+	m, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m.Status != StatusClean {
+		return nil, nil, ErrNotServable
+	}
+
+	data, err := s.repo.GetContent(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load media: %w", err)
+	}
+	if data == nil {
+		return nil, nil, ErrMediaNotFound
+	}
+	return m, data, nil
+}
+
+// Report quarantines media flagged by a family member until an admin releases it
+func (s *service) Report(ctx context.Context, userID, id, reason string) (*Media, error) {
+	m, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Status = StatusQuarantined
+	m.StatusReason = "reported: " + strings.TrimSpace(reason)
+	if err := s.repo.UpdateStatus(ctx, id, m.Status, m.StatusReason, nil); err != nil {
+		return nil, fmt.Errorf("failed to report media: %w", err)
+	}
+	return m, nil
+}
+
+// Release lets an admin clear quarantined media. The file is rescanned first,
+// so anything the scanner flags stays quarantined.
+func (s *service) Release(ctx context.Context, userID, id string) (*Media, error) {
+	m, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.familyService.GetMemberRole(ctx, m.FamilyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "admin" {
+		return nil, ErrNotAdmin
+	}
+
+	data, err := s.repo.GetContent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load media: %w", err)
+	}
+
+	m.Status, m.StatusReason = StatusPending, ""
+	s.applyScan(ctx, m, data)
+
+	if err := s.repo.UpdateStatus(ctx, id, m.Status, m.StatusReason, m.ScannedAt); err != nil {
+		return nil, fmt.Errorf("failed to release media: %w", err)
+	}
+	if m.Status == StatusQuarantined {
+		return nil, ErrInfected
+	}
+	return m, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// RescanPending retries scanning for uploads whose first scan failed
+func (s *service) RescanPending(ctx context.Context) error {
+	pending, err := s.repo.ListByStatus(ctx, StatusPending, rescanBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list pending media: %w", err)
+	}
+
+	for i := range pending {
+		m := &pending[i]
+		data, err := s.repo.GetContent(ctx, m.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load media %s: %w", m.ID, err)
+		}
+
+		s.applyScan(ctx, m, data)
+		if m.Status == StatusPending {
+			continue // Scanner still unavailable; try again next pass
+		}
+		if err := s.repo.UpdateStatus(ctx, m.ID, m.Status, m.StatusReason, m.ScannedAt); err != nil {
+			return fmt.Errorf("failed to update media %s: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+// applyScan runs the scanner and records the verdict on m. Scanner errors
+// leave the media pending rather than failing the caller.
+func (s *service) applyScan(ctx context.Context, m *Media, data []byte) {
+	result, err := s.scanner.Scan(ctx, data)
+	if err != nil {
+		log.Printf("[media] scan of %s failed, leaving pending: %v", m.ID, err)
+		return
+	}
+
+	now := time.Now()
+	m.ScannedAt = &now
+	if result.Clean {
+		m.Status = StatusClean
+		return
+	}
+	m.Status = StatusQuarantined
+	m.StatusReason = "malware: " + result.Signature
+}
+
+func (s *service) requireMember(ctx context.Context, familyID, userID string) error {
+	if _, err := s.familyService.GetMemberRole(ctx, familyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	return nil
+}
+
+func sniffContentType(data []byte) string {
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return contentType
+}
+
+func sanitiseFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		return "upload"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
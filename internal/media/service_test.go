@@ -0,0 +1,218 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+// pngHeader is enough for content sniffing to detect image/png
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+type mockRepository struct {
+	media map[string]*Media
+	data  map[string][]byte
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		media: make(map[string]*Media),
+		data:  make(map[string][]byte),
+	}
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Media, error) {
+	if item, ok := m.media[id]; ok {
+		copied := *item
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) GetContent(ctx context.Context, id string) ([]byte, error) {
+	return m.data[id], nil
+}
+
+func (m *mockRepository) ListByStatus(ctx context.Context, status Status, limit int) ([]Media, error) {
+	var result []Media
+	for _, item := range m.media {
+		if item.Status == status {
+			result = append(result, *item)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) Create(ctx context.Context, item *Media, data []byte) error {
+	copied := *item
+	m.media[item.ID] = &copied
+	m.data[item.ID] = data
+	return nil
+}
+
+func (m *mockRepository) UpdateStatus(ctx context.Context, id string, status Status, reason string, scannedAt *time.Time) error {
+	m.media[id].Status = status
+	m.media[id].StatusReason = reason
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	delete(m.media, id)
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	roles map[string]string
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if role, ok := m.roles[familyID+"/"+userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockScanner struct {
+	result *ScanResult
+	err    error
+}
+
+func (m *mockScanner) Scan(ctx context.Context, data []byte) (*ScanResult, error) {
+	return m.result, m.err
+}
+
+func newTestService(scanner Scanner) (Service, *mockRepository) {
+	repo := newMockRepository()
+	familySvc := &mockFamilyService{roles: map[string]string{
+		"family-1/admin-1":  "admin",
+		"family-1/member-1": "member",
+	}}
+	return NewService(repo, familySvc, scanner, 1024), repo
+}
+
+func TestService_Upload_Clean(t *testing.T) {
+	svc, repo := newTestService(NoopScanner{})
+
+	m, err := svc.Upload(context.Background(), "member-1", &UploadRequest{
+		FamilyID: "family-1", Filename: "../../etc/photo.png", Data: pngHeader,
+	})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if m.Status != StatusClean || m.ContentType != "image/png" {
+		t.Errorf("Upload() = %+v, want clean image/png", m)
+	}
+	if m.Filename != "photo.png" {
+		t.Errorf("Upload() filename = %q, want path stripped", m.Filename)
+	}
+	if _, ok := repo.media[m.ID]; !ok {
+		t.Error("Upload() should store the media")
+	}
+}
+
+func TestService_Upload_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		data    []byte
+		wantErr error
+	}{
+		{"not a member", "stranger", pngHeader, ErrNotMember},
+		{"empty", "member-1", nil, ErrEmptyFile},
+		{"too large", "member-1", make([]byte, 2048), ErrFileTooLarge},
+		{"disallowed type", "member-1", []byte("#!/bin/sh\nrm -rf /"), ErrUnsupportedType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(NoopScanner{})
+			_, err := svc.Upload(context.Background(), tt.userID, &UploadRequest{FamilyID: "family-1", Data: tt.data})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Upload() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_Upload_Infected(t *testing.T) {
+	svc, _ := newTestService(&mockScanner{result: &ScanResult{Signature: "Eicar-Test-Signature"}})
+
+	m, err := svc.Upload(context.Background(), "member-1", &UploadRequest{FamilyID: "family-1", Data: pngHeader})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if m.Status != StatusQuarantined || m.StatusReason != "malware: Eicar-Test-Signature" {
+		t.Errorf("Upload() = %+v, want quarantined", m)
+	}
+
+	if _, _, err := svc.Content(context.Background(), "member-1", m.ID); !errors.Is(err, ErrNotServable) {
+		t.Errorf("Content() error = %v, want ErrNotServable", err)
+	}
+}
+
+func TestService_Upload_ScannerDown(t *testing.T) {
+	scanner := &mockScanner{err: errors.New("connection refused")}
+	svc, repo := newTestService(scanner)
+
+	m, err := svc.Upload(context.Background(), "member-1", &UploadRequest{FamilyID: "family-1", Data: pngHeader})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if m.Status != StatusPending {
+		t.Errorf("Upload() status = %s, want pending", m.Status)
+	}
+
+	// Scanner comes back; the rescan clears the upload
+	scanner.err = nil
+	scanner.result = &ScanResult{Clean: true}
+	if err := svc.RescanPending(context.Background()); err != nil {
+		t.Fatalf("RescanPending() error = %v", err)
+	}
+	if repo.media[m.ID].Status != StatusClean {
+		t.Errorf("RescanPending() status = %s, want clean", repo.media[m.ID].Status)
+	}
+}
+
+func TestService_ReportAndRelease(t *testing.T) {
+	svc, _ := newTestService(NoopScanner{})
+
+	m, _ := svc.Upload(context.Background(), "member-1", &UploadRequest{FamilyID: "family-1", Data: pngHeader})
+
+	reported, err := svc.Report(context.Background(), "member-1", m.ID, "wrong child")
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if reported.Status != StatusQuarantined {
+		t.Errorf("Report() status = %s, want quarantined", reported.Status)
+	}
+
+	if _, err := svc.Release(context.Background(), "member-1", m.ID); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("Release() by member error = %v, want ErrNotAdmin", err)
+	}
+
+	released, err := svc.Release(context.Background(), "admin-1", m.ID)
+	if err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if released.Status != StatusClean {
+		t.Errorf("Release() status = %s, want clean", released.Status)
+	}
+
+	_, data, err := svc.Content(context.Background(), "member-1", m.ID)
+	if err != nil || len(data) == 0 {
+		t.Errorf("Content() after release = %d bytes, %v", len(data), err)
+	}
+}
+
+func TestService_Get_NotFound(t *testing.T) {
+	svc, _ := newTestService(NoopScanner{})
+
+	if _, err := svc.Get(context.Background(), "member-1", "missing"); !errors.Is(err, ErrMediaNotFound) {
+		t.Errorf("Get() error = %v, want ErrMediaNotFound", err)
+	}
+}
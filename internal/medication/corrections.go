@@ -0,0 +1,105 @@
+package medication
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/occurrence"
+)
+
+// WithCorrections keeps the original of every corrected dose. The store must
+// be built on the same database as the repository.
+func WithCorrections(store corrections.Store) Option {
+	return func(s *service) {
+		s.corrections = store
+	}
+}
+
+func (s *service) CorrectLog(ctx context.Context, userID, medicationID, logID string, req *CorrectLogRequest) (*MedicationLog, error) {
+	if s.corrections == nil {
+		return nil, corrections.ErrUnavailable
+	}
+	reason, err := corrections.Reason(req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	log, err := s.getLog(ctx, userID, medicationID, logID)
+	if err != nil {
+		return nil, err
+	}
+	med, err := s.repo.GetByID(ctx, medicationID)
+	if err != nil {
+		return nil, err
+	}
+	if med == nil {
+		return nil, ErrLogNotFound
+	}
+	if err := s.authorize(ctx, userID, med.ChildID); err != nil {
+		return nil, err
+	}
+	if _, err := med.DoseOf(req.Dosage); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDosage, err)
+	}
+
+	now := time.Now()
+	occurrence.Adjust(ctx, "given_at", &req.GivenAt, now)
+	if err := occurrence.Validate(req.GivenAt, now); err != nil {
+		return nil, err
+	}
+
+	original := *log
+	log.GivenAt = req.GivenAt
+	log.Dosage = req.Dosage
+	log.Notes = req.Notes
+	log.CorrectedAt = &now
+
+	correction := &corrections.Correction{
+		EntityType:  corrections.EntityMedicationLog,
+		EntityID:    log.ID,
+		ChildID:     log.ChildID,
+		Reason:      reason,
+		CorrectedBy: userID,
+		CorrectedAt: now,
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateLog(ctx, log); err != nil {
+			return err
+		}
+		return s.corrections.Record(ctx, correction, &original, log)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to correct medication log: %w", err)
+	}
+
+	return log, nil
+}
+
+func (s *service) ListLogCorrections(ctx context.Context, userID, medicationID, logID string) ([]corrections.Correction, error) {
+	if s.corrections == nil {
+		return nil, corrections.ErrUnavailable
+	}
+	log, err := s.getLog(ctx, userID, medicationID, logID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, userID, log.ChildID); err != nil {
+		return nil, err
+	}
+	return s.corrections.List(ctx, corrections.EntityMedicationLog, logID)
+}
+
+// getLog returns the log only if it is a dose of medicationID that userID
+// may see
+func (s *service) getLog(ctx context.Context, userID, medicationID, logID string) (*MedicationLog, error) {
+	log, err := s.repo.GetLogByID(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	if log == nil || log.MedicationID != medicationID || !log.VisibleTo(userID) {
+		return nil, ErrLogNotFound
+	}
+	return log, nil
+}
@@ -0,0 +1,36 @@
+package medication
+
+import (
+	"context"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/delta"
+)
+
+// WithTombstones remembers deleted medications so that ListSince can
+// report them
+func WithTombstones(tombstones delta.Tombstones) Option {
+	return func(s *service) {
+		s.tombstones = tombstones
+	}
+}
+
+// ListSince returns the medications matching filter that were written after
+// since, and the IDs of the child's medications deleted since.
+// filter.ChildID must be set.
+func (s *service) ListSince(ctx context.Context, filter *MedicationFilter, since time.Time) (*delta.Changes[Medication], error) {
+	now := time.Now()
+	deleted, err := delta.Deleted(ctx, s.tombstones, delta.EntityMedication, filter.ChildID, since, now)
+	if err != nil {
+		return nil, err
+	}
+
+	meds, err := s.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return delta.Build(meds, since, deleted, now, func(m *Medication) (time.Time, time.Time) {
+		return m.CreatedAt, m.UpdatedAt
+	}), nil
+}
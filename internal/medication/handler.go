@@ -1,8 +1,15 @@
 package medication
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,19 +29,48 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.DELETE("/:id", h.delete)
 	rg.POST("/:id/deactivate", h.deactivate)
 
+	rg.GET("/ingredients", h.lookupIngredients)
 	rg.POST("/log", h.logMedication)
 	rg.GET("/:id/logs", h.getLogs)
 	rg.GET("/:id/logs/last", h.getLastLog)
+	rg.POST("/:id/logs/:logId/corrections", h.correctLog)
+	rg.GET("/:id/logs/:logId/corrections", h.listLogCorrections)
+	rg.POST("/:id/logs/:logId/private", h.setLogPrivate)
 }
 
 func (h *Handler) list(c *gin.Context) {
 	filter := &MedicationFilter{
 		ChildID:    c.Query("child_id"),
 		ActiveOnly: c.Query("active_only") == "true",
+		ViewerID:   c.GetString("user_id"),
+	}
+
+	since, err := delta.ParseSince(c.Query("since"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if since != nil {
+		if filter.ChildID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "child_id is required with since"})
+			return
+		}
+		changes, err := h.service.ListSince(c.Request.Context(), filter, *since)
+		if errors.Is(err, delta.ErrSinceExpired) {
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, changes)
+		return
 	}
+
 	meds, err := h.service.List(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, meds)
@@ -47,9 +83,14 @@ func (h *Handler) create(c *gin.Context) {
 		return
 	}
 
+	req.UserID = c.GetString("user_id")
 	med, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, ErrInvalidDosage) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, med)
@@ -57,9 +98,9 @@ func (h *Handler) create(c *gin.Context) {
 
 func (h *Handler) get(c *gin.Context) {
 	id := c.Param("id")
-	med, err := h.service.Get(c.Request.Context(), id)
+	med, err := h.service.Get(c.Request.Context(), c.GetString("user_id"), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, med)
@@ -73,9 +114,13 @@ func (h *Handler) update(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	med, err := h.service.Update(c.Request.Context(), id, &req)
+	med, err := h.service.Update(c.Request.Context(), c.GetString("user_id"), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, ErrInvalidDosage) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, med)
@@ -83,8 +128,8 @@ func (h *Handler) update(c *gin.Context) {
 
 func (h *Handler) delete(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), id); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -92,8 +137,8 @@ func (h *Handler) delete(c *gin.Context) {
 
 func (h *Handler) deactivate(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.service.Deactivate(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Deactivate(c.Request.Context(), c.GetString("user_id"), id); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusOK)
@@ -109,7 +154,11 @@ func (h *Handler) logMedication(c *gin.Context) {
 	userID := c.GetString("user_id")
 	log, err := h.service.LogMedication(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, occurrence.ErrInvalid) || errors.Is(err, ErrInvalidDosage) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, log)
@@ -117,9 +166,9 @@ func (h *Handler) logMedication(c *gin.Context) {
 
 func (h *Handler) getLogs(c *gin.Context) {
 	id := c.Param("id")
-	logs, err := h.service.GetLogs(c.Request.Context(), id)
+	logs, err := h.service.GetLogs(c.Request.Context(), id, c.GetString("user_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, logs)
@@ -127,10 +176,83 @@ func (h *Handler) getLogs(c *gin.Context) {
 
 func (h *Handler) getLastLog(c *gin.Context) {
 	id := c.Param("id")
-	log, err := h.service.GetLastLog(c.Request.Context(), id)
+	log, err := h.service.GetLastLog(c.Request.Context(), id, c.GetString("user_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, log)
 }
+
+func (h *Handler) correctLog(c *gin.Context) {
+	var req CorrectLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	log, err := h.service.CorrectLog(c.Request.Context(), userID, c.Param("id"), c.Param("logId"), &req)
+	if err != nil {
+		respondCorrectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, log)
+}
+
+func (h *Handler) listLogCorrections(c *gin.Context) {
+	list, err := h.service.ListLogCorrections(c.Request.Context(), c.GetString("user_id"), c.Param("id"), c.Param("logId"))
+	if err != nil {
+		respondCorrectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *Handler) setLogPrivate(c *gin.Context) {
+	var req SetPrivateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.SetLogPrivate(c.Request.Context(), c.GetString("user_id"), c.Param("id"), c.Param("logId"), req.Private); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func respondCorrectionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrLogNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, corrections.ErrReasonRequired), errors.Is(err, ErrInvalidDosage), errors.Is(err, occurrence.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondError(c, err)
+	}
+}
+
+// respondError maps errors from reading and changing medications and doses
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, visibility.ErrChildNotFound), errors.Is(err, ErrLogNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, family.ErrNotMember), errors.Is(err, visibility.ErrHidden), errors.Is(err, ErrNotGiver):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// lookupIngredients shows what a name normalizes to, so clients can show
+// the ingredients before the medication is saved
+func (h *Handler) lookupIngredients(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"name": name, "ingredients": NormalizeIngredients(name)})
+}
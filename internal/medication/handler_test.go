@@ -5,11 +5,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"iter"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/visibility"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,12 +29,15 @@ type mockService struct {
 	createFn        func(ctx context.Context, req *CreateMedicationRequest) (*Medication, error)
 	getFn           func(ctx context.Context, id string) (*Medication, error)
 	listFn          func(ctx context.Context, filter *MedicationFilter) ([]Medication, error)
+	listSinceFn     func(ctx context.Context, filter *MedicationFilter, since time.Time) (*delta.Changes[Medication], error)
 	updateFn        func(ctx context.Context, id string, req *CreateMedicationRequest) (*Medication, error)
 	deleteFn        func(ctx context.Context, id string) error
 	deactivateFn    func(ctx context.Context, id string) error
 	logMedicationFn func(ctx context.Context, userID string, req *LogMedicationRequest) (*MedicationLog, error)
 	getLogsFn       func(ctx context.Context, medicationID string) ([]MedicationLog, error)
 	getLastLogFn    func(ctx context.Context, medicationID string) (*MedicationLog, error)
+	correctLogFn    func(ctx context.Context, userID, medicationID, logID string, req *CorrectLogRequest) (*MedicationLog, error)
+	setLogPrivateFn func(ctx context.Context, userID, medicationID, logID string, private bool) error
 }
 
 func (m *mockService) Create(ctx context.Context, req *CreateMedicationRequest) (*Medication, error) {
@@ -37,7 +47,7 @@ func (m *mockService) Create(ctx context.Context, req *CreateMedicationRequest)
 	return nil, nil
 }
 
-func (m *mockService) Get(ctx context.Context, id string) (*Medication, error) {
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Medication, error) {
 	if m.getFn != nil {
 		return m.getFn(ctx, id)
 	}
@@ -51,21 +61,32 @@ func (m *mockService) List(ctx context.Context, filter *MedicationFilter) ([]Med
 	return nil, nil
 }
 
-func (m *mockService) Update(ctx context.Context, id string, req *CreateMedicationRequest) (*Medication, error) {
+func (m *mockService) Stream(ctx context.Context, filter *MedicationFilter) iter.Seq2[Medication, error] {
+	return nil
+}
+
+func (m *mockService) ListSince(ctx context.Context, filter *MedicationFilter, since time.Time) (*delta.Changes[Medication], error) {
+	if m.listSinceFn != nil {
+		return m.listSinceFn(ctx, filter, since)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Update(ctx context.Context, userID, id string, req *CreateMedicationRequest) (*Medication, error) {
 	if m.updateFn != nil {
 		return m.updateFn(ctx, id, req)
 	}
 	return nil, nil
 }
 
-func (m *mockService) Delete(ctx context.Context, id string) error {
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
 	if m.deleteFn != nil {
 		return m.deleteFn(ctx, id)
 	}
 	return nil
 }
 
-func (m *mockService) Deactivate(ctx context.Context, id string) error {
+func (m *mockService) Deactivate(ctx context.Context, userID, id string) error {
 	if m.deactivateFn != nil {
 		return m.deactivateFn(ctx, id)
 	}
@@ -79,23 +100,42 @@ func (m *mockService) LogMedication(ctx context.Context, userID string, req *Log
 	return nil, nil
 }
 
-func (m *mockService) GetLogs(ctx context.Context, medicationID string) ([]MedicationLog, error) {
+func (m *mockService) GetLogs(ctx context.Context, medicationID, viewerID string) ([]MedicationLog, error) {
 	if m.getLogsFn != nil {
 		return m.getLogsFn(ctx, medicationID)
 	}
 	return nil, nil
 }
 
-func (m *mockService) GetLastLog(ctx context.Context, medicationID string) (*MedicationLog, error) {
+func (m *mockService) GetLastLog(ctx context.Context, medicationID, viewerID string) (*MedicationLog, error) {
 	if m.getLastLogFn != nil {
 		return m.getLastLogFn(ctx, medicationID)
 	}
 	return nil, nil
 }
 
+func (m *mockService) CorrectLog(ctx context.Context, userID, medicationID, logID string, req *CorrectLogRequest) (*MedicationLog, error) {
+	if m.correctLogFn != nil {
+		return m.correctLogFn(ctx, userID, medicationID, logID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ListLogCorrections(ctx context.Context, userID, medicationID, logID string) ([]corrections.Correction, error) {
+	return []corrections.Correction{}, nil
+}
+
+func (m *mockService) SetLogPrivate(ctx context.Context, userID, medicationID, logID string, private bool) error {
+	if m.setLogPrivateFn != nil {
+		return m.setLogPrivateFn(ctx, userID, medicationID, logID, private)
+	}
+	return nil
+}
+
 // setupRouter creates a test router with the handler registered
 func setupRouter(svc Service) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 	handler := NewHandler(svc)
 
 	// Add middleware to set user_id in context (simulating auth)
@@ -318,6 +358,41 @@ func TestList_ServiceError(t *testing.T) {
 	}
 }
 
+func TestList_Since(t *testing.T) {
+	svc := &mockService{
+		listSinceFn: func(ctx context.Context, filter *MedicationFilter, since time.Time) (*delta.Changes[Medication], error) {
+			if since.Year() == 2020 {
+				return nil, delta.ErrSinceExpired
+			}
+			return &delta.Changes[Medication]{
+				Created: []Medication{{ID: "med-1"}},
+				Updated: []Medication{},
+				Deleted: []string{},
+			}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+	}{
+		{"child_id=child-123&since=2025-01-01T00:00:00Z", http.StatusOK},
+		{"since=2025-01-01T00:00:00Z", http.StatusBadRequest},
+		{"child_id=child-123&since=recently", http.StatusBadRequest},
+		{"child_id=child-123&since=2020-01-01T00:00:00Z", http.StatusGone},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/medications?"+tt.query, http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.query, tt.wantStatus, w.Code)
+		}
+	}
+}
+
 func TestList_EmptyResult(t *testing.T) {
 	svc := &mockService{
 		listFn: func(ctx context.Context, filter *MedicationFilter) ([]Medication, error) {
@@ -1064,6 +1139,23 @@ func TestGetLogs_ServiceError(t *testing.T) {
 	}
 }
 
+func TestGetLogs_Hidden(t *testing.T) {
+	svc := &mockService{
+		getLogsFn: func(ctx context.Context, medicationID string) ([]MedicationLog, error) {
+			return nil, visibility.ErrHidden
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/medications/med-123/logs", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
 func TestGetLogs_VerifiesMedicationIDParam(t *testing.T) {
 	var capturedMedicationID string
 	svc := &mockService{
@@ -1409,3 +1501,118 @@ func TestGetLogs_MultipleResults(t *testing.T) {
 		t.Errorf("Expected 2 logs, got %d", len(result))
 	}
 }
+
+// =============================================================================
+// Correction Tests
+// =============================================================================
+
+func TestCorrectLog_Success(t *testing.T) {
+	var capturedUser, capturedMed, capturedLog string
+	svc := &mockService{
+		correctLogFn: func(ctx context.Context, userID, medicationID, logID string, req *CorrectLogRequest) (*MedicationLog, error) {
+			capturedUser, capturedMed, capturedLog = userID, medicationID, logID
+			now := time.Now()
+			return &MedicationLog{ID: logID, MedicationID: medicationID, Dosage: req.Dosage, CorrectedAt: &now}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"given_at":"2025-03-01T10:00:00Z","dosage":"2.5","reason":"entered 5 ml instead of 2.5 ml"}`
+	req := httptest.NewRequest("POST", "/medications/med-1/logs/log-1/corrections", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if capturedUser != "test-user-123" || capturedMed != "med-1" || capturedLog != "log-1" {
+		t.Errorf("CorrectLog() called with %s, %s, %s", capturedUser, capturedMed, capturedLog)
+	}
+}
+
+func TestCorrectLog_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+		want int
+	}{
+		{"missing reason", `{"given_at":"2025-03-01T10:00:00Z","dosage":"2.5"}`, nil, http.StatusBadRequest},
+		{"not found", `{"given_at":"2025-03-01T10:00:00Z","dosage":"2.5","reason":"typo"}`, ErrLogNotFound, http.StatusNotFound},
+		{"blank reason", `{"given_at":"2025-03-01T10:00:00Z","dosage":"2.5","reason":" "}`, corrections.ErrReasonRequired, http.StatusBadRequest},
+		{"unavailable", `{"given_at":"2025-03-01T10:00:00Z","dosage":"2.5","reason":"typo"}`, corrections.ErrUnavailable, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				correctLogFn: func(ctx context.Context, userID, medicationID, logID string, req *CorrectLogRequest) (*MedicationLog, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/medications/med-1/logs/log-1/corrections", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestLookupIngredients(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/medications/ingredients?name=Nurofen", http.NoBody))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"rxcui":"5640"`) {
+		t.Errorf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/medications/ingredients", http.NoBody))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status without a name = %d, want 400", w.Code)
+	}
+}
+
+func TestSetLogPrivate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, http.StatusOK},
+		{"not giver", ErrNotGiver, http.StatusForbidden},
+		{"not found", ErrLogNotFound, http.StatusNotFound},
+		{"other", errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				setLogPrivateFn: func(ctx context.Context, userID, medicationID, logID string, private bool) error {
+					if userID != "test-user-123" || medicationID != "med-123" || logID != "log-1" || !private {
+						t.Errorf("SetLogPrivate(%s, %s, %s, %v)", userID, medicationID, logID, private)
+					}
+					return tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/medications/med-123/logs/log-1/private", bytes.NewBufferString(`{"private":true}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
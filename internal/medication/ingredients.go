@@ -0,0 +1,152 @@
+package medication
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ninenine/babytrack/internal/warnings"
+)
+
+// Ingredient is an active ingredient as RxNorm names it
+type Ingredient struct {
+	RxCUI string `json:"rxcui"`
+	Name  string `json:"name"`
+}
+
+// ingredients is the bundled subset of RxNorm: the ingredients of the
+// medicines most often given to babies and young children
+var ingredients = map[string]Ingredient{
+	"acetaminophen":   {"161", "acetaminophen"},
+	"albuterol":       {"435", "albuterol"},
+	"amoxicillin":     {"723", "amoxicillin"},
+	"azithromycin":    {"18631", "azithromycin"},
+	"cefdinir":        {"25037", "cefdinir"},
+	"cephalexin":      {"2231", "cephalexin"},
+	"cetirizine":      {"20610", "cetirizine"},
+	"cholecalciferol": {"2418", "cholecalciferol"},
+	"clavulanate":     {"48203", "clavulanate"},
+	"dexamethasone":   {"3264", "dexamethasone"},
+	"diphenhydramine": {"3498", "diphenhydramine"},
+	"famotidine":      {"4278", "famotidine"},
+	"fexofenadine":    {"87636", "fexofenadine"},
+	"fluticasone":     {"41126", "fluticasone"},
+	"hydrocortisone":  {"5492", "hydrocortisone"},
+	"ibuprofen":       {"5640", "ibuprofen"},
+	"loratadine":      {"28889", "loratadine"},
+	"montelukast":     {"88249", "montelukast"},
+	"mupirocin":       {"42372", "mupirocin"},
+	"nystatin":        {"7597", "nystatin"},
+	"omeprazole":      {"7646", "omeprazole"},
+	"ondansetron":     {"26225", "ondansetron"},
+	"oseltamivir":     {"260101", "oseltamivir"},
+	"prednisolone":    {"8638", "prednisolone"},
+	"simethicone":     {"9796", "simethicone"},
+}
+
+// ingredientNames maps brand names, other countries' names and the
+// ingredients' own names, lowercased with punctuation as spaces, to the
+// ingredients they contain
+var ingredientNames = map[string][]string{
+	"paracetamol": {"acetaminophen"}, "tylenol": {"acetaminophen"}, "panadol": {"acetaminophen"},
+	"calpol": {"acetaminophen"}, "feverall": {"acetaminophen"}, "tempra": {"acetaminophen"},
+	"advil": {"ibuprofen"}, "motrin": {"ibuprofen"}, "nurofen": {"ibuprofen"},
+	"salbutamol": {"albuterol"}, "ventolin": {"albuterol"}, "proair": {"albuterol"},
+	"amoxil":    {"amoxicillin"},
+	"augmentin": {"amoxicillin", "clavulanate"}, "co amoxiclav": {"amoxicillin", "clavulanate"},
+	"amoxiclav": {"amoxicillin", "clavulanate"}, "clavulanic acid": {"clavulanate"},
+	"zithromax": {"azithromycin"}, "omnicef": {"cefdinir"}, "keflex": {"cephalexin"}, "cefalexin": {"cephalexin"},
+	"zyrtec": {"cetirizine"}, "claritin": {"loratadine"}, "clarityn": {"loratadine"},
+	"allegra": {"fexofenadine"}, "benadryl": {"diphenhydramine"},
+	"vitamin d": {"cholecalciferol"}, "vitamin d3": {"cholecalciferol"}, "colecalciferol": {"cholecalciferol"},
+	"decadron": {"dexamethasone"}, "pepcid": {"famotidine"}, "prilosec": {"omeprazole"}, "losec": {"omeprazole"},
+	"flovent": {"fluticasone"}, "flonase": {"fluticasone"}, "flixotide": {"fluticasone"},
+	"cortaid": {"hydrocortisone"}, "singulair": {"montelukast"}, "bactroban": {"mupirocin"},
+	"mycostatin": {"nystatin"}, "zofran": {"ondansetron"}, "tamiflu": {"oseltamivir"},
+	"orapred": {"prednisolone"}, "prelone": {"prednisolone"},
+	"mylicon": {"simethicone"}, "infacol": {"simethicone"}, "dimethicone": {"simethicone"},
+}
+
+// longestName is the most words in any name above
+const longestName = 2
+
+// NormalizeIngredients returns the active ingredients of a medication as
+// entered, such as "Children's Tylenol 160mg/5ml", sorted by name. Names
+// the bundled subset doesn't know give none.
+func NormalizeIngredients(name string) []Ingredient {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var found []string
+	for i := 0; i < len(words); {
+		matched := 1
+		for n := min(longestName, len(words)-i); n > 0; n-- {
+			phrase := strings.Join(words[i:i+n], " ")
+			if _, ok := ingredients[phrase]; ok {
+				found = append(found, phrase)
+			} else if names, ok := ingredientNames[phrase]; ok {
+				found = append(found, names...)
+			} else {
+				continue
+			}
+			matched = n
+			break
+		}
+		i += matched
+	}
+
+	slices.Sort(found)
+	found = slices.Compact(found)
+	result := make([]Ingredient, len(found))
+	for i, f := range found {
+		result[i] = ingredients[f]
+	}
+	return result
+}
+
+// ingredientNamesOf returns what is stored with a medication
+func ingredientNamesOf(name string) []string {
+	names := []string{}
+	for _, in := range NormalizeIngredients(name) {
+		names = append(names, in.Name)
+	}
+	return names
+}
+
+// normalize fills in the ingredients of medications saved before they were
+// stored
+func normalize(meds ...*Medication) {
+	for _, m := range meds {
+		if m != nil && m.ActiveIngredients == nil {
+			m.ActiveIngredients = ingredientNamesOf(m.Name)
+		}
+	}
+}
+
+// warnSharedIngredients warns when another of the child's active medications
+// has an ingredient in common with med, such as Tylenol and Calpol, so doses
+// of the two add up
+func (s *service) warnSharedIngredients(ctx context.Context, med *Medication) {
+	if len(med.ActiveIngredients) == 0 {
+		return
+	}
+	others, err := s.repo.List(ctx, &MedicationFilter{ChildID: med.ChildID, ActiveOnly: true})
+	if err != nil {
+		return
+	}
+	for i := range others {
+		other := &others[i]
+		if other.ID == med.ID {
+			continue
+		}
+		normalize(other)
+		for _, in := range med.ActiveIngredients {
+			if slices.Contains(other.ActiveIngredients, in) {
+				warnings.Addf(ctx, "%s and %s both contain %s", med.Name, other.Name, in)
+				break
+			}
+		}
+	}
+}
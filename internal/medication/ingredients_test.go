@@ -0,0 +1,88 @@
+package medication
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/warnings"
+)
+
+func TestNormalizeIngredients(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Children's Tylenol 160mg/5ml", []string{"acetaminophen"}},
+		{"Calpol Infant Suspension", []string{"acetaminophen"}},
+		{"ibuprofen", []string{"ibuprofen"}},
+		{"Co-amoxiclav 250/62", []string{"amoxicillin", "clavulanate"}},
+		{"Amoxicillin-clavulanate", []string{"amoxicillin", "clavulanate"}},
+		{"Vitamin D3 drops", []string{"cholecalciferol"}},
+		{"Gripe water", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, in := range NormalizeIngredients(tt.name) {
+			if in.RxCUI == "" {
+				t.Errorf("NormalizeIngredients(%q) gave %q without an RxCUI", tt.name, in.Name)
+			}
+			got = append(got, in.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("NormalizeIngredients(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestService_Create_SharedIngredientWarning(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	create := func(ctx context.Context, childID, name string) *Medication {
+		t.Helper()
+		med, err := svc.Create(ctx, &CreateMedicationRequest{
+			ChildID: childID, Name: name, Dosage: "5", Unit: "ml",
+			Frequency: "every_6_hours", StartDate: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+		return med
+	}
+
+	tylenol := create(context.Background(), "child-123", "Tylenol")
+	if !slices.Equal(tylenol.ActiveIngredients, []string{"acetaminophen"}) {
+		t.Errorf("Create() ActiveIngredients = %v, want [acetaminophen]", tylenol.ActiveIngredients)
+	}
+
+	ctx := warnings.NewContext(context.Background())
+	create(ctx, "child-123", "Calpol")
+	if got := warnings.From(ctx); len(got) != 1 || got[0] != "Calpol and Tylenol both contain acetaminophen" {
+		t.Errorf("warnings = %v, want one about acetaminophen", got)
+	}
+
+	// Another child's medicines and other ingredients don't count
+	for _, tt := range []struct{ childID, name string }{{"child-456", "Panadol"}, {"child-123", "Motrin"}} {
+		ctx := warnings.NewContext(context.Background())
+		create(ctx, tt.childID, tt.name)
+		if got := warnings.From(ctx); len(got) > 0 {
+			t.Errorf("%s: warnings = %v, want none", tt.name, got)
+		}
+	}
+}
+
+func TestService_Get_NormalizesOlderMedications(t *testing.T) {
+	repo := newMockRepository()
+	repo.medications["med-1"] = &Medication{ID: "med-1", Name: "Advil"}
+	svc := NewService(repo)
+
+	med, err := svc.Get(context.Background(), "user-123", "med-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !slices.Equal(med.ActiveIngredients, []string{"ibuprofen"}) {
+		t.Errorf("Get() ActiveIngredients = %v, want [ibuprofen]", med.ActiveIngredients)
+	}
+}
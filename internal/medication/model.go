@@ -1,20 +1,72 @@
 package medication
 
-import "time"
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/measure"
+)
 
 type Medication struct {
-	ID           string     `json:"id"`
-	ChildID      string     `json:"child_id"`
-	Name         string     `json:"name"`
-	Dosage       string     `json:"dosage"`
-	Unit         string     `json:"unit"`
-	Frequency    string     `json:"frequency"` // daily, twice_daily, as_needed, etc.
-	Instructions string     `json:"instructions,omitempty"`
-	StartDate    time.Time  `json:"start_date"`
-	EndDate      *time.Time `json:"end_date,omitempty"`
-	Active       bool       `json:"active"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	ID           string `json:"id"`
+	ChildID      string `json:"child_id"`
+	Name         string `json:"name"`
+	Dosage       string `json:"dosage"`
+	Unit         string `json:"unit"`
+	Frequency    string `json:"frequency"` // daily, twice_daily, as_needed, etc.
+	Instructions string `json:"instructions,omitempty"`
+	// ActiveIngredients are the RxNorm names of what the medication contains,
+	// from its name; empty when the name isn't recognised
+	ActiveIngredients []string   `json:"active_ingredients,omitempty"`
+	StartDate         time.Time  `json:"start_date"`
+	EndDate           *time.Time `json:"end_date,omitempty"`
+	Active            bool       `json:"active"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// Dose returns the prescribed dosage as a quantity
+func (m *Medication) Dose() (measure.Quantity, error) {
+	return m.DoseOf(m.Dosage)
+}
+
+// DoseOf reads a dose of this medication, such as a logged "250" or
+// "0.25 g", and converts it to the medication's unit
+func (m *Medication) DoseOf(dosage string) (measure.Quantity, error) {
+	unit := measure.ParseUnit(m.Unit)
+	q, err := measure.ParseQuantity(dosage, unit)
+	if err != nil {
+		return measure.Quantity{}, err
+	}
+	return q.In(unit)
+}
+
+// DoseGrace is how early a dose may come and still count as on time
+const DoseGrace = 30 * time.Minute
+
+// DoseInterval is the time between doses for a frequency. It reports false
+// for as_needed, which has no schedule; unknown frequencies are taken as
+// daily.
+func DoseInterval(frequency string) (time.Duration, bool) {
+	switch frequency {
+	case "once_daily":
+		return 24 * time.Hour, true
+	case "twice_daily":
+		return 12 * time.Hour, true
+	case "three_times_daily":
+		return 8 * time.Hour, true
+	case "four_times_daily":
+		return 6 * time.Hour, true
+	case "every_4_hours":
+		return 4 * time.Hour, true
+	case "every_6_hours":
+		return 6 * time.Hour, true
+	case "every_8_hours":
+		return 8 * time.Hour, true
+	case "as_needed":
+		return 0, false
+	default:
+		return 24 * time.Hour, true
+	}
 }
 
 type MedicationLog struct {
@@ -27,6 +79,17 @@ type MedicationLog struct {
 	Notes        string     `json:"notes,omitempty"`
 	CreatedAt    time.Time  `json:"created_at"`
 	SyncedAt     *time.Time `json:"synced_at,omitempty"`
+	// CorrectedAt is when the dose was last corrected. The values it
+	// replaced are kept with its corrections.
+	CorrectedAt *time.Time `json:"corrected_at,omitempty"`
+	// Private doses are only shown to the member who gave them, including
+	// in exports
+	Private bool `json:"private"`
+}
+
+// VisibleTo reports whether userID may see the dose
+func (l *MedicationLog) VisibleTo(userID string) bool {
+	return !l.Private || l.GivenBy == userID
 }
 
 type CreateMedicationRequest struct {
@@ -38,6 +101,9 @@ type CreateMedicationRequest struct {
 	Instructions string     `json:"instructions,omitempty"`
 	StartDate    time.Time  `json:"start_date" binding:"required"`
 	EndDate      *time.Time `json:"end_date,omitempty"`
+	// UserID is set by the server to the member adding the medication, who
+	// must be able to see the child's medications. It is ignored on update.
+	UserID string `json:"-"`
 }
 
 type LogMedicationRequest struct {
@@ -45,9 +111,26 @@ type LogMedicationRequest struct {
 	GivenAt      time.Time `json:"given_at" binding:"required"`
 	Dosage       string    `json:"dosage" binding:"required"`
 	Notes        string    `json:"notes,omitempty"`
+	// Private is only read when logging; SetLogPrivate changes it afterwards
+	Private bool `json:"private"`
+}
+
+type SetPrivateRequest struct {
+	Private bool `json:"private"`
+}
+
+// CorrectLogRequest replaces the details of a logged dose
+type CorrectLogRequest struct {
+	GivenAt time.Time `json:"given_at" binding:"required"`
+	Dosage  string    `json:"dosage" binding:"required"`
+	Notes   string    `json:"notes,omitempty"`
+	// Reason says what was wrong, e.g. "entered 5 ml instead of 2.5 ml"
+	Reason string `json:"reason" binding:"required"`
 }
 
 type MedicationFilter struct {
 	ChildID    string
 	ActiveOnly bool
+	// ViewerID leaves out medications hidden from that member when set
+	ViewerID string
 }
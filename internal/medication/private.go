@@ -0,0 +1,27 @@
+package medication
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetLogPrivate lets the member who gave a dose hide it from the other
+// members, or show it again. Other members' private doses are not found.
+func (s *service) SetLogPrivate(ctx context.Context, userID, medicationID, logID string, private bool) error {
+	log, err := s.getLog(ctx, userID, medicationID, logID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, userID, log.ChildID); err != nil {
+		return err
+	}
+	if log.GivenBy != userID {
+		return ErrNotGiver
+	}
+
+	log.Private = private
+	if err := s.repo.UpdateLog(ctx, log); err != nil {
+		return fmt.Errorf("failed to update medication log: %w", err)
+	}
+	return nil
+}
@@ -5,21 +5,32 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"iter"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
+
+	"github.com/lib/pq"
 )
 
 type Repository interface {
 	// Medications
 	GetByID(ctx context.Context, id string) (*Medication, error)
 	List(ctx context.Context, filter *MedicationFilter) ([]Medication, error)
+	// Stream yields what List returns, reading rows as they are consumed
+	Stream(ctx context.Context, filter *MedicationFilter) iter.Seq2[Medication, error]
 	Create(ctx context.Context, med *Medication) error
 	Update(ctx context.Context, med *Medication) error
 	Delete(ctx context.Context, id string) error
 
 	// Medication Logs
 	GetLogByID(ctx context.Context, id string) (*MedicationLog, error)
-	ListLogs(ctx context.Context, medicationID string) ([]MedicationLog, error)
+	// ListLogs and GetLastLog leave out other members' private doses when
+	// viewerID is set
+	ListLogs(ctx context.Context, medicationID, viewerID string) ([]MedicationLog, error)
 	CreateLog(ctx context.Context, log *MedicationLog) error
-	GetLastLog(ctx context.Context, medicationID string) (*MedicationLog, error)
+	UpdateLog(ctx context.Context, log *MedicationLog) error
+	GetLastLog(ctx context.Context, medicationID, viewerID string) (*MedicationLog, error)
 }
 
 type repository struct {
@@ -30,9 +41,14 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
+// HealthCheck checks the medication and dose log tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "medications", "medication_logs")
+}
+
 func (r *repository) GetByID(ctx context.Context, id string) (*Medication, error) {
 	query := `
-		SELECT id, child_id, name, dosage, unit, frequency, instructions,
+		SELECT id, child_id, name, dosage, unit, frequency, instructions, active_ingredients,
 		       start_date, end_date, active, created_at, updated_at
 		FROM medications
 		WHERE id = $1
@@ -40,11 +56,12 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Medication, error
 
 	var m Medication
 	var instructions sql.NullString
+	var ingredients pq.StringArray
 	var endDate sql.NullTime
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&m.ID, &m.ChildID, &m.Name, &m.Dosage, &m.Unit, &m.Frequency,
-		&instructions, &m.StartDate, &endDate, &m.Active, &m.CreatedAt, &m.UpdatedAt,
+		&instructions, &ingredients, &m.StartDate, &endDate, &m.Active, &m.CreatedAt, &m.UpdatedAt,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -57,6 +74,10 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Medication, error
 	if instructions.Valid {
 		m.Instructions = instructions.String
 	}
+	// NULL for medications saved before ingredients were
+	if ingredients != nil {
+		m.ActiveIngredients = ingredients
+	}
 	if endDate.Valid {
 		m.EndDate = &endDate.Time
 	}
@@ -64,9 +85,10 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Medication, error
 	return &m, nil
 }
 
-func (r *repository) List(ctx context.Context, filter *MedicationFilter) ([]Medication, error) {
+// filterQuery selects the medications matching filter, by name
+func filterQuery(filter *MedicationFilter) (string, []any) {
 	query := `
-		SELECT id, child_id, name, dosage, unit, frequency, instructions,
+		SELECT id, child_id, name, dosage, unit, frequency, instructions, active_ingredients,
 		       start_date, end_date, active, created_at, updated_at
 		FROM medications
 		WHERE 1=1
@@ -85,49 +107,64 @@ func (r *repository) List(ctx context.Context, filter *MedicationFilter) ([]Medi
 		args = append(args, true)
 	}
 
-	query += ` ORDER BY name ASC`
+	return query + ` ORDER BY name ASC`, args
+}
 
+func scanMedication(rows *sql.Rows) (Medication, error) {
+	var m Medication
+	var instructions sql.NullString
+	var ingredients pq.StringArray
+	var endDate sql.NullTime
+
+	if err := rows.Scan(
+		&m.ID, &m.ChildID, &m.Name, &m.Dosage, &m.Unit, &m.Frequency,
+		&instructions, &ingredients, &m.StartDate, &endDate, &m.Active, &m.CreatedAt, &m.UpdatedAt,
+	); err != nil {
+		return Medication{}, err
+	}
+
+	if instructions.Valid {
+		m.Instructions = instructions.String
+	}
+	if ingredients != nil {
+		m.ActiveIngredients = ingredients
+	}
+	if endDate.Valid {
+		m.EndDate = &endDate.Time
+	}
+	return m, nil
+}
+
+func (r *repository) List(ctx context.Context, filter *MedicationFilter) ([]Medication, error) {
+	query, args := filterQuery(filter)
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close() //nolint:errcheck // Best-effort close
 
-	var medications []Medication
+	medications := []Medication{}
 	for rows.Next() {
-		var m Medication
-		var instructions sql.NullString
-		var endDate sql.NullTime
-
-		if err := rows.Scan(
-			&m.ID, &m.ChildID, &m.Name, &m.Dosage, &m.Unit, &m.Frequency,
-			&instructions, &m.StartDate, &endDate, &m.Active, &m.CreatedAt, &m.UpdatedAt,
-		); err != nil {
+		m, err := scanMedication(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		if instructions.Valid {
-			m.Instructions = instructions.String
-		}
-		if endDate.Valid {
-			m.EndDate = &endDate.Time
-		}
-
 		medications = append(medications, m)
 	}
 
-	if medications == nil {
-		return []Medication{}, nil
-	}
-
 	return medications, rows.Err()
 }
 
+func (r *repository) Stream(ctx context.Context, filter *MedicationFilter) iter.Seq2[Medication, error] {
+	query, args := filterQuery(filter)
+	return db.Stream(ctx, r.db, scanMedication, query, args...)
+}
+
 func (r *repository) Create(ctx context.Context, med *Medication) error {
 	query := `
-		INSERT INTO medications (id, child_id, name, dosage, unit, frequency, instructions,
+		INSERT INTO medications (id, child_id, name, dosage, unit, frequency, instructions, active_ingredients,
 		                         start_date, end_date, active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 
 	var instructions *string
@@ -137,7 +174,7 @@ func (r *repository) Create(ctx context.Context, med *Medication) error {
 
 	_, err := r.db.ExecContext(ctx, query,
 		med.ID, med.ChildID, med.Name, med.Dosage, med.Unit, med.Frequency,
-		instructions, med.StartDate, med.EndDate, med.Active,
+		instructions, pq.Array(med.ActiveIngredients), med.StartDate, med.EndDate, med.Active,
 		med.CreatedAt, med.UpdatedAt,
 	)
 
@@ -147,8 +184,8 @@ func (r *repository) Create(ctx context.Context, med *Medication) error {
 func (r *repository) Update(ctx context.Context, med *Medication) error {
 	query := `
 		UPDATE medications
-		SET name = $2, dosage = $3, unit = $4, frequency = $5, instructions = $6,
-		    start_date = $7, end_date = $8, active = $9, updated_at = $10
+		SET name = $2, dosage = $3, unit = $4, frequency = $5, instructions = $6, active_ingredients = $7,
+		    start_date = $8, end_date = $9, active = $10, updated_at = $11
 		WHERE id = $1
 	`
 
@@ -159,7 +196,7 @@ func (r *repository) Update(ctx context.Context, med *Medication) error {
 
 	_, err := r.db.ExecContext(ctx, query,
 		med.ID, med.Name, med.Dosage, med.Unit, med.Frequency,
-		instructions, med.StartDate, med.EndDate, med.Active, med.UpdatedAt,
+		instructions, pq.Array(med.ActiveIngredients), med.StartDate, med.EndDate, med.Active, med.UpdatedAt,
 	)
 
 	return err
@@ -167,24 +204,24 @@ func (r *repository) Update(ctx context.Context, med *Medication) error {
 
 func (r *repository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM medications WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, id)
 	return err
 }
 
 func (r *repository) GetLogByID(ctx context.Context, id string) (*MedicationLog, error) {
 	query := `
-		SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at
+		SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at, corrected_at, private
 		FROM medication_logs
 		WHERE id = $1
 	`
 
 	var log MedicationLog
 	var notes sql.NullString
-	var syncedAt sql.NullTime
+	var syncedAt, correctedAt sql.NullTime
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&log.ID, &log.MedicationID, &log.ChildID, &log.GivenAt, &log.GivenBy,
-		&log.Dosage, &notes, &log.CreatedAt, &syncedAt,
+		&log.Dosage, &notes, &log.CreatedAt, &syncedAt, &correctedAt, &log.Private,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -200,20 +237,23 @@ func (r *repository) GetLogByID(ctx context.Context, id string) (*MedicationLog,
 	if syncedAt.Valid {
 		log.SyncedAt = &syncedAt.Time
 	}
+	if correctedAt.Valid {
+		log.CorrectedAt = &correctedAt.Time
+	}
 
 	return &log, nil
 }
 
-func (r *repository) ListLogs(ctx context.Context, medicationID string) ([]MedicationLog, error) {
+func (r *repository) ListLogs(ctx context.Context, medicationID, viewerID string) ([]MedicationLog, error) {
 	query := `
-		SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at
+		SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at, corrected_at, private
 		FROM medication_logs
-		WHERE medication_id = $1
+		WHERE medication_id = $1 AND ($2 = '' OR private = FALSE OR given_by = $2)
 		ORDER BY given_at DESC
 		LIMIT 100
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, medicationID)
+	rows, err := r.db.QueryContext(ctx, query, medicationID, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -223,11 +263,11 @@ func (r *repository) ListLogs(ctx context.Context, medicationID string) ([]Medic
 	for rows.Next() {
 		var log MedicationLog
 		var notes sql.NullString
-		var syncedAt sql.NullTime
+		var syncedAt, correctedAt sql.NullTime
 
 		if err := rows.Scan(
 			&log.ID, &log.MedicationID, &log.ChildID, &log.GivenAt, &log.GivenBy,
-			&log.Dosage, &notes, &log.CreatedAt, &syncedAt,
+			&log.Dosage, &notes, &log.CreatedAt, &syncedAt, &correctedAt, &log.Private,
 		); err != nil {
 			return nil, err
 		}
@@ -238,6 +278,9 @@ func (r *repository) ListLogs(ctx context.Context, medicationID string) ([]Medic
 		if syncedAt.Valid {
 			log.SyncedAt = &syncedAt.Time
 		}
+		if correctedAt.Valid {
+			log.CorrectedAt = &correctedAt.Time
+		}
 
 		logs = append(logs, log)
 	}
@@ -251,8 +294,8 @@ func (r *repository) ListLogs(ctx context.Context, medicationID string) ([]Medic
 
 func (r *repository) CreateLog(ctx context.Context, log *MedicationLog) error {
 	query := `
-		INSERT INTO medication_logs (id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO medication_logs (id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at, private)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	var notes *string
@@ -262,28 +305,44 @@ func (r *repository) CreateLog(ctx context.Context, log *MedicationLog) error {
 
 	_, err := r.db.ExecContext(ctx, query,
 		log.ID, log.MedicationID, log.ChildID, log.GivenAt, log.GivenBy,
-		log.Dosage, notes, log.CreatedAt, log.SyncedAt,
+		log.Dosage, notes, log.CreatedAt, log.SyncedAt, log.Private,
 	)
 
 	return err
 }
 
-func (r *repository) GetLastLog(ctx context.Context, medicationID string) (*MedicationLog, error) {
+func (r *repository) UpdateLog(ctx context.Context, log *MedicationLog) error {
 	query := `
-		SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at
+		UPDATE medication_logs
+		SET given_at = $2, dosage = $3, notes = $4, corrected_at = $5, private = $6
+		WHERE id = $1
+	`
+
+	var notes *string
+	if log.Notes != "" {
+		notes = &log.Notes
+	}
+
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, log.ID, log.GivenAt, log.Dosage, notes, log.CorrectedAt, log.Private)
+	return err
+}
+
+func (r *repository) GetLastLog(ctx context.Context, medicationID, viewerID string) (*MedicationLog, error) {
+	query := `
+		SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at, corrected_at, private
 		FROM medication_logs
-		WHERE medication_id = $1
+		WHERE medication_id = $1 AND ($2 = '' OR private = FALSE OR given_by = $2)
 		ORDER BY given_at DESC
 		LIMIT 1
 	`
 
 	var log MedicationLog
 	var notes sql.NullString
-	var syncedAt sql.NullTime
+	var syncedAt, correctedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, medicationID).Scan(
+	err := r.db.QueryRowContext(ctx, query, medicationID, viewerID).Scan(
 		&log.ID, &log.MedicationID, &log.ChildID, &log.GivenAt, &log.GivenBy,
-		&log.Dosage, &notes, &log.CreatedAt, &syncedAt,
+		&log.Dosage, &notes, &log.CreatedAt, &syncedAt, &correctedAt, &log.Private,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -299,6 +358,9 @@ func (r *repository) GetLastLog(ctx context.Context, medicationID string) (*Medi
 	if syncedAt.Valid {
 		log.SyncedAt = &syncedAt.Time
 	}
+	if correctedAt.Valid {
+		log.CorrectedAt = &correctedAt.Time
+	}
 
 	return &log, nil
 }
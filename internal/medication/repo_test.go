@@ -20,12 +20,12 @@ func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
 }
 
 var medicationColumns = []string{
-	"id", "child_id", "name", "dosage", "unit", "frequency", "instructions",
+	"id", "child_id", "name", "dosage", "unit", "frequency", "instructions", "active_ingredients",
 	"start_date", "end_date", "active", "created_at", "updated_at",
 }
 
 var medicationLogColumns = []string{
-	"id", "medication_id", "child_id", "given_at", "given_by", "dosage", "notes", "created_at", "synced_at",
+	"id", "medication_id", "child_id", "given_at", "given_by", "dosage", "notes", "created_at", "synced_at", "corrected_at", "private",
 }
 
 // =============================================================================
@@ -40,7 +40,7 @@ func TestRepository_GetByID(t *testing.T) {
 	now := time.Now()
 	endDate := now.Add(30 * 24 * time.Hour)
 	rows := sqlmock.NewRows(medicationColumns).
-		AddRow("med-123", "child-456", "Ibuprofen", "200mg", "ml", "daily", "Take with food", now, endDate, true, now, now)
+		AddRow("med-123", "child-456", "Ibuprofen", "200mg", "ml", "daily", "Take with food", "{ibuprofen}", now, endDate, true, now, now)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dosage, unit, frequency, instructions").
 		WithArgs("med-123").
@@ -67,6 +67,10 @@ func TestRepository_GetByID(t *testing.T) {
 		t.Errorf("GetByID() Instructions = %v, want Take with food", med.Instructions)
 	}
 
+	if len(med.ActiveIngredients) != 1 || med.ActiveIngredients[0] != "ibuprofen" {
+		t.Errorf("GetByID() ActiveIngredients = %v, want [ibuprofen]", med.ActiveIngredients)
+	}
+
 	if med.EndDate == nil {
 		t.Error("GetByID() EndDate should not be nil")
 	}
@@ -125,7 +129,7 @@ func TestRepository_GetByID_NullOptionalFields(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(medicationColumns).
-		AddRow("med-123", "child-456", "Ibuprofen", "200mg", "ml", "daily", nil, now, nil, true, now, now)
+		AddRow("med-123", "child-456", "Ibuprofen", "200mg", "ml", "daily", nil, nil, now, nil, true, now, now)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dosage, unit, frequency, instructions").
 		WithArgs("med-123").
@@ -161,8 +165,8 @@ func TestRepository_List(t *testing.T) {
 	now := time.Now()
 	endDate := now.Add(30 * 24 * time.Hour)
 	rows := sqlmock.NewRows(medicationColumns).
-		AddRow("med-1", "child-456", "Ibuprofen", "200mg", "ml", "daily", "Take with food", now, endDate, true, now, now).
-		AddRow("med-2", "child-456", "Acetaminophen", "500mg", "tablet", "as_needed", nil, now, nil, true, now, now)
+		AddRow("med-1", "child-456", "Ibuprofen", "200mg", "ml", "daily", "Take with food", nil, now, endDate, true, now, now).
+		AddRow("med-2", "child-456", "Acetaminophen", "500mg", "tablet", "as_needed", nil, nil, now, nil, true, now, now)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dosage, unit, frequency, instructions").
 		WithArgs("child-456").
@@ -190,7 +194,7 @@ func TestRepository_List_ActiveOnly(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(medicationColumns).
-		AddRow("med-1", "child-456", "Ibuprofen", "200mg", "ml", "daily", nil, now, nil, true, now, now)
+		AddRow("med-1", "child-456", "Ibuprofen", "200mg", "ml", "daily", nil, nil, now, nil, true, now, now)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dosage, unit, frequency, instructions").
 		WithArgs("child-456", true).
@@ -314,7 +318,7 @@ func TestRepository_Create(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO medications").
 		WithArgs(med.ID, med.ChildID, med.Name, med.Dosage, med.Unit, med.Frequency,
-			&med.Instructions, med.StartDate, med.EndDate, med.Active, med.CreatedAt, med.UpdatedAt).
+			&med.Instructions, nil, med.StartDate, med.EndDate, med.Active, med.CreatedAt, med.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), med)
@@ -348,7 +352,7 @@ func TestRepository_Create_NoOptionalFields(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO medications").
 		WithArgs(med.ID, med.ChildID, med.Name, med.Dosage, med.Unit, med.Frequency,
-			nil, med.StartDate, nil, med.Active, med.CreatedAt, med.UpdatedAt).
+			nil, nil, med.StartDate, nil, med.Active, med.CreatedAt, med.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), med)
@@ -381,7 +385,7 @@ func TestRepository_Create_Error(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO medications").
 		WithArgs(med.ID, med.ChildID, med.Name, med.Dosage, med.Unit, med.Frequency,
-			nil, med.StartDate, nil, med.Active, med.CreatedAt, med.UpdatedAt).
+			nil, nil, med.StartDate, nil, med.Active, med.CreatedAt, med.UpdatedAt).
 		WillReturnError(errors.New("duplicate key"))
 
 	err := repo.Create(context.Background(), med)
@@ -420,7 +424,7 @@ func TestRepository_Update(t *testing.T) {
 
 	mock.ExpectExec("UPDATE medications SET name").
 		WithArgs(med.ID, med.Name, med.Dosage, med.Unit, med.Frequency,
-			&med.Instructions, med.StartDate, med.EndDate, med.Active, med.UpdatedAt).
+			&med.Instructions, nil, med.StartDate, med.EndDate, med.Active, med.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), med)
@@ -452,7 +456,7 @@ func TestRepository_Update_Deactivate(t *testing.T) {
 
 	mock.ExpectExec("UPDATE medications SET name").
 		WithArgs(med.ID, med.Name, med.Dosage, med.Unit, med.Frequency,
-			nil, med.StartDate, nil, med.Active, med.UpdatedAt).
+			nil, nil, med.StartDate, nil, med.Active, med.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), med)
@@ -483,7 +487,7 @@ func TestRepository_Update_Error(t *testing.T) {
 
 	mock.ExpectExec("UPDATE medications SET name").
 		WithArgs(med.ID, med.Name, med.Dosage, med.Unit, med.Frequency,
-			nil, med.StartDate, nil, med.Active, med.UpdatedAt).
+			nil, nil, med.StartDate, nil, med.Active, med.UpdatedAt).
 		WillReturnError(errors.New("database error"))
 
 	err := repo.Update(context.Background(), med)
@@ -550,7 +554,7 @@ func TestRepository_GetLogByID(t *testing.T) {
 	now := time.Now()
 	syncedAt := now.Add(time.Hour)
 	rows := sqlmock.NewRows(medicationLogColumns).
-		AddRow("log-123", "med-456", "child-789", now, "user-abc", "200mg", "Patient felt better", now, syncedAt)
+		AddRow("log-123", "med-456", "child-789", now, "user-abc", "200mg", "Patient felt better", now, syncedAt, nil, false)
 
 	mock.ExpectQuery("SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at").
 		WithArgs("log-123").
@@ -635,7 +639,7 @@ func TestRepository_GetLogByID_NullOptionalFields(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(medicationLogColumns).
-		AddRow("log-123", "med-456", "child-789", now, "user-abc", "200mg", nil, now, nil)
+		AddRow("log-123", "med-456", "child-789", now, "user-abc", "200mg", nil, now, nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at").
 		WithArgs("log-123").
@@ -671,14 +675,14 @@ func TestRepository_ListLogs(t *testing.T) {
 	now := time.Now()
 	syncedAt := now.Add(time.Hour)
 	rows := sqlmock.NewRows(medicationLogColumns).
-		AddRow("log-1", "med-456", "child-789", now, "user-abc", "200mg", "Note 1", now, syncedAt).
-		AddRow("log-2", "med-456", "child-789", now.Add(-time.Hour), "user-def", "200mg", nil, now, nil)
+		AddRow("log-1", "med-456", "child-789", now, "user-abc", "200mg", "Note 1", now, syncedAt, nil, false).
+		AddRow("log-2", "med-456", "child-789", now.Add(-time.Hour), "user-def", "200mg", nil, now, nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at").
-		WithArgs("med-456").
+		WithArgs("med-456", "").
 		WillReturnRows(rows)
 
-	logs, err := repo.ListLogs(context.Background(), "med-456")
+	logs, err := repo.ListLogs(context.Background(), "med-456", "")
 	if err != nil {
 		t.Fatalf("ListLogs() error = %v", err)
 	}
@@ -700,6 +704,32 @@ func TestRepository_ListLogs(t *testing.T) {
 	}
 }
 
+func TestRepository_ListLogs_Private(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(medicationLogColumns).
+		AddRow("log-1", "med-456", "child-789", now, "user-abc", "200mg", nil, now, nil, nil, true)
+
+	mock.ExpectQuery("WHERE medication_id = \\$1 AND \\(\\$2 = '' OR private = FALSE OR given_by = \\$2\\)").
+		WithArgs("med-456", "user-abc").
+		WillReturnRows(rows)
+
+	logs, err := repo.ListLogs(context.Background(), "med-456", "user-abc")
+	if err != nil {
+		t.Fatalf("ListLogs() error = %v", err)
+	}
+	if len(logs) != 1 || !logs[0].Private {
+		t.Errorf("ListLogs() = %+v, want the giver's private dose", logs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestRepository_ListLogs_Empty(t *testing.T) {
 	db, mock := newMockDB(t)
 	defer db.Close()
@@ -708,10 +738,10 @@ func TestRepository_ListLogs_Empty(t *testing.T) {
 	rows := sqlmock.NewRows(medicationLogColumns)
 
 	mock.ExpectQuery("SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at").
-		WithArgs("med-456").
+		WithArgs("med-456", "").
 		WillReturnRows(rows)
 
-	logs, err := repo.ListLogs(context.Background(), "med-456")
+	logs, err := repo.ListLogs(context.Background(), "med-456", "")
 	if err != nil {
 		t.Fatalf("ListLogs() error = %v", err)
 	}
@@ -735,10 +765,10 @@ func TestRepository_ListLogs_Error(t *testing.T) {
 	repo := NewRepository(db)
 
 	mock.ExpectQuery("SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at").
-		WithArgs("med-456").
+		WithArgs("med-456", "").
 		WillReturnError(errors.New("database error"))
 
-	_, err := repo.ListLogs(context.Background(), "med-456")
+	_, err := repo.ListLogs(context.Background(), "med-456", "")
 	if err == nil {
 		t.Error("ListLogs() should return error on database failure")
 	}
@@ -758,10 +788,10 @@ func TestRepository_ListLogs_ScanError(t *testing.T) {
 		AddRow("log-1", "med-456")
 
 	mock.ExpectQuery("SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at").
-		WithArgs("med-456").
+		WithArgs("med-456", "").
 		WillReturnRows(rows)
 
-	_, err := repo.ListLogs(context.Background(), "med-456")
+	_, err := repo.ListLogs(context.Background(), "med-456", "")
 	if err == nil {
 		t.Error("ListLogs() should return error on scan failure")
 	}
@@ -775,6 +805,32 @@ func TestRepository_ListLogs_ScanError(t *testing.T) {
 // CreateLog Tests (LogMedication)
 // =============================================================================
 
+func TestRepository_UpdateLog(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	log := &MedicationLog{
+		ID:          "log-123",
+		GivenAt:     now.Add(-time.Hour),
+		Dosage:      "2.5 ml",
+		CorrectedAt: &now,
+	}
+
+	mock.ExpectExec("UPDATE medication_logs SET given_at").
+		WithArgs(log.ID, log.GivenAt, log.Dosage, nil, log.CorrectedAt, log.Private).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdateLog(context.Background(), log); err != nil {
+		t.Fatalf("UpdateLog() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestRepository_CreateLog(t *testing.T) {
 	db, mock := newMockDB(t)
 	defer db.Close()
@@ -796,7 +852,7 @@ func TestRepository_CreateLog(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO medication_logs").
 		WithArgs(log.ID, log.MedicationID, log.ChildID, log.GivenAt, log.GivenBy,
-			log.Dosage, &log.Notes, log.CreatedAt, log.SyncedAt).
+			log.Dosage, &log.Notes, log.CreatedAt, log.SyncedAt, log.Private).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.CreateLog(context.Background(), log)
@@ -827,7 +883,7 @@ func TestRepository_CreateLog_NoOptionalFields(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO medication_logs").
 		WithArgs(log.ID, log.MedicationID, log.ChildID, log.GivenAt, log.GivenBy,
-			log.Dosage, nil, log.CreatedAt, nil).
+			log.Dosage, nil, log.CreatedAt, nil, log.Private).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.CreateLog(context.Background(), log)
@@ -858,7 +914,7 @@ func TestRepository_CreateLog_Error(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO medication_logs").
 		WithArgs(log.ID, log.MedicationID, log.ChildID, log.GivenAt, log.GivenBy,
-			log.Dosage, nil, log.CreatedAt, nil).
+			log.Dosage, nil, log.CreatedAt, nil, log.Private).
 		WillReturnError(errors.New("duplicate key"))
 
 	err := repo.CreateLog(context.Background(), log)
@@ -883,13 +939,13 @@ func TestRepository_GetLastLog(t *testing.T) {
 	now := time.Now()
 	syncedAt := now.Add(time.Hour)
 	rows := sqlmock.NewRows(medicationLogColumns).
-		AddRow("log-123", "med-456", "child-789", now, "user-abc", "200mg", "Latest dose", now, syncedAt)
+		AddRow("log-123", "med-456", "child-789", now, "user-abc", "200mg", "Latest dose", now, syncedAt, nil, false)
 
 	mock.ExpectQuery("SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at").
-		WithArgs("med-456").
+		WithArgs("med-456", "").
 		WillReturnRows(rows)
 
-	log, err := repo.GetLastLog(context.Background(), "med-456")
+	log, err := repo.GetLastLog(context.Background(), "med-456", "")
 	if err != nil {
 		t.Fatalf("GetLastLog() error = %v", err)
 	}
@@ -917,10 +973,10 @@ func TestRepository_GetLastLog_NotFound(t *testing.T) {
 	repo := NewRepository(db)
 
 	mock.ExpectQuery("SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at").
-		WithArgs("med-no-logs").
+		WithArgs("med-no-logs", "").
 		WillReturnError(sql.ErrNoRows)
 
-	log, err := repo.GetLastLog(context.Background(), "med-no-logs")
+	log, err := repo.GetLastLog(context.Background(), "med-no-logs", "")
 	if err != nil {
 		t.Fatalf("GetLastLog() error = %v", err)
 	}
@@ -940,10 +996,10 @@ func TestRepository_GetLastLog_Error(t *testing.T) {
 	repo := NewRepository(db)
 
 	mock.ExpectQuery("SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at").
-		WithArgs("med-456").
+		WithArgs("med-456", "").
 		WillReturnError(errors.New("database error"))
 
-	_, err := repo.GetLastLog(context.Background(), "med-456")
+	_, err := repo.GetLastLog(context.Background(), "med-456", "")
 	if err == nil {
 		t.Error("GetLastLog() should return error on database failure")
 	}
@@ -960,13 +1016,13 @@ func TestRepository_GetLastLog_NullOptionalFields(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(medicationLogColumns).
-		AddRow("log-123", "med-456", "child-789", now, "user-abc", "200mg", nil, now, nil)
+		AddRow("log-123", "med-456", "child-789", now, "user-abc", "200mg", nil, now, nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, medication_id, child_id, given_at, given_by, dosage, notes, created_at, synced_at").
-		WithArgs("med-456").
+		WithArgs("med-456", "").
 		WillReturnRows(rows)
 
-	log, err := repo.GetLastLog(context.Background(), "med-456")
+	log, err := repo.GetLastLog(context.Background(), "med-456", "")
 	if err != nil {
 		t.Fatalf("GetLastLog() error = %v", err)
 	}
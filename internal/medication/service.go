@@ -2,52 +2,113 @@ package medication
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"iter"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/measure"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+	"github.com/ninenine/babytrack/internal/warnings"
 )
 
 type Service interface {
 	// Medications
 	Create(ctx context.Context, req *CreateMedicationRequest) (*Medication, error)
-	Get(ctx context.Context, id string) (*Medication, error)
+	Get(ctx context.Context, userID, id string) (*Medication, error)
 	List(ctx context.Context, filter *MedicationFilter) ([]Medication, error)
-	Update(ctx context.Context, id string, req *CreateMedicationRequest) (*Medication, error)
-	Delete(ctx context.Context, id string) error
-	Deactivate(ctx context.Context, id string) error
+	// Stream yields what List returns without holding it all, for exports
+	Stream(ctx context.Context, filter *MedicationFilter) iter.Seq2[Medication, error]
+	ListSince(ctx context.Context, filter *MedicationFilter, since time.Time) (*delta.Changes[Medication], error)
+	Update(ctx context.Context, userID, id string, req *CreateMedicationRequest) (*Medication, error)
+	Delete(ctx context.Context, userID, id string) error
+	Deactivate(ctx context.Context, userID, id string) error
 
 	// Medication Logs
 	LogMedication(ctx context.Context, userID string, req *LogMedicationRequest) (*MedicationLog, error)
-	GetLogs(ctx context.Context, medicationID string) ([]MedicationLog, error)
-	GetLastLog(ctx context.Context, medicationID string) (*MedicationLog, error)
+	// GetLogs and GetLastLog check viewerID may see the medication's doses
+	// when it is set
+	GetLogs(ctx context.Context, medicationID, viewerID string) ([]MedicationLog, error)
+	GetLastLog(ctx context.Context, medicationID, viewerID string) (*MedicationLog, error)
+	// CorrectLog changes a logged dose, keeping the values it replaces and
+	// the reason given
+	CorrectLog(ctx context.Context, userID, medicationID, logID string, req *CorrectLogRequest) (*MedicationLog, error)
+	// ListLogCorrections returns a dose's corrections, oldest first
+	ListLogCorrections(ctx context.Context, userID, medicationID, logID string) ([]corrections.Correction, error)
+	// SetLogPrivate hides a dose from the other members, or shows it again.
+	// Only the member who gave the dose may change it.
+	SetLogPrivate(ctx context.Context, userID, medicationID, logID string, private bool) error
 }
 
+var (
+	ErrInvalidDosage = errors.New("invalid dosage")
+	ErrLogNotFound   = errors.New("medication log not found")
+	ErrNotGiver      = errors.New("only the member who gave a dose can change who sees it")
+)
+
 type service struct {
-	repo Repository
+	repo        Repository
+	tx          db.TxManager
+	tombstones  delta.Tombstones
+	corrections corrections.Store
+	visibility  visibility.Service
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+type Option func(*service)
+
+// WithTxManager deletes a medication along with its tombstone, and corrects
+// a dose along with the record of its original, atomically. The repository
+// must be built on the same database.
+func WithTxManager(tx db.TxManager) Option {
+	return func(s *service) {
+		s.tx = tx
+	}
+}
+
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{repo: repo, tx: db.NoTx}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether medications and their doses can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
 }
 
 func (s *service) Create(ctx context.Context, req *CreateMedicationRequest) (*Medication, error) {
+	if err := s.authorize(ctx, req.UserID, req.ChildID); err != nil {
+		return nil, err
+	}
 	now := time.Now()
 
 	med := &Medication{
-		ID:           generateID(),
-		ChildID:      req.ChildID,
-		Name:         req.Name,
-		Dosage:       req.Dosage,
-		Unit:         req.Unit,
-		Frequency:    req.Frequency,
-		Instructions: req.Instructions,
-		StartDate:    req.StartDate,
-		EndDate:      req.EndDate,
-		Active:       true,
-		CreatedAt:    now,
-		UpdatedAt:    now,
+		ID:                ids.New(),
+		ChildID:           req.ChildID,
+		Name:              req.Name,
+		Dosage:            req.Dosage,
+		Unit:              req.Unit,
+		Frequency:         req.Frequency,
+		Instructions:      req.Instructions,
+		ActiveIngredients: ingredientNamesOf(req.Name),
+		StartDate:         req.StartDate,
+		EndDate:           req.EndDate,
+		Active:            true,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if _, err := med.Dose(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDosage, err)
 	}
+	s.warnSharedIngredients(ctx, med)
 
 	if err := s.repo.Create(ctx, med); err != nil {
 		return nil, fmt.Errorf("failed to create medication: %w", err)
@@ -56,15 +117,55 @@ func (s *service) Create(ctx context.Context, req *CreateMedicationRequest) (*Me
 	return med, nil
 }
 
-func (s *service) Get(ctx context.Context, id string) (*Medication, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *service) Get(ctx context.Context, userID, id string) (*Medication, error) {
+	med, err := s.repo.GetByID(ctx, id)
+	if err != nil || med == nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, userID, med.ChildID); err != nil {
+		return nil, err
+	}
+	normalize(med)
+	return med, nil
 }
 
 func (s *service) List(ctx context.Context, filter *MedicationFilter) ([]Medication, error) {
-	return s.repo.List(ctx, filter)
+	viewer, err := s.viewer(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	meds, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if meds, err = visibility.Filter(ctx, viewer, meds, childOf); err != nil {
+		return nil, err
+	}
+	for i := range meds {
+		normalize(&meds[i])
+	}
+	return meds, nil
 }
 
-func (s *service) Update(ctx context.Context, id string, req *CreateMedicationRequest) (*Medication, error) {
+func (s *service) Stream(ctx context.Context, filter *MedicationFilter) iter.Seq2[Medication, error] {
+	return func(yield func(Medication, error) bool) {
+		viewer, err := s.viewer(ctx, filter)
+		if err != nil {
+			yield(Medication{}, err)
+			return
+		}
+		for m, err := range visibility.FilterSeq(ctx, viewer, s.repo.Stream(ctx, filter), childOf) {
+			if err == nil {
+				normalize(&m)
+			}
+			if !yield(m, err) {
+				return
+			}
+		}
+	}
+}
+
+func (s *service) Update(ctx context.Context, userID, id string, req *CreateMedicationRequest) (*Medication, error) {
 	med, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -72,8 +173,12 @@ func (s *service) Update(ctx context.Context, id string, req *CreateMedicationRe
 	if med == nil {
 		return nil, fmt.Errorf("medication not found")
 	}
+	if err := s.authorize(ctx, userID, med.ChildID); err != nil {
+		return nil, err
+	}
 
 	med.Name = req.Name
+	med.ActiveIngredients = ingredientNamesOf(req.Name)
 	med.Dosage = req.Dosage
 	med.Unit = req.Unit
 	med.Frequency = req.Frequency
@@ -81,6 +186,12 @@ func (s *service) Update(ctx context.Context, id string, req *CreateMedicationRe
 	med.StartDate = req.StartDate
 	med.EndDate = req.EndDate
 	med.UpdatedAt = time.Now()
+	if _, err := med.Dose(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDosage, err)
+	}
+	if med.Active {
+		s.warnSharedIngredients(ctx, med)
+	}
 
 	if err := s.repo.Update(ctx, med); err != nil {
 		return nil, fmt.Errorf("failed to update medication: %w", err)
@@ -89,11 +200,31 @@ func (s *service) Update(ctx context.Context, id string, req *CreateMedicationRe
 	return med, nil
 }
 
-func (s *service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	med, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if med != nil {
+		if err := s.authorize(ctx, userID, med.ChildID); err != nil {
+			return err
+		}
+	}
+	if s.tombstones == nil {
+		return s.repo.Delete(ctx, id)
+	}
+	if med == nil {
+		return nil
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.tombstones.Record(ctx, delta.EntityMedication, id, med.ChildID, time.Now())
+	})
 }
 
-func (s *service) Deactivate(ctx context.Context, id string) error {
+func (s *service) Deactivate(ctx context.Context, userID, id string) error {
 	med, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
@@ -101,6 +232,9 @@ func (s *service) Deactivate(ctx context.Context, id string) error {
 	if med == nil {
 		return fmt.Errorf("medication not found")
 	}
+	if err := s.authorize(ctx, userID, med.ChildID); err != nil {
+		return err
+	}
 
 	med.Active = false
 	now := time.Now()
@@ -123,11 +257,25 @@ func (s *service) LogMedication(ctx context.Context, userID string, req *LogMedi
 	if med == nil {
 		return nil, fmt.Errorf("medication not found")
 	}
+	if err := s.authorize(ctx, userID, med.ChildID); err != nil {
+		return nil, err
+	}
+
+	if _, err := med.DoseOf(req.Dosage); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDosage, err)
+	}
 
 	now := time.Now()
 
+	occurrence.Adjust(ctx, "given_at", &req.GivenAt, now)
+	if err := occurrence.Validate(req.GivenAt, now); err != nil {
+		return nil, err
+	}
+
+	s.warnEarlyDose(ctx, med, req.GivenAt)
+
 	log := &MedicationLog{
-		ID:           generateID(),
+		ID:           ids.New(),
 		MedicationID: req.MedicationID,
 		ChildID:      med.ChildID,
 		GivenAt:      req.GivenAt,
@@ -136,6 +284,7 @@ func (s *service) LogMedication(ctx context.Context, userID string, req *LogMedi
 		Notes:        req.Notes,
 		CreatedAt:    now,
 		SyncedAt:     &now,
+		Private:      req.Private && userID != "",
 	}
 
 	if err := s.repo.CreateLog(ctx, log); err != nil {
@@ -145,16 +294,46 @@ func (s *service) LogMedication(ctx context.Context, userID string, req *LogMedi
 	return log, nil
 }
 
-func (s *service) GetLogs(ctx context.Context, medicationID string) ([]MedicationLog, error) {
-	return s.repo.ListLogs(ctx, medicationID)
+// warnEarlyDose warns when a dose comes before the previous one's interval
+// is up, which may mean a double dose. The dose is still logged.
+func (s *service) warnEarlyDose(ctx context.Context, med *Medication, givenAt time.Time) {
+	interval, scheduled := DoseInterval(med.Frequency)
+	if !scheduled {
+		return
+	}
+	last, err := s.repo.GetLastLog(ctx, med.ID, "")
+	if err != nil || last == nil || !givenAt.After(last.GivenAt) {
+		return
+	}
+	if since := givenAt.Sub(last.GivenAt); since < interval-DoseGrace {
+		warnings.Addf(ctx, "%s was last given %s before this dose; it is due every %s",
+			med.Name, measure.Duration(since), measure.Duration(interval))
+	}
+}
+
+func (s *service) GetLogs(ctx context.Context, medicationID, viewerID string) ([]MedicationLog, error) {
+	if err := s.authorizeViewer(ctx, medicationID, viewerID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListLogs(ctx, medicationID, viewerID)
 }
 
-func (s *service) GetLastLog(ctx context.Context, medicationID string) (*MedicationLog, error) {
-	return s.repo.GetLastLog(ctx, medicationID)
+func (s *service) GetLastLog(ctx context.Context, medicationID, viewerID string) (*MedicationLog, error) {
+	if err := s.authorizeViewer(ctx, medicationID, viewerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLastLog(ctx, medicationID, viewerID)
 }
 
-func generateID() string {
-	b := make([]byte, 16)
-	rand.Read(b) //nolint:errcheck // crypto/rand.Read rarely fails
-	return hex.EncodeToString(b)
+// authorizeViewer checks viewerID may see the medication's doses. A
+// medication that does not exist has none to hide.
+func (s *service) authorizeViewer(ctx context.Context, medicationID, viewerID string) error {
+	if viewerID == "" || s.visibility == nil {
+		return nil
+	}
+	med, err := s.repo.GetByID(ctx, medicationID)
+	if err != nil || med == nil {
+		return err
+	}
+	return s.authorize(ctx, viewerID, med.ChildID)
 }
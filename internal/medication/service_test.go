@@ -2,9 +2,17 @@ package medication
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"iter"
 	"testing"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/visibility"
+	"github.com/ninenine/babytrack/internal/warnings"
 )
 
 // mockRepository is a test double for Repository
@@ -46,6 +54,17 @@ func (m *mockRepository) List(ctx context.Context, filter *MedicationFilter) ([]
 	return result, nil
 }
 
+func (m *mockRepository) Stream(ctx context.Context, filter *MedicationFilter) iter.Seq2[Medication, error] {
+	meds, _ := m.List(ctx, filter)
+	return func(yield func(Medication, error) bool) {
+		for _, m := range meds {
+			if !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (m *mockRepository) Create(ctx context.Context, med *Medication) error {
 	if m.createErr != nil {
 		return m.createErr
@@ -78,22 +97,27 @@ func (m *mockRepository) CreateLog(ctx context.Context, log *MedicationLog) erro
 	return nil
 }
 
-func (m *mockRepository) ListLogs(ctx context.Context, medicationID string) ([]MedicationLog, error) {
+func (m *mockRepository) ListLogs(ctx context.Context, medicationID, viewerID string) ([]MedicationLog, error) {
 	logs := m.logs[medicationID]
 	var result []MedicationLog
 	for _, log := range logs {
-		result = append(result, *log)
+		if viewerID == "" || log.VisibleTo(viewerID) {
+			result = append(result, *log)
+		}
 	}
 	return result, nil
 }
 
-func (m *mockRepository) GetLastLog(ctx context.Context, medicationID string) (*MedicationLog, error) {
+func (m *mockRepository) GetLastLog(ctx context.Context, medicationID, viewerID string) (*MedicationLog, error) {
 	logs := m.logs[medicationID]
 	if len(logs) == 0 {
 		return nil, nil
 	}
 	var latest *MedicationLog
 	for _, log := range logs {
+		if viewerID != "" && !log.VisibleTo(viewerID) {
+			continue
+		}
 		if latest == nil || log.GivenAt.After(latest.GivenAt) {
 			latest = log
 		}
@@ -112,6 +136,15 @@ func (m *mockRepository) GetLogByID(ctx context.Context, id string) (*Medication
 	return nil, nil
 }
 
+func (m *mockRepository) UpdateLog(ctx context.Context, log *MedicationLog) error {
+	for i, l := range m.logs[log.MedicationID] {
+		if l.ID == log.ID {
+			m.logs[log.MedicationID][i] = log
+		}
+	}
+	return nil
+}
+
 func TestService_Create(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -190,7 +223,7 @@ func TestService_Get(t *testing.T) {
 	}
 	created, _ := svc.Create(context.Background(), req)
 
-	med, err := svc.Get(context.Background(), created.ID)
+	med, err := svc.Get(context.Background(), "user-123", created.ID)
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -208,7 +241,7 @@ func TestService_Get_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	med, err := svc.Get(context.Background(), "non-existent")
+	med, err := svc.Get(context.Background(), "user-123", "non-existent")
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -282,7 +315,7 @@ func TestService_List_ActiveOnly(t *testing.T) {
 		StartDate: time.Now(),
 	}
 	inactive, _ := svc.Create(context.Background(), inactiveReq)
-	svc.Deactivate(context.Background(), inactive.ID)
+	svc.Deactivate(context.Background(), "user-123", inactive.ID)
 
 	filter := &MedicationFilter{ChildID: "child-123", ActiveOnly: true}
 	meds, err := svc.List(context.Background(), filter)
@@ -319,7 +352,7 @@ func TestService_Update(t *testing.T) {
 		StartDate:    created.StartDate,
 	}
 
-	updated, err := svc.Update(context.Background(), created.ID, updateReq)
+	updated, err := svc.Update(context.Background(), "user-123", created.ID, updateReq)
 	if err != nil {
 		t.Fatalf("Update() error = %v", err)
 	}
@@ -350,7 +383,7 @@ func TestService_Update_NotFound(t *testing.T) {
 		StartDate: time.Now(),
 	}
 
-	_, err := svc.Update(context.Background(), "non-existent", req)
+	_, err := svc.Update(context.Background(), "user-123", "non-existent", req)
 	if err == nil {
 		t.Error("Update() should return error for non-existent medication")
 	}
@@ -370,17 +403,83 @@ func TestService_Delete(t *testing.T) {
 	}
 	created, _ := svc.Create(context.Background(), req)
 
-	err := svc.Delete(context.Background(), created.ID)
+	err := svc.Delete(context.Background(), "user-123", created.ID)
 	if err != nil {
 		t.Fatalf("Delete() error = %v", err)
 	}
 
-	med, _ := svc.Get(context.Background(), created.ID)
+	med, _ := svc.Get(context.Background(), "user-123", created.ID)
 	if med != nil {
 		t.Error("Delete() should remove the medication")
 	}
 }
 
+type mockTombstones struct {
+	deleted map[string]string // entity ID -> scope ID
+}
+
+func (m *mockTombstones) Record(ctx context.Context, entityType delta.EntityType, entityID, scopeID string, at time.Time) error {
+	m.deleted[entityID] = scopeID
+	return nil
+}
+
+func (m *mockTombstones) DeletedSince(ctx context.Context, entityType delta.EntityType, scopeID string, since time.Time) ([]string, error) {
+	var ids []string
+	for id, scope := range m.deleted {
+		if scope == scopeID {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockTombstones) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockTombstones) Since(ctx context.Context, scopeIDs []string, since time.Time) ([]delta.Tombstone, error) {
+	return nil, nil
+}
+
+func (m *mockTombstones) Retention() time.Duration {
+	return delta.DefaultRetention
+}
+
+func TestService_ListSince(t *testing.T) {
+	repo := newMockRepository()
+	tombstones := &mockTombstones{deleted: make(map[string]string)}
+	svc := NewService(repo, WithTombstones(tombstones))
+
+	since := time.Now().Add(-time.Hour)
+	repo.medications["med-old"] = &Medication{ID: "med-old", ChildID: "child-123", CreatedAt: since.Add(-time.Hour), UpdatedAt: since.Add(-time.Hour)}
+	repo.medications["med-new"] = &Medication{ID: "med-new", ChildID: "child-123", CreatedAt: since.Add(time.Minute), UpdatedAt: since.Add(time.Minute)}
+	repo.medications["med-gone"] = &Medication{ID: "med-gone", ChildID: "child-123"}
+	repo.medications["med-stopped"] = &Medication{ID: "med-stopped", ChildID: "child-123", Active: true, CreatedAt: since.Add(-time.Hour)}
+	if err := svc.Deactivate(context.Background(), "user-123", "med-stopped"); err != nil {
+		t.Fatalf("Deactivate() error = %v", err)
+	}
+	if err := svc.Delete(context.Background(), "user-123", "med-gone"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	changes, err := svc.ListSince(context.Background(), &MedicationFilter{ChildID: "child-123"}, since)
+	if err != nil {
+		t.Fatalf("ListSince() error = %v", err)
+	}
+	if len(changes.Created) != 1 || changes.Created[0].ID != "med-new" {
+		t.Errorf("Created = %+v, want med-new", changes.Created)
+	}
+	if len(changes.Updated) != 1 || changes.Updated[0].ID != "med-stopped" {
+		t.Errorf("Updated = %+v, want med-stopped", changes.Updated)
+	}
+	if len(changes.Deleted) != 1 || changes.Deleted[0] != "med-gone" {
+		t.Errorf("Deleted = %v, want med-gone", changes.Deleted)
+	}
+	if tombstones.deleted["med-gone"] != "child-123" {
+		t.Errorf("tombstone scope = %q, want child-123", tombstones.deleted["med-gone"])
+	}
+}
+
 func TestService_Deactivate(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -395,12 +494,12 @@ func TestService_Deactivate(t *testing.T) {
 	}
 	created, _ := svc.Create(context.Background(), req)
 
-	err := svc.Deactivate(context.Background(), created.ID)
+	err := svc.Deactivate(context.Background(), "user-123", created.ID)
 	if err != nil {
 		t.Fatalf("Deactivate() error = %v", err)
 	}
 
-	med, _ := svc.Get(context.Background(), created.ID)
+	med, _ := svc.Get(context.Background(), "user-123", created.ID)
 	if med.Active {
 		t.Error("Deactivate() should set Active to false")
 	}
@@ -414,7 +513,7 @@ func TestService_Deactivate_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	err := svc.Deactivate(context.Background(), "non-existent")
+	err := svc.Deactivate(context.Background(), "user-123", "non-existent")
 	if err == nil {
 		t.Error("Deactivate() should return error for non-existent medication")
 	}
@@ -464,6 +563,81 @@ func TestService_LogMedication(t *testing.T) {
 	}
 }
 
+func TestService_Dosage(t *testing.T) {
+	svc := NewService(newMockRepository())
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, &CreateMedicationRequest{
+		ChildID: "child-123", Name: "Test Med", Dosage: "a spoonful", Unit: "ml",
+		Frequency: "daily", StartDate: time.Now(),
+	})
+	if !errors.Is(err, ErrInvalidDosage) {
+		t.Errorf("Create() error = %v, want %v", err, ErrInvalidDosage)
+	}
+
+	med, err := svc.Create(ctx, &CreateMedicationRequest{
+		ChildID: "child-123", Name: "Test Med", Dosage: "250", Unit: "mg",
+		Frequency: "daily", StartDate: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	tests := []struct {
+		dosage  string
+		wantErr error
+	}{
+		{"250", nil},
+		{"0.25 g", nil},
+		{"5 ml", ErrInvalidDosage},
+		{"0", ErrInvalidDosage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dosage, func(t *testing.T) {
+			_, err := svc.LogMedication(ctx, "user-123", &LogMedicationRequest{
+				MedicationID: med.ID, GivenAt: time.Now(), Dosage: tt.dosage,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("LogMedication() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_LogMedication_EarlyDoseWarning(t *testing.T) {
+	svc := NewService(newMockRepository())
+	med, err := svc.Create(context.Background(), &CreateMedicationRequest{
+		ChildID: "child-123", Name: "Ibuprofen", Dosage: "5", Unit: "ml",
+		Frequency: "every_6_hours", StartDate: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name    string
+		givenAt time.Time
+		warn    bool
+	}{
+		{"first dose", now.Add(-8 * time.Hour), false},
+		{"on time", now.Add(-2*time.Hour - 20*time.Minute), false},
+		{"too soon", now, true},
+	}
+	for _, tt := range tests {
+		ctx := warnings.NewContext(context.Background())
+		_, err := svc.LogMedication(ctx, "user-123", &LogMedicationRequest{
+			MedicationID: med.ID, GivenAt: tt.givenAt, Dosage: "5",
+		})
+		if err != nil {
+			t.Fatalf("%s: LogMedication() error = %v", tt.name, err)
+		}
+		if got := warnings.From(ctx); (len(got) > 0) != tt.warn {
+			t.Errorf("%s: warnings = %v, want warning %v", tt.name, got, tt.warn)
+		}
+	}
+}
+
 func TestService_LogMedication_MedicationNotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -499,13 +673,13 @@ func TestService_GetLogs(t *testing.T) {
 	for i := range 3 {
 		logReq := &LogMedicationRequest{
 			MedicationID: med.ID,
-			GivenAt:      time.Now().Add(time.Duration(i) * time.Hour),
+			GivenAt:      time.Now().Add(time.Duration(-i) * time.Hour),
 			Dosage:       "10mg",
 		}
 		svc.LogMedication(context.Background(), "user-123", logReq)
 	}
 
-	logs, err := svc.GetLogs(context.Background(), med.ID)
+	logs, err := svc.GetLogs(context.Background(), med.ID, "")
 	if err != nil {
 		t.Fatalf("GetLogs() error = %v", err)
 	}
@@ -515,6 +689,84 @@ func TestService_GetLogs(t *testing.T) {
 	}
 }
 
+// mockVisibilityService hides child-hidden's medications, and puts
+// child-other in another family
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) CheckChild(ctx context.Context, userID, childID string, recordType visibility.RecordType) error {
+	switch childID {
+	case "child-hidden":
+		return visibility.ErrHidden
+	case "child-other":
+		return family.ErrNotMember
+	}
+	return nil
+}
+
+func TestService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewService(repo, WithVisibility(&mockVisibilityService{}))
+
+	ids := map[string]string{}
+	for _, childID := range []string{"child-123", "child-hidden", "child-other"} {
+		med, err := svc.Create(ctx, &CreateMedicationRequest{ChildID: childID, Name: "Acetaminophen", Dosage: "5", Unit: "ml", StartDate: time.Now()})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids[childID] = med.ID
+	}
+
+	if _, err := svc.Get(ctx, "user-123", ids["child-123"]); err != nil {
+		t.Errorf("Get() visible error = %v", err)
+	}
+	if _, err := svc.Get(ctx, "user-123", ids["child-hidden"]); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Get() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Get(ctx, "user-123", ids["child-other"]); !errors.Is(err, family.ErrNotMember) {
+		t.Errorf("Get() other family error = %v, want ErrNotMember", err)
+	}
+	req := &CreateMedicationRequest{Name: "Ibuprofen", Dosage: "5", Unit: "ml", StartDate: time.Now()}
+	if _, err := svc.Update(ctx, "user-123", ids["child-hidden"], req); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Update() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Delete(ctx, "user-123", ids["child-hidden"]); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Delete() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Create(ctx, &CreateMedicationRequest{ChildID: "child-hidden", Name: "Acetaminophen", Dosage: "5", Unit: "ml", StartDate: time.Now(), UserID: "user-123"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Create() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Deactivate(ctx, "user-123", ids["child-hidden"]); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Deactivate() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.GetLogs(ctx, ids["child-hidden"], "user-123"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("GetLogs() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.GetLogs(ctx, ids["child-hidden"], ""); err != nil {
+		t.Errorf("GetLogs() without a viewer error = %v", err)
+	}
+	logReq := &LogMedicationRequest{MedicationID: ids["child-hidden"], GivenAt: time.Now(), Dosage: "5"}
+	if _, err := svc.LogMedication(ctx, "user-123", logReq); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("LogMedication() hidden error = %v, want ErrHidden", err)
+	}
+
+	list, err := svc.List(ctx, &MedicationFilter{ViewerID: "user-123"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ChildID != "child-123" {
+		t.Errorf("List() = %+v, want only child-123's medication", list)
+	}
+	if _, err := svc.List(ctx, &MedicationFilter{ChildID: "child-hidden", ViewerID: "user-123"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("List() hidden child error = %v, want ErrHidden", err)
+	}
+	if all, _ := svc.List(ctx, &MedicationFilter{}); len(all) != 3 {
+		t.Errorf("List() without a viewer = %d medications, want 3", len(all))
+	}
+}
+
 func TestService_GetLastLog(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -535,7 +787,7 @@ func TestService_GetLastLog(t *testing.T) {
 	for i := range 3 {
 		logReq := &LogMedicationRequest{
 			MedicationID: med.ID,
-			GivenAt:      now.Add(time.Duration(-i) * time.Hour), // Earlier times
+			GivenAt:      now.Add(time.Duration(-i-1) * time.Hour), // Earlier times
 			Dosage:       "10mg",
 		}
 		svc.LogMedication(context.Background(), "user-123", logReq)
@@ -544,12 +796,12 @@ func TestService_GetLastLog(t *testing.T) {
 	// Log the most recent one
 	latestLogReq := &LogMedicationRequest{
 		MedicationID: med.ID,
-		GivenAt:      now.Add(1 * time.Hour), // Most recent
-		Dosage:       "latest",
+		GivenAt:      now, // Most recent
+		Dosage:       "12mg",
 	}
 	svc.LogMedication(context.Background(), "user-123", latestLogReq)
 
-	lastLog, err := svc.GetLastLog(context.Background(), med.ID)
+	lastLog, err := svc.GetLastLog(context.Background(), med.ID, "")
 	if err != nil {
 		t.Fatalf("GetLastLog() error = %v", err)
 	}
@@ -558,8 +810,8 @@ func TestService_GetLastLog(t *testing.T) {
 		t.Fatal("GetLastLog() returned nil")
 	}
 
-	if lastLog.Dosage != "latest" {
-		t.Errorf("GetLastLog() returned wrong log, Dosage = %v, want 'latest'", lastLog.Dosage)
+	if lastLog.Dosage != "12mg" {
+		t.Errorf("GetLastLog() returned wrong log, Dosage = %v, want '12mg'", lastLog.Dosage)
 	}
 }
 
@@ -567,7 +819,7 @@ func TestService_GetLastLog_NoLogs(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	lastLog, err := svc.GetLastLog(context.Background(), "med-no-logs")
+	lastLog, err := svc.GetLastLog(context.Background(), "med-no-logs", "")
 	if err != nil {
 		t.Fatalf("GetLastLog() error = %v", err)
 	}
@@ -576,3 +828,148 @@ func TestService_GetLastLog_NoLogs(t *testing.T) {
 		t.Error("GetLastLog() should return nil when no logs exist")
 	}
 }
+
+// mockCorrections is a test double for corrections.Store
+type mockCorrections struct {
+	recorded []corrections.Correction
+}
+
+func (m *mockCorrections) Record(ctx context.Context, c *corrections.Correction, original, corrected any) error {
+	c.Original, _ = json.Marshal(original)
+	c.Corrected, _ = json.Marshal(corrected)
+	m.recorded = append(m.recorded, *c)
+	return nil
+}
+
+func (m *mockCorrections) List(ctx context.Context, entityType corrections.EntityType, entityID string) ([]corrections.Correction, error) {
+	var list []corrections.Correction
+	for _, c := range m.recorded {
+		if c.EntityType == entityType && c.EntityID == entityID {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+
+func newCorrectionTestService() (Service, *mockRepository, *mockCorrections) {
+	repo := newMockRepository()
+	repo.medications["med-1"] = &Medication{ID: "med-1", ChildID: "child-1", Name: "Paracetamol", Dosage: "2.5", Unit: "ml"}
+	given := time.Now().Add(-2 * time.Hour)
+	repo.logs["med-1"] = []*MedicationLog{{ID: "log-1", MedicationID: "med-1", ChildID: "child-1", GivenAt: given, GivenBy: "user-1", Dosage: "5"}}
+	store := &mockCorrections{}
+	return NewService(repo, WithCorrections(store)), repo, store
+}
+
+func TestService_CorrectLog(t *testing.T) {
+	svc, repo, store := newCorrectionTestService()
+	given := repo.logs["med-1"][0].GivenAt
+
+	log, err := svc.CorrectLog(context.Background(), "user-2", "med-1", "log-1", &CorrectLogRequest{
+		GivenAt: given,
+		Dosage:  "2.5",
+		Reason:  "entered 5 ml instead of 2.5 ml",
+	})
+	if err != nil {
+		t.Fatalf("CorrectLog() error = %v", err)
+	}
+	if log.Dosage != "2.5" || log.GivenBy != "user-1" || log.CorrectedAt == nil {
+		t.Errorf("CorrectLog() = %+v", log)
+	}
+	if repo.logs["med-1"][0].Dosage != "2.5" {
+		t.Error("Expected the corrected dose to be saved")
+	}
+
+	if len(store.recorded) != 1 {
+		t.Fatalf("Expected one correction, got %d", len(store.recorded))
+	}
+	c := store.recorded[0]
+	if c.EntityType != corrections.EntityMedicationLog || c.EntityID != "log-1" || c.CorrectedBy != "user-2" {
+		t.Errorf("Unexpected correction %+v", c)
+	}
+	var original MedicationLog
+	if err := json.Unmarshal(c.Original, &original); err != nil {
+		t.Fatalf("Failed to decode original: %v", err)
+	}
+	if original.Dosage != "5" || original.CorrectedAt != nil {
+		t.Errorf("Original = %+v, want the dose as first logged", original)
+	}
+
+	list, err := svc.ListLogCorrections(context.Background(), "user-123", "med-1", "log-1")
+	if err != nil || len(list) != 1 {
+		t.Errorf("ListLogCorrections() = %v, %v", list, err)
+	}
+}
+
+func TestService_CorrectLog_Errors(t *testing.T) {
+	given := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		medicationID string
+		req          CorrectLogRequest
+		wantErr      error
+	}{
+		{"blank reason", "med-1", CorrectLogRequest{GivenAt: given, Dosage: "2.5", Reason: "  "}, corrections.ErrReasonRequired},
+		{"bad dosage", "med-1", CorrectLogRequest{GivenAt: given, Dosage: "a lot", Reason: "typo"}, ErrInvalidDosage},
+		{"other medication", "med-2", CorrectLogRequest{GivenAt: given, Dosage: "2.5", Reason: "typo"}, ErrLogNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, store := newCorrectionTestService()
+			_, err := svc.CorrectLog(context.Background(), "user-2", tt.medicationID, "log-1", &tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CorrectLog() error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.logs["med-1"][0].Dosage != "5" || len(store.recorded) != 0 {
+				t.Error("A rejected correction should change nothing")
+			}
+		})
+	}
+}
+
+func TestService_PrivateLogs(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newCorrectionTestService()
+	given := repo.logs["med-1"][0].GivenAt
+
+	private, err := svc.LogMedication(ctx, "user-1", &LogMedicationRequest{
+		MedicationID: "med-1", GivenAt: given.Add(time.Hour), Dosage: "2.5", Private: true,
+	})
+	if err != nil {
+		t.Fatalf("LogMedication() error = %v", err)
+	}
+	if !private.Private {
+		t.Fatal("LogMedication() should keep the dose private")
+	}
+
+	if logs, _ := svc.GetLogs(ctx, "med-1", "user-2"); len(logs) != 1 || logs[0].ID != "log-1" {
+		t.Errorf("GetLogs() for another member = %+v, want only the shared dose", logs)
+	}
+	if logs, _ := svc.GetLogs(ctx, "med-1", "user-1"); len(logs) != 2 {
+		t.Errorf("GetLogs() for the giver = %d doses, want 2", len(logs))
+	}
+	if last, _ := svc.GetLastLog(ctx, "med-1", "user-2"); last == nil || last.ID != "log-1" {
+		t.Errorf("GetLastLog() for another member = %+v, want the newest shared dose", last)
+	}
+	if last, _ := svc.GetLastLog(ctx, "med-1", "user-1"); last == nil || last.ID != private.ID {
+		t.Errorf("GetLastLog() for the giver = %+v, want the private dose", last)
+	}
+
+	if _, err := svc.ListLogCorrections(ctx, "user-2", "med-1", private.ID); !errors.Is(err, ErrLogNotFound) {
+		t.Errorf("ListLogCorrections() by another member error = %v, want ErrLogNotFound", err)
+	}
+	if err := svc.SetLogPrivate(ctx, "user-2", "med-1", private.ID, false); !errors.Is(err, ErrLogNotFound) {
+		t.Errorf("SetLogPrivate() by another member error = %v, want ErrLogNotFound", err)
+	}
+	if err := svc.SetLogPrivate(ctx, "user-2", "med-1", "log-1", true); !errors.Is(err, ErrNotGiver) {
+		t.Errorf("SetLogPrivate() of a shared dose by another member error = %v, want ErrNotGiver", err)
+	}
+
+	if err := svc.SetLogPrivate(ctx, "user-1", "med-1", private.ID, false); err != nil {
+		t.Fatalf("SetLogPrivate() by the giver error = %v", err)
+	}
+	if logs, _ := svc.GetLogs(ctx, "med-1", "user-2"); len(logs) != 2 {
+		t.Errorf("GetLogs() for another member after sharing = %d doses, want 2", len(logs))
+	}
+}
@@ -0,0 +1,34 @@
+package medication
+
+import (
+	"context"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// WithVisibility applies family visibility settings, so members cannot see or
+// change medications and doses hidden from them
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+// authorize checks that userID may see the child's medications. Callers that
+// check access their own way, like care plans open to grant holders, pass no
+// user.
+func (s *service) authorize(ctx context.Context, userID, childID string) error {
+	if s.visibility == nil || userID == "" {
+		return nil
+	}
+	return s.visibility.CheckChild(ctx, userID, childID, visibility.RecordMedication)
+}
+
+// viewer filters a list for the filter's viewer, if it has one
+func (s *service) viewer(ctx context.Context, filter *MedicationFilter) (*visibility.Viewer, error) {
+	return visibility.ViewerFor(ctx, s.visibility, filter.ViewerID, filter.ChildID, visibility.RecordMedication)
+}
+
+func childOf(m Medication) string {
+	return m.ChildID
+}
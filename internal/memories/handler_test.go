@@ -0,0 +1,113 @@
+package memories
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	onThisDayFn func(ctx context.Context, userID, childID string, date time.Time) (*OnThisDay, error)
+}
+
+func (m *mockService) OnThisDay(ctx context.Context, userID, childID string, date time.Time) (*OnThisDay, error) {
+	if m.onThisDayFn != nil {
+		return m.onThisDayFn(ctx, userID, childID, date)
+	}
+	return nil, nil
+}
+
+func (m *mockService) CountDue(ctx context.Context, date time.Time) (map[string]int, error) {
+	return nil, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+
+	handler.RegisterChildRoutes(router.Group("/children"))
+	return router
+}
+
+func TestOnThisDay_Success(t *testing.T) {
+	var capturedUser, capturedChild string
+	var capturedDate time.Time
+	svc := &mockService{
+		onThisDayFn: func(ctx context.Context, userID, childID string, date time.Time) (*OnThisDay, error) {
+			capturedUser, capturedChild, capturedDate = userID, childID, date
+			return &OnThisDay{ChildID: childID, Date: date.Format(time.DateOnly), Memories: []Memory{
+				{Kind: KindJournal, ID: "entry-1", Date: "2024-03-10", MonthsAgo: 7, Title: "First steps"},
+			}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/children/child-1/on-this-day?date=2024-10-10", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if capturedUser != "test-user-123" || capturedChild != "child-1" || capturedDate.Format(time.DateOnly) != "2024-10-10" {
+		t.Errorf("service called with %q, %q, %v", capturedUser, capturedChild, capturedDate)
+	}
+
+	var got OnThisDay
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got.Memories) != 1 || got.Memories[0].MonthsAgo != 7 {
+		t.Errorf("response = %+v", got)
+	}
+}
+
+func TestOnThisDay_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		err    error
+		status int
+	}{
+		{"invalid date", "?date=10/10/2024", nil, http.StatusBadRequest},
+		{"child not found", "", ErrChildNotFound, http.StatusNotFound},
+		{"not member", "", ErrNotMember, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				onThisDayFn: func(ctx context.Context, userID, childID string, date time.Time) (*OnThisDay, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/children/child-1/on-this-day"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
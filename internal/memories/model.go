@@ -0,0 +1,29 @@
+package memories
+
+type Kind string
+
+const (
+	KindJournal     Kind = "journal"
+	KindMilestone   Kind = "milestone"
+	KindVaccination Kind = "vaccination"
+	KindGrowth      Kind = "growth"
+)
+
+// Memory is something recorded for a child on the same day of an earlier month
+type Memory struct {
+	Kind      Kind   `json:"kind"`
+	ID        string `json:"id"` // the journal entry, note, vaccination or measurement
+	Date      string `json:"date"`
+	MonthsAgo int    `json:"months_ago"`
+	Title     string `json:"title"`
+	Detail    string `json:"detail,omitempty"`
+	MediaID   string `json:"media_id,omitempty"`
+	Milestone bool   `json:"milestone"`
+}
+
+// OnThisDay collects a child's memories for one day, newest first
+type OnThisDay struct {
+	ChildID  string   `json:"child_id"`
+	Date     string   `json:"date"`
+	Memories []Memory `json:"memories"`
+}
@@ -0,0 +1,228 @@
+// Package memories looks back through a child's records for what happened on
+// the same day in earlier months and years.
+package memories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/journal"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrChildNotFound = errors.New("child not found")
+	ErrNotMember     = errors.New("user is not a member of this family")
+)
+
+// milestoneTag marks a note as a milestone; there is no separate milestone record
+const milestoneTag = "milestone"
+
+type Service interface {
+	// OnThisDay returns the child's memories from earlier months that fell on
+	// the same day of the month as date
+	OnThisDay(ctx context.Context, userID, childID string, date time.Time) (*OnThisDay, error)
+	// CountDue counts each child's journal memories for date, keyed by child
+	CountDue(ctx context.Context, date time.Time) (map[string]int, error)
+}
+
+type Option func(*service)
+
+// WithVisibility leaves out milestone notes and vaccinations for members who
+// have them hidden
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+type service struct {
+	familyService      family.Service
+	journalService     journal.Service
+	notesService       notes.Service
+	growthService      growth.Service
+	vaccinationService vaccination.Service
+	visibility         visibility.Service
+}
+
+func NewService(
+	familyService family.Service,
+	journalService journal.Service,
+	notesService notes.Service,
+	growthService growth.Service,
+	vaccinationService vaccination.Service,
+	opts ...Option,
+) Service {
+	s := &service{
+		familyService:      familyService,
+		journalService:     journalService,
+		notesService:       notesService,
+		growthService:      growthService,
+		vaccinationService: vaccinationService,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *service) OnThisDay(ctx context.Context, userID, childID string, date time.Time) (*OnThisDay, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		return nil, ErrNotMember
+	}
+
+	memories := []Memory{}
+
+	entries, err := s.journalService.OnThisDay(ctx, childID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal entries: %w", err)
+	}
+	for _, e := range entries {
+		d, err := time.Parse(time.DateOnly, e.Date)
+		if err != nil {
+			continue
+		}
+		memories = append(memories, Memory{
+			Kind:      KindJournal,
+			ID:        e.ID,
+			Date:      e.Date,
+			MonthsAgo: monthsBetween(d, date),
+			Title:     e.Caption,
+			MediaID:   e.MediaID,
+			Milestone: e.Milestone,
+		})
+	}
+
+	hidden, err := s.hidden(ctx, userID, childID, visibility.RecordNotes)
+	if err != nil {
+		return nil, err
+	}
+	if !hidden {
+		milestones, err := s.notesService.List(ctx, &notes.NoteFilter{ChildID: childID, Tags: []string{milestoneTag}, ViewerID: userID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get milestones: %w", err)
+		}
+		for _, n := range milestones {
+			if !sameDayEarlier(n.OccurredAt, date) {
+				continue
+			}
+			title := n.Title
+			if title == "" {
+				title = n.Content
+			}
+			memories = append(memories, Memory{
+				Kind:      KindMilestone,
+				ID:        n.ID,
+				Date:      n.OccurredAt.Format(time.DateOnly),
+				MonthsAgo: monthsBetween(n.OccurredAt, date),
+				Title:     title,
+				Detail:    n.Content,
+				Milestone: true,
+			})
+		}
+	}
+
+	hidden, err = s.hidden(ctx, userID, childID, visibility.RecordVaccination)
+	if err != nil {
+		return nil, err
+	}
+	if !hidden {
+		vaxes, err := s.vaccinationService.List(ctx, &vaccination.VaccinationFilter{
+			ChildID:  childID,
+			Statuses: []vaccination.Status{vaccination.StatusCompleted},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get vaccinations: %w", err)
+		}
+		for _, v := range vaxes {
+			if v.AdministeredAt == nil || !sameDayEarlier(*v.AdministeredAt, date) {
+				continue
+			}
+			memories = append(memories, Memory{
+				Kind:      KindVaccination,
+				ID:        v.ID,
+				Date:      v.AdministeredAt.Format(time.DateOnly),
+				MonthsAgo: monthsBetween(*v.AdministeredAt, date),
+				Title:     fmt.Sprintf("%s (Dose %d)", v.Name, v.Dose),
+			})
+		}
+	}
+
+	measurements, err := s.growthService.List(ctx, &growth.MeasurementFilter{ChildID: childID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get growth measurements: %w", err)
+	}
+	for _, m := range measurements {
+		if !sameDayEarlier(m.MeasuredAt, date) {
+			continue
+		}
+		memories = append(memories, Memory{
+			Kind:      KindGrowth,
+			ID:        m.ID,
+			Date:      m.MeasuredAt.Format(time.DateOnly),
+			MonthsAgo: monthsBetween(m.MeasuredAt, date),
+			Title:     measurementTitle(&m),
+			Detail:    m.Notes,
+		})
+	}
+
+	sort.SliceStable(memories, func(i, j int) bool {
+		return memories[i].Date > memories[j].Date
+	})
+
+	return &OnThisDay{
+		ChildID:  childID,
+		Date:     date.Format(time.DateOnly),
+		Memories: memories,
+	}, nil
+}
+
+func (s *service) CountDue(ctx context.Context, date time.Time) (map[string]int, error) {
+	return s.journalService.CountOnThisDay(ctx, date)
+}
+
+func (s *service) hidden(ctx context.Context, userID, childID string, recordType visibility.RecordType) (bool, error) {
+	if s.visibility == nil {
+		return false, nil
+	}
+	return s.visibility.IsHidden(ctx, userID, childID, recordType)
+}
+
+// sameDayEarlier reports whether t falls on date's day of the month in an
+// earlier month
+func sameDayEarlier(t, date time.Time) bool {
+	return t.Day() == date.Day() && monthsBetween(t, date) > 0
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+}
+
+func measurementTitle(m *growth.Measurement) string {
+	var parts []string
+	if m.WeightKg != nil {
+		parts = append(parts, fmt.Sprintf("%.2f kg", *m.WeightKg))
+	}
+	if m.LengthCm != nil {
+		parts = append(parts, fmt.Sprintf("%.1f cm long", *m.LengthCm))
+	}
+	if m.HeadCircumferenceCm != nil {
+		parts = append(parts, fmt.Sprintf("%.1f cm head", *m.HeadCircumferenceCm))
+	}
+	return "Measured " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,172 @@
+package memories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/journal"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// mockFamilyService is a test double for family.Service; unused methods panic via the nil embed
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" {
+		return &family.Child{ID: "child-1", FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID == "user-1" {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockJournalService struct {
+	journal.Service
+	entries []journal.Entry
+}
+
+func (m *mockJournalService) OnThisDay(ctx context.Context, childID string, date time.Time) ([]journal.Entry, error) {
+	return m.entries, nil
+}
+
+func (m *mockJournalService) CountOnThisDay(ctx context.Context, date time.Time) (map[string]int, error) {
+	return map[string]int{"child-1": len(m.entries)}, nil
+}
+
+type mockNotesService struct {
+	notes.Service
+	notes []notes.Note
+}
+
+func (m *mockNotesService) List(ctx context.Context, filter *notes.NoteFilter) ([]notes.Note, error) {
+	return m.notes, nil
+}
+
+type mockGrowthService struct {
+	growth.Service
+	measurements []growth.Measurement
+}
+
+func (m *mockGrowthService) List(ctx context.Context, filter *growth.MeasurementFilter) ([]growth.Measurement, error) {
+	return m.measurements, nil
+}
+
+type mockVaccinationService struct {
+	vaccination.Service
+	vaccinations []vaccination.Vaccination
+}
+
+func (m *mockVaccinationService) List(ctx context.Context, filter *vaccination.VaccinationFilter) ([]vaccination.Vaccination, error) {
+	return m.vaccinations, nil
+}
+
+type mockVisibilityService struct {
+	visibility.Service
+	hidden visibility.RecordType
+}
+
+func (m *mockVisibilityService) IsHidden(ctx context.Context, userID, childID string, recordType visibility.RecordType) (bool, error) {
+	return recordType == m.hidden, nil
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 10, 0, 0, 0, time.UTC)
+}
+
+func newTestService(opts ...Option) Service {
+	weight := 7.4
+	administered := day(2024, time.June, 10)
+	return NewService(
+		&mockFamilyService{},
+		&mockJournalService{entries: []journal.Entry{
+			{ID: "entry-1", ChildID: "child-1", MediaID: "media-1", Caption: "First steps", Date: "2024-03-10", Milestone: true},
+		}},
+		&mockNotesService{notes: []notes.Note{
+			{ID: "note-1", ChildID: "child-1", Title: "First smile", OccurredAt: day(2023, time.October, 10)},
+			{ID: "note-2", ChildID: "child-1", Title: "Rolled over", OccurredAt: day(2024, time.January, 11)},
+		}},
+		&mockGrowthService{measurements: []growth.Measurement{
+			{ID: "growth-1", ChildID: "child-1", MeasuredAt: day(2024, time.August, 10), WeightKg: &weight},
+			{ID: "growth-2", ChildID: "child-1", MeasuredAt: day(2024, time.October, 10), WeightKg: &weight},
+		}},
+		&mockVaccinationService{vaccinations: []vaccination.Vaccination{
+			{ID: "vax-1", ChildID: "child-1", Name: "DTaP", Dose: 2, AdministeredAt: &administered},
+		}},
+		opts...,
+	)
+}
+
+func TestService_OnThisDay(t *testing.T) {
+	svc := newTestService()
+
+	got, err := svc.OnThisDay(context.Background(), "user-1", "child-1", day(2024, time.October, 10))
+	if err != nil {
+		t.Fatalf("OnThisDay() error = %v", err)
+	}
+
+	// Today's measurement and the note from another day are left out
+	want := []struct {
+		kind      Kind
+		id        string
+		monthsAgo int
+	}{
+		{KindGrowth, "growth-1", 2},
+		{KindVaccination, "vax-1", 4},
+		{KindJournal, "entry-1", 7},
+		{KindMilestone, "note-1", 12},
+	}
+	if len(got.Memories) != len(want) {
+		t.Fatalf("OnThisDay() = %+v, want %d memories", got.Memories, len(want))
+	}
+	for i, w := range want {
+		m := got.Memories[i]
+		if m.Kind != w.kind || m.ID != w.id || m.MonthsAgo != w.monthsAgo {
+			t.Errorf("Memories[%d] = %+v, want %s %s %d months ago", i, m, w.kind, w.id, w.monthsAgo)
+		}
+	}
+	if got.Memories[0].Title != "Measured 7.40 kg" {
+		t.Errorf("growth title = %q", got.Memories[0].Title)
+	}
+	if got.Date != "2024-10-10" {
+		t.Errorf("Date = %q, want 2024-10-10", got.Date)
+	}
+}
+
+func TestService_OnThisDay_Hidden(t *testing.T) {
+	svc := newTestService(WithVisibility(&mockVisibilityService{hidden: visibility.RecordNotes}))
+
+	got, err := svc.OnThisDay(context.Background(), "user-1", "child-1", day(2024, time.October, 10))
+	if err != nil {
+		t.Fatalf("OnThisDay() error = %v", err)
+	}
+	for _, m := range got.Memories {
+		if m.Kind == KindMilestone {
+			t.Errorf("OnThisDay() returned hidden milestone %+v", m)
+		}
+	}
+}
+
+func TestService_OnThisDay_Errors(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	if _, err := svc.OnThisDay(ctx, "user-1", "child-9", time.Now()); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("unknown child error = %v, want ErrChildNotFound", err)
+	}
+	if _, err := svc.OnThisDay(ctx, "user-9", "child-1", time.Now()); !errors.Is(err, ErrNotMember) {
+		t.Errorf("outsider error = %v, want ErrNotMember", err)
+	}
+}
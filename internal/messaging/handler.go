@@ -0,0 +1,114 @@
+package messaging
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ninenine/babytrack/internal/envelope"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterChildRoutes registers the care team thread on the children group
+func (h *Handler) RegisterChildRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id/messages", h.list)
+	rg.POST("/:id/messages", h.send)
+	rg.POST("/:id/messages/read", h.markRead)
+	rg.GET("/:id/messages/participants", h.participants)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	req := ListRequest{Before: c.Query("before")}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		req.Limit = n
+	}
+
+	userID := c.GetString("user_id")
+
+	thread, err := h.service.List(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	page := envelope.Pagination{HasMore: thread.HasMore}
+	if thread.HasMore && len(thread.Messages) > 0 {
+		page.Next = thread.Messages[0].ID
+	}
+	envelope.SetPagination(c, page)
+	c.JSON(http.StatusOK, thread)
+}
+
+func (h *Handler) send(c *gin.Context) {
+	var req SendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	m, err := h.service.Send(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, m)
+}
+
+func (h *Handler) markRead(c *gin.Context) {
+	var req MarkReadRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID := c.GetString("user_id")
+
+	marker, err := h.service.MarkRead(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, marker)
+}
+
+func (h *Handler) participants(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	team, err := h.service.Participants(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrChildNotFound), errors.Is(err, ErrMessageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNoAccess):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidMessage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
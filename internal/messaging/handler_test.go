@@ -0,0 +1,166 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	sendFn         func(ctx context.Context, userID, childID string, req *SendRequest) (*Message, error)
+	listFn         func(ctx context.Context, userID, childID string, req *ListRequest) (*Thread, error)
+	markReadFn     func(ctx context.Context, userID, childID string, req *MarkReadRequest) (*ReadMarker, error)
+	participantsFn func(ctx context.Context, userID, childID string) ([]Participant, error)
+}
+
+func (m *mockService) Send(ctx context.Context, userID, childID string, req *SendRequest) (*Message, error) {
+	if m.sendFn != nil {
+		return m.sendFn(ctx, userID, childID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) List(ctx context.Context, userID, childID string, req *ListRequest) (*Thread, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, childID, req)
+	}
+	return &Thread{ChildID: childID, Messages: []Message{}}, nil
+}
+
+func (m *mockService) MarkRead(ctx context.Context, userID, childID string, req *MarkReadRequest) (*ReadMarker, error) {
+	if m.markReadFn != nil {
+		return m.markReadFn(ctx, userID, childID, req)
+	}
+	return &ReadMarker{ChildID: childID}, nil
+}
+
+func (m *mockService) Participants(ctx context.Context, userID, childID string) ([]Participant, error) {
+	if m.participantsFn != nil {
+		return m.participantsFn(ctx, userID, childID)
+	}
+	return []Participant{}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterChildRoutes(protected.Group("/children"))
+	return router
+}
+
+func TestSend_Success(t *testing.T) {
+	svc := &mockService{
+		sendFn: func(ctx context.Context, userID, childID string, req *SendRequest) (*Message, error) {
+			if userID != "test-user-123" || childID != "child-1" {
+				t.Errorf("Unexpected userID %q childID %q", userID, childID)
+			}
+			return &Message{ID: "msg-1", ChildID: childID, Body: req.Body}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/children/child-1/messages", bytes.NewBufferString(`{"body":"Slept through"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSend_NoAccess(t *testing.T) {
+	svc := &mockService{
+		sendFn: func(ctx context.Context, userID, childID string, req *SendRequest) (*Message, error) {
+			return nil, ErrNoAccess
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/children/child-1/messages", bytes.NewBufferString(`{"body":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestList_Params(t *testing.T) {
+	svc := &mockService{
+		listFn: func(ctx context.Context, userID, childID string, req *ListRequest) (*Thread, error) {
+			if req.Before != "msg-9" || req.Limit != 20 {
+				t.Errorf("Unexpected request %+v", req)
+			}
+			return &Thread{ChildID: childID, Messages: []Message{{ID: "msg-5"}}, HasMore: true, Unread: 1}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/children/child-1/messages?before=msg-9&limit=20", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var thread Thread
+	json.Unmarshal(w.Body.Bytes(), &thread)
+	if !thread.HasMore || thread.Unread != 1 {
+		t.Errorf("Unexpected thread %+v", thread)
+	}
+}
+
+func TestList_InvalidLimit(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/children/child-1/messages?limit=0", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestMarkRead_EmptyBody(t *testing.T) {
+	called := false
+	svc := &mockService{
+		markReadFn: func(ctx context.Context, userID, childID string, req *MarkReadRequest) (*ReadMarker, error) {
+			called = true
+			if req.MessageID != "" {
+				t.Errorf("Expected no message ID, got %q", req.MessageID)
+			}
+			return &ReadMarker{ChildID: childID}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/children/child-1/messages/read", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !called {
+		t.Errorf("Expected status 200 with the service called, got %d", w.Code)
+	}
+}
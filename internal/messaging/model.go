@@ -0,0 +1,65 @@
+package messaging
+
+import "time"
+
+const (
+	// MaxBodyLength caps a message, in characters
+	MaxBodyLength = 4000
+
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// Message is one post in a child's care team thread. AuthorRole is the
+// author's family role ("admin" or "member") or, for professionals, their
+// profession, as it was when they posted.
+type Message struct {
+	ID         string    `json:"id"`
+	ChildID    string    `json:"child_id"`
+	AuthorID   string    `json:"author_id"`
+	AuthorName string    `json:"author_name"`
+	AuthorRole string    `json:"author_role"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Thread is a page of a child's messages, oldest first, with the caller's
+// read marker
+type Thread struct {
+	ChildID  string    `json:"child_id"`
+	Messages []Message `json:"messages"`
+	// HasMore is set when older messages exist; pass the first message's ID
+	// as before to fetch them
+	HasMore    bool       `json:"has_more"`
+	LastReadAt *time.Time `json:"last_read_at,omitempty"`
+	Unread     int        `json:"unread"`
+}
+
+// ReadMarker is how far a user has read a child's thread
+type ReadMarker struct {
+	ChildID    string     `json:"child_id"`
+	LastReadAt *time.Time `json:"last_read_at,omitempty"`
+	Unread     int        `json:"unread"`
+}
+
+// Participant is someone who can read and post in a child's thread
+type Participant struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+}
+
+type ListRequest struct {
+	// Before is a message ID; only older messages are returned
+	Before string
+	Limit  int
+}
+
+type SendRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type MarkReadRequest struct {
+	// MessageID is the last message read, the newest when left out
+	MessageID string `json:"message_id,omitempty"`
+}
@@ -0,0 +1,147 @@
+package messaging
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, m *Message) error
+	GetByID(ctx context.Context, id string) (*Message, error)
+	// List returns up to limit messages older than the before message, newest
+	// first; an empty before starts at the newest
+	List(ctx context.Context, childID, before string, limit int) ([]Message, error)
+
+	GetLastRead(ctx context.Context, childID, userID string) (*time.Time, error)
+	// MarkRead moves the user's marker forward to at; it never moves back
+	MarkRead(ctx context.Context, childID, userID string, at time.Time) error
+	// CountUnread counts others' messages after since, or all of them if since is nil
+	CountUnread(ctx context.Context, childID, userID string, since *time.Time) (int, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the message and read receipt tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "care_messages", "care_message_reads")
+}
+
+func (r *repository) Create(ctx context.Context, m *Message) error {
+	query := `
+		INSERT INTO care_messages (id, child_id, author_id, author_role, body, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		m.ID, m.ChildID, m.AuthorID, m.AuthorRole, m.Body, m.CreatedAt,
+	)
+	return err
+}
+
+// messageSelect leaves the author blank once their account is deleted
+const messageSelect = `
+	SELECT m.id, m.child_id, COALESCE(m.author_id, ''), COALESCE(u.name, ''), m.author_role, m.body, m.created_at
+	FROM care_messages m
+	LEFT JOIN users u ON u.id = m.author_id
+`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMessage(row scanner) (*Message, error) {
+	var m Message
+	if err := row.Scan(&m.ID, &m.ChildID, &m.AuthorID, &m.AuthorName, &m.AuthorRole, &m.Body, &m.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Message, error) {
+	m, err := scanMessage(r.db.QueryRowContext(ctx, messageSelect+`WHERE m.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return m, err
+}
+
+func (r *repository) List(ctx context.Context, childID, before string, limit int) ([]Message, error) {
+	query := messageSelect + `WHERE m.child_id = $1 `
+	args := []any{childID}
+	if before != "" {
+		query += `AND (m.created_at, m.id) < (SELECT created_at, id FROM care_messages WHERE id = $3) `
+		args = append(args, limit, before)
+	} else {
+		args = append(args, limit)
+	}
+	query += `ORDER BY m.created_at DESC, m.id DESC LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	messages := []Message{}
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *m)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *repository) GetLastRead(ctx context.Context, childID, userID string) (*time.Time, error) {
+	var at time.Time
+	err := r.db.QueryRowContext(ctx,
+		`SELECT last_read_at FROM care_message_reads WHERE child_id = $1 AND user_id = $2`, childID, userID,
+	).Scan(&at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+func (r *repository) MarkRead(ctx context.Context, childID, userID string, at time.Time) error {
+	query := `
+		INSERT INTO care_message_reads (child_id, user_id, last_read_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (child_id, user_id) DO UPDATE
+		SET last_read_at = GREATEST(care_message_reads.last_read_at, EXCLUDED.last_read_at)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, childID, userID, at)
+	return err
+}
+
+func (r *repository) CountUnread(ctx context.Context, childID, userID string, since *time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM care_messages
+		WHERE child_id = $1 AND author_id IS DISTINCT FROM $2 AND ($3::timestamptz IS NULL OR created_at > $3)
+	`
+
+	var sinceArg sql.NullTime
+	if since != nil {
+		sinceArg = sql.NullTime{Time: *since, Valid: true}
+	}
+
+	var n int
+	err := r.db.QueryRowContext(ctx, query, childID, userID, sinceArg).Scan(&n)
+	return n, err
+}
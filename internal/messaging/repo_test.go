@@ -0,0 +1,125 @@
+package messaging
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var messageColumns = []string{"id", "child_id", "author_id", "name", "author_role", "body", "created_at"}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	m := &Message{ID: "msg-1", ChildID: "child-1", AuthorID: "user-1", AuthorRole: "admin", Body: "Fever down this morning", CreatedAt: now}
+
+	mock.ExpectExec("INSERT INTO care_messages").
+		WithArgs("msg-1", "child-1", "user-1", "admin", "Fever down this morning", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), m); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_List_Before(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(messageColumns).
+		AddRow("msg-2", "child-1", "user-1", "Alex", "admin", "Second", now).
+		AddRow("msg-1", "child-1", "", "", "pediatrician", "First", now.Add(-time.Minute))
+
+	mock.ExpectQuery("SELECT .+ FROM care_messages m .+ WHERE m.child_id = \\$1 AND \\(m.created_at, m.id\\) < .+ LIMIT \\$2").
+		WithArgs("child-1", 51, "msg-3").
+		WillReturnRows(rows)
+
+	messages, err := repo.List(context.Background(), "child-1", "msg-3", 51)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(messages) != 2 || messages[0].AuthorName != "Alex" || messages[1].AuthorID != "" {
+		t.Errorf("List() = %+v", messages)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetLastRead_None(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT last_read_at FROM care_message_reads").
+		WithArgs("child-1", "user-1").
+		WillReturnError(sql.ErrNoRows)
+
+	at, err := repo.GetLastRead(context.Background(), "child-1", "user-1")
+	if err != nil {
+		t.Fatalf("GetLastRead() error = %v", err)
+	}
+	if at != nil {
+		t.Errorf("GetLastRead() = %v, want nil", at)
+	}
+}
+
+func TestRepository_MarkRead(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO care_message_reads .+ GREATEST").
+		WithArgs("child-1", "user-1", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.MarkRead(context.Background(), "child-1", "user-1", now); err != nil {
+		t.Fatalf("MarkRead() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_CountUnread(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM care_messages").
+		WithArgs("child-1", "user-1", sql.NullTime{Time: now, Valid: true}).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	n, err := repo.CountUnread(context.Background(), "child-1", "user-1", &now)
+	if err != nil {
+		t.Fatalf("CountUnread() error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("CountUnread() = %d, want 3", n)
+	}
+}
@@ -0,0 +1,253 @@
+// Package messaging keeps one message thread per child for its care team:
+// the family's members and any professionals holding an active access grant.
+// Posts notify the rest of the team, and each reader keeps a read marker.
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/grants"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+var (
+	ErrChildNotFound   = errors.New("child not found")
+	ErrMessageNotFound = errors.New("message not found")
+	ErrNoAccess        = errors.New("user is not on this child's care team")
+	ErrInvalidMessage  = errors.New("message must not be empty or longer than 4000 characters")
+)
+
+// previewLength is how much of a message goes into its notification
+const previewLength = 100
+
+// Notifier delivers new messages to the rest of the care team
+type Notifier interface {
+	SendToUsers(userIDs []string, event notifications.Event)
+}
+
+type Service interface {
+	Send(ctx context.Context, userID, childID string, req *SendRequest) (*Message, error)
+	List(ctx context.Context, userID, childID string, req *ListRequest) (*Thread, error)
+	MarkRead(ctx context.Context, userID, childID string, req *MarkReadRequest) (*ReadMarker, error)
+	Participants(ctx context.Context, userID, childID string) ([]Participant, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	grantsService grants.Service
+	notifier      Notifier
+	now           func() time.Time
+}
+
+func NewService(repo Repository, familyService family.Service, grantsService grants.Service, notifier Notifier) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+		grantsService: grantsService,
+		notifier:      notifier,
+		now:           time.Now,
+	}
+}
+
+// HealthCheck reports whether care messages can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Send(ctx context.Context, userID, childID string, req *SendRequest) (*Message, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" || utf8.RuneCountInString(body) > MaxBodyLength {
+		return nil, ErrInvalidMessage
+	}
+
+	child, team, author, err := s.team(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Message{
+		ID:         ids.New(),
+		ChildID:    childID,
+		AuthorID:   userID,
+		AuthorName: author.Name,
+		AuthorRole: author.Role,
+		Body:       body,
+		CreatedAt:  s.now(),
+	}
+	if err := s.repo.Create(ctx, m); err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+	// Authors have read their own message
+	if err := s.repo.MarkRead(ctx, childID, userID, m.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to mark message read: %w", err)
+	}
+
+	s.notify(child, team, m)
+	return m, nil
+}
+
+func (s *service) List(ctx context.Context, userID, childID string, req *ListRequest) (*Thread, error) {
+	if _, _, _, err := s.team(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	if req.Before != "" {
+		before, err := s.repo.GetByID(ctx, req.Before)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get message: %w", err)
+		}
+		if before == nil || before.ChildID != childID {
+			return nil, ErrMessageNotFound
+		}
+	}
+
+	// Fetch one extra to learn whether an older page exists
+	messages, err := s.repo.List(ctx, childID, req.Before, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	thread := &Thread{ChildID: childID, HasMore: len(messages) > limit}
+	if thread.HasMore {
+		messages = messages[:limit]
+	}
+	slices.Reverse(messages)
+	thread.Messages = messages
+
+	marker, err := s.marker(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+	thread.LastReadAt = marker.LastReadAt
+	thread.Unread = marker.Unread
+	return thread, nil
+}
+
+// MarkRead moves the user's read marker up to the given message, or to now
+// when none is given. Markers never move backwards.
+func (s *service) MarkRead(ctx context.Context, userID, childID string, req *MarkReadRequest) (*ReadMarker, error) {
+	if _, _, _, err := s.team(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	at := s.now()
+	if req.MessageID != "" {
+		m, err := s.repo.GetByID(ctx, req.MessageID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get message: %w", err)
+		}
+		if m == nil || m.ChildID != childID {
+			return nil, ErrMessageNotFound
+		}
+		at = m.CreatedAt
+	}
+
+	if err := s.repo.MarkRead(ctx, childID, userID, at); err != nil {
+		return nil, fmt.Errorf("failed to mark messages read: %w", err)
+	}
+	return s.marker(ctx, userID, childID)
+}
+
+func (s *service) Participants(ctx context.Context, userID, childID string) ([]Participant, error) {
+	_, team, _, err := s.team(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (s *service) marker(ctx context.Context, userID, childID string) (*ReadMarker, error) {
+	lastRead, err := s.repo.GetLastRead(ctx, childID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get read marker: %w", err)
+	}
+	unread, err := s.repo.CountUnread(ctx, childID, userID, lastRead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return &ReadMarker{ChildID: childID, LastReadAt: lastRead, Unread: unread}, nil
+}
+
+// team returns the child's care team and the user's place on it, or
+// ErrNoAccess when the user is neither a family member nor a professional
+// with an active grant
+func (s *service) team(ctx context.Context, userID, childID string) (*family.Child, []Participant, Participant, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, nil, Participant{}, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return nil, nil, Participant{}, ErrChildNotFound
+	}
+
+	members, err := s.familyService.GetFamilyMembers(ctx, child.FamilyID)
+	if err != nil {
+		return nil, nil, Participant{}, fmt.Errorf("failed to get family members: %w", err)
+	}
+	active, err := s.grantsService.ActiveForChild(ctx, childID)
+	if err != nil {
+		return nil, nil, Participant{}, err
+	}
+
+	team := make([]Participant, 0, len(members)+len(active))
+	for _, m := range members {
+		team = append(team, Participant{UserID: m.UserID, Name: m.Name, Role: m.Role})
+	}
+	for _, g := range active {
+		// A member who also holds a grant is on the team once, as a member
+		if slices.ContainsFunc(team[:len(members)], func(p Participant) bool { return p.UserID == g.ProfessionalID }) {
+			continue
+		}
+		team = append(team, Participant{UserID: g.ProfessionalID, Name: g.ProfessionalName, Role: string(g.Profession)})
+	}
+
+	for _, p := range team {
+		if p.UserID == userID {
+			return child, team, p, nil
+		}
+	}
+	return nil, nil, Participant{}, ErrNoAccess
+}
+
+// notify sends the message to everyone on the team except its author
+func (s *service) notify(child *family.Child, team []Participant, m *Message) {
+	recipients := make([]string, 0, len(team))
+	for _, p := range team {
+		if p.UserID != m.AuthorID {
+			recipients = append(recipients, p.UserID)
+		}
+	}
+
+	s.notifier.SendToUsers(recipients, notifications.Event{
+		ID:        ids.New(),
+		Type:      notifications.EventCareMessage,
+		Title:     "New message about " + child.Name,
+		Message:   m.AuthorName + ": " + preview(m.Body),
+		ChildID:   child.ID,
+		ChildName: child.Name,
+		Timestamp: m.CreatedAt,
+		Data:      m,
+	})
+}
+
+func preview(body string) string {
+	if utf8.RuneCountInString(body) <= previewLength {
+		return body
+	}
+	return string([]rune(body)[:previewLength]) + "…"
+}
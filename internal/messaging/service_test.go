@@ -0,0 +1,262 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/grants"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+type mockRepository struct {
+	messages []Message
+	reads    map[string]time.Time
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{reads: make(map[string]time.Time)}
+}
+
+func (m *mockRepository) Create(ctx context.Context, msg *Message) error {
+	m.messages = append(m.messages, *msg)
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Message, error) {
+	for _, msg := range m.messages {
+		if msg.ID == id {
+			return &msg, nil
+		}
+	}
+	return nil, nil
+}
+
+// List assumes messages were added oldest first
+func (m *mockRepository) List(ctx context.Context, childID, before string, limit int) ([]Message, error) {
+	messages := []Message{}
+	skipping := before != ""
+	for i := len(m.messages) - 1; i >= 0 && len(messages) < limit; i-- {
+		msg := m.messages[i]
+		if skipping {
+			skipping = msg.ID != before
+			continue
+		}
+		if msg.ChildID == childID {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+func (m *mockRepository) GetLastRead(ctx context.Context, childID, userID string) (*time.Time, error) {
+	if at, ok := m.reads[childID+"/"+userID]; ok {
+		return &at, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) MarkRead(ctx context.Context, childID, userID string, at time.Time) error {
+	if prev, ok := m.reads[childID+"/"+userID]; !ok || at.After(prev) {
+		m.reads[childID+"/"+userID] = at
+	}
+	return nil
+}
+
+func (m *mockRepository) CountUnread(ctx context.Context, childID, userID string, since *time.Time) (int, error) {
+	n := 0
+	for _, msg := range m.messages {
+		if msg.ChildID == childID && msg.AuthorID != userID && (since == nil || msg.CreatedAt.After(*since)) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	children map[string]*family.Child
+	members  map[string][]family.MemberWithUser
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+func (m *mockFamilyService) GetFamilyMembers(ctx context.Context, familyID string) ([]family.MemberWithUser, error) {
+	return m.members[familyID], nil
+}
+
+type mockGrantsService struct {
+	grants.Service
+	active []grants.Grant
+}
+
+func (m *mockGrantsService) ActiveForChild(ctx context.Context, childID string) ([]grants.Grant, error) {
+	return m.active, nil
+}
+
+type mockNotifier struct {
+	recipients []string
+	events     []notifications.Event
+}
+
+func (m *mockNotifier) SendToUsers(userIDs []string, event notifications.Event) {
+	m.recipients = userIDs
+	m.events = append(m.events, event)
+}
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService() (*service, *mockRepository, *mockNotifier) {
+	repo := newMockRepository()
+	notifier := &mockNotifier{}
+	now := testNow
+	svc := &service{
+		repo: repo,
+		familyService: &mockFamilyService{
+			children: map[string]*family.Child{
+				"child-1": {ID: "child-1", FamilyID: "family-1", Name: "Emma"},
+			},
+			members: map[string][]family.MemberWithUser{
+				"family-1": {
+					{UserID: "parent-1", Name: "Alex", Role: "admin"},
+					{UserID: "parent-2", Name: "Sam", Role: "member"},
+				},
+			},
+		},
+		grantsService: &mockGrantsService{active: []grants.Grant{
+			{ChildID: "child-1", ProfessionalID: "pro-1", ProfessionalName: "Dr. Patel", Profession: grants.ProfessionPediatrician},
+		}},
+		notifier: notifier,
+		now: func() time.Time {
+			now = now.Add(time.Minute)
+			return now
+		},
+	}
+	return svc, repo, notifier
+}
+
+func TestService_Send(t *testing.T) {
+	svc, _, notifier := newTestService()
+
+	m, err := svc.Send(context.Background(), "pro-1", "child-1", &SendRequest{Body: "  Keep an eye on the rash  "})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if m.Body != "Keep an eye on the rash" || m.AuthorName != "Dr. Patel" || m.AuthorRole != "pediatrician" {
+		t.Errorf("Send() = %+v", m)
+	}
+
+	slices.Sort(notifier.recipients)
+	if !slices.Equal(notifier.recipients, []string{"parent-1", "parent-2"}) {
+		t.Errorf("notified %v, want the family without the author", notifier.recipients)
+	}
+	event := notifier.events[0]
+	if event.Type != notifications.EventCareMessage || event.Title != "New message about Emma" ||
+		event.Message != "Dr. Patel: Keep an eye on the rash" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestService_Send_Invalid(t *testing.T) {
+	svc, _, _ := newTestService()
+
+	for _, body := range []string{"   ", strings.Repeat("a", MaxBodyLength+1)} {
+		if _, err := svc.Send(context.Background(), "parent-1", "child-1", &SendRequest{Body: body}); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("Send(%d chars) error = %v, want ErrInvalidMessage", len(body), err)
+		}
+	}
+}
+
+func TestService_NoAccess(t *testing.T) {
+	svc, _, _ := newTestService()
+
+	if _, err := svc.Send(context.Background(), "stranger", "child-1", &SendRequest{Body: "hi"}); !errors.Is(err, ErrNoAccess) {
+		t.Errorf("Send() error = %v, want ErrNoAccess", err)
+	}
+	if _, err := svc.List(context.Background(), "stranger", "child-1", &ListRequest{}); !errors.Is(err, ErrNoAccess) {
+		t.Errorf("List() error = %v, want ErrNoAccess", err)
+	}
+	if _, err := svc.List(context.Background(), "parent-1", "child-9", &ListRequest{}); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("List() error = %v, want ErrChildNotFound", err)
+	}
+}
+
+func TestService_List_Paging(t *testing.T) {
+	svc, _, _ := newTestService()
+	for _, body := range []string{"one", "two", "three"} {
+		if _, err := svc.Send(context.Background(), "parent-1", "child-1", &SendRequest{Body: body}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	thread, err := svc.List(context.Background(), "parent-2", "child-1", &ListRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if !thread.HasMore || len(thread.Messages) != 2 || thread.Messages[0].Body != "two" || thread.Messages[1].Body != "three" {
+		t.Fatalf("List() = %+v, want two and three oldest first with more", thread)
+	}
+
+	older, err := svc.List(context.Background(), "parent-2", "child-1", &ListRequest{Before: thread.Messages[0].ID, Limit: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if older.HasMore || len(older.Messages) != 1 || older.Messages[0].Body != "one" {
+		t.Errorf("List(before) = %+v, want only one", older)
+	}
+}
+
+func TestService_ReadMarkers(t *testing.T) {
+	svc, _, _ := newTestService()
+	first, _ := svc.Send(context.Background(), "parent-1", "child-1", &SendRequest{Body: "one"})
+	svc.Send(context.Background(), "pro-1", "child-1", &SendRequest{Body: "two"})
+
+	thread, _ := svc.List(context.Background(), "parent-2", "child-1", &ListRequest{})
+	if thread.Unread != 2 || thread.LastReadAt != nil {
+		t.Errorf("Unread = %d, LastReadAt = %v, want 2 and nil", thread.Unread, thread.LastReadAt)
+	}
+
+	// The author's own post counts as read
+	thread, _ = svc.List(context.Background(), "parent-1", "child-1", &ListRequest{})
+	if thread.Unread != 1 {
+		t.Errorf("author Unread = %d, want 1", thread.Unread)
+	}
+
+	marker, err := svc.MarkRead(context.Background(), "parent-2", "child-1", &MarkReadRequest{MessageID: first.ID})
+	if err != nil {
+		t.Fatalf("MarkRead() error = %v", err)
+	}
+	if marker.Unread != 1 || !marker.LastReadAt.Equal(first.CreatedAt) {
+		t.Errorf("MarkRead() = %+v, want 1 unread", marker)
+	}
+
+	marker, _ = svc.MarkRead(context.Background(), "parent-2", "child-1", &MarkReadRequest{})
+	if marker.Unread != 0 {
+		t.Errorf("MarkRead(all) Unread = %d, want 0", marker.Unread)
+	}
+
+	if _, err := svc.MarkRead(context.Background(), "parent-2", "child-1", &MarkReadRequest{MessageID: "msg-9"}); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("MarkRead(unknown) error = %v, want ErrMessageNotFound", err)
+	}
+}
+
+func TestService_Participants_MemberWithGrant(t *testing.T) {
+	svc, _, _ := newTestService()
+	svc.grantsService = &mockGrantsService{active: []grants.Grant{
+		{ChildID: "child-1", ProfessionalID: "parent-2", ProfessionalName: "Sam", Profession: grants.ProfessionNurse},
+	}}
+
+	team, err := svc.Participants(context.Background(), "parent-1", "child-1")
+	if err != nil {
+		t.Fatalf("Participants() error = %v", err)
+	}
+	if len(team) != 2 || team[1].Role != "member" {
+		t.Errorf("Participants() = %+v, want the member once, as a member", team)
+	}
+}
@@ -1,8 +1,14 @@
 package notes
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,16 +28,19 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.PUT("/:id", h.update)
 	rg.DELETE("/:id", h.delete)
 	rg.POST("/:id/pin", h.pin)
+	rg.POST("/:id/private", h.setPrivate)
+	rg.POST("/:id/seen", h.markSeen)
 }
 
 func (h *Handler) list(c *gin.Context) {
 	filter := &NoteFilter{
 		ChildID:    c.Query("child_id"),
 		PinnedOnly: c.Query("pinned_only") == "true",
+		ViewerID:   c.GetString("user_id"),
 	}
 	notes, err := h.service.List(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, notes)
@@ -47,7 +56,11 @@ func (h *Handler) create(c *gin.Context) {
 	userID := c.GetString("user_id")
 	note, err := h.service.Create(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, occurrence.ErrInvalid) || errors.Is(err, limits.ErrExceeded) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, note)
@@ -55,9 +68,13 @@ func (h *Handler) create(c *gin.Context) {
 
 func (h *Handler) get(c *gin.Context) {
 	id := c.Param("id")
-	note, err := h.service.Get(c.Request.Context(), id)
+	note, err := h.service.Get(c.Request.Context(), c.GetString("user_id"), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+	if note != nil && !note.VisibleTo(c.GetString("user_id")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNoteNotFound.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, note)
@@ -71,9 +88,17 @@ func (h *Handler) update(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	note, err := h.service.Update(c.Request.Context(), id, &req)
+	if !h.authorize(c, id) {
+		return
+	}
+
+	note, err := h.service.Update(c.Request.Context(), c.GetString("user_id"), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, occurrence.ErrInvalid) || errors.Is(err, limits.ErrExceeded) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, note)
@@ -81,8 +106,12 @@ func (h *Handler) update(c *gin.Context) {
 
 func (h *Handler) delete(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if !h.authorize(c, id) {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), id); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -98,6 +127,10 @@ func (h *Handler) pin(c *gin.Context) {
 		return
 	}
 
+	if !h.authorize(c, id) {
+		return
+	}
+
 	if err := h.service.Pin(c.Request.Context(), id, req.Pinned); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -105,14 +138,85 @@ func (h *Handler) pin(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+func (h *Handler) setPrivate(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Private bool `json:"private"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if err := h.service.SetPrivate(c.Request.Context(), userID, id, req.Private); err != nil {
+		switch {
+		case errors.Is(err, ErrNoteNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrNotAuthor):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			respondError(c, err)
+		}
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) markSeen(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("user_id")
+	if !h.authorize(c, id) {
+		return
+	}
+
+	if err := h.service.MarkSeen(c.Request.Context(), id, userID); err != nil {
+		if err.Error() == "note not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
 func (h *Handler) search(c *gin.Context) {
 	childID := c.Query("child_id")
 	query := c.Query("q")
 
-	notes, err := h.service.Search(c.Request.Context(), childID, query)
+	notes, err := h.service.Search(c.Request.Context(), childID, query, c.GetString("user_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, notes)
 }
+
+// authorize writes a 404 for notes the user cannot see, including other
+// members' private notes, or a 403 when the child's notes are hidden from
+// them, and reports whether the request may go on
+func (h *Handler) authorize(c *gin.Context, id string) bool {
+	err := h.service.Authorize(c.Request.Context(), c.GetString("user_id"), id)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, ErrNoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		respondError(c, err)
+	}
+	return false
+}
+
+// respondError maps errors from reading and changing notes
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, visibility.ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, family.ErrNotMember), errors.Is(err, visibility.ErrHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -5,11 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"iter"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/visibility"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -25,7 +30,10 @@ type mockService struct {
 	updateFn func(ctx context.Context, id string, req *UpdateNoteRequest) (*Note, error)
 	deleteFn func(ctx context.Context, id string) error
 	pinFn    func(ctx context.Context, id string, pinned bool) error
-	searchFn func(ctx context.Context, childID, query string) ([]Note, error)
+	searchFn func(ctx context.Context, childID, query, viewerID string) ([]Note, error)
+	seenFn   func(ctx context.Context, id, userID string) error
+	authFn   func(ctx context.Context, userID, id string) error
+	privFn   func(ctx context.Context, userID, id string, private bool) error
 }
 
 func (m *mockService) Create(ctx context.Context, userID string, req *CreateNoteRequest) (*Note, error) {
@@ -35,13 +43,17 @@ func (m *mockService) Create(ctx context.Context, userID string, req *CreateNote
 	return nil, nil
 }
 
-func (m *mockService) Get(ctx context.Context, id string) (*Note, error) {
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Note, error) {
 	if m.getFn != nil {
 		return m.getFn(ctx, id)
 	}
 	return nil, nil
 }
 
+func (m *mockService) GetVisible(ctx context.Context, id, userID string) (*Note, error) {
+	return nil, nil
+}
+
 func (m *mockService) List(ctx context.Context, filter *NoteFilter) ([]Note, error) {
 	if m.listFn != nil {
 		return m.listFn(ctx, filter)
@@ -49,14 +61,18 @@ func (m *mockService) List(ctx context.Context, filter *NoteFilter) ([]Note, err
 	return nil, nil
 }
 
-func (m *mockService) Update(ctx context.Context, id string, req *UpdateNoteRequest) (*Note, error) {
+func (m *mockService) Stream(ctx context.Context, filter *NoteFilter) iter.Seq2[Note, error] {
+	return nil
+}
+
+func (m *mockService) Update(ctx context.Context, userID, id string, req *UpdateNoteRequest) (*Note, error) {
 	if m.updateFn != nil {
 		return m.updateFn(ctx, id, req)
 	}
 	return nil, nil
 }
 
-func (m *mockService) Delete(ctx context.Context, id string) error {
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
 	if m.deleteFn != nil {
 		return m.deleteFn(ctx, id)
 	}
@@ -70,16 +86,38 @@ func (m *mockService) Pin(ctx context.Context, id string, pinned bool) error {
 	return nil
 }
 
-func (m *mockService) Search(ctx context.Context, childID, query string) ([]Note, error) {
+func (m *mockService) Search(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 	if m.searchFn != nil {
-		return m.searchFn(ctx, childID, query)
+		return m.searchFn(ctx, childID, query, viewerID)
 	}
 	return nil, nil
 }
 
+func (m *mockService) MarkSeen(ctx context.Context, id, userID string) error {
+	if m.seenFn != nil {
+		return m.seenFn(ctx, id, userID)
+	}
+	return nil
+}
+
+func (m *mockService) Authorize(ctx context.Context, userID, id string) error {
+	if m.authFn != nil {
+		return m.authFn(ctx, userID, id)
+	}
+	return nil
+}
+
+func (m *mockService) SetPrivate(ctx context.Context, userID, id string, private bool) error {
+	if m.privFn != nil {
+		return m.privFn(ctx, userID, id, private)
+	}
+	return nil
+}
+
 // setupRouter creates a test router with the handler registered
 func setupRouter(svc Service) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 	handler := NewHandler(svc)
 
 	// Add middleware to set user_id in context (simulating auth)
@@ -403,6 +441,23 @@ func TestGet_ServiceError(t *testing.T) {
 	}
 }
 
+func TestGet_Hidden(t *testing.T) {
+	svc := &mockService{
+		getFn: func(ctx context.Context, id string) (*Note, error) {
+			return nil, visibility.ErrHidden
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/notes/note-123", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
 func TestGet_VerifiesIDParam(t *testing.T) {
 	var capturedID string
 	svc := &mockService{
@@ -798,6 +853,32 @@ func TestUpdate_WithPartialFields(t *testing.T) {
 	}
 }
 
+func TestUpdate_PrivateNoteOfAnotherMember(t *testing.T) {
+	updated := false
+	svc := &mockService{
+		authFn: func(ctx context.Context, userID, id string) error {
+			return ErrNoteNotFound
+		},
+		updateFn: func(ctx context.Context, id string, req *UpdateNoteRequest) (*Note, error) {
+			updated = true
+			return nil, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("PUT", "/notes/note-123", bytes.NewBufferString(`{"content":"x"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if updated {
+		t.Error("Update should not be called for a note the user cannot see")
+	}
+}
+
 // =====================
 // Delete Handler Tests
 // =====================
@@ -870,7 +951,7 @@ func TestDelete_VerifiesIDParam(t *testing.T) {
 func TestSearch_Success(t *testing.T) {
 	notes := []Note{*sampleNote()}
 	svc := &mockService{
-		searchFn: func(ctx context.Context, childID, query string) ([]Note, error) {
+		searchFn: func(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 			return notes, nil
 		},
 	}
@@ -895,7 +976,7 @@ func TestSearch_Success(t *testing.T) {
 
 func TestSearch_ServiceError(t *testing.T) {
 	svc := &mockService{
-		searchFn: func(ctx context.Context, childID, query string) ([]Note, error) {
+		searchFn: func(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 			return nil, errors.New("search failed")
 		},
 	}
@@ -921,7 +1002,7 @@ func TestSearch_ServiceError(t *testing.T) {
 func TestSearch_VerifiesQueryParams(t *testing.T) {
 	var capturedChildID, capturedQuery string
 	svc := &mockService{
-		searchFn: func(ctx context.Context, childID, query string) ([]Note, error) {
+		searchFn: func(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 			capturedChildID = childID
 			capturedQuery = query
 			return []Note{}, nil
@@ -943,7 +1024,7 @@ func TestSearch_VerifiesQueryParams(t *testing.T) {
 
 func TestSearch_EmptyResult(t *testing.T) {
 	svc := &mockService{
-		searchFn: func(ctx context.Context, childID, query string) ([]Note, error) {
+		searchFn: func(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 			return []Note{}, nil
 		},
 	}
@@ -974,7 +1055,7 @@ func TestSearch_MultipleResults(t *testing.T) {
 
 	notes := []Note{*note1, *note2}
 	svc := &mockService{
-		searchFn: func(ctx context.Context, childID, query string) ([]Note, error) {
+		searchFn: func(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 			return notes, nil
 		},
 	}
@@ -1000,7 +1081,7 @@ func TestSearch_MultipleResults(t *testing.T) {
 func TestSearch_WithoutChildID(t *testing.T) {
 	var capturedChildID string
 	svc := &mockService{
-		searchFn: func(ctx context.Context, childID, query string) ([]Note, error) {
+		searchFn: func(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 			capturedChildID = childID
 			return []Note{}, nil
 		},
@@ -1022,7 +1103,7 @@ func TestSearch_WithoutChildID(t *testing.T) {
 func TestSearch_WithoutQuery(t *testing.T) {
 	var capturedQuery string
 	svc := &mockService{
-		searchFn: func(ctx context.Context, childID, query string) ([]Note, error) {
+		searchFn: func(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 			capturedQuery = query
 			return []Note{}, nil
 		},
@@ -1179,7 +1260,7 @@ func TestRegisterRoutes(t *testing.T) {
 		pinFn: func(ctx context.Context, id string, pinned bool) error {
 			return nil
 		},
-		searchFn: func(ctx context.Context, childID, query string) ([]Note, error) {
+		searchFn: func(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 			return []Note{}, nil
 		},
 	}
@@ -1292,7 +1373,7 @@ func TestUpdate_EmptyBody(t *testing.T) {
 func TestSearch_SpecialCharactersInQuery(t *testing.T) {
 	var capturedQuery string
 	svc := &mockService{
-		searchFn: func(ctx context.Context, childID, query string) ([]Note, error) {
+		searchFn: func(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 			capturedQuery = query
 			return []Note{}, nil
 		},
@@ -1388,3 +1469,140 @@ func TestPin_DefaultPinnedValue(t *testing.T) {
 		t.Error("Expected pinned to default to false")
 	}
 }
+
+func TestMarkSeen_Success(t *testing.T) {
+	var capturedUser string
+	svc := &mockService{
+		seenFn: func(ctx context.Context, id, userID string) error {
+			capturedUser = userID
+			return nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/notes/note-1/seen", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if capturedUser != "test-user-123" {
+		t.Errorf("Expected user test-user-123, got %s", capturedUser)
+	}
+}
+
+func TestMarkSeen_NotFound(t *testing.T) {
+	svc := &mockService{
+		seenFn: func(ctx context.Context, id, userID string) error {
+			return fmt.Errorf("note not found")
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/notes/missing/seen", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+// =====================
+// Privacy Tests
+// =====================
+
+func TestList_PassesViewer(t *testing.T) {
+	var capturedFilter *NoteFilter
+	svc := &mockService{
+		listFn: func(ctx context.Context, filter *NoteFilter) ([]Note, error) {
+			capturedFilter = filter
+			return []Note{}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/notes?child_id=child-456", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if capturedFilter == nil || capturedFilter.ViewerID != "test-user-123" {
+		t.Errorf("Expected ViewerID test-user-123, got %+v", capturedFilter)
+	}
+}
+
+func TestGet_PrivateNoteOfAnotherMember(t *testing.T) {
+	note := sampleNote()
+	note.AuthorID = "someone-else"
+	note.Private = true
+	svc := &mockService{
+		getFn: func(ctx context.Context, id string) (*Note, error) {
+			return note, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/notes/note-123", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestGet_OwnPrivateNote(t *testing.T) {
+	note := sampleNote()
+	note.Private = true
+	svc := &mockService{
+		getFn: func(ctx context.Context, id string) (*Note, error) {
+			return note, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/notes/note-123", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestSetPrivate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, http.StatusOK},
+		{"not author", ErrNotAuthor, http.StatusForbidden},
+		{"not found", ErrNoteNotFound, http.StatusNotFound},
+		{"other", errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				privFn: func(ctx context.Context, userID, id string, private bool) error {
+					if userID != "test-user-123" || id != "note-123" || !private {
+						t.Errorf("SetPrivate(%s, %s, %v)", userID, id, private)
+					}
+					return tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/notes/note-123/private", bytes.NewBufferString(`{"private":true}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
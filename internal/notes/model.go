@@ -3,16 +3,30 @@ package notes
 import "time"
 
 type Note struct {
-	ID        string     `json:"id"`
-	ChildID   string     `json:"child_id"`
-	AuthorID  string     `json:"author_id"`
-	Title     string     `json:"title,omitempty"`
-	Content   string     `json:"content"`
-	Tags      []string   `json:"tags,omitempty"`
-	Pinned    bool       `json:"pinned"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	SyncedAt  *time.Time `json:"synced_at,omitempty"`
+	ID       string   `json:"id"`
+	ChildID  string   `json:"child_id"`
+	AuthorID string   `json:"author_id"`
+	Title    string   `json:"title,omitempty"`
+	Content  string   `json:"content"`
+	Tags     []string `json:"tags,omitempty"`
+	Pinned   bool     `json:"pinned"`
+	// Private notes are only shown to their author, including in exports
+	Private bool `json:"private"`
+	// OccurredAt is when the observation was made; CreatedAt is when it was written down
+	OccurredAt time.Time  `json:"occurred_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	SyncedAt   *time.Time `json:"synced_at,omitempty"`
+	SeenBy     []NoteSeen `json:"seen_by"`
+	// MediaIDs are attachments, served through the media endpoints
+	MediaIDs []string `json:"media_ids,omitempty"`
+}
+
+// NoteSeen records that a family member has read a note
+type NoteSeen struct {
+	UserID string    `json:"user_id"`
+	Name   string    `json:"name"`
+	SeenAt time.Time `json:"seen_at"`
 }
 
 type CreateNoteRequest struct {
@@ -21,6 +35,10 @@ type CreateNoteRequest struct {
 	Content string   `json:"content" binding:"required"`
 	Tags    []string `json:"tags,omitempty"`
 	Pinned  bool     `json:"pinned"`
+	Private bool     `json:"private"`
+	// OccurredAt backdates the note; it defaults to now
+	OccurredAt *time.Time `json:"occurred_at,omitempty"`
+	MediaIDs   []string   `json:"media_ids,omitempty"`
 }
 
 type UpdateNoteRequest struct {
@@ -28,6 +46,10 @@ type UpdateNoteRequest struct {
 	Content string   `json:"content"`
 	Tags    []string `json:"tags,omitempty"`
 	Pinned  bool     `json:"pinned"`
+	// Private is left as it is when omitted; only the author may change it
+	Private *bool `json:"private,omitempty"`
+	// OccurredAt backdates the note; it defaults to now
+	OccurredAt *time.Time `json:"occurred_at,omitempty"`
 }
 
 type NoteFilter struct {
@@ -36,4 +58,11 @@ type NoteFilter struct {
 	Tags       []string
 	PinnedOnly bool
 	Search     string
+	// ViewerID hides other members' private notes when set
+	ViewerID string
+}
+
+// VisibleTo reports whether userID may see the note
+func (n *Note) VisibleTo(userID string) bool {
+	return !n.Private || n.AuthorID == userID
 }
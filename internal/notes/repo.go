@@ -5,17 +5,26 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"iter"
+	"time"
 
 	"github.com/lib/pq"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
 )
 
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*Note, error)
 	List(ctx context.Context, filter *NoteFilter) ([]Note, error)
+	// Stream yields what List returns, reading rows as they are consumed
+	Stream(ctx context.Context, filter *NoteFilter) iter.Seq2[Note, error]
 	Create(ctx context.Context, note *Note) error
 	Update(ctx context.Context, note *Note) error
 	Delete(ctx context.Context, id string) error
-	Search(ctx context.Context, childID, query string) ([]Note, error)
+	Search(ctx context.Context, childID, query, viewerID string) ([]Note, error)
+	MarkSeen(ctx context.Context, noteID, userID string, seenAt time.Time) error
+	GetSeenBy(ctx context.Context, noteIDs []string) (map[string][]NoteSeen, error)
 }
 
 type repository struct {
@@ -26,22 +35,27 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
+// HealthCheck checks the note and read receipt tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "notes", "note_reads")
+}
+
 func (r *repository) GetByID(ctx context.Context, id string) (*Note, error) {
 	query := `
 		SELECT id, child_id, author_id, title, content, tags, pinned,
-		       created_at, updated_at, synced_at
+		       occurred_at, created_at, updated_at, synced_at, media_ids, private
 		FROM notes
 		WHERE id = $1
 	`
 
 	var n Note
 	var title sql.NullString
-	var tags pq.StringArray
+	var tags, mediaIDs pq.StringArray
 	var syncedAt sql.NullTime
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&n.ID, &n.ChildID, &n.AuthorID, &title, &n.Content, &tags,
-		&n.Pinned, &n.CreatedAt, &n.UpdatedAt, &syncedAt,
+		&n.Pinned, &n.OccurredAt, &n.CreatedAt, &n.UpdatedAt, &syncedAt, &mediaIDs, &n.Private,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -55,6 +69,7 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Note, error) {
 		n.Title = title.String
 	}
 	n.Tags = tags
+	n.MediaIDs = mediaIDs
 	if syncedAt.Valid {
 		n.SyncedAt = &syncedAt.Time
 	}
@@ -62,10 +77,11 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Note, error) {
 	return &n, nil
 }
 
-func (r *repository) List(ctx context.Context, filter *NoteFilter) ([]Note, error) {
+// filterQuery selects the notes matching filter, pinned first, then newest
+func filterQuery(filter *NoteFilter) (string, []any) {
 	query := `
 		SELECT id, child_id, author_id, title, content, tags, pinned,
-		       created_at, updated_at, synced_at
+		       occurred_at, created_at, updated_at, synced_at, media_ids, private
 		FROM notes
 		WHERE 1=1
 	`
@@ -90,56 +106,74 @@ func (r *repository) List(ctx context.Context, filter *NoteFilter) ([]Note, erro
 		argIndex++
 	}
 
+	if filter.ViewerID != "" {
+		query += fmt.Sprintf(` AND (private = FALSE OR author_id = $%d)`, argIndex)
+		args = append(args, filter.ViewerID)
+		argIndex++
+	}
+
 	if len(filter.Tags) > 0 {
 		query += fmt.Sprintf(` AND tags && $%d`, argIndex)
 		args = append(args, pq.Array(filter.Tags))
 	}
 
-	query += ` ORDER BY pinned DESC, created_at DESC`
+	return query + ` ORDER BY pinned DESC, occurred_at DESC`, args
+}
+
+func scanNote(rows *sql.Rows) (Note, error) {
+	var n Note
+	var title sql.NullString
+	var tags, mediaIDs pq.StringArray
+	var syncedAt sql.NullTime
+
+	if err := rows.Scan(
+		&n.ID, &n.ChildID, &n.AuthorID, &title, &n.Content, &tags,
+		&n.Pinned, &n.OccurredAt, &n.CreatedAt, &n.UpdatedAt, &syncedAt, &mediaIDs, &n.Private,
+	); err != nil {
+		return Note{}, err
+	}
+
+	if title.Valid {
+		n.Title = title.String
+	}
+	n.Tags = tags
+	n.MediaIDs = mediaIDs
+	if syncedAt.Valid {
+		n.SyncedAt = &syncedAt.Time
+	}
+	return n, nil
+}
 
+func (r *repository) List(ctx context.Context, filter *NoteFilter) ([]Note, error) {
+	query, args := filterQuery(filter)
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close() //nolint:errcheck // Best-effort close
 
-	var notes []Note
+	notes := []Note{}
 	for rows.Next() {
-		var n Note
-		var title sql.NullString
-		var tags pq.StringArray
-		var syncedAt sql.NullTime
-
-		if err := rows.Scan(
-			&n.ID, &n.ChildID, &n.AuthorID, &title, &n.Content, &tags,
-			&n.Pinned, &n.CreatedAt, &n.UpdatedAt, &syncedAt,
-		); err != nil {
+		n, err := scanNote(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		if title.Valid {
-			n.Title = title.String
-		}
-		n.Tags = tags
-		if syncedAt.Valid {
-			n.SyncedAt = &syncedAt.Time
-		}
-
 		notes = append(notes, n)
 	}
 
-	if notes == nil {
-		return []Note{}, nil
-	}
-
 	return notes, rows.Err()
 }
 
+func (r *repository) Stream(ctx context.Context, filter *NoteFilter) iter.Seq2[Note, error] {
+	query, args := filterQuery(filter)
+	return db.Stream(ctx, r.db, scanNote, query, args...)
+}
+
 func (r *repository) Create(ctx context.Context, note *Note) error {
 	query := `
 		INSERT INTO notes (id, child_id, author_id, title, content, tags, pinned,
-		                   created_at, updated_at, synced_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		                   occurred_at, created_at, updated_at, synced_at, media_ids, private)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 
 	var title *string
@@ -147,9 +181,16 @@ func (r *repository) Create(ctx context.Context, note *Note) error {
 		title = &note.Title
 	}
 
+	// media_ids is NOT NULL, and pq encodes a nil slice as NULL
+	mediaIDs := note.MediaIDs
+	if mediaIDs == nil {
+		mediaIDs = []string{}
+	}
+
 	_, err := r.db.ExecContext(ctx, query,
 		note.ID, note.ChildID, note.AuthorID, title, note.Content,
-		pq.Array(note.Tags), note.Pinned, note.CreatedAt, note.UpdatedAt, note.SyncedAt,
+		pq.Array(note.Tags), note.Pinned, note.OccurredAt, note.CreatedAt, note.UpdatedAt, note.SyncedAt,
+		pq.Array(mediaIDs), note.Private,
 	)
 
 	return err
@@ -158,7 +199,8 @@ func (r *repository) Create(ctx context.Context, note *Note) error {
 func (r *repository) Update(ctx context.Context, note *Note) error {
 	query := `
 		UPDATE notes
-		SET title = $2, content = $3, tags = $4, pinned = $5, updated_at = $6, synced_at = $7
+		SET title = $2, content = $3, tags = $4, pinned = $5, occurred_at = $6,
+		    updated_at = $7, synced_at = $8, private = $9
 		WHERE id = $1
 	`
 
@@ -169,7 +211,7 @@ func (r *repository) Update(ctx context.Context, note *Note) error {
 
 	_, err := r.db.ExecContext(ctx, query,
 		note.ID, title, note.Content, pq.Array(note.Tags),
-		note.Pinned, note.UpdatedAt, note.SyncedAt,
+		note.Pinned, note.OccurredAt, note.UpdatedAt, note.SyncedAt, note.Private,
 	)
 
 	return err
@@ -177,24 +219,26 @@ func (r *repository) Update(ctx context.Context, note *Note) error {
 
 func (r *repository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM notes WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *repository) Search(ctx context.Context, childID, query string) ([]Note, error) {
+// Search leaves out private notes written by anyone but viewerID
+func (r *repository) Search(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 	sqlQuery := `
 		SELECT id, child_id, author_id, title, content, tags, pinned,
-		       created_at, updated_at, synced_at
+		       occurred_at, created_at, updated_at, synced_at, media_ids, private
 		FROM notes
 		WHERE child_id = $1
 		  AND (title ILIKE $2 OR content ILIKE $2)
-		ORDER BY pinned DESC, created_at DESC
+		  AND (private = FALSE OR author_id = $3)
+		ORDER BY pinned DESC, occurred_at DESC
 		LIMIT 50
 	`
 
 	searchPattern := "%" + query + "%"
 
-	rows, err := r.db.QueryContext(ctx, sqlQuery, childID, searchPattern)
+	rows, err := r.db.QueryContext(ctx, sqlQuery, childID, searchPattern, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -204,12 +248,12 @@ func (r *repository) Search(ctx context.Context, childID, query string) ([]Note,
 	for rows.Next() {
 		var n Note
 		var title sql.NullString
-		var tags pq.StringArray
+		var tags, mediaIDs pq.StringArray
 		var syncedAt sql.NullTime
 
 		if err := rows.Scan(
 			&n.ID, &n.ChildID, &n.AuthorID, &title, &n.Content, &tags,
-			&n.Pinned, &n.CreatedAt, &n.UpdatedAt, &syncedAt,
+			&n.Pinned, &n.OccurredAt, &n.CreatedAt, &n.UpdatedAt, &syncedAt, &mediaIDs, &n.Private,
 		); err != nil {
 			return nil, err
 		}
@@ -218,6 +262,7 @@ func (r *repository) Search(ctx context.Context, childID, query string) ([]Note,
 			n.Title = title.String
 		}
 		n.Tags = tags
+		n.MediaIDs = mediaIDs
 		if syncedAt.Valid {
 			n.SyncedAt = &syncedAt.Time
 		}
@@ -231,3 +276,47 @@ func (r *repository) Search(ctx context.Context, childID, query string) ([]Note,
 
 	return notes, rows.Err()
 }
+
+// MarkSeen records the first time a user saw a note; later views are ignored
+func (r *repository) MarkSeen(ctx context.Context, noteID, userID string, seenAt time.Time) error {
+	query := `
+		INSERT INTO note_reads (note_id, user_id, seen_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (note_id, user_id) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, noteID, userID, seenAt)
+	return err
+}
+
+// GetSeenBy returns the readers of each note, keyed by note ID
+func (r *repository) GetSeenBy(ctx context.Context, noteIDs []string) (map[string][]NoteSeen, error) {
+	seenBy := make(map[string][]NoteSeen)
+	if len(noteIDs) == 0 {
+		return seenBy, nil
+	}
+
+	query := `
+		SELECT r.note_id, r.user_id, u.name, r.seen_at
+		FROM note_reads r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.note_id = ANY($1)
+		ORDER BY r.seen_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(noteIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	for rows.Next() {
+		var noteID string
+		var seen NoteSeen
+		if err := rows.Scan(&noteID, &seen.UserID, &seen.Name, &seen.SeenAt); err != nil {
+			return nil, err
+		}
+		seenBy[noteID] = append(seenBy[noteID], seen)
+	}
+
+	return seenBy, rows.Err()
+}
@@ -22,7 +22,7 @@ func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
 
 var noteColumns = []string{
 	"id", "child_id", "author_id", "title", "content", "tags", "pinned",
-	"created_at", "updated_at", "synced_at",
+	"occurred_at", "created_at", "updated_at", "synced_at", "media_ids", "private",
 }
 
 // =============================================================================
@@ -37,7 +37,7 @@ func TestRepository_GetByID(t *testing.T) {
 	now := time.Now()
 	syncedAt := now.Add(time.Hour)
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-123", "child-456", "author-789", "Test Title", "Test content", pq.Array([]string{"tag1", "tag2"}), true, now, now, syncedAt)
+		AddRow("note-123", "child-456", "author-789", "Test Title", "Test content", pq.Array([]string{"tag1", "tag2"}), true, now, now, now, syncedAt, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
 		WithArgs("note-123").
@@ -138,7 +138,7 @@ func TestRepository_GetByID_NullOptionalFields(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-123", "child-456", "author-789", nil, "Test content", pq.Array([]string{}), false, now, now, nil)
+		AddRow("note-123", "child-456", "author-789", nil, "Test content", pq.Array([]string{}), false, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
 		WithArgs("note-123").
@@ -178,8 +178,8 @@ func TestRepository_List(t *testing.T) {
 	now := time.Now()
 	syncedAt := now.Add(time.Hour)
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-1", "Title 1", "Content 1", pq.Array([]string{"tag1"}), true, now, now, syncedAt).
-		AddRow("note-2", "child-456", "author-2", nil, "Content 2", pq.Array([]string{}), false, now, now, nil)
+		AddRow("note-1", "child-456", "author-1", "Title 1", "Content 1", pq.Array([]string{"tag1"}), true, now, now, now, syncedAt, pq.Array([]string{}), false).
+		AddRow("note-2", "child-456", "author-2", nil, "Content 2", pq.Array([]string{}), false, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
 		WithArgs("child-456").
@@ -215,7 +215,7 @@ func TestRepository_List_WithAuthorFilter(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-123", "Title 1", "Content 1", pq.Array([]string{}), false, now, now, nil)
+		AddRow("note-1", "child-456", "author-123", "Title 1", "Content 1", pq.Array([]string{}), false, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
 		WithArgs("child-456", "author-123").
@@ -240,6 +240,33 @@ func TestRepository_List_WithAuthorFilter(t *testing.T) {
 	}
 }
 
+func TestRepository_List_HidesOthersPrivateNotes(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(noteColumns).
+		AddRow("note-1", "child-456", "viewer-1", "Mine", "Content", pq.Array([]string{}), false, now, now, now, nil, pq.Array([]string{}), true)
+
+	mock.ExpectQuery("SELECT (.+) FROM notes WHERE 1=1 AND child_id = \\$1 AND \\(private = FALSE OR author_id = \\$2\\)").
+		WithArgs("child-456", "viewer-1").
+		WillReturnRows(rows)
+
+	notes, err := repo.List(context.Background(), &NoteFilter{ChildID: "child-456", ViewerID: "viewer-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(notes) != 1 || !notes[0].Private {
+		t.Errorf("List() = %+v, want the viewer's private note", notes)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestRepository_List_PinnedOnly(t *testing.T) {
 	db, mock := newMockDB(t)
 	defer db.Close()
@@ -247,7 +274,7 @@ func TestRepository_List_PinnedOnly(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-1", "Pinned Note", "Content", pq.Array([]string{}), true, now, now, nil)
+		AddRow("note-1", "child-456", "author-1", "Pinned Note", "Content", pq.Array([]string{}), true, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
 		WithArgs("child-456", true).
@@ -279,7 +306,7 @@ func TestRepository_List_WithTagsFilter(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-1", "Tagged Note", "Content", pq.Array([]string{"important", "health"}), false, now, now, nil)
+		AddRow("note-1", "child-456", "author-1", "Tagged Note", "Content", pq.Array([]string{"important", "health"}), false, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
 		WithArgs("child-456", pq.Array([]string{"important"})).
@@ -307,7 +334,7 @@ func TestRepository_List_AllFilters(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-123", "Full Filter Note", "Content", pq.Array([]string{"urgent"}), true, now, now, nil)
+		AddRow("note-1", "child-456", "author-123", "Full Filter Note", "Content", pq.Array([]string{"urgent"}), true, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
 		WithArgs("child-456", "author-123", true, pq.Array([]string{"urgent"})).
@@ -415,8 +442,8 @@ func TestRepository_GetPinned(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-1", "Pinned 1", "Content 1", pq.Array([]string{"important"}), true, now, now, nil).
-		AddRow("note-2", "child-456", "author-2", "Pinned 2", "Content 2", pq.Array([]string{}), true, now, now, nil)
+		AddRow("note-1", "child-456", "author-1", "Pinned 1", "Content 1", pq.Array([]string{"important"}), true, now, now, now, nil, pq.Array([]string{}), false).
+		AddRow("note-2", "child-456", "author-2", "Pinned 2", "Content 2", pq.Array([]string{}), true, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
 		WithArgs("child-456", true).
@@ -499,7 +526,8 @@ func TestRepository_Create(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO notes").
 		WithArgs(note.ID, note.ChildID, note.AuthorID, &note.Title, note.Content,
-			pq.Array(note.Tags), note.Pinned, note.CreatedAt, note.UpdatedAt, note.SyncedAt).
+			pq.Array(note.Tags), note.Pinned, note.OccurredAt, note.CreatedAt, note.UpdatedAt, note.SyncedAt,
+			pq.Array([]string{}), note.Private).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), note)
@@ -531,7 +559,8 @@ func TestRepository_Create_NoOptionalFields(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO notes").
 		WithArgs(note.ID, note.ChildID, note.AuthorID, nil, note.Content,
-			pq.Array(note.Tags), note.Pinned, note.CreatedAt, note.UpdatedAt, nil).
+			pq.Array(note.Tags), note.Pinned, note.OccurredAt, note.CreatedAt, note.UpdatedAt, nil,
+			pq.Array([]string{}), note.Private).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), note)
@@ -564,7 +593,8 @@ func TestRepository_Create_WithTags(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO notes").
 		WithArgs(note.ID, note.ChildID, note.AuthorID, &note.Title, note.Content,
-			pq.Array(note.Tags), note.Pinned, note.CreatedAt, note.UpdatedAt, nil).
+			pq.Array(note.Tags), note.Pinned, note.OccurredAt, note.CreatedAt, note.UpdatedAt, nil,
+			pq.Array([]string{}), note.Private).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), note)
@@ -595,7 +625,8 @@ func TestRepository_Create_Error(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO notes").
 		WithArgs(note.ID, note.ChildID, note.AuthorID, nil, note.Content,
-			pq.Array(note.Tags), note.Pinned, note.CreatedAt, note.UpdatedAt, nil).
+			pq.Array(note.Tags), note.Pinned, note.OccurredAt, note.CreatedAt, note.UpdatedAt, nil,
+			pq.Array([]string{}), note.Private).
 		WillReturnError(errors.New("duplicate key"))
 
 	err := repo.Create(context.Background(), note)
@@ -631,7 +662,7 @@ func TestRepository_Update(t *testing.T) {
 
 	mock.ExpectExec("UPDATE notes SET title").
 		WithArgs(note.ID, &note.Title, note.Content, pq.Array(note.Tags),
-			note.Pinned, note.UpdatedAt, note.SyncedAt).
+			note.Pinned, note.OccurredAt, note.UpdatedAt, note.SyncedAt, note.Private).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), note)
@@ -661,7 +692,7 @@ func TestRepository_Update_NoTitle(t *testing.T) {
 
 	mock.ExpectExec("UPDATE notes SET title").
 		WithArgs(note.ID, nil, note.Content, pq.Array(note.Tags),
-			note.Pinned, note.UpdatedAt, nil).
+			note.Pinned, note.OccurredAt, note.UpdatedAt, nil, false).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), note)
@@ -689,7 +720,7 @@ func TestRepository_Update_Error(t *testing.T) {
 
 	mock.ExpectExec("UPDATE notes SET title").
 		WithArgs(note.ID, nil, note.Content, pq.Array(note.Tags),
-			note.Pinned, note.UpdatedAt, nil).
+			note.Pinned, note.OccurredAt, note.UpdatedAt, nil, false).
 		WillReturnError(errors.New("database error"))
 
 	err := repo.Update(context.Background(), note)
@@ -723,7 +754,7 @@ func TestRepository_Pin(t *testing.T) {
 
 	mock.ExpectExec("UPDATE notes SET title").
 		WithArgs(note.ID, &note.Title, note.Content, pq.Array(note.Tags),
-			true, note.UpdatedAt, nil).
+			true, note.OccurredAt, note.UpdatedAt, nil, false).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), note)
@@ -753,7 +784,7 @@ func TestRepository_Unpin(t *testing.T) {
 
 	mock.ExpectExec("UPDATE notes SET title").
 		WithArgs(note.ID, &note.Title, note.Content, pq.Array(note.Tags),
-			false, note.UpdatedAt, nil).
+			false, note.OccurredAt, note.UpdatedAt, nil, false).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), note)
@@ -840,14 +871,14 @@ func TestRepository_Search(t *testing.T) {
 	now := time.Now()
 	syncedAt := now.Add(time.Hour)
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-1", "Doctor Visit", "Visited the doctor today", pq.Array([]string{"health"}), true, now, now, syncedAt).
-		AddRow("note-2", "child-456", "author-2", nil, "Doctor recommended vitamins", pq.Array([]string{}), false, now, now, nil)
+		AddRow("note-1", "child-456", "author-1", "Doctor Visit", "Visited the doctor today", pq.Array([]string{"health"}), true, now, now, now, syncedAt, pq.Array([]string{}), false).
+		AddRow("note-2", "child-456", "author-2", nil, "Doctor recommended vitamins", pq.Array([]string{}), false, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
-		WithArgs("child-456", "%doctor%").
+		WithArgs("child-456", "%doctor%", "author-1").
 		WillReturnRows(rows)
 
-	notes, err := repo.Search(context.Background(), "child-456", "doctor")
+	notes, err := repo.Search(context.Background(), "child-456", "doctor", "author-1")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -868,13 +899,13 @@ func TestRepository_Search_ByTitle(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-1", "Vaccination Record", "Got flu shot", pq.Array([]string{"health"}), false, now, now, nil)
+		AddRow("note-1", "child-456", "author-1", "Vaccination Record", "Got flu shot", pq.Array([]string{"health"}), false, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
-		WithArgs("child-456", "%vaccination%").
+		WithArgs("child-456", "%vaccination%", "author-1").
 		WillReturnRows(rows)
 
-	notes, err := repo.Search(context.Background(), "child-456", "vaccination")
+	notes, err := repo.Search(context.Background(), "child-456", "vaccination", "author-1")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -899,13 +930,13 @@ func TestRepository_Search_ByContent(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-1", "General Note", "Remember to buy milk for baby", pq.Array([]string{}), false, now, now, nil)
+		AddRow("note-1", "child-456", "author-1", "General Note", "Remember to buy milk for baby", pq.Array([]string{}), false, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
-		WithArgs("child-456", "%milk%").
+		WithArgs("child-456", "%milk%", "author-1").
 		WillReturnRows(rows)
 
-	notes, err := repo.Search(context.Background(), "child-456", "milk")
+	notes, err := repo.Search(context.Background(), "child-456", "milk", "author-1")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -927,10 +958,10 @@ func TestRepository_Search_Empty(t *testing.T) {
 	rows := sqlmock.NewRows(noteColumns)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
-		WithArgs("child-456", "%nonexistent%").
+		WithArgs("child-456", "%nonexistent%", "author-1").
 		WillReturnRows(rows)
 
-	notes, err := repo.Search(context.Background(), "child-456", "nonexistent")
+	notes, err := repo.Search(context.Background(), "child-456", "nonexistent", "author-1")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -954,10 +985,10 @@ func TestRepository_Search_Error(t *testing.T) {
 	repo := NewRepository(db)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
-		WithArgs("child-456", "%test%").
+		WithArgs("child-456", "%test%", "author-1").
 		WillReturnError(errors.New("database error"))
 
-	_, err := repo.Search(context.Background(), "child-456", "test")
+	_, err := repo.Search(context.Background(), "child-456", "test", "author-1")
 	if err == nil {
 		t.Error("Search() should return error on database failure")
 	}
@@ -977,10 +1008,10 @@ func TestRepository_Search_ScanError(t *testing.T) {
 		AddRow("note-1", "child-456")
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
-		WithArgs("child-456", "%test%").
+		WithArgs("child-456", "%test%", "author-1").
 		WillReturnRows(rows)
 
-	_, err := repo.Search(context.Background(), "child-456", "test")
+	_, err := repo.Search(context.Background(), "child-456", "test", "author-1")
 	if err == nil {
 		t.Error("Search() should return error on scan failure")
 	}
@@ -997,13 +1028,13 @@ func TestRepository_Search_NullOptionalFields(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-1", nil, "Content with null title", pq.Array([]string{}), false, now, now, nil)
+		AddRow("note-1", "child-456", "author-1", nil, "Content with null title", pq.Array([]string{}), false, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
-		WithArgs("child-456", "%content%").
+		WithArgs("child-456", "%content%", "author-1").
 		WillReturnRows(rows)
 
-	notes, err := repo.Search(context.Background(), "child-456", "content")
+	notes, err := repo.Search(context.Background(), "child-456", "content", "author-1")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -1032,13 +1063,13 @@ func TestRepository_Search_WithTags(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(noteColumns).
-		AddRow("note-1", "child-456", "author-1", "Health Note", "Regular checkup notes", pq.Array([]string{"health", "checkup", "routine"}), true, now, now, nil)
+		AddRow("note-1", "child-456", "author-1", "Health Note", "Regular checkup notes", pq.Array([]string{"health", "checkup", "routine"}), true, now, now, now, nil, pq.Array([]string{}), false)
 
 	mock.ExpectQuery("SELECT id, child_id, author_id, title, content, tags, pinned").
-		WithArgs("child-456", "%checkup%").
+		WithArgs("child-456", "%checkup%", "author-1").
 		WillReturnRows(rows)
 
-	notes, err := repo.Search(context.Background(), "child-456", "checkup")
+	notes, err := repo.Search(context.Background(), "child-456", "checkup", "author-1")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -1062,3 +1093,65 @@ func TestRepository_Search_WithTags(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func TestRepository_MarkSeen(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO note_reads").
+		WithArgs("note-1", "user-1", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.MarkSeen(context.Background(), "note-1", "user-1", now); err != nil {
+		t.Fatalf("MarkSeen() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetSeenBy(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"note_id", "user_id", "name", "seen_at"}).
+		AddRow("note-1", "user-1", "Sam", now).
+		AddRow("note-1", "user-2", "Alex", now)
+
+	mock.ExpectQuery("SELECT r.note_id, r.user_id, u.name, r.seen_at").
+		WillReturnRows(rows)
+
+	seenBy, err := repo.GetSeenBy(context.Background(), []string{"note-1", "note-2"})
+	if err != nil {
+		t.Fatalf("GetSeenBy() error = %v", err)
+	}
+	if len(seenBy["note-1"]) != 2 || seenBy["note-1"][1].Name != "Alex" {
+		t.Errorf("GetSeenBy() = %+v", seenBy)
+	}
+	if _, ok := seenBy["note-2"]; ok {
+		t.Error("GetSeenBy() should not include unseen notes")
+	}
+}
+
+func TestRepository_GetSeenBy_NoIDs(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	seenBy, err := repo.GetSeenBy(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetSeenBy() error = %v", err)
+	}
+	if len(seenBy) != 0 {
+		t.Errorf("GetSeenBy() = %v, want empty", seenBy)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -2,44 +2,113 @@ package notes
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"iter"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrNoteNotFound = errors.New("note not found")
+	ErrNotAuthor    = errors.New("only the author can change who sees a note")
 )
 
 type Service interface {
 	Create(ctx context.Context, userID string, req *CreateNoteRequest) (*Note, error)
-	Get(ctx context.Context, id string) (*Note, error)
+	Get(ctx context.Context, userID, id string) (*Note, error)
+	// GetVisible is Get for a viewer: another member's private note is
+	// returned as nil, as if it did not exist
+	GetVisible(ctx context.Context, id, userID string) (*Note, error)
 	List(ctx context.Context, filter *NoteFilter) ([]Note, error)
-	Update(ctx context.Context, id string, req *UpdateNoteRequest) (*Note, error)
-	Delete(ctx context.Context, id string) error
+	// Stream yields what List returns without holding it all, for exports
+	Stream(ctx context.Context, filter *NoteFilter) iter.Seq2[Note, error]
+	Update(ctx context.Context, userID, id string, req *UpdateNoteRequest) (*Note, error)
+	Delete(ctx context.Context, userID, id string) error
 	Pin(ctx context.Context, id string, pinned bool) error
-	Search(ctx context.Context, childID, query string) ([]Note, error)
+	Search(ctx context.Context, childID, query, viewerID string) ([]Note, error)
+	MarkSeen(ctx context.Context, id, userID string) error
+	// Authorize returns ErrNoteNotFound unless the note exists and userID may
+	// see it, and the visibility error when the child's notes are hidden
+	Authorize(ctx context.Context, userID, id string) error
+	SetPrivate(ctx context.Context, userID, id string, private bool) error
 }
 
 type service struct {
-	repo Repository
+	repo       Repository
+	tx         db.TxManager
+	tombstones delta.Tombstones
+	visibility visibility.Service
+}
+
+type Option func(*service)
+
+// WithTombstones remembers deleted notes so that sync pulls can report them.
+// tx deletes a note and records its tombstone atomically; the repository
+// must be built on the same database.
+func WithTombstones(tombstones delta.Tombstones, tx db.TxManager) Option {
+	return func(s *service) {
+		s.tombstones = tombstones
+		s.tx = tx
+	}
+}
+
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{repo: repo, tx: db.NoTx}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// HealthCheck reports whether notes can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
 }
 
 func (s *service) Create(ctx context.Context, userID string, req *CreateNoteRequest) (*Note, error) {
+	// Internal callers may write without an author and check access themselves
+	if userID != "" {
+		if err := s.authorize(ctx, userID, req.ChildID); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkLimits(req.Title, req.Content, req.Tags, req.MediaIDs); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 
+	occurredAt := now
+	if req.OccurredAt != nil {
+		occurrence.Adjust(ctx, "occurred_at", req.OccurredAt, now)
+		if err := occurrence.Validate(*req.OccurredAt, now); err != nil {
+			return nil, err
+		}
+		occurredAt = *req.OccurredAt
+	}
+
 	note := &Note{
-		ID:        generateID(),
-		ChildID:   req.ChildID,
-		AuthorID:  userID,
-		Title:     req.Title,
-		Content:   req.Content,
-		Tags:      req.Tags,
-		Pinned:    req.Pinned,
-		CreatedAt: now,
-		UpdatedAt: now,
-		SyncedAt:  &now,
+		ID:         ids.New(),
+		ChildID:    req.ChildID,
+		AuthorID:   userID,
+		Title:      req.Title,
+		Content:    req.Content,
+		Tags:       req.Tags,
+		Pinned:     req.Pinned,
+		Private:    req.Private,
+		OccurredAt: occurredAt,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		SyncedAt:   &now,
+		MediaIDs:   req.MediaIDs,
 	}
 
 	if err := s.repo.Create(ctx, note); err != nil {
@@ -49,25 +118,116 @@ func (s *service) Create(ctx context.Context, userID string, req *CreateNoteRequ
 	return note, nil
 }
 
-func (s *service) Get(ctx context.Context, id string) (*Note, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *service) Get(ctx context.Context, userID, id string) (*Note, error) {
+	note, err := s.repo.GetByID(ctx, id)
+	if err != nil || note == nil {
+		return note, err
+	}
+	if err := s.authorize(ctx, userID, note.ChildID); err != nil {
+		return nil, err
+	}
+
+	notes := []Note{*note}
+	if err := s.attachSeenBy(ctx, notes); err != nil {
+		return nil, err
+	}
+	return &notes[0], nil
+}
+
+func (s *service) GetVisible(ctx context.Context, id, userID string) (*Note, error) {
+	note, err := s.Get(ctx, userID, id)
+	if err != nil || note == nil || !note.VisibleTo(userID) {
+		return nil, err
+	}
+	return note, nil
 }
 
 func (s *service) List(ctx context.Context, filter *NoteFilter) ([]Note, error) {
-	return s.repo.List(ctx, filter)
+	viewer, err := s.viewer(ctx, filter.ViewerID, filter.ChildID)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if notes, err = visibility.Filter(ctx, viewer, notes, childOf); err != nil {
+		return nil, err
+	}
+	if err := s.attachSeenBy(ctx, notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
 }
 
-func (s *service) Update(ctx context.Context, id string, req *UpdateNoteRequest) (*Note, error) {
+// streamBatch is how many notes Stream reads before looking up their read
+// receipts in one query
+const streamBatch = 500
+
+func (s *service) Stream(ctx context.Context, filter *NoteFilter) iter.Seq2[Note, error] {
+	return func(yield func(Note, error) bool) {
+		batch := make([]Note, 0, streamBatch)
+
+		// flush reports whether the caller wants more
+		flush := func() bool {
+			if err := s.attachSeenBy(ctx, batch); err != nil {
+				yield(Note{}, err)
+				return false
+			}
+			for _, n := range batch {
+				if !yield(n, nil) {
+					return false
+				}
+			}
+			batch = batch[:0]
+			return true
+		}
+
+		viewer, err := s.viewer(ctx, filter.ViewerID, filter.ChildID)
+		if err != nil {
+			yield(Note{}, err)
+			return
+		}
+		for n, err := range visibility.FilterSeq(ctx, viewer, s.repo.Stream(ctx, filter), childOf) {
+			if err != nil {
+				yield(Note{}, err)
+				return
+			}
+			batch = append(batch, n)
+			if len(batch) == streamBatch && !flush() {
+				return
+			}
+		}
+		flush()
+	}
+}
+
+func (s *service) Update(ctx context.Context, userID, id string, req *UpdateNoteRequest) (*Note, error) {
+	if err := checkLimits(req.Title, req.Content, req.Tags, nil); err != nil {
+		return nil, err
+	}
+
 	note, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if note == nil {
-		return nil, fmt.Errorf("note not found")
+		return nil, ErrNoteNotFound
+	}
+	if err := s.authorize(ctx, userID, note.ChildID); err != nil {
+		return nil, err
 	}
 
 	now := time.Now()
 
+	if req.OccurredAt != nil {
+		occurrence.Adjust(ctx, "occurred_at", req.OccurredAt, now)
+		if err := occurrence.Validate(*req.OccurredAt, now); err != nil {
+			return nil, err
+		}
+		note.OccurredAt = *req.OccurredAt
+	}
+
 	note.Title = req.Title
 	note.Content = req.Content
 	note.Tags = req.Tags
@@ -82,8 +242,23 @@ func (s *service) Update(ctx context.Context, id string, req *UpdateNoteRequest)
 	return note, nil
 }
 
-func (s *service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	note, err := s.repo.GetByID(ctx, id)
+	if err != nil || note == nil {
+		return err
+	}
+	if err := s.authorize(ctx, userID, note.ChildID); err != nil {
+		return err
+	}
+	if s.tombstones == nil {
+		return s.repo.Delete(ctx, id)
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.tombstones.Record(ctx, delta.EntityNote, id, note.ChildID, time.Now())
+	})
 }
 
 func (s *service) Pin(ctx context.Context, id string, pinned bool) error {
@@ -92,7 +267,7 @@ func (s *service) Pin(ctx context.Context, id string, pinned bool) error {
 		return err
 	}
 	if note == nil {
-		return fmt.Errorf("note not found")
+		return ErrNoteNotFound
 	}
 
 	now := time.Now()
@@ -107,12 +282,117 @@ func (s *service) Pin(ctx context.Context, id string, pinned bool) error {
 	return nil
 }
 
-func (s *service) Search(ctx context.Context, childID, query string) ([]Note, error) {
-	return s.repo.Search(ctx, childID, query)
+func (s *service) Search(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
+	viewer, err := s.viewer(ctx, viewerID, childID)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.repo.Search(ctx, childID, query, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if notes, err = visibility.Filter(ctx, viewer, notes, childOf); err != nil {
+		return nil, err
+	}
+	if err := s.attachSeenBy(ctx, notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+func (s *service) MarkSeen(ctx context.Context, id, userID string) error {
+	note, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if note == nil {
+		return ErrNoteNotFound
+	}
+
+	if err := s.repo.MarkSeen(ctx, id, userID, time.Now()); err != nil {
+		return fmt.Errorf("failed to mark note seen: %w", err)
+	}
+
+	return nil
+}
+
+func (s *service) Authorize(ctx context.Context, userID, id string) error {
+	note, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	// Another member's private note is reported as missing, not forbidden
+	if note == nil || !note.VisibleTo(userID) {
+		return ErrNoteNotFound
+	}
+	return s.authorize(ctx, userID, note.ChildID)
+}
+
+func (s *service) SetPrivate(ctx context.Context, userID, id string, private bool) error {
+	note, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if note == nil || !note.VisibleTo(userID) {
+		return ErrNoteNotFound
+	}
+	if err := s.authorize(ctx, userID, note.ChildID); err != nil {
+		return err
+	}
+	if note.AuthorID != userID {
+		return ErrNotAuthor
+	}
+
+	now := time.Now()
+	note.Private = private
+	note.UpdatedAt = now
+	note.SyncedAt = &now
+
+	if err := s.repo.Update(ctx, note); err != nil {
+		return fmt.Errorf("failed to update note: %w", err)
+	}
+
+	return nil
+}
+
+// attachSeenBy fills in the readers of each note with a single query
+func (s *service) attachSeenBy(ctx context.Context, notes []Note) error {
+	ids := make([]string, len(notes))
+	for i := range notes {
+		ids[i] = notes[i].ID
+	}
+
+	seenBy, err := s.repo.GetSeenBy(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load read receipts: %w", err)
+	}
+
+	for i := range notes {
+		notes[i].SeenBy = seenBy[notes[i].ID]
+		if notes[i].SeenBy == nil {
+			notes[i].SeenBy = []NoteSeen{}
+		}
+	}
+	return nil
 }
 
-func generateID() string {
-	b := make([]byte, 16)
-	rand.Read(b) //nolint:errcheck // crypto/rand.Read rarely fails
-	return hex.EncodeToString(b)
+// checkLimits holds a note to the configured limits on its text, tags and
+// attachments
+func checkLimits(title, content string, tags, mediaIDs []string) error {
+	l := limits.Get()
+	if err := limits.Length("title", title, l.NameLength); err != nil {
+		return err
+	}
+	if err := limits.Length("content", content, l.TextLength); err != nil {
+		return err
+	}
+	if err := limits.Items("tags", len(tags), l.TagsPerNote); err != nil {
+		return err
+	}
+	for i, tag := range tags {
+		if err := limits.Length(fmt.Sprintf("tags[%d]", i), tag, l.NameLength); err != nil {
+			return err
+		}
+	}
+	return limits.Items("media_ids", len(mediaIDs), l.AttachmentsPerRecord)
 }
@@ -3,9 +3,19 @@ package notes
 import (
 	"context"
 	"errors"
+	"fmt"
+	"iter"
 	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
 // mockRepository is a test double for Repository
@@ -14,11 +24,13 @@ type mockRepository struct {
 	createErr error
 	updateErr error
 	deleteErr error
+	seen      map[string][]NoteSeen
 }
 
 func newMockRepository() *mockRepository {
 	return &mockRepository{
 		notes: make(map[string]*Note),
+		seen:  make(map[string][]NoteSeen),
 	}
 }
 
@@ -42,6 +54,9 @@ func (m *mockRepository) List(ctx context.Context, filter *NoteFilter) ([]Note,
 		if filter.PinnedOnly && !note.Pinned {
 			continue
 		}
+		if filter.ViewerID != "" && !note.VisibleTo(filter.ViewerID) {
+			continue
+		}
 		if len(filter.Tags) > 0 {
 			hasTag := false
 			for _, filterTag := range filter.Tags {
@@ -58,6 +73,17 @@ func (m *mockRepository) List(ctx context.Context, filter *NoteFilter) ([]Note,
 	return result, nil
 }
 
+func (m *mockRepository) Stream(ctx context.Context, filter *NoteFilter) iter.Seq2[Note, error] {
+	notes, _ := m.List(ctx, filter)
+	return func(yield func(Note, error) bool) {
+		for _, n := range notes {
+			if !yield(n, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (m *mockRepository) Create(ctx context.Context, note *Note) error {
 	if m.createErr != nil {
 		return m.createErr
@@ -82,13 +108,36 @@ func (m *mockRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *mockRepository) Search(ctx context.Context, childID, query string) ([]Note, error) {
+func (m *mockRepository) MarkSeen(ctx context.Context, noteID, userID string, seenAt time.Time) error {
+	for _, seen := range m.seen[noteID] {
+		if seen.UserID == userID {
+			return nil
+		}
+	}
+	m.seen[noteID] = append(m.seen[noteID], NoteSeen{UserID: userID, SeenAt: seenAt})
+	return nil
+}
+
+func (m *mockRepository) GetSeenBy(ctx context.Context, noteIDs []string) (map[string][]NoteSeen, error) {
+	result := make(map[string][]NoteSeen)
+	for _, id := range noteIDs {
+		if seen, ok := m.seen[id]; ok {
+			result[id] = seen
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) Search(ctx context.Context, childID, query, viewerID string) ([]Note, error) {
 	var result []Note
 	queryLower := strings.ToLower(query)
 	for _, note := range m.notes {
 		if childID != "" && note.ChildID != childID {
 			continue
 		}
+		if !note.VisibleTo(viewerID) {
+			continue
+		}
 		// Simple search in title and content
 		if strings.Contains(strings.ToLower(note.Title), queryLower) ||
 			strings.Contains(strings.ToLower(note.Content), queryLower) {
@@ -144,6 +193,78 @@ func TestService_Create(t *testing.T) {
 	}
 }
 
+func TestService_Create_Backdated(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	occurredAt := time.Now().AddDate(0, 0, -3)
+	req := &CreateNoteRequest{
+		ChildID:    "child-123",
+		Content:    "Rolled over for the first time",
+		OccurredAt: &occurredAt,
+	}
+
+	note, err := svc.Create(context.Background(), "user-123", req)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if !note.OccurredAt.Equal(occurredAt) {
+		t.Errorf("Create() OccurredAt = %v, want %v", note.OccurredAt, occurredAt)
+	}
+	if !note.CreatedAt.After(occurredAt) {
+		t.Errorf("Create() CreatedAt = %v, want the time the note was written", note.CreatedAt)
+	}
+}
+
+func TestService_Create_FutureOccurredAt(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	occurredAt := time.Now().Add(24 * time.Hour)
+	req := &CreateNoteRequest{
+		ChildID:    "child-123",
+		Content:    "Not yet",
+		OccurredAt: &occurredAt,
+	}
+
+	_, err := svc.Create(context.Background(), "user-123", req)
+	if !errors.Is(err, occurrence.ErrInvalid) {
+		t.Errorf("Create() error = %v, want %v", err, occurrence.ErrInvalid)
+	}
+	if len(repo.notes) != 0 {
+		t.Error("Create() should not store a note with an invalid occurrence time")
+	}
+}
+
+func TestService_Create_OverLimits(t *testing.T) {
+	l := limits.Get()
+	tests := []struct {
+		name string
+		req  CreateNoteRequest
+	}{
+		{"title", CreateNoteRequest{Title: strings.Repeat("t", l.NameLength+1), Content: "x"}},
+		{"content", CreateNoteRequest{Content: strings.Repeat("c", l.TextLength+1)}},
+		{"tag count", CreateNoteRequest{Content: "x", Tags: make([]string, l.TagsPerNote+1)}},
+		{"tag length", CreateNoteRequest{Content: "x", Tags: []string{"ok", strings.Repeat("g", l.NameLength+1)}}},
+		{"media", CreateNoteRequest{Content: "x", MediaIDs: make([]string, l.AttachmentsPerRecord+1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			svc := NewService(repo)
+
+			tt.req.ChildID = "child-123"
+			if _, err := svc.Create(context.Background(), "user-123", &tt.req); !errors.Is(err, limits.ErrExceeded) {
+				t.Errorf("Create() error = %v, want %v", err, limits.ErrExceeded)
+			}
+			if len(repo.notes) != 0 {
+				t.Error("Create() should not store a note over the limits")
+			}
+		})
+	}
+}
+
 func TestService_Create_RepoError(t *testing.T) {
 	repo := newMockRepository()
 	repo.createErr = errors.New("database error")
@@ -170,7 +291,7 @@ func TestService_Get(t *testing.T) {
 	}
 	created, _ := svc.Create(context.Background(), "user-123", req)
 
-	note, err := svc.Get(context.Background(), created.ID)
+	note, err := svc.Get(context.Background(), "user-123", created.ID)
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -188,7 +309,7 @@ func TestService_Get_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	note, err := svc.Get(context.Background(), "non-existent")
+	note, err := svc.Get(context.Background(), "user-123", "non-existent")
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -198,6 +319,22 @@ func TestService_Get_NotFound(t *testing.T) {
 	}
 }
 
+func TestService_GetVisible(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	created, _ := svc.Create(ctx, "user-1", &CreateNoteRequest{ChildID: "child-123", Content: "Just for me", Private: true})
+
+	if note, err := svc.GetVisible(ctx, created.ID, "user-1"); err != nil || note == nil {
+		t.Errorf("GetVisible() by the author = %v, %v", note, err)
+	}
+	// Another member's private note reads as missing
+	if note, err := svc.GetVisible(ctx, created.ID, "user-2"); err != nil || note != nil {
+		t.Errorf("GetVisible() by another member = %v, %v; want nil, nil", note, err)
+	}
+}
+
 func TestService_List(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -337,7 +474,7 @@ func TestService_Update(t *testing.T) {
 		Pinned:  true,
 	}
 
-	updated, err := svc.Update(context.Background(), created.ID, updateReq)
+	updated, err := svc.Update(context.Background(), "user-123", created.ID, updateReq)
 	if err != nil {
 		t.Fatalf("Update() error = %v", err)
 	}
@@ -368,7 +505,7 @@ func TestService_Update_NotFound(t *testing.T) {
 		Content: "Updated content",
 	}
 
-	_, err := svc.Update(context.Background(), "non-existent", updateReq)
+	_, err := svc.Update(context.Background(), "user-123", "non-existent", updateReq)
 	if err == nil {
 		t.Error("Update() should return error for non-existent note")
 	}
@@ -384,17 +521,48 @@ func TestService_Delete(t *testing.T) {
 	}
 	created, _ := svc.Create(context.Background(), "user-123", req)
 
-	err := svc.Delete(context.Background(), created.ID)
+	err := svc.Delete(context.Background(), "user-123", created.ID)
 	if err != nil {
 		t.Fatalf("Delete() error = %v", err)
 	}
 
-	note, _ := svc.Get(context.Background(), created.ID)
+	note, _ := svc.Get(context.Background(), "user-123", created.ID)
 	if note != nil {
 		t.Error("Delete() should remove the note")
 	}
 }
 
+// mockTombstones is a test double for delta.Tombstones
+type mockTombstones struct {
+	delta.Tombstones
+	deleted map[string]string // entity ID -> scope ID
+}
+
+func (m *mockTombstones) Record(ctx context.Context, entityType delta.EntityType, entityID, scopeID string, at time.Time) error {
+	if entityType != delta.EntityNote {
+		return errors.New("unexpected entity type")
+	}
+	m.deleted[entityID] = scopeID
+	return nil
+}
+
+func TestService_Delete_RecordsTombstone(t *testing.T) {
+	repo := newMockRepository()
+	tombstones := &mockTombstones{deleted: make(map[string]string)}
+	svc := NewService(repo, WithTombstones(tombstones, db.NoTx))
+
+	created, _ := svc.Create(context.Background(), "user-123", &CreateNoteRequest{
+		ChildID: "child-123",
+		Content: "Test note",
+	})
+	if err := svc.Delete(context.Background(), "user-123", created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if tombstones.deleted[created.ID] != "child-123" {
+		t.Errorf("tombstones = %v, want %s deleted from child-123", tombstones.deleted, created.ID)
+	}
+}
+
 func TestService_Pin(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -412,7 +580,7 @@ func TestService_Pin(t *testing.T) {
 		t.Fatalf("Pin(true) error = %v", err)
 	}
 
-	note, _ := svc.Get(context.Background(), created.ID)
+	note, _ := svc.Get(context.Background(), "user-123", created.ID)
 	if !note.Pinned {
 		t.Error("Pin(true) should set Pinned to true")
 	}
@@ -423,7 +591,7 @@ func TestService_Pin(t *testing.T) {
 		t.Fatalf("Pin(false) error = %v", err)
 	}
 
-	note, _ = svc.Get(context.Background(), created.ID)
+	note, _ = svc.Get(context.Background(), "user-123", created.ID)
 	if note.Pinned {
 		t.Error("Pin(false) should set Pinned to false")
 	}
@@ -466,7 +634,7 @@ func TestService_Search(t *testing.T) {
 	svc.Create(context.Background(), "user-123", note3)
 
 	// Search for "steps"
-	results, err := svc.Search(context.Background(), "child-123", "steps")
+	results, err := svc.Search(context.Background(), "child-123", "steps", "user-123")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -488,7 +656,7 @@ func TestService_Search_TitleMatch(t *testing.T) {
 	svc.Create(context.Background(), "user-123", req)
 
 	// Search should match title
-	results, err := svc.Search(context.Background(), "child-123", "milestone")
+	results, err := svc.Search(context.Background(), "child-123", "milestone", "user-123")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -510,7 +678,7 @@ func TestService_Search_CaseInsensitive(t *testing.T) {
 	svc.Create(context.Background(), "user-123", req)
 
 	// Search should be case insensitive
-	results, err := svc.Search(context.Background(), "child-123", "uppercase")
+	results, err := svc.Search(context.Background(), "child-123", "uppercase", "user-123")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -532,7 +700,7 @@ func TestService_Search_NoResults(t *testing.T) {
 	svc.Create(context.Background(), "user-123", req)
 
 	// Search for non-existent term
-	results, err := svc.Search(context.Background(), "child-123", "nonexistent")
+	results, err := svc.Search(context.Background(), "child-123", "nonexistent", "user-123")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -561,7 +729,7 @@ func TestService_Search_ChildFilter(t *testing.T) {
 	svc.Create(context.Background(), "user-123", req2)
 
 	// Search should filter by child
-	results, err := svc.Search(context.Background(), "child-123", "matching")
+	results, err := svc.Search(context.Background(), "child-123", "matching", "user-123")
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -570,3 +738,244 @@ func TestService_Search_ChildFilter(t *testing.T) {
 		t.Errorf("Search() with child filter returned %d notes, want 1", len(results))
 	}
 }
+
+func TestService_MarkSeen(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	repo.notes["note-1"] = &Note{ID: "note-1", ChildID: "child-1", Content: "Bedtime is 7pm", Pinned: true}
+
+	if err := svc.MarkSeen(context.Background(), "note-1", "user-1"); err != nil {
+		t.Fatalf("MarkSeen() error = %v", err)
+	}
+	// Seeing a note twice keeps a single receipt
+	if err := svc.MarkSeen(context.Background(), "note-1", "user-1"); err != nil {
+		t.Fatalf("MarkSeen() error = %v", err)
+	}
+
+	note, err := svc.Get(context.Background(), "user-123", "note-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(note.SeenBy) != 1 || note.SeenBy[0].UserID != "user-1" {
+		t.Errorf("Get() SeenBy = %+v, want one receipt from user-1", note.SeenBy)
+	}
+}
+
+func TestService_MarkSeen_NotFound(t *testing.T) {
+	svc := NewService(newMockRepository())
+
+	err := svc.MarkSeen(context.Background(), "missing", "user-1")
+	if err == nil || err.Error() != "note not found" {
+		t.Errorf("MarkSeen() error = %v, want note not found", err)
+	}
+}
+
+func TestService_List_IncludesSeenBy(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	repo.notes["note-1"] = &Note{ID: "note-1", ChildID: "child-1"}
+	repo.notes["note-2"] = &Note{ID: "note-2", ChildID: "child-1"}
+	repo.seen["note-1"] = []NoteSeen{{UserID: "user-2"}}
+
+	notes, err := svc.List(context.Background(), &NoteFilter{ChildID: "child-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	for _, n := range notes {
+		if n.SeenBy == nil {
+			t.Errorf("note %s SeenBy should be an empty list, not nil", n.ID)
+		}
+		if n.ID == "note-1" && len(n.SeenBy) != 1 {
+			t.Errorf("note-1 SeenBy = %+v, want 1 receipt", n.SeenBy)
+		}
+	}
+}
+
+func TestService_Stream_IncludesSeenBy(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	// One more than a batch, so the last note's receipts come from a second
+	// lookup
+	total := streamBatch + 1
+	for i := range total {
+		id := fmt.Sprintf("note-%03d", i)
+		repo.notes[id] = &Note{ID: id, ChildID: "child-1"}
+	}
+	repo.seen["note-000"] = []NoteSeen{{UserID: "user-2"}}
+	repo.seen[fmt.Sprintf("note-%03d", streamBatch)] = []NoteSeen{{UserID: "user-3"}}
+
+	n, receipts := 0, 0
+	for note, err := range svc.Stream(context.Background(), &NoteFilter{ChildID: "child-1"}) {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		if note.SeenBy == nil {
+			t.Fatalf("note %s SeenBy should be an empty list, not nil", note.ID)
+		}
+		n++
+		receipts += len(note.SeenBy)
+	}
+	if n != total || receipts != 2 {
+		t.Errorf("Stream() yielded %d notes with %d receipts, want %d with 2", n, receipts, total)
+	}
+}
+
+func TestService_Create_Private(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	note, err := svc.Create(context.Background(), "user-1", &CreateNoteRequest{ChildID: "child-1", Content: "Just for me", Private: true})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !repo.notes[note.ID].Private {
+		t.Error("Create() should store the note as private")
+	}
+
+	others, err := svc.List(context.Background(), &NoteFilter{ChildID: "child-1", ViewerID: "user-2"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(others) != 0 {
+		t.Errorf("List() for another member returned %d notes, want 0", len(others))
+	}
+
+	mine, err := svc.List(context.Background(), &NoteFilter{ChildID: "child-1", ViewerID: "user-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(mine) != 1 {
+		t.Errorf("List() for the author returned %d notes, want 1", len(mine))
+	}
+}
+
+func TestService_Authorize(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	repo.notes["private"] = &Note{ID: "private", AuthorID: "user-1", Private: true}
+	repo.notes["shared"] = &Note{ID: "shared", AuthorID: "user-1"}
+
+	tests := []struct {
+		name   string
+		userID string
+		id     string
+		want   error
+	}{
+		{"author sees private note", "user-1", "private", nil},
+		{"other member cannot see private note", "user-2", "private", ErrNoteNotFound},
+		{"other member sees shared note", "user-2", "shared", nil},
+		{"missing note", "user-1", "missing", ErrNoteNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.Authorize(context.Background(), tt.userID, tt.id); !errors.Is(err, tt.want) {
+				t.Errorf("Authorize() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_SetPrivate(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	repo.notes["note-1"] = &Note{ID: "note-1", AuthorID: "user-1"}
+
+	if err := svc.SetPrivate(context.Background(), "user-2", "note-1", true); !errors.Is(err, ErrNotAuthor) {
+		t.Errorf("SetPrivate() by another member error = %v, want ErrNotAuthor", err)
+	}
+	if repo.notes["note-1"].Private {
+		t.Error("SetPrivate() by another member should not change the note")
+	}
+
+	if err := svc.SetPrivate(context.Background(), "user-1", "note-1", true); err != nil {
+		t.Fatalf("SetPrivate() error = %v", err)
+	}
+	if !repo.notes["note-1"].Private {
+		t.Error("SetPrivate() should mark the note private")
+	}
+
+	if err := svc.SetPrivate(context.Background(), "user-2", "note-1", false); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("SetPrivate() on a hidden note error = %v, want ErrNoteNotFound", err)
+	}
+}
+
+// mockVisibilityService hides child-hidden's notes, and puts child-other in
+// another family
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) CheckChild(ctx context.Context, userID, childID string, recordType visibility.RecordType) error {
+	switch childID {
+	case "child-hidden":
+		return visibility.ErrHidden
+	case "child-other":
+		return family.ErrNotMember
+	}
+	return nil
+}
+
+func TestService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewService(repo, WithVisibility(&mockVisibilityService{}))
+	for _, childID := range []string{"child-123", "child-hidden", "child-other"} {
+		repo.notes[childID] = &Note{ID: childID, ChildID: childID, AuthorID: "user-1", Content: "first tooth"}
+	}
+
+	if _, err := svc.Get(ctx, "user-2", "child-123"); err != nil {
+		t.Errorf("Get() visible error = %v", err)
+	}
+	if _, err := svc.Get(ctx, "user-2", "child-hidden"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Get() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Get(ctx, "user-2", "child-other"); !errors.Is(err, family.ErrNotMember) {
+		t.Errorf("Get() other family error = %v, want ErrNotMember", err)
+	}
+	if err := svc.Authorize(ctx, "user-2", "child-hidden"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Authorize() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Update(ctx, "user-2", "child-hidden", &UpdateNoteRequest{Content: "changed"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Update() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Delete(ctx, "user-2", "child-hidden"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Delete() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Create(ctx, "user-2", &CreateNoteRequest{ChildID: "child-hidden", Content: "first tooth"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Create() hidden error = %v, want ErrHidden", err)
+	}
+	if repo.notes["child-hidden"] == nil {
+		t.Error("Delete() removed a hidden note")
+	}
+
+	list, err := svc.List(ctx, &NoteFilter{ViewerID: "user-2"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ChildID != "child-123" {
+		t.Errorf("List() = %+v, want only child-123's note", list)
+	}
+	if _, err := svc.List(ctx, &NoteFilter{ChildID: "child-hidden", ViewerID: "user-2"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("List() hidden child error = %v, want ErrHidden", err)
+	}
+	found, err := svc.Search(ctx, "", "tooth", "user-2")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(found) != 1 {
+		t.Errorf("Search() found %d notes, want 1", len(found))
+	}
+	var streamed int
+	for _, err := range svc.Stream(ctx, &NoteFilter{ViewerID: "user-2"}) {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		streamed++
+	}
+	if streamed != 1 {
+		t.Errorf("Stream() yielded %d notes, want 1", streamed)
+	}
+}
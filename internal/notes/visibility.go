@@ -0,0 +1,32 @@
+package notes
+
+import (
+	"context"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// WithVisibility applies family visibility settings, so members cannot see or
+// change notes hidden from them
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+// authorize checks that userID may see the child's notes
+func (s *service) authorize(ctx context.Context, userID, childID string) error {
+	if s.visibility == nil {
+		return nil
+	}
+	return s.visibility.CheckChild(ctx, userID, childID, visibility.RecordNotes)
+}
+
+// viewer filters a list for viewerID, if there is one
+func (s *service) viewer(ctx context.Context, viewerID, childID string) (*visibility.Viewer, error) {
+	return visibility.ViewerFor(ctx, s.visibility, viewerID, childID, visibility.RecordNotes)
+}
+
+func childOf(n Note) string {
+	return n.ChildID
+}
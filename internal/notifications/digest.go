@@ -0,0 +1,115 @@
+package notifications
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/ids"
+)
+
+// DigestRules sets, per event type, how long to hold events back so that a
+// burst goes out as one summary. Types without a rule are sent at once.
+type DigestRules map[EventType]time.Duration
+
+// DigestData is carried by a summary event in place of the events it replaces
+type DigestData struct {
+	Count  int     `json:"count"`
+	Events []Event `json:"events"`
+}
+
+// batch collects the events for one audience, child and type until its
+// window closes
+type batch struct {
+	userIDs []string
+	events  []Event
+}
+
+type digester struct {
+	rules DigestRules
+	send  func(userIDs []string, event Event)
+
+	mu      sync.Mutex
+	batches map[string]*batch
+}
+
+func newDigester(rules DigestRules, send func(userIDs []string, event Event)) *digester {
+	return &digester{
+		rules:   rules,
+		send:    send,
+		batches: make(map[string]*batch),
+	}
+}
+
+// add holds the event if its type has a window and reports whether it did.
+// The window starts with the first event of a batch, so a steady stream is
+// still delivered once per window rather than never.
+func (d *digester) add(userIDs []string, event Event) bool {
+	window := d.rules[event.Type]
+	if window <= 0 {
+		return false
+	}
+
+	key := batchKey(userIDs, event)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if b, ok := d.batches[key]; ok {
+		b.events = append(b.events, event)
+		return true
+	}
+
+	d.batches[key] = &batch{userIDs: userIDs, events: []Event{event}}
+	time.AfterFunc(window, func() { d.flush(key) })
+	return true
+}
+
+func (d *digester) flush(key string) {
+	d.mu.Lock()
+	b, ok := d.batches[key]
+	delete(d.batches, key)
+	d.mu.Unlock()
+
+	if ok {
+		d.send(b.userIDs, summarise(b.events))
+	}
+}
+
+func batchKey(userIDs []string, event Event) string {
+	audience := "*"
+	if userIDs != nil {
+		sorted := slices.Clone(userIDs)
+		slices.Sort(sorted)
+		audience = strings.Join(sorted, ",")
+	}
+	return audience + "|" + event.ChildID + "|" + string(event.Type)
+}
+
+// summarise turns a batch into the event to send. A lone event goes out
+// unchanged; several become one, e.g. "3 new notifications for Emma", that
+// keeps their type and child so preferences still apply to it.
+func summarise(events []Event) Event {
+	if len(events) == 1 {
+		return events[0]
+	}
+
+	last := events[len(events)-1]
+	title := fmt.Sprintf("%d new notifications", len(events))
+	if last.ChildName != "" {
+		title += " for " + last.ChildName
+	}
+
+	return Event{
+		ID:        ids.New(),
+		Type:      last.Type,
+		Title:     title,
+		Message:   last.Message,
+		ChildID:   last.ChildID,
+		ChildName: last.ChildName,
+		Timestamp: last.Timestamp,
+		Data:      DigestData{Count: len(events), Events: events},
+	}
+}
@@ -0,0 +1,121 @@
+package notifications
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type sent struct {
+	userIDs []string
+	event   Event
+}
+
+type recorder struct {
+	mu   sync.Mutex
+	sent []sent
+}
+
+func (r *recorder) send(userIDs []string, event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, sent{userIDs: userIDs, event: event})
+}
+
+func (r *recorder) all() []sent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]sent(nil), r.sent...)
+}
+
+func TestDigester_PassesThroughTypesWithoutRule(t *testing.T) {
+	rec := &recorder{}
+	d := newDigester(DigestRules{EventSleepInsight: time.Minute}, rec.send)
+
+	if d.add(nil, Event{Type: EventMedicationDue}) {
+		t.Error("add() should not hold events without a rule")
+	}
+}
+
+func TestDigester_CoalescesBurst(t *testing.T) {
+	rec := &recorder{}
+	d := newDigester(DigestRules{EventSleepInsight: 20 * time.Millisecond}, rec.send)
+
+	for _, id := range []string{"a", "b", "c"} {
+		if !d.add(nil, Event{ID: id, Type: EventSleepInsight, ChildID: "child-1", ChildName: "Emma", Message: id}) {
+			t.Fatalf("add() should hold event %s", id)
+		}
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	got := rec.all()
+	if len(got) != 1 {
+		t.Fatalf("expected 1 summary, got %d", len(got))
+	}
+
+	summary := got[0].event
+	if summary.Title != "3 new notifications for Emma" {
+		t.Errorf("summary Title = %q, want %q", summary.Title, "3 new notifications for Emma")
+	}
+	if summary.Type != EventSleepInsight || summary.ChildID != "child-1" {
+		t.Errorf("summary should keep type and child, got %s / %s", summary.Type, summary.ChildID)
+	}
+	data, ok := summary.Data.(DigestData)
+	if !ok || data.Count != 3 || len(data.Events) != 3 {
+		t.Errorf("summary Data = %+v, want the 3 events", summary.Data)
+	}
+	if summary.Message != "c" {
+		t.Errorf("summary Message = %q, want the latest", summary.Message)
+	}
+}
+
+func TestDigester_SingleEventUnchanged(t *testing.T) {
+	rec := &recorder{}
+	d := newDigester(DigestRules{EventSleepInsight: 10 * time.Millisecond}, rec.send)
+
+	d.add([]string{"user-1"}, Event{ID: "only", Type: EventSleepInsight})
+	time.Sleep(40 * time.Millisecond)
+
+	got := rec.all()
+	if len(got) != 1 || got[0].event.ID != "only" {
+		t.Fatalf("expected the original event, got %+v", got)
+	}
+	if len(got[0].userIDs) != 1 || got[0].userIDs[0] != "user-1" {
+		t.Errorf("event sent to %v, want [user-1]", got[0].userIDs)
+	}
+}
+
+func TestDigester_SeparatesChildrenAndAudiences(t *testing.T) {
+	rec := &recorder{}
+	d := newDigester(DigestRules{EventSleepInsight: 10 * time.Millisecond}, rec.send)
+
+	d.add(nil, Event{Type: EventSleepInsight, ChildID: "child-1"})
+	d.add(nil, Event{Type: EventSleepInsight, ChildID: "child-2"})
+	d.add([]string{"user-1"}, Event{Type: EventSleepInsight, ChildID: "child-1"})
+	time.Sleep(40 * time.Millisecond)
+
+	if got := rec.all(); len(got) != 3 {
+		t.Errorf("expected 3 separate deliveries, got %d", len(got))
+	}
+}
+
+func TestHub_Digest(t *testing.T) {
+	hub := NewHub()
+	hub.SetDigest(DigestRules{EventMedicationDue: 20 * time.Millisecond})
+	go hub.Run()
+
+	time.Sleep(10 * time.Millisecond)
+
+	client := &Client{UserID: "user-1", Send: make(chan []byte, 256)}
+	hub.Register(client)
+	time.Sleep(10 * time.Millisecond)
+
+	hub.Broadcast(Event{ID: "1", Type: EventMedicationDue, ChildID: "child-1"})
+	hub.Broadcast(Event{ID: "2", Type: EventMedicationDue, ChildID: "child-1"})
+	hub.Broadcast(Event{ID: "3", Type: EventAppointmentSoon, ChildID: "child-1"})
+	time.Sleep(60 * time.Millisecond)
+
+	if got := len(client.Send); got != 2 {
+		t.Errorf("client received %d messages, want 2 (one immediate, one summary)", got)
+	}
+}
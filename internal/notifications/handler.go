@@ -4,8 +4,9 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/ninenine/babytrack/internal/ids"
+
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 // Handler handles SSE notification endpoints
@@ -52,7 +53,7 @@ func (h *Handler) Stream(c *gin.Context) {
 	log.Printf("[SSE] Client connected: %s", client.UserID)
 
 	// Send initial connection event (SSE writes - connection failures handled by context cancellation)
-	_, _ = fmt.Fprintf(c.Writer, "id: %s\n", uuid.New().String())       //nolint:errcheck
+	_, _ = fmt.Fprintf(c.Writer, "id: %s\n", ids.New())                 //nolint:errcheck
 	_, _ = c.Writer.WriteString("event: connected\n")                   //nolint:errcheck
 	_, _ = c.Writer.WriteString("data: {\"status\":\"connected\"}\n\n") //nolint:errcheck
 	c.Writer.Flush()
@@ -70,9 +71,9 @@ func (h *Handler) Stream(c *gin.Context) {
 				return
 			}
 			// SSE writes - connection failures handled by context cancellation
-			_, _ = fmt.Fprintf(c.Writer, "id: %s\n", uuid.New().String()) //nolint:errcheck
-			_, _ = c.Writer.WriteString("event: notification\n")          //nolint:errcheck
-			_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", data)            //nolint:errcheck
+			_, _ = fmt.Fprintf(c.Writer, "id: %s\n", ids.New())  //nolint:errcheck
+			_, _ = c.Writer.WriteString("event: notification\n") //nolint:errcheck
+			_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", data)   //nolint:errcheck
 			c.Writer.Flush()
 		}
 	}
@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,6 +24,7 @@ func setupTestHub() *Hub {
 
 func setupRouter(hub *Hub) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 
 	// Simulated auth middleware
 	router.Use(func(c *gin.Context) {
@@ -1,19 +1,31 @@
 package notifications
 
 import (
-	"encoding/json"
+	"context"
+	"slices"
 	"sync"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/apijson"
+	"github.com/ninenine/babytrack/internal/health"
 )
 
 // EventType represents the type of notification event
 type EventType string
 
 const (
-	EventMedicationDue   EventType = "medication_due"
-	EventVaccinationDue  EventType = "vaccination_due"
-	EventAppointmentSoon EventType = "appointment_soon"
-	EventSleepInsight    EventType = "sleep_insight"
+	EventMedicationDue    EventType = "medication_due"
+	EventVaccinationDue   EventType = "vaccination_due"
+	EventAppointmentSoon  EventType = "appointment_soon"
+	EventSleepInsight     EventType = "sleep_insight"
+	EventPresence         EventType = "presence"
+	EventSleepEnded       EventType = "sleep_ended"
+	EventFeedingInsight   EventType = "feeding_insight"
+	EventOnThisDay        EventType = "on_this_day"
+	EventCareMessage      EventType = "care_message"
+	EventDocumentExpiring EventType = "document_expiring"
+	EventNursingTimer     EventType = "nursing_timer"
+	EventGrowthReminder   EventType = "growth_reminder"
 )
 
 // Event represents a notification event to be sent to clients
@@ -25,6 +37,7 @@ type Event struct {
 	ChildID   string    `json:"childId,omitempty"`
 	ChildName string    `json:"childName,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data,omitempty"`
 }
 
 // Client represents a connected SSE client
@@ -33,15 +46,30 @@ type Client struct {
 	Send   chan []byte
 }
 
+// directEvent is an event for the connections of specific users only
+type directEvent struct {
+	userIDs []string
+	event   Event
+}
+
 // Hub manages all SSE client connections and broadcasts events
 type Hub struct {
 	clients    map[*Client]bool
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan Event
+	direct     chan directEvent
+	filter     Filter
+	digest     *digester
 	mu         sync.RWMutex
 }
 
+// Filter decides whether an event is delivered to a user, e.g. to honour
+// their notification preferences
+type Filter interface {
+	Allow(userID string, event Event) bool
+}
+
 // NewHub creates a new notification hub
 func NewHub() *Hub {
 	return &Hub{
@@ -49,6 +77,7 @@ func NewHub() *Hub {
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		broadcast:  make(chan Event, 100),
+		direct:     make(chan directEvent, 100),
 	}
 }
 
@@ -70,24 +99,64 @@ func (h *Hub) Run() {
 			h.mu.Unlock()
 
 		case event := <-h.broadcast:
-			data, err := json.Marshal(event)
-			if err != nil {
-				continue
-			}
+			h.deliver(event, nil)
 
-			h.mu.RLock()
-			for client := range h.clients {
-				select {
-				case client.Send <- data:
-				default:
-					// Client buffer full, skip
-				}
-			}
-			h.mu.RUnlock()
+		case d := <-h.direct:
+			h.deliver(d.event, d.userIDs)
+		}
+	}
+}
+
+// deliver sends the event to every client, or only to the clients of
+// userIDs when given, that the filter lets it through to
+func (h *Hub) deliver(event Event, userIDs []string) {
+	data, err := apijson.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	h.mu.RLock()
+	recipients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		if userIDs == nil || slices.Contains(userIDs, client.UserID) {
+			recipients = append(recipients, client)
+		}
+	}
+	filter := h.filter
+	h.mu.RUnlock()
+
+	// The filter may read from the database, so it runs outside the lock
+	allowed := make(map[string]bool)
+	for _, client := range recipients {
+		ok, seen := allowed[client.UserID]
+		if !seen {
+			ok = filter == nil || filter.Allow(client.UserID, event)
+			allowed[client.UserID] = ok
+		}
+		if !ok {
+			continue
+		}
+		select {
+		case client.Send <- data:
+		default:
+			// Client buffer full, skip
 		}
 	}
 }
 
+// SetFilter makes the hub consult f before delivering each event to a user
+func (h *Hub) SetFilter(f Filter) {
+	h.mu.Lock()
+	h.filter = f
+	h.mu.Unlock()
+}
+
+// SetDigest coalesces events of the given types into summaries. Call it
+// before the hub starts sending.
+func (h *Hub) SetDigest(rules DigestRules) {
+	h.digest = newDigester(rules, h.enqueue)
+}
+
 // Register adds a new client to the hub
 func (h *Hub) Register(client *Client) {
 	h.register <- client
@@ -100,7 +169,36 @@ func (h *Hub) Unregister(client *Client) {
 
 // Broadcast sends an event to all connected clients
 func (h *Hub) Broadcast(event Event) {
-	h.broadcast <- event
+	if h.digest != nil && h.digest.add(nil, event) {
+		return
+	}
+	h.enqueue(nil, event)
+}
+
+// SendToUsers sends an event to the connected clients of the given users only
+func (h *Hub) SendToUsers(userIDs []string, event Event) {
+	if len(userIDs) == 0 {
+		return
+	}
+	if h.digest != nil && h.digest.add(userIDs, event) {
+		return
+	}
+	h.enqueue(userIDs, event)
+}
+
+// enqueue hands the event to the run loop; nil userIDs means everyone
+func (h *Hub) enqueue(userIDs []string, event Event) {
+	if userIDs == nil {
+		h.broadcast <- event
+		return
+	}
+	h.direct <- directEvent{userIDs: userIDs, event: event}
+}
+
+// HealthCheck reports the hub as degraded while events back up waiting for
+// delivery. Once the queues fill, the services sending events block.
+func (h *Hub) HealthCheck(ctx context.Context) error {
+	return health.Queue(len(h.broadcast)+len(h.direct), cap(h.broadcast)+cap(h.direct))
 }
 
 // ClientCount returns the number of connected clients
@@ -130,6 +130,76 @@ func TestHub_Broadcast(t *testing.T) {
 	}
 }
 
+func TestHub_SendToUsers(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	time.Sleep(10 * time.Millisecond)
+
+	target := &Client{UserID: "user-1", Send: make(chan []byte, 256)}
+	other := &Client{UserID: "user-2", Send: make(chan []byte, 256)}
+	hub.Register(target)
+	hub.Register(other)
+	time.Sleep(10 * time.Millisecond)
+
+	hub.SendToUsers([]string{"user-1"}, Event{ID: "event-123", Type: EventPresence, Timestamp: time.Now()})
+	time.Sleep(10 * time.Millisecond)
+
+	select {
+	case data := <-target.Send:
+		var received Event
+		if err := json.Unmarshal(data, &received); err != nil {
+			t.Fatalf("Failed to unmarshal event: %v", err)
+		}
+		if received.ID != "event-123" {
+			t.Errorf("Target received event ID = %v, want event-123", received.ID)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Target did not receive the event")
+	}
+
+	select {
+	case <-other.Send:
+		t.Error("Other user should not receive a targeted event")
+	default:
+	}
+}
+
+type denyUser string
+
+func (d denyUser) Allow(userID string, event Event) bool {
+	return userID != string(d)
+}
+
+func TestHub_Filter(t *testing.T) {
+	hub := NewHub()
+	hub.SetFilter(denyUser("user-2"))
+	go hub.Run()
+
+	time.Sleep(10 * time.Millisecond)
+
+	allowed := &Client{UserID: "user-1", Send: make(chan []byte, 256)}
+	denied := &Client{UserID: "user-2", Send: make(chan []byte, 256)}
+	hub.Register(allowed)
+	hub.Register(denied)
+	time.Sleep(10 * time.Millisecond)
+
+	hub.Broadcast(Event{ID: "event-123", Type: EventMedicationDue, Timestamp: time.Now()})
+	time.Sleep(10 * time.Millisecond)
+
+	select {
+	case <-allowed.Send:
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Allowed user did not receive the event")
+	}
+
+	select {
+	case <-denied.Send:
+		t.Error("Filtered user should not receive the event")
+	default:
+	}
+}
+
 func TestHub_BroadcastToNoClients(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
@@ -211,6 +281,8 @@ func TestEvent_Types(t *testing.T) {
 		{EventVaccinationDue, "vaccination_due"},
 		{EventAppointmentSoon, "appointment_soon"},
 		{EventSleepInsight, "sleep_insight"},
+		{EventFeedingInsight, "feeding_insight"},
+		{EventOnThisDay, "on_this_day"},
 	}
 
 	for _, tt := range tests {
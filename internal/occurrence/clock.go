@@ -0,0 +1,79 @@
+package occurrence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/warnings"
+)
+
+const (
+	// MinClockOffset is the smallest reported offset that is applied. Anything
+	// less is indistinguishable from network delay.
+	MinClockOffset = 2 * time.Second
+	// MaxClockOffset is the largest offset accepted. A client further out than
+	// this has the wrong date set rather than a drifting clock.
+	MaxClockOffset = 24 * time.Hour
+)
+
+var ErrClockOffset = errors.New("implausible clock offset")
+
+// Normalize clamps a time that is ahead of now by no more than MaxFutureSkew
+// back to now, so a fast clock cannot put records in the future. It reports
+// whether the time was ahead at all. Times further ahead are left for
+// Validate to reject.
+func Normalize(t *time.Time, now time.Time) bool {
+	if t == nil || t.IsZero() || !t.After(now) {
+		return false
+	}
+	if !t.After(now.Add(MaxFutureSkew)) {
+		*t = now
+	}
+	return true
+}
+
+// Adjust normalizes t and, when it was moved back to now, warns the client
+// that the named field was changed
+func Adjust(ctx context.Context, field string, t *time.Time, now time.Time) {
+	if Normalize(t, now) && t.Equal(now) {
+		warnings.Addf(ctx, "%s was ahead of the server clock and was set to the current time", field)
+	}
+}
+
+// Clock corrects timestamps written on a client whose clock is off from the
+// server's. Offset is the client clock minus the server clock.
+type Clock struct {
+	Offset time.Duration
+	Now    time.Time
+	// Suspicious is set once a fixed time is still in the future after
+	// correcting for the offset (beyond MinClockOffset of slack), which a
+	// drifting clock cannot explain
+	Suspicious bool
+}
+
+// NewClock applies the policy for client-reported offsets: small ones are
+// ignored and implausibly large ones refused.
+func NewClock(offset time.Duration, now time.Time) (*Clock, error) {
+	if offset > MaxClockOffset || offset < -MaxClockOffset {
+		return nil, fmt.Errorf("%w: %s", ErrClockOffset, offset)
+	}
+	if offset < MinClockOffset && offset > -MinClockOffset {
+		offset = 0
+	}
+	return &Clock{Offset: offset, Now: now}, nil
+}
+
+// Fix moves a client timestamp onto the server clock and normalizes it. Nil
+// and zero times are left alone.
+func (c *Clock) Fix(t *time.Time) {
+	if t == nil || t.IsZero() {
+		return
+	}
+	*t = t.Add(-c.Offset)
+	if t.After(c.Now.Add(MinClockOffset)) {
+		c.Suspicious = true
+	}
+	Normalize(t, c.Now)
+}
@@ -0,0 +1,100 @@
+package occurrence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/warnings"
+)
+
+func TestNormalize(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		want      time.Time
+		wantAhead bool
+	}{
+		{"past", now.Add(-time.Hour), now.Add(-time.Hour), false},
+		{"slightly ahead", now.Add(2 * time.Minute), now, true},
+		{"far ahead left for validation", now.Add(time.Hour), now.Add(time.Hour), true},
+		{"zero", time.Time{}, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := tt.at
+			ahead := Normalize(&at, now)
+			if !at.Equal(tt.want) || ahead != tt.wantAhead {
+				t.Errorf("Normalize() = %v, %v; want %v, %v", at, ahead, tt.want, tt.wantAhead)
+			}
+		})
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx := warnings.NewContext(context.Background())
+
+	past := now.Add(-time.Hour)
+	Adjust(ctx, "start_time", &past, now)
+	if got := warnings.From(ctx); len(got) != 0 {
+		t.Fatalf("Adjust() warned about a past time: %v", got)
+	}
+
+	ahead := now.Add(2 * time.Minute)
+	Adjust(ctx, "start_time", &ahead, now)
+	if !ahead.Equal(now) {
+		t.Errorf("Adjust() = %v, want %v", ahead, now)
+	}
+	if got := warnings.From(ctx); len(got) != 1 {
+		t.Errorf("Adjust() warnings = %v, want one", got)
+	}
+
+	// Too far ahead is left for Validate to reject, not adjusted
+	future := now.Add(time.Hour)
+	Adjust(ctx, "end_time", &future, now)
+	if got := warnings.From(ctx); len(got) != 1 {
+		t.Errorf("Adjust() warnings = %v, want no new warning", got)
+	}
+}
+
+func TestNewClock(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	c, err := NewClock(time.Second, now)
+	if err != nil || c.Offset != 0 {
+		t.Errorf("NewClock(1s) = %+v, %v; want offset ignored", c, err)
+	}
+
+	if _, err := NewClock(-48*time.Hour, now); !errors.Is(err, ErrClockOffset) {
+		t.Errorf("NewClock(-48h) error = %v, want ErrClockOffset", err)
+	}
+}
+
+func TestClock_Fix(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Client clock is 10 minutes fast: its "now" is 12:10
+	c, err := NewClock(10*time.Minute, now)
+	if err != nil {
+		t.Fatalf("NewClock() error = %v", err)
+	}
+
+	at := now.Add(10 * time.Minute)
+	c.Fix(&at)
+	if !at.Equal(now) || c.Suspicious {
+		t.Errorf("Fix() = %v, suspicious %v; want %v and not suspicious", at, c.Suspicious, now)
+	}
+
+	c.Fix(nil)
+
+	ahead := now.Add(13 * time.Minute)
+	c.Fix(&ahead)
+	if !ahead.Equal(now) || !c.Suspicious {
+		t.Errorf("Fix() = %v, suspicious %v; want clamped to now and flagged", ahead, c.Suspicious)
+	}
+}
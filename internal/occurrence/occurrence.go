@@ -0,0 +1,54 @@
+// Package occurrence validates when a record happened, as distinct from when
+// it was entered. Every record keeps created_at for the moment it was written
+// and an occurrence time (start_time, given_at, measured_at, occurred_at, ...)
+// for the moment it happened; stats, timelines and exports use the latter.
+package occurrence
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxFutureSkew tolerates clocks on phones and devices running slightly ahead
+	MaxFutureSkew = 5 * time.Minute
+	// MaxBackfill is how far back a record may be entered after the fact. It
+	// covers a whole childhood so transfers keep their history, and mostly
+	// catches typos in the year.
+	MaxBackfill = 10 * 365 * 24 * time.Hour
+)
+
+var ErrInvalid = errors.New("invalid occurrence time")
+
+// Validate checks that a record did not happen in the future and is not
+// older than the backfill window.
+func Validate(occurredAt, now time.Time) error {
+	if occurredAt.IsZero() {
+		return fmt.Errorf("%w: time is required", ErrInvalid)
+	}
+	if occurredAt.After(now.Add(MaxFutureSkew)) {
+		return fmt.Errorf("%w: %s is in the future", ErrInvalid, occurredAt.Format(time.RFC3339))
+	}
+	if occurredAt.Before(now.Add(-MaxBackfill)) {
+		return fmt.Errorf("%w: %s is older than the backfill window", ErrInvalid, occurredAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ValidateRange checks a record with a start and an optional end time
+func ValidateRange(start time.Time, end *time.Time, now time.Time) error {
+	if err := Validate(start, now); err != nil {
+		return err
+	}
+	if end == nil {
+		return nil
+	}
+	if end.Before(start) {
+		return fmt.Errorf("%w: end time is before start time", ErrInvalid)
+	}
+	if end.After(now.Add(MaxFutureSkew)) {
+		return fmt.Errorf("%w: %s is in the future", ErrInvalid, end.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package occurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		wantErr bool
+	}{
+		{"now", now, false},
+		{"backdated a week", now.AddDate(0, 0, -7), false},
+		{"slightly ahead within skew", now.Add(2 * time.Minute), false},
+		{"in the future", now.Add(time.Hour), true},
+		{"older than backfill window", now.Add(-MaxBackfill - time.Hour), true},
+		{"zero", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.at, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Errorf("expected ErrInvalid, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRange(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-2 * time.Hour)
+	end := now.Add(-time.Hour)
+	before := start.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	if err := ValidateRange(start, nil, now); err != nil {
+		t.Errorf("open range: unexpected error: %v", err)
+	}
+	if err := ValidateRange(start, &end, now); err != nil {
+		t.Errorf("closed range: unexpected error: %v", err)
+	}
+	if err := ValidateRange(start, &before, now); !errors.Is(err, ErrInvalid) {
+		t.Errorf("end before start: expected ErrInvalid, got %v", err)
+	}
+	if err := ValidateRange(start, &future, now); !errors.Is(err, ErrInvalid) {
+		t.Errorf("end in future: expected ErrInvalid, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocument_WriteTo(t *testing.T) {
+	doc := New(A4Width, A4Height)
+	doc.AddPage()
+	doc.Text(36, 50, Bold, 14, "Daily log (Emma)")
+	doc.Line(36, 60, 559, 60, 0.5)
+	doc.AddPage()
+	doc.FillRect(36, 36, 100, 20, 0.9)
+	doc.Rect(36, 36, 100, 20, 0.5)
+
+	out := doc.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("Not a PDF: %q...", out[:20])
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Error("Expected two pages")
+	}
+	// Parentheses in text are escaped and y runs up from the bottom
+	if !bytes.Contains(out, []byte(`BT /F2 14 Tf 36 791.89 Td (Daily log \(Emma\)) Tj ET`)) {
+		t.Errorf("Text not drawn as expected:\n%s", out)
+	}
+
+	// Every xref entry points at its object
+	start, err := strconv.Atoi(string(regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)[1]))
+	if err != nil || !bytes.HasPrefix(out[start:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point at the xref table")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out, -1)
+	if len(entries) != 8 {
+		t.Fatalf("Expected 8 objects, got %d", len(entries))
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(string(out[off:]), want) {
+			t.Errorf("xref entry %d points at %q", i+1, out[off:off+10])
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	got := encode("Zoë – 2½\tml ✓")
+	want := []byte{'Z', 'o', 0xeb, ' ', 0x96, ' ', '2', 0xbd, ' ', 'm', 'l', ' ', '?'}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encode() = %v, want %v", got, want)
+	}
+}
+
+func TestWidth(t *testing.T) {
+	// H 722, e 556, l 222, l 222, o 556
+	if got := Width(Regular, 10, "Hello"); got != 22.78 {
+		t.Errorf("Width() = %v, want 22.78", got)
+	}
+	if Width(Bold, 10, "Hello") <= Width(Regular, 10, "Hello") {
+		t.Error("Bold text should be wider")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	width := Width(Regular, 10, "120 ml bottle, ")
+	got := Wrap(Regular, 10, "120 ml bottle, took it all", width)
+	want := []string{"120 ml bottle,", "took it all"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Wrap() = %q, want %q", got, want)
+	}
+
+	got = Wrap(Regular, 10, "Supercalifragilistic", Width(Regular, 10, "Supercali"))
+	if len(got) < 2 || strings.Join(got, "") != "Supercalifragilistic" {
+		t.Errorf("Wrap() of a long word = %q", got)
+	}
+	for _, line := range got {
+		if Width(Regular, 10, line) > Width(Regular, 10, "Supercali") {
+			t.Errorf("Line %q is too wide", line)
+		}
+	}
+
+	if got := Wrap(Regular, 10, "  ", width); len(got) != 0 {
+		t.Errorf("Wrap() of blank text = %q", got)
+	}
+}
@@ -0,0 +1,142 @@
+package playtime
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/occurrence"
+)
+
+const defaultDays = 7
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterChildRoutes registers activity sessions and their daily totals on
+// the children group
+func (h *Handler) RegisterChildRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id/activities", h.list)
+	rg.POST("/:id/activities", h.log)
+	rg.POST("/:id/activities/start", h.start)
+	rg.GET("/:id/activities/totals", h.totals)
+	rg.POST("/:id/activities/:sessionId/stop", h.stop)
+	rg.DELETE("/:id/activities/:sessionId", h.delete)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	sessions, err := h.service.List(c.Request.Context(), c.GetString("user_id"), c.Param("id"), Type(c.Query("type")))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *Handler) log(c *gin.Context) {
+	var req LogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := h.service.Log(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+func (h *Handler) start(c *gin.Context) {
+	var req StartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := h.service.Start(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+func (h *Handler) stop(c *gin.Context) {
+	session, err := h.service.Stop(c.Request.Context(), c.GetString("user_id"), c.Param("id"), c.Param("sessionId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// totals adds up ?days= days (default 7, ending today) from ?start=, counted
+// in ?tz= (default UTC)
+func (h *Handler) totals(c *gin.Context) {
+	loc := time.UTC
+	if tz := c.Query("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tz: " + tz})
+			return
+		}
+	}
+
+	days := defaultDays
+	if v := c.Query("days"); v != "" {
+		var err error
+		if days, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": ErrInvalidDays.Error()})
+			return
+		}
+	}
+
+	start := time.Now().In(loc).AddDate(0, 0, 1-days)
+	if v := c.Query("start"); v != "" {
+		var err error
+		if start, err = time.ParseInLocation(time.DateOnly, v, loc); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start format, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	totals, err := h.service.DailyTotals(c.Request.Context(), c.GetString("user_id"), c.Param("id"), start, days)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, totals)
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), c.Param("id"), c.Param("sessionId")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrChildNotFound), errors.Is(err, ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidType), errors.Is(err, ErrInvalidDays), errors.Is(err, occurrence.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAlreadyRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,208 @@
+package playtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/occurrence"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	startFn       func(ctx context.Context, userID, childID string, req *StartRequest) (*Session, error)
+	stopFn        func(ctx context.Context, userID, childID, id string) (*Session, error)
+	logFn         func(ctx context.Context, userID, childID string, req *LogRequest) (*Session, error)
+	listFn        func(ctx context.Context, userID, childID string, sessionType Type) ([]Session, error)
+	activeFn      func(ctx context.Context, userID, childID string) ([]Session, error)
+	deleteFn      func(ctx context.Context, userID, childID, id string) error
+	dailyTotalsFn func(ctx context.Context, userID, childID string, start time.Time, days int) (*Totals, error)
+}
+
+func (m *mockService) Start(ctx context.Context, userID, childID string, req *StartRequest) (*Session, error) {
+	return m.startFn(ctx, userID, childID, req)
+}
+
+func (m *mockService) Stop(ctx context.Context, userID, childID, id string) (*Session, error) {
+	return m.stopFn(ctx, userID, childID, id)
+}
+
+func (m *mockService) Log(ctx context.Context, userID, childID string, req *LogRequest) (*Session, error) {
+	return m.logFn(ctx, userID, childID, req)
+}
+
+func (m *mockService) List(ctx context.Context, userID, childID string, sessionType Type) ([]Session, error) {
+	return m.listFn(ctx, userID, childID, sessionType)
+}
+
+func (m *mockService) Active(ctx context.Context, userID, childID string) ([]Session, error) {
+	return m.activeFn(ctx, userID, childID)
+}
+
+func (m *mockService) Delete(ctx context.Context, userID, childID, id string) error {
+	return m.deleteFn(ctx, userID, childID, id)
+}
+
+func (m *mockService) DailyTotals(ctx context.Context, userID, childID string, start time.Time, days int) (*Totals, error) {
+	return m.dailyTotalsFn(ctx, userID, childID, start, days)
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterChildRoutes(router.Group("/children"))
+	return router
+}
+
+func TestHandler_Start(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"started", `{"type":"tummy_time"}`, nil, http.StatusCreated},
+		{"missing type", `{}`, nil, http.StatusBadRequest},
+		{"invalid type", `{"type":"nap"}`, ErrInvalidType, http.StatusBadRequest},
+		{"already running", `{"type":"tummy_time"}`, ErrAlreadyRunning, http.StatusConflict},
+		{"not a member", `{"type":"play"}`, ErrNotMember, http.StatusForbidden},
+		{"unknown child", `{"type":"play"}`, ErrChildNotFound, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{startFn: func(ctx context.Context, userID, childID string, req *StartRequest) (*Session, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &Session{ID: "act-1", ChildID: childID, Type: req.Type, StartedBy: userID}, nil
+			}}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/children/child-1/activities/start", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			setupRouter(svc).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Stop(t *testing.T) {
+	end := time.Now()
+	svc := &mockService{stopFn: func(ctx context.Context, userID, childID, id string) (*Session, error) {
+		if childID != "child-1" || id != "act-1" {
+			return nil, ErrSessionNotFound
+		}
+		return &Session{ID: id, ChildID: childID, Type: TypeScreen, EndTime: &end, EndedBy: userID}, nil
+	}}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/children/child-1/activities/act-1/stop", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var got Session
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.EndTime == nil || got.EndedBy != "test-user-123" {
+		t.Errorf("body = %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/children/child-1/activities/act-9/stop", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown session status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandler_Log_InvalidTime(t *testing.T) {
+	svc := &mockService{logFn: func(ctx context.Context, userID, childID string, req *LogRequest) (*Session, error) {
+		return nil, fmt.Errorf("%w: end time is before start time", occurrence.ErrInvalid)
+	}}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/children/child-1/activities",
+		bytes.NewBufferString(`{"type":"outdoor","start_time":"2024-05-01T10:00:00Z","end_time":"2024-05-01T09:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	setupRouter(svc).ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandler_Totals(t *testing.T) {
+	var gotStart time.Time
+	var gotDays int
+	svc := &mockService{dailyTotalsFn: func(ctx context.Context, userID, childID string, start time.Time, days int) (*Totals, error) {
+		if days < 1 || days > MaxDays {
+			return nil, ErrInvalidDays
+		}
+		gotStart, gotDays = start, days
+		return &Totals{ChildID: childID, Timezone: start.Location().String(), Days: []DayTotals{}}, nil
+	}}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/children/child-1/activities/totals?start=2024-04-01&days=3&tz=Europe/London", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if gotDays != 3 || gotStart.Format(time.DateOnly) != "2024-04-01" || gotStart.Location().String() != "Europe/London" {
+		t.Errorf("DailyTotals(start = %v, days = %d)", gotStart, gotDays)
+	}
+
+	// Without a start, the range ends today
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/children/child-1/activities/totals", nil))
+	if w.Code != http.StatusOK || gotDays != defaultDays {
+		t.Errorf("default status = %d, days = %d", w.Code, gotDays)
+	}
+	if want := time.Now().UTC().AddDate(0, 0, 1-defaultDays).Format(time.DateOnly); gotStart.Format(time.DateOnly) != want {
+		t.Errorf("default start = %v, want %s", gotStart, want)
+	}
+
+	for _, query := range []string{"tz=Mars/Olympus", "start=01-04-2024", "days=a", "days=90"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/children/child-1/activities/totals?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHandler_Delete(t *testing.T) {
+	svc := &mockService{deleteFn: func(ctx context.Context, userID, childID, id string) error {
+		if id != "act-1" {
+			return ErrSessionNotFound
+		}
+		return nil
+	}}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/children/child-1/activities/act-1", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
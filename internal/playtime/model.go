@@ -0,0 +1,75 @@
+package playtime
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/measure"
+)
+
+type Type string
+
+const (
+	TypeTummyTime Type = "tummy_time"
+	TypeOutdoor   Type = "outdoor"
+	TypeScreen    Type = "screen"
+	TypePlay      Type = "play"
+)
+
+// Types is every activity type, in the order daily totals list them
+var Types = []Type{TypeTummyTime, TypeOutdoor, TypeScreen, TypePlay}
+
+var validTypes = map[Type]bool{
+	TypeTummyTime: true,
+	TypeOutdoor:   true,
+	TypeScreen:    true,
+	TypePlay:      true,
+}
+
+// Session is one stretch of an activity. A child may have one running
+// session of each type at a time.
+type Session struct {
+	ID        string     `json:"id"`
+	ChildID   string     `json:"child_id"`
+	Type      Type       `json:"type"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+	Notes     string     `json:"notes,omitempty"`
+	StartedBy string     `json:"started_by,omitempty"`
+	EndedBy   string     `json:"ended_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Duration returns how long the activity lasted, and false while it is
+// still running
+func (s *Session) Duration() (measure.Duration, bool) {
+	return measure.Between(s.StartTime, s.EndTime)
+}
+
+type StartRequest struct {
+	Type  Type   `json:"type" binding:"required"`
+	Notes string `json:"notes,omitempty"`
+}
+
+// LogRequest records a session after the fact
+type LogRequest struct {
+	Type      Type      `json:"type" binding:"required"`
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required"`
+	Notes     string    `json:"notes,omitempty"`
+}
+
+// DayTotals is the time spent on each activity over one local day. Sessions
+// that cross midnight count toward both days, and a running session counts
+// up to now.
+type DayTotals struct {
+	Date    string           `json:"date"` // YYYY-MM-DD
+	Minutes map[Type]float64 `json:"minutes"`
+	Total   float64          `json:"total_minutes"`
+}
+
+type Totals struct {
+	ChildID  string      `json:"child_id"`
+	Timezone string      `json:"timezone"`
+	Days     []DayTotals `json:"days"`
+}
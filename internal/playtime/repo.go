@@ -0,0 +1,164 @@
+package playtime
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	// Create reports false, writing nothing, when the session is running and
+	// the child already has one of the same type running
+	Create(ctx context.Context, s *Session) (bool, error)
+	GetByID(ctx context.Context, id string) (*Session, error)
+	// List returns the child's latest 100 sessions, newest first, of one type
+	// unless sessionType is empty
+	List(ctx context.Context, childID string, sessionType Type) ([]Session, error)
+	ListActive(ctx context.Context, childID string) ([]Session, error)
+	// ListBetween returns sessions that overlap [from, to), oldest first
+	ListBetween(ctx context.Context, childID string, from, to time.Time) ([]Session, error)
+	// End reports false if the session had already ended
+	End(ctx context.Context, id string, endTime time.Time, endedBy string) (bool, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the activity session table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "activity_sessions")
+}
+
+const sessionColumns = `id, child_id, type, start_time, end_time, notes, COALESCE(started_by, ''), COALESCE(ended_by, ''), created_at, updated_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row scanner) (*Session, error) {
+	var s Session
+	var endTime sql.NullTime
+	if err := row.Scan(
+		&s.ID, &s.ChildID, &s.Type, &s.StartTime, &endTime, &s.Notes, &s.StartedBy, &s.EndedBy, &s.CreatedAt, &s.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if endTime.Valid {
+		s.EndTime = &endTime.Time
+	}
+	return &s, nil
+}
+
+func nullString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func (r *repository) Create(ctx context.Context, s *Session) (bool, error) {
+	// The partial unique index on running sessions turns a second start of
+	// the same type into a no-op
+	query := `
+		INSERT INTO activity_sessions (id, child_id, type, start_time, end_time, notes, started_by, ended_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (child_id, type) WHERE end_time IS NULL DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		s.ID, s.ChildID, s.Type, s.StartTime, s.EndTime, s.Notes,
+		nullString(s.StartedBy), nullString(s.EndedBy), s.CreatedAt, s.UpdatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Session, error) {
+	s, err := scanSession(r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM activity_sessions WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return s, err
+}
+
+func (r *repository) List(ctx context.Context, childID string, sessionType Type) ([]Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM activity_sessions WHERE child_id = $1`
+	args := []any{childID}
+	if sessionType != "" {
+		query += ` AND type = $2`
+		args = append(args, sessionType)
+	}
+	query += ` ORDER BY start_time DESC LIMIT 100`
+	return r.query(ctx, query, args...)
+}
+
+func (r *repository) ListActive(ctx context.Context, childID string) ([]Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM activity_sessions
+		WHERE child_id = $1 AND end_time IS NULL
+		ORDER BY start_time ASC`
+	return r.query(ctx, query, childID)
+}
+
+func (r *repository) ListBetween(ctx context.Context, childID string, from, to time.Time) ([]Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM activity_sessions
+		WHERE child_id = $1 AND start_time < $3 AND (end_time IS NULL OR end_time > $2)
+		ORDER BY start_time ASC`
+	return r.query(ctx, query, childID, from, to)
+}
+
+func (r *repository) query(ctx context.Context, query string, args ...any) ([]Session, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	sessions := []Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (r *repository) End(ctx context.Context, id string, endTime time.Time, endedBy string) (bool, error) {
+	query := `
+		UPDATE activity_sessions
+		SET end_time = $2, ended_by = $3, updated_at = $2
+		WHERE id = $1 AND end_time IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, endTime, nullString(endedBy))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM activity_sessions WHERE id = $1`, id)
+	return err
+}
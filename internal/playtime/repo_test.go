@@ -0,0 +1,104 @@
+package playtime
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var sessionRowColumns = []string{"id", "child_id", "type", "start_time", "end_time", "notes", "started_by", "ended_by", "created_at", "updated_at"}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	s := &Session{ID: "act-1", ChildID: "child-1", Type: TypeTummyTime, StartTime: now, StartedBy: "user-1", CreatedAt: now, UpdatedAt: now}
+	mock.ExpectExec("INSERT INTO activity_sessions .* ON CONFLICT \\(child_id, type\\) WHERE end_time IS NULL DO NOTHING").
+		WithArgs("act-1", "child-1", TypeTummyTime, now, nil, "", "user-1", nil, now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO activity_sessions").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	created, err := repo.Create(context.Background(), s)
+	if err != nil || !created {
+		t.Fatalf("Create() = %v, %v; want true", created, err)
+	}
+	created, err = repo.Create(context.Background(), s)
+	if err != nil || created {
+		t.Errorf("Create() while running = %v, %v; want false", created, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListBetween(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	end := from.Add(9 * time.Hour)
+	mock.ExpectQuery("SELECT id, child_id, type, .* FROM activity_sessions\\s+WHERE child_id = \\$1 AND start_time < \\$3 AND \\(end_time IS NULL OR end_time > \\$2\\)").
+		WithArgs("child-1", from, to).
+		WillReturnRows(sqlmock.NewRows(sessionRowColumns).
+			AddRow("act-1", "child-1", "outdoor", from.Add(8*time.Hour), end, "Park", "user-1", "user-2", from, end).
+			AddRow("act-2", "child-1", "play", from.Add(10*time.Hour), nil, "", "", "", from, from))
+
+	sessions, err := repo.ListBetween(context.Background(), "child-1", from, to)
+	if err != nil {
+		t.Fatalf("ListBetween() error = %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].EndTime == nil || sessions[0].EndedBy != "user-2" || sessions[1].EndTime != nil {
+		t.Errorf("ListBetween() = %+v", sessions)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_End(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE activity_sessions\\s+SET end_time = \\$2, ended_by = \\$3, updated_at = \\$2\\s+WHERE id = \\$1 AND end_time IS NULL").
+		WithArgs("act-1", now, "user-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ended, err := repo.End(context.Background(), "act-1", now, "user-1")
+	if err != nil || ended {
+		t.Errorf("End() of an ended session = %v, %v; want false", ended, err)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT .* FROM activity_sessions WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(sessionRowColumns))
+
+	s, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || s != nil {
+		t.Errorf("GetByID() = %v, %v; want nil, nil", s, err)
+	}
+}
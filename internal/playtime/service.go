@@ -0,0 +1,291 @@
+// Package playtime times what a child does while awake: tummy time, time
+// outdoors, screen time and play. Sessions start and stop like sleep, and
+// add up into daily totals per activity.
+package playtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/occurrence"
+)
+
+// MaxDays bounds one request for daily totals
+const MaxDays = 31
+
+var (
+	ErrChildNotFound   = errors.New("child not found")
+	ErrSessionNotFound = errors.New("activity session not found")
+	ErrNotMember       = errors.New("user is not a member of this family")
+	ErrInvalidType     = errors.New("type must be one of tummy_time, outdoor, screen or play")
+	ErrAlreadyRunning  = errors.New("an activity of this type is already running")
+	ErrInvalidDays     = fmt.Errorf("days must be between 1 and %d", MaxDays)
+)
+
+type Service interface {
+	// Start begins a session now; each type can only run once at a time
+	Start(ctx context.Context, userID, childID string, req *StartRequest) (*Session, error)
+	// Stop ends a running session. Stopping one that has already ended
+	// returns it unchanged.
+	Stop(ctx context.Context, userID, childID, id string) (*Session, error)
+	Log(ctx context.Context, userID, childID string, req *LogRequest) (*Session, error)
+	List(ctx context.Context, userID, childID string, sessionType Type) ([]Session, error)
+	Active(ctx context.Context, userID, childID string) ([]Session, error)
+	Delete(ctx context.Context, userID, childID, id string) error
+	// DailyTotals adds up each activity over days local days from start,
+	// which should be midnight in the timezone to count in
+	DailyTotals(ctx context.Context, userID, childID string, start time.Time, days int) (*Totals, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	now           func() time.Time
+}
+
+func NewService(repo Repository, familyService family.Service) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+		now:           time.Now,
+	}
+}
+
+// HealthCheck reports whether activity sessions can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Start(ctx context.Context, userID, childID string, req *StartRequest) (*Session, error) {
+	if !validTypes[req.Type] {
+		return nil, ErrInvalidType
+	}
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	session := &Session{
+		ID:        ids.New(),
+		ChildID:   childID,
+		Type:      req.Type,
+		StartTime: now,
+		Notes:     strings.TrimSpace(req.Notes),
+		StartedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	created, err := s.repo.Create(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start activity: %w", err)
+	}
+	if !created {
+		return nil, ErrAlreadyRunning
+	}
+	return session, nil
+}
+
+func (s *service) Stop(ctx context.Context, userID, childID, id string) (*Session, error) {
+	session, err := s.childSession(ctx, userID, childID, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.EndTime != nil {
+		return session, nil
+	}
+
+	now := s.now()
+	ended, err := s.repo.End(ctx, id, now, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stop activity: %w", err)
+	}
+	if !ended {
+		// Someone else stopped it first; their end time stands
+		return s.childSession(ctx, userID, childID, id)
+	}
+
+	session.EndTime = &now
+	session.EndedBy = userID
+	session.UpdatedAt = now
+	return session, nil
+}
+
+func (s *service) Log(ctx context.Context, userID, childID string, req *LogRequest) (*Session, error) {
+	if !validTypes[req.Type] {
+		return nil, ErrInvalidType
+	}
+
+	now := s.now()
+	occurrence.Adjust(ctx, "start_time", &req.StartTime, now)
+	occurrence.Adjust(ctx, "end_time", &req.EndTime, now)
+	if err := occurrence.ValidateRange(req.StartTime, &req.EndTime, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	end := req.EndTime
+	session := &Session{
+		ID:        ids.New(),
+		ChildID:   childID,
+		Type:      req.Type,
+		StartTime: req.StartTime,
+		EndTime:   &end,
+		Notes:     strings.TrimSpace(req.Notes),
+		StartedBy: userID,
+		EndedBy:   userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := s.repo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to log activity: %w", err)
+	}
+	return session, nil
+}
+
+func (s *service) List(ctx context.Context, userID, childID string, sessionType Type) ([]Session, error) {
+	if sessionType != "" && !validTypes[sessionType] {
+		return nil, ErrInvalidType
+	}
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.List(ctx, childID, sessionType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activities: %w", err)
+	}
+	return sessions, nil
+}
+
+func (s *service) Active(ctx context.Context, userID, childID string) ([]Session, error) {
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.ListActive(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list running activities: %w", err)
+	}
+	return sessions, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, childID, id string) error {
+	if _, err := s.childSession(ctx, userID, childID, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete activity: %w", err)
+	}
+	return nil
+}
+
+func (s *service) DailyTotals(ctx context.Context, userID, childID string, start time.Time, days int) (*Totals, error) {
+	if days < 1 || days > MaxDays {
+		return nil, ErrInvalidDays
+	}
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	loc := start.Location()
+	y, m, d := start.Date()
+	first := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	end := time.Date(y, m, d+days, 0, 0, 0, 0, loc)
+
+	sessions, err := s.repo.ListBetween(ctx, childID, first, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activities: %w", err)
+	}
+
+	now := s.now()
+	totals := &Totals{ChildID: childID, Timezone: loc.String(), Days: make([]DayTotals, 0, days)}
+	for i := range days {
+		// Built from the date rather than adding 24h, so days stay aligned to
+		// midnight across daylight saving changes
+		dayStart := time.Date(y, m, d+i, 0, 0, 0, 0, loc)
+		dayEnd := time.Date(y, m, d+i+1, 0, 0, 0, 0, loc)
+
+		day := DayTotals{Date: dayStart.Format(time.DateOnly), Minutes: make(map[Type]float64, len(Types))}
+		for _, t := range Types {
+			day.Minutes[t] = 0
+		}
+		for _, sess := range sessions {
+			minutes := overlap(&sess, dayStart, dayEnd, now).Minutes()
+			day.Minutes[sess.Type] += minutes
+			day.Total += minutes
+		}
+		for _, t := range Types {
+			day.Minutes[t] = roundMinutes(day.Minutes[t])
+		}
+		day.Total = roundMinutes(day.Total)
+		totals.Days = append(totals.Days, day)
+	}
+	return totals, nil
+}
+
+// overlap is how much of the session falls within [from, to). A running
+// session is counted up to now.
+func overlap(sess *Session, from, to, now time.Time) time.Duration {
+	end := now
+	if sess.EndTime != nil {
+		end = *sess.EndTime
+	}
+	start := sess.StartTime
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	return max(end.Sub(start), 0)
+}
+
+func roundMinutes(m float64) float64 {
+	return math.Round(m*10) / 10
+}
+
+// childSession checks membership and loads one of the child's sessions
+func (s *service) childSession(ctx context.Context, userID, childID, id string) (*Session, error) {
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", err)
+	}
+	if session == nil || session.ChildID != childID {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// authorize lets in members of the child's family
+func (s *service) authorize(ctx context.Context, userID, childID string) error {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return ErrChildNotFound
+	}
+
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package playtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/occurrence"
+)
+
+var testNow = time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+type mockRepository struct {
+	sessions []Session
+	// endRace makes End report the session was already ended elsewhere
+	endRace bool
+}
+
+func (m *mockRepository) Create(ctx context.Context, s *Session) (bool, error) {
+	if s.EndTime == nil {
+		for _, other := range m.sessions {
+			if other.ChildID == s.ChildID && other.Type == s.Type && other.EndTime == nil {
+				return false, nil
+			}
+		}
+	}
+	m.sessions = append(m.sessions, *s)
+	return true, nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Session, error) {
+	for _, s := range m.sessions {
+		if s.ID == id {
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) List(ctx context.Context, childID string, sessionType Type) ([]Session, error) {
+	sessions := []Session{}
+	for _, s := range m.sessions {
+		if s.ChildID == childID && (sessionType == "" || s.Type == sessionType) {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockRepository) ListActive(ctx context.Context, childID string) ([]Session, error) {
+	sessions := []Session{}
+	for _, s := range m.sessions {
+		if s.ChildID == childID && s.EndTime == nil {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockRepository) ListBetween(ctx context.Context, childID string, from, to time.Time) ([]Session, error) {
+	sessions := []Session{}
+	for _, s := range m.sessions {
+		if s.ChildID == childID && s.StartTime.Before(to) && (s.EndTime == nil || s.EndTime.After(from)) {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockRepository) End(ctx context.Context, id string, endTime time.Time, endedBy string) (bool, error) {
+	for i := range m.sessions {
+		s := &m.sessions[i]
+		if s.ID != id || s.EndTime != nil {
+			continue
+		}
+		if m.endRace {
+			other := endTime.Add(-time.Minute)
+			s.EndTime = &other
+			s.EndedBy = "user-3"
+			return false, nil
+		}
+		s.EndTime = &endTime
+		s.EndedBy = endedBy
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	for i, s := range m.sessions {
+		if s.ID == id {
+			m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" || childID == "child-2" {
+		return &family.Child{ID: childID, FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID == "user-1" {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+func newTestService(repo *mockRepository) Service {
+	svc := NewService(repo, &mockFamilyService{})
+	svc.(*service).now = func() time.Time { return testNow }
+	return svc
+}
+
+func session(id string, t Type, start time.Time, minutes int) Session {
+	s := Session{ID: id, ChildID: "child-1", Type: t, StartTime: start}
+	if minutes >= 0 {
+		end := start.Add(time.Duration(minutes) * time.Minute)
+		s.EndTime = &end
+	}
+	return s
+}
+
+func TestService_Start(t *testing.T) {
+	repo := &mockRepository{}
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	s, err := svc.Start(ctx, "user-1", "child-1", &StartRequest{Type: TypeTummyTime, Notes: " On the mat "})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if !s.StartTime.Equal(testNow) || s.EndTime != nil || s.StartedBy != "user-1" || s.Notes != "On the mat" {
+		t.Errorf("Start() = %+v", s)
+	}
+
+	// A different type may run alongside
+	if _, err := svc.Start(ctx, "user-1", "child-1", &StartRequest{Type: TypeOutdoor}); err != nil {
+		t.Errorf("Start() of a second type error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		childID string
+		userID  string
+		typ     Type
+		want    error
+	}{
+		{"already running", "child-1", "user-1", TypeTummyTime, ErrAlreadyRunning},
+		{"unknown type", "child-1", "user-1", "nap", ErrInvalidType},
+		{"unknown child", "child-9", "user-1", TypePlay, ErrChildNotFound},
+		{"not a member", "child-1", "user-2", TypePlay, ErrNotMember},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Start(ctx, tt.userID, tt.childID, &StartRequest{Type: tt.typ}); !errors.Is(err, tt.want) {
+				t.Errorf("Start() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Stop(t *testing.T) {
+	repo := &mockRepository{sessions: []Session{session("act-1", TypeScreen, testNow.Add(-20*time.Minute), -1)}}
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	s, err := svc.Stop(ctx, "user-1", "child-1", "act-1")
+	if err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if s.EndTime == nil || !s.EndTime.Equal(testNow) || s.EndedBy != "user-1" {
+		t.Errorf("Stop() = %+v", s)
+	}
+
+	// Stopping again returns the session as it was
+	again, err := svc.Stop(ctx, "user-1", "child-1", "act-1")
+	if err != nil || !again.EndTime.Equal(testNow) {
+		t.Errorf("Stop() again = %+v, %v", again, err)
+	}
+
+	if _, err := svc.Stop(ctx, "user-1", "child-2", "act-1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Stop() for another child error = %v, want %v", err, ErrSessionNotFound)
+	}
+}
+
+func TestService_Stop_Race(t *testing.T) {
+	repo := &mockRepository{
+		sessions: []Session{session("act-1", TypePlay, testNow.Add(-time.Hour), -1)},
+		endRace:  true,
+	}
+	svc := newTestService(repo)
+
+	s, err := svc.Stop(context.Background(), "user-1", "child-1", "act-1")
+	if err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if s.EndedBy != "user-3" || !s.EndTime.Equal(testNow.Add(-time.Minute)) {
+		t.Errorf("Stop() = %+v, want the other stop to stand", s)
+	}
+}
+
+func TestService_Log(t *testing.T) {
+	repo := &mockRepository{sessions: []Session{session("act-1", TypeOutdoor, testNow.Add(-time.Hour), -1)}}
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	// A finished session can be logged while one of its type is running
+	s, err := svc.Log(ctx, "user-1", "child-1", &LogRequest{
+		Type:      TypeOutdoor,
+		StartTime: testNow.Add(-5 * time.Hour),
+		EndTime:   testNow.Add(-4 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if s.EndTime == nil || len(repo.sessions) != 2 {
+		t.Errorf("Log() = %+v", s)
+	}
+
+	_, err = svc.Log(ctx, "user-1", "child-1", &LogRequest{
+		Type:      TypeOutdoor,
+		StartTime: testNow.Add(-time.Hour),
+		EndTime:   testNow.Add(-2 * time.Hour),
+	})
+	if !errors.Is(err, occurrence.ErrInvalid) {
+		t.Errorf("Log() ending before it starts error = %v, want %v", err, occurrence.ErrInvalid)
+	}
+}
+
+func TestService_DailyTotals(t *testing.T) {
+	day := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+	repo := &mockRepository{sessions: []Session{
+		session("act-1", TypeTummyTime, day.Add(8*time.Hour), 10),
+		session("act-2", TypeTummyTime, day.Add(15*time.Hour), 5),
+		// Crosses midnight: 30 minutes on each day
+		session("act-3", TypeScreen, day.Add(23*time.Hour+30*time.Minute), 60),
+		// Still running at 09:00 on May 1st
+		session("act-4", TypePlay, testNow.Add(-45*time.Minute), -1),
+		session("act-5", TypeOutdoor, day.AddDate(0, 0, -3), 60),
+	}}
+	svc := newTestService(repo)
+
+	totals, err := svc.DailyTotals(context.Background(), "user-1", "child-1", day, 2)
+	if err != nil {
+		t.Fatalf("DailyTotals() error = %v", err)
+	}
+	if len(totals.Days) != 2 || totals.Timezone != "UTC" {
+		t.Fatalf("DailyTotals() = %+v", totals)
+	}
+
+	first, second := totals.Days[0], totals.Days[1]
+	if first.Date != "2024-04-30" || first.Minutes[TypeTummyTime] != 15 || first.Minutes[TypeScreen] != 30 || first.Total != 45 {
+		t.Errorf("first day = %+v", first)
+	}
+	if second.Date != "2024-05-01" || second.Minutes[TypeScreen] != 30 || second.Minutes[TypePlay] != 45 || second.Total != 75 {
+		t.Errorf("second day = %+v", second)
+	}
+	if v, ok := second.Minutes[TypeOutdoor]; !ok || v != 0 {
+		t.Errorf("second day outdoor = %v, %v; want 0 listed", v, ok)
+	}
+
+	if _, err := svc.DailyTotals(context.Background(), "user-1", "child-1", day, MaxDays+1); !errors.Is(err, ErrInvalidDays) {
+		t.Errorf("DailyTotals() over the limit error = %v, want %v", err, ErrInvalidDays)
+	}
+}
+
+func TestService_DailyTotals_DaylightSaving(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	// Clocks went forward at 2am on March 10th, 2024
+	day := time.Date(2024, 3, 10, 0, 0, 0, 0, ny)
+	repo := &mockRepository{sessions: []Session{
+		session("act-1", TypeTummyTime, time.Date(2024, 3, 11, 0, 10, 0, 0, ny), 10),
+	}}
+	svc := newTestService(repo)
+	svc.(*service).now = func() time.Time { return time.Date(2024, 3, 12, 12, 0, 0, 0, ny) }
+
+	totals, err := svc.DailyTotals(context.Background(), "user-1", "child-1", day, 2)
+	if err != nil {
+		t.Fatalf("DailyTotals() error = %v", err)
+	}
+	if totals.Days[0].Total != 0 || totals.Days[1].Date != "2024-03-11" || totals.Days[1].Minutes[TypeTummyTime] != 10 {
+		t.Errorf("DailyTotals() = %+v", totals.Days)
+	}
+}
+
+func TestService_Delete(t *testing.T) {
+	repo := &mockRepository{sessions: []Session{session("act-1", TypePlay, testNow.Add(-time.Hour), 20)}}
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	if err := svc.Delete(ctx, "user-2", "child-1", "act-1"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Delete() by a non-member error = %v, want %v", err, ErrNotMember)
+	}
+	if err := svc.Delete(ctx, "user-1", "child-1", "act-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(repo.sessions) != 0 {
+		t.Errorf("sessions left = %d, want 0", len(repo.sessions))
+	}
+	if err := svc.Delete(ctx, "user-1", "child-1", "act-1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Delete() again error = %v, want %v", err, ErrSessionNotFound)
+	}
+}
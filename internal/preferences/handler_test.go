@@ -0,0 +1,157 @@
+package preferences
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/notifications"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	getFn    func(ctx context.Context, userID string) (*Preferences, error)
+	updateFn func(ctx context.Context, userID string, req *UpdatePreferencesRequest) (*Preferences, error)
+}
+
+func (m *mockService) Get(ctx context.Context, userID string) (*Preferences, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID)
+	}
+	return defaults(userID), nil
+}
+
+func (m *mockService) Update(ctx context.Context, userID string, req *UpdatePreferencesRequest) (*Preferences, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, userID, req)
+	}
+	return defaults(userID), nil
+}
+
+func (m *mockService) Route(ctx context.Context, userID string, event notifications.Event, now time.Time) Channel {
+	return ChannelPush
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterRoutes(router.Group("/notifications/preferences"))
+	return router
+}
+
+func TestGet_Success(t *testing.T) {
+	var capturedUserID string
+	svc := &mockService{
+		getFn: func(ctx context.Context, userID string) (*Preferences, error) {
+			capturedUserID = userID
+			return defaults(userID), nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/notifications/preferences", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if capturedUserID != "test-user-123" {
+		t.Errorf("Expected user test-user-123, got %s", capturedUserID)
+	}
+
+	var result Preferences
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if result.Timezone != "UTC" {
+		t.Errorf("Expected timezone UTC, got %s", result.Timezone)
+	}
+}
+
+func TestUpdate_Success(t *testing.T) {
+	var captured *UpdatePreferencesRequest
+	svc := &mockService{
+		updateFn: func(ctx context.Context, userID string, req *UpdatePreferencesRequest) (*Preferences, error) {
+			captured = req
+			return defaults(userID), nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"quiet_hours":{"start":"22:00","end":"07:00"},"channels":{"medication_due":"push"},"muted_children":["child-1"]}`
+	req := httptest.NewRequest("PUT", "/notifications/preferences", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if captured.QuietHours == nil || captured.QuietHours.Start != "22:00" {
+		t.Errorf("Expected quiet hours to be passed through, got %+v", captured.QuietHours)
+	}
+	if captured.Channels[notifications.EventMedicationDue] != ChannelPush {
+		t.Errorf("Expected channels to be passed through, got %v", captured.Channels)
+	}
+}
+
+func TestUpdate_InvalidJSON(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("PUT", "/notifications/preferences", strings.NewReader("not json"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestUpdate_ErrorStatuses(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrInvalidChannel, http.StatusBadRequest},
+		{ErrInvalidQuietHours, http.StatusBadRequest},
+		{ErrChildNotFound, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			svc := &mockService{
+				updateFn: func(ctx context.Context, userID string, req *UpdatePreferencesRequest) (*Preferences, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("PUT", "/notifications/preferences", strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package preferences
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+// Channel is where a notification is delivered. Push goes out over the
+// notification stream.
+type Channel string
+
+const (
+	ChannelPush  Channel = "push"
+	ChannelEmail Channel = "email"
+	ChannelNone  Channel = "none"
+)
+
+// QuietHours holds back push notifications between Start and End ("HH:MM"
+// in the user's timezone). A window may wrap past midnight, e.g. 22:00-07:00.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Preferences are a user's notification routing rules. Event types without
+// an entry in Channels use push.
+type Preferences struct {
+	UserID        string                              `json:"user_id"`
+	QuietHours    *QuietHours                         `json:"quiet_hours,omitempty"`
+	Timezone      string                              `json:"timezone"`
+	Channels      map[notifications.EventType]Channel `json:"channels"`
+	MutedChildren []string                            `json:"muted_children"`
+	UpdatedAt     time.Time                           `json:"updated_at"`
+}
+
+type UpdatePreferencesRequest struct {
+	QuietHours    *QuietHours                         `json:"quiet_hours"`
+	Timezone      string                              `json:"timezone"`
+	Channels      map[notifications.EventType]Channel `json:"channels"`
+	MutedChildren []string                            `json:"muted_children"`
+}
@@ -0,0 +1,92 @@
+package preferences
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ninenine/babytrack/internal/health"
+
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	Get(ctx context.Context, userID string) (*Preferences, error)
+	Upsert(ctx context.Context, p *Preferences) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the preferences table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "notification_preferences")
+}
+
+func (r *repository) Get(ctx context.Context, userID string) (*Preferences, error) {
+	query := `
+		SELECT user_id, quiet_start, quiet_end, timezone, channels, muted_children, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`
+
+	var p Preferences
+	var quietStart, quietEnd sql.NullString
+	var channels []byte
+	var muted pq.StringArray
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&p.UserID, &quietStart, &quietEnd, &p.Timezone, &channels, &muted, &p.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if quietStart.Valid && quietEnd.Valid {
+		p.QuietHours = &QuietHours{Start: quietStart.String, End: quietEnd.String}
+	}
+	if err := json.Unmarshal(channels, &p.Channels); err != nil {
+		return nil, fmt.Errorf("failed to decode channels: %w", err)
+	}
+	p.MutedChildren = []string(muted)
+	if p.MutedChildren == nil {
+		p.MutedChildren = []string{}
+	}
+
+	return &p, nil
+}
+
+func (r *repository) Upsert(ctx context.Context, p *Preferences) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, quiet_start, quiet_end, timezone, channels, muted_children, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE
+		SET quiet_start = $2, quiet_end = $3, timezone = $4, channels = $5, muted_children = $6, updated_at = $7
+	`
+
+	var quietStart, quietEnd *string
+	if p.QuietHours != nil {
+		quietStart = &p.QuietHours.Start
+		quietEnd = &p.QuietHours.End
+	}
+
+	channels, err := json.Marshal(p.Channels)
+	if err != nil {
+		return fmt.Errorf("failed to encode channels: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
+		p.UserID, quietStart, quietEnd, p.Timezone, channels, pq.Array(p.MutedChildren), p.UpdatedAt,
+	)
+	return err
+}
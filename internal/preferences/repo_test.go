@@ -0,0 +1,115 @@
+package preferences
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/notifications"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var preferenceColumns = []string{"user_id", "quiet_start", "quiet_end", "timezone", "channels", "muted_children", "updated_at"}
+
+func TestRepository_Get(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(preferenceColumns).
+		AddRow("user-1", "22:00", "07:00", "Europe/London", []byte(`{"sleep_insight":"email"}`), "{child-1}", now)
+
+	mock.ExpectQuery("SELECT user_id, quiet_start, quiet_end, timezone, channels, muted_children, updated_at FROM notification_preferences").
+		WithArgs("user-1").
+		WillReturnRows(rows)
+
+	p, err := repo.Get(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if p.QuietHours == nil || p.QuietHours.Start != "22:00" || p.QuietHours.End != "07:00" {
+		t.Errorf("Get() QuietHours = %+v, want 22:00-07:00", p.QuietHours)
+	}
+	if p.Channels[notifications.EventSleepInsight] != ChannelEmail {
+		t.Errorf("Get() Channels = %v, want sleep_insight routed to email", p.Channels)
+	}
+	if len(p.MutedChildren) != 1 || p.MutedChildren[0] != "child-1" {
+		t.Errorf("Get() MutedChildren = %v, want [child-1]", p.MutedChildren)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Get_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT user_id").
+		WithArgs("user-1").
+		WillReturnError(sql.ErrNoRows)
+
+	p, err := repo.Get(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if p != nil {
+		t.Errorf("Get() = %+v, want nil", p)
+	}
+}
+
+func TestRepository_Upsert(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	p := &Preferences{
+		UserID:        "user-1",
+		Timezone:      "UTC",
+		Channels:      map[notifications.EventType]Channel{notifications.EventMedicationDue: ChannelNone},
+		MutedChildren: []string{},
+		UpdatedAt:     time.Now(),
+	}
+
+	mock.ExpectExec("INSERT INTO notification_preferences .* ON CONFLICT \\(user_id\\) DO UPDATE").
+		WithArgs("user-1", nil, nil, "UTC", []byte(`{"medication_due":"none"}`), pq.Array(p.MutedChildren), p.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.Upsert(context.Background(), p); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Upsert_Error(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectExec("INSERT INTO notification_preferences").
+		WillReturnError(errors.New("database error"))
+
+	err := repo.Upsert(context.Background(), &Preferences{UserID: "user-1"})
+	if err == nil {
+		t.Error("Upsert() should return error on database failure")
+	}
+}
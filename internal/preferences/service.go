@@ -0,0 +1,272 @@
+// Package preferences decides, per user, whether and where a notification is
+// delivered. Rules are evaluated server-side just before dispatch.
+package preferences
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+var (
+	ErrInvalidEventType  = errors.New("invalid event type")
+	ErrInvalidChannel    = errors.New("invalid channel")
+	ErrInvalidQuietHours = errors.New("invalid quiet hours")
+	ErrInvalidTimezone   = errors.New("invalid timezone")
+	ErrChildNotFound     = errors.New("child not found")
+	ErrNotMember         = errors.New("user is not a member of this family")
+)
+
+// RoutableEvents are the alerts a user can route or silence. Other events,
+// such as presence, keep shared screens in step and are always delivered.
+var RoutableEvents = []notifications.EventType{
+	notifications.EventMedicationDue,
+	notifications.EventVaccinationDue,
+	notifications.EventAppointmentSoon,
+	notifications.EventSleepInsight,
+	notifications.EventSleepEnded,
+	notifications.EventFeedingInsight,
+	notifications.EventOnThisDay,
+	notifications.EventCareMessage,
+	notifications.EventDocumentExpiring,
+	notifications.EventGrowthReminder,
+}
+
+var validChannels = []Channel{ChannelPush, ChannelEmail, ChannelNone}
+
+const clockLayout = "15:04"
+
+type Service interface {
+	Get(ctx context.Context, userID string) (*Preferences, error)
+	Update(ctx context.Context, userID string, req *UpdatePreferencesRequest) (*Preferences, error)
+	// Route returns the channel an event should go out on for the user, or
+	// ChannelNone when it is muted or falls in their quiet hours
+	Route(ctx context.Context, userID string, event notifications.Event, now time.Time) Channel
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+
+	// Dispatch runs for every recipient of every event, so preferences are
+	// cached after the first read and replaced on update.
+	mu    sync.RWMutex
+	cache map[string]*Preferences
+}
+
+func NewService(repo Repository, familyService family.Service) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+		cache:         make(map[string]*Preferences),
+	}
+}
+
+// HealthCheck reports whether notification preferences can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Get(ctx context.Context, userID string) (*Preferences, error) {
+	s.mu.RLock()
+	p, ok := s.cache[userID]
+	s.mu.RUnlock()
+	if ok {
+		return p, nil
+	}
+
+	p, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	if p == nil {
+		p = defaults(userID)
+	}
+
+	s.mu.Lock()
+	s.cache[userID] = p
+	s.mu.Unlock()
+
+	return p, nil
+}
+
+// Update replaces all of the user's preferences with the request
+func (s *service) Update(ctx context.Context, userID string, req *UpdatePreferencesRequest) (*Preferences, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	for _, childID := range req.MutedChildren {
+		if err := s.checkChild(ctx, userID, childID); err != nil {
+			return nil, err
+		}
+	}
+
+	p := &Preferences{
+		UserID:        userID,
+		QuietHours:    req.QuietHours,
+		Timezone:      req.Timezone,
+		Channels:      req.Channels,
+		MutedChildren: req.MutedChildren,
+		UpdatedAt:     time.Now(),
+	}
+	if p.Timezone == "" {
+		p.Timezone = "UTC"
+	}
+	if p.Channels == nil {
+		p.Channels = map[notifications.EventType]Channel{}
+	}
+	if p.MutedChildren == nil {
+		p.MutedChildren = []string{}
+	}
+
+	if err := s.repo.Upsert(ctx, p); err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %w", err)
+	}
+
+	s.mu.Lock()
+	s.cache[userID] = p
+	s.mu.Unlock()
+
+	return p, nil
+}
+
+func (s *service) Route(ctx context.Context, userID string, event notifications.Event, now time.Time) Channel {
+	if !slices.Contains(RoutableEvents, event.Type) {
+		return ChannelPush
+	}
+
+	p, err := s.Get(ctx, userID)
+	if err != nil {
+		// Better a notification during quiet hours than a missed dose
+		log.Printf("[preferences] %v; delivering %s to %s", err, event.Type, userID)
+		return ChannelPush
+	}
+
+	if event.ChildID != "" && slices.Contains(p.MutedChildren, event.ChildID) {
+		return ChannelNone
+	}
+
+	channel, ok := p.Channels[event.Type]
+	if !ok {
+		channel = ChannelPush
+	}
+	if channel == ChannelPush && inQuietHours(p, now) {
+		return ChannelNone
+	}
+	return channel
+}
+
+func (s *service) checkChild(ctx context.Context, userID, childID string) error {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return ErrChildNotFound
+	}
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return fmt.Errorf("failed to check membership: %w", err)
+	}
+	return nil
+}
+
+func validate(req *UpdatePreferencesRequest) error {
+	if err := limits.Items("muted_children", len(req.MutedChildren), limits.Get().ChildrenPerRequest); err != nil {
+		return err
+	}
+
+	for eventType, channel := range req.Channels {
+		if !slices.Contains(RoutableEvents, eventType) {
+			return fmt.Errorf("%w: %s", ErrInvalidEventType, eventType)
+		}
+		if !slices.Contains(validChannels, channel) {
+			return fmt.Errorf("%w: %s", ErrInvalidChannel, channel)
+		}
+	}
+
+	if q := req.QuietHours; q != nil {
+		start, err := time.Parse(clockLayout, q.Start)
+		if err != nil {
+			return fmt.Errorf("%w: start must be HH:MM", ErrInvalidQuietHours)
+		}
+		end, err := time.Parse(clockLayout, q.End)
+		if err != nil {
+			return fmt.Errorf("%w: end must be HH:MM", ErrInvalidQuietHours)
+		}
+		if start.Equal(end) {
+			return fmt.Errorf("%w: start and end must differ", ErrInvalidQuietHours)
+		}
+	}
+
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidTimezone, req.Timezone)
+		}
+	}
+	return nil
+}
+
+// inQuietHours compares wall-clock minutes in the user's timezone, so the
+// window follows daylight saving changes
+func inQuietHours(p *Preferences, now time.Time) bool {
+	if p.QuietHours == nil {
+		return false
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start, err := time.Parse(clockLayout, p.QuietHours.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(clockLayout, p.QuietHours.End)
+	if err != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+func defaults(userID string) *Preferences {
+	return &Preferences{
+		UserID:        userID,
+		Timezone:      "UTC",
+		Channels:      map[notifications.EventType]Channel{},
+		MutedChildren: []string{},
+	}
+}
+
+// HubFilter lets the notification hub consult each recipient's preferences.
+// The stream is the push channel, so anything routed elsewhere is held back.
+func HubFilter(s Service) notifications.Filter {
+	return hubFilter{service: s}
+}
+
+type hubFilter struct {
+	service Service
+}
+
+func (f hubFilter) Allow(userID string, event notifications.Event) bool {
+	return f.service.Route(context.Background(), userID, event, time.Now()) == ChannelPush
+}
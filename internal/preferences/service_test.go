@@ -0,0 +1,229 @@
+package preferences
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+// mockRepository is a test double for Repository
+type mockRepository struct {
+	prefs  map[string]*Preferences
+	gets   int
+	getErr error
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{prefs: make(map[string]*Preferences)}
+}
+
+func (m *mockRepository) Get(ctx context.Context, userID string) (*Preferences, error) {
+	m.gets++
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return m.prefs[userID], nil
+}
+
+func (m *mockRepository) Upsert(ctx context.Context, p *Preferences) error {
+	m.prefs[p.UserID] = p
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	switch childID {
+	case "child-1":
+		return &family.Child{ID: childID, FamilyID: "family-1"}, nil
+	case "child-other":
+		return &family.Child{ID: childID, FamilyID: "family-2"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if familyID == "family-1" {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+func medicationDue(childID string) notifications.Event {
+	return notifications.Event{Type: notifications.EventMedicationDue, ChildID: childID}
+}
+
+func TestService_Get_Defaults(t *testing.T) {
+	svc := NewService(newMockRepository(), &mockFamilyService{})
+
+	p, err := svc.Get(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if p.Timezone != "UTC" || p.QuietHours != nil || len(p.Channels) != 0 || len(p.MutedChildren) != 0 {
+		t.Errorf("Get() = %+v, want defaults", p)
+	}
+}
+
+func TestService_Get_Cached(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, &mockFamilyService{})
+
+	svc.Get(context.Background(), "user-1")
+	svc.Get(context.Background(), "user-1")
+
+	if repo.gets != 1 {
+		t.Errorf("repo read %d times, want 1", repo.gets)
+	}
+}
+
+func TestService_Update(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, &mockFamilyService{})
+
+	p, err := svc.Update(context.Background(), "user-1", &UpdatePreferencesRequest{
+		QuietHours:    &QuietHours{Start: "22:00", End: "07:00"},
+		Timezone:      "Europe/London",
+		Channels:      map[notifications.EventType]Channel{notifications.EventSleepInsight: ChannelEmail},
+		MutedChildren: []string{"child-1"},
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if repo.prefs["user-1"] != p {
+		t.Error("Update() should save the preferences")
+	}
+
+	got, _ := svc.Get(context.Background(), "user-1")
+	if got.Timezone != "Europe/London" {
+		t.Errorf("Get() after Update() Timezone = %v, want Europe/London", got.Timezone)
+	}
+}
+
+func TestService_Update_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		req  UpdatePreferencesRequest
+		want error
+	}{
+		{
+			name: "unknown event type",
+			req:  UpdatePreferencesRequest{Channels: map[notifications.EventType]Channel{notifications.EventPresence: ChannelNone}},
+			want: ErrInvalidEventType,
+		},
+		{
+			name: "unknown channel",
+			req:  UpdatePreferencesRequest{Channels: map[notifications.EventType]Channel{notifications.EventMedicationDue: "sms"}},
+			want: ErrInvalidChannel,
+		},
+		{
+			name: "bad quiet hours",
+			req:  UpdatePreferencesRequest{QuietHours: &QuietHours{Start: "10pm", End: "07:00"}},
+			want: ErrInvalidQuietHours,
+		},
+		{
+			name: "empty quiet hours",
+			req:  UpdatePreferencesRequest{QuietHours: &QuietHours{Start: "07:00", End: "07:00"}},
+			want: ErrInvalidQuietHours,
+		},
+		{
+			name: "bad timezone",
+			req:  UpdatePreferencesRequest{Timezone: "Mars/Olympus"},
+			want: ErrInvalidTimezone,
+		},
+		{
+			name: "unknown child",
+			req:  UpdatePreferencesRequest{MutedChildren: []string{"child-404"}},
+			want: ErrChildNotFound,
+		},
+		{
+			name: "another family's child",
+			req:  UpdatePreferencesRequest{MutedChildren: []string{"child-other"}},
+			want: ErrNotMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService(newMockRepository(), &mockFamilyService{})
+			_, err := svc.Update(context.Background(), "user-1", &tt.req)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Update() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Route(t *testing.T) {
+	svc := NewService(newMockRepository(), &mockFamilyService{})
+	_, err := svc.Update(context.Background(), "user-1", &UpdatePreferencesRequest{
+		QuietHours:    &QuietHours{Start: "22:00", End: "07:00"},
+		Timezone:      "America/New_York",
+		Channels:      map[notifications.EventType]Channel{notifications.EventSleepInsight: ChannelEmail},
+		MutedChildren: []string{"child-1"},
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	ny, _ := time.LoadLocation("America/New_York")
+	afternoon := time.Date(2026, 3, 2, 14, 0, 0, 0, ny)
+	night := time.Date(2026, 3, 2, 23, 30, 0, 0, ny)
+	earlyMorning := time.Date(2026, 3, 3, 6, 59, 0, 0, ny)
+	morning := time.Date(2026, 3, 3, 7, 0, 0, 0, ny)
+
+	tests := []struct {
+		name  string
+		event notifications.Event
+		now   time.Time
+		want  Channel
+	}{
+		{"default push", medicationDue("child-2"), afternoon, ChannelPush},
+		{"quiet hours before midnight", medicationDue("child-2"), night, ChannelNone},
+		{"quiet hours after midnight", medicationDue("child-2"), earlyMorning, ChannelNone},
+		{"quiet hours end", medicationDue("child-2"), morning, ChannelPush},
+		{"muted child", medicationDue("child-1"), afternoon, ChannelNone},
+		{"routed to email", notifications.Event{Type: notifications.EventSleepInsight}, afternoon, ChannelEmail},
+		{"email ignores quiet hours", notifications.Event{Type: notifications.EventSleepInsight}, night, ChannelEmail},
+		{"presence always delivered", notifications.Event{Type: notifications.EventPresence, ChildID: "child-1"}, night, ChannelPush},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := svc.Route(context.Background(), "user-1", tt.event, tt.now); got != tt.want {
+				t.Errorf("Route() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Route_RepoErrorDelivers(t *testing.T) {
+	repo := newMockRepository()
+	repo.getErr = errors.New("database error")
+	svc := NewService(repo, &mockFamilyService{})
+
+	if got := svc.Route(context.Background(), "user-1", medicationDue("child-1"), time.Now()); got != ChannelPush {
+		t.Errorf("Route() = %v, want %v", got, ChannelPush)
+	}
+}
+
+func TestHubFilter(t *testing.T) {
+	svc := NewService(newMockRepository(), &mockFamilyService{})
+	svc.Update(context.Background(), "user-1", &UpdatePreferencesRequest{
+		Channels: map[notifications.EventType]Channel{notifications.EventMedicationDue: ChannelEmail},
+	})
+
+	filter := HubFilter(svc)
+	if filter.Allow("user-1", medicationDue("child-1")) {
+		t.Error("Allow() should hold back events routed to email")
+	}
+	if !filter.Allow("user-2", medicationDue("child-1")) {
+		t.Error("Allow() should deliver with default preferences")
+	}
+}
@@ -0,0 +1,80 @@
+package presence
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.list)
+	rg.POST("", h.heartbeat)
+	rg.DELETE("", h.end)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	childID := c.Query("child_id")
+	if childID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "child_id is required"})
+		return
+	}
+
+	presences, err := h.service.List(c.Request.Context(), c.GetString("user_id"), childID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, presences)
+}
+
+func (h *Handler) heartbeat(c *gin.Context) {
+	var req HeartbeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.service.Heartbeat(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *Handler) end(c *gin.Context) {
+	childID := c.Query("child_id")
+	activity := c.Query("activity")
+	if childID == "" || activity == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "child_id and activity are required"})
+		return
+	}
+
+	if err := h.service.End(c.Request.Context(), c.GetString("user_id"), childID, Activity(activity)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidActivity):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,147 @@
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	heartbeatFn func(ctx context.Context, userID string, req *HeartbeatRequest) (*Presence, error)
+	endFn       func(ctx context.Context, userID, childID string, activity Activity) error
+	listFn      func(ctx context.Context, userID, childID string) ([]Presence, error)
+}
+
+func (m *mockService) Heartbeat(ctx context.Context, userID string, req *HeartbeatRequest) (*Presence, error) {
+	if m.heartbeatFn != nil {
+		return m.heartbeatFn(ctx, userID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) End(ctx context.Context, userID, childID string, activity Activity) error {
+	if m.endFn != nil {
+		return m.endFn(ctx, userID, childID, activity)
+	}
+	return nil
+}
+
+func (m *mockService) List(ctx context.Context, userID, childID string) ([]Presence, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, childID)
+	}
+	return []Presence{}, nil
+}
+
+func (m *mockService) Expire(ctx context.Context, now time.Time) (int, error) {
+	return 0, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterRoutes(router.Group("/presence"))
+	return router
+}
+
+func TestHeartbeat_Success(t *testing.T) {
+	var captured *HeartbeatRequest
+	svc := &mockService{
+		heartbeatFn: func(ctx context.Context, userID string, req *HeartbeatRequest) (*Presence, error) {
+			captured = req
+			return &Presence{UserID: userID, ChildID: req.ChildID, Activity: req.Activity, Active: true}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"child_id":"child-1","activity":"sleep_timer"}`
+	req := httptest.NewRequest("POST", "/presence", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if captured == nil || captured.Activity != ActivitySleepTimer {
+		t.Errorf("Unexpected request %+v", captured)
+	}
+
+	var p Presence
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if p.UserID != "test-user-123" || !p.Active {
+		t.Errorf("Unexpected presence %+v", p)
+	}
+}
+
+func TestHeartbeat_NotMember(t *testing.T) {
+	svc := &mockService{
+		heartbeatFn: func(ctx context.Context, userID string, req *HeartbeatRequest) (*Presence, error) {
+			return nil, ErrNotMember
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/presence", strings.NewReader(`{"child_id":"child-1","activity":"logging"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestList_RequiresChildID(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/presence", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestEnd_Success(t *testing.T) {
+	var gotChild string
+	var gotActivity Activity
+	svc := &mockService{
+		endFn: func(ctx context.Context, userID, childID string, activity Activity) error {
+			gotChild, gotActivity = childID, activity
+			return nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("DELETE", "/presence?child_id=child-1&activity=typing_note", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if gotChild != "child-1" || gotActivity != ActivityTypingNote {
+		t.Errorf("End() called with %s/%s", gotChild, gotActivity)
+	}
+}
@@ -0,0 +1,27 @@
+package presence
+
+import "time"
+
+type Activity string
+
+const (
+	ActivityTypingNote Activity = "typing_note"
+	ActivitySleepTimer Activity = "sleep_timer"
+	ActivityLogging    Activity = "logging"
+)
+
+// Presence says a family member is working on a child's records right now.
+// Active is false on the event sent when they stop or their heartbeat lapses.
+type Presence struct {
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	ChildID   string    `json:"child_id"`
+	Activity  Activity  `json:"activity"`
+	Active    bool      `json:"active"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type HeartbeatRequest struct {
+	ChildID  string   `json:"child_id" binding:"required"`
+	Activity Activity `json:"activity" binding:"required"`
+}
@@ -0,0 +1,212 @@
+// Package presence tracks which family members are logging for a child right
+// now, so co-caregivers don't enter the same feed or nap twice. State is kept
+// in memory and lapses unless the client keeps sending heartbeats.
+package presence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+var (
+	ErrChildNotFound   = errors.New("child not found")
+	ErrNotMember       = errors.New("user is not a member of this family")
+	ErrInvalidActivity = errors.New("invalid activity")
+)
+
+// TTL is how long a presence lasts without a heartbeat
+const TTL = 30 * time.Second
+
+var validActivities = []Activity{ActivityTypingNote, ActivitySleepTimer, ActivityLogging}
+
+// Notifier delivers presence changes to the other members of a family
+type Notifier interface {
+	SendToUsers(userIDs []string, event notifications.Event)
+}
+
+type Service interface {
+	Heartbeat(ctx context.Context, userID string, req *HeartbeatRequest) (*Presence, error)
+	End(ctx context.Context, userID, childID string, activity Activity) error
+	List(ctx context.Context, userID, childID string) ([]Presence, error)
+	// Expire drops presences whose heartbeat lapsed before now and returns how many ended
+	Expire(ctx context.Context, now time.Time) (int, error)
+}
+
+type key struct {
+	userID   string
+	childID  string
+	activity Activity
+}
+
+type entry struct {
+	presence Presence
+	familyID string
+}
+
+type service struct {
+	familyService family.Service
+	notifier      Notifier
+
+	mu     sync.Mutex
+	active map[key]entry
+}
+
+func NewService(familyService family.Service, notifier Notifier) Service {
+	return &service{
+		familyService: familyService,
+		notifier:      notifier,
+		active:        make(map[key]entry),
+	}
+}
+
+func (s *service) Heartbeat(ctx context.Context, userID string, req *HeartbeatRequest) (*Presence, error) {
+	if !slices.Contains(validActivities, req.Activity) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidActivity, req.Activity)
+	}
+
+	familyID, members, err := s.familyOf(ctx, userID, req.ChildID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := Presence{
+		UserID:    userID,
+		ChildID:   req.ChildID,
+		Activity:  req.Activity,
+		Active:    true,
+		ExpiresAt: time.Now().Add(TTL),
+	}
+	for _, m := range members {
+		if m.UserID == userID {
+			p.Name = m.Name
+		}
+	}
+
+	s.mu.Lock()
+	s.active[key{userID, req.ChildID, req.Activity}] = entry{presence: p, familyID: familyID}
+	s.mu.Unlock()
+
+	s.notify(members, p)
+	return &p, nil
+}
+
+func (s *service) End(ctx context.Context, userID, childID string, activity Activity) error {
+	k := key{userID, childID, activity}
+
+	s.mu.Lock()
+	e, ok := s.active[k]
+	delete(s.active, k)
+	s.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return s.notifyEnded(ctx, e)
+}
+
+func (s *service) List(ctx context.Context, userID, childID string) ([]Presence, error) {
+	if _, _, err := s.familyOf(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	presences := []Presence{}
+
+	s.mu.Lock()
+	for k, e := range s.active {
+		if k.childID == childID && k.userID != userID && e.presence.ExpiresAt.After(now) {
+			presences = append(presences, e.presence)
+		}
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(presences, func(a, b Presence) int {
+		if c := strings.Compare(a.UserID, b.UserID); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.Activity), string(b.Activity))
+	})
+	return presences, nil
+}
+
+func (s *service) Expire(ctx context.Context, now time.Time) (int, error) {
+	var expired []entry
+
+	s.mu.Lock()
+	for k, e := range s.active {
+		if !e.presence.ExpiresAt.After(now) {
+			expired = append(expired, e)
+			delete(s.active, k)
+		}
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, e := range expired {
+		if err := s.notifyEnded(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(expired), errors.Join(errs...)
+}
+
+// familyOf checks the user belongs to the child's family and returns the
+// family with its members, who are the audience for presence events.
+func (s *service) familyOf(ctx context.Context, userID, childID string) (string, []family.MemberWithUser, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return "", nil, ErrChildNotFound
+	}
+
+	members, err := s.familyService.GetFamilyMembers(ctx, child.FamilyID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get family members: %w", err)
+	}
+	if !slices.ContainsFunc(members, func(m family.MemberWithUser) bool { return m.UserID == userID }) {
+		return "", nil, ErrNotMember
+	}
+	return child.FamilyID, members, nil
+}
+
+func (s *service) notifyEnded(ctx context.Context, e entry) error {
+	members, err := s.familyService.GetFamilyMembers(ctx, e.familyID)
+	if err != nil {
+		return fmt.Errorf("failed to get family members: %w", err)
+	}
+
+	p := e.presence
+	p.Active = false
+	p.ExpiresAt = time.Now()
+	s.notify(members, p)
+	return nil
+}
+
+// notify sends the presence to everyone in the family except its owner
+func (s *service) notify(members []family.MemberWithUser, p Presence) {
+	recipients := make([]string, 0, len(members))
+	for _, m := range members {
+		if m.UserID != p.UserID {
+			recipients = append(recipients, m.UserID)
+		}
+	}
+
+	s.notifier.SendToUsers(recipients, notifications.Event{
+		ID:        ids.New(),
+		Type:      notifications.EventPresence,
+		ChildID:   p.ChildID,
+		Timestamp: time.Now(),
+		Data:      p,
+	})
+}
@@ -0,0 +1,158 @@
+package presence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" {
+		return &family.Child{ID: "child-1", FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetFamilyMembers(ctx context.Context, familyID string) ([]family.MemberWithUser, error) {
+	return []family.MemberWithUser{
+		{UserID: "user-1", Name: "Sam"},
+		{UserID: "user-2", Name: "Alex"},
+	}, nil
+}
+
+type sentEvent struct {
+	userIDs []string
+	event   notifications.Event
+}
+
+type mockNotifier struct {
+	sent []sentEvent
+}
+
+func (m *mockNotifier) SendToUsers(userIDs []string, event notifications.Event) {
+	m.sent = append(m.sent, sentEvent{userIDs: userIDs, event: event})
+}
+
+func TestService_Heartbeat(t *testing.T) {
+	notifier := &mockNotifier{}
+	svc := NewService(&mockFamilyService{}, notifier)
+
+	p, err := svc.Heartbeat(context.Background(), "user-1", &HeartbeatRequest{ChildID: "child-1", Activity: ActivitySleepTimer})
+	if err != nil {
+		t.Fatalf("Heartbeat() error = %v", err)
+	}
+	if p.Name != "Sam" || !p.Active || time.Until(p.ExpiresAt) <= 0 {
+		t.Errorf("Heartbeat() = %+v, want an active presence for Sam", p)
+	}
+
+	if len(notifier.sent) != 1 {
+		t.Fatalf("Heartbeat() sent %d events, want 1", len(notifier.sent))
+	}
+	sent := notifier.sent[0]
+	if len(sent.userIDs) != 1 || sent.userIDs[0] != "user-2" {
+		t.Errorf("Heartbeat() notified %v, want only the other member", sent.userIDs)
+	}
+	if sent.event.Type != notifications.EventPresence || sent.event.ChildID != "child-1" {
+		t.Errorf("Heartbeat() sent %+v, want a presence event for child-1", sent.event)
+	}
+
+	others, err := svc.List(context.Background(), "user-2", "child-1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(others) != 1 || others[0].UserID != "user-1" {
+		t.Errorf("List() = %+v, want user-1's presence", others)
+	}
+
+	own, _ := svc.List(context.Background(), "user-1", "child-1")
+	if len(own) != 0 {
+		t.Errorf("List() for the owner = %+v, want none", own)
+	}
+}
+
+func TestService_Heartbeat_Errors(t *testing.T) {
+	svc := NewService(&mockFamilyService{}, &mockNotifier{})
+
+	tests := []struct {
+		name    string
+		userID  string
+		req     *HeartbeatRequest
+		wantErr error
+	}{
+		{"invalid activity", "user-1", &HeartbeatRequest{ChildID: "child-1", Activity: "dancing"}, ErrInvalidActivity},
+		{"unknown child", "user-1", &HeartbeatRequest{ChildID: "child-9", Activity: ActivityLogging}, ErrChildNotFound},
+		{"not a member", "user-3", &HeartbeatRequest{ChildID: "child-1", Activity: ActivityLogging}, ErrNotMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Heartbeat(context.Background(), tt.userID, tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Heartbeat() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_End(t *testing.T) {
+	notifier := &mockNotifier{}
+	svc := NewService(&mockFamilyService{}, notifier)
+	ctx := context.Background()
+
+	if _, err := svc.Heartbeat(ctx, "user-1", &HeartbeatRequest{ChildID: "child-1", Activity: ActivityTypingNote}); err != nil {
+		t.Fatalf("Heartbeat() error = %v", err)
+	}
+	if err := svc.End(ctx, "user-1", "child-1", ActivityTypingNote); err != nil {
+		t.Fatalf("End() error = %v", err)
+	}
+
+	if len(notifier.sent) != 2 {
+		t.Fatalf("End() sent %d events in total, want 2", len(notifier.sent))
+	}
+	if ended, ok := notifier.sent[1].event.Data.(Presence); !ok || ended.Active {
+		t.Errorf("End() sent %+v, want an inactive presence", notifier.sent[1].event.Data)
+	}
+
+	// Ending again is a no-op
+	if err := svc.End(ctx, "user-1", "child-1", ActivityTypingNote); err != nil {
+		t.Errorf("End() again error = %v", err)
+	}
+	if len(notifier.sent) != 2 {
+		t.Errorf("End() again sent another event")
+	}
+}
+
+func TestService_Expire(t *testing.T) {
+	notifier := &mockNotifier{}
+	svc := NewService(&mockFamilyService{}, notifier)
+	ctx := context.Background()
+
+	if _, err := svc.Heartbeat(ctx, "user-1", &HeartbeatRequest{ChildID: "child-1", Activity: ActivityLogging}); err != nil {
+		t.Fatalf("Heartbeat() error = %v", err)
+	}
+
+	ended, err := svc.Expire(ctx, time.Now())
+	if err != nil || ended != 0 {
+		t.Fatalf("Expire(now) = %d, %v; want nothing expired yet", ended, err)
+	}
+
+	ended, err = svc.Expire(ctx, time.Now().Add(TTL+time.Second))
+	if err != nil || ended != 1 {
+		t.Fatalf("Expire(after TTL) = %d, %v; want 1", ended, err)
+	}
+
+	others, _ := svc.List(ctx, "user-2", "child-1")
+	if len(others) != 0 {
+		t.Errorf("List() after expiry = %+v, want none", others)
+	}
+	if len(notifier.sent) != 2 {
+		t.Errorf("Expire() should notify the family that the presence ended")
+	}
+}
@@ -0,0 +1,31 @@
+// Package profiling serves Go runtime profiles to operators.
+package profiling
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct{}
+
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// RegisterAdminRoutes registers the net/http/pprof endpoints. The group is
+// expected to carry the admin token check.
+func (h *Handler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/", gin.WrapF(pprof.Index))
+	rg.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	rg.GET("/profile", gin.WrapF(pprof.Profile))
+	rg.GET("/symbol", gin.WrapF(pprof.Symbol))
+	rg.POST("/symbol", gin.WrapF(pprof.Symbol))
+	rg.GET("/trace", gin.WrapF(pprof.Trace))
+	// heap, goroutine, allocs, block, mutex and threadcreate
+	rg.GET("/:profile", h.lookup)
+}
+
+func (h *Handler) lookup(c *gin.Context) {
+	pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+}
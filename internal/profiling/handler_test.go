@@ -0,0 +1,82 @@
+package profiling
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/maintenance"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func setupRouter(token string) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	admin := maintenance.NewHandler(maintenance.NewSwitch(false, "", 0), token)
+	NewHandler().RegisterAdminRoutes(router.Group("/admin/debug/pprof", admin.RequireAdmin))
+	return router
+}
+
+func TestIndex(t *testing.T) {
+	router := setupRouter("operator-secret")
+
+	req := httptest.NewRequest("GET", "/admin/debug/pprof/", http.NoBody)
+	req.Header.Set(maintenance.HeaderAdminToken, "operator-secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "goroutine") {
+		t.Error("Expected the index to list the goroutine profile")
+	}
+}
+
+func TestNamedProfile(t *testing.T) {
+	router := setupRouter("operator-secret")
+
+	req := httptest.NewRequest("GET", "/admin/debug/pprof/goroutine?debug=1", http.NoBody)
+	req.Header.Set(maintenance.HeaderAdminToken, "operator-secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Errorf("Expected a goroutine dump, got %q", w.Body.String())
+	}
+}
+
+func TestUnknownProfile(t *testing.T) {
+	router := setupRouter("operator-secret")
+
+	req := httptest.NewRequest("GET", "/admin/debug/pprof/nope", http.NoBody)
+	req.Header.Set(maintenance.HeaderAdminToken, "operator-secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestRequiresToken(t *testing.T) {
+	router := setupRouter("operator-secret")
+
+	req := httptest.NewRequest("GET", "/admin/debug/pprof/heap", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
@@ -0,0 +1,190 @@
+package pumping
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/occurrence"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterFamilyRoutes registers sessions and the milk stash on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/pumping", h.listSessions)
+	rg.POST("/:familyId/pumping", h.createSession)
+	rg.POST("/:familyId/pumping/start", h.startSession)
+	rg.POST("/:familyId/pumping/:sessionId/stop", h.stopSession)
+	rg.DELETE("/:familyId/pumping/:sessionId", h.deleteSession)
+
+	rg.GET("/:familyId/milk-stash", h.inventory)
+	rg.POST("/:familyId/milk-stash", h.addItem)
+	rg.POST("/:familyId/milk-stash/:itemId/thaw", h.thaw)
+	rg.POST("/:familyId/milk-stash/:itemId/consume", h.consume)
+	rg.DELETE("/:familyId/milk-stash/:itemId", h.discard)
+}
+
+func (h *Handler) listSessions(c *gin.Context) {
+	filter := &SessionFilter{FamilyID: c.Param("familyId")}
+	for param, dst := range map[string]**time.Time{"from": &filter.StartDate, "to": &filter.EndDate} {
+		v := c.Query(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+			return
+		}
+		*dst = &t
+	}
+
+	sessions, err := h.service.ListSessions(c.Request.Context(), c.GetString("user_id"), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *Handler) createSession(c *gin.Context) {
+	var req CreateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := h.service.CreateSession(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+func (h *Handler) startSession(c *gin.Context) {
+	var req StartSessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	session, err := h.service.StartSession(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+func (h *Handler) stopSession(c *gin.Context) {
+	var req StopSessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	session, err := h.service.StopSession(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), c.Param("sessionId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+func (h *Handler) deleteSession(c *gin.Context) {
+	err := h.service.DeleteSession(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), c.Param("sessionId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) inventory(c *gin.Context) {
+	inv, err := h.service.Inventory(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), c.Query("all") == "true")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, inv)
+}
+
+func (h *Handler) addItem(c *gin.Context) {
+	var req AddItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := h.service.AddItem(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *Handler) thaw(c *gin.Context) {
+	item, err := h.service.Thaw(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), c.Param("itemId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *Handler) consume(c *gin.Context) {
+	var req ConsumeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := h.service.Consume(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), c.Param("itemId"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *Handler) discard(c *gin.Context) {
+	if err := h.service.Discard(c.Request.Context(), c.GetString("user_id"), c.Param("familyId"), c.Param("itemId")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSessionNotFound), errors.Is(err, ErrItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidAmount), errors.Is(err, ErrAmountRequired), errors.Is(err, ErrInvalidExpiry),
+		errors.Is(err, occurrence.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrFeedingNotFound), errors.Is(err, ErrNotBottleFeed):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrExpired), errors.Is(err, ErrInsufficientMilk), errors.Is(err, ErrAlreadyConsumed),
+		errors.Is(err, ErrNotFrozen), errors.Is(err, ErrAlreadyRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
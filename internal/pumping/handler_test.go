@@ -0,0 +1,273 @@
+package pumping
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	createSessionFn func(ctx context.Context, userID, familyID string, req *CreateSessionRequest) (*Session, error)
+	startSessionFn  func(ctx context.Context, userID, familyID string, req *StartSessionRequest) (*Session, error)
+	stopSessionFn   func(ctx context.Context, userID, familyID, sessionID string, req *StopSessionRequest) (*Session, error)
+	inventoryFn     func(ctx context.Context, userID, familyID string, all bool) (*Inventory, error)
+	addItemFn       func(ctx context.Context, userID, familyID string, req *AddItemRequest) (*Item, error)
+	consumeFn       func(ctx context.Context, userID, familyID, itemID string, req *ConsumeRequest) (*Item, error)
+}
+
+func (m *mockService) CreateSession(ctx context.Context, userID, familyID string, req *CreateSessionRequest) (*Session, error) {
+	if m.createSessionFn != nil {
+		return m.createSessionFn(ctx, userID, familyID, req)
+	}
+	return &Session{FamilyID: familyID}, nil
+}
+
+func (m *mockService) ListSessions(ctx context.Context, userID string, filter *SessionFilter) ([]Session, error) {
+	return []Session{}, nil
+}
+
+func (m *mockService) DeleteSession(ctx context.Context, userID, familyID, sessionID string) error {
+	return nil
+}
+
+func (m *mockService) StartSession(ctx context.Context, userID, familyID string, req *StartSessionRequest) (*Session, error) {
+	if m.startSessionFn != nil {
+		return m.startSessionFn(ctx, userID, familyID, req)
+	}
+	return &Session{FamilyID: familyID, Running: true}, nil
+}
+
+func (m *mockService) StopSession(ctx context.Context, userID, familyID, sessionID string, req *StopSessionRequest) (*Session, error) {
+	if m.stopSessionFn != nil {
+		return m.stopSessionFn(ctx, userID, familyID, sessionID, req)
+	}
+	return &Session{ID: sessionID, FamilyID: familyID}, nil
+}
+
+func (m *mockService) Running(ctx context.Context, userID, familyID string) ([]Session, error) {
+	return []Session{}, nil
+}
+
+func (m *mockService) Inventory(ctx context.Context, userID, familyID string, all bool) (*Inventory, error) {
+	if m.inventoryFn != nil {
+		return m.inventoryFn(ctx, userID, familyID, all)
+	}
+	return &Inventory{FamilyID: familyID, Items: []Item{}}, nil
+}
+
+func (m *mockService) AddItem(ctx context.Context, userID, familyID string, req *AddItemRequest) (*Item, error) {
+	if m.addItemFn != nil {
+		return m.addItemFn(ctx, userID, familyID, req)
+	}
+	return &Item{FamilyID: familyID}, nil
+}
+
+func (m *mockService) Thaw(ctx context.Context, userID, familyID, itemID string) (*Item, error) {
+	return &Item{ID: itemID}, nil
+}
+
+func (m *mockService) Discard(ctx context.Context, userID, familyID, itemID string) error {
+	return nil
+}
+
+func (m *mockService) Consume(ctx context.Context, userID, familyID, itemID string, req *ConsumeRequest) (*Item, error) {
+	if m.consumeFn != nil {
+		return m.consumeFn(ctx, userID, familyID, itemID, req)
+	}
+	return &Item{ID: itemID}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+
+	handler.RegisterFamilyRoutes(router.Group("/families"))
+	return router
+}
+
+func TestCreateSession_Success(t *testing.T) {
+	var captured *CreateSessionRequest
+	svc := &mockService{
+		createSessionFn: func(ctx context.Context, userID, familyID string, req *CreateSessionRequest) (*Session, error) {
+			captured = req
+			return &Session{ID: "session-1", FamilyID: familyID}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"start_time":"2024-03-10T08:00:00Z","duration_minutes":15,"side":"left","amount":100,"store":"freezer"}`
+	req := httptest.NewRequest("POST", "/families/family-1/pumping", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", w.Code)
+	}
+	if captured == nil || captured.Side != SideLeft || captured.Store != StorageFreezer {
+		t.Errorf("Unexpected request %+v", captured)
+	}
+}
+
+func TestCreateSession_InvalidStorage(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	body := `{"start_time":"2024-03-10T08:00:00Z","amount":100,"store":"counter"}`
+	req := httptest.NewRequest("POST", "/families/family-1/pumping", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestStartSession(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+		want int
+	}{
+		{"no body", "", nil, http.StatusCreated},
+		{"side", `{"side":"right"}`, nil, http.StatusCreated},
+		{"invalid side", `{"side":"middle"}`, nil, http.StatusBadRequest},
+		{"already running", "", ErrAlreadyRunning, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				startSessionFn: func(ctx context.Context, userID, familyID string, req *StartSessionRequest) (*Session, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &Session{ID: "session-1", FamilyID: familyID, Side: req.Side, Running: true}, nil
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/families/family-1/pumping/start", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestStopSession(t *testing.T) {
+	var captured *StopSessionRequest
+	var capturedID string
+	svc := &mockService{
+		stopSessionFn: func(ctx context.Context, userID, familyID, sessionID string, req *StopSessionRequest) (*Session, error) {
+			captured, capturedID = req, sessionID
+			return &Session{ID: sessionID, FamilyID: familyID, DurationMinutes: 20}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"amount":4,"unit":"oz","store":"fridge"}`
+	req := httptest.NewRequest("POST", "/families/family-1/pumping/session-1/stop", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if capturedID != "session-1" || captured == nil || *captured.Amount != 4 || captured.Store != StorageFridge {
+		t.Errorf("Unexpected request %s %+v", capturedID, captured)
+	}
+}
+
+func TestInventory_All(t *testing.T) {
+	var capturedAll bool
+	svc := &mockService{
+		inventoryFn: func(ctx context.Context, userID, familyID string, all bool) (*Inventory, error) {
+			capturedAll = all
+			return &Inventory{FamilyID: familyID, Items: []Item{}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/families/family-1/milk-stash?all=true", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if !capturedAll {
+		t.Error("Expected all=true to be passed through")
+	}
+}
+
+func TestConsume_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"not member", ErrNotMember, http.StatusForbidden},
+		{"missing item", ErrItemNotFound, http.StatusNotFound},
+		{"not a bottle", ErrNotBottleFeed, http.StatusUnprocessableEntity},
+		{"expired", ErrExpired, http.StatusConflict},
+		{"not enough", ErrInsufficientMilk, http.StatusConflict},
+		{"no amount", ErrAmountRequired, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				consumeFn: func(ctx context.Context, userID, familyID, itemID string, req *ConsumeRequest) (*Item, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/families/family-1/milk-stash/item-1/consume", bytes.NewBufferString(`{"feeding_id":"feed-1"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestConsume_MissingFeeding(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("POST", "/families/family-1/milk-stash/item-1/consume", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
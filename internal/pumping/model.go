@@ -0,0 +1,142 @@
+package pumping
+
+import (
+	"time"
+)
+
+type Side string
+
+const (
+	SideLeft  Side = "left"
+	SideRight Side = "right"
+	SideBoth  Side = "both"
+)
+
+// Storage is where a container of expressed milk is kept
+type Storage string
+
+const (
+	StorageFridge  Storage = "fridge"
+	StorageFreezer Storage = "freezer"
+)
+
+// How long expressed milk keeps, following the CDC's guidance. Thawed milk
+// must be used within a day of leaving the freezer.
+var shelfLife = map[Storage]time.Duration{
+	StorageFridge:  4 * 24 * time.Hour,
+	StorageFreezer: 183 * 24 * time.Hour, // about 6 months
+}
+
+const ThawedShelfLife = 24 * time.Hour
+
+type Session struct {
+	ID              string    `json:"id"`
+	FamilyID        string    `json:"family_id"`
+	PumpedBy        string    `json:"pumped_by,omitempty"`
+	StartTime       time.Time `json:"start_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Side            Side      `json:"side"`
+	Amount          *float64  `json:"amount,omitempty"` // as entered, in Unit
+	Unit            string    `json:"unit,omitempty"`
+	// AmountMl is Amount in ml, so sessions in either unit add up
+	AmountMl *float64 `json:"amount_ml,omitempty"`
+	Notes    string   `json:"notes,omitempty"`
+	// Running is set from a timed start until the session is stopped
+	Running   bool      `json:"running"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateSessionRequest struct {
+	StartTime       time.Time `json:"start_time" binding:"required"`
+	DurationMinutes int       `json:"duration_minutes" binding:"gte=0"`
+	Side            Side      `json:"side,omitempty" binding:"omitempty,oneof=left right both"`
+	Amount          *float64  `json:"amount,omitempty"`
+	Unit            string    `json:"unit,omitempty"`
+	Notes           string    `json:"notes,omitempty"`
+	// Store puts the whole amount into the stash as one container
+	Store Storage `json:"store,omitempty" binding:"omitempty,oneof=fridge freezer"`
+}
+
+// StartSessionRequest starts timing a session now
+type StartSessionRequest struct {
+	Side  Side   `json:"side,omitempty" binding:"omitempty,oneof=left right both"`
+	Notes string `json:"notes,omitempty"`
+}
+
+// StopSessionRequest stops a running session, recording what was pumped
+type StopSessionRequest struct {
+	Amount *float64 `json:"amount,omitempty"`
+	Unit   string   `json:"unit,omitempty"`
+	// Store puts the whole amount into the stash as one container
+	Store Storage `json:"store,omitempty" binding:"omitempty,oneof=fridge freezer"`
+}
+
+type SessionFilter struct {
+	FamilyID  string
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// Item is one container of stored milk
+type Item struct {
+	ID          string     `json:"id"`
+	FamilyID    string     `json:"family_id"`
+	SessionID   string     `json:"session_id,omitempty"`
+	Storage     Storage    `json:"storage"`
+	AmountMl    float64    `json:"amount_ml"`
+	RemainingMl float64    `json:"remaining_ml"`
+	PumpedAt    time.Time  `json:"pumped_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ThawedAt    *time.Time `json:"thawed_at,omitempty"`
+	DiscardedAt *time.Time `json:"discarded_at,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	// Expired is set when the item is read past ExpiresAt
+	Expired bool `json:"expired"`
+}
+
+// Available reports whether milk can still be taken from the item at t
+func (i *Item) Available(t time.Time) bool {
+	return i.DiscardedAt == nil && i.RemainingMl > 0 && t.Before(i.ExpiresAt)
+}
+
+type AddItemRequest struct {
+	Amount  float64 `json:"amount" binding:"required"`
+	Unit    string  `json:"unit,omitempty"` // ml when empty
+	Storage Storage `json:"storage" binding:"required,oneof=fridge freezer"`
+	// PumpedAt defaults to now; expiry is counted from it unless ExpiresAt
+	// is given
+	PumpedAt  *time.Time `json:"pumped_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Notes     string     `json:"notes,omitempty"`
+}
+
+// ConsumeRequest draws milk from an item for a bottle feed. Amount defaults
+// to the feed's own amount.
+type ConsumeRequest struct {
+	FeedingID string   `json:"feeding_id" binding:"required"`
+	Amount    *float64 `json:"amount,omitempty"`
+	Unit      string   `json:"unit,omitempty"`
+}
+
+type Consumption struct {
+	ID         string    `json:"id"`
+	ItemID     string    `json:"item_id"`
+	FeedingID  string    `json:"feeding_id"`
+	AmountMl   float64   `json:"amount_ml"`
+	ConsumedBy string    `json:"consumed_by,omitempty"`
+	ConsumedAt time.Time `json:"consumed_at"`
+}
+
+// Inventory is the family's stash, soonest to expire first. Totals count
+// only milk that can still be used.
+type Inventory struct {
+	FamilyID  string  `json:"family_id"`
+	Items     []Item  `json:"items"`
+	TotalMl   float64 `json:"total_ml"`
+	FridgeMl  float64 `json:"fridge_ml"`
+	FreezerMl float64 `json:"freezer_ml"`
+}
@@ -0,0 +1,408 @@
+package pumping
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	// CreateSession stores a session, and item with it when the milk was put
+	// straight into the stash
+	CreateSession(ctx context.Context, s *Session, item *Item) error
+	GetSession(ctx context.Context, id string) (*Session, error)
+	ListSessions(ctx context.Context, filter *SessionFilter) ([]Session, error)
+	DeleteSession(ctx context.Context, id string) error
+	// StartSession stores a running session. It reports false, writing
+	// nothing, when the member already has one running in the family.
+	StartSession(ctx context.Context, s *Session) (bool, error)
+	// StopSession saves the stopped session's duration and amount, and item
+	// with it. It reports false, changing nothing, if the session had
+	// already stopped.
+	StopSession(ctx context.Context, s *Session, item *Item) (bool, error)
+	// ListRunning returns the family's running sessions, oldest first
+	ListRunning(ctx context.Context, familyID string) ([]Session, error)
+
+	CreateItem(ctx context.Context, item *Item) error
+	GetItem(ctx context.Context, id string) (*Item, error)
+	// ListItems returns the family's items by expiry; unless all is set,
+	// only those with milk left that have not been discarded
+	ListItems(ctx context.Context, familyID string, all bool) ([]Item, error)
+	// Thaw moves a frozen item to the fridge, reporting false when it is no
+	// longer in the freezer
+	Thaw(ctx context.Context, id string, at, expiresAt time.Time) (bool, error)
+	Discard(ctx context.Context, id string, at time.Time) (bool, error)
+
+	// Consume takes c.AmountMl from the item and records c. It reports false,
+	// changing nothing, when the item no longer has that much usable milk.
+	Consume(ctx context.Context, c *Consumption) (bool, error)
+	ListConsumptions(ctx context.Context, feedingID string) ([]Consumption, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the session, stash and consumption tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "pumping_sessions", "milk_stash", "milk_consumptions")
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+const sessionColumns = `id, family_id, COALESCE(pumped_by, ''), start_time, duration_minutes, side,
+	amount, unit, amount_ml, notes, running, created_at, updated_at`
+
+func scanSession(row scanner) (*Session, error) {
+	var s Session
+	var amount, amountMl sql.NullFloat64
+
+	if err := row.Scan(
+		&s.ID, &s.FamilyID, &s.PumpedBy, &s.StartTime, &s.DurationMinutes, &s.Side,
+		&amount, &s.Unit, &amountMl, &s.Notes, &s.Running, &s.CreatedAt, &s.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if amount.Valid {
+		s.Amount = &amount.Float64
+	}
+	if amountMl.Valid {
+		s.AmountMl = &amountMl.Float64
+	}
+	return &s, nil
+}
+
+func (r *repository) CreateSession(ctx context.Context, s *Session, item *Item) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit
+
+	query := `
+		INSERT INTO pumping_sessions (id, family_id, pumped_by, start_time, duration_minutes, side,
+		                              amount, unit, amount_ml, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		s.ID, s.FamilyID, s.PumpedBy, s.StartTime, s.DurationMinutes, s.Side,
+		s.Amount, s.Unit, s.AmountMl, s.Notes, s.CreatedAt, s.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if item != nil {
+		if err := insertItem(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) GetSession(ctx context.Context, id string) (*Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM pumping_sessions WHERE id = $1`
+
+	s, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return s, err
+}
+
+func (r *repository) ListSessions(ctx context.Context, filter *SessionFilter) ([]Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM pumping_sessions WHERE family_id = $1`
+	args := []any{filter.FamilyID}
+	argIndex := 2
+
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(` AND start_time >= $%d`, argIndex)
+		args = append(args, *filter.StartDate)
+		argIndex++
+	}
+
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(` AND start_time <= $%d`, argIndex)
+		args = append(args, *filter.EndDate)
+	}
+
+	query += ` ORDER BY start_time DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	sessions := []Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (r *repository) DeleteSession(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM pumping_sessions WHERE id = $1`, id)
+	return err
+}
+
+func (r *repository) StartSession(ctx context.Context, s *Session) (bool, error) {
+	// The partial unique index on running sessions turns a second start by
+	// the same member into a no-op
+	query := `
+		INSERT INTO pumping_sessions (id, family_id, pumped_by, start_time, side, notes, running, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, TRUE, $7, $8)
+		ON CONFLICT (family_id, pumped_by) WHERE running DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		s.ID, s.FamilyID, s.PumpedBy, s.StartTime, s.Side, s.Notes, s.CreatedAt, s.UpdatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *repository) StopSession(ctx context.Context, s *Session, item *Item) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit
+
+	query := `
+		UPDATE pumping_sessions
+		SET running = FALSE, duration_minutes = $2, amount = $3, unit = $4, amount_ml = $5, updated_at = $6
+		WHERE id = $1 AND running
+	`
+	result, err := tx.ExecContext(ctx, query, s.ID, s.DurationMinutes, s.Amount, s.Unit, s.AmountMl, s.UpdatedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+
+	if item != nil {
+		if err := insertItem(ctx, tx, item); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *repository) ListRunning(ctx context.Context, familyID string) ([]Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM pumping_sessions
+		WHERE family_id = $1 AND running
+		ORDER BY start_time ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	sessions := []Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+
+	return sessions, rows.Err()
+}
+
+const itemColumns = `id, family_id, COALESCE(session_id, ''), storage, amount_ml, remaining_ml, pumped_at,
+	expires_at, thawed_at, discarded_at, notes, COALESCE(created_by, ''), created_at, updated_at`
+
+func scanItem(row scanner) (*Item, error) {
+	var i Item
+	var thawedAt, discardedAt sql.NullTime
+
+	if err := row.Scan(
+		&i.ID, &i.FamilyID, &i.SessionID, &i.Storage, &i.AmountMl, &i.RemainingMl, &i.PumpedAt,
+		&i.ExpiresAt, &thawedAt, &discardedAt, &i.Notes, &i.CreatedBy, &i.CreatedAt, &i.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if thawedAt.Valid {
+		i.ThawedAt = &thawedAt.Time
+	}
+	if discardedAt.Valid {
+		i.DiscardedAt = &discardedAt.Time
+	}
+	return &i, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertItem(ctx context.Context, db execer, i *Item) error {
+	query := `
+		INSERT INTO milk_stash (id, family_id, session_id, storage, amount_ml, remaining_ml, pumped_at,
+		                        expires_at, notes, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	var sessionID *string
+	if i.SessionID != "" {
+		sessionID = &i.SessionID
+	}
+
+	_, err := db.ExecContext(ctx, query,
+		i.ID, i.FamilyID, sessionID, i.Storage, i.AmountMl, i.RemainingMl, i.PumpedAt,
+		i.ExpiresAt, i.Notes, i.CreatedBy, i.CreatedAt, i.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) CreateItem(ctx context.Context, item *Item) error {
+	return insertItem(ctx, r.db, item)
+}
+
+func (r *repository) GetItem(ctx context.Context, id string) (*Item, error) {
+	query := `SELECT ` + itemColumns + ` FROM milk_stash WHERE id = $1`
+
+	item, err := scanItem(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return item, err
+}
+
+func (r *repository) ListItems(ctx context.Context, familyID string, all bool) ([]Item, error) {
+	query := `SELECT ` + itemColumns + ` FROM milk_stash WHERE family_id = $1`
+	if !all {
+		query += ` AND discarded_at IS NULL AND remaining_ml > 0`
+	}
+	query += ` ORDER BY expires_at ASC, pumped_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	items := []Item{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *repository) Thaw(ctx context.Context, id string, at, expiresAt time.Time) (bool, error) {
+	query := `
+		UPDATE milk_stash
+		SET storage = 'fridge', thawed_at = $2, expires_at = LEAST(expires_at, $3), updated_at = $2
+		WHERE id = $1 AND storage = 'freezer' AND discarded_at IS NULL
+	`
+	return affected(r.db.ExecContext(ctx, query, id, at, expiresAt))
+}
+
+func (r *repository) Discard(ctx context.Context, id string, at time.Time) (bool, error) {
+	query := `
+		UPDATE milk_stash SET discarded_at = $2, updated_at = $2
+		WHERE id = $1 AND discarded_at IS NULL
+	`
+	return affected(r.db.ExecContext(ctx, query, id, at))
+}
+
+func (r *repository) Consume(ctx context.Context, c *Consumption) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit
+
+	// The conditions repeat the service's checks so two bottles drawn from
+	// the same container at once cannot take more than it holds
+	ok, err := affected(tx.ExecContext(ctx, `
+		UPDATE milk_stash SET remaining_ml = remaining_ml - $2, updated_at = $3
+		WHERE id = $1 AND remaining_ml >= $2 AND discarded_at IS NULL AND expires_at > $3
+	`, c.ItemID, c.AmountMl, c.ConsumedAt))
+	if err != nil || !ok {
+		return false, err
+	}
+
+	ok, err = affected(tx.ExecContext(ctx, `
+		INSERT INTO milk_consumptions (id, item_id, feeding_id, amount_ml, consumed_by, consumed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (item_id, feeding_id) DO NOTHING
+	`, c.ID, c.ItemID, c.FeedingID, c.AmountMl, c.ConsumedBy, c.ConsumedAt))
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *repository) ListConsumptions(ctx context.Context, feedingID string) ([]Consumption, error) {
+	query := `
+		SELECT id, item_id, feeding_id, amount_ml, COALESCE(consumed_by, ''), consumed_at
+		FROM milk_consumptions WHERE feeding_id = $1
+		ORDER BY consumed_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, feedingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	consumptions := []Consumption{}
+	for rows.Next() {
+		var c Consumption
+		if err := rows.Scan(&c.ID, &c.ItemID, &c.FeedingID, &c.AmountMl, &c.ConsumedBy, &c.ConsumedAt); err != nil {
+			return nil, err
+		}
+		consumptions = append(consumptions, c)
+	}
+
+	return consumptions, rows.Err()
+}
+
+func affected(result sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
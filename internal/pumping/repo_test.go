@@ -0,0 +1,226 @@
+package pumping
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_CreateSession_WithItem(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	ml := 120.0
+	session := &Session{ID: "session-1", FamilyID: "family-1", PumpedBy: "user-1", StartTime: now, Side: SideBoth,
+		Amount: &ml, Unit: "ml", AmountMl: &ml, CreatedAt: now, UpdatedAt: now}
+	item := &Item{ID: "item-1", FamilyID: "family-1", SessionID: "session-1", Storage: StorageFridge,
+		AmountMl: ml, RemainingMl: ml, PumpedAt: now, ExpiresAt: now.Add(96 * time.Hour), CreatedBy: "user-1"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO pumping_sessions").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO milk_stash").
+		WithArgs("item-1", "family-1", sqlmock.AnyArg(), StorageFridge, ml, ml, now, item.ExpiresAt, "", "user-1",
+			sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.CreateSession(context.Background(), session, item); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_StartSession_AlreadyRunning(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	session := &Session{ID: "session-1", FamilyID: "family-1", PumpedBy: "user-1", StartTime: now, Side: SideBoth,
+		Running: true, CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectExec("INSERT INTO pumping_sessions .* ON CONFLICT \\(family_id, pumped_by\\) WHERE running DO NOTHING").
+		WithArgs("session-1", "family-1", "user-1", now, SideBoth, "", now, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	started, err := repo.StartSession(context.Background(), session)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	if started {
+		t.Error("StartSession() = true, want false while another session is running")
+	}
+}
+
+func TestRepository_StopSession(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	ml := 90.0
+	session := &Session{ID: "session-1", DurationMinutes: 15, Amount: &ml, Unit: "ml", AmountMl: &ml, UpdatedAt: now}
+	item := &Item{ID: "item-1", FamilyID: "family-1", SessionID: "session-1", Storage: StorageFridge,
+		AmountMl: ml, RemainingMl: ml, PumpedAt: now, ExpiresAt: now.Add(96 * time.Hour)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE pumping_sessions .* WHERE id = \\$1 AND running").
+		WithArgs("session-1", 15, &ml, "ml", &ml, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO milk_stash").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	stopped, err := repo.StopSession(context.Background(), session, item)
+	if err != nil || !stopped {
+		t.Fatalf("StopSession() = %v, %v; want true", stopped, err)
+	}
+
+	// Already stopped: the milk isn't stored twice
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE pumping_sessions").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	stopped, err = repo.StopSession(context.Background(), session, item)
+	if err != nil || stopped {
+		t.Errorf("StopSession() = %v, %v; want false", stopped, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetItem_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("FROM milk_stash WHERE id").
+		WithArgs("item-1").
+		WillReturnError(sql.ErrNoRows)
+
+	item, err := repo.GetItem(context.Background(), "item-1")
+	if err != nil {
+		t.Fatalf("GetItem() error = %v", err)
+	}
+	if item != nil {
+		t.Errorf("GetItem() = %+v, want nil", item)
+	}
+}
+
+func TestRepository_ListItems(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "family_id", "session_id", "storage", "amount_ml", "remaining_ml", "pumped_at",
+		"expires_at", "thawed_at", "discarded_at", "notes", "created_by", "created_at", "updated_at"}).
+		AddRow("item-1", "family-1", "", "freezer", 150.0, 150.0, now, now.Add(time.Hour), nil, nil, "", "user-1", now, now).
+		AddRow("item-2", "family-1", "session-1", "fridge", 120.0, 30.0, now, now.Add(2*time.Hour), now, nil, "", "", now, now)
+
+	mock.ExpectQuery("discarded_at IS NULL AND remaining_ml > 0").
+		WithArgs("family-1").
+		WillReturnRows(rows)
+
+	items, err := repo.ListItems(context.Background(), "family-1", false)
+	if err != nil {
+		t.Fatalf("ListItems() error = %v", err)
+	}
+	if len(items) != 2 || items[0].ThawedAt != nil || items[1].ThawedAt == nil || items[1].SessionID != "session-1" {
+		t.Errorf("ListItems() = %+v", items)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Consume(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	c := &Consumption{ID: "c-1", ItemID: "item-1", FeedingID: "feed-1", AmountMl: 90, ConsumedBy: "user-1", ConsumedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE milk_stash SET remaining_ml = remaining_ml - \\$2").
+		WithArgs("item-1", 90.0, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO milk_consumptions").
+		WithArgs("c-1", "item-1", "feed-1", 90.0, "user-1", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	ok, err := repo.Consume(context.Background(), c)
+	if err != nil || !ok {
+		t.Fatalf("Consume() = %v, %v, want true", ok, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Consume_NotEnough(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE milk_stash").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	ok, err := repo.Consume(context.Background(), &Consumption{ItemID: "item-1", AmountMl: 500, ConsumedAt: time.Now()})
+	if err != nil || ok {
+		t.Fatalf("Consume() = %v, %v, want false", ok, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Consume_RollsBack(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE milk_stash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO milk_consumptions").
+		WillReturnError(errors.New("database error"))
+	mock.ExpectRollback()
+
+	if _, err := repo.Consume(context.Background(), &Consumption{ItemID: "item-1", AmountMl: 50, ConsumedAt: time.Now()}); err == nil {
+		t.Error("Consume() should return error")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
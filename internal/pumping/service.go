@@ -0,0 +1,511 @@
+// Package pumping logs pumping sessions and keeps the family's stash of
+// expressed milk, drawn down as bottle feeds use it.
+package pumping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/measure"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrNotMember        = errors.New("user is not a member of this family")
+	ErrSessionNotFound  = errors.New("pumping session not found")
+	ErrItemNotFound     = errors.New("milk not found")
+	ErrFeedingNotFound  = errors.New("feeding not found")
+	ErrNotBottleFeed    = errors.New("stored milk can only be used for bottle feeds")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrAmountRequired   = errors.New("an amount is required")
+	ErrInvalidExpiry    = errors.New("expiry must be after the milk was pumped")
+	ErrExpired          = errors.New("this milk has expired")
+	ErrInsufficientMilk = errors.New("not enough milk left in this container")
+	ErrAlreadyConsumed  = errors.New("this feed has already used milk from this container")
+	ErrNotFrozen        = errors.New("only frozen milk can be thawed")
+	ErrAlreadyRunning   = errors.New("you already have a pumping session running")
+)
+
+type Service interface {
+	CreateSession(ctx context.Context, userID, familyID string, req *CreateSessionRequest) (*Session, error)
+	ListSessions(ctx context.Context, userID string, filter *SessionFilter) ([]Session, error)
+	DeleteSession(ctx context.Context, userID, familyID, sessionID string) error
+	// StartSession starts timing a session for the user now
+	StartSession(ctx context.Context, userID, familyID string, req *StartSessionRequest) (*Session, error)
+	// StopSession fills in a running session's duration and what was
+	// pumped. A session that has already stopped is returned unchanged.
+	StopSession(ctx context.Context, userID, familyID, sessionID string, req *StopSessionRequest) (*Session, error)
+	// Running lists the family's sessions still being timed
+	Running(ctx context.Context, userID, familyID string) ([]Session, error)
+
+	// Inventory lists the stash; all includes empty and discarded items
+	Inventory(ctx context.Context, userID, familyID string, all bool) (*Inventory, error)
+	AddItem(ctx context.Context, userID, familyID string, req *AddItemRequest) (*Item, error)
+	Thaw(ctx context.Context, userID, familyID, itemID string) (*Item, error)
+	Discard(ctx context.Context, userID, familyID, itemID string) error
+	// Consume draws milk from an item for one of the family's bottle feeds
+	// and returns the item with what is left
+	Consume(ctx context.Context, userID, familyID, itemID string, req *ConsumeRequest) (*Item, error)
+}
+
+type service struct {
+	repo           Repository
+	familyService  family.Service
+	feedingService feeding.Service
+	now            func() time.Time
+}
+
+func NewService(repo Repository, familyService family.Service, feedingService feeding.Service) Service {
+	return &service{
+		repo:           repo,
+		familyService:  familyService,
+		feedingService: feedingService,
+		now:            time.Now,
+	}
+}
+
+// HealthCheck reports whether sessions and the stash can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) CreateSession(ctx context.Context, userID, familyID string, req *CreateSessionRequest) (*Session, error) {
+	if err := s.requireMember(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	occurrence.Adjust(ctx, "start_time", &req.StartTime, now)
+	if err := occurrence.Validate(req.StartTime, now); err != nil {
+		return nil, err
+	}
+
+	side := req.Side
+	if side == "" {
+		side = SideBoth
+	}
+
+	session := &Session{
+		ID:              ids.New(),
+		FamilyID:        familyID,
+		PumpedBy:        userID,
+		StartTime:       req.StartTime,
+		DurationMinutes: req.DurationMinutes,
+		Side:            side,
+		Notes:           req.Notes,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	item, err := setAmount(session, req.Amount, req.Unit, req.Store, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateSession(ctx, session, item); err != nil {
+		return nil, fmt.Errorf("failed to create pumping session: %w", err)
+	}
+	return session, nil
+}
+
+func (s *service) StartSession(ctx context.Context, userID, familyID string, req *StartSessionRequest) (*Session, error) {
+	if err := s.requireMember(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	side := req.Side
+	if side == "" {
+		side = SideBoth
+	}
+
+	now := s.now()
+	session := &Session{
+		ID:        ids.New(),
+		FamilyID:  familyID,
+		PumpedBy:  userID,
+		StartTime: now,
+		Side:      side,
+		Notes:     strings.TrimSpace(req.Notes),
+		Running:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	started, err := s.repo.StartSession(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start pumping session: %w", err)
+	}
+	if !started {
+		return nil, ErrAlreadyRunning
+	}
+	return session, nil
+}
+
+func (s *service) StopSession(ctx context.Context, userID, familyID, sessionID string, req *StopSessionRequest) (*Session, error) {
+	session, err := s.familySession(ctx, userID, familyID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !session.Running {
+		return session, nil
+	}
+
+	now := s.now()
+	session.Running = false
+	session.DurationMinutes = int(math.Round(now.Sub(session.StartTime).Minutes()))
+	session.UpdatedAt = now
+	item, err := setAmount(session, req.Amount, req.Unit, req.Store, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	stopped, err := s.repo.StopSession(ctx, session, item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stop pumping session: %w", err)
+	}
+	if !stopped {
+		// Someone else stopped it first; what they recorded stands
+		return s.familySession(ctx, userID, familyID, sessionID)
+	}
+	return session, nil
+}
+
+func (s *service) Running(ctx context.Context, userID, familyID string) ([]Session, error) {
+	if err := s.requireMember(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.ListRunning(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list running pumping sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// setAmount records what was pumped on the session, and returns the stash
+// item to store it as when store is set
+func setAmount(session *Session, amount *float64, unit string, store Storage, userID string, now time.Time) (*Item, error) {
+	if amount != nil {
+		ml, unit, err := toMl(*amount, unit)
+		if err != nil {
+			return nil, err
+		}
+		session.Amount = amount
+		session.Unit = unit
+		session.AmountMl = &ml
+	}
+
+	if store == "" {
+		return nil, nil
+	}
+	if session.AmountMl == nil {
+		return nil, fmt.Errorf("%w to store milk", ErrAmountRequired)
+	}
+	return &Item{
+		ID:          ids.New(),
+		FamilyID:    session.FamilyID,
+		SessionID:   session.ID,
+		Storage:     store,
+		AmountMl:    *session.AmountMl,
+		RemainingMl: *session.AmountMl,
+		PumpedAt:    session.StartTime,
+		ExpiresAt:   session.StartTime.Add(shelfLife[store]),
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+func (s *service) ListSessions(ctx context.Context, userID string, filter *SessionFilter) ([]Session, error) {
+	if err := s.requireMember(ctx, filter.FamilyID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListSessions(ctx, filter)
+}
+
+func (s *service) DeleteSession(ctx context.Context, userID, familyID, sessionID string) error {
+	if _, err := s.familySession(ctx, userID, familyID, sessionID); err != nil {
+		return err
+	}
+
+	// Milk stored from the session stays in the stash
+	return s.repo.DeleteSession(ctx, sessionID)
+}
+
+func (s *service) Inventory(ctx context.Context, userID, familyID string, all bool) (*Inventory, error) {
+	if err := s.requireMember(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.ListItems(ctx, familyID, all)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list milk: %w", err)
+	}
+
+	now := s.now()
+	inv := &Inventory{FamilyID: familyID, Items: items}
+	for i := range inv.Items {
+		item := &inv.Items[i]
+		item.Expired = !now.Before(item.ExpiresAt)
+		if !item.Available(now) {
+			continue
+		}
+		inv.TotalMl += item.RemainingMl
+		switch item.Storage {
+		case StorageFridge:
+			inv.FridgeMl += item.RemainingMl
+		case StorageFreezer:
+			inv.FreezerMl += item.RemainingMl
+		}
+	}
+	return inv, nil
+}
+
+func (s *service) AddItem(ctx context.Context, userID, familyID string, req *AddItemRequest) (*Item, error) {
+	if err := s.requireMember(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	ml, _, err := toMl(req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	pumpedAt := now
+	if req.PumpedAt != nil {
+		pumpedAt = *req.PumpedAt
+		occurrence.Adjust(ctx, "pumped_at", &pumpedAt, now)
+		if err := occurrence.Validate(pumpedAt, now); err != nil {
+			return nil, err
+		}
+	}
+
+	expiresAt := pumpedAt.Add(shelfLife[req.Storage])
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(pumpedAt) {
+			return nil, ErrInvalidExpiry
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	item := &Item{
+		ID:          ids.New(),
+		FamilyID:    familyID,
+		Storage:     req.Storage,
+		AmountMl:    ml,
+		RemainingMl: ml,
+		PumpedAt:    pumpedAt,
+		ExpiresAt:   expiresAt,
+		Notes:       strings.TrimSpace(req.Notes),
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Expired:     !now.Before(expiresAt),
+	}
+
+	if err := s.repo.CreateItem(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to store milk: %w", err)
+	}
+	return item, nil
+}
+
+func (s *service) Thaw(ctx context.Context, userID, familyID, itemID string) (*Item, error) {
+	item, err := s.familyItem(ctx, userID, familyID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Storage != StorageFreezer || item.DiscardedAt != nil {
+		return nil, ErrNotFrozen
+	}
+
+	now := s.now()
+	ok, err := s.repo.Thaw(ctx, itemID, now, now.Add(ThawedShelfLife))
+	if err != nil {
+		return nil, fmt.Errorf("failed to thaw milk: %w", err)
+	}
+	if !ok {
+		return nil, ErrNotFrozen
+	}
+	return s.reload(ctx, itemID)
+}
+
+func (s *service) Discard(ctx context.Context, userID, familyID, itemID string) error {
+	if _, err := s.familyItem(ctx, userID, familyID, itemID); err != nil {
+		return err
+	}
+
+	// Discarding twice is harmless, so a false here is not an error
+	if _, err := s.repo.Discard(ctx, itemID, s.now()); err != nil {
+		return fmt.Errorf("failed to discard milk: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Consume(ctx context.Context, userID, familyID, itemID string, req *ConsumeRequest) (*Item, error) {
+	item, err := s.familyItem(ctx, userID, familyID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := s.familyFeeding(ctx, userID, familyID, req.FeedingID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ml float64
+	switch {
+	case req.Amount != nil:
+		ml, _, err = toMl(*req.Amount, req.Unit)
+	case feed.Amount != nil:
+		ml, _, err = toMl(*feed.Amount, feed.Unit)
+	default:
+		err = fmt.Errorf("%w: the feed has no amount", ErrAmountRequired)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if !now.Before(item.ExpiresAt) {
+		return nil, ErrExpired
+	}
+	if item.DiscardedAt != nil || item.RemainingMl < ml {
+		return nil, fmt.Errorf("%w: %g ml left", ErrInsufficientMilk, item.RemainingMl)
+	}
+
+	used, err := s.repo.ListConsumptions(ctx, feed.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list milk used: %w", err)
+	}
+	for _, c := range used {
+		if c.ItemID == itemID {
+			return nil, ErrAlreadyConsumed
+		}
+	}
+
+	ok, err := s.repo.Consume(ctx, &Consumption{
+		ID:         ids.New(),
+		ItemID:     itemID,
+		FeedingID:  feed.ID,
+		AmountMl:   ml,
+		ConsumedBy: userID,
+		ConsumedAt: now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to use milk: %w", err)
+	}
+	if !ok {
+		// Another feed got there first
+		return nil, ErrInsufficientMilk
+	}
+	return s.reload(ctx, itemID)
+}
+
+// familyItem checks membership and loads one of the family's items
+func (s *service) familySession(ctx context.Context, userID, familyID, sessionID string) (*Session, error) {
+	if err := s.requireMember(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pumping session: %w", err)
+	}
+	if session == nil || session.FamilyID != familyID {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (s *service) familyItem(ctx context.Context, userID, familyID, itemID string) (*Item, error) {
+	if err := s.requireMember(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	item, err := s.repo.GetItem(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milk: %w", err)
+	}
+	if item == nil || item.FamilyID != familyID {
+		return nil, ErrItemNotFound
+	}
+	return item, nil
+}
+
+// familyFeeding loads a bottle feed given to one of the family's children.
+// A feed hidden from the user is reported as not found.
+func (s *service) familyFeeding(ctx context.Context, userID, familyID, feedingID string) (*feeding.Feeding, error) {
+	feed, err := s.feedingService.Get(ctx, userID, feedingID)
+	if errors.Is(err, visibility.ErrHidden) {
+		return nil, ErrFeedingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feeding: %w", err)
+	}
+	if feed == nil {
+		return nil, ErrFeedingNotFound
+	}
+
+	child, err := s.familyService.GetChild(ctx, feed.ChildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil || child.FamilyID != familyID {
+		return nil, ErrFeedingNotFound
+	}
+
+	if feed.Type != feeding.FeedingTypeBottle {
+		return nil, ErrNotBottleFeed
+	}
+	return feed, nil
+}
+
+func (s *service) reload(ctx context.Context, itemID string) (*Item, error) {
+	item, err := s.repo.GetItem(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milk: %w", err)
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	item.Expired = !s.now().Before(item.ExpiresAt)
+	return item, nil
+}
+
+func (s *service) requireMember(ctx context.Context, familyID, userID string) error {
+	if _, err := s.familyService.GetMemberRole(ctx, familyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	return nil
+}
+
+// toMl converts a volume to ml, to the precision stored. It also returns the
+// unit in its canonical spelling; amounts without one are taken as ml.
+func toMl(amount float64, unit string) (float64, string, error) {
+	u := measure.ParseUnit(unit)
+	if u == "" {
+		u = measure.Milliliter
+	}
+	q := measure.Quantity{Value: amount, Unit: u}
+	if err := q.Validate(); err != nil {
+		return 0, "", fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+	ml, err := q.In(measure.Milliliter)
+	if err != nil {
+		return 0, "", fmt.Errorf("%w: %q is not a volume", ErrInvalidAmount, unit)
+	}
+	return ml.Round(2).Value, string(u), nil
+}
@@ -0,0 +1,462 @@
+package pumping
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+)
+
+type mockRepository struct {
+	sessions     map[string]*Session
+	items        map[string]*Item
+	consumptions []Consumption
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		sessions: make(map[string]*Session),
+		items:    make(map[string]*Item),
+	}
+}
+
+func (m *mockRepository) CreateSession(ctx context.Context, s *Session, item *Item) error {
+	copied := *s
+	m.sessions[s.ID] = &copied
+	if item != nil {
+		return m.CreateItem(ctx, item)
+	}
+	return nil
+}
+
+func (m *mockRepository) GetSession(ctx context.Context, id string) (*Session, error) {
+	if s, ok := m.sessions[id]; ok {
+		copied := *s
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListSessions(ctx context.Context, filter *SessionFilter) ([]Session, error) {
+	sessions := []Session{}
+	for _, s := range m.sessions {
+		if s.FamilyID == filter.FamilyID {
+			sessions = append(sessions, *s)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockRepository) DeleteSession(ctx context.Context, id string) error {
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *mockRepository) StartSession(ctx context.Context, s *Session) (bool, error) {
+	for _, other := range m.sessions {
+		if other.Running && other.FamilyID == s.FamilyID && other.PumpedBy == s.PumpedBy {
+			return false, nil
+		}
+	}
+	return true, m.CreateSession(ctx, s, nil)
+}
+
+func (m *mockRepository) StopSession(ctx context.Context, s *Session, item *Item) (bool, error) {
+	if current, ok := m.sessions[s.ID]; !ok || !current.Running {
+		return false, nil
+	}
+	return true, m.CreateSession(ctx, s, item)
+}
+
+func (m *mockRepository) ListRunning(ctx context.Context, familyID string) ([]Session, error) {
+	sessions := []Session{}
+	for _, s := range m.sessions {
+		if s.FamilyID == familyID && s.Running {
+			sessions = append(sessions, *s)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockRepository) CreateItem(ctx context.Context, item *Item) error {
+	copied := *item
+	m.items[item.ID] = &copied
+	return nil
+}
+
+func (m *mockRepository) GetItem(ctx context.Context, id string) (*Item, error) {
+	if item, ok := m.items[id]; ok {
+		copied := *item
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListItems(ctx context.Context, familyID string, all bool) ([]Item, error) {
+	items := []Item{}
+	for _, item := range m.items {
+		if item.FamilyID == familyID && (all || item.DiscardedAt == nil && item.RemainingMl > 0) {
+			items = append(items, *item)
+		}
+	}
+	return items, nil
+}
+
+func (m *mockRepository) Thaw(ctx context.Context, id string, at, expiresAt time.Time) (bool, error) {
+	item := m.items[id]
+	if item == nil || item.Storage != StorageFreezer {
+		return false, nil
+	}
+	item.Storage = StorageFridge
+	item.ThawedAt = &at
+	if expiresAt.Before(item.ExpiresAt) {
+		item.ExpiresAt = expiresAt
+	}
+	return true, nil
+}
+
+func (m *mockRepository) Discard(ctx context.Context, id string, at time.Time) (bool, error) {
+	item := m.items[id]
+	if item == nil || item.DiscardedAt != nil {
+		return false, nil
+	}
+	item.DiscardedAt = &at
+	return true, nil
+}
+
+func (m *mockRepository) Consume(ctx context.Context, c *Consumption) (bool, error) {
+	item := m.items[c.ItemID]
+	if item == nil || item.RemainingMl < c.AmountMl {
+		return false, nil
+	}
+	item.RemainingMl -= c.AmountMl
+	m.consumptions = append(m.consumptions, *c)
+	return true, nil
+}
+
+func (m *mockRepository) ListConsumptions(ctx context.Context, feedingID string) ([]Consumption, error) {
+	consumptions := []Consumption{}
+	for _, c := range m.consumptions {
+		if c.FeedingID == feedingID {
+			consumptions = append(consumptions, c)
+		}
+	}
+	return consumptions, nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	roles    map[string]string
+	children map[string]*family.Child
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if role, ok := m.roles[familyID+"/"+userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+type mockFeedingService struct {
+	feeding.Service
+	feedings map[string]*feeding.Feeding
+}
+
+func (m *mockFeedingService) Get(ctx context.Context, userID, id string) (*feeding.Feeding, error) {
+	return m.feedings[id], nil
+}
+
+var testNow = time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestService(repo *mockRepository, feedings ...*feeding.Feeding) Service {
+	familySvc := &mockFamilyService{
+		roles: map[string]string{"family-1/user-1": "member", "family-2/user-2": "admin"},
+		children: map[string]*family.Child{
+			"child-1": {ID: "child-1", FamilyID: "family-1"},
+			"child-2": {ID: "child-2", FamilyID: "family-2"},
+		},
+	}
+	feedingSvc := &mockFeedingService{feedings: make(map[string]*feeding.Feeding)}
+	for _, f := range feedings {
+		feedingSvc.feedings[f.ID] = f
+	}
+
+	svc := NewService(repo, familySvc, feedingSvc)
+	svc.(*service).now = func() time.Time { return testNow }
+	return svc
+}
+
+func amount(v float64) *float64 {
+	return &v
+}
+
+func TestService_CreateSession_Stores(t *testing.T) {
+	repo := newMockRepository()
+	svc := newTestService(repo)
+
+	start := testNow.Add(-time.Hour)
+	session, err := svc.CreateSession(context.Background(), "user-1", "family-1", &CreateSessionRequest{
+		StartTime:       start,
+		DurationMinutes: 20,
+		Amount:          amount(4),
+		Unit:            "oz",
+		Store:           StorageFreezer,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	if session.Side != SideBoth || session.AmountMl == nil || *session.AmountMl != 118.29 {
+		t.Errorf("CreateSession() = %+v, want both sides and 118.29 ml", session)
+	}
+	if len(repo.items) != 1 {
+		t.Fatalf("CreateSession() stored %d items, want 1", len(repo.items))
+	}
+	for _, item := range repo.items {
+		if item.SessionID != session.ID || item.RemainingMl != 118.29 {
+			t.Errorf("CreateSession() item = %+v", item)
+		}
+		if !item.ExpiresAt.Equal(start.Add(shelfLife[StorageFreezer])) {
+			t.Errorf("CreateSession() item expires %v, want freezer shelf life from pumping", item.ExpiresAt)
+		}
+	}
+}
+
+func TestService_CreateSession_StoreNeedsAmount(t *testing.T) {
+	svc := newTestService(newMockRepository())
+
+	_, err := svc.CreateSession(context.Background(), "user-1", "family-1", &CreateSessionRequest{
+		StartTime: testNow,
+		Store:     StorageFridge,
+	})
+	if !errors.Is(err, ErrAmountRequired) {
+		t.Errorf("CreateSession() error = %v, want ErrAmountRequired", err)
+	}
+}
+
+func TestService_CreateSession_NotMember(t *testing.T) {
+	svc := newTestService(newMockRepository())
+
+	_, err := svc.CreateSession(context.Background(), "user-2", "family-1", &CreateSessionRequest{StartTime: testNow})
+	if !errors.Is(err, ErrNotMember) {
+		t.Errorf("CreateSession() error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestService_StartStopSession(t *testing.T) {
+	repo := newMockRepository()
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	session, err := svc.StartSession(ctx, "user-1", "family-1", &StartSessionRequest{Side: SideLeft})
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	if !session.Running || session.Side != SideLeft || !session.StartTime.Equal(testNow) {
+		t.Errorf("StartSession() = %+v", session)
+	}
+	if _, err := svc.StartSession(ctx, "user-1", "family-1", &StartSessionRequest{}); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("second StartSession() error = %v, want ErrAlreadyRunning", err)
+	}
+
+	running, err := svc.Running(ctx, "user-1", "family-1")
+	if err != nil || len(running) != 1 || running[0].ID != session.ID {
+		t.Fatalf("Running() = %+v, %v", running, err)
+	}
+
+	svc.(*service).now = func() time.Time { return testNow.Add(18*time.Minute + 40*time.Second) }
+	stopped, err := svc.StopSession(ctx, "user-1", "family-1", session.ID, &StopSessionRequest{Amount: amount(120), Store: StorageFridge})
+	if err != nil {
+		t.Fatalf("StopSession() error = %v", err)
+	}
+	if stopped.Running || stopped.DurationMinutes != 19 || *stopped.AmountMl != 120 {
+		t.Errorf("StopSession() = %+v, want 19 minutes and 120 ml", stopped)
+	}
+	if len(repo.items) != 1 {
+		t.Errorf("StopSession() stored %d items, want 1", len(repo.items))
+	}
+
+	// A second stop returns the session as it was first stopped
+	again, err := svc.StopSession(ctx, "user-1", "family-1", session.ID, &StopSessionRequest{Amount: amount(50)})
+	if err != nil || *again.AmountMl != 120 || len(repo.items) != 1 {
+		t.Errorf("second StopSession() = %+v, %v", again, err)
+	}
+	if running, _ := svc.Running(ctx, "user-1", "family-1"); len(running) != 0 {
+		t.Errorf("Running() after stop = %+v, want none", running)
+	}
+}
+
+func TestService_StopSession_Errors(t *testing.T) {
+	repo := newMockRepository()
+	repo.sessions["other"] = &Session{ID: "other", FamilyID: "family-2", Running: true}
+	repo.sessions["mine"] = &Session{ID: "mine", FamilyID: "family-1", StartTime: testNow, Running: true}
+	svc := newTestService(repo)
+
+	tests := []struct {
+		name string
+		id   string
+		req  StopSessionRequest
+		want error
+	}{
+		{"another family's session", "other", StopSessionRequest{}, ErrSessionNotFound},
+		{"unknown session", "missing", StopSessionRequest{}, ErrSessionNotFound},
+		{"store without amount", "mine", StopSessionRequest{Store: StorageFreezer}, ErrAmountRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.StopSession(context.Background(), "user-1", "family-1", tt.id, &tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("StopSession() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if !repo.sessions["mine"].Running {
+		t.Error("a refused stop should leave the session running")
+	}
+}
+
+func TestService_AddItem_RejectsMass(t *testing.T) {
+	svc := newTestService(newMockRepository())
+
+	_, err := svc.AddItem(context.Background(), "user-1", "family-1", &AddItemRequest{Amount: 100, Unit: "g", Storage: StorageFridge})
+	if !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("AddItem() error = %v, want ErrInvalidAmount", err)
+	}
+}
+
+func TestService_Inventory_Totals(t *testing.T) {
+	repo := newMockRepository()
+	repo.items["fresh"] = &Item{ID: "fresh", FamilyID: "family-1", Storage: StorageFridge, RemainingMl: 90, ExpiresAt: testNow.Add(time.Hour)}
+	repo.items["frozen"] = &Item{ID: "frozen", FamilyID: "family-1", Storage: StorageFreezer, RemainingMl: 150, ExpiresAt: testNow.Add(30 * 24 * time.Hour)}
+	repo.items["old"] = &Item{ID: "old", FamilyID: "family-1", Storage: StorageFridge, RemainingMl: 60, ExpiresAt: testNow.Add(-time.Hour)}
+	repo.items["other"] = &Item{ID: "other", FamilyID: "family-2", Storage: StorageFridge, RemainingMl: 60, ExpiresAt: testNow.Add(time.Hour)}
+	svc := newTestService(repo)
+
+	inv, err := svc.Inventory(context.Background(), "user-1", "family-1", false)
+	if err != nil {
+		t.Fatalf("Inventory() error = %v", err)
+	}
+
+	if len(inv.Items) != 3 {
+		t.Errorf("Inventory() listed %d items, want 3", len(inv.Items))
+	}
+	if inv.TotalMl != 240 || inv.FridgeMl != 90 || inv.FreezerMl != 150 {
+		t.Errorf("Inventory() totals = %v/%v/%v, want expired milk left out", inv.TotalMl, inv.FridgeMl, inv.FreezerMl)
+	}
+	for _, item := range inv.Items {
+		if item.Expired != (item.ID == "old") {
+			t.Errorf("Inventory() item %s expired = %v", item.ID, item.Expired)
+		}
+	}
+}
+
+func TestService_Thaw(t *testing.T) {
+	repo := newMockRepository()
+	repo.items["frozen"] = &Item{ID: "frozen", FamilyID: "family-1", Storage: StorageFreezer, RemainingMl: 150, ExpiresAt: testNow.Add(90 * 24 * time.Hour)}
+	svc := newTestService(repo)
+
+	item, err := svc.Thaw(context.Background(), "user-1", "family-1", "frozen")
+	if err != nil {
+		t.Fatalf("Thaw() error = %v", err)
+	}
+	if item.Storage != StorageFridge || !item.ExpiresAt.Equal(testNow.Add(ThawedShelfLife)) {
+		t.Errorf("Thaw() = %+v, want in the fridge for a day", item)
+	}
+
+	if _, err := svc.Thaw(context.Background(), "user-1", "family-1", "frozen"); !errors.Is(err, ErrNotFrozen) {
+		t.Errorf("Thaw() again error = %v, want ErrNotFrozen", err)
+	}
+}
+
+func TestService_Consume_UsesFeedAmount(t *testing.T) {
+	repo := newMockRepository()
+	repo.items["bag"] = &Item{ID: "bag", FamilyID: "family-1", Storage: StorageFridge, RemainingMl: 150, ExpiresAt: testNow.Add(time.Hour)}
+	feed := &feeding.Feeding{ID: "feed-1", ChildID: "child-1", Type: feeding.FeedingTypeBottle, Amount: amount(90), Unit: "ml"}
+	svc := newTestService(repo, feed)
+
+	item, err := svc.Consume(context.Background(), "user-1", "family-1", "bag", &ConsumeRequest{FeedingID: "feed-1"})
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if item.RemainingMl != 60 {
+		t.Errorf("Consume() left %v ml, want 60", item.RemainingMl)
+	}
+	if len(repo.consumptions) != 1 || repo.consumptions[0].ConsumedBy != "user-1" {
+		t.Errorf("Consume() recorded %+v", repo.consumptions)
+	}
+
+	_, err = svc.Consume(context.Background(), "user-1", "family-1", "bag", &ConsumeRequest{FeedingID: "feed-1", Amount: amount(10)})
+	if !errors.Is(err, ErrAlreadyConsumed) {
+		t.Errorf("Consume() twice error = %v, want ErrAlreadyConsumed", err)
+	}
+}
+
+func TestService_Consume_Rejections(t *testing.T) {
+	feeds := []*feeding.Feeding{
+		{ID: "bottle", ChildID: "child-1", Type: feeding.FeedingTypeBottle},
+		{ID: "breast", ChildID: "child-1", Type: feeding.FeedingTypeBreast},
+		{ID: "elsewhere", ChildID: "child-2", Type: feeding.FeedingTypeBottle, Amount: amount(60)},
+	}
+
+	tests := []struct {
+		name string
+		item Item
+		req  ConsumeRequest
+		want error
+	}{
+		{"expired", Item{RemainingMl: 100, ExpiresAt: testNow.Add(-time.Minute)}, ConsumeRequest{FeedingID: "bottle", Amount: amount(50)}, ErrExpired},
+		{"too much", Item{RemainingMl: 40, ExpiresAt: testNow.Add(time.Hour)}, ConsumeRequest{FeedingID: "bottle", Amount: amount(50)}, ErrInsufficientMilk},
+		{"no amount", Item{RemainingMl: 100, ExpiresAt: testNow.Add(time.Hour)}, ConsumeRequest{FeedingID: "bottle"}, ErrAmountRequired},
+		{"breastfeed", Item{RemainingMl: 100, ExpiresAt: testNow.Add(time.Hour)}, ConsumeRequest{FeedingID: "breast", Amount: amount(50)}, ErrNotBottleFeed},
+		{"other family's feed", Item{RemainingMl: 100, ExpiresAt: testNow.Add(time.Hour)}, ConsumeRequest{FeedingID: "elsewhere"}, ErrFeedingNotFound},
+		{"unknown feed", Item{RemainingMl: 100, ExpiresAt: testNow.Add(time.Hour)}, ConsumeRequest{FeedingID: "missing"}, ErrFeedingNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			item := tt.item
+			item.ID, item.FamilyID, item.Storage = "bag", "family-1", StorageFridge
+			repo.items["bag"] = &item
+			svc := newTestService(repo, feeds...)
+
+			_, err := svc.Consume(context.Background(), "user-1", "family-1", "bag", &tt.req)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Consume() error = %v, want %v", err, tt.want)
+			}
+			if repo.items["bag"].RemainingMl != tt.item.RemainingMl {
+				t.Error("Consume() should leave the item untouched")
+			}
+		})
+	}
+}
+
+func TestService_Consume_OtherFamilysItem(t *testing.T) {
+	repo := newMockRepository()
+	repo.items["bag"] = &Item{ID: "bag", FamilyID: "family-2", RemainingMl: 100, ExpiresAt: testNow.Add(time.Hour)}
+	svc := newTestService(repo)
+
+	_, err := svc.Consume(context.Background(), "user-1", "family-1", "bag", &ConsumeRequest{FeedingID: "feed-1"})
+	if !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Consume() error = %v, want ErrItemNotFound", err)
+	}
+}
+
+func TestService_DeleteSession_OtherFamily(t *testing.T) {
+	repo := newMockRepository()
+	repo.sessions["session-1"] = &Session{ID: "session-1", FamilyID: "family-2"}
+	svc := newTestService(repo)
+
+	if err := svc.DeleteSession(context.Background(), "user-1", "family-1", "session-1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("DeleteSession() error = %v, want ErrSessionNotFound", err)
+	}
+	if _, ok := repo.sessions["session-1"]; !ok {
+		t.Error("DeleteSession() should not delete another family's session")
+	}
+}
@@ -0,0 +1,295 @@
+package qr
+
+// grid is a code being drawn. Function modules, the finders, timing,
+// alignment, format and version areas, are never masked or written with data.
+type grid struct {
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newGrid(size int) *grid {
+	g := &grid{size: size, modules: make([][]bool, size), isFunction: make([][]bool, size)}
+	for y := range size {
+		g.modules[y] = make([]bool, size)
+		g.isFunction[y] = make([]bool, size)
+	}
+	return g
+}
+
+func (g *grid) setFunction(x, y int, dark bool) {
+	g.modules[y][x] = dark
+	g.isFunction[y][x] = true
+}
+
+// build lays out the codewords in version n, trying every mask and keeping
+// the one that scores best
+func build(n int, data []byte) *Code {
+	g := newGrid(17 + 4*n)
+	g.drawFunctionPatterns(n)
+	g.drawCodewords(data)
+
+	best, bestPenalty := 0, -1
+	for mask := range 8 {
+		g.applyMask(mask)
+		g.drawFormat(mask)
+		if p := g.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		g.applyMask(mask) // masking twice undoes it
+	}
+	g.applyMask(best)
+	g.drawFormat(best)
+
+	return &Code{size: g.size, modules: g.modules}
+}
+
+func (g *grid) drawFunctionPatterns(n int) {
+	for i := range g.size {
+		g.setFunction(6, i, i%2 == 0)
+		g.setFunction(i, 6, i%2 == 0)
+	}
+
+	g.drawFinder(3, 3)
+	g.drawFinder(g.size-4, 3)
+	g.drawFinder(3, g.size-4)
+
+	centres := versions[n].alignment
+	last := len(centres) - 1
+	for i, cx := range centres {
+		for j, cy := range centres {
+			// Skip the three that would overlap the finders
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			g.drawAlignment(cx, cy)
+		}
+	}
+
+	// Reserve the format areas; they are filled in once the mask is chosen
+	g.drawFormat(0)
+	g.drawVersion(n)
+}
+
+// drawFinder draws a finder pattern and its separator centred on x, y
+func (g *grid) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= g.size || yy < 0 || yy >= g.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			g.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (g *grid) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			g.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// formatBits returns the 15 format bits for level M and a mask
+func formatBits(mask int) int {
+	data := 0b00<<3 | mask // 00 is level M
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawFormat writes both copies of the format bits, and the dark module
+func (g *grid) drawFormat(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		g.setFunction(8, i, bit(i))
+	}
+	g.setFunction(8, 7, bit(6))
+	g.setFunction(8, 8, bit(7))
+	g.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		g.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		g.setFunction(g.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		g.setFunction(8, g.size-15+i, bit(i))
+	}
+	g.setFunction(8, g.size-8, true)
+}
+
+// versionBits returns the 18 version bits, which versions 7 and up carry
+func versionBits(n int) int {
+	rem := n
+	for range 12 {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	return n<<12 | rem
+}
+
+func (g *grid) drawVersion(n int) {
+	if n < 7 {
+		return
+	}
+	bits := versionBits(n)
+	for i := range 18 {
+		dark := bits>>i&1 == 1
+		a, b := g.size-11+i%3, i/3
+		g.setFunction(a, b, dark)
+		g.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords fills the data area in the zigzag order, two columns at a
+// time from the bottom right, alternately upwards and downwards
+func (g *grid) drawCodewords(data []byte) {
+	i := 0
+	for right := g.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vert := range g.size {
+			y := vert
+			if upward {
+				y = g.size - 1 - vert
+			}
+			for j := range 2 {
+				x := right - j
+				if g.isFunction[y][x] || i >= len(data)*8 {
+					continue
+				}
+				g.modules[y][x] = data[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+func (g *grid) applyMask(mask int) {
+	for y := range g.size {
+		for x := range g.size {
+			if g.isFunction[y][x] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip {
+				g.modules[y][x] = !g.modules[y][x]
+			}
+		}
+	}
+}
+
+// Penalty weights from the standard
+const (
+	penaltyRun    = 3
+	penaltyBlock  = 3
+	penaltyFinder = 40
+	penaltyRatio  = 10
+)
+
+// penalty scores how hard the code would be to scan: long runs, 2x2
+// blocks, shapes like a finder, and an uneven balance of dark and light
+func (g *grid) penalty() int {
+	total := 0
+	row := make([]bool, g.size)
+	col := make([]bool, g.size)
+	for i := range g.size {
+		for j := range g.size {
+			row[j], col[j] = g.modules[i][j], g.modules[j][i]
+		}
+		total += linePenalty(row) + linePenalty(col)
+	}
+
+	dark := 0
+	for y := range g.size {
+		for x := range g.size {
+			c := g.modules[y][x]
+			if c {
+				dark++
+			}
+			if x+1 < g.size && y+1 < g.size &&
+				c == g.modules[y][x+1] && c == g.modules[y+1][x] && c == g.modules[y+1][x+1] {
+				total += penaltyBlock
+			}
+		}
+	}
+
+	cells := g.size * g.size
+	k := (abs(dark*20-cells*10)+cells-1)/cells - 1
+	return total + k*penaltyRatio
+}
+
+// finderLike is dark-light-dark-dark-dark-light-dark with four light
+// modules on one side
+var finderLike = [2][11]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+func linePenalty(line []bool) int {
+	total := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			total += penaltyRun + run - 5
+		}
+		run = 1
+	}
+
+	for i := 0; i+11 <= len(line); i++ {
+		for _, pattern := range finderLike {
+			if matches(line[i:i+11], pattern[:]) {
+				total += penaltyFinder
+			}
+		}
+	}
+	return total
+}
+
+func matches(a, b []bool) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,162 @@
+// Package qr draws QR codes, such as the family invite links a co-parent
+// scans from another phone. It covers what links need: byte mode at error
+// correction level M, in versions 1 to 10, which hold up to 213 bytes.
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+var ErrTooLong = errors.New("data is too long for a QR code")
+
+// QuietZone is the light border, in modules, that scanners need around a
+// code
+const QuietZone = 4
+
+// Code is a QR code as a square of modules, true for dark
+type Code struct {
+	size    int
+	modules [][]bool
+}
+
+// Size returns the number of modules along each side
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Dark reports whether the module at column x, row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// version is the layout of one version at level M
+type version struct {
+	ecPerBlock int
+	// blocks lists each block's count of data codewords
+	blocks []int
+	// alignment holds the centres of the alignment patterns along each axis
+	alignment []int
+}
+
+var versions = [...]version{
+	1:  {10, []int{16}, nil},
+	2:  {16, []int{28}, []int{6, 18}},
+	3:  {26, []int{44}, []int{6, 22}},
+	4:  {18, []int{32, 32}, []int{6, 26}},
+	5:  {24, []int{43, 43}, []int{6, 30}},
+	6:  {16, []int{27, 27, 27, 27}, []int{6, 34}},
+	7:  {18, []int{31, 31, 31, 31}, []int{6, 22, 38}},
+	8:  {22, []int{38, 38, 39, 39}, []int{6, 24, 42}},
+	9:  {22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}},
+	10: {26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}},
+}
+
+func (v version) dataCodewords() int {
+	n := 0
+	for _, b := range v.blocks {
+		n += b
+	}
+	return n
+}
+
+// Encode makes the smallest code that holds data
+func Encode(data []byte) (*Code, error) {
+	for n := 1; n < len(versions); n++ {
+		countBits := 8
+		if n >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= versions[n].dataCodewords()*8 {
+			return build(n, codewords(versions[n], countBits, data)), nil
+		}
+	}
+	return nil, ErrTooLong
+}
+
+// codewords encodes data in byte mode, pads it to the version's capacity
+// and interleaves the blocks with their error correction
+func codewords(v version, countBits int, data []byte) []byte {
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), countBits)
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := v.dataCodewords() * 8
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	padded := bits.bytes()
+
+	divisor := generator(v.ecPerBlock)
+	blocks := make([][]byte, len(v.blocks))
+	ecc := make([][]byte, len(v.blocks))
+	for i, n := range v.blocks {
+		blocks[i], padded = padded[:n], padded[n:]
+		ecc[i] = remainder(blocks[i], divisor)
+	}
+
+	var out []byte
+	for i := 0; i < v.blocks[len(v.blocks)-1]; i++ {
+		for _, b := range blocks {
+			if i < len(b) {
+				out = append(out, b[i])
+			}
+		}
+	}
+	for i := 0; i < v.ecPerBlock; i++ {
+		for _, e := range ecc {
+			out = append(out, e[i])
+		}
+	}
+	return out
+}
+
+// PNG renders the code with scale pixels per module and a quiet zone
+func (c *Code) PNG(scale int) ([]byte, error) {
+	side := (c.size + 2*QuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			top, left := (y+QuietZone)*scale, (x+QuietZone)*scale
+			for py := top; py < top+scale; py++ {
+				for px := left; px < left+scale; px++ {
+					img.SetColorIndex(px, py, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out
+}
@@ -0,0 +1,232 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestRemainder_HelloWorld(t *testing.T) {
+	// The 1-M example from the standard's annex: "HELLO WORLD" in
+	// alphanumeric mode and its ten error correction codewords
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := remainder(data, generator(10)); !bytes.Equal(got, want) {
+		t.Errorf("remainder() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	for mask, want := range map[int]int{0: 0b101010000010010, 1: 0b101000100100101, 7: 0b100101010100000} {
+		if got := formatBits(mask); got != want {
+			t.Errorf("formatBits(%d) = %015b, want %015b", mask, got, want)
+		}
+	}
+	if got := versionBits(7); got != 0b000111110010010100 {
+		t.Errorf("versionBits(7) = %018b", got)
+	}
+}
+
+func TestEncode_Versions(t *testing.T) {
+	tests := []struct {
+		length int
+		size   int
+	}{
+		{14, 21},  // version 1 holds 14 bytes
+		{15, 25},  // version 2
+		{80, 37},  // version 5, a typical invite link
+		{213, 57}, // version 10, the most
+	}
+
+	for _, tt := range tests {
+		code, err := Encode(bytes.Repeat([]byte("a"), tt.length))
+		if err != nil {
+			t.Fatalf("Encode(%d bytes) error = %v", tt.length, err)
+		}
+		if code.Size() != tt.size {
+			t.Errorf("Encode(%d bytes) size = %d, want %d", tt.length, code.Size(), tt.size)
+		}
+	}
+
+	if _, err := Encode(bytes.Repeat([]byte("a"), 214)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode(214 bytes) error = %v, want ErrTooLong", err)
+	}
+}
+
+// TestEncode_ReadBack reads codes the way a scanner would: the format bits,
+// the unmasked codewords and each block's Reed-Solomon syndromes
+func TestEncode_ReadBack(t *testing.T) {
+	for _, text := range []string{
+		"hi",
+		"https://babytrack.example.com/join?invite=" + strings.Repeat("Xy9_", 11),
+		strings.Repeat("long invite link ", 12),
+	} {
+		code, err := Encode([]byte(text))
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		n := (code.Size() - 17) / 4
+		v := versions[n]
+
+		// Finder corners are dark, with light separators inside
+		for _, p := range [][2]int{{0, 0}, {code.Size() - 1, 0}, {0, code.Size() - 1}} {
+			if !code.Dark(p[0], p[1]) {
+				t.Errorf("version %d: finder corner %v is light", n, p)
+			}
+		}
+
+		// Format bits, from the copy beside the top left finder
+		format := 0
+		for i := 0; i <= 5; i++ {
+			format |= bit(code, 8, i) << i
+		}
+		format |= bit(code, 8, 7)<<6 | bit(code, 8, 8)<<7 | bit(code, 7, 8)<<8
+		for i := 9; i < 15; i++ {
+			format |= bit(code, 14-i, 8) << i
+		}
+		mask := -1
+		for m := range 8 {
+			if formatBits(m) == format {
+				mask = m
+			}
+		}
+		if mask < 0 {
+			t.Fatalf("version %d: format bits %015b are not level M", n, format)
+		}
+
+		// Unmask by drawing a blank code of the same version
+		g := newGrid(code.Size())
+		g.drawFunctionPatterns(n)
+		g.applyMask(mask)
+		var raw bitBuffer
+		for right := g.size - 1; right >= 1; right -= 2 {
+			if right == 6 {
+				right = 5
+			}
+			for vert := range g.size {
+				y := vert
+				if (right+1)&2 == 0 {
+					y = g.size - 1 - vert
+				}
+				for j := range 2 {
+					if x := right - j; !g.isFunction[y][x] {
+						raw = append(raw, code.Dark(x, y) != g.modules[y][x])
+					}
+				}
+			}
+		}
+		codewords := raw.bytes()
+
+		// De-interleave and check every block
+		blocks := make([][]byte, len(v.blocks))
+		k := 0
+		for i := 0; i < v.blocks[len(v.blocks)-1]; i++ {
+			for b, size := range v.blocks {
+				if i < size {
+					blocks[b] = append(blocks[b], codewords[k])
+					k++
+				}
+			}
+		}
+		var data []byte
+		for _, b := range blocks {
+			data = append(data, b...)
+		}
+		for i := 0; i < v.ecPerBlock; i++ {
+			for b := range blocks {
+				blocks[b] = append(blocks[b], codewords[k])
+				k++
+			}
+		}
+		for b, block := range blocks {
+			if !syndromesZero(block, v.ecPerBlock) {
+				t.Errorf("version %d: block %d fails its Reed-Solomon check", n, b)
+			}
+		}
+
+		// Byte mode, then the count and the bytes
+		countBits := 8
+		if n >= 10 {
+			countBits = 16
+		}
+		var bits bitBuffer
+		for _, b := range data {
+			bits.append(int(b), 8)
+		}
+		if read(bits, 0, 4) != 0b0100 {
+			t.Fatalf("version %d: mode = %04b, want byte mode", n, read(bits, 0, 4))
+		}
+		length := read(bits, 4, countBits)
+		var got []byte
+		for i := range length {
+			got = append(got, byte(read(bits, 4+countBits+8*i, 8)))
+		}
+		if string(got) != text {
+			t.Errorf("version %d: read back %q, want %q", n, got, text)
+		}
+	}
+}
+
+func TestPNG(t *testing.T) {
+	code, err := Encode([]byte("hi"))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	data, err := code.PNG(4)
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if side := (21 + 2*QuietZone) * 4; img.Bounds().Dx() != side || img.Bounds().Dy() != side {
+		t.Errorf("PNG() bounds = %v, want %dx%d", img.Bounds(), side, side)
+	}
+	// The quiet zone is white and the top left finder corner black
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Error("PNG() quiet zone is dark")
+	}
+	if r, _, _, _ := img.At(QuietZone*4, QuietZone*4).RGBA(); r != 0 {
+		t.Error("PNG() finder corner is light")
+	}
+}
+
+func bit(c *Code, x, y int) int {
+	if c.Dark(x, y) {
+		return 1
+	}
+	return 0
+}
+
+func read(bits bitBuffer, from, n int) int {
+	v := 0
+	for _, b := range bits[from : from+n] {
+		v <<= 1
+		if b {
+			v |= 1
+		}
+	}
+	return v
+}
+
+// syndromesZero evaluates the block, as a polynomial, at the generator's
+// roots; a valid block is zero at all of them
+func syndromesZero(block []byte, ecLen int) bool {
+	root := byte(1)
+	for range ecLen {
+		var s byte
+		for _, c := range block {
+			s = multiply(s, root) ^ c
+		}
+		if s != 0 {
+			return false
+		}
+		root = multiply(root, 0x02)
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package qr
+
+// multiply multiplies in GF(256) modulo x^8 + x^4 + x^3 + x^2 + 1
+func multiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// generator returns the coefficients of the Reed-Solomon generator
+// polynomial of the given degree, highest first, without the leading 1
+func generator(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range result {
+			result[j] = multiply(result[j], root)
+			if j+1 < degree {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = multiply(root, 0x02)
+	}
+	return result
+}
+
+// remainder returns the error correction codewords for data
+func remainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= multiply(d, factor)
+		}
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package quality
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	getFn     func(ctx context.Context, userID, childID string) (*Report, error)
+	refreshFn func(ctx context.Context, userID, childID string) (*Report, error)
+}
+
+func (m *mockService) Get(ctx context.Context, userID, childID string) (*Report, error) {
+	return m.getFn(ctx, userID, childID)
+}
+
+func (m *mockService) Refresh(ctx context.Context, userID, childID string) (*Report, error) {
+	return m.refreshFn(ctx, userID, childID)
+}
+
+func (m *mockService) AnalyzeAll(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterChildRoutes(router.Group("/children"))
+	return router
+}
+
+func TestHandler_Get(t *testing.T) {
+	svc := &mockService{getFn: func(ctx context.Context, userID, childID string) (*Report, error) {
+		if userID != "test-user-123" || childID != "child-1" {
+			t.Errorf("Get() called with %s, %s", userID, childID)
+		}
+		return &Report{ChildID: childID, Issues: []Issue{{Rule: RuleOverlappingSleep, Fix: reconcilePath}}}, nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("GET", "/children/child-1/data-quality", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var got Report
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || len(got.Issues) != 1 {
+		t.Errorf("Expected one issue, got %s", w.Body.String())
+	}
+}
+
+func TestHandler_Refresh(t *testing.T) {
+	called := false
+	svc := &mockService{refreshFn: func(ctx context.Context, userID, childID string) (*Report, error) {
+		called = true
+		return &Report{ChildID: childID, Issues: []Issue{}}, nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("POST", "/children/child-1/data-quality/refresh", nil))
+
+	if w.Code != http.StatusOK || !called {
+		t.Errorf("Expected status 200 and a refresh, got %d", w.Code)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"child not found", ErrChildNotFound, http.StatusNotFound},
+		{"not member", ErrNotMember, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{getFn: func(ctx context.Context, userID, childID string) (*Report, error) {
+				return nil, tt.err
+			}}
+
+			w := httptest.NewRecorder()
+			setupRouter(svc).ServeHTTP(w, httptest.NewRequest("GET", "/children/child-1/data-quality", nil))
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package quality
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// Rule names the check that found an issue
+type Rule string
+
+const (
+	// RuleOverlappingSleep flags sleep sessions that overlap each other
+	RuleOverlappingSleep Rule = "overlapping_sleep"
+	// RuleFeedDuringSleep flags a feed that started while the child was asleep
+	RuleFeedDuringSleep Rule = "feed_during_sleep"
+	// RuleDuplicateVaccination flags more than one record of the same dose
+	RuleDuplicateVaccination Rule = "duplicate_vaccination"
+	// RuleBeforeBirth flags a record dated before the child was born
+	RuleBeforeBirth Rule = "before_birth"
+)
+
+// RecordType is the kind of record an issue points at
+type RecordType string
+
+const (
+	RecordFeeding       RecordType = "feeding"
+	RecordSleep         RecordType = "sleep"
+	RecordVaccination   RecordType = "vaccination"
+	RecordMedicationLog RecordType = "medication_log"
+	RecordGrowth        RecordType = "growth"
+)
+
+// hiddenAs maps each record type onto the type family visibility settings
+// know about. Types without an entry are never hidden.
+var hiddenAs = map[RecordType]visibility.RecordType{
+	RecordFeeding:       visibility.RecordFeeding,
+	RecordSleep:         visibility.RecordSleep,
+	RecordVaccination:   visibility.RecordVaccination,
+	RecordMedicationLog: visibility.RecordMedication,
+}
+
+// RecordRef points at one record involved in an issue
+type RecordRef struct {
+	Type RecordType `json:"type"`
+	ID   string     `json:"id"`
+	At   time.Time  `json:"at"`
+	// Link is the API path to view, correct or delete the record. Medication
+	// doses link to their medication's log.
+	Link string `json:"link"`
+}
+
+type Issue struct {
+	Rule    Rule        `json:"rule"`
+	Message string      `json:"message"`
+	Records []RecordRef `json:"records"`
+	// Fix is an endpoint that resolves the whole issue at once, when there is
+	// one. Overlapping sleep is merged by POSTing the child to it.
+	Fix string `json:"fix,omitempty"`
+}
+
+// Report is the result of the last run of the checks for one child
+type Report struct {
+	ChildID     string    `json:"child_id"`
+	Issues      []Issue   `json:"issues"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
@@ -0,0 +1,89 @@
+package quality
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	// ListChildIDs returns every child, for the analysis job
+	ListChildIDs(ctx context.Context) ([]string, error)
+	GetReport(ctx context.Context, childID string) (*Report, error)
+	// SaveReport replaces the child's stored report
+	SaveReport(ctx context.Context, r *Report) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the reports table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "data_quality_reports")
+}
+
+func (r *repository) ListChildIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM children ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *repository) GetReport(ctx context.Context, childID string) (*Report, error) {
+	query := `SELECT child_id, issues, generated_at FROM data_quality_reports WHERE child_id = $1`
+
+	var report Report
+	var issues []byte
+	err := r.db.QueryRowContext(ctx, query, childID).Scan(&report.ChildID, &issues, &report.GeneratedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(issues, &report.Issues); err != nil {
+		return nil, fmt.Errorf("failed to decode issues: %w", err)
+	}
+	if report.Issues == nil {
+		report.Issues = []Issue{}
+	}
+	return &report, nil
+}
+
+func (r *repository) SaveReport(ctx context.Context, report *Report) error {
+	query := `
+		INSERT INTO data_quality_reports (child_id, issues, generated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (child_id) DO UPDATE
+		SET issues = EXCLUDED.issues, generated_at = EXCLUDED.generated_at
+	`
+
+	issues, err := json.Marshal(report.Issues)
+	if err != nil {
+		return fmt.Errorf("failed to encode issues: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, query, report.ChildID, issues, report.GeneratedAt)
+	return err
+}
@@ -0,0 +1,89 @@
+package quality
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_ListChildIDs(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT id FROM children").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("child-1").AddRow("child-2"))
+
+	ids, err := repo.ListChildIDs(context.Background())
+	if err != nil {
+		t.Fatalf("ListChildIDs() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "child-1" {
+		t.Errorf("ListChildIDs() = %v", ids)
+	}
+}
+
+func TestRepository_GetReport(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	issues := `[{"rule":"before_birth","message":"A feeding record is dated 2023-12-30, before the child was born","records":[{"type":"feeding","id":"feed-1","at":"2023-12-30T08:00:00Z","link":"/api/feeding/feed-1"}]}]`
+	mock.ExpectQuery("SELECT child_id, issues, generated_at FROM data_quality_reports WHERE child_id = \\$1").
+		WithArgs("child-1").
+		WillReturnRows(sqlmock.NewRows([]string{"child_id", "issues", "generated_at"}).AddRow("child-1", []byte(issues), now))
+
+	report, err := repo.GetReport(context.Background(), "child-1")
+	if err != nil {
+		t.Fatalf("GetReport() error = %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Rule != RuleBeforeBirth || report.Issues[0].Records[0].ID != "feed-1" {
+		t.Errorf("GetReport() = %+v", report)
+	}
+}
+
+func TestRepository_GetReport_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT .* FROM data_quality_reports").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"child_id", "issues", "generated_at"}))
+
+	report, err := repo.GetReport(context.Background(), "missing")
+	if err != nil || report != nil {
+		t.Errorf("GetReport() = %v, %v; want nil, nil", report, err)
+	}
+}
+
+func TestRepository_SaveReport(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO data_quality_reports .* ON CONFLICT \\(child_id\\) DO UPDATE").
+		WithArgs("child-1", []byte("[]"), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SaveReport(context.Background(), &Report{ChildID: "child-1", Issues: []Issue{}, GeneratedAt: now}); err != nil {
+		t.Fatalf("SaveReport() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package quality
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+)
+
+// birthGrace allows records up to a day before the date of birth, which is
+// stored without a time zone
+const birthGrace = 24 * time.Hour
+
+// reconcilePath merges overlapping sleep
+const reconcilePath = "/api/sleep/reconcile"
+
+// records is everything the checks read for one child
+type records struct {
+	dateOfBirth  time.Time
+	sleeps       []sleep.Sleep
+	conflicts    []sleep.Conflict
+	feeds        []feeding.Feeding
+	vaccinations []vaccination.Vaccination
+	doses        []medication.MedicationLog
+	measurements []growth.Measurement
+}
+
+// check runs every rule over the child's records
+func check(r *records, now time.Time) []Issue {
+	issues := []Issue{}
+	issues = append(issues, overlappingSleep(r.conflicts)...)
+	issues = append(issues, feedsDuringSleep(r.feeds, r.sleeps, now)...)
+	issues = append(issues, duplicateVaccinations(r.vaccinations)...)
+	issues = append(issues, beforeBirth(r)...)
+	return issues
+}
+
+func overlappingSleep(conflicts []sleep.Conflict) []Issue {
+	var issues []Issue
+	for _, c := range conflicts {
+		refs := make([]RecordRef, 0, len(c.Records))
+		for i := range c.Records {
+			refs = append(refs, sleepRef(&c.Records[i]))
+		}
+		issues = append(issues, Issue{
+			Rule:    RuleOverlappingSleep,
+			Message: fmt.Sprintf("%d sleep sessions overlap", len(c.Records)),
+			Records: refs,
+			Fix:     reconcilePath,
+		})
+	}
+	return issues
+}
+
+// feedsDuringSleep flags feeds that started strictly inside a sleep session.
+// A session still running counts as lasting until now.
+func feedsDuringSleep(feeds []feeding.Feeding, sleeps []sleep.Sleep, now time.Time) []Issue {
+	if len(sleeps) == 0 {
+		return nil
+	}
+
+	sorted := append([]sleep.Sleep(nil), sleeps...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartTime.Before(sorted[j].StartTime) })
+
+	end := func(s *sleep.Sleep) time.Time {
+		if s.EndTime != nil {
+			return *s.EndTime
+		}
+		return now
+	}
+	var longest time.Duration
+	for i := range sorted {
+		longest = max(longest, end(&sorted[i]).Sub(sorted[i].StartTime))
+	}
+
+	var issues []Issue
+	for i := range feeds {
+		f := &feeds[i]
+		// Only sessions that started within the longest session before the
+		// feed can contain it
+		first := sort.Search(len(sorted), func(k int) bool {
+			return !sorted[k].StartTime.Before(f.StartTime.Add(-longest))
+		})
+		for k := first; k < len(sorted) && sorted[k].StartTime.Before(f.StartTime); k++ {
+			s := &sorted[k]
+			if f.StartTime.Before(end(s)) {
+				issues = append(issues, Issue{
+					Rule:    RuleFeedDuringSleep,
+					Message: fmt.Sprintf("A %s feed was logged while the child was asleep", f.Type),
+					Records: []RecordRef{feedingRef(f), sleepRef(s)},
+				})
+				break
+			}
+		}
+	}
+	return issues
+}
+
+// duplicateVaccinations flags more than one record of the same vaccine dose,
+// whatever their status
+func duplicateVaccinations(vaxes []vaccination.Vaccination) []Issue {
+	type doseKey struct {
+		name string
+		dose int
+	}
+	groups := map[doseKey][]*vaccination.Vaccination{}
+	var order []doseKey
+	for i := range vaxes {
+		key := doseKey{strings.ToLower(strings.TrimSpace(vaxes[i].Name)), vaxes[i].Dose}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], &vaxes[i])
+	}
+
+	var issues []Issue
+	for _, key := range order {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		refs := make([]RecordRef, 0, len(group))
+		for _, v := range group {
+			refs = append(refs, vaccinationRef(v))
+		}
+		issues = append(issues, Issue{
+			Rule:    RuleDuplicateVaccination,
+			Message: fmt.Sprintf("%s dose %d is recorded %d times", group[0].Name, group[0].Dose, len(group)),
+			Records: refs,
+		})
+	}
+	return issues
+}
+
+// beforeBirth flags each record dated more than birthGrace before the date of
+// birth. Vaccinations count by when they were given, not scheduled.
+func beforeBirth(r *records) []Issue {
+	if r.dateOfBirth.IsZero() {
+		return nil
+	}
+	cutoff := r.dateOfBirth.Add(-birthGrace)
+
+	var refs []RecordRef
+	for i := range r.feeds {
+		if r.feeds[i].StartTime.Before(cutoff) {
+			refs = append(refs, feedingRef(&r.feeds[i]))
+		}
+	}
+	for i := range r.sleeps {
+		if r.sleeps[i].StartTime.Before(cutoff) {
+			refs = append(refs, sleepRef(&r.sleeps[i]))
+		}
+	}
+	for i := range r.vaccinations {
+		if at := r.vaccinations[i].AdministeredAt; at != nil && at.Before(cutoff) {
+			refs = append(refs, vaccinationRef(&r.vaccinations[i]))
+		}
+	}
+	for i := range r.doses {
+		if r.doses[i].GivenAt.Before(cutoff) {
+			d := &r.doses[i]
+			refs = append(refs, RecordRef{Type: RecordMedicationLog, ID: d.ID, At: d.GivenAt,
+				Link: "/api/medications/" + d.MedicationID + "/logs"})
+		}
+	}
+	for i := range r.measurements {
+		if m := &r.measurements[i]; m.MeasuredAt.Before(cutoff) {
+			refs = append(refs, RecordRef{Type: RecordGrowth, ID: m.ID, At: m.MeasuredAt, Link: "/api/growth/" + m.ID})
+		}
+	}
+
+	issues := make([]Issue, 0, len(refs))
+	for _, ref := range refs {
+		issues = append(issues, Issue{
+			Rule:    RuleBeforeBirth,
+			Message: fmt.Sprintf("A %s record is dated %s, before the child was born", strings.ReplaceAll(string(ref.Type), "_", " "), ref.At.Format(time.DateOnly)),
+			Records: []RecordRef{ref},
+		})
+	}
+	return issues
+}
+
+func sleepRef(s *sleep.Sleep) RecordRef {
+	return RecordRef{Type: RecordSleep, ID: s.ID, At: s.StartTime, Link: "/api/sleep/" + s.ID}
+}
+
+func feedingRef(f *feeding.Feeding) RecordRef {
+	return RecordRef{Type: RecordFeeding, ID: f.ID, At: f.StartTime, Link: "/api/feeding/" + f.ID}
+}
+
+func vaccinationRef(v *vaccination.Vaccination) RecordRef {
+	at := v.ScheduledAt
+	if v.AdministeredAt != nil {
+		at = *v.AdministeredAt
+	}
+	return RecordRef{Type: RecordVaccination, ID: v.ID, At: at, Link: "/api/vaccinations/" + v.ID}
+}
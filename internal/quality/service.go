@@ -0,0 +1,251 @@
+// Package quality checks each child's records for entries that are probably
+// mistakes, such as overlapping sleep or doses recorded twice, and points at
+// the records to correct.
+package quality
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrChildNotFound = errors.New("child not found")
+	ErrNotMember     = errors.New("user is not a member of this family")
+)
+
+type Service interface {
+	// Get returns the child's latest report, running the checks first when
+	// the child has none yet
+	Get(ctx context.Context, userID, childID string) (*Report, error)
+	// Refresh runs the checks for the child now, e.g. after records were fixed
+	Refresh(ctx context.Context, userID, childID string) (*Report, error)
+	// AnalyzeAll runs the checks for every child and stores their reports,
+	// returning how many children were checked
+	AnalyzeAll(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repo               Repository
+	familyService      family.Service
+	sleepService       sleep.Service
+	feedingService     feeding.Service
+	vaccinationService vaccination.Service
+	medicationService  medication.Service
+	growthService      growth.Service
+	visibility         visibility.Service
+	now                func() time.Time
+}
+
+type Option func(*service)
+
+// WithVisibility leaves out issues about record types hidden from the member
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+func NewService(
+	repo Repository,
+	familyService family.Service,
+	sleepService sleep.Service,
+	feedingService feeding.Service,
+	vaccinationService vaccination.Service,
+	medicationService medication.Service,
+	growthService growth.Service,
+	opts ...Option,
+) Service {
+	s := &service{
+		repo:               repo,
+		familyService:      familyService,
+		sleepService:       sleepService,
+		feedingService:     feedingService,
+		vaccinationService: vaccinationService,
+		medicationService:  medicationService,
+		growthService:      growthService,
+		now:                time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether stored data quality reports can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Get(ctx context.Context, userID, childID string) (*Report, error) {
+	child, err := s.authorize(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.repo.GetReport(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	if report == nil {
+		if report, err = s.analyze(ctx, child); err != nil {
+			return nil, err
+		}
+	}
+	return s.visibleTo(ctx, userID, report)
+}
+
+func (s *service) Refresh(ctx context.Context, userID, childID string) (*Report, error) {
+	child, err := s.authorize(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.analyze(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+	return s.visibleTo(ctx, userID, report)
+}
+
+func (s *service) AnalyzeAll(ctx context.Context) (int, error) {
+	childIDs, err := s.repo.ListChildIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list children: %w", err)
+	}
+
+	// One child's failure should not stop the rest from being checked
+	checked := 0
+	var failed []error
+	for _, id := range childIDs {
+		if ctx.Err() != nil {
+			return checked, ctx.Err()
+		}
+		child, err := s.familyService.GetChild(ctx, id)
+		if err != nil {
+			failed = append(failed, fmt.Errorf("child %s: %w", id, err))
+			continue
+		}
+		if child == nil {
+			continue
+		}
+		if _, err := s.analyze(ctx, child); err != nil {
+			failed = append(failed, fmt.Errorf("child %s: %w", id, err))
+			continue
+		}
+		checked++
+	}
+	return checked, errors.Join(failed...)
+}
+
+// analyze runs the checks over the child's records and stores the report
+func (s *service) analyze(ctx context.Context, child *family.Child) (*Report, error) {
+	now := s.now()
+	r, err := s.load(ctx, child, now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{ChildID: child.ID, Issues: check(r, now), GeneratedAt: now}
+	if err := s.repo.SaveReport(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to save report: %w", err)
+	}
+	return report, nil
+}
+
+func (s *service) load(ctx context.Context, child *family.Child, now time.Time) (*records, error) {
+	r := &records{dateOfBirth: child.DateOfBirth}
+	var err error
+
+	if r.sleeps, err = s.sleepService.List(ctx, &sleep.SleepFilter{ChildID: child.ID}); err != nil {
+		return nil, fmt.Errorf("failed to list sleep: %w", err)
+	}
+	if r.conflicts, err = s.sleepService.ListConflicts(ctx, child.ID, time.Time{}, now); err != nil {
+		return nil, fmt.Errorf("failed to list sleep conflicts: %w", err)
+	}
+	if r.feeds, err = s.feedingService.List(ctx, &feeding.FeedingFilter{ChildID: child.ID}); err != nil {
+		return nil, fmt.Errorf("failed to list feedings: %w", err)
+	}
+	if r.vaccinations, err = s.vaccinationService.List(ctx, &vaccination.VaccinationFilter{ChildID: child.ID}); err != nil {
+		return nil, fmt.Errorf("failed to list vaccinations: %w", err)
+	}
+	if r.measurements, err = s.growthService.List(ctx, &growth.MeasurementFilter{ChildID: child.ID}); err != nil {
+		return nil, fmt.Errorf("failed to list measurements: %w", err)
+	}
+
+	meds, err := s.medicationService.List(ctx, &medication.MedicationFilter{ChildID: child.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list medications: %w", err)
+	}
+	for _, med := range meds {
+		doses, err := s.medicationService.GetLogs(ctx, med.ID, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list doses: %w", err)
+		}
+		r.doses = append(r.doses, doses...)
+	}
+
+	return r, nil
+}
+
+// visibleTo drops issues that involve a record type hidden from the user
+func (s *service) visibleTo(ctx context.Context, userID string, report *Report) (*Report, error) {
+	if s.visibility == nil {
+		return report, nil
+	}
+
+	hidden := map[RecordType]bool{}
+	for recordType, as := range hiddenAs {
+		h, err := s.visibility.IsHidden(ctx, userID, report.ChildID, as)
+		if err != nil {
+			return nil, err
+		}
+		hidden[recordType] = h
+	}
+
+	visible := *report
+	visible.Issues = []Issue{}
+	for _, issue := range report.Issues {
+		if !involvesHidden(issue, hidden) {
+			visible.Issues = append(visible.Issues, issue)
+		}
+	}
+	return &visible, nil
+}
+
+func involvesHidden(issue Issue, hidden map[RecordType]bool) bool {
+	for _, ref := range issue.Records {
+		if hidden[ref.Type] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) authorize(ctx context.Context, userID, childID string) (*family.Child, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+	return child, nil
+}
@@ -0,0 +1,324 @@
+package quality
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	testNow   = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	testBirth = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func at(day, hour int) time.Time {
+	return time.Date(2024, 2, day, hour, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+type mockRepository struct {
+	reports  map[string]*Report
+	childIDs []string
+	saves    int
+}
+
+func (m *mockRepository) ListChildIDs(ctx context.Context) ([]string, error) {
+	return m.childIDs, nil
+}
+
+func (m *mockRepository) GetReport(ctx context.Context, childID string) (*Report, error) {
+	return m.reports[childID], nil
+}
+
+func (m *mockRepository) SaveReport(ctx context.Context, r *Report) error {
+	m.saves++
+	m.reports[r.ChildID] = r
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	switch childID {
+	case "child-1", "child-2":
+		return &family.Child{ID: childID, FamilyID: "family-1", DateOfBirth: testBirth}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID == "user-1" || userID == "sitter" {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockSleepService struct {
+	sleep.Service
+	sleeps    []sleep.Sleep
+	conflicts []sleep.Conflict
+}
+
+func (m *mockSleepService) List(ctx context.Context, filter *sleep.SleepFilter) ([]sleep.Sleep, error) {
+	if filter.ChildID != "child-1" {
+		return nil, errors.New("database error")
+	}
+	return m.sleeps, nil
+}
+
+func (m *mockSleepService) ListConflicts(ctx context.Context, childID string, from, to time.Time) ([]sleep.Conflict, error) {
+	return m.conflicts, nil
+}
+
+type mockFeedingService struct {
+	feeding.Service
+	feeds []feeding.Feeding
+}
+
+func (m *mockFeedingService) List(ctx context.Context, filter *feeding.FeedingFilter) ([]feeding.Feeding, error) {
+	return m.feeds, nil
+}
+
+type mockVaccinationService struct {
+	vaccination.Service
+	vaxes []vaccination.Vaccination
+}
+
+func (m *mockVaccinationService) List(ctx context.Context, filter *vaccination.VaccinationFilter) ([]vaccination.Vaccination, error) {
+	return m.vaxes, nil
+}
+
+type mockMedicationService struct {
+	medication.Service
+	logs []medication.MedicationLog
+}
+
+func (m *mockMedicationService) List(ctx context.Context, filter *medication.MedicationFilter) ([]medication.Medication, error) {
+	return []medication.Medication{{ID: "med-1", ChildID: filter.ChildID}}, nil
+}
+
+func (m *mockMedicationService) GetLogs(ctx context.Context, medicationID, viewerID string) ([]medication.MedicationLog, error) {
+	return m.logs, nil
+}
+
+type mockGrowthService struct {
+	growth.Service
+}
+
+func (m *mockGrowthService) List(ctx context.Context, filter *growth.MeasurementFilter) ([]growth.Measurement, error) {
+	return nil, nil
+}
+
+// mockVisibilityService hides feeding from the sitter
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) IsHidden(ctx context.Context, userID, childID string, recordType visibility.RecordType) (bool, error) {
+	return userID == "sitter" && recordType == visibility.RecordFeeding, nil
+}
+
+func newTestService(opts ...Option) (*service, *mockRepository) {
+	repo := &mockRepository{reports: map[string]*Report{}}
+	sleeps := &mockSleepService{
+		sleeps: []sleep.Sleep{{ID: "sleep-1", StartTime: at(10, 13), EndTime: ptr(at(10, 15))}},
+	}
+	feeds := &mockFeedingService{
+		feeds: []feeding.Feeding{{ID: "feed-1", Type: feeding.FeedingTypeBottle, StartTime: at(10, 14)}},
+	}
+	svc := NewService(repo, &mockFamilyService{}, sleeps, feeds, &mockVaccinationService{},
+		&mockMedicationService{}, &mockGrowthService{}, opts...).(*service)
+	svc.now = func() time.Time { return testNow }
+	return svc, repo
+}
+
+func TestCheck_OverlappingSleep(t *testing.T) {
+	r := &records{conflicts: []sleep.Conflict{{
+		ChildID: "child-1",
+		Records: []sleep.Sleep{{ID: "sleep-1", StartTime: at(10, 20)}, {ID: "sleep-2", StartTime: at(10, 21)}},
+	}}}
+
+	issues := check(r, testNow)
+	if len(issues) != 1 || issues[0].Rule != RuleOverlappingSleep || len(issues[0].Records) != 2 {
+		t.Fatalf("check() = %+v", issues)
+	}
+	if issues[0].Fix != reconcilePath || issues[0].Records[1].Link != "/api/sleep/sleep-2" {
+		t.Errorf("check() issue = %+v", issues[0])
+	}
+}
+
+func TestCheck_FeedDuringSleep(t *testing.T) {
+	r := &records{
+		sleeps: []sleep.Sleep{
+			// A long night followed by a short nap, so the night is found
+			// through the longest-session window
+			{ID: "night", StartTime: at(10, 0), EndTime: ptr(at(10, 8))},
+			{ID: "nap", StartTime: at(10, 13), EndTime: ptr(at(10, 14))},
+			{ID: "ongoing", StartTime: at(29, 20)},
+		},
+		feeds: []feeding.Feeding{
+			{ID: "during-night", StartTime: at(10, 6)},
+			{ID: "after-nap", StartTime: at(10, 14)},
+			{ID: "before-nap", StartTime: at(10, 13)},
+			{ID: "during-ongoing", StartTime: at(29, 23)},
+		},
+	}
+
+	issues := check(r, testNow)
+	if len(issues) != 2 {
+		t.Fatalf("check() = %+v, want 2 issues", issues)
+	}
+	if issues[0].Records[0].ID != "during-night" || issues[0].Records[1].ID != "night" {
+		t.Errorf("check() first issue = %+v", issues[0])
+	}
+	if issues[1].Records[0].ID != "during-ongoing" || issues[1].Records[1].ID != "ongoing" {
+		t.Errorf("check() second issue = %+v", issues[1])
+	}
+}
+
+func TestCheck_DuplicateVaccination(t *testing.T) {
+	r := &records{vaccinations: []vaccination.Vaccination{
+		{ID: "vax-1", Name: "MMR", Dose: 1, ScheduledAt: at(1, 9)},
+		{ID: "vax-2", Name: "mmr ", Dose: 1, ScheduledAt: at(2, 9)},
+		{ID: "vax-3", Name: "MMR", Dose: 2, ScheduledAt: at(3, 9)},
+	}}
+
+	issues := check(r, testNow)
+	if len(issues) != 1 || issues[0].Rule != RuleDuplicateVaccination || len(issues[0].Records) != 2 {
+		t.Errorf("check() = %+v", issues)
+	}
+}
+
+func TestCheck_BeforeBirth(t *testing.T) {
+	r := &records{
+		dateOfBirth: testBirth,
+		feeds:       []feeding.Feeding{{ID: "feed-1", StartTime: testBirth.Add(-12 * time.Hour)}},
+		vaccinations: []vaccination.Vaccination{
+			{ID: "vax-1", Name: "Hep B", Dose: 1, ScheduledAt: testBirth.AddDate(0, 0, -7)},
+		},
+		doses: []medication.MedicationLog{
+			{ID: "log-1", MedicationID: "med-1", GivenAt: testBirth.AddDate(-1, 0, 0)},
+		},
+		measurements: []growth.Measurement{{ID: "m-1", MeasuredAt: testBirth.AddDate(0, 0, -3)}},
+	}
+
+	issues := check(r, testNow)
+	if len(issues) != 2 {
+		t.Fatalf("check() = %+v, want 2 issues", issues)
+	}
+	if ref := issues[0].Records[0]; ref.Type != RecordMedicationLog || ref.Link != "/api/medications/med-1/logs" {
+		t.Errorf("check() first issue = %+v", issues[0])
+	}
+	if ref := issues[1].Records[0]; ref.Type != RecordGrowth || ref.ID != "m-1" {
+		t.Errorf("check() second issue = %+v", issues[1])
+	}
+}
+
+func TestCheck_Clean(t *testing.T) {
+	issues := check(&records{dateOfBirth: testBirth}, testNow)
+	if issues == nil || len(issues) != 0 {
+		t.Errorf("check() = %#v, want empty slice", issues)
+	}
+}
+
+func TestService_Get_AnalyzesOnFirstRequest(t *testing.T) {
+	svc, repo := newTestService()
+
+	report, err := svc.Get(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Rule != RuleFeedDuringSleep || !report.GeneratedAt.Equal(testNow) {
+		t.Errorf("Get() = %+v", report)
+	}
+	if repo.saves != 1 {
+		t.Errorf("Get() should store the new report, got %d saves", repo.saves)
+	}
+
+	if _, err := svc.Get(context.Background(), "user-1", "child-1"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if repo.saves != 1 {
+		t.Errorf("Get() should reuse the stored report, got %d saves", repo.saves)
+	}
+}
+
+func TestService_Refresh(t *testing.T) {
+	svc, repo := newTestService()
+	repo.reports["child-1"] = &Report{ChildID: "child-1", Issues: []Issue{}}
+
+	report, err := svc.Refresh(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if len(report.Issues) != 1 || repo.saves != 1 {
+		t.Errorf("Refresh() = %+v with %d saves", report, repo.saves)
+	}
+}
+
+func TestService_Get_Errors(t *testing.T) {
+	svc, _ := newTestService()
+
+	tests := []struct {
+		name    string
+		userID  string
+		childID string
+		want    error
+	}{
+		{"child not found", "user-1", "missing", ErrChildNotFound},
+		{"not member", "stranger", "child-1", ErrNotMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Get(context.Background(), tt.userID, tt.childID); !errors.Is(err, tt.want) {
+				t.Errorf("Get() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Get_HidesIssuesWithHiddenRecords(t *testing.T) {
+	svc, _ := newTestService(WithVisibility(&mockVisibilityService{}))
+
+	report, err := svc.Get(context.Background(), "sitter", "child-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Errorf("Get() should hide issues involving feeds, got %+v", report.Issues)
+	}
+
+	report, err = svc.Get(context.Background(), "user-1", "child-1")
+	if err != nil || len(report.Issues) != 1 {
+		t.Errorf("Get() = %+v, %v; want the stored issue", report, err)
+	}
+}
+
+func TestService_AnalyzeAll(t *testing.T) {
+	svc, repo := newTestService()
+	repo.childIDs = []string{"child-1", "child-2", "deleted"}
+
+	// child-2's sleep fails to load, which should not stop child-1
+	checked, err := svc.AnalyzeAll(context.Background())
+	if err == nil {
+		t.Error("AnalyzeAll() should report the failed child")
+	}
+	if checked != 1 || repo.reports["child-1"] == nil {
+		t.Errorf("AnalyzeAll() checked %d children, reports = %v", checked, repo.reports)
+	}
+}
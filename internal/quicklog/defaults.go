@@ -0,0 +1,208 @@
+package quicklog
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/measure"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+const (
+	// defaultsWindow is how far back records are read for suggestions
+	defaultsWindow = 14 * 24 * time.Hour
+	// sleepTypeWindow is how close to the current time of day a sleep must
+	// have started to count towards the usual sleep type
+	sleepTypeWindow = 90 * time.Minute
+	// bottleStep rounds a suggested bottle to the marks on a bottle
+	bottleStep = 5.0
+)
+
+// Defaults are suggested values for quick logging, worked out from the
+// child's recent records. A suggestion is left out when there is nothing to
+// go on or the records are hidden from the user.
+type Defaults struct {
+	ChildID string `json:"child_id"`
+	// Bottle and Formula are the median amount in ml of the child's recent
+	// bottle and formula feeds
+	Bottle  *AmountDefault `json:"bottle,omitempty"`
+	Formula *AmountDefault `json:"formula,omitempty"`
+	// Sleep is the type the child usually sleeps at this time of day
+	Sleep       *SleepDefault       `json:"sleep,omitempty"`
+	Medications []MedicationDefault `json:"medications"`
+}
+
+type AmountDefault struct {
+	Amount  float64      `json:"amount"`
+	Unit    measure.Unit `json:"unit"`
+	Samples int          `json:"samples"`
+}
+
+type SleepDefault struct {
+	Type    sleep.SleepType `json:"type"`
+	Samples int             `json:"samples"`
+}
+
+// MedicationDefault is an active medication with the dosage last given, or
+// the prescribed dosage before the first dose
+type MedicationDefault struct {
+	MedicationID string     `json:"medication_id"`
+	Name         string     `json:"name"`
+	Dosage       string     `json:"dosage"`
+	LastGivenAt  *time.Time `json:"last_given_at,omitempty"`
+}
+
+func (s *service) Defaults(ctx context.Context, userID, childRef string) (*Defaults, error) {
+	child, err := s.resolveChild(ctx, userID, childRef)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	defaults := &Defaults{ChildID: child.ID, Medications: []MedicationDefault{}}
+
+	visible, err := s.visibleTypes(ctx, userID, child)
+	if err != nil {
+		return nil, err
+	}
+
+	if visible[visibility.RecordFeeding] {
+		since := now.Add(-defaultsWindow)
+		feeds, err := s.feedingService.List(ctx, &feeding.FeedingFilter{ChildID: child.ID, StartDate: &since})
+		if err != nil {
+			return nil, err
+		}
+		defaults.Bottle = typicalAmount(feeds, feeding.FeedingTypeBottle)
+		defaults.Formula = typicalAmount(feeds, feeding.FeedingTypeFormula)
+	}
+
+	if visible[visibility.RecordSleep] {
+		since := now.Add(-defaultsWindow)
+		sleeps, err := s.sleepService.List(ctx, &sleep.SleepFilter{ChildID: child.ID, StartDate: &since})
+		if err != nil {
+			return nil, err
+		}
+		defaults.Sleep = usualSleep(sleeps, now)
+	}
+
+	if visible[visibility.RecordMedication] {
+		meds, err := s.medicationService.List(ctx, &medication.MedicationFilter{ChildID: child.ID, ActiveOnly: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, med := range meds {
+			d := MedicationDefault{MedicationID: med.ID, Name: med.Name, Dosage: med.Dosage}
+			last, err := s.medicationService.GetLastLog(ctx, med.ID, "")
+			if err != nil {
+				return nil, err
+			}
+			if last != nil {
+				d.Dosage = last.Dosage
+				d.LastGivenAt = &last.GivenAt
+			}
+			defaults.Medications = append(defaults.Medications, d)
+		}
+	}
+
+	return defaults, nil
+}
+
+// visibleTypes reports which record types behind the suggestions the user
+// may see for the child
+func (s *service) visibleTypes(ctx context.Context, userID string, child *family.Child) (map[visibility.RecordType]bool, error) {
+	visible := map[visibility.RecordType]bool{
+		visibility.RecordFeeding:    true,
+		visibility.RecordSleep:      true,
+		visibility.RecordMedication: true,
+	}
+	if s.visibility == nil {
+		return visible, nil
+	}
+	for recordType := range visible {
+		hidden, err := s.visibility.IsHidden(ctx, userID, child.ID, recordType)
+		if err != nil {
+			return nil, err
+		}
+		visible[recordType] = !hidden
+	}
+	return visible, nil
+}
+
+// typicalAmount returns the median amount of the feeds of one type, in ml and
+// rounded to the nearest 5 ml. Amounts in units that do not convert to ml
+// are skipped.
+func typicalAmount(feeds []feeding.Feeding, feedingType feeding.FeedingType) *AmountDefault {
+	var amounts []float64
+	for i := range feeds {
+		if feeds[i].Type != feedingType {
+			continue
+		}
+		q, ok := feeds[i].Quantity()
+		if !ok {
+			continue
+		}
+		ml, err := q.In(measure.Milliliter)
+		if err != nil {
+			continue
+		}
+		amounts = append(amounts, ml.Value)
+	}
+	if len(amounts) == 0 {
+		return nil
+	}
+
+	slices.Sort(amounts)
+	median := amounts[len(amounts)/2]
+	if len(amounts)%2 == 0 {
+		median = (amounts[len(amounts)/2-1] + median) / 2
+	}
+	rounded := math.Max(bottleStep, math.Round(median/bottleStep)*bottleStep)
+	return &AmountDefault{Amount: rounded, Unit: measure.Milliliter, Samples: len(amounts)}
+}
+
+// usualSleep returns the type most often started within sleepTypeWindow of
+// now's time of day, preferring a nap on a tie. Times of day are compared in
+// UTC, which gives the same answer as the family's own zone apart from an
+// hour either side of a daylight saving change.
+func usualSleep(sleeps []sleep.Sleep, now time.Time) *SleepDefault {
+	counts := map[sleep.SleepType]int{}
+	total := 0
+	for i := range sleeps {
+		if clockDistance(sleeps[i].StartTime, now) > sleepTypeWindow {
+			continue
+		}
+		counts[sleeps[i].Type]++
+		total++
+	}
+	if total == 0 {
+		return nil
+	}
+
+	usual := sleep.SleepTypeNap
+	if counts[sleep.SleepTypeNight] > counts[sleep.SleepTypeNap] {
+		usual = sleep.SleepTypeNight
+	}
+	return &SleepDefault{Type: usual, Samples: total}
+}
+
+// clockDistance is how far apart the times of day of a and b are, wrapping
+// around midnight
+func clockDistance(a, b time.Time) time.Duration {
+	day := 24 * time.Hour
+	diff := (sinceMidnight(a) - sinceMidnight(b)) % day
+	if diff < 0 {
+		diff = -diff
+	}
+	return min(diff, day-diff)
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	t = t.UTC()
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
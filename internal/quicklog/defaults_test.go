@@ -0,0 +1,109 @@
+package quicklog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+func amount(v float64) *float64 {
+	return &v
+}
+
+func TestService_Defaults(t *testing.T) {
+	svc, d := newTestService()
+	now := time.Date(2024, 5, 10, 14, 0, 0, 0, time.UTC)
+	svc.(*service).now = func() time.Time { return now }
+
+	d.feeding.history = []feeding.Feeding{
+		{Type: feeding.FeedingTypeBottle, Amount: amount(110), Unit: "ml"},
+		{Type: feeding.FeedingTypeBottle, Amount: amount(4), Unit: "oz"},
+		{Type: feeding.FeedingTypeBottle, Amount: amount(150)},
+		{Type: feeding.FeedingTypeBottle},
+		{Type: feeding.FeedingTypeFormula, Amount: amount(90), Unit: "ml"},
+		{Type: feeding.FeedingTypeBreast, Side: "left"},
+	}
+	d.sleep.history = []sleep.Sleep{
+		{Type: sleep.SleepTypeNap, StartTime: now.AddDate(0, 0, -1).Add(-30 * time.Minute)},
+		{Type: sleep.SleepTypeNap, StartTime: now.AddDate(0, 0, -2).Add(time.Hour)},
+		{Type: sleep.SleepTypeNight, StartTime: now.AddDate(0, 0, -1).Add(6 * time.Hour)},
+		{Type: sleep.SleepTypeNight, StartTime: now.AddDate(0, 0, -2).Add(6 * time.Hour)},
+		{Type: sleep.SleepTypeNight, StartTime: now.AddDate(0, 0, -3).Add(6 * time.Hour)},
+	}
+	given := now.Add(-6 * time.Hour)
+	d.medication.meds = []medication.Medication{
+		{ID: "med-1", Name: "Vitamin D", Dosage: "400"},
+		{ID: "med-2", Name: "Ibuprofen", Dosage: "2.5 ml"},
+	}
+	d.medication.last = map[string]*medication.MedicationLog{
+		"med-2": {MedicationID: "med-2", Dosage: "5 ml", GivenAt: given},
+	}
+
+	defaults, err := svc.Defaults(context.Background(), "user-1", "")
+	if err != nil {
+		t.Fatalf("Defaults() error = %v", err)
+	}
+
+	// 110, 118.3 and 150 ml: the median rounds to 120
+	if defaults.Bottle == nil || defaults.Bottle.Amount != 120 || defaults.Bottle.Samples != 3 {
+		t.Errorf("Bottle = %+v, want 120 ml from 3 feeds", defaults.Bottle)
+	}
+	if defaults.Formula == nil || defaults.Formula.Amount != 90 {
+		t.Errorf("Formula = %+v, want 90 ml", defaults.Formula)
+	}
+	// Only the two afternoon naps are near 14:00
+	if defaults.Sleep == nil || defaults.Sleep.Type != sleep.SleepTypeNap || defaults.Sleep.Samples != 2 {
+		t.Errorf("Sleep = %+v, want a nap from 2 sleeps", defaults.Sleep)
+	}
+
+	if len(defaults.Medications) != 2 {
+		t.Fatalf("Medications = %+v, want both active medications", defaults.Medications)
+	}
+	if defaults.Medications[0].Dosage != "400" || defaults.Medications[0].LastGivenAt != nil {
+		t.Errorf("never given medication = %+v, want the prescribed dosage", defaults.Medications[0])
+	}
+	if defaults.Medications[1].Dosage != "5 ml" || !defaults.Medications[1].LastGivenAt.Equal(given) {
+		t.Errorf("given medication = %+v, want the last dosage", defaults.Medications[1])
+	}
+}
+
+func TestService_Defaults_NoHistory(t *testing.T) {
+	svc, _ := newTestService()
+
+	defaults, err := svc.Defaults(context.Background(), "user-1", "emma")
+	if err != nil {
+		t.Fatalf("Defaults() error = %v", err)
+	}
+	if defaults.ChildID != "child-1" || defaults.Bottle != nil || defaults.Formula != nil || defaults.Sleep != nil || len(defaults.Medications) != 0 {
+		t.Errorf("Defaults() without history = %+v", defaults)
+	}
+}
+
+func TestService_Defaults_Hidden(t *testing.T) {
+	svc, d := newTestService(WithVisibility(&mockVisibilityService{hidden: visibility.RecordFeeding}))
+	d.feeding.history = []feeding.Feeding{{Type: feeding.FeedingTypeBottle, Amount: amount(120)}}
+
+	defaults, err := svc.Defaults(context.Background(), "user-1", "")
+	if err != nil {
+		t.Fatalf("Defaults() error = %v", err)
+	}
+	if defaults.Bottle != nil {
+		t.Errorf("Bottle = %+v, want nothing from hidden feeds", defaults.Bottle)
+	}
+}
+
+func TestClockDistance(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2024, 5, 10, h, m, 0, 0, time.UTC) }
+
+	if got := clockDistance(at(23, 30), at(0, 15)); got != 45*time.Minute {
+		t.Errorf("clockDistance() across midnight = %v, want 45m", got)
+	}
+	if got := clockDistance(at(14, 0), at(2, 0).AddDate(0, 0, -3)); got != 12*time.Hour {
+		t.Errorf("clockDistance() = %v, want 12h", got)
+	}
+}
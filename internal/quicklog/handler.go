@@ -0,0 +1,132 @@
+package quicklog
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/limits"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterKeyRoutes registers API key management and logging defaults for
+// signed-in users
+func (h *Handler) RegisterKeyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/defaults", h.defaults)
+	rg.GET("/keys", h.listKeys)
+	rg.POST("/keys", h.createKey)
+	rg.DELETE("/keys/:id", h.revokeKey)
+}
+
+// RegisterRoutes registers the quick-log endpoint, which authenticates with a
+// personal API key instead of a session. Middleware in authenticated runs once
+// the key is verified, with user_id and api_key_id set in the context.
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup, authenticated ...gin.HandlerFunc) {
+	handlers := append([]gin.HandlerFunc{h.authenticate}, authenticated...)
+	rg.POST("", append(handlers, h.log)...)
+}
+
+func (h *Handler) listKeys(c *gin.Context) {
+	keys, err := h.service.ListKeys(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *Handler) createKey(c *gin.Context) {
+	var req CreateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := h.service.CreateKey(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *Handler) revokeKey(c *gin.Context) {
+	if err := h.service.RevokeKey(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) authenticate(c *gin.Context) {
+	key, err := h.service.Authenticate(c.Request.Context(), apiKeyFromRequest(c))
+	if err != nil {
+		respondError(c, err)
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", key.UserID)
+	c.Set("api_key_id", key.ID)
+	c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), key.UserID))
+	c.Next()
+}
+
+func (h *Handler) log(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.Log(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *Handler) defaults(c *gin.Context) {
+	defaults, err := h.service.Defaults(c.Request.Context(), c.GetString("user_id"), c.Query("child_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, defaults)
+}
+
+// apiKeyFromRequest reads the key from X-Api-Key, falling back to a bearer
+// token for automation apps that can only set the Authorization header
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader(HeaderAPIKey); key != "" {
+		return key
+	}
+	return strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidAPIKey):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrKeyNotFound), errors.Is(err, ErrChildNotFound), errors.Is(err, ErrMedicationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrChildRequired), errors.Is(err, ErrUnknownType), errors.Is(err, ErrInvalidValue),
+		errors.Is(err, limits.ErrExceeded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,240 @@
+package quicklog
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	createKeyFn    func(ctx context.Context, userID string, req *CreateKeyRequest) (*APIKey, error)
+	listKeysFn     func(ctx context.Context, userID string) ([]APIKey, error)
+	revokeKeyFn    func(ctx context.Context, userID, id string) error
+	authenticateFn func(ctx context.Context, apiKey string) (*APIKey, error)
+	logFn          func(ctx context.Context, userID string, req *Request) (*Result, error)
+	defaultsFn     func(ctx context.Context, userID, childRef string) (*Defaults, error)
+}
+
+func (m *mockService) CreateKey(ctx context.Context, userID string, req *CreateKeyRequest) (*APIKey, error) {
+	if m.createKeyFn != nil {
+		return m.createKeyFn(ctx, userID, req)
+	}
+	return &APIKey{}, nil
+}
+
+func (m *mockService) ListKeys(ctx context.Context, userID string) ([]APIKey, error) {
+	if m.listKeysFn != nil {
+		return m.listKeysFn(ctx, userID)
+	}
+	return []APIKey{}, nil
+}
+
+func (m *mockService) RevokeKey(ctx context.Context, userID, id string) error {
+	if m.revokeKeyFn != nil {
+		return m.revokeKeyFn(ctx, userID, id)
+	}
+	return nil
+}
+
+func (m *mockService) Authenticate(ctx context.Context, apiKey string) (*APIKey, error) {
+	if m.authenticateFn != nil {
+		return m.authenticateFn(ctx, apiKey)
+	}
+	return nil, ErrInvalidAPIKey
+}
+
+func (m *mockService) Log(ctx context.Context, userID string, req *Request) (*Result, error) {
+	if m.logFn != nil {
+		return m.logFn(ctx, userID, req)
+	}
+	return &Result{}, nil
+}
+
+func (m *mockService) Defaults(ctx context.Context, userID, childRef string) (*Defaults, error) {
+	if m.defaultsFn != nil {
+		return m.defaultsFn(ctx, userID, childRef)
+	}
+	return &Defaults{}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	handler.RegisterRoutes(router.Group("/quicklog"))
+
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	handler.RegisterKeyRoutes(protected.Group("/quicklog"))
+	return router
+}
+
+func TestCreateKey_Success(t *testing.T) {
+	var gotUser string
+	svc := &mockService{
+		createKeyFn: func(ctx context.Context, userID string, req *CreateKeyRequest) (*APIKey, error) {
+			gotUser = userID
+			return &APIKey{ID: "key-1", Name: req.Name, Key: "bt_secret"}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/quicklog/keys", bytes.NewBufferString(`{"name":"Phone"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", w.Code)
+	}
+	if gotUser != "test-user-123" {
+		t.Errorf("Expected key for session user, got %q", gotUser)
+	}
+}
+
+func TestRevokeKey_NotFound(t *testing.T) {
+	svc := &mockService{
+		revokeKeyFn: func(ctx context.Context, userID, id string) error {
+			return ErrKeyNotFound
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("DELETE", "/quicklog/keys/key-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestLog_Success(t *testing.T) {
+	var gotKey, gotUser string
+	var gotReq *Request
+	svc := &mockService{
+		authenticateFn: func(ctx context.Context, apiKey string) (*APIKey, error) {
+			gotKey = apiKey
+			return &APIKey{ID: "key-1", UserID: "user-1"}, nil
+		},
+		logFn: func(ctx context.Context, userID string, req *Request) (*Result, error) {
+			gotUser, gotReq = userID, req
+			return &Result{Type: EntryFeeding, RecordID: "feeding-1"}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"child":"Emma","type":"bottle","value":120}`
+	req := httptest.NewRequest("POST", "/quicklog", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer bt_secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotKey != "bt_secret" || gotUser != "user-1" {
+		t.Errorf("Expected bearer key and key owner, got %q and %q", gotKey, gotUser)
+	}
+	if gotReq.Value != "120" || gotReq.Child != "Emma" {
+		t.Errorf("Expected numeric value read as text, got %+v", gotReq)
+	}
+}
+
+func TestLog_ErrorStatuses(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"bad key", ErrInvalidAPIKey, http.StatusUnauthorized},
+		{"unknown child", ErrChildNotFound, http.StatusNotFound},
+		{"ambiguous child", ErrChildRequired, http.StatusBadRequest},
+		{"hidden", ErrHidden, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				authenticateFn: func(ctx context.Context, apiKey string) (*APIKey, error) {
+					if tt.err == ErrInvalidAPIKey {
+						return nil, tt.err
+					}
+					return &APIKey{UserID: "user-1"}, nil
+				},
+				logFn: func(ctx context.Context, userID string, req *Request) (*Result, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/quicklog", bytes.NewBufferString(`{"type":"note","value":"hi"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(HeaderAPIKey, "bt_secret")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestDefaults_Success(t *testing.T) {
+	var gotUser, gotChild string
+	svc := &mockService{
+		defaultsFn: func(ctx context.Context, userID, childRef string) (*Defaults, error) {
+			gotUser, gotChild = userID, childRef
+			return &Defaults{ChildID: "child-1", Bottle: &AmountDefault{Amount: 120, Unit: "ml", Samples: 8}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/quicklog/defaults?child_id=child-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if gotUser != "test-user-123" || gotChild != "child-1" {
+		t.Errorf("Defaults() called with %q, %q", gotUser, gotChild)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"amount":120`)) {
+		t.Errorf("Expected the bottle suggestion, got %s", w.Body.String())
+	}
+}
+
+func TestDefaults_ChildNotFound(t *testing.T) {
+	svc := &mockService{
+		defaultsFn: func(ctx context.Context, userID, childRef string) (*Defaults, error) {
+			return nil, ErrChildNotFound
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/quicklog/defaults?child_id=child-9", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,78 @@
+package quicklog
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+type EntryType string
+
+const (
+	EntryFeeding    EntryType = "feeding"
+	EntryBottle     EntryType = "bottle"
+	EntryBreast     EntryType = "breast"
+	EntryFormula    EntryType = "formula"
+	EntrySolid      EntryType = "solid"
+	EntrySleep      EntryType = "sleep"
+	EntryMedication EntryType = "medication"
+	EntryNote       EntryType = "note"
+	EntryWeight     EntryType = "weight"
+)
+
+// HeaderAPIKey carries a personal API key on quick-log requests
+const HeaderAPIKey = "X-Api-Key"
+
+// APIKey lets shortcuts and automation apps log on behalf of a user without a
+// browser session. The key itself is only returned when it is created.
+type APIKey struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Key        string     `json:"key,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+type CreateKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// Value accepts either a JSON string or a number, since shortcut apps are not
+// consistent about which they send for "120" or "3.4"
+type Value string
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = Value(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*v = Value(n.String())
+	return nil
+}
+
+func (v Value) Float() (float64, error) {
+	return strconv.ParseFloat(string(v), 64)
+}
+
+// Request is a single quick-log entry. Child may be a child ID or name and can
+// be left out when the user only has one child.
+type Request struct {
+	Child string    `json:"child"`
+	Type  EntryType `json:"type" binding:"required"`
+	Value Value     `json:"value"`
+}
+
+// Result names the record a quick-log entry became, with a one-line summary
+// suitable for a shortcut to show or speak back
+type Result struct {
+	Type     EntryType `json:"type"`
+	RecordID string    `json:"record_id"`
+	ChildID  string    `json:"child_id"`
+	Summary  string    `json:"summary"`
+}
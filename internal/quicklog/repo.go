@@ -0,0 +1,122 @@
+package quicklog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, key *APIKey, keyHash string) error
+	GetByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	ListByUser(ctx context.Context, userID string) ([]APIKey, error)
+	Revoke(ctx context.Context, userID, id string, revokedAt time.Time) (bool, error)
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the API key table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "api_keys")
+}
+
+func (r *repository) Create(ctx context.Context, key *APIKey, keyHash string) error {
+	query := `
+		INSERT INTO api_keys (id, user_id, name, key_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, key.ID, key.UserID, key.Name, keyHash, key.CreatedAt)
+	return err
+}
+
+// GetByHash returns nil when no unrevoked key has the given hash
+func (r *repository) GetByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	query := `
+		SELECT id, user_id, name, created_at, last_used_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`
+
+	var k APIKey
+	var lastUsedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, keyHash).Scan(
+		&k.ID, &k.UserID, &k.Name, &k.CreatedAt, &lastUsedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &k, nil
+}
+
+func (r *repository) ListByUser(ctx context.Context, userID string) ([]APIKey, error) {
+	query := `
+		SELECT id, user_id, name, created_at, last_used_at
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	keys := []APIKey{}
+	for rows.Next() {
+		var k APIKey
+		var lastUsedAt sql.NullTime
+		if err := rows.Scan(&k.ID, &k.UserID, &k.Name, &k.CreatedAt, &lastUsedAt); err != nil {
+			return nil, err
+		}
+		if lastUsedAt.Valid {
+			k.LastUsedAt = &lastUsedAt.Time
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke reports whether a live key belonging to the user was revoked
+func (r *repository) Revoke(ctx context.Context, userID, id string, revokedAt time.Time) (bool, error) {
+	query := `
+		UPDATE api_keys SET revoked_at = $3
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID, revokedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *repository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, usedAt)
+	return err
+}
@@ -0,0 +1,94 @@
+package quicklog
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	k := &APIKey{ID: "key-1", UserID: "user-1", Name: "Phone", CreatedAt: now}
+
+	mock.ExpectExec("INSERT INTO api_keys").
+		WithArgs("key-1", "user-1", "Phone", "hash", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), k, "hash"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetByHash(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, user_id, name, created_at, last_used_at FROM api_keys").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "created_at", "last_used_at"}).
+			AddRow("key-1", "user-1", "Phone", now, nil))
+
+	k, err := repo.GetByHash(context.Background(), "hash")
+	if err != nil {
+		t.Fatalf("GetByHash() error = %v", err)
+	}
+	if k == nil || k.UserID != "user-1" || k.LastUsedAt != nil {
+		t.Errorf("GetByHash() = %+v", k)
+	}
+}
+
+func TestRepository_GetByHash_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT id, user_id, name, created_at, last_used_at FROM api_keys").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	k, err := repo.GetByHash(context.Background(), "missing")
+	if err != nil || k != nil {
+		t.Errorf("GetByHash() = %+v, %v; want nil, nil", k, err)
+	}
+}
+
+func TestRepository_Revoke(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE api_keys SET revoked_at").
+		WithArgs("key-1", "user-2", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	revoked, err := repo.Revoke(context.Background(), "user-2", "key-1", now)
+	if err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if revoked {
+		t.Error("Revoke() should report false when no key of the user matched")
+	}
+}
@@ -0,0 +1,499 @@
+package quicklog
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/measure"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrInvalidAPIKey      = errors.New("invalid api key")
+	ErrKeyNotFound        = errors.New("api key not found")
+	ErrChildNotFound      = errors.New("child not found")
+	ErrChildRequired      = errors.New("child is required when you have more than one")
+	ErrUnknownType        = errors.New("unknown quick-log type")
+	ErrInvalidValue       = errors.New("invalid value")
+	ErrMedicationNotFound = errors.New("medication not found")
+	ErrHidden             = errors.New("these records are hidden from you")
+)
+
+// keyPrefix makes quick-log keys recognisable in shortcut configs and logs
+const keyPrefix = "bt_"
+
+// Source tags growth measurements that arrived through a quick-log request
+const Source = "quicklog"
+
+// hiddenAs maps each entry type onto the record type family visibility
+// settings know about. Types without an entry are never hidden.
+var hiddenAs = map[EntryType]visibility.RecordType{
+	EntryFeeding:    visibility.RecordFeeding,
+	EntryBottle:     visibility.RecordFeeding,
+	EntryBreast:     visibility.RecordFeeding,
+	EntryFormula:    visibility.RecordFeeding,
+	EntrySolid:      visibility.RecordFeeding,
+	EntrySleep:      visibility.RecordSleep,
+	EntryMedication: visibility.RecordMedication,
+	EntryNote:       visibility.RecordNotes,
+}
+
+type Service interface {
+	CreateKey(ctx context.Context, userID string, req *CreateKeyRequest) (*APIKey, error)
+	ListKeys(ctx context.Context, userID string) ([]APIKey, error)
+	RevokeKey(ctx context.Context, userID, id string) error
+	Authenticate(ctx context.Context, apiKey string) (*APIKey, error)
+	Log(ctx context.Context, userID string, req *Request) (*Result, error)
+	// Defaults suggests values for quick-logging the child from its recent
+	// records. childRef is a child ID or name, as in a quick-log request.
+	Defaults(ctx context.Context, userID, childRef string) (*Defaults, error)
+}
+
+type service struct {
+	repo              Repository
+	familyService     family.Service
+	feedingService    feeding.Service
+	sleepService      sleep.Service
+	medicationService medication.Service
+	notesService      notes.Service
+	growthService     growth.Service
+	visibility        visibility.Service
+	now               func() time.Time
+}
+
+type Option func(*service)
+
+// WithVisibility applies family visibility settings so a quick-log key cannot
+// write records its owner is not allowed to see
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+func NewService(
+	repo Repository,
+	familyService family.Service,
+	feedingService feeding.Service,
+	sleepService sleep.Service,
+	medicationService medication.Service,
+	notesService notes.Service,
+	growthService growth.Service,
+	opts ...Option,
+) Service {
+	s := &service{
+		repo:              repo,
+		familyService:     familyService,
+		feedingService:    feedingService,
+		sleepService:      sleepService,
+		medicationService: medicationService,
+		notesService:      notesService,
+		growthService:     growthService,
+		now:               time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether API keys can be checked, which every quick
+// log needs
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) CreateKey(ctx context.Context, userID string, req *CreateKeyRequest) (*APIKey, error) {
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key := &APIKey{
+		ID:        ids.New(),
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repo.Create(ctx, key, hashKey(apiKey)); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	key.Key = apiKey
+	return key, nil
+}
+
+func (s *service) ListKeys(ctx context.Context, userID string) ([]APIKey, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) RevokeKey(ctx context.Context, userID, id string) error {
+	revoked, err := s.repo.Revoke(ctx, userID, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if !revoked {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+func (s *service) Authenticate(ctx context.Context, apiKey string) (*APIKey, error) {
+	if !strings.HasPrefix(apiKey, keyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetByHash(ctx, hashKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if key == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, key.ID, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to update api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// Log maps a quick-log entry onto the module that owns its record type,
+// filling in whatever a one-tap shortcut leaves out: the time is always now,
+// bottle amounts are in ml and a bare sleep entry toggles the running timer.
+func (s *service) Log(ctx context.Context, userID string, req *Request) (*Result, error) {
+	child, err := s.resolveChild(ctx, userID, req.Child)
+	if err != nil {
+		return nil, err
+	}
+
+	if recordType, ok := hiddenAs[req.Type]; ok && s.visibility != nil {
+		hidden, err := s.visibility.IsHidden(ctx, userID, child.ID, recordType)
+		if err != nil {
+			return nil, err
+		}
+		if hidden {
+			return nil, ErrHidden
+		}
+	}
+
+	value := strings.TrimSpace(string(req.Value))
+
+	switch req.Type {
+	case EntryFeeding, EntryBottle, EntryFormula:
+		feedingType := feeding.FeedingTypeBottle
+		if req.Type == EntryFormula {
+			feedingType = feeding.FeedingTypeFormula
+		}
+		return s.logFeeding(ctx, child, feedingType, value)
+	case EntryBreast:
+		return s.logBreast(ctx, child, value)
+	case EntrySolid:
+		return s.logSolid(ctx, child, value)
+	case EntrySleep:
+		return s.logSleep(ctx, userID, child, value)
+	case EntryMedication:
+		return s.logMedication(ctx, userID, child, value)
+	case EntryNote:
+		return s.logNote(ctx, userID, child, value)
+	case EntryWeight:
+		return s.logWeight(ctx, child, value)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, req.Type)
+	}
+}
+
+func (s *service) logFeeding(ctx context.Context, child *family.Child, feedingType feeding.FeedingType, value string) (*Result, error) {
+	req := &feeding.CreateFeedingRequest{
+		ChildID:   child.ID,
+		Type:      feedingType,
+		StartTime: time.Now(),
+	}
+
+	summary := fmt.Sprintf("Logged a %s feed for %s", feedingType, child.Name)
+	if value != "" {
+		amount, err := parsePositive(value)
+		if err != nil {
+			return nil, err
+		}
+		req.Amount = &amount
+		req.Unit = string(measure.Milliliter)
+		summary = fmt.Sprintf("Logged %s ml %s for %s", formatNumber(amount), feedingType, child.Name)
+	}
+
+	f, err := s.feedingService.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Type: EntryFeeding, RecordID: f.ID, ChildID: child.ID, Summary: summary}, nil
+}
+
+func (s *service) logBreast(ctx context.Context, child *family.Child, value string) (*Result, error) {
+	side := strings.ToLower(value)
+	switch side {
+	case "", "left", "right", "both":
+	default:
+		return nil, fmt.Errorf("%w: side must be left, right or both", ErrInvalidValue)
+	}
+
+	f, err := s.feedingService.Create(ctx, &feeding.CreateFeedingRequest{
+		ChildID:   child.ID,
+		Type:      feeding.FeedingTypeBreast,
+		StartTime: time.Now(),
+		Side:      side,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("Logged a breast feed for %s", child.Name)
+	if side != "" {
+		summary = fmt.Sprintf("Logged a breast feed (%s) for %s", side, child.Name)
+	}
+	return &Result{Type: EntryFeeding, RecordID: f.ID, ChildID: child.ID, Summary: summary}, nil
+}
+
+func (s *service) logSolid(ctx context.Context, child *family.Child, value string) (*Result, error) {
+	f, err := s.feedingService.Create(ctx, &feeding.CreateFeedingRequest{
+		ChildID:   child.ID,
+		Type:      feeding.FeedingTypeSolid,
+		StartTime: time.Now(),
+		Notes:     value,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Type:     EntryFeeding,
+		RecordID: f.ID,
+		ChildID:  child.ID,
+		Summary:  fmt.Sprintf("Logged solids for %s", child.Name),
+	}, nil
+}
+
+// logSleep starts or ends the sleep timer. "start", "nap" and "night" start
+// one, "end" stops it and an empty value flips whichever state the child is in.
+func (s *service) logSleep(ctx context.Context, userID string, child *family.Child, value string) (*Result, error) {
+	action := strings.ToLower(value)
+	switch action {
+	case "", "start", "end", "nap", "night":
+	default:
+		return nil, fmt.Errorf("%w: sleep value must be start, end, nap or night", ErrInvalidValue)
+	}
+
+	active, err := s.sleepService.GetActiveSleep(ctx, child.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if action == "end" || (action == "" && active != nil) {
+		if active == nil {
+			return nil, fmt.Errorf("%w: %s is not asleep", ErrInvalidValue, child.Name)
+		}
+		ended, err := s.sleepService.EndSleep(ctx, userID, active.ID)
+		if err != nil {
+			return nil, err
+		}
+		summary := fmt.Sprintf("%s woke up", child.Name)
+		if d, ok := ended.Duration(); ok {
+			summary = fmt.Sprintf("%s slept for %s", child.Name, d)
+		}
+		return &Result{Type: EntrySleep, RecordID: ended.ID, ChildID: child.ID, Summary: summary}, nil
+	}
+
+	if active != nil {
+		return &Result{
+			Type:     EntrySleep,
+			RecordID: active.ID,
+			ChildID:  child.ID,
+			Summary:  fmt.Sprintf("%s is already asleep", child.Name),
+		}, nil
+	}
+
+	sleepType := sleep.SleepTypeNap
+	if action == "night" {
+		sleepType = sleep.SleepTypeNight
+	}
+	started, err := s.sleepService.StartSleep(ctx, userID, child.ID, sleepType)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Type:     EntrySleep,
+		RecordID: started.ID,
+		ChildID:  child.ID,
+		Summary:  fmt.Sprintf("Started a %s for %s", sleepType, child.Name),
+	}, nil
+}
+
+// logMedication records a dose of one of the child's active medications at
+// its prescribed dosage. The value names the medication and may be left out
+// when only one is active.
+func (s *service) logMedication(ctx context.Context, userID string, child *family.Child, value string) (*Result, error) {
+	meds, err := s.medicationService.List(ctx, &medication.MedicationFilter{ChildID: child.ID, ActiveOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var med *medication.Medication
+	switch {
+	case value == "" && len(meds) == 1:
+		med = &meds[0]
+	case value == "":
+		return nil, fmt.Errorf("%w: name the medication", ErrInvalidValue)
+	default:
+		for i := range meds {
+			if meds[i].ID == value || strings.EqualFold(meds[i].Name, value) {
+				med = &meds[i]
+				break
+			}
+		}
+	}
+	if med == nil {
+		return nil, ErrMedicationNotFound
+	}
+
+	log, err := s.medicationService.LogMedication(ctx, userID, &medication.LogMedicationRequest{
+		MedicationID: med.ID,
+		GivenAt:      time.Now(),
+		Dosage:       med.Dosage,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Type:     EntryMedication,
+		RecordID: log.ID,
+		ChildID:  child.ID,
+		Summary:  fmt.Sprintf("Gave %s to %s", med.Name, child.Name),
+	}, nil
+}
+
+func (s *service) logNote(ctx context.Context, userID string, child *family.Child, value string) (*Result, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%w: a note needs some text", ErrInvalidValue)
+	}
+
+	note, err := s.notesService.Create(ctx, userID, &notes.CreateNoteRequest{
+		ChildID: child.ID,
+		Content: value,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Type:     EntryNote,
+		RecordID: note.ID,
+		ChildID:  child.ID,
+		Summary:  fmt.Sprintf("Saved a note for %s", child.Name),
+	}, nil
+}
+
+func (s *service) logWeight(ctx context.Context, child *family.Child, value string) (*Result, error) {
+	weight, err := parsePositive(value)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := s.growthService.Create(ctx, &growth.CreateMeasurementRequest{
+		ChildID:    child.ID,
+		MeasuredAt: time.Now(),
+		WeightKg:   &weight,
+		Source:     Source,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Type:     EntryWeight,
+		RecordID: m.ID,
+		ChildID:  child.ID,
+		Summary:  fmt.Sprintf("Logged %s kg for %s", formatNumber(weight), child.Name),
+	}, nil
+}
+
+// resolveChild finds the child across every family the user belongs to,
+// matching on ID first and then on name. Only children the user can see are
+// candidates, so a match also proves access.
+func (s *service) resolveChild(ctx context.Context, userID, ref string) (*family.Child, error) {
+	families, err := s.familyService.GetUserFamilies(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []family.Child
+	for _, f := range families {
+		children = append(children, f.Children...)
+	}
+
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		if len(children) == 1 {
+			return &children[0], nil
+		}
+		if len(children) == 0 {
+			return nil, ErrChildNotFound
+		}
+		return nil, ErrChildRequired
+	}
+
+	for i := range children {
+		if children[i].ID == ref {
+			return &children[i], nil
+		}
+	}
+
+	var match *family.Child
+	for i := range children {
+		if strings.EqualFold(children[i].Name, ref) {
+			if match != nil {
+				return nil, fmt.Errorf("%w: more than one child is called %s", ErrChildRequired, ref)
+			}
+			match = &children[i]
+		}
+	}
+	if match == nil {
+		return nil, ErrChildNotFound
+	}
+	return match, nil
+}
+
+func parsePositive(value string) (float64, error) {
+	n, err := Value(value).Float()
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: expected a positive number", ErrInvalidValue)
+	}
+	return n, nil
+}
+
+func formatNumber(n float64) string {
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", n), ".0")
+}
+
+func hashKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,388 @@
+package quicklog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/growth"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// mockRepository implements Repository for testing
+type mockRepository struct {
+	keys    map[string]*APIKey
+	hashes  map[string]string // hash -> key ID
+	revoked map[string]bool
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		keys:    make(map[string]*APIKey),
+		hashes:  make(map[string]string),
+		revoked: make(map[string]bool),
+	}
+}
+
+func (m *mockRepository) Create(ctx context.Context, key *APIKey, keyHash string) error {
+	stored := *key
+	m.keys[key.ID] = &stored
+	m.hashes[keyHash] = key.ID
+	return nil
+}
+
+func (m *mockRepository) GetByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	id, ok := m.hashes[keyHash]
+	if !ok || m.revoked[id] {
+		return nil, nil
+	}
+	k := *m.keys[id]
+	return &k, nil
+}
+
+func (m *mockRepository) ListByUser(ctx context.Context, userID string) ([]APIKey, error) {
+	var result []APIKey
+	for _, k := range m.keys {
+		if k.UserID == userID && !m.revoked[k.ID] {
+			result = append(result, *k)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) Revoke(ctx context.Context, userID, id string, revokedAt time.Time) (bool, error) {
+	k, ok := m.keys[id]
+	if !ok || k.UserID != userID || m.revoked[id] {
+		return false, nil
+	}
+	m.revoked[id] = true
+	return true, nil
+}
+
+func (m *mockRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	if k, ok := m.keys[id]; ok {
+		k.LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	children []family.Child
+}
+
+func (m *mockFamilyService) GetUserFamilies(ctx context.Context, userID string) ([]family.FamilyWithChildren, error) {
+	if userID != "user-1" {
+		return []family.FamilyWithChildren{}, nil
+	}
+	return []family.FamilyWithChildren{{ID: "family-1", Children: m.children}}, nil
+}
+
+type mockFeedingService struct {
+	feeding.Service
+	created []*feeding.CreateFeedingRequest
+	history []feeding.Feeding
+}
+
+func (m *mockFeedingService) List(ctx context.Context, filter *feeding.FeedingFilter) ([]feeding.Feeding, error) {
+	return m.history, nil
+}
+
+func (m *mockFeedingService) Create(ctx context.Context, req *feeding.CreateFeedingRequest) (*feeding.Feeding, error) {
+	m.created = append(m.created, req)
+	return &feeding.Feeding{ID: fmt.Sprintf("feeding-%d", len(m.created))}, nil
+}
+
+type mockSleepService struct {
+	sleep.Service
+	active  *sleep.Sleep
+	started []sleep.SleepType
+	ended   []string
+	history []sleep.Sleep
+}
+
+func (m *mockSleepService) List(ctx context.Context, filter *sleep.SleepFilter) ([]sleep.Sleep, error) {
+	return m.history, nil
+}
+
+func (m *mockSleepService) GetActiveSleep(ctx context.Context, childID, viewerID string) (*sleep.Sleep, error) {
+	return m.active, nil
+}
+
+func (m *mockSleepService) StartSleep(ctx context.Context, userID, childID string, sleepType sleep.SleepType) (*sleep.Sleep, error) {
+	m.started = append(m.started, sleepType)
+	return &sleep.Sleep{ID: "sleep-new", ChildID: childID, Type: sleepType, StartTime: time.Now()}, nil
+}
+
+func (m *mockSleepService) EndSleep(ctx context.Context, userID, id string) (*sleep.Sleep, error) {
+	m.ended = append(m.ended, id)
+	s := *m.active
+	end := s.StartTime.Add(90 * time.Minute)
+	s.EndTime = &end
+	return &s, nil
+}
+
+type mockMedicationService struct {
+	medication.Service
+	meds   []medication.Medication
+	logged []*medication.LogMedicationRequest
+	last   map[string]*medication.MedicationLog
+}
+
+func (m *mockMedicationService) GetLastLog(ctx context.Context, medicationID, viewerID string) (*medication.MedicationLog, error) {
+	return m.last[medicationID], nil
+}
+
+func (m *mockMedicationService) List(ctx context.Context, filter *medication.MedicationFilter) ([]medication.Medication, error) {
+	return m.meds, nil
+}
+
+func (m *mockMedicationService) LogMedication(ctx context.Context, userID string, req *medication.LogMedicationRequest) (*medication.MedicationLog, error) {
+	m.logged = append(m.logged, req)
+	return &medication.MedicationLog{ID: "log-1", MedicationID: req.MedicationID}, nil
+}
+
+type mockNotesService struct {
+	notes.Service
+	created []*notes.CreateNoteRequest
+}
+
+func (m *mockNotesService) Create(ctx context.Context, userID string, req *notes.CreateNoteRequest) (*notes.Note, error) {
+	m.created = append(m.created, req)
+	return &notes.Note{ID: "note-1"}, nil
+}
+
+type mockGrowthService struct {
+	growth.Service
+	created []*growth.CreateMeasurementRequest
+}
+
+func (m *mockGrowthService) Create(ctx context.Context, req *growth.CreateMeasurementRequest) (*growth.Measurement, error) {
+	m.created = append(m.created, req)
+	return &growth.Measurement{ID: "growth-1"}, nil
+}
+
+type mockVisibilityService struct {
+	visibility.Service
+	hidden visibility.RecordType
+}
+
+func (m *mockVisibilityService) IsHidden(ctx context.Context, userID, childID string, recordType visibility.RecordType) (bool, error) {
+	return recordType == m.hidden, nil
+}
+
+type testDeps struct {
+	repo       *mockRepository
+	family     *mockFamilyService
+	feeding    *mockFeedingService
+	sleep      *mockSleepService
+	medication *mockMedicationService
+	notes      *mockNotesService
+	growth     *mockGrowthService
+}
+
+func newTestService(opts ...Option) (Service, *testDeps) {
+	d := &testDeps{
+		repo:       newMockRepository(),
+		family:     &mockFamilyService{children: []family.Child{{ID: "child-1", FamilyID: "family-1", Name: "Emma"}}},
+		feeding:    &mockFeedingService{},
+		sleep:      &mockSleepService{},
+		medication: &mockMedicationService{},
+		notes:      &mockNotesService{},
+		growth:     &mockGrowthService{},
+	}
+	svc := NewService(d.repo, d.family, d.feeding, d.sleep, d.medication, d.notes, d.growth, opts...)
+	return svc, d
+}
+
+func TestService_CreateAndAuthenticate(t *testing.T) {
+	svc, d := newTestService()
+	ctx := context.Background()
+
+	key, err := svc.CreateKey(ctx, "user-1", &CreateKeyRequest{Name: " Phone "})
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+	if !strings.HasPrefix(key.Key, keyPrefix) || key.Name != "Phone" {
+		t.Errorf("CreateKey() = %+v", key)
+	}
+	if _, ok := d.repo.hashes[key.Key]; ok {
+		t.Error("CreateKey() should store only a hash of the key")
+	}
+
+	authed, err := svc.Authenticate(ctx, key.Key)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if authed.UserID != "user-1" || authed.Key != "" || d.repo.keys[key.ID].LastUsedAt == nil {
+		t.Errorf("Authenticate() = %+v, want owner, key hidden and last use recorded", authed)
+	}
+
+	if _, err := svc.Authenticate(ctx, "bt_wrong"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() wrong key error = %v, want ErrInvalidAPIKey", err)
+	}
+
+	if err := svc.RevokeKey(ctx, "user-2", key.ID); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("RevokeKey() by another user error = %v, want ErrKeyNotFound", err)
+	}
+	if err := svc.RevokeKey(ctx, "user-1", key.ID); err != nil {
+		t.Fatalf("RevokeKey() error = %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, key.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() revoked key error = %v, want ErrInvalidAPIKey", err)
+	}
+}
+
+func TestService_Log_Bottle(t *testing.T) {
+	svc, d := newTestService()
+
+	result, err := svc.Log(context.Background(), "user-1", &Request{Type: EntryBottle, Value: "120"})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+
+	got := d.feeding.created[0]
+	if got.ChildID != "child-1" || got.Type != feeding.FeedingTypeBottle || *got.Amount != 120 || got.Unit != "ml" {
+		t.Errorf("feeding request = %+v", got)
+	}
+	if result.Summary != "Logged 120 ml bottle for Emma" {
+		t.Errorf("Summary = %q", result.Summary)
+	}
+}
+
+func TestService_Log_ChildByName(t *testing.T) {
+	svc, d := newTestService()
+	d.family.children = []family.Child{
+		{ID: "child-1", FamilyID: "family-1", Name: "Emma"},
+		{ID: "child-2", FamilyID: "family-1", Name: "Noah"},
+	}
+	ctx := context.Background()
+
+	result, err := svc.Log(ctx, "user-1", &Request{Child: "noah", Type: EntryNote, Value: "First tooth"})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if result.ChildID != "child-2" || d.notes.created[0].Content != "First tooth" {
+		t.Errorf("Log() = %+v, note = %+v", result, d.notes.created[0])
+	}
+
+	if _, err := svc.Log(ctx, "user-1", &Request{Type: EntryNote, Value: "x"}); !errors.Is(err, ErrChildRequired) {
+		t.Errorf("Log() without child error = %v, want ErrChildRequired", err)
+	}
+	if _, err := svc.Log(ctx, "user-2", &Request{Child: "child-1", Type: EntryNote, Value: "x"}); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("Log() by non-member error = %v, want ErrChildNotFound", err)
+	}
+}
+
+func TestService_Log_SleepToggle(t *testing.T) {
+	svc, d := newTestService()
+	ctx := context.Background()
+
+	result, err := svc.Log(ctx, "user-1", &Request{Type: EntrySleep})
+	if err != nil {
+		t.Fatalf("Log() start error = %v", err)
+	}
+	if len(d.sleep.started) != 1 || d.sleep.started[0] != sleep.SleepTypeNap || result.RecordID != "sleep-new" {
+		t.Errorf("Log() start = %+v, started = %v", result, d.sleep.started)
+	}
+
+	d.sleep.active = &sleep.Sleep{ID: "sleep-1", ChildID: "child-1", StartTime: time.Now().Add(-90 * time.Minute)}
+	result, err = svc.Log(ctx, "user-1", &Request{Type: EntrySleep})
+	if err != nil {
+		t.Fatalf("Log() end error = %v", err)
+	}
+	if len(d.sleep.ended) != 1 || result.Summary != "Emma slept for 1h 30m" {
+		t.Errorf("Log() end = %+v, ended = %v", result, d.sleep.ended)
+	}
+
+	result, err = svc.Log(ctx, "user-1", &Request{Type: EntrySleep, Value: "night"})
+	if err != nil {
+		t.Fatalf("Log() start while asleep error = %v", err)
+	}
+	if len(d.sleep.started) != 1 || result.RecordID != "sleep-1" {
+		t.Errorf("Log() start while asleep should keep the running timer, got %+v", result)
+	}
+}
+
+func TestService_Log_Medication(t *testing.T) {
+	svc, d := newTestService()
+	d.medication.meds = []medication.Medication{
+		{ID: "med-1", ChildID: "child-1", Name: "Vitamin D", Dosage: "400 IU", Active: true},
+		{ID: "med-2", ChildID: "child-1", Name: "Paracetamol", Dosage: "2.5 ml", Active: true},
+	}
+	ctx := context.Background()
+
+	if _, err := svc.Log(ctx, "user-1", &Request{Type: EntryMedication, Value: "paracetamol"}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if got := d.medication.logged[0]; got.MedicationID != "med-2" || got.Dosage != "2.5 ml" {
+		t.Errorf("medication log = %+v", got)
+	}
+
+	if _, err := svc.Log(ctx, "user-1", &Request{Type: EntryMedication}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Log() without name error = %v, want ErrInvalidValue", err)
+	}
+	if _, err := svc.Log(ctx, "user-1", &Request{Type: EntryMedication, Value: "Ibuprofen"}); !errors.Is(err, ErrMedicationNotFound) {
+		t.Errorf("Log() unknown medication error = %v, want ErrMedicationNotFound", err)
+	}
+}
+
+func TestService_Log_Weight(t *testing.T) {
+	svc, d := newTestService()
+
+	if _, err := svc.Log(context.Background(), "user-1", &Request{Type: EntryWeight, Value: "5.4"}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if got := d.growth.created[0]; *got.WeightKg != 5.4 || got.Source != Source {
+		t.Errorf("growth request = %+v", got)
+	}
+}
+
+func TestService_Log_Validation(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  Request
+		want error
+	}{
+		{"unknown type", Request{Type: "diaper"}, ErrUnknownType},
+		{"negative amount", Request{Type: EntryBottle, Value: "-10"}, ErrInvalidValue},
+		{"bad side", Request{Type: EntryBreast, Value: "middle"}, ErrInvalidValue},
+		{"empty note", Request{Type: EntryNote}, ErrInvalidValue},
+		{"weight missing", Request{Type: EntryWeight}, ErrInvalidValue},
+		{"end while awake", Request{Type: EntrySleep, Value: "end"}, ErrInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Log(ctx, "user-1", &tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("Log() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Log_Hidden(t *testing.T) {
+	svc, d := newTestService(WithVisibility(&mockVisibilityService{hidden: visibility.RecordFeeding}))
+	ctx := context.Background()
+
+	if _, err := svc.Log(ctx, "user-1", &Request{Type: EntryBottle, Value: "90"}); !errors.Is(err, ErrHidden) {
+		t.Errorf("Log() hidden type error = %v, want ErrHidden", err)
+	}
+	if len(d.feeding.created) != 0 {
+		t.Error("Log() should not create a hidden record")
+	}
+	if _, err := svc.Log(ctx, "user-1", &Request{Type: EntryWeight, Value: "5"}); err != nil {
+		t.Errorf("Log() visible type error = %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package quota
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers that tell clients how much of the day's allowance is left
+const (
+	HeaderLimit     = "X-Quota-Limit"
+	HeaderRemaining = "X-Quota-Remaining"
+	HeaderReset     = "X-Quota-Reset"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Enforce counts each request against the caller's daily quota and refuses it
+// with 429 once the quota is spent. It runs after authentication: requests
+// made with a personal API key (api_key_id in the context) count against the
+// key, the rest against user_id. Requests with neither pass through, as do
+// all requests if the count can't be recorded, so a database hiccup doesn't
+// lock everyone out.
+func (h *Handler) Enforce() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		if userID == "" {
+			c.Next()
+			return
+		}
+		subject := UserSubject(userID)
+		if keyID := c.GetString("api_key_id"); keyID != "" {
+			subject = KeySubject(keyID)
+		}
+
+		q, err := h.service.Consume(c.Request.Context(), subject, userID)
+		if err != nil {
+			log.Printf("[quota] Failed to count request for %s: %v", subject, err)
+			c.Next()
+			return
+		}
+
+		if q.Limit > 0 {
+			c.Header(HeaderLimit, strconv.Itoa(q.Limit))
+			c.Header(HeaderRemaining, strconv.Itoa(q.Remaining))
+			c.Header(HeaderReset, strconv.FormatInt(q.ResetsAt.Unix(), 10))
+		}
+		if q.Exceeded() {
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(q.ResetsAt).Seconds())+1))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "daily quota exceeded",
+				"quota": q,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,114 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	Service
+	consumeFn func(ctx context.Context, subject, userID string) (*Quota, error)
+	subject   string
+}
+
+func (m *mockService) Consume(ctx context.Context, subject, userID string) (*Quota, error) {
+	m.subject = subject
+	return m.consumeFn(ctx, subject, userID)
+}
+
+func setupRouter(svc Service, keyID string) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		if keyID != "" {
+			c.Set("api_key_id", keyID)
+		}
+		c.Next()
+	})
+	router.Use(NewHandler(svc).Enforce())
+	router.GET("/feedings", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+	return router
+}
+
+func serve(router *gin.Engine) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/feedings", nil))
+	return w
+}
+
+func TestEnforce_WithinQuota(t *testing.T) {
+	svc := &mockService{consumeFn: func(ctx context.Context, subject, userID string) (*Quota, error) {
+		return &Quota{Limit: 100, Used: 10, Remaining: 90}, nil
+	}}
+
+	w := serve(setupRouter(svc, ""))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if svc.subject != "user:test-user-123" {
+		t.Errorf("Expected the user to be charged, got %q", svc.subject)
+	}
+	if w.Header().Get(HeaderLimit) != "100" || w.Header().Get(HeaderRemaining) != "90" {
+		t.Errorf("Expected quota headers, got %v", w.Header())
+	}
+	if w.Header().Get(HeaderReset) == "" {
+		t.Error("Expected a reset header")
+	}
+}
+
+func TestEnforce_Exceeded(t *testing.T) {
+	svc := &mockService{consumeFn: func(ctx context.Context, subject, userID string) (*Quota, error) {
+		return &Quota{Limit: 100, Used: 100, Rejected: 1}, nil
+	}}
+
+	w := serve(setupRouter(svc, "key-1"))
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	if svc.subject != "key:key-1" {
+		t.Errorf("Expected the API key to be charged, got %q", svc.subject)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+	if w.Header().Get(HeaderRemaining) != "0" {
+		t.Errorf("Expected no quota remaining, got %q", w.Header().Get(HeaderRemaining))
+	}
+}
+
+func TestEnforce_Unlimited(t *testing.T) {
+	svc := &mockService{consumeFn: func(ctx context.Context, subject, userID string) (*Quota, error) {
+		return &Quota{Used: 5000}, nil
+	}}
+
+	w := serve(setupRouter(svc, ""))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if w.Header().Get(HeaderLimit) != "" {
+		t.Error("Expected no quota headers on an unlimited plan")
+	}
+}
+
+func TestEnforce_FailsOpen(t *testing.T) {
+	svc := &mockService{consumeFn: func(ctx context.Context, subject, userID string) (*Quota, error) {
+		return nil, errors.New("database error")
+	}}
+
+	if w := serve(setupRouter(svc, "")); w.Code != http.StatusOK {
+		t.Errorf("Expected the request through when counting fails, got %d", w.Code)
+	}
+}
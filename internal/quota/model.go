@@ -0,0 +1,43 @@
+package quota
+
+import "time"
+
+// Subjects are what a quota is counted against: a signed-in user, or one of
+// their personal API keys, which each get an allowance of their own
+const (
+	userPrefix = "user:"
+	keyPrefix  = "key:"
+)
+
+func UserSubject(userID string) string {
+	return userPrefix + userID
+}
+
+func KeySubject(keyID string) string {
+	return keyPrefix + keyID
+}
+
+// Quota is a subject's allowance for the current UTC day
+type Quota struct {
+	Subject string `json:"subject"`
+	Plan    string `json:"plan"`
+	// Limit is the daily allowance; 0 means unlimited
+	Limit     int `json:"limit"`
+	Used      int `json:"used"`
+	Remaining int `json:"remaining"`
+	// Rejected counts today's requests refused with 429 once the limit was reached
+	Rejected int       `json:"rejected"`
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+// Exceeded reports whether the request that produced the quota is over the limit
+func (q *Quota) Exceeded() bool {
+	return q.Limit > 0 && q.Rejected > 0
+}
+
+// Usage is a user's consumption today, for the profile endpoint
+type Usage struct {
+	Plan string  `json:"plan"`
+	User Quota   `json:"user"`
+	Keys []Quota `json:"api_keys"`
+}
@@ -0,0 +1,104 @@
+package quota
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	// Increment counts one request for the subject on day and returns the new
+	// count with the plan of the user the subject belongs to
+	Increment(ctx context.Context, subject, userID string, day time.Time) (int, string, error)
+	// ListForUser returns the day's counts for the user and each of their API keys
+	ListForUser(ctx context.Context, userID string, day time.Time) (map[string]int, error)
+	GetPlan(ctx context.Context, userID string) (string, error)
+	// Purge deletes counts for days before the given one
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the usage table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "api_usage")
+}
+
+// Increment upserts the counter and reads the plan in the same statement so
+// the per-request check costs a single round trip
+func (r *repository) Increment(ctx context.Context, subject, userID string, day time.Time) (int, string, error) {
+	query := `
+		WITH hit AS (
+			INSERT INTO api_usage (subject, day, count)
+			VALUES ($1, $2, 1)
+			ON CONFLICT (subject, day) DO UPDATE SET count = api_usage.count + 1
+			RETURNING count
+		)
+		SELECT hit.count, COALESCE((SELECT plan FROM users WHERE id = $3), '')
+		FROM hit
+	`
+
+	var count int
+	var plan string
+	err := r.db.QueryRowContext(ctx, query, subject, day.Format(time.DateOnly), userID).Scan(&count, &plan)
+	if err != nil {
+		return 0, "", err
+	}
+	return count, plan, nil
+}
+
+func (r *repository) ListForUser(ctx context.Context, userID string, day time.Time) (map[string]int, error) {
+	query := `
+		SELECT subject, count
+		FROM api_usage
+		WHERE day = $1
+		  AND (subject = $2 OR subject IN (SELECT 'key:' || id FROM api_keys WHERE user_id = $3))
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, day.Format(time.DateOnly), UserSubject(userID), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var subject string
+		var count int
+		if err := rows.Scan(&subject, &count); err != nil {
+			return nil, err
+		}
+		counts[subject] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *repository) GetPlan(ctx context.Context, userID string) (string, error) {
+	var plan sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT plan FROM users WHERE id = $1`, userID).Scan(&plan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return plan.String, nil
+}
+
+func (r *repository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM api_usage WHERE day < $1`, before.Format(time.DateOnly))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,98 @@
+package quota
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var testDay = time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func TestRepository_Increment(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("INSERT INTO api_usage").
+		WithArgs("key:key-1", "2024-03-10", "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "plan"}).AddRow(12, "pro"))
+
+	count, plan, err := repo.Increment(context.Background(), "key:key-1", "user-1", testDay)
+	if err != nil {
+		t.Fatalf("Increment() error = %v", err)
+	}
+	if count != 12 || plan != "pro" {
+		t.Errorf("Increment() = %d, %q, want 12, pro", count, plan)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListForUser(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT subject, count").
+		WithArgs("2024-03-10", "user:user-1", "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"subject", "count"}).
+			AddRow("user:user-1", 30).
+			AddRow("key:key-1", 5))
+
+	counts, err := repo.ListForUser(context.Background(), "user-1", testDay)
+	if err != nil {
+		t.Fatalf("ListForUser() error = %v", err)
+	}
+	if counts["user:user-1"] != 30 || counts["key:key-1"] != 5 {
+		t.Errorf("ListForUser() = %v", counts)
+	}
+}
+
+func TestRepository_GetPlan(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT plan FROM users").
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"plan"}).AddRow(nil))
+
+	plan, err := repo.GetPlan(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("GetPlan() error = %v", err)
+	}
+	if plan != "" {
+		t.Errorf("GetPlan() = %q, want empty for no plan", plan)
+	}
+}
+
+func TestRepository_Purge(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectExec("DELETE FROM api_usage").
+		WithArgs("2024-03-10").
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	purged, err := repo.Purge(context.Background(), testDay)
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if purged != 4 {
+		t.Errorf("Purge() = %d, want 4", purged)
+	}
+}
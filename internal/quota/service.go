@@ -0,0 +1,128 @@
+// Package quota enforces daily request allowances per user and per personal
+// API key, on top of the burst rate limits of public endpoints. The allowance
+// depends on the user's plan; counts live in the database so every server
+// instance shares them, and days run in UTC.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+// RetentionDays is how long daily counts are kept for support questions
+const RetentionDays = 30
+
+// Plans maps plan names to a daily request limit; 0 means unlimited
+type Plans map[string]int
+
+type Service interface {
+	// Consume counts a request against the subject and returns its quota,
+	// which is Exceeded if the request should be refused
+	Consume(ctx context.Context, subject, userID string) (*Quota, error)
+	Usage(ctx context.Context, userID string) (*Usage, error)
+	// Purge drops counts older than the retention period
+	Purge(ctx context.Context, now time.Time) (int64, error)
+}
+
+type service struct {
+	repo        Repository
+	plans       Plans
+	defaultPlan string
+	now         func() time.Time
+}
+
+// NewService limits users without a plan, or with one not in plans, to the
+// default plan
+func NewService(repo Repository, plans Plans, defaultPlan string) Service {
+	return &service{
+		repo:        repo,
+		plans:       plans,
+		defaultPlan: defaultPlan,
+		now:         time.Now,
+	}
+}
+
+// HealthCheck reports whether API usage can be counted
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Consume(ctx context.Context, subject, userID string) (*Quota, error) {
+	day := startOfDay(s.now())
+
+	count, plan, err := s.repo.Increment(ctx, subject, userID, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count request: %w", err)
+	}
+
+	return s.quota(subject, plan, count, day), nil
+}
+
+func (s *service) Usage(ctx context.Context, userID string) (*Usage, error) {
+	day := startOfDay(s.now())
+
+	plan, err := s.repo.GetPlan(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+	counts, err := s.repo.ListForUser(ctx, userID, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	user := UserSubject(userID)
+	usage := &Usage{Keys: []Quota{}}
+	usage.User = *s.quota(user, plan, counts[user], day)
+	usage.Plan = usage.User.Plan
+
+	for subject, count := range counts {
+		if strings.HasPrefix(subject, keyPrefix) {
+			usage.Keys = append(usage.Keys, *s.quota(subject, plan, count, day))
+		}
+	}
+	sort.Slice(usage.Keys, func(i, j int) bool { return usage.Keys[i].Subject < usage.Keys[j].Subject })
+
+	return usage, nil
+}
+
+func (s *service) Purge(ctx context.Context, now time.Time) (int64, error) {
+	return s.repo.Purge(ctx, startOfDay(now).AddDate(0, 0, -RetentionDays))
+}
+
+// quota works out the allowance from the day's count. Requests past the
+// limit are still counted, so the overflow is the number refused.
+func (s *service) quota(subject, plan string, count int, day time.Time) *Quota {
+	plan = s.resolvePlan(plan)
+	q := &Quota{
+		Subject:  subject,
+		Plan:     plan,
+		Limit:    s.plans[plan],
+		Used:     count,
+		ResetsAt: day.AddDate(0, 0, 1),
+	}
+	if q.Limit > 0 {
+		if count > q.Limit {
+			q.Used = q.Limit
+			q.Rejected = count - q.Limit
+		}
+		q.Remaining = q.Limit - q.Used
+	}
+	return q
+}
+
+func (s *service) resolvePlan(plan string) string {
+	if _, ok := s.plans[plan]; ok {
+		return plan
+	}
+	return s.defaultPlan
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,135 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockRepository struct {
+	counts map[string]int
+	plans  map[string]string
+	purged time.Time
+	err    error
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{counts: make(map[string]int), plans: make(map[string]string)}
+}
+
+func (m *mockRepository) Increment(ctx context.Context, subject, userID string, day time.Time) (int, string, error) {
+	if m.err != nil {
+		return 0, "", m.err
+	}
+	m.counts[subject]++
+	return m.counts[subject], m.plans[userID], nil
+}
+
+func (m *mockRepository) ListForUser(ctx context.Context, userID string, day time.Time) (map[string]int, error) {
+	return m.counts, nil
+}
+
+func (m *mockRepository) GetPlan(ctx context.Context, userID string) (string, error) {
+	return m.plans[userID], nil
+}
+
+func (m *mockRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	m.purged = before
+	return 0, nil
+}
+
+func newTestService(repo Repository) *service {
+	svc := NewService(repo, Plans{"free": 3, "pro": 0}, "free").(*service)
+	svc.now = func() time.Time { return time.Date(2024, 3, 10, 22, 30, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestService_Consume(t *testing.T) {
+	repo := newMockRepository()
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		q, err := svc.Consume(ctx, "user:user-1", "user-1")
+		if err != nil {
+			t.Fatalf("Consume() error = %v", err)
+		}
+		if q.Exceeded() || q.Used != i || q.Remaining != 3-i {
+			t.Errorf("Consume() #%d = %+v, want within quota", i, q)
+		}
+	}
+
+	q, err := svc.Consume(ctx, "user:user-1", "user-1")
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if !q.Exceeded() || q.Used != 3 || q.Remaining != 0 || q.Rejected != 1 {
+		t.Errorf("Consume() over limit = %+v, want exceeded with one rejection", q)
+	}
+	if want := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC); !q.ResetsAt.Equal(want) {
+		t.Errorf("ResetsAt = %v, want %v", q.ResetsAt, want)
+	}
+}
+
+func TestService_Consume_PlanLimits(t *testing.T) {
+	repo := newMockRepository()
+	repo.plans["user-1"] = "pro"
+	repo.plans["user-2"] = "legacy"
+	repo.counts["user:user-1"] = 1000
+	svc := newTestService(repo)
+
+	q, _ := svc.Consume(context.Background(), "user:user-1", "user-1")
+	if q.Plan != "pro" || q.Limit != 0 || q.Exceeded() {
+		t.Errorf("Consume() on unlimited plan = %+v", q)
+	}
+
+	q, _ = svc.Consume(context.Background(), "user:user-2", "user-2")
+	if q.Plan != "free" || q.Limit != 3 {
+		t.Errorf("Consume() on unknown plan = %+v, want the default plan", q)
+	}
+}
+
+func TestService_Consume_Error(t *testing.T) {
+	repo := newMockRepository()
+	repo.err = errors.New("database error")
+	svc := newTestService(repo)
+
+	if _, err := svc.Consume(context.Background(), "user:user-1", "user-1"); err == nil {
+		t.Error("Consume() should return error when counting fails")
+	}
+}
+
+func TestService_Usage(t *testing.T) {
+	repo := newMockRepository()
+	repo.counts["user:user-1"] = 2
+	repo.counts["key:key-b"] = 5
+	repo.counts["key:key-a"] = 1
+	svc := newTestService(repo)
+
+	usage, err := svc.Usage(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("Usage() error = %v", err)
+	}
+	if usage.Plan != "free" || usage.User.Used != 2 || usage.User.Remaining != 1 {
+		t.Errorf("Usage() user = %+v", usage.User)
+	}
+	if len(usage.Keys) != 2 || usage.Keys[0].Subject != "key:key-a" {
+		t.Fatalf("Usage() keys = %+v, want both keys in order", usage.Keys)
+	}
+	if usage.Keys[1].Used != 3 || usage.Keys[1].Rejected != 2 {
+		t.Errorf("Usage() key over limit = %+v, want 3 used and 2 rejected", usage.Keys[1])
+	}
+}
+
+func TestService_Purge(t *testing.T) {
+	repo := newMockRepository()
+	svc := newTestService(repo)
+
+	if _, err := svc.Purge(context.Background(), time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !repo.purged.Equal(want) {
+		t.Errorf("Purge() before %v, want %v", repo.purged, want)
+	}
+}
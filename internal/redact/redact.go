@@ -0,0 +1,165 @@
+// Package redact masks personal data and secrets before they reach logs and
+// error reports: emails, tokens and API keys, note content and medical
+// fields. The defaults can be extended from config with more field names and
+// patterns, so verbose logging can be turned on in production without
+// writing what families record to disk.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Mask replaces whatever is redacted
+const Mask = "[redacted]"
+
+// DefaultKeys are the JSON fields and query parameters whose values are
+// always masked, matched without regard to case
+var DefaultKeys = []string{
+	// Secrets
+	"token", "access_token", "refresh_token", "id_token", "code", "api_key", "key",
+	"password", "secret", "authorization", "jwt_secret",
+	// Contact details
+	"email", "phone", "address",
+	// Note and message content
+	"notes", "content", "caption", "body", "text",
+	// Medical and insurance details
+	"insurance", "pharmacy", "policy_number", "group_number", "policy_holder",
+	"allergies", "conditions", "diagnosis", "symptoms", "dosage", "instructions",
+}
+
+// DefaultPatterns are masked wherever they appear
+var DefaultPatterns = []string{
+	`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,    // emails
+	`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`, // JWTs
+	`(?i)bearer\s+[A-Za-z0-9._~+/=-]+`,                  // Authorization header values
+	`\bbt_[0-9a-f]{16,}\b`,                              // quick-log API keys
+	`\b[0-9a-f]{64}\b`,                                  // device API keys
+}
+
+type Config struct {
+	// Keys are masked on top of DefaultKeys
+	Keys []string
+	// Allow keeps fields that DefaultKeys would mask
+	Allow []string
+	// Patterns are regular expressions masked on top of DefaultPatterns
+	Patterns []string
+}
+
+type Redactor struct {
+	keys     map[string]bool
+	patterns []*regexp.Regexp
+	// query matches key=value pairs and fields matches "key": value pairs of
+	// the masked keys in free text
+	query  *regexp.Regexp
+	fields *regexp.Regexp
+}
+
+func New(cfg Config) (*Redactor, error) {
+	r := &Redactor{keys: make(map[string]bool)}
+	for _, k := range slices.Concat(DefaultKeys, cfg.Keys) {
+		r.keys[strings.ToLower(strings.TrimSpace(k))] = true
+	}
+	for _, k := range cfg.Allow {
+		delete(r.keys, strings.ToLower(strings.TrimSpace(k)))
+	}
+	delete(r.keys, "")
+
+	for _, p := range slices.Concat(DefaultPatterns, cfg.Patterns) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	if len(r.keys) > 0 {
+		names := make([]string, 0, len(r.keys))
+		for k := range r.keys {
+			names = append(names, regexp.QuoteMeta(k))
+		}
+		alt := strings.Join(names, "|")
+		r.query = regexp.MustCompile(`(?i)\b(` + alt + `)=[^&\s"']*`)
+		r.fields = regexp.MustCompile(`(?i)"(` + alt + `)"\s*:\s*("(?:[^"\\]|\\.)*"|[^,{}\[\]\s]+)`)
+	}
+	return r, nil
+}
+
+// String masks the patterns, and the values of masked keys written as
+// key=value or "key": value, anywhere in s
+func (r *Redactor) String(s string) string {
+	return string(r.Bytes([]byte(s)))
+}
+
+func (r *Redactor) Bytes(b []byte) []byte {
+	if r.query != nil {
+		b = r.query.ReplaceAll(b, []byte("${1}="+Mask))
+		b = r.fields.ReplaceAll(b, []byte(`"${1}":"`+Mask+`"`))
+	}
+	for _, re := range r.patterns {
+		b = re.ReplaceAll(b, []byte(Mask))
+	}
+	return b
+}
+
+// JSON masks the values of masked keys in a JSON body, however deeply
+// nested, and the patterns in every other string. It reports false for
+// bodies that are not JSON.
+func (r *Redactor) JSON(body []byte) ([]byte, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	out, err := json.Marshal(r.value(v))
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+func (r *Redactor) value(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			if value != nil && r.keys[strings.ToLower(k)] {
+				v[k] = Mask
+			} else {
+				v[k] = r.value(value)
+			}
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = r.value(v[i])
+		}
+		return v
+	case string:
+		return r.String(v)
+	}
+	return v
+}
+
+// Writer redacts everything written through it, for log output. Loggers
+// write a line at a time, so nothing is split mid-match.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return &writer{r: r, w: w}
+}
+
+type writer struct {
+	r *Redactor
+	w io.Writer
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := w.w.Write(w.r.Bytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
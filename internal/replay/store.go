@@ -0,0 +1,68 @@
+// Package replay records nonces so that a signed webhook or a sync event
+// is only ever acted on once.
+package replay
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Store interface {
+	// Claim records nonce under scope until expiresAt. It returns false if
+	// the nonce has already been claimed, i.e. the request is a replay.
+	Claim(ctx context.Context, scope, nonce string, expiresAt time.Time) (bool, error)
+	// Release forgets a claim so that a request which failed part-way can be retried.
+	Release(ctx context.Context, scope, nonce string) error
+	// Purge drops claims that expired before the given time and returns how many were removed.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) Store {
+	return &store{db: db}
+}
+
+// HealthCheck checks the nonce table. Signed webhooks and sync pushes are
+// refused while it cannot be written.
+func (s *store) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, s.db, "request_nonces")
+}
+
+func (s *store) Claim(ctx context.Context, scope, nonce string, expiresAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO request_nonces (scope, nonce, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scope, nonce) DO NOTHING
+	`
+
+	result, err := s.db.ExecContext(ctx, query, scope, nonce, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (s *store) Release(ctx context.Context, scope, nonce string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM request_nonces WHERE scope = $1 AND nonce = $2`, scope, nonce)
+	return err
+}
+
+func (s *store) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM request_nonces WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,63 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestStore_Claim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	s := NewStore(db)
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectExec("INSERT INTO request_nonces").
+		WithArgs("webhook:key-1", "nonce-1", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO request_nonces").
+		WithArgs("webhook:key-1", "nonce-1", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	fresh, err := s.Claim(context.Background(), "webhook:key-1", "nonce-1", expiresAt)
+	if err != nil || !fresh {
+		t.Fatalf("first Claim() = %v, %v; want true", fresh, err)
+	}
+
+	fresh, err = s.Claim(context.Background(), "webhook:key-1", "nonce-1", expiresAt)
+	if err != nil || fresh {
+		t.Errorf("replayed Claim() = %v, %v; want false", fresh, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestStore_Purge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	s := NewStore(db)
+
+	now := time.Now()
+	mock.ExpectExec("DELETE FROM request_nonces WHERE expires_at").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	purged, err := s.Purge(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if purged != 3 {
+		t.Errorf("Purge() = %d, want 3", purged)
+	}
+}
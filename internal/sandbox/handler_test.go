@@ -0,0 +1,140 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/notifications"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	createFn   func(ctx context.Context, userID string, req *CreateRequest) (*Created, error)
+	getFn      func(ctx context.Context, userID, familyID string) (*Sandbox, error)
+	capturesFn func(ctx context.Context, userID, familyID string, limit int) ([]Capture, error)
+	resetFn    func(ctx context.Context, userID, familyID string) (*Sandbox, error)
+}
+
+func (m *mockService) Create(ctx context.Context, userID string, req *CreateRequest) (*Created, error) {
+	return m.createFn(ctx, userID, req)
+}
+
+func (m *mockService) Get(ctx context.Context, userID, familyID string) (*Sandbox, error) {
+	return m.getFn(ctx, userID, familyID)
+}
+
+func (m *mockService) Captures(ctx context.Context, userID, familyID string, limit int) ([]Capture, error) {
+	return m.capturesFn(ctx, userID, familyID, limit)
+}
+
+func (m *mockService) Reset(ctx context.Context, userID, familyID string) (*Sandbox, error) {
+	return m.resetFn(ctx, userID, familyID)
+}
+
+func (m *mockService) ResetDue(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (m *mockService) CaptureEvent(ctx context.Context, userID string, event notifications.Event) bool {
+	return false
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterFamilyRoutes(router.Group("/families"))
+	return router
+}
+
+func TestHandler_Create(t *testing.T) {
+	svc := &mockService{createFn: func(ctx context.Context, userID string, req *CreateRequest) (*Created, error) {
+		if userID != "test-user-123" || req.Name != "Test family" {
+			t.Errorf("Create() called with %s, %+v", userID, req)
+		}
+		return &Created{Family: &family.Family{ID: "family-1"}, Sandbox: &Sandbox{FamilyID: "family-1"}}, nil
+	}}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/families/sandbox", bytes.NewBufferString(`{"name":"Test family"}`)))
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/families/sandbox", bytes.NewBufferString(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a name, got %d", w.Code)
+	}
+}
+
+func TestHandler_Captures(t *testing.T) {
+	svc := &mockService{capturesFn: func(ctx context.Context, userID, familyID string, limit int) ([]Capture, error) {
+		if familyID != "family-1" || limit != 10 {
+			t.Errorf("Captures() called with %s, %d", familyID, limit)
+		}
+		return []Capture{{ID: "c1"}}, nil
+	}}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/families/family-1/sandbox/captures?limit=10", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/families/family-1/sandbox/captures?limit=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a bad limit, got %d", w.Code)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrNotSandbox, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+		{ErrNotAdmin, http.StatusForbidden},
+		{context.DeadlineExceeded, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		svc := &mockService{
+			getFn: func(ctx context.Context, userID, familyID string) (*Sandbox, error) {
+				return nil, tt.err
+			},
+			resetFn: func(ctx context.Context, userID, familyID string) (*Sandbox, error) {
+				return nil, tt.err
+			},
+		}
+		router := setupRouter(svc)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/families/family-1/sandbox", nil))
+		if w.Code != tt.want {
+			t.Errorf("GET %v: status = %d, want %d", tt.err, w.Code, tt.want)
+		}
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/families/family-1/sandbox/reset", nil))
+		if w.Code != tt.want {
+			t.Errorf("reset %v: status = %d, want %d", tt.err, w.Code, tt.want)
+		}
+	}
+}
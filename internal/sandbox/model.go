@@ -0,0 +1,56 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+const (
+	// DefaultResetInterval is how often sandbox records are wiped when the
+	// config leaves it out
+	DefaultResetInterval = 24 * time.Hour
+
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// Sandbox marks a family as a test family
+type Sandbox struct {
+	FamilyID  string    `json:"family_id"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ResetAt is when the family's records were last wiped
+	ResetAt     time.Time `json:"reset_at"`
+	NextResetAt time.Time `json:"next_reset_at"`
+}
+
+// CaptureKind is the kind of side effect that was held back
+type CaptureKind string
+
+const (
+	CaptureNotification CaptureKind = "notification"
+)
+
+// Capture is a side effect a sandbox family would have caused, recorded in
+// place of carrying it out
+type Capture struct {
+	ID       string      `json:"id"`
+	FamilyID string      `json:"family_id"`
+	UserID   string      `json:"user_id"`
+	Kind     CaptureKind `json:"kind"`
+	// Payload is what would have been sent, e.g. the notification event
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type CreateRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// Created is a new sandbox family
+type Created struct {
+	Family  *family.Family `json:"family"`
+	Sandbox *Sandbox       `json:"sandbox"`
+}
@@ -0,0 +1,157 @@
+package sandbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, sb *Sandbox) error
+	Get(ctx context.Context, familyID string) (*Sandbox, error)
+	// FamilyOfChild returns the sandbox family the child belongs to, or ""
+	// when the child's family is not a sandbox
+	FamilyOfChild(ctx context.Context, childID string) (string, error)
+	// ListDue returns the sandbox families last reset before the given time
+	ListDue(ctx context.Context, before time.Time) ([]string, error)
+	MarkReset(ctx context.Context, familyID string, at time.Time) error
+
+	AddCapture(ctx context.Context, c *Capture) error
+	ListCaptures(ctx context.Context, familyID string, limit int) ([]Capture, error)
+	DeleteCaptures(ctx context.Context, familyID string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the sandbox family and capture tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "sandbox_families", "sandbox_captures")
+}
+
+func (r *repository) Create(ctx context.Context, sb *Sandbox) error {
+	query := `
+		INSERT INTO sandbox_families (family_id, created_by, created_at, reset_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, sb.FamilyID, nullString(sb.CreatedBy), sb.CreatedAt, sb.ResetAt)
+	return err
+}
+
+func (r *repository) Get(ctx context.Context, familyID string) (*Sandbox, error) {
+	query := `
+		SELECT family_id, COALESCE(created_by, ''), created_at, reset_at
+		FROM sandbox_families
+		WHERE family_id = $1
+	`
+
+	var sb Sandbox
+	err := r.db.QueryRowContext(ctx, query, familyID).Scan(&sb.FamilyID, &sb.CreatedBy, &sb.CreatedAt, &sb.ResetAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sb, nil
+}
+
+func (r *repository) FamilyOfChild(ctx context.Context, childID string) (string, error) {
+	query := `
+		SELECT sf.family_id
+		FROM sandbox_families sf
+		INNER JOIN children c ON c.family_id = sf.family_id
+		WHERE c.id = $1
+	`
+
+	var familyID string
+	err := r.db.QueryRowContext(ctx, query, childID).Scan(&familyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return familyID, err
+}
+
+func (r *repository) ListDue(ctx context.Context, before time.Time) ([]string, error) {
+	query := `SELECT family_id FROM sandbox_families WHERE reset_at < $1 ORDER BY reset_at`
+
+	rows, err := r.db.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	var familyIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		familyIDs = append(familyIDs, id)
+	}
+	return familyIDs, rows.Err()
+}
+
+func (r *repository) MarkReset(ctx context.Context, familyID string, at time.Time) error {
+	query := `UPDATE sandbox_families SET reset_at = $2 WHERE family_id = $1`
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, familyID, at)
+	return err
+}
+
+func (r *repository) AddCapture(ctx context.Context, c *Capture) error {
+	query := `
+		INSERT INTO sandbox_captures (id, family_id, user_id, kind, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, c.ID, c.FamilyID, c.UserID, c.Kind, []byte(c.Payload), c.CreatedAt)
+	return err
+}
+
+func (r *repository) ListCaptures(ctx context.Context, familyID string, limit int) ([]Capture, error) {
+	query := `
+		SELECT id, family_id, user_id, kind, payload, created_at
+		FROM sandbox_captures
+		WHERE family_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, familyID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	captures := []Capture{}
+	for rows.Next() {
+		var c Capture
+		var payload []byte
+		if err := rows.Scan(&c.ID, &c.FamilyID, &c.UserID, &c.Kind, &payload, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		c.Payload = payload
+		captures = append(captures, c)
+	}
+	return captures, rows.Err()
+}
+
+func (r *repository) DeleteCaptures(ctx context.Context, familyID string) error {
+	query := `DELETE FROM sandbox_captures WHERE family_id = $1`
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, familyID)
+	return err
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
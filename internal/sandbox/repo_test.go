@@ -0,0 +1,130 @@
+package sandbox
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO sandbox_families").
+		WithArgs("family-1", "user-1", now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), &Sandbox{FamilyID: "family-1", CreatedBy: "user-1", CreatedAt: now, ResetAt: now}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Get(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT family_id, COALESCE\\(created_by, ''\\), created_at, reset_at").
+		WithArgs("family-1").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id", "created_by", "created_at", "reset_at"}).
+			AddRow("family-1", "user-1", now, now))
+	mock.ExpectQuery("SELECT family_id, COALESCE\\(created_by, ''\\), created_at, reset_at").
+		WithArgs("family-2").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id", "created_by", "created_at", "reset_at"}))
+
+	sb, err := repo.Get(context.Background(), "family-1")
+	if err != nil || sb == nil || sb.CreatedBy != "user-1" {
+		t.Errorf("Get() = %+v, %v", sb, err)
+	}
+	sb, err = repo.Get(context.Background(), "family-2")
+	if err != nil || sb != nil {
+		t.Errorf("Get() = %v, %v; want nil, nil", sb, err)
+	}
+}
+
+func TestRepository_FamilyOfChild(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT sf.family_id FROM sandbox_families sf").
+		WithArgs("child-1").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id"}).AddRow("family-1"))
+	mock.ExpectQuery("SELECT sf.family_id FROM sandbox_families sf").
+		WithArgs("child-2").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id"}))
+
+	if id, err := repo.FamilyOfChild(context.Background(), "child-1"); err != nil || id != "family-1" {
+		t.Errorf("FamilyOfChild() = %q, %v", id, err)
+	}
+	if id, err := repo.FamilyOfChild(context.Background(), "child-2"); err != nil || id != "" {
+		t.Errorf("FamilyOfChild() = %q, %v; want none", id, err)
+	}
+}
+
+func TestRepository_ListDue(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	before := time.Now()
+	mock.ExpectQuery("SELECT family_id FROM sandbox_families WHERE reset_at < \\$1").
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"family_id"}).AddRow("family-1").AddRow("family-2"))
+
+	ids, err := repo.ListDue(context.Background(), before)
+	if err != nil || len(ids) != 2 {
+		t.Errorf("ListDue() = %v, %v", ids, err)
+	}
+}
+
+func TestRepository_Captures(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	c := &Capture{ID: "c1", FamilyID: "family-1", UserID: "user-1", Kind: CaptureNotification, Payload: []byte(`{"id":"e1"}`), CreatedAt: now}
+	mock.ExpectExec("INSERT INTO sandbox_captures").
+		WithArgs("c1", "family-1", "user-1", CaptureNotification, []byte(`{"id":"e1"}`), now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id, family_id, user_id, kind, payload, created_at FROM sandbox_captures").
+		WithArgs("family-1", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "family_id", "user_id", "kind", "payload", "created_at"}).
+			AddRow("c1", "family-1", "user-1", "notification", []byte(`{"id":"e1"}`), now))
+	mock.ExpectExec("DELETE FROM sandbox_captures WHERE family_id = \\$1").
+		WithArgs("family-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.AddCapture(context.Background(), c); err != nil {
+		t.Fatalf("AddCapture() error = %v", err)
+	}
+	captures, err := repo.ListCaptures(context.Background(), "family-1", 50)
+	if err != nil || len(captures) != 1 || string(captures[0].Payload) != `{"id":"e1"}` {
+		t.Errorf("ListCaptures() = %+v, %v", captures, err)
+	}
+	if err := repo.DeleteCaptures(context.Background(), "family-1"); err != nil {
+		t.Errorf("DeleteCaptures() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+// Package sandbox provides test families for integration developers. A
+// sandbox family's records are wiped on a schedule, and notifications about
+// its children are recorded in a capture log instead of being delivered.
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/apijson"
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+var (
+	ErrNotSandbox = errors.New("family is not a sandbox")
+	ErrNotMember  = errors.New("user is not a member of this family")
+	ErrNotAdmin   = errors.New("only admins can reset a sandbox")
+)
+
+type Service interface {
+	// Create creates a new family, with the user as its admin, and marks it
+	// as a sandbox. Existing families cannot become sandboxes, since their
+	// records would be wiped.
+	Create(ctx context.Context, userID string, req *CreateRequest) (*Created, error)
+	Get(ctx context.Context, userID, familyID string) (*Sandbox, error)
+	// Captures returns the family's captured side effects, newest first
+	Captures(ctx context.Context, userID, familyID string, limit int) ([]Capture, error)
+	// Reset wipes the family's records now
+	Reset(ctx context.Context, userID, familyID string) (*Sandbox, error)
+	// ResetDue wipes every sandbox whose reset interval has passed,
+	// returning how many were reset
+	ResetDue(ctx context.Context) (int, error)
+	// CaptureEvent records the event for the user when it is about a child
+	// of a sandbox family, and reports whether it did
+	CaptureEvent(ctx context.Context, userID string, event notifications.Event) bool
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	tx            db.TxManager
+	resetInterval time.Duration
+	now           func() time.Time
+
+	// children caches which sandbox family each child belongs to, "" for
+	// none. Families cannot become or stop being sandboxes, so entries
+	// never go stale.
+	mu       sync.Mutex
+	children map[string]string
+}
+
+type Option func(*service)
+
+// WithTxManager wipes a sandbox all or nothing. The repository must be built
+// on the same database as the family repository.
+func WithTxManager(tx db.TxManager) Option {
+	return func(s *service) {
+		s.tx = tx
+	}
+}
+
+// WithResetInterval sets how often sandbox records are wiped
+func WithResetInterval(d time.Duration) Option {
+	return func(s *service) {
+		if d > 0 {
+			s.resetInterval = d
+		}
+	}
+}
+
+func NewService(repo Repository, familyService family.Service, opts ...Option) Service {
+	s := &service{
+		repo:          repo,
+		familyService: familyService,
+		tx:            db.NoTx,
+		resetInterval: DefaultResetInterval,
+		now:           time.Now,
+		children:      map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether sandbox families can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Create(ctx context.Context, userID string, req *CreateRequest) (*Created, error) {
+	now := s.now()
+	created := &Created{Sandbox: &Sandbox{CreatedBy: userID, CreatedAt: now, ResetAt: now}}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		f, err := s.familyService.CreateFamily(ctx, userID, &family.CreateFamilyRequest{Name: req.Name})
+		if err != nil {
+			return err
+		}
+		created.Family = f
+		created.Sandbox.FamilyID = f.ID
+		if err := s.repo.Create(ctx, created.Sandbox); err != nil {
+			return fmt.Errorf("failed to create sandbox: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created.Sandbox.NextResetAt = now.Add(s.resetInterval)
+	return created, nil
+}
+
+func (s *service) Get(ctx context.Context, userID, familyID string) (*Sandbox, error) {
+	if _, err := s.memberRole(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+	return s.get(ctx, familyID)
+}
+
+func (s *service) Captures(ctx context.Context, userID, familyID string, limit int) ([]Capture, error) {
+	if _, err := s.Get(ctx, userID, familyID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	captures, err := s.repo.ListCaptures(ctx, familyID, min(limit, MaxPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list captures: %w", err)
+	}
+	return captures, nil
+}
+
+func (s *service) Reset(ctx context.Context, userID, familyID string) (*Sandbox, error) {
+	role, err := s.memberRole(ctx, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "admin" {
+		return nil, ErrNotAdmin
+	}
+	if _, err := s.get(ctx, familyID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reset(ctx, familyID); err != nil {
+		return nil, err
+	}
+	return s.get(ctx, familyID)
+}
+
+func (s *service) ResetDue(ctx context.Context) (int, error) {
+	familyIDs, err := s.repo.ListDue(ctx, s.now().Add(-s.resetInterval))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+
+	// One sandbox failing to reset should not keep the rest from theirs
+	reset := 0
+	var failed []error
+	for _, id := range familyIDs {
+		if ctx.Err() != nil {
+			return reset, ctx.Err()
+		}
+		if err := s.reset(ctx, id); err != nil {
+			failed = append(failed, fmt.Errorf("family %s: %w", id, err))
+			continue
+		}
+		reset++
+	}
+	return reset, errors.Join(failed...)
+}
+
+// reset deletes the family's children, and with them every record about
+// them, and clears the capture log. The family and its members are kept so
+// developers can carry on with the same accounts.
+func (s *service) reset(ctx context.Context, familyID string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		children, err := s.familyService.GetChildren(ctx, familyID)
+		if err != nil {
+			return fmt.Errorf("failed to get children: %w", err)
+		}
+		for _, child := range children {
+			if err := s.familyService.DeleteChild(ctx, child.ID); err != nil {
+				return fmt.Errorf("failed to delete child %s: %w", child.ID, err)
+			}
+		}
+		if err := s.repo.DeleteCaptures(ctx, familyID); err != nil {
+			return fmt.Errorf("failed to clear captures: %w", err)
+		}
+		if err := s.repo.MarkReset(ctx, familyID, s.now()); err != nil {
+			return fmt.Errorf("failed to mark sandbox reset: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *service) CaptureEvent(ctx context.Context, userID string, event notifications.Event) bool {
+	if event.ChildID == "" {
+		return false
+	}
+	familyID, err := s.familyOfChild(ctx, event.ChildID)
+	if err != nil {
+		// Delivering a sandbox event is better than losing a real one
+		log.Printf("[sandbox] failed to look up child %s: %v", event.ChildID, err)
+		return false
+	}
+	if familyID == "" {
+		return false
+	}
+
+	payload, err := apijson.Marshal(event)
+	if err != nil {
+		log.Printf("[sandbox] failed to encode %s event: %v", event.Type, err)
+		return true
+	}
+	c := &Capture{
+		ID:        ids.New(),
+		FamilyID:  familyID,
+		UserID:    userID,
+		Kind:      CaptureNotification,
+		Payload:   payload,
+		CreatedAt: s.now(),
+	}
+	if err := s.repo.AddCapture(ctx, c); err != nil {
+		log.Printf("[sandbox] failed to capture %s event for family %s: %v", event.Type, familyID, err)
+	}
+	return true
+}
+
+func (s *service) familyOfChild(ctx context.Context, childID string) (string, error) {
+	s.mu.Lock()
+	familyID, ok := s.children[childID]
+	s.mu.Unlock()
+	if ok {
+		return familyID, nil
+	}
+
+	familyID, err := s.repo.FamilyOfChild(ctx, childID)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	s.children[childID] = familyID
+	s.mu.Unlock()
+	return familyID, nil
+}
+
+func (s *service) get(ctx context.Context, familyID string) (*Sandbox, error) {
+	sb, err := s.repo.Get(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sandbox: %w", err)
+	}
+	if sb == nil {
+		return nil, ErrNotSandbox
+	}
+	sb.NextResetAt = sb.ResetAt.Add(s.resetInterval)
+	return sb, nil
+}
+
+func (s *service) memberRole(ctx context.Context, familyID, userID string) (string, error) {
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return "", ErrNotMember
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// HubFilter holds back events about sandbox children, recording them in the
+// capture log, after next has decided the user would receive them
+func HubFilter(s Service, next notifications.Filter) notifications.Filter {
+	return hubFilter{service: s, next: next}
+}
+
+type hubFilter struct {
+	service Service
+	next    notifications.Filter
+}
+
+func (f hubFilter) Allow(userID string, event notifications.Event) bool {
+	if f.next != nil && !f.next.Allow(userID, event) {
+		return false
+	}
+	return !f.service.CaptureEvent(context.Background(), userID, event)
+}
@@ -0,0 +1,294 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/notifications"
+)
+
+var testNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type mockRepository struct {
+	sandboxes   map[string]*Sandbox
+	childFamily map[string]string
+	captures    []Capture
+	lookups     int
+	createErr   error
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		sandboxes: map[string]*Sandbox{
+			"sandbox-1": {FamilyID: "sandbox-1", CreatedAt: testNow.Add(-48 * time.Hour), ResetAt: testNow.Add(-25 * time.Hour)},
+			"sandbox-2": {FamilyID: "sandbox-2", CreatedAt: testNow.Add(-48 * time.Hour), ResetAt: testNow.Add(-time.Hour)},
+		},
+		childFamily: map[string]string{"sandbox-child": "sandbox-1"},
+	}
+}
+
+func (m *mockRepository) Create(ctx context.Context, sb *Sandbox) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.sandboxes[sb.FamilyID] = sb
+	return nil
+}
+
+func (m *mockRepository) Get(ctx context.Context, familyID string) (*Sandbox, error) {
+	if sb, ok := m.sandboxes[familyID]; ok {
+		copied := *sb
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) FamilyOfChild(ctx context.Context, childID string) (string, error) {
+	m.lookups++
+	return m.childFamily[childID], nil
+}
+
+func (m *mockRepository) ListDue(ctx context.Context, before time.Time) ([]string, error) {
+	var ids []string
+	for id, sb := range m.sandboxes {
+		if sb.ResetAt.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockRepository) MarkReset(ctx context.Context, familyID string, at time.Time) error {
+	m.sandboxes[familyID].ResetAt = at
+	return nil
+}
+
+func (m *mockRepository) AddCapture(ctx context.Context, c *Capture) error {
+	m.captures = append(m.captures, *c)
+	return nil
+}
+
+func (m *mockRepository) ListCaptures(ctx context.Context, familyID string, limit int) ([]Capture, error) {
+	var out []Capture
+	for _, c := range m.captures {
+		if c.FamilyID == familyID && len(out) < limit {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepository) DeleteCaptures(ctx context.Context, familyID string) error {
+	kept := m.captures[:0]
+	for _, c := range m.captures {
+		if c.FamilyID != familyID {
+			kept = append(kept, c)
+		}
+	}
+	m.captures = kept
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	children map[string][]family.Child
+	deleted  []string
+}
+
+func (m *mockFamilyService) CreateFamily(ctx context.Context, userID string, req *family.CreateFamilyRequest) (*family.Family, error) {
+	return &family.Family{ID: "family-new", Name: req.Name}, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	switch userID {
+	case "admin":
+		return "admin", nil
+	case "member":
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) GetChildren(ctx context.Context, familyID string) ([]family.Child, error) {
+	return m.children[familyID], nil
+}
+
+func (m *mockFamilyService) DeleteChild(ctx context.Context, childID string) error {
+	m.deleted = append(m.deleted, childID)
+	return nil
+}
+
+type recordingTx struct {
+	calls int
+}
+
+func (r *recordingTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.calls++
+	return fn(ctx)
+}
+
+func newTestService(repo *mockRepository, families *mockFamilyService, opts ...Option) *service {
+	svc := NewService(repo, families, opts...).(*service)
+	svc.now = func() time.Time { return testNow }
+	return svc
+}
+
+func TestService_Create(t *testing.T) {
+	repo := newMockRepository()
+	tx := &recordingTx{}
+	svc := newTestService(repo, &mockFamilyService{}, WithTxManager(tx))
+
+	created, err := svc.Create(context.Background(), "admin", &CreateRequest{Name: "Test family"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.Family.ID != "family-new" || created.Sandbox.FamilyID != "family-new" || created.Sandbox.CreatedBy != "admin" {
+		t.Errorf("Create() = %+v", created)
+	}
+	if !created.Sandbox.NextResetAt.Equal(testNow.Add(DefaultResetInterval)) {
+		t.Errorf("NextResetAt = %v", created.Sandbox.NextResetAt)
+	}
+	if tx.calls != 1 || repo.sandboxes["family-new"] == nil {
+		t.Errorf("Expected the family and sandbox created in one transaction, got %d calls", tx.calls)
+	}
+}
+
+func TestService_Create_Error(t *testing.T) {
+	repo := newMockRepository()
+	repo.createErr = errors.New("database error")
+	svc := newTestService(repo, &mockFamilyService{})
+
+	if _, err := svc.Create(context.Background(), "admin", &CreateRequest{Name: "Test family"}); err == nil {
+		t.Error("expected error when the sandbox cannot be stored")
+	}
+}
+
+func TestService_Get(t *testing.T) {
+	svc := newTestService(newMockRepository(), &mockFamilyService{}, WithResetInterval(12*time.Hour))
+
+	sb, err := svc.Get(context.Background(), "member", "sandbox-2")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !sb.NextResetAt.Equal(testNow.Add(11 * time.Hour)) {
+		t.Errorf("NextResetAt = %v, want 11 hours from now", sb.NextResetAt)
+	}
+
+	if _, err := svc.Get(context.Background(), "member", "family-1"); !errors.Is(err, ErrNotSandbox) {
+		t.Errorf("ordinary family: error = %v, want ErrNotSandbox", err)
+	}
+	if _, err := svc.Get(context.Background(), "stranger", "sandbox-2"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("non-member: error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestService_Reset(t *testing.T) {
+	repo := newMockRepository()
+	repo.captures = []Capture{{ID: "c1", FamilyID: "sandbox-2"}, {ID: "c2", FamilyID: "sandbox-1"}}
+	families := &mockFamilyService{children: map[string][]family.Child{"sandbox-2": {{ID: "child-a"}, {ID: "child-b"}}}}
+	svc := newTestService(repo, families)
+
+	if _, err := svc.Reset(context.Background(), "member", "sandbox-2"); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("member: error = %v, want ErrNotAdmin", err)
+	}
+
+	sb, err := svc.Reset(context.Background(), "admin", "sandbox-2")
+	if err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if !sb.ResetAt.Equal(testNow) {
+		t.Errorf("ResetAt = %v, want now", sb.ResetAt)
+	}
+	if len(families.deleted) != 2 {
+		t.Errorf("deleted = %v, want both children", families.deleted)
+	}
+	if len(repo.captures) != 1 || repo.captures[0].FamilyID != "sandbox-1" {
+		t.Errorf("captures = %+v, want only the other sandbox's", repo.captures)
+	}
+
+	if _, err := svc.Reset(context.Background(), "admin", "family-1"); !errors.Is(err, ErrNotSandbox) {
+		t.Errorf("ordinary family: error = %v, want ErrNotSandbox", err)
+	}
+}
+
+func TestService_ResetDue(t *testing.T) {
+	repo := newMockRepository()
+	families := &mockFamilyService{children: map[string][]family.Child{
+		"sandbox-1": {{ID: "sandbox-child"}},
+		"sandbox-2": {{ID: "recent-child"}},
+	}}
+	svc := newTestService(repo, families)
+
+	reset, err := svc.ResetDue(context.Background())
+	if err != nil {
+		t.Fatalf("ResetDue() error = %v", err)
+	}
+	if reset != 1 || len(families.deleted) != 1 || families.deleted[0] != "sandbox-child" {
+		t.Errorf("ResetDue() = %d, deleted %v; want only the overdue sandbox", reset, families.deleted)
+	}
+}
+
+func TestService_CaptureEvent(t *testing.T) {
+	repo := newMockRepository()
+	svc := newTestService(repo, &mockFamilyService{})
+	event := notifications.Event{ID: "e1", Type: notifications.EventMedicationDue, ChildID: "sandbox-child"}
+
+	if !svc.CaptureEvent(context.Background(), "user-1", event) {
+		t.Fatal("expected the sandbox event to be captured")
+	}
+	if len(repo.captures) != 1 {
+		t.Fatalf("captures = %d, want 1", len(repo.captures))
+	}
+	c := repo.captures[0]
+	var payload notifications.Event
+	if err := json.Unmarshal(c.Payload, &payload); err != nil || payload.ID != "e1" {
+		t.Errorf("payload = %s (%v)", c.Payload, err)
+	}
+	if c.FamilyID != "sandbox-1" || c.UserID != "user-1" || c.Kind != CaptureNotification {
+		t.Errorf("capture = %+v", c)
+	}
+
+	// Real children go through, and lookups are cached
+	event.ChildID = "child-1"
+	for range 2 {
+		if svc.CaptureEvent(context.Background(), "user-1", event) {
+			t.Error("expected an ordinary child's event to be delivered")
+		}
+	}
+	if repo.lookups != 2 {
+		t.Errorf("lookups = %d, want one per child", repo.lookups)
+	}
+
+	event.ChildID = ""
+	if svc.CaptureEvent(context.Background(), "user-1", event) {
+		t.Error("expected an event without a child to be delivered")
+	}
+}
+
+type denyAll struct{}
+
+func (denyAll) Allow(userID string, event notifications.Event) bool { return false }
+
+func TestHubFilter(t *testing.T) {
+	repo := newMockRepository()
+	svc := newTestService(repo, &mockFamilyService{})
+	sandboxEvent := notifications.Event{Type: notifications.EventMedicationDue, ChildID: "sandbox-child"}
+
+	filter := HubFilter(svc, nil)
+	if filter.Allow("user-1", sandboxEvent) {
+		t.Error("expected the sandbox event to be held back")
+	}
+	if !filter.Allow("user-1", notifications.Event{ChildID: "child-1"}) {
+		t.Error("expected an ordinary event to be delivered")
+	}
+
+	// Events the user would not have received are not captured
+	before := len(repo.captures)
+	if HubFilter(svc, denyAll{}).Allow("user-1", sandboxEvent) || len(repo.captures) != before {
+		t.Errorf("captures = %d, want %d", len(repo.captures), before)
+	}
+}
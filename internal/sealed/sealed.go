@@ -0,0 +1,78 @@
+// Package sealed encrypts small values before they are stored, so a copy of
+// the database alone does not reveal them.
+//
+// Values are sealed with AES-256-GCM under the server's encryption key. Each
+// value is bound to a label, such as the row and column it is stored in, and
+// only opens under the same label, so sealed values cannot be swapped between
+// rows.
+package sealed
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of an encryption key in bytes
+const KeySize = 32
+
+var (
+	ErrInvalidKey = fmt.Errorf("encryption key must be %d bytes, base64 encoded", KeySize)
+	ErrTampered   = errors.New("sealed value is corrupt or was sealed under another key")
+)
+
+type Box struct {
+	aead cipher.AEAD
+}
+
+// ParseKey decodes a base64 key as written in the config file, e.g. the
+// output of `openssl rand -base64 32`
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+func NewBox(key []byte) (*Box, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext bound to label. The random nonce is prepended to
+// the result.
+func (b *Box) Seal(plaintext []byte, label string) []byte {
+	nonce := make([]byte, b.aead.NonceSize(), b.aead.NonceSize()+len(plaintext)+b.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("sealed: reading random nonce: %v", err))
+	}
+	return b.aead.Seal(nonce, nonce, plaintext, []byte(label))
+}
+
+// Open decrypts a value from Seal. It fails with ErrTampered when the value
+// was changed, sealed under another key or sealed with another label.
+func (b *Box) Open(sealed []byte, label string) ([]byte, error) {
+	n := b.aead.NonceSize()
+	if len(sealed) < n+b.aead.Overhead() {
+		return nil, ErrTampered
+	}
+	plaintext, err := b.aead.Open(nil, sealed[:n], sealed[n:], []byte(label))
+	if err != nil {
+		return nil, ErrTampered
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,80 @@
+package sealed
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func testBox(t *testing.T, fill byte) *Box {
+	t.Helper()
+	box, err := NewBox(bytes.Repeat([]byte{fill}, KeySize))
+	if err != nil {
+		t.Fatalf("NewBox() error = %v", err)
+	}
+	return box
+}
+
+func TestBox_RoundTrip(t *testing.T) {
+	box := testBox(t, 1)
+
+	sealed := box.Seal([]byte("policy 12345"), "family-1/insurance")
+	if bytes.Contains(sealed, []byte("12345")) {
+		t.Fatal("Seal() left the plaintext readable")
+	}
+
+	got, err := box.Open(sealed, "family-1/insurance")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if string(got) != "policy 12345" {
+		t.Errorf("Open() = %q", got)
+	}
+
+	if again := box.Seal([]byte("policy 12345"), "family-1/insurance"); bytes.Equal(again, sealed) {
+		t.Error("Seal() should use a fresh nonce each time")
+	}
+}
+
+func TestBox_Open_Rejects(t *testing.T) {
+	box := testBox(t, 1)
+	sealed := box.Seal([]byte("policy 12345"), "family-1/insurance")
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name   string
+		box    *Box
+		sealed []byte
+		label  string
+	}{
+		{"other label", box, sealed, "family-2/insurance"},
+		{"other key", testBox(t, 2), sealed, "family-1/insurance"},
+		{"tampered", box, tampered, "family-1/insurance"},
+		{"truncated", box, sealed[:10], "family-1/insurance"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.box.Open(tt.sealed, tt.label); !errors.Is(err, ErrTampered) {
+				t.Errorf("Open() error = %v, want ErrTampered", err)
+			}
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, KeySize)
+	got, err := ParseKey(base64.StdEncoding.EncodeToString(key))
+	if err != nil || !bytes.Equal(got, key) {
+		t.Errorf("ParseKey() = %v, %v", got, err)
+	}
+
+	for _, s := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := ParseKey(s); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ParseKey(%q) error = %v, want ErrInvalidKey", s, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package sleep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/ids"
+)
+
+// maxMergeGap is the longest time awake between two sessions that can still
+// be merged into one
+const maxMergeGap = time.Hour
+
+var (
+	ErrNotAdjacent  = errors.New("sleep sessions are not adjacent")
+	ErrInvalidSplit = errors.New("split time must fall inside the session")
+)
+
+// Merge joins two sessions of the same child into the earlier one, which
+// runs until the later one ends, with the time awake between them logged as
+// a waking. The sessions must follow each other with no
+// other session between them and at most maxMergeGap awake. Either order of
+// IDs is accepted.
+func (s *service) Merge(ctx context.Context, userID, firstID, secondID string) (*EditResult, error) {
+	if firstID == secondID {
+		return nil, fmt.Errorf("%w: cannot merge a session with itself", ErrNotAdjacent)
+	}
+	earlier, err := s.getForEdit(ctx, userID, firstID)
+	if err != nil {
+		return nil, err
+	}
+	later, err := s.getForEdit(ctx, userID, secondID)
+	if err != nil {
+		return nil, err
+	}
+	if later.StartTime.Before(earlier.StartTime) {
+		earlier, later = later, earlier
+	}
+
+	if earlier.ChildID != later.ChildID {
+		return nil, fmt.Errorf("%w: sessions belong to different children", ErrNotAdjacent)
+	}
+	if earlier.EndTime == nil {
+		return nil, fmt.Errorf("%w: the earlier session is still running", ErrNotAdjacent)
+	}
+	if later.StartTime.Sub(*earlier.EndTime) > maxMergeGap {
+		return nil, fmt.Errorf("%w: more than %s awake between them", ErrNotAdjacent, maxMergeGap)
+	}
+
+	between, err := s.repo.ListBetween(ctx, earlier.ChildID, earlier.StartTime, later.StartTime.Add(time.Nanosecond))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sleep: %w", err)
+	}
+	for _, other := range between {
+		if other.ID != earlier.ID && other.ID != later.ID && other.StartTime.After(earlier.StartTime) && !other.StartTime.After(later.StartTime) {
+			return nil, fmt.Errorf("%w: another session starts between them", ErrNotAdjacent)
+		}
+	}
+
+	merged := *earlier
+	// A later session nested inside the earlier one leaves its end alone
+	if later.EndTime == nil || later.EndTime.After(*earlier.EndTime) {
+		merged.EndTime = later.EndTime
+		merged.EndedBy = later.EndedBy
+	}
+	if later.Type == SleepTypeNight {
+		merged.Type = SleepTypeNight
+	}
+	if merged.Quality == nil {
+		merged.Quality = later.Quality
+	}
+	merged.Notes = joinNotes(earlier.Notes, later.Notes)
+	merged.UpdatedAt = time.Now()
+
+	// The later session's wakings come along, and the time awake between
+	// the two becomes a waking of its own
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, &merged); err != nil {
+			return fmt.Errorf("failed to update sleep: %w", err)
+		}
+		if err := s.repo.MoveWakings(ctx, later.ID, merged.ID, time.Time{}); err != nil {
+			return fmt.Errorf("failed to move wakings: %w", err)
+		}
+		if later.StartTime.After(*earlier.EndTime) {
+			gap := &Waking{
+				ID:        ids.New(),
+				SleepID:   merged.ID,
+				StartTime: *earlier.EndTime,
+				EndTime:   &later.StartTime,
+				CreatedBy: userID,
+				CreatedAt: merged.UpdatedAt,
+			}
+			if err := s.repo.CreateWaking(ctx, gap); err != nil {
+				return fmt.Errorf("failed to create waking: %w", err)
+			}
+		}
+		if err := s.deleteRecord(ctx, later); err != nil {
+			return fmt.Errorf("failed to delete sleep: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	kept, err := s.withWakings(ctx, &merged)
+	if err != nil {
+		return nil, err
+	}
+	s.recordVersion(ctx, kept, audit.ActionUpdate)
+	s.recordVersion(ctx, later, audit.ActionDelete)
+
+	return &EditResult{Sessions: []Session{sessionOf(*kept)}, Removed: []string{later.ID}}, nil
+}
+
+// Split cuts a session in two at the given time. The first part keeps the
+// record's ID and notes; the second is a new record running to the original
+// end, so splitting a running session leaves the second part running.
+func (s *service) Split(ctx context.Context, userID, id string, at time.Time) (*EditResult, error) {
+	original, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	if original.EndTime != nil {
+		end = *original.EndTime
+	}
+	if !at.After(original.StartTime) || !at.Before(end) {
+		return nil, ErrInvalidSplit
+	}
+
+	now := time.Now()
+	first := *original
+	first.EndTime = &at
+	first.EndedBy = ""
+	first.UpdatedAt = now
+
+	second := Sleep{
+		ID:        ids.New(),
+		ChildID:   original.ChildID,
+		Type:      original.Type,
+		StartTime: at,
+		EndTime:   original.EndTime,
+		Quality:   original.Quality,
+		Source:    original.Source,
+		StartedBy: original.StartedBy,
+		EndedBy:   original.EndedBy,
+		Private:   original.Private,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, &first); err != nil {
+			return fmt.Errorf("failed to update sleep: %w", err)
+		}
+		if err := s.repo.Create(ctx, &second); err != nil {
+			return fmt.Errorf("failed to create sleep: %w", err)
+		}
+		// Wakings follow the part they started in
+		if err := s.repo.MoveWakings(ctx, first.ID, second.ID, at); err != nil {
+			return fmt.Errorf("failed to move wakings: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parts := []Sleep{first, second}
+	if err := s.attachWakings(ctx, parts); err != nil {
+		return nil, err
+	}
+	s.recordVersion(ctx, &parts[0], audit.ActionUpdate)
+	s.recordVersion(ctx, &parts[1], audit.ActionCreate)
+
+	return &EditResult{Sessions: []Session{sessionOf(parts[0]), sessionOf(parts[1])}, Removed: []string{}}, nil
+}
+
+func (s *service) getForEdit(ctx context.Context, userID, id string) (*Sleep, error) {
+	sl, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sl == nil || hiddenFrom(sl, userID) {
+		return nil, ErrNotFound
+	}
+	if err := s.authorize(ctx, userID, sl.ChildID); err != nil {
+		return nil, err
+	}
+	return sl, nil
+}
+
+func sessionOf(sl Sleep) Session {
+	session := Session{Sleep: sl}
+	if d, ok := sl.Duration(); ok {
+		session.Duration = &d
+	}
+	return session
+}
+
+func joinNotes(a, b string) string {
+	var parts []string
+	for _, n := range []string{a, b} {
+		if n = strings.TrimSpace(n); n != "" {
+			parts = append(parts, n)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -1,11 +1,20 @@
 package sleep
 
 import (
+	"errors"
 	"net/http"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
 
 	"github.com/gin-gonic/gin"
 )
 
+// defaultReconcileWindow is how far back conflicts are looked for when no range is given
+const defaultReconcileWindow = 7 * 24 * time.Hour
+
 type Handler struct {
 	service Service
 }
@@ -20,18 +29,27 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.GET("/:id", h.get)
 	rg.PUT("/:id", h.update)
 	rg.DELETE("/:id", h.delete)
+	rg.POST("/:id/private", h.setPrivate)
 	rg.POST("/start", h.startSleep)
 	rg.POST("/:id/end", h.endSleep)
 	rg.GET("/active/:childId", h.getActive)
+	rg.GET("/conflicts", h.listConflicts)
+	rg.POST("/reconcile", h.reconcile)
+	rg.POST("/merge", h.merge)
+	rg.POST("/:id/split", h.split)
+	rg.POST("/:id/wakings", h.addWaking)
+	rg.POST("/:id/wakings/:wakingId/end", h.endWaking)
+	rg.DELETE("/:id/wakings/:wakingId", h.removeWaking)
 }
 
 func (h *Handler) list(c *gin.Context) {
 	filter := &SleepFilter{
-		ChildID: c.Query("child_id"),
+		ChildID:  c.Query("child_id"),
+		ViewerID: c.GetString("user_id"),
 	}
 	sleeps, err := h.service.List(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, sleeps)
@@ -43,10 +61,15 @@ func (h *Handler) create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.StartedBy = c.GetString("user_id")
 
 	sleep, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, occurrence.ErrInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, sleep)
@@ -54,9 +77,9 @@ func (h *Handler) create(c *gin.Context) {
 
 func (h *Handler) get(c *gin.Context) {
 	id := c.Param("id")
-	sleep, err := h.service.Get(c.Request.Context(), id)
+	sleep, err := h.service.Get(c.Request.Context(), c.GetString("user_id"), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, sleep)
@@ -70,9 +93,13 @@ func (h *Handler) update(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	sleep, err := h.service.Update(c.Request.Context(), id, &req)
+	sleep, err := h.service.Update(c.Request.Context(), c.GetString("user_id"), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, occurrence.ErrInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, sleep)
@@ -80,8 +107,8 @@ func (h *Handler) update(c *gin.Context) {
 
 func (h *Handler) delete(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), id); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -97,9 +124,9 @@ func (h *Handler) startSleep(c *gin.Context) {
 		return
 	}
 
-	sleep, err := h.service.StartSleep(c.Request.Context(), req.ChildID, req.Type)
+	sleep, err := h.service.StartSleep(c.Request.Context(), c.GetString("user_id"), req.ChildID, req.Type)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, sleep)
@@ -107,9 +134,9 @@ func (h *Handler) startSleep(c *gin.Context) {
 
 func (h *Handler) endSleep(c *gin.Context) {
 	id := c.Param("id")
-	sleep, err := h.service.EndSleep(c.Request.Context(), id)
+	sleep, err := h.service.EndSleep(c.Request.Context(), c.GetString("user_id"), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, sleep)
@@ -117,7 +144,7 @@ func (h *Handler) endSleep(c *gin.Context) {
 
 func (h *Handler) getActive(c *gin.Context) {
 	childID := c.Param("childId")
-	sleep, err := h.service.GetActiveSleep(c.Request.Context(), childID)
+	sleep, err := h.service.GetActiveSleep(c.Request.Context(), childID, c.GetString("user_id"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -128,3 +155,166 @@ func (h *Handler) getActive(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, sleep)
 }
+
+func (h *Handler) listConflicts(c *gin.Context) {
+	childID := c.Query("child_id")
+	if childID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "child_id is required"})
+		return
+	}
+
+	var from, to *time.Time
+	for name, dst := range map[string]**time.Time{"from": &from, "to": &to} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be an RFC3339 timestamp"})
+			return
+		}
+		*dst = &t
+	}
+
+	start, end, ok := reconcileWindow(from, to)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	conflicts, err := h.service.ListConflicts(c.Request.Context(), childID, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, conflicts)
+}
+
+func (h *Handler) reconcile(c *gin.Context) {
+	var req ReconcileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	start, end, ok := reconcileWindow(req.From, req.To)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	result, err := h.service.Reconcile(c.Request.Context(), req.ChildID, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) merge(c *gin.Context) {
+	var req MergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.Merge(c.Request.Context(), c.GetString("user_id"), req.IDs[0], req.IDs[1])
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) setPrivate(c *gin.Context) {
+	var req SetPrivateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.SetPrivate(c.Request.Context(), c.GetString("user_id"), c.Param("id"), req.Private); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) split(c *gin.Context) {
+	var req SplitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.Split(c.Request.Context(), c.GetString("user_id"), c.Param("id"), req.At)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) addWaking(c *gin.Context) {
+	var req WakingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sleep, err := h.service.AddWaking(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, sleep)
+}
+
+func (h *Handler) endWaking(c *gin.Context) {
+	sleep, err := h.service.EndWaking(c.Request.Context(), c.GetString("user_id"), c.Param("id"), c.Param("wakingId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sleep)
+}
+
+func (h *Handler) removeWaking(c *gin.Context) {
+	sleep, err := h.service.RemoveWaking(c.Request.Context(), c.GetString("user_id"), c.Param("id"), c.Param("wakingId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sleep)
+}
+
+// reconcileWindow fills in a missing range, ending now and spanning the default window
+func reconcileWindow(from, to *time.Time) (start, end time.Time, ok bool) {
+	end = time.Now()
+	if to != nil {
+		end = *to
+	}
+	start = end.Add(-defaultReconcileWindow)
+	if from != nil {
+		start = *from
+	}
+	return start, end, start.Before(end)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrChildNotFound), errors.Is(err, ErrWakingNotFound),
+		errors.Is(err, visibility.ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember), errors.Is(err, family.ErrNotMember), errors.Is(err, visibility.ErrHidden),
+		errors.Is(err, ErrNotAuthor):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotAdjacent), errors.Is(err, ErrInvalidSplit), errors.Is(err, ErrInvalidWaking):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSessionEnded):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"iter"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/visibility"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -24,9 +28,17 @@ type mockService struct {
 	listFn           func(ctx context.Context, filter *SleepFilter) ([]Sleep, error)
 	updateFn         func(ctx context.Context, id string, req *CreateSleepRequest) (*Sleep, error)
 	deleteFn         func(ctx context.Context, id string) error
-	startSleepFn     func(ctx context.Context, childID string, sleepType SleepType) (*Sleep, error)
-	endSleepFn       func(ctx context.Context, id string) (*Sleep, error)
-	getActiveSleepFn func(ctx context.Context, childID string) (*Sleep, error)
+	startSleepFn     func(ctx context.Context, userID, childID string, sleepType SleepType) (*Sleep, error)
+	endSleepFn       func(ctx context.Context, userID, id string) (*Sleep, error)
+	getActiveSleepFn func(ctx context.Context, childID, viewerID string) (*Sleep, error)
+	listConflictsFn  func(ctx context.Context, childID string, from, to time.Time) ([]Conflict, error)
+	reconcileFn      func(ctx context.Context, childID string, from, to time.Time) (*ReconcileResult, error)
+	mergeFn          func(ctx context.Context, userID, firstID, secondID string) (*EditResult, error)
+	splitFn          func(ctx context.Context, userID, id string, at time.Time) (*EditResult, error)
+	addWakingFn      func(ctx context.Context, userID, sleepID string, req *WakingRequest) (*Sleep, error)
+	endWakingFn      func(ctx context.Context, userID, sleepID, wakingID string) (*Sleep, error)
+	removeWakingFn   func(ctx context.Context, userID, sleepID, wakingID string) (*Sleep, error)
+	setPrivateFn     func(ctx context.Context, userID, id string, private bool) error
 }
 
 func (m *mockService) Create(ctx context.Context, req *CreateSleepRequest) (*Sleep, error) {
@@ -36,7 +48,7 @@ func (m *mockService) Create(ctx context.Context, req *CreateSleepRequest) (*Sle
 	return nil, nil
 }
 
-func (m *mockService) Get(ctx context.Context, id string) (*Sleep, error) {
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Sleep, error) {
 	if m.getFn != nil {
 		return m.getFn(ctx, id)
 	}
@@ -50,44 +62,90 @@ func (m *mockService) List(ctx context.Context, filter *SleepFilter) ([]Sleep, e
 	return nil, nil
 }
 
-func (m *mockService) Update(ctx context.Context, id string, req *CreateSleepRequest) (*Sleep, error) {
+func (m *mockService) Stream(ctx context.Context, filter *SleepFilter) iter.Seq2[Sleep, error] {
+	return nil
+}
+
+func (m *mockService) Update(ctx context.Context, userID, id string, req *CreateSleepRequest) (*Sleep, error) {
 	if m.updateFn != nil {
 		return m.updateFn(ctx, id, req)
 	}
 	return nil, nil
 }
 
-func (m *mockService) Delete(ctx context.Context, id string) error {
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
 	if m.deleteFn != nil {
 		return m.deleteFn(ctx, id)
 	}
 	return nil
 }
 
-func (m *mockService) StartSleep(ctx context.Context, childID string, sleepType SleepType) (*Sleep, error) {
+func (m *mockService) StartSleep(ctx context.Context, userID, childID string, sleepType SleepType) (*Sleep, error) {
 	if m.startSleepFn != nil {
-		return m.startSleepFn(ctx, childID, sleepType)
+		return m.startSleepFn(ctx, userID, childID, sleepType)
 	}
 	return nil, nil
 }
 
-func (m *mockService) EndSleep(ctx context.Context, id string) (*Sleep, error) {
+func (m *mockService) EndSleep(ctx context.Context, userID, id string) (*Sleep, error) {
 	if m.endSleepFn != nil {
-		return m.endSleepFn(ctx, id)
+		return m.endSleepFn(ctx, userID, id)
 	}
 	return nil, nil
 }
 
-func (m *mockService) GetActiveSleep(ctx context.Context, childID string) (*Sleep, error) {
+func (m *mockService) GetActiveSleep(ctx context.Context, childID, viewerID string) (*Sleep, error) {
 	if m.getActiveSleepFn != nil {
-		return m.getActiveSleepFn(ctx, childID)
+		return m.getActiveSleepFn(ctx, childID, viewerID)
 	}
 	return nil, nil
 }
 
+func (m *mockService) ListConflicts(ctx context.Context, childID string, from, to time.Time) ([]Conflict, error) {
+	if m.listConflictsFn != nil {
+		return m.listConflictsFn(ctx, childID, from, to)
+	}
+	return []Conflict{}, nil
+}
+
+func (m *mockService) Reconcile(ctx context.Context, childID string, from, to time.Time) (*ReconcileResult, error) {
+	if m.reconcileFn != nil {
+		return m.reconcileFn(ctx, childID, from, to)
+	}
+	return &ReconcileResult{}, nil
+}
+
+func (m *mockService) Merge(ctx context.Context, userID, firstID, secondID string) (*EditResult, error) {
+	return m.mergeFn(ctx, userID, firstID, secondID)
+}
+
+func (m *mockService) Split(ctx context.Context, userID, id string, at time.Time) (*EditResult, error) {
+	return m.splitFn(ctx, userID, id, at)
+}
+
+func (m *mockService) AddWaking(ctx context.Context, userID, sleepID string, req *WakingRequest) (*Sleep, error) {
+	return m.addWakingFn(ctx, userID, sleepID, req)
+}
+
+func (m *mockService) EndWaking(ctx context.Context, userID, sleepID, wakingID string) (*Sleep, error) {
+	return m.endWakingFn(ctx, userID, sleepID, wakingID)
+}
+
+func (m *mockService) RemoveWaking(ctx context.Context, userID, sleepID, wakingID string) (*Sleep, error) {
+	return m.removeWakingFn(ctx, userID, sleepID, wakingID)
+}
+
+func (m *mockService) SetPrivate(ctx context.Context, userID, id string, private bool) error {
+	if m.setPrivateFn != nil {
+		return m.setPrivateFn(ctx, userID, id, private)
+	}
+	return nil
+}
+
 // setupRouter creates a test router with the handler registered
 func setupRouter(svc Service) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 	handler := NewHandler(svc)
 
 	// Add middleware to set user_id in context (simulating auth)
@@ -310,6 +368,23 @@ func TestGet_ServiceError(t *testing.T) {
 	}
 }
 
+func TestGet_Hidden(t *testing.T) {
+	svc := &mockService{
+		getFn: func(ctx context.Context, id string) (*Sleep, error) {
+			return nil, visibility.ErrHidden
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/sleep/sleep-123", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
 func TestGet_VerifiesIDParam(t *testing.T) {
 	var capturedID string
 	svc := &mockService{
@@ -454,6 +529,9 @@ func TestCreate_VerifiesRequestData(t *testing.T) {
 	if capturedReq.Type != reqBody.Type {
 		t.Errorf("Expected Type %s, got %s", reqBody.Type, capturedReq.Type)
 	}
+	if capturedReq.StartedBy != "test-user-123" {
+		t.Errorf("Expected StartedBy test-user-123, got %s", capturedReq.StartedBy)
+	}
 }
 
 // =====================
@@ -649,7 +727,7 @@ func TestDelete_VerifiesIDParam(t *testing.T) {
 func TestStartSleep_Success(t *testing.T) {
 	slp := sampleActiveSleep()
 	svc := &mockService{
-		startSleepFn: func(ctx context.Context, childID string, sleepType SleepType) (*Sleep, error) {
+		startSleepFn: func(ctx context.Context, userID, childID string, sleepType SleepType) (*Sleep, error) {
 			return slp, nil
 		},
 	}
@@ -727,7 +805,7 @@ func TestStartSleep_MissingType(t *testing.T) {
 
 func TestStartSleep_ServiceError(t *testing.T) {
 	svc := &mockService{
-		startSleepFn: func(ctx context.Context, childID string, sleepType SleepType) (*Sleep, error) {
+		startSleepFn: func(ctx context.Context, userID, childID string, sleepType SleepType) (*Sleep, error) {
 			return nil, errors.New("failed to start sleep")
 		},
 	}
@@ -759,7 +837,7 @@ func TestStartSleep_VerifiesRequestData(t *testing.T) {
 	var capturedChildID string
 	var capturedType SleepType
 	svc := &mockService{
-		startSleepFn: func(ctx context.Context, childID string, sleepType SleepType) (*Sleep, error) {
+		startSleepFn: func(ctx context.Context, userID, childID string, sleepType SleepType) (*Sleep, error) {
 			capturedChildID = childID
 			capturedType = sleepType
 			return sampleActiveSleep(), nil
@@ -791,7 +869,7 @@ func TestStartSleep_VerifiesRequestData(t *testing.T) {
 func TestEndSleep_Success(t *testing.T) {
 	slp := sampleSleep() // Has EndTime set
 	svc := &mockService{
-		endSleepFn: func(ctx context.Context, id string) (*Sleep, error) {
+		endSleepFn: func(ctx context.Context, userID, id string) (*Sleep, error) {
 			return slp, nil
 		},
 	}
@@ -816,7 +894,7 @@ func TestEndSleep_Success(t *testing.T) {
 
 func TestEndSleep_ServiceError(t *testing.T) {
 	svc := &mockService{
-		endSleepFn: func(ctx context.Context, id string) (*Sleep, error) {
+		endSleepFn: func(ctx context.Context, userID, id string) (*Sleep, error) {
 			return nil, errors.New("sleep record not found")
 		},
 	}
@@ -842,7 +920,7 @@ func TestEndSleep_ServiceError(t *testing.T) {
 func TestEndSleep_VerifiesIDParam(t *testing.T) {
 	var capturedID string
 	svc := &mockService{
-		endSleepFn: func(ctx context.Context, id string) (*Sleep, error) {
+		endSleepFn: func(ctx context.Context, userID, id string) (*Sleep, error) {
 			capturedID = id
 			return sampleSleep(), nil
 		},
@@ -858,14 +936,65 @@ func TestEndSleep_VerifiesIDParam(t *testing.T) {
 	}
 }
 
+func TestEndSleep_PassesUserID(t *testing.T) {
+	var capturedUserID string
+	svc := &mockService{
+		endSleepFn: func(ctx context.Context, userID, id string) (*Sleep, error) {
+			capturedUserID = userID
+			return sampleSleep(), nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/sleep/sleep-123/end", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if capturedUserID != "test-user-123" {
+		t.Errorf("Expected user test-user-123, got %s", capturedUserID)
+	}
+}
+
+func TestEndSleep_ErrorStatuses(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrNotFound, http.StatusNotFound},
+		{ErrChildNotFound, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			svc := &mockService{
+				endSleepFn: func(ctx context.Context, userID, id string) (*Sleep, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/sleep/sleep-123/end", http.NoBody)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
 // =====================
 // GetActive Handler Tests
 // =====================
 
 func TestGetActive_Success(t *testing.T) {
 	slp := sampleActiveSleep()
+	var gotViewer string
 	svc := &mockService{
-		getActiveSleepFn: func(ctx context.Context, childID string) (*Sleep, error) {
+		getActiveSleepFn: func(ctx context.Context, childID, viewerID string) (*Sleep, error) {
+			gotViewer = viewerID
 			return slp, nil
 		},
 	}
@@ -878,6 +1007,9 @@ func TestGetActive_Success(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
+	if gotViewer != "test-user-123" {
+		t.Errorf("Expected the caller as viewer, got %q", gotViewer)
+	}
 
 	var result Sleep
 	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
@@ -890,7 +1022,7 @@ func TestGetActive_Success(t *testing.T) {
 
 func TestGetActive_NoActiveSleep(t *testing.T) {
 	svc := &mockService{
-		getActiveSleepFn: func(ctx context.Context, childID string) (*Sleep, error) {
+		getActiveSleepFn: func(ctx context.Context, childID, viewerID string) (*Sleep, error) {
 			return nil, nil
 		},
 	}
@@ -912,7 +1044,7 @@ func TestGetActive_NoActiveSleep(t *testing.T) {
 
 func TestGetActive_ServiceError(t *testing.T) {
 	svc := &mockService{
-		getActiveSleepFn: func(ctx context.Context, childID string) (*Sleep, error) {
+		getActiveSleepFn: func(ctx context.Context, childID, viewerID string) (*Sleep, error) {
 			return nil, errors.New("database error")
 		},
 	}
@@ -938,7 +1070,7 @@ func TestGetActive_ServiceError(t *testing.T) {
 func TestGetActive_VerifiesChildIDParam(t *testing.T) {
 	var capturedChildID string
 	svc := &mockService{
-		getActiveSleepFn: func(ctx context.Context, childID string) (*Sleep, error) {
+		getActiveSleepFn: func(ctx context.Context, childID, viewerID string) (*Sleep, error) {
 			capturedChildID = childID
 			return sampleActiveSleep(), nil
 		},
@@ -976,13 +1108,13 @@ func TestRegisterRoutes(t *testing.T) {
 		deleteFn: func(ctx context.Context, id string) error {
 			return nil
 		},
-		startSleepFn: func(ctx context.Context, childID string, sleepType SleepType) (*Sleep, error) {
+		startSleepFn: func(ctx context.Context, userID, childID string, sleepType SleepType) (*Sleep, error) {
 			return sampleActiveSleep(), nil
 		},
-		endSleepFn: func(ctx context.Context, id string) (*Sleep, error) {
+		endSleepFn: func(ctx context.Context, userID, id string) (*Sleep, error) {
 			return sampleSleep(), nil
 		},
-		getActiveSleepFn: func(ctx context.Context, childID string) (*Sleep, error) {
+		getActiveSleepFn: func(ctx context.Context, childID, viewerID string) (*Sleep, error) {
 			return sampleActiveSleep(), nil
 		},
 	}
@@ -1046,7 +1178,7 @@ func TestNewHandler(t *testing.T) {
 func TestStartSleep_NapType(t *testing.T) {
 	var capturedType SleepType
 	svc := &mockService{
-		startSleepFn: func(ctx context.Context, childID string, sleepType SleepType) (*Sleep, error) {
+		startSleepFn: func(ctx context.Context, userID, childID string, sleepType SleepType) (*Sleep, error) {
 			capturedType = sleepType
 			return sampleActiveSleep(), nil
 		},
@@ -1070,7 +1202,7 @@ func TestStartSleep_NapType(t *testing.T) {
 func TestStartSleep_NightType(t *testing.T) {
 	var capturedType SleepType
 	svc := &mockService{
-		startSleepFn: func(ctx context.Context, childID string, sleepType SleepType) (*Sleep, error) {
+		startSleepFn: func(ctx context.Context, userID, childID string, sleepType SleepType) (*Sleep, error) {
 			capturedType = sleepType
 			return sampleActiveSleep(), nil
 		},
@@ -1190,3 +1322,261 @@ func TestCreate_WithoutOptionalFields(t *testing.T) {
 		t.Error("Expected Quality to be nil")
 	}
 }
+
+func TestHandler_ListConflicts(t *testing.T) {
+	var gotFrom, gotTo time.Time
+	svc := &mockService{
+		listConflictsFn: func(ctx context.Context, childID string, from, to time.Time) ([]Conflict, error) {
+			gotFrom, gotTo = from, to
+			return []Conflict{{ChildID: childID, KeepID: "sleep-1"}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/sleep/conflicts?child_id=child-1&to=2024-06-08T00:00:00Z", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	wantTo := time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)
+	if !gotTo.Equal(wantTo) || !gotFrom.Equal(wantTo.Add(-defaultReconcileWindow)) {
+		t.Errorf("Unexpected window %v - %v", gotFrom, gotTo)
+	}
+}
+
+func TestHandler_ListConflicts_BadRange(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/sleep/conflicts?child_id=child-1&from=2024-06-08T00:00:00Z&to=2024-06-01T00:00:00Z", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandler_Reconcile(t *testing.T) {
+	svc := &mockService{
+		reconcileFn: func(ctx context.Context, childID string, from, to time.Time) (*ReconcileResult, error) {
+			return &ReconcileResult{Kept: []Sleep{{ID: "sleep-1"}}, Removed: []string{"sleep-2"}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/sleep/reconcile", bytes.NewBufferString(`{"child_id":"child-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var result ReconcileResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if len(result.Removed) != 1 || result.Removed[0] != "sleep-2" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestHandler_Merge(t *testing.T) {
+	svc := &mockService{
+		mergeFn: func(ctx context.Context, userID, firstID, secondID string) (*EditResult, error) {
+			if userID != "test-user-123" || firstID != "sleep-1" || secondID != "sleep-2" {
+				t.Errorf("Merge() called with %s, %s, %s", userID, firstID, secondID)
+			}
+			return &EditResult{Sessions: []Session{{Sleep: Sleep{ID: "sleep-1"}}}, Removed: []string{"sleep-2"}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/sleep/merge", bytes.NewBufferString(`{"ids":["sleep-1","sleep-2"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestHandler_Merge_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+		want int
+	}{
+		{"one id", `{"ids":["sleep-1"]}`, nil, http.StatusBadRequest},
+		{"not adjacent", `{"ids":["sleep-1","sleep-2"]}`, ErrNotAdjacent, http.StatusBadRequest},
+		{"not found", `{"ids":["sleep-1","sleep-2"]}`, ErrNotFound, http.StatusNotFound},
+		{"not member", `{"ids":["sleep-1","sleep-2"]}`, ErrNotMember, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				mergeFn: func(ctx context.Context, userID, firstID, secondID string) (*EditResult, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/sleep/merge", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_Split(t *testing.T) {
+	var gotAt time.Time
+	svc := &mockService{
+		splitFn: func(ctx context.Context, userID, id string, at time.Time) (*EditResult, error) {
+			gotAt = at
+			if id == "sleep-1" && at.Hour() == 23 {
+				return &EditResult{Sessions: []Session{{Sleep: Sleep{ID: "sleep-1"}}, {Sleep: Sleep{ID: "sleep-3"}}}, Removed: []string{}}, nil
+			}
+			return nil, ErrInvalidSplit
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/sleep/sleep-1/split", bytes.NewBufferString(`{"at":"2024-06-01T23:30:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (at %v)", w.Code, gotAt)
+	}
+	var result EditResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if len(result.Sessions) != 2 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	req = httptest.NewRequest("POST", "/sleep/sleep-1/split", bytes.NewBufferString(`{"at":"2024-06-02T09:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a time outside the session, got %d", w.Code)
+	}
+}
+
+func TestHandler_AddWaking(t *testing.T) {
+	svc := &mockService{
+		addWakingFn: func(ctx context.Context, userID, sleepID string, req *WakingRequest) (*Sleep, error) {
+			if sleepID != "sleep-1" || req.Reason != WakingTeething {
+				t.Errorf("AddWaking() called with %s, %+v", sleepID, req)
+			}
+			return &Sleep{ID: sleepID, Wakings: []Waking{{ID: "waking-1", Reason: req.Reason}}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/sleep/sleep-1/wakings", bytes.NewBufferString(`{"reason":"teething"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+}
+
+func TestHandler_AddWaking_InvalidReason(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("POST", "/sleep/sleep-1/wakings", bytes.NewBufferString(`{"reason":"bored"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandler_Wakings_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"session ended", ErrSessionEnded, http.StatusConflict},
+		{"waking not found", ErrWakingNotFound, http.StatusNotFound},
+		{"not member", ErrNotMember, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				endWakingFn: func(ctx context.Context, userID, sleepID, wakingID string) (*Sleep, error) {
+					return nil, tt.err
+				},
+				removeWakingFn: func(ctx context.Context, userID, sleepID, wakingID string) (*Sleep, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			for _, r := range []*http.Request{
+				httptest.NewRequest("POST", "/sleep/sleep-1/wakings/waking-1/end", http.NoBody),
+				httptest.NewRequest("DELETE", "/sleep/sleep-1/wakings/waking-1", http.NoBody),
+			} {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, r)
+				if w.Code != tt.want {
+					t.Errorf("%s %s: expected status %d, got %d", r.Method, r.URL.Path, tt.want, w.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestSetPrivate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, http.StatusOK},
+		{"not starter", ErrNotAuthor, http.StatusForbidden},
+		{"not found", ErrNotFound, http.StatusNotFound},
+		{"other", errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				setPrivateFn: func(ctx context.Context, userID, id string, private bool) error {
+					if userID != "test-user-123" || id != "sleep-123" || !private {
+						t.Errorf("SetPrivate(%s, %s, %v)", userID, id, private)
+					}
+					return tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/sleep/sleep-123/private", bytes.NewBufferString(`{"private":true}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package sleep
+
+import (
+	"context"
+	"log"
+
+	"github.com/ninenine/babytrack/internal/audit"
+)
+
+// WithHistory keeps a version of every sleep record written, for as-of exports
+func WithHistory(store audit.Store) Option {
+	return func(s *service) {
+		s.history = store
+	}
+}
+
+// recordVersion is best effort: the write has already happened, so a
+// failure to keep its history is only logged.
+func (s *service) recordVersion(ctx context.Context, sl *Sleep, action audit.Action) {
+	if s.history == nil {
+		return
+	}
+	if err := s.history.Record(ctx, audit.EntitySleep, sl.ID, sl.ChildID, action, sl); err != nil {
+		log.Printf("[sleep] failed to record %s of %s: %v", action, sl.ID, err)
+	}
+}
@@ -1,6 +1,10 @@
 package sleep
 
-import "time"
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/measure"
+)
 
 type SleepType string
 
@@ -9,6 +13,9 @@ const (
 	SleepTypeNight SleepType = "night"
 )
 
+// SourceManual marks records logged by hand; device pushes use "device:<id>"
+const SourceManual = "manual"
+
 type Sleep struct {
 	ID        string     `json:"id"`
 	ChildID   string     `json:"child_id"`
@@ -17,9 +24,68 @@ type Sleep struct {
 	EndTime   *time.Time `json:"end_time,omitempty"`
 	Quality   *int       `json:"quality,omitempty"` // 1-5 rating
 	Notes     string     `json:"notes,omitempty"`
+	Source    string     `json:"source"`
+	StartedBy string     `json:"started_by,omitempty"`
+	EndedBy   string     `json:"ended_by,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	SyncedAt  *time.Time `json:"synced_at,omitempty"`
+	Wakings   []Waking   `json:"wakings,omitempty"`
+	// Private sleep is only shown to the member who started it, including
+	// in exports
+	Private bool `json:"private"`
+}
+
+// VisibleTo reports whether userID may see the sleep
+func (s *Sleep) VisibleTo(userID string) bool {
+	return !s.Private || s.StartedBy == userID
+}
+
+// Duration returns how long the child slept, and false while they are still
+// asleep
+func (s *Sleep) Duration() (measure.Duration, bool) {
+	return measure.Between(s.StartTime, s.EndTime)
+}
+
+// AsleepDuration is Duration less the time spent awake in ended wakings
+func (s *Sleep) AsleepDuration() (measure.Duration, bool) {
+	d, ok := s.Duration()
+	if !ok {
+		return 0, false
+	}
+	for _, w := range s.Wakings {
+		if awake, ok := w.Duration(); ok {
+			d -= awake
+		}
+	}
+	return max(d, 0), true
+}
+
+type WakingReason string
+
+const (
+	WakingHungry   WakingReason = "hungry"
+	WakingDiaper   WakingReason = "diaper"
+	WakingComfort  WakingReason = "comfort"
+	WakingTeething WakingReason = "teething"
+	WakingUnwell   WakingReason = "unwell"
+	WakingOther    WakingReason = "other"
+)
+
+// Waking is a time the child woke during a sleep session and went back down
+type Waking struct {
+	ID        string       `json:"id"`
+	SleepID   string       `json:"sleep_id"`
+	StartTime time.Time    `json:"start_time"`
+	EndTime   *time.Time   `json:"end_time,omitempty"` // unset while the child is still awake
+	Reason    WakingReason `json:"reason,omitempty"`
+	CreatedBy string       `json:"created_by,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// Duration returns how long the child was awake, and false while they still are
+func (w *Waking) Duration() (measure.Duration, bool) {
+	return measure.Between(w.StartTime, w.EndTime)
 }
 
 type CreateSleepRequest struct {
@@ -29,6 +95,16 @@ type CreateSleepRequest struct {
 	EndTime   *time.Time `json:"end_time,omitempty"`
 	Quality   *int       `json:"quality,omitempty"`
 	Notes     string     `json:"notes,omitempty"`
+	Source    string     `json:"source,omitempty"`
+	// Private is only read on create; SetPrivate changes it afterwards
+	Private bool `json:"private"`
+	// StartedBy is set by the server to the member logging the sleep. Sleep
+	// without one cannot be private.
+	StartedBy string `json:"-"`
+}
+
+type SetPrivateRequest struct {
+	Private bool `json:"private"`
 }
 
 type SleepFilter struct {
@@ -36,6 +112,9 @@ type SleepFilter struct {
 	StartDate *time.Time
 	EndDate   *time.Time
 	Type      *SleepType
+	// ViewerID leaves out sleep hidden from that member, and other members'
+	// private sleep, when set
+	ViewerID string
 }
 
 type SleepStats struct {
@@ -44,3 +123,51 @@ type SleepStats struct {
 	AverageNight time.Duration `json:"average_night"`
 	NapCount     int           `json:"nap_count"`
 }
+
+// Conflict is a group of overlapping sleep records for one child, typically a
+// monitor's epochs and a session a parent logged by hand.
+type Conflict struct {
+	ChildID string  `json:"child_id"`
+	Records []Sleep `json:"records"`
+	KeepID  string  `json:"keep_id"` // record kept if the conflict is merged
+}
+
+type ReconcileRequest struct {
+	ChildID string     `json:"child_id" binding:"required"`
+	From    *time.Time `json:"from,omitempty"`
+	To      *time.Time `json:"to,omitempty"`
+}
+
+type ReconcileResult struct {
+	Kept    []Sleep  `json:"kept"`
+	Removed []string `json:"removed"`
+}
+
+type MergeRequest struct {
+	IDs []string `json:"ids" binding:"required,len=2"`
+}
+
+type SplitRequest struct {
+	At time.Time `json:"at" binding:"required"`
+}
+
+// Session is a sleep record with its length worked out
+type Session struct {
+	Sleep
+	Duration *measure.Duration `json:"duration,omitempty"` // minutes; absent while asleep
+}
+
+// EditResult is what a merge or split left behind, with the length of each
+// session recalculated
+type EditResult struct {
+	Sessions []Session `json:"sessions"`
+	Removed  []string  `json:"removed"`
+}
+
+// WakingRequest logs a waking. The start defaults to now; leave the end
+// unset while the child is still awake.
+type WakingRequest struct {
+	StartTime *time.Time   `json:"start_time,omitempty"`
+	EndTime   *time.Time   `json:"end_time,omitempty"`
+	Reason    WakingReason `json:"reason,omitempty" binding:"omitempty,oneof=hungry diaper comfort teething unwell other"`
+}
@@ -0,0 +1,41 @@
+package sleep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+)
+
+var ErrNotAuthor = errors.New("only the member who started a sleep can change who sees it")
+
+// hiddenFrom reports whether the sleep is another member's private sleep.
+// Reads without a user, by jobs and other services, see all sleep.
+func hiddenFrom(sl *Sleep, userID string) bool {
+	return userID != "" && !sl.VisibleTo(userID)
+}
+
+// SetPrivate lets the member who logged the sleep hide it from the other
+// members, or show it again. Other members' private sleep is not found.
+func (s *service) SetPrivate(ctx context.Context, userID, id string, private bool) error {
+	sl, err := s.getForEdit(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if sl.StartedBy != userID {
+		return ErrNotAuthor
+	}
+
+	sl.Private = private
+	sl.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, sl); err != nil {
+		return fmt.Errorf("failed to update sleep: %w", err)
+	}
+	if sl, err = s.withWakings(ctx, sl); err != nil {
+		return err
+	}
+	s.recordVersion(ctx, sl, audit.ActionUpdate)
+	return nil
+}
@@ -0,0 +1,148 @@
+package sleep
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+)
+
+// DefaultSourcePriority prefers what a parent logged over what a device
+// inferred when the two overlap.
+var DefaultSourcePriority = []string{SourceManual, "device"}
+
+// Option configures the sleep service
+type Option func(*service)
+
+// WithSourcePriority sets which record survives a merge. Entries are matched
+// against a record's source exactly or as a prefix before ":", so "device"
+// matches "device:abc". Sources not listed rank last.
+func WithSourcePriority(priority []string) Option {
+	return func(s *service) {
+		if len(priority) > 0 {
+			s.sourcePriority = priority
+		}
+	}
+}
+
+func (s *service) ListConflicts(ctx context.Context, childID string, from, to time.Time) ([]Conflict, error) {
+	records, err := s.repo.ListBetween(ctx, childID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sleep: %w", err)
+	}
+
+	conflicts := []Conflict{}
+	for _, group := range overlapGroups(records, time.Now()) {
+		conflicts = append(conflicts, Conflict{
+			ChildID: childID,
+			Records: group,
+			KeepID:  s.preferred(group).ID,
+		})
+	}
+	return conflicts, nil
+}
+
+// Reconcile merges every conflict in the window. The preferred record keeps
+// its own times; quality and notes missing from it are filled from the
+// records it absorbs, which are then deleted.
+func (s *service) Reconcile(ctx context.Context, childID string, from, to time.Time) (*ReconcileResult, error) {
+	conflicts, err := s.ListConflicts(ctx, childID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{Kept: []Sleep{}, Removed: []string{}}
+	for _, c := range conflicts {
+		keep := s.preferred(c.Records)
+
+		for _, other := range c.Records {
+			if other.ID == keep.ID {
+				continue
+			}
+			if keep.Quality == nil && other.Quality != nil {
+				keep.Quality = other.Quality
+			}
+			if keep.Notes == "" && other.Notes != "" {
+				keep.Notes = other.Notes
+			}
+		}
+
+		keep.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, &keep); err != nil {
+			return result, fmt.Errorf("failed to update sleep: %w", err)
+		}
+		s.recordVersion(ctx, &keep, audit.ActionUpdate)
+		result.Kept = append(result.Kept, keep)
+
+		for _, other := range c.Records {
+			if other.ID == keep.ID {
+				continue
+			}
+			if err := s.deleteRecord(ctx, &other); err != nil {
+				return result, fmt.Errorf("failed to delete sleep: %w", err)
+			}
+			s.recordVersion(ctx, &other, audit.ActionDelete)
+			result.Removed = append(result.Removed, other.ID)
+		}
+	}
+
+	return result, nil
+}
+
+// preferred picks the record with the highest-priority source, breaking
+// ties by the earliest created.
+func (s *service) preferred(records []Sleep) Sleep {
+	return slices.MinFunc(records, func(a, b Sleep) int {
+		if ra, rb := s.sourceRank(a.Source), s.sourceRank(b.Source); ra != rb {
+			return ra - rb
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
+
+func (s *service) sourceRank(source string) int {
+	for i, p := range s.sourcePriority {
+		if source == p || strings.HasPrefix(source, p+":") {
+			return i
+		}
+	}
+	return len(s.sourcePriority)
+}
+
+// overlapGroups clusters records sorted by start time into groups whose
+// intervals chain together. Only groups of two or more are returned.
+func overlapGroups(records []Sleep, now time.Time) [][]Sleep {
+	end := func(s Sleep) time.Time {
+		if s.EndTime != nil {
+			return *s.EndTime
+		}
+		return now
+	}
+
+	var groups [][]Sleep
+	var current []Sleep
+	var currentEnd time.Time
+
+	for _, r := range records {
+		if len(current) > 0 && r.StartTime.Before(currentEnd) {
+			current = append(current, r)
+			if e := end(r); e.After(currentEnd) {
+				currentEnd = e
+			}
+			continue
+		}
+		if len(current) > 1 {
+			groups = append(groups, current)
+		}
+		current = []Sleep{r}
+		currentEnd = end(r)
+	}
+	if len(current) > 1 {
+		groups = append(groups, current)
+	}
+
+	return groups
+}
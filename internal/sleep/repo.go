@@ -5,15 +5,39 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"iter"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/ninenine/babytrack/internal/archive"
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
 )
 
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*Sleep, error)
+	// List returns the latest 100 records matching filter, newest first
 	List(ctx context.Context, filter *SleepFilter) ([]Sleep, error)
+	// Stream yields every record matching filter, oldest first, reading
+	// rows as they are consumed
+	Stream(ctx context.Context, filter *SleepFilter) iter.Seq2[Sleep, error]
 	Create(ctx context.Context, sleep *Sleep) error
 	Update(ctx context.Context, sleep *Sleep) error
 	Delete(ctx context.Context, id string) error
-	GetActiveSleep(ctx context.Context, childID string) (*Sleep, error)
+	End(ctx context.Context, id string, endTime time.Time, endedBy string) (bool, error)
+	GetActiveSleep(ctx context.Context, childID, viewerID string) (*Sleep, error)
+	ListBetween(ctx context.Context, childID string, from, to time.Time) ([]Sleep, error)
+	// ListWakings returns the wakings of each session, keyed by sleep ID
+	ListWakings(ctx context.Context, sleepIDs []string) (map[string][]Waking, error)
+	CreateWaking(ctx context.Context, w *Waking) error
+	EndWaking(ctx context.Context, id string, endTime time.Time) error
+	// EndOpenWakings ends any waking of the session still running
+	EndOpenWakings(ctx context.Context, sleepID string, endTime time.Time) error
+	DeleteWaking(ctx context.Context, id string) error
+	// MoveWakings reassigns the wakings of one session that start at or
+	// after since to another
+	MoveWakings(ctx context.Context, fromSleepID, toSleepID string, since time.Time) error
 }
 
 type repository struct {
@@ -24,27 +48,28 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) GetByID(ctx context.Context, id string) (*Sleep, error) {
-	query := `
-		SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at
-		FROM sleep_records
-		WHERE id = $1
-	`
+// HealthCheck checks the sleep and waking tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "sleep_records", "sleep_wakings")
+}
 
+const recordColumns = `id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at, source, started_by, ended_by, private`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSleep(row scanner) (*Sleep, error) {
 	var s Sleep
 	var endTime, syncedAt sql.NullTime
 	var quality sql.NullInt32
-	var notes sql.NullString
+	var notes, startedBy, endedBy sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	if err := row.Scan(
 		&s.ID, &s.ChildID, &s.Type, &s.StartTime, &endTime,
-		&quality, &notes, &s.CreatedAt, &s.UpdatedAt, &syncedAt,
-	)
-
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, nil
-	}
-	if err != nil {
+		&quality, &notes, &s.CreatedAt, &s.UpdatedAt, &syncedAt, &s.Source,
+		&startedBy, &endedBy, &s.Private,
+	); err != nil {
 		return nil, err
 	}
 
@@ -58,6 +83,8 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Sleep, error) {
 	if notes.Valid {
 		s.Notes = notes.String
 	}
+	s.StartedBy = startedBy.String
+	s.EndedBy = endedBy.String
 	if syncedAt.Valid {
 		s.SyncedAt = &syncedAt.Time
 	}
@@ -65,12 +92,19 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Sleep, error) {
 	return &s, nil
 }
 
-func (r *repository) List(ctx context.Context, filter *SleepFilter) ([]Sleep, error) {
-	query := `
-		SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at
-		FROM sleep_records
-		WHERE 1=1
-	`
+func (r *repository) GetByID(ctx context.Context, id string) (*Sleep, error) {
+	query := `SELECT ` + recordColumns + ` FROM sleep_records_all WHERE id = $1`
+
+	s, err := scanSleep(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return s, err
+}
+
+// filterQuery selects the records matching filter, leaving the order to the caller
+func filterQuery(filter *SleepFilter) (string, []any) {
+	query := `SELECT ` + recordColumns + ` FROM sleep_records_all WHERE 1=1`
 	args := []any{}
 	argIndex := 1
 
@@ -95,49 +129,50 @@ func (r *repository) List(ctx context.Context, filter *SleepFilter) ([]Sleep, er
 	if filter.Type != nil {
 		query += fmt.Sprintf(` AND type = $%d`, argIndex)
 		args = append(args, *filter.Type)
+		argIndex++
+	}
+
+	if filter.ViewerID != "" {
+		query += fmt.Sprintf(` AND (private = FALSE OR started_by = $%d)`, argIndex)
+		args = append(args, filter.ViewerID)
 	}
 
+	return query, args
+}
+
+func (r *repository) List(ctx context.Context, filter *SleepFilter) ([]Sleep, error) {
+	query, args := filterQuery(filter)
 	query += ` ORDER BY start_time DESC LIMIT 100`
+	return r.query(ctx, query, args...)
+}
+
+func (r *repository) Stream(ctx context.Context, filter *SleepFilter) iter.Seq2[Sleep, error] {
+	query, args := filterQuery(filter)
+	query += ` ORDER BY start_time ASC, id ASC`
+
+	return db.Stream(ctx, r.db, func(rows *sql.Rows) (Sleep, error) {
+		s, err := scanSleep(rows)
+		if err != nil {
+			return Sleep{}, err
+		}
+		return *s, nil
+	}, query, args...)
+}
 
+func (r *repository) query(ctx context.Context, query string, args ...any) ([]Sleep, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close() //nolint:errcheck // Best-effort close
 
-	var sleeps []Sleep
+	sleeps := []Sleep{}
 	for rows.Next() {
-		var s Sleep
-		var endTime, syncedAt sql.NullTime
-		var quality sql.NullInt32
-		var notes sql.NullString
-
-		if err := rows.Scan(
-			&s.ID, &s.ChildID, &s.Type, &s.StartTime, &endTime,
-			&quality, &notes, &s.CreatedAt, &s.UpdatedAt, &syncedAt,
-		); err != nil {
+		s, err := scanSleep(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		if endTime.Valid {
-			s.EndTime = &endTime.Time
-		}
-		if quality.Valid {
-			q := int(quality.Int32)
-			s.Quality = &q
-		}
-		if notes.Valid {
-			s.Notes = notes.String
-		}
-		if syncedAt.Valid {
-			s.SyncedAt = &syncedAt.Time
-		}
-
-		sleeps = append(sleeps, s)
-	}
-
-	if sleeps == nil {
-		return []Sleep{}, nil
+		sleeps = append(sleeps, *s)
 	}
 
 	return sleeps, rows.Err()
@@ -145,16 +180,19 @@ func (r *repository) List(ctx context.Context, filter *SleepFilter) ([]Sleep, er
 
 func (r *repository) Create(ctx context.Context, sleep *Sleep) error {
 	query := `
-		INSERT INTO sleep_records (id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO sleep_records (id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, source, started_by, private)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
-	var notes *string
+	var notes, startedBy *string
 	if sleep.Notes != "" {
 		notes = &sleep.Notes
 	}
+	if sleep.StartedBy != "" {
+		startedBy = &sleep.StartedBy
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query,
 		sleep.ID,
 		sleep.ChildID,
 		sleep.Type,
@@ -164,15 +202,23 @@ func (r *repository) Create(ctx context.Context, sleep *Sleep) error {
 		notes,
 		sleep.CreatedAt,
 		sleep.UpdatedAt,
+		sleep.Source,
+		startedBy,
+		sleep.Private,
 	)
 
 	return err
 }
 
 func (r *repository) Update(ctx context.Context, sleep *Sleep) error {
+	// Edits to archived history bring the record back to the live table
+	if err := archive.Restore(ctx, r.db, archive.Sleep, sleep.ID); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE sleep_records
-		SET type = $2, start_time = $3, end_time = $4, quality = $5, notes = $6, updated_at = $7
+		SET type = $2, start_time = $3, end_time = $4, quality = $5, notes = $6, updated_at = $7, private = $8
 		WHERE id = $1
 	`
 
@@ -181,7 +227,7 @@ func (r *repository) Update(ctx context.Context, sleep *Sleep) error {
 		notes = &sleep.Notes
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query,
 		sleep.ID,
 		sleep.Type,
 		sleep.StartTime,
@@ -189,56 +235,150 @@ func (r *repository) Update(ctx context.Context, sleep *Sleep) error {
 		sleep.Quality,
 		notes,
 		sleep.UpdatedAt,
+		sleep.Private,
 	)
 
 	return err
 }
 
+// End closes an active session. It reports false when the session had
+// already ended, leaving the first end time in place.
+func (r *repository) End(ctx context.Context, id string, endTime time.Time, endedBy string) (bool, error) {
+	query := `
+		UPDATE sleep_records
+		SET end_time = $2, ended_by = $3, updated_at = $2
+		WHERE id = $1 AND end_time IS NULL
+	`
+
+	var by *string
+	if endedBy != "" {
+		by = &endedBy
+	}
+
+	result, err := r.db.ExecContext(ctx, query, id, endTime, by)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
 func (r *repository) Delete(ctx context.Context, id string) error {
+	if err := archive.Restore(ctx, r.db, archive.Sleep, id); err != nil {
+		return err
+	}
+
 	query := `DELETE FROM sleep_records WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *repository) GetActiveSleep(ctx context.Context, childID string) (*Sleep, error) {
+// GetActiveSleep returns the child's running sleep if viewerID may see it,
+// or whichever is running when viewerID is empty
+func (r *repository) GetActiveSleep(ctx context.Context, childID, viewerID string) (*Sleep, error) {
 	query := `
-		SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at
+		SELECT ` + recordColumns + `
 		FROM sleep_records
-		WHERE child_id = $1 AND end_time IS NULL
+		WHERE child_id = $1 AND end_time IS NULL AND ($2 = '' OR private = FALSE OR started_by = $2)
 		ORDER BY start_time DESC
 		LIMIT 1
 	`
 
-	var s Sleep
-	var endTime, syncedAt sql.NullTime
-	var quality sql.NullInt32
-	var notes sql.NullString
-
-	err := r.db.QueryRowContext(ctx, query, childID).Scan(
-		&s.ID, &s.ChildID, &s.Type, &s.StartTime, &endTime,
-		&quality, &notes, &s.CreatedAt, &s.UpdatedAt, &syncedAt,
-	)
-
+	s, err := scanSleep(db.Use(ctx, r.db).QueryRowContext(ctx, query, childID, viewerID))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
+	return s, err
+}
+
+// ListBetween returns every record for the child that overlaps [from, to),
+// oldest first. Active sessions are treated as running until now.
+func (r *repository) ListBetween(ctx context.Context, childID string, from, to time.Time) ([]Sleep, error) {
+	query := `
+		SELECT ` + recordColumns + `
+		FROM sleep_records_all
+		WHERE child_id = $1 AND start_time < $3 AND COALESCE(end_time, NOW()) > $2
+		ORDER BY start_time ASC
+	`
+	return r.query(ctx, query, childID, from, to)
+}
+
+func (r *repository) ListWakings(ctx context.Context, sleepIDs []string) (map[string][]Waking, error) {
+	wakings := make(map[string][]Waking)
+	if len(sleepIDs) == 0 {
+		return wakings, nil
+	}
+
+	query := `
+		SELECT id, sleep_id, start_time, end_time, COALESCE(reason, ''), COALESCE(created_by, ''), created_at
+		FROM sleep_wakings
+		WHERE sleep_id = ANY($1)
+		ORDER BY start_time ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(sleepIDs))
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
 
-	if endTime.Valid {
-		s.EndTime = &endTime.Time
-	}
-	if quality.Valid {
-		q := int(quality.Int32)
-		s.Quality = &q
+	for rows.Next() {
+		var w Waking
+		var endTime sql.NullTime
+		if err := rows.Scan(&w.ID, &w.SleepID, &w.StartTime, &endTime, &w.Reason, &w.CreatedBy, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		if endTime.Valid {
+			w.EndTime = &endTime.Time
+		}
+		wakings[w.SleepID] = append(wakings[w.SleepID], w)
 	}
-	if notes.Valid {
-		s.Notes = notes.String
+
+	return wakings, rows.Err()
+}
+
+func (r *repository) CreateWaking(ctx context.Context, w *Waking) error {
+	query := `
+		INSERT INTO sleep_wakings (id, sleep_id, start_time, end_time, reason, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	var reason, createdBy *string
+	if w.Reason != "" {
+		r := string(w.Reason)
+		reason = &r
 	}
-	if syncedAt.Valid {
-		s.SyncedAt = &syncedAt.Time
+	if w.CreatedBy != "" {
+		createdBy = &w.CreatedBy
 	}
 
-	return &s, nil
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, w.ID, w.SleepID, w.StartTime, w.EndTime, reason, createdBy, w.CreatedAt)
+	return err
+}
+
+func (r *repository) EndWaking(ctx context.Context, id string, endTime time.Time) error {
+	query := `UPDATE sleep_wakings SET end_time = $2 WHERE id = $1 AND end_time IS NULL`
+	_, err := r.db.ExecContext(ctx, query, id, endTime)
+	return err
+}
+
+func (r *repository) EndOpenWakings(ctx context.Context, sleepID string, endTime time.Time) error {
+	query := `UPDATE sleep_wakings SET end_time = $2 WHERE sleep_id = $1 AND end_time IS NULL`
+	_, err := r.db.ExecContext(ctx, query, sleepID, endTime)
+	return err
+}
+
+func (r *repository) DeleteWaking(ctx context.Context, id string) error {
+	query := `DELETE FROM sleep_wakings WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *repository) MoveWakings(ctx context.Context, fromSleepID, toSleepID string, since time.Time) error {
+	query := `UPDATE sleep_wakings SET sleep_id = $2 WHERE sleep_id = $1 AND start_time >= $3`
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, fromSleepID, toSleepID, since)
+	return err
 }
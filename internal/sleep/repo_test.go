@@ -20,7 +20,7 @@ func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
 }
 
 var sleepColumns = []string{
-	"id", "child_id", "type", "start_time", "end_time", "quality", "notes", "created_at", "updated_at", "synced_at",
+	"id", "child_id", "type", "start_time", "end_time", "quality", "notes", "created_at", "updated_at", "synced_at", "source", "started_by", "ended_by", "private",
 }
 
 func TestRepository_GetByID(t *testing.T) {
@@ -32,7 +32,7 @@ func TestRepository_GetByID(t *testing.T) {
 	endTime := now.Add(2 * time.Hour)
 	quality := 4
 	rows := sqlmock.NewRows(sleepColumns).
-		AddRow("sleep-123", "child-456", "nap", now, endTime, quality, "Good nap", now, now, now)
+		AddRow("sleep-123", "child-456", "nap", now, endTime, quality, "Good nap", now, now, now, "manual", nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at").
 		WithArgs("sleep-123").
@@ -125,7 +125,7 @@ func TestRepository_GetByID_NullOptionalFields(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(sleepColumns).
-		AddRow("sleep-123", "child-456", "night", now, nil, nil, nil, now, now, nil)
+		AddRow("sleep-123", "child-456", "night", now, nil, nil, nil, now, now, nil, "manual", nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at").
 		WithArgs("sleep-123").
@@ -166,8 +166,8 @@ func TestRepository_List(t *testing.T) {
 	endTime := now.Add(2 * time.Hour)
 	quality := 5
 	rows := sqlmock.NewRows(sleepColumns).
-		AddRow("sleep-1", "child-456", "nap", now, endTime, quality, "Nap notes", now, now, now).
-		AddRow("sleep-2", "child-456", "night", now, endTime, quality, "Night notes", now, now, nil)
+		AddRow("sleep-1", "child-456", "nap", now, endTime, quality, "Nap notes", now, now, now, "manual", nil, nil, false).
+		AddRow("sleep-2", "child-456", "night", now, endTime, quality, "Night notes", now, now, nil, "manual", nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at").
 		WithArgs("child-456").
@@ -207,7 +207,7 @@ func TestRepository_List_WithAllFilters(t *testing.T) {
 	sleepType := SleepTypeNap
 
 	rows := sqlmock.NewRows(sleepColumns).
-		AddRow("sleep-1", "child-456", "nap", now, now.Add(time.Hour), 4, "Filtered nap", now, now, nil)
+		AddRow("sleep-1", "child-456", "nap", now, now.Add(time.Hour), 4, "Filtered nap", now, now, nil, "manual", nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at").
 		WithArgs("child-456", startDate, endDate, sleepType).
@@ -262,6 +262,32 @@ func TestRepository_List_Empty(t *testing.T) {
 	}
 }
 
+func TestRepository_List_Private(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(sleepColumns).
+		AddRow("sleep-1", "child-456", "nap", now, nil, nil, nil, now, now, nil, "manual", "user-1", nil, true)
+
+	mock.ExpectQuery("FROM sleep_records_all WHERE 1=1 AND child_id = \\$1 AND \\(private = FALSE OR started_by = \\$2\\)").
+		WithArgs("child-456", "user-1").
+		WillReturnRows(rows)
+
+	sleeps, err := repo.List(context.Background(), &SleepFilter{ChildID: "child-456", ViewerID: "user-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(sleeps) != 1 || sleeps[0].StartedBy != "user-1" || !sleeps[0].Private {
+		t.Errorf("List() = %+v, want the starter's private sleep", sleeps)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestRepository_List_Error(t *testing.T) {
 	db, mock := newMockDB(t)
 	defer db.Close()
@@ -288,7 +314,7 @@ func TestRepository_List_ScanError(t *testing.T) {
 
 	// Create rows with invalid data type to trigger scan error
 	rows := sqlmock.NewRows(sleepColumns).
-		AddRow("sleep-1", "child-456", "nap", "invalid-time", nil, nil, nil, nil, nil, nil)
+		AddRow("sleep-1", "child-456", "nap", "invalid-time", nil, nil, nil, nil, nil, nil, "manual", nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at").
 		WillReturnRows(rows)
@@ -311,7 +337,7 @@ func TestRepository_List_NullOptionalFields(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(sleepColumns).
-		AddRow("sleep-1", "child-456", "nap", now, nil, nil, nil, now, now, nil)
+		AddRow("sleep-1", "child-456", "nap", now, nil, nil, nil, now, now, nil, "manual", nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at").
 		WillReturnRows(rows)
@@ -369,7 +395,7 @@ func TestRepository_Create(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO sleep_records").
-		WithArgs(s.ID, s.ChildID, s.Type, s.StartTime, s.EndTime, s.Quality, &s.Notes, s.CreatedAt, s.UpdatedAt).
+		WithArgs(s.ID, s.ChildID, s.Type, s.StartTime, s.EndTime, s.Quality, &s.Notes, s.CreatedAt, s.UpdatedAt, s.Source, nil, false).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), s)
@@ -398,7 +424,7 @@ func TestRepository_Create_NoOptionalFields(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO sleep_records").
-		WithArgs(s.ID, s.ChildID, s.Type, s.StartTime, nil, nil, nil, s.CreatedAt, s.UpdatedAt).
+		WithArgs(s.ID, s.ChildID, s.Type, s.StartTime, nil, nil, nil, s.CreatedAt, s.UpdatedAt, s.Source, nil, false).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), s)
@@ -427,7 +453,7 @@ func TestRepository_Create_Error(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO sleep_records").
-		WithArgs(s.ID, s.ChildID, s.Type, s.StartTime, nil, nil, nil, s.CreatedAt, s.UpdatedAt).
+		WithArgs(s.ID, s.ChildID, s.Type, s.StartTime, nil, nil, nil, s.CreatedAt, s.UpdatedAt, s.Source, nil, false).
 		WillReturnError(errors.New("duplicate key"))
 
 	err := repo.Create(context.Background(), s)
@@ -458,8 +484,10 @@ func TestRepository_Update(t *testing.T) {
 		UpdatedAt: now,
 	}
 
+	mock.ExpectExec("INSERT INTO sleep_records SELECT \\* FROM restored").
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("UPDATE sleep_records SET type").
-		WithArgs(s.ID, s.Type, s.StartTime, s.EndTime, s.Quality, &s.Notes, s.UpdatedAt).
+		WithArgs(s.ID, s.Type, s.StartTime, s.EndTime, s.Quality, &s.Notes, s.UpdatedAt, false).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), s)
@@ -485,8 +513,10 @@ func TestRepository_Update_NoOptionalFields(t *testing.T) {
 		UpdatedAt: now,
 	}
 
+	mock.ExpectExec("INSERT INTO sleep_records SELECT \\* FROM restored").
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("UPDATE sleep_records SET type").
-		WithArgs(s.ID, s.Type, s.StartTime, nil, nil, nil, s.UpdatedAt).
+		WithArgs(s.ID, s.Type, s.StartTime, nil, nil, nil, s.UpdatedAt, false).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), s)
@@ -512,8 +542,10 @@ func TestRepository_Update_Error(t *testing.T) {
 		UpdatedAt: now,
 	}
 
+	mock.ExpectExec("INSERT INTO sleep_records SELECT \\* FROM restored").
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("UPDATE sleep_records SET type").
-		WithArgs(s.ID, s.Type, s.StartTime, nil, nil, nil, s.UpdatedAt).
+		WithArgs(s.ID, s.Type, s.StartTime, nil, nil, nil, s.UpdatedAt, false).
 		WillReturnError(errors.New("database error"))
 
 	err := repo.Update(context.Background(), s)
@@ -526,11 +558,59 @@ func TestRepository_Update_Error(t *testing.T) {
 	}
 }
 
+func TestRepository_End(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE sleep_records SET end_time = \\$2, ended_by = \\$3, updated_at = \\$2 WHERE id = \\$1 AND end_time IS NULL").
+		WithArgs("sleep-123", now, "user-456").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ended, err := repo.End(context.Background(), "sleep-123", now, "user-456")
+	if err != nil {
+		t.Fatalf("End() error = %v", err)
+	}
+	if !ended {
+		t.Error("End() should report an active session as ended")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_End_AlreadyEnded(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE sleep_records").
+		WithArgs("sleep-123", now, "user-456").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ended, err := repo.End(context.Background(), "sleep-123", now, "user-456")
+	if err != nil {
+		t.Fatalf("End() error = %v", err)
+	}
+	if ended {
+		t.Error("End() should not report a finished session as ended again")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestRepository_Delete(t *testing.T) {
 	db, mock := newMockDB(t)
 	defer db.Close()
 	repo := NewRepository(db)
 
+	mock.ExpectExec("INSERT INTO sleep_records SELECT \\* FROM restored").
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("DELETE FROM sleep_records WHERE id").
 		WithArgs("delete-sleep").
 		WillReturnResult(sqlmock.NewResult(0, 1))
@@ -550,6 +630,8 @@ func TestRepository_Delete_Error(t *testing.T) {
 	defer db.Close()
 	repo := NewRepository(db)
 
+	mock.ExpectExec("INSERT INTO sleep_records SELECT \\* FROM restored").
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("DELETE FROM sleep_records WHERE id").
 		WithArgs("error-delete").
 		WillReturnError(errors.New("database error"))
@@ -571,13 +653,13 @@ func TestRepository_GetActiveSleep(t *testing.T) {
 
 	now := time.Now()
 	rows := sqlmock.NewRows(sleepColumns).
-		AddRow("active-sleep", "child-456", "nap", now, nil, nil, "Active nap", now, now, nil)
+		AddRow("active-sleep", "child-456", "nap", now, nil, nil, "Active nap", now, now, nil, "manual", nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at").
-		WithArgs("child-456").
+		WithArgs("child-456", "").
 		WillReturnRows(rows)
 
-	s, err := repo.GetActiveSleep(context.Background(), "child-456")
+	s, err := repo.GetActiveSleep(context.Background(), "child-456", "")
 	if err != nil {
 		t.Fatalf("GetActiveSleep() error = %v", err)
 	}
@@ -613,10 +695,10 @@ func TestRepository_GetActiveSleep_NotFound(t *testing.T) {
 	repo := NewRepository(db)
 
 	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at").
-		WithArgs("child-456").
+		WithArgs("child-456", "").
 		WillReturnError(sql.ErrNoRows)
 
-	s, err := repo.GetActiveSleep(context.Background(), "child-456")
+	s, err := repo.GetActiveSleep(context.Background(), "child-456", "")
 	if err != nil {
 		t.Fatalf("GetActiveSleep() error = %v", err)
 	}
@@ -636,10 +718,10 @@ func TestRepository_GetActiveSleep_Error(t *testing.T) {
 	repo := NewRepository(db)
 
 	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at").
-		WithArgs("child-456").
+		WithArgs("child-456", "").
 		WillReturnError(errors.New("database error"))
 
-	_, err := repo.GetActiveSleep(context.Background(), "child-456")
+	_, err := repo.GetActiveSleep(context.Background(), "child-456", "")
 	if err == nil {
 		t.Error("GetActiveSleep() should return error on database failure")
 	}
@@ -657,13 +739,13 @@ func TestRepository_GetActiveSleep_WithQuality(t *testing.T) {
 	now := time.Now()
 	quality := 3
 	rows := sqlmock.NewRows(sleepColumns).
-		AddRow("active-sleep", "child-456", "night", now, nil, quality, nil, now, now, now)
+		AddRow("active-sleep", "child-456", "night", now, nil, quality, nil, now, now, now, "manual", nil, nil, false)
 
 	mock.ExpectQuery("SELECT id, child_id, type, start_time, end_time, quality, notes, created_at, updated_at, synced_at").
-		WithArgs("child-456").
+		WithArgs("child-456", "").
 		WillReturnRows(rows)
 
-	s, err := repo.GetActiveSleep(context.Background(), "child-456")
+	s, err := repo.GetActiveSleep(context.Background(), "child-456", "")
 	if err != nil {
 		t.Fatalf("GetActiveSleep() error = %v", err)
 	}
@@ -684,3 +766,146 @@ func TestRepository_GetActiveSleep_WithQuality(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func TestRepository_GetActiveSleep_Viewer(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("FROM sleep_records WHERE child_id = \\$1 AND end_time IS NULL AND \\(\\$2 = '' OR private = FALSE OR started_by = \\$2\\)").
+		WithArgs("child-456", "user-1").
+		WillReturnError(sql.ErrNoRows)
+
+	s, err := repo.GetActiveSleep(context.Background(), "child-456", "user-1")
+	if err != nil || s != nil {
+		t.Errorf("GetActiveSleep() = %+v, %v; want another member's private sleep left out", s, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListBetween(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	start := from.Add(13 * time.Hour)
+	end := start.Add(time.Hour)
+
+	rows := sqlmock.NewRows(sleepColumns).
+		AddRow("sleep-1", "child-456", "nap", start, end, nil, nil, from, from, nil, "device:dev-1", nil, nil, false).
+		AddRow("sleep-2", "child-456", "nap", start.Add(5*time.Minute), nil, nil, nil, from, from, nil, "manual", nil, nil, false)
+
+	mock.ExpectQuery("SELECT (.+) FROM sleep_records_all WHERE child_id = \\$1 AND start_time < \\$3").
+		WithArgs("child-456", from, to).
+		WillReturnRows(rows)
+
+	sleeps, err := repo.ListBetween(context.Background(), "child-456", from, to)
+	if err != nil {
+		t.Fatalf("ListBetween() error = %v", err)
+	}
+	if len(sleeps) != 2 || sleeps[0].Source != "device:dev-1" || sleeps[1].EndTime != nil {
+		t.Errorf("ListBetween() = %+v", sleeps)
+	}
+}
+
+func TestRepository_ListWakings(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, sleep_id, start_time, end_time, .* FROM sleep_wakings WHERE sleep_id = ANY\\(\\$1\\)").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "sleep_id", "start_time", "end_time", "reason", "created_by", "created_at"}).
+			AddRow("waking-1", "sleep-1", now.Add(-time.Hour), now.Add(-50*time.Minute), "hungry", "user-1", now).
+			AddRow("waking-2", "sleep-1", now.Add(-10*time.Minute), nil, "", "", now))
+
+	wakings, err := repo.ListWakings(context.Background(), []string{"sleep-1", "sleep-2"})
+	if err != nil {
+		t.Fatalf("ListWakings() error = %v", err)
+	}
+	got := wakings["sleep-1"]
+	if len(got) != 2 || got[0].Reason != WakingHungry || got[0].EndTime == nil || got[1].EndTime != nil {
+		t.Errorf("ListWakings() = %+v", wakings)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListWakings_NoSessions(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	wakings, err := repo.ListWakings(context.Background(), nil)
+	if err != nil || len(wakings) != 0 {
+		t.Errorf("ListWakings() = %v, %v; want empty without a query", wakings, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_CreateWaking(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO sleep_wakings").
+		WithArgs("waking-1", "sleep-1", now, nil, nil, "user-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := &Waking{ID: "waking-1", SleepID: "sleep-1", StartTime: now, CreatedBy: "user-1", CreatedAt: now}
+	if err := repo.CreateWaking(context.Background(), w); err != nil {
+		t.Fatalf("CreateWaking() error = %v", err)
+	}
+}
+
+func TestRepository_MoveWakings(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	at := time.Now()
+	mock.ExpectExec("UPDATE sleep_wakings SET sleep_id = \\$2 WHERE sleep_id = \\$1 AND start_time >= \\$3").
+		WithArgs("sleep-1", "sleep-2", at).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := repo.MoveWakings(context.Background(), "sleep-1", "sleep-2", at); err != nil {
+		t.Fatalf("MoveWakings() error = %v", err)
+	}
+}
+
+func TestRepository_Stream(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT .* FROM sleep_records_all WHERE 1=1 AND child_id = \\$1 ORDER BY start_time ASC, id ASC$").
+		WithArgs("child-456").
+		WillReturnRows(sqlmock.NewRows(sleepColumns).
+			AddRow("sleep-1", "child-456", "night", now.AddDate(-3, 0, 0), now, 4, nil, now, now, nil, "manual", nil, nil, false).
+			AddRow("sleep-2", "child-456", "nap", now, nil, nil, nil, now, now, nil, "manual", nil, nil, false))
+
+	var got []Sleep
+	for s, err := range repo.Stream(context.Background(), &SleepFilter{ChildID: "child-456"}) {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		got = append(got, s)
+	}
+	if len(got) != 2 || got[0].Quality == nil || *got[0].Quality != 4 || got[1].EndTime != nil {
+		t.Errorf("Stream() = %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
@@ -2,42 +2,101 @@ package sleep
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"fmt"
+	"iter"
+	"log"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
 type Service interface {
 	Create(ctx context.Context, req *CreateSleepRequest) (*Sleep, error)
-	Get(ctx context.Context, id string) (*Sleep, error)
+	Get(ctx context.Context, userID, id string) (*Sleep, error)
 	List(ctx context.Context, filter *SleepFilter) ([]Sleep, error)
-	Update(ctx context.Context, id string, req *CreateSleepRequest) (*Sleep, error)
-	Delete(ctx context.Context, id string) error
-	StartSleep(ctx context.Context, childID string, sleepType SleepType) (*Sleep, error)
-	EndSleep(ctx context.Context, id string) (*Sleep, error)
-	GetActiveSleep(ctx context.Context, childID string) (*Sleep, error)
+	// Stream yields every matching record with its wakings, oldest first,
+	// for exports too large to list
+	Stream(ctx context.Context, filter *SleepFilter) iter.Seq2[Sleep, error]
+	Update(ctx context.Context, userID, id string, req *CreateSleepRequest) (*Sleep, error)
+	Delete(ctx context.Context, userID, id string) error
+	SetPrivate(ctx context.Context, userID, id string, private bool) error
+	StartSleep(ctx context.Context, userID, childID string, sleepType SleepType) (*Sleep, error)
+	// EndSleep may be called by any family member; ending a session that has
+	// already ended returns it unchanged.
+	EndSleep(ctx context.Context, userID, id string) (*Sleep, error)
+	// GetActiveSleep leaves out another member's private sleep when viewerID is set
+	GetActiveSleep(ctx context.Context, childID, viewerID string) (*Sleep, error)
+	ListConflicts(ctx context.Context, childID string, from, to time.Time) ([]Conflict, error)
+	Reconcile(ctx context.Context, childID string, from, to time.Time) (*ReconcileResult, error)
+	Merge(ctx context.Context, userID, firstID, secondID string) (*EditResult, error)
+	Split(ctx context.Context, userID, id string, at time.Time) (*EditResult, error)
+	AddWaking(ctx context.Context, userID, sleepID string, req *WakingRequest) (*Sleep, error)
+	EndWaking(ctx context.Context, userID, sleepID, wakingID string) (*Sleep, error)
+	RemoveWaking(ctx context.Context, userID, sleepID, wakingID string) (*Sleep, error)
 }
 
 type service struct {
-	repo Repository
+	repo           Repository
+	sourcePriority []string
+	history        audit.Store
+	familyService  family.Service
+	notifier       Notifier
+	tx             db.TxManager
+	tombstones     delta.Tombstones
+	visibility     visibility.Service
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{repo: repo, tx: db.NoTx, sourcePriority: DefaultSourcePriority}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether sleep records can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
 }
 
 func (s *service) Create(ctx context.Context, req *CreateSleepRequest) (*Sleep, error) {
+	// Internal callers log without a member and check access themselves
+	if req.StartedBy != "" {
+		if err := s.authorize(ctx, req.StartedBy, req.ChildID); err != nil {
+			return nil, err
+		}
+	}
 	now := time.Now()
 
+	occurrence.Adjust(ctx, "start_time", &req.StartTime, now)
+	occurrence.Adjust(ctx, "end_time", req.EndTime, now)
+	if err := occurrence.ValidateRange(req.StartTime, req.EndTime, now); err != nil {
+		return nil, err
+	}
+
+	source := req.Source
+	if source == "" {
+		source = SourceManual
+	}
+
 	sleep := &Sleep{
-		ID:        generateID(),
+		ID:        ids.New(),
 		ChildID:   req.ChildID,
 		Type:      req.Type,
 		StartTime: req.StartTime,
 		EndTime:   req.EndTime,
 		Quality:   req.Quality,
 		Notes:     req.Notes,
+		Source:    source,
+		StartedBy: req.StartedBy,
+		Private:   req.Private && req.StartedBy != "",
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -45,25 +104,102 @@ func (s *service) Create(ctx context.Context, req *CreateSleepRequest) (*Sleep,
 	if err := s.repo.Create(ctx, sleep); err != nil {
 		return nil, fmt.Errorf("failed to create sleep: %w", err)
 	}
+	s.recordVersion(ctx, sleep, audit.ActionCreate)
 
 	return sleep, nil
 }
 
-func (s *service) Get(ctx context.Context, id string) (*Sleep, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *service) Get(ctx context.Context, userID, id string) (*Sleep, error) {
+	sleep, err := s.repo.GetByID(ctx, id)
+	if err != nil || sleep == nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, userID, sleep.ChildID); err != nil {
+		return nil, err
+	}
+	if hiddenFrom(sleep, userID) {
+		return nil, nil
+	}
+	return s.withWakings(ctx, sleep)
 }
 
 func (s *service) List(ctx context.Context, filter *SleepFilter) ([]Sleep, error) {
-	return s.repo.List(ctx, filter)
+	viewer, err := s.viewer(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sleeps, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if sleeps, err = visibility.Filter(ctx, viewer, sleeps, childOf); err != nil {
+		return nil, err
+	}
+	if err := s.attachWakings(ctx, sleeps); err != nil {
+		return nil, err
+	}
+	return sleeps, nil
 }
 
-func (s *service) Update(ctx context.Context, id string, req *CreateSleepRequest) (*Sleep, error) {
+// streamBatch is how many records Stream reads before looking up their
+// wakings in one query
+const streamBatch = 500
+
+func (s *service) Stream(ctx context.Context, filter *SleepFilter) iter.Seq2[Sleep, error] {
+	return func(yield func(Sleep, error) bool) {
+		viewer, err := s.viewer(ctx, filter)
+		if err != nil {
+			yield(Sleep{}, err)
+			return
+		}
+		batch := make([]Sleep, 0, streamBatch)
+
+		// flush reports whether the caller wants more
+		flush := func() bool {
+			if err := s.attachWakings(ctx, batch); err != nil {
+				yield(Sleep{}, err)
+				return false
+			}
+			for _, sl := range batch {
+				if !yield(sl, nil) {
+					return false
+				}
+			}
+			batch = batch[:0]
+			return true
+		}
+
+		for sl, err := range visibility.FilterSeq(ctx, viewer, s.repo.Stream(ctx, filter), childOf) {
+			if err != nil {
+				yield(Sleep{}, err)
+				return
+			}
+			batch = append(batch, sl)
+			if len(batch) == streamBatch && !flush() {
+				return
+			}
+		}
+		flush()
+	}
+}
+
+func (s *service) Update(ctx context.Context, userID, id string, req *CreateSleepRequest) (*Sleep, error) {
 	sleep, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if sleep == nil {
-		return nil, fmt.Errorf("sleep not found")
+	if sleep == nil || hiddenFrom(sleep, userID) {
+		return nil, ErrNotFound
+	}
+	if err := s.authorize(ctx, userID, sleep.ChildID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	occurrence.Adjust(ctx, "start_time", &req.StartTime, now)
+	occurrence.Adjust(ctx, "end_time", req.EndTime, now)
+	if err := occurrence.ValidateRange(req.StartTime, req.EndTime, now); err != nil {
+		return nil, err
 	}
 
 	sleep.Type = req.Type
@@ -76,22 +212,46 @@ func (s *service) Update(ctx context.Context, id string, req *CreateSleepRequest
 	if err := s.repo.Update(ctx, sleep); err != nil {
 		return nil, fmt.Errorf("failed to update sleep: %w", err)
 	}
+	s.recordVersion(ctx, sleep, audit.ActionUpdate)
 
 	return sleep, nil
 }
 
-func (s *service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	sleep, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sleep == nil {
+		return s.repo.Delete(ctx, id)
+	}
+	if hiddenFrom(sleep, userID) {
+		return ErrNotFound
+	}
+	if err := s.authorize(ctx, userID, sleep.ChildID); err != nil {
+		return err
+	}
+	if err := s.deleteRecord(ctx, sleep); err != nil {
+		return err
+	}
+	s.recordVersion(ctx, sleep, audit.ActionDelete)
+	return nil
 }
 
-func (s *service) StartSleep(ctx context.Context, childID string, sleepType SleepType) (*Sleep, error) {
+func (s *service) StartSleep(ctx context.Context, userID, childID string, sleepType SleepType) (*Sleep, error) {
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 
 	sleep := &Sleep{
-		ID:        generateID(),
+		ID:        ids.New(),
 		ChildID:   childID,
 		Type:      sleepType,
 		StartTime: now,
+		Source:    SourceManual,
+		StartedBy: userID,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -99,36 +259,57 @@ func (s *service) StartSleep(ctx context.Context, childID string, sleepType Slee
 	if err := s.repo.Create(ctx, sleep); err != nil {
 		return nil, fmt.Errorf("failed to start sleep: %w", err)
 	}
+	s.recordVersion(ctx, sleep, audit.ActionCreate)
 
 	return sleep, nil
 }
 
-func (s *service) EndSleep(ctx context.Context, id string) (*Sleep, error) {
+func (s *service) EndSleep(ctx context.Context, userID, id string) (*Sleep, error) {
 	sleep, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if sleep == nil {
-		return nil, fmt.Errorf("sleep not found")
+	if sleep == nil || hiddenFrom(sleep, userID) {
+		return nil, ErrNotFound
+	}
+	if err := s.authorize(ctx, userID, sleep.ChildID); err != nil {
+		return nil, err
+	}
+	if sleep.EndTime != nil {
+		return sleep, nil
 	}
 
 	now := time.Now()
+	ended, err := s.repo.End(ctx, id, now, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to end sleep: %w", err)
+	}
+	if !ended {
+		// Another device ended it between the read and the write; theirs stands
+		return s.Get(ctx, userID, id)
+	}
+
+	// A child still awake when the session ends was awake until then
+	if err := s.repo.EndOpenWakings(ctx, id, now); err != nil {
+		log.Printf("[sleep] failed to end wakings of %s: %v", id, err)
+	}
+
 	sleep.EndTime = &now
+	sleep.EndedBy = userID
 	sleep.UpdatedAt = now
-
-	if err := s.repo.Update(ctx, sleep); err != nil {
-		return nil, fmt.Errorf("failed to end sleep: %w", err)
+	if sleep, err = s.withWakings(ctx, sleep); err != nil {
+		return nil, err
 	}
+	s.recordVersion(ctx, sleep, audit.ActionUpdate)
+	s.notifyEnded(sleep)
 
 	return sleep, nil
 }
 
-func (s *service) GetActiveSleep(ctx context.Context, childID string) (*Sleep, error) {
-	return s.repo.GetActiveSleep(ctx, childID)
-}
-
-func generateID() string {
-	b := make([]byte, 16)
-	rand.Read(b) //nolint:errcheck // crypto/rand.Read rarely fails
-	return hex.EncodeToString(b)
+func (s *service) GetActiveSleep(ctx context.Context, childID, viewerID string) (*Sleep, error) {
+	sleep, err := s.repo.GetActiveSleep(ctx, childID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withWakings(ctx, sleep)
 }
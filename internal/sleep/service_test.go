@@ -3,13 +3,24 @@ package sleep
 import (
 	"context"
 	"errors"
+	"fmt"
+	"iter"
+	"slices"
 	"testing"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/notifications"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
 // mockRepository is a test double for Repository
 type mockRepository struct {
 	sleeps    map[string]*Sleep
+	wakings   map[string]*Waking
 	createErr error
 	updateErr error
 	deleteErr error
@@ -17,7 +28,8 @@ type mockRepository struct {
 
 func newMockRepository() *mockRepository {
 	return &mockRepository{
-		sleeps: make(map[string]*Sleep),
+		sleeps:  make(map[string]*Sleep),
+		wakings: make(map[string]*Waking),
 	}
 }
 
@@ -38,11 +50,26 @@ func (m *mockRepository) List(ctx context.Context, filter *SleepFilter) ([]Sleep
 		if filter.Type != nil && s.Type != *filter.Type {
 			continue
 		}
+		if filter.ViewerID != "" && !s.VisibleTo(filter.ViewerID) {
+			continue
+		}
 		result = append(result, *s)
 	}
 	return result, nil
 }
 
+func (m *mockRepository) Stream(ctx context.Context, filter *SleepFilter) iter.Seq2[Sleep, error] {
+	sleeps, _ := m.List(ctx, filter)
+	slices.SortFunc(sleeps, func(a, b Sleep) int { return a.StartTime.Compare(b.StartTime) })
+	return func(yield func(Sleep, error) bool) {
+		for _, s := range sleeps {
+			if !yield(s, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (m *mockRepository) Create(ctx context.Context, sleep *Sleep) error {
 	if m.createErr != nil {
 		return m.createErr
@@ -67,7 +94,21 @@ func (m *mockRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *mockRepository) GetActiveSleep(ctx context.Context, childID string) (*Sleep, error) {
+func (m *mockRepository) End(ctx context.Context, id string, endTime time.Time, endedBy string) (bool, error) {
+	if m.updateErr != nil {
+		return false, m.updateErr
+	}
+	s, ok := m.sleeps[id]
+	if !ok || s.EndTime != nil {
+		return false, nil
+	}
+	s.EndTime = &endTime
+	s.EndedBy = endedBy
+	s.UpdatedAt = endTime
+	return true, nil
+}
+
+func (m *mockRepository) GetActiveSleep(ctx context.Context, childID, viewerID string) (*Sleep, error) {
 	for _, s := range m.sleeps {
 		if s.ChildID == childID && s.EndTime == nil {
 			return s, nil
@@ -76,11 +117,71 @@ func (m *mockRepository) GetActiveSleep(ctx context.Context, childID string) (*S
 	return nil, nil
 }
 
+func (m *mockRepository) ListBetween(ctx context.Context, childID string, from, to time.Time) ([]Sleep, error) {
+	result := []Sleep{}
+	for _, s := range m.sleeps {
+		if s.ChildID == childID && s.StartTime.Before(to) {
+			result = append(result, *s)
+		}
+	}
+	slices.SortFunc(result, func(a, b Sleep) int { return a.StartTime.Compare(b.StartTime) })
+	return result, nil
+}
+
+func (m *mockRepository) ListWakings(ctx context.Context, sleepIDs []string) (map[string][]Waking, error) {
+	result := make(map[string][]Waking)
+	for _, w := range m.wakings {
+		if slices.Contains(sleepIDs, w.SleepID) {
+			result[w.SleepID] = append(result[w.SleepID], *w)
+		}
+	}
+	for _, ws := range result {
+		slices.SortFunc(ws, func(a, b Waking) int { return a.StartTime.Compare(b.StartTime) })
+	}
+	return result, nil
+}
+
+func (m *mockRepository) CreateWaking(ctx context.Context, w *Waking) error {
+	stored := *w
+	m.wakings[w.ID] = &stored
+	return nil
+}
+
+func (m *mockRepository) EndWaking(ctx context.Context, id string, endTime time.Time) error {
+	if w, ok := m.wakings[id]; ok && w.EndTime == nil {
+		w.EndTime = &endTime
+	}
+	return nil
+}
+
+func (m *mockRepository) EndOpenWakings(ctx context.Context, sleepID string, endTime time.Time) error {
+	for _, w := range m.wakings {
+		if w.SleepID == sleepID && w.EndTime == nil {
+			w.EndTime = &endTime
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) DeleteWaking(ctx context.Context, id string) error {
+	delete(m.wakings, id)
+	return nil
+}
+
+func (m *mockRepository) MoveWakings(ctx context.Context, fromSleepID, toSleepID string, since time.Time) error {
+	for _, w := range m.wakings {
+		if w.SleepID == fromSleepID && !w.StartTime.Before(since) {
+			w.SleepID = toSleepID
+		}
+	}
+	return nil
+}
+
 func TestService_Create(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	startTime := time.Now()
+	startTime := time.Now().Add(-3 * time.Hour)
 	endTime := startTime.Add(2 * time.Hour)
 	quality := 4
 
@@ -163,7 +264,7 @@ func TestService_Get(t *testing.T) {
 	}
 	created, _ := svc.Create(context.Background(), req)
 
-	sleep, err := svc.Get(context.Background(), created.ID)
+	sleep, err := svc.Get(context.Background(), "user-123", created.ID)
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -181,7 +282,7 @@ func TestService_Get_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	sleep, err := svc.Get(context.Background(), "non-existent")
+	sleep, err := svc.Get(context.Background(), "user-123", "non-existent")
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -200,7 +301,7 @@ func TestService_List(t *testing.T) {
 		req := &CreateSleepRequest{
 			ChildID:   "child-123",
 			Type:      SleepTypeNap,
-			StartTime: time.Now().Add(time.Duration(i) * time.Hour),
+			StartTime: time.Now().Add(time.Duration(-i) * time.Hour),
 		}
 		svc.Create(context.Background(), req)
 	}
@@ -224,6 +325,52 @@ func TestService_List(t *testing.T) {
 	}
 }
 
+func TestService_Stream(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	base := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	total := streamBatch + 2
+	for i := range total {
+		id := fmt.Sprintf("sleep-%03d", i)
+		repo.sleeps[id] = &Sleep{ID: id, ChildID: "child-123", Type: SleepTypeNight, StartTime: base.Add(time.Duration(i) * time.Hour)}
+	}
+	repo.sleeps["other"] = &Sleep{ID: "other", ChildID: "child-456", StartTime: base}
+	last := fmt.Sprintf("sleep-%03d", total-1)
+	repo.wakings["w-1"] = &Waking{ID: "w-1", SleepID: last, StartTime: base}
+
+	var got []Sleep
+	for sl, err := range svc.Stream(context.Background(), &SleepFilter{ChildID: "child-123"}) {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		got = append(got, sl)
+	}
+
+	if len(got) != total {
+		t.Fatalf("Stream() yielded %d records, want %d", len(got), total)
+	}
+	if got[0].ID != "sleep-000" || got[total-1].ID != last {
+		t.Errorf("Stream() order = %s .. %s", got[0].ID, got[total-1].ID)
+	}
+	// The last record falls in the second batch, so its wakings come from a
+	// second lookup
+	if len(got[total-1].Wakings) != 1 {
+		t.Errorf("Stream() wakings = %+v", got[total-1].Wakings)
+	}
+
+	count := 0
+	for range svc.Stream(context.Background(), &SleepFilter{ChildID: "child-123"}) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	if count != 3 {
+		t.Errorf("Stream() kept going after break: %d", count)
+	}
+}
+
 func TestService_List_WithTypeFilter(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -273,7 +420,7 @@ func TestService_Update(t *testing.T) {
 	created, _ := svc.Create(context.Background(), req)
 
 	newQuality := 5
-	newEndTime := time.Now().Add(3 * time.Hour)
+	newEndTime := time.Now()
 	updateReq := &CreateSleepRequest{
 		ChildID:   "child-123",
 		Type:      SleepTypeNight,
@@ -283,7 +430,7 @@ func TestService_Update(t *testing.T) {
 		Notes:     "Updated notes",
 	}
 
-	updated, err := svc.Update(context.Background(), created.ID, updateReq)
+	updated, err := svc.Update(context.Background(), "user-123", created.ID, updateReq)
 	if err != nil {
 		t.Fatalf("Update() error = %v", err)
 	}
@@ -311,7 +458,7 @@ func TestService_Update_NotFound(t *testing.T) {
 		StartTime: time.Now(),
 	}
 
-	_, err := svc.Update(context.Background(), "non-existent", req)
+	_, err := svc.Update(context.Background(), "user-123", "non-existent", req)
 	if err == nil {
 		t.Error("Update() should return error for non-existent sleep")
 	}
@@ -328,22 +475,189 @@ func TestService_Delete(t *testing.T) {
 	}
 	created, _ := svc.Create(context.Background(), req)
 
-	err := svc.Delete(context.Background(), created.ID)
+	err := svc.Delete(context.Background(), "user-123", created.ID)
 	if err != nil {
 		t.Fatalf("Delete() error = %v", err)
 	}
 
-	sleep, _ := svc.Get(context.Background(), created.ID)
+	sleep, _ := svc.Get(context.Background(), "user-123", created.ID)
 	if sleep != nil {
 		t.Error("Delete() should remove the sleep")
 	}
 }
 
+// mockTombstones is a test double for delta.Tombstones
+type mockTombstones struct {
+	delta.Tombstones
+	deleted map[string]string // entity ID -> scope ID
+}
+
+func (m *mockTombstones) Record(ctx context.Context, entityType delta.EntityType, entityID, scopeID string, at time.Time) error {
+	if entityType != delta.EntitySleep {
+		return errors.New("unexpected entity type")
+	}
+	m.deleted[entityID] = scopeID
+	return nil
+}
+
+func TestService_Delete_RecordsTombstone(t *testing.T) {
+	repo := newMockRepository()
+	tombstones := &mockTombstones{deleted: make(map[string]string)}
+	svc := NewService(repo, WithTombstones(tombstones, db.NoTx))
+
+	created, _ := svc.Create(context.Background(), &CreateSleepRequest{
+		ChildID:   "child-123",
+		Type:      SleepTypeNap,
+		StartTime: time.Now(),
+	})
+	if err := svc.Delete(context.Background(), "user-123", created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if tombstones.deleted[created.ID] != "child-123" {
+		t.Errorf("tombstones = %v, want %s deleted from child-123", tombstones.deleted, created.ID)
+	}
+}
+
+// mockVisibilityService hides child-hidden's sleep, and puts child-other in
+// another family
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) CheckChild(ctx context.Context, userID, childID string, recordType visibility.RecordType) error {
+	switch childID {
+	case "child-hidden":
+		return visibility.ErrHidden
+	case "child-other":
+		return family.ErrNotMember
+	}
+	return nil
+}
+
+func TestService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewService(repo, WithVisibility(&mockVisibilityService{}))
+
+	ids := map[string]string{}
+	for _, childID := range []string{"child-123", "child-hidden", "child-other"} {
+		sl, err := svc.Create(ctx, &CreateSleepRequest{ChildID: childID, Type: SleepTypeNap, StartTime: time.Now()})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids[childID] = sl.ID
+	}
+
+	if _, err := svc.Get(ctx, "user-123", ids["child-123"]); err != nil {
+		t.Errorf("Get() visible error = %v", err)
+	}
+	if _, err := svc.Get(ctx, "user-123", ids["child-hidden"]); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Get() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Get(ctx, "user-123", ids["child-other"]); !errors.Is(err, family.ErrNotMember) {
+		t.Errorf("Get() other family error = %v, want ErrNotMember", err)
+	}
+	req := &CreateSleepRequest{Type: SleepTypeNap, StartTime: time.Now()}
+	if _, err := svc.Update(ctx, "user-123", ids["child-hidden"], req); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Update() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Delete(ctx, "user-123", ids["child-hidden"]); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Delete() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Create(ctx, &CreateSleepRequest{ChildID: "child-hidden", Type: SleepTypeNap, StartTime: time.Now(), StartedBy: "user-123"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Create() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.EndSleep(ctx, "user-123", ids["child-hidden"]); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("EndSleep() hidden error = %v, want ErrHidden", err)
+	}
+
+	list, err := svc.List(ctx, &SleepFilter{ViewerID: "user-123"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ChildID != "child-123" {
+		t.Errorf("List() = %+v, want only child-123's sleep", list)
+	}
+	if _, err := svc.List(ctx, &SleepFilter{ChildID: "child-hidden", ViewerID: "user-123"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("List() hidden child error = %v, want ErrHidden", err)
+	}
+	var streamed int
+	for _, err := range svc.Stream(ctx, &SleepFilter{ViewerID: "user-123"}) {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		streamed++
+	}
+	if streamed != 1 {
+		t.Errorf("Stream() yielded %d sessions, want 1", streamed)
+	}
+	if all, _ := svc.List(ctx, &SleepFilter{}); len(all) != 3 {
+		t.Errorf("List() without a viewer = %d sessions, want 3", len(all))
+	}
+}
+
+func TestService_Private(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	start := time.Now().Add(-time.Hour)
+	private, err := svc.Create(ctx, &CreateSleepRequest{
+		ChildID: "child-123", Type: SleepTypeNap, StartTime: start, Private: true, StartedBy: "user-1",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	shared, err := svc.Create(ctx, &CreateSleepRequest{
+		ChildID: "child-123", Type: SleepTypeNap, StartTime: start.Add(-3 * time.Hour), StartedBy: "user-1",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !private.Private || shared.Private {
+		t.Fatalf("Create() private = %v, %v; want true, false", private.Private, shared.Private)
+	}
+
+	if sl, err := svc.Get(ctx, "user-1", private.ID); err != nil || sl == nil {
+		t.Errorf("Get() by starter = %v, %v; want the sleep", sl, err)
+	}
+	if sl, err := svc.Get(ctx, "user-2", private.ID); err != nil || sl != nil {
+		t.Errorf("Get() by another member = %v, %v; want not found", sl, err)
+	}
+	if list, _ := svc.List(ctx, &SleepFilter{ChildID: "child-123", ViewerID: "user-2"}); len(list) != 1 || list[0].ID != shared.ID {
+		t.Errorf("List() for another member = %+v, want only the shared sleep", list)
+	}
+
+	req := &CreateSleepRequest{Type: SleepTypeNap, StartTime: start}
+	if _, err := svc.Update(ctx, "user-2", private.ID, req); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update() by another member error = %v, want ErrNotFound", err)
+	}
+	if _, err := svc.EndSleep(ctx, "user-2", private.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("EndSleep() by another member error = %v, want ErrNotFound", err)
+	}
+	if err := svc.Delete(ctx, "user-2", private.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() by another member error = %v, want ErrNotFound", err)
+	}
+	if err := svc.SetPrivate(ctx, "user-2", private.ID, false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetPrivate() by another member error = %v, want ErrNotFound", err)
+	}
+	if err := svc.SetPrivate(ctx, "user-2", shared.ID, true); !errors.Is(err, ErrNotAuthor) {
+		t.Errorf("SetPrivate() of shared sleep by another member error = %v, want ErrNotAuthor", err)
+	}
+
+	if err := svc.SetPrivate(ctx, "user-1", private.ID, false); err != nil {
+		t.Fatalf("SetPrivate() by starter error = %v", err)
+	}
+	if sl, err := svc.Get(ctx, "user-2", private.ID); err != nil || sl == nil {
+		t.Errorf("Get() by another member after sharing = %v, %v; want the sleep", sl, err)
+	}
+}
+
 func TestService_StartSleep(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	sleep, err := svc.StartSleep(context.Background(), "child-123", SleepTypeNap)
+	sleep, err := svc.StartSleep(context.Background(), "user-123", "child-123", SleepTypeNap)
 	if err != nil {
 		t.Fatalf("StartSleep() error = %v", err)
 	}
@@ -374,7 +688,7 @@ func TestService_StartSleep_RepoError(t *testing.T) {
 	repo.createErr = errors.New("database error")
 	svc := NewService(repo)
 
-	_, err := svc.StartSleep(context.Background(), "child-123", SleepTypeNap)
+	_, err := svc.StartSleep(context.Background(), "user-123", "child-123", SleepTypeNap)
 	if err == nil {
 		t.Error("StartSleep() should return error when repo fails")
 	}
@@ -385,10 +699,10 @@ func TestService_EndSleep(t *testing.T) {
 	svc := NewService(repo)
 
 	// Start a sleep
-	started, _ := svc.StartSleep(context.Background(), "child-123", SleepTypeNap)
+	started, _ := svc.StartSleep(context.Background(), "user-123", "child-123", SleepTypeNap)
 
 	// End it
-	ended, err := svc.EndSleep(context.Background(), started.ID)
+	ended, err := svc.EndSleep(context.Background(), "user-123", started.ID)
 	if err != nil {
 		t.Fatalf("EndSleep() error = %v", err)
 	}
@@ -406,7 +720,7 @@ func TestService_EndSleep_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	_, err := svc.EndSleep(context.Background(), "non-existent")
+	_, err := svc.EndSleep(context.Background(), "user-123", "non-existent")
 	if err == nil {
 		t.Error("EndSleep() should return error for non-existent sleep")
 	}
@@ -416,25 +730,152 @@ func TestService_EndSleep_RepoError(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	started, _ := svc.StartSleep(context.Background(), "child-123", SleepTypeNap)
+	started, _ := svc.StartSleep(context.Background(), "user-123", "child-123", SleepTypeNap)
 
 	repo.updateErr = errors.New("database error")
 
-	_, err := svc.EndSleep(context.Background(), started.ID)
+	_, err := svc.EndSleep(context.Background(), "user-123", started.ID)
 	if err == nil {
 		t.Error("EndSleep() should return error when repo fails")
 	}
 }
 
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-123" {
+		return &family.Child{ID: childID, FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID == "user-123" || userID == "user-456" {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockNotifier struct {
+	userIDs []string
+	events  []notifications.Event
+}
+
+func (m *mockNotifier) SendToUsers(userIDs []string, event notifications.Event) {
+	m.userIDs = append(m.userIDs, userIDs...)
+	m.events = append(m.events, event)
+}
+
+func TestService_EndSleep_ByAnotherMember(t *testing.T) {
+	repo := newMockRepository()
+	notifier := &mockNotifier{}
+	svc := NewService(repo, WithFamily(&mockFamilyService{}), WithNotifier(notifier))
+
+	started, err := svc.StartSleep(context.Background(), "user-123", "child-123", SleepTypeNap)
+	if err != nil {
+		t.Fatalf("StartSleep() error = %v", err)
+	}
+	if started.StartedBy != "user-123" {
+		t.Errorf("StartSleep() StartedBy = %v, want user-123", started.StartedBy)
+	}
+
+	ended, err := svc.EndSleep(context.Background(), "user-456", started.ID)
+	if err != nil {
+		t.Fatalf("EndSleep() error = %v", err)
+	}
+	if ended.EndedBy != "user-456" {
+		t.Errorf("EndSleep() EndedBy = %v, want user-456", ended.EndedBy)
+	}
+
+	if len(notifier.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(notifier.events))
+	}
+	if notifier.events[0].Type != notifications.EventSleepEnded {
+		t.Errorf("event type = %v, want %v", notifier.events[0].Type, notifications.EventSleepEnded)
+	}
+	if len(notifier.userIDs) != 1 || notifier.userIDs[0] != "user-123" {
+		t.Errorf("event sent to %v, want [user-123]", notifier.userIDs)
+	}
+}
+
+func TestService_EndSleep_BySameMemberDoesNotNotify(t *testing.T) {
+	repo := newMockRepository()
+	notifier := &mockNotifier{}
+	svc := NewService(repo, WithFamily(&mockFamilyService{}), WithNotifier(notifier))
+
+	started, _ := svc.StartSleep(context.Background(), "user-123", "child-123", SleepTypeNap)
+
+	if _, err := svc.EndSleep(context.Background(), "user-123", started.ID); err != nil {
+		t.Fatalf("EndSleep() error = %v", err)
+	}
+	if len(notifier.events) != 0 {
+		t.Errorf("expected no events, got %d", len(notifier.events))
+	}
+}
+
+func TestService_EndSleep_AlreadyEnded(t *testing.T) {
+	repo := newMockRepository()
+	notifier := &mockNotifier{}
+	svc := NewService(repo, WithFamily(&mockFamilyService{}), WithNotifier(notifier))
+
+	started, _ := svc.StartSleep(context.Background(), "user-123", "child-123", SleepTypeNap)
+
+	first, err := svc.EndSleep(context.Background(), "user-456", started.ID)
+	if err != nil {
+		t.Fatalf("EndSleep() error = %v", err)
+	}
+	firstEnd := *first.EndTime
+
+	second, err := svc.EndSleep(context.Background(), "user-123", started.ID)
+	if err != nil {
+		t.Fatalf("EndSleep() second call error = %v", err)
+	}
+	if !second.EndTime.Equal(firstEnd) {
+		t.Errorf("EndSleep() second call changed EndTime to %v, want %v", second.EndTime, firstEnd)
+	}
+	if second.EndedBy != "user-456" {
+		t.Errorf("EndSleep() EndedBy = %v, want user-456", second.EndedBy)
+	}
+	if len(notifier.events) != 1 {
+		t.Errorf("expected 1 event, got %d", len(notifier.events))
+	}
+}
+
+func TestService_EndSleep_NotMember(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, WithFamily(&mockFamilyService{}))
+
+	started, _ := svc.StartSleep(context.Background(), "user-123", "child-123", SleepTypeNap)
+
+	_, err := svc.EndSleep(context.Background(), "outsider", started.ID)
+	if !errors.Is(err, ErrNotMember) {
+		t.Errorf("EndSleep() error = %v, want %v", err, ErrNotMember)
+	}
+	if repo.sleeps[started.ID].EndTime != nil {
+		t.Error("EndSleep() should not end the sleep for a non-member")
+	}
+}
+
+func TestService_StartSleep_ChildNotFound(t *testing.T) {
+	svc := NewService(newMockRepository(), WithFamily(&mockFamilyService{}))
+
+	_, err := svc.StartSleep(context.Background(), "user-123", "child-999", SleepTypeNap)
+	if !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("StartSleep() error = %v, want %v", err, ErrChildNotFound)
+	}
+}
+
 func TestService_GetActiveSleep(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
 	// Start a sleep (no end time = active)
-	started, _ := svc.StartSleep(context.Background(), "child-123", SleepTypeNap)
+	started, _ := svc.StartSleep(context.Background(), "user-123", "child-123", SleepTypeNap)
 
 	// Get active sleep
-	active, err := svc.GetActiveSleep(context.Background(), "child-123")
+	active, err := svc.GetActiveSleep(context.Background(), "child-123", "")
 	if err != nil {
 		t.Fatalf("GetActiveSleep() error = %v", err)
 	}
@@ -463,7 +904,7 @@ func TestService_GetActiveSleep_NoActive(t *testing.T) {
 	svc.Create(context.Background(), req)
 
 	// Get active sleep
-	active, err := svc.GetActiveSleep(context.Background(), "child-123")
+	active, err := svc.GetActiveSleep(context.Background(), "child-123", "")
 	if err != nil {
 		t.Fatalf("GetActiveSleep() error = %v", err)
 	}
@@ -478,10 +919,10 @@ func TestService_GetActiveSleep_DifferentChild(t *testing.T) {
 	svc := NewService(repo)
 
 	// Start sleep for child-123
-	svc.StartSleep(context.Background(), "child-123", SleepTypeNap)
+	svc.StartSleep(context.Background(), "user-123", "child-123", SleepTypeNap)
 
 	// Get active sleep for child-456
-	active, err := svc.GetActiveSleep(context.Background(), "child-456")
+	active, err := svc.GetActiveSleep(context.Background(), "child-456", "")
 	if err != nil {
 		t.Fatalf("GetActiveSleep() error = %v", err)
 	}
@@ -516,3 +957,428 @@ func TestSleepTypes(t *testing.T) {
 		})
 	}
 }
+
+func sleepAt(id, source string, start time.Time, dur time.Duration, created time.Time) *Sleep {
+	end := start.Add(dur)
+	return &Sleep{ID: id, ChildID: "child-1", Type: SleepTypeNap, StartTime: start, EndTime: &end, Source: source, CreatedAt: created}
+}
+
+func TestService_ListConflicts(t *testing.T) {
+	repo := newMockRepository()
+	base := time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC)
+
+	// Monitor epochs and a manual log overlap; a later nap stands alone
+	repo.sleeps["epoch-1"] = sleepAt("epoch-1", "device:dev-1", base, 40*time.Minute, base)
+	repo.sleeps["epoch-2"] = sleepAt("epoch-2", "device:dev-1", base.Add(45*time.Minute), 30*time.Minute, base)
+	repo.sleeps["manual"] = sleepAt("manual", SourceManual, base.Add(5*time.Minute), time.Hour, base.Add(time.Hour))
+	repo.sleeps["later"] = sleepAt("later", SourceManual, base.Add(4*time.Hour), time.Hour, base)
+
+	svc := NewService(repo)
+	conflicts, err := svc.ListConflicts(context.Background(), "child-1", base.Add(-time.Hour), base.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("ListConflicts() error = %v", err)
+	}
+
+	if len(conflicts) != 1 {
+		t.Fatalf("ListConflicts() returned %d conflicts, want 1", len(conflicts))
+	}
+	if len(conflicts[0].Records) != 3 {
+		t.Errorf("ListConflicts() grouped %d records, want 3", len(conflicts[0].Records))
+	}
+	if conflicts[0].KeepID != "manual" {
+		t.Errorf("ListConflicts() KeepID = %s, want manual by default priority", conflicts[0].KeepID)
+	}
+}
+
+func TestService_Reconcile_DevicePriority(t *testing.T) {
+	repo := newMockRepository()
+	base := time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC)
+	quality := 4
+
+	repo.sleeps["epoch"] = sleepAt("epoch", "device:dev-1", base, time.Hour, base)
+	manual := sleepAt("manual", SourceManual, base.Add(10*time.Minute), time.Hour, base)
+	manual.Quality = &quality
+	manual.Notes = "Fussy going down"
+	repo.sleeps["manual"] = manual
+
+	svc := NewService(repo, WithSourcePriority([]string{"device", SourceManual}))
+	result, err := svc.Reconcile(context.Background(), "child-1", base.Add(-time.Hour), base.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if len(result.Removed) != 1 || result.Removed[0] != "manual" {
+		t.Errorf("Reconcile() removed %v, want [manual]", result.Removed)
+	}
+	kept := repo.sleeps["epoch"]
+	if !kept.StartTime.Equal(base) {
+		t.Errorf("Reconcile() should keep the device's times, got start %v", kept.StartTime)
+	}
+	if kept.Quality == nil || *kept.Quality != 4 || kept.Notes != "Fussy going down" {
+		t.Errorf("Reconcile() should carry over quality and notes, got %+v", kept)
+	}
+	if _, ok := repo.sleeps["manual"]; ok {
+		t.Error("Reconcile() should delete the merged record")
+	}
+}
+
+func TestService_Reconcile_NoConflicts(t *testing.T) {
+	repo := newMockRepository()
+	base := time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC)
+	repo.sleeps["a"] = sleepAt("a", SourceManual, base, time.Hour, base)
+	repo.sleeps["b"] = sleepAt("b", "device:dev-1", base.Add(time.Hour), time.Hour, base)
+
+	svc := NewService(repo)
+	result, err := svc.Reconcile(context.Background(), "child-1", base.Add(-time.Hour), base.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if len(result.Removed) != 0 || len(repo.sleeps) != 2 {
+		t.Errorf("Reconcile() should leave back-to-back sessions alone, got %+v", result)
+	}
+}
+
+// mockHistory records the actions written to the audit store
+type mockHistory struct {
+	audit.Store
+	actions []string
+}
+
+func (m *mockHistory) Record(ctx context.Context, entityType audit.EntityType, entityID, childID string, action audit.Action, data any) error {
+	m.actions = append(m.actions, string(action)+" "+entityID)
+	return nil
+}
+
+func TestService_Merge(t *testing.T) {
+	repo := newMockRepository()
+	history := &mockHistory{}
+	base := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+	quality := 3
+
+	// The night was logged as two sessions around a 20 minute waking
+	first := sleepAt("first", SourceManual, base, 3*time.Hour, base)
+	first.Type = SleepTypeNight
+	first.Notes = "Went down easily"
+	second := sleepAt("second", SourceManual, base.Add(3*time.Hour+20*time.Minute), 5*time.Hour, base)
+	second.Quality = &quality
+	second.Notes = "Woke once"
+	first.ChildID, second.ChildID = "child-123", "child-123"
+	repo.sleeps["first"], repo.sleeps["second"] = first, second
+
+	svc := NewService(repo, WithFamily(&mockFamilyService{}), WithHistory(history))
+	result, err := svc.Merge(context.Background(), "user-123", "second", "first")
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	if len(result.Sessions) != 1 || len(result.Removed) != 1 || result.Removed[0] != "second" {
+		t.Fatalf("Merge() = %+v", result)
+	}
+	merged := result.Sessions[0]
+	if merged.ID != "first" || merged.Type != SleepTypeNight || !merged.EndTime.Equal(base.Add(8*time.Hour+20*time.Minute)) {
+		t.Errorf("Merge() kept %+v", merged.Sleep)
+	}
+	if merged.Duration == nil || merged.Duration.Minutes() != 500 {
+		t.Errorf("Merge() Duration = %v, want 500 minutes", merged.Duration)
+	}
+	if merged.Quality == nil || *merged.Quality != 3 || merged.Notes != "Went down easily\nWoke once" {
+		t.Errorf("Merge() should carry over quality and notes, got %+v", merged.Sleep)
+	}
+	if _, ok := repo.sleeps["second"]; ok {
+		t.Error("Merge() should delete the later session")
+	}
+	if len(merged.Wakings) != 1 || !merged.Wakings[0].StartTime.Equal(base.Add(3*time.Hour)) {
+		t.Errorf("Merge() should log the time awake as a waking, got %+v", merged.Wakings)
+	}
+	if want := []string{"update first", "delete second"}; !slices.Equal(history.actions, want) {
+		t.Errorf("Merge() audit = %v, want %v", history.actions, want)
+	}
+}
+
+// recordingTx counts units of work and reports the error its function
+// returned. Nested calls join the outer unit, as db.NewTxManager's do.
+type recordingTx struct {
+	calls int
+	err   error
+}
+
+type inTxKey struct{}
+
+func (r *recordingTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(inTxKey{}) != nil {
+		return fn(ctx)
+	}
+	r.calls++
+	r.err = fn(context.WithValue(ctx, inTxKey{}, true))
+	return r.err
+}
+
+func TestService_Merge_SingleUnitOfWork(t *testing.T) {
+	repo := newMockRepository()
+	repo.deleteErr = errors.New("database error")
+	base := time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC)
+	repo.sleeps["a"] = sleepAt("a", SourceManual, base, time.Hour, base)
+	repo.sleeps["b"] = sleepAt("b", SourceManual, base.Add(70*time.Minute), time.Hour, base)
+	repo.sleeps["a"].ChildID, repo.sleeps["b"].ChildID = "child-123", "child-123"
+
+	tx := &recordingTx{}
+	svc := NewService(repo, WithFamily(&mockFamilyService{}), WithTombstones(&mockTombstones{deleted: make(map[string]string)}, tx))
+	if _, err := svc.Merge(context.Background(), "user-123", "a", "b"); err == nil {
+		t.Fatal("Merge() should return error when the delete fails")
+	}
+	if tx.calls != 1 || tx.err == nil {
+		t.Errorf("Expected the merge to fail inside one transaction, got %d calls (err %v)", tx.calls, tx.err)
+	}
+}
+
+func TestService_Merge_NotAdjacent(t *testing.T) {
+	base := time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		sleeps []*Sleep
+	}{
+		{"gap too long", []*Sleep{
+			sleepAt("a", SourceManual, base, time.Hour, base),
+			sleepAt("b", SourceManual, base.Add(3*time.Hour), time.Hour, base),
+		}},
+		{"session between", []*Sleep{
+			sleepAt("a", SourceManual, base, time.Hour, base),
+			sleepAt("between", SourceManual, base.Add(70*time.Minute), 10*time.Minute, base),
+			sleepAt("b", SourceManual, base.Add(90*time.Minute), time.Hour, base),
+		}},
+		{"earlier still running", []*Sleep{
+			{ID: "a", ChildID: "child-1", StartTime: base},
+			sleepAt("b", SourceManual, base.Add(time.Hour), time.Hour, base),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			for _, sl := range tt.sleeps {
+				repo.sleeps[sl.ID] = sl
+			}
+
+			svc := NewService(repo)
+			if _, err := svc.Merge(context.Background(), "user-123", "a", "b"); !errors.Is(err, ErrNotAdjacent) {
+				t.Errorf("Merge() error = %v, want ErrNotAdjacent", err)
+			}
+			if _, ok := repo.sleeps["b"]; !ok {
+				t.Error("Merge() should leave both sessions")
+			}
+		})
+	}
+}
+
+func TestService_Split(t *testing.T) {
+	repo := newMockRepository()
+	history := &mockHistory{}
+	base := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+
+	night := sleepAt("night", SourceManual, base, 10*time.Hour, base)
+	night.Type = SleepTypeNight
+	night.Notes = "Teething"
+	night.EndedBy = "user-456"
+	night.ChildID = "child-123"
+	repo.sleeps["night"] = night
+
+	svc := NewService(repo, WithFamily(&mockFamilyService{}), WithHistory(history))
+	result, err := svc.Split(context.Background(), "user-123", "night", base.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+
+	if len(result.Sessions) != 2 {
+		t.Fatalf("Split() = %+v", result)
+	}
+	first, second := result.Sessions[0], result.Sessions[1]
+	if first.ID != "night" || first.Duration.Minutes() != 240 || first.Notes != "Teething" {
+		t.Errorf("Split() first = %+v", first)
+	}
+	if second.ID == "night" || second.Type != SleepTypeNight || second.Duration.Minutes() != 360 || second.EndedBy != "user-456" {
+		t.Errorf("Split() second = %+v", second)
+	}
+	if len(repo.sleeps) != 2 {
+		t.Errorf("Split() should store both sessions, got %d", len(repo.sleeps))
+	}
+	if want := []string{"update night", "create " + second.ID}; !slices.Equal(history.actions, want) {
+		t.Errorf("Split() audit = %v, want %v", history.actions, want)
+	}
+}
+
+func TestService_Split_SingleUnitOfWork(t *testing.T) {
+	repo := newMockRepository()
+	repo.createErr = errors.New("database error")
+	base := time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC)
+	repo.sleeps["nap"] = sleepAt("nap", SourceManual, base, time.Hour, base)
+	repo.sleeps["nap"].ChildID = "child-123"
+
+	tx := &recordingTx{}
+	svc := NewService(repo, WithFamily(&mockFamilyService{}), WithTombstones(&mockTombstones{deleted: make(map[string]string)}, tx))
+	if _, err := svc.Split(context.Background(), "user-123", "nap", base.Add(30*time.Minute)); err == nil {
+		t.Fatal("Split() should return error when the insert fails")
+	}
+	if tx.calls != 1 || tx.err == nil {
+		t.Errorf("Expected the split to fail inside one transaction, got %d calls (err %v)", tx.calls, tx.err)
+	}
+}
+
+func TestService_Split_Errors(t *testing.T) {
+	repo := newMockRepository()
+	base := time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC)
+	repo.sleeps["nap"] = sleepAt("nap", SourceManual, base, time.Hour, base)
+	repo.sleeps["nap"].ChildID = "child-123"
+	repo.sleeps["other-child"] = &Sleep{ID: "other-child", ChildID: "child-999", StartTime: base}
+
+	svc := NewService(repo, WithFamily(&mockFamilyService{}))
+
+	tests := []struct {
+		name   string
+		userID string
+		id     string
+		at     time.Time
+		want   error
+	}{
+		{"at start", "user-123", "nap", base, ErrInvalidSplit},
+		{"after end", "user-123", "nap", base.Add(2 * time.Hour), ErrInvalidSplit},
+		{"not found", "user-123", "missing", base, ErrNotFound},
+		{"not member", "stranger", "nap", base.Add(time.Minute), ErrNotMember},
+		{"child not found", "user-123", "other-child", base.Add(time.Minute), ErrChildNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Split(context.Background(), tt.userID, tt.id, tt.at); !errors.Is(err, tt.want) {
+				t.Errorf("Split() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSleep_AsleepDuration(t *testing.T) {
+	base := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+	back := base.Add(4*time.Hour + 30*time.Minute)
+	night := sleepAt("night", SourceManual, base, 10*time.Hour, base)
+	night.Wakings = []Waking{
+		{StartTime: base.Add(4 * time.Hour), EndTime: &back},
+		{StartTime: base.Add(9 * time.Hour)}, // still open, so not counted
+	}
+
+	d, ok := night.AsleepDuration()
+	if !ok || d.Minutes() != 570 {
+		t.Errorf("AsleepDuration() = %v, %v; want 570 minutes", d.Minutes(), ok)
+	}
+}
+
+func TestService_Wakings(t *testing.T) {
+	repo := newMockRepository()
+	history := &mockHistory{}
+	svc := NewService(repo, WithFamily(&mockFamilyService{}), WithHistory(history))
+	ctx := context.Background()
+
+	started, err := svc.StartSleep(ctx, "user-123", "child-123", SleepTypeNight)
+	if err != nil {
+		t.Fatalf("StartSleep() error = %v", err)
+	}
+
+	withWaking, err := svc.AddWaking(ctx, "user-456", started.ID, &WakingRequest{Reason: WakingHungry})
+	if err != nil {
+		t.Fatalf("AddWaking() error = %v", err)
+	}
+	if len(withWaking.Wakings) != 1 || withWaking.Wakings[0].Reason != WakingHungry || withWaking.Wakings[0].CreatedBy != "user-456" {
+		t.Fatalf("AddWaking() = %+v", withWaking.Wakings)
+	}
+	wakingID := withWaking.Wakings[0].ID
+
+	ended, err := svc.EndWaking(ctx, "user-123", started.ID, wakingID)
+	if err != nil {
+		t.Fatalf("EndWaking() error = %v", err)
+	}
+	if ended.Wakings[0].EndTime == nil {
+		t.Error("EndWaking() should set the end time")
+	}
+
+	active, err := svc.GetActiveSleep(ctx, "child-123", "")
+	if err != nil || len(active.Wakings) != 1 {
+		t.Errorf("GetActiveSleep() = %+v, %v; want the waking attached", active, err)
+	}
+
+	removed, err := svc.RemoveWaking(ctx, "user-123", started.ID, wakingID)
+	if err != nil {
+		t.Fatalf("RemoveWaking() error = %v", err)
+	}
+	if len(removed.Wakings) != 0 || len(repo.wakings) != 0 {
+		t.Errorf("RemoveWaking() left %+v", removed.Wakings)
+	}
+	if len(history.actions) != 4 {
+		t.Errorf("each change should be kept in the history, got %v", history.actions)
+	}
+}
+
+func TestService_EndSleep_EndsOpenWakings(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, WithFamily(&mockFamilyService{}))
+	ctx := context.Background()
+
+	started, _ := svc.StartSleep(ctx, "user-123", "child-123", SleepTypeNap)
+	if _, err := svc.AddWaking(ctx, "user-123", started.ID, &WakingRequest{}); err != nil {
+		t.Fatalf("AddWaking() error = %v", err)
+	}
+
+	ended, err := svc.EndSleep(ctx, "user-123", started.ID)
+	if err != nil {
+		t.Fatalf("EndSleep() error = %v", err)
+	}
+	if len(ended.Wakings) != 1 || ended.Wakings[0].EndTime == nil || !ended.Wakings[0].EndTime.Equal(*ended.EndTime) {
+		t.Errorf("EndSleep() should end the open waking with the session, got %+v", ended.Wakings)
+	}
+}
+
+func TestService_Wakings_Errors(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, WithFamily(&mockFamilyService{}))
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour)
+	repo.sleeps["active"] = &Sleep{ID: "active", ChildID: "child-123", StartTime: base}
+	repo.sleeps["ended"] = sleepAt("ended", SourceManual, base, 30*time.Minute, base)
+	repo.sleeps["ended"].ChildID = "child-123"
+	future := time.Now().Add(time.Hour)
+	before := base.Add(-time.Minute)
+
+	tests := []struct {
+		name string
+		fn   func() error
+		want error
+	}{
+		{"session ended", func() error {
+			_, err := svc.AddWaking(ctx, "user-123", "ended", &WakingRequest{})
+			return err
+		}, ErrSessionEnded},
+		{"before session", func() error {
+			_, err := svc.AddWaking(ctx, "user-123", "active", &WakingRequest{StartTime: &before})
+			return err
+		}, ErrInvalidWaking},
+		{"ends in future", func() error {
+			_, err := svc.AddWaking(ctx, "user-123", "active", &WakingRequest{EndTime: &future})
+			return err
+		}, ErrInvalidWaking},
+		{"not member", func() error {
+			_, err := svc.AddWaking(ctx, "stranger", "active", &WakingRequest{})
+			return err
+		}, ErrNotMember},
+		{"waking not found", func() error {
+			_, err := svc.RemoveWaking(ctx, "user-123", "active", "missing")
+			return err
+		}, ErrWakingNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn(); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package sleep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/notifications"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrNotFound      = errors.New("sleep not found")
+	ErrChildNotFound = errors.New("child not found")
+	ErrNotMember     = errors.New("user is not a member of this family")
+)
+
+// Notifier tells the device that started a timer when someone else stops it
+type Notifier interface {
+	SendToUsers(userIDs []string, event notifications.Event)
+}
+
+// WithFamily restricts starting and ending timers to members of the child's
+// family. Any member may end a timer, whoever started it.
+func WithFamily(familyService family.Service) Option {
+	return func(s *service) {
+		s.familyService = familyService
+	}
+}
+
+// WithNotifier sends a sleep_ended event to the member who started a timer
+// when another member ends it
+func WithNotifier(n Notifier) Option {
+	return func(s *service) {
+		s.notifier = n
+	}
+}
+
+// authorize checks the user belongs to the child's family, and that its sleep
+// is not hidden from them. Without a family or visibility service every
+// caller is allowed.
+func (s *service) authorize(ctx context.Context, userID, childID string) error {
+	if s.visibility != nil {
+		return s.visibility.CheckChild(ctx, userID, childID, visibility.RecordSleep)
+	}
+	if s.familyService == nil {
+		return nil
+	}
+
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return ErrChildNotFound
+	}
+
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return fmt.Errorf("failed to check membership: %w", err)
+	}
+	return nil
+}
+
+// notifyEnded lets the originating device stop its local timer
+func (s *service) notifyEnded(sl *Sleep) {
+	if s.notifier == nil || sl.StartedBy == "" || sl.StartedBy == sl.EndedBy {
+		return
+	}
+
+	s.notifier.SendToUsers([]string{sl.StartedBy}, notifications.Event{
+		ID:        ids.New(),
+		Type:      notifications.EventSleepEnded,
+		Title:     "Sleep timer stopped",
+		Message:   "A sleep timer you started was ended on another device",
+		ChildID:   sl.ChildID,
+		Timestamp: time.Now(),
+		Data:      sl,
+	})
+}
@@ -0,0 +1,33 @@
+package sleep
+
+import (
+	"context"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+)
+
+// WithTombstones remembers deleted sleep records so that sync pulls can
+// report them. tx deletes a record and records its tombstone atomically;
+// the repository must be built on the same database.
+func WithTombstones(tombstones delta.Tombstones, tx db.TxManager) Option {
+	return func(s *service) {
+		s.tombstones = tombstones
+		s.tx = tx
+	}
+}
+
+// deleteRecord deletes the sleep record and leaves a tombstone for it in the
+// same transaction
+func (s *service) deleteRecord(ctx context.Context, sl *Sleep) error {
+	if s.tombstones == nil {
+		return s.repo.Delete(ctx, sl.ID)
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, sl.ID); err != nil {
+			return err
+		}
+		return s.tombstones.Record(ctx, delta.EntitySleep, sl.ID, sl.ChildID, time.Now())
+	})
+}
@@ -0,0 +1,24 @@
+package sleep
+
+import (
+	"context"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// WithVisibility applies family visibility settings, so members cannot see or
+// change sleep hidden from them
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+// viewer filters a list for the filter's viewer, if it has one
+func (s *service) viewer(ctx context.Context, filter *SleepFilter) (*visibility.Viewer, error) {
+	return visibility.ViewerFor(ctx, s.visibility, filter.ViewerID, filter.ChildID, visibility.RecordSleep)
+}
+
+func childOf(sl Sleep) string {
+	return sl.ChildID
+}
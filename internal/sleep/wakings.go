@@ -0,0 +1,155 @@
+package sleep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/ids"
+)
+
+var (
+	ErrWakingNotFound = errors.New("waking not found")
+	ErrSessionEnded   = errors.New("sleep session has already ended")
+	ErrInvalidWaking  = errors.New("waking must fall between the start of the session and now")
+)
+
+// AddWaking logs a night waking on a running session
+func (s *service) AddWaking(ctx context.Context, userID, sleepID string, req *WakingRequest) (*Sleep, error) {
+	session, err := s.getActiveForWaking(ctx, userID, sleepID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	start := now
+	if req.StartTime != nil {
+		start = *req.StartTime
+	}
+	if start.Before(session.StartTime) || start.After(now) {
+		return nil, ErrInvalidWaking
+	}
+	if req.EndTime != nil && (req.EndTime.Before(start) || req.EndTime.After(now)) {
+		return nil, ErrInvalidWaking
+	}
+
+	waking := &Waking{
+		ID:        ids.New(),
+		SleepID:   session.ID,
+		StartTime: start,
+		EndTime:   req.EndTime,
+		Reason:    req.Reason,
+		CreatedBy: userID,
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateWaking(ctx, waking); err != nil {
+		return nil, fmt.Errorf("failed to create waking: %w", err)
+	}
+
+	return s.wakingsChanged(ctx, session)
+}
+
+// EndWaking marks the child as back asleep. Ending a waking that has already
+// ended leaves it unchanged.
+func (s *service) EndWaking(ctx context.Context, userID, sleepID, wakingID string) (*Sleep, error) {
+	session, err := s.getActiveForWaking(ctx, userID, sleepID)
+	if err != nil {
+		return nil, err
+	}
+	waking, err := findWaking(session, wakingID)
+	if err != nil {
+		return nil, err
+	}
+	if waking.EndTime != nil {
+		return session, nil
+	}
+
+	if err := s.repo.EndWaking(ctx, wakingID, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to end waking: %w", err)
+	}
+
+	return s.wakingsChanged(ctx, session)
+}
+
+func (s *service) RemoveWaking(ctx context.Context, userID, sleepID, wakingID string) (*Sleep, error) {
+	session, err := s.getActiveForWaking(ctx, userID, sleepID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := findWaking(session, wakingID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.DeleteWaking(ctx, wakingID); err != nil {
+		return nil, fmt.Errorf("failed to delete waking: %w", err)
+	}
+
+	return s.wakingsChanged(ctx, session)
+}
+
+// getActiveForWaking loads a session the user may log wakings on. Wakings
+// are only added or removed while the session is running.
+func (s *service) getActiveForWaking(ctx context.Context, userID, sleepID string) (*Sleep, error) {
+	session, err := s.getForEdit(ctx, userID, sleepID)
+	if err != nil {
+		return nil, err
+	}
+	if session.EndTime != nil {
+		return nil, ErrSessionEnded
+	}
+	return s.withWakings(ctx, session)
+}
+
+// wakingsChanged reloads the session's wakings and keeps the new version in
+// the record history
+func (s *service) wakingsChanged(ctx context.Context, session *Sleep) (*Sleep, error) {
+	updated, err := s.withWakings(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	s.recordVersion(ctx, updated, audit.ActionUpdate)
+	return updated, nil
+}
+
+// withWakings returns a copy of the session with its wakings filled in
+func (s *service) withWakings(ctx context.Context, session *Sleep) (*Sleep, error) {
+	if session == nil {
+		return nil, nil
+	}
+	sessions := []Sleep{*session}
+	if err := s.attachWakings(ctx, sessions); err != nil {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
+
+// attachWakings fills in the wakings of each session with a single query
+func (s *service) attachWakings(ctx context.Context, sessions []Sleep) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	sleepIDs := make([]string, len(sessions))
+	for i := range sessions {
+		sleepIDs[i] = sessions[i].ID
+	}
+
+	wakings, err := s.repo.ListWakings(ctx, sleepIDs)
+	if err != nil {
+		return fmt.Errorf("failed to list wakings: %w", err)
+	}
+	for i := range sessions {
+		sessions[i].Wakings = wakings[sessions[i].ID]
+	}
+	return nil
+}
+
+func findWaking(session *Sleep, wakingID string) (*Waking, error) {
+	for i := range session.Wakings {
+		if session.Wakings[i].ID == wakingID {
+			return &session.Wakings[i], nil
+		}
+	}
+	return nil, ErrWakingNotFound
+}
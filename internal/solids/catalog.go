@@ -0,0 +1,51 @@
+package solids
+
+// CatalogFood is a common first food offered when listing what to try next
+type CatalogFood struct {
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Allergen Allergen `json:"allergen,omitempty"`
+}
+
+// Catalog holds common first foods, including at least one source of each
+// major allergen so the not-tried list covers the checklist
+var Catalog = []CatalogFood{
+	{Name: "avocado", Category: "fruit"},
+	{Name: "banana", Category: "fruit"},
+	{Name: "apple", Category: "fruit"},
+	{Name: "pear", Category: "fruit"},
+	{Name: "peach", Category: "fruit"},
+	{Name: "blueberries", Category: "fruit"},
+	{Name: "mango", Category: "fruit"},
+	{Name: "sweet potato", Category: "vegetable"},
+	{Name: "carrot", Category: "vegetable"},
+	{Name: "butternut squash", Category: "vegetable"},
+	{Name: "peas", Category: "vegetable"},
+	{Name: "green beans", Category: "vegetable"},
+	{Name: "broccoli", Category: "vegetable"},
+	{Name: "spinach", Category: "vegetable"},
+	{Name: "oatmeal", Category: "grain"},
+	{Name: "rice cereal", Category: "grain"},
+	{Name: "wheat toast", Category: "grain", Allergen: AllergenWheat},
+	{Name: "pasta", Category: "grain", Allergen: AllergenWheat},
+	{Name: "chicken", Category: "protein"},
+	{Name: "beef", Category: "protein"},
+	{Name: "lentils", Category: "protein"},
+	{Name: "egg", Category: "protein", Allergen: AllergenEgg},
+	{Name: "salmon", Category: "protein", Allergen: AllergenFish},
+	{Name: "shrimp", Category: "protein", Allergen: AllergenShellfish},
+	{Name: "tofu", Category: "protein", Allergen: AllergenSoy},
+	{Name: "peanut butter", Category: "protein", Allergen: AllergenPeanut},
+	{Name: "almond butter", Category: "protein", Allergen: AllergenTreeNuts},
+	{Name: "tahini", Category: "protein", Allergen: AllergenSesame},
+	{Name: "yogurt", Category: "dairy", Allergen: AllergenMilk},
+	{Name: "cheese", Category: "dairy", Allergen: AllergenMilk},
+}
+
+var catalogByName = func() map[string]CatalogFood {
+	m := make(map[string]CatalogFood, len(Catalog))
+	for _, f := range Catalog {
+		m[f.Name] = f
+	}
+	return m
+}()
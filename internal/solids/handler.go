@@ -0,0 +1,90 @@
+package solids
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterChildRoutes registers food logs, the food list and the allergen
+// checklist on the children group
+func (h *Handler) RegisterChildRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id/foods", h.foods)
+	rg.GET("/:id/food-logs", h.listLogs)
+	rg.POST("/:id/food-logs", h.logFood)
+	rg.DELETE("/:id/food-logs/:logId", h.deleteLog)
+	rg.GET("/:id/allergens", h.allergens)
+}
+
+func (h *Handler) foods(c *gin.Context) {
+	list, err := h.service.Foods(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *Handler) listLogs(c *gin.Context) {
+	logs, err := h.service.ListLogs(c.Request.Context(), c.GetString("user_id"), c.Param("id"), c.Query("food"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, logs)
+}
+
+func (h *Handler) logFood(c *gin.Context) {
+	var req LogFoodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	l, err := h.service.LogFood(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, l)
+}
+
+func (h *Handler) deleteLog(c *gin.Context) {
+	if err := h.service.DeleteLog(c.Request.Context(), c.GetString("user_id"), c.Param("id"), c.Param("logId")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) allergens(c *gin.Context) {
+	checklist, err := h.service.Allergens(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, checklist)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrChildNotFound), errors.Is(err, ErrLogNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidFood), errors.Is(err, ErrInvalidReaction), errors.Is(err, ErrInvalidSeverity),
+		errors.Is(err, ErrInvalidAllergen), errors.Is(err, ErrFutureTime):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
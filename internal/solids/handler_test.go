@@ -0,0 +1,171 @@
+package solids
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	logFoodFn   func(ctx context.Context, userID, childID string, req *LogFoodRequest) (*FoodLog, error)
+	listLogsFn  func(ctx context.Context, userID, childID, food string) ([]FoodLog, error)
+	deleteLogFn func(ctx context.Context, userID, childID, id string) error
+	foodsFn     func(ctx context.Context, userID, childID string) (*FoodList, error)
+	allergensFn func(ctx context.Context, userID, childID string) (*AllergenChecklist, error)
+}
+
+func (m *mockService) LogFood(ctx context.Context, userID, childID string, req *LogFoodRequest) (*FoodLog, error) {
+	return m.logFoodFn(ctx, userID, childID, req)
+}
+
+func (m *mockService) ListLogs(ctx context.Context, userID, childID, food string) ([]FoodLog, error) {
+	return m.listLogsFn(ctx, userID, childID, food)
+}
+
+func (m *mockService) DeleteLog(ctx context.Context, userID, childID, id string) error {
+	return m.deleteLogFn(ctx, userID, childID, id)
+}
+
+func (m *mockService) Foods(ctx context.Context, userID, childID string) (*FoodList, error) {
+	return m.foodsFn(ctx, userID, childID)
+}
+
+func (m *mockService) Allergens(ctx context.Context, userID, childID string) (*AllergenChecklist, error) {
+	return m.allergensFn(ctx, userID, childID)
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterChildRoutes(router.Group("/children"))
+	return router
+}
+
+func TestHandler_Foods(t *testing.T) {
+	svc := &mockService{foodsFn: func(ctx context.Context, userID, childID string) (*FoodList, error) {
+		if userID != "test-user-123" || childID != "child-1" {
+			t.Errorf("Foods(%q, %q)", userID, childID)
+		}
+		return &FoodList{
+			Tried:    []FoodSummary{{Food: "banana", Times: 2}},
+			NotTried: []CatalogFood{{Name: "egg", Category: "protein", Allergen: AllergenEgg}},
+		}, nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/children/child-1/foods", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var got FoodList
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got.Tried) != 1 || len(got.NotTried) != 1 || got.NotTried[0].Allergen != AllergenEgg {
+		t.Errorf("body = %+v", got)
+	}
+}
+
+func TestHandler_LogFood(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"created", `{"food":"egg","reaction":"rash","severity":"mild"}`, nil, http.StatusCreated},
+		{"missing reaction", `{"food":"egg"}`, nil, http.StatusBadRequest},
+		{"invalid severity", `{"food":"egg","reaction":"none","severity":"mild"}`, ErrInvalidSeverity, http.StatusBadRequest},
+		{"not a member", `{"food":"egg","reaction":"none"}`, ErrNotMember, http.StatusForbidden},
+		{"unknown child", `{"food":"egg","reaction":"none"}`, ErrChildNotFound, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{logFoodFn: func(ctx context.Context, userID, childID string, req *LogFoodRequest) (*FoodLog, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &FoodLog{ID: "log-1", ChildID: childID, Food: req.Food, Reaction: req.Reaction}, nil
+			}}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/children/child-1/food-logs", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			setupRouter(svc).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_ListLogs(t *testing.T) {
+	svc := &mockService{listLogsFn: func(ctx context.Context, userID, childID, food string) ([]FoodLog, error) {
+		if food != "peanut butter" {
+			t.Errorf("ListLogs() food = %q", food)
+		}
+		return []FoodLog{{ID: "log-1", Food: food}}, nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/children/child-1/food-logs?food=peanut+butter", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandler_DeleteLog(t *testing.T) {
+	svc := &mockService{deleteLogFn: func(ctx context.Context, userID, childID, id string) error {
+		if childID != "child-1" || id != "log-9" {
+			t.Errorf("DeleteLog(%q, %q)", childID, id)
+		}
+		return ErrLogNotFound
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/children/child-1/food-logs/log-9", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandler_Allergens(t *testing.T) {
+	svc := &mockService{allergensFn: func(ctx context.Context, userID, childID string) (*AllergenChecklist, error) {
+		return &AllergenChecklist{ChildID: childID, Total: 9, Introduced: 1,
+			Allergens: []AllergenEntry{{Allergen: AllergenMilk, Status: AllergenIntroduced}}}, nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/children/child-1/allergens", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var got AllergenChecklist
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Introduced != 1 || got.Allergens[0].Status != AllergenIntroduced {
+		t.Errorf("body = %+v", got)
+	}
+}
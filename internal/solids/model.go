@@ -0,0 +1,138 @@
+package solids
+
+import "time"
+
+// Reaction is what was seen after the child ate a food
+type Reaction string
+
+const (
+	ReactionNone     Reaction = "none"
+	ReactionRash     Reaction = "rash"
+	ReactionHives    Reaction = "hives"
+	ReactionVomiting Reaction = "vomiting"
+	ReactionDiarrhea Reaction = "diarrhea"
+	ReactionSwelling Reaction = "swelling"
+	ReactionOther    Reaction = "other"
+)
+
+var validReactions = map[Reaction]bool{
+	ReactionNone:     true,
+	ReactionRash:     true,
+	ReactionHives:    true,
+	ReactionVomiting: true,
+	ReactionDiarrhea: true,
+	ReactionSwelling: true,
+	ReactionOther:    true,
+}
+
+type Severity string
+
+const (
+	SeverityMild     Severity = "mild"
+	SeverityModerate Severity = "moderate"
+	SeveritySevere   Severity = "severe"
+)
+
+var validSeverities = map[Severity]bool{
+	SeverityMild:     true,
+	SeverityModerate: true,
+	SeveritySevere:   true,
+}
+
+// Allergen is one of the nine major food allergens
+type Allergen string
+
+const (
+	AllergenMilk      Allergen = "milk"
+	AllergenEgg       Allergen = "egg"
+	AllergenPeanut    Allergen = "peanut"
+	AllergenTreeNuts  Allergen = "tree_nuts"
+	AllergenSoy       Allergen = "soy"
+	AllergenWheat     Allergen = "wheat"
+	AllergenFish      Allergen = "fish"
+	AllergenShellfish Allergen = "shellfish"
+	AllergenSesame    Allergen = "sesame"
+)
+
+// Allergens lists the major allergens in checklist order
+var Allergens = []Allergen{
+	AllergenMilk, AllergenEgg, AllergenPeanut, AllergenTreeNuts, AllergenSoy,
+	AllergenWheat, AllergenFish, AllergenShellfish, AllergenSesame,
+}
+
+var validAllergens = func() map[Allergen]bool {
+	m := make(map[Allergen]bool, len(Allergens))
+	for _, a := range Allergens {
+		m[a] = true
+	}
+	return m
+}()
+
+// FoodLog records one time a child tried a food and how they reacted
+type FoodLog struct {
+	ID       string    `json:"id"`
+	ChildID  string    `json:"child_id"`
+	Food     string    `json:"food"` // Trimmed and lower-cased
+	Allergen Allergen  `json:"allergen,omitempty"`
+	TriedAt  time.Time `json:"tried_at"`
+	Reaction Reaction  `json:"reaction"`
+	// Severity is only set when there was a reaction
+	Severity  Severity  `json:"severity,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+	LoggedBy  string    `json:"logged_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LogFoodRequest struct {
+	Food string `json:"food" binding:"required"`
+	// Allergen defaults to the catalog's entry for the food, if it has one
+	Allergen Allergen   `json:"allergen"`
+	TriedAt  *time.Time `json:"tried_at"`
+	Reaction Reaction   `json:"reaction" binding:"required"`
+	Severity Severity   `json:"severity"`
+	Notes    string     `json:"notes"`
+}
+
+// FoodSummary is everything logged for one food
+type FoodSummary struct {
+	Food         string    `json:"food"`
+	Category     string    `json:"category,omitempty"`
+	Allergen     Allergen  `json:"allergen,omitempty"`
+	Times        int       `json:"times"`
+	FirstTriedAt time.Time `json:"first_tried_at"`
+	LastTriedAt  time.Time `json:"last_tried_at"`
+	// Reacted is set when any time the food was tried brought a reaction
+	Reacted      bool     `json:"reacted"`
+	LastReaction Reaction `json:"last_reaction"`
+}
+
+// FoodList splits the catalog into foods the child has tried and those still
+// to try. Tried also includes foods that are not in the catalog.
+type FoodList struct {
+	Tried    []FoodSummary `json:"tried"`
+	NotTried []CatalogFood `json:"not_tried"`
+}
+
+type AllergenStatus string
+
+const (
+	AllergenNotIntroduced AllergenStatus = "not_introduced"
+	AllergenIntroduced    AllergenStatus = "introduced"
+	AllergenReacted       AllergenStatus = "reacted"
+)
+
+type AllergenEntry struct {
+	Allergen     Allergen       `json:"allergen"`
+	Status       AllergenStatus `json:"status"`
+	Times        int            `json:"times"`
+	FirstTriedAt *time.Time     `json:"first_tried_at,omitempty"`
+	Foods        []string       `json:"foods"`
+}
+
+// AllergenChecklist has one entry per major allergen, in Allergens order
+type AllergenChecklist struct {
+	ChildID    string          `json:"child_id"`
+	Introduced int             `json:"introduced"`
+	Total      int             `json:"total"`
+	Allergens  []AllergenEntry `json:"allergens"`
+}
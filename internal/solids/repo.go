@@ -0,0 +1,104 @@
+package solids
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	Create(ctx context.Context, l *FoodLog) error
+	GetByID(ctx context.Context, id string) (*FoodLog, error)
+	// ListByChild returns the child's logs, oldest first, for one food unless
+	// food is empty
+	ListByChild(ctx context.Context, childID, food string) ([]FoodLog, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the food log table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "food_logs")
+}
+
+const logColumns = `id, child_id, food, allergen, tried_at, reaction, severity, notes, COALESCE(logged_by, ''), created_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanLog(row scanner) (*FoodLog, error) {
+	var l FoodLog
+	if err := row.Scan(
+		&l.ID, &l.ChildID, &l.Food, &l.Allergen, &l.TriedAt, &l.Reaction, &l.Severity, &l.Notes, &l.LoggedBy, &l.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *repository) Create(ctx context.Context, l *FoodLog) error {
+	query := `
+		INSERT INTO food_logs (id, child_id, food, allergen, tried_at, reaction, severity, notes, logged_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	var loggedBy *string
+	if l.LoggedBy != "" {
+		loggedBy = &l.LoggedBy
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		l.ID, l.ChildID, l.Food, l.Allergen, l.TriedAt, l.Reaction, l.Severity, l.Notes, loggedBy, l.CreatedAt,
+	)
+	return err
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*FoodLog, error) {
+	l, err := scanLog(r.db.QueryRowContext(ctx, `SELECT `+logColumns+` FROM food_logs WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return l, err
+}
+
+func (r *repository) ListByChild(ctx context.Context, childID, food string) ([]FoodLog, error) {
+	query := `SELECT ` + logColumns + ` FROM food_logs WHERE child_id = $1`
+	args := []any{childID}
+	if food != "" {
+		query += ` AND food = $2`
+		args = append(args, food)
+	}
+	query += ` ORDER BY tried_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	logs := []FoodLog{}
+	for rows.Next() {
+		l, err := scanLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *l)
+	}
+
+	return logs, rows.Err()
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM food_logs WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,79 @@
+package solids
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var logRowColumns = []string{"id", "child_id", "food", "allergen", "tried_at", "reaction", "severity", "notes", "logged_by", "created_at"}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	l := &FoodLog{ID: "log-1", ChildID: "child-1", Food: "egg", Allergen: AllergenEgg, TriedAt: now, Reaction: ReactionRash, Severity: SeverityMild, CreatedAt: now}
+	mock.ExpectExec("INSERT INTO food_logs").
+		WithArgs("log-1", "child-1", "egg", AllergenEgg, now, ReactionRash, SeverityMild, "", nil, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), l); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListByChild(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, child_id, food, .* FROM food_logs WHERE child_id = \\$1 AND food = \\$2 ORDER BY tried_at ASC").
+		WithArgs("child-1", "peanut butter").
+		WillReturnRows(sqlmock.NewRows(logRowColumns).
+			AddRow("log-1", "child-1", "peanut butter", "peanut", now.Add(-time.Hour), "none", "", "", "user-1", now).
+			AddRow("log-2", "child-1", "peanut butter", "peanut", now, "hives", "moderate", "On the cheeks", "", now))
+
+	logs, err := repo.ListByChild(context.Background(), "child-1", "peanut butter")
+	if err != nil {
+		t.Fatalf("ListByChild() error = %v", err)
+	}
+	if len(logs) != 2 || logs[0].LoggedBy != "user-1" || logs[1].Reaction != ReactionHives || logs[1].Severity != SeverityModerate {
+		t.Errorf("ListByChild() = %+v", logs)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT .* FROM food_logs WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(logRowColumns))
+
+	l, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || l != nil {
+		t.Errorf("GetByID() = %v, %v; want nil, nil", l, err)
+	}
+}
@@ -0,0 +1,252 @@
+// Package solids tracks a child's introduction to solid food: each food tried,
+// any reaction it brought, and progress through the major allergens.
+package solids
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+)
+
+// maxFoodLength matches the food_logs.food column
+const maxFoodLength = 100
+
+var (
+	ErrChildNotFound   = errors.New("child not found")
+	ErrLogNotFound     = errors.New("food log not found")
+	ErrNotMember       = errors.New("user is not a member of this family")
+	ErrInvalidFood     = errors.New("food must be between 1 and 100 characters")
+	ErrInvalidReaction = errors.New("reaction must be one of none, rash, hives, vomiting, diarrhea, swelling or other")
+	ErrInvalidSeverity = errors.New("severity must be mild, moderate or severe, and is only set with a reaction")
+	ErrInvalidAllergen = errors.New("allergen must be one of milk, egg, peanut, tree_nuts, soy, wheat, fish, shellfish or sesame")
+	ErrFutureTime      = errors.New("tried_at cannot be in the future")
+)
+
+type Service interface {
+	LogFood(ctx context.Context, userID, childID string, req *LogFoodRequest) (*FoodLog, error)
+	// ListLogs returns the child's logs, oldest first, for one food unless
+	// food is empty
+	ListLogs(ctx context.Context, userID, childID, food string) ([]FoodLog, error)
+	DeleteLog(ctx context.Context, userID, childID, id string) error
+	// Foods lists the foods the child has tried and the catalog foods they
+	// have not
+	Foods(ctx context.Context, userID, childID string) (*FoodList, error)
+	Allergens(ctx context.Context, userID, childID string) (*AllergenChecklist, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	now           func() time.Time
+}
+
+func NewService(repo Repository, familyService family.Service) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+		now:           time.Now,
+	}
+}
+
+// HealthCheck reports whether food logs can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) LogFood(ctx context.Context, userID, childID string, req *LogFoodRequest) (*FoodLog, error) {
+	food := normalizeFood(req.Food)
+	if food == "" || len(food) > maxFoodLength {
+		return nil, ErrInvalidFood
+	}
+	if !validReactions[req.Reaction] {
+		return nil, ErrInvalidReaction
+	}
+	if req.Severity != "" && (req.Reaction == ReactionNone || !validSeverities[req.Severity]) {
+		return nil, ErrInvalidSeverity
+	}
+
+	allergen := req.Allergen
+	if allergen == "" {
+		allergen = catalogByName[food].Allergen
+	} else if !validAllergens[allergen] {
+		return nil, ErrInvalidAllergen
+	}
+
+	now := s.now()
+	triedAt := now
+	if req.TriedAt != nil {
+		if req.TriedAt.After(now) {
+			return nil, ErrFutureTime
+		}
+		triedAt = *req.TriedAt
+	}
+
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	l := &FoodLog{
+		ID:        ids.New(),
+		ChildID:   childID,
+		Food:      food,
+		Allergen:  allergen,
+		TriedAt:   triedAt,
+		Reaction:  req.Reaction,
+		Severity:  req.Severity,
+		Notes:     req.Notes,
+		LoggedBy:  userID,
+		CreatedAt: now,
+	}
+	if err := s.repo.Create(ctx, l); err != nil {
+		return nil, fmt.Errorf("failed to log food: %w", err)
+	}
+	return l, nil
+}
+
+func (s *service) ListLogs(ctx context.Context, userID, childID, food string) ([]FoodLog, error) {
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	logs, err := s.repo.ListByChild(ctx, childID, normalizeFood(food))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list food logs: %w", err)
+	}
+	return logs, nil
+}
+
+func (s *service) DeleteLog(ctx context.Context, userID, childID, id string) error {
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return err
+	}
+
+	l, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get food log: %w", err)
+	}
+	if l == nil || l.ChildID != childID {
+		return ErrLogNotFound
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete food log: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Foods(ctx context.Context, userID, childID string) (*FoodList, error) {
+	logs, err := s.ListLogs(ctx, userID, childID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Logs come oldest first, so the last one seen for a food is its latest
+	byFood := map[string]*FoodSummary{}
+	for _, l := range logs {
+		f, ok := byFood[l.Food]
+		if !ok {
+			f = &FoodSummary{
+				Food:         l.Food,
+				Category:     catalogByName[l.Food].Category,
+				FirstTriedAt: l.TriedAt,
+			}
+			byFood[l.Food] = f
+		}
+		f.Times++
+		f.LastTriedAt = l.TriedAt
+		f.LastReaction = l.Reaction
+		if l.Allergen != "" {
+			f.Allergen = l.Allergen
+		}
+		if l.Reaction != ReactionNone {
+			f.Reacted = true
+		}
+	}
+
+	list := &FoodList{Tried: []FoodSummary{}, NotTried: []CatalogFood{}}
+	for _, f := range byFood {
+		list.Tried = append(list.Tried, *f)
+	}
+	sort.Slice(list.Tried, func(i, j int) bool {
+		return list.Tried[i].FirstTriedAt.Before(list.Tried[j].FirstTriedAt)
+	})
+	for _, c := range Catalog {
+		if _, ok := byFood[c.Name]; !ok {
+			list.NotTried = append(list.NotTried, c)
+		}
+	}
+	return list, nil
+}
+
+func (s *service) Allergens(ctx context.Context, userID, childID string) (*AllergenChecklist, error) {
+	logs, err := s.ListLogs(ctx, userID, childID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[Allergen]*AllergenEntry, len(Allergens))
+	checklist := &AllergenChecklist{ChildID: childID, Total: len(Allergens), Allergens: []AllergenEntry{}}
+	for _, a := range Allergens {
+		entries[a] = &AllergenEntry{Allergen: a, Status: AllergenNotIntroduced, Foods: []string{}}
+	}
+
+	for _, l := range logs {
+		e, ok := entries[l.Allergen]
+		if !ok {
+			continue
+		}
+		if e.FirstTriedAt == nil {
+			triedAt := l.TriedAt
+			e.FirstTriedAt = &triedAt
+		}
+		e.Times++
+		if !slices.Contains(e.Foods, l.Food) {
+			e.Foods = append(e.Foods, l.Food)
+		}
+		// Any reaction marks the allergen, even if later tries went fine
+		if l.Reaction != ReactionNone {
+			e.Status = AllergenReacted
+		} else if e.Status == AllergenNotIntroduced {
+			e.Status = AllergenIntroduced
+		}
+	}
+
+	for _, a := range Allergens {
+		if entries[a].Status != AllergenNotIntroduced {
+			checklist.Introduced++
+		}
+		checklist.Allergens = append(checklist.Allergens, *entries[a])
+	}
+	return checklist, nil
+}
+
+func normalizeFood(food string) string {
+	return strings.ToLower(strings.Join(strings.Fields(food), " "))
+}
+
+// authorize lets in members of the child's family
+func (s *service) authorize(ctx context.Context, userID, childID string) error {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return ErrChildNotFound
+	}
+
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package solids
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+var testNow = time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+type mockRepository struct {
+	logs []FoodLog
+}
+
+func (m *mockRepository) Create(ctx context.Context, l *FoodLog) error {
+	m.logs = append(m.logs, *l)
+	return nil
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*FoodLog, error) {
+	for _, l := range m.logs {
+		if l.ID == id {
+			return &l, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListByChild(ctx context.Context, childID, food string) ([]FoodLog, error) {
+	logs := []FoodLog{}
+	for _, l := range m.logs {
+		if l.ChildID == childID && (food == "" || l.Food == food) {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	for i, l := range m.logs {
+		if l.ID == id {
+			m.logs = append(m.logs[:i], m.logs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" || childID == "child-2" {
+		return &family.Child{ID: childID, FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID == "user-1" {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+func newTestService(repo *mockRepository) Service {
+	svc := NewService(repo, &mockFamilyService{})
+	svc.(*service).now = func() time.Time { return testNow }
+	return svc
+}
+
+func at(hoursAgo int) *time.Time {
+	t := testNow.Add(-time.Duration(hoursAgo) * time.Hour)
+	return &t
+}
+
+func TestService_LogFood(t *testing.T) {
+	repo := &mockRepository{}
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	l, err := svc.LogFood(ctx, "user-1", "child-1", &LogFoodRequest{Food: "  Peanut   Butter ", Reaction: ReactionNone})
+	if err != nil {
+		t.Fatalf("LogFood() error = %v", err)
+	}
+	if l.Food != "peanut butter" || l.Allergen != AllergenPeanut || !l.TriedAt.Equal(testNow) || l.LoggedBy != "user-1" {
+		t.Errorf("LogFood() = %+v", l)
+	}
+
+	l, err = svc.LogFood(ctx, "user-1", "child-1", &LogFoodRequest{Food: "Pancake", Allergen: AllergenWheat, Reaction: ReactionRash, Severity: SeverityMild})
+	if err != nil || l.Allergen != AllergenWheat {
+		t.Errorf("LogFood() with allergen = %+v, %v", l, err)
+	}
+
+	tests := []struct {
+		name    string
+		childID string
+		userID  string
+		req     LogFoodRequest
+		want    error
+	}{
+		{"blank food", "child-1", "user-1", LogFoodRequest{Food: "   ", Reaction: ReactionNone}, ErrInvalidFood},
+		{"unknown reaction", "child-1", "user-1", LogFoodRequest{Food: "egg", Reaction: "sneezing"}, ErrInvalidReaction},
+		{"severity without reaction", "child-1", "user-1", LogFoodRequest{Food: "egg", Reaction: ReactionNone, Severity: SeverityMild}, ErrInvalidSeverity},
+		{"unknown severity", "child-1", "user-1", LogFoodRequest{Food: "egg", Reaction: ReactionRash, Severity: "extreme"}, ErrInvalidSeverity},
+		{"unknown allergen", "child-1", "user-1", LogFoodRequest{Food: "kiwi", Allergen: "kiwi", Reaction: ReactionNone}, ErrInvalidAllergen},
+		{"future", "child-1", "user-1", LogFoodRequest{Food: "egg", Reaction: ReactionNone, TriedAt: at(-1)}, ErrFutureTime},
+		{"unknown child", "child-9", "user-1", LogFoodRequest{Food: "egg", Reaction: ReactionNone}, ErrChildNotFound},
+		{"not a member", "child-1", "user-2", LogFoodRequest{Food: "egg", Reaction: ReactionNone}, ErrNotMember},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.LogFood(ctx, tt.userID, tt.childID, &tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("LogFood() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Foods(t *testing.T) {
+	repo := &mockRepository{logs: []FoodLog{
+		{ID: "1", ChildID: "child-1", Food: "banana", TriedAt: *at(48), Reaction: ReactionNone},
+		{ID: "2", ChildID: "child-1", Food: "egg", Allergen: AllergenEgg, TriedAt: *at(24), Reaction: ReactionRash},
+		{ID: "3", ChildID: "child-1", Food: "banana", TriedAt: *at(2), Reaction: ReactionNone},
+		{ID: "4", ChildID: "child-1", Food: "dragon fruit", TriedAt: *at(1), Reaction: ReactionNone},
+		{ID: "5", ChildID: "child-2", Food: "avocado", TriedAt: *at(1), Reaction: ReactionNone},
+	}}
+	svc := newTestService(repo)
+
+	list, err := svc.Foods(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Foods() error = %v", err)
+	}
+	if len(list.Tried) != 3 {
+		t.Fatalf("Foods() tried = %+v", list.Tried)
+	}
+	banana := list.Tried[0]
+	if banana.Food != "banana" || banana.Times != 2 || banana.Category != "fruit" || !banana.LastTriedAt.Equal(*at(2)) || banana.Reacted {
+		t.Errorf("banana = %+v", banana)
+	}
+	if egg := list.Tried[1]; egg.Food != "egg" || !egg.Reacted || egg.LastReaction != ReactionRash {
+		t.Errorf("egg = %+v", egg)
+	}
+	if other := list.Tried[2]; other.Food != "dragon fruit" || other.Category != "" {
+		t.Errorf("dragon fruit = %+v", other)
+	}
+
+	if len(list.NotTried) != len(Catalog)-2 {
+		t.Errorf("Foods() not tried = %d, want %d", len(list.NotTried), len(Catalog)-2)
+	}
+	for _, f := range list.NotTried {
+		if f.Name == "banana" || f.Name == "egg" {
+			t.Errorf("Foods() lists %q as not tried", f.Name)
+		}
+	}
+}
+
+func TestService_Allergens(t *testing.T) {
+	repo := &mockRepository{logs: []FoodLog{
+		{ID: "1", ChildID: "child-1", Food: "yogurt", Allergen: AllergenMilk, TriedAt: *at(72), Reaction: ReactionNone},
+		{ID: "2", ChildID: "child-1", Food: "cheese", Allergen: AllergenMilk, TriedAt: *at(48), Reaction: ReactionNone},
+		{ID: "3", ChildID: "child-1", Food: "peanut butter", Allergen: AllergenPeanut, TriedAt: *at(24), Reaction: ReactionHives},
+		{ID: "4", ChildID: "child-1", Food: "peanut butter", Allergen: AllergenPeanut, TriedAt: *at(2), Reaction: ReactionNone},
+		{ID: "5", ChildID: "child-1", Food: "banana", TriedAt: *at(1), Reaction: ReactionNone},
+	}}
+	svc := newTestService(repo)
+
+	checklist, err := svc.Allergens(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Allergens() error = %v", err)
+	}
+	if checklist.Total != 9 || checklist.Introduced != 2 || len(checklist.Allergens) != 9 {
+		t.Fatalf("Allergens() = %+v", checklist)
+	}
+
+	milk := checklist.Allergens[0]
+	if milk.Status != AllergenIntroduced || milk.Times != 2 || len(milk.Foods) != 2 || !milk.FirstTriedAt.Equal(*at(72)) {
+		t.Errorf("milk = %+v", milk)
+	}
+	if peanut := checklist.Allergens[2]; peanut.Status != AllergenReacted || len(peanut.Foods) != 1 {
+		t.Errorf("peanut = %+v", peanut)
+	}
+	if egg := checklist.Allergens[1]; egg.Status != AllergenNotIntroduced || egg.FirstTriedAt != nil {
+		t.Errorf("egg = %+v", egg)
+	}
+}
+
+func TestService_DeleteLog(t *testing.T) {
+	repo := &mockRepository{logs: []FoodLog{
+		{ID: "log-1", ChildID: "child-1", Food: "egg"},
+		{ID: "log-2", ChildID: "child-2", Food: "egg"},
+	}}
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	if err := svc.DeleteLog(ctx, "user-1", "child-1", "log-2"); !errors.Is(err, ErrLogNotFound) {
+		t.Errorf("DeleteLog() of another child's log error = %v, want ErrLogNotFound", err)
+	}
+	if err := svc.DeleteLog(ctx, "user-1", "child-1", "log-1"); err != nil {
+		t.Fatalf("DeleteLog() error = %v", err)
+	}
+	if len(repo.logs) != 1 || repo.logs[0].ID != "log-2" {
+		t.Errorf("logs after delete = %+v", repo.logs)
+	}
+}
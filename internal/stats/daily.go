@@ -0,0 +1,144 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+const (
+	DefaultDailyDays = 7
+	MaxDailyDays     = 90
+)
+
+// epoch starts a range covering a child's whole history
+const epoch = "0001-01-01"
+
+func (s *service) Daily(ctx context.Context, userID, childID string, days int) (*DailyStats, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+
+	if days <= 0 {
+		days = DefaultDailyDays
+	}
+	days = min(days, MaxDailyDays)
+
+	today := dateOf(s.now())
+	from := today.AddDate(0, 0, -(days - 1))
+	tomorrow := today.AddDate(0, 0, 1)
+
+	refreshedAt, err := s.repo.GetDailyRefresh(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last refresh: %w", err)
+	}
+
+	// Stored summaries are complete up to the day of the last refresh; from
+	// there on the logs are read directly
+	liveFrom := from
+	if refreshedAt != nil {
+		liveFrom = dateOf(refreshedAt.In(today.Location()))
+		if liveFrom.After(today) {
+			liveFrom = today
+		}
+		if liveFrom.Before(from) {
+			liveFrom = from
+		}
+	}
+
+	byDate := make(map[string]DailySummary, days)
+	if liveFrom.After(from) {
+		stored, err := s.repo.ListDailySummaries(ctx, childID, day(from), day(liveFrom))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list daily stats: %w", err)
+		}
+		for _, d := range stored {
+			byDate[d.Date] = d
+		}
+	}
+
+	live, err := s.repo.AggregateDays(ctx, []string{childID}, day(liveFrom), day(tomorrow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate daily stats: %w", err)
+	}
+	for _, d := range live {
+		byDate[d.Date] = d
+	}
+
+	result := &DailyStats{ChildID: childID, Days: make([]DailySummary, 0, days), RefreshedAt: refreshedAt}
+	for d := from; d.Before(tomorrow); d = d.AddDate(0, 0, 1) {
+		summary := byDate[day(d)]
+		summary.Date = day(d)
+		summary.Live = !d.Before(liveFrom)
+		result.Days = append(result.Days, summary)
+	}
+	return result, nil
+}
+
+func (s *service) RefreshDaily(ctx context.Context, full bool) (int, error) {
+	started := s.now()
+	today := day(dateOf(started))
+
+	last, err := s.repo.GetDailyRefresh(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last refresh: %w", err)
+	}
+
+	var written int
+	if full || last == nil {
+		written, err = s.repo.MaterializeDays(ctx, nil, epoch, today, started)
+		if err != nil {
+			return 0, fmt.Errorf("failed to rebuild daily stats: %w", err)
+		}
+	} else {
+		// Days that were still live at the last refresh, for everyone
+		written, err = s.repo.MaterializeDays(ctx, nil, day(dateOf(last.In(started.Location()))), today, started)
+		if err != nil {
+			return 0, fmt.Errorf("failed to materialize new days: %w", err)
+		}
+
+		// Logs can be back-dated, edited or deleted, so a child with any
+		// change gets their whole history recomputed
+		changed, err := s.repo.ChangedChildren(ctx, *last)
+		if err != nil {
+			return 0, fmt.Errorf("failed to find changed children: %w", err)
+		}
+		if len(changed) > 0 {
+			n, err := s.repo.MaterializeDays(ctx, changed, epoch, today, started)
+			if err != nil {
+				return 0, fmt.Errorf("failed to recompute changed children: %w", err)
+			}
+			written += n
+		}
+	}
+
+	// Changes made while this ran are picked up next time, since the
+	// watermark is when the run started
+	if err := s.repo.SetDailyRefresh(ctx, started); err != nil {
+		return 0, fmt.Errorf("failed to record refresh: %w", err)
+	}
+	return written, nil
+}
+
+// dateOf truncates t to midnight in its own location
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func day(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
@@ -0,0 +1,104 @@
+package stats
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ninenine/babytrack/internal/family"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/population/:metric", h.getCurve)
+	rg.GET("/children/:childId/daily", h.getDaily)
+}
+
+// RegisterFamilyRoutes registers the opt-in routes on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/stats-opt-in", h.getOptIn)
+	rg.PUT("/:familyId/stats-opt-in", h.setOptIn)
+}
+
+func (h *Handler) getCurve(c *gin.Context) {
+	metric := Metric(c.Param("metric"))
+
+	curve, err := h.service.GetCurve(c.Request.Context(), metric)
+	if err != nil {
+		if errors.Is(err, ErrUnknownMetric) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, curve)
+}
+
+func (h *Handler) getDaily(c *gin.Context) {
+	days := 0
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive number"})
+			return
+		}
+		days = n
+	}
+
+	daily, err := h.service.Daily(c.Request.Context(), c.GetString("user_id"), c.Param("childId"), days)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrChildNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrNotMember):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, daily)
+}
+
+func (h *Handler) getOptIn(c *gin.Context) {
+	familyID := c.Param("familyId")
+
+	optIn, err := h.service.GetOptIn(c.Request.Context(), familyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, optIn)
+}
+
+func (h *Handler) setOptIn(c *gin.Context) {
+	var req SetOptInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	familyID := c.Param("familyId")
+	userID := c.GetString("user_id")
+
+	optIn, err := h.service.SetOptIn(c.Request.Context(), familyID, userID, *req.OptedIn)
+	if err != nil {
+		if errors.Is(err, ErrNotAdmin) || errors.Is(err, family.ErrNotMember) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, optIn)
+}
@@ -0,0 +1,230 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	getOptInFn func(ctx context.Context, familyID string) (*OptIn, error)
+	setOptInFn func(ctx context.Context, familyID, userID string, optedIn bool) (*OptIn, error)
+	refreshFn  func(ctx context.Context) error
+	getCurveFn func(ctx context.Context, metric Metric) (*Curve, error)
+	dailyFn    func(ctx context.Context, userID, childID string, days int) (*DailyStats, error)
+}
+
+func (m *mockService) GetOptIn(ctx context.Context, familyID string) (*OptIn, error) {
+	if m.getOptInFn != nil {
+		return m.getOptInFn(ctx, familyID)
+	}
+	return &OptIn{FamilyID: familyID}, nil
+}
+
+func (m *mockService) SetOptIn(ctx context.Context, familyID, userID string, optedIn bool) (*OptIn, error) {
+	if m.setOptInFn != nil {
+		return m.setOptInFn(ctx, familyID, userID, optedIn)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Refresh(ctx context.Context) error {
+	if m.refreshFn != nil {
+		return m.refreshFn(ctx)
+	}
+	return nil
+}
+
+func (m *mockService) GetCurve(ctx context.Context, metric Metric) (*Curve, error) {
+	if m.getCurveFn != nil {
+		return m.getCurveFn(ctx, metric)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Daily(ctx context.Context, userID, childID string, days int) (*DailyStats, error) {
+	if m.dailyFn != nil {
+		return m.dailyFn(ctx, userID, childID, days)
+	}
+	return &DailyStats{ChildID: childID}, nil
+}
+
+func (m *mockService) RefreshDaily(ctx context.Context, full bool) (int, error) {
+	return 0, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+
+	handler.RegisterRoutes(router.Group("/stats"))
+	handler.RegisterFamilyRoutes(router.Group("/families"))
+	return router
+}
+
+func TestGetCurve_Success(t *testing.T) {
+	svc := &mockService{
+		getCurveFn: func(ctx context.Context, metric Metric) (*Curve, error) {
+			return &Curve{Metric: metric, Points: []CurvePoint{}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/stats/population/sleep_hours_per_day", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestGetCurve_UnknownMetric(t *testing.T) {
+	svc := &mockService{
+		getCurveFn: func(ctx context.Context, metric Metric) (*Curve, error) {
+			return nil, ErrUnknownMetric
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/stats/population/weight", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestSetOptIn_Success(t *testing.T) {
+	var capturedUser string
+	var capturedOptIn bool
+	svc := &mockService{
+		setOptInFn: func(ctx context.Context, familyID, userID string, optedIn bool) (*OptIn, error) {
+			capturedUser = userID
+			capturedOptIn = optedIn
+			return &OptIn{FamilyID: familyID, OptedIn: optedIn}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("PUT", "/families/family-1/stats-opt-in", bytes.NewBufferString(`{"opted_in":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if capturedUser != "test-user-123" || !capturedOptIn {
+		t.Errorf("Unexpected call user=%s optedIn=%v", capturedUser, capturedOptIn)
+	}
+}
+
+func TestSetOptIn_MissingField(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("PUT", "/families/family-1/stats-opt-in", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestSetOptIn_NotAdmin(t *testing.T) {
+	svc := &mockService{
+		setOptInFn: func(ctx context.Context, familyID, userID string, optedIn bool) (*OptIn, error) {
+			return nil, ErrNotAdmin
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("PUT", "/families/family-1/stats-opt-in", bytes.NewBufferString(`{"opted_in":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestGetDaily_Success(t *testing.T) {
+	var capturedDays int
+	svc := &mockService{
+		dailyFn: func(ctx context.Context, userID, childID string, days int) (*DailyStats, error) {
+			capturedDays = days
+			return &DailyStats{ChildID: childID, Days: []DailySummary{{Date: "2024-03-01", Feedings: 6}}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/stats/children/child-1/daily?days=14", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if capturedDays != 14 {
+		t.Errorf("Expected days 14, got %d", capturedDays)
+	}
+}
+
+func TestGetDaily_InvalidDays(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/stats/children/child-1/daily?days=week", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestGetDaily_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrChildNotFound, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		svc := &mockService{
+			dailyFn: func(ctx context.Context, userID, childID string, days int) (*DailyStats, error) {
+				return nil, tt.err
+			},
+		}
+		router := setupRouter(svc)
+
+		req := httptest.NewRequest("GET", "/stats/children/child-1/daily", http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.want, w.Code)
+		}
+	}
+}
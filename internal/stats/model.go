@@ -0,0 +1,61 @@
+package stats
+
+import "time"
+
+type Metric string
+
+const (
+	MetricSleepHoursPerDay Metric = "sleep_hours_per_day"
+)
+
+// MinCohortSize is the k-anonymity threshold: an age bucket is only published
+// when at least this many distinct children contributed to it
+const MinCohortSize = 10
+
+// MaxAgeWeeks bounds the published curves to the first two years
+const MaxAgeWeeks = 104
+
+type OptIn struct {
+	FamilyID  string     `json:"family_id"`
+	OptedIn   bool       `json:"opted_in"`
+	OptedInAt *time.Time `json:"opted_in_at,omitempty"`
+}
+
+type SetOptInRequest struct {
+	OptedIn *bool `json:"opted_in" binding:"required"`
+}
+
+type CurvePoint struct {
+	AgeWeeks   int     `json:"age_weeks"`
+	Value      float64 `json:"value"`
+	SampleSize int     `json:"sample_size"`
+}
+
+type Curve struct {
+	Metric        Metric       `json:"metric"`
+	MinCohortSize int          `json:"min_cohort_size"`
+	Points        []CurvePoint `json:"points"`
+	ComputedAt    *time.Time   `json:"computed_at,omitempty"`
+}
+
+// DailySummary is one child's feeding and sleep totals for a calendar day.
+// Sleep counts only sleeps that have ended, on the day they started.
+type DailySummary struct {
+	ChildID        string  `json:"-"`
+	Date           string  `json:"date"` // YYYY-MM-DD
+	Feedings       int     `json:"feedings"`
+	FeedingMl      float64 `json:"feeding_ml"`
+	NursingMinutes float64 `json:"nursing_minutes"`
+	Sleeps         int     `json:"sleeps"`
+	SleepMinutes   float64 `json:"sleep_minutes"`
+	// Live marks a day computed from the logs for this request, because it
+	// is today or the summary table has not caught up with it yet
+	Live bool `json:"live"`
+}
+
+type DailyStats struct {
+	ChildID string         `json:"child_id"`
+	Days    []DailySummary `json:"days"` // oldest first, ending with today
+	// RefreshedAt is when the summary table was last brought up to date
+	RefreshedAt *time.Time `json:"refreshed_at,omitempty"`
+}
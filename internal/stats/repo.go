@@ -0,0 +1,324 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	GetOptIn(ctx context.Context, familyID string) (*time.Time, error)
+	SetOptIn(ctx context.Context, familyID string, at time.Time) error
+	DeleteOptIn(ctx context.Context, familyID string) error
+	AggregateSleepHours(ctx context.Context, maxAgeWeeks, minCohort int) ([]CurvePoint, error)
+	ReplaceCurve(ctx context.Context, metric Metric, points []CurvePoint, computedAt time.Time) error
+	GetCurve(ctx context.Context, metric Metric) (*Curve, error)
+
+	// AggregateDays totals the logs per child and day, from day from up to
+	// but not including day to (YYYY-MM-DD). Nil childIDs means every child.
+	AggregateDays(ctx context.Context, childIDs []string, from, to string) ([]DailySummary, error)
+	// MaterializeDays replaces the stored summaries in the same range with
+	// fresh totals, returning how many days were written
+	MaterializeDays(ctx context.Context, childIDs []string, from, to string, computedAt time.Time) (int, error)
+	ListDailySummaries(ctx context.Context, childID, from, to string) ([]DailySummary, error)
+	// ChangedChildren lists children whose feedings or sleep were written
+	// or deleted after since
+	ChangedChildren(ctx context.Context, since time.Time) ([]string, error)
+	GetDailyRefresh(ctx context.Context) (*time.Time, error)
+	SetDailyRefresh(ctx context.Context, at time.Time) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the population statistics, opt-in and daily summary tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "population_stats", "stats_opt_ins", "child_daily_stats")
+}
+
+func (r *repository) GetOptIn(ctx context.Context, familyID string) (*time.Time, error) {
+	query := `SELECT opted_in_at FROM stats_opt_ins WHERE family_id = $1`
+
+	var at time.Time
+	err := r.db.QueryRowContext(ctx, query, familyID).Scan(&at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+func (r *repository) SetOptIn(ctx context.Context, familyID string, at time.Time) error {
+	query := `
+		INSERT INTO stats_opt_ins (family_id, opted_in_at)
+		VALUES ($1, $2)
+		ON CONFLICT (family_id) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, familyID, at)
+	return err
+}
+
+func (r *repository) DeleteOptIn(ctx context.Context, familyID string) error {
+	query := `DELETE FROM stats_opt_ins WHERE family_id = $1`
+	_, err := r.db.ExecContext(ctx, query, familyID)
+	return err
+}
+
+// AggregateSleepHours computes the mean total sleep per child-day for each
+// week of age, using only opted-in families. Buckets with fewer than
+// minCohort distinct children are dropped in SQL so they never leave the database.
+func (r *repository) AggregateSleepHours(ctx context.Context, maxAgeWeeks, minCohort int) ([]CurvePoint, error) {
+	query := `
+		WITH daily AS (
+			SELECT s.child_id,
+				(s.start_time::date - c.date_of_birth) / 7 AS age_weeks,
+				s.start_time::date AS day,
+				SUM(EXTRACT(EPOCH FROM (s.end_time - s.start_time))) / 3600.0 AS hours
+			FROM sleep_records_all s
+			JOIN children c ON c.id = s.child_id
+			JOIN stats_opt_ins o ON o.family_id = c.family_id
+			WHERE s.end_time IS NOT NULL
+			GROUP BY s.child_id, age_weeks, day
+		)
+		SELECT age_weeks, AVG(hours), COUNT(DISTINCT child_id)
+		FROM daily
+		WHERE age_weeks BETWEEN 0 AND $1
+		GROUP BY age_weeks
+		HAVING COUNT(DISTINCT child_id) >= $2
+		ORDER BY age_weeks ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, maxAgeWeeks, minCohort)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	points := []CurvePoint{}
+	for rows.Next() {
+		var p CurvePoint
+		if err := rows.Scan(&p.AgeWeeks, &p.Value, &p.SampleSize); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
+
+// ReplaceCurve swaps the stored curve for a metric in a single transaction so
+// readers never see a partially refreshed curve
+func (r *repository) ReplaceCurve(ctx context.Context, metric Metric, points []CurvePoint, computedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM population_stats WHERE metric = $1`, metric); err != nil {
+		return err
+	}
+
+	insert := `
+		INSERT INTO population_stats (metric, age_weeks, value, sample_size, computed_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, p := range points {
+		if _, err := tx.ExecContext(ctx, insert, metric, p.AgeWeeks, p.Value, p.SampleSize, computedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) GetCurve(ctx context.Context, metric Metric) (*Curve, error) {
+	query := `
+		SELECT age_weeks, value, sample_size, computed_at
+		FROM population_stats
+		WHERE metric = $1
+		ORDER BY age_weeks ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, metric)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	curve := &Curve{
+		Metric: metric,
+		Points: []CurvePoint{},
+	}
+	for rows.Next() {
+		var p CurvePoint
+		var computedAt time.Time
+		if err := rows.Scan(&p.AgeWeeks, &p.Value, &p.SampleSize, &computedAt); err != nil {
+			return nil, err
+		}
+		curve.Points = append(curve.Points, p)
+		curve.ComputedAt = &computedAt
+	}
+
+	return curve, rows.Err()
+}
+
+// dailyAggregate totals feedings and ended sleep per child and day of
+// start_time. $1 holds the child IDs, or NULL for all; $2 and $3 bound the
+// days. Ounces are converted at 29.5735 ml, as feeding stats do.
+const dailyAggregate = `
+	WITH f AS (
+		SELECT child_id, start_time::date AS day, COUNT(*) AS feedings,
+			COALESCE(SUM(CASE WHEN type <> 'solid' AND amount IS NOT NULL
+				THEN amount * CASE WHEN LOWER(unit) = 'oz' THEN 29.5735 ELSE 1 END END), 0) AS ml,
+			COALESCE(SUM(CASE WHEN type = 'breast' AND end_time IS NOT NULL
+				THEN EXTRACT(EPOCH FROM (end_time - start_time)) / 60 END), 0) AS nursing
+		FROM feedings_all
+		WHERE ($1::text[] IS NULL OR child_id = ANY($1))
+			AND start_time::date >= $2::date AND start_time::date < $3::date
+		GROUP BY child_id, day
+	), s AS (
+		SELECT child_id, start_time::date AS day, COUNT(*) AS sleeps,
+			SUM(EXTRACT(EPOCH FROM (end_time - start_time))) / 60 AS minutes
+		FROM sleep_records_all
+		WHERE end_time IS NOT NULL AND ($1::text[] IS NULL OR child_id = ANY($1))
+			AND start_time::date >= $2::date AND start_time::date < $3::date
+		GROUP BY child_id, day
+	)
+	SELECT COALESCE(f.child_id, s.child_id) AS child_id, COALESCE(f.day, s.day) AS day,
+		COALESCE(f.feedings, 0) AS feedings, COALESCE(f.ml, 0) AS feeding_ml, COALESCE(f.nursing, 0) AS nursing_minutes,
+		COALESCE(s.sleeps, 0) AS sleeps, COALESCE(s.minutes, 0) AS sleep_minutes
+	FROM f FULL OUTER JOIN s ON s.child_id = f.child_id AND s.day = f.day
+`
+
+func (r *repository) AggregateDays(ctx context.Context, childIDs []string, from, to string) ([]DailySummary, error) {
+	rows, err := r.db.QueryContext(ctx, dailyAggregate+` ORDER BY day ASC`, pq.Array(childIDs), from, to)
+	if err != nil {
+		return nil, err
+	}
+	return scanDailySummaries(rows)
+}
+
+func (r *repository) MaterializeDays(ctx context.Context, childIDs []string, from, to string, computedAt time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit
+
+	// Days whose logs were all deleted must lose their row too
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM child_daily_stats
+		WHERE ($1::text[] IS NULL OR child_id = ANY($1)) AND day >= $2::date AND day < $3::date
+	`, pq.Array(childIDs), from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO child_daily_stats (child_id, day, feedings, feeding_ml, nursing_minutes, sleeps, sleep_minutes, computed_at)
+		SELECT agg.*, $4 FROM (`+dailyAggregate+`) AS agg
+	`, pq.Array(childIDs), from, to, computedAt)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), tx.Commit()
+}
+
+func (r *repository) ListDailySummaries(ctx context.Context, childID, from, to string) ([]DailySummary, error) {
+	query := `
+		SELECT child_id, day, feedings, feeding_ml, nursing_minutes, sleeps, sleep_minutes
+		FROM child_daily_stats
+		WHERE child_id = $1 AND day >= $2::date AND day < $3::date
+		ORDER BY day ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, childID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return scanDailySummaries(rows)
+}
+
+func scanDailySummaries(rows *sql.Rows) ([]DailySummary, error) {
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	summaries := []DailySummary{}
+	for rows.Next() {
+		var d DailySummary
+		var day time.Time
+		if err := rows.Scan(&d.ChildID, &day, &d.Feedings, &d.FeedingMl, &d.NursingMinutes, &d.Sleeps, &d.SleepMinutes); err != nil {
+			return nil, err
+		}
+		d.Date = day.Format(time.DateOnly)
+		summaries = append(summaries, d)
+	}
+
+	return summaries, rows.Err()
+}
+
+func (r *repository) ChangedChildren(ctx context.Context, since time.Time) ([]string, error) {
+	query := `
+		SELECT child_id FROM feedings WHERE updated_at > $1
+		UNION
+		SELECT child_id FROM sleep_records WHERE updated_at > $1
+		UNION
+		SELECT scope_id FROM tombstones WHERE entity_type IN ($2, $3) AND deleted_at > $1
+	`
+	rows, err := r.db.QueryContext(ctx, query, since, delta.EntityFeeding, delta.EntitySleep)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	childIDs := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		childIDs = append(childIDs, id)
+	}
+
+	return childIDs, rows.Err()
+}
+
+func (r *repository) GetDailyRefresh(ctx context.Context) (*time.Time, error) {
+	var at time.Time
+	err := r.db.QueryRowContext(ctx, `SELECT refreshed_at FROM child_daily_stats_refreshes`).Scan(&at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+func (r *repository) SetDailyRefresh(ctx context.Context, at time.Time) error {
+	query := `
+		INSERT INTO child_daily_stats_refreshes (id, refreshed_at)
+		VALUES (TRUE, $1)
+		ON CONFLICT (id) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at
+	`
+	_, err := r.db.ExecContext(ctx, query, at)
+	return err
+}
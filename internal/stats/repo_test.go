@@ -0,0 +1,184 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_GetOptIn_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT opted_in_at FROM stats_opt_ins").
+		WithArgs("family-1").
+		WillReturnError(sql.ErrNoRows)
+
+	at, err := repo.GetOptIn(context.Background(), "family-1")
+	if err != nil {
+		t.Fatalf("GetOptIn() error = %v", err)
+	}
+	if at != nil {
+		t.Errorf("GetOptIn() = %v, want nil", at)
+	}
+}
+
+func TestRepository_AggregateSleepHours(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	rows := sqlmock.NewRows([]string{"age_weeks", "avg", "count"}).
+		AddRow(4, 15.2, 12).
+		AddRow(5, 14.9, 11)
+
+	mock.ExpectQuery("WITH daily AS").
+		WithArgs(MaxAgeWeeks, MinCohortSize).
+		WillReturnRows(rows)
+
+	points, err := repo.AggregateSleepHours(context.Background(), MaxAgeWeeks, MinCohortSize)
+	if err != nil {
+		t.Fatalf("AggregateSleepHours() error = %v", err)
+	}
+	if len(points) != 2 || points[0].AgeWeeks != 4 || points[0].SampleSize != 12 {
+		t.Errorf("AggregateSleepHours() = %+v", points)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ReplaceCurve(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	points := []CurvePoint{{AgeWeeks: 4, Value: 15.2, SampleSize: 12}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM population_stats").
+		WithArgs(MetricSleepHoursPerDay).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO population_stats").
+		WithArgs(MetricSleepHoursPerDay, 4, 15.2, 12, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.ReplaceCurve(context.Background(), MetricSleepHoursPerDay, points, now); err != nil {
+		t.Fatalf("ReplaceCurve() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ReplaceCurve_RollsBack(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM population_stats").
+		WillReturnError(errors.New("database error"))
+	mock.ExpectRollback()
+
+	if err := repo.ReplaceCurve(context.Background(), MetricSleepHoursPerDay, nil, time.Now()); err == nil {
+		t.Error("ReplaceCurve() should return error")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_AggregateDays(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	rows := sqlmock.NewRows([]string{"child_id", "day", "feedings", "feeding_ml", "nursing_minutes", "sleeps", "sleep_minutes"}).
+		AddRow("child-1", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), 6, 480.0, 35.0, 0, 0.0)
+
+	mock.ExpectQuery("FROM f FULL OUTER JOIN s").
+		WithArgs(sqlmock.AnyArg(), "2024-03-01", "2024-03-02").
+		WillReturnRows(rows)
+
+	days, err := repo.AggregateDays(context.Background(), []string{"child-1"}, "2024-03-01", "2024-03-02")
+	if err != nil {
+		t.Fatalf("AggregateDays() error = %v", err)
+	}
+	if len(days) != 1 || days[0].Date != "2024-03-01" || days[0].Feedings != 6 || days[0].FeedingMl != 480 {
+		t.Errorf("AggregateDays() = %+v", days)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_MaterializeDays(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM child_daily_stats").
+		WithArgs(sqlmock.AnyArg(), "2024-03-01", "2024-03-03").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO child_daily_stats").
+		WithArgs(sqlmock.AnyArg(), "2024-03-01", "2024-03-03", now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	n, err := repo.MaterializeDays(context.Background(), nil, "2024-03-01", "2024-03-03", now)
+	if err != nil {
+		t.Fatalf("MaterializeDays() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("MaterializeDays() = %d, want 2", n)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetDailyRefresh_Never(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT refreshed_at FROM child_daily_stats_refreshes").
+		WillReturnError(sql.ErrNoRows)
+
+	at, err := repo.GetDailyRefresh(context.Background())
+	if err != nil {
+		t.Fatalf("GetDailyRefresh() error = %v", err)
+	}
+	if at != nil {
+		t.Errorf("GetDailyRefresh() = %v, want nil", at)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
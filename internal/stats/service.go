@@ -0,0 +1,125 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+var (
+	ErrUnknownMetric = errors.New("unknown metric")
+	ErrNotAdmin      = errors.New("only admins can change stats sharing")
+	ErrChildNotFound = errors.New("child not found")
+	ErrNotMember     = errors.New("user is not a member of this family")
+)
+
+type Service interface {
+	GetOptIn(ctx context.Context, familyID string) (*OptIn, error)
+	SetOptIn(ctx context.Context, familyID, userID string, optedIn bool) (*OptIn, error)
+	Refresh(ctx context.Context) error
+	GetCurve(ctx context.Context, metric Metric) (*Curve, error)
+
+	// Daily returns a child's totals for the last days days, today included
+	Daily(ctx context.Context, userID, childID string, days int) (*DailyStats, error)
+	// RefreshDaily brings the stored daily summaries up to yesterday. A full
+	// refresh rebuilds every child's history; otherwise only days since the
+	// last refresh and children with edited logs are recomputed.
+	RefreshDaily(ctx context.Context, full bool) (int, error)
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+	now           func() time.Time
+}
+
+func NewService(repo Repository, familyService family.Service) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+		now:           time.Now,
+	}
+}
+
+// HealthCheck reports whether population statistics can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) GetOptIn(ctx context.Context, familyID string) (*OptIn, error) {
+	at, err := s.repo.GetOptIn(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opt-in: %w", err)
+	}
+	return &OptIn{FamilyID: familyID, OptedIn: at != nil, OptedInAt: at}, nil
+}
+
+// SetOptIn records a family's consent to contribute to population stats.
+// Opting out removes the family from the next refresh.
+func (s *service) SetOptIn(ctx context.Context, familyID, userID string, optedIn bool) (*OptIn, error) {
+	role, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "admin" {
+		return nil, ErrNotAdmin
+	}
+
+	if optedIn {
+		err = s.repo.SetOptIn(ctx, familyID, time.Now())
+	} else {
+		err = s.repo.DeleteOptIn(ctx, familyID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update opt-in: %w", err)
+	}
+
+	return s.GetOptIn(ctx, familyID)
+}
+
+// Refresh recomputes every published curve from opted-in families
+func (s *service) Refresh(ctx context.Context) error {
+	points, err := s.repo.AggregateSleepHours(ctx, MaxAgeWeeks, MinCohortSize)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate sleep: %w", err)
+	}
+
+	if err := s.repo.ReplaceCurve(ctx, MetricSleepHoursPerDay, anonymise(points), time.Now()); err != nil {
+		return fmt.Errorf("failed to store curve: %w", err)
+	}
+	return nil
+}
+
+func (s *service) GetCurve(ctx context.Context, metric Metric) (*Curve, error) {
+	if metric != MetricSleepHoursPerDay {
+		return nil, ErrUnknownMetric
+	}
+
+	curve, err := s.repo.GetCurve(ctx, metric)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get curve: %w", err)
+	}
+
+	curve.Points = anonymise(curve.Points)
+	curve.MinCohortSize = MinCohortSize
+	return curve, nil
+}
+
+// anonymise drops buckets below the k-anonymity threshold and rounds values
+// so individual contributions can't be reconstructed from small changes
+func anonymise(points []CurvePoint) []CurvePoint {
+	result := make([]CurvePoint, 0, len(points))
+	for _, p := range points {
+		if p.SampleSize < MinCohortSize {
+			continue
+		}
+		p.Value = math.Round(p.Value*10) / 10
+		result = append(result, p)
+	}
+	return result
+}
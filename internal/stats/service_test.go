@@ -0,0 +1,305 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+// mockRepository is a test double for Repository
+type mockRepository struct {
+	optIns    map[string]time.Time
+	aggregate []CurvePoint
+	stored    map[Metric][]CurvePoint
+
+	summaries    []DailySummary // stored daily rows
+	live         []DailySummary // what AggregateDays returns
+	refreshedAt  *time.Time
+	changed      []string
+	aggregated   [][2]string // from/to passed to AggregateDays
+	materialized []materializeCall
+}
+
+type materializeCall struct {
+	childIDs []string
+	from, to string
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		optIns: make(map[string]time.Time),
+		stored: make(map[Metric][]CurvePoint),
+	}
+}
+
+func (m *mockRepository) GetOptIn(ctx context.Context, familyID string) (*time.Time, error) {
+	if at, ok := m.optIns[familyID]; ok {
+		return &at, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) SetOptIn(ctx context.Context, familyID string, at time.Time) error {
+	m.optIns[familyID] = at
+	return nil
+}
+
+func (m *mockRepository) DeleteOptIn(ctx context.Context, familyID string) error {
+	delete(m.optIns, familyID)
+	return nil
+}
+
+func (m *mockRepository) AggregateSleepHours(ctx context.Context, maxAgeWeeks, minCohort int) ([]CurvePoint, error) {
+	return m.aggregate, nil
+}
+
+func (m *mockRepository) ReplaceCurve(ctx context.Context, metric Metric, points []CurvePoint, computedAt time.Time) error {
+	m.stored[metric] = points
+	return nil
+}
+
+func (m *mockRepository) GetCurve(ctx context.Context, metric Metric) (*Curve, error) {
+	return &Curve{Metric: metric, Points: m.stored[metric]}, nil
+}
+
+func (m *mockRepository) AggregateDays(ctx context.Context, childIDs []string, from, to string) ([]DailySummary, error) {
+	m.aggregated = append(m.aggregated, [2]string{from, to})
+	return m.live, nil
+}
+
+func (m *mockRepository) MaterializeDays(ctx context.Context, childIDs []string, from, to string, computedAt time.Time) (int, error) {
+	m.materialized = append(m.materialized, materializeCall{childIDs: childIDs, from: from, to: to})
+	return 1, nil
+}
+
+func (m *mockRepository) ListDailySummaries(ctx context.Context, childID, from, to string) ([]DailySummary, error) {
+	return m.summaries, nil
+}
+
+func (m *mockRepository) ChangedChildren(ctx context.Context, since time.Time) ([]string, error) {
+	return m.changed, nil
+}
+
+func (m *mockRepository) GetDailyRefresh(ctx context.Context) (*time.Time, error) {
+	return m.refreshedAt, nil
+}
+
+func (m *mockRepository) SetDailyRefresh(ctx context.Context, at time.Time) error {
+	m.refreshedAt = &at
+	return nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	roles    map[string]string
+	children map[string]*family.Child
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if role, ok := m.roles[userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+func TestService_SetOptIn(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, &mockFamilyService{roles: map[string]string{"admin-1": "admin"}})
+
+	optIn, err := svc.SetOptIn(context.Background(), "family-1", "admin-1", true)
+	if err != nil {
+		t.Fatalf("SetOptIn() error = %v", err)
+	}
+	if !optIn.OptedIn || optIn.OptedInAt == nil {
+		t.Errorf("SetOptIn(true) = %+v, want opted in", optIn)
+	}
+
+	optIn, err = svc.SetOptIn(context.Background(), "family-1", "admin-1", false)
+	if err != nil {
+		t.Fatalf("SetOptIn() error = %v", err)
+	}
+	if optIn.OptedIn {
+		t.Error("SetOptIn(false) should opt the family out")
+	}
+}
+
+func TestService_SetOptIn_RequiresAdmin(t *testing.T) {
+	svc := NewService(newMockRepository(), &mockFamilyService{roles: map[string]string{"member-1": "member"}})
+
+	_, err := svc.SetOptIn(context.Background(), "family-1", "member-1", true)
+	if !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("SetOptIn() error = %v, want ErrNotAdmin", err)
+	}
+}
+
+func TestService_Refresh_EnforcesCohortSize(t *testing.T) {
+	repo := newMockRepository()
+	repo.aggregate = []CurvePoint{
+		{AgeWeeks: 1, Value: 15.234, SampleSize: MinCohortSize},
+		{AgeWeeks: 2, Value: 14.8, SampleSize: MinCohortSize - 1},
+	}
+	svc := NewService(repo, &mockFamilyService{})
+
+	if err := svc.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	stored := repo.stored[MetricSleepHoursPerDay]
+	if len(stored) != 1 {
+		t.Fatalf("Refresh() stored %d points, want 1 (small cohort dropped)", len(stored))
+	}
+	if stored[0].Value != 15.2 {
+		t.Errorf("Refresh() value = %v, want rounded 15.2", stored[0].Value)
+	}
+}
+
+func TestService_GetCurve(t *testing.T) {
+	repo := newMockRepository()
+	repo.stored[MetricSleepHoursPerDay] = []CurvePoint{{AgeWeeks: 3, Value: 15, SampleSize: 20}}
+	svc := NewService(repo, &mockFamilyService{})
+
+	curve, err := svc.GetCurve(context.Background(), MetricSleepHoursPerDay)
+	if err != nil {
+		t.Fatalf("GetCurve() error = %v", err)
+	}
+	if len(curve.Points) != 1 || curve.MinCohortSize != MinCohortSize {
+		t.Errorf("GetCurve() = %+v", curve)
+	}
+}
+
+func TestService_GetCurve_UnknownMetric(t *testing.T) {
+	svc := NewService(newMockRepository(), &mockFamilyService{})
+
+	_, err := svc.GetCurve(context.Background(), "weight")
+	if !errors.Is(err, ErrUnknownMetric) {
+		t.Errorf("GetCurve() error = %v, want ErrUnknownMetric", err)
+	}
+}
+
+func newDailyService(repo *mockRepository, now time.Time) Service {
+	familySvc := &mockFamilyService{
+		roles:    map[string]string{"user-1": "member"},
+		children: map[string]*family.Child{"child-1": {ID: "child-1", FamilyID: "family-1"}},
+	}
+	svc := NewService(repo, familySvc)
+	svc.(*service).now = func() time.Time { return now }
+	return svc
+}
+
+func TestService_Daily_MergesStoredAndLive(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	refreshed := time.Date(2024, 3, 9, 2, 0, 0, 0, time.UTC)
+
+	repo := newMockRepository()
+	repo.refreshedAt = &refreshed
+	repo.summaries = []DailySummary{{ChildID: "child-1", Date: "2024-03-05", Feedings: 7}}
+	repo.live = []DailySummary{{ChildID: "child-1", Date: "2024-03-10", Feedings: 3}}
+	svc := newDailyService(repo, now)
+
+	daily, err := svc.Daily(context.Background(), "user-1", "child-1", 0)
+	if err != nil {
+		t.Fatalf("Daily() error = %v", err)
+	}
+
+	if len(daily.Days) != DefaultDailyDays {
+		t.Fatalf("Daily() returned %d days, want %d", len(daily.Days), DefaultDailyDays)
+	}
+	if first := daily.Days[0]; first.Date != "2024-03-04" || first.Feedings != 0 || first.Live {
+		t.Errorf("Daily() first day = %+v, want an empty stored 2024-03-04", first)
+	}
+	if d := daily.Days[1]; d.Feedings != 7 || d.Live {
+		t.Errorf("Daily() 2024-03-05 = %+v, want 7 stored feedings", d)
+	}
+	// Days from the refresh on are read from the logs
+	if d := daily.Days[5]; d.Date != "2024-03-09" || !d.Live {
+		t.Errorf("Daily() 2024-03-09 = %+v, want live", d)
+	}
+	if d := daily.Days[6]; d.Feedings != 3 || !d.Live {
+		t.Errorf("Daily() today = %+v, want 3 live feedings", d)
+	}
+	if len(repo.aggregated) != 1 || repo.aggregated[0] != [2]string{"2024-03-09", "2024-03-11"} {
+		t.Errorf("Daily() aggregated %v, want 2024-03-09 to 2024-03-11", repo.aggregated)
+	}
+}
+
+func TestService_Daily_NeverRefreshed(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	repo := newMockRepository()
+	svc := newDailyService(repo, now)
+
+	daily, err := svc.Daily(context.Background(), "user-1", "child-1", 500)
+	if err != nil {
+		t.Fatalf("Daily() error = %v", err)
+	}
+	if len(daily.Days) != MaxDailyDays {
+		t.Errorf("Daily() returned %d days, want the %d-day cap", len(daily.Days), MaxDailyDays)
+	}
+	for _, d := range daily.Days {
+		if !d.Live {
+			t.Fatalf("Daily() day %s should be live before the first refresh", d.Date)
+		}
+	}
+}
+
+func TestService_Daily_Access(t *testing.T) {
+	svc := newDailyService(newMockRepository(), time.Now())
+
+	if _, err := svc.Daily(context.Background(), "user-1", "child-2", 7); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("Daily() error = %v, want ErrChildNotFound", err)
+	}
+	if _, err := svc.Daily(context.Background(), "stranger", "child-1", 7); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Daily() error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestService_RefreshDaily_FirstRunIsFull(t *testing.T) {
+	now := time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC)
+	repo := newMockRepository()
+	svc := newDailyService(repo, now)
+
+	if _, err := svc.RefreshDaily(context.Background(), false); err != nil {
+		t.Fatalf("RefreshDaily() error = %v", err)
+	}
+
+	if len(repo.materialized) != 1 {
+		t.Fatalf("RefreshDaily() materialized %d times, want 1", len(repo.materialized))
+	}
+	if call := repo.materialized[0]; call.childIDs != nil || call.from != epoch || call.to != "2024-03-10" {
+		t.Errorf("RefreshDaily() materialized %+v, want every child up to today", call)
+	}
+	if repo.refreshedAt == nil || !repo.refreshedAt.Equal(now) {
+		t.Errorf("RefreshDaily() refreshedAt = %v, want %v", repo.refreshedAt, now)
+	}
+}
+
+func TestService_RefreshDaily_Incremental(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	last := time.Date(2024, 3, 9, 23, 45, 0, 0, time.UTC)
+
+	repo := newMockRepository()
+	repo.refreshedAt = &last
+	repo.changed = []string{"child-1"}
+	svc := newDailyService(repo, now)
+
+	written, err := svc.RefreshDaily(context.Background(), false)
+	if err != nil {
+		t.Fatalf("RefreshDaily() error = %v", err)
+	}
+
+	if len(repo.materialized) != 2 || written != 2 {
+		t.Fatalf("RefreshDaily() materialized %+v, want new days then changed children", repo.materialized)
+	}
+	if call := repo.materialized[0]; call.childIDs != nil || call.from != "2024-03-09" || call.to != "2024-03-10" {
+		t.Errorf("RefreshDaily() new days = %+v", call)
+	}
+	if call := repo.materialized[1]; len(call.childIDs) != 1 || call.from != epoch {
+		t.Errorf("RefreshDaily() changed children = %+v", call)
+	}
+}
@@ -0,0 +1,28 @@
+package status
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the public status endpoint
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.get)
+}
+
+func (h *Handler) get(c *gin.Context) {
+	st := h.service.Get(c.Request.Context())
+
+	// Clients poll this; let intermediaries share a response for a few seconds
+	c.Header("Cache-Control", "public, max-age=10")
+	c.JSON(http.StatusOK, st)
+}
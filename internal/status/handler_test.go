@@ -0,0 +1,54 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	getFn func(ctx context.Context) *Status
+}
+
+func (m *mockService) Get(ctx context.Context) *Status {
+	if m.getFn != nil {
+		return m.getFn(ctx)
+	}
+	return &Status{Status: LevelOK, Maintenance: []MaintenanceWindow{}}
+}
+
+func TestGet_Success(t *testing.T) {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	NewHandler(&mockService{}).RegisterRoutes(router.Group("/status"))
+
+	req := httptest.NewRequest("GET", "/status", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var st Status
+	if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if st.Status != LevelOK {
+		t.Errorf("Expected ok, got %s", st.Status)
+	}
+	if w.Header().Get("Cache-Control") == "" {
+		t.Error("Expected a Cache-Control header")
+	}
+}
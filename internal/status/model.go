@@ -0,0 +1,28 @@
+package status
+
+import "time"
+
+type Level string
+
+const (
+	LevelOK          Level = "ok"
+	LevelMaintenance Level = "maintenance"
+	LevelOutage      Level = "outage"
+)
+
+// MaintenanceWindow is a planned period during which the service may be
+// unavailable
+type MaintenanceWindow struct {
+	Title    string    `json:"title"`
+	Message  string    `json:"message,omitempty"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// Status is the coarse public view of service health. It deliberately says
+// nothing about individual components beyond whether the app can be used.
+type Status struct {
+	Status      Level               `json:"status"`
+	Maintenance []MaintenanceWindow `json:"maintenance"`
+	CheckedAt   time.Time           `json:"checked_at"`
+}
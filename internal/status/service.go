@@ -0,0 +1,128 @@
+package status
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/maintenance"
+)
+
+const (
+	// cacheFor bounds how often the database is pinged on behalf of
+	// unauthenticated callers
+	cacheFor    = 10 * time.Second
+	pingTimeout = 2 * time.Second
+)
+
+// Pinger reports whether the database is reachable. *sql.DB satisfies it.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// MaintenanceSource supplies planned maintenance windows
+type MaintenanceSource interface {
+	Maintenance(ctx context.Context) ([]MaintenanceWindow, error)
+}
+
+// Schedule is a fixed list of maintenance windows, typically from config
+type Schedule []MaintenanceWindow
+
+func (s Schedule) Maintenance(ctx context.Context) ([]MaintenanceWindow, error) {
+	return s, nil
+}
+
+type Service interface {
+	Get(ctx context.Context) *Status
+}
+
+type service struct {
+	db          Pinger
+	maintenance MaintenanceSource
+	mode        *maintenance.Switch
+	now         func() time.Time
+
+	mu     sync.Mutex
+	cached *Status
+}
+
+type Option func(*service)
+
+// WithMaintenanceMode reports maintenance whenever the operator switch is on,
+// whether or not a window was scheduled
+func WithMaintenanceMode(sw *maintenance.Switch) Option {
+	return func(s *service) {
+		s.mode = sw
+	}
+}
+
+func NewService(db Pinger, windows MaintenanceSource, opts ...Option) Service {
+	s := &service{db: db, maintenance: windows, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Get returns the current status, reusing a recent check so the endpoint can
+// be polled by every client without each poll reaching the database
+func (s *service) Get(ctx context.Context) *Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.cached != nil && now.Sub(s.cached.CheckedAt) < cacheFor {
+		return s.cached
+	}
+
+	st := &Status{
+		Status:      LevelOK,
+		Maintenance: s.upcoming(ctx, now),
+		CheckedAt:   now,
+	}
+
+	for _, w := range st.Maintenance {
+		if !now.Before(w.StartsAt) {
+			st.Status = LevelMaintenance
+		}
+	}
+	if s.mode != nil && s.mode.Get().Enabled {
+		st.Status = LevelMaintenance
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := s.db.PingContext(pingCtx); err != nil {
+		log.Printf("[status] database ping failed: %v", err)
+		st.Status = LevelOutage
+	}
+
+	s.cached = st
+	return st
+}
+
+// upcoming returns the windows that have not yet ended, soonest first
+func (s *service) upcoming(ctx context.Context, now time.Time) []MaintenanceWindow {
+	windows := []MaintenanceWindow{}
+	if s.maintenance == nil {
+		return windows
+	}
+
+	all, err := s.maintenance.Maintenance(ctx)
+	if err != nil {
+		log.Printf("[status] failed to load maintenance windows: %v", err)
+		return windows
+	}
+
+	for _, w := range all {
+		if w.EndsAt.After(now) {
+			windows = append(windows, w)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].StartsAt.Before(windows[j].StartsAt)
+	})
+	return windows
+}
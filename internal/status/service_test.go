@@ -0,0 +1,96 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/maintenance"
+)
+
+type mockPinger struct {
+	err   error
+	pings int
+}
+
+func (m *mockPinger) PingContext(ctx context.Context) error {
+	m.pings++
+	return m.err
+}
+
+func newTestService(db Pinger, schedule Schedule, now time.Time) *service {
+	s := NewService(db, schedule).(*service)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestService_Get_OK(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	schedule := Schedule{
+		{Title: "Later", StartsAt: now.Add(48 * time.Hour), EndsAt: now.Add(49 * time.Hour)},
+		{Title: "Past", StartsAt: now.Add(-3 * time.Hour), EndsAt: now.Add(-2 * time.Hour)},
+		{Title: "Soon", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)},
+	}
+	svc := newTestService(&mockPinger{}, schedule, now)
+
+	st := svc.Get(context.Background())
+	if st.Status != LevelOK {
+		t.Errorf("Status = %s, want ok", st.Status)
+	}
+	if len(st.Maintenance) != 2 || st.Maintenance[0].Title != "Soon" || st.Maintenance[1].Title != "Later" {
+		t.Errorf("Maintenance = %+v, want upcoming windows soonest first", st.Maintenance)
+	}
+}
+
+func TestService_Get_DuringMaintenance(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	schedule := Schedule{{Title: "Upgrade", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}}
+	svc := newTestService(&mockPinger{}, schedule, now)
+
+	if st := svc.Get(context.Background()); st.Status != LevelMaintenance {
+		t.Errorf("Status = %s, want maintenance", st.Status)
+	}
+}
+
+func TestService_Get_DatabaseDown(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc := newTestService(&mockPinger{err: errors.New("connection refused")}, nil, now)
+
+	st := svc.Get(context.Background())
+	if st.Status != LevelOutage {
+		t.Errorf("Status = %s, want outage", st.Status)
+	}
+	if st.Maintenance == nil {
+		t.Error("Maintenance should be an empty list, not null")
+	}
+}
+
+func TestService_Get_Cached(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	db := &mockPinger{}
+	svc := newTestService(db, nil, now)
+
+	svc.Get(context.Background())
+	svc.Get(context.Background())
+	if db.pings != 1 {
+		t.Errorf("pings = %d, want 1 within the cache window", db.pings)
+	}
+
+	svc.now = func() time.Time { return now.Add(cacheFor) }
+	svc.Get(context.Background())
+	if db.pings != 2 {
+		t.Errorf("pings = %d, want a fresh check once the cache expires", db.pings)
+	}
+}
+
+func TestService_Get_MaintenanceMode(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	sw := maintenance.NewSwitch(true, "", 0)
+	s := NewService(&mockPinger{}, nil, WithMaintenanceMode(sw)).(*service)
+	s.now = func() time.Time { return now }
+
+	if st := s.Get(context.Background()); st.Status != LevelMaintenance {
+		t.Errorf("Status = %s, want maintenance while the switch is on", st.Status)
+	}
+}
@@ -1,7 +1,14 @@
 package sync
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/envelope"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/occurrence"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,6 +25,10 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.POST("/push", h.push)
 	rg.GET("/pull", h.pull)
 	rg.GET("/status", h.status)
+	rg.GET("/conflicts", h.listConflicts)
+	rg.POST("/conflicts/:id/resolve", h.resolveConflict)
+	rg.GET("/devices", h.listDevices)
+	rg.POST("/devices/:deviceId/reset", h.resetDevice)
 }
 
 func (h *Handler) push(c *gin.Context) {
@@ -29,6 +40,24 @@ func (h *Handler) push(c *gin.Context) {
 
 	userID := c.GetString("user_id")
 	resp, err := h.service.Push(c.Request.Context(), userID, &req)
+	var maintenanceErr *MaintenanceError
+	if errors.As(err, &maintenanceErr) {
+		c.Header("Retry-After", strconv.Itoa(maintenanceErr.RetryAfter))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":       err.Error(),
+			"maintenance": true,
+			"retry_after": maintenanceErr.RetryAfter,
+		})
+		return
+	}
+	if errors.Is(err, occurrence.ErrClockOffset) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, limits.ErrExceeded) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -40,20 +69,84 @@ func (h *Handler) pull(c *gin.Context) {
 	userID := c.GetString("user_id")
 	lastSync := c.Query("last_sync")
 
-	resp, err := h.service.Pull(c.Request.Context(), userID, lastSync)
+	resp, err := h.service.Pull(c.Request.Context(), userID, c.Query("client_id"), lastSync)
+	if errors.Is(err, delta.ErrInvalidSince) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "last_sync must be an RFC 3339 timestamp"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	page := envelope.Pagination{HasMore: resp.HasMore}
+	if resp.HasMore {
+		page.Next = resp.ServerTime
+	}
+	envelope.SetPagination(c, page)
 	c.JSON(http.StatusOK, resp)
 }
 
 func (h *Handler) status(c *gin.Context) {
 	userID := c.GetString("user_id")
-	status, err := h.service.Status(c.Request.Context(), userID)
+	status, err := h.service.Status(c.Request.Context(), userID, c.Query("client_id"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, status)
 }
+
+func (h *Handler) listConflicts(c *gin.Context) {
+	userID := c.GetString("user_id")
+	conflicts, err := h.service.ListConflicts(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, conflicts)
+}
+
+func (h *Handler) resolveConflict(c *gin.Context) {
+	var req ResolveConflictRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	conflict, err := h.service.ResolveConflict(c.Request.Context(), userID, c.Param("id"), &req)
+	switch {
+	case errors.Is(err, ErrConflictNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrConflictResolved):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidResolution):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, conflict)
+	}
+}
+
+func (h *Handler) listDevices(c *gin.Context) {
+	devices, err := h.service.ListDevices(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, devices)
+}
+
+func (h *Handler) resetDevice(c *gin.Context) {
+	device, err := h.service.ResetDevice(c.Request.Context(), c.GetString("user_id"), c.Param("deviceId"))
+	if errors.Is(err, ErrDeviceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, device)
+}
@@ -5,11 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/limits"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,8 +25,14 @@ func init() {
 // mockSyncService implements the Service interface for testing
 type mockSyncService struct {
 	pushFn   func(ctx context.Context, userID string, req *PushRequest) (*PushResponse, error)
-	pullFn   func(ctx context.Context, userID string, lastSync string) (*PullResponse, error)
-	statusFn func(ctx context.Context, userID string) (*SyncStatus, error)
+	pullFn   func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error)
+	statusFn func(ctx context.Context, userID, clientID string) (*SyncStatus, error)
+
+	listConflictsFn   func(ctx context.Context, userID string) ([]Conflict, error)
+	resolveConflictFn func(ctx context.Context, userID, id string, req *ResolveConflictRequest) (*Conflict, error)
+
+	listDevicesFn func(ctx context.Context, userID string) ([]DeviceState, error)
+	resetDeviceFn func(ctx context.Context, userID, deviceID string) (*DeviceState, error)
 }
 
 func (m *mockSyncService) Push(ctx context.Context, userID string, req *PushRequest) (*PushResponse, error) {
@@ -31,16 +42,44 @@ func (m *mockSyncService) Push(ctx context.Context, userID string, req *PushRequ
 	return nil, nil
 }
 
-func (m *mockSyncService) Pull(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+func (m *mockSyncService) Pull(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 	if m.pullFn != nil {
-		return m.pullFn(ctx, userID, lastSync)
+		return m.pullFn(ctx, userID, clientID, lastSync)
 	}
 	return nil, nil
 }
 
-func (m *mockSyncService) Status(ctx context.Context, userID string) (*SyncStatus, error) {
+func (m *mockSyncService) Status(ctx context.Context, userID, clientID string) (*SyncStatus, error) {
 	if m.statusFn != nil {
-		return m.statusFn(ctx, userID)
+		return m.statusFn(ctx, userID, clientID)
+	}
+	return nil, nil
+}
+
+func (m *mockSyncService) ListConflicts(ctx context.Context, userID string) ([]Conflict, error) {
+	if m.listConflictsFn != nil {
+		return m.listConflictsFn(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockSyncService) ResolveConflict(ctx context.Context, userID, id string, req *ResolveConflictRequest) (*Conflict, error) {
+	if m.resolveConflictFn != nil {
+		return m.resolveConflictFn(ctx, userID, id, req)
+	}
+	return nil, nil
+}
+
+func (m *mockSyncService) ListDevices(ctx context.Context, userID string) ([]DeviceState, error) {
+	if m.listDevicesFn != nil {
+		return m.listDevicesFn(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockSyncService) ResetDevice(ctx context.Context, userID, deviceID string) (*DeviceState, error) {
+	if m.resetDeviceFn != nil {
+		return m.resetDeviceFn(ctx, userID, deviceID)
 	}
 	return nil, nil
 }
@@ -48,6 +87,7 @@ func (m *mockSyncService) Status(ctx context.Context, userID string) (*SyncStatu
 // setupRouter creates a test router with the handler registered
 func setupRouter(svc Service) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 	handler := NewHandler(svc)
 
 	// Add middleware to set user_id in context (simulating auth)
@@ -64,6 +104,7 @@ func setupRouter(svc Service) *gin.Engine {
 // setupRouterWithUserID creates a test router with a custom user_id
 func setupRouterWithUserID(svc Service, userID string) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 	handler := NewHandler(svc)
 
 	router.Use(func(c *gin.Context) {
@@ -174,6 +215,47 @@ func TestPush_Success(t *testing.T) {
 	}
 }
 
+func TestPush_Maintenance(t *testing.T) {
+	svc := &mockSyncService{
+		pushFn: func(ctx context.Context, userID string, req *PushRequest) (*PushResponse, error) {
+			return nil, &MaintenanceError{RetryAfter: 120}
+		},
+	}
+	router := setupRouter(svc)
+
+	body, _ := json.Marshal(samplePushRequest())
+	req := httptest.NewRequest("POST", "/sync/push", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "120" {
+		t.Errorf("Expected Retry-After 120, got %q", w.Header().Get("Retry-After"))
+	}
+}
+
+func TestPush_TooManyEvents(t *testing.T) {
+	svc := &mockSyncService{
+		pushFn: func(ctx context.Context, userID string, req *PushRequest) (*PushResponse, error) {
+			return nil, limits.Items("events", 600, 500)
+		},
+	}
+	router := setupRouter(svc)
+
+	body, _ := json.Marshal(samplePushRequest())
+	req := httptest.NewRequest("POST", "/sync/push", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+}
+
 func TestPush_ServiceError(t *testing.T) {
 	svc := &mockSyncService{
 		pushFn: func(ctx context.Context, userID string, req *PushRequest) (*PushResponse, error) {
@@ -436,7 +518,7 @@ func TestPush_WithPartialFailures(t *testing.T) {
 func TestPull_Success(t *testing.T) {
 	pullResp := samplePullResponse()
 	svc := &mockSyncService{
-		pullFn: func(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 			return pullResp, nil
 		},
 	}
@@ -464,7 +546,7 @@ func TestPull_Success(t *testing.T) {
 
 func TestPull_ServiceError(t *testing.T) {
 	svc := &mockSyncService{
-		pullFn: func(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 			return nil, errors.New("database connection failed")
 		},
 	}
@@ -487,10 +569,27 @@ func TestPull_ServiceError(t *testing.T) {
 	}
 }
 
+func TestPull_InvalidLastSync(t *testing.T) {
+	svc := &mockSyncService{
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
+			return nil, fmt.Errorf("%w: %q", delta.ErrInvalidSince, lastSync)
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/sync/pull?last_sync=yesterday", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
 func TestPull_VerifiesUserIDFromContext(t *testing.T) {
 	var capturedUserID string
 	svc := &mockSyncService{
-		pullFn: func(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 			capturedUserID = userID
 			return samplePullResponse(), nil
 		},
@@ -509,7 +608,7 @@ func TestPull_VerifiesUserIDFromContext(t *testing.T) {
 func TestPull_VerifiesLastSyncQueryParam(t *testing.T) {
 	var capturedLastSync string
 	svc := &mockSyncService{
-		pullFn: func(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 			capturedLastSync = lastSync
 			return samplePullResponse(), nil
 		},
@@ -528,7 +627,7 @@ func TestPull_VerifiesLastSyncQueryParam(t *testing.T) {
 func TestPull_EmptyLastSync(t *testing.T) {
 	var capturedLastSync string
 	svc := &mockSyncService{
-		pullFn: func(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 			capturedLastSync = lastSync
 			return samplePullResponse(), nil
 		},
@@ -549,7 +648,7 @@ func TestPull_EmptyLastSync(t *testing.T) {
 
 func TestPull_EmptyEvents(t *testing.T) {
 	svc := &mockSyncService{
-		pullFn: func(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 			return &PullResponse{
 				Events:     []Event{},
 				ServerTime: time.Now().UTC().Format(time.RFC3339),
@@ -578,7 +677,7 @@ func TestPull_EmptyEvents(t *testing.T) {
 
 func TestPull_HasMoreTrue(t *testing.T) {
 	svc := &mockSyncService{
-		pullFn: func(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 			return &PullResponse{
 				Events: []Event{
 					{ID: "event-1", Type: EventTypeFeeding, Action: "create"},
@@ -609,7 +708,7 @@ func TestPull_HasMoreTrue(t *testing.T) {
 
 func TestPull_MultipleEvents(t *testing.T) {
 	svc := &mockSyncService{
-		pullFn: func(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 			return &PullResponse{
 				Events: []Event{
 					{ID: "event-1", Type: EventTypeFeeding, Action: "create"},
@@ -647,7 +746,7 @@ func TestPull_MultipleEvents(t *testing.T) {
 func TestStatus_Success(t *testing.T) {
 	statusResp := sampleSyncStatus()
 	svc := &mockSyncService{
-		statusFn: func(ctx context.Context, userID string) (*SyncStatus, error) {
+		statusFn: func(ctx context.Context, userID, clientID string) (*SyncStatus, error) {
 			return statusResp, nil
 		},
 	}
@@ -675,7 +774,7 @@ func TestStatus_Success(t *testing.T) {
 
 func TestStatus_ServiceError(t *testing.T) {
 	svc := &mockSyncService{
-		statusFn: func(ctx context.Context, userID string) (*SyncStatus, error) {
+		statusFn: func(ctx context.Context, userID, clientID string) (*SyncStatus, error) {
 			return nil, errors.New("failed to get sync status")
 		},
 	}
@@ -701,7 +800,7 @@ func TestStatus_ServiceError(t *testing.T) {
 func TestStatus_VerifiesUserIDFromContext(t *testing.T) {
 	var capturedUserID string
 	svc := &mockSyncService{
-		statusFn: func(ctx context.Context, userID string) (*SyncStatus, error) {
+		statusFn: func(ctx context.Context, userID, clientID string) (*SyncStatus, error) {
 			capturedUserID = userID
 			return sampleSyncStatus(), nil
 		},
@@ -719,7 +818,7 @@ func TestStatus_VerifiesUserIDFromContext(t *testing.T) {
 
 func TestStatus_EmptyLastSync(t *testing.T) {
 	svc := &mockSyncService{
-		statusFn: func(ctx context.Context, userID string) (*SyncStatus, error) {
+		statusFn: func(ctx context.Context, userID, clientID string) (*SyncStatus, error) {
 			return &SyncStatus{
 				LastSync:   "",
 				Pending:    0,
@@ -748,7 +847,7 @@ func TestStatus_EmptyLastSync(t *testing.T) {
 
 func TestStatus_ZeroPending(t *testing.T) {
 	svc := &mockSyncService{
-		statusFn: func(ctx context.Context, userID string) (*SyncStatus, error) {
+		statusFn: func(ctx context.Context, userID, clientID string) (*SyncStatus, error) {
 			return &SyncStatus{
 				LastSync:   time.Now().UTC().Format(time.RFC3339),
 				Pending:    0,
@@ -784,10 +883,10 @@ func TestRegisterRoutes(t *testing.T) {
 		pushFn: func(ctx context.Context, userID string, req *PushRequest) (*PushResponse, error) {
 			return samplePushResponse(), nil
 		},
-		pullFn: func(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 			return samplePullResponse(), nil
 		},
-		statusFn: func(ctx context.Context, userID string) (*SyncStatus, error) {
+		statusFn: func(ctx context.Context, userID, clientID string) (*SyncStatus, error) {
 			return sampleSyncStatus(), nil
 		},
 	}
@@ -1028,7 +1127,7 @@ func TestPush_LargePayload(t *testing.T) {
 func TestPull_ResponseContainsServerTime(t *testing.T) {
 	serverTime := "2025-12-28T15:30:00Z"
 	svc := &mockSyncService{
-		pullFn: func(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
 			return &PullResponse{
 				Events:     []Event{},
 				ServerTime: serverTime,
@@ -1054,7 +1153,7 @@ func TestPull_ResponseContainsServerTime(t *testing.T) {
 func TestStatus_ResponseContainsServerTime(t *testing.T) {
 	serverTime := "2025-12-28T15:30:00Z"
 	svc := &mockSyncService{
-		statusFn: func(ctx context.Context, userID string) (*SyncStatus, error) {
+		statusFn: func(ctx context.Context, userID, clientID string) (*SyncStatus, error) {
 			return &SyncStatus{
 				LastSync:   "",
 				Pending:    0,
@@ -1106,3 +1205,155 @@ func TestPush_ResponseContainsServerTime(t *testing.T) {
 		t.Errorf("Expected ServerTime %s, got %s", serverTime, result.ServerTime)
 	}
 }
+
+func TestListConflicts(t *testing.T) {
+	svc := &mockSyncService{
+		listConflictsFn: func(ctx context.Context, userID string) ([]Conflict, error) {
+			if userID != "test-user-123" {
+				t.Errorf("userID = %s, want test-user-123", userID)
+			}
+			return []Conflict{{ID: "conflict-1", EntityType: EventTypeFeeding}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/sync/conflicts", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var result []Conflict
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(result) != 1 || result[0].ID != "conflict-1" {
+		t.Errorf("Expected conflict-1, got %+v", result)
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"resolved", `{"keep":"client"}`, nil, http.StatusOK},
+		{"missing keep", `{}`, nil, http.StatusBadRequest},
+		{"invalid keep", `{"keep":"both"}`, ErrInvalidResolution, http.StatusBadRequest},
+		{"not found", `{"keep":"server"}`, ErrConflictNotFound, http.StatusNotFound},
+		{"already resolved", `{"keep":"server"}`, ErrConflictResolved, http.StatusConflict},
+		{"service error", `{"keep":"client"}`, errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockSyncService{
+				resolveConflictFn: func(ctx context.Context, userID, id string, req *ResolveConflictRequest) (*Conflict, error) {
+					if id != "conflict-1" {
+						t.Errorf("id = %s, want conflict-1", id)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &Conflict{ID: id, Resolution: req.Keep}, nil
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/sync/conflicts/conflict-1/resolve", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPullAndStatus_PassClientID(t *testing.T) {
+	var pulled, checked string
+	svc := &mockSyncService{
+		pullFn: func(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
+			pulled = clientID
+			return &PullResponse{Events: []Event{}}, nil
+		},
+		statusFn: func(ctx context.Context, userID, clientID string) (*SyncStatus, error) {
+			checked = clientID
+			return &SyncStatus{}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	for _, path := range []string{"/sync/pull?client_id=phone-1", "/sync/status?client_id=phone-1"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, http.NoBody))
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s status = %d, want %d", path, w.Code, http.StatusOK)
+		}
+	}
+	if pulled != "phone-1" || checked != "phone-1" {
+		t.Errorf("client IDs = %q, %q; want phone-1", pulled, checked)
+	}
+}
+
+func TestListDevices(t *testing.T) {
+	svc := &mockSyncService{
+		listDevicesFn: func(ctx context.Context, userID string) ([]DeviceState, error) {
+			return []DeviceState{{DeviceID: "phone-1", LastToken: "2025-01-01T00:00:00Z"}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/sync/devices", http.NoBody))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var result []DeviceState
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(result) != 1 || result[0].DeviceID != "phone-1" {
+		t.Errorf("Expected phone-1, got %+v", result)
+	}
+}
+
+func TestResetDevice(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"reset", nil, http.StatusOK},
+		{"unknown device", ErrDeviceNotFound, http.StatusNotFound},
+		{"service error", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockSyncService{
+				resetDeviceFn: func(ctx context.Context, userID, deviceID string) (*DeviceState, error) {
+					if deviceID != "phone-1" {
+						t.Errorf("deviceID = %s, want phone-1", deviceID)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &DeviceState{DeviceID: deviceID, ResetPending: true}, nil
+				},
+			}
+			router := setupRouter(svc)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/sync/devices/phone-1/reset", http.NoBody))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrConflictNotFound  = errors.New("conflict not found")
+	ErrConflictResolved  = errors.New("conflict already resolved")
+	ErrInvalidResolution = errors.New("keep must be client or server")
+	ErrDeviceNotFound    = errors.New("sync device not found")
+)
+
+// Which version a conflict is settled in favour of
+const (
+	KeepClient = "client"
+	KeepServer = "server"
+)
+
+// Conflict is an offline edit that lost to a newer server version during
+// push. ClientData is the losing edit as it would have been applied, so it
+// can be replayed unchanged.
+type Conflict struct {
+	ID         string          `json:"id"`
+	UserID     string          `json:"user_id"`
+	EventID    string          `json:"event_id"`
+	EntityType EventType       `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	ClientData json.RawMessage `json:"client_data"`
+	ServerData json.RawMessage `json:"server_data"`
+	ClientTime time.Time       `json:"client_time"` // when the edit was made offline
+	ServerTime time.Time       `json:"server_time"` // when the winning version was saved
+	Resolution string          `json:"resolution,omitempty"`
+	ResolvedAt *time.Time      `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type ResolveConflictRequest struct {
+	Keep string `json:"keep" binding:"required"`
+}
+
+// DeviceState is the sync checkpoint of one client, identified by the
+// client_id it syncs with. LastToken is the server_time of its last pull.
+type DeviceState struct {
+	DeviceID   string    `json:"device_id"`
+	LastToken  string    `json:"last_token,omitempty"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// ResetPending is set by a reset and cleared by the device's next pull,
+	// which starts again from the beginning
+	ResetPending bool       `json:"reset_pending"`
+	ResetAt      *time.Time `json:"reset_at,omitempty"`
+}
@@ -0,0 +1,228 @@
+package sync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type ConflictRepository interface {
+	Create(ctx context.Context, c *Conflict) error
+	Get(ctx context.Context, id string) (*Conflict, error)
+	// ListOpen returns the user's unresolved conflicts, newest first
+	ListOpen(ctx context.Context, userID string, limit int) ([]Conflict, error)
+	// Resolve marks an open conflict resolved, reporting false if it was
+	// resolved already
+	Resolve(ctx context.Context, id, resolution string, at time.Time) (bool, error)
+}
+
+type conflictRepository struct {
+	db *sql.DB
+}
+
+func NewConflictRepository(db *sql.DB) ConflictRepository {
+	return &conflictRepository{db: db}
+}
+
+// HealthCheck checks the conflicts table
+func (r *conflictRepository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "sync_conflicts")
+}
+
+const conflictColumns = `id, user_id, event_id, entity_type, entity_id, client_data, server_data,
+		       client_time, server_time, COALESCE(resolution, ''), resolved_at, created_at`
+
+func (r *conflictRepository) Create(ctx context.Context, c *Conflict) error {
+	query := `
+		INSERT INTO sync_conflicts (id, user_id, event_id, entity_type, entity_id, client_data, server_data, client_time, server_time, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		c.ID, c.UserID, c.EventID, c.EntityType, c.EntityID,
+		[]byte(c.ClientData), []byte(c.ServerData), c.ClientTime, c.ServerTime, c.CreatedAt,
+	)
+	return err
+}
+
+func (r *conflictRepository) Get(ctx context.Context, id string) (*Conflict, error) {
+	query := `SELECT ` + conflictColumns + ` FROM sync_conflicts WHERE id = $1`
+
+	c, err := scanConflict(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *conflictRepository) ListOpen(ctx context.Context, userID string, limit int) ([]Conflict, error) {
+	query := `
+		SELECT ` + conflictColumns + `
+		FROM sync_conflicts
+		WHERE user_id = $1 AND resolved_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	conflicts := []Conflict{}
+	for rows.Next() {
+		c, err := scanConflict(rows)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, *c)
+	}
+	return conflicts, rows.Err()
+}
+
+func (r *conflictRepository) Resolve(ctx context.Context, id, resolution string, at time.Time) (bool, error) {
+	query := `UPDATE sync_conflicts SET resolution = $2, resolved_at = $3 WHERE id = $1 AND resolved_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, resolution, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+type DeviceRepository interface {
+	// Touch records that the device synced. A non-empty token moves its
+	// checkpoint and clears a pending reset.
+	Touch(ctx context.Context, userID, deviceID, token string, at time.Time) error
+	Get(ctx context.Context, userID, deviceID string) (*DeviceState, error)
+	List(ctx context.Context, userID string) ([]DeviceState, error)
+	// Reset drops the device's checkpoint, reporting false if the device is
+	// unknown
+	Reset(ctx context.Context, userID, deviceID string, at time.Time) (bool, error)
+}
+
+type deviceRepository struct {
+	db *sql.DB
+}
+
+func NewDeviceRepository(db *sql.DB) DeviceRepository {
+	return &deviceRepository{db: db}
+}
+
+// HealthCheck checks the devices table that sync checkpoints are kept in
+func (r *deviceRepository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "sync_devices")
+}
+
+const deviceColumns = `device_id, COALESCE(last_token, ''), last_seen_at, reset_pending, reset_at`
+
+func (r *deviceRepository) Touch(ctx context.Context, userID, deviceID, token string, at time.Time) error {
+	query := `
+		INSERT INTO sync_devices (user_id, device_id, last_token, last_seen_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, device_id) DO UPDATE SET
+			last_seen_at = EXCLUDED.last_seen_at,
+			last_token = COALESCE(EXCLUDED.last_token, sync_devices.last_token),
+			reset_pending = sync_devices.reset_pending AND EXCLUDED.last_token IS NULL
+	`
+
+	lastToken := sql.NullString{String: token, Valid: token != ""}
+	_, err := r.db.ExecContext(ctx, query, userID, deviceID, lastToken, at)
+	return err
+}
+
+func (r *deviceRepository) Get(ctx context.Context, userID, deviceID string) (*DeviceState, error) {
+	query := `SELECT ` + deviceColumns + ` FROM sync_devices WHERE user_id = $1 AND device_id = $2`
+
+	d, err := scanDevice(r.db.QueryRowContext(ctx, query, userID, deviceID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *deviceRepository) List(ctx context.Context, userID string) ([]DeviceState, error) {
+	query := `SELECT ` + deviceColumns + ` FROM sync_devices WHERE user_id = $1 ORDER BY last_seen_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	devices := []DeviceState{}
+	for rows.Next() {
+		d, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, *d)
+	}
+	return devices, rows.Err()
+}
+
+func (r *deviceRepository) Reset(ctx context.Context, userID, deviceID string, at time.Time) (bool, error) {
+	query := `
+		UPDATE sync_devices
+		SET last_token = NULL, reset_pending = TRUE, reset_at = $3
+		WHERE user_id = $1 AND device_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, deviceID, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func scanDevice(row rowScanner) (*DeviceState, error) {
+	var d DeviceState
+	var resetAt sql.NullTime
+	if err := row.Scan(&d.DeviceID, &d.LastToken, &d.LastSeenAt, &d.ResetPending, &resetAt); err != nil {
+		return nil, err
+	}
+	if resetAt.Valid {
+		d.ResetAt = &resetAt.Time
+	}
+	return &d, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanConflict(row rowScanner) (*Conflict, error) {
+	var c Conflict
+	var clientData, serverData []byte
+	var resolvedAt sql.NullTime
+	if err := row.Scan(
+		&c.ID, &c.UserID, &c.EventID, &c.EntityType, &c.EntityID, &clientData, &serverData,
+		&c.ClientTime, &c.ServerTime, &c.Resolution, &resolvedAt, &c.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	c.ClientData = clientData
+	c.ServerData = serverData
+	if resolvedAt.Valid {
+		c.ResolvedAt = &resolvedAt.Time
+	}
+	return &c, nil
+}
@@ -0,0 +1,180 @@
+package sync
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var conflictRowColumns = []string{
+	"id", "user_id", "event_id", "entity_type", "entity_id", "client_data", "server_data",
+	"client_time", "server_time", "resolution", "resolved_at", "created_at",
+}
+
+func TestConflictRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewConflictRepository(db)
+
+	now := time.Now()
+	c := &Conflict{
+		ID: "conflict-1", UserID: "user-1", EventID: "event-1", EntityType: EventTypeFeeding, EntityID: "feeding-1",
+		ClientData: json.RawMessage(`{"type":"bottle"}`), ServerData: json.RawMessage(`{"type":"breast"}`),
+		ClientTime: now.Add(-time.Hour), ServerTime: now, CreatedAt: now,
+	}
+	mock.ExpectExec("INSERT INTO sync_conflicts").
+		WithArgs("conflict-1", "user-1", "event-1", EventTypeFeeding, "feeding-1",
+			[]byte(`{"type":"bottle"}`), []byte(`{"type":"breast"}`), c.ClientTime, now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), c); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestConflictRepository_Get(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewConflictRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("FROM sync_conflicts WHERE id = \\$1").
+		WithArgs("conflict-1").
+		WillReturnRows(sqlmock.NewRows(conflictRowColumns).
+			AddRow("conflict-1", "user-1", "event-1", "feeding", "feeding-1", []byte(`{}`), []byte(`{}`), now, now, "server", now, now))
+	mock.ExpectQuery("FROM sync_conflicts WHERE id = \\$1").
+		WithArgs("conflict-2").
+		WillReturnRows(sqlmock.NewRows(conflictRowColumns))
+
+	c, err := repo.Get(context.Background(), "conflict-1")
+	if err != nil || c == nil || c.Resolution != KeepServer || c.ResolvedAt == nil {
+		t.Errorf("Get() = %+v, %v", c, err)
+	}
+	c, err = repo.Get(context.Background(), "conflict-2")
+	if err != nil || c != nil {
+		t.Errorf("Get() = %v, %v; want nil, nil", c, err)
+	}
+}
+
+func TestConflictRepository_ListOpen(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewConflictRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("WHERE user_id = \\$1 AND resolved_at IS NULL").
+		WithArgs("user-1", 100).
+		WillReturnRows(sqlmock.NewRows(conflictRowColumns).
+			AddRow("conflict-1", "user-1", "event-1", "sleep", "sleep-1", []byte(`{}`), []byte(`{}`), now, now, "", nil, now))
+
+	conflicts, err := repo.ListOpen(context.Background(), "user-1", 100)
+	if err != nil {
+		t.Fatalf("ListOpen() error = %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].EntityType != EventTypeSleep || conflicts[0].ResolvedAt != nil {
+		t.Errorf("ListOpen() = %+v", conflicts)
+	}
+}
+
+func TestConflictRepository_Resolve(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewConflictRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE sync_conflicts SET resolution").
+		WithArgs("conflict-1", KeepClient, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE sync_conflicts SET resolution").
+		WithArgs("conflict-1", KeepClient, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if ok, err := repo.Resolve(context.Background(), "conflict-1", KeepClient, now); err != nil || !ok {
+		t.Errorf("Resolve() = %v, %v; want true", ok, err)
+	}
+	if ok, err := repo.Resolve(context.Background(), "conflict-1", KeepClient, now); err != nil || ok {
+		t.Errorf("Resolve() again = %v, %v; want false", ok, err)
+	}
+}
+
+func TestDeviceRepository_Touch(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewDeviceRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO sync_devices .* ON CONFLICT \\(user_id, device_id\\) DO UPDATE").
+		WithArgs("user-1", "phone-1", sql.NullString{String: "2025-01-01T00:00:00Z", Valid: true}, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO sync_devices").
+		WithArgs("user-1", "phone-1", sql.NullString{}, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Touch(context.Background(), "user-1", "phone-1", "2025-01-01T00:00:00Z", now); err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+	if err := repo.Touch(context.Background(), "user-1", "phone-1", "", now); err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestDeviceRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewDeviceRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("FROM sync_devices WHERE user_id = \\$1 ORDER BY last_seen_at DESC").
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"device_id", "last_token", "last_seen_at", "reset_pending", "reset_at"}).
+			AddRow("phone-1", "2025-01-01T00:00:00Z", now, false, nil).
+			AddRow("tablet-1", "", now, true, now))
+
+	devices, err := repo.List(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(devices) != 2 || devices[0].ResetAt != nil || !devices[1].ResetPending || devices[1].ResetAt == nil {
+		t.Errorf("List() = %+v", devices)
+	}
+}
+
+func TestDeviceRepository_Reset(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewDeviceRepository(db)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE sync_devices").
+		WithArgs("user-1", "phone-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE sync_devices").
+		WithArgs("user-1", "phone-2", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if ok, err := repo.Reset(context.Background(), "user-1", "phone-1", now); err != nil || !ok {
+		t.Errorf("Reset() = %v, %v; want true", ok, err)
+	}
+	if ok, err := repo.Reset(context.Background(), "user-1", "phone-2", now); err != nil || ok {
+		t.Errorf("Reset() of unknown device = %v, %v; want false", ok, err)
+	}
+}
@@ -3,15 +3,30 @@ package sync
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
 	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/maintenance"
 	"github.com/ninenine/babytrack/internal/medication"
 	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/replay"
 	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
+// eventReplayWindow is how long a processed event ID is remembered. Clients
+// retry pending events for far less than this.
+const eventReplayWindow = 30 * 24 * time.Hour
+
 type EventType string
 
 const (
@@ -37,92 +52,239 @@ type Event struct {
 type PushRequest struct {
 	Events   []Event `json:"events"`
 	ClientID string  `json:"client_id"`
+	// ClockOffsetMs is how far the client clock runs ahead of the server's,
+	// measured by the client against server_time. Event times are shifted by
+	// it before they are stored.
+	ClockOffsetMs int64 `json:"clock_offset_ms,omitempty"`
 }
 
 type PushResponse struct {
 	Processed  int               `json:"processed"`
 	Failed     int               `json:"failed"`
 	FailedIDs  []string          `json:"failed_ids,omitempty"`
-	Results    map[string]string `json:"results,omitempty"` // eventID -> new server ID
+	Replayed   []string          `json:"replayed,omitempty"` // events already applied by an earlier push
+	Results    map[string]string `json:"results,omitempty"`  // eventID -> new server ID
 	ServerTime string            `json:"server_time"`
+	// ClockOffsetMs is the offset that was applied, zero if it was too small
+	// to matter
+	ClockOffsetMs int64 `json:"clock_offset_ms,omitempty"`
+	// Suspicious lists events dated in the future even after the clock
+	// offset was applied; they are clamped to the server time or rejected
+	Suspicious []string `json:"suspicious,omitempty"`
+	// Conflicts lists updates that lost to a newer server version. They are
+	// not applied but kept under GET /sync/conflicts.
+	Conflicts []string `json:"conflicts,omitempty"`
 }
 
 type PullResponse struct {
 	Events     []Event `json:"events"`
 	ServerTime string  `json:"server_time"`
 	HasMore    bool    `json:"has_more"`
+	// Reset tells the client its sync state was reset, or that last_sync is
+	// older than deletions are kept: last_sync was ignored and it should
+	// replace its local data with what follows
+	Reset bool `json:"reset,omitempty"`
 }
 
 type SyncStatus struct {
 	LastSync   string `json:"last_sync"`
 	Pending    int    `json:"pending"`
 	ServerTime string `json:"server_time"`
+	// Maintenance tells clients to hold their pending events and check back
+	// after RetryAfter seconds instead of pushing
+	Maintenance bool `json:"maintenance"`
+	RetryAfter  int  `json:"retry_after,omitempty"`
+	// Reset is set when the client's sync state was reset and its next pull
+	// starts from the beginning
+	Reset bool `json:"reset,omitempty"`
+}
+
+// MaintenanceError is returned by Push while maintenance mode is on
+type MaintenanceError struct {
+	RetryAfter int
+}
+
+func (e *MaintenanceError) Error() string {
+	return "sync is paused for maintenance"
 }
 
 type Service interface {
 	Push(ctx context.Context, userID string, req *PushRequest) (*PushResponse, error)
-	Pull(ctx context.Context, userID string, lastSync string) (*PullResponse, error)
-	Status(ctx context.Context, userID string) (*SyncStatus, error)
+	Pull(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error)
+	Status(ctx context.Context, userID, clientID string) (*SyncStatus, error)
+
+	ListConflicts(ctx context.Context, userID string) ([]Conflict, error)
+	ResolveConflict(ctx context.Context, userID, id string, req *ResolveConflictRequest) (*Conflict, error)
+
+	ListDevices(ctx context.Context, userID string) ([]DeviceState, error)
+	ResetDevice(ctx context.Context, userID, deviceID string) (*DeviceState, error)
 }
 
+// maxOpenConflicts caps how many unresolved conflicts are listed at once
+const maxOpenConflicts = 100
+
 type service struct {
+	replay            replay.Store
 	feedingService    feeding.Service
 	sleepService      sleep.Service
 	medicationService medication.Service
 	notesService      notes.Service
+	maintenance       *maintenance.Switch
+	conflicts         ConflictRepository
+	devices           DeviceRepository
+	tombstones        delta.Tombstones
+	familyService     family.Service
+	visibility        visibility.Service
+	now               func() time.Time
+}
+
+type Option func(*service)
+
+// WithConflicts keeps the losing side of each update conflict so it can be
+// listed and restored. Without it the losing edit is dropped.
+func WithConflicts(repo ConflictRepository) Option {
+	return func(s *service) {
+		s.conflicts = repo
+	}
+}
+
+// WithMaintenance pauses pushes while maintenance mode is on
+func WithMaintenance(sw *maintenance.Switch) Option {
+	return func(s *service) {
+		s.maintenance = sw
+	}
+}
+
+// WithDevices keeps a sync checkpoint for each client ID, so a single
+// client's state can be inspected and reset
+func WithDevices(repo DeviceRepository) Option {
+	return func(s *service) {
+		s.devices = repo
+	}
 }
 
 func NewService(
+	replayStore replay.Store,
 	feedingService feeding.Service,
 	sleepService sleep.Service,
 	medicationService medication.Service,
 	notesService notes.Service,
+	opts ...Option,
 ) Service {
-	return &service{
+	s := &service{
+		replay:            replayStore,
 		feedingService:    feedingService,
 		sleepService:      sleepService,
 		medicationService: medicationService,
 		notesService:      notesService,
+		now:               time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
+}
+
+// HealthCheck reports whether pushes can be deduplicated and whatever the
+// options added can be read: conflicts, device checkpoints and tombstones
+func (s *service) HealthCheck(ctx context.Context) error {
+	return errors.Join(
+		health.Of(ctx, s.replay),
+		health.Of(ctx, s.conflicts),
+		health.Of(ctx, s.devices),
+		health.Of(ctx, s.tombstones),
+	)
 }
 
 func (s *service) Push(ctx context.Context, userID string, req *PushRequest) (*PushResponse, error) {
+	if s.maintenance != nil {
+		if mode := s.maintenance.Get(); mode.Enabled {
+			return nil, &MaintenanceError{RetryAfter: mode.RetryAfterSeconds()}
+		}
+	}
+
+	// Clients with a longer queue push it in several goes
+	if err := limits.Items("events", len(req.Events), limits.Get().BulkItems); err != nil {
+		return nil, err
+	}
+
+	clock, err := occurrence.NewClock(time.Duration(req.ClockOffsetMs)*time.Millisecond, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	resp := &PushResponse{
-		Results:    make(map[string]string),
-		ServerTime: time.Now().UTC().Format(time.RFC3339),
+		Results:       make(map[string]string),
+		ServerTime:    clock.Now.UTC().Format(time.RFC3339),
+		ClockOffsetMs: clock.Offset.Milliseconds(),
 	}
 
 	for _, event := range req.Events {
-		err := s.processEvent(ctx, userID, &event, resp)
-		if err != nil {
+		clock.Suspicious = false
+		replayed, err := s.pushEvent(ctx, userID, &event, clock, resp)
+		if clock.Suspicious {
+			log.Printf("[sync] event %s from %s is dated in the future after a %s clock offset", event.ID, userID, clock.Offset)
+			resp.Suspicious = append(resp.Suspicious, event.ID)
+		}
+		switch {
+		case err != nil:
 			resp.Failed++
 			resp.FailedIDs = append(resp.FailedIDs, event.ID)
-		} else {
+		case replayed:
+			// Already applied; report success so the client drops it
+			resp.Processed++
+			resp.Replayed = append(resp.Replayed, event.ID)
+		default:
 			resp.Processed++
 		}
 	}
 
+	s.touchDevice(ctx, userID, req.ClientID, "")
+
 	return resp, nil
 }
 
-func (s *service) processEvent(ctx context.Context, userID string, event *Event, resp *PushResponse) error {
+// pushEvent applies an event at most once per user. The event ID is claimed
+// before processing and released again on failure so the client can retry.
+func (s *service) pushEvent(ctx context.Context, userID string, event *Event, clock *occurrence.Clock, resp *PushResponse) (replayed bool, err error) {
+	if event.ID == "" {
+		return false, fmt.Errorf("event id is required")
+	}
+
+	scope := "sync:" + userID
+	fresh, err := s.replay.Claim(ctx, scope, event.ID, time.Now().Add(eventReplayWindow))
+	if err != nil {
+		return false, fmt.Errorf("failed to record event: %w", err)
+	}
+	if !fresh {
+		return true, nil
+	}
+
+	if err := s.processEvent(ctx, userID, event, clock, resp); err != nil {
+		return false, errors.Join(err, s.replay.Release(ctx, scope, event.ID))
+	}
+
+	return false, nil
+}
+
+func (s *service) processEvent(ctx context.Context, userID string, event *Event, clock *occurrence.Clock, resp *PushResponse) error {
 	switch event.Type {
 	case EventTypeFeeding:
-		return s.processFeedingEvent(ctx, event, resp)
+		return s.processFeedingEvent(ctx, userID, event, clock, resp)
 	case EventTypeSleep:
-		return s.processSleepEvent(ctx, event, resp)
+		return s.processSleepEvent(ctx, userID, event, clock, resp)
 	case EventTypeMedication:
-		return s.processMedicationEvent(ctx, event, resp)
+		return s.processMedicationEvent(ctx, userID, event, clock, resp)
 	case EventTypeMedicationLog:
-		return s.processMedicationLogEvent(ctx, userID, event, resp)
+		return s.processMedicationLogEvent(ctx, userID, event, clock, resp)
 	case EventTypeNote:
-		return s.processNoteEvent(ctx, userID, event, resp)
+		return s.processNoteEvent(ctx, userID, event, clock, resp)
 	default:
 		return fmt.Errorf("unknown event type: %s", event.Type)
 	}
 }
 
-func (s *service) processFeedingEvent(ctx context.Context, event *Event, resp *PushResponse) error {
+func (s *service) processFeedingEvent(ctx context.Context, userID string, event *Event, clock *occurrence.Clock, resp *PushResponse) error {
 	dataBytes, err := json.Marshal(event.Data)
 	if err != nil {
 		return err
@@ -134,6 +296,9 @@ func (s *service) processFeedingEvent(ctx context.Context, event *Event, resp *P
 		if err := json.Unmarshal(dataBytes, &req); err != nil {
 			return err
 		}
+		clock.Fix(&req.StartTime)
+		clock.Fix(req.EndTime)
+		req.AuthorID = userID
 		result, err := s.feedingService.Create(ctx, &req)
 		if err != nil {
 			return err
@@ -146,18 +311,29 @@ func (s *service) processFeedingEvent(ctx context.Context, event *Event, resp *P
 		if err := json.Unmarshal(dataBytes, &req); err != nil {
 			return err
 		}
-		_, err := s.feedingService.Update(ctx, event.EntityID, &req)
+		clock.Fix(&req.StartTime)
+		clock.Fix(req.EndTime)
+		current, err := s.feedingService.Get(ctx, userID, event.EntityID)
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			if lost, err := s.lostToServer(ctx, userID, event, clock, &req, current, current.UpdatedAt, resp); err != nil || lost {
+				return err
+			}
+		}
+		_, err = s.feedingService.Update(ctx, userID, event.EntityID, &req)
 		return err
 
 	case "delete":
-		return s.feedingService.Delete(ctx, event.EntityID)
+		return s.feedingService.Delete(ctx, userID, event.EntityID)
 
 	default:
 		return fmt.Errorf("unknown action: %s", event.Action)
 	}
 }
 
-func (s *service) processSleepEvent(ctx context.Context, event *Event, resp *PushResponse) error {
+func (s *service) processSleepEvent(ctx context.Context, userID string, event *Event, clock *occurrence.Clock, resp *PushResponse) error {
 	dataBytes, err := json.Marshal(event.Data)
 	if err != nil {
 		return err
@@ -169,6 +345,9 @@ func (s *service) processSleepEvent(ctx context.Context, event *Event, resp *Pus
 		if err := json.Unmarshal(dataBytes, &req); err != nil {
 			return err
 		}
+		clock.Fix(&req.StartTime)
+		clock.Fix(req.EndTime)
+		req.StartedBy = userID
 		result, err := s.sleepService.Create(ctx, &req)
 		if err != nil {
 			return err
@@ -181,18 +360,29 @@ func (s *service) processSleepEvent(ctx context.Context, event *Event, resp *Pus
 		if err := json.Unmarshal(dataBytes, &req); err != nil {
 			return err
 		}
-		_, err := s.sleepService.Update(ctx, event.EntityID, &req)
+		clock.Fix(&req.StartTime)
+		clock.Fix(req.EndTime)
+		current, err := s.sleepService.Get(ctx, userID, event.EntityID)
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			if lost, err := s.lostToServer(ctx, userID, event, clock, &req, current, current.UpdatedAt, resp); err != nil || lost {
+				return err
+			}
+		}
+		_, err = s.sleepService.Update(ctx, userID, event.EntityID, &req)
 		return err
 
 	case "delete":
-		return s.sleepService.Delete(ctx, event.EntityID)
+		return s.sleepService.Delete(ctx, userID, event.EntityID)
 
 	default:
 		return fmt.Errorf("unknown action: %s", event.Action)
 	}
 }
 
-func (s *service) processMedicationEvent(ctx context.Context, event *Event, resp *PushResponse) error {
+func (s *service) processMedicationEvent(ctx context.Context, userID string, event *Event, clock *occurrence.Clock, resp *PushResponse) error {
 	dataBytes, err := json.Marshal(event.Data)
 	if err != nil {
 		return err
@@ -216,21 +406,30 @@ func (s *service) processMedicationEvent(ctx context.Context, event *Event, resp
 		if err := json.Unmarshal(dataBytes, &req); err != nil {
 			return err
 		}
-		_, err := s.medicationService.Update(ctx, event.EntityID, &req)
+		current, err := s.medicationService.Get(ctx, userID, event.EntityID)
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			if lost, err := s.lostToServer(ctx, userID, event, clock, &req, current, current.UpdatedAt, resp); err != nil || lost {
+				return err
+			}
+		}
+		_, err = s.medicationService.Update(ctx, userID, event.EntityID, &req)
 		return err
 
 	case "delete":
-		return s.medicationService.Delete(ctx, event.EntityID)
+		return s.medicationService.Delete(ctx, userID, event.EntityID)
 
 	case "deactivate":
-		return s.medicationService.Deactivate(ctx, event.EntityID)
+		return s.medicationService.Deactivate(ctx, userID, event.EntityID)
 
 	default:
 		return fmt.Errorf("unknown action: %s", event.Action)
 	}
 }
 
-func (s *service) processMedicationLogEvent(ctx context.Context, userID string, event *Event, resp *PushResponse) error {
+func (s *service) processMedicationLogEvent(ctx context.Context, userID string, event *Event, clock *occurrence.Clock, resp *PushResponse) error {
 	dataBytes, err := json.Marshal(event.Data)
 	if err != nil {
 		return err
@@ -242,6 +441,7 @@ func (s *service) processMedicationLogEvent(ctx context.Context, userID string,
 		if err := json.Unmarshal(dataBytes, &req); err != nil {
 			return err
 		}
+		clock.Fix(&req.GivenAt)
 		result, err := s.medicationService.LogMedication(ctx, userID, &req)
 		if err != nil {
 			return err
@@ -254,7 +454,7 @@ func (s *service) processMedicationLogEvent(ctx context.Context, userID string,
 	}
 }
 
-func (s *service) processNoteEvent(ctx context.Context, userID string, event *Event, resp *PushResponse) error {
+func (s *service) processNoteEvent(ctx context.Context, userID string, event *Event, clock *occurrence.Clock, resp *PushResponse) error {
 	dataBytes, err := json.Marshal(event.Data)
 	if err != nil {
 		return err
@@ -266,6 +466,7 @@ func (s *service) processNoteEvent(ctx context.Context, userID string, event *Ev
 		if err := json.Unmarshal(dataBytes, &req); err != nil {
 			return err
 		}
+		clock.Fix(req.OccurredAt)
 		result, err := s.notesService.Create(ctx, userID, &req)
 		if err != nil {
 			return err
@@ -278,30 +479,240 @@ func (s *service) processNoteEvent(ctx context.Context, userID string, event *Ev
 		if err := json.Unmarshal(dataBytes, &req); err != nil {
 			return err
 		}
-		_, err := s.notesService.Update(ctx, event.EntityID, &req)
+		clock.Fix(req.OccurredAt)
+		if err := s.notesService.Authorize(ctx, userID, event.EntityID); err != nil {
+			return err
+		}
+		current, err := s.notesService.Get(ctx, userID, event.EntityID)
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			if lost, err := s.lostToServer(ctx, userID, event, clock, &req, current, current.UpdatedAt, resp); err != nil || lost {
+				return err
+			}
+		}
+		_, err = s.notesService.Update(ctx, userID, event.EntityID, &req)
 		return err
 
 	case "delete":
-		return s.notesService.Delete(ctx, event.EntityID)
+		if err := s.notesService.Authorize(ctx, userID, event.EntityID); err != nil {
+			return err
+		}
+		return s.notesService.Delete(ctx, userID, event.EntityID)
 
 	default:
 		return fmt.Errorf("unknown action for note: %s", event.Action)
 	}
 }
 
-func (s *service) Pull(ctx context.Context, userID string, lastSync string) (*PullResponse, error) {
-	// For now, return empty - pull sync is more complex and requires
-	// tracking server-side changes. The push-first approach handles
-	// the primary offline use case.
-	return &PullResponse{
+// lostToServer settles an offline update by last write wins: the update
+// loses when the stored record was saved after the client made the edit.
+// The losing edit is kept as a conflict and the record is left alone.
+// Events without a timestamp always win.
+func (s *service) lostToServer(ctx context.Context, userID string, event *Event, clock *occurrence.Clock, edit, current any, updatedAt time.Time, resp *PushResponse) (bool, error) {
+	if event.Timestamp.IsZero() {
+		return false, nil
+	}
+	editedAt := event.Timestamp.Add(-clock.Offset)
+	if !updatedAt.After(editedAt) {
+		return false, nil
+	}
+
+	resp.Conflicts = append(resp.Conflicts, event.ID)
+	if s.conflicts == nil {
+		return true, nil
+	}
+
+	clientData, err := json.Marshal(edit)
+	if err != nil {
+		return false, err
+	}
+	serverData, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+	conflict := &Conflict{
+		ID:         ids.New(),
+		UserID:     userID,
+		EventID:    event.ID,
+		EntityType: event.Type,
+		EntityID:   event.EntityID,
+		ClientData: clientData,
+		ServerData: serverData,
+		ClientTime: editedAt.UTC(),
+		ServerTime: updatedAt.UTC(),
+		CreatedAt:  s.now(),
+	}
+	if err := s.conflicts.Create(ctx, conflict); err != nil {
+		resp.Conflicts = resp.Conflicts[:len(resp.Conflicts)-1]
+		return false, fmt.Errorf("failed to record conflict: %w", err)
+	}
+	return true, nil
+}
+
+func (s *service) ListConflicts(ctx context.Context, userID string) ([]Conflict, error) {
+	if s.conflicts == nil {
+		return []Conflict{}, nil
+	}
+	return s.conflicts.ListOpen(ctx, userID, maxOpenConflicts)
+}
+
+// ResolveConflict settles a conflict. Keeping the client version replays
+// the losing edit over the current record; keeping the server version only
+// closes the conflict.
+func (s *service) ResolveConflict(ctx context.Context, userID, id string, req *ResolveConflictRequest) (*Conflict, error) {
+	if req.Keep != KeepClient && req.Keep != KeepServer {
+		return nil, ErrInvalidResolution
+	}
+	if s.conflicts == nil {
+		return nil, ErrConflictNotFound
+	}
+
+	conflict, err := s.conflicts.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if conflict == nil || conflict.UserID != userID {
+		return nil, ErrConflictNotFound
+	}
+	if conflict.ResolvedAt != nil {
+		return nil, ErrConflictResolved
+	}
+
+	if req.Keep == KeepClient {
+		var data map[string]any
+		if err := json.Unmarshal(conflict.ClientData, &data); err != nil {
+			return nil, err
+		}
+		// No timestamp, so the edit is applied without another conflict check
+		event := &Event{
+			ID:       conflict.EventID,
+			Type:     conflict.EntityType,
+			Action:   "update",
+			EntityID: conflict.EntityID,
+			Data:     data,
+		}
+		clock := &occurrence.Clock{Now: s.now()}
+		if err := s.processEvent(ctx, userID, event, clock, &PushResponse{Results: make(map[string]string)}); err != nil {
+			return nil, fmt.Errorf("failed to restore edit: %w", err)
+		}
+	}
+
+	now := s.now()
+	resolved, err := s.conflicts.Resolve(ctx, id, req.Keep, now)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return nil, ErrConflictResolved
+	}
+	conflict.Resolution = req.Keep
+	conflict.ResolvedAt = &now
+	return conflict, nil
+}
+
+func (s *service) Pull(ctx context.Context, userID, clientID, lastSync string) (*PullResponse, error) {
+	now := s.now()
+	resp := &PullResponse{
 		Events:     []Event{},
-		ServerTime: time.Now().UTC().Format(time.RFC3339),
+		ServerTime: now.UTC().Format(time.RFC3339),
 		HasMore:    false,
-	}, nil
+	}
+
+	since, err := delta.ParseSince(lastSync)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := s.device(ctx, userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	// A reset device starts over whatever last_sync it still sends
+	resp.Reset = device != nil && device.ResetPending
+
+	// Only deletions are pulled; creates and updates travel by push and
+	// the list endpoints. A last_sync older than the retention may have
+	// missed deletions, so the client starts over instead.
+	if since != nil && !resp.Reset && s.tombstones != nil {
+		if delta.Check(*since, now, s.tombstones.Retention()) != nil {
+			resp.Reset = true
+		} else {
+			deleted, err := s.deletedSince(ctx, userID, *since)
+			if err != nil {
+				return nil, err
+			}
+			resp.Events = append(resp.Events, deleted...)
+		}
+	}
+
+	s.touchDevice(ctx, userID, clientID, resp.ServerTime)
+	return resp, nil
 }
 
-func (s *service) Status(ctx context.Context, userID string) (*SyncStatus, error) {
-	return &SyncStatus{
+func (s *service) Status(ctx context.Context, userID, clientID string) (*SyncStatus, error) {
+	status := &SyncStatus{
 		ServerTime: time.Now().UTC().Format(time.RFC3339),
-	}, nil
+	}
+	device, err := s.device(ctx, userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if device != nil {
+		status.LastSync = device.LastToken
+		status.Reset = device.ResetPending
+	}
+	s.touchDevice(ctx, userID, clientID, "")
+
+	if s.maintenance != nil {
+		if mode := s.maintenance.Get(); mode.Enabled {
+			status.Maintenance = true
+			status.RetryAfter = mode.RetryAfterSeconds()
+		}
+	}
+	return status, nil
+}
+
+func (s *service) ListDevices(ctx context.Context, userID string) ([]DeviceState, error) {
+	if s.devices == nil {
+		return []DeviceState{}, nil
+	}
+	return s.devices.List(ctx, userID)
+}
+
+// ResetDevice drops one device's checkpoint. Its next pull ignores the
+// cursor it sends and starts from the beginning; the user's other devices
+// are not affected.
+func (s *service) ResetDevice(ctx context.Context, userID, deviceID string) (*DeviceState, error) {
+	if s.devices == nil {
+		return nil, ErrDeviceNotFound
+	}
+	found, err := s.devices.Reset(ctx, userID, deviceID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrDeviceNotFound
+	}
+	return s.devices.Get(ctx, userID, deviceID)
+}
+
+// device returns the client's checkpoint, or nil when it has none
+func (s *service) device(ctx context.Context, userID, clientID string) (*DeviceState, error) {
+	if s.devices == nil || clientID == "" {
+		return nil, nil
+	}
+	return s.devices.Get(ctx, userID, clientID)
+}
+
+// touchDevice records that the client synced. Failures are logged rather
+// than failing the sync itself.
+func (s *service) touchDevice(ctx context.Context, userID, clientID, token string) {
+	if s.devices == nil || clientID == "" {
+		return
+	}
+	if err := s.devices.Touch(ctx, userID, clientID, token, s.now()); err != nil {
+		log.Printf("[sync] failed to record checkpoint for device %s of %s: %v", clientID, userID, err)
+	}
 }
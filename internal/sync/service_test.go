@@ -3,13 +3,23 @@ package sync
 import (
 	"context"
 	"errors"
+	"iter"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
 	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/maintenance"
 	"github.com/ninenine/babytrack/internal/medication"
 	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/occurrence"
 	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/testutil/factory"
+	"github.com/ninenine/babytrack/internal/visibility"
 )
 
 // Mock services for testing
@@ -36,12 +46,17 @@ func (m *mockFeedingService) Create(ctx context.Context, req *feeding.CreateFeed
 		ChildID:   req.ChildID,
 		Type:      req.Type,
 		StartTime: req.StartTime,
+		AuthorID:  req.AuthorID,
+		Private:   req.Private,
 	}
 	m.feedings[f.ID] = f
 	return f, nil
 }
 
-func (m *mockFeedingService) Get(ctx context.Context, id string) (*feeding.Feeding, error) {
+func (m *mockFeedingService) Get(ctx context.Context, userID, id string) (*feeding.Feeding, error) {
+	if f := m.feedings[id]; f != nil && !f.VisibleTo(userID) {
+		return nil, nil
+	}
 	return m.feedings[id], nil
 }
 
@@ -49,19 +64,23 @@ func (m *mockFeedingService) List(ctx context.Context, filter *feeding.FeedingFi
 	return nil, nil
 }
 
-func (m *mockFeedingService) Update(ctx context.Context, id string, req *feeding.CreateFeedingRequest) (*feeding.Feeding, error) {
+func (m *mockFeedingService) Stream(ctx context.Context, filter *feeding.FeedingFilter) iter.Seq2[feeding.Feeding, error] {
+	return nil
+}
+
+func (m *mockFeedingService) Update(ctx context.Context, userID, id string, req *feeding.CreateFeedingRequest) (*feeding.Feeding, error) {
 	if m.updateErr != nil {
 		return nil, m.updateErr
 	}
 	f, ok := m.feedings[id]
-	if !ok {
-		return nil, errors.New("not found")
+	if !ok || !f.VisibleTo(userID) {
+		return nil, feeding.ErrFeedingNotFound
 	}
 	f.Type = req.Type
 	return f, nil
 }
 
-func (m *mockFeedingService) Delete(ctx context.Context, id string) error {
+func (m *mockFeedingService) Delete(ctx context.Context, userID, id string) error {
 	if m.deleteErr != nil {
 		return m.deleteErr
 	}
@@ -69,7 +88,59 @@ func (m *mockFeedingService) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *mockFeedingService) GetLastFeeding(ctx context.Context, childID string) (*feeding.Feeding, error) {
+func (m *mockFeedingService) GetLastFeeding(ctx context.Context, childID, viewerID string) (*feeding.Feeding, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) GetGoal(ctx context.Context, childID string) (*feeding.Goal, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) ListGoals(ctx context.Context) ([]feeding.Goal, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) SetPrivate(ctx context.Context, userID, id string, private bool) error {
+	return nil
+}
+
+func (m *mockFeedingService) SetGoal(ctx context.Context, userID, childID string, req *feeding.SetGoalRequest) (*feeding.Goal, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) DeleteGoal(ctx context.Context, childID string) error {
+	return nil
+}
+
+func (m *mockFeedingService) GetStats(ctx context.Context, childID string, days int) (*feeding.Stats, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) GetFormulaGuide(ctx context.Context, childID string, req *feeding.FormulaGuideRequest) (*feeding.FormulaGuide, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) StartNursing(ctx context.Context, userID string, req *feeding.StartNursingRequest) (*feeding.NursingTimer, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) GetNursing(ctx context.Context, userID, childID string) (*feeding.NursingTimer, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) PauseNursing(ctx context.Context, userID, childID string) (*feeding.NursingTimer, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) ResumeNursing(ctx context.Context, userID, childID string, side feeding.Side) (*feeding.NursingTimer, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) SwitchNursingSide(ctx context.Context, userID, childID string, side feeding.Side) (*feeding.NursingTimer, error) {
+	return nil, nil
+}
+
+func (m *mockFeedingService) StopNursing(ctx context.Context, userID, childID string) (*feeding.NursingTimer, error) {
 	return nil, nil
 }
 
@@ -95,12 +166,14 @@ func (m *mockSleepService) Create(ctx context.Context, req *sleep.CreateSleepReq
 		ChildID:   req.ChildID,
 		Type:      req.Type,
 		StartTime: req.StartTime,
+		StartedBy: req.StartedBy,
+		Private:   req.Private,
 	}
 	m.sleeps[s.ID] = s
 	return s, nil
 }
 
-func (m *mockSleepService) Get(ctx context.Context, id string) (*sleep.Sleep, error) {
+func (m *mockSleepService) Get(ctx context.Context, userID, id string) (*sleep.Sleep, error) {
 	return m.sleeps[id], nil
 }
 
@@ -108,7 +181,11 @@ func (m *mockSleepService) List(ctx context.Context, filter *sleep.SleepFilter)
 	return nil, nil
 }
 
-func (m *mockSleepService) Update(ctx context.Context, id string, req *sleep.CreateSleepRequest) (*sleep.Sleep, error) {
+func (m *mockSleepService) Stream(ctx context.Context, filter *sleep.SleepFilter) iter.Seq2[sleep.Sleep, error] {
+	return nil
+}
+
+func (m *mockSleepService) Update(ctx context.Context, userID, id string, req *sleep.CreateSleepRequest) (*sleep.Sleep, error) {
 	if m.updateErr != nil {
 		return nil, m.updateErr
 	}
@@ -120,7 +197,7 @@ func (m *mockSleepService) Update(ctx context.Context, id string, req *sleep.Cre
 	return s, nil
 }
 
-func (m *mockSleepService) Delete(ctx context.Context, id string) error {
+func (m *mockSleepService) Delete(ctx context.Context, userID, id string) error {
 	if m.deleteErr != nil {
 		return m.deleteErr
 	}
@@ -128,18 +205,50 @@ func (m *mockSleepService) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *mockSleepService) StartSleep(ctx context.Context, childID string, sleepType sleep.SleepType) (*sleep.Sleep, error) {
+func (m *mockSleepService) StartSleep(ctx context.Context, userID, childID string, sleepType sleep.SleepType) (*sleep.Sleep, error) {
 	return nil, nil
 }
 
-func (m *mockSleepService) EndSleep(ctx context.Context, id string) (*sleep.Sleep, error) {
+func (m *mockSleepService) EndSleep(ctx context.Context, userID, id string) (*sleep.Sleep, error) {
 	return nil, nil
 }
 
-func (m *mockSleepService) GetActiveSleep(ctx context.Context, childID string) (*sleep.Sleep, error) {
+func (m *mockSleepService) GetActiveSleep(ctx context.Context, childID, viewerID string) (*sleep.Sleep, error) {
 	return nil, nil
 }
 
+func (m *mockSleepService) ListConflicts(ctx context.Context, childID string, from, to time.Time) ([]sleep.Conflict, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) Reconcile(ctx context.Context, childID string, from, to time.Time) (*sleep.ReconcileResult, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) Merge(ctx context.Context, userID, firstID, secondID string) (*sleep.EditResult, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) Split(ctx context.Context, userID, id string, at time.Time) (*sleep.EditResult, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) AddWaking(ctx context.Context, userID, sleepID string, req *sleep.WakingRequest) (*sleep.Sleep, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) EndWaking(ctx context.Context, userID, sleepID, wakingID string) (*sleep.Sleep, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) RemoveWaking(ctx context.Context, userID, sleepID, wakingID string) (*sleep.Sleep, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) SetPrivate(ctx context.Context, userID, id string, private bool) error {
+	return nil
+}
+
 type mockMedicationService struct {
 	medications map[string]*medication.Medication
 	logs        map[string]*medication.MedicationLog
@@ -169,15 +278,23 @@ func (m *mockMedicationService) Create(ctx context.Context, req *medication.Crea
 	return med, nil
 }
 
-func (m *mockMedicationService) Get(ctx context.Context, id string) (*medication.Medication, error) {
+func (m *mockMedicationService) Get(ctx context.Context, userID, id string) (*medication.Medication, error) {
 	return m.medications[id], nil
 }
 
+func (m *mockMedicationService) ListSince(ctx context.Context, filter *medication.MedicationFilter, since time.Time) (*delta.Changes[medication.Medication], error) {
+	return nil, nil
+}
+
 func (m *mockMedicationService) List(ctx context.Context, filter *medication.MedicationFilter) ([]medication.Medication, error) {
 	return nil, nil
 }
 
-func (m *mockMedicationService) Update(ctx context.Context, id string, req *medication.CreateMedicationRequest) (*medication.Medication, error) {
+func (m *mockMedicationService) Stream(ctx context.Context, filter *medication.MedicationFilter) iter.Seq2[medication.Medication, error] {
+	return nil
+}
+
+func (m *mockMedicationService) Update(ctx context.Context, userID, id string, req *medication.CreateMedicationRequest) (*medication.Medication, error) {
 	if m.updateErr != nil {
 		return nil, m.updateErr
 	}
@@ -189,7 +306,7 @@ func (m *mockMedicationService) Update(ctx context.Context, id string, req *medi
 	return med, nil
 }
 
-func (m *mockMedicationService) Delete(ctx context.Context, id string) error {
+func (m *mockMedicationService) Delete(ctx context.Context, userID, id string) error {
 	if m.deleteErr != nil {
 		return m.deleteErr
 	}
@@ -197,7 +314,7 @@ func (m *mockMedicationService) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *mockMedicationService) Deactivate(ctx context.Context, id string) error {
+func (m *mockMedicationService) Deactivate(ctx context.Context, userID, id string) error {
 	med, ok := m.medications[id]
 	if !ok {
 		return errors.New("not found")
@@ -219,11 +336,23 @@ func (m *mockMedicationService) LogMedication(ctx context.Context, userID string
 	return log, nil
 }
 
-func (m *mockMedicationService) GetLogs(ctx context.Context, medicationID string) ([]medication.MedicationLog, error) {
+func (m *mockMedicationService) GetLogs(ctx context.Context, medicationID, viewerID string) ([]medication.MedicationLog, error) {
+	return nil, nil
+}
+
+func (m *mockMedicationService) CorrectLog(ctx context.Context, userID, medicationID, logID string, req *medication.CorrectLogRequest) (*medication.MedicationLog, error) {
+	return nil, nil
+}
+
+func (m *mockMedicationService) ListLogCorrections(ctx context.Context, userID, medicationID, logID string) ([]corrections.Correction, error) {
 	return nil, nil
 }
 
-func (m *mockMedicationService) GetLastLog(ctx context.Context, medicationID string) (*medication.MedicationLog, error) {
+func (m *mockMedicationService) SetLogPrivate(ctx context.Context, userID, medicationID, logID string, private bool) error {
+	return nil
+}
+
+func (m *mockMedicationService) GetLastLog(ctx context.Context, medicationID, viewerID string) (*medication.MedicationLog, error) {
 	return nil, nil
 }
 
@@ -254,15 +383,26 @@ func (m *mockNotesService) Create(ctx context.Context, userID string, req *notes
 	return n, nil
 }
 
-func (m *mockNotesService) Get(ctx context.Context, id string) (*notes.Note, error) {
+func (m *mockNotesService) Get(ctx context.Context, userID, id string) (*notes.Note, error) {
 	return m.notes[id], nil
 }
 
+func (m *mockNotesService) GetVisible(ctx context.Context, id, userID string) (*notes.Note, error) {
+	if n := m.notes[id]; n != nil && n.VisibleTo(userID) {
+		return n, nil
+	}
+	return nil, nil
+}
+
 func (m *mockNotesService) List(ctx context.Context, filter *notes.NoteFilter) ([]notes.Note, error) {
 	return nil, nil
 }
 
-func (m *mockNotesService) Update(ctx context.Context, id string, req *notes.UpdateNoteRequest) (*notes.Note, error) {
+func (m *mockNotesService) Stream(ctx context.Context, filter *notes.NoteFilter) iter.Seq2[notes.Note, error] {
+	return nil
+}
+
+func (m *mockNotesService) Update(ctx context.Context, userID, id string, req *notes.UpdateNoteRequest) (*notes.Note, error) {
 	if m.updateErr != nil {
 		return nil, m.updateErr
 	}
@@ -274,7 +414,7 @@ func (m *mockNotesService) Update(ctx context.Context, id string, req *notes.Upd
 	return n, nil
 }
 
-func (m *mockNotesService) Delete(ctx context.Context, id string) error {
+func (m *mockNotesService) Delete(ctx context.Context, userID, id string) error {
 	if m.deleteErr != nil {
 		return m.deleteErr
 	}
@@ -286,10 +426,138 @@ func (m *mockNotesService) Pin(ctx context.Context, id string, pinned bool) erro
 	return nil
 }
 
-func (m *mockNotesService) Search(ctx context.Context, childID, query string) ([]notes.Note, error) {
+func (m *mockNotesService) Search(ctx context.Context, childID, query, viewerID string) ([]notes.Note, error) {
 	return nil, nil
 }
 
+func (m *mockNotesService) MarkSeen(ctx context.Context, id, userID string) error {
+	return nil
+}
+
+func (m *mockNotesService) Authorize(ctx context.Context, userID, id string) error {
+	if n, ok := m.notes[id]; ok && !n.VisibleTo(userID) {
+		return notes.ErrNoteNotFound
+	}
+	return nil
+}
+
+func (m *mockNotesService) SetPrivate(ctx context.Context, userID, id string, private bool) error {
+	return nil
+}
+
+type mockReplayStore struct {
+	claimed map[string]bool
+}
+
+func newMockReplayStore() *mockReplayStore {
+	return &mockReplayStore{claimed: make(map[string]bool)}
+}
+
+func (m *mockReplayStore) Claim(ctx context.Context, scope, nonce string, expiresAt time.Time) (bool, error) {
+	if m.claimed[scope+"/"+nonce] {
+		return false, nil
+	}
+	m.claimed[scope+"/"+nonce] = true
+	return true, nil
+}
+
+func (m *mockReplayStore) Release(ctx context.Context, scope, nonce string) error {
+	delete(m.claimed, scope+"/"+nonce)
+	return nil
+}
+
+func (m *mockReplayStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type mockConflictRepo struct {
+	conflicts map[string]*Conflict
+}
+
+func newMockConflictRepo() *mockConflictRepo {
+	return &mockConflictRepo{conflicts: make(map[string]*Conflict)}
+}
+
+func (m *mockConflictRepo) Create(ctx context.Context, c *Conflict) error {
+	m.conflicts[c.ID] = c
+	return nil
+}
+
+func (m *mockConflictRepo) Get(ctx context.Context, id string) (*Conflict, error) {
+	return m.conflicts[id], nil
+}
+
+func (m *mockConflictRepo) ListOpen(ctx context.Context, userID string, limit int) ([]Conflict, error) {
+	list := []Conflict{}
+	for _, c := range m.conflicts {
+		if c.UserID == userID && c.ResolvedAt == nil {
+			list = append(list, *c)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockConflictRepo) Resolve(ctx context.Context, id, resolution string, at time.Time) (bool, error) {
+	c := m.conflicts[id]
+	if c == nil || c.ResolvedAt != nil {
+		return false, nil
+	}
+	c.Resolution = resolution
+	c.ResolvedAt = &at
+	return true, nil
+}
+
+type mockDeviceRepo struct {
+	devices map[string]*DeviceState // keyed by user/device
+}
+
+func newMockDeviceRepo() *mockDeviceRepo {
+	return &mockDeviceRepo{devices: make(map[string]*DeviceState)}
+}
+
+func (m *mockDeviceRepo) Touch(ctx context.Context, userID, deviceID, token string, at time.Time) error {
+	d := m.devices[userID+"/"+deviceID]
+	if d == nil {
+		d = &DeviceState{DeviceID: deviceID}
+		m.devices[userID+"/"+deviceID] = d
+	}
+	d.LastSeenAt = at
+	if token != "" {
+		d.LastToken = token
+		d.ResetPending = false
+	}
+	return nil
+}
+
+func (m *mockDeviceRepo) Get(ctx context.Context, userID, deviceID string) (*DeviceState, error) {
+	if d := m.devices[userID+"/"+deviceID]; d != nil {
+		copied := *d
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockDeviceRepo) List(ctx context.Context, userID string) ([]DeviceState, error) {
+	list := []DeviceState{}
+	for key, d := range m.devices {
+		if strings.HasPrefix(key, userID+"/") {
+			list = append(list, *d)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockDeviceRepo) Reset(ctx context.Context, userID, deviceID string, at time.Time) (bool, error) {
+	d := m.devices[userID+"/"+deviceID]
+	if d == nil {
+		return false, nil
+	}
+	d.LastToken = ""
+	d.ResetPending = true
+	d.ResetAt = &at
+	return true, nil
+}
+
 // Tests
 
 func TestService_Push_FeedingCreate(t *testing.T) {
@@ -298,7 +566,7 @@ func TestService_Push_FeedingCreate(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -333,6 +601,10 @@ func TestService_Push_FeedingCreate(t *testing.T) {
 	if resp.Results["event-1"] != "feeding-new-id" {
 		t.Errorf("Push() Results[event-1] = %v, want feeding-new-id", resp.Results["event-1"])
 	}
+
+	if f := feedingSvc.feedings["feeding-new-id"]; f == nil || f.AuthorID != "user-123" {
+		t.Errorf("Push() should log the feeding as the pushing member's, got %+v", f)
+	}
 }
 
 func TestService_Push_FeedingUpdate(t *testing.T) {
@@ -342,7 +614,7 @@ func TestService_Push_FeedingUpdate(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -372,6 +644,44 @@ func TestService_Push_FeedingUpdate(t *testing.T) {
 	}
 }
 
+func TestService_Push_PrivateFeedingOfAnotherMember(t *testing.T) {
+	feedingSvc := newMockFeedingService()
+	feedingSvc.feedings["feeding-123"] = &feeding.Feeding{ID: "feeding-123", Type: feeding.FeedingTypeBottle, AuthorID: "user-456", Private: true}
+
+	svc := NewService(newMockReplayStore(), feedingSvc, newMockSleepService(), newMockMedicationService(), newMockNotesService())
+
+	req := &PushRequest{
+		ClientID: "client-123",
+		Events: []Event{
+			{
+				ID:        "event-1",
+				Type:      EventTypeFeeding,
+				Action:    "update",
+				EntityID:  "feeding-123",
+				Timestamp: time.Now(),
+				Data: map[string]any{
+					"child_id":   "child-123",
+					"type":       "breast",
+					"start_time": time.Now().Format(time.RFC3339),
+				},
+			},
+		},
+	}
+
+	resp, err := svc.Push(context.Background(), "user-123", req)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	if resp.Failed != 1 {
+		t.Errorf("Push() Failed = %d, want 1", resp.Failed)
+	}
+
+	if feedingSvc.feedings["feeding-123"].Type != feeding.FeedingTypeBottle {
+		t.Error("Push() should not change another member's private feeding")
+	}
+}
+
 func TestService_Push_FeedingDelete(t *testing.T) {
 	feedingSvc := newMockFeedingService()
 	feedingSvc.feedings["feeding-123"] = &feeding.Feeding{ID: "feeding-123"}
@@ -379,7 +689,7 @@ func TestService_Push_FeedingDelete(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -414,7 +724,7 @@ func TestService_Push_SleepCreate(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -445,6 +755,10 @@ func TestService_Push_SleepCreate(t *testing.T) {
 	if resp.Results["event-1"] != "sleep-new-id" {
 		t.Errorf("Push() Results[event-1] = %v, want sleep-new-id", resp.Results["event-1"])
 	}
+
+	if s := sleepSvc.sleeps["sleep-new-id"]; s == nil || s.StartedBy != "user-123" {
+		t.Errorf("Push() should log the sleep as started by the pushing member, got %+v", s)
+	}
 }
 
 func TestService_Push_MedicationCreate(t *testing.T) {
@@ -453,7 +767,7 @@ func TestService_Push_MedicationCreate(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -496,7 +810,7 @@ func TestService_Push_MedicationDeactivate(t *testing.T) {
 	medSvc.medications["med-123"] = &medication.Medication{ID: "med-123", Active: true}
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -531,7 +845,7 @@ func TestService_Push_MedicationLog(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -570,7 +884,7 @@ func TestService_Push_NoteCreate(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -609,7 +923,7 @@ func TestService_Push_NoteUpdate(t *testing.T) {
 	notesSvc := newMockNotesService()
 	notesSvc.notes["note-123"] = &notes.Note{ID: "note-123", Content: "Original"}
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -644,7 +958,7 @@ func TestService_Push_NoteDelete(t *testing.T) {
 	notesSvc := newMockNotesService()
 	notesSvc.notes["note-123"] = &notes.Note{ID: "note-123"}
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -673,13 +987,46 @@ func TestService_Push_NoteDelete(t *testing.T) {
 	}
 }
 
+func TestService_Push_NoteDelete_OtherMembersPrivateNote(t *testing.T) {
+	notesSvc := newMockNotesService()
+	private := factory.Note(func(n *notes.Note) { n.ID, n.AuthorID, n.Private = "note-123", "user-456", true })
+	notesSvc.notes[private.ID] = &private
+
+	svc := NewService(newMockReplayStore(), newMockFeedingService(), newMockSleepService(), newMockMedicationService(), notesSvc)
+
+	req := &PushRequest{
+		ClientID: "client-123",
+		Events: []Event{
+			{
+				ID:        "event-1",
+				Type:      EventTypeNote,
+				Action:    "delete",
+				EntityID:  "note-123",
+				Timestamp: time.Now(),
+			},
+		},
+	}
+
+	resp, err := svc.Push(context.Background(), "user-123", req)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	if resp.Processed != 0 {
+		t.Errorf("Push() Processed = %d, want 0", resp.Processed)
+	}
+	if notesSvc.notes["note-123"] == nil {
+		t.Error("Push() should not delete another member's private note")
+	}
+}
+
 func TestService_Push_MultipleEvents(t *testing.T) {
 	feedingSvc := newMockFeedingService()
 	sleepSvc := newMockSleepService()
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -740,7 +1087,7 @@ func TestService_Push_WithFailures(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -793,7 +1140,7 @@ func TestService_Push_UnknownEventType(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -823,7 +1170,7 @@ func TestService_Push_UnknownAction(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -854,9 +1201,9 @@ func TestService_Pull(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
-	resp, err := svc.Pull(context.Background(), "user-123", "2024-01-01T00:00:00Z")
+	resp, err := svc.Pull(context.Background(), "user-123", "", "2024-01-01T00:00:00Z")
 	if err != nil {
 		t.Fatalf("Pull() error = %v", err)
 	}
@@ -870,15 +1217,120 @@ func TestService_Pull(t *testing.T) {
 	}
 }
 
+// mockFamilyService is a test double for family.Service
+type mockFamilyService struct {
+	family.Service
+	families map[string][]family.FamilyWithChildren // userID -> families
+}
+
+func (m *mockFamilyService) GetUserFamilies(ctx context.Context, userID string) ([]family.FamilyWithChildren, error) {
+	return m.families[userID], nil
+}
+
+// mockTombstones is a test double for delta.Tombstones
+type mockTombstones struct {
+	delta.Tombstones
+	stones []delta.Tombstone
+}
+
+func (m *mockTombstones) Since(ctx context.Context, scopeIDs []string, since time.Time) ([]delta.Tombstone, error) {
+	var out []delta.Tombstone
+	for _, t := range m.stones {
+		if t.DeletedAt.After(since) && slices.Contains(scopeIDs, t.ScopeID) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockTombstones) Retention() time.Duration {
+	return 7 * 24 * time.Hour
+}
+
+func TestService_Pull_Deletions(t *testing.T) {
+	now := time.Now()
+	tombstones := &mockTombstones{stones: []delta.Tombstone{
+		{EntityType: delta.EntityChild, EntityID: "child-2", ScopeID: "family-1", DeletedAt: now.Add(-time.Hour)},
+		{EntityType: delta.EntityFeeding, EntityID: "feeding-1", ScopeID: "child-1", DeletedAt: now.Add(-30 * time.Minute)},
+		{EntityType: delta.EntityNote, EntityID: "note-old", ScopeID: "child-1", DeletedAt: now.Add(-3 * time.Hour)},
+		{EntityType: delta.EntitySleep, EntityID: "sleep-other", ScopeID: "child-other", DeletedAt: now.Add(-time.Minute)},
+	}}
+	families := &mockFamilyService{families: map[string][]family.FamilyWithChildren{
+		"user-123": {{ID: factory.FamilyID, Children: []family.Child{factory.Child()}}},
+	}}
+	svc := NewService(newMockReplayStore(), newMockFeedingService(), newMockSleepService(), newMockMedicationService(), newMockNotesService(),
+		WithTombstones(tombstones, families))
+	ctx := context.Background()
+
+	resp, err := svc.Pull(ctx, "user-123", "", now.Add(-2*time.Hour).Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if resp.Reset || len(resp.Events) != 2 {
+		t.Fatalf("Pull() = %+v, want 2 delete events", resp)
+	}
+	if e := resp.Events[0]; e.Type != EventTypeChild || e.Action != "delete" || e.EntityID != "child-2" {
+		t.Errorf("Pull() first event = %+v, want child-2 deleted", e)
+	}
+	if e := resp.Events[1]; e.Type != EventTypeFeeding || e.EntityID != "feeding-1" {
+		t.Errorf("Pull() second event = %+v, want feeding-1 deleted", e)
+	}
+
+	// Without last_sync there is nothing to delete yet
+	if resp, _ := svc.Pull(ctx, "user-123", "", ""); len(resp.Events) != 0 || resp.Reset {
+		t.Errorf("Pull() without last_sync = %+v, want no events", resp)
+	}
+
+	// Past the retention deletions may be missing, so the client starts over
+	resp, err = svc.Pull(ctx, "user-123", "", now.Add(-8*24*time.Hour).Format(time.RFC3339))
+	if err != nil || !resp.Reset || len(resp.Events) != 0 {
+		t.Errorf("Pull() past retention = %+v, %v; want a reset", resp, err)
+	}
+
+	if _, err := svc.Pull(ctx, "user-123", "", "yesterday"); !errors.Is(err, delta.ErrInvalidSince) {
+		t.Errorf("Pull() with bad last_sync error = %v, want ErrInvalidSince", err)
+	}
+}
+
+// mockVisibilityService hides feeding from user-123
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) GetMemberVisibility(ctx context.Context, requesterID, familyID, userID string) (*visibility.MemberVisibility, error) {
+	return &visibility.MemberVisibility{FamilyID: familyID, UserID: userID, HiddenTypes: []visibility.RecordType{visibility.RecordFeeding}}, nil
+}
+
+func TestService_Pull_HiddenDeletions(t *testing.T) {
+	now := time.Now()
+	tombstones := &mockTombstones{stones: []delta.Tombstone{
+		{EntityType: delta.EntityFeeding, EntityID: "feeding-1", ScopeID: "child-1", DeletedAt: now.Add(-30 * time.Minute)},
+		{EntityType: delta.EntitySleep, EntityID: "sleep-1", ScopeID: "child-1", DeletedAt: now.Add(-20 * time.Minute)},
+	}}
+	families := &mockFamilyService{families: map[string][]family.FamilyWithChildren{
+		"user-123": {{ID: factory.FamilyID, Children: []family.Child{factory.Child()}}},
+	}}
+	svc := NewService(newMockReplayStore(), newMockFeedingService(), newMockSleepService(), newMockMedicationService(), newMockNotesService(),
+		WithTombstones(tombstones, families), WithVisibility(&mockVisibilityService{}))
+
+	resp, err := svc.Pull(context.Background(), "user-123", "", now.Add(-time.Hour).Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if len(resp.Events) != 1 || resp.Events[0].EntityID != "sleep-1" {
+		t.Errorf("Pull() = %+v, want only sleep-1 deleted", resp.Events)
+	}
+}
+
 func TestService_Status(t *testing.T) {
 	feedingSvc := newMockFeedingService()
 	sleepSvc := newMockSleepService()
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
-	status, err := svc.Status(context.Background(), "user-123")
+	status, err := svc.Status(context.Background(), "user-123", "")
 	if err != nil {
 		t.Fatalf("Status() error = %v", err)
 	}
@@ -894,7 +1346,7 @@ func TestService_Push_EmptyEvents(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -922,7 +1374,7 @@ func TestService_Push_SleepUpdate(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -959,7 +1411,7 @@ func TestService_Push_SleepDelete(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -994,7 +1446,7 @@ func TestService_Push_SleepUnknownAction(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -1026,7 +1478,7 @@ func TestService_Push_MedicationUpdate(t *testing.T) {
 	medSvc.medications["med-123"] = &medication.Medication{ID: "med-123", Name: "Original"}
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -1063,7 +1515,7 @@ func TestService_Push_MedicationDelete(t *testing.T) {
 	medSvc.medications["med-123"] = &medication.Medication{ID: "med-123"}
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -1098,7 +1550,7 @@ func TestService_Push_MedicationUnknownAction(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -1129,7 +1581,7 @@ func TestService_Push_MedicationLogUnknownAction(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -1160,7 +1612,7 @@ func TestService_Push_NoteUnknownAction(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -1192,7 +1644,7 @@ func TestService_Push_SleepServiceError(t *testing.T) {
 	medSvc := newMockMedicationService()
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -1228,7 +1680,7 @@ func TestService_Push_MedicationServiceError(t *testing.T) {
 	medSvc.createErr = errors.New("medication service error")
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -1263,7 +1715,7 @@ func TestService_Push_MedicationLogServiceError(t *testing.T) {
 	medSvc.logErr = errors.New("log service error")
 	notesSvc := newMockNotesService()
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -1298,7 +1750,7 @@ func TestService_Push_NoteServiceError(t *testing.T) {
 	notesSvc := newMockNotesService()
 	notesSvc.createErr = errors.New("note service error")
 
-	svc := NewService(feedingSvc, sleepSvc, medSvc, notesSvc)
+	svc := NewService(newMockReplayStore(), feedingSvc, sleepSvc, medSvc, notesSvc)
 
 	req := &PushRequest{
 		ClientID: "client-123",
@@ -1325,3 +1777,357 @@ func TestService_Push_NoteServiceError(t *testing.T) {
 		t.Errorf("Push() Failed = %d, want 1", resp.Failed)
 	}
 }
+
+func TestService_Push_ReplayedEvent(t *testing.T) {
+	feedingSvc := newMockFeedingService()
+	svc := NewService(newMockReplayStore(), feedingSvc, newMockSleepService(), newMockMedicationService(), newMockNotesService())
+
+	req := &PushRequest{
+		ClientID: "client-123",
+		Events: []Event{
+			{
+				ID:        "event-1",
+				Type:      EventTypeFeeding,
+				Action:    "create",
+				Timestamp: time.Now(),
+				Data: map[string]any{
+					"child_id":   "child-123",
+					"type":       "bottle",
+					"start_time": time.Now().Format(time.RFC3339),
+				},
+			},
+		},
+	}
+
+	if _, err := svc.Push(context.Background(), "user-123", req); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	// Forget the first write so a second create would be visible
+	delete(feedingSvc.feedings, "feeding-new-id")
+
+	resp, err := svc.Push(context.Background(), "user-123", req)
+	if err != nil {
+		t.Fatalf("replayed Push() error = %v", err)
+	}
+
+	if resp.Processed != 1 || len(resp.Replayed) != 1 || resp.Replayed[0] != "event-1" {
+		t.Errorf("replayed Push() = %+v, want event-1 reported as replayed", resp)
+	}
+	if len(feedingSvc.feedings) != 0 {
+		t.Error("replayed Push() should not create the feeding again")
+	}
+}
+
+func TestService_Push_FailedEventCanBeRetried(t *testing.T) {
+	notesSvc := newMockNotesService()
+	notesSvc.createErr = errors.New("note service error")
+	svc := NewService(newMockReplayStore(), newMockFeedingService(), newMockSleepService(), newMockMedicationService(), notesSvc)
+
+	req := &PushRequest{
+		Events: []Event{
+			{
+				ID:        "event-1",
+				Type:      EventTypeNote,
+				Action:    "create",
+				Timestamp: time.Now(),
+				Data: map[string]any{
+					"child_id": "child-123",
+					"content":  "Test note",
+				},
+			},
+		},
+	}
+
+	if resp, _ := svc.Push(context.Background(), "user-123", req); resp.Failed != 1 {
+		t.Fatalf("Push() Failed = %d, want 1", resp.Failed)
+	}
+
+	notesSvc.createErr = nil
+	resp, err := svc.Push(context.Background(), "user-123", req)
+	if err != nil {
+		t.Fatalf("retried Push() error = %v", err)
+	}
+	if resp.Processed != 1 || len(resp.Replayed) != 0 {
+		t.Errorf("retried Push() = %+v, want event processed again", resp)
+	}
+}
+
+func TestService_Push_MissingEventID(t *testing.T) {
+	svc := NewService(newMockReplayStore(), newMockFeedingService(), newMockSleepService(), newMockMedicationService(), newMockNotesService())
+
+	resp, err := svc.Push(context.Background(), "user-123", &PushRequest{
+		Events: []Event{{Type: EventTypeFeeding, Action: "delete", EntityID: "feeding-123"}},
+	})
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if resp.Failed != 1 {
+		t.Errorf("Push() Failed = %d, want 1", resp.Failed)
+	}
+}
+
+func TestService_Push_Maintenance(t *testing.T) {
+	sw := maintenance.NewSwitch(true, "", 90*time.Second)
+	feedingSvc := newMockFeedingService()
+	svc := NewService(newMockReplayStore(), feedingSvc, newMockSleepService(), newMockMedicationService(), newMockNotesService(),
+		WithMaintenance(sw))
+
+	_, err := svc.Push(context.Background(), "user-123", &PushRequest{
+		Events: []Event{{ID: "event-1", Type: EventTypeFeeding, Action: "delete", EntityID: "feeding-123"}},
+	})
+	var maintenanceErr *MaintenanceError
+	if !errors.As(err, &maintenanceErr) || maintenanceErr.RetryAfter != 90 {
+		t.Fatalf("Push() error = %v, want MaintenanceError with 90s retry", err)
+	}
+
+	status, err := svc.Status(context.Background(), "user-123", "")
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !status.Maintenance || status.RetryAfter != 90 {
+		t.Errorf("Status() = %+v, want maintenance with retry hint", status)
+	}
+
+	sw.Set(false, "", 0)
+	if status, _ := svc.Status(context.Background(), "user-123", ""); status.Maintenance || status.RetryAfter != 0 {
+		t.Errorf("Status() after maintenance = %+v", status)
+	}
+}
+
+func TestService_Push_ClockOffset(t *testing.T) {
+	feedingSvc := newMockFeedingService()
+	svc := NewService(newMockReplayStore(), feedingSvc, newMockSleepService(), newMockMedicationService(), newMockNotesService())
+
+	// The client clock runs an hour fast; event-1 happened 30 minutes ago and
+	// event-2 is future-dated even by the client's clock. The mock keeps the
+	// last feeding created, so event-1 goes second.
+	clientNow := time.Now().Add(time.Hour)
+	req := &PushRequest{
+		ClockOffsetMs: time.Hour.Milliseconds(),
+		Events: []Event{
+			{
+				ID:     "event-2",
+				Type:   EventTypeFeeding,
+				Action: "create",
+				Data: map[string]any{
+					"child_id":   "child-123",
+					"type":       "bottle",
+					"start_time": clientNow.Add(2 * time.Hour).Format(time.RFC3339Nano),
+				},
+			},
+			{
+				ID:     "event-1",
+				Type:   EventTypeFeeding,
+				Action: "create",
+				Data: map[string]any{
+					"child_id":   "child-123",
+					"type":       "bottle",
+					"start_time": clientNow.Add(-30 * time.Minute).Format(time.RFC3339Nano),
+				},
+			},
+		},
+	}
+
+	resp, err := svc.Push(context.Background(), "user-123", req)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	got := feedingSvc.feedings["feeding-new-id"].StartTime
+	if d := time.Until(got) + 30*time.Minute; d < -time.Second || d > time.Second {
+		t.Errorf("StartTime = %v, want about 30 minutes ago on the server clock", got)
+	}
+	if resp.ClockOffsetMs != time.Hour.Milliseconds() {
+		t.Errorf("ClockOffsetMs = %d, want %d", resp.ClockOffsetMs, time.Hour.Milliseconds())
+	}
+	if len(resp.Suspicious) != 1 || resp.Suspicious[0] != "event-2" {
+		t.Errorf("Suspicious = %v, want [event-2]", resp.Suspicious)
+	}
+}
+
+func TestService_Push_ImplausibleClockOffset(t *testing.T) {
+	svc := NewService(newMockReplayStore(), newMockFeedingService(), newMockSleepService(), newMockMedicationService(), newMockNotesService())
+
+	_, err := svc.Push(context.Background(), "user-123", &PushRequest{ClockOffsetMs: (72 * time.Hour).Milliseconds()})
+	if !errors.Is(err, occurrence.ErrClockOffset) {
+		t.Errorf("Push() error = %v, want ErrClockOffset", err)
+	}
+}
+
+func TestService_Push_UpdateLosesToNewerServerVersion(t *testing.T) {
+	feedingSvc := newMockFeedingService()
+	feedingSvc.feedings["feeding-123"] = &feeding.Feeding{ID: "feeding-123", Type: feeding.FeedingTypeBreast, UpdatedAt: time.Now()}
+	conflicts := newMockConflictRepo()
+
+	svc := NewService(newMockReplayStore(), feedingSvc, newMockSleepService(), newMockMedicationService(), newMockNotesService(),
+		WithConflicts(conflicts))
+
+	req := &PushRequest{
+		Events: []Event{{
+			ID:        "event-1",
+			Type:      EventTypeFeeding,
+			Action:    "update",
+			EntityID:  "feeding-123",
+			Timestamp: time.Now().Add(-time.Hour),
+			Data: map[string]any{
+				"child_id":   "child-123",
+				"type":       "bottle",
+				"start_time": time.Now().Add(-2 * time.Hour).Format(time.RFC3339),
+			},
+		}},
+	}
+
+	resp, err := svc.Push(context.Background(), "user-123", req)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if resp.Processed != 1 || len(resp.Conflicts) != 1 || resp.Conflicts[0] != "event-1" {
+		t.Fatalf("Push() = %+v, want event-1 processed as a conflict", resp)
+	}
+	if got := feedingSvc.feedings["feeding-123"].Type; got != feeding.FeedingTypeBreast {
+		t.Errorf("feeding type = %s, want the server version kept", got)
+	}
+
+	open, _ := svc.ListConflicts(context.Background(), "user-123")
+	if len(open) != 1 || open[0].EntityID != "feeding-123" || open[0].EntityType != EventTypeFeeding {
+		t.Fatalf("ListConflicts() = %+v, want one feeding conflict", open)
+	}
+	if others, _ := svc.ListConflicts(context.Background(), "user-456"); len(others) != 0 {
+		t.Errorf("ListConflicts() for another user = %d, want 0", len(others))
+	}
+
+	// Keeping the client version replays the losing edit
+	resolved, err := svc.ResolveConflict(context.Background(), "user-123", open[0].ID, &ResolveConflictRequest{Keep: KeepClient})
+	if err != nil {
+		t.Fatalf("ResolveConflict() error = %v", err)
+	}
+	if resolved.Resolution != KeepClient || resolved.ResolvedAt == nil {
+		t.Errorf("ResolveConflict() = %+v, want resolved for client", resolved)
+	}
+	if got := feedingSvc.feedings["feeding-123"].Type; got != feeding.FeedingTypeBottle {
+		t.Errorf("feeding type = %s, want the client edit restored", got)
+	}
+
+	if _, err := svc.ResolveConflict(context.Background(), "user-123", open[0].ID, &ResolveConflictRequest{Keep: KeepServer}); !errors.Is(err, ErrConflictResolved) {
+		t.Errorf("ResolveConflict() twice error = %v, want ErrConflictResolved", err)
+	}
+}
+
+func TestService_Push_NewerUpdateWins(t *testing.T) {
+	feedingSvc := newMockFeedingService()
+	feedingSvc.feedings["feeding-123"] = &feeding.Feeding{ID: "feeding-123", Type: feeding.FeedingTypeBreast, UpdatedAt: time.Now().Add(-time.Hour)}
+	conflicts := newMockConflictRepo()
+
+	svc := NewService(newMockReplayStore(), feedingSvc, newMockSleepService(), newMockMedicationService(), newMockNotesService(),
+		WithConflicts(conflicts))
+
+	req := &PushRequest{
+		Events: []Event{{
+			ID:        "event-1",
+			Type:      EventTypeFeeding,
+			Action:    "update",
+			EntityID:  "feeding-123",
+			Timestamp: time.Now(),
+			Data:      map[string]any{"child_id": "child-123", "type": "bottle", "start_time": time.Now().Format(time.RFC3339)},
+		}},
+	}
+
+	resp, err := svc.Push(context.Background(), "user-123", req)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if len(resp.Conflicts) != 0 || len(conflicts.conflicts) != 0 {
+		t.Errorf("Push() Conflicts = %v, want none", resp.Conflicts)
+	}
+	if got := feedingSvc.feedings["feeding-123"].Type; got != feeding.FeedingTypeBottle {
+		t.Errorf("feeding type = %s, want the client edit applied", got)
+	}
+}
+
+func TestService_ResolveConflict(t *testing.T) {
+	conflicts := newMockConflictRepo()
+	conflicts.conflicts["conflict-1"] = &Conflict{ID: "conflict-1", UserID: "user-123", EntityType: EventTypeNote, EntityID: "note-123"}
+	notesSvc := newMockNotesService()
+	notesSvc.notes["note-123"] = &notes.Note{ID: "note-123", Content: "Server"}
+
+	svc := NewService(newMockReplayStore(), newMockFeedingService(), newMockSleepService(), newMockMedicationService(), notesSvc,
+		WithConflicts(conflicts))
+
+	tests := []struct {
+		name    string
+		userID  string
+		id      string
+		keep    string
+		wantErr error
+	}{
+		{"invalid keep", "user-123", "conflict-1", "both", ErrInvalidResolution},
+		{"unknown conflict", "user-123", "conflict-2", KeepServer, ErrConflictNotFound},
+		{"another user's conflict", "user-456", "conflict-1", KeepServer, ErrConflictNotFound},
+		{"keep server", "user-123", "conflict-1", KeepServer, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.ResolveConflict(context.Background(), tt.userID, tt.id, &ResolveConflictRequest{Keep: tt.keep})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ResolveConflict() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if notesSvc.notes["note-123"].Content != "Server" {
+		t.Errorf("note content = %q, want the server version left alone", notesSvc.notes["note-123"].Content)
+	}
+}
+
+func TestService_DeviceCheckpoints(t *testing.T) {
+	devices := newMockDeviceRepo()
+	svc := NewService(newMockReplayStore(), newMockFeedingService(), newMockSleepService(), newMockMedicationService(), newMockNotesService(),
+		WithDevices(devices))
+	ctx := context.Background()
+
+	if _, err := svc.Push(ctx, "user-123", &PushRequest{ClientID: "phone-1"}); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if _, err := svc.Pull(ctx, "user-123", "tablet-1", ""); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	pulled, err := svc.Pull(ctx, "user-123", "phone-1", "")
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+
+	list, _ := svc.ListDevices(ctx, "user-123")
+	if len(list) != 2 {
+		t.Fatalf("ListDevices() = %d devices, want 2", len(list))
+	}
+	status, _ := svc.Status(ctx, "user-123", "phone-1")
+	if status.LastSync != pulled.ServerTime || status.Reset {
+		t.Errorf("Status() = %+v, want last_sync %s", status, pulled.ServerTime)
+	}
+
+	reset, err := svc.ResetDevice(ctx, "user-123", "phone-1")
+	if err != nil {
+		t.Fatalf("ResetDevice() error = %v", err)
+	}
+	if !reset.ResetPending || reset.LastToken != "" {
+		t.Errorf("ResetDevice() = %+v, want a pending reset without a token", reset)
+	}
+	if status, _ := svc.Status(ctx, "user-123", "phone-1"); !status.Reset || status.LastSync != "" {
+		t.Errorf("Status() after reset = %+v, want reset", status)
+	}
+	if status, _ := svc.Status(ctx, "user-123", "tablet-1"); status.Reset || status.LastSync == "" {
+		t.Errorf("Status() of another device = %+v, want it untouched", status)
+	}
+
+	// The next pull starts over and clears the reset
+	if resp, _ := svc.Pull(ctx, "user-123", "phone-1", pulled.ServerTime); !resp.Reset {
+		t.Error("Pull() after reset: Reset = false, want true")
+	}
+	if resp, _ := svc.Pull(ctx, "user-123", "phone-1", pulled.ServerTime); resp.Reset {
+		t.Error("second Pull() after reset: Reset = true, want false")
+	}
+
+	if _, err := svc.ResetDevice(ctx, "user-456", "phone-1"); !errors.Is(err, ErrDeviceNotFound) {
+		t.Errorf("ResetDevice() of another user's device error = %v, want ErrDeviceNotFound", err)
+	}
+}
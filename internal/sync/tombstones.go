@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// EventTypeChild only appears in pulls, when a child has been deleted
+const EventTypeChild EventType = "child"
+
+// WithTombstones reports deletions to pulling clients. familyService finds
+// the families and children whose deletions a user may see.
+func WithTombstones(tombstones delta.Tombstones, familyService family.Service) Option {
+	return func(s *service) {
+		s.tombstones = tombstones
+		s.familyService = familyService
+	}
+}
+
+// deletedSince returns a delete event for each record in the user's families
+// removed after since. Records that went with a deleted child are not listed
+// separately; the child's own delete event covers them. Nor are deletions of
+// record types hidden from the user.
+func (s *service) deletedSince(ctx context.Context, userID string, since time.Time) ([]Event, error) {
+	families, err := s.familyService.GetUserFamilies(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var scopes []string
+	// hidden holds the record types hidden from the user, by child
+	hidden := map[string]map[visibility.RecordType]bool{}
+	for _, f := range families {
+		types, err := s.hiddenTypes(ctx, userID, f.ID)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, f.ID)
+		for _, c := range f.Children {
+			scopes = append(scopes, c.ID)
+			hidden[c.ID] = types
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+
+	stones, err := s.tombstones.Since(ctx, scopes, since)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(stones))
+	for _, t := range stones {
+		if !visible(hidden, t) {
+			continue
+		}
+		// Entity and event type names are the same
+		eventType := EventType(t.EntityType)
+		events = append(events, Event{
+			ID:        string(eventType) + ":" + t.EntityID + ":delete",
+			Type:      eventType,
+			Action:    "delete",
+			EntityID:  t.EntityID,
+			Timestamp: t.DeletedAt.UTC(),
+		})
+	}
+	return events, nil
+}
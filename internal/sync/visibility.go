@@ -0,0 +1,48 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// recordTypes maps the entities that leave tombstones to the record type
+// that hides them
+var recordTypes = map[delta.EntityType]visibility.RecordType{
+	delta.EntityFeeding:     visibility.RecordFeeding,
+	delta.EntitySleep:       visibility.RecordSleep,
+	delta.EntityMedication:  visibility.RecordMedication,
+	delta.EntityVaccination: visibility.RecordVaccination,
+	delta.EntityNote:        visibility.RecordNotes,
+}
+
+// WithVisibility leaves deletions of record types hidden from the pulling
+// member out of their pulls
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+// hiddenTypes returns the record types hidden from the user in the family
+func (s *service) hiddenTypes(ctx context.Context, userID, familyID string) (map[visibility.RecordType]bool, error) {
+	if s.visibility == nil {
+		return nil, nil
+	}
+	v, err := s.visibility.GetMemberVisibility(ctx, userID, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	hidden := make(map[visibility.RecordType]bool, len(v.HiddenTypes))
+	for _, t := range v.HiddenTypes {
+		hidden[t] = true
+	}
+	return hidden, nil
+}
+
+// visible reports whether the tombstone's record type is visible in its scope
+func visible(hidden map[string]map[visibility.RecordType]bool, t delta.Tombstone) bool {
+	recordType, ok := recordTypes[t.EntityType]
+	return !ok || !hidden[t.ScopeID][recordType]
+}
@@ -0,0 +1,147 @@
+package synccapture
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	recorder *Recorder
+}
+
+func NewHandler(recorder *Recorder) *Handler {
+	return &Handler{recorder: recorder}
+}
+
+// RegisterRoutes registers the user's opt-in on the sync group
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/debug", h.status)
+	rg.PUT("/debug", h.set)
+}
+
+// RegisterAdminRoutes registers support's view of the captures. The group
+// must already require the admin token.
+func (h *Handler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:userId", h.captured)
+	rg.DELETE("/:userId", h.clear)
+}
+
+// Capture records the request and response when the user has opted in. Use
+// it on the sync routes only.
+func (h *Handler) Capture(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if !h.recorder.Enabled(userID) {
+		c.Next()
+		return
+	}
+
+	e := Exchange{
+		ID:        ids.New(),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Query:     c.Request.URL.RawQuery,
+		UserAgent: c.Request.UserAgent(),
+		At:        time.Now(),
+	}
+
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		e.Request, e.Omitted = captureBody(body, "request", e.Omitted)
+	}
+
+	w := &teeWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	e.Status = w.Status()
+	e.DurationMs = time.Since(e.At).Milliseconds()
+	if w.overflow {
+		e.Omitted = append(e.Omitted, "response")
+	} else {
+		e.Response, e.Omitted = captureBody(w.body.Bytes(), "response", e.Omitted)
+	}
+	h.recorder.Record(userID, e)
+}
+
+func captureBody(body []byte, name string, omitted []string) ([]byte, []string) {
+	if len(body) == 0 {
+		return nil, omitted
+	}
+	if len(body) > MaxBodyBytes {
+		return nil, append(omitted, name)
+	}
+	sanitized, ok := sanitize(body)
+	if !ok {
+		return nil, append(omitted, name)
+	}
+	return sanitized, omitted
+}
+
+// teeWriter keeps a copy of the response body, up to MaxBodyBytes
+type teeWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *teeWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *teeWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *teeWriter) keep(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > MaxBodyBytes {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
+
+func (h *Handler) status(c *gin.Context) {
+	c.JSON(http.StatusOK, h.recorder.Status(c.GetString("user_id")))
+}
+
+func (h *Handler) set(c *gin.Context) {
+	var req SetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if *req.Enabled {
+		c.JSON(http.StatusOK, h.recorder.Enable(userID))
+		return
+	}
+	c.JSON(http.StatusOK, h.recorder.Disable(userID))
+}
+
+func (h *Handler) captured(c *gin.Context) {
+	c.JSON(http.StatusOK, h.recorder.Captured(c.Param("userId")))
+}
+
+func (h *Handler) clear(c *gin.Context) {
+	h.recorder.Clear(c.Param("userId"))
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,117 @@
+package synccapture
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func setupRouter(h *Handler, userID string) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	syncGroup := router.Group("/sync")
+	syncGroup.POST("/push", h.Capture, func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		var req map[string]any
+		if err := json.Unmarshal(body, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "handler did not get the body"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"processed": 1, "server_time": "2024-03-01T12:00:00Z"})
+	})
+	// The push handler above is a stand-in, so only these are recorded
+	h.RegisterRoutes(syncGroup.Group("", apispectest.Record("/api")))
+	h.RegisterAdminRoutes(router.Group("/admin/sync-captures", apispectest.Record("/api")))
+	return router
+}
+
+func serve(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_Capture(t *testing.T) {
+	h := NewHandler(NewRecorder())
+	router := setupRouter(h, "user-1")
+	push := `{"client_id":"phone-1","events":[{"id":"e1","data":{"notes":"secret"}}]}`
+
+	// Nothing is captured before the user opts in
+	if w := serve(router, "POST", "/sync/push", push); w.Code != http.StatusOK {
+		t.Fatalf("push: status = %d", w.Code)
+	}
+	if got := h.recorder.Captured("user-1").Exchanges; len(got) != 0 {
+		t.Fatalf("captured %d exchanges before opting in", len(got))
+	}
+
+	if w := serve(router, "PUT", "/sync/debug", `{"enabled":true}`); w.Code != http.StatusOK {
+		t.Fatalf("opt in: status = %d", w.Code)
+	}
+	if w := serve(router, "POST", "/sync/push?x=1", push); w.Code != http.StatusOK {
+		t.Fatalf("push: status = %d: %s", w.Code, w.Body.String())
+	}
+
+	w := serve(router, "GET", "/admin/sync-captures/user-1", "")
+	var got Captured
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to parse %s: %v", w.Body.String(), err)
+	}
+	if !got.Enabled || len(got.Exchanges) != 1 {
+		t.Fatalf("captured = %s", w.Body.String())
+	}
+	e := got.Exchanges[0]
+	if e.Path != "/sync/push" || e.Query != "x=1" || e.Status != http.StatusOK {
+		t.Errorf("exchange = %+v", e)
+	}
+	if bytes.Contains(e.Request, []byte("secret")) || !bytes.Contains(e.Request, []byte(`"client_id":"phone-1"`)) {
+		t.Errorf("request = %s, want notes redacted", e.Request)
+	}
+	if !bytes.Contains(e.Response, []byte(`"processed":1`)) {
+		t.Errorf("response = %s", e.Response)
+	}
+
+	if w := serve(router, "DELETE", "/admin/sync-captures/user-1", ""); w.Code != http.StatusNoContent {
+		t.Errorf("clear: status = %d", w.Code)
+	}
+	if got := h.recorder.Captured("user-1").Exchanges; len(got) != 0 {
+		t.Errorf("captured %d exchanges after clearing", len(got))
+	}
+}
+
+func TestHandler_Set(t *testing.T) {
+	h := NewHandler(NewRecorder())
+	router := setupRouter(h, "user-1")
+
+	if w := serve(router, "PUT", "/sync/debug", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("missing enabled: status = %d, want 400", w.Code)
+	}
+
+	serve(router, "PUT", "/sync/debug", `{"enabled":true}`)
+	w := serve(router, "PUT", "/sync/debug", `{"enabled":false}`)
+	var st Status
+	if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil || st.Enabled {
+		t.Errorf("opt out = %s", w.Body.String())
+	}
+
+	w = serve(router, "GET", "/sync/debug", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil || st.Enabled {
+		t.Errorf("status = %s", w.Body.String())
+	}
+}
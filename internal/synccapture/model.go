@@ -0,0 +1,51 @@
+package synccapture
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// Window is how long capture stays on after a user turns it on
+	Window = 24 * time.Hour
+	// BufferSize is how many exchanges are kept per user; older ones are
+	// dropped as new ones arrive
+	BufferSize = 100
+	// MaxBodyBytes bounds each captured body; larger bodies are left out
+	MaxBodyBytes = 64 << 10
+)
+
+// Exchange is one sync request and the response it got, with the content of
+// records redacted
+type Exchange struct {
+	ID         string          `json:"id"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	Query      string          `json:"query,omitempty"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	Status     int             `json:"status"`
+	DurationMs int64           `json:"duration_ms"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	// Omitted notes bodies left out for being too large or not JSON
+	Omitted []string  `json:"omitted,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// Status is whether a user's sync requests are being captured
+type Status struct {
+	Enabled bool       `json:"enabled"`
+	Until   *time.Time `json:"until,omitempty"`
+}
+
+// Captured is what support sees for a user
+type Captured struct {
+	UserID string `json:"user_id"`
+	Status
+	// Exchanges are newest first
+	Exchanges []Exchange `json:"exchanges"`
+}
+
+type SetRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
@@ -0,0 +1,129 @@
+// Package synccapture records a user's sync requests and responses, with
+// record contents redacted, while they have opted in. Support reads them back
+// through the admin API to work out why data did not sync.
+package synccapture
+
+import (
+	"sync"
+	"time"
+)
+
+// Recorder keeps the opt-ins and each user's recent exchanges in memory, per
+// server instance
+type Recorder struct {
+	mu      sync.Mutex
+	until   map[string]time.Time
+	buffers map[string]*ring
+	now     func() time.Time
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		until:   map[string]time.Time{},
+		buffers: map[string]*ring{},
+		now:     time.Now,
+	}
+}
+
+// Enable captures the user's sync requests for the next Window. Enabling
+// again extends it.
+func (r *Recorder) Enable(userID string) Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until := r.now().Add(Window)
+	r.until[userID] = until
+	return Status{Enabled: true, Until: &until}
+}
+
+// Disable stops capturing. Exchanges already captured are kept for support
+// until cleared.
+func (r *Recorder) Disable(userID string) Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.until, userID)
+	return Status{}
+}
+
+func (r *Recorder) Status(userID string) Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status(userID)
+}
+
+func (r *Recorder) status(userID string) Status {
+	until, ok := r.until[userID]
+	if !ok {
+		return Status{}
+	}
+	if !r.now().Before(until) {
+		delete(r.until, userID)
+		return Status{}
+	}
+	return Status{Enabled: true, Until: &until}
+}
+
+// Enabled reports whether the user's sync requests are being captured
+func (r *Recorder) Enabled(userID string) bool {
+	return r.Status(userID).Enabled
+}
+
+// Record adds an exchange to the user's buffer
+func (r *Recorder) Record(userID string, e Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buf, ok := r.buffers[userID]
+	if !ok {
+		buf = &ring{}
+		r.buffers[userID] = buf
+	}
+	buf.add(e)
+}
+
+// Captured returns the user's status and exchanges, newest first
+func (r *Recorder) Captured(userID string) *Captured {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := &Captured{UserID: userID, Status: r.status(userID), Exchanges: []Exchange{}}
+	if buf, ok := r.buffers[userID]; ok {
+		c.Exchanges = buf.newestFirst()
+	}
+	return c
+}
+
+// Clear drops the user's captured exchanges
+func (r *Recorder) Clear(userID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.buffers, userID)
+}
+
+// ring holds the last BufferSize exchanges
+type ring struct {
+	items [BufferSize]Exchange
+	next  int
+	full  bool
+}
+
+func (b *ring) add(e Exchange) {
+	b.items[b.next] = e
+	b.next = (b.next + 1) % BufferSize
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+func (b *ring) newestFirst() []Exchange {
+	n := b.next
+	if b.full {
+		n = BufferSize
+	}
+	out := make([]Exchange, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, b.items[(b.next-i+BufferSize)%BufferSize])
+	}
+	return out
+}
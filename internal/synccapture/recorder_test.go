@@ -0,0 +1,72 @@
+package synccapture
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRecorder_Window(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRecorder()
+	r.now = func() time.Time { return now }
+
+	if r.Enabled("user-1") {
+		t.Fatal("expected capture off until the user opts in")
+	}
+	st := r.Enable("user-1")
+	if !st.Enabled || !st.Until.Equal(now.Add(Window)) {
+		t.Errorf("Enable() = %+v", st)
+	}
+
+	now = now.Add(Window - time.Minute)
+	if !r.Enabled("user-1") {
+		t.Error("expected capture on within the window")
+	}
+	now = now.Add(time.Minute)
+	if r.Enabled("user-1") {
+		t.Error("expected capture off once the window ends")
+	}
+
+	r.Enable("user-1")
+	if st := r.Disable("user-1"); st.Enabled || r.Enabled("user-1") {
+		t.Errorf("Disable() = %+v", st)
+	}
+}
+
+func TestRecorder_Ring(t *testing.T) {
+	r := NewRecorder()
+	for i := range BufferSize + 5 {
+		r.Record("user-1", Exchange{ID: strconv.Itoa(i)})
+	}
+
+	got := r.Captured("user-1").Exchanges
+	if len(got) != BufferSize {
+		t.Fatalf("len = %d, want %d", len(got), BufferSize)
+	}
+	if got[0].ID != strconv.Itoa(BufferSize+4) || got[BufferSize-1].ID != "5" {
+		t.Errorf("newest = %s, oldest = %s; want the last %d, newest first", got[0].ID, got[BufferSize-1].ID, BufferSize)
+	}
+
+	r.Clear("user-1")
+	if got := r.Captured("user-1").Exchanges; got == nil || len(got) != 0 {
+		t.Errorf("after Clear() = %v, want empty", got)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	body := []byte(`{"client_id":"phone-1","events":[{"id":"e1","type":"feeding","action":"create","entity_id":"0192f6a4-7c1e-7b3a-9d2f-5e8c1a4b6d70","timestamp":"2024-03-01T12:00:00Z","data":{"notes":"Fussy after","amount":120,"child_id":"c1"}}],"results":{"e1":"0192f6a4-7c1e-7b3a-9d2f-5e8c1a4b6d71"}}`)
+
+	got, ok := sanitize(body)
+	if !ok {
+		t.Fatal("sanitize() failed")
+	}
+	want := `{"client_id":"phone-1","events":[{"action":"create","data":{"amount":120,"child_id":"c1","notes":"[redacted 11 chars]"},"entity_id":"0192f6a4-7c1e-7b3a-9d2f-5e8c1a4b6d70","id":"e1","timestamp":"2024-03-01T12:00:00Z","type":"feeding"}],"results":{"e1":"0192f6a4-7c1e-7b3a-9d2f-5e8c1a4b6d71"}}`
+	if string(got) != want {
+		t.Errorf("sanitize() = %s\nwant %s", got, want)
+	}
+
+	if _, ok := sanitize([]byte("not json")); ok {
+		t.Error("expected bodies that are not JSON to be left out")
+	}
+}
@@ -0,0 +1,78 @@
+package synccapture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// keptKeys hold strings that describe how a sync went rather than what was
+// recorded, so they are captured as they are
+var keptKeys = map[string]bool{
+	"type":        true,
+	"action":      true,
+	"status":      true,
+	"source":      true,
+	"unit":        true,
+	"side":        true,
+	"kind":        true,
+	"error":       true,
+	"client_id":   true,
+	"last_sync":   true,
+	"server_time": true,
+}
+
+// idPattern matches record IDs: UUIDv7 strings and the older 32-character hex IDs
+var idPattern = regexp.MustCompile(`^([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}|[0-9a-f]{32})$`)
+
+// sanitize returns the JSON body with free text redacted. IDs, timestamps,
+// numbers, booleans and the strings under keptKeys are kept, which is what
+// tracing a sync needs; names, notes and the like are replaced by their
+// length. Bodies that are not JSON are not captured.
+func sanitize(body []byte) (json.RawMessage, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	out, err := json.Marshal(redact("", v))
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+func redact(key string, v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			v[k] = redact(k, value)
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = redact(key, v[i])
+		}
+		return v
+	case string:
+		if v == "" || keptKeys[key] || key == "id" || strings.HasSuffix(key, "_id") || isStructural(v) {
+			return v
+		}
+		return fmt.Sprintf("[redacted %d chars]", len([]rune(v)))
+	}
+	return v
+}
+
+// isStructural reports whether a string is an ID or a timestamp, which are
+// kept wherever they appear, e.g. as values of a map keyed by event ID
+func isStructural(s string) bool {
+	if idPattern.MatchString(s) {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339Nano, s)
+	return err == nil
+}
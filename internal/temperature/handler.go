@@ -0,0 +1,135 @@
+package temperature
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.list)
+	rg.POST("", h.create)
+	rg.GET("/episodes", h.listEpisodes)
+	rg.GET("/:id", h.get)
+	rg.DELETE("/:id", h.delete)
+}
+
+// readingFilter reads child_id and the optional from/to range (RFC 3339)
+func readingFilter(c *gin.Context) (*ReadingFilter, bool) {
+	filter := &ReadingFilter{ChildID: c.Query("child_id"), ViewerID: c.GetString("user_id")}
+	if filter.ChildID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "child_id is required"})
+		return nil, false
+	}
+	for param, dst := range map[string]**time.Time{"from": &filter.StartDate, "to": &filter.EndDate} {
+		v := c.Query(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+			return nil, false
+		}
+		*dst = &t
+	}
+	return filter, true
+}
+
+func (h *Handler) list(c *gin.Context) {
+	filter, ok := readingFilter(c)
+	if !ok {
+		return
+	}
+
+	readings, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, readings)
+}
+
+func (h *Handler) listEpisodes(c *gin.Context) {
+	filter, ok := readingFilter(c)
+	if !ok {
+		return
+	}
+
+	episodes, err := h.service.Episodes(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, episodes)
+}
+
+func (h *Handler) create(c *gin.Context) {
+	var req CreateReadingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rd, err := h.service.Create(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidUnit), errors.Is(err, ErrInvalidSite), errors.Is(err, ErrImplausible),
+			errors.Is(err, occurrence.ErrInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrMedicationNotFound):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			respondError(c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, rd)
+}
+
+func (h *Handler) get(c *gin.Context) {
+	rd, err := h.service.Get(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if rd == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "temperature reading not found"})
+		return
+	}
+	c.JSON(http.StatusOK, rd)
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondError maps errors from reading and deleting temperature readings
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, visibility.ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, family.ErrNotMember), errors.Is(err, visibility.ErrHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
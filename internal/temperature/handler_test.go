@@ -0,0 +1,182 @@
+package temperature
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/visibility"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	createFn   func(ctx context.Context, req *CreateReadingRequest) (*Reading, error)
+	getFn      func(ctx context.Context, id string) (*Reading, error)
+	listFn     func(ctx context.Context, filter *ReadingFilter) ([]Reading, error)
+	deleteFn   func(ctx context.Context, id string) error
+	episodesFn func(ctx context.Context, filter *ReadingFilter) ([]Episode, error)
+}
+
+func (m *mockService) Create(ctx context.Context, userID string, req *CreateReadingRequest) (*Reading, error) {
+	if m.createFn != nil {
+		return m.createFn(ctx, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Reading, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *mockService) List(ctx context.Context, filter *ReadingFilter) ([]Reading, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, filter)
+	}
+	return []Reading{}, nil
+}
+
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
+	if m.deleteFn != nil {
+		return m.deleteFn(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockService) Episodes(ctx context.Context, filter *ReadingFilter) ([]Episode, error) {
+	if m.episodesFn != nil {
+		return m.episodesFn(ctx, filter)
+	}
+	return []Episode{}, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+	handler.RegisterRoutes(router.Group("/temperature"))
+	return router
+}
+
+func TestList_RequiresChild(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/temperature", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreate_Success(t *testing.T) {
+	svc := &mockService{
+		createFn: func(ctx context.Context, req *CreateReadingRequest) (*Reading, error) {
+			return &Reading{ID: "t-1", ChildID: req.ChildID, Value: req.Value, Unit: req.Unit}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"child_id":"child-1","taken_at":"2024-03-01T08:00:00Z","value":38.4,"unit":"C","site":"axillary"}`
+	req := httptest.NewRequest("POST", "/temperature", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", w.Code)
+	}
+}
+
+func TestCreate_Implausible(t *testing.T) {
+	svc := &mockService{
+		createFn: func(ctx context.Context, req *CreateReadingRequest) (*Reading, error) {
+			return nil, ErrImplausible
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"child_id":"child-1","taken_at":"2024-03-01T08:00:00Z","value":101,"unit":"C"}`
+	req := httptest.NewRequest("POST", "/temperature", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestListEpisodes(t *testing.T) {
+	var captured *ReadingFilter
+	svc := &mockService{
+		episodesFn: func(ctx context.Context, filter *ReadingFilter) ([]Episode, error) {
+			captured = filter
+			return []Episode{{PeakCelsius: 39.1}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/temperature/episodes?child_id=child-1&from=2024-03-01T00:00:00Z", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if captured.ChildID != "child-1" || captured.StartDate == nil || captured.EndDate != nil {
+		t.Errorf("Episodes() filter = %+v", captured)
+	}
+}
+
+func TestListEpisodes_InvalidRange(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/temperature/episodes?child_id=child-1&to=yesterday", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestGet_NotFound(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/temperature/missing", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestGet_Hidden(t *testing.T) {
+	router := setupRouter(&mockService{
+		getFn: func(ctx context.Context, id string) (*Reading, error) {
+			return nil, visibility.ErrHidden
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/temperature/rd-1", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,115 @@
+package temperature
+
+import (
+	"math"
+	"time"
+)
+
+type Unit string
+
+const (
+	Celsius    Unit = "C"
+	Fahrenheit Unit = "F"
+)
+
+// Site is where on the body a temperature was taken. Each reads differently,
+// so each has its own fever threshold.
+type Site string
+
+const (
+	SiteRectal   Site = "rectal"
+	SiteOral     Site = "oral"
+	SiteAxillary Site = "axillary" // armpit
+	SiteTympanic Site = "tympanic" // ear
+	SiteTemporal Site = "temporal" // forehead
+)
+
+// feverThresholds are the lowest temperatures, in °C, counted as a fever at
+// each site. Readings with no site use DefaultFeverThreshold.
+var feverThresholds = map[Site]float64{
+	SiteRectal:   38.0,
+	SiteOral:     37.8,
+	SiteAxillary: 37.5,
+	SiteTympanic: 38.0,
+	SiteTemporal: 38.0,
+}
+
+// DefaultFeverThreshold is the usual 38 °C (100.4 °F) cut-off
+const DefaultFeverThreshold = 38.0
+
+// FeverThreshold returns the temperature in °C from which a reading at site
+// is a fever
+func FeverThreshold(site Site) float64 {
+	if t, ok := feverThresholds[site]; ok {
+		return t
+	}
+	return DefaultFeverThreshold
+}
+
+// ToCelsius converts a value in unit to °C, to one decimal place
+func ToCelsius(value float64, unit Unit) float64 {
+	if unit == Fahrenheit {
+		value = (value - 32) * 5 / 9
+	}
+	return math.Round(value*10) / 10
+}
+
+type Reading struct {
+	ID      string    `json:"id"`
+	ChildID string    `json:"child_id"`
+	TakenAt time.Time `json:"taken_at"`
+	Value   float64   `json:"value"`
+	Unit    Unit      `json:"unit"`
+	Site    Site      `json:"site,omitempty"`
+	// Celsius is Value in °C, so readings in either unit compare directly
+	Celsius float64 `json:"celsius"`
+	// Fever is set when Celsius reaches the threshold for Site
+	Fever    bool     `json:"fever"`
+	Symptoms []string `json:"symptoms"`
+	// MedicationID links one of the child's medications given for the
+	// fever; MedicationGiven describes it in words, e.g. "ibuprofen 5 ml"
+	MedicationID    string    `json:"medication_id,omitempty"`
+	MedicationGiven string    `json:"medication_given,omitempty"`
+	Notes           string    `json:"notes,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type CreateReadingRequest struct {
+	ChildID         string    `json:"child_id" binding:"required"`
+	TakenAt         time.Time `json:"taken_at" binding:"required"`
+	Value           float64   `json:"value" binding:"required"`
+	Unit            Unit      `json:"unit" binding:"required"`
+	Site            Site      `json:"site,omitempty"`
+	Symptoms        []string  `json:"symptoms,omitempty"`
+	MedicationID    string    `json:"medication_id,omitempty"`
+	MedicationGiven string    `json:"medication_given,omitempty"`
+	Notes           string    `json:"notes,omitempty"`
+}
+
+type ReadingFilter struct {
+	ChildID   string
+	StartDate *time.Time
+	EndDate   *time.Time
+	// ViewerID is checked against the child's visibility settings when set
+	ViewerID string
+}
+
+// EpisodeGap is how long after the last fever reading another one still
+// belongs to the same episode
+const EpisodeGap = 24 * time.Hour
+
+// Episode is one bout of fever: readings from the first fever reading to the
+// first normal one after the last, plotted in order as the fever curve
+type Episode struct {
+	StartedAt time.Time `json:"started_at"`
+	// EndedAt is the first normal reading after the fever, or the last fever
+	// reading if none was taken. It is empty while the episode is ongoing.
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+	Ongoing     bool       `json:"ongoing"`
+	PeakCelsius float64    `json:"peak_celsius"`
+	PeakAt      time.Time  `json:"peak_at"`
+	// Symptoms gathers every symptom noted during the episode
+	Symptoms []string  `json:"symptoms"`
+	Readings []Reading `json:"readings"`
+}
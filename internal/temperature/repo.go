@@ -0,0 +1,128 @@
+package temperature
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	GetByID(ctx context.Context, id string) (*Reading, error)
+	List(ctx context.Context, filter *ReadingFilter) ([]Reading, error)
+	Create(ctx context.Context, r *Reading) error
+	Delete(ctx context.Context, id string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the readings table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "temperature_readings")
+}
+
+const readingColumns = `id, child_id, taken_at, value, unit, site, celsius, symptoms,
+	COALESCE(medication_id, ''), medication_given, notes, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReading(row rowScanner) (*Reading, error) {
+	var rd Reading
+	var symptoms pq.StringArray
+
+	if err := row.Scan(
+		&rd.ID, &rd.ChildID, &rd.TakenAt, &rd.Value, &rd.Unit, &rd.Site, &rd.Celsius, &symptoms,
+		&rd.MedicationID, &rd.MedicationGiven, &rd.Notes, &rd.CreatedAt, &rd.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	rd.Symptoms = []string(symptoms)
+	if rd.Symptoms == nil {
+		rd.Symptoms = []string{}
+	}
+	rd.Fever = rd.Celsius >= FeverThreshold(rd.Site)
+	return &rd, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Reading, error) {
+	query := `SELECT ` + readingColumns + ` FROM temperature_readings WHERE id = $1`
+
+	rd, err := scanReading(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return rd, err
+}
+
+func (r *repository) List(ctx context.Context, filter *ReadingFilter) ([]Reading, error) {
+	query := `SELECT ` + readingColumns + ` FROM temperature_readings WHERE child_id = $1`
+	args := []any{filter.ChildID}
+	argIndex := 2
+
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(` AND taken_at >= $%d`, argIndex)
+		args = append(args, *filter.StartDate)
+		argIndex++
+	}
+
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(` AND taken_at <= $%d`, argIndex)
+		args = append(args, *filter.EndDate)
+	}
+
+	query += ` ORDER BY taken_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	readings := []Reading{}
+	for rows.Next() {
+		rd, err := scanReading(rows)
+		if err != nil {
+			return nil, err
+		}
+		readings = append(readings, *rd)
+	}
+
+	return readings, rows.Err()
+}
+
+func (r *repository) Create(ctx context.Context, rd *Reading) error {
+	query := `
+		INSERT INTO temperature_readings (id, child_id, taken_at, value, unit, site, celsius, symptoms,
+		                                  medication_id, medication_given, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	var medicationID *string
+	if rd.MedicationID != "" {
+		medicationID = &rd.MedicationID
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		rd.ID, rd.ChildID, rd.TakenAt, rd.Value, rd.Unit, rd.Site, rd.Celsius, pq.Array(rd.Symptoms),
+		medicationID, rd.MedicationGiven, rd.Notes, rd.CreatedAt, rd.UpdatedAt,
+	)
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM temperature_readings WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,110 @@
+package temperature
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+var readingRowColumns = []string{
+	"id", "child_id", "taken_at", "value", "unit", "site", "celsius", "symptoms",
+	"medication_id", "medication_given", "notes", "created_at", "updated_at",
+}
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(readingRowColumns).
+		AddRow("t-1", "child-1", now, 100.9, "F", "oral", 38.3, "{cough,\"runny nose\"}", "med-1", "ibuprofen 5 ml", "", now, now)
+
+	mock.ExpectQuery("SELECT id, child_id, taken_at").
+		WithArgs("t-1").
+		WillReturnRows(rows)
+
+	rd, err := repo.GetByID(context.Background(), "t-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if rd.Unit != Fahrenheit || rd.Celsius != 38.3 || !rd.Fever || len(rd.Symptoms) != 2 || rd.MedicationID != "med-1" {
+		t.Errorf("GetByID() = %+v", rd)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT id, child_id, taken_at").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	rd, err := repo.GetByID(context.Background(), "missing")
+	if err != nil || rd != nil {
+		t.Errorf("GetByID() = %v, %v; want nil, nil", rd, err)
+	}
+}
+
+func TestRepository_List_WithDates(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	start := now.AddDate(0, 0, -7)
+	rows := sqlmock.NewRows(readingRowColumns).
+		AddRow("t-2", "child-1", now, 37.4, "C", "axillary", 37.4, "{}", "", "", "", now, now).
+		AddRow("t-1", "child-1", start, 37.6, "C", "axillary", 37.6, "{}", "", "", "", now, now)
+
+	mock.ExpectQuery("SELECT .* FROM temperature_readings WHERE child_id = \\$1 AND taken_at >= \\$2 AND taken_at <= \\$3").
+		WithArgs("child-1", start, now).
+		WillReturnRows(rows)
+
+	readings, err := repo.List(context.Background(), &ReadingFilter{ChildID: "child-1", StartDate: &start, EndDate: &now})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(readings) != 2 || readings[0].Fever || !readings[1].Fever {
+		t.Errorf("List() = %+v, want the armpit reading of 37.6 counted as a fever", readings)
+	}
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rd := &Reading{
+		ID: "t-1", ChildID: "child-1", TakenAt: now, Value: 38.5, Unit: Celsius, Site: SiteRectal, Celsius: 38.5,
+		Symptoms: []string{"cough"}, CreatedAt: now, UpdatedAt: now,
+	}
+
+	mock.ExpectExec("INSERT INTO temperature_readings").
+		WithArgs("t-1", "child-1", now, 38.5, Celsius, SiteRectal, 38.5, pq.Array([]string{"cough"}),
+			nil, "", "", now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), rd); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
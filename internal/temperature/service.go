@@ -0,0 +1,257 @@
+package temperature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// Temperatures outside this range, in °C, are taken to be typos or the wrong
+// unit rather than readings
+const (
+	minCelsius = 30.0
+	maxCelsius = 45.0
+)
+
+var (
+	ErrInvalidUnit        = errors.New("unit must be C or F")
+	ErrInvalidSite        = errors.New("site must be rectal, oral, axillary, tympanic or temporal")
+	ErrImplausible        = errors.New("temperature is outside the plausible range")
+	ErrMedicationNotFound = errors.New("medication not found for this child")
+)
+
+type Service interface {
+	Create(ctx context.Context, userID string, req *CreateReadingRequest) (*Reading, error)
+	Get(ctx context.Context, userID, id string) (*Reading, error)
+	List(ctx context.Context, filter *ReadingFilter) ([]Reading, error)
+	Delete(ctx context.Context, userID, id string) error
+	// Episodes groups the child's readings into fever episodes, newest first
+	Episodes(ctx context.Context, filter *ReadingFilter) ([]Episode, error)
+}
+
+type service struct {
+	repo              Repository
+	medicationService medication.Service
+	visibility        visibility.Service
+	now               func() time.Time
+}
+
+type Option func(*service)
+
+// WithMedications checks that a linked medication is one of the child's
+func WithMedications(medicationService medication.Service) Option {
+	return func(s *service) {
+		s.medicationService = medicationService
+	}
+}
+
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{repo: repo, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether readings can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Create(ctx context.Context, userID string, req *CreateReadingRequest) (*Reading, error) {
+	unit := Unit(strings.ToUpper(strings.TrimSpace(string(req.Unit))))
+	if unit != Celsius && unit != Fahrenheit {
+		return nil, ErrInvalidUnit
+	}
+	site := Site(strings.ToLower(strings.TrimSpace(string(req.Site))))
+	if _, ok := feverThresholds[site]; !ok && site != "" {
+		return nil, ErrInvalidSite
+	}
+	celsius := ToCelsius(req.Value, unit)
+	if celsius < minCelsius || celsius > maxCelsius {
+		return nil, fmt.Errorf("%w: %g °%s", ErrImplausible, req.Value, unit)
+	}
+
+	now := s.now()
+	occurrence.Adjust(ctx, "taken_at", &req.TakenAt, now)
+	if err := occurrence.Validate(req.TakenAt, now); err != nil {
+		return nil, err
+	}
+
+	if req.MedicationID != "" && s.medicationService != nil {
+		med, err := s.medicationService.Get(ctx, userID, req.MedicationID)
+		if err != nil {
+			return nil, err
+		}
+		if med == nil || med.ChildID != req.ChildID {
+			return nil, ErrMedicationNotFound
+		}
+	}
+
+	rd := &Reading{
+		ID:              ids.New(),
+		ChildID:         req.ChildID,
+		TakenAt:         req.TakenAt,
+		Value:           req.Value,
+		Unit:            unit,
+		Site:            site,
+		Celsius:         celsius,
+		Fever:           celsius >= FeverThreshold(site),
+		Symptoms:        normalizeSymptoms(req.Symptoms),
+		MedicationID:    req.MedicationID,
+		MedicationGiven: strings.TrimSpace(req.MedicationGiven),
+		Notes:           req.Notes,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := s.repo.Create(ctx, rd); err != nil {
+		return nil, fmt.Errorf("failed to create temperature reading: %w", err)
+	}
+	return rd, nil
+}
+
+// normalizeSymptoms lowercases symptoms and drops blanks and repeats, so they
+// group across readings
+func normalizeSymptoms(symptoms []string) []string {
+	out := []string{}
+	seen := make(map[string]bool, len(symptoms))
+	for _, sym := range symptoms {
+		sym = strings.ToLower(strings.TrimSpace(sym))
+		if sym == "" || seen[sym] {
+			continue
+		}
+		seen[sym] = true
+		out = append(out, sym)
+	}
+	return out
+}
+
+func (s *service) Get(ctx context.Context, userID, id string) (*Reading, error) {
+	rd, err := s.repo.GetByID(ctx, id)
+	if err != nil || rd == nil {
+		return rd, err
+	}
+	if err := s.authorize(ctx, userID, rd.ChildID); err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+func (s *service) List(ctx context.Context, filter *ReadingFilter) ([]Reading, error) {
+	if err := s.authorizeFilter(ctx, filter); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, filter)
+}
+
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	rd, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rd != nil {
+		if err := s.authorize(ctx, userID, rd.ChildID); err != nil {
+			return err
+		}
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) Episodes(ctx context.Context, filter *ReadingFilter) ([]Episode, error) {
+	if err := s.authorizeFilter(ctx, filter); err != nil {
+		return nil, err
+	}
+	readings, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	episodes := groupEpisodes(readings, s.now())
+
+	// Newest first, as readings are listed
+	for i, j := 0, len(episodes)-1; i < j; i, j = i+1, j-1 {
+		episodes[i], episodes[j] = episodes[j], episodes[i]
+	}
+	return episodes, nil
+}
+
+// groupEpisodes walks the readings in time order. A fever reading opens an
+// episode, or joins the current one if it comes within EpisodeGap of the last
+// fever reading, even after a normal one, since fevers often dip and return.
+// Normal readings within the gap stay in the episode to show the fever break.
+func groupEpisodes(readings []Reading, now time.Time) []Episode {
+	sorted := make([]Reading, len(readings))
+	copy(sorted, readings)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].TakenAt.Before(sorted[j].TakenAt) })
+
+	episodes := []Episode{}
+	var cur *Episode
+	var lastFever time.Time
+
+	closeEpisode := func() {
+		if cur == nil {
+			return
+		}
+		if cur.EndedAt == nil {
+			if now.Sub(lastFever) <= EpisodeGap {
+				cur.Ongoing = true
+			} else {
+				end := lastFever
+				cur.EndedAt = &end
+			}
+		}
+		cur.Symptoms = episodeSymptoms(cur.Readings)
+		episodes = append(episodes, *cur)
+		cur = nil
+	}
+
+	for i := range sorted {
+		rd := sorted[i]
+		withinGap := cur != nil && rd.TakenAt.Sub(lastFever) <= EpisodeGap
+
+		if !rd.Fever {
+			if !withinGap {
+				closeEpisode()
+				continue
+			}
+			cur.Readings = append(cur.Readings, rd)
+			if cur.EndedAt == nil {
+				end := rd.TakenAt
+				cur.EndedAt = &end
+			}
+			continue
+		}
+
+		if !withinGap {
+			closeEpisode()
+			cur = &Episode{StartedAt: rd.TakenAt}
+		}
+		cur.Readings = append(cur.Readings, rd)
+		cur.EndedAt = nil
+		lastFever = rd.TakenAt
+		if rd.Celsius > cur.PeakCelsius {
+			cur.PeakCelsius = rd.Celsius
+			cur.PeakAt = rd.TakenAt
+		}
+	}
+	closeEpisode()
+
+	return episodes
+}
+
+func episodeSymptoms(readings []Reading) []string {
+	var all []string
+	for i := range readings {
+		all = append(all, readings[i].Symptoms...)
+	}
+	return normalizeSymptoms(all)
+}
@@ -0,0 +1,220 @@
+package temperature
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type mockRepository struct {
+	readings map[string]*Reading
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{readings: make(map[string]*Reading)}
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Reading, error) {
+	return m.readings[id], nil
+}
+
+func (m *mockRepository) List(ctx context.Context, filter *ReadingFilter) ([]Reading, error) {
+	result := []Reading{}
+	for _, rd := range m.readings {
+		if rd.ChildID == filter.ChildID {
+			result = append(result, *rd)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) Create(ctx context.Context, rd *Reading) error {
+	m.readings[rd.ID] = rd
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	delete(m.readings, id)
+	return nil
+}
+
+type mockMedicationService struct {
+	medication.Service
+	meds map[string]*medication.Medication
+}
+
+func (m *mockMedicationService) Get(ctx context.Context, userID, id string) (*medication.Medication, error) {
+	return m.meds[id], nil
+}
+
+func TestService_Create(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	rd, err := svc.Create(context.Background(), "user-1", &CreateReadingRequest{
+		ChildID: "child-1", TakenAt: time.Now().Add(-time.Hour), Value: 101.3, Unit: "f", Site: "Oral",
+		Symptoms: []string{" Cough", "cough", "Rash", ""},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if rd.Unit != Fahrenheit || rd.Site != SiteOral || rd.Celsius != 38.5 || !rd.Fever {
+		t.Errorf("Create() = %+v", rd)
+	}
+	if len(rd.Symptoms) != 2 || rd.Symptoms[0] != "cough" || rd.Symptoms[1] != "rash" {
+		t.Errorf("Create() symptoms = %v, want [cough rash]", rd.Symptoms)
+	}
+	if len(repo.readings) != 1 {
+		t.Error("Create() should store the reading")
+	}
+}
+
+func TestService_Create_Validation(t *testing.T) {
+	svc := NewService(newMockRepository())
+	taken := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		req  CreateReadingRequest
+		want error
+	}{
+		{"unit", CreateReadingRequest{ChildID: "child-1", TakenAt: taken, Value: 38, Unit: "K"}, ErrInvalidUnit},
+		{"site", CreateReadingRequest{ChildID: "child-1", TakenAt: taken, Value: 38, Unit: Celsius, Site: "toe"}, ErrInvalidSite},
+		{"wrong unit", CreateReadingRequest{ChildID: "child-1", TakenAt: taken, Value: 101, Unit: Celsius}, ErrImplausible},
+		{"future", CreateReadingRequest{ChildID: "child-1", TakenAt: time.Now().Add(time.Hour), Value: 38, Unit: Celsius}, occurrence.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Create(context.Background(), "user-1", &tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("Create() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Create_MedicationOfAnotherChild(t *testing.T) {
+	meds := &mockMedicationService{meds: map[string]*medication.Medication{
+		"med-1": {ID: "med-1", ChildID: "child-2"},
+	}}
+	svc := NewService(newMockRepository(), WithMedications(meds))
+
+	_, err := svc.Create(context.Background(), "user-1", &CreateReadingRequest{
+		ChildID: "child-1", TakenAt: time.Now().Add(-time.Hour), Value: 38.4, Unit: Celsius, MedicationID: "med-1",
+	})
+	if !errors.Is(err, ErrMedicationNotFound) {
+		t.Errorf("Create() error = %v, want ErrMedicationNotFound", err)
+	}
+}
+
+func reading(id string, at time.Time, celsius float64, symptoms ...string) *Reading {
+	return &Reading{
+		ID: id, ChildID: "child-1", TakenAt: at, Value: celsius, Unit: Celsius, Celsius: celsius,
+		Fever: celsius >= DefaultFeverThreshold, Symptoms: symptoms,
+	}
+}
+
+// mockVisibilityService hides child-hidden's readings
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) CheckChild(ctx context.Context, userID, childID string, recordType visibility.RecordType) error {
+	if childID == "child-hidden" {
+		return visibility.ErrHidden
+	}
+	return nil
+}
+
+func TestService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewService(repo, WithVisibility(&mockVisibilityService{}))
+	repo.readings["rd-1"] = &Reading{ID: "rd-1", ChildID: "child-1", Celsius: 37}
+	repo.readings["rd-2"] = &Reading{ID: "rd-2", ChildID: "child-hidden", Celsius: 37}
+
+	if rd, err := svc.Get(ctx, "user-1", "rd-1"); err != nil || rd == nil {
+		t.Errorf("Get() visible = %v, %v", rd, err)
+	}
+	if _, err := svc.Get(ctx, "user-1", "rd-2"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Get() hidden error = %v, want ErrHidden", err)
+	}
+	filter := &ReadingFilter{ChildID: "child-hidden", ViewerID: "user-1"}
+	if _, err := svc.List(ctx, filter); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("List() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Episodes(ctx, filter); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Episodes() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Delete(ctx, "user-1", "rd-2"); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Delete() hidden error = %v, want ErrHidden", err)
+	}
+	if repo.readings["rd-2"] == nil {
+		t.Error("Delete() removed a hidden reading")
+	}
+}
+
+func TestService_Episodes(t *testing.T) {
+	repo := newMockRepository()
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, rd := range []*Reading{
+		// A fever that dips overnight and comes back, then breaks
+		reading("a1", now.AddDate(0, 0, -6), 38.2, "cough"),
+		reading("a2", now.AddDate(0, 0, -6).Add(6*time.Hour), 39.1),
+		reading("a3", now.AddDate(0, 0, -6).Add(12*time.Hour), 37.4),
+		reading("a4", now.AddDate(0, 0, -5), 38.6, "rash"),
+		reading("a5", now.AddDate(0, 0, -5).Add(8*time.Hour), 37.0),
+		// A normal reading days later belongs to no episode
+		reading("n1", now.AddDate(0, 0, -3), 36.9),
+		// The current fever
+		reading("b1", now.Add(-2*time.Hour), 38.8),
+	} {
+		repo.readings[rd.ID] = rd
+	}
+	svc := NewService(repo)
+	svc.(*service).now = func() time.Time { return now }
+
+	episodes, err := svc.Episodes(context.Background(), &ReadingFilter{ChildID: "child-1"})
+	if err != nil {
+		t.Fatalf("Episodes() error = %v", err)
+	}
+	if len(episodes) != 2 {
+		t.Fatalf("Episodes() = %d episodes, want 2", len(episodes))
+	}
+
+	current, past := episodes[0], episodes[1]
+	if !current.Ongoing || current.EndedAt != nil || len(current.Readings) != 1 {
+		t.Errorf("Episodes() current = %+v", current)
+	}
+	if past.Ongoing || past.EndedAt == nil || !past.EndedAt.Equal(now.AddDate(0, 0, -5).Add(8*time.Hour)) {
+		t.Errorf("Episodes() past episode should end at the first normal reading after the fever, got %+v", past.EndedAt)
+	}
+	if len(past.Readings) != 5 || past.PeakCelsius != 39.1 {
+		t.Errorf("Episodes() past readings = %d, peak = %v", len(past.Readings), past.PeakCelsius)
+	}
+	if len(past.Symptoms) != 2 {
+		t.Errorf("Episodes() past symptoms = %v, want cough and rash", past.Symptoms)
+	}
+}
+
+func TestService_Episodes_EndsWithoutNormalReading(t *testing.T) {
+	repo := newMockRepository()
+	now := time.Now()
+	last := now.AddDate(0, 0, -3)
+	repo.readings["a1"] = reading("a1", last.Add(-4*time.Hour), 38.4)
+	repo.readings["a2"] = reading("a2", last, 38.1)
+	svc := NewService(repo)
+
+	episodes, err := svc.Episodes(context.Background(), &ReadingFilter{ChildID: "child-1"})
+	if err != nil {
+		t.Fatalf("Episodes() error = %v", err)
+	}
+	if len(episodes) != 1 || episodes[0].Ongoing || episodes[0].EndedAt == nil || !episodes[0].EndedAt.Equal(last) {
+		t.Errorf("Episodes() = %+v, want one episode ending at the last fever reading", episodes)
+	}
+}
@@ -0,0 +1,32 @@
+package temperature
+
+import (
+	"context"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// WithVisibility applies family visibility settings, so members cannot see or
+// delete readings hidden from them
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+// authorize checks that userID may see the child's readings
+func (s *service) authorize(ctx context.Context, userID, childID string) error {
+	if s.visibility == nil {
+		return nil
+	}
+	return s.visibility.CheckChild(ctx, userID, childID, visibility.RecordTemperature)
+}
+
+// authorizeFilter checks the filter's viewer, if it has one, may see the
+// child's readings
+func (s *service) authorizeFilter(ctx context.Context, filter *ReadingFilter) error {
+	if filter.ViewerID == "" {
+		return nil
+	}
+	return s.authorize(ctx, filter.ViewerID, filter.ChildID)
+}
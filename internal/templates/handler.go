@@ -0,0 +1,108 @@
+package templates
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.list)
+	rg.POST("", h.create)
+	rg.GET("/:id", h.get)
+	rg.PUT("/:id", h.update)
+	rg.DELETE("/:id", h.delete)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	filter := &TemplateFilter{
+		FamilyID: c.Query("family_id"),
+	}
+	if kind := c.Query("kind"); kind != "" {
+		k := Kind(kind)
+		filter.Kind = &k
+	}
+
+	templates, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+func (h *Handler) create(c *gin.Context) {
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	tmpl, err := h.service.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidKind) || errors.Is(err, ErrInvalidPayload) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, tmpl)
+}
+
+func (h *Handler) get(c *gin.Context) {
+	id := c.Param("id")
+	tmpl, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if tmpl == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrTemplateNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tmpl)
+}
+
+func (h *Handler) update(c *gin.Context) {
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id := c.Param("id")
+	tmpl, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidKind) || errors.Is(err, ErrInvalidPayload) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, ErrTemplateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tmpl)
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
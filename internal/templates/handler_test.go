@@ -0,0 +1,238 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	createFn func(ctx context.Context, userID string, req *CreateTemplateRequest) (*Template, error)
+	getFn    func(ctx context.Context, id string) (*Template, error)
+	listFn   func(ctx context.Context, filter *TemplateFilter) ([]Template, error)
+	updateFn func(ctx context.Context, id string, req *CreateTemplateRequest) (*Template, error)
+	deleteFn func(ctx context.Context, id string) error
+}
+
+func (m *mockService) Create(ctx context.Context, userID string, req *CreateTemplateRequest) (*Template, error) {
+	if m.createFn != nil {
+		return m.createFn(ctx, userID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Get(ctx context.Context, id string) (*Template, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *mockService) List(ctx context.Context, filter *TemplateFilter) ([]Template, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, filter)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Update(ctx context.Context, id string, req *CreateTemplateRequest) (*Template, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, id, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Delete(ctx context.Context, id string) error {
+	if m.deleteFn != nil {
+		return m.deleteFn(ctx, id)
+	}
+	return nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+
+	handler.RegisterRoutes(router.Group("/templates"))
+	return router
+}
+
+func TestList_WithFilters(t *testing.T) {
+	var captured *TemplateFilter
+	svc := &mockService{
+		listFn: func(ctx context.Context, filter *TemplateFilter) ([]Template, error) {
+			captured = filter
+			return []Template{{ID: "tmpl-1"}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/templates?family_id=family-1&kind=feeding", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if captured.FamilyID != "family-1" {
+		t.Errorf("Expected FamilyID family-1, got %s", captured.FamilyID)
+	}
+	if captured.Kind == nil || *captured.Kind != KindFeeding {
+		t.Errorf("Expected Kind feeding, got %v", captured.Kind)
+	}
+}
+
+func TestCreate_Success(t *testing.T) {
+	var capturedUserID string
+	svc := &mockService{
+		createFn: func(ctx context.Context, userID string, req *CreateTemplateRequest) (*Template, error) {
+			capturedUserID = userID
+			return &Template{ID: "tmpl-1", FamilyID: req.FamilyID, Kind: req.Kind, Name: req.Name, Payload: req.Payload}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"family_id":"family-1","kind":"feeding","name":"Standard bottle","payload":{"amount":120,"unit":"ml"}}`
+	req := httptest.NewRequest("POST", "/templates", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", w.Code)
+	}
+	if capturedUserID != "test-user-123" {
+		t.Errorf("Expected userID test-user-123, got %s", capturedUserID)
+	}
+
+	var result Template
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if string(result.Payload) != `{"amount":120,"unit":"ml"}` {
+		t.Errorf("Expected payload to round-trip, got %s", result.Payload)
+	}
+}
+
+func TestCreate_MissingFields(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("POST", "/templates", bytes.NewBufferString(`{"name":"x"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreate_InvalidKind(t *testing.T) {
+	svc := &mockService{
+		createFn: func(ctx context.Context, userID string, req *CreateTemplateRequest) (*Template, error) {
+			return nil, ErrInvalidKind
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"family_id":"family-1","kind":"diaper","name":"Wet","payload":{}}`
+	req := httptest.NewRequest("POST", "/templates", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreate_ServiceError(t *testing.T) {
+	svc := &mockService{
+		createFn: func(ctx context.Context, userID string, req *CreateTemplateRequest) (*Template, error) {
+			return nil, errors.New("database error")
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"family_id":"family-1","kind":"note","name":"Drop-off","payload":{}}`
+	req := httptest.NewRequest("POST", "/templates", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func TestGet_NotFound(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/templates/missing", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	svc := &mockService{
+		updateFn: func(ctx context.Context, id string, req *CreateTemplateRequest) (*Template, error) {
+			return nil, ErrTemplateNotFound
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"family_id":"family-1","kind":"note","name":"Drop-off","payload":{}}`
+	req := httptest.NewRequest("PUT", "/templates/missing", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestDelete_Success(t *testing.T) {
+	var capturedID string
+	svc := &mockService{
+		deleteFn: func(ctx context.Context, id string) error {
+			capturedID = id
+			return nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("DELETE", "/templates/tmpl-1", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if capturedID != "tmpl-1" {
+		t.Errorf("Expected id tmpl-1, got %s", capturedID)
+	}
+}
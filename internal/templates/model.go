@@ -0,0 +1,42 @@
+package templates
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Kind identifies which create request a template prefills
+type Kind string
+
+const (
+	KindNote        Kind = "note"
+	KindFeeding     Kind = "feeding"
+	KindSleep       Kind = "sleep"
+	KindMedication  Kind = "medication"
+	KindAppointment Kind = "appointment"
+)
+
+// Template is a reusable preset that clients apply to prefill a create request
+// (e.g. "daycare drop-off note" or "standard bottle 120ml formula").
+type Template struct {
+	ID        string          `json:"id"`
+	FamilyID  string          `json:"family_id"`
+	CreatedBy string          `json:"created_by"`
+	Kind      Kind            `json:"kind"`
+	Name      string          `json:"name"`
+	Payload   json.RawMessage `json:"payload"` // partial create request for the kind
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type CreateTemplateRequest struct {
+	FamilyID string          `json:"family_id" binding:"required"`
+	Kind     Kind            `json:"kind" binding:"required"`
+	Name     string          `json:"name" binding:"required"`
+	Payload  json.RawMessage `json:"payload" binding:"required"`
+}
+
+type TemplateFilter struct {
+	FamilyID string
+	Kind     *Kind
+}
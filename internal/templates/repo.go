@@ -0,0 +1,141 @@
+package templates
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	GetByID(ctx context.Context, id string) (*Template, error)
+	List(ctx context.Context, filter *TemplateFilter) ([]Template, error)
+	Create(ctx context.Context, tmpl *Template) error
+	Update(ctx context.Context, tmpl *Template) error
+	Delete(ctx context.Context, id string) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the templates table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "templates")
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*Template, error) {
+	query := `
+		SELECT id, family_id, created_by, kind, name, payload, created_at, updated_at
+		FROM templates
+		WHERE id = $1
+	`
+
+	var t Template
+	var payload []byte
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&t.ID, &t.FamilyID, &t.CreatedBy, &t.Kind, &t.Name, &payload, &t.CreatedAt, &t.UpdatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.Payload = payload
+
+	return &t, nil
+}
+
+func (r *repository) List(ctx context.Context, filter *TemplateFilter) ([]Template, error) {
+	query := `
+		SELECT id, family_id, created_by, kind, name, payload, created_at, updated_at
+		FROM templates
+		WHERE 1=1
+	`
+	args := []any{}
+	argIndex := 1
+
+	if filter.FamilyID != "" {
+		query += fmt.Sprintf(` AND family_id = $%d`, argIndex)
+		args = append(args, filter.FamilyID)
+		argIndex++
+	}
+
+	if filter.Kind != nil {
+		query += fmt.Sprintf(` AND kind = $%d`, argIndex)
+		args = append(args, *filter.Kind)
+	}
+
+	query += ` ORDER BY kind ASC, name ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	var templates []Template
+	for rows.Next() {
+		var t Template
+		var payload []byte
+
+		if err := rows.Scan(
+			&t.ID, &t.FamilyID, &t.CreatedBy, &t.Kind, &t.Name, &payload, &t.CreatedAt, &t.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		t.Payload = payload
+		templates = append(templates, t)
+	}
+
+	if templates == nil {
+		return []Template{}, nil
+	}
+
+	return templates, rows.Err()
+}
+
+func (r *repository) Create(ctx context.Context, tmpl *Template) error {
+	query := `
+		INSERT INTO templates (id, family_id, created_by, kind, name, payload, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		tmpl.ID, tmpl.FamilyID, tmpl.CreatedBy, tmpl.Kind, tmpl.Name, []byte(tmpl.Payload),
+		tmpl.CreatedAt, tmpl.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *repository) Update(ctx context.Context, tmpl *Template) error {
+	query := `
+		UPDATE templates
+		SET kind = $2, name = $3, payload = $4, updated_at = $5
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		tmpl.ID, tmpl.Kind, tmpl.Name, []byte(tmpl.Payload), tmpl.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM templates WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
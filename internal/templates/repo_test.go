@@ -0,0 +1,157 @@
+package templates
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var templateColumns = []string{
+	"id", "family_id", "created_by", "kind", "name", "payload", "created_at", "updated_at",
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(templateColumns).
+		AddRow("tmpl-123", "family-456", "user-789", "feeding", "Standard bottle",
+			[]byte(`{"type":"formula","amount":120,"unit":"ml"}`), now, now)
+
+	mock.ExpectQuery("SELECT id, family_id, created_by, kind, name, payload").
+		WithArgs("tmpl-123").
+		WillReturnRows(rows)
+
+	tmpl, err := repo.GetByID(context.Background(), "tmpl-123")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if tmpl == nil {
+		t.Fatal("GetByID() returned nil")
+	}
+	if tmpl.Kind != KindFeeding {
+		t.Errorf("GetByID() Kind = %v, want feeding", tmpl.Kind)
+	}
+	if string(tmpl.Payload) != `{"type":"formula","amount":120,"unit":"ml"}` {
+		t.Errorf("GetByID() Payload = %s", tmpl.Payload)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT id, family_id, created_by, kind, name, payload").
+		WithArgs("non-existent").
+		WillReturnError(sql.ErrNoRows)
+
+	tmpl, err := repo.GetByID(context.Background(), "non-existent")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if tmpl != nil {
+		t.Error("GetByID() should return nil for non-existent template")
+	}
+}
+
+func TestRepository_List_WithFilters(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(templateColumns).
+		AddRow("tmpl-1", "family-456", "user-789", "note", "Daycare drop-off", []byte(`{"title":"Drop-off"}`), now, now)
+
+	kind := KindNote
+	mock.ExpectQuery("SELECT id, family_id, created_by, kind, name, payload").
+		WithArgs("family-456", kind).
+		WillReturnRows(rows)
+
+	templates, err := repo.List(context.Background(), &TemplateFilter{FamilyID: "family-456", Kind: &kind})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(templates) != 1 {
+		t.Errorf("List() returned %d templates, want 1", len(templates))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_List_Empty(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT id, family_id, created_by, kind, name, payload").
+		WillReturnRows(sqlmock.NewRows(templateColumns))
+
+	templates, err := repo.List(context.Background(), &TemplateFilter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if templates == nil || len(templates) != 0 {
+		t.Errorf("List() = %v, want empty slice", templates)
+	}
+}
+
+func TestRepository_Create(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	tmpl := &Template{
+		ID: "tmpl-123", FamilyID: "family-456", CreatedBy: "user-789", Kind: KindNote,
+		Name: "Daycare drop-off", Payload: []byte(`{"title":"Drop-off"}`), CreatedAt: now, UpdatedAt: now,
+	}
+
+	mock.ExpectExec("INSERT INTO templates").
+		WithArgs(tmpl.ID, tmpl.FamilyID, tmpl.CreatedBy, tmpl.Kind, tmpl.Name, []byte(tmpl.Payload), tmpl.CreatedAt, tmpl.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := repo.Create(context.Background(), tmpl); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_Delete_Error(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectExec("DELETE FROM templates").
+		WithArgs("tmpl-123").
+		WillReturnError(errors.New("database error"))
+
+	if err := repo.Delete(context.Background(), "tmpl-123"); err == nil {
+		t.Error("Delete() should return error")
+	}
+}
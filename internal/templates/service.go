@@ -0,0 +1,116 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+)
+
+var (
+	ErrInvalidKind      = errors.New("invalid template kind")
+	ErrInvalidPayload   = errors.New("template payload must be a JSON object")
+	ErrTemplateNotFound = errors.New("template not found")
+)
+
+type Service interface {
+	Create(ctx context.Context, userID string, req *CreateTemplateRequest) (*Template, error)
+	Get(ctx context.Context, id string) (*Template, error)
+	List(ctx context.Context, filter *TemplateFilter) ([]Template, error)
+	Update(ctx context.Context, id string, req *CreateTemplateRequest) (*Template, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+// HealthCheck reports whether templates can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) Create(ctx context.Context, userID string, req *CreateTemplateRequest) (*Template, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	tmpl := &Template{
+		ID:        ids.New(),
+		FamilyID:  req.FamilyID,
+		CreatedBy: userID,
+		Kind:      req.Kind,
+		Name:      req.Name,
+		Payload:   req.Payload,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.Create(ctx, tmpl); err != nil {
+		return nil, fmt.Errorf("failed to create template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (*Template, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) List(ctx context.Context, filter *TemplateFilter) ([]Template, error) {
+	return s.repo.List(ctx, filter)
+}
+
+func (s *service) Update(ctx context.Context, id string, req *CreateTemplateRequest) (*Template, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	tmpl, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		return nil, ErrTemplateNotFound
+	}
+
+	tmpl.Kind = req.Kind
+	tmpl.Name = req.Name
+	tmpl.Payload = req.Payload
+	tmpl.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, tmpl); err != nil {
+		return nil, fmt.Errorf("failed to update template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+func (s *service) Delete(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func validate(req *CreateTemplateRequest) error {
+	switch req.Kind {
+	case KindNote, KindFeeding, KindSleep, KindMedication, KindAppointment:
+	default:
+		return ErrInvalidKind
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(req.Payload, &payload); err != nil || payload == nil {
+		return ErrInvalidPayload
+	}
+
+	return nil
+}
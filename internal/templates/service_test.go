@@ -0,0 +1,163 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// mockRepository is a test double for Repository
+type mockRepository struct {
+	templates map[string]*Template
+	createErr error
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{templates: make(map[string]*Template)}
+}
+
+func (m *mockRepository) GetByID(ctx context.Context, id string) (*Template, error) {
+	tmpl, ok := m.templates[id]
+	if !ok {
+		return nil, nil
+	}
+	return tmpl, nil
+}
+
+func (m *mockRepository) List(ctx context.Context, filter *TemplateFilter) ([]Template, error) {
+	var result []Template
+	for _, tmpl := range m.templates {
+		if filter.FamilyID != "" && tmpl.FamilyID != filter.FamilyID {
+			continue
+		}
+		if filter.Kind != nil && tmpl.Kind != *filter.Kind {
+			continue
+		}
+		result = append(result, *tmpl)
+	}
+	return result, nil
+}
+
+func (m *mockRepository) Create(ctx context.Context, tmpl *Template) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.templates[tmpl.ID] = tmpl
+	return nil
+}
+
+func (m *mockRepository) Update(ctx context.Context, tmpl *Template) error {
+	m.templates[tmpl.ID] = tmpl
+	return nil
+}
+
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	delete(m.templates, id)
+	return nil
+}
+
+func TestService_Create(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	req := &CreateTemplateRequest{
+		FamilyID: "family-123",
+		Kind:     KindFeeding,
+		Name:     "Standard bottle",
+		Payload:  []byte(`{"type":"formula","amount":120,"unit":"ml"}`),
+	}
+
+	tmpl, err := svc.Create(context.Background(), "user-123", req)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if tmpl.ID == "" {
+		t.Error("Create() should generate an ID")
+	}
+	if tmpl.CreatedBy != "user-123" {
+		t.Errorf("Create() CreatedBy = %v, want user-123", tmpl.CreatedBy)
+	}
+	if _, ok := repo.templates[tmpl.ID]; !ok {
+		t.Error("Create() should persist the template")
+	}
+}
+
+func TestService_Create_InvalidKind(t *testing.T) {
+	svc := NewService(newMockRepository())
+
+	_, err := svc.Create(context.Background(), "user-123", &CreateTemplateRequest{
+		FamilyID: "family-123", Kind: "diaper", Name: "Wet", Payload: []byte(`{}`),
+	})
+	if !errors.Is(err, ErrInvalidKind) {
+		t.Errorf("Create() error = %v, want ErrInvalidKind", err)
+	}
+}
+
+func TestService_Create_InvalidPayload(t *testing.T) {
+	svc := NewService(newMockRepository())
+
+	for _, payload := range []string{`[1,2]`, `"text"`, `null`} {
+		_, err := svc.Create(context.Background(), "user-123", &CreateTemplateRequest{
+			FamilyID: "family-123", Kind: KindNote, Name: "Note", Payload: []byte(payload),
+		})
+		if !errors.Is(err, ErrInvalidPayload) {
+			t.Errorf("Create() with payload %s error = %v, want ErrInvalidPayload", payload, err)
+		}
+	}
+}
+
+func TestService_Create_RepoError(t *testing.T) {
+	repo := newMockRepository()
+	repo.createErr = errors.New("database error")
+	svc := NewService(repo)
+
+	_, err := svc.Create(context.Background(), "user-123", &CreateTemplateRequest{
+		FamilyID: "family-123", Kind: KindNote, Name: "Note", Payload: []byte(`{}`),
+	})
+	if err == nil {
+		t.Error("Create() should return error when repo fails")
+	}
+}
+
+func TestService_Update(t *testing.T) {
+	repo := newMockRepository()
+	repo.templates["tmpl-1"] = &Template{ID: "tmpl-1", FamilyID: "family-123", Kind: KindNote, Name: "Old", Payload: []byte(`{}`)}
+	svc := NewService(repo)
+
+	tmpl, err := svc.Update(context.Background(), "tmpl-1", &CreateTemplateRequest{
+		FamilyID: "family-123", Kind: KindNote, Name: "New", Payload: []byte(`{"title":"New"}`),
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if tmpl.Name != "New" {
+		t.Errorf("Update() Name = %v, want New", tmpl.Name)
+	}
+}
+
+func TestService_Update_NotFound(t *testing.T) {
+	svc := NewService(newMockRepository())
+
+	_, err := svc.Update(context.Background(), "missing", &CreateTemplateRequest{
+		FamilyID: "family-123", Kind: KindNote, Name: "New", Payload: []byte(`{}`),
+	})
+	if !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Update() error = %v, want ErrTemplateNotFound", err)
+	}
+}
+
+func TestService_List_FiltersByKind(t *testing.T) {
+	repo := newMockRepository()
+	repo.templates["a"] = &Template{ID: "a", FamilyID: "family-123", Kind: KindNote}
+	repo.templates["b"] = &Template{ID: "b", FamilyID: "family-123", Kind: KindFeeding}
+	svc := NewService(repo)
+
+	kind := KindFeeding
+	templates, err := svc.List(context.Background(), &TemplateFilter{FamilyID: "family-123", Kind: &kind})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(templates) != 1 || templates[0].ID != "b" {
+		t.Errorf("List() = %v, want only template b", templates)
+	}
+}
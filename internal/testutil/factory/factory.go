@@ -0,0 +1,128 @@
+// Package factory builds entities for tests. Each builder fills in sane
+// defaults for one child in one family and then applies the overrides in
+// order, so a test only spells out the fields it cares about:
+//
+//	vax := factory.Vaccination(func(v *vaccination.Vaccination) {
+//		v.ScheduledAt = now.AddDate(0, 0, -5)
+//	})
+//
+// Defaults use fixed IDs (family-1, child-1, user-1, ...) and times relative
+// to the current time, since most services compare against time.Now.
+// Packages whose entities are built here cannot use it from their own
+// internal tests without an import cycle.
+//
+// It is meant for new tests; existing tests keep their hand-built fixtures.
+package factory
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+)
+
+// IDs the defaults refer to each other by
+const (
+	FamilyID = "family-1"
+	ChildID  = "child-1"
+	UserID   = "user-1"
+)
+
+func apply[T any](v *T, overrides []func(*T)) T {
+	for _, override := range overrides {
+		override(v)
+	}
+	return *v
+}
+
+func Family(overrides ...func(*family.Family)) family.Family {
+	now := time.Now()
+	return apply(&family.Family{
+		ID:        FamilyID,
+		Name:      "Test Family",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, overrides)
+}
+
+// Child is a three-month-old in the default family
+func Child(overrides ...func(*family.Child)) family.Child {
+	now := time.Now()
+	return apply(&family.Child{
+		ID:          ChildID,
+		FamilyID:    FamilyID,
+		Name:        "Test Child",
+		DateOfBirth: now.AddDate(0, -3, 0).Truncate(24 * time.Hour),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, overrides)
+}
+
+// Vaccination is a first dose scheduled for tomorrow
+func Vaccination(overrides ...func(*vaccination.Vaccination)) vaccination.Vaccination {
+	now := time.Now()
+	return apply(&vaccination.Vaccination{
+		ID:          "vax-1",
+		ChildID:     ChildID,
+		Name:        "DTaP",
+		Dose:        1,
+		ScheduledAt: now.AddDate(0, 0, 1),
+		Status:      vaccination.StatusScheduled,
+		DocumentIDs: []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, overrides)
+}
+
+// Medication is an active once-daily course started yesterday with no end date
+func Medication(overrides ...func(*medication.Medication)) medication.Medication {
+	now := time.Now()
+	return apply(&medication.Medication{
+		ID:        "med-1",
+		ChildID:   ChildID,
+		Name:      "Vitamin D",
+		Dosage:    "400",
+		Unit:      "IU",
+		Frequency: "once_daily",
+		StartDate: now.AddDate(0, 0, -1),
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, overrides)
+}
+
+// Sleep is a one-hour nap that ended just now
+func Sleep(overrides ...func(*sleep.Sleep)) sleep.Sleep {
+	now := time.Now()
+	return apply(&sleep.Sleep{
+		ID:        "sleep-1",
+		ChildID:   ChildID,
+		Type:      sleep.SleepTypeNap,
+		StartTime: now.Add(-time.Hour),
+		EndTime:   &now,
+		Source:    sleep.SourceManual,
+		StartedBy: UserID,
+		EndedBy:   UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, overrides)
+}
+
+// Note is an unpinned note written just now by the default user
+func Note(overrides ...func(*notes.Note)) notes.Note {
+	now := time.Now()
+	return apply(&notes.Note{
+		ID:         "note-1",
+		ChildID:    ChildID,
+		AuthorID:   UserID,
+		Content:    "Test note",
+		Tags:       []string{},
+		OccurredAt: now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		SeenBy:     []notes.NoteSeen{},
+	}, overrides)
+}
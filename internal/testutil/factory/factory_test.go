@@ -0,0 +1,57 @@
+package factory
+
+import (
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+)
+
+func TestDefaultsAreConsistent(t *testing.T) {
+	f := Family()
+	c := Child()
+	if c.FamilyID != f.ID {
+		t.Errorf("Child().FamilyID = %q, want %q", c.FamilyID, f.ID)
+	}
+	for name, childID := range map[string]string{
+		"Vaccination": Vaccination().ChildID,
+		"Medication":  Medication().ChildID,
+		"Sleep":       Sleep().ChildID,
+		"Note":        Note().ChildID,
+	} {
+		if childID != c.ID {
+			t.Errorf("%s().ChildID = %q, want %q", name, childID, c.ID)
+		}
+	}
+
+	v := Vaccination()
+	if !v.Status.Valid() || v.Completed || v.AdministeredAt != nil {
+		t.Errorf("Vaccination() = %+v, want a valid scheduled dose", v)
+	}
+	s := Sleep()
+	if s.EndTime == nil || !s.EndTime.After(s.StartTime) {
+		t.Errorf("Sleep() = %+v, want a finished sleep", s)
+	}
+}
+
+func TestOverridesApplyInOrder(t *testing.T) {
+	c := Child(
+		func(c *family.Child) { c.Name = "First" },
+		func(c *family.Child) { c.Name = "Second" },
+	)
+	if c.Name != "Second" {
+		t.Errorf("Child().Name = %q, want the last override", c.Name)
+	}
+
+	s := Sleep(func(s *sleep.Sleep) { s.EndTime = nil })
+	if s.EndTime != nil {
+		t.Error("Sleep() override to in progress was not applied")
+	}
+
+	// Each call builds a fresh value
+	a := Vaccination(func(v *vaccination.Vaccination) { v.DocumentIDs = append(v.DocumentIDs, "media-1") })
+	if b := Vaccination(); len(b.DocumentIDs) != 0 || len(a.DocumentIDs) != 1 {
+		t.Errorf("Vaccination() shares state between calls: %v, %v", a.DocumentIDs, b.DocumentIDs)
+	}
+}
@@ -0,0 +1,79 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService is a test double for Service
+type mockService struct {
+	activeFn func(ctx context.Context, userID, childID string) (*Active, error)
+}
+
+func (m *mockService) Active(ctx context.Context, userID, childID string) (*Active, error) {
+	return m.activeFn(ctx, userID, childID)
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+	NewHandler(svc).RegisterChildRoutes(router.Group("/children"))
+	return router
+}
+
+func TestHandler_Active(t *testing.T) {
+	svc := &mockService{activeFn: func(ctx context.Context, userID, childID string) (*Active, error) {
+		if userID != "test-user-123" || childID != "child-1" {
+			t.Errorf("Active() called with %s, %s", userID, childID)
+		}
+		return &Active{ChildID: childID, Activities: []Activity{{Kind: KindSleep, ID: "sleep-1", ElapsedSeconds: 60}}}, nil
+	}}
+
+	w := httptest.NewRecorder()
+	setupRouter(svc).ServeHTTP(w, httptest.NewRequest("GET", "/children/child-1/active", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var got Active
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || len(got.Activities) != 1 || got.Activities[0].Kind != KindSleep {
+		t.Errorf("Response = %s", w.Body.String())
+	}
+}
+
+func TestHandler_Active_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrChildNotFound, http.StatusNotFound},
+		{ErrNotMember, http.StatusForbidden},
+		{context.DeadlineExceeded, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		svc := &mockService{activeFn: func(ctx context.Context, userID, childID string) (*Active, error) {
+			return nil, tt.err
+		}}
+
+		w := httptest.NewRecorder()
+		setupRouter(svc).ServeHTTP(w, httptest.NewRequest("GET", "/children/child-1/active", nil))
+		if w.Code != tt.want {
+			t.Errorf("%v: status = %d, want %d", tt.err, w.Code, tt.want)
+		}
+	}
+}
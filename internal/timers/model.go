@@ -0,0 +1,46 @@
+package timers
+
+import (
+	"time"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+// Kind names a timed activity
+type Kind string
+
+const (
+	KindSleep    Kind = "sleep"
+	KindNursing  Kind = "nursing"
+	KindActivity Kind = "activity"
+	KindPumping  Kind = "pumping"
+)
+
+// Activity is one timed activity in progress
+type Activity struct {
+	Kind Kind `json:"kind"`
+	// ID is the record the timer belongs to: the sleep, activity or pumping
+	// session, or the feeding a nursing timer is counting for
+	ID        string `json:"id"`
+	StartedBy string `json:"started_by,omitempty"`
+	Paused    bool   `json:"paused"`
+	// ElapsedSeconds is the time counted up to the response's as_of. While
+	// running, clients add the time since as_of to keep ticking.
+	ElapsedSeconds int `json:"elapsed_seconds"`
+	// Detail is the activity's own record, as its endpoints return it
+	Detail any `json:"detail"`
+}
+
+// Active is every activity in progress for a child
+type Active struct {
+	ChildID    string     `json:"child_id"`
+	Activities []Activity `json:"activities"`
+	AsOf       time.Time  `json:"as_of"`
+}
+
+// hiddenAs maps each kind onto the record type family visibility settings
+// know about
+var hiddenAs = map[Kind]visibility.RecordType{
+	KindSleep:   visibility.RecordSleep,
+	KindNursing: visibility.RecordFeeding,
+}
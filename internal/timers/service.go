@@ -0,0 +1,104 @@
+// Package timers gathers a child's in-progress timed activities, such as a
+// running sleep or nursing timer, into one response for widgets and watch
+// apps.
+package timers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrChildNotFound = errors.New("child not found")
+	ErrNotMember     = errors.New("user is not a member of this family")
+)
+
+type Service interface {
+	// Active returns the child's in-progress activities
+	Active(ctx context.Context, userID, childID string) (*Active, error)
+}
+
+type service struct {
+	familyService family.Service
+	sources       []Source
+	visibility    visibility.Service
+	now           func() time.Time
+}
+
+type Option func(*service)
+
+// WithVisibility leaves out activities whose record type is hidden from the
+// member
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+func NewService(familyService family.Service, sources []Source, opts ...Option) Service {
+	s := &service{familyService: familyService, sources: sources, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *service) Active(ctx context.Context, userID, childID string) (*Active, error) {
+	if err := s.authorize(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	active := &Active{ChildID: childID, Activities: []Activity{}, AsOf: now}
+	for _, src := range s.sources {
+		hidden, err := s.hidden(ctx, userID, childID, src.Kind())
+		if err != nil {
+			return nil, err
+		}
+		if hidden {
+			continue
+		}
+
+		activities, err := src.Active(ctx, userID, childID, now)
+		if err != nil {
+			return nil, err
+		}
+		active.Activities = append(active.Activities, activities...)
+	}
+	return active, nil
+}
+
+func (s *service) hidden(ctx context.Context, userID, childID string, kind Kind) (bool, error) {
+	as, ok := hiddenAs[kind]
+	if s.visibility == nil || !ok {
+		return false, nil
+	}
+	hidden, err := s.visibility.IsHidden(ctx, userID, childID, as)
+	if err != nil {
+		return false, fmt.Errorf("failed to check visibility: %w", err)
+	}
+	return hidden, nil
+}
+
+func (s *service) authorize(ctx context.Context, userID, childID string) error {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return ErrChildNotFound
+	}
+
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package timers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/playtime"
+	"github.com/ninenine/babytrack/internal/pumping"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var testNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type mockFamilyService struct {
+	family.Service
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	if childID == "child-1" {
+		return &family.Child{ID: childID, FamilyID: "family-1"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if userID == "user-1" || userID == "sitter" {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+type mockSleepService struct {
+	sleep.Service
+	active *sleep.Sleep
+	err    error
+}
+
+func (m *mockSleepService) GetActiveSleep(ctx context.Context, childID, viewerID string) (*sleep.Sleep, error) {
+	return m.active, m.err
+}
+
+type mockFeedingService struct {
+	feeding.Service
+	timer *feeding.NursingTimer
+}
+
+func (m *mockFeedingService) GetNursing(ctx context.Context, userID, childID string) (*feeding.NursingTimer, error) {
+	return m.timer, nil
+}
+
+type mockPlaytimeService struct {
+	playtime.Service
+	sessions []playtime.Session
+}
+
+func (m *mockPlaytimeService) Active(ctx context.Context, userID, childID string) ([]playtime.Session, error) {
+	return m.sessions, nil
+}
+
+type mockPumpingService struct {
+	pumping.Service
+	running map[string][]pumping.Session
+}
+
+func (m *mockPumpingService) Running(ctx context.Context, userID, familyID string) ([]pumping.Session, error) {
+	return m.running[familyID], nil
+}
+
+// mockVisibilityService hides feeding from the sitter
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) IsHidden(ctx context.Context, userID, childID string, recordType visibility.RecordType) (bool, error) {
+	return userID == "sitter" && recordType == visibility.RecordFeeding, nil
+}
+
+func newTestService(sleeps *mockSleepService, feeds *mockFeedingService, opts ...Option) *service {
+	svc := NewService(&mockFamilyService{}, []Source{SleepSource(sleeps), NursingSource(feeds)}, opts...).(*service)
+	svc.now = func() time.Time { return testNow }
+	return svc
+}
+
+func TestService_Active(t *testing.T) {
+	sleeps := &mockSleepService{active: &sleep.Sleep{ID: "sleep-1", StartTime: testNow.Add(-90 * time.Minute), StartedBy: "user-2"}}
+	feeds := &mockFeedingService{timer: &feeding.NursingTimer{FeedingID: "feed-1", Paused: true, LeftSeconds: 300, RightSeconds: 120}}
+	svc := newTestService(sleeps, feeds)
+
+	active, err := svc.Active(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Active() error = %v", err)
+	}
+	if !active.AsOf.Equal(testNow) || len(active.Activities) != 2 {
+		t.Fatalf("Active() = %+v", active)
+	}
+
+	got := active.Activities[0]
+	if got.Kind != KindSleep || got.ID != "sleep-1" || got.StartedBy != "user-2" || got.Paused || got.ElapsedSeconds != 5400 {
+		t.Errorf("sleep activity = %+v", got)
+	}
+	got = active.Activities[1]
+	if got.Kind != KindNursing || got.ID != "feed-1" || !got.Paused || got.ElapsedSeconds != 420 {
+		t.Errorf("nursing activity = %+v", got)
+	}
+}
+
+func TestService_Active_PlaytimeAndPumping(t *testing.T) {
+	activities := &mockPlaytimeService{sessions: []playtime.Session{
+		{ID: "tummy-1", Type: playtime.TypeTummyTime, StartTime: testNow.Add(-5 * time.Minute), StartedBy: "user-1"},
+		{ID: "outdoor-1", Type: playtime.TypeOutdoor, StartTime: testNow.Add(-time.Hour)},
+	}}
+	pumps := &mockPumpingService{running: map[string][]pumping.Session{
+		"family-1": {{ID: "pump-1", StartTime: testNow.Add(-10 * time.Minute), PumpedBy: "user-2", Running: true}},
+	}}
+	svc := NewService(&mockFamilyService{}, []Source{
+		PlaytimeSource(activities),
+		PumpingSource(pumps, &mockFamilyService{}),
+	}).(*service)
+	svc.now = func() time.Time { return testNow }
+
+	active, err := svc.Active(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Active() error = %v", err)
+	}
+	if len(active.Activities) != 3 {
+		t.Fatalf("Active() = %+v, want two activities and one pumping session", active.Activities)
+	}
+
+	want := []struct {
+		kind    Kind
+		id      string
+		elapsed int
+	}{
+		{KindActivity, "tummy-1", 300},
+		{KindActivity, "outdoor-1", 3600},
+		{KindPumping, "pump-1", 600},
+	}
+	for i, w := range want {
+		got := active.Activities[i]
+		if got.Kind != w.kind || got.ID != w.id || got.ElapsedSeconds != w.elapsed {
+			t.Errorf("activity %d = %+v, want %s %s after %ds", i, got, w.kind, w.id, w.elapsed)
+		}
+	}
+	if active.Activities[2].StartedBy != "user-2" {
+		t.Errorf("pumping started_by = %q, want the member pumping", active.Activities[2].StartedBy)
+	}
+}
+
+func TestService_Active_None(t *testing.T) {
+	svc := newTestService(&mockSleepService{}, &mockFeedingService{})
+
+	active, err := svc.Active(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Active() error = %v", err)
+	}
+	if active.Activities == nil || len(active.Activities) != 0 {
+		t.Errorf("Activities = %v, want empty", active.Activities)
+	}
+}
+
+func TestService_Active_Hidden(t *testing.T) {
+	sleeps := &mockSleepService{active: &sleep.Sleep{ID: "sleep-1", StartTime: testNow}}
+	feeds := &mockFeedingService{timer: &feeding.NursingTimer{FeedingID: "feed-1"}}
+	svc := newTestService(sleeps, feeds, WithVisibility(&mockVisibilityService{}))
+
+	active, err := svc.Active(context.Background(), "sitter", "child-1")
+	if err != nil {
+		t.Fatalf("Active() error = %v", err)
+	}
+	if len(active.Activities) != 1 || active.Activities[0].Kind != KindSleep {
+		t.Errorf("Activities = %+v, want only sleep", active.Activities)
+	}
+}
+
+func TestService_Active_Errors(t *testing.T) {
+	svc := newTestService(&mockSleepService{}, &mockFeedingService{})
+
+	if _, err := svc.Active(context.Background(), "user-1", "missing"); !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("unknown child: error = %v, want ErrChildNotFound", err)
+	}
+	if _, err := svc.Active(context.Background(), "stranger", "child-1"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("non-member: error = %v, want ErrNotMember", err)
+	}
+
+	failing := newTestService(&mockSleepService{err: errors.New("database error")}, &mockFeedingService{})
+	if _, err := failing.Active(context.Background(), "user-1", "child-1"); err == nil {
+		t.Error("expected error when a source fails")
+	}
+}
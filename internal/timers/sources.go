@@ -0,0 +1,151 @@
+package timers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/playtime"
+	"github.com/ninenine/babytrack/internal/pumping"
+	"github.com/ninenine/babytrack/internal/sleep"
+)
+
+// Source reports a child's in-progress activities of one kind. A timed
+// activity joins the endpoint by registering a Source for it.
+type Source interface {
+	Kind() Kind
+	// Active returns the running activities, or none
+	Active(ctx context.Context, userID, childID string, now time.Time) ([]Activity, error)
+}
+
+type sleepSource struct {
+	sleepService sleep.Service
+}
+
+// SleepSource reports the child's running sleep timer
+func SleepSource(sleepService sleep.Service) Source {
+	return &sleepSource{sleepService: sleepService}
+}
+
+func (s *sleepSource) Kind() Kind { return KindSleep }
+
+func (s *sleepSource) Active(ctx context.Context, userID, childID string, now time.Time) ([]Activity, error) {
+	active, err := s.sleepService.GetActiveSleep(ctx, childID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active sleep: %w", err)
+	}
+	if active == nil {
+		return nil, nil
+	}
+	return []Activity{{
+		Kind:           KindSleep,
+		ID:             active.ID,
+		StartedBy:      active.StartedBy,
+		ElapsedSeconds: int(now.Sub(active.StartTime).Seconds()),
+		Detail:         active,
+	}}, nil
+}
+
+type nursingSource struct {
+	feedingService feeding.Service
+}
+
+// NursingSource reports the child's running nursing timer
+func NursingSource(feedingService feeding.Service) Source {
+	return &nursingSource{feedingService: feedingService}
+}
+
+func (s *nursingSource) Kind() Kind { return KindNursing }
+
+func (s *nursingSource) Active(ctx context.Context, userID, childID string, now time.Time) ([]Activity, error) {
+	timer, err := s.feedingService.GetNursing(ctx, userID, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nursing timer: %w", err)
+	}
+	if timer == nil {
+		return nil, nil
+	}
+	return []Activity{{
+		Kind:           KindNursing,
+		ID:             timer.FeedingID,
+		StartedBy:      timer.StartedBy,
+		Paused:         timer.Paused,
+		ElapsedSeconds: timer.LeftSeconds + timer.RightSeconds,
+		Detail:         timer,
+	}}, nil
+}
+
+type playtimeSource struct {
+	playtimeService playtime.Service
+}
+
+// PlaytimeSource reports the child's running activity sessions, one for each
+// type that is running
+func PlaytimeSource(playtimeService playtime.Service) Source {
+	return &playtimeSource{playtimeService: playtimeService}
+}
+
+func (s *playtimeSource) Kind() Kind { return KindActivity }
+
+func (s *playtimeSource) Active(ctx context.Context, userID, childID string, now time.Time) ([]Activity, error) {
+	sessions, err := s.playtimeService.Active(ctx, userID, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get running activities: %w", err)
+	}
+
+	activities := make([]Activity, 0, len(sessions))
+	for i := range sessions {
+		session := &sessions[i]
+		activities = append(activities, Activity{
+			Kind:           KindActivity,
+			ID:             session.ID,
+			StartedBy:      session.StartedBy,
+			ElapsedSeconds: int(now.Sub(session.StartTime).Seconds()),
+			Detail:         session,
+		})
+	}
+	return activities, nil
+}
+
+type pumpingSource struct {
+	pumpingService pumping.Service
+	familyService  family.Service
+}
+
+// PumpingSource reports pumping sessions running in the child's family.
+// Expressed milk belongs to the family, so every child of it shows them.
+func PumpingSource(pumpingService pumping.Service, familyService family.Service) Source {
+	return &pumpingSource{pumpingService: pumpingService, familyService: familyService}
+}
+
+func (s *pumpingSource) Kind() Kind { return KindPumping }
+
+func (s *pumpingSource) Active(ctx context.Context, userID, childID string, now time.Time) ([]Activity, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child: %w", err)
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+
+	sessions, err := s.pumpingService.Running(ctx, userID, child.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get running pumping sessions: %w", err)
+	}
+
+	activities := make([]Activity, 0, len(sessions))
+	for i := range sessions {
+		session := &sessions[i]
+		activities = append(activities, Activity{
+			Kind:           KindPumping,
+			ID:             session.ID,
+			StartedBy:      session.PumpedBy,
+			ElapsedSeconds: int(now.Sub(session.StartTime).Seconds()),
+			Detail:         session,
+		})
+	}
+	return activities, nil
+}
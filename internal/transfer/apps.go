@@ -0,0 +1,298 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/measure"
+	"github.com/ninenine/babytrack/internal/sleep"
+)
+
+// Sources a child can be imported from. Apart from SourceBundle, each names an
+// app whose export is mapped onto a bundle before import.
+const (
+	SourceBundle      = "bundle"
+	SourceHuckleberry = "huckleberry"
+	SourceBabyTracker = "baby_tracker"
+	SourceGlowBaby    = "glow_baby"
+)
+
+var (
+	ErrUnknownSource = errors.New("unknown import source")
+	ErrInvalidExport = errors.New("invalid export file")
+)
+
+// AppImportRequest carries another app's export file. Those exports say
+// little about the child, so the child's details come with the file.
+type AppImportRequest struct {
+	Source      string
+	Name        string
+	DateOfBirth time.Time
+	Gender      string
+	// Location is the time zone the export's local times were written in;
+	// nil means UTC
+	Location *time.Location
+	Data     []byte
+}
+
+// appRecords is what a mapper recovers from an export
+type appRecords struct {
+	feedings []feeding.Feeding
+	sleep    []sleep.Sleep
+	skipped  map[string]int
+}
+
+// appMapper reads one app's export, interpreting local times in loc
+type appMapper func(data []byte, loc *time.Location) (*appRecords, error)
+
+var appMappers = map[string]appMapper{
+	SourceHuckleberry: mapHuckleberry,
+	SourceBabyTracker: mapBabyTracker,
+	SourceGlowBaby:    mapGlowBaby,
+}
+
+// addFeeding keeps ref, the record's position in the export, as its source ID
+func (r *appRecords) addFeeding(ref string, f *feeding.Feeding) {
+	f.ID = ref
+	f.CreatedAt = f.StartTime
+	r.feedings = append(r.feedings, *f)
+}
+
+func (r *appRecords) addSleep(ref string, start time.Time, end *time.Time, notes string) {
+	r.sleep = append(r.sleep, sleep.Sleep{
+		ID: ref, Type: sleepTypeAt(start), StartTime: start, EndTime: end, Notes: notes, CreatedAt: start,
+	})
+}
+
+func (r *appRecords) skip(kind string) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "" {
+		kind = "unknown"
+	}
+	if r.skipped == nil {
+		r.skipped = make(map[string]int)
+	}
+	r.skipped[kind]++
+}
+
+// sleepTypeAt guesses nap or night from when the sleep began, since none of
+// the supported apps export the distinction
+func sleepTypeAt(start time.Time) sleep.SleepType {
+	if h := start.Hour(); h >= 19 || h < 7 {
+		return sleep.SleepTypeNight
+	}
+	return sleep.SleepTypeNap
+}
+
+// csvTable is a CSV export whose columns are looked up by header name, so
+// column order and extra columns don't matter
+type csvTable struct {
+	cols map[string]int
+	rows [][]string
+}
+
+func readCSV(data []byte) (*csvTable, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExport, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no header row", ErrInvalidExport)
+	}
+
+	t := &csvTable{cols: make(map[string]int), rows: records[1:]}
+	for i, h := range records[0] {
+		t.cols[headerKey(h)] = i
+	}
+	return t, nil
+}
+
+// headerKey drops case, punctuation and any parenthesized unit, so
+// "Left duration (min)" and "left_duration" are the same column
+func headerKey(h string) string {
+	if i := strings.IndexByte(h, '('); i >= 0 {
+		h = h[:i]
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(h) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (t *csvTable) has(names ...string) bool {
+	for _, n := range names {
+		if _, ok := t.cols[headerKey(n)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// get returns the row's value in the first of names the table has
+func (t *csvTable) get(row []string, names ...string) string {
+	for _, n := range names {
+		if i, ok := t.cols[headerKey(n)]; ok {
+			if i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+func blankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// localLayouts covers the timestamp styles the supported apps write, in
+// ISO and US order
+var localLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"1/2/06, 15:04",
+	"1/2/06, 3:04 PM",
+	"1/2/06 15:04",
+	"1/2/06 3:04 PM",
+	"1/2/2006, 15:04",
+	"1/2/2006, 3:04 PM",
+	"1/2/2006 15:04",
+	"1/2/2006 3:04 PM",
+	"Jan 2, 2006 15:04",
+	"Jan 2, 2006 3:04 PM",
+}
+
+// parseLocalTime reads a timestamp, taking times without an offset as local
+// to loc
+func parseLocalTime(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
+
+// parseMinutes reads a duration given in minutes ("15", "15.5"), as h:mm
+// ("1:05") or as "1h5m". Blank means zero.
+func parseMinutes(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if m, err := strconv.ParseFloat(s, 64); err == nil && m >= 0 {
+		return time.Duration(m * float64(time.Minute)), nil
+	}
+	if h, m, ok := strings.Cut(s, ":"); ok {
+		hours, err1 := strconv.Atoi(h)
+		mins, err2 := strconv.Atoi(m)
+		if err1 == nil && err2 == nil && hours >= 0 && mins >= 0 {
+			return time.Duration(hours)*time.Hour + time.Duration(mins)*time.Minute, nil
+		}
+	}
+	if d, err := time.ParseDuration(strings.ReplaceAll(s, " ", "")); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("unrecognized duration %q", s)
+}
+
+// parseAmount reads a volume such as "120 ml" or "4oz", in unit when it is
+// given separately. Blank means no amount.
+func parseAmount(s, unit string) (*float64, string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, "", nil
+	}
+	if unit = strings.TrimSpace(unit); unit != "" {
+		s += " " + unit
+	}
+	q, err := measure.ParseQuantity(s, measure.Milliliter)
+	if err != nil {
+		return nil, "", err
+	}
+	return &q.Value, string(q.Unit), nil
+}
+
+// breastSide names the side fed from given how long each side took
+func breastSide(left, right time.Duration) string {
+	switch {
+	case left > 0 && right > 0:
+		return "both"
+	case left > 0:
+		return string(feeding.SideLeft)
+	case right > 0:
+		return string(feeding.SideRight)
+	}
+	return ""
+}
+
+// normalizeSide maps an app's side label onto left, right or both
+func normalizeSide(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "l", "left":
+		return string(feeding.SideLeft)
+	case "r", "right":
+		return string(feeding.SideRight)
+	case "both", "lr", "rl", "left/right", "right/left":
+		return "both"
+	}
+	return ""
+}
+
+// rowError points at the row of an export that couldn't be read, counting the
+// header as row 1 as spreadsheets do
+func rowError(file string, i int, err error) error {
+	if file != "" {
+		return fmt.Errorf("%w: %s row %d: %w", ErrInvalidExport, file, i+2, err)
+	}
+	return fmt.Errorf("%w: row %d: %w", ErrInvalidExport, i+2, err)
+}
+
+// timeRange reads a start time and an optional end time
+func timeRange(start, end string, loc *time.Location) (time.Time, *time.Time, error) {
+	s, err := parseLocalTime(start, loc)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	if strings.TrimSpace(end) == "" {
+		return s, nil, nil
+	}
+	e, err := parseLocalTime(end, loc)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return s, &e, nil
+}
+
+// endAfter returns start plus d, or nil when there is no duration
+func endAfter(start time.Time, d time.Duration) *time.Time {
+	if d <= 0 {
+		return nil
+	}
+	end := start.Add(d)
+	return &end
+}
@@ -0,0 +1,161 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/sleep"
+)
+
+func TestMapHuckleberry(t *testing.T) {
+	export := "Type,Start,End,Duration,Start Condition,Start Location,End Condition,Notes\n" +
+		"Sleep,2024-03-01 13:00,2024-03-01 14:30,01:30,,,,Short nap\n" +
+		"Feed,2024-03-01 15:00,2024-03-01 15:20,00:20,00:12L,Breast,00:08R,\n" +
+		"Feed,2024-03-01 18:00,2024-03-01 18:10,00:10,Breast Milk,Bottle,4oz,\n" +
+		"Solids,2024-03-01 12:00,,,Banana,,,\n" +
+		"Diaper,2024-03-01 16:00,,,Wet,,,\n" +
+		"Pump,2024-03-01 17:00,,,,,,\n"
+
+	recs, err := mapHuckleberry([]byte(export), time.UTC)
+	if err != nil {
+		t.Fatalf("mapHuckleberry() error = %v", err)
+	}
+
+	if len(recs.sleep) != 1 || recs.sleep[0].Type != sleep.SleepTypeNap || recs.sleep[0].ID != "row-2" {
+		t.Errorf("mapHuckleberry() sleep = %+v", recs.sleep)
+	}
+	if len(recs.feedings) != 3 {
+		t.Fatalf("mapHuckleberry() mapped %d feedings, want 3", len(recs.feedings))
+	}
+	if f := recs.feedings[0]; f.Type != feeding.FeedingTypeBreast || f.Side != "both" {
+		t.Errorf("mapHuckleberry() breast feed = %+v", f)
+	}
+	if f := recs.feedings[1]; f.Type != feeding.FeedingTypeBottle || f.Amount == nil || *f.Amount != 4 || f.Unit != "oz" {
+		t.Errorf("mapHuckleberry() bottle = %+v", f)
+	}
+	if f := recs.feedings[2]; f.Type != feeding.FeedingTypeSolid || f.Notes != "Banana" {
+		t.Errorf("mapHuckleberry() solids = %+v", f)
+	}
+	if recs.skipped["diaper"] != 1 || recs.skipped["pump"] != 1 {
+		t.Errorf("mapHuckleberry() skipped = %v", recs.skipped)
+	}
+}
+
+func TestMapHuckleberry_BadTime(t *testing.T) {
+	export := "Type,Start,End\nSleep,yesterday,\n"
+
+	_, err := mapHuckleberry([]byte(export), time.UTC)
+	if !errors.Is(err, ErrInvalidExport) {
+		t.Errorf("mapHuckleberry() error = %v, want ErrInvalidExport", err)
+	}
+}
+
+func TestMapBabyTracker_Zip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	sheets := map[string]string{
+		"Ava_nursing.csv": "Baby,Time,Start Side,Left duration (min),Right duration (min),Total Duration (min),Note\n" +
+			"Ava,\"3/1/24, 9:00 PM\",Left,10,0,10,\n",
+		"Ava_formula.csv": "Baby,Time,Amount,Note\nAva,\"3/1/24, 11:00 AM\",120 ml,Before nap\n",
+		"Ava_sleep.csv":   "Baby,Time,Duration(minutes),Note\nAva,\"3/1/24, 8:00 PM\",600,\n",
+		"Ava_diaper.csv":  "Baby,Time,Status,Note\nAva,\"3/1/24, 7:00 AM\",Wet,\nAva,\"3/1/24, 9:00 AM\",Dirty,\n",
+	}
+	for name, content := range sheets {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	loc := time.FixedZone("EST", -5*3600)
+	recs, err := mapBabyTracker(buf.Bytes(), loc)
+	if err != nil {
+		t.Fatalf("mapBabyTracker() error = %v", err)
+	}
+
+	if len(recs.feedings) != 2 || len(recs.sleep) != 1 {
+		t.Fatalf("mapBabyTracker() feedings=%d sleep=%d, want 2 and 1", len(recs.feedings), len(recs.sleep))
+	}
+	for _, f := range recs.feedings {
+		switch f.Type {
+		case feeding.FeedingTypeBreast:
+			if f.Side != "left" || f.EndTime == nil || f.EndTime.Sub(f.StartTime) != 10*time.Minute {
+				t.Errorf("mapBabyTracker() nursing = %+v", f)
+			}
+			if f.StartTime.UTC().Hour() != 2 {
+				t.Errorf("mapBabyTracker() should read times in the given zone, got %v", f.StartTime.UTC())
+			}
+		case feeding.FeedingTypeFormula:
+			if f.Amount == nil || *f.Amount != 120 || f.Unit != "ml" || f.ID != "Ava_formula.csv:2" {
+				t.Errorf("mapBabyTracker() formula = %+v", f)
+			}
+		default:
+			t.Errorf("mapBabyTracker() unexpected feeding %+v", f)
+		}
+	}
+	if s := recs.sleep[0]; s.Type != sleep.SleepTypeNight || s.EndTime == nil || s.EndTime.Sub(s.StartTime) != 10*time.Hour {
+		t.Errorf("mapBabyTracker() sleep = %+v", s)
+	}
+	if recs.skipped["diaper"] != 2 {
+		t.Errorf("mapBabyTracker() skipped = %v", recs.skipped)
+	}
+}
+
+func TestMapBabyTracker_SingleSheet(t *testing.T) {
+	export := "Time,Duration(minutes),Note\n2024-03-01 13:00,45,\n"
+
+	recs, err := mapBabyTracker([]byte(export), time.UTC)
+	if err != nil {
+		t.Fatalf("mapBabyTracker() error = %v", err)
+	}
+	if len(recs.sleep) != 1 || recs.sleep[0].ID != "row-2" {
+		t.Errorf("mapBabyTracker() sleep = %+v", recs.sleep)
+	}
+}
+
+func TestMapGlowBaby(t *testing.T) {
+	export := `{"events":[
+		{"id":"g-1","type":"Breastfeeding","start":"2024-03-01T09:00:00Z","end":"2024-03-01T09:15:00Z","side":"R"},
+		{"type":"bottle","start_time":1709290800,"amount":90,"unit":"ml"},
+		{"type":"sleep","start":"2024-03-01 21:00","end":"2024-03-02 06:00"},
+		{"type":"diaper","start":"2024-03-01T10:00:00Z"}
+	]}`
+
+	recs, err := mapGlowBaby([]byte(export), time.UTC)
+	if err != nil {
+		t.Fatalf("mapGlowBaby() error = %v", err)
+	}
+
+	if len(recs.feedings) != 2 {
+		t.Fatalf("mapGlowBaby() mapped %d feedings, want 2", len(recs.feedings))
+	}
+	if f := recs.feedings[0]; f.ID != "g-1" || f.Side != "right" || f.EndTime == nil {
+		t.Errorf("mapGlowBaby() breastfeed = %+v", f)
+	}
+	if f := recs.feedings[1]; f.Amount == nil || *f.Amount != 90 || !f.StartTime.Equal(time.Unix(1709290800, 0)) {
+		t.Errorf("mapGlowBaby() bottle = %+v", f)
+	}
+	if len(recs.sleep) != 1 || recs.sleep[0].Type != sleep.SleepTypeNight {
+		t.Errorf("mapGlowBaby() sleep = %+v", recs.sleep)
+	}
+	if recs.skipped["diaper"] != 1 {
+		t.Errorf("mapGlowBaby() skipped = %v", recs.skipped)
+	}
+}
+
+func TestMapGlowBaby_NotJSON(t *testing.T) {
+	_, err := mapGlowBaby([]byte("Type,Start\n"), time.UTC)
+	if !errors.Is(err, ErrInvalidExport) {
+		t.Errorf("mapGlowBaby() error = %v, want ErrInvalidExport", err)
+	}
+}
@@ -0,0 +1,203 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+)
+
+// Baby Tracker's export is a zip holding one CSV per activity, named after
+// the activity, e.g. "Ava_nursing.csv". A single sheet can be uploaded too.
+const (
+	babyTrackerNursing   = "nursing"
+	babyTrackerFormula   = "formula"
+	babyTrackerExpressed = "expressed"
+	babyTrackerSleep     = "sleep"
+	babyTrackerSolids    = "solids"
+)
+
+// maxBabyTrackerSheet bounds each CSV read out of the zip
+const maxBabyTrackerSheet = 16 << 20
+
+func mapBabyTracker(data []byte, loc *time.Location) (*appRecords, error) {
+	recs := &appRecords{}
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		t, err := readCSV(data)
+		if err != nil {
+			return nil, err
+		}
+		kind := babyTrackerKindFromColumns(t)
+		if kind == "" {
+			return nil, fmt.Errorf("%w: unrecognized Baby Tracker sheet", ErrInvalidExport)
+		}
+		return recs, babyTrackerSheet(recs, "", kind, t, loc)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExport, err)
+	}
+	for _, f := range zr.File {
+		name := path.Base(f.Name)
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(name), ".csv") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		sheet, err := readZipFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidExport, name, err)
+		}
+		t, err := readCSV(sheet)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		kind := babyTrackerKindFromName(name)
+		if !babyTrackerMapped(kind) {
+			// Count every entry of an activity that has no equivalent here
+			for _, row := range t.rows {
+				if !blankRow(row) {
+					recs.skip(kind)
+				}
+			}
+			continue
+		}
+		if err := babyTrackerSheet(recs, name, kind, t, loc); err != nil {
+			return nil, err
+		}
+	}
+	return recs, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxBabyTrackerSheet+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBabyTrackerSheet {
+		return nil, fmt.Errorf("file too large")
+	}
+	return data, nil
+}
+
+// babyTrackerKindFromName reads the activity from the last part of a sheet's
+// name, so "Ava_nursing.csv" is nursing
+func babyTrackerKindFromName(name string) string {
+	base := strings.ToLower(strings.TrimSuffix(name, path.Ext(name)))
+	if i := strings.LastIndexAny(base, "_- "); i >= 0 {
+		base = base[i+1:]
+	}
+	switch base {
+	case "solid", "food", "foods":
+		return babyTrackerSolids
+	case "pumped", "bottle":
+		return babyTrackerExpressed
+	}
+	return base
+}
+
+// babyTrackerKindFromColumns tells a lone sheet's activity from its columns
+func babyTrackerKindFromColumns(t *csvTable) string {
+	switch {
+	case t.has("Left duration", "Right duration", "Start Side"):
+		return babyTrackerNursing
+	case t.has("Amount"):
+		return babyTrackerExpressed
+	case t.has("Food type", "Food"):
+		return babyTrackerSolids
+	case t.has("Duration"):
+		return babyTrackerSleep
+	}
+	return ""
+}
+
+func babyTrackerMapped(kind string) bool {
+	switch kind {
+	case babyTrackerNursing, babyTrackerFormula, babyTrackerExpressed, babyTrackerSleep, babyTrackerSolids:
+		return true
+	}
+	return false
+}
+
+func babyTrackerSheet(recs *appRecords, file, kind string, t *csvTable, loc *time.Location) error {
+	for i, row := range t.rows {
+		if blankRow(row) {
+			continue
+		}
+		ref := fmt.Sprintf("row-%d", i+2)
+		if file != "" {
+			ref = fmt.Sprintf("%s:%d", file, i+2)
+		}
+		if err := babyTrackerRow(recs, ref, kind, t, row, loc); err != nil {
+			return rowError(file, i, err)
+		}
+	}
+	return nil
+}
+
+func babyTrackerRow(recs *appRecords, ref, kind string, t *csvTable, row []string, loc *time.Location) error {
+	start, err := parseLocalTime(t.get(row, "Time", "Start Time", "Date"), loc)
+	if err != nil {
+		return err
+	}
+	notes := t.get(row, "Note", "Notes")
+
+	switch kind {
+	case babyTrackerSleep:
+		d, err := parseMinutes(t.get(row, "Duration"))
+		if err != nil {
+			return err
+		}
+		recs.addSleep(ref, start, endAfter(start, d), notes)
+
+	case babyTrackerNursing:
+		left, err := parseMinutes(t.get(row, "Left duration"))
+		if err != nil {
+			return err
+		}
+		right, err := parseMinutes(t.get(row, "Right duration"))
+		if err != nil {
+			return err
+		}
+		total, err := parseMinutes(t.get(row, "Total Duration"))
+		if err != nil {
+			return err
+		}
+		if total == 0 {
+			total = left + right
+		}
+		side := breastSide(left, right)
+		if side == "" {
+			side = normalizeSide(t.get(row, "Start Side"))
+		}
+		recs.addFeeding(ref, &feeding.Feeding{
+			Type: feeding.FeedingTypeBreast, StartTime: start, EndTime: endAfter(start, total), Side: side, Notes: notes,
+		})
+
+	case babyTrackerFormula, babyTrackerExpressed:
+		amount, unit, err := parseAmount(t.get(row, "Amount"), t.get(row, "Unit"))
+		if err != nil {
+			return err
+		}
+		typ := feeding.FeedingTypeBottle
+		if kind == babyTrackerFormula {
+			typ = feeding.FeedingTypeFormula
+		}
+		recs.addFeeding(ref, &feeding.Feeding{Type: typ, StartTime: start, Amount: amount, Unit: unit, Notes: notes})
+
+	case babyTrackerSolids:
+		recs.addFeeding(ref, &feeding.Feeding{
+			Type: feeding.FeedingTypeSolid, StartTime: start, Notes: joinNotes(t.get(row, "Food type", "Food"), notes),
+		})
+	}
+	return nil
+}
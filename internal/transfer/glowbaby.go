@@ -0,0 +1,154 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+)
+
+// glowBabyFeedTypes maps Glow Baby event types onto feeding types; sleep is
+// handled apart and anything else is skipped
+var glowBabyFeedTypes = map[string]feeding.FeedingType{
+	"breastfeeding":  feeding.FeedingTypeBreast,
+	"breast_feeding": feeding.FeedingTypeBreast,
+	"nursing":        feeding.FeedingTypeBreast,
+	"bottle":         feeding.FeedingTypeBottle,
+	"bottle_feeding": feeding.FeedingTypeBottle,
+	"breast_milk":    feeding.FeedingTypeBottle,
+	"formula":        feeding.FeedingTypeFormula,
+	"solid":          feeding.FeedingTypeSolid,
+	"solids":         feeding.FeedingTypeSolid,
+	"meal":           feeding.FeedingTypeSolid,
+}
+
+// mapGlowBaby reads Glow Baby's JSON export: an array of events, or an object
+// holding them under "events" or "data". Each event has a type and a start
+// time, given as RFC 3339, a local time or Unix seconds or milliseconds.
+func mapGlowBaby(data []byte, loc *time.Location) (*appRecords, error) {
+	events, err := glowBabyEvents(data)
+	if err != nil {
+		return nil, err
+	}
+
+	recs := &appRecords{}
+	for i, ev := range events {
+		kind := strings.ToLower(strings.ReplaceAll(ev.str("type", "event_type", "category"), " ", "_"))
+		ref := fmt.Sprintf("event-%d", i)
+		if id := ev.str("id", "uuid"); id != "" {
+			ref = id
+		}
+
+		feedType, isFeed := glowBabyFeedTypes[kind]
+		if !isFeed && kind != "sleep" && kind != "nap" {
+			recs.skip(kind)
+			continue
+		}
+
+		start, err := ev.time(loc, "start", "start_time", "startTime", "time", "timestamp")
+		if err != nil {
+			return nil, fmt.Errorf("%w: event %d: %w", ErrInvalidExport, i, err)
+		}
+		var end *time.Time
+		if ev.present("end", "end_time", "endTime") {
+			e, err := ev.time(loc, "end", "end_time", "endTime")
+			if err != nil {
+				return nil, fmt.Errorf("%w: event %d: %w", ErrInvalidExport, i, err)
+			}
+			end = &e
+		}
+		notes := ev.str("notes", "note", "comment")
+
+		if !isFeed {
+			recs.addSleep(ref, start, end, notes)
+			continue
+		}
+
+		f := &feeding.Feeding{Type: feedType, StartTime: start, EndTime: end, Notes: notes}
+		if feedType == feeding.FeedingTypeBreast {
+			f.Side = normalizeSide(ev.str("side", "breast"))
+		} else if feedType != feeding.FeedingTypeSolid {
+			f.Amount, f.Unit, err = parseAmount(ev.str("amount", "volume"), ev.str("unit", "amount_unit"))
+			if err != nil {
+				return nil, fmt.Errorf("%w: event %d: %w", ErrInvalidExport, i, err)
+			}
+		}
+		recs.addFeeding(ref, f)
+	}
+	return recs, nil
+}
+
+// glowBabyEvent is one event with its keys compared case-insensitively
+type glowBabyEvent map[string]any
+
+func glowBabyEvents(data []byte) ([]glowBabyEvent, error) {
+	data = bytes.TrimSpace(data)
+	var events []glowBabyEvent
+	if len(data) > 0 && data[0] == '{' {
+		var wrapped map[string]json.RawMessage
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidExport, err)
+		}
+		list, ok := wrapped["events"]
+		if !ok {
+			list, ok = wrapped["data"]
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: expected an events list", ErrInvalidExport)
+		}
+		data = list
+	}
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExport, err)
+	}
+	return events, nil
+}
+
+func (e glowBabyEvent) lookup(keys ...string) (any, bool) {
+	for _, k := range keys {
+		for ek, v := range e {
+			if strings.EqualFold(ek, k) && v != nil {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func (e glowBabyEvent) present(keys ...string) bool {
+	v, ok := e.lookup(keys...)
+	return ok && v != ""
+}
+
+// str returns the first of keys the event has, with numbers written out
+func (e glowBabyEvent) str(keys ...string) string {
+	v, _ := e.lookup(keys...)
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func (e glowBabyEvent) time(loc *time.Location, keys ...string) (time.Time, error) {
+	v, ok := e.lookup(keys...)
+	if !ok {
+		return time.Time{}, fmt.Errorf("missing %s", keys[0])
+	}
+	if n, ok := v.(float64); ok {
+		// Thirteen digits is milliseconds; seconds won't get there for millennia
+		if math.Abs(n) >= 1e12 {
+			return time.UnixMilli(int64(n)), nil
+		}
+		return time.Unix(int64(n), 0), nil
+	}
+	s, _ := v.(string)
+	return parseLocalTime(s, loc)
+}
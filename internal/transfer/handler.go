@@ -0,0 +1,174 @@
+package transfer
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/apijson"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/occurrence"
+)
+
+// maxAppExport bounds an export uploaded from another app
+const maxAppExport = 32 << 20
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterChildRoutes registers the bundle export routes on the children
+// group. Middleware in export runs ahead of the bundle download only.
+func (h *Handler) RegisterChildRoutes(rg *gin.RouterGroup, export ...gin.HandlerFunc) {
+	rg.Group("", export...).GET("/:id/bundle", h.exportBundle)
+	rg.GET("/:id/imports", h.listImports)
+}
+
+// RegisterFamilyRoutes registers the import route on the families group. It
+// takes a bundle, or another app's export when ?source= names the app.
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.POST("/:familyId/child-imports", h.importChild)
+}
+
+func (h *Handler) exportBundle(c *gin.Context) {
+	userID := c.GetString("user_id")
+	childID := c.Param("id")
+
+	bundle, err := h.service.Stream(c.Request.Context(), userID, childID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="child-`+childID+`.json"`)
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := writeBundle(c.Writer, bundle); err != nil {
+		// The status has gone out, so all that is left is to stop. The
+		// error is recorded for the export middleware, which may still be
+		// holding the file back.
+		log.Printf("[transfer] bundle export of child %s ended early: %v", childID, err)
+		c.Error(err) //nolint:errcheck // Returns err
+		c.Abort()
+	}
+}
+
+// writeBundle writes the bundle with the same fields, in the same order, as
+// encoding a Bundle would
+func writeBundle(w io.Writer, s *BundleStream) error {
+	b := s.Bundle
+	o := apijson.NewObjectWriter(w)
+	o.Field("version", b.Version)
+	o.Field("exported_at", b.ExportedAt)
+	o.Field("exported_by", b.ExportedBy)
+	o.Field("source_family_id", b.SourceFamilyID)
+	o.Field("child", b.Child)
+	apijson.StreamField(o, "feedings", s.Feedings)
+	apijson.StreamField(o, "sleep", s.Sleep)
+	apijson.StreamField(o, "medications", s.Medications)
+	apijson.StreamField(o, "vaccinations", s.Vaccinations)
+	apijson.StreamField(o, "appointments", s.Appointments)
+	apijson.StreamField(o, "notes", s.Notes)
+	apijson.StreamField(o, "journal", s.Journal)
+	return o.Close()
+}
+
+func (h *Handler) importChild(c *gin.Context) {
+	if source := c.Query("source"); source != "" && source != SourceBundle {
+		h.importApp(c, source)
+		return
+	}
+
+	var bundle Bundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	familyID := c.Param("familyId")
+
+	result, err := h.service.Import(c.Request.Context(), userID, familyID, &bundle)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// importApp takes the export file as the raw body. The child's details go in
+// the query, since other apps' exports don't carry them reliably.
+func (h *Handler) importApp(c *gin.Context, source string) {
+	name := c.Query("name")
+	dob, err := time.Parse("2006-01-02", c.Query("date_of_birth"))
+	if name == "" || err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name and date_of_birth (YYYY-MM-DD) are required"})
+		return
+	}
+	loc := time.UTC
+	if tz := c.Query("timezone"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timezone"})
+			return
+		}
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxAppExport))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "export file too large"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	familyID := c.Param("familyId")
+
+	result, err := h.service.ImportApp(c.Request.Context(), userID, familyID, &AppImportRequest{
+		Source:      source,
+		Name:        name,
+		DateOfBirth: dob,
+		Gender:      c.Query("gender"),
+		Location:    loc,
+		Data:        data,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *Handler) listImports(c *gin.Context) {
+	userID := c.GetString("user_id")
+	childID := c.Param("id")
+
+	imports, err := h.service.ListImports(c.Request.Context(), userID, childID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, imports)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUnsupportedVersion), errors.Is(err, ErrUnknownSource), errors.Is(err, ErrInvalidExport):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, occurrence.ErrInvalid), errors.Is(err, feeding.ErrInvalidAmount):
+		// A record the import carried in was rejected, and nothing was kept
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,329 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/journal"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	exportFn      func(ctx context.Context, userID, childID string) (*Bundle, error)
+	streamFn      func(ctx context.Context, userID, childID string) (*BundleStream, error)
+	importFn      func(ctx context.Context, userID, familyID string, bundle *Bundle) (*ImportResult, error)
+	importAppFn   func(ctx context.Context, userID, familyID string, req *AppImportRequest) (*ImportResult, error)
+	listImportsFn func(ctx context.Context, userID, childID string) ([]ChildImport, error)
+}
+
+func (m *mockService) Export(ctx context.Context, userID, childID string) (*Bundle, error) {
+	if m.exportFn != nil {
+		return m.exportFn(ctx, userID, childID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ExportShared(ctx context.Context, userID, childID string) (*Bundle, error) {
+	return nil, nil
+}
+
+func (m *mockService) Stream(ctx context.Context, userID, childID string) (*BundleStream, error) {
+	if m.streamFn != nil {
+		return m.streamFn(ctx, userID, childID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Import(ctx context.Context, userID, familyID string, bundle *Bundle) (*ImportResult, error) {
+	if m.importFn != nil {
+		return m.importFn(ctx, userID, familyID, bundle)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ImportApp(ctx context.Context, userID, familyID string, req *AppImportRequest) (*ImportResult, error) {
+	if m.importAppFn != nil {
+		return m.importAppFn(ctx, userID, familyID, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ListImports(ctx context.Context, userID, childID string) ([]ChildImport, error) {
+	if m.listImportsFn != nil {
+		return m.listImportsFn(ctx, userID, childID)
+	}
+	return nil, nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	router.Use(apispectest.Record("/api"))
+	handler := NewHandler(svc)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+
+	handler.RegisterChildRoutes(router.Group("/children"))
+	handler.RegisterFamilyRoutes(router.Group("/families"))
+	return router
+}
+
+func TestExportBundle_Success(t *testing.T) {
+	exportedAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	amount := 120.0
+	feedings := []feeding.Feeding{
+		{ID: "feed-1", ChildID: "child-1", Type: feeding.FeedingTypeBottle, StartTime: exportedAt.AddDate(-2, 0, 0), Amount: &amount, Unit: "ml"},
+		{ID: "feed-2", ChildID: "child-1", Type: feeding.FeedingTypeBreast, StartTime: exportedAt.Add(-time.Hour)},
+	}
+	head := &Bundle{
+		Version:        BundleVersion,
+		ExportedAt:     exportedAt,
+		ExportedBy:     "test-user-123",
+		SourceFamilyID: "family-1",
+		Child:          family.Child{ID: "child-1", Name: "Ava"},
+	}
+	note := notes.Note{ID: "note-1", Content: "First steps"}
+	svc := &mockService{
+		streamFn: func(ctx context.Context, userID, childID string) (*BundleStream, error) {
+			return &BundleStream{
+				Bundle:       head,
+				Feedings:     seq(feedings...),
+				Sleep:        seq[sleep.Sleep](),
+				Medications:  seq[BundleMedication](),
+				Vaccinations: seq[vaccination.Vaccination](),
+				Appointments: seq[appointment.Appointment](),
+				Notes:        seq(note),
+				Journal:      seq[journal.Entry](),
+			}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/children/child-1/bundle", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Disposition") == "" {
+		t.Error("Expected bundle to be served as an attachment")
+	}
+
+	// The stream must read back as the bundle encoding would have written it
+	full := *head
+	full.Feedings = feedings
+	full.Sleep = []sleep.Sleep{}
+	full.Medications = []BundleMedication{}
+	full.Vaccinations = []vaccination.Vaccination{}
+	full.Appointments = []appointment.Appointment{}
+	full.Notes = []notes.Note{note}
+	full.Journal = []journal.Entry{}
+	want, err := json.Marshal(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Body.String() != string(want) {
+		t.Errorf("Body = %s\nwant %s", w.Body.String(), want)
+	}
+}
+
+func TestExportBundle_NotMember(t *testing.T) {
+	svc := &mockService{
+		streamFn: func(ctx context.Context, userID, childID string) (*BundleStream, error) {
+			return nil, ErrNotMember
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/children/child-1/bundle", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestExportBundle_StreamError(t *testing.T) {
+	svc := &mockService{
+		streamFn: func(ctx context.Context, userID, childID string) (*BundleStream, error) {
+			return &BundleStream{
+				Bundle: &Bundle{Version: BundleVersion},
+				Feedings: func(yield func(feeding.Feeding, error) bool) {
+					yield(feeding.Feeding{}, errors.New("connection reset"))
+				},
+			}, nil
+		},
+	}
+
+	// Stands in for Encrypt, which drops the archive when the route failed
+	var recorded []error
+	router := gin.New()
+	handler := NewHandler(svc)
+	handler.RegisterChildRoutes(router.Group("/children"), func(c *gin.Context) {
+		c.Next()
+		for _, err := range c.Errors {
+			recorded = append(recorded, err.Err)
+		}
+	})
+
+	req := httptest.NewRequest("GET", "/children/child-1/bundle", http.NoBody)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(recorded) != 1 || recorded[0].Error() != "connection reset" {
+		t.Errorf("Expected the stream error to be recorded on the context, got %v", recorded)
+	}
+}
+
+func seq[T any](vs ...T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, v := range vs {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestImportBundle_Success(t *testing.T) {
+	var capturedFamily string
+	svc := &mockService{
+		importFn: func(ctx context.Context, userID, familyID string, bundle *Bundle) (*ImportResult, error) {
+			capturedFamily = familyID
+			return &ImportResult{Child: &family.Child{ID: "new-child"}, Import: &ChildImport{ID: "imp-1"}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"version":1,"child":{"id":"child-1","name":"Ava","date_of_birth":"2024-01-01T00:00:00Z"}}`
+	req := httptest.NewRequest("POST", "/families/family-2/child-imports", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", w.Code)
+	}
+	if capturedFamily != "family-2" {
+		t.Errorf("Expected family-2, got %s", capturedFamily)
+	}
+}
+
+func TestImportBundle_UnsupportedVersion(t *testing.T) {
+	svc := &mockService{
+		importFn: func(ctx context.Context, userID, familyID string, bundle *Bundle) (*ImportResult, error) {
+			return nil, ErrUnsupportedVersion
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/families/family-2/child-imports", bytes.NewBufferString(`{"version":2}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestImportApp_Success(t *testing.T) {
+	var captured *AppImportRequest
+	svc := &mockService{
+		importFn: func(ctx context.Context, userID, familyID string, bundle *Bundle) (*ImportResult, error) {
+			t.Error("an app export should not be read as a bundle")
+			return nil, nil
+		},
+		importAppFn: func(ctx context.Context, userID, familyID string, req *AppImportRequest) (*ImportResult, error) {
+			captured = req
+			return &ImportResult{Child: &family.Child{ID: "new-child"}, Import: &ChildImport{ID: "imp-1", Source: req.Source}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	url := "/families/family-2/child-imports?source=huckleberry&name=Ava&date_of_birth=2024-01-01&timezone=Europe/London"
+	req := httptest.NewRequest("POST", url, bytes.NewBufferString("Type,Start\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if captured.Source != SourceHuckleberry || captured.Name != "Ava" || captured.Location.String() != "Europe/London" {
+		t.Errorf("ImportApp() request = %+v", captured)
+	}
+	if string(captured.Data) != "Type,Start\n" {
+		t.Errorf("ImportApp() data = %q, want the raw body", captured.Data)
+	}
+}
+
+func TestImportApp_MissingChildDetails(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("POST", "/families/family-2/child-imports?source=glow_baby&name=Ava", bytes.NewBufferString("[]"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestImportApp_InvalidExport(t *testing.T) {
+	svc := &mockService{
+		importAppFn: func(ctx context.Context, userID, familyID string, req *AppImportRequest) (*ImportResult, error) {
+			return nil, ErrInvalidExport
+		},
+	}
+	router := setupRouter(svc)
+
+	url := "/families/family-2/child-imports?source=baby_tracker&name=Ava&date_of_birth=2024-01-01"
+	req := httptest.NewRequest("POST", url, bytes.NewBufferString("not a sheet"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestListImports_ChildNotFound(t *testing.T) {
+	svc := &mockService{
+		listImportsFn: func(ctx context.Context, userID, childID string) ([]ChildImport, error) {
+			return nil, ErrChildNotFound
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/children/missing/imports", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
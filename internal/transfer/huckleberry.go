@@ -0,0 +1,124 @@
+package transfer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/feeding"
+)
+
+// mapHuckleberry reads Huckleberry's CSV export, a single sheet with one row
+// per entry. Feeds say in Start Location whether they were bottle or breast;
+// a bottle's contents are in Start Condition and its amount in End Condition,
+// while a breast feed puts each side's time there, e.g. "00:12L".
+func mapHuckleberry(data []byte, loc *time.Location) (*appRecords, error) {
+	t, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	if !t.has("Type") || !t.has("Start") {
+		return nil, fmt.Errorf("%w: expected Type and Start columns", ErrInvalidExport)
+	}
+
+	recs := &appRecords{}
+	for i, row := range t.rows {
+		if blankRow(row) {
+			continue
+		}
+		kind := t.get(row, "Type")
+		ref := fmt.Sprintf("row-%d", i+2)
+
+		switch strings.ToLower(kind) {
+		case "sleep":
+			start, end, err := timeRange(t.get(row, "Start"), t.get(row, "End"), loc)
+			if err != nil {
+				return nil, rowError("", i, err)
+			}
+			recs.addSleep(ref, start, end, t.get(row, "Notes"))
+		case "feed":
+			f, err := huckleberryFeed(t, row, loc)
+			if err != nil {
+				return nil, rowError("", i, err)
+			}
+			recs.addFeeding(ref, f)
+		case "solids":
+			start, end, err := timeRange(t.get(row, "Start"), t.get(row, "End"), loc)
+			if err != nil {
+				return nil, rowError("", i, err)
+			}
+			recs.addFeeding(ref, &feeding.Feeding{
+				Type: feeding.FeedingTypeSolid, StartTime: start, EndTime: end,
+				Notes: joinNotes(t.get(row, "Start Condition"), t.get(row, "Notes")),
+			})
+		default:
+			recs.skip(kind)
+		}
+	}
+	return recs, nil
+}
+
+func huckleberryFeed(t *csvTable, row []string, loc *time.Location) (*feeding.Feeding, error) {
+	start, end, err := timeRange(t.get(row, "Start"), t.get(row, "End"), loc)
+	if err != nil {
+		return nil, err
+	}
+	f := &feeding.Feeding{StartTime: start, EndTime: end, Notes: t.get(row, "Notes")}
+
+	contents := t.get(row, "Start Condition")
+	if strings.EqualFold(t.get(row, "Start Location"), "bottle") {
+		f.Type = feeding.FeedingTypeBottle
+		if strings.Contains(strings.ToLower(contents), "formula") {
+			f.Type = feeding.FeedingTypeFormula
+		}
+		if f.Amount, f.Unit, err = parseAmount(t.get(row, "End Condition"), ""); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	f.Type = feeding.FeedingTypeBreast
+	var side [2]time.Duration // left, right
+	for _, cond := range []string{contents, t.get(row, "End Condition")} {
+		d, s := splitSideTime(cond)
+		switch s {
+		case "L":
+			side[0] += d
+		case "R":
+			side[1] += d
+		}
+	}
+	f.Side = breastSide(side[0], side[1])
+	return f, nil
+}
+
+// splitSideTime reads a side's feeding time such as "00:12L"
+func splitSideTime(s string) (time.Duration, string) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0, ""
+	}
+	side := s[len(s)-1:]
+	if side != "L" && side != "R" {
+		return 0, ""
+	}
+	d, err := parseMinutes(s[:len(s)-1])
+	if err != nil {
+		return 0, ""
+	}
+	// A side with no time still counts as used
+	if d == 0 {
+		d = time.Second
+	}
+	return d, side
+}
+
+func joinNotes(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, " - ")
+}
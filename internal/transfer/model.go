@@ -0,0 +1,98 @@
+package transfer
+
+import (
+	"iter"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/journal"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+)
+
+// BundleVersion is bumped whenever the bundle layout changes incompatibly
+const BundleVersion = 1
+
+// Bundle is a portable snapshot of everything recorded about one child
+type Bundle struct {
+	Version        int                       `json:"version"`
+	ExportedAt     time.Time                 `json:"exported_at"`
+	ExportedBy     string                    `json:"exported_by"`
+	SourceFamilyID string                    `json:"source_family_id"`
+	Child          family.Child              `json:"child"`
+	Feedings       []feeding.Feeding         `json:"feedings"`
+	Sleep          []sleep.Sleep             `json:"sleep"`
+	Medications    []BundleMedication        `json:"medications"`
+	Vaccinations   []vaccination.Vaccination `json:"vaccinations"`
+	Appointments   []appointment.Appointment `json:"appointments"`
+	Notes          []notes.Note              `json:"notes"`
+	// Journal carries captions and dates; the photos stay with the source family
+	Journal []journal.Entry `json:"journal"`
+}
+
+// BundleStream is a bundle for download whose records are read from the
+// database as they are written, in the order of Bundle's fields. Bundle
+// holds the header and the child; its record lists are left empty.
+type BundleStream struct {
+	Bundle       *Bundle
+	Feedings     iter.Seq2[feeding.Feeding, error]
+	Sleep        iter.Seq2[sleep.Sleep, error]
+	Medications  iter.Seq2[BundleMedication, error]
+	Vaccinations iter.Seq2[vaccination.Vaccination, error]
+	Appointments iter.Seq2[appointment.Appointment, error]
+	Notes        iter.Seq2[notes.Note, error]
+	Journal      iter.Seq2[journal.Entry, error]
+}
+
+// BundleMedication carries a medication together with its dose history
+type BundleMedication struct {
+	medication.Medication
+	Logs []medication.MedicationLog `json:"logs"`
+}
+
+type EntityType string
+
+const (
+	EntityFeeding       EntityType = "feeding"
+	EntitySleep         EntityType = "sleep"
+	EntityMedication    EntityType = "medication"
+	EntityMedicationLog EntityType = "medication_log"
+	EntityVaccination   EntityType = "vaccination"
+	EntityAppointment   EntityType = "appointment"
+	EntityNote          EntityType = "note"
+	EntityJournalEntry  EntityType = "journal_entry"
+)
+
+// ChildImport is the provenance marker left on a child created from a bundle.
+// Source is SourceBundle, or the app whose export the bundle was mapped from.
+type ChildImport struct {
+	ID             string           `json:"id"`
+	ChildID        string           `json:"child_id"`
+	Source         string           `json:"source"`
+	SourceChildID  string           `json:"source_child_id"`
+	SourceFamilyID string           `json:"source_family_id"`
+	ExportedAt     time.Time        `json:"exported_at"`
+	ImportedBy     string           `json:"imported_by,omitempty"`
+	ImportedAt     time.Time        `json:"imported_at"`
+	Records        []ImportedRecord `json:"records,omitempty"`
+}
+
+// ImportedRecord maps a record created on import back to its original
+type ImportedRecord struct {
+	EntityType      EntityType `json:"entity_type"`
+	EntityID        string     `json:"entity_id"`
+	SourceID        string     `json:"source_id"`
+	SourceCreatedAt *time.Time `json:"source_created_at,omitempty"`
+}
+
+type ImportResult struct {
+	Child  *family.Child `json:"child"`
+	Import *ChildImport  `json:"import"`
+	// Skipped counts rows of another app's export that were not imported,
+	// by their kind in that app
+	Skipped map[string]int `json:"skipped,omitempty"`
+}
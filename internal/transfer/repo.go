@@ -0,0 +1,91 @@
+package transfer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	CreateImport(ctx context.Context, imp *ChildImport) error
+	ListImports(ctx context.Context, childID string) ([]ChildImport, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the import tables
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "child_imports", "imported_records")
+}
+
+// CreateImport stores the import and its record mappings in one transaction
+func (r *repository) CreateImport(ctx context.Context, imp *ChildImport) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit
+
+	query := `
+		INSERT INTO child_imports (id, child_id, source, source_child_id, source_family_id, exported_at, imported_by, imported_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		imp.ID, imp.ChildID, imp.Source, imp.SourceChildID, imp.SourceFamilyID, imp.ExportedAt, imp.ImportedBy, imp.ImportedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	recordQuery := `
+		INSERT INTO imported_records (import_id, entity_type, entity_id, source_id, source_created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, rec := range imp.Records {
+		if _, err := tx.ExecContext(ctx, recordQuery, imp.ID, rec.EntityType, rec.EntityID, rec.SourceID, rec.SourceCreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) ListImports(ctx context.Context, childID string) ([]ChildImport, error) {
+	query := `
+		SELECT id, child_id, source, source_child_id, source_family_id, exported_at, imported_by, imported_at
+		FROM child_imports
+		WHERE child_id = $1
+		ORDER BY imported_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, childID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	imports := []ChildImport{}
+	for rows.Next() {
+		var imp ChildImport
+		var importedBy sql.NullString
+
+		if err := rows.Scan(
+			&imp.ID, &imp.ChildID, &imp.Source, &imp.SourceChildID, &imp.SourceFamilyID, &imp.ExportedAt, &importedBy, &imp.ImportedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		imp.ImportedBy = importedBy.String
+		imports = append(imports, imp)
+	}
+
+	return imports, rows.Err()
+}
@@ -0,0 +1,91 @@
+package transfer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_CreateImport(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	imp := &ChildImport{
+		ID: "imp-1", ChildID: "child-2", Source: SourceBundle, SourceChildID: "child-1", SourceFamilyID: "family-1",
+		ExportedAt: now, ImportedBy: "user-1", ImportedAt: now,
+		Records: []ImportedRecord{{EntityType: EntityNote, EntityID: "note-2", SourceID: "note-1", SourceCreatedAt: &now}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO child_imports").
+		WithArgs("imp-1", "child-2", SourceBundle, "child-1", "family-1", now, "user-1", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO imported_records").
+		WithArgs("imp-1", EntityNote, "note-2", "note-1", &now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.CreateImport(context.Background(), imp); err != nil {
+		t.Fatalf("CreateImport() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_CreateImport_RollsBack(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO child_imports").
+		WillReturnError(errors.New("database error"))
+	mock.ExpectRollback()
+
+	if err := repo.CreateImport(context.Background(), &ChildImport{ID: "imp-1"}); err == nil {
+		t.Error("CreateImport() should return error")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListImports(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "child_id", "source", "source_child_id", "source_family_id", "exported_at", "imported_by", "imported_at"}).
+		AddRow("imp-1", "child-2", SourceHuckleberry, "", "", now, nil, now)
+
+	mock.ExpectQuery("SELECT id, child_id, source, source_child_id").
+		WithArgs("child-2").
+		WillReturnRows(rows)
+
+	imports, err := repo.ListImports(context.Background(), "child-2")
+	if err != nil {
+		t.Fatalf("ListImports() error = %v", err)
+	}
+	if len(imports) != 1 || imports[0].Source != SourceHuckleberry || imports[0].ImportedBy != "" {
+		t.Errorf("ListImports() = %+v", imports)
+	}
+}
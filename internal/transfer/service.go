@@ -0,0 +1,502 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/journal"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+var (
+	ErrChildNotFound      = errors.New("child not found")
+	ErrNotMember          = errors.New("user is not a member of this family")
+	ErrUnsupportedVersion = errors.New("unsupported bundle version")
+)
+
+type Service interface {
+	Export(ctx context.Context, userID, childID string) (*Bundle, error)
+	// ExportShared is Export read as a member who logged none of the records,
+	// so every private record is left out, the exporter's own included. It is
+	// for sharing a child's records outside the family.
+	ExportShared(ctx context.Context, userID, childID string) (*Bundle, error)
+	Stream(ctx context.Context, userID, childID string) (*BundleStream, error)
+	Import(ctx context.Context, userID, familyID string, bundle *Bundle) (*ImportResult, error)
+	ImportApp(ctx context.Context, userID, familyID string, req *AppImportRequest) (*ImportResult, error)
+	ListImports(ctx context.Context, userID, childID string) ([]ChildImport, error)
+}
+
+type service struct {
+	repo               Repository
+	familyService      family.Service
+	feedingService     feeding.Service
+	sleepService       sleep.Service
+	medicationService  medication.Service
+	vaccinationService vaccination.Service
+	appointmentService appointment.Service
+	notesService       notes.Service
+	journalService     journal.Service
+	visibility         visibility.Service
+}
+
+func NewService(
+	repo Repository,
+	familyService family.Service,
+	feedingService feeding.Service,
+	sleepService sleep.Service,
+	medicationService medication.Service,
+	vaccinationService vaccination.Service,
+	appointmentService appointment.Service,
+	notesService notes.Service,
+	journalService journal.Service,
+	opts ...Option,
+) Service {
+	s := &service{
+		repo:               repo,
+		familyService:      familyService,
+		feedingService:     feedingService,
+		sleepService:       sleepService,
+		medicationService:  medicationService,
+		vaccinationService: vaccinationService,
+		appointmentService: appointmentService,
+		notesService:       notesService,
+		journalService:     journalService,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether imports can be tracked
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+// Export collects the child's complete history into a portable bundle
+func (s *service) Export(ctx context.Context, userID, childID string) (*Bundle, error) {
+	st, err := s.Stream(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	b := st.Bundle
+	if b.Feedings, err = collect("feedings", st.Feedings); err != nil {
+		return nil, err
+	}
+	if b.Sleep, err = collect("sleep", st.Sleep); err != nil {
+		return nil, err
+	}
+	if b.Medications, err = collect("medications", st.Medications); err != nil {
+		return nil, err
+	}
+	if b.Vaccinations, err = collect("vaccinations", st.Vaccinations); err != nil {
+		return nil, err
+	}
+	if b.Appointments, err = collect("appointments", st.Appointments); err != nil {
+		return nil, err
+	}
+	if b.Notes, err = collect("notes", st.Notes); err != nil {
+		return nil, err
+	}
+	if b.Journal, err = collect("journal", st.Journal); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *service) ExportShared(ctx context.Context, userID, childID string) (*Bundle, error) {
+	b, err := s.Export(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Feedings = slices.DeleteFunc(b.Feedings, func(f feeding.Feeding) bool { return f.Private })
+	b.Sleep = slices.DeleteFunc(b.Sleep, func(sl sleep.Sleep) bool { return sl.Private })
+	for i := range b.Medications {
+		b.Medications[i].Logs = slices.DeleteFunc(b.Medications[i].Logs, func(l medication.MedicationLog) bool { return l.Private })
+	}
+	b.Notes = slices.DeleteFunc(b.Notes, func(n notes.Note) bool { return n.Private })
+	return b, nil
+}
+
+func collect[T any](section string, seq iter.Seq2[T, error]) ([]T, error) {
+	out := []T{}
+	for v, err := range seq {
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", section, err)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// Stream checks access and describes the bundle; its records are read only
+// as the bundle is written out
+func (s *service) Stream(ctx context.Context, userID, childID string) (*BundleStream, error) {
+	child, err := s.authorizeChild(ctx, userID, childID)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := s.hiddenTypes(ctx, userID, child.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BundleStream{
+		Bundle: &Bundle{
+			Version:        BundleVersion,
+			ExportedAt:     time.Now(),
+			ExportedBy:     userID,
+			SourceFamilyID: child.FamilyID,
+			Child:          *child,
+		},
+		Feedings:     unlessHidden(hidden, visibility.RecordFeeding, s.feedingService.Stream(ctx, &feeding.FeedingFilter{ChildID: childID, ViewerID: userID})),
+		Sleep:        unlessHidden(hidden, visibility.RecordSleep, s.sleepService.Stream(ctx, &sleep.SleepFilter{ChildID: childID, ViewerID: userID})),
+		Medications:  unlessHidden(hidden, visibility.RecordMedication, s.streamMedications(ctx, userID, childID)),
+		Vaccinations: unlessHidden(hidden, visibility.RecordVaccination, s.vaccinationService.Stream(ctx, &vaccination.VaccinationFilter{ChildID: childID})),
+		Appointments: unlessHidden(hidden, visibility.RecordAppointment, s.appointmentService.Stream(ctx, &appointment.AppointmentFilter{ChildID: childID})),
+		// Private notes go only into their author's own export
+		Notes:   unlessHidden(hidden, visibility.RecordNotes, s.notesService.Stream(ctx, &notes.NoteFilter{ChildID: childID, ViewerID: userID})),
+		Journal: unlessHidden(hidden, visibility.RecordJournal, s.journalService.Stream(ctx, childID)),
+	}, nil
+}
+
+// streamMedications yields each medication with the dose history userID may
+// see, which is looked up when the medication is reached
+func (s *service) streamMedications(ctx context.Context, userID, childID string) iter.Seq2[BundleMedication, error] {
+	return func(yield func(BundleMedication, error) bool) {
+		for med, err := range s.medicationService.Stream(ctx, &medication.MedicationFilter{ChildID: childID}) {
+			if err != nil {
+				yield(BundleMedication{}, err)
+				return
+			}
+			logs, err := s.medicationService.GetLogs(ctx, med.ID, userID)
+			if err != nil {
+				yield(BundleMedication{}, fmt.Errorf("failed to export medication logs: %w", err))
+				return
+			}
+			if !yield(BundleMedication{Medication: med, Logs: logs}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Import recreates a bundled child in the target family. Every created record
+// is mapped back to its source ID so the history's origin stays traceable.
+// If any record fails the new child is deleted, cascading to what was created.
+func (s *service) Import(ctx context.Context, userID, familyID string, b *Bundle) (*ImportResult, error) {
+	if b.Version != BundleVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	if err := s.requireMember(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+	return s.importBundle(ctx, userID, familyID, SourceBundle, b)
+}
+
+// ImportApp maps another app's export onto a bundle and imports that as a new
+// child. Entries of kinds with no equivalent here are counted, not imported.
+func (s *service) ImportApp(ctx context.Context, userID, familyID string, req *AppImportRequest) (*ImportResult, error) {
+	mapper, ok := appMappers[req.Source]
+	if !ok {
+		return nil, ErrUnknownSource
+	}
+	if err := s.requireMember(ctx, familyID, userID); err != nil {
+		return nil, err
+	}
+
+	loc := req.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	recs, err := mapper(req.Data, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Other apps' exports don't record when they were made
+	b := &Bundle{
+		Version:    BundleVersion,
+		ExportedAt: time.Now(),
+		Child:      family.Child{Name: req.Name, DateOfBirth: req.DateOfBirth, Gender: req.Gender},
+		Feedings:   recs.feedings,
+		Sleep:      recs.sleep,
+	}
+	result, err := s.importBundle(ctx, userID, familyID, req.Source, b)
+	if err != nil {
+		return nil, err
+	}
+	result.Skipped = recs.skipped
+	return result, nil
+}
+
+func (s *service) importBundle(ctx context.Context, userID, familyID, source string, b *Bundle) (*ImportResult, error) {
+	child, err := s.familyService.AddChild(ctx, familyID, &family.AddChildRequest{
+		Name:        b.Child.Name,
+		DateOfBirth: b.Child.DateOfBirth,
+		Gender:      b.Child.Gender,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create child: %w", err)
+	}
+
+	imp := &ChildImport{
+		ID:             ids.New(),
+		ChildID:        child.ID,
+		Source:         source,
+		SourceChildID:  b.Child.ID,
+		SourceFamilyID: b.SourceFamilyID,
+		ExportedAt:     b.ExportedAt,
+		ImportedBy:     userID,
+		ImportedAt:     time.Now(),
+	}
+
+	imp.Records, err = s.importRecords(ctx, userID, child.ID, b)
+	if err == nil {
+		err = s.repo.CreateImport(ctx, imp)
+	}
+	if err != nil {
+		if delErr := s.familyService.DeleteChild(ctx, child.ID); delErr != nil {
+			log.Printf("[transfer] failed to clean up partial import of child %s: %v", child.ID, delErr)
+		}
+		return nil, fmt.Errorf("failed to import bundle: %w", err)
+	}
+
+	return &ImportResult{Child: child, Import: imp}, nil
+}
+
+func (s *service) ListImports(ctx context.Context, userID, childID string) ([]ChildImport, error) {
+	if _, err := s.authorizeChild(ctx, userID, childID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListImports(ctx, childID)
+}
+
+func (s *service) importRecords(ctx context.Context, userID, childID string, b *Bundle) ([]ImportedRecord, error) {
+	var records []ImportedRecord
+	track := func(entityType EntityType, entityID, sourceID string, sourceCreatedAt time.Time) {
+		at := sourceCreatedAt
+		records = append(records, ImportedRecord{
+			EntityType:      entityType,
+			EntityID:        entityID,
+			SourceID:        sourceID,
+			SourceCreatedAt: &at,
+		})
+	}
+
+	for i := range b.Feedings {
+		f := &b.Feedings[i]
+		created, err := s.feedingService.Create(ctx, &feeding.CreateFeedingRequest{
+			ChildID: childID, Type: f.Type, StartTime: f.StartTime, EndTime: f.EndTime,
+			Amount: f.Amount, Unit: f.Unit, Side: f.Side, Notes: f.Notes,
+			Private: f.Private, AuthorID: userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		track(EntityFeeding, created.ID, f.ID, f.CreatedAt)
+	}
+
+	for i := range b.Sleep {
+		sl := &b.Sleep[i]
+		created, err := s.sleepService.Create(ctx, &sleep.CreateSleepRequest{
+			ChildID: childID, Type: sl.Type, StartTime: sl.StartTime, EndTime: sl.EndTime,
+			Quality: sl.Quality, Notes: sl.Notes, Source: sl.Source,
+			Private: sl.Private, StartedBy: userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		track(EntitySleep, created.ID, sl.ID, sl.CreatedAt)
+	}
+
+	if err := s.importMedications(ctx, userID, childID, b.Medications, track); err != nil {
+		return nil, err
+	}
+	if err := s.importVaccinations(ctx, userID, childID, b.Vaccinations, track); err != nil {
+		return nil, err
+	}
+	if err := s.importAppointments(ctx, userID, childID, b.Appointments, track); err != nil {
+		return nil, err
+	}
+
+	for i := range b.Notes {
+		n := &b.Notes[i]
+		// Bundles exported before notes had an occurrence time only carry created_at
+		occurredAt := n.OccurredAt
+		if occurredAt.IsZero() {
+			occurredAt = n.CreatedAt
+		}
+		created, err := s.notesService.Create(ctx, userID, &notes.CreateNoteRequest{
+			ChildID: childID, Title: n.Title, Content: n.Content, Tags: n.Tags, Pinned: n.Pinned,
+			Private: n.Private, OccurredAt: &occurredAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		track(EntityNote, created.ID, n.ID, n.CreatedAt)
+	}
+
+	if err := s.importJournal(ctx, userID, childID, b.Journal, track); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// importJournal recreates journal entries without their photos, which belong
+// to the source family's media. Photo-only entries have nothing left to carry.
+func (s *service) importJournal(ctx context.Context, userID, childID string, entries []journal.Entry, track trackFunc) error {
+	for i := range entries {
+		e := &entries[i]
+		if e.Caption == "" {
+			continue
+		}
+		created, err := s.journalService.Create(ctx, userID, &journal.CreateEntryRequest{
+			ChildID: childID, Caption: e.Caption, Date: e.Date, Milestone: e.Milestone,
+		})
+		if err != nil {
+			return err
+		}
+		track(EntityJournalEntry, created.ID, e.ID, e.CreatedAt)
+	}
+	return nil
+}
+
+type trackFunc func(entityType EntityType, entityID, sourceID string, sourceCreatedAt time.Time)
+
+func (s *service) importMedications(ctx context.Context, userID, childID string, meds []BundleMedication, track trackFunc) error {
+	for i := range meds {
+		m := &meds[i]
+		created, err := s.medicationService.Create(ctx, &medication.CreateMedicationRequest{
+			ChildID: childID, Name: m.Name, Dosage: m.Dosage, Unit: m.Unit, Frequency: m.Frequency,
+			Instructions: m.Instructions, StartDate: m.StartDate, EndDate: m.EndDate,
+		})
+		if err != nil {
+			return err
+		}
+		track(EntityMedication, created.ID, m.ID, m.CreatedAt)
+
+		for j := range m.Logs {
+			l := &m.Logs[j]
+			logged, err := s.medicationService.LogMedication(ctx, userID, &medication.LogMedicationRequest{
+				MedicationID: created.ID, GivenAt: l.GivenAt, Dosage: l.Dosage, Notes: l.Notes,
+				Private: l.Private,
+			})
+			if err != nil {
+				return err
+			}
+			track(EntityMedicationLog, logged.ID, l.ID, l.CreatedAt)
+		}
+
+		if !m.Active {
+			if err := s.medicationService.Deactivate(ctx, userID, created.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *service) importVaccinations(ctx context.Context, userID, childID string, vaxes []vaccination.Vaccination, track trackFunc) error {
+	for i := range vaxes {
+		v := &vaxes[i]
+		created, err := s.vaccinationService.Create(ctx, &vaccination.CreateVaccinationRequest{
+			ChildID: childID, Name: v.Name, Dose: v.Dose, ScheduledAt: v.ScheduledAt,
+		})
+		if err != nil {
+			return err
+		}
+		track(EntityVaccination, created.ID, v.ID, v.CreatedAt)
+
+		switch {
+		case v.Completed && v.AdministeredAt != nil:
+			_, err := s.vaccinationService.RecordAdministration(ctx, userID, created.ID, &vaccination.RecordVaccinationRequest{
+				AdministeredAt: *v.AdministeredAt, Provider: v.Provider, Location: v.Location,
+				LotNumber: v.LotNumber, Notes: v.Notes,
+			})
+			if err != nil {
+				return err
+			}
+		case v.Status == vaccination.StatusSkipped, v.Status == vaccination.StatusRefused, v.Status == vaccination.StatusContraindicated:
+			// Exemption documents are media of the source family and stay behind
+			_, err := s.vaccinationService.SetStatus(ctx, userID, created.ID, &vaccination.SetStatusRequest{
+				Status: v.Status, Reason: v.StatusReason, ReasonCode: v.ReasonCode,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *service) importAppointments(ctx context.Context, userID, childID string, apts []appointment.Appointment, track trackFunc) error {
+	for i := range apts {
+		a := &apts[i]
+		created, err := s.appointmentService.Create(ctx, &appointment.CreateAppointmentRequest{
+			ChildID: childID, Type: a.Type, Title: a.Title, Provider: a.Provider, Location: a.Location,
+			ScheduledAt: a.ScheduledAt, Duration: a.Duration, Notes: a.Notes, Reason: a.Reason,
+		})
+		if err != nil {
+			return err
+		}
+		track(EntityAppointment, created.ID, a.ID, a.CreatedAt)
+
+		// Recording the outcome completes the appointment as well. Links to
+		// vaccinations and measurements are not part of the bundle.
+		switch {
+		case a.Cancelled:
+			err = s.appointmentService.Cancel(ctx, userID, created.ID)
+		case a.Outcome != "":
+			_, err = s.appointmentService.RecordOutcome(ctx, userID, created.ID, &appointment.RecordOutcomeRequest{Outcome: a.Outcome})
+		case a.Completed:
+			err = s.appointmentService.Complete(ctx, userID, created.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizeChild loads the child and checks the user belongs to its family
+func (s *service) authorizeChild(ctx context.Context, userID, childID string) (*family.Child, error) {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if child == nil {
+		return nil, ErrChildNotFound
+	}
+	if err := s.requireMember(ctx, child.FamilyID, userID); err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+func (s *service) requireMember(ctx context.Context, familyID, userID string) error {
+	if _, err := s.familyService.GetMemberRole(ctx, familyID, userID); err != nil {
+		if errors.Is(err, family.ErrNotMember) {
+			return ErrNotMember
+		}
+		return err
+	}
+	return nil
+}
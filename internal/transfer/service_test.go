@@ -0,0 +1,517 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/appointment"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/feeding"
+	"github.com/ninenine/babytrack/internal/journal"
+	"github.com/ninenine/babytrack/internal/medication"
+	"github.com/ninenine/babytrack/internal/notes"
+	"github.com/ninenine/babytrack/internal/sleep"
+	"github.com/ninenine/babytrack/internal/testutil/factory"
+	"github.com/ninenine/babytrack/internal/vaccination"
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type mockRepository struct {
+	imports   []ChildImport
+	createErr error
+}
+
+func (m *mockRepository) CreateImport(ctx context.Context, imp *ChildImport) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.imports = append(m.imports, *imp)
+	return nil
+}
+
+func (m *mockRepository) ListImports(ctx context.Context, childID string) ([]ChildImport, error) {
+	return m.imports, nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	children map[string]*family.Child
+	members  map[string]bool // familyID/userID
+	deleted  []string
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if m.members[familyID+"/"+userID] {
+		return "member", nil
+	}
+	return "", family.ErrNotMember
+}
+
+func (m *mockFamilyService) AddChild(ctx context.Context, familyID string, req *family.AddChildRequest) (*family.Child, error) {
+	return &family.Child{ID: "new-child", FamilyID: familyID, Name: req.Name, DateOfBirth: req.DateOfBirth}, nil
+}
+
+func (m *mockFamilyService) DeleteChild(ctx context.Context, childID string) error {
+	m.deleted = append(m.deleted, childID)
+	return nil
+}
+
+type mockFeedingService struct {
+	feeding.Service
+	feedings []feeding.Feeding
+	created  []feeding.CreateFeedingRequest
+	viewerID string
+}
+
+func (m *mockFeedingService) List(ctx context.Context, filter *feeding.FeedingFilter) ([]feeding.Feeding, error) {
+	return m.feedings, nil
+}
+
+func (m *mockFeedingService) Stream(ctx context.Context, filter *feeding.FeedingFilter) iter.Seq2[feeding.Feeding, error] {
+	m.viewerID = filter.ViewerID
+	return func(yield func(feeding.Feeding, error) bool) {
+		for _, f := range m.feedings {
+			if !yield(f, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (m *mockFeedingService) Create(ctx context.Context, req *feeding.CreateFeedingRequest) (*feeding.Feeding, error) {
+	m.created = append(m.created, *req)
+	return &feeding.Feeding{ID: "new-feeding", ChildID: req.ChildID}, nil
+}
+
+type mockSleepService struct {
+	sleep.Service
+	sleeps    []sleep.Sleep
+	createErr error
+	viewerID  string
+}
+
+func (m *mockSleepService) List(ctx context.Context, filter *sleep.SleepFilter) ([]sleep.Sleep, error) {
+	return nil, nil
+}
+
+func (m *mockSleepService) Stream(ctx context.Context, filter *sleep.SleepFilter) iter.Seq2[sleep.Sleep, error] {
+	m.viewerID = filter.ViewerID
+	return seq(m.sleeps...)
+}
+
+func (m *mockSleepService) Create(ctx context.Context, req *sleep.CreateSleepRequest) (*sleep.Sleep, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	return &sleep.Sleep{ID: "new-sleep", ChildID: req.ChildID}, nil
+}
+
+type mockMedicationService struct {
+	medication.Service
+	meds        []medication.Medication
+	logs        []medication.MedicationLog
+	deactivated []string
+	viewerID    string
+}
+
+func (m *mockMedicationService) Stream(ctx context.Context, filter *medication.MedicationFilter) iter.Seq2[medication.Medication, error] {
+	return seq(m.meds...)
+}
+
+func (m *mockMedicationService) GetLogs(ctx context.Context, medicationID, viewerID string) ([]medication.MedicationLog, error) {
+	m.viewerID = viewerID
+	return m.logs, nil
+}
+
+func (m *mockMedicationService) Create(ctx context.Context, req *medication.CreateMedicationRequest) (*medication.Medication, error) {
+	return &medication.Medication{ID: "new-med", ChildID: req.ChildID, Active: true}, nil
+}
+
+func (m *mockMedicationService) LogMedication(ctx context.Context, userID string, req *medication.LogMedicationRequest) (*medication.MedicationLog, error) {
+	return &medication.MedicationLog{ID: "new-log", MedicationID: req.MedicationID}, nil
+}
+
+func (m *mockMedicationService) Deactivate(ctx context.Context, userID, id string) error {
+	m.deactivated = append(m.deactivated, id)
+	return nil
+}
+
+type mockVaccinationService struct {
+	vaccination.Service
+	recorded []string
+	statuses []vaccination.Status
+}
+
+func (m *mockVaccinationService) Stream(ctx context.Context, filter *vaccination.VaccinationFilter) iter.Seq2[vaccination.Vaccination, error] {
+	return seq[vaccination.Vaccination]()
+}
+
+func (m *mockVaccinationService) Create(ctx context.Context, req *vaccination.CreateVaccinationRequest) (*vaccination.Vaccination, error) {
+	return &vaccination.Vaccination{ID: "new-vax", ChildID: req.ChildID}, nil
+}
+
+func (m *mockVaccinationService) RecordAdministration(ctx context.Context, userID, id string, req *vaccination.RecordVaccinationRequest) (*vaccination.Vaccination, error) {
+	m.recorded = append(m.recorded, id)
+	return &vaccination.Vaccination{ID: id, Status: vaccination.StatusCompleted, Completed: true}, nil
+}
+
+func (m *mockVaccinationService) SetStatus(ctx context.Context, userID, id string, req *vaccination.SetStatusRequest) (*vaccination.Vaccination, error) {
+	m.statuses = append(m.statuses, req.Status)
+	return &vaccination.Vaccination{ID: id, Status: req.Status, StatusReason: req.Reason}, nil
+}
+
+type mockAppointmentService struct {
+	appointment.Service
+	cancelled []string
+}
+
+func (m *mockAppointmentService) Stream(ctx context.Context, filter *appointment.AppointmentFilter) iter.Seq2[appointment.Appointment, error] {
+	return seq[appointment.Appointment]()
+}
+
+func (m *mockAppointmentService) Create(ctx context.Context, req *appointment.CreateAppointmentRequest) (*appointment.Appointment, error) {
+	return &appointment.Appointment{ID: "new-apt", ChildID: req.ChildID}, nil
+}
+
+func (m *mockAppointmentService) Cancel(ctx context.Context, userID, id string) error {
+	m.cancelled = append(m.cancelled, id)
+	return nil
+}
+
+type mockNotesService struct {
+	notes.Service
+	private  []notes.Note
+	viewerID string
+}
+
+func (m *mockNotesService) Stream(ctx context.Context, filter *notes.NoteFilter) iter.Seq2[notes.Note, error] {
+	m.viewerID = filter.ViewerID
+	return seq(append([]notes.Note{{ID: "note-1", ChildID: filter.ChildID, Content: "Allergic to peanuts"}}, m.private...)...)
+}
+
+func (m *mockNotesService) Create(ctx context.Context, userID string, req *notes.CreateNoteRequest) (*notes.Note, error) {
+	return &notes.Note{ID: "new-note", ChildID: req.ChildID, AuthorID: userID}, nil
+}
+
+type mockJournalService struct {
+	journal.Service
+	created []journal.CreateEntryRequest
+}
+
+func (m *mockJournalService) Stream(ctx context.Context, childID string) iter.Seq2[journal.Entry, error] {
+	return seq(journal.Entry{ID: "entry-1", ChildID: childID, MediaID: "media-1", Caption: "First steps", Date: "2024-03-10"})
+}
+
+func (m *mockJournalService) Create(ctx context.Context, userID string, req *journal.CreateEntryRequest) (*journal.Entry, error) {
+	m.created = append(m.created, *req)
+	return &journal.Entry{ID: "new-entry", ChildID: req.ChildID}, nil
+}
+
+type testServices struct {
+	repo        *mockRepository
+	family      *mockFamilyService
+	feeding     *mockFeedingService
+	sleep       *mockSleepService
+	medication  *mockMedicationService
+	vaccination *mockVaccinationService
+	appointment *mockAppointmentService
+	notes       *mockNotesService
+	journal     *mockJournalService
+}
+
+func newTestService() (Service, *testServices) {
+	ava := factory.Child(func(c *family.Child) { c.Name = "Ava" })
+	ts := &testServices{
+		repo: &mockRepository{},
+		family: &mockFamilyService{
+			children: map[string]*family.Child{factory.ChildID: &ava},
+			members:  map[string]bool{"family-1/user-1": true, "family-2/user-2": true},
+		},
+		feeding:     &mockFeedingService{},
+		sleep:       &mockSleepService{},
+		medication:  &mockMedicationService{},
+		vaccination: &mockVaccinationService{},
+		appointment: &mockAppointmentService{},
+		notes:       &mockNotesService{},
+		journal:     &mockJournalService{},
+	}
+	svc := NewService(ts.repo, ts.family, ts.feeding, ts.sleep, ts.medication, ts.vaccination, ts.appointment,
+		ts.notes, ts.journal)
+	return svc, ts
+}
+
+func TestService_Export(t *testing.T) {
+	svc, ts := newTestService()
+	ts.feeding.feedings = []feeding.Feeding{{ID: "feed-1", ChildID: "child-1"}}
+	ts.medication.meds = []medication.Medication{factory.Medication()}
+	ts.medication.logs = []medication.MedicationLog{{ID: "log-1", MedicationID: "med-1"}}
+
+	b, err := svc.Export(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if b.Version != BundleVersion || b.SourceFamilyID != "family-1" || b.Child.Name != "Ava" {
+		t.Errorf("Export() header = %+v", b)
+	}
+	if len(b.Feedings) != 1 || len(b.Notes) != 1 || len(b.Journal) != 1 {
+		t.Errorf("Export() feedings=%d notes=%d journal=%d, want 1 each", len(b.Feedings), len(b.Notes), len(b.Journal))
+	}
+	if len(b.Medications) != 1 || len(b.Medications[0].Logs) != 1 {
+		t.Errorf("Export() should include medication logs, got %+v", b.Medications)
+	}
+	if ts.notes.viewerID != "user-1" {
+		t.Errorf("Export() should only include the exporter's own private notes, listed as %q", ts.notes.viewerID)
+	}
+	if ts.feeding.viewerID != "user-1" || ts.sleep.viewerID != "user-1" || ts.medication.viewerID != "user-1" {
+		t.Errorf("Export() should only include the exporter's own private logs, listed as feedings=%q sleep=%q doses=%q",
+			ts.feeding.viewerID, ts.sleep.viewerID, ts.medication.viewerID)
+	}
+}
+
+func TestService_ExportShared(t *testing.T) {
+	svc, ts := newTestService()
+	// The exporter logged the private records, so Export would include them
+	ts.feeding.feedings = []feeding.Feeding{
+		{ID: "feed-1", ChildID: "child-1"},
+		{ID: "feed-private", ChildID: "child-1", AuthorID: "user-1", Private: true},
+	}
+	ts.sleep.sleeps = []sleep.Sleep{factory.Sleep(func(s *sleep.Sleep) { s.ID, s.Private = "sleep-private", true })}
+	ts.medication.meds = []medication.Medication{factory.Medication()}
+	ts.medication.logs = []medication.MedicationLog{
+		{ID: "log-1", MedicationID: "med-1"},
+		{ID: "log-private", MedicationID: "med-1", GivenBy: "user-1", Private: true},
+	}
+	ts.notes.private = []notes.Note{factory.Note(func(n *notes.Note) { n.ID, n.Private = "note-private", true })}
+
+	b, err := svc.ExportShared(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("ExportShared() error = %v", err)
+	}
+	if len(b.Feedings) != 1 || b.Feedings[0].ID != "feed-1" || len(b.Sleep) != 0 {
+		t.Errorf("ExportShared() feedings=%+v sleep=%+v, want private ones left out", b.Feedings, b.Sleep)
+	}
+	if len(b.Medications) != 1 || len(b.Medications[0].Logs) != 1 || b.Medications[0].Logs[0].ID != "log-1" {
+		t.Errorf("ExportShared() medications = %+v, want the private dose left out", b.Medications)
+	}
+	if len(b.Notes) != 1 || b.Notes[0].ID != "note-1" {
+		t.Errorf("ExportShared() notes = %+v, want the private note left out", b.Notes)
+	}
+
+	if _, err := svc.ExportShared(context.Background(), "stranger", "child-1"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("ExportShared() error = %v, want ErrNotMember", err)
+	}
+}
+
+// mockVisibilityService hides feedings and notes from everyone
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) GetMemberVisibility(ctx context.Context, requesterID, familyID, userID string) (*visibility.MemberVisibility, error) {
+	return &visibility.MemberVisibility{FamilyID: familyID, UserID: userID,
+		HiddenTypes: []visibility.RecordType{visibility.RecordFeeding, visibility.RecordNotes}}, nil
+}
+
+func TestService_Export_Hidden(t *testing.T) {
+	_, ts := newTestService()
+	svc := NewService(ts.repo, ts.family, ts.feeding, ts.sleep, ts.medication, ts.vaccination, ts.appointment,
+		ts.notes, ts.journal, WithVisibility(&mockVisibilityService{}))
+	ts.feeding.feedings = []feeding.Feeding{{ID: "feed-1", ChildID: "child-1"}}
+
+	b, err := svc.Export(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(b.Feedings) != 0 || len(b.Notes) != 0 {
+		t.Errorf("Export() feedings=%d notes=%d, want hidden types left out", len(b.Feedings), len(b.Notes))
+	}
+	if len(b.Journal) != 1 {
+		t.Errorf("Export() journal=%d, want 1", len(b.Journal))
+	}
+}
+
+func TestService_Stream(t *testing.T) {
+	svc, ts := newTestService()
+	ts.feeding.feedings = []feeding.Feeding{{ID: "feed-1", ChildID: "child-1"}, {ID: "feed-2", ChildID: "child-1"}}
+	ts.medication.meds = []medication.Medication{factory.Medication()}
+	ts.medication.logs = []medication.MedicationLog{{ID: "log-1", MedicationID: "med-1"}}
+
+	s, err := svc.Stream(context.Background(), "user-1", "child-1")
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if s.Bundle.SourceFamilyID != "family-1" || s.Bundle.Notes != nil || s.Bundle.Feedings != nil {
+		t.Errorf("Stream() bundle = %+v", s.Bundle)
+	}
+
+	for med, err := range s.Medications {
+		if err != nil || len(med.Logs) != 1 {
+			t.Errorf("Stream() medication = %+v, %v; want it with its log", med, err)
+		}
+	}
+
+	n := 0
+	for _, err := range s.Feedings {
+		if err != nil {
+			t.Fatalf("Stream() feedings error = %v", err)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("Stream() yielded %d feedings, want 2", n)
+	}
+
+	if _, err := svc.Stream(context.Background(), "stranger", "child-1"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Stream() error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestService_Export_NotMember(t *testing.T) {
+	svc, _ := newTestService()
+
+	_, err := svc.Export(context.Background(), "stranger", "child-1")
+	if !errors.Is(err, ErrNotMember) {
+		t.Errorf("Export() error = %v, want ErrNotMember", err)
+	}
+}
+
+func TestService_Export_ChildNotFound(t *testing.T) {
+	svc, _ := newTestService()
+
+	_, err := svc.Export(context.Background(), "user-1", "missing")
+	if !errors.Is(err, ErrChildNotFound) {
+		t.Errorf("Export() error = %v, want ErrChildNotFound", err)
+	}
+}
+
+func TestService_Import(t *testing.T) {
+	svc, ts := newTestService()
+	given := time.Now().Add(-48 * time.Hour)
+
+	b := &Bundle{
+		Version:        BundleVersion,
+		ExportedAt:     time.Now(),
+		SourceFamilyID: "family-1",
+		Child:          family.Child{ID: "child-1", Name: "Ava"},
+		Feedings:       []feeding.Feeding{{ID: "feed-1"}},
+		Medications: []BundleMedication{{
+			Medication: medication.Medication{ID: "med-1", Active: false},
+			Logs:       []medication.MedicationLog{{ID: "log-1"}},
+		}},
+		Vaccinations: []vaccination.Vaccination{
+			{ID: "vax-1", Status: vaccination.StatusCompleted, Completed: true, AdministeredAt: &given},
+			{ID: "vax-2", Status: vaccination.StatusRefused, StatusReason: "Parent declined"},
+		},
+		Appointments: []appointment.Appointment{{ID: "apt-1", Cancelled: true}},
+		Notes:        []notes.Note{{ID: "note-1"}},
+		Journal: []journal.Entry{
+			{ID: "entry-1", MediaID: "media-1", Caption: "First steps", Date: "2024-03-10", Milestone: true},
+			{ID: "entry-2", MediaID: "media-2", Date: "2024-03-11"},
+		},
+	}
+
+	result, err := svc.Import(context.Background(), "user-2", "family-2", b)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if result.Child.FamilyID != "family-2" {
+		t.Errorf("Import() child family = %s, want family-2", result.Child.FamilyID)
+	}
+	if result.Import.Source != SourceBundle || result.Import.SourceChildID != "child-1" || result.Import.SourceFamilyID != "family-1" {
+		t.Errorf("Import() provenance = %+v", result.Import)
+	}
+	if len(result.Import.Records) != 8 {
+		t.Errorf("Import() mapped %d records, want 8", len(result.Import.Records))
+	}
+	if len(ts.journal.created) != 1 || ts.journal.created[0].MediaID != "" || !ts.journal.created[0].Milestone {
+		t.Errorf("Import() journal = %+v, want the captioned entry without its photo", ts.journal.created)
+	}
+	if len(ts.repo.imports) != 1 {
+		t.Error("Import() should persist the provenance record")
+	}
+	if len(ts.medication.deactivated) != 1 || len(ts.vaccination.recorded) != 1 ||
+		len(ts.vaccination.statuses) != 1 || len(ts.appointment.cancelled) != 1 {
+		t.Error("Import() should carry over medication, vaccination and appointment status")
+	}
+}
+
+func TestService_Import_UnsupportedVersion(t *testing.T) {
+	svc, _ := newTestService()
+
+	_, err := svc.Import(context.Background(), "user-2", "family-2", &Bundle{Version: 99})
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Import() error = %v, want ErrUnsupportedVersion", err)
+	}
+}
+
+func TestService_Import_CleansUpOnFailure(t *testing.T) {
+	svc, ts := newTestService()
+	ts.sleep.createErr = errors.New("database error")
+
+	b := &Bundle{Version: BundleVersion, Sleep: []sleep.Sleep{{ID: "sleep-1"}}}
+
+	if _, err := svc.Import(context.Background(), "user-2", "family-2", b); err == nil {
+		t.Fatal("Import() should return error")
+	}
+	if len(ts.family.deleted) != 1 || ts.family.deleted[0] != "new-child" {
+		t.Errorf("Import() should delete the partially imported child, deleted = %v", ts.family.deleted)
+	}
+	if len(ts.repo.imports) != 0 {
+		t.Error("Import() should not record provenance for a failed import")
+	}
+}
+
+func TestService_ImportApp(t *testing.T) {
+	svc, ts := newTestService()
+	export := "Type,Start,End,Start Condition,Start Location,End Condition,Notes\n" +
+		"Sleep,2024-03-01 20:00,2024-03-02 06:30,,,,\n" +
+		"Feed,2024-03-02 07:00,2024-03-02 07:10,Formula,Bottle,120ml,\n" +
+		"Diaper,2024-03-02 07:15,,,,,\n"
+
+	result, err := svc.ImportApp(context.Background(), "user-2", "family-2", &AppImportRequest{
+		Source: SourceHuckleberry, Name: "Ava", DateOfBirth: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Data: []byte(export),
+	})
+	if err != nil {
+		t.Fatalf("ImportApp() error = %v", err)
+	}
+
+	if result.Import.Source != SourceHuckleberry || len(result.Import.Records) != 2 {
+		t.Errorf("ImportApp() import = %+v", result.Import)
+	}
+	if result.Skipped["diaper"] != 1 {
+		t.Errorf("ImportApp() skipped = %v, want the diaper counted", result.Skipped)
+	}
+	if len(ts.feeding.created) != 1 || ts.feeding.created[0].Type != feeding.FeedingTypeFormula {
+		t.Errorf("ImportApp() feedings = %+v", ts.feeding.created)
+	}
+	if len(ts.repo.imports) != 1 || ts.repo.imports[0].Source != SourceHuckleberry {
+		t.Error("ImportApp() should record the app as the import's source")
+	}
+}
+
+func TestService_ImportApp_UnknownSource(t *testing.T) {
+	svc, _ := newTestService()
+
+	_, err := svc.ImportApp(context.Background(), "user-2", "family-2", &AppImportRequest{Source: "other"})
+	if !errors.Is(err, ErrUnknownSource) {
+		t.Errorf("ImportApp() error = %v, want ErrUnknownSource", err)
+	}
+}
+
+func TestService_ImportApp_NotMember(t *testing.T) {
+	svc, _ := newTestService()
+
+	_, err := svc.ImportApp(context.Background(), "stranger", "family-2", &AppImportRequest{Source: SourceGlowBaby, Data: []byte("[]")})
+	if !errors.Is(err, ErrNotMember) {
+		t.Errorf("ImportApp() error = %v, want ErrNotMember", err)
+	}
+}
@@ -0,0 +1,39 @@
+package transfer
+
+import (
+	"context"
+	"iter"
+	"slices"
+
+	"github.com/ninenine/babytrack/internal/visibility"
+)
+
+type Option func(*service)
+
+// WithVisibility leaves the record types hidden from the exporting member out
+// of their bundles
+func WithVisibility(v visibility.Service) Option {
+	return func(s *service) {
+		s.visibility = v
+	}
+}
+
+// hiddenTypes returns the record types hidden from the user in the family
+func (s *service) hiddenTypes(ctx context.Context, userID, familyID string) ([]visibility.RecordType, error) {
+	if s.visibility == nil {
+		return nil, nil
+	}
+	v, err := s.visibility.GetMemberVisibility(ctx, userID, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return v.HiddenTypes, nil
+}
+
+// unlessHidden yields the records, or nothing when their type is hidden
+func unlessHidden[T any](hidden []visibility.RecordType, recordType visibility.RecordType, records iter.Seq2[T, error]) iter.Seq2[T, error] {
+	if slices.Contains(hidden, recordType) {
+		return func(func(T, error) bool) {}
+	}
+	return records
+}
@@ -0,0 +1,81 @@
+package vaccination
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/corrections"
+)
+
+// WithCorrections keeps the original of every corrected dose. The store must
+// be built on the same database as the repository.
+func WithCorrections(store corrections.Store) Option {
+	return func(s *service) {
+		s.corrections = store
+	}
+}
+
+func (s *service) Correct(ctx context.Context, userID, id string, req *CorrectVaccinationRequest) (*Vaccination, error) {
+	if s.corrections == nil {
+		return nil, corrections.ErrUnavailable
+	}
+	reason, err := corrections.Reason(req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	vax, err := s.getForChange(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if vax.Status != StatusCompleted {
+		return nil, ErrNotAdministered
+	}
+
+	original := *vax
+	if name := strings.TrimSpace(req.Name); name != "" {
+		vax.Name = name
+	}
+	if req.Dose > 0 {
+		vax.Dose = req.Dose
+	}
+	now := time.Now()
+	if err := s.administer(ctx, vax, &req.RecordVaccinationRequest, now); err != nil {
+		return nil, err
+	}
+	vax.CorrectedAt = &now
+
+	correction := &corrections.Correction{
+		EntityType:  corrections.EntityVaccination,
+		EntityID:    vax.ID,
+		ChildID:     vax.ChildID,
+		Reason:      reason,
+		CorrectedBy: userID,
+		CorrectedAt: now,
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, vax); err != nil {
+			return err
+		}
+		return s.corrections.Record(ctx, correction, &original, vax)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to correct vaccination: %w", err)
+	}
+	s.recordVersion(ctx, vax, audit.ActionUpdate)
+
+	return vax, nil
+}
+
+func (s *service) ListCorrections(ctx context.Context, userID, id string) ([]corrections.Correction, error) {
+	if s.corrections == nil {
+		return nil, corrections.ErrUnavailable
+	}
+	if _, err := s.getForChange(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.corrections.List(ctx, corrections.EntityVaccination, id)
+}
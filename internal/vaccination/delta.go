@@ -0,0 +1,50 @@
+package vaccination
+
+import (
+	"context"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/delta"
+)
+
+// WithTombstones remembers deleted vaccinations so that ListSince can
+// report them
+func WithTombstones(tombstones delta.Tombstones) Option {
+	return func(s *service) {
+		s.tombstones = tombstones
+	}
+}
+
+// ListSince returns the vaccinations matching filter that were written
+// after since, and the IDs of the child's vaccinations deleted since.
+// filter.ChildID must be set.
+func (s *service) ListSince(ctx context.Context, filter *VaccinationFilter, since time.Time) (*delta.Changes[Vaccination], error) {
+	now := time.Now()
+	deleted, err := delta.Deleted(ctx, s.tombstones, delta.EntityVaccination, filter.ChildID, since, now)
+	if err != nil {
+		return nil, err
+	}
+
+	vaxes, err := s.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return delta.Build(vaxes, since, deleted, now, func(v *Vaccination) (time.Time, time.Time) {
+		return v.CreatedAt, v.UpdatedAt
+	}), nil
+}
+
+// deleteRecord deletes the vaccination and leaves a tombstone for it in the
+// same transaction
+func (s *service) deleteRecord(ctx context.Context, vax *Vaccination) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, vax.ID); err != nil {
+			return err
+		}
+		if s.tombstones == nil {
+			return nil
+		}
+		return s.tombstones.Record(ctx, delta.EntityVaccination, vax.ID, vax.ChildID, time.Now())
+	})
+}
@@ -1,8 +1,22 @@
 package vaccination
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,34 +29,91 @@ func NewHandler(service Service) *Handler {
 	return &Handler{service: service}
 }
 
-func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+// RegisterRoutes registers the vaccination routes. Middleware in export runs
+// ahead of the refusals download only.
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup, export ...gin.HandlerFunc) {
 	rg.GET("", h.list)
 	rg.POST("", h.create)
 	rg.GET("/schedule", h.getSchedule)
 	rg.GET("/upcoming/:childId", h.getUpcoming)
+	rg.GET("/coverage/:childId", h.getCoverage)
+	rg.GET("/prefill/:childId", h.getPrefill)
 	rg.POST("/generate/:childId", h.generateSchedule)
 	rg.GET("/:id", h.get)
 	rg.PUT("/:id", h.update)
 	rg.DELETE("/:id", h.delete)
 	rg.POST("/:id/record", h.recordAdministration)
+	rg.POST("/:id/status", h.setStatus)
+	rg.POST("/:id/corrections", h.correct)
+	rg.GET("/:id/corrections", h.listCorrections)
+	rg.Group("", export...).GET("/refusals/:childId", h.exportRefusals)
+}
+
+// parseStatuses reads a comma-separated status filter. The older completed
+// flag is still accepted when no status is given.
+func parseStatuses(status, completed string) ([]Status, error) {
+	if status == "" {
+		switch completed {
+		case "":
+			return nil, nil
+		case "true":
+			return []Status{StatusCompleted}, nil
+		default:
+			return []Status{StatusScheduled, StatusSkipped, StatusRefused, StatusContraindicated}, nil
+		}
+	}
+
+	var statuses []Status
+	for part := range strings.SplitSeq(status, ",") {
+		st := Status(strings.TrimSpace(part))
+		if !st.Valid() {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, st)
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
 }
 
 func (h *Handler) list(c *gin.Context) {
-	completed := c.Query("completed")
-	var completedPtr *bool
-	if completed != "" {
-		val := completed == "true"
-		completedPtr = &val
+	statuses, err := parseStatuses(c.Query("status"), c.Query("completed"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	filter := &VaccinationFilter{
 		ChildID:      c.Query("child_id"),
-		Completed:    completedPtr,
+		Statuses:     statuses,
 		UpcomingOnly: c.Query("upcoming_only") == "true",
+		ViewerID:     c.GetString("user_id"),
+	}
+
+	since, err := delta.ParseSince(c.Query("since"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if since != nil {
+		if filter.ChildID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "child_id is required with since"})
+			return
+		}
+		changes, err := h.service.ListSince(c.Request.Context(), filter, *since)
+		if errors.Is(err, delta.ErrSinceExpired) {
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, changes)
+		return
 	}
+
 	vaxes, err := h.service.List(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, vaxes)
@@ -55,9 +126,10 @@ func (h *Handler) create(c *gin.Context) {
 		return
 	}
 
+	req.UserID = c.GetString("user_id")
 	vax, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, vax)
@@ -65,9 +137,9 @@ func (h *Handler) create(c *gin.Context) {
 
 func (h *Handler) get(c *gin.Context) {
 	id := c.Param("id")
-	vax, err := h.service.Get(c.Request.Context(), id)
+	vax, err := h.service.Get(c.Request.Context(), c.GetString("user_id"), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, vax)
@@ -81,9 +153,9 @@ func (h *Handler) update(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	vax, err := h.service.Update(c.Request.Context(), id, &req)
+	vax, err := h.service.Update(c.Request.Context(), c.GetString("user_id"), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, vax)
@@ -91,8 +163,8 @@ func (h *Handler) update(c *gin.Context) {
 
 func (h *Handler) delete(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Delete(c.Request.Context(), c.GetString("user_id"), id); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -106,14 +178,121 @@ func (h *Handler) recordAdministration(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	vax, err := h.service.RecordAdministration(c.Request.Context(), id, &req)
+	vax, err := h.service.RecordAdministration(c.Request.Context(), c.GetString("user_id"), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, vax)
+}
+
+func (h *Handler) setStatus(c *gin.Context) {
+	var req SetStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	vax, err := h.service.SetStatus(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, vax)
 }
 
+func (h *Handler) correct(c *gin.Context) {
+	var req CorrectVaccinationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	vax, err := h.service.Correct(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, vax)
+}
+
+func (h *Handler) listCorrections(c *gin.Context) {
+	list, err := h.service.ListCorrections(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// exportRefusals downloads the child's refused and contraindicated doses with
+// their documented reasons and exemptions, e.g. for a school or daycare form
+func (h *Handler) exportRefusals(c *gin.Context) {
+	vaxes, err := h.service.List(c.Request.Context(), &VaccinationFilter{
+		ChildID:  c.Param("childId"),
+		Statuses: []Status{StatusRefused, StatusContraindicated},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	records := [][]string{{"vaccine", "dose", "scheduled_at", "status", "reason_code", "reason", "exemption", "document_ids", "recorded_at"}}
+	for _, v := range vaxes {
+		recordedAt := ""
+		if v.StatusChangedAt != nil {
+			recordedAt = v.StatusChangedAt.UTC().Format(time.RFC3339)
+		}
+		records = append(records, []string{
+			v.Name,
+			strconv.Itoa(v.Dose),
+			v.ScheduledAt.Format("2006-01-02"),
+			string(v.Status),
+			string(v.ReasonCode),
+			v.StatusReason,
+			strconv.FormatBool(v.Exemption),
+			strings.Join(v.DocumentIDs, " "),
+			recordedAt,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := csv.NewWriter(&buf).WriteAll(records); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="vaccination-refusals.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+func respondError(c *gin.Context, err error) {
+	var timingErr *DoseTimingError
+	if errors.As(err, &timingErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":    ErrDoseTooEarly.Error(),
+			"problems": timingErr.Problems,
+		})
+		return
+	}
+
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, visibility.ErrChildNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, family.ErrNotMember), errors.Is(err, visibility.ErrHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrCorrectionRequired), errors.Is(err, ErrNotAdministered):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidStatus), errors.Is(err, ErrReasonRequired), errors.Is(err, ErrInvalidReasonCode),
+		errors.Is(err, ErrInvalidExemption), errors.Is(err, occurrence.ErrInvalid), errors.Is(err, corrections.ErrReasonRequired),
+		errors.Is(err, limits.ErrExceeded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func (h *Handler) getUpcoming(c *gin.Context) {
 	childID := c.Param("childId")
 	days := 30 // default
@@ -153,3 +332,29 @@ func (h *Handler) generateSchedule(c *gin.Context) {
 	}
 	c.JSON(http.StatusCreated, vaxes)
 }
+
+func (h *Handler) getCoverage(c *gin.Context) {
+	childID := c.Param("childId")
+
+	asOf, err := audit.ParseAsOf(c.Query("as_of"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid as_of date"})
+		return
+	}
+
+	report, err := h.service.GetCoverage(c.Request.Context(), childID, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) getPrefill(c *gin.Context) {
+	prefill, err := h.service.SuggestPrefill(c.Request.Context(), c.Param("childId"), c.Query("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prefill)
+}
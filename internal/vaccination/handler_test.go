@@ -5,11 +5,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"iter"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/visibility"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,12 +29,18 @@ type mockService struct {
 	createFn                   func(ctx context.Context, req *CreateVaccinationRequest) (*Vaccination, error)
 	getFn                      func(ctx context.Context, id string) (*Vaccination, error)
 	listFn                     func(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error)
+	listSinceFn                func(ctx context.Context, filter *VaccinationFilter, since time.Time) (*delta.Changes[Vaccination], error)
 	updateFn                   func(ctx context.Context, id string, req *CreateVaccinationRequest) (*Vaccination, error)
 	deleteFn                   func(ctx context.Context, id string) error
 	recordAdministrationFn     func(ctx context.Context, id string, req *RecordVaccinationRequest) (*Vaccination, error)
+	setStatusFn                func(ctx context.Context, id string, req *SetStatusRequest) (*Vaccination, error)
 	getUpcomingFn              func(ctx context.Context, childID string, days int) ([]Vaccination, error)
 	getScheduleFn              func() []VaccinationSchedule
 	generateScheduleForChildFn func(ctx context.Context, childID string, birthDate string) ([]Vaccination, error)
+	getCoverageFn              func(ctx context.Context, childID string, asOf *time.Time) (*CoverageReport, error)
+	suggestPrefillFn           func(ctx context.Context, childID, name string) (*Prefill, error)
+	correctFn                  func(ctx context.Context, userID, id string, req *CorrectVaccinationRequest) (*Vaccination, error)
+	listCorrectionsFn          func(ctx context.Context, id string) ([]corrections.Correction, error)
 }
 
 func (m *mockService) Create(ctx context.Context, req *CreateVaccinationRequest) (*Vaccination, error) {
@@ -37,7 +50,7 @@ func (m *mockService) Create(ctx context.Context, req *CreateVaccinationRequest)
 	return nil, nil
 }
 
-func (m *mockService) Get(ctx context.Context, id string) (*Vaccination, error) {
+func (m *mockService) Get(ctx context.Context, userID, id string) (*Vaccination, error) {
 	if m.getFn != nil {
 		return m.getFn(ctx, id)
 	}
@@ -51,27 +64,59 @@ func (m *mockService) List(ctx context.Context, filter *VaccinationFilter) ([]Va
 	return nil, nil
 }
 
-func (m *mockService) Update(ctx context.Context, id string, req *CreateVaccinationRequest) (*Vaccination, error) {
+func (m *mockService) Stream(ctx context.Context, filter *VaccinationFilter) iter.Seq2[Vaccination, error] {
+	return nil
+}
+
+func (m *mockService) ListSince(ctx context.Context, filter *VaccinationFilter, since time.Time) (*delta.Changes[Vaccination], error) {
+	if m.listSinceFn != nil {
+		return m.listSinceFn(ctx, filter, since)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Update(ctx context.Context, userID, id string, req *CreateVaccinationRequest) (*Vaccination, error) {
 	if m.updateFn != nil {
 		return m.updateFn(ctx, id, req)
 	}
 	return nil, nil
 }
 
-func (m *mockService) Delete(ctx context.Context, id string) error {
+func (m *mockService) Delete(ctx context.Context, userID, id string) error {
 	if m.deleteFn != nil {
 		return m.deleteFn(ctx, id)
 	}
 	return nil
 }
 
-func (m *mockService) RecordAdministration(ctx context.Context, id string, req *RecordVaccinationRequest) (*Vaccination, error) {
+func (m *mockService) RecordAdministration(ctx context.Context, userID, id string, req *RecordVaccinationRequest) (*Vaccination, error) {
 	if m.recordAdministrationFn != nil {
 		return m.recordAdministrationFn(ctx, id, req)
 	}
 	return nil, nil
 }
 
+func (m *mockService) SetStatus(ctx context.Context, userID, id string, req *SetStatusRequest) (*Vaccination, error) {
+	if m.setStatusFn != nil {
+		return m.setStatusFn(ctx, id, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) Correct(ctx context.Context, userID, id string, req *CorrectVaccinationRequest) (*Vaccination, error) {
+	if m.correctFn != nil {
+		return m.correctFn(ctx, userID, id, req)
+	}
+	return nil, nil
+}
+
+func (m *mockService) ListCorrections(ctx context.Context, userID, id string) ([]corrections.Correction, error) {
+	if m.listCorrectionsFn != nil {
+		return m.listCorrectionsFn(ctx, id)
+	}
+	return []corrections.Correction{}, nil
+}
+
 func (m *mockService) GetUpcoming(ctx context.Context, childID string, days int) ([]Vaccination, error) {
 	if m.getUpcomingFn != nil {
 		return m.getUpcomingFn(ctx, childID, days)
@@ -93,9 +138,24 @@ func (m *mockService) GenerateScheduleForChild(ctx context.Context, childID stri
 	return nil, nil
 }
 
+func (m *mockService) GetCoverage(ctx context.Context, childID string, asOf *time.Time) (*CoverageReport, error) {
+	if m.getCoverageFn != nil {
+		return m.getCoverageFn(ctx, childID, asOf)
+	}
+	return nil, nil
+}
+
+func (m *mockService) SuggestPrefill(ctx context.Context, childID, name string) (*Prefill, error) {
+	if m.suggestPrefillFn != nil {
+		return m.suggestPrefillFn(ctx, childID, name)
+	}
+	return nil, nil
+}
+
 // setupRouter creates a test router with the handler registered
 func setupRouter(svc Service) *gin.Engine {
 	router := gin.New()
+	router.Use(apispectest.Record("/api"))
 	handler := NewHandler(svc)
 
 	// Add middleware to set user_id in context (simulating auth)
@@ -118,7 +178,7 @@ func sampleVaccination() *Vaccination {
 		Name:        "DTaP",
 		Dose:        1,
 		ScheduledAt: time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC),
-		Completed:   false,
+		Status:      StatusScheduled,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -139,7 +199,7 @@ func completedVaccination() *Vaccination {
 		Location:       "Pediatric Clinic",
 		LotNumber:      "LOT123ABC",
 		Notes:          "No reactions observed",
-		Completed:      true,
+		Status:         StatusCompleted,
 		CreatedAt:      now,
 		UpdatedAt:      now,
 	}
@@ -265,11 +325,8 @@ func TestList_WithCompletedTrueFilter(t *testing.T) {
 	if capturedFilter == nil {
 		t.Fatal("Filter was not passed to service")
 	}
-	if capturedFilter.Completed == nil {
-		t.Fatal("Expected Completed to be set")
-	}
-	if *capturedFilter.Completed != true {
-		t.Error("Expected Completed to be true")
+	if !slices.Equal(capturedFilter.Statuses, []Status{StatusCompleted}) {
+		t.Errorf("Expected completed status filter, got %v", capturedFilter.Statuses)
 	}
 }
 
@@ -293,11 +350,8 @@ func TestList_WithCompletedFalseFilter(t *testing.T) {
 	if capturedFilter == nil {
 		t.Fatal("Filter was not passed to service")
 	}
-	if capturedFilter.Completed == nil {
-		t.Fatal("Expected Completed to be set")
-	}
-	if *capturedFilter.Completed != false {
-		t.Error("Expected Completed to be false")
+	if len(capturedFilter.Statuses) == 0 || slices.Contains(capturedFilter.Statuses, StatusCompleted) {
+		t.Errorf("Expected every status but completed, got %v", capturedFilter.Statuses)
 	}
 }
 
@@ -349,15 +403,15 @@ func TestList_WithAllFilters(t *testing.T) {
 	if capturedFilter.ChildID != "child-456" {
 		t.Errorf("Expected ChildID child-456, got %s", capturedFilter.ChildID)
 	}
-	if capturedFilter.Completed == nil || *capturedFilter.Completed != false {
-		t.Error("Expected Completed to be false")
+	if len(capturedFilter.Statuses) == 0 || slices.Contains(capturedFilter.Statuses, StatusCompleted) {
+		t.Errorf("Expected every status but completed, got %v", capturedFilter.Statuses)
 	}
 	if !capturedFilter.UpcomingOnly {
 		t.Error("Expected UpcomingOnly to be true")
 	}
 }
 
-func TestList_CompletedNilWhenNotProvided(t *testing.T) {
+func TestList_StatusesNilWhenNotProvided(t *testing.T) {
 	var capturedFilter *VaccinationFilter
 	svc := &mockService{
 		listFn: func(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error) {
@@ -371,8 +425,42 @@ func TestList_CompletedNilWhenNotProvided(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	if capturedFilter.Completed != nil {
-		t.Error("Expected Completed to be nil when not provided")
+	if capturedFilter.Statuses != nil {
+		t.Error("Expected Statuses to be nil when not provided")
+	}
+}
+
+func TestList_WithStatusFilter(t *testing.T) {
+	var capturedFilter *VaccinationFilter
+	svc := &mockService{
+		listFn: func(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error) {
+			capturedFilter = filter
+			return []Vaccination{}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/vaccinations?status=refused,contraindicated&completed=true", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !slices.Equal(capturedFilter.Statuses, []Status{StatusRefused, StatusContraindicated}) {
+		t.Errorf("Expected status to take precedence over completed, got %v", capturedFilter.Statuses)
+	}
+}
+
+func TestList_InvalidStatus(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/vaccinations?status=lost", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
 	}
 }
 
@@ -401,6 +489,55 @@ func TestList_ServiceError(t *testing.T) {
 	}
 }
 
+func TestList_Since(t *testing.T) {
+	svc := &mockService{
+		listSinceFn: func(ctx context.Context, filter *VaccinationFilter, since time.Time) (*delta.Changes[Vaccination], error) {
+			if filter.ChildID != "child-123" {
+				t.Errorf("Expected child_id child-123, got %s", filter.ChildID)
+			}
+			if since.Year() == 2020 {
+				return nil, delta.ErrSinceExpired
+			}
+			return &delta.Changes[Vaccination]{
+				Created: []Vaccination{},
+				Updated: []Vaccination{{ID: "vax-1"}},
+				Deleted: []string{"vax-2"},
+			}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+	}{
+		{"child_id=child-123&since=2025-01-01T00:00:00Z", http.StatusOK},
+		{"since=2025-01-01T00:00:00Z", http.StatusBadRequest},
+		{"child_id=child-123&since=2025-01-01", http.StatusBadRequest},
+		{"child_id=child-123&since=2020-01-01T00:00:00Z", http.StatusGone},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/vaccinations?"+tt.query, http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.query, tt.wantStatus, w.Code)
+			continue
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		var result delta.Changes[Vaccination]
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if len(result.Updated) != 1 || len(result.Deleted) != 1 {
+			t.Errorf("Unexpected delta %+v", result)
+		}
+	}
+}
+
 func TestList_EmptyResult(t *testing.T) {
 	svc := &mockService{
 		listFn: func(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error) {
@@ -484,6 +621,23 @@ func TestGet_ServiceError(t *testing.T) {
 	}
 }
 
+func TestGet_Hidden(t *testing.T) {
+	svc := &mockService{
+		getFn: func(ctx context.Context, id string) (*Vaccination, error) {
+			return nil, visibility.ErrHidden
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/vaccinations/vax-123", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
 func TestGet_VerifiesIDParam(t *testing.T) {
 	var capturedID string
 	svc := &mockService{
@@ -613,8 +767,14 @@ func TestCreate_MissingName(t *testing.T) {
 	}
 }
 
-func TestCreate_MissingDose(t *testing.T) {
-	svc := &mockService{}
+func TestCreate_MissingDoseIsDefaulted(t *testing.T) {
+	var capturedReq *CreateVaccinationRequest
+	svc := &mockService{
+		createFn: func(ctx context.Context, req *CreateVaccinationRequest) (*Vaccination, error) {
+			capturedReq = req
+			return &Vaccination{ID: "vax-1", Dose: 2}, nil
+		},
+	}
 	router := setupRouter(svc)
 
 	body, _ := json.Marshal(map[string]any{
@@ -627,8 +787,26 @@ func TestCreate_MissingDose(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201 for missing dose, got %d", w.Code)
+	}
+	if capturedReq.Dose != 0 {
+		t.Errorf("Expected dose left for the service to default, got %d", capturedReq.Dose)
+	}
+}
+
+func TestCreate_InvalidDose(t *testing.T) {
+	svc := &mockService{}
+	router := setupRouter(svc)
+
+	body := `{"child_id":"child-456","name":"DTaP","dose":-1,"scheduled_at":"2025-03-15T10:00:00Z"}`
+	req := httptest.NewRequest("POST", "/vaccinations", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
 	if w.Code != http.StatusBadRequest {
-		t.Errorf("Expected status 400 for missing dose, got %d", w.Code)
+		t.Errorf("Expected status 400 for negative dose, got %d", w.Code)
 	}
 }
 
@@ -922,7 +1100,7 @@ func TestRecordAdministration_Success(t *testing.T) {
 	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
 		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
-	if !result.Completed {
+	if result.Status != StatusCompleted {
 		t.Error("Expected vaccination to be marked as completed")
 	}
 	if result.Provider != "Dr. Smith" {
@@ -982,6 +1160,36 @@ func TestRecordAdministration_MissingAdministeredAt(t *testing.T) {
 	}
 }
 
+func TestRecordAdministration_DoseTooEarly(t *testing.T) {
+	svc := &mockService{
+		recordAdministrationFn: func(ctx context.Context, id string, req *RecordVaccinationRequest) (*Vaccination, error) {
+			return nil, &DoseTimingError{Problems: []string{"DTaP dose 2 was given 2 weeks after dose 1; the minimum interval is 4 weeks"}}
+		},
+	}
+	router := setupRouter(svc)
+
+	body, _ := json.Marshal(validRecordVaccinationRequest())
+	req := httptest.NewRequest("POST", "/vaccinations/vax-1/record", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d", w.Code)
+	}
+
+	var result struct {
+		Error    string   `json:"error"`
+		Problems []string `json:"problems"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if result.Error != ErrDoseTooEarly.Error() || len(result.Problems) != 1 {
+		t.Errorf("Unexpected response %+v", result)
+	}
+}
+
 func TestRecordAdministration_ServiceError(t *testing.T) {
 	svc := &mockService{
 		recordAdministrationFn: func(ctx context.Context, id string, req *RecordVaccinationRequest) (*Vaccination, error) {
@@ -1080,6 +1288,97 @@ func TestRecordAdministration_WithOptionalFieldsOnly(t *testing.T) {
 // GetUpcoming Handler Tests
 // =====================
 
+func TestSetStatus_ErrorStatuses(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"not found", ErrNotFound, http.StatusNotFound},
+		{"bad transition", ErrInvalidTransition, http.StatusConflict},
+		{"missing reason", ErrReasonRequired, http.StatusBadRequest},
+		{"unknown status", ErrInvalidStatus, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				setStatusFn: func(ctx context.Context, id string, req *SetStatusRequest) (*Vaccination, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			req := httptest.NewRequest("POST", "/vaccinations/vax-123/status", bytes.NewBufferString(`{"status":"refused"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestSetStatus_Success(t *testing.T) {
+	var capturedID string
+	var capturedReq *SetStatusRequest
+	svc := &mockService{
+		setStatusFn: func(ctx context.Context, id string, req *SetStatusRequest) (*Vaccination, error) {
+			capturedID, capturedReq = id, req
+			return &Vaccination{ID: id, Status: req.Status, StatusReason: req.Reason}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"status":"contraindicated","reason":"Egg allergy"}`
+	req := httptest.NewRequest("POST", "/vaccinations/vax-123/status", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if capturedID != "vax-123" || capturedReq.Status != StatusContraindicated || capturedReq.Reason != "Egg allergy" {
+		t.Errorf("Unexpected request %s %+v", capturedID, capturedReq)
+	}
+}
+
+func TestExportRefusals(t *testing.T) {
+	var capturedFilter *VaccinationFilter
+	changedAt := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	svc := &mockService{
+		listFn: func(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error) {
+			capturedFilter = filter
+			return []Vaccination{{
+				Name: "MMR", Dose: 1, ScheduledAt: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+				Status: StatusRefused, StatusReason: "Parent declined, will revisit", StatusChangedAt: &changedAt,
+				ReasonCode: ReasonReligious, Exemption: true, DocumentIDs: []string{"media-1", "media-2"},
+			}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/vaccinations/refusals/child-456", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if capturedFilter.ChildID != "child-456" ||
+		!slices.Equal(capturedFilter.Statuses, []Status{StatusRefused, StatusContraindicated}) {
+		t.Errorf("Unexpected filter %+v", capturedFilter)
+	}
+	want := "vaccine,dose,scheduled_at,status,reason_code,reason,exemption,document_ids,recorded_at\n" +
+		"MMR,1,2025-03-15,refused,religious,\"Parent declined, will revisit\",true,media-1 media-2,2025-03-01T09:30:00Z\n"
+	if w.Body.String() != want {
+		t.Errorf("Unexpected CSV:\n%s", w.Body.String())
+	}
+}
+
 func TestGetUpcoming_Success(t *testing.T) {
 	vaccinations := []Vaccination{*sampleVaccination()}
 	svc := &mockService{
@@ -1774,3 +2073,264 @@ func TestCreate_WithDifferentDoses(t *testing.T) {
 		})
 	}
 }
+
+// =====================
+// GetCoverage Handler Tests
+// =====================
+
+func TestGetCoverage_Success(t *testing.T) {
+	var capturedChildID string
+	svc := &mockService{
+		getCoverageFn: func(ctx context.Context, childID string, asOf *time.Time) (*CoverageReport, error) {
+			capturedChildID = childID
+			return &CoverageReport{
+				ChildID:         childID,
+				DosesRequired:   2,
+				DosesCompleted:  1,
+				PercentComplete: 50,
+				Antigens: []AntigenCoverage{
+					{Name: "DTaP", DosesRequired: 2, DosesCompleted: 1, PercentComplete: 50, OverdueDoses: []int{2}},
+				},
+			}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/vaccinations/coverage/child-456", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if capturedChildID != "child-456" {
+		t.Errorf("Expected childID child-456, got %s", capturedChildID)
+	}
+
+	var result CoverageReport
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if result.PercentComplete != 50 {
+		t.Errorf("Expected PercentComplete 50, got %v", result.PercentComplete)
+	}
+	if len(result.Antigens) != 1 || len(result.Antigens[0].OverdueDoses) != 1 {
+		t.Errorf("Expected 1 antigen with 1 overdue dose, got %+v", result.Antigens)
+	}
+}
+
+func TestGetCoverage_AsOf(t *testing.T) {
+	var capturedAsOf *time.Time
+	svc := &mockService{
+		getCoverageFn: func(ctx context.Context, childID string, asOf *time.Time) (*CoverageReport, error) {
+			capturedAsOf = asOf
+			return &CoverageReport{ChildID: childID, AsOf: asOf}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/vaccinations/coverage/child-456?as_of=2024-03-01", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	want := time.Date(2024, 3, 1, 23, 59, 59, 999999999, time.UTC)
+	if capturedAsOf == nil || !capturedAsOf.Equal(want) {
+		t.Errorf("Expected as_of at the end of 2024-03-01, got %v", capturedAsOf)
+	}
+}
+
+func TestGetCoverage_InvalidAsOf(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("GET", "/vaccinations/coverage/child-456?as_of=last-week", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestGetCoverage_ServiceError(t *testing.T) {
+	svc := &mockService{
+		getCoverageFn: func(ctx context.Context, childID string, asOf *time.Time) (*CoverageReport, error) {
+			return nil, errors.New("database error")
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/vaccinations/coverage/child-456", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+// =====================
+// GetPrefill Handler Tests
+// =====================
+
+func TestGetPrefill_Success(t *testing.T) {
+	var capturedChildID, capturedName string
+	svc := &mockService{
+		suggestPrefillFn: func(ctx context.Context, childID, name string) (*Prefill, error) {
+			capturedChildID, capturedName = childID, name
+			return &Prefill{ChildID: childID, Name: name, Provider: "Dr. Smith", LotPrefix: "U7"}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/vaccinations/prefill/child-456?name=DTaP", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if capturedChildID != "child-456" || capturedName != "DTaP" {
+		t.Errorf("SuggestPrefill() called with %q, %q", capturedChildID, capturedName)
+	}
+
+	var got Prefill
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if got.Provider != "Dr. Smith" || got.LotPrefix != "U7" {
+		t.Errorf("Expected the suggestion in the body, got %+v", got)
+	}
+}
+
+// =============================================================================
+// Correction Tests
+// =============================================================================
+
+func TestCorrect_Success(t *testing.T) {
+	var capturedUser, capturedID string
+	var capturedReq *CorrectVaccinationRequest
+	svc := &mockService{
+		correctFn: func(ctx context.Context, userID, id string, req *CorrectVaccinationRequest) (*Vaccination, error) {
+			capturedUser, capturedID, capturedReq = userID, id, req
+			now := time.Now()
+			return &Vaccination{ID: id, LotNumber: req.LotNumber, Status: StatusCompleted, CorrectedAt: &now}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	body := `{"administered_at":"2025-03-01T10:00:00Z","lot_number":"A7","reason":"lot misread from the card"}`
+	req := httptest.NewRequest("POST", "/vaccinations/vax-123/corrections", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if capturedUser != "test-user-123" || capturedID != "vax-123" {
+		t.Errorf("Correct() called with %s, %s", capturedUser, capturedID)
+	}
+	if capturedReq.LotNumber != "A7" || capturedReq.Reason != "lot misread from the card" {
+		t.Errorf("Unexpected request %+v", capturedReq)
+	}
+
+	var result Vaccination
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if result.CorrectedAt == nil {
+		t.Error("Expected the response to be marked corrected")
+	}
+}
+
+func TestCorrect_MissingReason(t *testing.T) {
+	router := setupRouter(&mockService{})
+
+	req := httptest.NewRequest("POST", "/vaccinations/vax-123/corrections", bytes.NewBufferString(`{"administered_at":"2025-03-01T10:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCorrect_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"not found", ErrNotFound, http.StatusNotFound},
+		{"not administered", ErrNotAdministered, http.StatusConflict},
+		{"blank reason", corrections.ErrReasonRequired, http.StatusBadRequest},
+		{"too early", &DoseTimingError{Problems: []string{"too early"}}, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				correctFn: func(ctx context.Context, userID, id string, req *CorrectVaccinationRequest) (*Vaccination, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(svc)
+
+			body := `{"administered_at":"2025-03-01T10:00:00Z","reason":"wrong date"}`
+			req := httptest.NewRequest("POST", "/vaccinations/vax-123/corrections", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRecordAdministration_CorrectionRequired(t *testing.T) {
+	svc := &mockService{
+		recordAdministrationFn: func(ctx context.Context, id string, req *RecordVaccinationRequest) (*Vaccination, error) {
+			return nil, ErrCorrectionRequired
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("POST", "/vaccinations/vax-123/record", bytes.NewBufferString(`{"administered_at":"2025-03-01T10:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", w.Code)
+	}
+}
+
+func TestListCorrections(t *testing.T) {
+	svc := &mockService{
+		listCorrectionsFn: func(ctx context.Context, id string) ([]corrections.Correction, error) {
+			return []corrections.Correction{{ID: "corr-1", EntityID: id, Reason: "wrong date"}}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("GET", "/vaccinations/vax-123/corrections", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var list []corrections.Correction
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(list) != 1 || list[0].EntityID != "vax-123" {
+		t.Errorf("Unexpected corrections %+v", list)
+	}
+}
@@ -0,0 +1,29 @@
+package vaccination
+
+import (
+	"context"
+	"log"
+
+	"github.com/ninenine/babytrack/internal/audit"
+)
+
+// Option configures the vaccination service
+type Option func(*service)
+
+// WithHistory keeps a version of every vaccination record written, for as-of exports
+func WithHistory(store audit.Store) Option {
+	return func(s *service) {
+		s.history = store
+	}
+}
+
+// recordVersion is best effort: the write has already happened, so a
+// failure to keep its history is only logged.
+func (s *service) recordVersion(ctx context.Context, vax *Vaccination, action audit.Action) {
+	if s.history == nil {
+		return
+	}
+	if err := s.history.Record(ctx, audit.EntityVaccination, vax.ID, vax.ChildID, action, vax); err != nil {
+		log.Printf("[vaccination] failed to record %s of %s: %v", action, vax.ID, err)
+	}
+}
@@ -0,0 +1,129 @@
+package vaccination
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+// intervalGrace lets a dose up to 4 days before its minimum age or interval
+// still count, as immunisation schedules allow
+const intervalGrace = 4 * 24 * time.Hour
+
+// DoseTimingError is returned when a dose is given before its minimum age or
+// interval. Sending an override reason records it anyway.
+type DoseTimingError struct {
+	Problems []string
+}
+
+func (e *DoseTimingError) Error() string {
+	return ErrDoseTooEarly.Error() + ": " + strings.Join(e.Problems, "; ")
+}
+
+func (e *DoseTimingError) Unwrap() error {
+	return ErrDoseTooEarly
+}
+
+// WithFamily lets the minimum age check read each child's date of birth.
+// Without it only intervals between doses are checked.
+func WithFamily(familyService family.Service) Option {
+	return func(s *service) {
+		s.familyService = familyService
+	}
+}
+
+// timingProblems checks a dose given at givenAt against the schedule's
+// minimum age and its interval since the previous completed dose of the
+// series. Vaccines not on the schedule are not checked.
+func (s *service) timingProblems(ctx context.Context, vax *Vaccination, givenAt time.Time) ([]string, error) {
+	rule, ok := s.scheduleFor(vax.Name, vax.Dose)
+	if !ok {
+		return nil, nil
+	}
+
+	var problems []string
+	label := fmt.Sprintf("%s dose %d", vax.Name, vax.Dose)
+
+	if s.familyService != nil && rule.MinAgeWeeks > 0 {
+		child, err := s.familyService.GetChild(ctx, vax.ChildID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get child: %w", err)
+		}
+		if child != nil && !child.DateOfBirth.IsZero() {
+			minAge := weeks(rule.MinAgeWeeks)
+			if age := givenAt.Sub(child.DateOfBirth); age < minAge-intervalGrace {
+				problems = append(problems, fmt.Sprintf("%s was given at %s old; the minimum age is %s",
+					label, span(age), span(minAge)))
+			}
+		}
+	}
+
+	if rule.MinIntervalWeeks > 0 {
+		prev, err := s.previousDose(ctx, vax)
+		if err != nil {
+			return nil, err
+		}
+		if prev != nil {
+			minInterval := weeks(rule.MinIntervalWeeks)
+			since := givenAt.Sub(*prev.AdministeredAt)
+			switch {
+			case since < 0:
+				problems = append(problems, fmt.Sprintf("%s was given before dose %d", label, prev.Dose))
+			case since < minInterval-intervalGrace:
+				problems = append(problems, fmt.Sprintf("%s was given %s after dose %d; the minimum interval is %s",
+					label, span(since), prev.Dose, span(minInterval)))
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+func (s *service) scheduleFor(name string, dose int) (VaccinationSchedule, bool) {
+	for _, sched := range s.repo.GetSchedule() {
+		if strings.EqualFold(sched.Name, name) && sched.Dose == dose {
+			return sched, true
+		}
+	}
+	return VaccinationSchedule{}, false
+}
+
+// previousDose returns the latest completed earlier dose of the vaccine, or nil
+func (s *service) previousDose(ctx context.Context, vax *Vaccination) (*Vaccination, error) {
+	vaxes, err := s.repo.List(ctx, &VaccinationFilter{ChildID: vax.ChildID, Statuses: []Status{StatusCompleted}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vaccinations: %w", err)
+	}
+
+	var prev *Vaccination
+	for i := range vaxes {
+		v := &vaxes[i]
+		if !strings.EqualFold(v.Name, vax.Name) || v.Dose >= vax.Dose || v.AdministeredAt == nil {
+			continue
+		}
+		if prev == nil || v.Dose > prev.Dose {
+			prev = v
+		}
+	}
+	return prev, nil
+}
+
+func weeks(n int) time.Duration {
+	return time.Duration(n) * 7 * 24 * time.Hour
+}
+
+// span describes an age or gap in days under two weeks and in weeks above
+func span(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	switch {
+	case days == 1:
+		return "1 day"
+	case days < 14:
+		return fmt.Sprintf("%d days", days)
+	default:
+		return fmt.Sprintf("%d weeks", days/7)
+	}
+}
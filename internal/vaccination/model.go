@@ -1,21 +1,97 @@
 package vaccination
 
-import "time"
+import (
+	"slices"
+	"time"
+)
+
+// Status is where a vaccination is in its lifecycle
+type Status string
+
+const (
+	StatusScheduled       Status = "scheduled"
+	StatusCompleted       Status = "completed"
+	StatusSkipped         Status = "skipped"
+	StatusRefused         Status = "refused"
+	StatusContraindicated Status = "contraindicated"
+)
+
+// transitions lists the statuses each status may move to. Completed is final:
+// a dose recorded by mistake is deleted rather than reopened.
+var transitions = map[Status][]Status{
+	StatusScheduled:       {StatusCompleted, StatusSkipped, StatusRefused, StatusContraindicated},
+	StatusSkipped:         {StatusScheduled, StatusCompleted},
+	StatusRefused:         {StatusScheduled, StatusCompleted},
+	StatusContraindicated: {StatusScheduled},
+	StatusCompleted:       {},
+}
+
+// Valid reports whether s is a known status
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransition reports whether a vaccination in status s may move to next
+func (s Status) CanTransition(next Status) bool {
+	return slices.Contains(transitions[s], next)
+}
+
+// RequiresReason reports whether moving into s must be documented
+func (s Status) RequiresReason() bool {
+	return s == StatusRefused || s == StatusContraindicated
+}
+
+// ReasonCode classifies why a dose was skipped, refused or contraindicated
+type ReasonCode string
+
+const (
+	ReasonMedical   ReasonCode = "medical"   // medical contraindication or precaution
+	ReasonAllergy   ReasonCode = "allergy"   // allergy to a vaccine component
+	ReasonImmunity  ReasonCode = "immunity"  // evidence of immunity, e.g. prior infection
+	ReasonReligious ReasonCode = "religious" // religious exemption
+	ReasonPersonal  ReasonCode = "personal"  // personal or philosophical belief
+	ReasonIllness   ReasonCode = "illness"   // acute illness at the appointment
+	ReasonSupply    ReasonCode = "supply"    // vaccine unavailable
+	ReasonOther     ReasonCode = "other"     // explained in the reason text
+)
+
+var reasonCodes = []ReasonCode{
+	ReasonMedical, ReasonAllergy, ReasonImmunity, ReasonReligious,
+	ReasonPersonal, ReasonIllness, ReasonSupply, ReasonOther,
+}
+
+// Valid reports whether c is a known reason code
+func (c ReasonCode) Valid() bool {
+	return slices.Contains(reasonCodes, c)
+}
 
 type Vaccination struct {
-	ID             string     `json:"id"`
-	ChildID        string     `json:"child_id"`
-	Name           string     `json:"name"`
-	Dose           int        `json:"dose"` // 1st, 2nd, 3rd, etc.
-	ScheduledAt    time.Time  `json:"scheduled_at"`
-	AdministeredAt *time.Time `json:"administered_at,omitempty"`
-	Provider       string     `json:"provider,omitempty"`
-	Location       string     `json:"location,omitempty"`
-	LotNumber      string     `json:"lot_number,omitempty"`
-	Notes          string     `json:"notes,omitempty"`
-	Completed      bool       `json:"completed"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
+	ID              string     `json:"id"`
+	ChildID         string     `json:"child_id"`
+	Name            string     `json:"name"`
+	Dose            int        `json:"dose"` // 1st, 2nd, 3rd, etc.
+	ScheduledAt     time.Time  `json:"scheduled_at"`
+	AdministeredAt  *time.Time `json:"administered_at,omitempty"`
+	Provider        string     `json:"provider,omitempty"`
+	Location        string     `json:"location,omitempty"`
+	LotNumber       string     `json:"lot_number,omitempty"`
+	Notes           string     `json:"notes,omitempty"`
+	Status          Status     `json:"status"`
+	StatusReason    string     `json:"status_reason,omitempty"` // why a dose was skipped, refused or contraindicated
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	ReasonCode      ReasonCode `json:"reason_code,omitempty"`
+	Exemption       bool       `json:"exemption"`    // a formal exemption is on file
+	DocumentIDs     []string   `json:"document_ids"` // supporting documents, served through the media endpoints
+	Completed       bool       `json:"completed"`    // derived from Status for clients that predate it
+	// IntervalOverride explains why a dose given before its minimum age or
+	// interval was recorded anyway
+	IntervalOverride string `json:"interval_override,omitempty"`
+	// CorrectedAt is when an administered dose was last corrected. The
+	// values it replaced are kept with its corrections.
+	CorrectedAt *time.Time `json:"corrected_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 type VaccinationSchedule struct {
@@ -26,13 +102,23 @@ type VaccinationSchedule struct {
 	AgeMonths   int    `json:"age_months"` // recommended age in months (for display)
 	AgeLabel    string `json:"age_label"`  // human-readable age (e.g., "Birth", "6 weeks")
 	Dose        int    `json:"dose"`
+	// MinAgeWeeks and MinIntervalWeeks are the earliest a dose counts, by age
+	// and since the previous dose of the series. Earlier doses need an override.
+	MinAgeWeeks      int `json:"min_age_weeks"`
+	MinIntervalWeeks int `json:"min_interval_weeks,omitempty"`
 }
 
+// CreateVaccinationRequest is used for updates too. An omitted dose defaults
+// to the child's next dose of the vaccine on create and is left unchanged on
+// update.
 type CreateVaccinationRequest struct {
 	ChildID     string    `json:"child_id" binding:"required"`
 	Name        string    `json:"name" binding:"required"`
-	Dose        int       `json:"dose" binding:"required"`
+	Dose        int       `json:"dose,omitempty" binding:"omitempty,min=1"`
 	ScheduledAt time.Time `json:"scheduled_at" binding:"required"`
+	// UserID is set by the server to the member adding the vaccination, who
+	// must be able to see the child's vaccinations. It is ignored on update.
+	UserID string `json:"-"`
 }
 
 type RecordVaccinationRequest struct {
@@ -41,10 +127,73 @@ type RecordVaccinationRequest struct {
 	Location       string    `json:"location,omitempty"`
 	LotNumber      string    `json:"lot_number,omitempty"`
 	Notes          string    `json:"notes,omitempty"`
+	// OverrideReason records a dose that fails the minimum age or interval
+	// checks, e.g. "catch-up schedule advised by the clinic"
+	OverrideReason string `json:"override_reason,omitempty"`
+}
+
+// CorrectVaccinationRequest corrects an administered dose. Name and dose are
+// left unchanged when omitted; the administration details are replaced as
+// by RecordVaccinationRequest.
+type CorrectVaccinationRequest struct {
+	Name string `json:"name,omitempty"`
+	Dose int    `json:"dose,omitempty" binding:"omitempty,min=1"`
+	RecordVaccinationRequest
+	// Reason says what was wrong, e.g. "lot number misread from the card"
+	Reason string `json:"reason" binding:"required"`
+}
+
+// SetStatusRequest moves a vaccination to any status but completed, which
+// needs the administration details of RecordVaccinationRequest
+type SetStatusRequest struct {
+	Status     Status     `json:"status" binding:"required"`
+	Reason     string     `json:"reason,omitempty"`
+	ReasonCode ReasonCode `json:"reason_code,omitempty"`
+	// Exemption records a formal exemption for a refused or contraindicated
+	// dose and needs at least one supporting document
+	Exemption   bool     `json:"exemption,omitempty"`
+	DocumentIDs []string `json:"document_ids,omitempty"`
 }
 
 type VaccinationFilter struct {
-	ChildID      string
-	Completed    *bool
+	ChildID string
+	// Statuses matches any of the given statuses; empty matches all
+	Statuses     []Status
 	UpcomingOnly bool
+	// ViewerID leaves out vaccinations hidden from that member when set
+	ViewerID string
+}
+
+// AntigenCoverage summarises how far a child is through the series for one vaccine
+type AntigenCoverage struct {
+	Name            string         `json:"name"`
+	DosesRequired   int            `json:"doses_required"`
+	DosesCompleted  int            `json:"doses_completed"`
+	PercentComplete float64        `json:"percent_complete"`
+	OverdueDoses    []int          `json:"overdue_doses"`
+	NextDose        *int           `json:"next_dose,omitempty"`
+	NextEligibleAt  *time.Time     `json:"next_eligible_at,omitempty"`
+	Declined        []DeclinedDose `json:"declined"`
+}
+
+// DeclinedDose is a dose deliberately not given, for footnoting coverage
+type DeclinedDose struct {
+	Dose       int        `json:"dose"`
+	Status     Status     `json:"status"`
+	ReasonCode ReasonCode `json:"reason_code,omitempty"`
+	Exemption  bool       `json:"exemption"`
+}
+
+// CoverageReport is the per-child vaccination coverage computed against the schedule
+type CoverageReport struct {
+	ChildID         string            `json:"child_id"`
+	DosesRequired   int               `json:"doses_required"`
+	DosesCompleted  int               `json:"doses_completed"`
+	PercentComplete float64           `json:"percent_complete"`
+	OverdueCount    int               `json:"overdue_count"`
+	ExemptCount     int               `json:"exempt_count"`
+	Antigens        []AntigenCoverage `json:"antigens"`
+	AsOf            *time.Time        `json:"as_of,omitempty"`
+	GeneratedAt     time.Time         `json:"generated_at"`
+	Footnotes       []string          `json:"footnotes"` // one per declined or early dose, for printing under the record
 }
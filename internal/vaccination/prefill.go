@@ -0,0 +1,100 @@
+package vaccination
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// prefillLots is how many of the child's latest lots of a vaccine are
+// compared for a lot prefix
+const prefillLots = 3
+
+// Prefill suggests administration details for a dose from the child's
+// earlier administrations. Fields are empty when there is nothing to go on.
+type Prefill struct {
+	ChildID  string `json:"child_id"`
+	Name     string `json:"name,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Location string `json:"location,omitempty"`
+	// LotPrefix is the start of the lot numbers of this vaccine given to the
+	// child before, e.g. the manufacturer code, to be completed at the clinic
+	LotPrefix string `json:"lot_prefix,omitempty"`
+	// BasedOn is the administration the provider and location came from
+	BasedOn *Vaccination `json:"based_on,omitempty"`
+}
+
+func (s *service) SuggestPrefill(ctx context.Context, childID, name string) (*Prefill, error) {
+	given, err := s.repo.List(ctx, &VaccinationFilter{ChildID: childID, Statuses: []Status{StatusCompleted}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vaccinations: %w", err)
+	}
+
+	// Newest administration first
+	sort.SliceStable(given, func(i, j int) bool {
+		return administeredAt(&given[i]).After(administeredAt(&given[j]))
+	})
+
+	prefill := &Prefill{ChildID: childID, Name: name}
+	for i := range given {
+		v := &given[i]
+		if v.Provider != "" || v.Location != "" {
+			prefill.Provider = v.Provider
+			prefill.Location = v.Location
+			prefill.BasedOn = v
+			break
+		}
+	}
+
+	if name != "" {
+		var lots []string
+		for _, v := range given {
+			if strings.EqualFold(v.Name, name) && v.LotNumber != "" {
+				lots = append(lots, strings.TrimSpace(v.LotNumber))
+			}
+			if len(lots) == prefillLots {
+				break
+			}
+		}
+		prefill.LotPrefix = lotPrefix(lots)
+	}
+
+	return prefill, nil
+}
+
+// administeredAt orders doses recorded without an administration time by
+// their scheduled time
+func administeredAt(v *Vaccination) time.Time {
+	if v.AdministeredAt != nil {
+		return *v.AdministeredAt
+	}
+	return v.ScheduledAt
+}
+
+// lotPrefix returns the prefix shared by lots, or for a single lot the
+// letters before its first digit. Lots from different manufacturers share
+// nothing and give an empty prefix.
+func lotPrefix(lots []string) string {
+	switch len(lots) {
+	case 0:
+		return ""
+	case 1:
+		if i := strings.IndexFunc(lots[0], unicode.IsDigit); i >= 0 {
+			return lots[0][:i]
+		}
+		return ""
+	}
+
+	prefix := lots[0]
+	for _, lot := range lots[1:] {
+		n := 0
+		for n < len(prefix) && n < len(lot) && prefix[n] == lot[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return prefix
+}
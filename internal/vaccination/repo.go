@@ -5,16 +5,29 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"iter"
+	"strings"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/health"
+
+	"github.com/lib/pq"
 )
 
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*Vaccination, error)
 	List(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error)
+	// Stream yields what List returns, reading rows as they are consumed
+	Stream(ctx context.Context, filter *VaccinationFilter) iter.Seq2[Vaccination, error]
 	Create(ctx context.Context, vax *Vaccination) error
+	// CreateBatch inserts many vaccinations in as few statements as possible
+	CreateBatch(ctx context.Context, vaxes []Vaccination) error
 	Update(ctx context.Context, vax *Vaccination) error
 	Delete(ctx context.Context, id string) error
 	GetUpcoming(ctx context.Context, childID string, days int) ([]Vaccination, error)
+	// MaxDose returns the highest dose recorded for the vaccine, or 0
+	MaxDose(ctx context.Context, childID, name string) (int, error)
 	GetSchedule() []VaccinationSchedule
 }
 
@@ -26,27 +39,29 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) GetByID(ctx context.Context, id string) (*Vaccination, error) {
-	query := `
-		SELECT id, child_id, name, dose, scheduled_at, administered_at,
-		       provider, location, lot_number, notes, completed, created_at, updated_at
-		FROM vaccinations
-		WHERE id = $1
-	`
+// HealthCheck checks the vaccinations table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "vaccinations")
+}
+
+const vaccinationSelect = `id, child_id, name, dose, scheduled_at, administered_at,
+		       provider, location, lot_number, notes, status, status_reason, status_changed_at,
+		       reason_code, exemption, document_ids, created_at, updated_at, interval_override, corrected_at`
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanVaccination(row rowScanner) (*Vaccination, error) {
 	var v Vaccination
-	var administeredAt sql.NullTime
-	var provider, location, lotNumber, notes sql.NullString
+	var administeredAt, statusChangedAt, correctedAt sql.NullTime
+	var provider, location, lotNumber, notes, statusReason, reasonCode, intervalOverride sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	if err := row.Scan(
 		&v.ID, &v.ChildID, &v.Name, &v.Dose, &v.ScheduledAt, &administeredAt,
-		&provider, &location, &lotNumber, &notes, &v.Completed, &v.CreatedAt, &v.UpdatedAt,
-	)
-
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, nil
-	}
-	if err != nil {
+		&provider, &location, &lotNumber, &notes, &v.Status, &statusReason, &statusChangedAt,
+		&reasonCode, &v.Exemption, pq.Array(&v.DocumentIDs), &v.CreatedAt, &v.UpdatedAt, &intervalOverride, &correctedAt,
+	); err != nil {
 		return nil, err
 	}
 
@@ -65,14 +80,43 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Vaccination, erro
 	if notes.Valid {
 		v.Notes = notes.String
 	}
+	if statusReason.Valid {
+		v.StatusReason = statusReason.String
+	}
+	if statusChangedAt.Valid {
+		v.StatusChangedAt = &statusChangedAt.Time
+	}
+	v.ReasonCode = ReasonCode(reasonCode.String)
+	v.IntervalOverride = intervalOverride.String
+	if correctedAt.Valid {
+		v.CorrectedAt = &correctedAt.Time
+	}
+	if v.DocumentIDs == nil {
+		v.DocumentIDs = []string{}
+	}
+	v.Completed = v.Status == StatusCompleted
 
 	return &v, nil
 }
 
-func (r *repository) List(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error) {
+func (r *repository) GetByID(ctx context.Context, id string) (*Vaccination, error) {
 	query := `
-		SELECT id, child_id, name, dose, scheduled_at, administered_at,
-		       provider, location, lot_number, notes, completed, created_at, updated_at
+		SELECT ` + vaccinationSelect + `
+		FROM vaccinations
+		WHERE id = $1
+	`
+
+	v, err := scanVaccination(db.Use(ctx, r.db).QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return v, err
+}
+
+// filterQuery selects the vaccinations matching filter, in schedule order
+func filterQuery(filter *VaccinationFilter) (string, []any) {
+	query := `
+		SELECT ` + vaccinationSelect + `
 		FROM vaccinations
 		WHERE 1=1
 	`
@@ -85,69 +129,61 @@ func (r *repository) List(ctx context.Context, filter *VaccinationFilter) ([]Vac
 		argIndex++
 	}
 
-	if filter.Completed != nil {
-		query += fmt.Sprintf(` AND completed = $%d`, argIndex)
-		args = append(args, *filter.Completed)
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, st := range filter.Statuses {
+			statuses[i] = string(st)
+		}
+		query += fmt.Sprintf(` AND status = ANY($%d)`, argIndex)
+		args = append(args, pq.Array(statuses))
 		argIndex++
 	}
 
 	if filter.UpcomingOnly {
-		query += fmt.Sprintf(` AND completed = false AND scheduled_at >= $%d`, argIndex)
+		query += fmt.Sprintf(` AND status = 'scheduled' AND scheduled_at >= $%d`, argIndex)
 		args = append(args, time.Now().Truncate(24*time.Hour))
 	}
 
-	query += ` ORDER BY scheduled_at ASC`
+	return query + ` ORDER BY scheduled_at ASC`, args
+}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+func (r *repository) List(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error) {
+	query, args := filterQuery(filter)
+	rows, err := db.Use(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close() //nolint:errcheck // Best-effort close
 
-	var vaccinations []Vaccination
+	vaccinations := []Vaccination{}
 	for rows.Next() {
-		var v Vaccination
-		var administeredAt sql.NullTime
-		var provider, location, lotNumber, notes sql.NullString
-
-		if err := rows.Scan(
-			&v.ID, &v.ChildID, &v.Name, &v.Dose, &v.ScheduledAt, &administeredAt,
-			&provider, &location, &lotNumber, &notes, &v.Completed, &v.CreatedAt, &v.UpdatedAt,
-		); err != nil {
+		v, err := scanVaccination(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		if administeredAt.Valid {
-			v.AdministeredAt = &administeredAt.Time
-		}
-		if provider.Valid {
-			v.Provider = provider.String
-		}
-		if location.Valid {
-			v.Location = location.String
-		}
-		if lotNumber.Valid {
-			v.LotNumber = lotNumber.String
-		}
-		if notes.Valid {
-			v.Notes = notes.String
-		}
-
-		vaccinations = append(vaccinations, v)
-	}
-
-	if vaccinations == nil {
-		return []Vaccination{}, nil
+		vaccinations = append(vaccinations, *v)
 	}
 
 	return vaccinations, rows.Err()
 }
 
+func (r *repository) Stream(ctx context.Context, filter *VaccinationFilter) iter.Seq2[Vaccination, error] {
+	query, args := filterQuery(filter)
+	return db.Stream(ctx, db.Use(ctx, r.db), func(rows *sql.Rows) (Vaccination, error) {
+		v, err := scanVaccination(rows)
+		if err != nil {
+			return Vaccination{}, err
+		}
+		return *v, nil
+	}, query, args...)
+}
+
 func (r *repository) Create(ctx context.Context, vax *Vaccination) error {
 	query := `
 		INSERT INTO vaccinations (id, child_id, name, dose, scheduled_at, administered_at,
-		                          provider, location, lot_number, notes, completed, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		                          provider, location, lot_number, notes, status, status_reason, status_changed_at,
+		                          reason_code, exemption, document_ids, created_at, updated_at, interval_override)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
 
 	var provider, location, lotNumber, notes *string
@@ -164,20 +200,110 @@ func (r *repository) Create(ctx context.Context, vax *Vaccination) error {
 		notes = &vax.Notes
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query,
 		vax.ID, vax.ChildID, vax.Name, vax.Dose, vax.ScheduledAt, vax.AdministeredAt,
-		provider, location, lotNumber, notes, vax.Completed, vax.CreatedAt, vax.UpdatedAt,
+		provider, location, lotNumber, notes, vax.Status, nullIfEmpty(vax.StatusReason), vax.StatusChangedAt,
+		nullIfEmpty(string(vax.ReasonCode)), vax.Exemption, pq.Array(documentIDs(vax)), vax.CreatedAt, vax.UpdatedAt,
+		nullIfEmpty(vax.IntervalOverride),
 	)
 
 	return err
 }
 
+// insertColumns is the number of values bound per row by CreateBatch
+const insertColumns = 19
+
+// batchRows keeps a multi-row insert under PostgreSQL's limit of 65535 bind
+// parameters per statement
+const batchRows = 65535 / insertColumns
+
+// CreateBatch writes the vaccinations with one multi-row INSERT per
+// batchRows rows. Callers wanting all-or-nothing across batches should run
+// it inside a transaction.
+func (r *repository) CreateBatch(ctx context.Context, vaxes []Vaccination) error {
+	for start := 0; start < len(vaxes); start += batchRows {
+		end := min(start+batchRows, len(vaxes))
+		if err := r.insertRows(ctx, vaxes[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *repository) insertRows(ctx context.Context, vaxes []Vaccination) error {
+	var query strings.Builder
+	query.WriteString(`
+		INSERT INTO vaccinations (id, child_id, name, dose, scheduled_at, administered_at,
+		                          provider, location, lot_number, notes, status, status_reason, status_changed_at,
+		                          reason_code, exemption, document_ids, created_at, updated_at, interval_override)
+		VALUES `)
+
+	args := make([]any, 0, len(vaxes)*insertColumns)
+	for i := range vaxes {
+		vax := &vaxes[i]
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for c := 1; c <= insertColumns; c++ {
+			if c > 1 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", len(args)+c)
+		}
+		query.WriteString(")")
+
+		args = append(args,
+			vax.ID, vax.ChildID, vax.Name, vax.Dose, vax.ScheduledAt, vax.AdministeredAt,
+			nullIfEmpty(vax.Provider), nullIfEmpty(vax.Location), nullIfEmpty(vax.LotNumber), nullIfEmpty(vax.Notes),
+			vax.Status, nullIfEmpty(vax.StatusReason), vax.StatusChangedAt,
+			nullIfEmpty(string(vax.ReasonCode)), vax.Exemption, pq.Array(documentIDs(vax)), vax.CreatedAt, vax.UpdatedAt,
+			nullIfEmpty(vax.IntervalOverride),
+		)
+	}
+
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query.String(), args...)
+	return err
+}
+
+// documentIDs never returns nil, which pq would encode as NULL for a NOT NULL
+// column
+func documentIDs(vax *Vaccination) []string {
+	if vax.DocumentIDs == nil {
+		return []string{}
+	}
+	return vax.DocumentIDs
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// MaxDose matches the vaccine name case-insensitively so "dtap" and "DTaP"
+// count as the same series
+func (r *repository) MaxDose(ctx context.Context, childID, name string) (int, error) {
+	query := `
+		SELECT COALESCE(MAX(dose), 0)
+		FROM vaccinations
+		WHERE child_id = $1 AND LOWER(name) = LOWER($2)
+	`
+
+	var dose int
+	err := db.Use(ctx, r.db).QueryRowContext(ctx, query, childID, name).Scan(&dose)
+	return dose, err
+}
+
 func (r *repository) Update(ctx context.Context, vax *Vaccination) error {
 	query := `
 		UPDATE vaccinations
 		SET name = $2, dose = $3, scheduled_at = $4, administered_at = $5,
 		    provider = $6, location = $7, lot_number = $8, notes = $9,
-		    completed = $10, updated_at = $11
+		    status = $10, status_reason = $11, status_changed_at = $12,
+		    reason_code = $13, exemption = $14, document_ids = $15, updated_at = $16,
+		    interval_override = $17, corrected_at = $18
 		WHERE id = $1
 	`
 
@@ -195,9 +321,11 @@ func (r *repository) Update(ctx context.Context, vax *Vaccination) error {
 		notes = &vax.Notes
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query,
 		vax.ID, vax.Name, vax.Dose, vax.ScheduledAt, vax.AdministeredAt,
-		provider, location, lotNumber, notes, vax.Completed, vax.UpdatedAt,
+		provider, location, lotNumber, notes, vax.Status, nullIfEmpty(vax.StatusReason), vax.StatusChangedAt,
+		nullIfEmpty(string(vax.ReasonCode)), vax.Exemption, pq.Array(documentIDs(vax)), vax.UpdatedAt,
+		nullIfEmpty(vax.IntervalOverride), vax.CorrectedAt,
 	)
 
 	return err
@@ -205,17 +333,18 @@ func (r *repository) Update(ctx context.Context, vax *Vaccination) error {
 
 func (r *repository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM vaccinations WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := db.Use(ctx, r.db).ExecContext(ctx, query, id)
 	return err
 }
 
 func (r *repository) GetUpcoming(ctx context.Context, childID string, days int) ([]Vaccination, error) {
 	query := `
 		SELECT id, child_id, name, dose, scheduled_at, administered_at,
-		       provider, location, lot_number, notes, completed, created_at, updated_at
+		       provider, location, lot_number, notes, status, status_reason, status_changed_at,
+		       reason_code, exemption, document_ids, created_at, updated_at, interval_override, corrected_at
 		FROM vaccinations
 		WHERE child_id = $1
-		  AND completed = false
+		  AND status = 'scheduled'
 		  AND scheduled_at >= $2
 		  AND scheduled_at <= $3
 		ORDER BY scheduled_at ASC
@@ -224,7 +353,7 @@ func (r *repository) GetUpcoming(ctx context.Context, childID string, days int)
 	now := time.Now().Truncate(24 * time.Hour)
 	endDate := now.AddDate(0, 0, days)
 
-	rows, err := r.db.QueryContext(ctx, query, childID, now, endDate)
+	rows, err := db.Use(ctx, r.db).QueryContext(ctx, query, childID, now, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -233,12 +362,13 @@ func (r *repository) GetUpcoming(ctx context.Context, childID string, days int)
 	var vaccinations []Vaccination
 	for rows.Next() {
 		var v Vaccination
-		var administeredAt sql.NullTime
-		var provider, location, lotNumber, notes sql.NullString
+		var administeredAt, statusChangedAt, correctedAt sql.NullTime
+		var provider, location, lotNumber, notes, statusReason, reasonCode, intervalOverride sql.NullString
 
 		if err := rows.Scan(
 			&v.ID, &v.ChildID, &v.Name, &v.Dose, &v.ScheduledAt, &administeredAt,
-			&provider, &location, &lotNumber, &notes, &v.Completed, &v.CreatedAt, &v.UpdatedAt,
+			&provider, &location, &lotNumber, &notes, &v.Status, &statusReason, &statusChangedAt,
+			&reasonCode, &v.Exemption, pq.Array(&v.DocumentIDs), &v.CreatedAt, &v.UpdatedAt, &intervalOverride, &correctedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -258,6 +388,21 @@ func (r *repository) GetUpcoming(ctx context.Context, childID string, days int)
 		if notes.Valid {
 			v.Notes = notes.String
 		}
+		if statusReason.Valid {
+			v.StatusReason = statusReason.String
+		}
+		if statusChangedAt.Valid {
+			v.StatusChangedAt = &statusChangedAt.Time
+		}
+		v.ReasonCode = ReasonCode(reasonCode.String)
+		v.IntervalOverride = intervalOverride.String
+		if correctedAt.Valid {
+			v.CorrectedAt = &correctedAt.Time
+		}
+		if v.DocumentIDs == nil {
+			v.DocumentIDs = []string{}
+		}
+		v.Completed = v.Status == StatusCompleted
 
 		vaccinations = append(vaccinations, v)
 	}
@@ -270,7 +415,8 @@ func (r *repository) GetUpcoming(ctx context.Context, childID string, days int)
 }
 
 func (r *repository) GetSchedule() []VaccinationSchedule {
-	// Kenya Expanded Program on Immunisation (EPI) schedule
+	// Kenya Expanded Program on Immunisation (EPI) schedule. Minimum ages and
+	// intervals are the WHO minimums for each series.
 	return []VaccinationSchedule{
 		// Birth
 		{ID: "bcg-1", Name: "BCG", Description: "Bacillus Calmette-Guérin (Tuberculosis)", AgeWeeks: 0, AgeMonths: 0, AgeLabel: "Birth", Dose: 1},
@@ -278,35 +424,35 @@ func (r *repository) GetSchedule() []VaccinationSchedule {
 		{ID: "hepb-1", Name: "Hepatitis B", Description: "Birth dose", AgeWeeks: 0, AgeMonths: 0, AgeLabel: "Birth", Dose: 1},
 
 		// 6 weeks
-		{ID: "penta-1", Name: "Pentavalent", Description: "DPT-HepB-Hib - First dose", AgeWeeks: 6, AgeMonths: 1, AgeLabel: "6 weeks", Dose: 1},
-		{ID: "opv-1", Name: "OPV", Description: "Oral Polio Vaccine - First dose", AgeWeeks: 6, AgeMonths: 1, AgeLabel: "6 weeks", Dose: 1},
-		{ID: "pcv-1", Name: "PCV", Description: "Pneumococcal - First dose", AgeWeeks: 6, AgeMonths: 1, AgeLabel: "6 weeks", Dose: 1},
-		{ID: "rv-1", Name: "Rotavirus", Description: "First dose", AgeWeeks: 6, AgeMonths: 1, AgeLabel: "6 weeks", Dose: 1},
+		{ID: "penta-1", Name: "Pentavalent", Description: "DPT-HepB-Hib - First dose", AgeWeeks: 6, AgeMonths: 1, AgeLabel: "6 weeks", Dose: 1, MinAgeWeeks: 6},
+		{ID: "opv-1", Name: "OPV", Description: "Oral Polio Vaccine - First dose", AgeWeeks: 6, AgeMonths: 1, AgeLabel: "6 weeks", Dose: 1, MinAgeWeeks: 6, MinIntervalWeeks: 4},
+		{ID: "pcv-1", Name: "PCV", Description: "Pneumococcal - First dose", AgeWeeks: 6, AgeMonths: 1, AgeLabel: "6 weeks", Dose: 1, MinAgeWeeks: 6},
+		{ID: "rv-1", Name: "Rotavirus", Description: "First dose", AgeWeeks: 6, AgeMonths: 1, AgeLabel: "6 weeks", Dose: 1, MinAgeWeeks: 6},
 
 		// 10 weeks
-		{ID: "penta-2", Name: "Pentavalent", Description: "DPT-HepB-Hib - Second dose", AgeWeeks: 10, AgeMonths: 2, AgeLabel: "10 weeks", Dose: 2},
-		{ID: "opv-2", Name: "OPV", Description: "Oral Polio Vaccine - Second dose", AgeWeeks: 10, AgeMonths: 2, AgeLabel: "10 weeks", Dose: 2},
-		{ID: "pcv-2", Name: "PCV", Description: "Pneumococcal - Second dose", AgeWeeks: 10, AgeMonths: 2, AgeLabel: "10 weeks", Dose: 2},
-		{ID: "rv-2", Name: "Rotavirus", Description: "Second dose", AgeWeeks: 10, AgeMonths: 2, AgeLabel: "10 weeks", Dose: 2},
+		{ID: "penta-2", Name: "Pentavalent", Description: "DPT-HepB-Hib - Second dose", AgeWeeks: 10, AgeMonths: 2, AgeLabel: "10 weeks", Dose: 2, MinAgeWeeks: 10, MinIntervalWeeks: 4},
+		{ID: "opv-2", Name: "OPV", Description: "Oral Polio Vaccine - Second dose", AgeWeeks: 10, AgeMonths: 2, AgeLabel: "10 weeks", Dose: 2, MinAgeWeeks: 10, MinIntervalWeeks: 4},
+		{ID: "pcv-2", Name: "PCV", Description: "Pneumococcal - Second dose", AgeWeeks: 10, AgeMonths: 2, AgeLabel: "10 weeks", Dose: 2, MinAgeWeeks: 10, MinIntervalWeeks: 4},
+		{ID: "rv-2", Name: "Rotavirus", Description: "Second dose", AgeWeeks: 10, AgeMonths: 2, AgeLabel: "10 weeks", Dose: 2, MinAgeWeeks: 10, MinIntervalWeeks: 4},
 
 		// 14 weeks
-		{ID: "penta-3", Name: "Pentavalent", Description: "DPT-HepB-Hib - Third dose", AgeWeeks: 14, AgeMonths: 3, AgeLabel: "14 weeks", Dose: 3},
-		{ID: "opv-3", Name: "OPV", Description: "Oral Polio Vaccine - Third dose", AgeWeeks: 14, AgeMonths: 3, AgeLabel: "14 weeks", Dose: 3},
-		{ID: "ipv-1", Name: "IPV", Description: "Inactivated Polio Vaccine", AgeWeeks: 14, AgeMonths: 3, AgeLabel: "14 weeks", Dose: 1},
-		{ID: "pcv-3", Name: "PCV", Description: "Pneumococcal - Third dose", AgeWeeks: 14, AgeMonths: 3, AgeLabel: "14 weeks", Dose: 3},
+		{ID: "penta-3", Name: "Pentavalent", Description: "DPT-HepB-Hib - Third dose", AgeWeeks: 14, AgeMonths: 3, AgeLabel: "14 weeks", Dose: 3, MinAgeWeeks: 14, MinIntervalWeeks: 4},
+		{ID: "opv-3", Name: "OPV", Description: "Oral Polio Vaccine - Third dose", AgeWeeks: 14, AgeMonths: 3, AgeLabel: "14 weeks", Dose: 3, MinAgeWeeks: 14, MinIntervalWeeks: 4},
+		{ID: "ipv-1", Name: "IPV", Description: "Inactivated Polio Vaccine", AgeWeeks: 14, AgeMonths: 3, AgeLabel: "14 weeks", Dose: 1, MinAgeWeeks: 14},
+		{ID: "pcv-3", Name: "PCV", Description: "Pneumococcal - Third dose", AgeWeeks: 14, AgeMonths: 3, AgeLabel: "14 weeks", Dose: 3, MinAgeWeeks: 14, MinIntervalWeeks: 4},
 
 		// 6 months
-		{ID: "vita-1", Name: "Vitamin A", Description: "First supplement", AgeWeeks: 26, AgeMonths: 6, AgeLabel: "6 months", Dose: 1},
+		{ID: "vita-1", Name: "Vitamin A", Description: "First supplement", AgeWeeks: 26, AgeMonths: 6, AgeLabel: "6 months", Dose: 1, MinAgeWeeks: 26},
 
 		// 9 months
-		{ID: "mr-1", Name: "Measles-Rubella", Description: "MR - First dose", AgeWeeks: 39, AgeMonths: 9, AgeLabel: "9 months", Dose: 1},
-		{ID: "yellow-fever-1", Name: "Yellow Fever", Description: "Single dose (endemic areas)", AgeWeeks: 39, AgeMonths: 9, AgeLabel: "9 months", Dose: 1},
+		{ID: "mr-1", Name: "Measles-Rubella", Description: "MR - First dose", AgeWeeks: 39, AgeMonths: 9, AgeLabel: "9 months", Dose: 1, MinAgeWeeks: 39},
+		{ID: "yellow-fever-1", Name: "Yellow Fever", Description: "Single dose (endemic areas)", AgeWeeks: 39, AgeMonths: 9, AgeLabel: "9 months", Dose: 1, MinAgeWeeks: 39},
 
 		// 12 months
-		{ID: "vita-2", Name: "Vitamin A", Description: "Second supplement", AgeWeeks: 52, AgeMonths: 12, AgeLabel: "12 months", Dose: 2},
+		{ID: "vita-2", Name: "Vitamin A", Description: "Second supplement", AgeWeeks: 52, AgeMonths: 12, AgeLabel: "12 months", Dose: 2, MinAgeWeeks: 43, MinIntervalWeeks: 17},
 
 		// 18 months
-		{ID: "mr-2", Name: "Measles-Rubella", Description: "MR - Second dose", AgeWeeks: 78, AgeMonths: 18, AgeLabel: "18 months", Dose: 2},
-		{ID: "vita-3", Name: "Vitamin A", Description: "Third supplement", AgeWeeks: 78, AgeMonths: 18, AgeLabel: "18 months", Dose: 3},
+		{ID: "mr-2", Name: "Measles-Rubella", Description: "MR - Second dose", AgeWeeks: 78, AgeMonths: 18, AgeLabel: "18 months", Dose: 2, MinAgeWeeks: 43, MinIntervalWeeks: 4},
+		{ID: "vita-3", Name: "Vitamin A", Description: "Third supplement", AgeWeeks: 78, AgeMonths: 18, AgeLabel: "18 months", Dose: 3, MinAgeWeeks: 60, MinIntervalWeeks: 17},
 	}
 }
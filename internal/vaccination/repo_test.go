@@ -4,10 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"regexp"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 )
 
 func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
@@ -21,7 +25,8 @@ func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
 
 var vaccinationColumns = []string{
 	"id", "child_id", "name", "dose", "scheduled_at", "administered_at",
-	"provider", "location", "lot_number", "notes", "completed", "created_at", "updated_at",
+	"provider", "location", "lot_number", "notes", "status", "status_reason", "status_changed_at",
+	"reason_code", "exemption", "document_ids", "created_at", "updated_at", "interval_override", "corrected_at",
 }
 
 // =============================================================================
@@ -37,7 +42,7 @@ func TestRepository_GetByID(t *testing.T) {
 	administeredAt := now.Add(-time.Hour)
 	rows := sqlmock.NewRows(vaccinationColumns).
 		AddRow("vax-123", "child-456", "BCG", 1, now, administeredAt,
-			"Dr. Smith", "City Hospital", "LOT123", "First dose given", true, now, now)
+			"Dr. Smith", "City Hospital", "LOT123", "First dose given", "completed", nil, now, nil, false, "{}", now, now, nil, nil)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dose, scheduled_at, administered_at").
 		WithArgs("vax-123").
@@ -135,6 +140,33 @@ func TestRepository_GetByID_Error(t *testing.T) {
 	}
 }
 
+func TestRepository_GetByID_Exemption(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(vaccinationColumns).
+		AddRow("vax-123", "child-456", "MMR", 1, now, nil,
+			nil, nil, nil, nil, "contraindicated", "egg allergy", now, "allergy", true, "{media-1,media-2}", now, now, nil, nil)
+
+	mock.ExpectQuery("SELECT id, child_id, name, dose, scheduled_at, administered_at").
+		WithArgs("vax-123").
+		WillReturnRows(rows)
+
+	vax, err := repo.GetByID(context.Background(), "vax-123")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+
+	if vax.Status != StatusContraindicated || vax.ReasonCode != ReasonAllergy || !vax.Exemption {
+		t.Errorf("GetByID() = %s/%s exemption %v", vax.Status, vax.ReasonCode, vax.Exemption)
+	}
+	if !slices.Equal(vax.DocumentIDs, []string{"media-1", "media-2"}) {
+		t.Errorf("GetByID() DocumentIDs = %v", vax.DocumentIDs)
+	}
+}
+
 func TestRepository_GetByID_NullOptionalFields(t *testing.T) {
 	db, mock := newMockDB(t)
 	defer db.Close()
@@ -143,7 +175,7 @@ func TestRepository_GetByID_NullOptionalFields(t *testing.T) {
 	now := time.Now()
 	rows := sqlmock.NewRows(vaccinationColumns).
 		AddRow("vax-123", "child-456", "BCG", 1, now, nil,
-			nil, nil, nil, nil, false, now, now)
+			nil, nil, nil, nil, "scheduled", nil, nil, nil, false, "{}", now, now, nil, nil)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dose, scheduled_at, administered_at").
 		WithArgs("vax-123").
@@ -192,9 +224,9 @@ func TestRepository_List(t *testing.T) {
 	administeredAt := now.Add(-time.Hour)
 	rows := sqlmock.NewRows(vaccinationColumns).
 		AddRow("vax-1", "child-456", "BCG", 1, now, administeredAt,
-			"Dr. Smith", "City Hospital", "LOT123", "First dose", true, now, now).
+			"Dr. Smith", "City Hospital", "LOT123", "First dose", "completed", nil, now, nil, false, "{}", now, now, nil, nil).
 		AddRow("vax-2", "child-456", "OPV", 1, now.Add(24*time.Hour), nil,
-			nil, nil, nil, nil, false, now, now)
+			nil, nil, nil, nil, "scheduled", nil, nil, nil, false, "{}", now, now, nil, nil)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dose, scheduled_at, administered_at").
 		WithArgs("child-456").
@@ -223,7 +255,38 @@ func TestRepository_List(t *testing.T) {
 	}
 }
 
-func TestRepository_List_WithCompletedFilter(t *testing.T) {
+func TestRepository_Stream(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(vaccinationColumns).
+		AddRow("vax-1", "child-456", "BCG", 1, now, now,
+			nil, nil, nil, nil, "completed", nil, now, nil, false, "{doc-1}", now, now, nil, nil).
+		AddRow("vax-2", "child-456", "OPV", 1, now.Add(24*time.Hour), nil,
+			nil, nil, nil, nil, "scheduled", nil, nil, nil, false, "{}", now, now, nil, nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM vaccinations WHERE 1=1 AND child_id = \\$1 ORDER BY scheduled_at ASC").
+		WithArgs("child-456").
+		WillReturnRows(rows)
+
+	var got []Vaccination
+	for v, err := range repo.Stream(context.Background(), &VaccinationFilter{ChildID: "child-456"}) {
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		got = append(got, v)
+	}
+	if len(got) != 2 || !got[0].Completed || len(got[0].DocumentIDs) != 1 || got[1].Name != "OPV" {
+		t.Errorf("Stream() = %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_List_WithStatusFilter(t *testing.T) {
 	db, mock := newMockDB(t)
 	defer db.Close()
 	repo := NewRepository(db)
@@ -231,14 +294,13 @@ func TestRepository_List_WithCompletedFilter(t *testing.T) {
 	now := time.Now()
 	rows := sqlmock.NewRows(vaccinationColumns).
 		AddRow("vax-1", "child-456", "BCG", 1, now, now,
-			"Dr. Smith", "City Hospital", "LOT123", "Done", true, now, now)
+			"Dr. Smith", "City Hospital", "LOT123", "Done", "completed", nil, now, nil, false, "{}", now, now, nil, nil)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dose, scheduled_at, administered_at").
-		WithArgs("child-456", true).
+		WithArgs("child-456", pq.Array([]string{"completed"})).
 		WillReturnRows(rows)
 
-	completed := true
-	filter := &VaccinationFilter{ChildID: "child-456", Completed: &completed}
+	filter := &VaccinationFilter{ChildID: "child-456", Statuses: []Status{StatusCompleted}}
 	vaccinations, err := repo.List(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("List() error = %v", err)
@@ -249,7 +311,7 @@ func TestRepository_List_WithCompletedFilter(t *testing.T) {
 	}
 
 	if !vaccinations[0].Completed {
-		t.Error("List() with Completed filter should return only completed vaccinations")
+		t.Error("List() with status filter should return only completed vaccinations")
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -266,7 +328,7 @@ func TestRepository_List_UpcomingOnly(t *testing.T) {
 	scheduledAt := now.Add(7 * 24 * time.Hour)
 	rows := sqlmock.NewRows(vaccinationColumns).
 		AddRow("vax-1", "child-456", "Pentavalent", 2, scheduledAt, nil,
-			nil, nil, nil, nil, false, now, now)
+			nil, nil, nil, nil, "scheduled", nil, nil, nil, false, "{}", now, now, nil, nil)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dose, scheduled_at, administered_at").
 		WithArgs("child-456", sqlmock.AnyArg()).
@@ -384,14 +446,14 @@ func TestRepository_Create(t *testing.T) {
 		Location:       "City Hospital",
 		LotNumber:      "LOT123",
 		Notes:          "Administered successfully",
-		Completed:      true,
+		Status:         StatusCompleted,
 		CreatedAt:      now,
 		UpdatedAt:      now,
 	}
 
 	mock.ExpectExec("INSERT INTO vaccinations").
 		WithArgs(vax.ID, vax.ChildID, vax.Name, vax.Dose, vax.ScheduledAt, vax.AdministeredAt,
-			&vax.Provider, &vax.Location, &vax.LotNumber, &vax.Notes, vax.Completed, vax.CreatedAt, vax.UpdatedAt).
+			&vax.Provider, &vax.Location, &vax.LotNumber, &vax.Notes, vax.Status, nil, nil, nil, false, pq.Array([]string{}), vax.CreatedAt, vax.UpdatedAt, nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), vax)
@@ -416,14 +478,14 @@ func TestRepository_Create_NoOptionalFields(t *testing.T) {
 		Name:        "BCG",
 		Dose:        1,
 		ScheduledAt: now,
-		Completed:   false,
+		Status:      StatusScheduled,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 
 	mock.ExpectExec("INSERT INTO vaccinations").
 		WithArgs(vax.ID, vax.ChildID, vax.Name, vax.Dose, vax.ScheduledAt, nil,
-			nil, nil, nil, nil, vax.Completed, vax.CreatedAt, vax.UpdatedAt).
+			nil, nil, nil, nil, vax.Status, nil, nil, nil, false, pq.Array([]string{}), vax.CreatedAt, vax.UpdatedAt, nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), vax)
@@ -436,6 +498,56 @@ func TestRepository_Create_NoOptionalFields(t *testing.T) {
 	}
 }
 
+func TestRepository_CreateBatch(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	vaxes := []Vaccination{
+		{ID: "vax-1", ChildID: "child-123", Name: "Hepatitis B", Dose: 1, ScheduledAt: now, Status: StatusScheduled, CreatedAt: now, UpdatedAt: now},
+		{ID: "vax-2", ChildID: "child-123", Name: "DTaP", Dose: 1, ScheduledAt: now, Provider: "Clinic", Status: StatusScheduled, CreatedAt: now, UpdatedAt: now},
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19), ($20,")).
+		WithArgs(
+			"vax-1", "child-123", "Hepatitis B", 1, now, nil, nil, nil, nil, nil, StatusScheduled, nil, nil, nil, false, pq.Array([]string{}), now, now, nil,
+			"vax-2", "child-123", "DTaP", 1, now, nil, &vaxes[1].Provider, nil, nil, nil, StatusScheduled, nil, nil, nil, false, pq.Array([]string{}), now, now, nil,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := repo.CreateBatch(context.Background(), vaxes); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_CreateBatch_SplitsLargeBatches(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	now := time.Now()
+	vaxes := make([]Vaccination, batchRows+1)
+	for i := range vaxes {
+		vaxes[i] = Vaccination{ID: fmt.Sprintf("vax-%d", i), ChildID: "child-123", Name: "DTaP", ScheduledAt: now}
+	}
+
+	mock.ExpectExec("INSERT INTO vaccinations").WillReturnResult(sqlmock.NewResult(0, batchRows))
+	mock.ExpectExec("INSERT INTO vaccinations").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.CreateBatch(context.Background(), vaxes); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestRepository_Create_Error(t *testing.T) {
 	db, mock := newMockDB(t)
 	defer db.Close()
@@ -454,7 +566,7 @@ func TestRepository_Create_Error(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO vaccinations").
 		WithArgs(vax.ID, vax.ChildID, vax.Name, vax.Dose, vax.ScheduledAt, nil,
-			nil, nil, nil, nil, vax.Completed, vax.CreatedAt, vax.UpdatedAt).
+			nil, nil, nil, nil, vax.Status, nil, nil, nil, false, pq.Array([]string{}), vax.CreatedAt, vax.UpdatedAt, nil).
 		WillReturnError(errors.New("duplicate key"))
 
 	err := repo.Create(context.Background(), vax)
@@ -471,6 +583,28 @@ func TestRepository_Create_Error(t *testing.T) {
 // Update Tests
 // =============================================================================
 
+func TestRepository_MaxDose(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(dose\\), 0\\)").
+		WithArgs("child-123", "DTaP").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(2))
+
+	dose, err := repo.MaxDose(context.Background(), "child-123", "DTaP")
+	if err != nil {
+		t.Fatalf("MaxDose() error = %v", err)
+	}
+	if dose != 2 {
+		t.Errorf("MaxDose() = %d, want 2", dose)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestRepository_Update(t *testing.T) {
 	db, mock := newMockDB(t)
 	defer db.Close()
@@ -488,13 +622,13 @@ func TestRepository_Update(t *testing.T) {
 		Location:       "County Clinic",
 		LotNumber:      "LOT456",
 		Notes:          "Updated notes",
-		Completed:      true,
+		Status:         StatusCompleted,
 		UpdatedAt:      now,
 	}
 
 	mock.ExpectExec("UPDATE vaccinations SET name").
 		WithArgs(vax.ID, vax.Name, vax.Dose, vax.ScheduledAt, vax.AdministeredAt,
-			&vax.Provider, &vax.Location, &vax.LotNumber, &vax.Notes, vax.Completed, vax.UpdatedAt).
+			&vax.Provider, &vax.Location, &vax.LotNumber, &vax.Notes, vax.Status, nil, nil, nil, false, pq.Array([]string{}), vax.UpdatedAt, nil, nil).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), vax)
@@ -518,13 +652,13 @@ func TestRepository_Update_NoOptionalFields(t *testing.T) {
 		Name:        "Updated Vax",
 		Dose:        1,
 		ScheduledAt: now,
-		Completed:   false,
+		Status:      StatusScheduled,
 		UpdatedAt:   now,
 	}
 
 	mock.ExpectExec("UPDATE vaccinations SET name").
 		WithArgs(vax.ID, vax.Name, vax.Dose, vax.ScheduledAt, nil,
-			nil, nil, nil, nil, vax.Completed, vax.UpdatedAt).
+			nil, nil, nil, nil, vax.Status, nil, nil, nil, false, pq.Array([]string{}), vax.UpdatedAt, nil, nil).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), vax)
@@ -553,13 +687,13 @@ func TestRepository_Update_MarkCompleted(t *testing.T) {
 		Provider:       "Dr. Smith",
 		Location:       "City Hospital",
 		LotNumber:      "LOT789",
-		Completed:      true,
+		Status:         StatusCompleted,
 		UpdatedAt:      now,
 	}
 
 	mock.ExpectExec("UPDATE vaccinations SET name").
 		WithArgs(vax.ID, vax.Name, vax.Dose, vax.ScheduledAt, vax.AdministeredAt,
-			&vax.Provider, &vax.Location, &vax.LotNumber, nil, vax.Completed, vax.UpdatedAt).
+			&vax.Provider, &vax.Location, &vax.LotNumber, nil, vax.Status, nil, nil, nil, false, pq.Array([]string{}), vax.UpdatedAt, nil, nil).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := repo.Update(context.Background(), vax)
@@ -588,7 +722,7 @@ func TestRepository_Update_Error(t *testing.T) {
 
 	mock.ExpectExec("UPDATE vaccinations SET name").
 		WithArgs(vax.ID, vax.Name, vax.Dose, vax.ScheduledAt, nil,
-			nil, nil, nil, nil, vax.Completed, vax.UpdatedAt).
+			nil, nil, nil, nil, vax.Status, nil, nil, nil, false, pq.Array([]string{}), vax.UpdatedAt, nil, nil).
 		WillReturnError(errors.New("database error"))
 
 	err := repo.Update(context.Background(), vax)
@@ -656,9 +790,9 @@ func TestRepository_GetUpcoming(t *testing.T) {
 	scheduledAt := now.Add(7 * 24 * time.Hour)
 	rows := sqlmock.NewRows(vaccinationColumns).
 		AddRow("vax-1", "child-456", "Pentavalent", 2, scheduledAt, nil,
-			nil, nil, nil, nil, false, now, now).
+			nil, nil, nil, nil, "scheduled", nil, nil, nil, false, "{}", now, now, nil, nil).
 		AddRow("vax-2", "child-456", "PCV", 2, scheduledAt.Add(24*time.Hour), nil,
-			nil, nil, nil, nil, false, now, now)
+			nil, nil, nil, nil, "scheduled", nil, nil, nil, false, "{}", now, now, nil, nil)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dose, scheduled_at, administered_at").
 		WithArgs("child-456", sqlmock.AnyArg(), sqlmock.AnyArg()).
@@ -773,7 +907,7 @@ func TestRepository_GetUpcoming_WithOptionalFields(t *testing.T) {
 	scheduledAt := now.Add(7 * 24 * time.Hour)
 	rows := sqlmock.NewRows(vaccinationColumns).
 		AddRow("vax-1", "child-456", "BCG", 1, scheduledAt, nil,
-			"Dr. Smith", "City Hospital", nil, "Scheduled appointment", false, now, now)
+			"Dr. Smith", "City Hospital", nil, "Scheduled appointment", "scheduled", nil, nil, nil, false, "{}", now, now, nil, nil)
 
 	mock.ExpectQuery("SELECT id, child_id, name, dose, scheduled_at, administered_at").
 		WithArgs("child-456", sqlmock.AnyArg(), sqlmock.AnyArg()).
@@ -2,42 +2,127 @@ package vaccination
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"iter"
+	"math"
+	"strings"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/db"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+	"github.com/ninenine/babytrack/internal/ids"
+	"github.com/ninenine/babytrack/internal/limits"
+	"github.com/ninenine/babytrack/internal/occurrence"
+	"github.com/ninenine/babytrack/internal/visibility"
+	"github.com/ninenine/babytrack/internal/warnings"
+)
+
+var (
+	ErrNotFound          = errors.New("vaccination not found")
+	ErrInvalidStatus     = errors.New("invalid vaccination status")
+	ErrInvalidTransition = errors.New("vaccination status change not allowed")
+	ErrReasonRequired    = errors.New("a reason is required for refused or contraindicated doses")
+	ErrInvalidReasonCode = errors.New("invalid reason code")
+	ErrInvalidExemption  = errors.New("an exemption needs a refused or contraindicated dose and a supporting document")
+	ErrDoseTooEarly      = errors.New("dose given before its minimum age or interval")
+	// ErrCorrectionRequired is returned for edits to an administered dose,
+	// which must go through Correct so the original is kept
+	ErrCorrectionRequired = errors.New("an administered dose can only be changed by a correction")
+	ErrNotAdministered    = errors.New("only administered doses can be corrected")
 )
 
 type Service interface {
 	Create(ctx context.Context, req *CreateVaccinationRequest) (*Vaccination, error)
-	Get(ctx context.Context, id string) (*Vaccination, error)
+	Get(ctx context.Context, userID, id string) (*Vaccination, error)
 	List(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error)
-	Update(ctx context.Context, id string, req *CreateVaccinationRequest) (*Vaccination, error)
-	Delete(ctx context.Context, id string) error
-	RecordAdministration(ctx context.Context, id string, req *RecordVaccinationRequest) (*Vaccination, error)
+	// Stream yields what List returns without holding it all, for exports
+	Stream(ctx context.Context, filter *VaccinationFilter) iter.Seq2[Vaccination, error]
+	ListSince(ctx context.Context, filter *VaccinationFilter, since time.Time) (*delta.Changes[Vaccination], error)
+	Update(ctx context.Context, userID, id string, req *CreateVaccinationRequest) (*Vaccination, error)
+	Delete(ctx context.Context, userID, id string) error
+	RecordAdministration(ctx context.Context, userID, id string, req *RecordVaccinationRequest) (*Vaccination, error)
+	// Correct changes an administered dose, keeping the values it replaces
+	// and the reason given
+	Correct(ctx context.Context, userID, id string, req *CorrectVaccinationRequest) (*Vaccination, error)
+	// ListCorrections returns a dose's corrections, oldest first
+	ListCorrections(ctx context.Context, userID, id string) ([]corrections.Correction, error)
+	// SetStatus moves a dose to skipped, refused, contraindicated or back to
+	// scheduled, enforcing the allowed transitions
+	SetStatus(ctx context.Context, userID, id string, req *SetStatusRequest) (*Vaccination, error)
 	GetUpcoming(ctx context.Context, childID string, days int) ([]Vaccination, error)
 	GetSchedule() []VaccinationSchedule
 	GenerateScheduleForChild(ctx context.Context, childID string, birthDate string) ([]Vaccination, error)
+	// GetCoverage reports coverage now, or as the records stood at asOf when set
+	GetCoverage(ctx context.Context, childID string, asOf *time.Time) (*CoverageReport, error)
+	// SuggestPrefill suggests the provider, location and, for the named
+	// vaccine, lot prefix from the child's earlier administrations
+	SuggestPrefill(ctx context.Context, childID, name string) (*Prefill, error)
 }
 
 type service struct {
-	repo Repository
+	repo          Repository
+	history       audit.Store
+	tx            db.TxManager
+	familyService family.Service
+	tombstones    delta.Tombstones
+	corrections   corrections.Store
+	visibility    visibility.Service
+}
+
+// WithTxManager makes multi-record writes such as schedule generation all or
+// nothing. The repository must be built on the same database.
+func WithTxManager(tx db.TxManager) Option {
+	return func(s *service) {
+		s.tx = tx
+	}
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{repo: repo, tx: db.NoTx}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HealthCheck reports whether vaccinations can be read
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
 }
 
 func (s *service) Create(ctx context.Context, req *CreateVaccinationRequest) (*Vaccination, error) {
+	// Internal callers create without a user and check access themselves
+	if req.UserID != "" {
+		if err := s.authorize(ctx, req.UserID, req.ChildID); err != nil {
+			return nil, err
+		}
+	}
 	now := time.Now()
 
+	// Clients that leave the dose out get the next one in the series rather
+	// than a second "dose 1"
+	dose := req.Dose
+	if dose <= 0 {
+		last, err := s.repo.MaxDose(ctx, req.ChildID, req.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine dose: %w", err)
+		}
+		dose = last + 1
+	}
+
 	vax := &Vaccination{
-		ID:          generateID(),
+		ID:          ids.New(),
 		ChildID:     req.ChildID,
 		Name:        req.Name,
-		Dose:        req.Dose,
+		Dose:        dose,
 		ScheduledAt: req.ScheduledAt,
-		Completed:   false,
+		Status:      StatusScheduled,
+		DocumentIDs: []string{},
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -45,50 +130,194 @@ func (s *service) Create(ctx context.Context, req *CreateVaccinationRequest) (*V
 	if err := s.repo.Create(ctx, vax); err != nil {
 		return nil, fmt.Errorf("failed to create vaccination: %w", err)
 	}
+	s.recordVersion(ctx, vax, audit.ActionCreate)
 
 	return vax, nil
 }
 
-func (s *service) Get(ctx context.Context, id string) (*Vaccination, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *service) Get(ctx context.Context, userID, id string) (*Vaccination, error) {
+	vax, err := s.repo.GetByID(ctx, id)
+	if err != nil || vax == nil {
+		return vax, err
+	}
+	if err := s.authorize(ctx, userID, vax.ChildID); err != nil {
+		return nil, err
+	}
+	return vax, nil
 }
 
 func (s *service) List(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error) {
-	return s.repo.List(ctx, filter)
+	viewer, err := s.viewer(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	vaxes, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return visibility.Filter(ctx, viewer, vaxes, childOf)
+}
+
+func (s *service) Stream(ctx context.Context, filter *VaccinationFilter) iter.Seq2[Vaccination, error] {
+	viewer, err := s.viewer(ctx, filter)
+	if err != nil {
+		return func(yield func(Vaccination, error) bool) {
+			yield(Vaccination{}, err)
+		}
+	}
+	return visibility.FilterSeq(ctx, viewer, s.repo.Stream(ctx, filter), childOf)
 }
 
-func (s *service) Update(ctx context.Context, id string, req *CreateVaccinationRequest) (*Vaccination, error) {
+// getForChange returns the vaccination for userID to change
+func (s *service) getForChange(ctx context.Context, userID, id string) (*Vaccination, error) {
 	vax, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if vax == nil {
-		return nil, fmt.Errorf("vaccination not found")
+		return nil, ErrNotFound
+	}
+	if err := s.authorize(ctx, userID, vax.ChildID); err != nil {
+		return nil, err
+	}
+	return vax, nil
+}
+
+func (s *service) Update(ctx context.Context, userID, id string, req *CreateVaccinationRequest) (*Vaccination, error) {
+	vax, err := s.getForChange(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if vax.Status == StatusCompleted && (vax.Name != req.Name || (req.Dose > 0 && req.Dose != vax.Dose)) {
+		return nil, ErrCorrectionRequired
 	}
 
 	vax.Name = req.Name
-	vax.Dose = req.Dose
+	if req.Dose > 0 {
+		vax.Dose = req.Dose
+	}
 	vax.ScheduledAt = req.ScheduledAt
 	vax.UpdatedAt = time.Now()
 
 	if err := s.repo.Update(ctx, vax); err != nil {
 		return nil, fmt.Errorf("failed to update vaccination: %w", err)
 	}
+	s.recordVersion(ctx, vax, audit.ActionUpdate)
 
 	return vax, nil
 }
 
-func (s *service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+func (s *service) Delete(ctx context.Context, userID, id string) error {
+	vax, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if vax == nil {
+		return s.repo.Delete(ctx, id)
+	}
+	if err := s.authorize(ctx, userID, vax.ChildID); err != nil {
+		return err
+	}
+	if err := s.deleteRecord(ctx, vax); err != nil {
+		return err
+	}
+	s.recordVersion(ctx, vax, audit.ActionDelete)
+	return nil
 }
 
-func (s *service) RecordAdministration(ctx context.Context, id string, req *RecordVaccinationRequest) (*Vaccination, error) {
-	vax, err := s.repo.GetByID(ctx, id)
+func (s *service) RecordAdministration(ctx context.Context, userID, id string, req *RecordVaccinationRequest) (*Vaccination, error) {
+	vax, err := s.getForChange(ctx, userID, id)
 	if err != nil {
 		return nil, err
 	}
-	if vax == nil {
-		return nil, fmt.Errorf("vaccination not found")
+
+	if vax.Status == StatusCompleted {
+		return nil, ErrCorrectionRequired
+	}
+	if !vax.Status.CanTransition(StatusCompleted) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, vax.Status, StatusCompleted)
+	}
+
+	now := time.Now()
+	if err := s.administer(ctx, vax, req, now); err != nil {
+		return nil, err
+	}
+	vax.Status = StatusCompleted
+	vax.StatusChangedAt = &now
+	clearReason(vax)
+
+	if err := s.repo.Update(ctx, vax); err != nil {
+		return nil, fmt.Errorf("failed to record vaccination: %w", err)
+	}
+	s.recordVersion(ctx, vax, audit.ActionUpdate)
+
+	return vax, nil
+}
+
+func (s *service) SetStatus(ctx context.Context, userID, id string, req *SetStatusRequest) (*Vaccination, error) {
+	if !req.Status.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, req.Status)
+	}
+
+	vax, err := s.getForChange(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Completing a dose goes through RecordAdministration so it always has
+	// an administration date
+	if req.Status == StatusCompleted || !vax.Status.CanTransition(req.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, vax.Status, req.Status)
+	}
+	if err := validateReason(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	vax.Status = req.Status
+	vax.StatusChangedAt = &now
+	vax.UpdatedAt = now
+	clearReason(vax)
+	if req.Status != StatusScheduled {
+		vax.StatusReason = strings.TrimSpace(req.Reason)
+		vax.ReasonCode = req.ReasonCode
+		vax.Exemption = req.Exemption
+		vax.DocumentIDs = documents(req.DocumentIDs)
+	}
+
+	if err := s.repo.Update(ctx, vax); err != nil {
+		return nil, fmt.Errorf("failed to update vaccination status: %w", err)
+	}
+	s.recordVersion(ctx, vax, audit.ActionUpdate)
+
+	return vax, nil
+}
+
+// administer validates and applies the administration details of req. A
+// dose given too early may not count towards the series, so it is only
+// recorded with a reason; a correction keeps the reason already given.
+func (s *service) administer(ctx context.Context, vax *Vaccination, req *RecordVaccinationRequest, now time.Time) error {
+	occurrence.Adjust(ctx, "administered_at", &req.AdministeredAt, now)
+	if err := occurrence.Validate(req.AdministeredAt, now); err != nil {
+		return err
+	}
+
+	problems, err := s.timingProblems(ctx, vax, req.AdministeredAt)
+	if err != nil {
+		return err
+	}
+	override := strings.TrimSpace(req.OverrideReason)
+	if override == "" {
+		override = vax.IntervalOverride
+	}
+	if len(problems) > 0 && override == "" {
+		return &DoseTimingError{Problems: problems}
+	}
+	if len(problems) == 0 {
+		override = ""
+	}
+	for _, p := range problems {
+		warnings.Addf(ctx, "%s; recorded with an override", p)
 	}
 
 	vax.AdministeredAt = &req.AdministeredAt
@@ -96,14 +325,53 @@ func (s *service) RecordAdministration(ctx context.Context, id string, req *Reco
 	vax.Location = req.Location
 	vax.LotNumber = req.LotNumber
 	vax.Notes = req.Notes
+	vax.IntervalOverride = override
 	vax.Completed = true
-	vax.UpdatedAt = time.Now()
+	vax.UpdatedAt = now
+	return nil
+}
 
-	if err := s.repo.Update(ctx, vax); err != nil {
-		return nil, fmt.Errorf("failed to record vaccination: %w", err)
+// validateReason checks the documentation supplied with a status change.
+// Refusals and contraindications need a code or free text, "other" always
+// needs text, and an exemption must be backed by a document.
+func validateReason(req *SetStatusRequest) error {
+	if req.ReasonCode != "" && !req.ReasonCode.Valid() {
+		return fmt.Errorf("%w: %q", ErrInvalidReasonCode, req.ReasonCode)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if req.Status.RequiresReason() && reason == "" && req.ReasonCode == "" {
+		return ErrReasonRequired
 	}
+	if req.ReasonCode == ReasonOther && reason == "" {
+		return ErrReasonRequired
+	}
+	if err := limits.Items("document_ids", len(req.DocumentIDs), limits.Get().AttachmentsPerRecord); err != nil {
+		return err
+	}
+	if req.Exemption && (!req.Status.RequiresReason() || len(documents(req.DocumentIDs)) == 0) {
+		return ErrInvalidExemption
+	}
+	return nil
+}
 
-	return vax, nil
+// documents drops blank IDs and never returns nil
+func documents(ids []string) []string {
+	docs := []string{}
+	for _, id := range ids {
+		if id = strings.TrimSpace(id); id != "" {
+			docs = append(docs, id)
+		}
+	}
+	return docs
+}
+
+// clearReason drops the documentation of an earlier skip or refusal. The
+// audit history keeps it.
+func clearReason(vax *Vaccination) {
+	vax.StatusReason = ""
+	vax.ReasonCode = ""
+	vax.Exemption = false
+	vax.DocumentIDs = []string{}
 }
 
 func (s *service) GetUpcoming(ctx context.Context, childID string, days int) ([]Vaccination, error) {
@@ -146,30 +414,193 @@ func (s *service) GenerateScheduleForChild(ctx context.Context, childID string,
 
 		// Only create future vaccinations or ones due in the past 30 days
 		if scheduledAt.After(now.AddDate(0, 0, -30)) {
-			vax := &Vaccination{
-				ID:          generateID(),
+			vaccinations = append(vaccinations, Vaccination{
+				ID:          ids.New(),
 				ChildID:     childID,
 				Name:        sched.Name,
 				Dose:        sched.Dose,
 				ScheduledAt: scheduledAt,
-				Completed:   false,
+				Status:      StatusScheduled,
+				DocumentIDs: []string{},
 				CreatedAt:   now,
 				UpdatedAt:   now,
-			}
+			})
+		}
+	}
+
+	// A partial schedule would look complete to the parent, so either every
+	// dose is inserted or none is
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateBatch(ctx, vaccinations); err != nil {
+			return fmt.Errorf("failed to create vaccination schedule: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// History is best effort and kept outside the transaction, so a failed
+	// audit insert cannot abort the schedule
+	for i := range vaccinations {
+		s.recordVersion(ctx, &vaccinations[i], audit.ActionCreate)
+	}
+
+	return vaccinations, nil
+}
 
-			if err := s.repo.Create(ctx, vax); err != nil {
-				return nil, fmt.Errorf("failed to create vaccination %s: %w", sched.Name, err)
+func (s *service) GetCoverage(ctx context.Context, childID string, asOf *time.Time) (*CoverageReport, error) {
+	vaxes, err := s.listAsOf(ctx, childID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the child's records by antigen and dose, preferring completed ones
+	records := make(map[string]map[int]Vaccination)
+	for _, v := range vaxes {
+		if records[v.Name] == nil {
+			records[v.Name] = make(map[int]Vaccination)
+		}
+		if existing, ok := records[v.Name][v.Dose]; ok && existing.Status == StatusCompleted {
+			continue
+		}
+		records[v.Name][v.Dose] = v
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	if asOf != nil {
+		today = asOf.Truncate(24 * time.Hour)
+	}
+	report := &CoverageReport{
+		ChildID:     childID,
+		Antigens:    []AntigenCoverage{},
+		Footnotes:   []string{},
+		AsOf:        asOf,
+		GeneratedAt: time.Now(),
+	}
+
+	// Walk the schedule in order so antigens appear in the order they are given
+	antigenIndex := make(map[string]int)
+	for _, sched := range s.repo.GetSchedule() {
+		i, ok := antigenIndex[sched.Name]
+		if !ok {
+			i = len(report.Antigens)
+			antigenIndex[sched.Name] = i
+			report.Antigens = append(report.Antigens, AntigenCoverage{
+				Name:         sched.Name,
+				OverdueDoses: []int{},
+				Declined:     []DeclinedDose{},
+			})
+		}
+		coverage := &report.Antigens[i]
+		coverage.DosesRequired++
+
+		v, recorded := records[sched.Name][sched.Dose]
+		if !recorded {
+			continue
+		}
+		if v.Status == StatusCompleted {
+			coverage.DosesCompleted++
+			if v.IntervalOverride != "" {
+				report.Footnotes = append(report.Footnotes, fmt.Sprintf("%s dose %d: given before its minimum age or interval (%s)",
+					v.Name, v.Dose, v.IntervalOverride))
+			}
+			continue
+		}
+		// Skipped, refused and contraindicated doses are deliberate and are
+		// neither overdue nor next, but are footnoted
+		if v.Status != StatusScheduled {
+			coverage.Declined = append(coverage.Declined, DeclinedDose{
+				Dose: v.Dose, Status: v.Status, ReasonCode: v.ReasonCode, Exemption: v.Exemption,
+			})
+			report.Footnotes = append(report.Footnotes, footnote(&v))
+			if v.Exemption {
+				report.ExemptCount++
 			}
+			continue
+		}
+		if v.ScheduledAt.Before(today) {
+			coverage.OverdueDoses = append(coverage.OverdueDoses, v.Dose)
+		}
+		if coverage.NextEligibleAt == nil || v.ScheduledAt.Before(*coverage.NextEligibleAt) {
+			dose := v.Dose
+			scheduledAt := v.ScheduledAt
+			coverage.NextDose = &dose
+			coverage.NextEligibleAt = &scheduledAt
+		}
+	}
 
-			vaccinations = append(vaccinations, *vax)
+	for i := range report.Antigens {
+		coverage := &report.Antigens[i]
+		coverage.PercentComplete = percentOf(coverage.DosesCompleted, coverage.DosesRequired)
+		report.DosesRequired += coverage.DosesRequired
+		report.DosesCompleted += coverage.DosesCompleted
+		report.OverdueCount += len(coverage.OverdueDoses)
+	}
+	report.PercentComplete = percentOf(report.DosesCompleted, report.DosesRequired)
+
+	return report, nil
+}
+
+// footnote describes a declined dose, e.g.
+// "MMR dose 1: contraindicated (allergy: egg), exemption on file"
+func footnote(v *Vaccination) string {
+	var why []string
+	if v.ReasonCode != "" {
+		why = append(why, string(v.ReasonCode))
+	}
+	if v.StatusReason != "" {
+		why = append(why, v.StatusReason)
+	}
+
+	note := fmt.Sprintf("%s dose %d: %s", v.Name, v.Dose, v.Status)
+	if len(why) > 0 {
+		note += " (" + strings.Join(why, ": ") + ")"
+	}
+	if v.Exemption {
+		note += ", exemption on file"
+	}
+	return note
+}
+
+// listAsOf returns the child's vaccinations, rebuilt from their history when
+// asOf is set.
+func (s *service) listAsOf(ctx context.Context, childID string, asOf *time.Time) ([]Vaccination, error) {
+	if asOf == nil {
+		vaxes, err := s.repo.List(ctx, &VaccinationFilter{ChildID: childID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list vaccinations: %w", err)
 		}
+		return vaxes, nil
 	}
 
-	return vaccinations, nil
+	if s.history == nil {
+		return nil, audit.ErrNoHistory
+	}
+	versions, err := s.history.AsOf(ctx, childID, audit.EntityVaccination, *asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vaccination history: %w", err)
+	}
+	vaxes, err := audit.Decode[Vaccination](versions)
+	if err != nil {
+		return nil, err
+	}
+	// Versions recorded before statuses existed only carry the completed flag
+	for i := range vaxes {
+		if vaxes[i].Status == "" {
+			vaxes[i].Status = StatusScheduled
+			if vaxes[i].Completed {
+				vaxes[i].Status = StatusCompleted
+			}
+		}
+	}
+	return vaxes, nil
 }
 
-func generateID() string {
-	b := make([]byte, 16)
-	rand.Read(b) //nolint:errcheck // crypto/rand.Read rarely fails
-	return hex.EncodeToString(b)
+// percentOf returns part/total as a percentage rounded to one decimal place
+func percentOf(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*1000) / 10
 }
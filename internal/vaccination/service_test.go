@@ -2,18 +2,31 @@ package vaccination
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"iter"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/ninenine/babytrack/internal/audit"
+	"github.com/ninenine/babytrack/internal/corrections"
+	"github.com/ninenine/babytrack/internal/delta"
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/visibility"
+	"github.com/ninenine/babytrack/internal/warnings"
 )
 
 // mockRepository is a test double for Repository
 type mockRepository struct {
 	vaccinations map[string]*Vaccination
 	schedule     []VaccinationSchedule
+	listErr      error
 	createErr    error
 	updateErr    error
 	deleteErr    error
+	batches      int
 }
 
 func newMockRepository() *mockRepository {
@@ -36,12 +49,15 @@ func (m *mockRepository) GetByID(ctx context.Context, id string) (*Vaccination,
 }
 
 func (m *mockRepository) List(ctx context.Context, filter *VaccinationFilter) ([]Vaccination, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
 	var result []Vaccination
 	for _, vax := range m.vaccinations {
 		if filter.ChildID != "" && vax.ChildID != filter.ChildID {
 			continue
 		}
-		if filter.Completed != nil && vax.Completed != *filter.Completed {
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, vax.Status) {
 			continue
 		}
 		result = append(result, *vax)
@@ -49,6 +65,17 @@ func (m *mockRepository) List(ctx context.Context, filter *VaccinationFilter) ([
 	return result, nil
 }
 
+func (m *mockRepository) Stream(ctx context.Context, filter *VaccinationFilter) iter.Seq2[Vaccination, error] {
+	vaxes, _ := m.List(ctx, filter)
+	return func(yield func(Vaccination, error) bool) {
+		for _, v := range vaxes {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (m *mockRepository) Create(ctx context.Context, vax *Vaccination) error {
 	if m.createErr != nil {
 		return m.createErr
@@ -57,6 +84,27 @@ func (m *mockRepository) Create(ctx context.Context, vax *Vaccination) error {
 	return nil
 }
 
+func (m *mockRepository) CreateBatch(ctx context.Context, vaxes []Vaccination) error {
+	m.batches++
+	if m.createErr != nil {
+		return m.createErr
+	}
+	for i := range vaxes {
+		m.vaccinations[vaxes[i].ID] = &vaxes[i]
+	}
+	return nil
+}
+
+func (m *mockRepository) MaxDose(ctx context.Context, childID, name string) (int, error) {
+	maxDose := 0
+	for _, v := range m.vaccinations {
+		if v.ChildID == childID && strings.EqualFold(v.Name, name) && v.Dose > maxDose {
+			maxDose = v.Dose
+		}
+	}
+	return maxDose, nil
+}
+
 func (m *mockRepository) Update(ctx context.Context, vax *Vaccination) error {
 	if m.updateErr != nil {
 		return m.updateErr
@@ -79,7 +127,7 @@ func (m *mockRepository) GetUpcoming(ctx context.Context, childID string, days i
 	cutoff := now.AddDate(0, 0, days)
 
 	for _, vax := range m.vaccinations {
-		if vax.ChildID == childID && !vax.Completed {
+		if vax.ChildID == childID && vax.Status == StatusScheduled {
 			if vax.ScheduledAt.After(now) && vax.ScheduledAt.Before(cutoff) {
 				result = append(result, *vax)
 			}
@@ -126,8 +174,36 @@ func TestService_Create(t *testing.T) {
 		t.Errorf("Create() Dose = %v, want 1", vax.Dose)
 	}
 
-	if vax.Completed {
-		t.Error("Create() should set Completed to false")
+	if vax.Status != StatusScheduled {
+		t.Errorf("Create() Status = %q, want scheduled", vax.Status)
+	}
+}
+
+func TestService_Create_DefaultsNextDose(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	repo.vaccinations["dtap-1"] = &Vaccination{ID: "dtap-1", ChildID: "child-123", Name: "DTaP", Dose: 1}
+	repo.vaccinations["dtap-2"] = &Vaccination{ID: "dtap-2", ChildID: "child-123", Name: "DTaP", Dose: 2}
+	repo.vaccinations["other"] = &Vaccination{ID: "other", ChildID: "child-456", Name: "DTaP", Dose: 4}
+
+	vax, err := svc.Create(context.Background(), &CreateVaccinationRequest{
+		ChildID: "child-123", Name: "dtap", ScheduledAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if vax.Dose != 3 {
+		t.Errorf("Create() Dose = %d, want 3", vax.Dose)
+	}
+
+	first, err := svc.Create(context.Background(), &CreateVaccinationRequest{
+		ChildID: "child-123", Name: "MMR", ScheduledAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if first.Dose != 1 {
+		t.Errorf("Create() Dose = %d for a new vaccine, want 1", first.Dose)
 	}
 }
 
@@ -161,7 +237,7 @@ func TestService_Get(t *testing.T) {
 	}
 	created, _ := svc.Create(context.Background(), req)
 
-	vax, err := svc.Get(context.Background(), created.ID)
+	vax, err := svc.Get(context.Background(), "user-1", created.ID)
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -179,7 +255,7 @@ func TestService_Get_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
 
-	vax, err := svc.Get(context.Background(), "non-existent")
+	vax, err := svc.Get(context.Background(), "user-1", "non-existent")
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
@@ -250,11 +326,10 @@ func TestService_List_WithCompletedFilter(t *testing.T) {
 		AdministeredAt: time.Now(),
 		Provider:       "Dr. Smith",
 	}
-	svc.RecordAdministration(context.Background(), completed.ID, recordReq)
+	svc.RecordAdministration(context.Background(), "user-1", completed.ID, recordReq)
 
 	// Filter by completed only
-	isCompleted := true
-	filter := &VaccinationFilter{ChildID: "child-123", Completed: &isCompleted}
+	filter := &VaccinationFilter{ChildID: "child-123", Statuses: []Status{StatusCompleted}}
 	vaxs, err := svc.List(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("List() error = %v", err)
@@ -285,7 +360,7 @@ func TestService_Update(t *testing.T) {
 		ScheduledAt: newSchedule,
 	}
 
-	updated, err := svc.Update(context.Background(), created.ID, updateReq)
+	updated, err := svc.Update(context.Background(), "user-1", created.ID, updateReq)
 	if err != nil {
 		t.Fatalf("Update() error = %v", err)
 	}
@@ -299,6 +374,22 @@ func TestService_Update(t *testing.T) {
 	}
 }
 
+func TestService_Update_KeepsDoseWhenOmitted(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	repo.vaccinations["dtap-2"] = &Vaccination{ID: "dtap-2", ChildID: "child-123", Name: "DTaP", Dose: 2}
+
+	vax, err := svc.Update(context.Background(), "user-1", "dtap-2", &CreateVaccinationRequest{
+		ChildID: "child-123", Name: "DTaP", ScheduledAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if vax.Dose != 2 {
+		t.Errorf("Update() Dose = %d, want 2", vax.Dose)
+	}
+}
+
 func TestService_Update_NotFound(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -310,7 +401,7 @@ func TestService_Update_NotFound(t *testing.T) {
 		ScheduledAt: time.Now(),
 	}
 
-	_, err := svc.Update(context.Background(), "non-existent", req)
+	_, err := svc.Update(context.Background(), "user-1", "non-existent", req)
 	if err == nil {
 		t.Error("Update() should return error for non-existent vaccination")
 	}
@@ -328,17 +419,146 @@ func TestService_Delete(t *testing.T) {
 	}
 	created, _ := svc.Create(context.Background(), req)
 
-	err := svc.Delete(context.Background(), created.ID)
+	err := svc.Delete(context.Background(), "user-1", created.ID)
 	if err != nil {
 		t.Fatalf("Delete() error = %v", err)
 	}
 
-	vax, _ := svc.Get(context.Background(), created.ID)
+	vax, _ := svc.Get(context.Background(), "user-1", created.ID)
 	if vax != nil {
 		t.Error("Delete() should remove the vaccination")
 	}
 }
 
+type mockTombstones struct {
+	deleted map[string]string // entity ID -> scope ID
+}
+
+func (m *mockTombstones) Record(ctx context.Context, entityType delta.EntityType, entityID, scopeID string, at time.Time) error {
+	m.deleted[entityID] = scopeID
+	return nil
+}
+
+func (m *mockTombstones) DeletedSince(ctx context.Context, entityType delta.EntityType, scopeID string, since time.Time) ([]string, error) {
+	var ids []string
+	for id, scope := range m.deleted {
+		if scope == scopeID {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockTombstones) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockTombstones) Since(ctx context.Context, scopeIDs []string, since time.Time) ([]delta.Tombstone, error) {
+	return nil, nil
+}
+
+func (m *mockTombstones) Retention() time.Duration {
+	return delta.DefaultRetention
+}
+
+func TestService_ListSince(t *testing.T) {
+	repo := newMockRepository()
+	tombstones := &mockTombstones{deleted: make(map[string]string)}
+	svc := NewService(repo, WithTombstones(tombstones))
+
+	since := time.Now().Add(-time.Hour)
+	repo.vaccinations["vax-old"] = &Vaccination{ID: "vax-old", ChildID: "child-123", CreatedAt: since.Add(-time.Hour), UpdatedAt: since.Add(-time.Hour)}
+	repo.vaccinations["vax-edited"] = &Vaccination{ID: "vax-edited", ChildID: "child-123", CreatedAt: since.Add(-time.Hour), UpdatedAt: since.Add(time.Minute)}
+	repo.vaccinations["vax-other"] = &Vaccination{ID: "vax-other", ChildID: "child-456", CreatedAt: since.Add(time.Minute)}
+	created, _ := svc.Create(context.Background(), &CreateVaccinationRequest{ChildID: "child-123", Name: "MMR", Dose: 1, ScheduledAt: time.Now()})
+	removed, _ := svc.Create(context.Background(), &CreateVaccinationRequest{ChildID: "child-123", Name: "DTaP", Dose: 1, ScheduledAt: time.Now()})
+	if err := svc.Delete(context.Background(), "user-1", removed.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	changes, err := svc.ListSince(context.Background(), &VaccinationFilter{ChildID: "child-123"}, since)
+	if err != nil {
+		t.Fatalf("ListSince() error = %v", err)
+	}
+	if len(changes.Created) != 1 || changes.Created[0].ID != created.ID {
+		t.Errorf("Created = %+v, want %s", changes.Created, created.ID)
+	}
+	if len(changes.Updated) != 1 || changes.Updated[0].ID != "vax-edited" {
+		t.Errorf("Updated = %+v, want vax-edited", changes.Updated)
+	}
+	if len(changes.Deleted) != 1 || changes.Deleted[0] != removed.ID {
+		t.Errorf("Deleted = %v, want %s", changes.Deleted, removed.ID)
+	}
+}
+
+// mockVisibilityService hides child-hidden's vaccinations, and puts
+// child-other in another family
+type mockVisibilityService struct {
+	visibility.Service
+}
+
+func (m *mockVisibilityService) CheckChild(ctx context.Context, userID, childID string, recordType visibility.RecordType) error {
+	switch childID {
+	case "child-hidden":
+		return visibility.ErrHidden
+	case "child-other":
+		return family.ErrNotMember
+	}
+	return nil
+}
+
+func TestService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewService(repo, WithVisibility(&mockVisibilityService{}))
+
+	ids := map[string]string{}
+	for _, childID := range []string{"child-123", "child-hidden", "child-other"} {
+		vax, err := svc.Create(ctx, &CreateVaccinationRequest{ChildID: childID, Name: "Test Vax", Dose: 1, ScheduledAt: time.Now()})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids[childID] = vax.ID
+	}
+
+	if _, err := svc.Get(ctx, "user-1", ids["child-123"]); err != nil {
+		t.Errorf("Get() visible error = %v", err)
+	}
+	if _, err := svc.Get(ctx, "user-1", ids["child-hidden"]); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Get() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Get(ctx, "user-1", ids["child-other"]); !errors.Is(err, family.ErrNotMember) {
+		t.Errorf("Get() other family error = %v, want ErrNotMember", err)
+	}
+	req := &CreateVaccinationRequest{Name: "Test Vax", ScheduledAt: time.Now()}
+	if _, err := svc.Update(ctx, "user-1", ids["child-hidden"], req); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Update() hidden error = %v, want ErrHidden", err)
+	}
+	if err := svc.Delete(ctx, "user-1", ids["child-hidden"]); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Delete() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.Create(ctx, &CreateVaccinationRequest{ChildID: "child-hidden", Name: "Test Vax", Dose: 1, ScheduledAt: time.Now(), UserID: "user-1"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("Create() hidden error = %v, want ErrHidden", err)
+	}
+	if _, err := svc.SetStatus(ctx, "user-1", ids["child-hidden"], &SetStatusRequest{Status: StatusSkipped}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("SetStatus() hidden error = %v, want ErrHidden", err)
+	}
+
+	list, err := svc.List(ctx, &VaccinationFilter{ViewerID: "user-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ChildID != "child-123" {
+		t.Errorf("List() = %+v, want only child-123's vaccination", list)
+	}
+	if _, err := svc.List(ctx, &VaccinationFilter{ChildID: "child-hidden", ViewerID: "user-1"}); !errors.Is(err, visibility.ErrHidden) {
+		t.Errorf("List() hidden child error = %v, want ErrHidden", err)
+	}
+	if all, _ := svc.List(ctx, &VaccinationFilter{}); len(all) != 3 {
+		t.Errorf("List() without a viewer = %d vaccinations, want 3", len(all))
+	}
+}
+
 func TestService_RecordAdministration(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -361,7 +581,7 @@ func TestService_RecordAdministration(t *testing.T) {
 		Notes:          "No adverse reactions",
 	}
 
-	recorded, err := svc.RecordAdministration(context.Background(), created.ID, recordReq)
+	recorded, err := svc.RecordAdministration(context.Background(), "user-1", created.ID, recordReq)
 	if err != nil {
 		t.Fatalf("RecordAdministration() error = %v", err)
 	}
@@ -396,12 +616,316 @@ func TestService_RecordAdministration_NotFound(t *testing.T) {
 		Provider:       "Dr. Smith",
 	}
 
-	_, err := svc.RecordAdministration(context.Background(), "non-existent", recordReq)
+	_, err := svc.RecordAdministration(context.Background(), "user-1", "non-existent", recordReq)
 	if err == nil {
 		t.Error("RecordAdministration() should return error for non-existent vaccination")
 	}
 }
 
+func TestService_RecordAdministration_Contraindicated(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	repo.vaccinations["vax-1"] = &Vaccination{ID: "vax-1", ChildID: "child-123", Name: "MMR", Dose: 1, Status: StatusContraindicated}
+
+	_, err := svc.RecordAdministration(context.Background(), "user-1", "vax-1", &RecordVaccinationRequest{AdministeredAt: time.Now()})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("RecordAdministration() error = %v, want ErrInvalidTransition", err)
+	}
+}
+
+// mockFamilyService answers child lookups for the minimum age check
+type mockFamilyService struct {
+	family.Service
+	children map[string]*family.Child
+}
+
+func (m *mockFamilyService) GetChild(ctx context.Context, childID string) (*family.Child, error) {
+	return m.children[childID], nil
+}
+
+// newTimingTestService returns a service whose DTaP series needs 6 weeks of
+// age for dose 1 and 4 weeks between doses, for a child born birth
+func newTimingTestService(birth time.Time, opts ...Option) (Service, *mockRepository) {
+	repo := newMockRepository()
+	repo.schedule = []VaccinationSchedule{
+		{ID: "dtap-1", Name: "DTaP", Dose: 1, AgeWeeks: 6, MinAgeWeeks: 6},
+		{ID: "dtap-2", Name: "DTaP", Dose: 2, AgeWeeks: 10, MinAgeWeeks: 10, MinIntervalWeeks: 4},
+	}
+	familySvc := &mockFamilyService{children: map[string]*family.Child{
+		"child-123": {ID: "child-123", DateOfBirth: birth},
+	}}
+	return NewService(repo, append([]Option{WithFamily(familySvc)}, opts...)...), repo
+}
+
+func TestService_RecordAdministration_TooYoung(t *testing.T) {
+	now := time.Now()
+	svc, repo := newTimingTestService(now.AddDate(0, 0, -28))
+	repo.vaccinations["vax-1"] = &Vaccination{ID: "vax-1", ChildID: "child-123", Name: "DTaP", Dose: 1, Status: StatusScheduled}
+
+	_, err := svc.RecordAdministration(context.Background(), "user-1", "vax-1", &RecordVaccinationRequest{AdministeredAt: now})
+	var timingErr *DoseTimingError
+	if !errors.As(err, &timingErr) || !errors.Is(err, ErrDoseTooEarly) {
+		t.Fatalf("RecordAdministration() error = %v, want DoseTimingError", err)
+	}
+	if len(timingErr.Problems) != 1 || !strings.Contains(timingErr.Problems[0], "the minimum age is 6 weeks") {
+		t.Errorf("Unexpected problems %v", timingErr.Problems)
+	}
+	if repo.vaccinations["vax-1"].Status != StatusScheduled {
+		t.Error("A rejected dose should not be recorded")
+	}
+}
+
+func TestService_RecordAdministration_IntervalTooShort(t *testing.T) {
+	now := time.Now()
+	svc, repo := newTimingTestService(now.AddDate(0, -6, 0))
+	given := now.AddDate(0, 0, -14)
+	repo.vaccinations["vax-1"] = &Vaccination{ID: "vax-1", ChildID: "child-123", Name: "DTaP", Dose: 1, Status: StatusCompleted, AdministeredAt: &given}
+	repo.vaccinations["vax-2"] = &Vaccination{ID: "vax-2", ChildID: "child-123", Name: "DTaP", Dose: 2, Status: StatusScheduled}
+
+	_, err := svc.RecordAdministration(context.Background(), "user-1", "vax-2", &RecordVaccinationRequest{AdministeredAt: now})
+	var timingErr *DoseTimingError
+	if !errors.As(err, &timingErr) {
+		t.Fatalf("RecordAdministration() error = %v, want DoseTimingError", err)
+	}
+	if !strings.Contains(timingErr.Problems[0], "given 2 weeks after dose 1; the minimum interval is 4 weeks") {
+		t.Errorf("Unexpected problems %v", timingErr.Problems)
+	}
+}
+
+func TestService_RecordAdministration_WithinGrace(t *testing.T) {
+	now := time.Now()
+	svc, repo := newTimingTestService(now.AddDate(0, -6, 0))
+	given := now.AddDate(0, 0, -26)
+	repo.vaccinations["vax-1"] = &Vaccination{ID: "vax-1", ChildID: "child-123", Name: "DTaP", Dose: 1, Status: StatusCompleted, AdministeredAt: &given}
+	repo.vaccinations["vax-2"] = &Vaccination{ID: "vax-2", ChildID: "child-123", Name: "DTaP", Dose: 2, Status: StatusScheduled}
+
+	if _, err := svc.RecordAdministration(context.Background(), "user-1", "vax-2", &RecordVaccinationRequest{AdministeredAt: now}); err != nil {
+		t.Errorf("A dose 2 days inside the minimum interval should count, got %v", err)
+	}
+}
+
+func TestService_RecordAdministration_Override(t *testing.T) {
+	now := time.Now()
+	svc, repo := newTimingTestService(now.AddDate(0, 0, -40))
+	repo.vaccinations["vax-1"] = &Vaccination{ID: "vax-1", ChildID: "child-123", Name: "DTaP", Dose: 1, Status: StatusScheduled}
+
+	ctx := warnings.NewContext(context.Background())
+	recorded, err := svc.RecordAdministration(ctx, "user-1", "vax-1", &RecordVaccinationRequest{
+		AdministeredAt: now.AddDate(0, 0, -10),
+		OverrideReason: " Travel schedule advised by clinic ",
+	})
+	if err != nil {
+		t.Fatalf("RecordAdministration() error = %v", err)
+	}
+	if recorded.IntervalOverride != "Travel schedule advised by clinic" {
+		t.Errorf("IntervalOverride = %q", recorded.IntervalOverride)
+	}
+	if got := warnings.From(ctx); len(got) != 1 || !strings.Contains(got[0], "recorded with an override") {
+		t.Errorf("Expected an override warning, got %v", got)
+	}
+
+	// Correcting keeps the reason while the dose is still early, and clears
+	// it once the corrected date is in range
+	svc, repo = newTimingTestService(now.AddDate(0, 0, -40), WithCorrections(&mockCorrections{}))
+	repo.vaccinations["vax-1"] = recorded
+	recorded, err = svc.Correct(context.Background(), "user-1", "vax-1", &CorrectVaccinationRequest{
+		RecordVaccinationRequest: RecordVaccinationRequest{AdministeredAt: now.AddDate(0, 0, -9)},
+		Reason:                   "wrong day",
+	})
+	if err != nil || recorded.IntervalOverride == "" {
+		t.Fatalf("Correct() = %+v, %v, want the override kept", recorded, err)
+	}
+	recorded, err = svc.Correct(context.Background(), "user-1", "vax-1", &CorrectVaccinationRequest{
+		RecordVaccinationRequest: RecordVaccinationRequest{AdministeredAt: now},
+		Reason:                   "wrong day again",
+	})
+	if err != nil {
+		t.Fatalf("Correct() error = %v", err)
+	}
+	if recorded.IntervalOverride != "" {
+		t.Errorf("IntervalOverride = %q, want cleared", recorded.IntervalOverride)
+	}
+}
+
+func TestService_GetCoverage_FootnotesOverride(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	given := time.Now()
+	repo.vaccinations["vax-1"] = &Vaccination{
+		ID: "vax-1", ChildID: "child-123", Name: "DTaP", Dose: 1, Status: StatusCompleted,
+		AdministeredAt: &given, IntervalOverride: "catch-up schedule",
+	}
+
+	report, err := svc.GetCoverage(context.Background(), "child-123", nil)
+	if err != nil {
+		t.Fatalf("GetCoverage() error = %v", err)
+	}
+	want := "DTaP dose 1: given before its minimum age or interval (catch-up schedule)"
+	if !slices.Contains(report.Footnotes, want) {
+		t.Errorf("Footnotes = %v, want %q", report.Footnotes, want)
+	}
+}
+
+func TestService_SetStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    Status
+		to      Status
+		reason  string
+		wantErr error
+	}{
+		{"skip scheduled", StatusScheduled, StatusSkipped, "", nil},
+		{"refuse with reason", StatusScheduled, StatusRefused, "Parent declined", nil},
+		{"refuse without reason", StatusScheduled, StatusRefused, "  ", ErrReasonRequired},
+		{"contraindicate without reason", StatusScheduled, StatusContraindicated, "", ErrReasonRequired},
+		{"reschedule refused", StatusRefused, StatusScheduled, "", nil},
+		{"lift contraindication", StatusContraindicated, StatusScheduled, "", nil},
+		{"skip contraindicated", StatusContraindicated, StatusSkipped, "", ErrInvalidTransition},
+		{"reopen completed", StatusCompleted, StatusScheduled, "", ErrInvalidTransition},
+		{"complete without record", StatusScheduled, StatusCompleted, "", ErrInvalidTransition},
+		{"unknown status", StatusScheduled, Status("lost"), "", ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			svc := NewService(repo)
+			repo.vaccinations["vax-1"] = &Vaccination{ID: "vax-1", ChildID: "child-123", Name: "MMR", Dose: 1, Status: tt.from}
+
+			vax, err := svc.SetStatus(context.Background(), "user-1", "vax-1", &SetStatusRequest{Status: tt.to, Reason: tt.reason})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetStatus() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if repo.vaccinations["vax-1"].Status != tt.from {
+					t.Errorf("SetStatus() changed status to %q on error", repo.vaccinations["vax-1"].Status)
+				}
+				return
+			}
+			if vax.Status != tt.to || vax.StatusChangedAt == nil {
+				t.Errorf("SetStatus() = %q changed at %v, want %q with a time", vax.Status, vax.StatusChangedAt, tt.to)
+			}
+			if vax.StatusReason != strings.TrimSpace(tt.reason) {
+				t.Errorf("SetStatus() StatusReason = %q, want %q", vax.StatusReason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestService_SetStatus_Documentation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     SetStatusRequest
+		wantErr error
+	}{
+		{"code without text", SetStatusRequest{Status: StatusRefused, ReasonCode: ReasonReligious}, nil},
+		{"unknown code", SetStatusRequest{Status: StatusRefused, ReasonCode: "whim"}, ErrInvalidReasonCode},
+		{"other without text", SetStatusRequest{Status: StatusSkipped, ReasonCode: ReasonOther}, ErrReasonRequired},
+		{"exemption with document", SetStatusRequest{
+			Status: StatusContraindicated, ReasonCode: ReasonAllergy, Exemption: true, DocumentIDs: []string{"media-1"},
+		}, nil},
+		{"exemption without document", SetStatusRequest{
+			Status: StatusContraindicated, ReasonCode: ReasonAllergy, Exemption: true, DocumentIDs: []string{" "},
+		}, ErrInvalidExemption},
+		{"exemption for skipped dose", SetStatusRequest{
+			Status: StatusSkipped, ReasonCode: ReasonIllness, Exemption: true, DocumentIDs: []string{"media-1"},
+		}, ErrInvalidExemption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			svc := NewService(repo)
+			repo.vaccinations["vax-1"] = &Vaccination{ID: "vax-1", ChildID: "child-123", Name: "MMR", Dose: 1, Status: StatusScheduled}
+
+			vax, err := svc.SetStatus(context.Background(), "user-1", "vax-1", &tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetStatus() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (vax.ReasonCode != tt.req.ReasonCode || vax.Exemption != tt.req.Exemption) {
+				t.Errorf("SetStatus() = %+v, want documentation from %+v", vax, tt.req)
+			}
+		})
+	}
+}
+
+func TestService_SetStatus_RescheduleClearsDocumentation(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	repo.vaccinations["vax-1"] = &Vaccination{
+		ID: "vax-1", ChildID: "child-123", Name: "MMR", Dose: 1, Status: StatusRefused,
+		StatusReason: "Parent declined", ReasonCode: ReasonPersonal, Exemption: true, DocumentIDs: []string{"media-1"},
+	}
+
+	vax, err := svc.SetStatus(context.Background(), "user-1", "vax-1", &SetStatusRequest{Status: StatusScheduled, ReasonCode: ReasonPersonal})
+	if err != nil {
+		t.Fatalf("SetStatus() error = %v", err)
+	}
+	if vax.StatusReason != "" || vax.ReasonCode != "" || vax.Exemption || len(vax.DocumentIDs) != 0 {
+		t.Errorf("SetStatus() kept refusal documentation: %+v", vax)
+	}
+}
+
+func TestService_SetStatus_NotFound(t *testing.T) {
+	svc := NewService(newMockRepository())
+
+	_, err := svc.SetStatus(context.Background(), "user-1", "missing", &SetStatusRequest{Status: StatusSkipped})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetStatus() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestService_GetCoverage_DeclinedDosesNotOverdue(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	past := time.Now().AddDate(0, 0, -10)
+	repo.vaccinations["dtap-1"] = &Vaccination{
+		ID: "dtap-1", ChildID: "child-123", Name: "DTaP", Dose: 1, ScheduledAt: past, Status: StatusRefused,
+	}
+
+	report, err := svc.GetCoverage(context.Background(), "child-123", nil)
+	if err != nil {
+		t.Fatalf("GetCoverage() error = %v", err)
+	}
+	if report.OverdueCount != 0 || report.DosesCompleted != 0 {
+		t.Errorf("GetCoverage() overdue = %d, completed = %d, want 0 and 0", report.OverdueCount, report.DosesCompleted)
+	}
+}
+
+func TestService_GetCoverage_Exemptions(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	past := time.Now().AddDate(0, 0, -10)
+	repo.vaccinations["dtap-2"] = &Vaccination{
+		ID: "dtap-2", ChildID: "child-123", Name: "DTaP", Dose: 2, ScheduledAt: past,
+		Status: StatusContraindicated, ReasonCode: ReasonAllergy, StatusReason: "anaphylaxis after dose 1",
+		Exemption: true, DocumentIDs: []string{"media-1"},
+	}
+
+	report, err := svc.GetCoverage(context.Background(), "child-123", nil)
+	if err != nil {
+		t.Fatalf("GetCoverage() error = %v", err)
+	}
+
+	if report.ExemptCount != 1 {
+		t.Errorf("GetCoverage() ExemptCount = %d, want 1", report.ExemptCount)
+	}
+	want := "DTaP dose 2: contraindicated (allergy: anaphylaxis after dose 1), exemption on file"
+	if len(report.Footnotes) != 1 || report.Footnotes[0] != want {
+		t.Errorf("GetCoverage() Footnotes = %q, want [%q]", report.Footnotes, want)
+	}
+	for _, a := range report.Antigens {
+		if a.Name != "DTaP" {
+			continue
+		}
+		if len(a.Declined) != 1 || a.Declined[0].Dose != 2 || !a.Declined[0].Exemption {
+			t.Errorf("GetCoverage() DTaP Declined = %+v", a.Declined)
+		}
+	}
+}
+
 func TestService_GetUpcoming(t *testing.T) {
 	repo := newMockRepository()
 	svc := NewService(repo)
@@ -482,6 +1006,9 @@ func TestService_GenerateScheduleForChild(t *testing.T) {
 	if len(vaccinations) == 0 {
 		t.Error("GenerateScheduleForChild() should generate vaccinations")
 	}
+	if repo.batches != 1 {
+		t.Errorf("GenerateScheduleForChild() should insert in one batch, got %d", repo.batches)
+	}
 
 	// All vaccinations should be for the correct child
 	for _, vax := range vaccinations {
@@ -517,3 +1044,429 @@ func TestService_GenerateScheduleForChild_RFC3339Format(t *testing.T) {
 		t.Error("GenerateScheduleForChild() should work with RFC3339 format")
 	}
 }
+
+// recordingTx counts units of work and reports the error its function returned
+type recordingTx struct {
+	calls int
+	err   error
+}
+
+func (r *recordingTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.calls++
+	r.err = fn(ctx)
+	return r.err
+}
+
+func TestService_GenerateScheduleForChild_SingleUnitOfWork(t *testing.T) {
+	repo := newMockRepository()
+	repo.createErr = errors.New("database error")
+	tx := &recordingTx{}
+	svc := NewService(repo, WithTxManager(tx))
+
+	birthDate := time.Now().AddDate(0, -1, 0).Format("2006-01-02")
+	if _, err := svc.GenerateScheduleForChild(context.Background(), "child-123", birthDate); err == nil {
+		t.Fatal("GenerateScheduleForChild() should return error when an insert fails")
+	}
+	if tx.calls != 1 || tx.err == nil {
+		t.Errorf("Expected inserts to fail inside one transaction, got %d calls (err %v)", tx.calls, tx.err)
+	}
+}
+
+func TestService_GetCoverage(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+
+	now := time.Now()
+	administeredAt := now.AddDate(0, -2, 0)
+	repo.vaccinations["dtap-1"] = &Vaccination{
+		ID: "dtap-1", ChildID: "child-123", Name: "DTaP", Dose: 1,
+		ScheduledAt: now.AddDate(0, -2, 0), AdministeredAt: &administeredAt, Status: StatusCompleted,
+	}
+	repo.vaccinations["dtap-2"] = &Vaccination{
+		ID: "dtap-2", ChildID: "child-123", Name: "DTaP", Dose: 2,
+		ScheduledAt: now.AddDate(0, 0, -10), Status: StatusScheduled,
+	}
+	repo.vaccinations["other-child"] = &Vaccination{
+		ID: "other-child", ChildID: "child-999", Name: "Hepatitis B", Dose: 1,
+		ScheduledAt: now.AddDate(0, 0, -10), Status: StatusCompleted,
+	}
+
+	report, err := svc.GetCoverage(context.Background(), "child-123", nil)
+	if err != nil {
+		t.Fatalf("GetCoverage() error = %v", err)
+	}
+
+	if report.DosesRequired != 3 {
+		t.Errorf("GetCoverage() DosesRequired = %d, want 3", report.DosesRequired)
+	}
+	if report.DosesCompleted != 1 {
+		t.Errorf("GetCoverage() DosesCompleted = %d, want 1", report.DosesCompleted)
+	}
+	if report.PercentComplete != 33.3 {
+		t.Errorf("GetCoverage() PercentComplete = %v, want 33.3", report.PercentComplete)
+	}
+	if report.OverdueCount != 1 {
+		t.Errorf("GetCoverage() OverdueCount = %d, want 1", report.OverdueCount)
+	}
+
+	if len(report.Antigens) != 2 {
+		t.Fatalf("GetCoverage() returned %d antigens, want 2", len(report.Antigens))
+	}
+
+	hepB := report.Antigens[0]
+	if hepB.Name != "Hepatitis B" || hepB.DosesCompleted != 0 || hepB.NextEligibleAt != nil {
+		t.Errorf("GetCoverage() unexpected Hepatitis B coverage: %+v", hepB)
+	}
+
+	dtap := report.Antigens[1]
+	if dtap.PercentComplete != 50 {
+		t.Errorf("GetCoverage() DTaP PercentComplete = %v, want 50", dtap.PercentComplete)
+	}
+	if len(dtap.OverdueDoses) != 1 || dtap.OverdueDoses[0] != 2 {
+		t.Errorf("GetCoverage() DTaP OverdueDoses = %v, want [2]", dtap.OverdueDoses)
+	}
+	if dtap.NextDose == nil || *dtap.NextDose != 2 {
+		t.Errorf("GetCoverage() DTaP NextDose = %v, want 2", dtap.NextDose)
+	}
+	if dtap.NextEligibleAt == nil {
+		t.Error("GetCoverage() DTaP NextEligibleAt should be set")
+	}
+}
+
+// memoryHistory is an in-memory audit.Store whose clock advances a minute per write
+type memoryHistory struct {
+	versions []audit.Version
+	clock    time.Time
+}
+
+func (m *memoryHistory) Record(ctx context.Context, entityType audit.EntityType, entityID, childID string, action audit.Action, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	m.clock = m.clock.Add(time.Minute)
+	m.versions = append(m.versions, audit.Version{
+		EntityType: entityType, EntityID: entityID, ChildID: childID,
+		Action: action, Data: payload, RecordedAt: m.clock,
+	})
+	return nil
+}
+
+func (m *memoryHistory) AsOf(ctx context.Context, childID string, entityType audit.EntityType, asOf time.Time) ([]audit.Version, error) {
+	latest := make(map[string]audit.Version)
+	var order []string
+	for _, v := range m.versions {
+		if v.ChildID != childID || v.EntityType != entityType || v.RecordedAt.After(asOf) {
+			continue
+		}
+		if _, seen := latest[v.EntityID]; !seen {
+			order = append(order, v.EntityID)
+		}
+		latest[v.EntityID] = v
+	}
+
+	result := []audit.Version{}
+	for _, id := range order {
+		if latest[id].Action != audit.ActionDelete {
+			result = append(result, latest[id])
+		}
+	}
+	return result, nil
+}
+
+func TestService_GetCoverage_AsOf(t *testing.T) {
+	repo := newMockRepository()
+	history := &memoryHistory{clock: time.Now().AddDate(0, -1, 0)}
+	svc := NewService(repo, WithHistory(history))
+	ctx := context.Background()
+
+	created, err := svc.Create(ctx, &CreateVaccinationRequest{
+		ChildID: "child-123", Name: "DTaP", Dose: 1, ScheduledAt: time.Now().AddDate(0, 0, -40),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	beforeAdministration := history.clock
+
+	if _, err := svc.RecordAdministration(ctx, "user-1", created.ID, &RecordVaccinationRequest{AdministeredAt: time.Now().AddDate(0, 0, -1)}); err != nil {
+		t.Fatalf("RecordAdministration() error = %v", err)
+	}
+
+	then, err := svc.GetCoverage(ctx, "child-123", &beforeAdministration)
+	if err != nil {
+		t.Fatalf("GetCoverage(asOf) error = %v", err)
+	}
+	if then.DosesCompleted != 0 || then.OverdueCount != 1 {
+		t.Errorf("GetCoverage(asOf) completed = %d, overdue = %d; want 0 and 1", then.DosesCompleted, then.OverdueCount)
+	}
+	if then.AsOf == nil || !then.AsOf.Equal(beforeAdministration) {
+		t.Errorf("GetCoverage(asOf) AsOf = %v, want %v", then.AsOf, beforeAdministration)
+	}
+
+	current, err := svc.GetCoverage(ctx, "child-123", nil)
+	if err != nil {
+		t.Fatalf("GetCoverage() error = %v", err)
+	}
+	if current.DosesCompleted != 1 {
+		t.Errorf("GetCoverage() completed = %d, want 1", current.DosesCompleted)
+	}
+
+	if err := svc.Delete(ctx, "user-1", created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	afterDelete := history.clock
+	gone, err := svc.GetCoverage(ctx, "child-123", &afterDelete)
+	if err != nil {
+		t.Fatalf("GetCoverage(after delete) error = %v", err)
+	}
+	if gone.DosesCompleted != 0 || gone.OverdueCount != 0 {
+		t.Errorf("GetCoverage(after delete) should not include the deleted record: %+v", gone)
+	}
+}
+
+func TestService_GetCoverage_AsOfWithoutHistory(t *testing.T) {
+	svc := NewService(newMockRepository())
+
+	asOf := time.Now().AddDate(0, -1, 0)
+	_, err := svc.GetCoverage(context.Background(), "child-123", &asOf)
+	if !errors.Is(err, audit.ErrNoHistory) {
+		t.Errorf("GetCoverage() error = %v, want %v", err, audit.ErrNoHistory)
+	}
+}
+
+func TestService_GetCoverage_RepoError(t *testing.T) {
+	repo := newMockRepository()
+	repo.listErr = errors.New("database error")
+	svc := NewService(repo)
+
+	_, err := svc.GetCoverage(context.Background(), "child-123", nil)
+	if err == nil {
+		t.Error("GetCoverage() should return error when repo fails")
+	}
+}
+
+// discardRepository drops created doses, so the benchmark measures schedule
+// generation rather than the mock's map growing
+type discardRepository struct {
+	*mockRepository
+}
+
+func (discardRepository) CreateBatch(ctx context.Context, vaxes []Vaccination) error {
+	return nil
+}
+
+func BenchmarkService_GenerateScheduleForChild(b *testing.B) {
+	repo := discardRepository{newMockRepository()}
+	repo.schedule = NewRepository(nil).GetSchedule()
+	svc := NewService(repo)
+	ctx := context.Background()
+	birth := time.Now().AddDate(0, -1, 0).Format(time.DateOnly)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := svc.GenerateScheduleForChild(ctx, "child-1", birth); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestService_SuggestPrefill(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	at := func(days int) *time.Time {
+		t := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+		return &t
+	}
+	repo.vaccinations["vax-1"] = &Vaccination{ID: "vax-1", ChildID: "child-123", Name: "DTaP", Dose: 1, Status: StatusCompleted,
+		AdministeredAt: at(0), Provider: "Dr. Old", Location: "Old Clinic", LotNumber: "U7045AA"}
+	repo.vaccinations["vax-2"] = &Vaccination{ID: "vax-2", ChildID: "child-123", Name: "DTaP", Dose: 2, Status: StatusCompleted,
+		AdministeredAt: at(60), Provider: "Dr. Smith", Location: "Main Street Clinic", LotNumber: "U7112BB"}
+	repo.vaccinations["vax-3"] = &Vaccination{ID: "vax-3", ChildID: "child-123", Name: "Hepatitis B", Dose: 2, Status: StatusCompleted,
+		AdministeredAt: at(90), LotNumber: "HB4410"}
+	repo.vaccinations["vax-4"] = &Vaccination{ID: "vax-4", ChildID: "child-123", Name: "DTaP", Dose: 3, Status: StatusScheduled,
+		Provider: "Dr. Planned"}
+	repo.vaccinations["vax-5"] = &Vaccination{ID: "vax-5", ChildID: "child-999", Name: "DTaP", Dose: 1, Status: StatusCompleted,
+		AdministeredAt: at(100), Provider: "Dr. Elsewhere"}
+
+	prefill, err := svc.SuggestPrefill(context.Background(), "child-123", "dtap")
+	if err != nil {
+		t.Fatalf("SuggestPrefill() error = %v", err)
+	}
+
+	// The latest dose has no provider or location, so the one before is used
+	if prefill.Provider != "Dr. Smith" || prefill.Location != "Main Street Clinic" {
+		t.Errorf("SuggestPrefill() provider = %q at %q, want Dr. Smith at Main Street Clinic", prefill.Provider, prefill.Location)
+	}
+	if prefill.BasedOn == nil || prefill.BasedOn.ID != "vax-2" {
+		t.Errorf("SuggestPrefill() BasedOn = %+v, want vax-2", prefill.BasedOn)
+	}
+	if prefill.LotPrefix != "U7" {
+		t.Errorf("SuggestPrefill() LotPrefix = %q, want U7", prefill.LotPrefix)
+	}
+}
+
+func TestService_SuggestPrefill_NoHistory(t *testing.T) {
+	svc := NewService(newMockRepository())
+
+	prefill, err := svc.SuggestPrefill(context.Background(), "child-123", "MMR")
+	if err != nil {
+		t.Fatalf("SuggestPrefill() error = %v", err)
+	}
+	if prefill.Provider != "" || prefill.Location != "" || prefill.LotPrefix != "" || prefill.BasedOn != nil {
+		t.Errorf("SuggestPrefill() without history = %+v, want empty", prefill)
+	}
+}
+
+func TestLotPrefix(t *testing.T) {
+	tests := []struct {
+		lots []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"U7045AA"}, "U"},
+		{[]string{"7045AA"}, ""},
+		{[]string{"EW0150", "EW0182"}, "EW01"},
+		{[]string{"EW0150", "U7045AA"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := lotPrefix(tt.lots); got != tt.want {
+			t.Errorf("lotPrefix(%v) = %q, want %q", tt.lots, got, tt.want)
+		}
+	}
+}
+
+// =============================================================================
+// Correction Tests
+// =============================================================================
+
+// mockCorrections is a test double for corrections.Store
+type mockCorrections struct {
+	recorded []corrections.Correction
+}
+
+func (m *mockCorrections) Record(ctx context.Context, c *corrections.Correction, original, corrected any) error {
+	c.ID = "corr-1"
+	c.Original, _ = json.Marshal(original)
+	c.Corrected, _ = json.Marshal(corrected)
+	m.recorded = append(m.recorded, *c)
+	return nil
+}
+
+func (m *mockCorrections) List(ctx context.Context, entityType corrections.EntityType, entityID string) ([]corrections.Correction, error) {
+	var list []corrections.Correction
+	for _, c := range m.recorded {
+		if c.EntityType == entityType && c.EntityID == entityID {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+
+func TestService_Correct(t *testing.T) {
+	repo := newMockRepository()
+	store := &mockCorrections{}
+	svc := NewService(repo, WithCorrections(store))
+	given := time.Now().AddDate(0, 0, -3)
+	repo.vaccinations["vax-1"] = &Vaccination{
+		ID: "vax-1", ChildID: "child-123", Name: "MMR", Dose: 1, Status: StatusCompleted,
+		AdministeredAt: &given, LotNumber: "A1", Provider: "Dr. Smith",
+	}
+
+	corrected, err := svc.Correct(context.Background(), "user-1", "vax-1", &CorrectVaccinationRequest{
+		Dose: 2,
+		RecordVaccinationRequest: RecordVaccinationRequest{
+			AdministeredAt: given, LotNumber: "A7", Provider: "Dr. Smith",
+		},
+		Reason: "  lot and dose misread from the card ",
+	})
+	if err != nil {
+		t.Fatalf("Correct() error = %v", err)
+	}
+	if corrected.LotNumber != "A7" || corrected.Dose != 2 || corrected.Name != "MMR" || corrected.CorrectedAt == nil {
+		t.Errorf("Correct() = %+v", corrected)
+	}
+	if repo.vaccinations["vax-1"].LotNumber != "A7" {
+		t.Error("Expected the corrected values to be saved")
+	}
+
+	if len(store.recorded) != 1 {
+		t.Fatalf("Expected one correction, got %d", len(store.recorded))
+	}
+	c := store.recorded[0]
+	if c.Reason != "lot and dose misread from the card" || c.CorrectedBy != "user-1" || c.EntityType != corrections.EntityVaccination {
+		t.Errorf("Unexpected correction %+v", c)
+	}
+	var original Vaccination
+	if err := json.Unmarshal(c.Original, &original); err != nil {
+		t.Fatalf("Failed to decode original: %v", err)
+	}
+	if original.LotNumber != "A1" || original.Dose != 1 || original.CorrectedAt != nil {
+		t.Errorf("Original = %+v, want the values before the correction", original)
+	}
+
+	list, err := svc.ListCorrections(context.Background(), "user-1", "vax-1")
+	if err != nil || len(list) != 1 {
+		t.Errorf("ListCorrections() = %v, %v", list, err)
+	}
+}
+
+func TestService_Correct_Errors(t *testing.T) {
+	given := time.Now().AddDate(0, 0, -3)
+	valid := RecordVaccinationRequest{AdministeredAt: given}
+
+	tests := []struct {
+		name    string
+		vax     *Vaccination
+		reason  string
+		opts    []Option
+		wantErr error
+	}{
+		{"scheduled dose", &Vaccination{ID: "vax-1", Status: StatusScheduled}, "wrong", nil, ErrNotAdministered},
+		{"blank reason", &Vaccination{ID: "vax-1", Status: StatusCompleted, AdministeredAt: &given}, " ", nil, corrections.ErrReasonRequired},
+		{"missing", nil, "wrong", nil, ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			if tt.vax != nil {
+				repo.vaccinations[tt.vax.ID] = tt.vax
+			}
+			svc := NewService(repo, WithCorrections(&mockCorrections{}))
+
+			_, err := svc.Correct(context.Background(), "user-1", "vax-1", &CorrectVaccinationRequest{
+				RecordVaccinationRequest: valid, Reason: tt.reason,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Correct() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	svc := NewService(newMockRepository())
+	if _, err := svc.Correct(context.Background(), "user-1", "vax-1", &CorrectVaccinationRequest{Reason: "x"}); !errors.Is(err, corrections.ErrUnavailable) {
+		t.Errorf("Correct() without a store error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestService_EditsToAdministeredDoses(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo)
+	given := time.Now().AddDate(0, 0, -3)
+	repo.vaccinations["vax-1"] = &Vaccination{
+		ID: "vax-1", ChildID: "child-123", Name: "MMR", Dose: 1, Status: StatusCompleted,
+		AdministeredAt: &given, ScheduledAt: given,
+	}
+	ctx := context.Background()
+
+	if _, err := svc.RecordAdministration(ctx, "user-1", "vax-1", &RecordVaccinationRequest{AdministeredAt: given}); !errors.Is(err, ErrCorrectionRequired) {
+		t.Errorf("RecordAdministration() on a completed dose error = %v, want ErrCorrectionRequired", err)
+	}
+	if _, err := svc.Update(ctx, "user-1", "vax-1", &CreateVaccinationRequest{ChildID: "child-123", Name: "MMRV", ScheduledAt: given}); !errors.Is(err, ErrCorrectionRequired) {
+		t.Errorf("Update() renaming a completed dose error = %v, want ErrCorrectionRequired", err)
+	}
+	if _, err := svc.Update(ctx, "user-1", "vax-1", &CreateVaccinationRequest{ChildID: "child-123", Name: "MMR", ScheduledAt: given.AddDate(0, 0, -1)}); err != nil {
+		t.Errorf("Update() moving the scheduled date error = %v", err)
+	}
+}
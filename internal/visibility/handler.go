@@ -0,0 +1,92 @@
+package visibility
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ninenine/babytrack/internal/family"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterFamilyRoutes registers the visibility settings routes on the families group
+func (h *Handler) RegisterFamilyRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:familyId/members/:userId/visibility", h.getVisibility)
+	rg.PUT("/:familyId/members/:userId/visibility", h.setVisibility)
+}
+
+// Enforce rejects child-scoped requests for a record type hidden from the
+// current user. The child is taken from the child_id query parameter or the
+// :childId path parameter; requests without either pass through.
+func (h *Handler) Enforce(recordType RecordType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		childID := c.Query("child_id")
+		if childID == "" {
+			childID = c.Param("childId")
+		}
+		if childID == "" {
+			c.Next()
+			return
+		}
+
+		hidden, err := h.service.IsHidden(c.Request.Context(), c.GetString("user_id"), childID, recordType)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if hidden {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrHidden.Error()})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (h *Handler) getVisibility(c *gin.Context) {
+	requesterID := c.GetString("user_id")
+
+	v, err := h.service.GetMemberVisibility(c.Request.Context(), requesterID, c.Param("familyId"), c.Param("userId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+func (h *Handler) setVisibility(c *gin.Context) {
+	var req SetVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	requesterID := c.GetString("user_id")
+
+	v, err := h.service.SetMemberVisibility(c.Request.Context(), requesterID, c.Param("familyId"), c.Param("userId"), req.HiddenTypes)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidRecordType), errors.Is(err, ErrCannotRestrictAdmin):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotAdmin), errors.Is(err, ErrVisibilityNotAllowed),
+		errors.Is(err, family.ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
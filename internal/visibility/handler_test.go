@@ -0,0 +1,142 @@
+package visibility
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/apispec/apispectest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mockService implements the Service interface for testing
+type mockService struct {
+	getFn      func(ctx context.Context, requesterID, familyID, userID string) (*MemberVisibility, error)
+	setFn      func(ctx context.Context, requesterID, familyID, userID string, hidden []RecordType) (*MemberVisibility, error)
+	isHiddenFn func(ctx context.Context, userID, childID string, recordType RecordType) (bool, error)
+}
+
+func (m *mockService) GetMemberVisibility(ctx context.Context, requesterID, familyID, userID string) (*MemberVisibility, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, requesterID, familyID, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockService) SetMemberVisibility(ctx context.Context, requesterID, familyID, userID string, hidden []RecordType) (*MemberVisibility, error) {
+	if m.setFn != nil {
+		return m.setFn(ctx, requesterID, familyID, userID, hidden)
+	}
+	return nil, nil
+}
+
+func (m *mockService) IsHidden(ctx context.Context, userID, childID string, recordType RecordType) (bool, error) {
+	if m.isHiddenFn != nil {
+		return m.isHiddenFn(ctx, userID, childID, recordType)
+	}
+	return false, nil
+}
+
+func (m *mockService) CheckChild(ctx context.Context, userID, childID string, recordType RecordType) error {
+	return nil
+}
+
+func setupRouter(svc Service) *gin.Engine {
+	router := gin.New()
+	handler := NewHandler(svc)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-123")
+		c.Next()
+	})
+
+	// The notes routes below are stand-ins, so only these are recorded
+	handler.RegisterFamilyRoutes(router.Group("/families", apispectest.Record("/api")))
+
+	notesGroup := router.Group("/notes", handler.Enforce(RecordNotes))
+	notesGroup.GET("", func(c *gin.Context) { c.JSON(http.StatusOK, []string{}) })
+	notesGroup.GET("/last/:childId", func(c *gin.Context) { c.JSON(http.StatusOK, nil) })
+	return router
+}
+
+func TestSetVisibility_Success(t *testing.T) {
+	var captured []RecordType
+	svc := &mockService{
+		setFn: func(ctx context.Context, requesterID, familyID, userID string, hidden []RecordType) (*MemberVisibility, error) {
+			captured = hidden
+			return &MemberVisibility{FamilyID: familyID, UserID: userID, HiddenTypes: hidden}, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("PUT", "/families/family-1/members/sitter-1/visibility", bytes.NewBufferString(`{"hidden_types":["notes"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if len(captured) != 1 || captured[0] != RecordNotes {
+		t.Errorf("Expected [notes], got %v", captured)
+	}
+}
+
+func TestSetVisibility_NotAdmin(t *testing.T) {
+	svc := &mockService{
+		setFn: func(ctx context.Context, requesterID, familyID, userID string, hidden []RecordType) (*MemberVisibility, error) {
+			return nil, ErrNotAdmin
+		},
+	}
+	router := setupRouter(svc)
+
+	req := httptest.NewRequest("PUT", "/families/family-1/members/sitter-1/visibility", bytes.NewBufferString(`{"hidden_types":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	svc := &mockService{
+		isHiddenFn: func(ctx context.Context, userID, childID string, recordType RecordType) (bool, error) {
+			if childID == "db-error" {
+				return false, errors.New("database error")
+			}
+			return childID == "hidden-child" && recordType == RecordNotes, nil
+		},
+	}
+	router := setupRouter(svc)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/notes?child_id=hidden-child", http.StatusForbidden},
+		{"/notes/last/hidden-child", http.StatusForbidden},
+		{"/notes?child_id=visible-child", http.StatusOK},
+		{"/notes", http.StatusOK},
+		{"/notes?child_id=db-error", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("GET %s: expected status %d, got %d", tt.path, tt.want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package visibility
+
+type RecordType string
+
+const (
+	RecordFeeding     RecordType = "feeding"
+	RecordSleep       RecordType = "sleep"
+	RecordMedication  RecordType = "medication"
+	RecordVaccination RecordType = "vaccination"
+	RecordAppointment RecordType = "appointment"
+	RecordNotes       RecordType = "notes"
+	RecordGrowth      RecordType = "growth"
+	RecordTemperature RecordType = "temperature"
+	RecordJournal     RecordType = "journal"
+)
+
+// MemberVisibility lists the record types hidden from one member of a family
+type MemberVisibility struct {
+	FamilyID    string       `json:"family_id"`
+	UserID      string       `json:"user_id"`
+	HiddenTypes []RecordType `json:"hidden_types"`
+}
+
+type SetVisibilityRequest struct {
+	HiddenTypes []RecordType `json:"hidden_types"`
+}
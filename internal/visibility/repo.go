@@ -0,0 +1,94 @@
+package visibility
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+type Repository interface {
+	ListHidden(ctx context.Context, familyID, userID string) ([]RecordType, error)
+	ReplaceHidden(ctx context.Context, familyID, userID string, types []RecordType) error
+	IsHiddenForChild(ctx context.Context, childID, userID string, recordType RecordType) (bool, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// HealthCheck checks the hidden record types table
+func (r *repository) HealthCheck(ctx context.Context) error {
+	return health.Tables(ctx, r.db, "hidden_record_types")
+}
+
+func (r *repository) ListHidden(ctx context.Context, familyID, userID string) ([]RecordType, error) {
+	query := `
+		SELECT record_type
+		FROM hidden_record_types
+		WHERE family_id = $1 AND user_id = $2
+		ORDER BY record_type ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Best-effort close
+
+	types := []RecordType{}
+	for rows.Next() {
+		var t RecordType
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+
+	return types, rows.Err()
+}
+
+func (r *repository) ReplaceHidden(ctx context.Context, familyID, userID string, types []RecordType) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM hidden_record_types WHERE family_id = $1 AND user_id = $2`, familyID, userID); err != nil {
+		return err
+	}
+
+	insert := `INSERT INTO hidden_record_types (family_id, user_id, record_type) VALUES ($1, $2, $3)`
+	for _, t := range types {
+		if _, err := tx.ExecContext(ctx, insert, familyID, userID, t); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// IsHiddenForChild resolves the child's family in the same query so the
+// per-request check costs a single round trip
+func (r *repository) IsHiddenForChild(ctx context.Context, childID, userID string, recordType RecordType) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM hidden_record_types h
+			JOIN children c ON c.family_id = h.family_id
+			WHERE c.id = $1 AND h.user_id = $2 AND h.record_type = $3
+		)
+	`
+
+	var hidden bool
+	if err := r.db.QueryRowContext(ctx, query, childID, userID, recordType).Scan(&hidden); err != nil {
+		return false, err
+	}
+	return hidden, nil
+}
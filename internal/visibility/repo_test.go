@@ -0,0 +1,77 @@
+package visibility
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_ListHidden(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT record_type").
+		WithArgs("family-1", "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"record_type"}).AddRow("notes"))
+
+	types, err := repo.ListHidden(context.Background(), "family-1", "user-1")
+	if err != nil {
+		t.Fatalf("ListHidden() error = %v", err)
+	}
+	if len(types) != 1 || types[0] != RecordNotes {
+		t.Errorf("ListHidden() = %v, want [notes]", types)
+	}
+}
+
+func TestRepository_ReplaceHidden(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM hidden_record_types").
+		WithArgs("family-1", "user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO hidden_record_types").
+		WithArgs("family-1", "user-1", RecordNotes).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.ReplaceHidden(context.Background(), "family-1", "user-1", []RecordType{RecordNotes}); err != nil {
+		t.Fatalf("ReplaceHidden() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_IsHiddenForChild(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("child-1", "user-1", RecordNotes).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	hidden, err := repo.IsHiddenForChild(context.Background(), "child-1", "user-1", RecordNotes)
+	if err != nil {
+		t.Fatalf("IsHiddenForChild() error = %v", err)
+	}
+	if !hidden {
+		t.Error("IsHiddenForChild() = false, want true")
+	}
+}
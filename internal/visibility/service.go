@@ -0,0 +1,145 @@
+package visibility
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ninenine/babytrack/internal/family"
+	"github.com/ninenine/babytrack/internal/health"
+)
+
+var (
+	ErrInvalidRecordType    = errors.New("invalid record type")
+	ErrNotAdmin             = errors.New("only admins can change member visibility")
+	ErrCannotRestrictAdmin  = errors.New("cannot hide records from an admin")
+	ErrVisibilityNotAllowed = errors.New("only admins or the member can view visibility settings")
+	ErrHidden               = errors.New("these records are hidden from you")
+	ErrChildNotFound        = errors.New("child not found")
+)
+
+type Service interface {
+	GetMemberVisibility(ctx context.Context, requesterID, familyID, userID string) (*MemberVisibility, error)
+	SetMemberVisibility(ctx context.Context, requesterID, familyID, userID string, hidden []RecordType) (*MemberVisibility, error)
+	IsHidden(ctx context.Context, userID, childID string, recordType RecordType) (bool, error)
+	// CheckChild returns family.ErrNotMember unless userID belongs to the
+	// child's family, and ErrHidden when recordType is hidden from them there
+	CheckChild(ctx context.Context, userID, childID string, recordType RecordType) error
+}
+
+type service struct {
+	repo          Repository
+	familyService family.Service
+}
+
+func NewService(repo Repository, familyService family.Service) Service {
+	return &service{
+		repo:          repo,
+		familyService: familyService,
+	}
+}
+
+// HealthCheck reports whether visibility settings can be read. Hidden
+// records cannot be filtered out without them.
+func (s *service) HealthCheck(ctx context.Context) error {
+	return health.Of(ctx, s.repo)
+}
+
+func (s *service) GetMemberVisibility(ctx context.Context, requesterID, familyID, userID string) (*MemberVisibility, error) {
+	if requesterID != userID {
+		role, err := s.familyService.GetMemberRole(ctx, familyID, requesterID)
+		if err != nil {
+			return nil, err
+		}
+		if role != "admin" {
+			return nil, ErrVisibilityNotAllowed
+		}
+	}
+
+	hidden, err := s.repo.ListHidden(ctx, familyID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visibility: %w", err)
+	}
+
+	return &MemberVisibility{FamilyID: familyID, UserID: userID, HiddenTypes: hidden}, nil
+}
+
+// SetMemberVisibility replaces the set of record types hidden from a member.
+// An empty list makes everything visible again.
+func (s *service) SetMemberVisibility(ctx context.Context, requesterID, familyID, userID string, hidden []RecordType) (*MemberVisibility, error) {
+	for _, t := range hidden {
+		if !validRecordType(t) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRecordType, t)
+		}
+	}
+
+	role, err := s.familyService.GetMemberRole(ctx, familyID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "admin" {
+		return nil, ErrNotAdmin
+	}
+
+	targetRole, err := s.familyService.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if targetRole == "admin" {
+		return nil, ErrCannotRestrictAdmin
+	}
+
+	if err := s.repo.ReplaceHidden(ctx, familyID, userID, dedupe(hidden)); err != nil {
+		return nil, fmt.Errorf("failed to update visibility: %w", err)
+	}
+
+	return s.GetMemberVisibility(ctx, requesterID, familyID, userID)
+}
+
+func (s *service) IsHidden(ctx context.Context, userID, childID string, recordType RecordType) (bool, error) {
+	return s.repo.IsHiddenForChild(ctx, childID, userID, recordType)
+}
+
+func (s *service) CheckChild(ctx context.Context, userID, childID string, recordType RecordType) error {
+	child, err := s.familyService.GetChild(ctx, childID)
+	if err != nil {
+		return err
+	}
+	if child == nil {
+		return ErrChildNotFound
+	}
+	if _, err := s.familyService.GetMemberRole(ctx, child.FamilyID, userID); err != nil {
+		return err
+	}
+
+	v, err := s.GetMemberVisibility(ctx, userID, child.FamilyID, userID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(v.HiddenTypes, recordType) {
+		return ErrHidden
+	}
+	return nil
+}
+
+func validRecordType(t RecordType) bool {
+	switch t {
+	case RecordFeeding, RecordSleep, RecordMedication, RecordVaccination, RecordAppointment, RecordNotes,
+		RecordGrowth, RecordTemperature, RecordJournal:
+		return true
+	}
+	return false
+}
+
+func dedupe(types []RecordType) []RecordType {
+	seen := make(map[RecordType]bool, len(types))
+	result := make([]RecordType, 0, len(types))
+	for _, t := range types {
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package visibility
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+type mockRepository struct {
+	hidden map[string][]RecordType // familyID/userID
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{hidden: make(map[string][]RecordType)}
+}
+
+func (m *mockRepository) ListHidden(ctx context.Context, familyID, userID string) ([]RecordType, error) {
+	return m.hidden[familyID+"/"+userID], nil
+}
+
+func (m *mockRepository) ReplaceHidden(ctx context.Context, familyID, userID string, types []RecordType) error {
+	m.hidden[familyID+"/"+userID] = types
+	return nil
+}
+
+func (m *mockRepository) IsHiddenForChild(ctx context.Context, childID, userID string, recordType RecordType) (bool, error) {
+	return false, nil
+}
+
+type mockFamilyService struct {
+	family.Service
+	roles map[string]string
+}
+
+func (m *mockFamilyService) GetMemberRole(ctx context.Context, familyID, userID string) (string, error) {
+	if role, ok := m.roles[userID]; ok {
+		return role, nil
+	}
+	return "", family.ErrNotMember
+}
+
+func newTestService() (Service, *mockRepository) {
+	repo := newMockRepository()
+	familySvc := &mockFamilyService{roles: map[string]string{
+		"admin-1":  "admin",
+		"admin-2":  "admin",
+		"sitter-1": "member",
+	}}
+	return NewService(repo, familySvc), repo
+}
+
+func TestService_SetMemberVisibility(t *testing.T) {
+	svc, repo := newTestService()
+
+	v, err := svc.SetMemberVisibility(context.Background(), "admin-1", "family-1", "sitter-1",
+		[]RecordType{RecordNotes, RecordMedication, RecordNotes})
+	if err != nil {
+		t.Fatalf("SetMemberVisibility() error = %v", err)
+	}
+
+	if len(v.HiddenTypes) != 2 {
+		t.Errorf("SetMemberVisibility() hidden = %v, want 2 deduplicated types", v.HiddenTypes)
+	}
+	if len(repo.hidden["family-1/sitter-1"]) != 2 {
+		t.Error("SetMemberVisibility() should persist hidden types")
+	}
+}
+
+func TestService_SetMemberVisibility_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		requester string
+		target    string
+		types     []RecordType
+		wantErr   error
+	}{
+		{"non-admin requester", "sitter-1", "sitter-1", []RecordType{RecordNotes}, ErrNotAdmin},
+		{"admin target", "admin-1", "admin-2", []RecordType{RecordNotes}, ErrCannotRestrictAdmin},
+		{"invalid type", "admin-1", "sitter-1", []RecordType{"photos"}, ErrInvalidRecordType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService()
+			_, err := svc.SetMemberVisibility(context.Background(), tt.requester, "family-1", tt.target, tt.types)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetMemberVisibility() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_GetMemberVisibility_Self(t *testing.T) {
+	svc, repo := newTestService()
+	repo.hidden["family-1/sitter-1"] = []RecordType{RecordNotes}
+
+	v, err := svc.GetMemberVisibility(context.Background(), "sitter-1", "family-1", "sitter-1")
+	if err != nil {
+		t.Fatalf("GetMemberVisibility() error = %v", err)
+	}
+	if len(v.HiddenTypes) != 1 {
+		t.Errorf("GetMemberVisibility() = %v", v.HiddenTypes)
+	}
+}
+
+func TestService_GetMemberVisibility_OtherMemberForbidden(t *testing.T) {
+	svc, _ := newTestService()
+
+	_, err := svc.GetMemberVisibility(context.Background(), "sitter-1", "family-1", "admin-1")
+	if !errors.Is(err, ErrVisibilityNotAllowed) {
+		t.Errorf("GetMemberVisibility() error = %v, want ErrVisibilityNotAllowed", err)
+	}
+}
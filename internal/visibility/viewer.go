@@ -0,0 +1,92 @@
+package visibility
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"github.com/ninenine/babytrack/internal/family"
+)
+
+// Viewer filters records across children for one member, checking each
+// child once
+type Viewer struct {
+	service    Service
+	userID     string
+	recordType RecordType
+	sees       map[string]bool
+}
+
+// ViewerFor returns the Viewer for a list filtered for viewerID, or nil when
+// there is no viewer to filter for. A list of one child's records fails with
+// CheckChild's error instead of coming back empty.
+func ViewerFor(ctx context.Context, s Service, viewerID, childID string, recordType RecordType) (*Viewer, error) {
+	if s == nil || viewerID == "" {
+		return nil, nil
+	}
+	v := &Viewer{service: s, userID: viewerID, recordType: recordType, sees: map[string]bool{}}
+	if childID != "" {
+		if err := s.CheckChild(ctx, viewerID, childID, recordType); err != nil {
+			return nil, err
+		}
+		v.sees[childID] = true
+	}
+	return v, nil
+}
+
+// Sees reports whether the member may see the child's records. Children
+// outside their families read as not seen.
+func (v *Viewer) Sees(ctx context.Context, childID string) (bool, error) {
+	if sees, ok := v.sees[childID]; ok {
+		return sees, nil
+	}
+	err := v.service.CheckChild(ctx, v.userID, childID, v.recordType)
+	switch {
+	case err == nil:
+		v.sees[childID] = true
+	case errors.Is(err, ErrHidden), errors.Is(err, ErrChildNotFound), errors.Is(err, family.ErrNotMember):
+		v.sees[childID] = false
+	default:
+		return false, err
+	}
+	return v.sees[childID], nil
+}
+
+// Filter keeps the records whose child the viewer sees. A nil viewer keeps
+// them all.
+func Filter[T any](ctx context.Context, v *Viewer, records []T, childOf func(T) string) ([]T, error) {
+	if v == nil {
+		return records, nil
+	}
+	kept := records[:0]
+	for _, r := range records {
+		sees, err := v.Sees(ctx, childOf(r))
+		if err != nil {
+			return nil, err
+		}
+		if sees {
+			kept = append(kept, r)
+		}
+	}
+	return kept, nil
+}
+
+// FilterSeq is Filter for streamed records
+func FilterSeq[T any](ctx context.Context, v *Viewer, records iter.Seq2[T, error], childOf func(T) string) iter.Seq2[T, error] {
+	if v == nil {
+		return records
+	}
+	return func(yield func(T, error) bool) {
+		for r, err := range records {
+			if err == nil {
+				var sees bool
+				if sees, err = v.Sees(ctx, childOf(r)); err == nil && !sees {
+					continue
+				}
+			}
+			if !yield(r, err) {
+				return
+			}
+		}
+	}
+}
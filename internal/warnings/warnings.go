@@ -0,0 +1,49 @@
+// Package warnings carries non-fatal problems with a request, such as a
+// timestamp that was adjusted, from the services that notice them back to the
+// response. The request's context holds the list; services add to it without
+// knowing whether anyone will read it.
+package warnings
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type contextKey struct{}
+
+type collector struct {
+	mu   sync.Mutex
+	list []string
+}
+
+// NewContext returns a context that collects warnings
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, &collector{})
+}
+
+// Add records a warning. It does nothing when ctx does not collect them.
+func Add(ctx context.Context, message string) {
+	c, ok := ctx.Value(contextKey{}).(*collector)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.list = append(c.list, message)
+}
+
+func Addf(ctx context.Context, format string, args ...any) {
+	Add(ctx, fmt.Sprintf(format, args...))
+}
+
+// From returns the warnings recorded so far
+func From(ctx context.Context) []string {
+	c, ok := ctx.Value(contextKey{}).(*collector)
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.list...)
+}
@@ -0,0 +1,28 @@
+package warnings
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestCollect(t *testing.T) {
+	ctx := NewContext(context.Background())
+
+	Add(ctx, "first")
+	Addf(ctx, "dose %d of %d", 3, 2)
+
+	if got := From(ctx); !slices.Equal(got, []string{"first", "dose 3 of 2"}) {
+		t.Errorf("From() = %v", got)
+	}
+}
+
+func TestAdd_WithoutCollector(t *testing.T) {
+	ctx := context.Background()
+
+	Add(ctx, "dropped")
+
+	if got := From(ctx); got != nil {
+		t.Errorf("From() = %v, want nil", got)
+	}
+}
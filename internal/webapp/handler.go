@@ -0,0 +1,126 @@
+package webapp
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Prefix is where the dashboard is mounted
+const Prefix = "/app"
+
+const (
+	cacheImmutable  = "public, max-age=31536000, immutable"
+	cacheRevalidate = "no-cache"
+)
+
+// versioned are the assets the page links with a content hash. The manifest
+// and service worker keep fixed same-origin URLs, as browsers require.
+var versioned = []string{"app.js", "app.css", "icon.svg"}
+
+//go:embed static
+var assets embed.FS
+
+// Handler serves the lightweight web dashboard: plain HTML, CSS and JS that
+// talk to the existing API, for self-hosters without the mobile app
+type Handler struct {
+	files    fs.FS
+	index    []byte
+	versions map[string]string
+	etags    map[string]string
+}
+
+// NewHandler serves the dashboard. assetBaseURL is an optional CDN origin,
+// such as https://cdn.example.com, that pulls from this server; the page
+// then loads its versioned assets from there.
+func NewHandler(assetBaseURL string) *Handler {
+	files, err := fs.Sub(assets, "static")
+	if err != nil {
+		panic(err)
+	}
+	h := &Handler{files: files, versions: map[string]string{}, etags: map[string]string{}}
+
+	err = fs.WalkDir(files, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(files, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		h.etags[name] = `"` + hex.EncodeToString(sum[:]) + `"`
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	index, err := fs.ReadFile(files, "index.html")
+	if err != nil {
+		panic(err)
+	}
+	base := strings.TrimSuffix(assetBaseURL, "/")
+	for _, name := range versioned {
+		h.versions[name] = strings.Trim(h.etags[name], `"`)[:12]
+		url := Prefix + "/" + name
+		index = []byte(strings.ReplaceAll(string(index), `"`+url+`"`, `"`+base+url+"?v="+h.versions[name]+`"`))
+	}
+	h.index = index
+
+	sum := sha256.Sum256(h.index)
+	h.etags["index.html"] = `"` + hex.EncodeToString(sum[:]) + `"`
+	return h
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, Prefix+"/")
+	})
+	rg.GET("/*filepath", h.serve)
+}
+
+// serve returns the asset at the path, or index.html for anything else so
+// the dashboard's own routes such as /app/login load the page
+func (h *Handler) serve(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("filepath"), "/")
+	if _, ok := h.etags[name]; !ok {
+		name = "index.html"
+	}
+
+	// Content-hash URLs never change meaning, so browsers and CDNs keep them
+	// for good. Everything else, the page in particular, is revalidated so an
+	// upgraded binary takes effect at once.
+	if v := c.Query("v"); v != "" && v == h.versions[name] {
+		c.Header("Cache-Control", cacheImmutable)
+	} else {
+		c.Header("Cache-Control", cacheRevalidate)
+	}
+	c.Header("ETag", h.etags[name])
+	if c.GetHeader("If-None-Match") == h.etags[name] {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	data := h.index
+	if name != "index.html" {
+		var err error
+		if data, err = fs.ReadFile(h.files, name); err != nil {
+			c.String(http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if path.Ext(name) == ".webmanifest" {
+		contentType = "application/manifest+json"
+	}
+	c.Data(http.StatusOK, contentType, data)
+}
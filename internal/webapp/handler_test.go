@@ -0,0 +1,143 @@
+package webapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func setupRouter() *gin.Engine {
+	router := gin.New()
+	NewHandler("").RegisterRoutes(router.Group(Prefix))
+	return router
+}
+
+func get(router *gin.Engine, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestServe_Index(t *testing.T) {
+	w := get(setupRouter(), "/app/")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Expected HTML, got %q", w.Header().Get("Content-Type"))
+	}
+	if w.Header().Get("Cache-Control") != "no-cache" {
+		t.Error("Expected the page to be revalidated")
+	}
+	if !strings.Contains(w.Body.String(), `"/app/app.js?v=`) {
+		t.Error("Expected the page to link assets by content hash")
+	}
+	if !strings.Contains(w.Body.String(), "/api/auth/google?client=dashboard") {
+		t.Error("Expected the page to offer sign-in that returns to the dashboard")
+	}
+}
+
+func TestServe_Assets(t *testing.T) {
+	router := setupRouter()
+
+	tests := []struct {
+		path        string
+		contentType string
+	}{
+		{"/app/app.js", "javascript"},
+		{"/app/app.css", "text/css"},
+		{"/app/sw.js", "javascript"},
+		{"/app/manifest.webmanifest", "application/manifest+json"},
+		{"/app/icon.svg", "image/svg+xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := get(router, tt.path)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+			if !strings.Contains(w.Header().Get("Content-Type"), tt.contentType) {
+				t.Errorf("Expected content type %q, got %q", tt.contentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestServe_FallsBackToIndex(t *testing.T) {
+	w := get(setupRouter(), "/app/login?token=abc")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "<title>BabyTrack</title>") {
+		t.Error("Expected the dashboard page for its own routes")
+	}
+}
+
+func TestServe_RedirectsBarePrefix(t *testing.T) {
+	w := get(setupRouter(), "/app")
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("Expected status 301, got %d", w.Code)
+	}
+	if w.Header().Get("Location") != "/app/" {
+		t.Errorf("Expected redirect to /app/, got %q", w.Header().Get("Location"))
+	}
+}
+
+func TestServe_VersionedAssetsAreImmutable(t *testing.T) {
+	h := NewHandler("")
+	router := gin.New()
+	h.RegisterRoutes(router.Group(Prefix))
+
+	w := get(router, "/app/app.js?v="+h.versions["app.js"])
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=31536000, immutable" {
+		t.Errorf("Expected a hashed URL to be cached for good, got %q", got)
+	}
+
+	w = get(router, "/app/app.js?v=old")
+	if got := w.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Expected a stale version to be revalidated, got %q", got)
+	}
+}
+
+func TestServe_NotModified(t *testing.T) {
+	router := setupRouter()
+
+	etag := get(router, "/app/app.css").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+
+	req := httptest.NewRequest("GET", "/app/app.css", nil)
+	req.Header.Set("If-None-Match", etag)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected status 304, got %d", w.Code)
+	}
+}
+
+func TestServe_AssetBaseURL(t *testing.T) {
+	router := gin.New()
+	NewHandler("https://cdn.example.com/").RegisterRoutes(router.Group(Prefix))
+
+	body := get(router, "/app/").Body.String()
+	if !strings.Contains(body, `"https://cdn.example.com/app/app.js?v=`) {
+		t.Error("Expected hashed assets to load from the CDN")
+	}
+	if !strings.Contains(body, `href="/app/manifest.webmanifest"`) {
+		t.Error("Expected the manifest to stay on this origin")
+	}
+}
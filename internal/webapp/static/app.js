@@ -0,0 +1,270 @@
+// BabyTrack web dashboard. Plain JavaScript against the public API, so it
+// needs no build step and ships inside the server binary.
+;(function () {
+  'use strict'
+
+  var TOKEN_KEY = 'babytrack.dashboard.token'
+  var CHILD_KEY = 'babytrack.dashboard.child'
+  var TIMELINE_DAYS = 3
+
+  var state = { token: localStorage.getItem(TOKEN_KEY), childId: null, activeSleep: null }
+
+  function $(id) {
+    return document.getElementById(id)
+  }
+
+  function show(id, visible) {
+    $(id).hidden = !visible
+  }
+
+  function setStatus(message) {
+    $('status').textContent = message || ''
+    show('status', !!message)
+  }
+
+  function signOut() {
+    localStorage.removeItem(TOKEN_KEY)
+    state.token = null
+    render()
+  }
+
+  function api(method, url, body) {
+    var opts = { method: method, headers: { Authorization: 'Bearer ' + state.token } }
+    if (body !== undefined) {
+      opts.headers['Content-Type'] = 'application/json'
+      opts.body = JSON.stringify(body)
+    }
+    return fetch(url, opts).then(function (res) {
+      if (res.status === 401) {
+        signOut()
+        throw new Error('Your session has expired, please sign in again')
+      }
+      return res.json().catch(function () { return null }).then(function (data) {
+        if (!res.ok) {
+          throw new Error((data && (data.message || data.error)) || 'Request failed (' + res.status + ')')
+        }
+        return data
+      })
+    })
+  }
+
+  // The sign-in callback lands on /app/login with the token or an error
+  function takeLoginResult() {
+    if (location.pathname !== '/app/login') return
+    var params = new URLSearchParams(location.search)
+    if (params.get('token')) {
+      state.token = params.get('token')
+      localStorage.setItem(TOKEN_KEY, state.token)
+    }
+    if (params.get('error')) {
+      $('login-error').textContent = 'Sign-in failed: ' + params.get('error')
+      show('login-error', true)
+    }
+    history.replaceState(null, '', '/app/')
+  }
+
+  function loadChildren() {
+    return api('GET', '/api/families').then(function (families) {
+      var select = $('child')
+      select.innerHTML = ''
+      ;(families || []).forEach(function (family) {
+        ;(family.children || []).forEach(function (child) {
+          var opt = document.createElement('option')
+          opt.value = child.id
+          opt.textContent = families.length > 1 ? child.name + ' (' + family.name + ')' : child.name
+          select.appendChild(opt)
+        })
+      })
+      if (!select.options.length) {
+        setStatus('Add a child in the BabyTrack app to start logging.')
+        return
+      }
+      var saved = localStorage.getItem(CHILD_KEY)
+      select.value = saved && select.querySelector('option[value="' + saved + '"]') ? saved : select.options[0].value
+      state.childId = select.value
+    })
+  }
+
+  function formatTime(date) {
+    return date.toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })
+  }
+
+  function minutesBetween(start, end) {
+    return Math.round((new Date(end) - new Date(start)) / 60000)
+  }
+
+  function describeFeeding(f) {
+    var text = f.type.charAt(0).toUpperCase() + f.type.slice(1)
+    if (f.amount) text += ' ' + f.amount + ' ' + (f.unit || 'ml')
+    if (f.end_time) text += ', ' + minutesBetween(f.start_time, f.end_time) + ' min'
+    if (f.side) text += ' (' + f.side + ')'
+    return text
+  }
+
+  function describeSleep(s) {
+    var text = s.type === 'night' ? 'Night sleep' : 'Nap'
+    return s.end_time ? text + ', ' + minutesBetween(s.start_time, s.end_time) + ' min' : text + ' (sleeping now)'
+  }
+
+  function loadTimeline() {
+    if (!state.childId) return Promise.resolve()
+    var q = '?child_id=' + encodeURIComponent(state.childId)
+    var since = Date.now() - TIMELINE_DAYS * 24 * 3600 * 1000
+
+    return Promise.all([
+      api('GET', '/api/feeding' + q),
+      api('GET', '/api/sleep' + q),
+      api('GET', '/api/notes' + q),
+      api('GET', '/api/sleep/active/' + encodeURIComponent(state.childId)),
+    ]).then(function (results) {
+      var entries = []
+      ;(results[0] || []).forEach(function (f) {
+        entries.push({ at: new Date(f.start_time), text: describeFeeding(f) })
+      })
+      ;(results[1] || []).forEach(function (s) {
+        entries.push({ at: new Date(s.start_time), text: describeSleep(s) })
+      })
+      ;(results[2] || []).forEach(function (n) {
+        entries.push({ at: new Date(n.occurred_at), text: n.title ? n.title + ': ' + n.content : n.content })
+      })
+
+      entries = entries.filter(function (e) { return e.at.getTime() >= since })
+      entries.sort(function (a, b) { return b.at - a.at })
+      renderTimeline(entries)
+
+      state.activeSleep = results[3]
+      $('sleep-state').textContent = state.activeSleep
+        ? 'Asleep since ' + formatTime(new Date(state.activeSleep.start_time))
+        : 'Awake'
+      $('sleep-toggle').textContent = state.activeSleep ? 'Wake up' : 'Start'
+      $('log-sleep').elements.type.hidden = !!state.activeSleep
+    })
+  }
+
+  function renderTimeline(entries) {
+    var list = $('timeline')
+    list.innerHTML = ''
+    if (!entries.length) {
+      var empty = document.createElement('li')
+      empty.textContent = 'Nothing logged in the last ' + TIMELINE_DAYS + ' days.'
+      list.appendChild(empty)
+      return
+    }
+
+    var lastDay = ''
+    entries.forEach(function (e) {
+      var day = e.at.toLocaleDateString([], { weekday: 'long', day: 'numeric', month: 'short' })
+      if (day !== lastDay) {
+        var heading = document.createElement('li')
+        heading.className = 'day'
+        heading.textContent = day
+        list.appendChild(heading)
+        lastDay = day
+      }
+      var item = document.createElement('li')
+      var time = document.createElement('time')
+      time.dateTime = e.at.toISOString()
+      time.textContent = formatTime(e.at)
+      var text = document.createElement('span')
+      text.textContent = e.text
+      item.appendChild(time)
+      item.appendChild(text)
+      list.appendChild(item)
+    })
+  }
+
+  function refresh() {
+    return loadTimeline().catch(function (err) { setStatus(err.message) })
+  }
+
+  // submit wires a quick-log form to a request built from its fields
+  function submit(id, build) {
+    $(id).addEventListener('submit', function (event) {
+      event.preventDefault()
+      var form = event.target
+      var button = form.querySelector('button')
+      var req = build(form.elements)
+      button.disabled = true
+      setStatus('')
+      api(req.method || 'POST', req.url, req.body)
+        .then(function () {
+          form.reset()
+          return refresh()
+        })
+        .catch(function (err) { setStatus(err.message) })
+        .then(function () { button.disabled = false })
+    })
+  }
+
+  submit('log-bottle', function (f) {
+    return {
+      url: '/api/feeding',
+      body: {
+        child_id: state.childId,
+        type: 'bottle',
+        start_time: new Date().toISOString(),
+        amount: Number(f.amount.value),
+        unit: f.unit.value,
+      },
+    }
+  })
+
+  submit('log-breast', function (f) {
+    var end = new Date()
+    var start = new Date(end.getTime() - Number(f.minutes.value) * 60000)
+    return {
+      url: '/api/feeding',
+      body: {
+        child_id: state.childId,
+        type: 'breast',
+        start_time: start.toISOString(),
+        end_time: end.toISOString(),
+        side: f.side.value,
+      },
+    }
+  })
+
+  submit('log-sleep', function (f) {
+    if (state.activeSleep) {
+      return { url: '/api/sleep/' + encodeURIComponent(state.activeSleep.id) + '/end' }
+    }
+    return { url: '/api/sleep/start', body: { child_id: state.childId, type: f.type.value } }
+  })
+
+  submit('log-note', function (f) {
+    return { url: '/api/notes', body: { child_id: state.childId, content: f.content.value } }
+  })
+
+  $('child').addEventListener('change', function (event) {
+    state.childId = event.target.value
+    localStorage.setItem(CHILD_KEY, state.childId)
+    refresh()
+  })
+
+  $('logout').addEventListener('click', signOut)
+
+  function render() {
+    var signedIn = !!state.token
+    show('login', !signedIn)
+    show('dashboard', signedIn)
+    show('account', signedIn)
+    if (!signedIn) return
+
+    api('GET', '/api/auth/me')
+      .then(loadChildren)
+      .then(refresh)
+      .catch(function (err) { setStatus(err.message) })
+  }
+
+  takeLoginResult()
+  render()
+
+  // Keep the timeline current while the page is open
+  setInterval(function () {
+    if (state.token && !document.hidden) refresh()
+  }, 60000)
+
+  if ('serviceWorker' in navigator) {
+    navigator.serviceWorker.register('/app/sw.js', { scope: '/app/' })
+  }
+})()
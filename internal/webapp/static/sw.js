@@ -0,0 +1,43 @@
+// Caches the dashboard shell so it opens offline. API requests always go to
+// the network; logging needs a connection.
+'use strict'
+
+var CACHE = 'babytrack-dashboard-v1'
+// Scripts and styles are linked by content hash, so they are cached as the
+// page loads them rather than listed here
+var SHELL = ['/app/', '/app/manifest.webmanifest']
+
+self.addEventListener('install', function (event) {
+  event.waitUntil(caches.open(CACHE).then(function (cache) { return cache.addAll(SHELL) }))
+  self.skipWaiting()
+})
+
+self.addEventListener('activate', function (event) {
+  event.waitUntil(
+    caches.keys().then(function (keys) {
+      return Promise.all(keys.filter(function (k) { return k !== CACHE }).map(function (k) { return caches.delete(k) }))
+    })
+  )
+  self.clients.claim()
+})
+
+// Network first, so a new server version is picked up, falling back to the
+// cached shell when offline
+self.addEventListener('fetch', function (event) {
+  var url = new URL(event.request.url)
+  if (event.request.method !== 'GET' || !url.pathname.startsWith('/app/')) return
+
+  event.respondWith(
+    fetch(event.request)
+      .then(function (res) {
+        if (res.ok) {
+          var copy = res.clone()
+          caches.open(CACHE).then(function (cache) { cache.put(event.request, copy) })
+        }
+        return res
+      })
+      .catch(function () {
+        return caches.match(event.request).then(function (hit) { return hit || caches.match('/app/') })
+      })
+  )
+})
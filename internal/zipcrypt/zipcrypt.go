@@ -0,0 +1,258 @@
+// Package zipcrypt writes zip archives whose contents are encrypted with a
+// passphrase, using WinZip's AES-256 scheme (AE-2). 7-Zip, WinZip, Keka,
+// libarchive and most archive tools open them; the zip format's own legacy
+// encryption is too weak for medical records. File names, sizes and times
+// are not encrypted.
+package zipcrypt
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // The WinZip AES format fixes PBKDF2 and the MAC to SHA-1
+	"encoding/binary"
+	"hash"
+	"io"
+	"math"
+	"time"
+)
+
+// From the WinZip AES specification
+const (
+	methodAES     = 99
+	flagEncrypted = 0x1
+	// Sizes follow the entry rather than lead it
+	flagDataDescriptor = 0x8
+	extraID            = 0x9901
+	versionAE2         = 2
+	strength256        = 3
+	keySize            = 32
+	saltSize           = 16
+	verifierSize       = 2
+	macSize            = 10
+	iterations         = 1000
+)
+
+// File is one entry of an archive
+type File struct {
+	Name     string
+	Modified time.Time
+	Data     []byte
+}
+
+// Write writes an archive of the files, each compressed and then encrypted
+// with a key derived from the passphrase and a fresh salt
+func Write(w io.Writer, passphrase string, files ...File) error {
+	zw := NewWriter(w, passphrase)
+	for _, f := range files {
+		fw, err := zw.Create(f.Name, f.Modified)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Writer writes an archive one entry at a time, compressing and encrypting
+// as the contents arrive, so an entry need not fit in memory. The sizes
+// follow each entry in a data descriptor.
+type Writer struct {
+	zw         *zip.Writer
+	passphrase string
+	last       *entry
+}
+
+// NewWriter returns a Writer whose entries are encrypted with keys derived
+// from the passphrase
+func NewWriter(w io.Writer, passphrase string) *Writer {
+	return &Writer{zw: zip.NewWriter(w), passphrase: passphrase}
+}
+
+// Create adds an entry and returns a writer for its contents. The entry is
+// sealed when the next one is created or the archive is closed.
+func (w *Writer) Create(name string, modified time.Time) (io.Writer, error) {
+	if err := w.seal(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	encKey, macKey, verifier, err := deriveKeys(w.passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	fh := &zip.FileHeader{
+		Name:   name,
+		Method: methodAES,
+		Flags:  flagEncrypted | flagDataDescriptor,
+		// AE-2 leaves the CRC out; the MAC covers the contents instead
+		CRC32: 0,
+		Extra: aesExtra(zip.Deflate),
+	}
+	fh.ModifiedDate, fh.ModifiedTime = dosTime(modified)
+
+	raw, err := w.zw.CreateRaw(fh)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := raw.Write(append(salt, verifier...)); err != nil {
+		return nil, err
+	}
+
+	e := &entry{header: fh, raw: raw, enc: &encrypter{w: raw, ctr: newCTR(block), mac: hmac.New(sha1.New, macKey)}}
+	if e.flate, err = flate.NewWriter(e.enc, flate.DefaultCompression); err != nil {
+		return nil, err
+	}
+	w.last = e
+	return e, nil
+}
+
+// Close seals the last entry and writes the central directory. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.seal(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+func (w *Writer) seal() error {
+	if w.last == nil {
+		return nil
+	}
+	e := w.last
+	w.last = nil
+	return e.close()
+}
+
+// entry compresses what is written to it into the encrypter
+type entry struct {
+	header *zip.FileHeader
+	raw    io.Writer
+	flate  *flate.Writer
+	enc    *encrypter
+	size   uint64
+}
+
+func (e *entry) Write(p []byte) (int, error) {
+	n, err := e.flate.Write(p)
+	e.size += uint64(n)
+	return n, err
+}
+
+// close finishes the body with the truncated HMAC of the ciphertext and
+// fills in the sizes, which zip.Writer puts in the data descriptor and the
+// central directory
+func (e *entry) close() error {
+	if err := e.flate.Close(); err != nil {
+		return err
+	}
+	if _, err := e.raw.Write(e.enc.mac.Sum(nil)[:macSize]); err != nil {
+		return err
+	}
+
+	fh := e.header
+	fh.CompressedSize64 = uint64(saltSize+verifierSize+macSize) + e.enc.n
+	fh.UncompressedSize64 = e.size
+	fh.CompressedSize = uint32(min(fh.CompressedSize64, math.MaxUint32))
+	fh.UncompressedSize = uint32(min(fh.UncompressedSize64, math.MaxUint32))
+	return nil
+}
+
+// encrypter encrypts an entry's compressed contents on their way out and
+// MACs the ciphertext
+type encrypter struct {
+	w   io.Writer
+	ctr *ctr
+	mac hash.Hash
+	n   uint64
+}
+
+func (e *encrypter) Write(p []byte) (int, error) {
+	ciphertext := make([]byte, len(p))
+	e.ctr.xorKeyStream(ciphertext, p)
+	e.mac.Write(ciphertext)
+	n, err := e.w.Write(ciphertext)
+	e.n += uint64(n)
+	return n, err
+}
+
+func deriveKeys(passphrase string, salt []byte) (encKey, macKey, verifier []byte, err error) {
+	keys, err := pbkdf2.Key(sha1.New, passphrase, salt, iterations, 2*keySize+verifierSize)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return keys[:keySize], keys[keySize : 2*keySize], keys[2*keySize:], nil
+}
+
+// xorKeyStream is AES in counter mode as WinZip uses it: the counter is a
+// little-endian number that starts at 1, unlike crypto/cipher's CTR
+func xorKeyStream(block cipher.Block, dst, src []byte) {
+	newCTR(block).xorKeyStream(dst, src)
+}
+
+// ctr is the state of xorKeyStream between writes
+type ctr struct {
+	block           cipher.Block
+	counter, stream [aes.BlockSize]byte
+	used            int
+}
+
+func newCTR(block cipher.Block) *ctr {
+	return &ctr{block: block, used: aes.BlockSize}
+}
+
+func (c *ctr) xorKeyStream(dst, src []byte) {
+	for i := range src {
+		if c.used == aes.BlockSize {
+			for j := range c.counter {
+				c.counter[j]++
+				if c.counter[j] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.stream[:], c.counter[:])
+			c.used = 0
+		}
+		dst[i] = src[i] ^ c.stream[c.used]
+		c.used++
+	}
+}
+
+// aesExtra is the extra field that marks an entry as AES encrypted and
+// records how it was compressed
+func aesExtra(method uint16) []byte {
+	b := make([]byte, 11)
+	binary.LittleEndian.PutUint16(b[0:], extraID)
+	binary.LittleEndian.PutUint16(b[2:], 7)
+	binary.LittleEndian.PutUint16(b[4:], versionAE2)
+	copy(b[6:], "AE")
+	b[8] = strength256
+	binary.LittleEndian.PutUint16(b[9:], method)
+	return b
+}
+
+// dosTime converts t to the MS-DOS date and time zip headers carry, which
+// start in 1980 and count seconds in twos
+func dosTime(t time.Time) (date, clock uint16) {
+	if t.Year() < 1980 {
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	date = uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	clock = uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, clock
+}
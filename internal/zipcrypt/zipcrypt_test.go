@@ -0,0 +1,176 @@
+package zipcrypt
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // The format's MAC
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite_ReadBack(t *testing.T) {
+	modified := time.Date(2024, 6, 1, 14, 30, 10, 0, time.UTC)
+	files := []File{
+		{Name: "dataset.csv", Modified: modified, Data: []byte("timestamp,type,metric,value\n")},
+		{Name: "bundle.json", Modified: modified, Data: []byte(strings.Repeat(`{"feeding":"bottle"}`, 500))},
+		{Name: "empty.txt", Modified: modified},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "correct horse", files...); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	if len(r.File) != len(files) {
+		t.Fatalf("archive has %d files, want %d", len(r.File), len(files))
+	}
+
+	for i, f := range r.File {
+		want := files[i]
+		if f.Name != want.Name || f.Method != methodAES || f.Flags&flagEncrypted == 0 {
+			t.Errorf("%s: header = %+v", want.Name, f.FileHeader)
+		}
+		if f.UncompressedSize64 != uint64(len(want.Data)) {
+			t.Errorf("%s: uncompressed size = %d, want %d", want.Name, f.UncompressedSize64, len(want.Data))
+		}
+		if !f.Modified.Equal(modified) {
+			t.Errorf("%s: modified = %v, want %v", want.Name, f.Modified, modified)
+		}
+		if !bytes.Contains(f.Extra, aesExtra(zip.Deflate)) {
+			t.Errorf("%s: missing the AES extra field", want.Name)
+		}
+
+		raw, err := f.OpenRaw()
+		if err != nil {
+			t.Fatalf("OpenRaw() error = %v", err)
+		}
+		body, _ := io.ReadAll(raw)
+
+		if _, err := open(body, "wrong horse"); err == nil {
+			t.Errorf("%s: opened with the wrong passphrase", want.Name)
+		}
+		got, err := open(body, "correct horse")
+		if err != nil {
+			t.Fatalf("%s: %v", want.Name, err)
+		}
+		if !bytes.Equal(got, want.Data) {
+			t.Errorf("%s: read back %d bytes that differ from the %d written", want.Name, len(got), len(want.Data))
+		}
+	}
+}
+
+func TestWrite_FreshSalt(t *testing.T) {
+	var a, b bytes.Buffer
+	file := File{Name: "a.txt", Data: []byte("same contents")}
+	if err := Write(&a, "passphrase", file); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(&b, "passphrase", file); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("Write() should salt every archive differently")
+	}
+}
+
+func TestWriter_Chunks(t *testing.T) {
+	// Odd-sized writes run across cipher blocks and flate's buffer
+	data := []byte(strings.Repeat("2024-06-01T08:00:00Z,feeding,amount_ml,120\n", 3000))
+
+	var buf bytes.Buffer
+	zw := NewWriter(&buf, "correct horse")
+	fw, err := zw.Create("dataset.csv", time.Now())
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for rest := data; len(rest) > 0; {
+		n := min(len(rest), 37)
+		if _, err := fw.Write(rest[:n]); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		rest = rest[n:]
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	f := r.File[0]
+	if f.UncompressedSize64 != uint64(len(data)) {
+		t.Errorf("uncompressed size = %d, want %d", f.UncompressedSize64, len(data))
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		t.Fatalf("OpenRaw() error = %v", err)
+	}
+	body, _ := io.ReadAll(raw)
+	if uint64(len(body)) != f.CompressedSize64 {
+		t.Errorf("body is %d bytes, header says %d", len(body), f.CompressedSize64)
+	}
+	got, err := open(body, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("read back contents differ from those written")
+	}
+}
+
+func TestXorKeyStream_Counter(t *testing.T) {
+	// The first block is encrypted under counter 1 in little-endian order,
+	// and the 257th carries into the second byte
+	block, _ := aes.NewCipher(make([]byte, keySize))
+	src := make([]byte, 257*aes.BlockSize)
+	dst := make([]byte, len(src))
+	xorKeyStream(block, dst, src)
+
+	var counter, want [aes.BlockSize]byte
+	counter[0] = 1
+	block.Encrypt(want[:], counter[:])
+	if !bytes.Equal(dst[:aes.BlockSize], want[:]) {
+		t.Error("first block should use counter 1")
+	}
+	counter[0], counter[1] = 1, 1
+	block.Encrypt(want[:], counter[:])
+	if !bytes.Equal(dst[256*aes.BlockSize:], want[:]) {
+		t.Error("block 257 should use counter 257")
+	}
+}
+
+// open checks and decrypts an entry's body the way an unzip tool does
+func open(body []byte, passphrase string) ([]byte, error) {
+	salt := body[:saltSize]
+	verifier := body[saltSize : saltSize+verifierSize]
+	ciphertext := body[saltSize+verifierSize : len(body)-macSize]
+	tag := body[len(body)-macSize:]
+
+	encKey, macKey, wantVerifier, err := deriveKeys(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(verifier, wantVerifier) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	mac := hmac.New(sha1.New, macKey)
+	mac.Write(ciphertext)
+	if !hmac.Equal(tag, mac.Sum(nil)[:macSize]) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	block, _ := aes.NewCipher(encKey)
+	compressed := make([]byte, len(ciphertext))
+	xorKeyStream(block, compressed, ciphertext)
+	return io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+}